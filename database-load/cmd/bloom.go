@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/bloom"
+)
+
+// defaultBitsPerCode gives the coupon filter a false positive rate of about
+// 1%; order-food uses the same default
+const defaultBitsPerCode = 10
+
+// refreshCouponFilter writes the coupon filter snapshot to
+// COUPON_FILTER_SNAPSHOT, when set, and tells order-food replicas to reload
+// their filters. Failures are only logged: replicas keep validating codes
+// against the database.
+func refreshCouponFilter(ctx context.Context, db *sql.DB) {
+	if path := app.Getenv("COUPON_FILTER_SNAPSHOT", ""); path != "" {
+		if err := writeCouponFilter(ctx, db, path, app.GetenvInt("COUPON_FILTER_BITS_PER_CODE", defaultBitsPerCode)); err != nil {
+			log.Printf("Warning: Failed to write coupon filter snapshot: %v", err)
+		}
+	}
+	if err := publishCouponInvalidation(ctx, db); err != nil {
+		log.Printf("Warning: Failed to publish coupon filter invalidation: %v", err)
+	}
+}
+
+// writeCouponFilter builds a filter of every code found in at least two
+// coupon files and writes it to path
+func writeCouponFilter(ctx context.Context, db *sql.DB, path string, bitsPerCode int) error {
+	log.Println("Building coupon filter snapshot...")
+	start := time.Now()
+
+	// The filter is sized from the planner's row estimate: every valid code
+	// appears in at least two files, so at most half the rows are valid
+	// codes. A stale estimate only raises the false positive rate.
+	var rows int64
+	if err := db.QueryRowContext(ctx, `SELECT reltuples::bigint FROM pg_class WHERE oid = 'coupons'::regclass`).Scan(&rows); err != nil {
+		return fmt.Errorf("failed to estimate coupon rows: %w", err)
+	}
+	if rows < 0 {
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM coupons`).Scan(&rows); err != nil {
+			return fmt.Errorf("failed to count coupon rows: %w", err)
+		}
+	}
+	// Leave room for campaign codes order-food adds to its copy
+	estimate := int(rows / 2)
+	filter := bloom.New(max(estimate+estimate/4, 100_000), bitsPerCode)
+
+	codes, err := db.QueryContext(ctx, `SELECT upper(coupon) FROM coupons GROUP BY upper(coupon) HAVING COUNT(DISTINCT file_name) >= 2`)
+	if err != nil {
+		return fmt.Errorf("failed to query valid coupons: %w", err)
+	}
+	defer codes.Close()
+	for codes.Next() {
+		var code string
+		if err := codes.Scan(&code); err != nil {
+			return fmt.Errorf("failed to scan coupon: %w", err)
+		}
+		filter.Add(code)
+	}
+	if err := codes.Err(); err != nil {
+		return fmt.Errorf("failed to read valid coupons: %w", err)
+	}
+
+	if err := filter.WriteFile(path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	log.Printf("✓ Wrote coupon filter snapshot with %d codes (%d MB) in %s",
+		filter.Count(), filter.SizeBytes()>>20, time.Since(start).Round(time.Second))
+	return nil
+}
+
+// publishCouponInvalidation records an invalidation event telling
+// order-food replicas to reload their coupon filters
+func publishCouponInvalidation(ctx context.Context, db *sql.DB) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := db.ExecContext(ctxTimeout, `INSERT INTO cache_invalidations (topic, cache_key) VALUES ('coupons', '*')`)
+	return err
+}
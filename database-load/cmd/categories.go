@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// categorySlugSeparators are the runs of characters replaced by a dash in
+// the ID of a category created for a product file
+var categorySlugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// categoryIDs remembers the IDs of the categories known to exist during a
+// load by name, so each is only looked up once
+type categoryIDs map[string]string
+
+// ensure returns the ID of the category named name, creating it unless it
+// exists. Products refer to existing categories by ID, and product files
+// name them rather than creating them. The category's ID is derived from
+// the name like order-food does, with a hash of the name added when another
+// category has that ID.
+func (known categoryIDs) ensure(ctx context.Context, db *sql.DB, name string) (string, error) {
+	if id, ok := known[name]; ok {
+		return id, nil
+	}
+
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	slug := categorySlug(name)
+	sum := sha1.Sum([]byte(name))
+	query := `INSERT INTO categories (id, name, created_at, updated_at) VALUES ($1, $2, NOW(), NOW())
+	          ON CONFLICT DO NOTHING`
+	for _, id := range []string{slug, strings.TrimRight(truncate(slug, 41), "-") + "-" + hex.EncodeToString(sum[:4])} {
+		if _, err := db.ExecContext(ctxTimeout, query, id, name); err != nil {
+			return "", fmt.Errorf("failed to create category %q: %w", name, err)
+		}
+		var existing string
+		err := db.QueryRowContext(ctxTimeout, `SELECT id FROM categories WHERE name = $1`, name).Scan(&existing)
+		if err == nil {
+			known[name] = existing
+			return existing, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("failed to look up category %q: %w", name, err)
+		}
+	}
+	return "", fmt.Errorf("failed to create category %q: its ID is taken", name)
+}
+
+// categorySlug derives a category ID from a name: lower case, with runs of
+// other characters turned into dashes
+func categorySlug(name string) string {
+	slug := strings.Trim(categorySlugSeparators.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	slug = strings.TrimRight(truncate(slug, 50), "-")
+	if slug == "" {
+		return "category"
+	}
+	return slug
+}
+
+// truncate cuts s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/doctor"
+)
+
+// requiredSchemaVersion is the newest migration the loader depends on
+const requiredSchemaVersion = 53
+
+// runDoctor implements the doctor command, which checks configuration,
+// database access and the data files and prints a diagnosis
+func runDoctor(ctx context.Context) error {
+	d := doctor.New(app.GetenvDuration("DOCTOR_TIMEOUT", doctor.DefaultTimeout))
+
+	cfg, err := loadConfig(ctx)
+	d.Check("configuration", func(ctx context.Context) (string, error) {
+		if err != nil {
+			return "", err
+		}
+		return "valid", nil
+	})
+
+	d.Check("data files", func(ctx context.Context) (string, error) {
+		dataDir := app.Getenv("DATA_DIR", "/data")
+		if _, err := os.Stat(dataDir); err != nil {
+			return "", err
+		}
+		products, err := filepath.Glob(filepath.Join(dataDir, "products", "*.csv"))
+		if err != nil {
+			return "", err
+		}
+		coupons, err := filepath.Glob(filepath.Join(dataDir, "*.txt"))
+		if err != nil {
+			return "", err
+		}
+		if len(products) == 0 && len(coupons) == 0 {
+			return "", fmt.Errorf("no product or coupon files in %s", dataDir)
+		}
+		return fmt.Sprintf("%d product files, %d coupon files", len(products), len(coupons)), nil
+	})
+
+	if err == nil {
+		db, err := sql.Open("postgres", cfg.sqlConnStr)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		d.Check("database connection", doctor.Connectivity(db))
+		d.CheckAfter("database connection", "schema version", doctor.MinSchemaVersion(db, requiredSchemaVersion))
+		d.CheckAfter("database connection", "product tables", doctor.Tables(db, "SELECT,INSERT,UPDATE", "products", "product_prices_currency", "product_translations"))
+		d.CheckAfter("database connection", "coupon table", doctor.Tables(db, "SELECT,INSERT", "coupons"))
+		d.CheckAfter("database connection", "cache invalidation outbox", doctor.Tables(db, "INSERT", "cache_invalidations"))
+		d.CheckAfter("database connection", "coupon uploads", doctor.Tables(db, "SELECT,UPDATE", "coupon_file_uploads"))
+		d.CheckAfter("database connection", "pipeline runs", doctor.Tables(db, "INSERT,UPDATE", "pipeline_runs"))
+	}
+
+	return d.Run(ctx, os.Stdout)
+}
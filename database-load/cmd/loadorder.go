@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
+)
+
+// defaultLoadOrderFile names the load order manifest in DATA_DIR
+const defaultLoadOrderFile = "load-order"
+
+// queuedFile is a coupon file waiting for a loader
+type queuedFile struct {
+	path string
+	// rank is the line of the first manifest pattern matching the file,
+	// or the number of patterns when none does
+	rank int
+	size int64
+}
+
+// orderCouponFiles sorts files into the order they should be loaded in.
+// Files are ranked by the first pattern of the load order manifest that
+// matches their name, and files matching no pattern come last; within a
+// rank smaller files come first, so campaign files are available long
+// before a huge base file. The manifest is LOAD_ORDER_FILE, by default
+// load-order in dataDir, and is optional.
+func orderCouponFiles(dataDir string, files []string) ([]string, error) {
+	path := app.Getenv("LOAD_ORDER_FILE", filepath.Join(dataDir, defaultLoadOrderFile))
+	patterns, err := readLoadOrder(path)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := make([]queuedFile, len(files))
+	for i, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", file, err)
+		}
+		queue[i] = queuedFile{path: file, rank: len(patterns), size: info.Size()}
+		for rank, pattern := range patterns {
+			if matched, _ := filepath.Match(pattern, filepath.Base(file)); matched {
+				queue[i].rank = rank
+				break
+			}
+		}
+	}
+
+	slices.SortStableFunc(queue, func(a, b queuedFile) int {
+		if a.rank != b.rank {
+			return a.rank - b.rank
+		}
+		switch {
+		case a.size < b.size:
+			return -1
+		case a.size > b.size:
+			return 1
+		}
+		return strings.Compare(a.path, b.path)
+	})
+
+	ordered := make([]string, len(queue))
+	for i, file := range queue {
+		ordered[i] = file.path
+	}
+	return ordered, nil
+}
+
+// readLoadOrder reads the file name patterns of a load order manifest, one
+// per line, highest priority first. Blank lines and lines starting with #
+// are skipped. A missing manifest has no patterns.
+func readLoadOrder(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open load order manifest: %w", err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		pattern := strings.TrimSpace(scanner.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q on line %d of %s: %w", pattern, line, path, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read load order manifest: %w", err)
+	}
+	log.Printf("Using load order manifest %s with %d patterns", path, len(patterns))
+	return patterns, nil
+}
@@ -5,10 +5,14 @@ import (
 	"context"
 	"database/sql"
 	"encoding/csv"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,6 +21,9 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	_ "github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/operation"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/promocode"
 )
 
 const (
@@ -24,83 +31,224 @@ const (
 	maxConcurrency = 8     // Increased concurrency for parallel processing
 )
 
+// couponsTable is the live coupons table
+var couponsTable = pgx.Identifier{"coupons"}
+
+// config holds the connection settings read from the environment
+type config struct {
+	// sqlConnStr is used for products through database/sql, pgxConnStr for
+	// coupons with CopyFrom
+	sqlConnStr string
+	pgxConnStr string
+	// transactionPooling is set when connecting through a transaction
+	// pooler, where no session state can be relied on
+	transactionPooling bool
+	dataDir            string
+	// stagedCoupons loads coupons into a per-run staging schema and swaps
+	// the loaded table in, instead of loading into the live table
+	stagedCoupons bool
+}
+
 func main() {
-	log.Println("Starting database load service...")
+	a := app.New("database-load")
 
-	ctx := context.Background()
+	// The command, if any, comes before the profiling flags
+	command, args := "", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	profile, err := parseProfileFlags(args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("Invalid arguments: %v", err)
+	}
+	if err := startProfiling(a, profile); err != nil {
+		log.Fatalf("Failed to start profiling: %v", err)
+	}
 
-	// Get database connection string from environment
-	dbHost := getEnv("DB_HOST", "postgres")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbUser := getEnv("DB_USER", "postgres")
-	dbPassword := getEnv("DB_PASSWORD", "postgres")
-	dbName := getEnv("DB_NAME", "orderfood")
+	// Run does not return
+	switch command {
+	case "doctor":
+		a.Run(runDoctor)
+	case "process-uploads":
+		a.Run(runProcessUploads)
+	}
+	a.Run(run)
+}
 
-	// Connection string for sql.DB (used for products - keeping backward compatibility)
-	sqlConnStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbHost, dbPort, dbUser, dbPassword, dbName)
+// run loads products and coupons from DATA_DIR
+func run(ctx context.Context) error {
+	log.Println("Starting database load service...")
 
-	// Connection string for pgx (used for coupons with CopyFrom)
-	pgxConnStr := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		dbUser, dbPassword, dbHost, dbPort, dbName)
+	// The operation ID ties this run to the rest of the pipeline; it is
+	// the trace ID of the run's span
+	ctx, op, span := app.StartOperation(ctx, "database-load")
+	defer span.End()
+	log.Printf("Operation %s (traceparent %s)", op.ID, op.Traceparent())
+
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if cfg.transactionPooling {
+		log.Println("Running in transaction pool mode: prepared statement caching and session settings are disabled")
+	}
 
 	// Connect to database using sql.DB for products
-	db, err := sql.Open("postgres", sqlConnStr)
+	db, err := sql.Open("postgres", cfg.sqlConnStr)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer db.Close()
 
 	// Test connection
 	if err := db.PingContext(ctx); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+		return fmt.Errorf("failed to ping database: %w", err)
 	}
 	log.Println("Successfully connected to database")
 
-	// Load data
-	dataDir := getEnv("DATA_DIR", "/data")
+	pipelineRun := operation.NewRun(db, op, "database-load")
+	if err := pipelineRun.Begin(ctx); err != nil {
+		log.Printf("Warning: Failed to record pipeline run: %v", err)
+	}
+
+	productCount, couponCount, err := load(ctx, db, cfg)
+	summary := fmt.Sprintf("%d products, %d coupons", productCount, couponCount)
+	if err := pipelineRun.Finish(ctx, summary, err); err != nil {
+		log.Printf("Warning: Failed to record pipeline run result: %v", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Println("Database load completed successfully")
+	return nil
+}
 
+// load loads products and then coupons and returns how many of each were loaded
+func load(ctx context.Context, db *sql.DB, cfg config) (int, int64, error) {
 	// Load products first
-	if err := loadProducts(ctx, db, filepath.Join(dataDir, "products")); err != nil {
-		log.Fatalf("Failed to load products: %v", err)
+	productCount, err := loadProducts(ctx, db, filepath.Join(cfg.dataDir, "products"))
+
+	// Tell running order-food replicas to drop their cached products, even
+	// after a partial load
+	if productCount > 0 {
+		if err := publishProductInvalidation(ctx, db); err != nil {
+			log.Printf("Warning: Failed to publish product cache invalidation: %v", err)
+		}
+	}
+	if err != nil {
+		return productCount, 0, fmt.Errorf("failed to load products: %w", err)
 	}
 
-	// Load coupons using pgx CopyFrom
-	if err := loadCouponsWithPgx(ctx, pgxConnStr, dataDir); err != nil {
-		log.Fatalf("Failed to load coupons: %v", err)
+	// Load coupons using pgx CopyFrom, straight into the live table or
+	// into a staging copy swapped in once complete
+	var couponCount int64
+	if cfg.stagedCoupons {
+		couponCount, err = loadCouponsStaged(ctx, db, cfg)
+	} else {
+		couponCount, err = loadCouponsWithPgx(ctx, cfg.pgxConnStr, cfg.dataDir, couponsTable, cfg.transactionPooling)
+	}
+	if err != nil {
+		return productCount, couponCount, fmt.Errorf("failed to load coupons: %w", err)
 	}
 
-	// Convert coupons table to LOGGED for crash safety
-	if err := convertToLoggedTable(ctx, pgxConnStr); err != nil {
-		log.Printf("Warning: Failed to convert table to LOGGED: %v", err)
+	// Convert coupons table to LOGGED for crash safety; a staged table is
+	// converted before it is swapped in
+	if !cfg.stagedCoupons {
+		if err := convertToLoggedTable(ctx, cfg.pgxConnStr); err != nil {
+			log.Printf("Warning: Failed to convert table to LOGGED: %v", err)
+		}
 	}
 
-	log.Println("Database load completed successfully")
+	// Let order-food replicas see the new codes in their coupon filters
+	refreshCouponFilter(ctx, db)
+
+	return productCount, couponCount, nil
 }
 
-func loadProducts(ctx context.Context, db *sql.DB, productsDir string) error {
+// loadConfig reads the connection settings from the environment. The
+// credentials are resolved once, so rotated credentials apply from the next
+// run on.
+func loadConfig(ctx context.Context) (config, error) {
+	dbHost := app.Getenv("DB_HOST", "postgres")
+	dbPort := app.Getenv("DB_PORT", "5432")
+	provider, err := app.NewCredentialProvider(ctx, dbHost, dbPort)
+	if err != nil {
+		return config{}, err
+	}
+	creds, err := provider.Credentials(ctx)
+	if err != nil {
+		return config{}, err
+	}
+	dbName := app.Getenv("DB_NAME", "orderfood")
+	// RDS IAM authentication only accepts TLS connections
+	sslMode := app.Getenv("DB_SSLMODE", "disable")
+
+	// Behind a transaction-pooling proxy such as pgbouncer consecutive
+	// statements may run on different backends, so no session state
+	// (prepared statements, SET) can be relied on
+	poolMode := app.Getenv("DB_POOL_MODE", "session")
+	if poolMode != "session" && poolMode != "transaction" {
+		return config{}, fmt.Errorf("invalid DB_POOL_MODE %q, expected \"session\" or \"transaction\"", poolMode)
+	}
+
+	loadMode := app.Getenv("COUPON_LOAD_MODE", "direct")
+	if loadMode != "direct" && loadMode != "staged" {
+		return config{}, fmt.Errorf("invalid COUPON_LOAD_MODE %q, expected \"direct\" or \"staged\"", loadMode)
+	}
+	if loadMode == "staged" && poolMode == "transaction" {
+		return config{}, errors.New("COUPON_LOAD_MODE=staged needs a session to hold its lock; connect to PostgreSQL or a session-mode pool")
+	}
+
+	cfg := config{
+		sqlConnStr: fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			dbHost, dbPort, creds.User, creds.Password, dbName, sslMode),
+		// Credentials are escaped since rotated passwords may contain URL
+		// metacharacters
+		pgxConnStr: fmt.Sprintf("postgres://%s@%s:%s/%s?sslmode=%s",
+			url.UserPassword(creds.User, creds.Password).String(), dbHost, dbPort, dbName, url.QueryEscape(sslMode)),
+		transactionPooling: poolMode == "transaction",
+		dataDir:            app.Getenv("DATA_DIR", "/data"),
+		stagedCoupons:      loadMode == "staged",
+	}
+
+	if cfg.transactionPooling {
+		// Use unnamed statements only; pgx otherwise caches named prepared
+		// statements, which fail with "prepared statement already exists"
+		// once the pooler hands the connection's backend to another client
+		cfg.sqlConnStr += " binary_parameters=yes"
+		cfg.pgxConnStr += "&default_query_exec_mode=exec"
+	}
+	return cfg, nil
+}
+
+func loadProducts(ctx context.Context, db *sql.DB, productsDir string) (int, error) {
 	log.Println("Loading products from CSV files...")
 
 	// Find all .csv files in the products directory
 	files, err := filepath.Glob(filepath.Join(productsDir, "*.csv"))
 	if err != nil {
-		return fmt.Errorf("failed to list product files: %w", err)
+		return 0, fmt.Errorf("failed to list product files: %w", err)
 	}
 
 	if len(files) == 0 {
 		log.Printf("No .csv files found in %s, skipping product load", productsDir)
-		return nil
+		return 0, nil
 	}
 
 	totalProducts := 0
+	categories := make(categoryIDs)
 
 	for _, filePath := range files {
 		fileName := filepath.Base(filePath)
 		log.Printf("Processing product file: %s", fileName)
 
-		count, err := loadProductsFromFile(ctx, db, filePath)
+		count, err := loadProductsFromFile(ctx, db, filePath, categories)
 		if err != nil {
-			return fmt.Errorf("failed to load products from %s: %w", fileName, err)
+			return totalProducts, fmt.Errorf("failed to load products from %s: %w", fileName, err)
 		}
 
 		totalProducts += count
@@ -108,10 +256,20 @@ func loadProducts(ctx context.Context, db *sql.DB, productsDir string) error {
 	}
 
 	log.Printf("✓ Total products loaded: %d", totalProducts)
-	return nil
+	return totalProducts, nil
 }
 
-func loadProductsFromFile(ctx context.Context, db *sql.DB, filePath string) (int, error) {
+// publishProductInvalidation records a cache invalidation event covering
+// every product in the outbox polled by order-food
+func publishProductInvalidation(ctx context.Context, db *sql.DB) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err := db.ExecContext(ctxTimeout, `INSERT INTO cache_invalidations (topic, cache_key) VALUES ('products', '*')`)
+	return err
+}
+
+func loadProductsFromFile(ctx context.Context, db *sql.DB, filePath string, categories categoryIDs) (int, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return 0, fmt.Errorf("failed to open file: %w", err)
@@ -119,12 +277,23 @@ func loadProductsFromFile(ctx context.Context, db *sql.DB, filePath string) (int
 	defer file.Close()
 
 	reader := csv.NewReader(file)
+	// Rows may leave out trailing optional columns
+	reader.FieldsPerRecord = -1
 
-	// Read header
-	_, err = reader.Read()
+	// Read header; columns are found by name, in any order
+	header, err := reader.Read()
 	if err != nil {
 		return 0, fmt.Errorf("failed to read CSV header: %w", err)
 	}
+	columns, err := mapProductColumns(header)
+	if err != nil {
+		return 0, err
+	}
+
+	// Optional per-currency price columns are named price_<ISO 4217 code>,
+	// localized columns name_<language> and description_<language>
+	currencyColumns := currencyPriceColumns(header)
+	translations := translationColumns(header)
 
 	// Read all records
 	records, err := reader.ReadAll()
@@ -134,69 +303,321 @@ func loadProductsFromFile(ctx context.Context, db *sql.DB, filePath string) (int
 
 	count := 0
 	for _, record := range records {
-		if len(record) < 4 {
+		id := optionalColumn(record, columns.id).String
+		name := optionalColumn(record, columns.name).String
+		priceStr := optionalColumn(record, columns.price).String
+		category := optionalColumn(record, columns.category).String
+		if id == "" || name == "" || priceStr == "" || category == "" {
 			log.Printf("Warning: Skipping invalid product record: %v", record)
 			continue
 		}
 
-		id := strings.TrimSpace(record[0])
-		name := strings.TrimSpace(record[1])
-		priceStr := strings.TrimSpace(record[2])
-		category := strings.TrimSpace(record[3])
-
 		price, err := strconv.ParseFloat(priceStr, 64)
 		if err != nil {
 			log.Printf("Warning: Invalid price '%s' for product '%s': %v", priceStr, name, err)
 			continue
 		}
 
-		// Insert product
-		query := `INSERT INTO products (id, name, price, category, created_at, updated_at)
-		          VALUES ($1, $2, $3, $4, NOW(), NOW())
+		sku := optionalColumn(record, columns.sku)
+		barcode := optionalColumn(record, columns.barcode)
+		description := optionalColumn(record, columns.description)
+
+		categoryID, err := categories.ensure(ctx, db, category)
+		if err != nil {
+			return count, err
+		}
+
+		// Insert product. A product deleted through the API is brought back
+		// while a product file still lists it.
+		query := `INSERT INTO products (id, name, price, category_id, sku, barcode, description, created_at, updated_at)
+		          VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
 		          ON CONFLICT (id) DO UPDATE
 		          SET name = EXCLUDED.name,
 		              price = EXCLUDED.price,
-		              category = EXCLUDED.category,
-		              updated_at = NOW()`
+		              category_id = EXCLUDED.category_id,
+		              sku = EXCLUDED.sku,
+		              barcode = EXCLUDED.barcode,
+		              description = EXCLUDED.description,
+		              updated_at = NOW(),
+		              deleted_at = NULL`
 
 		ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
-		_, err = db.ExecContext(ctxTimeout, query, id, name, price, category)
+		_, err = db.ExecContext(ctxTimeout, query, id, name, price, categoryID, sku, barcode, description)
 		cancel()
 
 		if err != nil {
 			return count, fmt.Errorf("failed to insert product '%s': %w", name, err)
 		}
 
+		if err := upsertCurrencyPrices(ctx, db, id, record, currencyColumns); err != nil {
+			return count, fmt.Errorf("failed to insert prices for product '%s': %w", name, err)
+		}
+
+		if err := upsertTranslations(ctx, db, id, record, translations); err != nil {
+			return count, fmt.Errorf("failed to insert translations for product '%s': %w", name, err)
+		}
+
 		count++
 	}
 
 	return count, nil
 }
 
+// currencyPriceColumns maps the index of every price_<CCY> header column to
+// its upper-cased currency code
+func currencyPriceColumns(header []string) map[int]string {
+	columns := make(map[int]string)
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if !strings.HasPrefix(strings.ToLower(name), "price_") {
+			continue
+		}
+		currency := strings.ToUpper(name[len("price_"):])
+		if len(currency) != 3 || strings.Trim(currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			log.Printf("Warning: Ignoring column '%s': not a valid ISO 4217 currency code", name)
+			continue
+		}
+		columns[i] = currency
+	}
+	return columns
+}
+
+// upsertCurrencyPrices stores the per-currency prices present on a product record
+func upsertCurrencyPrices(ctx context.Context, db *sql.DB, productID string, record []string, currencyColumns map[int]string) error {
+	query := `INSERT INTO product_prices_currency (product_id, currency, price, updated_at)
+	          VALUES ($1, $2, $3, NOW())
+	          ON CONFLICT (product_id, currency) DO UPDATE
+	          SET price = EXCLUDED.price,
+	              updated_at = NOW()`
+
+	for i, currency := range currencyColumns {
+		priceStr := optionalColumn(record, i)
+		if !priceStr.Valid {
+			continue
+		}
+
+		price, err := strconv.ParseFloat(priceStr.String, 64)
+		if err != nil || price < 0 {
+			log.Printf("Warning: Invalid %s price '%s' for product '%s'", currency, priceStr.String, productID)
+			continue
+		}
+
+		ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+		_, err = db.ExecContext(ctxTimeout, query, productID, currency, price)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to upsert %s price: %w", currency, err)
+		}
+	}
+
+	return nil
+}
+
+// headerIndex returns the index of the header column called name, ignoring
+// case, or -1 when there is none
+func headerIndex(header []string, name string) int {
+	for i, column := range header {
+		if strings.EqualFold(strings.TrimSpace(column), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// requiredProductColumns must be in every products CSV header
+var requiredProductColumns = []string{"id", "name", "price", "category"}
+
+// productColumns holds the indexes of the products CSV columns; -1 when an
+// optional column is missing
+type productColumns struct {
+	id, name, price, category int
+	sku, barcode, description int
+}
+
+// mapProductColumns finds the product columns in header by name, ignoring
+// case and order. It fails when a required column is missing or a column
+// is given twice. Columns it does not know are logged and ignored, so
+// exports can add columns before the loader reads them.
+func mapProductColumns(header []string) (productColumns, error) {
+	// Spreadsheet exports often start with a UTF-8 byte order mark
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	seen := make(map[string]bool, len(header))
+	for _, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if seen[column] {
+			return productColumns{}, fmt.Errorf("column '%s' appears more than once in the CSV header", column)
+		}
+		seen[column] = true
+
+		prefix, _, localized := strings.Cut(column, "_")
+		known := slices.Contains(requiredProductColumns, column) ||
+			column == "sku" || column == "barcode" || column == "description" ||
+			(localized && (prefix == "price" || prefix == "name" || prefix == "description"))
+		if !known {
+			log.Printf("Warning: Ignoring unknown column '%s'", column)
+		}
+	}
+
+	var missing []string
+	for _, column := range requiredProductColumns {
+		if !seen[column] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return productColumns{}, fmt.Errorf("CSV header is missing required columns: %s", strings.Join(missing, ", "))
+	}
+
+	return productColumns{
+		id:          headerIndex(header, "id"),
+		name:        headerIndex(header, "name"),
+		price:       headerIndex(header, "price"),
+		category:    headerIndex(header, "category"),
+		sku:         headerIndex(header, "sku"),
+		barcode:     headerIndex(header, "barcode"),
+		description: headerIndex(header, "description"),
+	}, nil
+}
+
+// translationColumn holds the indexes of the localized columns of one
+// language; -1 when the column is missing
+type translationColumn struct {
+	name, description int
+}
+
+// translationColumns maps the lower-cased language tag of every
+// name_<language> and description_<language> header column to the indexes
+// of its columns
+func translationColumns(header []string) map[string]translationColumn {
+	columns := make(map[string]translationColumn)
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		prefix, language, found := strings.Cut(column, "_")
+		if !found || (prefix != "name" && prefix != "description") {
+			continue
+		}
+		language = strings.ReplaceAll(language, "_", "-")
+		if !validLanguageTag(language) {
+			log.Printf("Warning: Ignoring column '%s': not a valid language tag", column)
+			continue
+		}
+		tc, ok := columns[language]
+		if !ok {
+			tc = translationColumn{name: -1, description: -1}
+		}
+		if prefix == "name" {
+			tc.name = i
+		} else {
+			tc.description = i
+		}
+		columns[language] = tc
+	}
+	return columns
+}
+
+// validLanguageTag reports whether tag is a lower-case language tag such
+// as fr or pt-br, the form product_translations accepts
+func validLanguageTag(tag string) bool {
+	for i, part := range strings.Split(tag, "-") {
+		if strings.Trim(part, "abcdefghijklmnopqrstuvwxyz0123456789") != "" || len(part) == 0 || len(part) > 8 {
+			return false
+		}
+		if i == 0 && (len(part) < 2 || len(part) > 3 || strings.Trim(part, "abcdefghijklmnopqrstuvwxyz") != "") {
+			return false
+		}
+	}
+	return true
+}
+
+// upsertTranslations stores the localized names and descriptions present
+// on a product record. A translation needs a name; its description falls
+// back to the default one when empty.
+func upsertTranslations(ctx context.Context, db *sql.DB, productID string, record []string, columns map[string]translationColumn) error {
+	query := `INSERT INTO product_translations (product_id, language, name, description, updated_at)
+	          VALUES ($1, $2, $3, $4, NOW())
+	          ON CONFLICT (product_id, language) DO UPDATE
+	          SET name = EXCLUDED.name,
+	              description = EXCLUDED.description,
+	              updated_at = NOW()`
+
+	for language, tc := range columns {
+		name := optionalColumn(record, tc.name)
+		description := optionalColumn(record, tc.description)
+		if !name.Valid {
+			if description.Valid {
+				log.Printf("Warning: Ignoring %s description for product '%s': it has no %s name", language, productID, language)
+			}
+			continue
+		}
+
+		ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+		_, err := db.ExecContext(ctxTimeout, query, productID, language, name, description)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to upsert %s translation: %w", language, err)
+		}
+	}
+
+	return nil
+}
+
+// optionalColumn returns the trimmed value at index i, or NULL when the column
+// is missing or empty
+func optionalColumn(record []string, i int) sql.NullString {
+	if i < 0 || i >= len(record) {
+		return sql.NullString{}
+	}
+	value := strings.TrimSpace(record[i])
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// baseNames returns the file names of paths
+func baseNames(paths []string) []string {
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+	}
+	return names
+}
+
 // Coupon represents a coupon record for batch processing
 type Coupon struct {
 	Code     string
 	FileName string
 }
 
-func loadCouponsWithPgx(ctx context.Context, connStr, dataDir string) error {
+// loadCouponsWithPgx bulk loads every coupon file in dataDir into table and
+// returns how many coupons were inserted. Session tuning is skipped under
+// transaction pooling, where SET would leak onto backends shared with other
+// clients.
+func loadCouponsWithPgx(ctx context.Context, connStr, dataDir string, table pgx.Identifier, transactionPooling bool) (int64, error) {
 	log.Println("Loading coupons from text files using pgx CopyFrom...")
 
 	// Find all .txt files in the data directory
 	files, err := filepath.Glob(filepath.Join(dataDir, "*.txt"))
 	if err != nil {
-		return fmt.Errorf("failed to list files: %w", err)
+		return 0, fmt.Errorf("failed to list files: %w", err)
 	}
 
 	if len(files) == 0 {
 		log.Printf("No .txt files found in %s, skipping coupon load", dataDir)
-		return nil
+		return 0, nil
 	}
 
-	log.Printf("Found %d files to process", len(files))
+	// Files are started in priority order as loaders free up
+	files, err = orderCouponFiles(dataDir, files)
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("Found %d files to process, in order: %s", len(files), strings.Join(baseNames(files), ", "))
 
 	// Optimize PostgreSQL for bulk loading
-	if err := optimizePostgresForBulkLoad(ctx, connStr); err != nil {
+	if transactionPooling {
+		log.Println("Skipping bulk load session settings in transaction pool mode")
+	} else if err := optimizePostgresForBulkLoad(ctx, connStr); err != nil {
 		log.Printf("Warning: Failed to optimize PostgreSQL settings: %v", err)
 	}
 
@@ -218,7 +639,7 @@ func loadCouponsWithPgx(ctx context.Context, connStr, dataDir string) error {
 			fileName := filepath.Base(fp)
 			log.Printf("Processing file: %s", fileName)
 
-			count, err := loadCouponsFromFileWithPgx(ctx, connStr, fp, fileName)
+			count, err := loadCouponsFromFileWithPgx(ctx, connStr, fp, fileName, table)
 			if err != nil {
 				errChan <- fmt.Errorf("failed to load coupons from %s: %w", fileName, err)
 				return
@@ -235,14 +656,14 @@ func loadCouponsWithPgx(ctx context.Context, connStr, dataDir string) error {
 
 	// Check for errors
 	if len(errChan) > 0 {
-		return <-errChan
+		return totalCoupons.Load(), <-errChan
 	}
 
 	log.Printf("✓ Total coupons loaded: %d", totalCoupons.Load())
-	return nil
+	return totalCoupons.Load(), nil
 }
 
-func loadCouponsFromFileWithPgx(ctx context.Context, connStr, filePath, fileName string) (int, error) {
+func loadCouponsFromFileWithPgx(ctx context.Context, connStr, filePath, fileName string, table pgx.Identifier) (int, error) {
 	// Connect to database using pgx
 	conn, err := pgx.Connect(ctx, connStr)
 	if err != nil {
@@ -265,7 +686,8 @@ func loadCouponsFromFileWithPgx(ctx context.Context, connStr, filePath, fileName
 	totalCount := 0
 
 	for scanner.Scan() {
-		coupon := strings.TrimSpace(scanner.Text())
+		// Codes are stored in upper case; order-food looks them up that way
+		coupon := promocode.Normalize(scanner.Text())
 		if coupon == "" {
 			continue // Skip empty lines
 		}
@@ -277,7 +699,7 @@ func loadCouponsFromFileWithPgx(ctx context.Context, connStr, filePath, fileName
 
 		// Insert batch when it reaches batchSize
 		if len(batch) >= batchSize {
-			count, err := insertCouponsBatchWithCopyFrom(ctx, conn, batch)
+			count, err := insertCouponsBatchWithCopyFrom(ctx, conn, table, batch)
 			if err != nil {
 				return totalCount, fmt.Errorf("failed to insert batch: %w", err)
 			}
@@ -293,7 +715,7 @@ func loadCouponsFromFileWithPgx(ctx context.Context, connStr, filePath, fileName
 
 	// Insert remaining coupons
 	if len(batch) > 0 {
-		count, err := insertCouponsBatchWithCopyFrom(ctx, conn, batch)
+		count, err := insertCouponsBatchWithCopyFrom(ctx, conn, table, batch)
 		if err != nil {
 			return totalCount, fmt.Errorf("failed to insert final batch: %w", err)
 		}
@@ -307,12 +729,12 @@ func loadCouponsFromFileWithPgx(ctx context.Context, connStr, filePath, fileName
 	return totalCount, nil
 }
 
-func insertCouponsBatchWithCopyFrom(ctx context.Context, conn *pgx.Conn, coupons []Coupon) (int, error) {
+func insertCouponsBatchWithCopyFrom(ctx context.Context, conn *pgx.Conn, table pgx.Identifier, coupons []Coupon) (int, error) {
 	if len(coupons) == 0 {
 		return 0, nil
 	}
 
-	// Use CopyFrom directly to the coupons table for maximum performance
+	// Use CopyFrom directly to the table for maximum performance
 	// This is much faster than using a temp table
 	rows := make([][]interface{}, len(coupons))
 	for i, c := range coupons {
@@ -321,7 +743,7 @@ func insertCouponsBatchWithCopyFrom(ctx context.Context, conn *pgx.Conn, coupons
 
 	copyCount, err := conn.CopyFrom(
 		ctx,
-		pgx.Identifier{"coupons"},
+		table,
 		[]string{"coupon", "file_name"},
 		pgx.CopyFromRows(rows),
 	)
@@ -346,12 +768,12 @@ func optimizePostgresForBulkLoad(ctx context.Context, connStr string) error {
 	defer conn.Close(ctx)
 
 	optimizations := []string{
-		"SET synchronous_commit = OFF",           // Faster commits, acceptable for bulk load
-		"SET maintenance_work_mem = '1GB'",       // More memory for index maintenance
-		"SET checkpoint_timeout = '30min'",       // Less frequent checkpoints
-		"SET max_wal_size = '4GB'",               // Allow more WAL before checkpoint
-		"SET wal_buffers = '16MB'",               // Larger WAL buffers
-		"SET effective_cache_size = '2GB'",       // Hint about available cache
+		"SET synchronous_commit = OFF",     // Faster commits, acceptable for bulk load
+		"SET maintenance_work_mem = '1GB'", // More memory for index maintenance
+		"SET checkpoint_timeout = '30min'", // Less frequent checkpoints
+		"SET max_wal_size = '4GB'",         // Allow more WAL before checkpoint
+		"SET wal_buffers = '16MB'",         // Larger WAL buffers
+		"SET effective_cache_size = '2GB'", // Hint about available cache
 	}
 
 	for _, sql := range optimizations {
@@ -382,10 +804,3 @@ func convertToLoggedTable(ctx context.Context, connStr string) error {
 	log.Println("✓ Coupons table converted to LOGGED (crash-safe)")
 	return nil
 }
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLoadProductsFromFile_RestoresDeletedProducts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	path := filepath.Join(t.TempDir(), "products.csv")
+	if err := os.WriteFile(path, []byte("id,name,price,category\n1,Waffle with Berries,6.5,Waffle\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectExec("INSERT INTO categories").
+		WithArgs("waffle", "Waffle").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT id FROM categories WHERE name = \\$1").
+		WithArgs("Waffle").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("waffle"))
+	// A product deleted through the API comes back while the file lists it
+	mock.ExpectExec("INSERT INTO products .+ ON CONFLICT \\(id\\) DO UPDATE .+ deleted_at = NULL").
+		WithArgs("1", "Waffle with Berries", 6.5, "waffle", nil, nil, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	count, err := loadProductsFromFile(context.Background(), db, path, make(categoryIDs))
+	if err != nil {
+		t.Fatalf("loadProductsFromFile: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("count = %d, want 1", count)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"runtime/trace"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
+)
+
+// profileConfig says which profiles of a run to record
+type profileConfig struct {
+	// cpuProfile, memProfile and trace are files written when set
+	cpuProfile string
+	memProfile string
+	trace      string
+	// pprofAddr is where to serve net/http/pprof while the loader runs
+	pprofAddr string
+}
+
+// parseProfileFlags reads the profiling flags from args; PPROF_ADDR
+// provides the default for --pprof-addr
+func parseProfileFlags(args []string) (profileConfig, error) {
+	var cfg profileConfig
+	flags := flag.NewFlagSet("database-load", flag.ContinueOnError)
+	flags.StringVar(&cfg.cpuProfile, "cpuprofile", "", "write a CPU profile of the run to this file")
+	flags.StringVar(&cfg.memProfile, "memprofile", "", "write an allocation profile to this file when the run ends")
+	flags.StringVar(&cfg.trace, "trace", "", "write an execution trace of the run to this file")
+	flags.StringVar(&cfg.pprofAddr, "pprof-addr", app.Getenv("PPROF_ADDR", ""), "serve net/http/pprof on this address, e.g. localhost:6060")
+	if err := flags.Parse(args); err != nil {
+		return profileConfig{}, err
+	}
+	if flags.NArg() > 0 {
+		return profileConfig{}, fmt.Errorf("unexpected arguments %q", flags.Args())
+	}
+	return cfg, nil
+}
+
+// startProfiling starts the profiles cfg asks for. They are stopped and
+// written by shutdown hooks of a, so they cover the whole run whether it
+// succeeds or fails.
+func startProfiling(a *app.App, cfg profileConfig) error {
+	if cfg.pprofAddr != "" {
+		if err := servePprof(a, cfg.pprofAddr); err != nil {
+			return err
+		}
+	}
+
+	if cfg.cpuProfile != "" {
+		f, err := os.Create(cfg.cpuProfile)
+		if err != nil {
+			return fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := runtimepprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		log.Printf("Writing CPU profile to %s", cfg.cpuProfile)
+		a.OnShutdown("CPU profile", func(context.Context) error {
+			runtimepprof.StopCPUProfile()
+			return f.Close()
+		})
+	}
+
+	if cfg.trace != "" {
+		f, err := os.Create(cfg.trace)
+		if err != nil {
+			return fmt.Errorf("failed to create trace: %w", err)
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to start trace: %w", err)
+		}
+		log.Printf("Writing execution trace to %s", cfg.trace)
+		a.OnShutdown("execution trace", func(context.Context) error {
+			trace.Stop()
+			return f.Close()
+		})
+	}
+
+	if cfg.memProfile != "" {
+		// Created up front so a bad path fails before hours of loading
+		f, err := os.Create(cfg.memProfile)
+		if err != nil {
+			return fmt.Errorf("failed to create memory profile: %w", err)
+		}
+		log.Printf("Writing allocation profile to %s", cfg.memProfile)
+		a.OnShutdown("memory profile", func(context.Context) error {
+			runtime.GC()
+			if err := runtimepprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+				f.Close()
+				return err
+			}
+			return f.Close()
+		})
+	}
+	return nil
+}
+
+// servePprof serves the net/http/pprof handlers on addr until shutdown
+func servePprof(a *app.App, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for pprof on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Warning: pprof server failed: %v", err)
+		}
+	}()
+	log.Printf("Serving pprof on http://%s/debug/pprof/", listener.Addr())
+	a.OnShutdown("pprof server", server.Shutdown)
+	return nil
+}
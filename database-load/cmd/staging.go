@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
+)
+
+// couponsLockKey names the advisory lock held by staged loads for their
+// whole run and by process-uploads around each file, so uploaded coupons
+// are not written to a table that is about to be swapped out
+const couponsLockKey = "database-load:coupons"
+
+// defaultSwapLockTimeout bounds how long the swap waits for readers of the
+// live coupons table before giving up
+const defaultSwapLockTimeout = 30 * time.Second
+
+// dependentView is a view or materialized view reading the coupons table.
+// Moving the table out of the schema would leave it reading the old table,
+// so it is dropped and recreated over the swapped in table.
+type dependentView struct {
+	name         string
+	materialized bool
+	definition   string
+	indexes      []string
+	comment      sql.NullString
+}
+
+// lockCoupons takes the coupons advisory lock on a dedicated connection and
+// returns the function releasing it. The lock belongs to the session, which
+// a transaction pool hands to other clients between transactions, so it is
+// refused in transaction pool mode rather than taken on a session it does
+// not own.
+func lockCoupons(ctx context.Context, db *sql.DB, cfg config) (func(), error) {
+	if cfg.transactionPooling {
+		return nil, errors.New("the coupons lock needs a session; it cannot be taken in transaction pool mode")
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for coupons lock: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", couponsLockKey); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to take coupons lock: %w", err)
+	}
+	return func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock(hashtext($1))", couponsLockKey); err != nil {
+			log.Printf("Warning: Failed to release coupons lock: %v", err)
+		}
+		conn.Close()
+	}, nil
+}
+
+// loadCouponsStaged loads the coupon files into a copy of the coupons table
+// in a schema of its own and swaps the copy in once it is complete, so
+// readers see either the previous coupons or all of the new ones. Coupons
+// of files not in this run are carried over into the copy. Nothing in the
+// live table changes when the load fails.
+func loadCouponsStaged(ctx context.Context, db *sql.DB, cfg config) (int64, error) {
+	files, err := filepath.Glob(filepath.Join(cfg.dataDir, "*.txt"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list files: %w", err)
+	}
+	if len(files) == 0 {
+		log.Printf("No .txt files found in %s, skipping coupon load", cfg.dataDir)
+		return 0, nil
+	}
+	fileNames := baseNames(files)
+
+	unlock, err := lockCoupons(ctx, db, cfg)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	var live string
+	if err := db.QueryRowContext(ctx, "SELECT current_schema()").Scan(&live); err != nil {
+		return 0, fmt.Errorf("failed to get current schema: %w", err)
+	}
+	run := time.Now().UTC().Format("20060102150405")
+	stage := "coupons_load_" + run
+	old := "coupons_old_" + run
+	log.Printf("Loading coupons into staging schema %s...", stage)
+
+	// Only the staging schema needs cleaning up until the swap commits
+	cleanup := func() {
+		if _, err := db.ExecContext(context.WithoutCancel(ctx), "DROP SCHEMA IF EXISTS "+pq.QuoteIdentifier(stage)+" CASCADE"); err != nil {
+			log.Printf("Warning: Failed to drop staging schema %s: %v", stage, err)
+		}
+	}
+
+	if err := createStagingTable(ctx, db, live, stage, fileNames); err != nil {
+		cleanup()
+		return 0, err
+	}
+
+	count, err := loadCouponsWithPgx(ctx, cfg.pgxConnStr, cfg.dataDir, pgx.Identifier{stage, "coupons"}, cfg.transactionPooling)
+	if err != nil {
+		cleanup()
+		return count, err
+	}
+
+	if err := finishStagingTable(ctx, db, live, stage); err != nil {
+		cleanup()
+		return count, err
+	}
+
+	if err := swapCouponsTable(ctx, db, live, stage, old); err != nil {
+		cleanup()
+		return count, err
+	}
+	log.Printf("✓ Swapped staged coupons table into %s", live)
+
+	// The previous table is no longer read by anything
+	for _, schema := range []string{stage, old} {
+		if _, err := db.ExecContext(ctx, "DROP SCHEMA IF EXISTS "+pq.QuoteIdentifier(schema)+" CASCADE"); err != nil {
+			log.Printf("Warning: Failed to drop schema %s: %v", schema, err)
+		}
+	}
+	return count, nil
+}
+
+// createStagingTable creates stage.coupons shaped like the live table,
+// without its indexes so the copy is not slowed down by them, and carries
+// over the coupons of files other than fileNames
+func createStagingTable(ctx context.Context, db *sql.DB, live, stage string, fileNames []string) error {
+	liveTable := pq.QuoteIdentifier(live) + ".coupons"
+	stageTable := pq.QuoteIdentifier(stage) + ".coupons"
+
+	statements := []string{
+		"CREATE SCHEMA " + pq.QuoteIdentifier(stage),
+		"CREATE UNLOGGED TABLE " + stageTable + " (LIKE " + liveTable + " INCLUDING ALL EXCLUDING INDEXES)",
+	}
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to create staging table: %w", err)
+		}
+	}
+
+	result, err := db.ExecContext(ctx,
+		"INSERT INTO "+stageTable+" SELECT * FROM "+liveTable+" WHERE file_name <> ALL($1)", pq.Array(fileNames))
+	if err != nil {
+		return fmt.Errorf("failed to carry over coupons: %w", err)
+	}
+	carried, _ := result.RowsAffected()
+	log.Printf("Carried over %d coupons of other files", carried)
+	return nil
+}
+
+// finishStagingTable makes the loaded stage.coupons crash-safe and gives
+// it the constraints and indexes of the live table
+func finishStagingTable(ctx context.Context, db *sql.DB, live, stage string) error {
+	stageTable := pq.QuoteIdentifier(stage) + ".coupons"
+
+	log.Println("Converting staged coupons table to LOGGED...")
+	if _, err := db.ExecContext(ctx, "ALTER TABLE "+stageTable+" SET LOGGED"); err != nil {
+		return fmt.Errorf("failed to convert staged table to logged: %w", err)
+	}
+
+	// Primary key and unique constraints come with their indexes; the
+	// other indexes are created from their definitions on the live table
+	rows, err := db.QueryContext(ctx, `
+		SELECT 'ALTER TABLE ' || quote_ident($2) || '.coupons ADD CONSTRAINT ' || quote_ident(con.conname) || ' ' || pg_get_constraintdef(con.oid)
+		FROM pg_constraint con
+		WHERE con.conrelid = to_regclass(quote_ident($1) || '.coupons') AND con.contype IN ('p', 'u', 'x')
+		UNION ALL
+		SELECT replace(pg_get_indexdef(i.indexrelid), ' ON ' || quote_ident($1) || '.coupons ', ' ON ' || quote_ident($2) || '.coupons ')
+		FROM pg_index i
+		WHERE i.indrelid = to_regclass(quote_ident($1) || '.coupons')
+			AND NOT EXISTS (SELECT 1 FROM pg_constraint con WHERE con.conindid = i.indexrelid)`,
+		live, stage)
+	if err != nil {
+		return fmt.Errorf("failed to read coupons indexes: %w", err)
+	}
+	var statements []string
+	for rows.Next() {
+		var statement string
+		if err := rows.Scan(&statement); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read coupons indexes: %w", err)
+		}
+		statements = append(statements, statement)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read coupons indexes: %w", err)
+	}
+
+	for _, statement := range append(statements, "ANALYZE "+stageTable) {
+		log.Printf("Running: %s", statement)
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to index staged table: %w", err)
+		}
+	}
+	return nil
+}
+
+// swapCouponsTable moves the live coupons table into old and the staged
+// one into live in one transaction, recreating the views reading coupons
+// over the new table. Materialized views are recreated empty; order-food
+// refreshes them after the coupon invalidation.
+func swapCouponsTable(ctx context.Context, db *sql.DB, live, stage, old string) error {
+	liveTable := pq.QuoteIdentifier(live) + ".coupons"
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin swap: %w", err)
+	}
+	defer tx.Rollback()
+
+	timeout := app.GetenvDuration("COUPON_SWAP_LOCK_TIMEOUT", defaultSwapLockTimeout)
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL lock_timeout = %d", timeout.Milliseconds())); err != nil {
+		return fmt.Errorf("failed to set lock timeout: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE "+liveTable+" IN ACCESS EXCLUSIVE MODE"); err != nil {
+		return fmt.Errorf("failed to lock coupons table: %w", err)
+	}
+
+	views, err := dependentViews(ctx, tx, liveTable)
+	if err != nil {
+		return err
+	}
+
+	var statements []string
+	for _, view := range views {
+		kind := "VIEW"
+		if view.materialized {
+			kind = "MATERIALIZED VIEW"
+		}
+		statements = append(statements, "DROP "+kind+" "+view.name)
+	}
+	statements = append(statements,
+		"CREATE SCHEMA "+pq.QuoteIdentifier(old),
+		"ALTER TABLE "+liveTable+" SET SCHEMA "+pq.QuoteIdentifier(old),
+		"ALTER TABLE "+pq.QuoteIdentifier(stage)+".coupons SET SCHEMA "+pq.QuoteIdentifier(live),
+	)
+	for _, view := range views {
+		if view.materialized {
+			statements = append(statements, "CREATE MATERIALIZED VIEW "+view.name+" AS "+view.definition+" WITH NO DATA")
+		} else {
+			statements = append(statements, "CREATE VIEW "+view.name+" AS "+view.definition)
+		}
+		statements = append(statements, view.indexes...)
+		if view.comment.Valid {
+			kind := "VIEW"
+			if view.materialized {
+				kind = "MATERIALIZED VIEW"
+			}
+			statements = append(statements, "COMMENT ON "+kind+" "+view.name+" IS "+pq.QuoteLiteral(view.comment.String))
+		}
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to swap coupons table (%s): %w", statement, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit swap: %w", err)
+	}
+	return nil
+}
+
+// dependentViews returns the views and materialized views reading table,
+// with what is needed to recreate them. Views depending on those views are
+// not handled; dropping their parent then fails and the swap is rolled
+// back.
+func dependentViews(ctx context.Context, tx *sql.Tx, table string) ([]dependentView, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT quote_ident(n.nspname) || '.' || quote_ident(v.relname), v.relkind = 'm',
+			pg_get_viewdef(v.oid), obj_description(v.oid, 'pg_class'), v.oid
+		FROM pg_depend d
+		JOIN pg_rewrite r ON r.oid = d.objid
+		JOIN pg_class v ON v.oid = r.ev_class
+		JOIN pg_namespace n ON n.oid = v.relnamespace
+		WHERE d.classid = 'pg_rewrite'::regclass AND d.refobjid = to_regclass($1) AND v.oid <> d.refobjid`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read views of coupons: %w", err)
+	}
+	var views []dependentView
+	var oids []int64
+	for rows.Next() {
+		var view dependentView
+		var oid int64
+		if err := rows.Scan(&view.name, &view.materialized, &view.definition, &view.comment, &oid); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read views of coupons: %w", err)
+		}
+		// pg_get_viewdef ends the query with a semicolon
+		view.definition = strings.TrimSuffix(strings.TrimSpace(view.definition), ";")
+		views = append(views, view)
+		oids = append(oids, oid)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read views of coupons: %w", err)
+	}
+
+	for i := range views {
+		if !views[i].materialized {
+			continue
+		}
+		indexes, err := tx.QueryContext(ctx, "SELECT pg_get_indexdef(indexrelid) FROM pg_index WHERE indrelid = $1", oids[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read indexes of %s: %w", views[i].name, err)
+		}
+		for indexes.Next() {
+			var index string
+			if err := indexes.Scan(&index); err != nil {
+				indexes.Close()
+				return nil, fmt.Errorf("failed to read indexes of %s: %w", views[i].name, err)
+			}
+			views[i].indexes = append(views[i].indexes, index)
+		}
+		indexes.Close()
+		if err := indexes.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read indexes of %s: %w", views[i].name, err)
+		}
+	}
+	return views, nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/operation"
+)
+
+// staleUploadAfter is how long an upload may stay in processing before it
+// is assumed to belong to a crashed run and is picked up again
+const staleUploadAfter = time.Hour
+
+// couponUpload is a coupon file uploaded through the order-food admin API
+type couponUpload struct {
+	id       string
+	fileName string
+	// operationID was started by the upload request; empty for uploads
+	// made before operations were recorded
+	operationID string
+}
+
+// runProcessUploads loads every pending coupon file upload from DATA_DIR
+// and records the outcome on the upload. Uploads are claimed one at a time
+// with SKIP LOCKED, so overlapping runs share the queue. Each load is
+// recorded as a pipeline run in the operation of its upload, so it can be
+// followed from the upload request.
+func runProcessUploads(ctx context.Context) error {
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open("postgres", cfg.sqlConnStr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	processed := 0
+	for {
+		upload, err := claimUpload(ctx, db)
+		if errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to claim coupon upload: %w", err)
+		}
+
+		op := operation.Continue(upload.operationID)
+		upload.operationID = op.ID
+		pipelineRun := operation.NewRun(db, op, "database-load process-uploads")
+		if err := pipelineRun.Begin(ctx); err != nil {
+			log.Printf("Warning: Failed to record pipeline run: %v", err)
+		}
+
+		log.Printf("Processing uploaded coupon file: %s (operation %s)", upload.fileName, op.ID)
+		count, loadErr := loadUpload(ctx, db, cfg, upload)
+		if err := finishUpload(ctx, db, upload, count, loadErr); err != nil {
+			return fmt.Errorf("failed to record coupon upload result: %w", err)
+		}
+		if err := pipelineRun.Finish(ctx, fmt.Sprintf("%d coupons from %s", count, upload.fileName), loadErr); err != nil {
+			log.Printf("Warning: Failed to record pipeline run result: %v", err)
+		}
+		if loadErr != nil {
+			log.Printf("Warning: Failed to load %s: %v", upload.fileName, loadErr)
+		} else {
+			log.Printf("✓ Loaded %d coupons from %s", count, upload.fileName)
+		}
+		processed++
+	}
+
+	log.Printf("✓ Processed %d uploaded coupon files", processed)
+	if processed > 0 {
+		refreshCouponFilter(ctx, db)
+	}
+	return nil
+}
+
+// loadUpload loads the coupons of upload into the live table. Outside
+// transaction pool mode it holds the coupons lock meanwhile, so a staged
+// load does not swap the table out from under it.
+func loadUpload(ctx context.Context, db *sql.DB, cfg config, upload couponUpload) (int, error) {
+	path := filepath.Join(cfg.dataDir, upload.fileName)
+	if cfg.transactionPooling {
+		return loadCouponsFromFileWithPgx(ctx, cfg.pgxConnStr, path, upload.fileName, couponsTable)
+	}
+
+	unlock, err := lockCoupons(ctx, db, cfg)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	return loadCouponsFromFileWithPgx(ctx, cfg.pgxConnStr, path, upload.fileName, couponsTable)
+}
+
+// claimUpload moves the oldest pending upload, or one abandoned in
+// processing, to processing and returns it. sql.ErrNoRows is returned when
+// there is nothing to do.
+func claimUpload(ctx context.Context, db *sql.DB) (couponUpload, error) {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE coupon_file_uploads
+	          SET status = 'processing', error = NULL, updated_at = NOW()
+	          WHERE id = (
+	              SELECT id FROM coupon_file_uploads
+	              WHERE status = 'pending'
+	                 OR (status = 'processing' AND updated_at < NOW() - make_interval(secs => $1))
+	              ORDER BY created_at
+	              LIMIT 1
+	              FOR UPDATE SKIP LOCKED
+	          )
+	          RETURNING id, file_name, COALESCE(operation_id, '')`
+	var upload couponUpload
+	err := db.QueryRowContext(ctxTimeout, query, staleUploadAfter.Seconds()).Scan(&upload.id, &upload.fileName, &upload.operationID)
+	return upload, err
+}
+
+// finishUpload records the result of loading an upload
+func finishUpload(ctx context.Context, db *sql.DB, upload couponUpload, count int, loadErr error) error {
+	ctxTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	status, message := "loaded", sql.NullString{}
+	if loadErr != nil {
+		status, message = "failed", sql.NullString{String: loadErr.Error(), Valid: true}
+	}
+
+	_, err := db.ExecContext(ctxTimeout,
+		`UPDATE coupon_file_uploads
+		 SET status = $2, coupons_loaded = $3, error = $4, operation_id = $5, updated_at = NOW()
+		 WHERE id = $1`,
+		upload.id, status, count, message, upload.operationID)
+	return err
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	_ "github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/database-migration/internal/migration"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/doctor"
+)
+
+// migrationsDir is where the image ships the migration files
+const migrationsDir = "migrations"
+
+// runDoctor implements the doctor command, which checks configuration,
+// database access and the migration files and prints a diagnosis
+func runDoctor(ctx context.Context) error {
+	d := doctor.New(app.GetenvDuration("DOCTOR_TIMEOUT", doctor.DefaultTimeout))
+
+	cfg, err := loadConfig(ctx)
+	d.Check("configuration", func(ctx context.Context) (string, error) {
+		if err != nil {
+			return "", err
+		}
+		if app.Getenv("DB_POOL_MODE", "session") == "transaction" {
+			return "", errors.New("DB_POOL_MODE=transaction; migrations need a session, so connect to PostgreSQL directly")
+		}
+		return "valid", nil
+	})
+
+	latest := 0
+	d.Check("migration files", func(ctx context.Context) (string, error) {
+		count, version, err := scanMigrations(migrationsDir)
+		if err != nil {
+			return "", err
+		}
+		latest = version
+		checks, err := migration.LoadChecks(migrationsDir)
+		if err != nil {
+			return "", fmt.Errorf("invalid post-migration checks: %w", err)
+		}
+		return fmt.Sprintf("%d migrations, latest version %d, %d post-migration checks", count, version, len(checks)), nil
+	})
+
+	if err == nil {
+		db, err := sql.Open("postgres", fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode))
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer db.Close()
+
+		d.Check("database connection", doctor.Connectivity(db))
+		d.CheckAfter("database connection", "privileges", func(ctx context.Context) (string, error) {
+			var canCreate bool
+			if err := db.QueryRowContext(ctx, `SELECT has_database_privilege(current_database(), 'CREATE')`).Scan(&canCreate); err != nil {
+				return "", err
+			}
+			if !canCreate {
+				return "", errors.New("user may not create objects in the database")
+			}
+			return "CREATE granted", nil
+		})
+		d.CheckAfter("database connection", "schema version", func(ctx context.Context) (string, error) {
+			version, dirty, ok, err := doctor.SchemaVersion(ctx, db)
+			switch {
+			case err != nil:
+				return "", err
+			case dirty:
+				return "", fmt.Errorf("version %d is dirty; fix the failed migration and force the version", version)
+			case !ok:
+				return fmt.Sprintf("empty database, %d migrations pending", latest), nil
+			case int(version) > latest:
+				return "", fmt.Errorf("database is at version %d, newer than the %d shipped with this image", version, latest)
+			}
+			return fmt.Sprintf("version %d, %d pending", version, latest-int(version)), nil
+		})
+	}
+
+	return d.Run(ctx, os.Stdout)
+}
+
+// scanMigrations counts the up migrations in dir and returns the highest
+// version
+func scanMigrations(dir string) (int, int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(files) == 0 {
+		return 0, 0, fmt.Errorf("no migrations found in %s", dir)
+	}
+
+	latest := 0
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(filepath.Base(file), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%s does not start with a version number", filepath.Base(file))
+		}
+		latest = max(latest, version)
+	}
+	return len(files), latest, nil
+}
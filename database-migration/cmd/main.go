@@ -2,48 +2,181 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/shyampundkar/kart-challenge-workspace/database-migration/internal/migration"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/operation"
 )
 
 func main() {
+	a := app.New("database-migration")
+
+	// Run does not return
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		a.Run(runDoctor)
+	}
+	a.Run(run)
+}
+
+// run applies all pending migrations
+func run(ctx context.Context) error {
 	log.Println("Starting database migration service...")
 
+	// The operation ID ties this run to the rest of the pipeline; it is
+	// the trace ID of the run's span
+	ctx, op, span := app.StartOperation(ctx, "database-migration")
+	defer span.End()
+	log.Printf("Operation %s (traceparent %s)", op.ID, op.Traceparent())
+
 	// Get database configuration from environment variables
-	dbConfig := migration.Config{
-		Host:     getEnv("DB_HOST", "localhost"),
-		Port:     getEnv("DB_PORT", "5432"),
-		User:     getEnv("DB_USER", "postgres"),
-		Password: getEnv("DB_PASSWORD", "postgres"),
-		DBName:   getEnv("DB_NAME", "orderfood"),
-		SSLMode:  getEnv("DB_SSLMODE", "disable"),
+	dbConfig, err := loadConfig(ctx)
+	if err != nil {
+		return err
+	}
+	waitConfig, err := loadWaitConfig(os.Args[1:])
+	if err != nil {
+		return err
+	}
+
+	// The migration lock is a session advisory lock, which a transaction
+	// pooler can release or hand to another client mid-run
+	if app.Getenv("DB_POOL_MODE", "session") == "transaction" {
+		log.Println("Warning: DB_POOL_MODE=transaction; migrations need a session, so point DB_HOST at PostgreSQL or a session-mode pool")
 	}
 
 	log.Printf("Connecting to database: %s@%s:%s/%s", dbConfig.User, dbConfig.Host, dbConfig.Port, dbConfig.DBName)
 
+	// PostgreSQL often starts alongside the job, so give it time to accept
+	// connections
+	if err := migration.WaitForDatabase(ctx, dbConfig, waitConfig); err != nil {
+		return err
+	}
+
 	// Create migrator
 	migrator, err := migration.NewMigrator(dbConfig)
 	if err != nil {
-		log.Fatalf("Failed to create migrator: %v", err)
+		return fmt.Errorf("failed to create migrator: %w", err)
 	}
 	defer migrator.Close()
 
-	// Run migrations
+	// Record the run; before the migration creating pipeline_runs has been
+	// applied it can only be recorded once migrating is done
+	pipelineRun := operation.NewRun(migrator.DB(), op, "database-migration")
+	if err := pipelineRun.Begin(ctx); err != nil {
+		log.Printf("Pipeline run will be recorded after migrating: %v", err)
+	}
+	from := schemaVersion(migrator)
+
+	// Post-conditions of the migrations about to be applied; row counts
+	// they compare against are taken first
+	checks, err := migration.LoadChecks(migrationsDir)
+	if err != nil {
+		return fmt.Errorf("invalid post-migration checks: %w", err)
+	}
+	checks = migration.Pending(checks, from)
+	rowCounts, err := migrator.CountRows(ctx, checks)
+	if err != nil {
+		return err
+	}
+
+	// Run migrations; a shutdown signal stops after the migration in progress
 	log.Println("Running database migrations...")
-	ctx := context.Background()
-	if err := migrator.Run(ctx); err != nil {
-		log.Fatalf("Migration failed: %v", err)
+	migrateErr := migrator.Run(ctx)
+	if migrateErr == nil && len(checks) > 0 {
+		migrateErr = verifyMigrations(ctx, migrator, checks, rowCounts, from)
+	}
+	summary := fmt.Sprintf("schema version %d to %d", from, schemaVersion(migrator))
+	if err := pipelineRun.Finish(ctx, summary, migrateErr); err != nil {
+		log.Printf("Warning: Failed to record pipeline run: %v", err)
+	}
+	if migrateErr != nil {
+		return fmt.Errorf("migration failed: %w", migrateErr)
 	}
 
 	log.Println("Database migration completed successfully")
+	return nil
 }
 
-// getEnv returns the value of an environment variable or a default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// verifyMigrations runs the post-migration checks and, when they fail and
+// MIGRATION_ROLLBACK_ON_CHECK_FAILURE is true, migrates back to version
+// from
+func verifyMigrations(ctx context.Context, migrator *migration.Migrator, checks []migration.Check, rowCounts migration.RowCounts, from uint) error {
+	log.Printf("Running %d post-migration checks...", len(checks))
+	err := migrator.Verify(ctx, checks, rowCounts)
+	if err == nil {
+		log.Println("✓ Post-migration checks passed")
+		return nil
+	}
+	if app.Getenv("MIGRATION_ROLLBACK_ON_CHECK_FAILURE", "false") != "true" {
+		return err
+	}
+
+	log.Printf("Post-migration checks failed, rolling back to version %d: %v", from, err)
+	if rollbackErr := migrator.Rollback(from); rollbackErr != nil {
+		return fmt.Errorf("%w; rollback to version %d failed: %v", err, from, rollbackErr)
+	}
+	log.Printf("Rolled back to version %d", from)
+	return err
+}
+
+// schemaVersion returns the applied schema version, 0 when none is
+func schemaVersion(m *migration.Migrator) uint {
+	version, _, err := m.Version()
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// loadConfig reads the database configuration from the environment. The
+// credentials are resolved once, so rotated credentials apply from the next
+// run on.
+func loadConfig(ctx context.Context) (migration.Config, error) {
+	host, port := app.Getenv("DB_HOST", "localhost"), app.Getenv("DB_PORT", "5432")
+	provider, err := app.NewCredentialProvider(ctx, host, port)
+	if err != nil {
+		return migration.Config{}, err
+	}
+	creds, err := provider.Credentials(ctx)
+	if err != nil {
+		return migration.Config{}, err
+	}
+	return migration.Config{
+		Host:     host,
+		Port:     port,
+		User:     creds.User,
+		Password: creds.Password,
+		DBName:   app.Getenv("DB_NAME", "orderfood"),
+		SSLMode:  app.Getenv("DB_SSLMODE", "disable"),
+	}, nil
+}
+
+// loadWaitConfig reads how long to wait for the database from
+// DB_WAIT_TIMEOUT, DB_WAIT_INITIAL_BACKOFF and DB_WAIT_MAX_BACKOFF; the
+// --wait-timeout flag in args overrides DB_WAIT_TIMEOUT
+func loadWaitConfig(args []string) (migration.WaitConfig, error) {
+	cfg := migration.WaitConfig{
+		Timeout:        app.GetenvDuration("DB_WAIT_TIMEOUT", time.Minute),
+		InitialBackoff: app.GetenvDuration("DB_WAIT_INITIAL_BACKOFF", 500*time.Millisecond),
+		MaxBackoff:     app.GetenvDuration("DB_WAIT_MAX_BACKOFF", 10*time.Second),
+	}
+
+	flags := flag.NewFlagSet("database-migration", flag.ContinueOnError)
+	flags.DurationVar(&cfg.Timeout, "wait-timeout", cfg.Timeout, "how long to wait for the database to accept connections; 0 tries once")
+	if err := flags.Parse(args); err != nil {
+		return migration.WaitConfig{}, err
+	}
+
+	switch {
+	case cfg.Timeout < 0:
+		return migration.WaitConfig{}, fmt.Errorf("wait timeout must not be negative, got %s", cfg.Timeout)
+	case cfg.InitialBackoff <= 0 || cfg.MaxBackoff < cfg.InitialBackoff:
+		return migration.WaitConfig{}, fmt.Errorf("DB_WAIT_INITIAL_BACKOFF must be positive and at most DB_WAIT_MAX_BACKOFF, got %s and %s", cfg.InitialBackoff, cfg.MaxBackoff)
 	}
-	return defaultValue
+	return cfg, nil
 }
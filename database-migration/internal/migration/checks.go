@@ -0,0 +1,256 @@
+package migration
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Kinds of post-migration checks
+const (
+	// CheckTable requires a table, view or materialized view: table <name>
+	CheckTable = "table"
+	// CheckColumn requires a column: column <table> <column>
+	CheckColumn = "column"
+	// CheckIndex requires a valid index on a table: index <table> <index>
+	CheckIndex = "index"
+	// CheckRows requires the row count of a table to stay within a
+	// percentage of its count before migrating: rows <table> within <n>%
+	CheckRows = "rows"
+	// CheckSQL requires a query to return true: sql <query>
+	CheckSQL = "sql"
+)
+
+// Check is a post-condition of a migration, declared in the
+// <version>_<name>.checks file next to it
+type Check struct {
+	Version uint
+	// Source is the file and line the check is declared on
+	Source string
+	Kind   string
+	Args   []string
+	// Tolerance is the fraction a CheckRows count may change by
+	Tolerance float64
+}
+
+func (c Check) String() string {
+	return c.Kind + " " + strings.Join(c.Args, " ")
+}
+
+// LoadChecks reads the checks declared in the .checks files of dir, in
+// version order. Blank lines and lines starting with # are ignored.
+func LoadChecks(dir string) ([]Check, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.checks"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var checks []Check
+	for _, file := range files {
+		prefix, _, _ := strings.Cut(filepath.Base(file), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s does not start with a version number", filepath.Base(file))
+		}
+		fileChecks, err := parseChecks(file, uint(version))
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, fileChecks...)
+	}
+	return checks, nil
+}
+
+// parseChecks parses the checks of one file
+func parseChecks(path string, version uint) ([]Check, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var checks []Check
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		source := fmt.Sprintf("%s:%d", filepath.Base(path), line)
+		check, err := parseCheck(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		check.Version = version
+		check.Source = source
+		checks = append(checks, check)
+	}
+	return checks, scanner.Err()
+}
+
+// parseCheck parses a check declaration
+func parseCheck(text string) (Check, error) {
+	kind, rest, _ := strings.Cut(text, " ")
+	rest = strings.TrimSpace(rest)
+	check := Check{Kind: kind, Args: strings.Fields(rest)}
+
+	switch kind {
+	case CheckTable:
+		if len(check.Args) != 1 {
+			return Check{}, errors.New("want table <name>")
+		}
+	case CheckColumn, CheckIndex:
+		if len(check.Args) != 2 {
+			return Check{}, fmt.Errorf("want %s <table> <%s>", kind, map[string]string{CheckColumn: "column", CheckIndex: "index"}[kind])
+		}
+	case CheckRows:
+		if len(check.Args) != 3 || check.Args[1] != "within" || !strings.HasSuffix(check.Args[2], "%") {
+			return Check{}, errors.New("want rows <table> within <n>%")
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(check.Args[2], "%"), 64)
+		if err != nil || percent < 0 {
+			return Check{}, fmt.Errorf("invalid percentage %q", check.Args[2])
+		}
+		check.Tolerance = percent / 100
+	case CheckSQL:
+		if rest == "" {
+			return Check{}, errors.New("want sql <query>")
+		}
+		check.Args = []string{rest}
+	default:
+		return Check{}, fmt.Errorf("unknown check %q, want table, column, index, rows or sql", kind)
+	}
+	return check, nil
+}
+
+// Pending returns the checks of the migrations after version
+func Pending(checks []Check, version uint) []Check {
+	var pending []Check
+	for _, c := range checks {
+		if c.Version > version {
+			pending = append(pending, c)
+		}
+	}
+	return pending
+}
+
+// RowCounts holds the row counts CheckRows checks compare against, by
+// table; tables that do not exist yet are left out
+type RowCounts map[string]int64
+
+// CountRows counts the rows of the tables checks compare row counts of
+func (m *Migrator) CountRows(ctx context.Context, checks []Check) (RowCounts, error) {
+	counts := make(RowCounts)
+	for _, c := range checks {
+		table := c.Args[0]
+		if _, done := counts[table]; c.Kind != CheckRows || done {
+			continue
+		}
+		var exists bool
+		if err := m.db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to look up table %s: %w", table, err)
+		}
+		if !exists {
+			continue
+		}
+		var count int64
+		if err := m.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+pq.QuoteIdentifier(table)).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count rows of %s: %w", table, err)
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
+
+// Verify runs checks against the migrated database, comparing row counts
+// with before, and returns an error listing every check that fails.
+// CheckRows checks of tables missing from before pass.
+func (m *Migrator) Verify(ctx context.Context, checks []Check, before RowCounts) error {
+	var failures []string
+	for _, c := range checks {
+		if err := m.verify(ctx, c, before); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s: %v", c.Source, c, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d post-migration checks failed:\n  %s", len(failures), strings.Join(failures, "\n  "))
+	}
+	return nil
+}
+
+// verify runs one check
+func (m *Migrator) verify(ctx context.Context, c Check, before RowCounts) error {
+	var ok bool
+	switch c.Kind {
+	case CheckTable:
+		if err := m.db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, c.Args[0]).Scan(&ok); err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("table does not exist")
+		}
+	case CheckColumn:
+		query := `SELECT EXISTS (
+		              SELECT 1 FROM information_schema.columns
+		              WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2)`
+		if err := m.db.QueryRowContext(ctx, query, c.Args[0], c.Args[1]).Scan(&ok); err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("column does not exist")
+		}
+	case CheckIndex:
+		// A failed CREATE INDEX CONCURRENTLY leaves an invalid index behind
+		query := `SELECT COALESCE(bool_and(i.indisvalid), false)
+		          FROM pg_index i
+		          JOIN pg_class ic ON ic.oid = i.indexrelid
+		          JOIN pg_class tc ON tc.oid = i.indrelid
+		          WHERE tc.relnamespace = current_schema()::regnamespace AND tc.relname = $1 AND ic.relname = $2`
+		if err := m.db.QueryRowContext(ctx, query, c.Args[0], c.Args[1]).Scan(&ok); err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("index does not exist or is invalid")
+		}
+	case CheckRows:
+		table := c.Args[0]
+		was, counted := before[table]
+		if !counted {
+			return nil
+		}
+		var count int64
+		if err := m.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+pq.QuoteIdentifier(table)).Scan(&count); err != nil {
+			return err
+		}
+		if math.Abs(float64(count-was)) > c.Tolerance*float64(was) {
+			return fmt.Errorf("%d rows, %d before migrating", count, was)
+		}
+	case CheckSQL:
+		if err := m.db.QueryRowContext(ctx, c.Args[0]).Scan(&ok); err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("query returned false")
+		}
+	}
+	return nil
+}
+
+// Rollback migrates back down to version, or removes every migration when
+// version is 0
+func (m *Migrator) Rollback(version uint) error {
+	if version == 0 {
+		return m.migrate.Down()
+	}
+	return m.migrate.Migrate(version)
+}
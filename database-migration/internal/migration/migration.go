@@ -32,13 +32,8 @@ type Migrator struct {
 
 // NewMigrator creates a new Migrator instance with golang-migrate
 func NewMigrator(config Config) (*Migrator, error) {
-	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
-	)
-
 	// Open database connection
-	db, err := sql.Open("postgres", connStr)
+	db, err := sql.Open("postgres", connString(config))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -80,6 +75,14 @@ func NewMigrator(config Config) (*Migrator, error) {
 	}, nil
 }
 
+// connString returns the lib/pq connection string of config
+func connString(config Config) string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		config.Host, config.Port, config.User, config.Password, config.DBName, config.SSLMode,
+	)
+}
+
 // Close closes the database connection and migrate instance
 func (m *Migrator) Close() error {
 	if m.migrate != nil {
@@ -94,6 +97,11 @@ func (m *Migrator) Close() error {
 	return nil
 }
 
+// DB returns the connection migrations run on; it is closed by Close
+func (m *Migrator) DB() *sql.DB {
+	return m.db
+}
+
 // Run executes all pending migrations (up)
 func (m *Migrator) Run(ctx context.Context) error {
 	log.Println("Starting database migrations...")
@@ -110,6 +118,17 @@ func (m *Migrator) Run(ctx context.Context) error {
 		log.Printf("Current migration version: %d (dirty: %v)", version, dirty)
 	}
 
+	// Stop after the migration in progress if ctx is cancelled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			m.migrate.GracefulStop <- true
+		case <-done:
+		}
+	}()
+
 	// Run all pending migrations
 	err = m.migrate.Up()
 	if err != nil {
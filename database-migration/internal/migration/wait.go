@@ -0,0 +1,76 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// WaitConfig bounds waiting for the database to accept connections
+type WaitConfig struct {
+	// Timeout is how long to keep trying; 0 tries once
+	Timeout time.Duration
+	// InitialBackoff is the wait after the first failed attempt, doubled
+	// after every further one up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// WaitForDatabase returns once the database of config accepts connections,
+// retrying with exponential backoff while PostgreSQL is still starting or
+// not reachable yet. It gives up when wait.Timeout passes, when ctx is
+// cancelled, or straight away when the server refuses the credentials or
+// the database does not exist, which waiting does not fix.
+func WaitForDatabase(ctx context.Context, config Config, wait WaitConfig) error {
+	db, err := sql.Open("postgres", connString(config))
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	deadline := time.Now().Add(wait.Timeout)
+	backoff := wait.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := db.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("Database accepted connections after %d attempts", attempt)
+			}
+			return nil
+		}
+		if permanentConnError(err) {
+			return fmt.Errorf("failed to ping database: %w", err)
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("database not ready after %s (%d attempts): %w", wait.Timeout, attempt, err)
+		}
+		delay := min(backoff, remaining)
+		log.Printf("Database not ready (attempt %d), retrying in %s: %v", attempt, delay, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		backoff = min(2*backoff, wait.MaxBackoff)
+	}
+}
+
+// permanentConnError reports whether err is an answer from a running
+// server that retrying will not change: rejected credentials or a missing
+// database
+func permanentConnError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code.Class() == "28" || pqErr.Code == "3D000"
+}
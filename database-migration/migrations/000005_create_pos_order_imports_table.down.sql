@@ -0,0 +1,5 @@
+-- Drop index first
+DROP INDEX IF EXISTS idx_pos_order_imports_order_id;
+
+-- Drop pos_order_imports table
+DROP TABLE IF EXISTS pos_order_imports CASCADE;
@@ -0,0 +1,21 @@
+-- Create pos_order_imports table to make legacy POS imports idempotent
+CREATE TABLE IF NOT EXISTS pos_order_imports (
+    ticket_number VARCHAR(64) PRIMARY KEY,
+    order_id VARCHAR(50) NOT NULL,
+    imported_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    -- Foreign key to orders table (CASCADE delete)
+    CONSTRAINT fk_pos_import_order
+        FOREIGN KEY (order_id)
+        REFERENCES orders(id)
+        ON DELETE CASCADE
+);
+
+-- Create index for reverse lookups from an order to its POS ticket
+CREATE INDEX IF NOT EXISTS idx_pos_order_imports_order_id ON pos_order_imports(order_id);
+
+-- Add comments to table
+COMMENT ON TABLE pos_order_imports IS 'Maps legacy POS ticket numbers to the orders they were imported as';
+COMMENT ON COLUMN pos_order_imports.ticket_number IS 'Ticket number assigned by the legacy POS (idempotency key)';
+COMMENT ON COLUMN pos_order_imports.order_id IS 'Reference to orders table';
+COMMENT ON COLUMN pos_order_imports.imported_at IS 'Timestamp of the first successful import';
@@ -0,0 +1,11 @@
+-- Add SKU and barcode columns so handheld scanners can resolve products
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS barcode VARCHAR(32);
+
+-- Both identifiers are optional but must be unique when present
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products(sku) WHERE sku IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_barcode ON products(barcode) WHERE barcode IS NOT NULL;
+
+-- Add comments to columns
+COMMENT ON COLUMN products.sku IS 'Stock keeping unit assigned by merchandising (optional)';
+COMMENT ON COLUMN products.barcode IS 'EAN/UPC barcode printed on the packaging (optional)';
@@ -0,0 +1,5 @@
+-- Drop data_backfills table
+DROP TABLE IF EXISTS data_backfills CASCADE;
+
+-- Drop total column
+ALTER TABLE orders DROP COLUMN IF EXISTS total;
@@ -0,0 +1,20 @@
+-- Add order total; NULL means the total has not been computed yet
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS total DECIMAL(12, 2) CHECK (total >= 0);
+
+COMMENT ON COLUMN orders.total IS 'Order total in dollars at the time it was computed';
+
+-- Create data_backfills table to make chunked data backfills resumable
+CREATE TABLE IF NOT EXISTS data_backfills (
+    name VARCHAR(100) PRIMARY KEY,
+    last_id VARCHAR(50) NOT NULL DEFAULT '',
+    processed BIGINT NOT NULL DEFAULT 0,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add comments to table
+COMMENT ON TABLE data_backfills IS 'Checkpoints for resumable data backfills';
+COMMENT ON COLUMN data_backfills.name IS 'Backfill identifier (e.g., order_totals)';
+COMMENT ON COLUMN data_backfills.last_id IS 'Last primary key processed; the next chunk starts after it';
+COMMENT ON COLUMN data_backfills.processed IS 'Number of rows processed so far';
+COMMENT ON COLUMN data_backfills.completed_at IS 'Set once the backfill has processed every row';
@@ -0,0 +1,2 @@
+-- Drop product_prices_currency table
+DROP TABLE IF EXISTS product_prices_currency CASCADE;
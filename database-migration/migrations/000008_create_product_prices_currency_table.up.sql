@@ -0,0 +1,20 @@
+-- Create product_prices_currency table for per-currency product prices
+CREATE TABLE IF NOT EXISTS product_prices_currency (
+    product_id VARCHAR(50) NOT NULL,
+    currency CHAR(3) NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    price DECIMAL(10, 2) NOT NULL CHECK (price >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, currency),
+
+    -- Foreign key to products table (CASCADE delete)
+    CONSTRAINT fk_product_price_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE
+);
+
+-- Add comments to table
+COMMENT ON TABLE product_prices_currency IS 'Product prices in currencies other than the base price';
+COMMENT ON COLUMN product_prices_currency.product_id IS 'Reference to products table';
+COMMENT ON COLUMN product_prices_currency.currency IS 'ISO 4217 currency code (e.g., EUR)';
+COMMENT ON COLUMN product_prices_currency.price IS 'Product price in the given currency';
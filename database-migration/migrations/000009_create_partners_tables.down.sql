@@ -0,0 +1,7 @@
+-- Drop indexes first
+DROP INDEX IF EXISTS idx_partner_api_keys_partner_id;
+DROP INDEX IF EXISTS idx_partners_status;
+
+-- Drop tables
+DROP TABLE IF EXISTS partner_api_keys CASCADE;
+DROP TABLE IF EXISTS partners CASCADE;
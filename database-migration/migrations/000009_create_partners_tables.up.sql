@@ -0,0 +1,42 @@
+-- Create partners table for self-registered API partners
+CREATE TABLE IF NOT EXISTS partners (
+    id VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    contact_email VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'approved', 'rejected', 'suspended')),
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index on status for the admin approval queue
+CREATE INDEX IF NOT EXISTS idx_partners_status ON partners(status);
+
+-- Create partner_api_keys table; only a hash of each key is stored
+CREATE TABLE IF NOT EXISTS partner_api_keys (
+    id SERIAL PRIMARY KEY,
+    partner_id VARCHAR(50) NOT NULL,
+    key_prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE,
+
+    -- Foreign key to partners table (CASCADE delete)
+    CONSTRAINT fk_partner_api_key_partner
+        FOREIGN KEY (partner_id)
+        REFERENCES partners(id)
+        ON DELETE CASCADE
+);
+
+-- Create index for listing a partner's keys
+CREATE INDEX IF NOT EXISTS idx_partner_api_keys_partner_id ON partner_api_keys(partner_id);
+
+-- Add comments to tables
+COMMENT ON TABLE partners IS 'API partners onboarded through self-registration';
+COMMENT ON COLUMN partners.status IS 'Approval state: pending, approved, rejected or suspended';
+COMMENT ON COLUMN partners.scopes IS 'Scopes granted to every key issued to the partner';
+COMMENT ON TABLE partner_api_keys IS 'API keys issued to partners';
+COMMENT ON COLUMN partner_api_keys.key_prefix IS 'First characters of the key, for identification in logs and support';
+COMMENT ON COLUMN partner_api_keys.key_hash IS 'SHA-256 hex digest of the key; the key itself is never stored';
+COMMENT ON COLUMN partner_api_keys.expires_at IS 'Set when the key is rotated out; NULL means no expiry';
@@ -0,0 +1,3 @@
+-- Drop archived_orders table
+DROP INDEX IF EXISTS idx_archived_orders_object_key;
+DROP TABLE IF EXISTS archived_orders;
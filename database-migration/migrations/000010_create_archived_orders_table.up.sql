@@ -0,0 +1,17 @@
+-- Create archived_orders table indexing orders moved to cold storage
+CREATE TABLE IF NOT EXISTS archived_orders (
+    order_id VARCHAR(50) PRIMARY KEY,
+    object_key TEXT NOT NULL,
+    order_created_at TIMESTAMP WITH TIME ZONE,
+    archived_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index to find every order stored in one archive object
+CREATE INDEX IF NOT EXISTS idx_archived_orders_object_key ON archived_orders(object_key);
+
+-- Add comments to table
+COMMENT ON TABLE archived_orders IS 'Orders deleted from PostgreSQL after being written to the order archive';
+COMMENT ON COLUMN archived_orders.order_id IS 'ID of the archived order';
+COMMENT ON COLUMN archived_orders.object_key IS 'Key of the compressed NDJSON archive object holding the order';
+COMMENT ON COLUMN archived_orders.order_created_at IS 'Creation timestamp of the archived order';
+COMMENT ON COLUMN archived_orders.archived_at IS 'When the order was moved to the archive';
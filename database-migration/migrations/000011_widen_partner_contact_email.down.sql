@@ -0,0 +1,2 @@
+-- Restore the original width; fails if encrypted values are still stored
+ALTER TABLE partners ALTER COLUMN contact_email TYPE VARCHAR(255);
@@ -0,0 +1,4 @@
+-- Widen partner contact emails to hold values encrypted at rest
+ALTER TABLE partners ALTER COLUMN contact_email TYPE TEXT;
+
+COMMENT ON COLUMN partners.contact_email IS 'Partner contact email; AES-GCM encrypted (enc:v1:<key id>:...) when PII encryption is enabled';
@@ -0,0 +1,3 @@
+-- Drop cache_invalidations outbox
+DROP INDEX IF EXISTS idx_cache_invalidations_created_at;
+DROP TABLE IF EXISTS cache_invalidations;
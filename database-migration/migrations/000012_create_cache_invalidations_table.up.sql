@@ -0,0 +1,17 @@
+-- Create cache_invalidations outbox read by every order-food replica
+CREATE TABLE IF NOT EXISTS cache_invalidations (
+    id BIGSERIAL PRIMARY KEY,
+    topic VARCHAR(50) NOT NULL,
+    cache_key TEXT NOT NULL DEFAULT '*',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index to prune expired events
+CREATE INDEX IF NOT EXISTS idx_cache_invalidations_created_at ON cache_invalidations(created_at);
+
+-- Add comments to table
+COMMENT ON TABLE cache_invalidations IS 'Outbox of cache invalidation events; replicas poll it and drop matching cache entries';
+COMMENT ON COLUMN cache_invalidations.id IS 'Monotonic event ID; replicas remember the last ID they applied';
+COMMENT ON COLUMN cache_invalidations.topic IS 'Cache the event applies to, e.g. products';
+COMMENT ON COLUMN cache_invalidations.cache_key IS 'Key to drop within the topic, or * for every entry';
+COMMENT ON COLUMN cache_invalidations.created_at IS 'When the event was published';
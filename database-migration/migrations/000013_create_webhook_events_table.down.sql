@@ -0,0 +1,3 @@
+-- Drop webhook_events dedup store
+DROP INDEX IF EXISTS idx_webhook_events_received_at;
+DROP TABLE IF EXISTS webhook_events;
@@ -0,0 +1,16 @@
+-- Create webhook_events dedup store for inbound webhook callbacks
+CREATE TABLE IF NOT EXISTS webhook_events (
+    source VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (source, event_id)
+);
+
+-- Create index to prune old events
+CREATE INDEX IF NOT EXISTS idx_webhook_events_received_at ON webhook_events(received_at);
+
+-- Add comments to table
+COMMENT ON TABLE webhook_events IS 'Webhook events already accepted; a repeated event ID is acknowledged without being processed again';
+COMMENT ON COLUMN webhook_events.source IS 'Sender of the webhook, e.g. a payment provider or partner';
+COMMENT ON COLUMN webhook_events.event_id IS 'Event ID assigned by the sender';
+COMMENT ON COLUMN webhook_events.received_at IS 'When the event was first accepted';
@@ -0,0 +1,5 @@
+-- Drop stock reservations and stock tracking
+DROP INDEX IF EXISTS idx_stock_reservations_expires_at;
+DROP INDEX IF EXISTS idx_stock_reservations_product_id;
+DROP TABLE IF EXISTS stock_reservations;
+ALTER TABLE products DROP COLUMN IF EXISTS stock;
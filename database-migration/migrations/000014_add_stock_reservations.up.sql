@@ -0,0 +1,26 @@
+-- Track stock per product; NULL means stock is not tracked and never runs out
+ALTER TABLE products ADD COLUMN IF NOT EXISTS stock INTEGER CHECK (stock >= 0);
+
+COMMENT ON COLUMN products.stock IS 'Units on hand, including reserved units; NULL when stock is not tracked';
+
+-- Create stock_reservations table holding stock for checkouts in progress
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id VARCHAR(50) NOT NULL,
+    product_id VARCHAR(50) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id, product_id)
+);
+
+-- Create indexes to sum active reservations per product and to reap expired ones
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_product_id ON stock_reservations(product_id, expires_at);
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_expires_at ON stock_reservations(expires_at);
+
+-- Add comments to table
+COMMENT ON TABLE stock_reservations IS 'Stock held for a checkout until the order is placed or the reservation expires';
+COMMENT ON COLUMN stock_reservations.id IS 'Reservation identifier (UUID) shared by all items of a reservation';
+COMMENT ON COLUMN stock_reservations.product_id IS 'Reserved product';
+COMMENT ON COLUMN stock_reservations.quantity IS 'Units held';
+COMMENT ON COLUMN stock_reservations.expires_at IS 'When the hold lapses; expired rows no longer count against stock';
+COMMENT ON COLUMN stock_reservations.created_at IS 'When the reservation was made';
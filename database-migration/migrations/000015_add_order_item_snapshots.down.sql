@@ -0,0 +1,7 @@
+-- Drop order item product snapshots
+DROP TRIGGER IF EXISTS trg_order_items_snapshot_product ON order_items;
+DROP FUNCTION IF EXISTS order_items_snapshot_product();
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE order_items DROP COLUMN IF EXISTS unit_price;
+ALTER TABLE order_items DROP COLUMN IF EXISTS product_category;
+ALTER TABLE order_items DROP COLUMN IF EXISTS product_name;
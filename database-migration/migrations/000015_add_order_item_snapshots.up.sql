@@ -0,0 +1,37 @@
+-- Snapshot product details on order items so renames and price changes do not rewrite order history
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS product_name VARCHAR(255);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS product_category VARCHAR(100);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS unit_price DECIMAL(10, 2) CHECK (unit_price >= 0);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(5, 4) NOT NULL DEFAULT 0 CHECK (tax_rate >= 0);
+
+-- Fill in rows written by replicas that predate the snapshot columns, both
+-- existing rows and rows inserted while a deployment rolls out
+CREATE OR REPLACE FUNCTION order_items_snapshot_product() RETURNS trigger AS $$
+BEGIN
+    IF NEW.product_name IS NULL OR NEW.unit_price IS NULL THEN
+        SELECT name, category, price
+        INTO NEW.product_name, NEW.product_category, NEW.unit_price
+        FROM products WHERE id = NEW.product_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_order_items_snapshot_product ON order_items;
+CREATE TRIGGER trg_order_items_snapshot_product
+    BEFORE INSERT ON order_items
+    FOR EACH ROW EXECUTE FUNCTION order_items_snapshot_product();
+
+UPDATE order_items oi
+SET product_name = p.name, product_category = p.category, unit_price = p.price
+FROM products p
+WHERE p.id = oi.product_id AND (oi.product_name IS NULL OR oi.unit_price IS NULL);
+
+ALTER TABLE order_items ALTER COLUMN product_name SET NOT NULL;
+ALTER TABLE order_items ALTER COLUMN unit_price SET NOT NULL;
+
+-- Add comments to columns
+COMMENT ON COLUMN order_items.product_name IS 'Product name when the order was placed';
+COMMENT ON COLUMN order_items.product_category IS 'Product category when the order was placed';
+COMMENT ON COLUMN order_items.unit_price IS 'Unit price in dollars when the order was placed';
+COMMENT ON COLUMN order_items.tax_rate IS 'Tax rate applied to the item, as a fraction (0.0825 = 8.25%)';
@@ -0,0 +1,3 @@
+-- Drop audit_log table
+DROP INDEX IF EXISTS idx_audit_log_action_created_at;
+DROP TABLE IF EXISTS audit_log;
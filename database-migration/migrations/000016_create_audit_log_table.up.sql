@@ -0,0 +1,18 @@
+-- Create audit_log table recording admin changes
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    action VARCHAR(100) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index to list entries by action over time
+CREATE INDEX IF NOT EXISTS idx_audit_log_action_created_at ON audit_log(action, created_at);
+
+-- Add comments to table
+COMMENT ON TABLE audit_log IS 'Append-only record of changes made through the admin API';
+COMMENT ON COLUMN audit_log.action IS 'What was changed, e.g. products.bulk_price';
+COMMENT ON COLUMN audit_log.actor IS 'Principal that made the change';
+COMMENT ON COLUMN audit_log.details IS 'Action specific description of the change, such as old and new values';
+COMMENT ON COLUMN audit_log.created_at IS 'When the change was committed';
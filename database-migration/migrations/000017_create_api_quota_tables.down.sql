@@ -0,0 +1,4 @@
+-- Drop api quota tables
+DROP INDEX IF EXISTS idx_api_usage_window_start;
+DROP TABLE IF EXISTS api_usage;
+DROP TABLE IF EXISTS api_quotas;
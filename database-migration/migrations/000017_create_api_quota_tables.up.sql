@@ -0,0 +1,26 @@
+-- Create api_quotas table holding per-caller rate limit overrides
+CREATE TABLE IF NOT EXISTS api_quotas (
+    principal VARCHAR(255) PRIMARY KEY,
+    request_limit INTEGER NOT NULL CHECK (request_limit > 0),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create api_usage table counting requests per caller and window
+CREATE TABLE IF NOT EXISTS api_usage (
+    principal VARCHAR(255) NOT NULL,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    requests INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (principal, window_start)
+);
+
+-- Create index to prune old windows
+CREATE INDEX IF NOT EXISTS idx_api_usage_window_start ON api_usage(window_start);
+
+-- Add comments to tables
+COMMENT ON TABLE api_quotas IS 'Rate limits of callers that differ from the configured default';
+COMMENT ON COLUMN api_quotas.principal IS 'Authenticated caller, e.g. partner:<id>';
+COMMENT ON COLUMN api_quotas.request_limit IS 'Requests allowed per rate limit window';
+COMMENT ON TABLE api_usage IS 'Requests made by each caller in each fixed rate limit window, shared by all replicas';
+COMMENT ON COLUMN api_usage.principal IS 'Authenticated caller, e.g. partner:<id>';
+COMMENT ON COLUMN api_usage.window_start IS 'Start of the fixed window the requests were counted in';
+COMMENT ON COLUMN api_usage.requests IS 'Requests made in the window so far';
@@ -0,0 +1,3 @@
+-- Drop coupon_file_uploads table
+DROP INDEX IF EXISTS idx_coupon_file_uploads_status;
+DROP TABLE IF EXISTS coupon_file_uploads;
@@ -0,0 +1,25 @@
+-- Create coupon_file_uploads table tracking coupon files uploaded through the admin API
+CREATE TABLE IF NOT EXISTS coupon_file_uploads (
+    id UUID PRIMARY KEY,
+    file_name VARCHAR(255) NOT NULL UNIQUE,
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'receiving'
+        CHECK (status IN ('receiving', 'pending', 'processing', 'loaded', 'failed')),
+    coupons_loaded BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    uploaded_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index for the loader to find pending uploads
+CREATE INDEX IF NOT EXISTS idx_coupon_file_uploads_status ON coupon_file_uploads(status, created_at);
+
+-- Add comments to table
+COMMENT ON TABLE coupon_file_uploads IS 'Coupon files uploaded for loading, and the progress of each load';
+COMMENT ON COLUMN coupon_file_uploads.file_name IS 'Name of the file in the coupon data directory, also stored as coupons.file_name';
+COMMENT ON COLUMN coupon_file_uploads.size_bytes IS 'Size of the uploaded file';
+COMMENT ON COLUMN coupon_file_uploads.status IS 'receiving while the upload is written, pending until the loader picks it up, then processing, loaded or failed';
+COMMENT ON COLUMN coupon_file_uploads.coupons_loaded IS 'Coupons inserted by the loader';
+COMMENT ON COLUMN coupon_file_uploads.error IS 'Why loading failed';
+COMMENT ON COLUMN coupon_file_uploads.uploaded_by IS 'Principal that uploaded the file';
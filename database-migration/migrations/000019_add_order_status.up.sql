@@ -0,0 +1,11 @@
+-- Add order status for the kitchen lifecycle. Orders placed before status
+-- tracking have long been served, so existing rows are marked completed;
+-- new orders start pending.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'completed'
+    CHECK (status IN ('pending', 'confirmed', 'preparing', 'completed', 'cancelled'));
+ALTER TABLE orders ALTER COLUMN status SET DEFAULT 'pending';
+
+-- Create index for kitchen systems polling open orders
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(status) WHERE status IN ('pending', 'confirmed', 'preparing');
+
+COMMENT ON COLUMN orders.status IS 'Lifecycle stage: pending, confirmed, preparing, completed or cancelled';
@@ -0,0 +1,3 @@
+-- Drop campaign tables; the generated codes stay in coupons
+DROP TABLE IF EXISTS campaign_codes CASCADE;
+DROP TABLE IF EXISTS campaigns CASCADE;
@@ -0,0 +1,29 @@
+-- Create campaigns table recording promo codes generated through the admin API
+CREATE TABLE IF NOT EXISTS campaigns (
+    id UUID PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE,
+    prefix VARCHAR(10) NOT NULL DEFAULT '',
+    code_length INTEGER NOT NULL CHECK (code_length BETWEEN 8 AND 10),
+    code_count INTEGER NOT NULL CHECK (code_count > 0),
+    file_names TEXT[] NOT NULL,
+    created_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create campaign_codes table; coupons is unlogged and cannot be listed by
+-- campaign efficiently, so the codes are kept here for exports
+CREATE TABLE IF NOT EXISTS campaign_codes (
+    campaign_id UUID NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    code VARCHAR(10) NOT NULL,
+    PRIMARY KEY (campaign_id, code)
+);
+
+-- Add comments to tables
+COMMENT ON TABLE campaigns IS 'Promo code campaigns whose codes were generated into the coupons table';
+COMMENT ON COLUMN campaigns.name IS 'Campaign name chosen by marketing';
+COMMENT ON COLUMN campaigns.prefix IS 'Prefix shared by the codes of the campaign';
+COMMENT ON COLUMN campaigns.code_length IS 'Length of each code including the prefix';
+COMMENT ON COLUMN campaigns.code_count IS 'Number of codes generated';
+COMMENT ON COLUMN campaigns.file_names IS 'coupons.file_name values every code was written under';
+COMMENT ON COLUMN campaigns.created_by IS 'Principal that created the campaign';
+COMMENT ON TABLE campaign_codes IS 'Codes generated for each campaign, the durable copy of their coupons rows';
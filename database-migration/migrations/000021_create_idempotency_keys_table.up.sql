@@ -0,0 +1,23 @@
+-- Create idempotency_keys table so clients can safely retry POST requests
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    principal VARCHAR(255) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+    status_code INTEGER,
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (principal, idempotency_key)
+);
+
+-- Create index to prune old keys
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+
+-- Add comments to table
+COMMENT ON TABLE idempotency_keys IS 'Idempotency-Key headers already used; a retry with the same key gets the stored response';
+COMMENT ON COLUMN idempotency_keys.principal IS 'Caller that sent the key; keys of different callers never collide';
+COMMENT ON COLUMN idempotency_keys.idempotency_key IS 'Value of the Idempotency-Key header';
+COMMENT ON COLUMN idempotency_keys.request_hash IS 'SHA-256 of the method, path and body, to refuse a key reused for another request';
+COMMENT ON COLUMN idempotency_keys.status_code IS 'Status of the stored response; NULL while the first request is in flight';
+COMMENT ON COLUMN idempotency_keys.response_body IS 'Body of the stored response';
+COMMENT ON COLUMN idempotency_keys.completed_at IS 'When the response was stored';
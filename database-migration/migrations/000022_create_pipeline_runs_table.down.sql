@@ -0,0 +1,6 @@
+-- Drop coupon upload operation
+DROP INDEX IF EXISTS idx_coupon_file_uploads_operation_id;
+ALTER TABLE coupon_file_uploads DROP COLUMN IF EXISTS operation_id;
+
+-- Drop pipeline_runs table
+DROP TABLE IF EXISTS pipeline_runs;
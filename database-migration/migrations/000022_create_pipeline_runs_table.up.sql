@@ -0,0 +1,33 @@
+-- Create pipeline_runs table recording every run of the migration and load
+-- jobs under its operation ID, so a failed pipeline can be followed across
+-- jobs, logs and traces
+CREATE TABLE IF NOT EXISTS pipeline_runs (
+    id BIGSERIAL PRIMARY KEY,
+    operation_id CHAR(32) NOT NULL,
+    job VARCHAR(100) NOT NULL,
+    instance_id VARCHAR(36) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running'
+        CHECK (status IN ('running', 'succeeded', 'failed')),
+    summary TEXT,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Create indexes to look runs up by operation and list the latest runs
+CREATE INDEX IF NOT EXISTS idx_pipeline_runs_operation_id ON pipeline_runs(operation_id);
+CREATE INDEX IF NOT EXISTS idx_pipeline_runs_started_at ON pipeline_runs(started_at DESC);
+
+-- Record the operation of coupon file uploads, which the loader continues
+ALTER TABLE coupon_file_uploads ADD COLUMN IF NOT EXISTS operation_id CHAR(32);
+CREATE INDEX IF NOT EXISTS idx_coupon_file_uploads_operation_id ON coupon_file_uploads(operation_id);
+
+-- Add comments to tables
+COMMENT ON TABLE pipeline_runs IS 'Runs of the database-migration and database-load jobs';
+COMMENT ON COLUMN pipeline_runs.operation_id IS 'W3C trace ID of the operation the run belongs to, shared by every job of a pipeline';
+COMMENT ON COLUMN pipeline_runs.job IS 'Job and command that ran, e.g. database-load process-uploads';
+COMMENT ON COLUMN pipeline_runs.instance_id IS 'Instance ID of the process, as logged and reported on /metrics';
+COMMENT ON COLUMN pipeline_runs.status IS 'running until the job finishes, then succeeded or failed; a run left running was killed';
+COMMENT ON COLUMN pipeline_runs.summary IS 'What the run did, e.g. the schema versions or rows loaded';
+COMMENT ON COLUMN pipeline_runs.error IS 'Why the run failed';
+COMMENT ON COLUMN coupon_file_uploads.operation_id IS 'Operation started by the upload request and continued by the loader';
@@ -0,0 +1,5 @@
+-- Drop discount column
+ALTER TABLE orders DROP COLUMN IF EXISTS discount;
+
+-- Drop coupon_discounts table
+DROP TABLE IF EXISTS coupon_discounts;
@@ -0,0 +1,21 @@
+-- Create coupon_discounts table; coupons holds one row per code and file and
+-- is unlogged, so the discount of a code is kept here
+CREATE TABLE IF NOT EXISTS coupon_discounts (
+    coupon VARCHAR(255) PRIMARY KEY,
+    discount_type VARCHAR(10) NOT NULL CHECK (discount_type IN ('percentage', 'fixed')),
+    discount_value DECIMAL(10, 2) NOT NULL CHECK (discount_value > 0),
+    updated_by VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (discount_type <> 'percentage' OR discount_value <= 100)
+);
+
+-- Add order discount; orders placed before discounts had none
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount DECIMAL(12, 2) NOT NULL DEFAULT 0 CHECK (discount >= 0);
+
+-- Add comments to table
+COMMENT ON TABLE coupon_discounts IS 'Discount given by a promo code; valid codes without a row give no discount';
+COMMENT ON COLUMN coupon_discounts.coupon IS 'Promo code, as in coupons.coupon';
+COMMENT ON COLUMN coupon_discounts.discount_type IS 'percentage of the order subtotal or fixed amount in dollars';
+COMMENT ON COLUMN coupon_discounts.discount_value IS 'Percentage (up to 100) or amount in dollars';
+COMMENT ON COLUMN coupon_discounts.updated_by IS 'Principal that last set the discount';
+COMMENT ON COLUMN orders.discount IS 'Promo code discount in dollars; total is the subtotal minus the discount';
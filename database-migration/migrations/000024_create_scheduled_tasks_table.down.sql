@@ -0,0 +1,2 @@
+-- Drop scheduled_tasks table
+DROP TABLE IF EXISTS scheduled_tasks;
@@ -0,0 +1,17 @@
+-- Create scheduled_tasks table holding the latest run of each recurring
+-- task, so replicas can tell when another replica last ran it
+CREATE TABLE IF NOT EXISTS scheduled_tasks (
+    name VARCHAR(64) PRIMARY KEY,
+    instance_id VARCHAR(36) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Add comments to table
+COMMENT ON TABLE scheduled_tasks IS 'Latest run of each order-food scheduled task across all replicas';
+COMMENT ON COLUMN scheduled_tasks.name IS 'Task name';
+COMMENT ON COLUMN scheduled_tasks.instance_id IS 'Instance ID of the replica that ran the task';
+COMMENT ON COLUMN scheduled_tasks.status IS 'succeeded or failed';
+COMMENT ON COLUMN scheduled_tasks.error IS 'Error of a failed run';
@@ -0,0 +1,5 @@
+-- Drop coupon_redemptions table
+DROP TABLE IF EXISTS coupon_redemptions;
+
+-- Drop coupon_limits table
+DROP TABLE IF EXISTS coupon_limits;
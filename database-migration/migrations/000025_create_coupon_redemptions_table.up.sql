@@ -0,0 +1,41 @@
+-- Create coupon_limits table; a promo code without a row can be used any
+-- number of times. Placing an order locks the code's row while it counts
+-- the redemption, so concurrent orders cannot overrun the limit.
+CREATE TABLE IF NOT EXISTS coupon_limits (
+    coupon VARCHAR(255) PRIMARY KEY,
+    max_redemptions INTEGER CHECK (max_redemptions > 0),
+    once_per_customer BOOLEAN NOT NULL DEFAULT FALSE,
+    redemptions INTEGER NOT NULL DEFAULT 0 CHECK (redemptions >= 0),
+    updated_by VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create coupon_redemptions table recording every order placed with a
+-- promo code. There is no foreign key to orders: archived orders leave the
+-- table but their redemptions must still count.
+CREATE TABLE IF NOT EXISTS coupon_redemptions (
+    id BIGSERIAL PRIMARY KEY,
+    coupon VARCHAR(255) NOT NULL,
+    order_id VARCHAR(36) NOT NULL UNIQUE,
+    customer_id VARCHAR(64),
+    redeemed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Record the orders placed with a promo code so far, so limits set later
+-- count them
+INSERT INTO coupon_redemptions (coupon, order_id, redeemed_at)
+SELECT coupon_code, id, created_at FROM orders
+WHERE coupon_code IS NOT NULL AND coupon_code <> ''
+ON CONFLICT (order_id) DO NOTHING;
+
+-- Create index for per-customer lookups
+CREATE INDEX IF NOT EXISTS idx_coupon_redemptions_coupon_customer ON coupon_redemptions(coupon, customer_id);
+
+-- Add comments to tables
+COMMENT ON TABLE coupon_limits IS 'Usage limits of promo codes; codes without a row are unlimited';
+COMMENT ON COLUMN coupon_limits.max_redemptions IS 'Orders that can use the code in total; NULL for no limit';
+COMMENT ON COLUMN coupon_limits.once_per_customer IS 'Whether each customer can use the code only once';
+COMMENT ON COLUMN coupon_limits.redemptions IS 'Orders that used the code, counted under the row lock';
+COMMENT ON COLUMN coupon_limits.updated_by IS 'Principal that last set the limits';
+COMMENT ON TABLE coupon_redemptions IS 'Orders placed with a promo code';
+COMMENT ON COLUMN coupon_redemptions.customer_id IS 'Customer ID supplied with the order, if any';
@@ -0,0 +1,5 @@
+-- Drop product_translations table
+DROP TABLE IF EXISTS product_translations CASCADE;
+
+-- Drop products.description
+ALTER TABLE products DROP COLUMN IF EXISTS description;
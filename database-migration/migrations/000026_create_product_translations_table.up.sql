@@ -0,0 +1,28 @@
+-- Add a description in the default language to products
+ALTER TABLE products ADD COLUMN IF NOT EXISTS description TEXT;
+
+-- Create product_translations table for localized product names and
+-- descriptions. Language tags are stored in lower case (e.g. fr, fr-ca) so
+-- they match Accept-Language tags regardless of case.
+CREATE TABLE IF NOT EXISTS product_translations (
+    product_id VARCHAR(50) NOT NULL,
+    language VARCHAR(35) NOT NULL CHECK (language ~ '^[a-z]{2,3}(-[a-z0-9]{1,8})*$'),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, language),
+
+    -- Foreign key to products table (CASCADE delete)
+    CONSTRAINT fk_product_translation_product
+        FOREIGN KEY (product_id)
+        REFERENCES products(id)
+        ON DELETE CASCADE
+);
+
+-- Add comments to table
+COMMENT ON COLUMN products.description IS 'Product description in the default language';
+COMMENT ON TABLE product_translations IS 'Product names and descriptions in languages other than the default';
+COMMENT ON COLUMN product_translations.product_id IS 'Reference to products table';
+COMMENT ON COLUMN product_translations.language IS 'Lower-case BCP 47 language tag (e.g., fr, pt-br)';
+COMMENT ON COLUMN product_translations.name IS 'Product name in the language';
+COMMENT ON COLUMN product_translations.description IS 'Product description in the language; the default description is used when NULL';
@@ -0,0 +1,7 @@
+-- Drop item discount column
+ALTER TABLE order_items DROP COLUMN IF EXISTS discount;
+
+-- Drop discount scope columns
+ALTER TABLE coupon_discounts
+    DROP COLUMN IF EXISTS categories,
+    DROP COLUMN IF EXISTS product_ids;
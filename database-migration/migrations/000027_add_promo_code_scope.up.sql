@@ -0,0 +1,14 @@
+-- Restrict promo code discounts to categories or products; a discount with
+-- neither applies to the whole order
+ALTER TABLE coupon_discounts
+    ADD COLUMN IF NOT EXISTS categories TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS product_ids TEXT[] NOT NULL DEFAULT '{}';
+
+-- Add item discount; the order discount is shared among the items it
+-- applied to
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS discount DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (discount >= 0);
+
+-- Add comments to columns
+COMMENT ON COLUMN coupon_discounts.categories IS 'Product categories the discount applies to';
+COMMENT ON COLUMN coupon_discounts.product_ids IS 'Products the discount applies to; with categories empty too, the whole order';
+COMMENT ON COLUMN order_items.discount IS 'Share of the order discount taken off this item, in dollars';
@@ -0,0 +1,9 @@
+-- Restore the unique indexes over every product; fails if a deleted
+-- product shares its SKU or barcode with another
+DROP INDEX IF EXISTS idx_products_sku;
+DROP INDEX IF EXISTS idx_products_barcode;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products(sku) WHERE sku IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_barcode ON products(barcode) WHERE barcode IS NOT NULL;
+
+-- Drop deleted_at column
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
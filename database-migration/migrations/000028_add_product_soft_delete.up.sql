@@ -0,0 +1,12 @@
+-- Add deleted_at so products removed through the API stay referenced by
+-- past orders and reservations instead of being deleted
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- SKUs and barcodes of deleted products can be given to new products
+DROP INDEX IF EXISTS idx_products_sku;
+DROP INDEX IF EXISTS idx_products_barcode;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products(sku) WHERE sku IS NOT NULL AND deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_barcode ON products(barcode) WHERE barcode IS NOT NULL AND deleted_at IS NULL;
+
+-- Add comment to column
+COMMENT ON COLUMN products.deleted_at IS 'When the product was deleted; deleted products are hidden from the catalogue and cannot be ordered';
@@ -0,0 +1,5 @@
+-- Drop search index
+DROP INDEX IF EXISTS idx_products_search_vector;
+
+-- Drop search_vector column
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,13 @@
+-- Add a search document over the name, category and description of each
+-- product, weighted in that order, for full-text product search
+ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(category, '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'C')
+) STORED;
+
+-- Create GIN index for search queries
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
+
+-- Add comment to column
+COMMENT ON COLUMN products.search_vector IS 'Full-text search document of the product, kept up to date by PostgreSQL';
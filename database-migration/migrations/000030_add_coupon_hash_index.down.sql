@@ -0,0 +1,2 @@
+-- Drop promo code hash index
+DROP INDEX IF EXISTS idx_coupons_coupon_hash;
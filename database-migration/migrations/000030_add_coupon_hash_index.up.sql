@@ -0,0 +1,11 @@
+-- Promo code validation looks codes up by equality only. A hash index keeps
+-- a 4-byte hash per row rather than the code itself, so it is a fraction of
+-- the size of the primary key's btree on (coupon, file_name) and stays in
+-- memory on tables of hundreds of millions of codes.
+--
+-- Codes are not narrowed to char(10): coupon files are loaded without
+-- checking code length, so longer codes may exist, and rewriting the table
+-- would lock it for as long as the rewrite takes.
+CREATE INDEX IF NOT EXISTS idx_coupons_coupon_hash ON coupons USING HASH (coupon);
+
+COMMENT ON INDEX idx_coupons_coupon_hash IS 'Equality lookups of promo codes, used by PROMO_CODE_LOOKUP=hash';
@@ -0,0 +1,6 @@
+-- Drop the customer of orders
+DROP INDEX IF EXISTS idx_orders_customer_created_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS customer_id;
+
+-- Drop customers table
+DROP TABLE IF EXISTS customers;
@@ -0,0 +1,26 @@
+-- Create customers table for customer accounts. Emails are stored
+-- lowercased and in plaintext, since logging in looks customers up by
+-- email; passwords only as a salted PBKDF2 hash.
+CREATE TABLE IF NOT EXISTS customers (
+    id VARCHAR(36) PRIMARY KEY,
+    email VARCHAR(254) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Attach orders to the customer account that placed them. Orders placed
+-- with an API key have no customer.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS customer_id VARCHAR(36)
+    REFERENCES customers(id) ON DELETE SET NULL;
+
+-- Create index for a customer's order history, newest first
+CREATE INDEX IF NOT EXISTS idx_orders_customer_created_at ON orders(customer_id, created_at DESC, id DESC)
+    WHERE customer_id IS NOT NULL;
+
+-- Add comments to tables
+COMMENT ON TABLE customers IS 'Customer accounts that log in with email and password';
+COMMENT ON COLUMN customers.email IS 'Login email, lowercased';
+COMMENT ON COLUMN customers.password_hash IS 'pbkdf2-sha256$<iterations>$<salt>$<hash>, base64 encoded; the password itself is never stored';
+COMMENT ON COLUMN orders.customer_id IS 'Customer account that placed the order; NULL for orders placed with an API key';
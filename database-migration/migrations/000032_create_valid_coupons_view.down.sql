@@ -0,0 +1,3 @@
+-- Drop valid coupons view and refresh state
+DROP TABLE IF EXISTS matview_refreshes;
+DROP MATERIALIZED VIEW IF EXISTS valid_coupons;
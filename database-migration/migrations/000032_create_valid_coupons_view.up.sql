@@ -0,0 +1,32 @@
+-- Promo codes in at least two coupon files, the default promo code policy,
+-- with the number of files each is in. Grouping the whole coupons table
+-- takes minutes on hundreds of millions of codes, so readers that can live
+-- with data as of the latest refresh use this view instead. order-food
+-- refreshes it after coupon loads and on a schedule.
+--
+-- It is created empty so the migration does not group coupons while
+-- holding its locks; the first refresh fills it.
+CREATE MATERIALIZED VIEW IF NOT EXISTS valid_coupons AS
+    SELECT coupon, COUNT(DISTINCT file_name)::int AS files
+    FROM coupons
+    GROUP BY coupon
+    HAVING COUNT(DISTINCT file_name) >= 2
+WITH NO DATA;
+
+-- REFRESH MATERIALIZED VIEW CONCURRENTLY needs a unique index
+CREATE UNIQUE INDEX IF NOT EXISTS idx_valid_coupons_coupon ON valid_coupons(coupon);
+
+-- State of the latest refresh of each materialized view, shared by every
+-- replica. refreshed_at is when the latest successful refresh started,
+-- which is how current the view's data is.
+CREATE TABLE IF NOT EXISTS matview_refreshes (
+    view_name VARCHAR(63) PRIMARY KEY,
+    instance_id VARCHAR(255) NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    refreshed_at TIMESTAMP WITH TIME ZONE,
+    last_duration_ms BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+COMMENT ON MATERIALIZED VIEW valid_coupons IS 'Promo codes in at least two coupon files, as of matview_refreshes.refreshed_at';
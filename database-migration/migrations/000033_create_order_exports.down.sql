@@ -0,0 +1,4 @@
+-- Drop order exports and cancellation times
+DROP TABLE IF EXISTS order_exports;
+DROP INDEX IF EXISTS idx_orders_cancelled_at;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancelled_at;
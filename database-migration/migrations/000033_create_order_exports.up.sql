@@ -0,0 +1,24 @@
+-- When an order was cancelled, which is when it is refunded. Orders
+-- cancelled before this column existed keep NULL and are never exported as
+-- refunds.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP WITH TIME ZONE;
+
+-- Create index for the daily refunds export
+CREATE INDEX IF NOT EXISTS idx_orders_cancelled_at ON orders(cancelled_at) WHERE cancelled_at IS NOT NULL;
+
+-- One row per business day exported to the accounting system. A day is
+-- only marked exported once every file and its manifest are delivered;
+-- failed days are retried on later runs.
+CREATE TABLE IF NOT EXISTS order_exports (
+    day DATE PRIMARY KEY,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('exported', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    orders INT NOT NULL DEFAULT 0,
+    refunds INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    exported_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN orders.cancelled_at IS 'When the order was cancelled and refunded';
+COMMENT ON TABLE order_exports IS 'Business days of orders and refunds delivered to the accounting system';
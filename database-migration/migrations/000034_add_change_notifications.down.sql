@@ -0,0 +1,5 @@
+-- Drop change notifications
+DROP TRIGGER IF EXISTS trg_orders_notify_change ON orders;
+DROP FUNCTION IF EXISTS orders_notify_change();
+DROP TRIGGER IF EXISTS trg_cache_invalidations_notify ON cache_invalidations;
+DROP FUNCTION IF EXISTS cache_invalidations_notify();
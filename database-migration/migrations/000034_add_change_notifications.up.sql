@@ -0,0 +1,33 @@
+-- Notify listeners of changes with LISTEN/NOTIFY. Notifications are sent
+-- when the writing transaction commits and never for one rolled back, so
+-- they can be acted on without re-checking, whoever the writer is. They
+-- are only delivered to sessions connected at the time, so listeners
+-- still read the tables to catch up after a reconnect.
+
+-- Wake the cache invalidation relay of every replica once events are
+-- committed, instead of at its next poll. One notification per statement
+-- is enough, since the relay reads every new event.
+CREATE OR REPLACE FUNCTION cache_invalidations_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('cache_invalidations', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_cache_invalidations_notify ON cache_invalidations;
+CREATE TRIGGER trg_cache_invalidations_notify
+    AFTER INSERT ON cache_invalidations
+    FOR EACH STATEMENT EXECUTE FUNCTION cache_invalidations_notify();
+
+-- Announce orders placed and status changes as {"id": ..., "status": ...}
+CREATE OR REPLACE FUNCTION orders_notify_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('order_changes', json_build_object('id', NEW.id, 'status', NEW.status)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_orders_notify_change ON orders;
+CREATE TRIGGER trg_orders_notify_change
+    AFTER INSERT OR UPDATE OF status ON orders
+    FOR EACH ROW EXECUTE FUNCTION orders_notify_change();
@@ -0,0 +1,3 @@
+-- Drop maintenance_jobs
+DROP INDEX IF EXISTS idx_maintenance_jobs_running;
+DROP TABLE IF EXISTS maintenance_jobs;
@@ -0,0 +1,23 @@
+-- Maintenance jobs started through the admin API, such as rebuilding the
+-- product search index and caches after a large load. A job runs on the
+-- replica that accepted it; any replica reports its progress.
+CREATE TABLE IF NOT EXISTS maintenance_jobs (
+    id UUID PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    steps JSONB NOT NULL DEFAULT '[]',
+    error TEXT NOT NULL DEFAULT '',
+    requested_by VARCHAR(255) NOT NULL,
+    instance_id VARCHAR(255) NOT NULL,
+    operation_id VARCHAR(32),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Only one job of a kind runs at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_maintenance_jobs_running ON maintenance_jobs(kind) WHERE status = 'running';
+
+COMMENT ON TABLE maintenance_jobs IS 'Maintenance jobs started through the admin API and their progress';
+COMMENT ON COLUMN maintenance_jobs.steps IS 'Name, status, duration and error of every step, in order';
+COMMENT ON COLUMN maintenance_jobs.updated_at IS 'When a step last started or finished; running jobs not updated for an hour are taken as abandoned';
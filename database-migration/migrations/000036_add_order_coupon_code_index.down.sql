@@ -0,0 +1,2 @@
+-- Drop order promo code index
+DROP INDEX IF EXISTS idx_orders_coupon_code_created_at;
@@ -0,0 +1,6 @@
+-- Order listings can be filtered by the promo code applied. Most orders
+-- have none, so only orders with a code are indexed; created_at follows so
+-- the newest orders with a code are read in index order.
+CREATE INDEX IF NOT EXISTS idx_orders_coupon_code_created_at ON orders(coupon_code, created_at DESC) WHERE coupon_code IS NOT NULL;
+
+COMMENT ON INDEX idx_orders_coupon_code_created_at IS 'Order listings filtered by couponCode';
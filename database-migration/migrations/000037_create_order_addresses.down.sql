@@ -0,0 +1,2 @@
+-- Drop order_addresses
+DROP TABLE IF EXISTS order_addresses;
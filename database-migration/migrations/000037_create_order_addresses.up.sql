@@ -0,0 +1,24 @@
+-- Delivery address and contact of orders that are delivered. Street lines,
+-- contact details and instructions are personal data and are written
+-- through the PII codec, encrypted when PII_ENCRYPTION_KEYS is set, so
+-- they are TEXT to hold the sealed form. City, region, postal code and
+-- country stay plaintext so deliveries can be grouped by area.
+CREATE TABLE IF NOT EXISTS order_addresses (
+    order_id VARCHAR(50) PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    line1 TEXT NOT NULL,
+    line2 TEXT NOT NULL DEFAULT '',
+    city VARCHAR(100) NOT NULL,
+    region VARCHAR(100) NOT NULL DEFAULT '',
+    postal_code VARCHAR(10) NOT NULL DEFAULT '',
+    country CHAR(2) NOT NULL,
+    contact_name TEXT NOT NULL,
+    contact_phone TEXT NOT NULL,
+    contact_email TEXT NOT NULL DEFAULT '',
+    instructions TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE order_addresses IS 'Delivery address and contact of delivered orders; deleted with their order';
+COMMENT ON COLUMN order_addresses.line1 IS 'Street address, encrypted when PII encryption is enabled';
+COMMENT ON COLUMN order_addresses.country IS 'ISO 3166-1 alpha-2 country code';
+COMMENT ON COLUMN order_addresses.contact_phone IS 'Phone number of the recipient, encrypted when PII encryption is enabled';
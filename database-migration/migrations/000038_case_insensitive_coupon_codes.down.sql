@@ -0,0 +1,13 @@
+-- Restore case-sensitive promo code lookups. Codes upper-cased by the up
+-- migration stay in upper case.
+DROP MATERIALIZED VIEW IF EXISTS valid_coupons;
+CREATE MATERIALIZED VIEW valid_coupons AS
+    SELECT coupon, COUNT(DISTINCT file_name)::int AS files
+    FROM coupons
+    GROUP BY coupon
+    HAVING COUNT(DISTINCT file_name) >= 2
+WITH NO DATA;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_valid_coupons_coupon ON valid_coupons(coupon);
+DELETE FROM matview_refreshes WHERE view_name = 'valid_coupons';
+COMMENT ON MATERIALIZED VIEW valid_coupons IS 'Promo codes in at least two coupon files, as of matview_refreshes.refreshed_at';
+
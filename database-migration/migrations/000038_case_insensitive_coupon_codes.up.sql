@@ -0,0 +1,44 @@
+-- Promo codes are case-insensitive: database-load stores them in upper case
+-- and order-food upper-cases what customers type. Codes loaded before that
+-- may be in any case, and rewriting hundreds of millions of coupons rows
+-- would lock the table for as long as it takes, so coupons are matched on
+-- upper(coupon) through expression indexes instead. 000050 and 000051 build
+-- them concurrently and 000053 drops the index on coupon itself, as
+-- CREATE INDEX CONCURRENTLY cannot run in the transaction golang-migrate
+-- wraps a multi-statement migration in.
+
+-- The tables keyed by promo code are small, so their codes are upper-cased.
+-- Where a code has rows in several cases, the upper-case row is kept.
+DELETE FROM coupon_discounts d
+WHERE coupon <> upper(coupon)
+  AND EXISTS (SELECT 1 FROM coupon_discounts u WHERE u.coupon = upper(d.coupon));
+UPDATE coupon_discounts SET coupon = upper(coupon) WHERE coupon <> upper(coupon);
+
+DELETE FROM coupon_limits l
+WHERE coupon <> upper(coupon)
+  AND EXISTS (SELECT 1 FROM coupon_limits u WHERE u.coupon = upper(l.coupon));
+UPDATE coupon_limits SET coupon = upper(coupon) WHERE coupon <> upper(coupon);
+
+UPDATE coupon_redemptions SET coupon = upper(coupon) WHERE coupon <> upper(coupon);
+UPDATE orders SET coupon_code = upper(coupon_code) WHERE coupon_code <> upper(coupon_code);
+
+-- Redemptions of merged codes now count towards one limit
+UPDATE coupon_limits l
+SET redemptions = (SELECT COUNT(*) FROM coupon_redemptions r WHERE r.coupon = l.coupon)
+WHERE redemptions <> (SELECT COUNT(*) FROM coupon_redemptions r WHERE r.coupon = l.coupon);
+
+-- Group valid codes case-insensitively. The view is recreated empty, as in
+-- 000032, and its refresh state is cleared so readers do not trust it
+-- until the next refresh fills it.
+DROP MATERIALIZED VIEW IF EXISTS valid_coupons;
+CREATE MATERIALIZED VIEW valid_coupons AS
+    SELECT upper(coupon) AS coupon, COUNT(DISTINCT file_name)::int AS files
+    FROM coupons
+    GROUP BY upper(coupon)
+    HAVING COUNT(DISTINCT file_name) >= 2
+WITH NO DATA;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_valid_coupons_coupon ON valid_coupons(coupon);
+DELETE FROM matview_refreshes WHERE view_name = 'valid_coupons';
+
+COMMENT ON MATERIALIZED VIEW valid_coupons IS 'Promo codes, in upper case, in at least two coupon files, as of matview_refreshes.refreshed_at';
+COMMENT ON COLUMN coupon_discounts.coupon IS 'Promo code in upper case, matching upper(coupons.coupon)';
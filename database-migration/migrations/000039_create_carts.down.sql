@@ -0,0 +1,3 @@
+-- Drop cart_items and carts
+DROP TABLE IF EXISTS cart_items;
+DROP TABLE IF EXISTS carts;
@@ -0,0 +1,36 @@
+-- Shopping carts. A cart is identified by an unguessable ID handed to the
+-- app that created it, and belongs to a customer account when it was
+-- created with a customer access token. Checking a cart out stores the ID
+-- of the order it became, after which the cart no longer changes. There
+-- is no foreign key to orders: archived orders leave the table. Carts not
+-- changed for CART_TTL are deleted by the abandoned-cart-reaper task.
+CREATE TABLE IF NOT EXISTS carts (
+    id VARCHAR(36) PRIMARY KEY,
+    account_id VARCHAR(36) REFERENCES customers(id) ON DELETE CASCADE,
+    coupon_code VARCHAR(255),
+    order_id VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Items in a cart, one row per product. Products deleted through the API
+-- only get deleted_at set and are left out when the cart is read.
+CREATE TABLE IF NOT EXISTS cart_items (
+    cart_id VARCHAR(36) NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+    product_id VARCHAR(50) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (cart_id, product_id)
+);
+
+-- Create index for the abandoned cart sweep
+CREATE INDEX IF NOT EXISTS idx_carts_updated_at ON carts(updated_at);
+
+-- Create index for deleting products
+CREATE INDEX IF NOT EXISTS idx_cart_items_product_id ON cart_items(product_id);
+
+COMMENT ON TABLE carts IS 'Shopping carts; deleted once abandoned for CART_TTL';
+COMMENT ON COLUMN carts.account_id IS 'Customer account that created the cart; NULL for carts created with an API key';
+COMMENT ON COLUMN carts.coupon_code IS 'Promo code previewed on the cart and applied at checkout';
+COMMENT ON COLUMN carts.order_id IS 'Order the cart was checked out as; NULL while it is open';
+COMMENT ON TABLE cart_items IS 'Products in a cart with their quantity';
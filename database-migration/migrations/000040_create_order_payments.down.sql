@@ -0,0 +1,2 @@
+-- Drop order_payments
+DROP TABLE IF EXISTS order_payments;
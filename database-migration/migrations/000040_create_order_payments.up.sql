@@ -0,0 +1,21 @@
+-- Payment of orders charged through a payment provider when they were
+-- placed. The order and its payment are stored in the transaction that
+-- confirmed the payment, so failed payments leave no row.
+CREATE TABLE IF NOT EXISTS order_payments (
+    order_id VARCHAR(50) PRIMARY KEY REFERENCES orders(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    intent_id VARCHAR(255) NOT NULL,
+    status VARCHAR(30) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_order_payments_intent ON order_payments(provider, intent_id);
+
+COMMENT ON TABLE order_payments IS 'Payment of orders charged through a payment provider; deleted with their order';
+COMMENT ON COLUMN order_payments.intent_id IS 'ID of the payment intent at the provider';
+COMMENT ON COLUMN order_payments.status IS 'Status of the payment intent at the provider when it was stored';
+COMMENT ON COLUMN order_payments.amount IS 'Amount charged in the smallest unit of the currency, e.g. cents';
+COMMENT ON COLUMN order_payments.currency IS 'ISO 4217 currency code, lower case';
@@ -0,0 +1,16 @@
+-- Latest result of each data quality check, such as order items whose
+-- product is gone or coupon files whose coupons are missing. The checks
+-- run as a scheduled task; the admin API reports the stored results.
+CREATE TABLE IF NOT EXISTS data_quality_findings (
+    check_name VARCHAR(50) PRIMARY KEY,
+    count BIGINT NOT NULL DEFAULT 0 CHECK (count >= 0),
+    samples JSONB NOT NULL DEFAULT '[]',
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE data_quality_findings IS 'Latest result of each data quality check';
+COMMENT ON COLUMN data_quality_findings.count IS 'Rows the check found; 0 when the data is clean';
+COMMENT ON COLUMN data_quality_findings.samples IS 'Up to 10 of the rows found, as identifiers to look them up by';
+COMMENT ON COLUMN data_quality_findings.error IS 'Why the check failed to run; count and samples are those of the last successful run';
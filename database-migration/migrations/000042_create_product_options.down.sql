@@ -0,0 +1,6 @@
+-- Drop order_item_modifiers, product_options and product_option_groups
+DROP TABLE IF EXISTS order_item_modifiers;
+DROP TABLE IF EXISTS product_options;
+DROP TABLE IF EXISTS product_option_groups;
+
+COMMENT ON COLUMN order_items.unit_price IS 'Unit price in dollars when the order was placed';
@@ -0,0 +1,62 @@
+-- Option groups offered with a product, such as its size or toppings.
+-- Customers pick between min_select and max_select options of each group
+-- when ordering the product.
+CREATE TABLE IF NOT EXISTS product_option_groups (
+    product_id VARCHAR(50) NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    id VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    min_select INTEGER NOT NULL DEFAULT 0 CHECK (min_select >= 0),
+    max_select INTEGER NOT NULL DEFAULT 1 CHECK (max_select >= 1 AND max_select >= min_select),
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, id)
+);
+
+-- Options of the option groups. Option IDs are unique per product, so an
+-- order item names its options without their group.
+CREATE TABLE IF NOT EXISTS product_options (
+    product_id VARCHAR(50) NOT NULL,
+    group_id VARCHAR(50) NOT NULL,
+    id VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    price DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (price >= 0),
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, id),
+
+    CONSTRAINT fk_product_option_group
+        FOREIGN KEY (product_id, group_id)
+        REFERENCES product_option_groups(product_id, id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_options_group ON product_options(product_id, group_id);
+
+-- Options selected for order items, snapshotted like the product of the
+-- item so later menu changes do not rewrite order history
+CREATE TABLE IF NOT EXISTS order_item_modifiers (
+    id SERIAL PRIMARY KEY,
+    order_id VARCHAR(50) NOT NULL,
+    product_id VARCHAR(50) NOT NULL,
+    option_id VARCHAR(50) NOT NULL,
+    group_name VARCHAR(100) NOT NULL,
+    option_name VARCHAR(100) NOT NULL,
+    price DECIMAL(10, 2) NOT NULL CHECK (price >= 0),
+    UNIQUE (order_id, product_id, option_id),
+
+    CONSTRAINT fk_order_item
+        FOREIGN KEY (order_id, product_id)
+        REFERENCES order_items(order_id, product_id)
+        ON DELETE CASCADE
+);
+
+-- Add comments to tables and columns
+COMMENT ON TABLE product_option_groups IS 'Choices offered with a product, such as its size or toppings';
+COMMENT ON COLUMN product_option_groups.min_select IS 'Fewest options of the group an order item must select; 0 makes the group optional';
+COMMENT ON COLUMN product_option_groups.max_select IS 'Most options of the group an order item may select';
+COMMENT ON COLUMN product_option_groups.position IS 'Place of the group in the product''s menu entry';
+COMMENT ON TABLE product_options IS 'Options of product option groups';
+COMMENT ON COLUMN product_options.id IS 'Option ID, unique per product';
+COMMENT ON COLUMN product_options.price IS 'Amount in dollars added to the unit price of the product when the option is selected';
+COMMENT ON COLUMN product_options.position IS 'Place of the option in its group';
+COMMENT ON TABLE order_item_modifiers IS 'Options selected for order items, as they were priced; deleted with their item';
+COMMENT ON COLUMN order_item_modifiers.price IS 'Option price in dollars when the order was placed, included in the unit price of the item';
+COMMENT ON COLUMN order_items.unit_price IS 'Unit price in dollars when the order was placed, including the prices of its modifiers';
@@ -0,0 +1,5 @@
+-- Drop the category foreign key and categories
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_products_category;
+DROP TABLE IF EXISTS categories;
+
+COMMENT ON COLUMN products.category IS 'Product category (e.g., Waffle, Pancakes)';
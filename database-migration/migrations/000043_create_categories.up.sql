@@ -0,0 +1,39 @@
+-- Categories of the menu. Products name their category, and renaming a
+-- category renames it on its products.
+CREATE TABLE IF NOT EXISTS categories (
+    id VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT uq_categories_name UNIQUE (name)
+);
+
+-- Create a category for every category products already name. IDs are the
+-- names in lower case with other characters turned into dashes; names
+-- that come out the same get a numbered suffix.
+INSERT INTO categories (id, name)
+SELECT CASE WHEN n = 1 THEN slug ELSE LEFT(slug, 45) || '-' || n END, category
+FROM (
+    SELECT category, slug, ROW_NUMBER() OVER (PARTITION BY slug ORDER BY category) AS n
+    FROM (
+        SELECT DISTINCT category,
+               COALESCE(NULLIF(LEFT(TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE(category, '[^A-Za-z0-9]+', '-', 'g'))), 50), ''), 'category') AS slug
+        FROM products
+        WHERE category IS NOT NULL
+    ) named
+) numbered
+ON CONFLICT DO NOTHING;
+
+-- Products may only name existing categories
+ALTER TABLE products
+    ADD CONSTRAINT fk_products_category
+    FOREIGN KEY (category) REFERENCES categories(name)
+    ON UPDATE CASCADE;
+
+-- Add comments to table
+COMMENT ON TABLE categories IS 'Categories of the menu, named by products.category';
+COMMENT ON COLUMN categories.id IS 'Category identifier used in URLs';
+COMMENT ON COLUMN categories.name IS 'Category name; renaming it renames it on its products';
+COMMENT ON COLUMN products.category IS 'Name of the category of the product, see categories';
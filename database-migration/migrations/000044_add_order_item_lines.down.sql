@@ -0,0 +1,26 @@
+-- Restore one item per product of an order, with modifiers keyed by the
+-- order and product. Fails while an order lists a product on several items;
+-- merge or delete those first.
+ALTER TABLE order_items ADD CONSTRAINT order_items_order_id_product_id_key UNIQUE (order_id, product_id);
+
+ALTER TABLE order_item_modifiers ADD COLUMN IF NOT EXISTS order_id VARCHAR(50);
+ALTER TABLE order_item_modifiers ADD COLUMN IF NOT EXISTS product_id VARCHAR(50);
+
+UPDATE order_item_modifiers m
+SET order_id = oi.order_id, product_id = oi.product_id
+FROM order_items oi
+WHERE oi.id = m.order_item_id;
+
+ALTER TABLE order_item_modifiers ALTER COLUMN order_id SET NOT NULL;
+ALTER TABLE order_item_modifiers ALTER COLUMN product_id SET NOT NULL;
+ALTER TABLE order_item_modifiers DROP COLUMN IF EXISTS order_item_id;
+
+ALTER TABLE order_item_modifiers
+    ADD CONSTRAINT order_item_modifiers_order_id_product_id_option_id_key UNIQUE (order_id, product_id, option_id);
+ALTER TABLE order_item_modifiers
+    ADD CONSTRAINT fk_order_item
+    FOREIGN KEY (order_id, product_id)
+    REFERENCES order_items(order_id, product_id)
+    ON DELETE CASCADE;
+
+COMMENT ON TABLE order_items IS 'Junction table linking orders to products (many-to-many relationship)';
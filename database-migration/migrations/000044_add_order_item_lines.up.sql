@@ -0,0 +1,29 @@
+-- Let an order list a product on several items, such as a small and a
+-- large pizza. Modifiers belong to the item they were selected for rather
+-- than to the product of the order.
+ALTER TABLE order_item_modifiers ADD COLUMN IF NOT EXISTS order_item_id INTEGER;
+
+UPDATE order_item_modifiers m
+SET order_item_id = oi.id
+FROM order_items oi
+WHERE oi.order_id = m.order_id AND oi.product_id = m.product_id AND m.order_item_id IS NULL;
+
+ALTER TABLE order_item_modifiers ALTER COLUMN order_item_id SET NOT NULL;
+
+-- Dropping the columns drops fk_order_item and the unique constraint on
+-- (order_id, product_id, option_id) with them
+ALTER TABLE order_item_modifiers DROP COLUMN IF EXISTS order_id;
+ALTER TABLE order_item_modifiers DROP COLUMN IF EXISTS product_id;
+
+ALTER TABLE order_item_modifiers
+    ADD CONSTRAINT fk_order_item_modifiers_item
+    FOREIGN KEY (order_item_id) REFERENCES order_items(id)
+    ON DELETE CASCADE;
+ALTER TABLE order_item_modifiers
+    ADD CONSTRAINT uq_order_item_modifiers_option UNIQUE (order_item_id, option_id);
+
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_order_id_product_id_key;
+
+-- Add comments to table and columns
+COMMENT ON TABLE order_items IS 'Items of orders; an order may list a product on several items with different modifiers';
+COMMENT ON COLUMN order_item_modifiers.order_item_id IS 'Order item the option was selected for';
@@ -0,0 +1,6 @@
+COMMENT ON COLUMN pos_order_imports.order_id IS NULL;
+
+-- Import records of archived orders point to no order, so the constraint is
+-- not validated against existing rows
+ALTER TABLE pos_order_imports
+    ADD CONSTRAINT fk_pos_import_order FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE NOT VALID;
@@ -0,0 +1,6 @@
+-- Archiving deletes an order, and the cascade deleted its POS import record
+-- with it, so re-sending the ticket imported the order a second time. The
+-- import records now outlive the orders they point to.
+ALTER TABLE pos_order_imports DROP CONSTRAINT IF EXISTS fk_pos_import_order;
+
+COMMENT ON COLUMN pos_order_imports.order_id IS 'Order created by the import; it may have been archived since';
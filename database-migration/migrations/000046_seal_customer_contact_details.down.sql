@@ -0,0 +1,12 @@
+-- Sealed emails and names stay sealed; decrypt them before migrating down,
+-- since the unique constraint and the old column lengths assume plaintext
+COMMENT ON COLUMN customers.email IS 'Login email, lowercased';
+COMMENT ON COLUMN customers.name IS NULL;
+
+ALTER TABLE customers ADD CONSTRAINT customers_email_key UNIQUE (email);
+
+DROP INDEX IF EXISTS idx_customers_email_hash;
+ALTER TABLE customers DROP COLUMN IF EXISTS email_hash;
+
+ALTER TABLE customers ALTER COLUMN name TYPE VARCHAR(100);
+ALTER TABLE customers ALTER COLUMN email TYPE VARCHAR(254);
@@ -0,0 +1,19 @@
+-- order-food seals customer emails and names with PII_ENCRYPTION_KEYS like
+-- other personal data. Sealed values are longer than the old columns allow
+-- and differ each time the same email is sealed, so customers are looked up
+-- and kept unique by email_hash instead: a keyed hash of the lowercased
+-- email. The key lives with order-food, which seals existing customers and
+-- fills in their hash when it starts.
+ALTER TABLE customers ALTER COLUMN email TYPE TEXT;
+ALTER TABLE customers ALTER COLUMN name TYPE TEXT;
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS email_hash TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customers_email_hash ON customers(email_hash);
+
+-- Emails not yet given a hash are still plaintext; order-food keeps them
+-- unique until they are
+ALTER TABLE customers DROP CONSTRAINT IF EXISTS customers_email_key;
+
+COMMENT ON COLUMN customers.email IS 'Login email, lowercased; sealed when PII_ENCRYPTION_KEYS is set';
+COMMENT ON COLUMN customers.name IS 'Display name; sealed when PII_ENCRYPTION_KEYS is set';
+COMMENT ON COLUMN customers.email_hash IS 'HMAC-SHA256 of the lowercased email keyed with PII_BLIND_INDEX_KEY; NULL until order-food has sealed the row';
@@ -0,0 +1 @@
+ALTER TABLE coupon_redemptions DROP COLUMN IF EXISTS discount;
@@ -0,0 +1,11 @@
+-- Record the discount of each redemption, so promo code analytics report
+-- what the codes took off orders, also once the orders have been archived
+ALTER TABLE coupon_redemptions ADD COLUMN IF NOT EXISTS discount DECIMAL(12, 2) NOT NULL DEFAULT 0 CHECK (discount >= 0);
+
+-- Take the discount of the orders redeemed so far from the orders; those
+-- archived already keep no discount
+UPDATE coupon_redemptions r SET discount = o.discount
+FROM orders o
+WHERE o.id = r.order_id AND o.discount > 0;
+
+COMMENT ON COLUMN coupon_redemptions.discount IS 'Discount the promo code took off the order, in dollars';
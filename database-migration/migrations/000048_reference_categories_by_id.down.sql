@@ -0,0 +1,36 @@
+-- Products name their category again
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category VARCHAR(100);
+UPDATE products p SET category = c.name FROM categories c WHERE c.id = p.category_id;
+CREATE INDEX IF NOT EXISTS idx_products_category ON products(category);
+ALTER TABLE products
+    ADD CONSTRAINT fk_products_category
+    FOREIGN KEY (category) REFERENCES categories(name)
+    ON UPDATE CASCADE;
+
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE products ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(category, '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
+COMMENT ON COLUMN products.search_vector IS 'Full-text search document of the product, kept up to date by PostgreSQL';
+
+CREATE OR REPLACE FUNCTION order_items_snapshot_product() RETURNS trigger AS $$
+BEGIN
+    IF NEW.product_name IS NULL OR NEW.unit_price IS NULL THEN
+        SELECT name, category, price
+        INTO NEW.product_name, NEW.product_category, NEW.unit_price
+        FROM products WHERE id = NEW.product_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_products_category_id;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_products_category_id;
+ALTER TABLE products DROP COLUMN IF EXISTS category_id;
+
+COMMENT ON TABLE categories IS 'Categories of the menu, named by products.category';
+COMMENT ON COLUMN categories.name IS 'Category name; renaming it renames it on its products';
+COMMENT ON COLUMN products.category IS 'Name of the category of the product, see categories';
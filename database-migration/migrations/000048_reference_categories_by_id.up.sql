@@ -0,0 +1,52 @@
+-- Products referred to their category by name, with a foreign key that
+-- renamed every product of a category along with it. They now refer to it
+-- by ID, so a rename only changes the category.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category_id VARCHAR(50);
+
+-- Products without a category are put in one, so every product has one
+INSERT INTO categories (id, name)
+SELECT 'uncategorized', 'Uncategorized'
+WHERE EXISTS (SELECT 1 FROM products WHERE category IS NULL)
+ON CONFLICT DO NOTHING;
+
+UPDATE products p SET category_id = c.id
+FROM categories c
+WHERE p.category_id IS NULL
+  AND c.name = COALESCE(p.category, 'Uncategorized');
+
+ALTER TABLE products ALTER COLUMN category_id SET NOT NULL;
+ALTER TABLE products
+    ADD CONSTRAINT fk_products_category_id
+    FOREIGN KEY (category_id) REFERENCES categories(id);
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products(category_id);
+
+-- Order items snapshot the name of the category of their product
+CREATE OR REPLACE FUNCTION order_items_snapshot_product() RETURNS trigger AS $$
+BEGIN
+    IF NEW.product_name IS NULL OR NEW.unit_price IS NULL THEN
+        SELECT p.name, c.name, p.price
+        INTO NEW.product_name, NEW.product_category, NEW.unit_price
+        FROM products p JOIN categories c ON c.id = p.category_id
+        WHERE p.id = NEW.product_id;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- The search document covered the category name, which products no longer
+-- hold; product search matches categories by their own name instead
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE products ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS fk_products_category;
+DROP INDEX IF EXISTS idx_products_category;
+ALTER TABLE products DROP COLUMN IF EXISTS category;
+
+COMMENT ON TABLE categories IS 'Categories of the menu, referred to by products.category_id';
+COMMENT ON COLUMN categories.name IS 'Category name, shown on its products';
+COMMENT ON COLUMN products.category_id IS 'Category of the product, see categories';
+COMMENT ON COLUMN products.search_vector IS 'Full-text search document of the product, kept up to date by PostgreSQL';
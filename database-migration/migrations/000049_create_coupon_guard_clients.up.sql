@@ -0,0 +1,20 @@
+-- Invalid promo code attempts and blocks per client, shared by every
+-- replica of order-food, so a client cannot spread its guesses over them
+CREATE TABLE IF NOT EXISTS coupon_guard_clients (
+    client VARCHAR(255) PRIMARY KEY,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    blocked_until TIMESTAMP WITH TIME ZONE
+);
+
+-- Listing the clients currently blocked only reads blocked ones
+CREATE INDEX IF NOT EXISTS idx_coupon_guard_clients_blocked_until
+    ON coupon_guard_clients (blocked_until)
+    WHERE blocked_until IS NOT NULL;
+
+-- Add comments to table
+COMMENT ON TABLE coupon_guard_clients IS 'Invalid promo code attempts and blocks of clients, see couponguard';
+COMMENT ON COLUMN coupon_guard_clients.client IS 'Client IP or partner principal';
+COMMENT ON COLUMN coupon_guard_clients.window_start IS 'Start of the window failures are counted in';
+COMMENT ON COLUMN coupon_guard_clients.failures IS 'Invalid attempts within the window';
+COMMENT ON COLUMN coupon_guard_clients.blocked_until IS 'End of the cool-down of a blocked client';
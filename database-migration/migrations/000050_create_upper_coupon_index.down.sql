@@ -0,0 +1,2 @@
+-- Drop case-insensitive promo code index
+DROP INDEX CONCURRENTLY IF EXISTS idx_coupons_upper_coupon;
@@ -0,0 +1,5 @@
+-- Case-insensitive promo code lookups of PROMO_CODE_LOOKUP=count, see
+-- 000038. The index is built without blocking writes to coupons, so this
+-- migration holds this one statement: golang-migrate runs a multi-statement
+-- file in one transaction, where CREATE INDEX CONCURRENTLY cannot run.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_coupons_upper_coupon ON coupons (upper(coupon), file_name);
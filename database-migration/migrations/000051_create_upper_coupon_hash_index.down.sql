@@ -0,0 +1,2 @@
+-- Drop case-insensitive promo code hash index
+DROP INDEX CONCURRENTLY IF EXISTS idx_coupons_upper_coupon_hash;
@@ -0,0 +1,3 @@
+-- Case-insensitive equality lookups of PROMO_CODE_LOOKUP=hash, see 000038.
+-- Built concurrently in a migration of its own, as in 000050.
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_coupons_upper_coupon_hash ON coupons USING HASH (upper(coupon));
@@ -0,0 +1,5 @@
+-- The down migration of 000053 recreates idx_coupons_coupon_hash without
+-- its comment, so it is restored here
+COMMENT ON INDEX idx_coupons_coupon_hash IS 'Equality lookups of promo codes, used by PROMO_CODE_LOOKUP=hash';
+COMMENT ON INDEX idx_coupons_upper_coupon IS NULL;
+COMMENT ON INDEX idx_coupons_upper_coupon_hash IS NULL;
@@ -0,0 +1,4 @@
+-- Document the indexes of 000050 and 000051, which cannot be commented in
+-- their own single-statement migrations
+COMMENT ON INDEX idx_coupons_upper_coupon IS 'Case-insensitive promo code lookups, used by PROMO_CODE_LOOKUP=count';
+COMMENT ON INDEX idx_coupons_upper_coupon_hash IS 'Case-insensitive equality lookups of promo codes, used by PROMO_CODE_LOOKUP=hash';
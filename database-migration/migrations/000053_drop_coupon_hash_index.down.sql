@@ -0,0 +1,2 @@
+-- Restore promo code hash index of 000030
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_coupons_coupon_hash ON coupons USING HASH (coupon);
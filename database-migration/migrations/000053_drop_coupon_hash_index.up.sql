@@ -0,0 +1,4 @@
+-- Lookups no longer compare coupon itself, see 000038. Dropped concurrently
+-- so lookups running on coupons are not blocked, in a migration of its own
+-- as in 000050.
+DROP INDEX CONCURRENTLY IF EXISTS idx_coupons_coupon_hash;
@@ -178,19 +178,25 @@ build_images() {
 EOF
     print_info "Using temporary Docker config to avoid credential helper issues"
 
+    # Stamp the images with the commit they are built from
+    BUILD_ARGS=(
+        --build-arg "GIT_SHA=$(git rev-parse --short HEAD 2>/dev/null || echo unknown)"
+        --build-arg "BUILD_TIME=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+    )
+
     # Build database-migration
     print_info "Building database-migration image..."
-    docker build -t database-migration:latest ./database-migration
+    docker build -t database-migration:latest -f database-migration/Dockerfile "${BUILD_ARGS[@]}" .
     print_success "database-migration image built"
 
     # Build database-load
     print_info "Building database-load image..."
-    docker build -t database-load:latest ./database-load
+    docker build -t database-load:latest -f database-load/Dockerfile "${BUILD_ARGS[@]}" .
     print_success "database-load image built"
 
     # Build order-food
     print_info "Building order-food image..."
-    docker build -t order-food:latest ./order-food
+    docker build -t order-food:latest -f order-food/Dockerfile "${BUILD_ARGS[@]}" .
     print_success "order-food image built"
 
     # Clean up temporary Docker config
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+)
+
+// runBackfillTotals implements the backfill-totals admin command
+func runBackfillTotals(args []string) error {
+	fs := flag.NewFlagSet("backfill-totals", flag.ExitOnError)
+	batchSize := fs.Int("batch-size", 1000, "number of orders recomputed per transaction")
+	restart := fs.Bool("restart", false, "discard saved progress and recompute every order")
+	_ = fs.Parse(args)
+
+	log.Println("Starting order totals backfill...")
+
+	db, _, err := connectDB()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	backfillService := service.NewBackfillService(repository.NewOrderRepository(db))
+	if _, err := backfillService.BackfillOrderTotals(*batchSize, *restart); err != nil {
+		return fmt.Errorf("order totals backfill failed: %w", err)
+	}
+	return nil
+}
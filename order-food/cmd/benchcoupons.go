@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+)
+
+// codeAlphabet is the alphabet random invalid codes are drawn from
+const codeAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// benchResult is the outcome of benchmarking one promo code lookup
+type benchResult struct {
+	lookup    service.PromoCodeLookup
+	requests  int
+	errors    int64
+	elapsed   time.Duration
+	latencies []time.Duration
+}
+
+// percentile returns the latency below which p percent of requests finished
+func (r benchResult) percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	return r.latencies[min(len(r.latencies)-1, int(float64(len(r.latencies))*p/100))]
+}
+
+// runBenchCoupons implements the bench-coupons admin command, which
+// validates the same mix of real and invalid promo codes with each lookup
+// under concurrent load and reports throughput and latency
+func runBenchCoupons(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bench-coupons", flag.ExitOnError)
+	list := fs.String("lookups", "count,hash", "comma-separated promo code lookups to compare")
+	concurrency := fs.Int("concurrency", 16, "number of concurrent validations")
+	requests := fs.Int("requests", 10000, "number of validations per lookup")
+	sample := fs.Int("codes", 1000, "number of codes sampled from the coupons table")
+	invalid := fs.Float64("invalid", 0.5, "share of validations using random codes that do not exist")
+	explain := fs.Bool("explain", false, "print the query plan of each lookup")
+	_ = fs.Parse(args)
+
+	if *concurrency < 1 || *requests < 1 || *sample < 1 {
+		return errors.New("-concurrency, -requests and -codes must be positive")
+	}
+	if *invalid < 0 || *invalid > 1 {
+		return errors.New("-invalid must be between 0 and 1")
+	}
+	var lookups []service.PromoCodeLookup
+	for _, name := range strings.Split(*list, ",") {
+		lookup, err := service.ParsePromoCodeLookup(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		lookups = append(lookups, lookup)
+	}
+
+	db, _, err := connectDB()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(*concurrency)
+	db.SetMaxIdleConns(*concurrency)
+
+	codes, err := sampleCouponCodes(ctx, db, *sample)
+	if err != nil {
+		return err
+	}
+	if len(codes) == 0 && *invalid < 1 {
+		return errors.New("the coupons table is empty; load coupons or use -invalid 1")
+	}
+	mix := make([]string, *requests)
+	for i := range mix {
+		if len(codes) == 0 || rand.Float64() < *invalid {
+			mix[i] = randomCode()
+		} else {
+			mix[i] = codes[rand.IntN(len(codes))]
+		}
+	}
+	log.Printf("Validating %d codes per lookup with %d workers, %d real codes sampled", *requests, *concurrency, len(codes))
+
+	for _, lookup := range lookups {
+		if *explain {
+			if err := explainLookup(ctx, db, lookup, mix[0]); err != nil {
+				return err
+			}
+		}
+		// Warm the buffer cache and connections so the first lookup is not
+		// penalised
+		promoCodes := service.NewPromoCodeService(db, nil)
+		promoCodes.SetLookup(lookup)
+		for _, code := range mix[:min(len(mix), *concurrency)] {
+			_, _, _ = promoCodes.ValidatePromoCode(code)
+		}
+
+		result := benchLookup(ctx, promoCodes, lookup, mix, *concurrency)
+		log.Printf("%-6s %8.0f req/s  p50 %-10v p95 %-10v p99 %-10v errors %d", lookup,
+			float64(result.requests)/result.elapsed.Seconds(), result.percentile(50),
+			result.percentile(95), result.percentile(99), result.errors)
+	}
+	return ctx.Err()
+}
+
+// sampleCouponCodes returns up to n codes from the coupons table, in upper
+// case as they are looked up. Codes are taken in storage order, which is
+// close enough to random for codes loaded from shuffled files.
+func sampleCouponCodes(ctx context.Context, db *sql.DB, n int) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT upper(coupon) FROM coupons LIMIT $1`, n)
+	if err != nil {
+		return nil, fmt.Errorf("error sampling coupons: %w", err)
+	}
+	defer rows.Close()
+
+	var codes []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("error scanning coupon: %w", err)
+		}
+		codes = append(codes, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error sampling coupons: %w", err)
+	}
+	return codes, nil
+}
+
+// randomCode returns a code of a valid length that is almost certainly not
+// a coupon
+func randomCode() string {
+	code := make([]byte, 8+rand.IntN(3))
+	for i := range code {
+		code[i] = codeAlphabet[rand.IntN(len(codeAlphabet))]
+	}
+	return string(code)
+}
+
+// benchLookup validates codes with workers concurrent validations
+func benchLookup(ctx context.Context, promoCodes *service.PromoCodeService, lookup service.PromoCodeLookup, codes []string, workers int) benchResult {
+	result := benchResult{lookup: lookup, latencies: make([]time.Duration, len(codes))}
+	var next atomic.Int64
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := int(next.Add(1)) - 1
+				if i >= len(codes) {
+					return
+				}
+				began := time.Now()
+				if _, _, err := promoCodes.ValidatePromoCode(codes[i]); err != nil {
+					atomic.AddInt64(&result.errors, 1)
+				}
+				result.latencies[i] = time.Since(began)
+			}
+		}()
+	}
+	wg.Wait()
+	result.elapsed = time.Since(start)
+
+	result.requests = min(int(next.Load()), len(codes))
+	result.latencies = result.latencies[:result.requests]
+	slices.Sort(result.latencies)
+	return result
+}
+
+// explainLookup prints the plan PostgreSQL executes lookup with for code
+func explainLookup(ctx context.Context, db *sql.DB, lookup service.PromoCodeLookup, code string) error {
+	rows, err := db.QueryContext(ctx, "EXPLAIN (ANALYZE, BUFFERS) "+service.PromoCodeQuery(lookup), code, service.DefaultPromoCodePolicy.MinFiles)
+	if err != nil {
+		return fmt.Errorf("error explaining %s lookup: %w", lookup, err)
+	}
+	defer rows.Close()
+
+	log.Printf("Plan of the %s lookup:", lookup)
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return fmt.Errorf("error scanning plan: %w", err)
+		}
+		log.Printf("  %s", line)
+	}
+	return rows.Err()
+}
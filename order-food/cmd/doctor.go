@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/database"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/middleware"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/pii"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/timezone"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/doctor"
+)
+
+// requiredSchemaVersion is the newest migration this build depends on
+const requiredSchemaVersion = 53
+
+// Tables the service only reads and tables it also writes
+var (
+	readTables      = []string{"products", "product_prices_currency", "product_translations", "coupons", "api_quotas", "pipeline_runs"}
+	readWriteTables = []string{
+		"orders", "order_items", "pos_order_imports", "data_backfills", "partners",
+		"partner_api_keys", "archived_orders", "cache_invalidations", "webhook_events",
+		"stock_reservations", "audit_log", "api_usage", "coupon_file_uploads",
+		"campaigns", "campaign_codes", "idempotency_keys", "coupon_discounts",
+		"scheduled_tasks", "coupon_limits", "coupon_redemptions", "order_exports",
+		"maintenance_jobs", "coupon_guard_clients",
+	}
+)
+
+// durationSettings and intSettings are validated by the doctor, since
+// invalid values otherwise fall back to defaults silently
+var (
+	durationSettings = []string{
+		"DB_CONN_MAX_LIFETIME", "PARTNER_KEY_ROTATION_GRACE", "COUPON_FAILURE_WINDOW", "COUPON_COOLDOWN",
+		"ORDER_RETENTION", "ORDER_ARCHIVE_INTERVAL", "PRODUCT_CACHE_TTL", "CACHE_INVALIDATION_INTERVAL",
+		"HEALTH_CHECK_TIMEOUT", "SHUTDOWN_TIMEOUT", "RESERVATION_TTL", "RESERVATION_SWEEP_INTERVAL",
+		"RATE_LIMIT_WINDOW", "REQUEST_TIMEOUT", "IDEMPOTENCY_KEY_TTL", "WARMUP_TIMEOUT",
+		"ORDER_VOLUME_WINDOW", "ORDER_VOLUME_CHECK_INTERVAL", "COUPON_FILTER_LOAD_TIMEOUT",
+		"DB_PROBE_INTERVAL", "DUAL_WRITE_VERIFY_INTERVAL", "ORDER_LANE_PARTNER_MAX_WAIT", "ORDER_LANE_PUBLIC_MAX_WAIT",
+	}
+	intSettings = []string{
+		"PORT", "COUPON_MAX_FAILURES", "ORDER_ARCHIVE_BATCH_SIZE",
+		"PAGINATION_DEFAULT_PER_PAGE", "PAGINATION_MAX_PER_PAGE", "RATE_LIMIT_REQUESTS",
+		"COUPON_UPLOAD_MAX_MB", "WARMUP_CONNECTIONS", "ORDER_VOLUME_BASELINE_DAYS", "ORDER_VOLUME_DROP_PERCENT",
+		"ORDER_VOLUME_MIN_EXPECTED", "COUPON_FILTER_BITS_PER_CODE", "DUAL_WRITE_QUEUE_SIZE",
+		"ORDER_LANE_PARTNER_MAX_IN_FLIGHT", "ORDER_LANE_PARTNER_MAX_QUEUED",
+		"ORDER_LANE_PUBLIC_MAX_IN_FLIGHT", "ORDER_LANE_PUBLIC_MAX_QUEUED",
+	}
+)
+
+// runDoctor implements the doctor command, which checks configuration,
+// database access and dependencies and prints a diagnosis
+func runDoctor(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	d := doctor.New(app.GetenvDuration("DOCTOR_TIMEOUT", doctor.DefaultTimeout))
+	d.Check("configuration", checkConfig)
+
+	db, failover, poolMode, err := openDB()
+	if err != nil {
+		d.Check("database connection", func(ctx context.Context) (string, error) { return "", err })
+	} else {
+		defer db.Close()
+		d.Check("database connection", doctor.Connectivity(db))
+		if len(failover.Endpoints()) > 1 {
+			d.Check("database endpoints", checkEndpoints(failover))
+		}
+		d.CheckAfter("database connection", "schema version", doctor.MinSchemaVersion(db, requiredSchemaVersion))
+		d.CheckAfter("database connection", "read-only tables", doctor.Tables(db, "SELECT", readTables...))
+		d.CheckAfter("database connection", "read-write tables", doctor.Tables(db, "SELECT,INSERT,UPDATE,DELETE", readWriteTables...))
+		d.CheckAfter("database connection", "product and stock updates", doctor.Tables(db, "INSERT,UPDATE", "products"))
+		d.CheckAfter("database connection", "campaign codes", doctor.Tables(db, "INSERT", "coupons"))
+		d.CheckAfter("database connection", "connection pooler", func(ctx context.Context) (string, error) {
+			pooled, err := database.DetectTransactionPooler(ctx, db)
+			switch {
+			case err != nil:
+				return "", err
+			case pooled && poolMode != database.PoolModeTransaction:
+				return "", errors.New("transaction pooler detected; set DB_POOL_MODE=transaction")
+			case pooled:
+				return "transaction pooler detected", nil
+			}
+			return "no transaction pooler detected", nil
+		})
+	}
+
+	if mirror, _, secondary, err := newDualWriteMirror(db); err != nil {
+		d.Check("dual-write datastore", func(ctx context.Context) (string, error) { return "", err })
+	} else if mirror != nil {
+		defer secondary.Close()
+		d.Check("dual-write datastore", doctor.Connectivity(secondary))
+	}
+
+	if app.Getenv("ORDER_ARCHIVE_BUCKET", "") != "" {
+		d.Check("order archive", checkArchiveBucket)
+	} else {
+		d.Check("order archive", checkWritableDir("ORDER_ARCHIVE_DIR", "archiving disabled"))
+	}
+	d.Check("coupon uploads", checkWritableDir("COUPON_UPLOAD_DIR", "coupon file uploads disabled"))
+	d.Check("OTLP exporter", doctor.Reachable(app.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")))
+
+	return d.Run(ctx, os.Stdout)
+}
+
+// checkEndpoints probes every host of a replicated cluster and fails when
+// none is the primary; unreachable standbys are only reported
+func checkEndpoints(failover *database.Failover) doctor.CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		var primary string
+		var down []string
+		for _, h := range failover.Probe(ctx) {
+			switch {
+			case h.Primary:
+				primary = h.Endpoint.String()
+			case !h.Up:
+				down = append(down, fmt.Sprintf("%s (%s)", h.Endpoint, h.Error))
+			}
+		}
+		if primary == "" {
+			return "", database.ErrNoPrimary
+		}
+		detail := "primary " + primary
+		if len(down) > 0 {
+			detail += "; unreachable: " + strings.Join(down, ", ")
+		}
+		return detail, nil
+	}
+}
+
+// checkConfig validates settings that would otherwise fail at first use or
+// silently fall back to defaults
+func checkConfig(ctx context.Context) (string, error) {
+	var problems []error
+
+	for _, key := range durationSettings {
+		if value := app.Getenv(key, ""); value != "" {
+			if _, err := time.ParseDuration(value); err != nil {
+				problems = append(problems, fmt.Errorf("%s=%q is not a duration", key, value))
+			}
+		}
+	}
+	for _, key := range intSettings {
+		if value := app.Getenv(key, ""); value != "" {
+			if n, err := strconv.Atoi(value); err != nil || n < 1 {
+				problems = append(problems, fmt.Errorf("%s=%q is not a positive integer", key, value))
+			}
+		}
+	}
+
+	if _, err := database.ParsePoolMode(app.Getenv("DB_POOL_MODE", "")); err != nil {
+		problems = append(problems, fmt.Errorf("DB_POOL_MODE: %w", err))
+	}
+	if _, err := service.ParsePromoCodeLookup(app.Getenv("PROMO_CODE_LOOKUP", "")); err != nil {
+		problems = append(problems, fmt.Errorf("PROMO_CODE_LOOKUP: %w", err))
+	}
+	if _, err := credentialProvider(database.Config{Host: app.Getenv("DB_HOST", "localhost"), Port: app.Getenv("DB_PORT", "5432")}); err != nil {
+		problems = append(problems, err)
+	}
+	if _, err := middleware.ParseChaosRules(app.Getenv("CHAOS_RULES", "")); err != nil {
+		problems = append(problems, fmt.Errorf("CHAOS_RULES: %w", err))
+	}
+	if _, err := middleware.ParseShadowRates(app.Getenv("SHADOW_TRAFFIC_RATES", "")); err != nil {
+		problems = append(problems, fmt.Errorf("SHADOW_TRAFFIC_RATES: %w", err))
+	}
+	if _, err := newPaymentProvider(); err != nil {
+		problems = append(problems, err)
+	}
+	if _, err := timezone.Parse(app.Getenv("BUSINESS_TIMEZONE", "UTC"), app.Getenv("LOCATION_TIMEZONES", "")); err != nil {
+		problems = append(problems, err)
+	}
+	if spec := app.Getenv("PII_ENCRYPTION_KEYS", ""); spec != "" {
+		if _, err := pii.ParseKeys(spec); err != nil {
+			problems = append(problems, fmt.Errorf("PII_ENCRYPTION_KEYS: %w", err))
+		}
+		if app.Getenv("PII_BLIND_INDEX_KEY", "") == "" {
+			problems = append(problems, fmt.Errorf("PII_BLIND_INDEX_KEY must be set with PII_ENCRYPTION_KEYS"))
+		}
+	}
+	if key := app.Getenv("PII_BLIND_INDEX_KEY", ""); key != "" {
+		if _, err := pii.ParseBlindIndexKey(key); err != nil {
+			problems = append(problems, fmt.Errorf("PII_BLIND_INDEX_KEY: %w", err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return "", joinProblems(problems)
+	}
+	if app.Getenv("ADMIN_API_KEY", "") == "" {
+		return "valid; ADMIN_API_KEY is not set so admin routes are disabled", nil
+	}
+	return "valid", nil
+}
+
+// checkWritableDir returns a check that the directory named by the
+// environment variable key can be written, skipped when key is unset
+func checkWritableDir(key, disabled string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		dir := app.Getenv(key, "")
+		if dir == "" {
+			return disabled, doctor.ErrSkipped
+		}
+
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", err
+		}
+		f, err := os.CreateTemp(dir, ".doctor-*")
+		if err != nil {
+			return "", fmt.Errorf("%s is not writable: %w", dir, err)
+		}
+		f.Close()
+		os.Remove(f.Name())
+		return dir + " is writable", nil
+	}
+}
+
+// checkArchiveBucket validates the S3 archive configuration. It does not
+// reach the bucket; the first archiver run does.
+func checkArchiveBucket(ctx context.Context) (string, error) {
+	_, location, err := newArchiveStore()
+	if err != nil {
+		return "", err
+	}
+	return location + " is configured", nil
+}
+
+// joinProblems combines config problems into one single-line error
+func joinProblems(problems []error) error {
+	messages := make([]string, len(problems))
+	for i, err := range problems {
+		messages[i] = err.Error()
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/dualwrite"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
+)
+
+// newDualWriteMirror returns a mirror of the tables listed in
+// DUAL_WRITE_TABLES to the datastore at DUAL_WRITE_DSN, or nil when no
+// table is listed. The caller closes the returned secondary pool.
+func newDualWriteMirror(db *sql.DB) (*dualwrite.Mirror, []string, *sql.DB, error) {
+	tables, err := dualwrite.ParseTables(app.Getenv("DUAL_WRITE_TABLES", ""))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid DUAL_WRITE_TABLES: %w", err)
+	}
+	if len(tables) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	dsn := app.Getenv("DUAL_WRITE_DSN", "")
+	if dsn == "" {
+		return nil, nil, nil, errors.New("DUAL_WRITE_DSN must be set to mirror writes")
+	}
+	secondary, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid DUAL_WRITE_DSN: %w", err)
+	}
+	mirror := dualwrite.New(db, secondary, tables, app.GetenvInt("DUAL_WRITE_QUEUE_SIZE", dualwrite.DefaultQueueSize))
+	return mirror, tables, secondary, nil
+}
+
+// runVerifyDualWrite implements the verify-dual-write admin command, which
+// compares the mirrored tables on both datastores and fails when they
+// diverge
+func runVerifyDualWrite(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("verify-dual-write", flag.ExitOnError)
+	list := fs.String("tables", app.Getenv("DUAL_WRITE_TABLES", ""), "comma-separated tables to verify")
+	repair := fs.Bool("repair", false, "copy divergent rows from PostgreSQL to the secondary datastore again")
+	show := fs.Int("show", 10, "number of divergent keys listed per kind")
+	_ = fs.Parse(args)
+
+	tables, err := dualwrite.ParseTables(*list)
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return errors.New("no table to verify; set DUAL_WRITE_TABLES or -tables")
+	}
+	dsn := app.Getenv("DUAL_WRITE_DSN", "")
+	if dsn == "" {
+		return errors.New("DUAL_WRITE_DSN must be set to verify mirrored writes")
+	}
+
+	db, _, err := connectDB()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+	secondary, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return fmt.Errorf("invalid DUAL_WRITE_DSN: %w", err)
+	}
+	defer secondary.Close()
+
+	mirror := dualwrite.New(db, secondary, tables, 0)
+	divergent := 0
+	for _, table := range tables {
+		report, err := mirror.Verify(ctx, table, *repair)
+		if err != nil && report.Table == "" {
+			return fmt.Errorf("verification of %s failed: %w", table, err)
+		}
+		log.Printf("%s: %d rows, %d missing, %d extra, %d different", table,
+			report.Rows, len(report.Missing), len(report.Extra), len(report.Different))
+		for _, kind := range []struct {
+			name string
+			keys []string
+		}{{"missing", report.Missing}, {"extra", report.Extra}, {"different", report.Different}} {
+			if len(kind.keys) > 0 {
+				log.Printf("  %s: %s", kind.name, strings.Join(kind.keys[:min(*show, len(kind.keys))], ", "))
+			}
+		}
+		if *repair {
+			log.Printf("  repaired %d rows", report.Repaired)
+			if err != nil {
+				log.Printf("  repair failed for some rows: %v", err)
+			}
+		}
+		divergent += report.Divergent() - report.Repaired
+	}
+
+	if divergent > 0 {
+		return fmt.Errorf("%d rows diverge between the datastores", divergent)
+	}
+	log.Println("✓ The datastores match")
+	return nil
+}
@@ -3,95 +3,1091 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
 
+	// Embedded zone data, since slim images ship without /usr/share/zoneinfo
+	_ "time/tzdata"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/archive"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/couponfilter"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/couponguard"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/database"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/deprecation"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/events"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/eventschema"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/export"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/handler"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/health"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/jwt"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/matview"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/middleware"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/payment"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/pii"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/productcache"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/router"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/router/ginrouter"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/scheduler"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/timezone"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/warmup"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/buildinfo"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/instance"
 )
 
+//go:generate swag init --dir .. --generalInfo cmd/main.go --output ../docs --outputTypes go,json,yaml --parseDependency
+
+// @title Order Food API
+// @version 1.0
+// @description Products, orders and promo codes of the Order Food service, with the admin API used to operate it.
+// @BasePath /
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name api_key
+// @securityDefinitions.apikey AdminKeyAuth
+// @in header
+// @name admin_key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Customer access token, as "Bearer <token>"
 func main() {
-	// Get port from environment variable or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	a := app.New("order-food")
+
+	// Admin commands run once and exit instead of starting the server;
+	// Run does not return
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "backfill-totals":
+			a.Run(func(ctx context.Context) error { return runBackfillTotals(os.Args[2:]) })
+		case "reencrypt-pii":
+			a.Run(func(ctx context.Context) error { return runReencryptPII(os.Args[2:]) })
+		case "doctor":
+			a.Run(func(ctx context.Context) error { return runDoctor(ctx, os.Args[2:]) })
+		case "verify-dual-write":
+			a.Run(func(ctx context.Context) error { return runVerifyDualWrite(ctx, os.Args[2:]) })
+		case "bench-coupons":
+			a.Run(func(ctx context.Context) error { return runBenchCoupons(ctx, os.Args[2:]) })
+		}
 	}
 
+	a.Run(func(ctx context.Context) error { return serve(ctx, a) })
+}
+
+// serve runs the API server until ctx is cancelled. Resources are released
+// by shutdown hooks in reverse order: HTTP server, background workers,
+// database.
+func serve(ctx context.Context, a *app.App) error {
+	// Get port from environment variable or use default
+	port := app.Getenv("PORT", "8080")
+
 	log.Println("Starting Order Food API server...")
 
 	// Connect to database
-	db, err := connectDB()
+	db, failover, err := connectDB()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	a.OnShutdown("database", func(ctx context.Context) error { return db.Close() })
+
+	// Probe every host of a replicated cluster so a promoted standby is
+	// found before requests have to fail over to it
+	if len(failover.Endpoints()) > 1 {
+		runInBackground(ctx, a, "database probes", func(ctx context.Context) {
+			failover.Run(ctx, app.GetenvDuration("DB_PROBE_INTERVAL", 5*time.Second))
+		})
+	}
+
+	// Business time zones for reports and dates entered by users
+	zones, err := timezone.Parse(app.Getenv("BUSINESS_TIMEZONE", "UTC"), app.Getenv("LOCATION_TIMEZONES", ""))
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		return fmt.Errorf("invalid time zone configuration: %w", err)
 	}
-	defer db.Close()
 
 	// Initialize repositories
 	productRepo := repository.NewProductRepository(db)
+	piiCodec := newPIICodec()
 	orderRepo := repository.NewOrderRepository(db)
+	orderRepo.SetCodec(piiCodec)
+	partnerRepo := repository.NewPartnerRepository(db, piiCodec)
+
+	// Promo code rules of the region, or else the environment, this replica
+	// is deployed to
+	environment, region := app.Getenv("ENVIRONMENT", ""), app.Getenv("REGION", "")
+	promoCodePolicies, err := service.ParsePromoCodePolicies(app.Getenv("PROMO_CODE_POLICIES", ""))
+	if err != nil {
+		return fmt.Errorf("invalid PROMO_CODE_POLICIES: %w", err)
+	}
+	promoCodePolicy := service.SelectPromoCodePolicy(promoCodePolicies, region, environment)
+	log.Printf("Validating promo codes with the %s policy: %s", promoCodePolicy.Name, promoCodePolicy.Rules())
 
 	// Initialize services
-	productService := service.NewProductService(productRepo)
-	orderService := service.NewOrderService(orderRepo, productRepo)
-	promoCodeService := service.NewPromoCodeService(db)
+	invalidationService := service.NewInvalidationService(repository.NewInvalidationRepository(db))
+	eventValidator, err := newEventValidator(ctx)
+	if err != nil {
+		return err
+	}
+	if eventValidator != nil {
+		invalidationService.SetValidator(eventValidator)
+	}
+	productCache := newProductCache(invalidationService)
+	couponFilter := newCouponFilter(db, invalidationService, promoCodePolicy.MinFiles)
+	invalidationInterval := app.GetenvDuration("CACHE_INVALIDATION_INTERVAL", 2*time.Second)
+	productService := service.NewProductService(productRepo, productCache)
+	sharedProductCache, err := newRedisProductCache(invalidationService)
+	if err != nil {
+		return err
+	}
+	if sharedProductCache != nil {
+		productService.SetSharedCache(sharedProductCache)
+	}
+	archiveService := newArchiveService(orderRepo)
+	reservationRepo := repository.NewReservationRepository(db)
+	promoCodeService := service.NewPromoCodeService(db, couponFilter)
+	promoCodeService.SetPolicy(promoCodePolicy)
+	promoCodeLookup, err := service.ParsePromoCodeLookup(app.Getenv("PROMO_CODE_LOOKUP", ""))
+	if err != nil {
+		return fmt.Errorf("invalid PROMO_CODE_LOOKUP: %w", err)
+	}
+	promoCodeService.SetLookup(promoCodeLookup)
+	promoCodeFallback, err := service.ParsePromoCodeFallback(app.Getenv("PROMO_CODE_FALLBACK", ""))
+	if err != nil {
+		return fmt.Errorf("invalid PROMO_CODE_FALLBACK: %w", err)
+	}
+	promoCodeService.SetFallback(promoCodeFallback, app.GetenvDuration("PROMO_CODE_LOOKUP_TIMEOUT", service.DefaultPromoCodeLookupTimeout))
+	if promoCodeFallback == service.PromoCodeFallbackOpen && couponFilter == nil {
+		slog.Warn("PROMO_CODE_FALLBACK=open needs COUPON_FILTER_ENABLED=true; promo codes are rejected while the database is degraded", "fallback", promoCodeFallback)
+	}
+	orderService := service.NewOrderService(repository.NewTxManager(db), orderRepo, productRepo, reservationRepo, promoCodeService, archiveService)
+	partnerService := service.NewPartnerService(partnerRepo, app.GetenvDuration("PARTNER_KEY_ROTATION_GRACE", service.DefaultKeyRotationGrace))
+	couponAnalyticsService := service.NewCouponAnalyticsService(repository.NewCouponRepository(db))
+	pricingService := service.NewPricingService(productRepo)
+	categoryRepo := repository.NewCategoryRepository(db)
+	categoryService := service.NewCategoryService(categoryRepo, productRepo)
+	couponFileRepo := repository.NewCouponFileRepository(db)
+	couponFileService := service.NewCouponFileService(couponFileRepo, app.Getenv("COUPON_UPLOAD_DIR", ""))
+	operationService := service.NewOperationService(repository.NewPipelineRunRepository(db), couponFileRepo)
+	campaignService := service.NewCampaignService(repository.NewCampaignRepository(db))
+	reservationService := service.NewReservationService(reservationRepo, app.GetenvDuration("RESERVATION_TTL", service.DefaultReservationTTL))
+	stockService := service.NewStockService(reservationRepo)
+	payments, err := newPaymentProvider()
+	if err != nil {
+		return err
+	}
+	if payments != nil {
+		orderService.SetPaymentProvider(payments, app.Getenv("PAYMENT_CURRENCY", "usd"))
+	}
+	cartService := service.NewCartService(repository.NewCartRepository(db), productRepo, promoCodeService, orderService, app.GetenvDuration("CART_TTL", service.DefaultCartTTL))
+
+	// Publish order and product events to the event broker
+	eventService, eventBroker, err := newEventService()
+	if err != nil {
+		return err
+	}
+	if eventService != nil {
+		if eventValidator != nil {
+			eventService.SetValidator(eventValidator)
+		}
+		orderService.SetEventPublisher(eventService)
+		productService.SetEventPublisher(eventService)
+		pricingService.SetEventPublisher(eventService)
+		categoryService.SetEventPublisher(eventService)
+		runInBackground(ctx, a, "event publisher", func(ctx context.Context) {
+			eventService.Run(ctx)
+			if err := eventBroker.Close(); err != nil {
+				log.Printf("Failed to close event broker connection: %v", err)
+			}
+		})
+	}
+
+	// Mirror writes to a second datastore under evaluation
+	mirror, mirroredTables, secondary, err := newDualWriteMirror(db)
+	if err != nil {
+		return err
+	}
+	if mirror != nil {
+		a.OnShutdown("dual-write datastore", func(ctx context.Context) error { return secondary.Close() })
+		productRepo.SetChangeRecorder(mirror)
+		categoryRepo.SetChangeRecorder(mirror)
+		orderRepo.SetChangeRecorder(mirror)
+		reservationRepo.SetChangeRecorder(mirror)
+		runInBackground(ctx, a, "dual-write mirror", mirror.Run)
+		log.Printf("Mirroring writes to %s to the dual-write datastore", strings.Join(mirroredTables, ", "))
+	}
+
+	// Brute-force protection on promo codes
+	var couponGuard *couponguard.Guard
+	if app.Getenv("COUPON_GUARD_ENABLED", "true") != "false" {
+		couponGuard = couponguard.New(couponguard.Config{
+			MaxFailures: app.GetenvInt("COUPON_MAX_FAILURES", couponguard.DefaultConfig.MaxFailures),
+			Window:      app.GetenvDuration("COUPON_FAILURE_WINDOW", couponguard.DefaultConfig.Window),
+			Cooldown:    app.GetenvDuration("COUPON_COOLDOWN", couponguard.DefaultConfig.Cooldown),
+		}, couponguard.NewPostgresStore(db))
+	}
+
+	// Pagination defaults and hard cap shared by all list endpoints
+	paginationConfig := utils.PaginationConfig{
+		DefaultPerPage: app.GetenvInt("PAGINATION_DEFAULT_PER_PAGE", utils.DefaultPaginationConfig.DefaultPerPage),
+		MaxPerPage:     app.GetenvInt("PAGINATION_MAX_PER_PAGE", utils.DefaultPaginationConfig.MaxPerPage),
+	}
+
+	// Caches and connections filled before the replica reports ready
+	warmer := newWarmer(db, productService, couponFilter, paginationConfig.DefaultPerPage)
 
 	// Initialize handlers
 	productHandler := handler.NewProductHandler(productService)
-	orderHandler := handler.NewOrderHandler(orderService, promoCodeService)
-	healthHandler := handler.NewHealthHandler()
+	categoryHandler := handler.NewCategoryHandler(categoryService, productService)
+	orderHandler := handler.NewOrderHandler(orderService, promoCodeService, couponGuard)
+	healthHandler := handler.NewHealthHandler(newHealthChecker(db, productCache, sharedProductCache, invalidationService, invalidationInterval), warmer)
+	partnerHandler := handler.NewPartnerHandler(partnerService)
+	couponGuardHandler := handler.NewCouponGuardHandler(couponGuard)
+	couponAnalyticsHandler := handler.NewCouponAnalyticsHandler(couponAnalyticsService, zones)
+	reservationHandler := handler.NewReservationHandler(reservationService)
+	cartHandler := handler.NewCartHandler(cartService, promoCodeService, couponGuard)
+	pricingHandler := handler.NewPricingHandler(pricingService)
+	stockHandler := handler.NewStockHandler(stockService)
+	rootHandler := handler.NewRootHandler()
+	campaignHandler := handler.NewCampaignHandler(campaignService)
+	operationHandler := handler.NewOperationHandler(operationService)
+	promoCodeHandler := handler.NewPromoCodeHandler(promoCodeService)
+	couponFileHandler := handler.NewCouponFileHandler(couponFileService, int64(app.GetenvInt("COUPON_UPLOAD_MAX_MB", 2048))<<20)
+
+	// Customer accounts, enabled once there is a secret to sign access tokens with
+	customerService, err := newCustomerService(db, piiCodec)
+	if err != nil {
+		return err
+	}
+	var customerHandler *handler.CustomerHandler
+	if customerService != nil {
+		customerHandler = handler.NewCustomerHandler(customerService)
+	}
 
 	// Setup router
-	r := router.SetupRouter(productHandler, orderHandler, healthHandler)
+	adminAPIKey := app.Getenv("ADMIN_API_KEY", "")
+	if adminAPIKey == "" {
+		log.Println("ADMIN_API_KEY is not set; admin routes are disabled")
+	}
+
+	chaos, err := newChaosConfig()
+	if err != nil {
+		return err
+	}
+	deprecations, err := newDeprecationRegistry()
+	if err != nil {
+		return err
+	}
+	shadowTraffic, err := newShadowTraffic()
+	if err != nil {
+		return err
+	}
+
+	// Per-caller request quotas, counted in the database so they hold
+	// across replicas
+	routerConfig := router.Config{
+		Pagination:      paginationConfig,
+		DefaultLanguage: app.Getenv("DEFAULT_LANGUAGE", utils.DefaultLanguage),
+		AdminAPIKey:     adminAPIKey,
+		APIKeyVerifiers: []middleware.APIKeyVerifier{partnerService.VerifyAPIKey},
+		Chaos:           chaos,
+		RequestTimeout:  app.GetenvDuration("REQUEST_TIMEOUT", 0),
+		Instance:        instance.Get(),
+		Swagger:         app.Getenv("SWAGGER_ENABLED", "false") == "true",
+		Deprecations:    deprecations,
+		ShadowTraffic:   shadowTraffic,
+	}
+	if customerService != nil {
+		routerConfig.TokenVerifier = customerService.VerifyToken
+	}
+	productListCache := newProductListCache(invalidationService)
+	routerConfig.ProductListCache = productListCache
+	orderLanes := newOrderLanes()
+	routerConfig.OrderLanes = orderLanes
+
+	// Responses to order creation retried with the same Idempotency-Key
+	idempotencyService := service.NewIdempotencyService(
+		repository.NewIdempotencyRepository(db),
+		app.GetenvDuration("IDEMPOTENCY_KEY_TTL", service.DefaultIdempotencyKeyTTL),
+	)
+	routerConfig.Idempotency = idempotencyService
+
+	// Recurring maintenance, run by one replica at a time
+	tasks := []scheduler.Task{
+		{Name: "idempotency-key-pruner", Interval: time.Hour, Run: idempotencyService.PruneExpired},
+		{
+			Name:     "stock-reservation-reaper",
+			Interval: app.GetenvDuration("RESERVATION_SWEEP_INTERVAL", time.Minute),
+			Run:      reservationService.ReleaseExpired,
+		},
+		{Name: "abandoned-cart-reaper", Interval: time.Hour, Run: cartService.DeleteAbandoned},
+	}
+	if app.Getenv("RATE_LIMIT_ENABLED", "true") != "false" {
+		window := app.GetenvDuration("RATE_LIMIT_WINDOW", service.DefaultRateLimitWindow)
+		quotaService := service.NewQuotaService(
+			repository.NewQuotaRepository(db),
+			app.GetenvInt("RATE_LIMIT_REQUESTS", service.DefaultRateLimitRequests),
+			window,
+		)
+		routerConfig.Quotas = quotaService
+		tasks = append(tasks, scheduler.Task{Name: "api-usage-pruner", Interval: window, Run: quotaService.PruneUsage})
+	}
+	// Move orders past their retention period to cold storage
+	if archiveService != nil {
+		batchSize := app.GetenvInt("ORDER_ARCHIVE_BATCH_SIZE", 500)
+		tasks = append(tasks, scheduler.Task{
+			Name:     "order-archiver",
+			Interval: app.GetenvDuration("ORDER_ARCHIVE_INTERVAL", time.Hour),
+			Run: func(ctx context.Context) error {
+				return archiveService.ArchiveBatch(ctx, batchSize)
+			},
+		})
+	}
+	if interval := app.GetenvDuration("DUAL_WRITE_VERIFY_INTERVAL", 0); mirror != nil && interval > 0 {
+		tasks = append(tasks, scheduler.Task{Name: "dual-write-verifier", Interval: interval, Run: mirror.VerifyFunc(mirroredTables)})
+	}
+	// Keep the valid coupons view fresh after loads and on a schedule
+	validCoupons := matview.New(repository.NewMatviewRepository(db), repository.ValidCouponsView, "valid-coupons-refresher", instance.Get().ID)
+	tasks = append(tasks, scheduler.Task{
+		Name:     "valid-coupons-refresher",
+		Interval: app.GetenvDuration("MATVIEW_REFRESH_INTERVAL", time.Hour),
+		Run:      validCoupons.Refresh,
+	})
+	// Push each day's orders and refunds to the accounting system
+	orderExportService, err := newOrderExportService(db, zones.Default())
+	if err != nil {
+		return err
+	}
+	if orderExportService != nil {
+		tasks = append(tasks, scheduler.Task{
+			Name:     "order-exporter",
+			Interval: app.GetenvDuration("ORDER_EXPORT_INTERVAL", time.Hour),
+			Run:      orderExportService.Run,
+		})
+	}
+	// Look for orphaned, unpriced and half-loaded data
+	dataQualityService := service.NewDataQualityService(repository.NewDataQualityRepository(db))
+	tasks = append(tasks, scheduler.Task{
+		Name:     service.DataQualityTask,
+		Interval: app.GetenvDuration("DATA_QUALITY_INTERVAL", 6*time.Hour),
+		Run:      dataQualityService.Run,
+	})
+	taskScheduler := scheduler.New(repository.NewScheduledTaskRepository(db), instance.Get().ID, tasks...)
+	runInBackground(ctx, a, "task scheduler", taskScheduler.Run)
+	invalidationService.Subscribe(models.InvalidationTopicCoupons, validCoupons.RefreshOnLoad(taskScheduler.Trigger))
+
+	// Rebuild the search index and caches on request, as after a large load
+	maintenanceRepo := repository.NewMaintenanceRepository(db)
+	maintenanceService := service.NewMaintenanceService(maintenanceRepo, instance.Get().ID,
+		service.MaintenanceStep{Name: "searchIndex", Run: maintenanceRepo.RebuildProductSearch},
+		service.MaintenanceStep{Name: "materializedViews", Run: func(ctx context.Context) error {
+			return taskScheduler.RunNow(ctx, "valid-coupons-refresher")
+		}},
+		service.MaintenanceStep{Name: "caches", Run: func(ctx context.Context) error {
+			// Every replica drops its cached products; this one does so
+			// before warming up again
+			if err := invalidationService.Publish(models.InvalidationTopicProducts, "*"); err != nil {
+				return err
+			}
+			if _, err := invalidationService.Poll(); err != nil {
+				return err
+			}
+			return warmer.Rewarm(ctx)
+		}},
+	)
+	runInBackground(ctx, a, "maintenance jobs", maintenanceService.Run)
+
+	// Alert when orders stop arriving at the usual rate
+	orderVolumeService := service.NewOrderVolumeService(orderRepo, service.OrderVolumeConfig{
+		Window:       app.GetenvDuration("ORDER_VOLUME_WINDOW", service.DefaultOrderVolumeConfig.Window),
+		BaselineDays: app.GetenvInt("ORDER_VOLUME_BASELINE_DAYS", service.DefaultOrderVolumeConfig.BaselineDays),
+		DropPercent:  app.GetenvInt("ORDER_VOLUME_DROP_PERCENT", service.DefaultOrderVolumeConfig.DropPercent),
+		MinExpected:  app.GetenvInt("ORDER_VOLUME_MIN_EXPECTED", service.DefaultOrderVolumeConfig.MinExpected),
+	})
+	runInBackground(ctx, a, "order volume checks", func(ctx context.Context) {
+		orderVolumeService.Run(ctx, app.GetenvDuration("ORDER_VOLUME_CHECK_INTERVAL", time.Minute))
+	})
+
+	metrics := []prometheus.Collector{taskScheduler, orderVolumeService, failover, queryLog, validCoupons, promoCodeService, deprecations}
+	if mirror != nil {
+		metrics = append(metrics, mirror)
+	}
+	if couponFilter != nil {
+		metrics = append(metrics, couponFilter)
+	}
+	if orderExportService != nil {
+		metrics = append(metrics, orderExportService)
+	}
+	if eventService != nil {
+		metrics = append(metrics, eventService)
+	}
+	if sharedProductCache != nil {
+		metrics = append(metrics, sharedProductCache)
+	}
+	if productListCache != nil {
+		metrics = append(metrics, productListCache)
+	}
+	if shadowTraffic != nil {
+		metrics = append(metrics, shadowTraffic)
+	}
+	if orderLanes != nil {
+		metrics = append(metrics, orderLanes)
+	}
+	if couponGuard != nil {
+		metrics = append(metrics, couponGuard)
+	}
+	versionHandler := handler.NewVersionHandler(buildinfo.Get(), instance.Get(), metrics...)
+	taskHandler := handler.NewTaskHandler(taskScheduler)
+	matviewHandler := handler.NewMatviewHandler([]service.MatviewServiceInterface{validCoupons}, taskScheduler)
+	orderVolumeHandler := handler.NewOrderVolumeHandler(orderVolumeService)
+	dataQualityHandler := handler.NewDataQualityHandler(dataQualityService)
+	configHandler := handler.NewConfigHandler(environment, region, promoCodeService)
+	maintenanceHandler := handler.NewMaintenanceHandler(maintenanceService)
+
+	api := router.NewAPI(
+		router.Handlers{
+			Product:         productHandler,
+			Category:        categoryHandler,
+			Order:           orderHandler,
+			Health:          healthHandler,
+			Partner:         partnerHandler,
+			CouponGuard:     couponGuardHandler,
+			Version:         versionHandler,
+			CouponAnalytics: couponAnalyticsHandler,
+			Reservation:     reservationHandler,
+			Cart:            cartHandler,
+			Pricing:         pricingHandler,
+			Stock:           stockHandler,
+			Root:            rootHandler,
+			CouponFile:      couponFileHandler,
+			Campaign:        campaignHandler,
+			Operation:       operationHandler,
+			PromoCode:       promoCodeHandler,
+			Task:            taskHandler,
+			Matview:         matviewHandler,
+			OrderVolume:     orderVolumeHandler,
+			DataQuality:     dataQualityHandler,
+			Config:          configHandler,
+			Maintenance:     maintenanceHandler,
+			Customer:        customerHandler,
+		},
+		routerConfig,
+	)
+	warnUnknownDeprecations(api.Endpoints(), deprecations)
+	r, err := newHTTPHandler(api)
+	if err != nil {
+		log.Fatalf("Failed to set up the router: %v", err)
+	}
+
+	// Drop cached products, update the coupon filter and refresh the valid
+	// coupons view when any replica or the load job changes them
+	runInBackground(ctx, a, "cache invalidation", func(ctx context.Context) {
+		invalidationService.Run(ctx, invalidationInterval)
+	})
+
+	// Poll the outbox as soon as an event is committed instead of waiting
+	// for the next interval
+	if listener := newListener(failover); listener != nil {
+		listener.Subscribe(models.NotifyChannelCacheInvalidations, func(string) { invalidationService.Notify() })
+		listener.OnResync(invalidationService.Notify)
+		runInBackground(ctx, a, "database notifications", listener.Run)
+	}
+
+	// The server starts straight away so liveness probes pass, but /ready
+	// answers 503 until the warm-up has finished
+	runInBackground(ctx, a, "warm-up", warmer.Run)
 
 	// Start server
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+	a.OnShutdown("http server", srv.Shutdown)
+
+	serverErr := make(chan error, 1)
+	go func() { serverErr <- srv.ListenAndServe() }()
+
 	log.Printf("Server is running on port %s", port)
-	log.Printf("Health check: http://localhost:%s/health", port)
+	log.Printf("Health check: http://localhost:%s/health (add ?verbose=true for dependencies)", port)
 	log.Printf("API endpoint: http://localhost:%s/api/v1", port)
 	log.Printf("Products: http://localhost:%s/api/v1/products", port)
 	log.Printf("Create Order: POST http://localhost:%s/api/v1/orders (requires api_key: apitest)", port)
 
-	// Graceful shutdown
+	// Run until a shutdown signal arrives or the server fails
+	select {
+	case <-ctx.Done():
+		log.Println("Shutting down server...")
+		return nil
+	case err := <-serverErr:
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+}
+
+// newListener creates a listener for database notifications on a
+// connection of its own, or nil when DB_LISTEN_ENABLED is false. LISTEN
+// needs a session, so it is not available through a transaction-pooling
+// proxy.
+func newListener(failover *database.Failover) *repository.Listener {
+	if app.Getenv("DB_LISTEN_ENABLED", "true") == "false" {
+		return nil
+	}
+	if mode, _ := database.ParsePoolMode(app.Getenv("DB_POOL_MODE", "")); mode == database.PoolModeTransaction {
+		log.Println("Not listening for database notifications in transaction pool mode; falling back to polling")
+		return nil
+	}
+	return repository.NewListener(failover.DSN)
+}
+
+// runInBackground runs fn in a goroutine and makes shutdown wait for it to
+// return once ctx is cancelled
+func runInBackground(ctx context.Context, a *app.App, name string, fn func(ctx context.Context)) {
+	done := make(chan struct{})
 	go func() {
-		if err := r.Run(":" + port); err != nil {
-			log.Fatalf("Failed to start server: %v", err)
-		}
+		defer close(done)
+		fn(ctx)
 	}()
+	a.OnShutdown(name, func(shutdownCtx context.Context) error {
+		select {
+		case <-done:
+			return nil
+		case <-shutdownCtx.Done():
+			return shutdownCtx.Err()
+		}
+	})
+}
+
+// newProductListCache returns the micro-cache of product listings,
+// subscribed to product invalidation events, or nil when
+// PRODUCT_LIST_CACHE_TTL is 0
+func newProductListCache(invalidations *service.InvalidationService) *middleware.MicroCache {
+	ttl := app.GetenvDuration("PRODUCT_LIST_CACHE_TTL", 2*time.Second)
+	if ttl <= 0 {
+		return nil
+	}
+	cache := middleware.NewMicroCache(ttl, app.GetenvDuration("PRODUCT_LIST_CACHE_STALE", 10*time.Second))
+	invalidations.Subscribe(models.InvalidationTopicProducts, cache.Invalidate)
+	return cache
+}
+
+// newProductCache returns the product read cache subscribed to product
+// invalidation events, or nil when PRODUCT_CACHE_TTL is 0
+func newProductCache(invalidations *service.InvalidationService) *productcache.Cache {
+	ttl := app.GetenvDuration("PRODUCT_CACHE_TTL", time.Minute)
+	if ttl <= 0 {
+		return nil
+	}
+	cache := productcache.New(ttl)
+	invalidations.Subscribe(models.InvalidationTopicProducts, cache.Invalidate)
+	return cache
+}
+
+// newRedisProductCache returns the product cache shared by the replicas in
+// the Redis at REDIS_URL, subscribed to product invalidation events, or
+// nil when REDIS_URL is not set
+func newRedisProductCache(invalidations *service.InvalidationService) (*productcache.Redis, error) {
+	redisURL := app.Getenv("REDIS_URL", "")
+	if redisURL == "" {
+		return nil, nil
+	}
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	cache := productcache.NewRedis(redis.NewClient(options),
+		app.Getenv("REDIS_KEY_PREFIX", "order-food:"),
+		app.GetenvDuration("REDIS_PRODUCT_CACHE_TTL", 5*time.Minute),
+		app.GetenvInt("REDIS_PRODUCT_CACHE_PAGES", productcache.DefaultRedisPages),
+		app.GetenvDuration("REDIS_TIMEOUT", 100*time.Millisecond))
+	invalidations.Subscribe(models.InvalidationTopicProducts, cache.Invalidate)
+	log.Printf("Caching products in Redis at %s", options.Addr)
+	return cache, nil
+}
+
+// newCouponFilter returns the coupon filter subscribed to coupon
+// invalidation events, or nil unless COUPON_FILTER_ENABLED is true. It
+// holds the codes in at least minFiles coupon files and is loaded by the
+// warm-up.
+func newCouponFilter(db *sql.DB, invalidations *service.InvalidationService, minFiles int) *couponfilter.Filter {
+	if app.Getenv("COUPON_FILTER_ENABLED", "false") != "true" {
+		return nil
+	}
+	filter := couponfilter.New(repository.NewCouponRepository(db), couponfilter.Config{
+		Snapshot:    app.Getenv("COUPON_FILTER_SNAPSHOT", ""),
+		BitsPerCode: app.GetenvInt("COUPON_FILTER_BITS_PER_CODE", couponfilter.DefaultBitsPerCode),
+		LoadTimeout: app.GetenvDuration("COUPON_FILTER_LOAD_TIMEOUT", time.Hour),
+		MinFiles:    minFiles,
+	})
+	invalidations.Subscribe(models.InvalidationTopicCoupons, filter.HandleInvalidation)
+	return filter
+}
+
+// hotQueries are run on every connection opened during warm-up; between
+// them they touch the tables behind the product listing and promo code
+// validation, which serve most requests
+var hotQueries = []string{
+	`SELECT id, name, price, category_id FROM products ORDER BY id LIMIT 1`,
+	`SELECT COUNT(DISTINCT file_name) FROM coupons WHERE coupon = ''`,
+}
+
+// newWarmer returns the warm-up run before the replica reports ready. The
+// pool keeps WARMUP_CONNECTIONS idle connections so the warmed ones are
+// not closed again. The coupon filter, when enabled, keeps loading in the
+// background if it is not ready by WARMUP_TIMEOUT.
+func newWarmer(db *sql.DB, products *service.ProductService, couponFilter *couponfilter.Filter, perPage int) *warmup.Warmer {
+	connections := app.GetenvInt("WARMUP_CONNECTIONS", 2)
+	if connections > 2 {
+		db.SetMaxIdleConns(connections)
+	}
+
+	steps := []warmup.Step{
+		{Name: "connections", Run: func(ctx context.Context) error {
+			return database.WarmPool(ctx, db, connections, hotQueries...)
+		}},
+		{Name: "productCache", Run: func(ctx context.Context) error {
+			return products.Warm(perPage)
+		}},
+	}
+	if couponFilter != nil {
+		steps = append(steps, warmup.Step{Name: "couponFilter", Run: func(ctx context.Context) error {
+			couponFilter.Reload()
+			return couponFilter.WaitLoaded(ctx)
+		}})
+	}
+	return warmup.New(app.GetenvDuration("WARMUP_TIMEOUT", 30*time.Second), steps...)
+}
+
+// newHealthChecker registers the dependency checks reported by
+// GET /health?verbose=true
+func newHealthChecker(db *sql.DB, productCache *productcache.Cache, sharedProductCache *productcache.Redis, invalidations *service.InvalidationService, invalidationInterval time.Duration) *health.Checker {
+	checker := health.NewChecker(app.GetenvDuration("HEALTH_CHECK_TIMEOUT", health.DefaultTimeout))
+	checker.Register("database", true, db.PingContext)
 
-	// Wait for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	// Reads fall through to the database while Redis is down
+	if sharedProductCache != nil {
+		checker.Register("redis", false, sharedProductCache.Ping)
+	}
+
+	// Cached products go stale when invalidation events stop arriving
+	if productCache != nil || sharedProductCache != nil {
+		maxAge := 3 * invalidationInterval
+		checker.Register("cacheInvalidation", false, func(ctx context.Context) error {
+			last := invalidations.LastPoll()
+			if last.IsZero() {
+				return errors.New("no successful poll yet")
+			}
+			if age := time.Since(last); age > maxAge {
+				return fmt.Errorf("last successful poll %s ago", age.Round(time.Second))
+			}
+			return nil
+		})
+	}
+
+	return checker
+}
+
+// newChaosConfig returns the fault injection settings, or nil unless
+// CHAOS_ENABLED is true. Fault injection is refused in production unless
+// CHAOS_ALLOW_PRODUCTION is also true.
+func newChaosConfig() (*middleware.ChaosConfig, error) {
+	if app.Getenv("CHAOS_ENABLED", "false") != "true" {
+		return nil, nil
+	}
+	if app.Getenv("ENVIRONMENT", "") == "production" && app.Getenv("CHAOS_ALLOW_PRODUCTION", "false") != "true" {
+		slog.Warn("CHAOS_ENABLED is ignored in production; set CHAOS_ALLOW_PRODUCTION=true to override", "environment", "production")
+		return nil, nil
+	}
 
-	log.Println("Shutting down server...")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	rules, err := middleware.ParseChaosRules(app.Getenv("CHAOS_RULES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CHAOS_RULES: %w", err)
+	}
+	cfg := &middleware.ChaosConfig{
+		Rules:        rules,
+		AllowHeaders: app.Getenv("CHAOS_ALLOW_HEADERS", "false") == "true",
+	}
+	slog.Warn("Fault injection is enabled", "rules", len(rules), "allow_headers", cfg.AllowHeaders)
+	return cfg, nil
+}
+
+// newEventValidator returns a validator of the event schemas registered
+// at SCHEMA_REGISTRY_URL, or nil when it is not set. It fails when the
+// payload of an event order-food publishes is incompatible with the
+// registered schema.
+func newEventValidator(ctx context.Context) (*eventschema.Validator, error) {
+	registryURL := app.Getenv("SCHEMA_REGISTRY_URL", "")
+	if registryURL == "" {
+		return nil, nil
+	}
+	client, err := eventschema.NewClient(registryURL, app.Getenv("SCHEMA_REGISTRY_USERNAME", ""), app.Getenv("SCHEMA_REGISTRY_PASSWORD", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCHEMA_REGISTRY_URL: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
+	validator, err := eventschema.Load(ctx, client, eventschema.Events)
+	if err != nil {
+		return nil, fmt.Errorf("event schemas rejected by the schema registry: %w", err)
+	}
+	log.Printf("Validating event payloads against %d schemas registered at %s", len(eventschema.Events), registryURL)
+	return validator, nil
+}
+
+// newEventService returns a service publishing domain events to the
+// broker at EVENT_BROKER_URL, with the connection to it, or nil when it is
+// not set
+func newEventService() (*service.EventService, events.Broker, error) {
+	brokerURL := app.Getenv("EVENT_BROKER_URL", "")
+	if brokerURL == "" {
+		return nil, nil, nil
+	}
+	broker, err := events.NewBroker(brokerURL, "order-food-"+instance.Get().ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid EVENT_BROKER_URL: %w", err)
+	}
+	prefix := app.Getenv("EVENT_TOPIC_PREFIX", "order-food")
+	queueSize := app.GetenvInt("EVENT_QUEUE_SIZE", service.DefaultEventQueueSize)
+	log.Printf("Publishing order and product events to %s.orders and %s.products", prefix, prefix)
+	return service.NewEventService(broker, prefix, queueSize), broker, nil
+}
+
+// newPaymentProvider returns the provider named by PAYMENT_PROVIDER that
+// orders are charged through, or nil when it is not set. The mock provider
+// is refused in production, where it would accept orders without charging
+// them.
+func newPaymentProvider() (payment.Provider, error) {
+	switch name := app.Getenv("PAYMENT_PROVIDER", ""); name {
+	case "":
+		return nil, nil
+	case "mock":
+		if app.Getenv("ENVIRONMENT", "") == "production" {
+			return nil, fmt.Errorf("PAYMENT_PROVIDER=mock cannot be used in production")
+		}
+		slog.Warn("Orders are charged through the mock payment provider; no money is taken", "provider", "mock")
+		return payment.NewMock(), nil
+	default:
+		return nil, fmt.Errorf("invalid PAYMENT_PROVIDER %q: expected mock", name)
+	}
+}
+
+// newDeprecationRegistry returns the routes and fields listed in
+// API_DEPRECATIONS as deprecated
+func newDeprecationRegistry() (*deprecation.Registry, error) {
+	deprecations, err := deprecation.Parse(app.Getenv("API_DEPRECATIONS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid API_DEPRECATIONS: %w", err)
+	}
+	registry, err := deprecation.NewRegistry(deprecations)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API_DEPRECATIONS: %w", err)
+	}
+	return registry, nil
+}
+
+// newShadowTraffic returns the mirror of API reads to the deployment at
+// SHADOW_TRAFFIC_URL, or nil when it is not set. SHADOW_TRAFFIC_RATES sets
+// the fraction of reads mirrored per caller and SHADOW_TRAFFIC_ROUTES the
+// routes mirrored, separated by semicolons.
+func newShadowTraffic() (*middleware.ShadowTraffic, error) {
+	target := app.Getenv("SHADOW_TRAFFIC_URL", "")
+	if target == "" {
+		return nil, nil
+	}
+	targetURL, err := url.Parse(target)
+	if err != nil || targetURL.Host == "" {
+		return nil, fmt.Errorf("invalid SHADOW_TRAFFIC_URL %q", target)
+	}
+	rates, err := middleware.ParseShadowRates(app.Getenv("SHADOW_TRAFFIC_RATES", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SHADOW_TRAFFIC_RATES: %w", err)
+	}
+	rate := rates["*"]
+	delete(rates, "*")
 
-	// Cleanup
-	log.Println("Server stopped")
-	_ = ctx // Use context if needed for cleanup
+	var routes []string
+	for _, route := range strings.Split(app.Getenv("SHADOW_TRAFFIC_ROUTES", ""), ";") {
+		if route = strings.TrimSpace(route); route != "" {
+			routes = append(routes, route)
+		}
+	}
+	log.Printf("Mirroring %g of API reads (%d callers overridden) to %s", rate, len(rates), targetURL.Redacted())
+	return middleware.NewShadowTraffic(middleware.ShadowConfig{
+		Target:      httputil.NewSingleHostReverseProxy(targetURL),
+		Routes:      routes,
+		Rate:        rate,
+		KeyRates:    rates,
+		MaxInFlight: app.GetenvInt("SHADOW_TRAFFIC_MAX_IN_FLIGHT", 0),
+	}), nil
 }
 
-func connectDB() (*sql.DB, error) {
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbUser := getEnv("DB_USER", "postgres")
-	dbPassword := getEnv("DB_PASSWORD", "postgres")
-	dbName := getEnv("DB_NAME", "orderfood")
-	dbSSLMode := getEnv("DB_SSLMODE", "disable")
+// newOrderLanes returns the lanes admitting order creation, or nil when
+// ORDER_LANES_ENABLED is false. Partners and public traffic each get their
+// own ORDER_LANE_<LANE>_* budget.
+func newOrderLanes() *middleware.OrderLanes {
+	if app.Getenv("ORDER_LANES_ENABLED", "true") == "false" {
+		return nil
+	}
+	cfg := middleware.OrderLanesConfig{
+		Partner: middleware.LaneConfig{
+			MaxInFlight: app.GetenvInt("ORDER_LANE_PARTNER_MAX_IN_FLIGHT", 32),
+			MaxQueued:   app.GetenvInt("ORDER_LANE_PARTNER_MAX_QUEUED", 64),
+			MaxWait:     app.GetenvDuration("ORDER_LANE_PARTNER_MAX_WAIT", 5*time.Second),
+		},
+		Public: middleware.LaneConfig{
+			MaxInFlight: app.GetenvInt("ORDER_LANE_PUBLIC_MAX_IN_FLIGHT", 24),
+			MaxQueued:   app.GetenvInt("ORDER_LANE_PUBLIC_MAX_QUEUED", 48),
+			MaxWait:     app.GetenvDuration("ORDER_LANE_PUBLIC_MAX_WAIT", 2*time.Second),
+		},
+	}
+	log.Printf("Order lanes: partners %d at once, public %d at once", cfg.Partner.MaxInFlight, cfg.Public.MaxInFlight)
+	return middleware.NewOrderLanes(cfg)
+}
 
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		dbHost, dbPort, dbUser, dbPassword, dbName, dbSSLMode)
+// newHTTPHandler serves api on the router HTTP_ROUTER names: gin, the
+// default, or std for net/http's ServeMux
+func newHTTPHandler(api *router.API) (http.Handler, error) {
+	switch name := app.Getenv("HTTP_ROUTER", "gin"); name {
+	case "gin":
+		return ginrouter.New(api), nil
+	case "std":
+		log.Printf("Serving the API with net/http")
+		return router.NewHandler(api), nil
+	default:
+		return nil, fmt.Errorf("unknown HTTP_ROUTER %q, expected gin or std", name)
+	}
+}
 
-	db, err := sql.Open("postgres", connStr)
+// warnUnknownDeprecations warns about deprecated routes the router does not
+// serve, which are never announced
+func warnUnknownDeprecations(routes []httpx.Route, registry *deprecation.Registry) {
+	served := make(map[string]bool)
+	for _, route := range routes {
+		served[route.Method+" "+route.Path] = true
+	}
+	for _, d := range registry.Deprecations() {
+		if !served[d.Method+" "+d.Route] {
+			slog.Warn("API_DEPRECATIONS lists an element that is not a route", "deprecation", d.Name())
+		}
+	}
+}
+
+// newArchiveService returns the order archiver configured from the
+// environment, or nil when neither ORDER_ARCHIVE_DIR nor
+// ORDER_ARCHIVE_BUCKET is set
+func newArchiveService(orderRepo *repository.OrderRepository) *service.ArchiveService {
+	store, location, err := newArchiveStore()
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		log.Fatalf("Failed to open order archive: %v", err)
+	}
+	if store == nil {
+		return nil
+	}
+
+	retention := app.GetenvDuration("ORDER_RETENTION", 365*24*time.Hour)
+	log.Printf("Archiving orders older than %s to %s", retention, location)
+	return service.NewArchiveService(orderRepo, store, retention)
+}
+
+// newArchiveStore returns the archive store configured from the
+// environment and where it keeps objects: a directory with
+// ORDER_ARCHIVE_DIR or an S3 bucket with ORDER_ARCHIVE_BUCKET. The store is
+// nil when neither is set.
+func newArchiveStore() (archive.Store, string, error) {
+	dir := app.Getenv("ORDER_ARCHIVE_DIR", "")
+	bucket := app.Getenv("ORDER_ARCHIVE_BUCKET", "")
+	switch {
+	case dir != "" && bucket != "":
+		return nil, "", fmt.Errorf("ORDER_ARCHIVE_DIR and ORDER_ARCHIVE_BUCKET are both set")
+	case dir != "":
+		store, err := archive.NewFileStore(dir)
+		return store, dir, err
+	case bucket == "":
+		return nil, "", nil
+	}
+
+	u, err := url.Parse(bucket)
+	if err != nil || u.Scheme != "s3" || u.Host == "" {
+		return nil, "", fmt.Errorf("invalid ORDER_ARCHIVE_BUCKET %q, want s3://bucket/prefix", bucket)
+	}
+	accessKeyID, secretAccessKey := app.Getenv("AWS_ACCESS_KEY_ID", ""), app.Getenv("AWS_SECRET_ACCESS_KEY", "")
+	if accessKeyID == "" || secretAccessKey == "" {
+		return nil, "", fmt.Errorf("ORDER_ARCHIVE_BUCKET needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	creds := credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, app.Getenv("AWS_SESSION_TOKEN", ""))
+	client, err := archive.NewS3Client(app.Getenv("AWS_REGION", ""), app.Getenv("ORDER_ARCHIVE_S3_ENDPOINT", ""), creds)
+	if err != nil {
+		return nil, "", err
+	}
+	store, err := archive.NewS3Store(client, u.Host, u.Path)
+	return store, bucket, err
+}
+
+// newOrderExportService returns the accounting export configured from the
+// environment, or nil when ORDER_EXPORT_DESTINATION is not set
+func newOrderExportService(db *sql.DB, zone *time.Location) (*service.OrderExportService, error) {
+	target := app.Getenv("ORDER_EXPORT_DESTINATION", "")
+	if target == "" {
+		return nil, nil
+	}
+
+	destination, err := export.NewDestination(target, export.DestinationOptions{
+		SFTPKeyFile:        app.Getenv("ORDER_EXPORT_SFTP_KEY_FILE", ""),
+		SFTPKnownHostsFile: app.Getenv("ORDER_EXPORT_SFTP_KNOWN_HOSTS", ""),
+		S3Region:           app.Getenv("AWS_REGION", ""),
+		S3Endpoint:         app.Getenv("ORDER_EXPORT_S3_ENDPOINT", ""),
+		S3Credentials: export.S3Credentials{
+			AccessKeyID:     app.Getenv("AWS_ACCESS_KEY_ID", ""),
+			SecretAccessKey: app.Getenv("AWS_SECRET_ACCESS_KEY", ""),
+			SessionToken:    app.Getenv("AWS_SESSION_TOKEN", ""),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid ORDER_EXPORT_DESTINATION: %w", err)
+	}
+
+	cfg := service.OrderExportConfig{
+		Destination:  destination,
+		Zone:         zone,
+		LookbackDays: app.GetenvInt("ORDER_EXPORT_LOOKBACK_DAYS", service.DefaultOrderExportLookbackDays),
+		Retries:      app.GetenvInt("ORDER_EXPORT_RETRIES", service.DefaultOrderExportRetries),
+		RetryDelay:   app.GetenvDuration("ORDER_EXPORT_RETRY_DELAY", service.DefaultOrderExportRetryDelay),
+	}
+	if keyFile := app.Getenv("ORDER_EXPORT_PGP_KEY_FILE", ""); keyFile != "" {
+		if cfg.Encrypter, err = export.NewEncrypterFromFile(keyFile); err != nil {
+			return nil, fmt.Errorf("invalid ORDER_EXPORT_PGP_KEY_FILE: %w", err)
+		}
+	} else {
+		log.Println("ORDER_EXPORT_PGP_KEY_FILE is not set; accounting exports are not encrypted")
+	}
+
+	log.Printf("Exporting orders and refunds for accounting to %s", target)
+	return service.NewOrderExportService(repository.NewOrderExportRepository(db), cfg), nil
+}
+
+// newCustomerService returns the customer account service signing access
+// tokens with JWT_SECRETS, or nil when it is not set. Customer emails and
+// names are sealed with codec, and customers stored before that are sealed
+// first.
+func newCustomerService(db *sql.DB, codec pii.Codec) (*service.CustomerService, error) {
+	spec := app.Getenv("JWT_SECRETS", "")
+	if spec == "" {
+		log.Println("JWT_SECRETS is not set; customer accounts are disabled")
+		return nil, nil
+	}
+
+	secrets, err := jwt.ParseSecrets(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_SECRETS: %w", err)
+	}
+	signer := jwt.NewSigner(secrets, app.GetenvDuration("JWT_TTL", jwt.DefaultTTL))
+
+	customerRepo := repository.NewCustomerRepository(db, codec, newPIIBlindIndex(codec))
+	count, err := customerRepo.BackfillEmailHashes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal customers after %d: %w", count, err)
+	}
+	if count > 0 {
+		log.Printf("Sealed the email and name of %d customers", count)
+	}
+	return service.NewCustomerService(customerRepo, signer), nil
+}
+
+// newPIICodec returns the codec protecting personal data at rest. Values
+// are encrypted when PII_ENCRYPTION_KEYS is set and stored in plaintext
+// otherwise.
+func newPIICodec() pii.Codec {
+	cipher := newPIICipher()
+	if cipher == nil {
+		log.Println("PII_ENCRYPTION_KEYS is not set; personal data is stored unencrypted")
+		return pii.Plaintext{}
+	}
+	return cipher
+}
+
+// newPIIBlindIndex returns the blind index customers are looked up by email
+// with, keyed with PII_BLIND_INDEX_KEY. The key is required once personal
+// data is encrypted with codec; without encryption the index is unkeyed.
+func newPIIBlindIndex(codec pii.Codec) *pii.BlindIndex {
+	key := app.Getenv("PII_BLIND_INDEX_KEY", "")
+	if key == "" {
+		if _, encrypted := codec.(*pii.Cipher); encrypted {
+			log.Fatal("PII_BLIND_INDEX_KEY must be set with PII_ENCRYPTION_KEYS")
+		}
+		return pii.NewBlindIndex(nil)
+	}
+
+	index, err := pii.ParseBlindIndexKey(key)
+	if err != nil {
+		log.Fatalf("Invalid PII_BLIND_INDEX_KEY: %v", err)
+	}
+	return index
+}
+
+// newPIICipher builds the PII cipher from PII_ENCRYPTION_KEYS, or returns
+// nil when it is not set
+func newPIICipher() *pii.Cipher {
+	spec := app.Getenv("PII_ENCRYPTION_KEYS", "")
+	if spec == "" {
+		return nil
+	}
+
+	keys, err := pii.ParseKeys(spec)
+	if err != nil {
+		log.Fatalf("Invalid PII_ENCRYPTION_KEYS: %v", err)
+	}
+	return pii.NewCipher(keys)
+}
+
+// queryLog counts the statements run on the pool opened by openDB and logs
+// the slow ones
+var queryLog *database.QueryLog
+
+// openDB builds the connection pool from the environment without
+// connecting
+func openDB() (*sql.DB, *database.Failover, database.PoolMode, error) {
+	poolMode, err := database.ParsePoolMode(app.Getenv("DB_POOL_MODE", ""))
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("invalid DB_POOL_MODE: %w", err)
+	}
+
+	cfg := database.Config{
+		Host:            app.Getenv("DB_HOST", "localhost"),
+		Port:            app.Getenv("DB_PORT", "5432"),
+		DBName:          app.Getenv("DB_NAME", "orderfood"),
+		SSLMode:         app.Getenv("DB_SSLMODE", "disable"),
+		ConnMaxLifetime: app.GetenvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		PoolMode:        poolMode,
+	}
+	queryLog = database.NewQueryLog(app.GetenvDuration("DB_SLOW_QUERY_THRESHOLD", database.DefaultSlowQueryThreshold))
+	cfg.Queries = queryLog
+
+	creds, err := credentialProvider(cfg)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	db, failover, err := database.Open(cfg, creds)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("invalid DB_HOST: %w", err)
+	}
+	return db, failover, poolMode, nil
+}
+
+func connectDB() (*sql.DB, *database.Failover, error) {
+	db, failover, poolMode, err := openDB()
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Test connection with retries
@@ -101,18 +1097,43 @@ func connectDB() (*sql.DB, error) {
 	for i := 0; i < 10; i++ {
 		if err := db.PingContext(ctx); err == nil {
 			log.Println("Successfully connected to database")
-			return db, nil
+			checkPoolMode(ctx, db, poolMode)
+			return db, failover, nil
 		}
 		log.Printf("Waiting for database connection... (attempt %d/10)", i+1)
 		time.Sleep(2 * time.Second)
 	}
 
-	return nil, fmt.Errorf("failed to connect to database after retries")
+	db.Close()
+	return nil, nil, fmt.Errorf("failed to connect to database after retries")
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// checkPoolMode warns when the configured pool mode does not match what
+// the connection looks like
+func checkPoolMode(ctx context.Context, db *sql.DB, mode database.PoolMode) {
+	pooled, err := database.DetectTransactionPooler(ctx, db)
+	if err != nil {
+		slog.Warn("Could not check for a connection pooler", "error", err)
+		return
 	}
-	return defaultValue
+
+	switch {
+	case pooled && mode != database.PoolModeTransaction:
+		slog.Warn("Statements on one connection are served by different backends, so a transaction-pooling proxy such as pgbouncer is in use; set DB_POOL_MODE=transaction", "pool_mode", mode)
+	case mode == database.PoolModeTransaction:
+		log.Println("Running in transaction pool mode: session state is not relied on between transactions")
+	}
+}
+
+// credentialProvider returns the source of database credentials selected by
+// DB_CREDENTIALS_PROVIDER, see app.NewCredentialProvider. IAM tokens for
+// RDS are signed for the first host of a replicated cluster.
+func credentialProvider(cfg database.Config) (app.CredentialProvider, error) {
+	endpoints, err := database.ParseEndpoints(cfg.Host, cfg.Port)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_HOST: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return app.NewCredentialProvider(ctx, endpoints[0].Host, endpoints[0].Port)
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+// runReencryptPII implements the reencrypt-pii admin command, which rewrites
+// personal data under the current key after a key rotation
+func runReencryptPII(args []string) error {
+	fs := flag.NewFlagSet("reencrypt-pii", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	cipher := newPIICipher()
+	if cipher == nil {
+		return errors.New("PII_ENCRYPTION_KEYS must be set to re-encrypt personal data")
+	}
+
+	log.Println("Re-encrypting personal data with the current key...")
+
+	db, _, err := connectDB()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.Close()
+
+	partnerRepo := repository.NewPartnerRepository(db, cipher)
+	count, err := partnerRepo.ReencryptContactDetails(cipher)
+	if err != nil {
+		return fmt.Errorf("re-encryption failed after %d partners: %w", count, err)
+	}
+
+	log.Printf("✓ Re-encrypted contact details of %d partners", count)
+
+	orderRepo := repository.NewOrderRepository(db)
+	orderRepo.SetCodec(cipher)
+	count, err = orderRepo.ReencryptDeliveries(cipher)
+	if err != nil {
+		return fmt.Errorf("re-encryption failed after %d delivery addresses: %w", count, err)
+	}
+
+	log.Printf("✓ Re-encrypted %d delivery addresses", count)
+
+	customerRepo := repository.NewCustomerRepository(db, cipher, newPIIBlindIndex(cipher))
+	count, err = customerRepo.ReencryptCustomers(cipher)
+	if err != nil {
+		return fmt.Errorf("re-encryption failed after %d customers: %w", count, err)
+	}
+
+	log.Printf("✓ Re-encrypted %d customers", count)
+	return nil
+}
@@ -0,0 +1,5794 @@
+// Package docs Code generated by swaggo/swag. DO NOT EDIT
+package docs
+
+import "github.com/swaggo/swag"
+
+const docTemplate = `{
+    "schemes": {{ marshal .Schemes }},
+    "swagger": "2.0",
+    "info": {
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
+        "contact": {},
+        "version": "{{.Version}}"
+    },
+    "host": "{{.Host}}",
+    "basePath": "{{.BasePath}}",
+    "paths": {
+        "/api/v1/admin/campaigns": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Generate count unique codes with an optional prefix and charset and make them valid promo codes by writing each under two or more coupon file names. Download the codes from the export link.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Generate a promo code campaign",
+                "parameters": [
+                    {
+                        "description": "Campaign",
+                        "name": "campaign",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CampaignReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Campaign"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A campaign with this name exists",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid code settings",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ValidationErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/campaigns/{campaignId}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a promo code campaign",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Campaign ID",
+                        "name": "campaignId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Campaign"
+                        }
+                    },
+                    "404": {
+                        "description": "Campaign not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/campaigns/{campaignId}/export": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "The codes of the campaign, one per line, in the format of a coupon file",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Download the codes of a campaign",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Campaign ID",
+                        "name": "campaignId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Codes, one per line",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Campaign not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/config": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "The environment and region this replica is deployed to and the promo code policy it validates codes against",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Active deployment configuration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.DeploymentConfig"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/coupon-files": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List coupon file uploads",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "perPage",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PaginatedResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Store a coupon file (one code per line) and queue it for loading. Loading happens asynchronously; poll the returned upload for its status.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Upload a coupon file",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Coupon file named \u003cname\u003e.txt",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CouponFileUpload"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid upload",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Uploads are not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A coupon file with this name exists",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "File too large",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/coupon-files/{uploadId}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Loading status of an uploaded coupon file: pending, processing, loaded or failed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a coupon file upload",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "uploadId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CouponFileUpload"
+                        }
+                    },
+                    "404": {
+                        "description": "Upload not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/coupon-guard": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Counters of this instance since start-up and the clients currently blocked on any instance",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Coupon brute-force protection status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.CouponGuardStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Protection disabled",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/coupon-guard/blocks/{client}": {
+            "delete": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Lift a coupon validation block",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client IP or partner principal",
+                        "name": "client",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Client not blocked",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/coupons/analytics": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Redemption counts, discount given and conversion by code and by coupon file for orders placed in [from, to). Redemptions of archived orders are included; the orders themselves are not.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Promo code analytics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start of the range, RFC 3339 or YYYY-MM-DD (default: 30 days before to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range, exclusive, RFC 3339 or YYYY-MM-DD (default: now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Location code whose time zone dates and days are in (default: BUSINESS_TIMEZONE)",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of codes and files to list (default 20, max 100)",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CouponAnalytics"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid range",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/data-quality": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "The latest result of each data quality check: orphaned order items, products priced at zero, coupon codes in only one file and coupon files whose coupons are missing. Checks run as the data-quality-checker scheduled task; follow the run link to run them now. Status is issues when a check found rows or failed, and unchecked while a check has not run yet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Data quality findings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.DataQualityReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/maintenance/jobs/{jobId}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Status of a maintenance job and of each of its steps: pending, running, done or failed. Any replica can answer.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Follow a maintenance job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "jobId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.MaintenanceJob"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/maintenance/reindex": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Rebuild the product search index, refresh the materialized views and re-warm the caches, as after a large database-load run. The job runs in the background on the replica serving the request; follow the self link to watch its progress.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Rebuild the product search index and caches",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.MaintenanceJob"
+                        }
+                    },
+                    "409": {
+                        "description": "A reindex is already running",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "The replica is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/matviews/{view}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "How stale the view is and how far a refresh under way anywhere in the cluster has got. Progress is estimated from how long the previous refresh took.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get materialized view freshness",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "valid_coupons",
+                        "description": "View name",
+                        "name": "view",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.MatviewStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown view",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/matviews/{view}/refresh": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Queue a refresh of the view on the replica serving the request. Follow the self link to watch its progress.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Refresh a materialized view now",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "valid_coupons",
+                        "description": "View name",
+                        "name": "view",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.MatviewStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown view",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "View is already being refreshed",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/operations/{operationId}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Every pipeline run and coupon file upload recorded under an operation ID. The ID is also the trace ID of the operation.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Follow an operation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Operation ID (32 hex characters)",
+                        "name": "operationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Operation"
+                        }
+                    },
+                    "404": {
+                        "description": "Operation not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/order-volume": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Orders placed in the latest window against the median of the same window on previous days. Status is drop when far fewer orders arrive than expected, and quiet when too few are expected to judge. Served from the latest check of this replica unless refresh is set.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Current vs expected order volume",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Count the orders now instead of returning the latest check",
+                        "name": "refresh",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderVolume"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/partners": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List partners",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by approval status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "perPage",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PaginatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/partners/{partnerId}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Approve a partner",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Partner ID",
+                        "name": "partnerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Partner"
+                        }
+                    },
+                    "404": {
+                        "description": "Partner not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Invalid status transition",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/partners/{partnerId}/keys/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Rotate a partner API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Partner ID",
+                        "name": "partnerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.IssuedAPIKey"
+                        }
+                    },
+                    "404": {
+                        "description": "Partner not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Partner not approved",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/partners/{partnerId}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reject a pending partner",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Partner ID",
+                        "name": "partnerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Partner"
+                        }
+                    },
+                    "404": {
+                        "description": "Partner not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Invalid status transition",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/partners/{partnerId}/suspend": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Suspend an approved partner",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Partner ID",
+                        "name": "partnerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Partner"
+                        }
+                    },
+                    "404": {
+                        "description": "Partner not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Invalid status transition",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/pipeline-runs": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Runs of the database-migration and database-load jobs, newest first. Follow the operation link of a failed run to see the rest of its pipeline.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List pipeline runs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by status: running, succeeded or failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "perPage",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PaginatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/products/bulk-price": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Preview the price changes of a set of rules, e.g. +5% for category Waffle. With apply set the changes are committed in one transaction with an audit log entry, and every replica drops the affected products from its cache.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Bulk update product prices",
+                "parameters": [
+                    {
+                        "description": "Price rules",
+                        "name": "rules",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.BulkPriceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.BulkPriceResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Prices changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid rules",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ValidationErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/products/{productId}/stock": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Returns the units on hand, how many are held by reservations and how many orders can still take. Stock is null for products that do not track it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get the stock of a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ProductStock"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Replace the units on hand, e.g. after a stock count, including units held by reservations. A null stock stops tracking the product, so it never runs out. The change is recorded in the audit log.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set the stock of a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New stock",
+                        "name": "stock",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.StockReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ProductStock"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/products/{productId}/stock/adjustments": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Add units to the stock of a product, e.g. for a delivery, or take them away with a negative delta, e.g. for breakage. The adjustment is applied to the stock at that moment, so concurrent orders are not overwritten, and is recorded in the audit log.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Adjust the stock of a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Units to add or, when negative, take away",
+                        "name": "adjustment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.StockAdjustmentReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ProductStock"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Product does not track stock",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Stock would go below zero",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/promo-codes/{code}/discount": {
+            "put": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Set the percentage or fixed amount a promo code takes off orders, optionally restricted to some categories or products. The code does not have to be loaded yet. Orders already placed keep their discount.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set the discount of a promo code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Promo code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Discount",
+                        "name": "discount",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Discount"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PromoCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid promo code or discount",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "The promo code stays valid but no longer takes anything off orders.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove the discount of a promo code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Promo code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Promo code has no discount",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/promo-codes/{code}/limits": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Returns the usage limits of a promo code and how many orders have used it. Codes without limits have zero values.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get the usage limits of a promo code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Promo code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PromoCode"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid promo code",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Limit how many orders can use a promo code in total, or let each customerId use it once. Zero limits make the code unlimited. Uses made before the limits were set count towards them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set the usage limits of a promo code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Promo code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Usage limits; redemptions is ignored",
+                        "name": "limits",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PromoCodeLimits"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PromoCode"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid promo code or limits",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/tasks": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Recurring maintenance tasks with their latest run anywhere in the cluster. Run counts and the next run time are those of the replica serving the request.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List scheduled tasks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ScheduledTask"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/tasks/{name}/run": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Queue a run of the task on the replica serving the request. The run goes ahead even if the task ran recently, unless another replica is running it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Run a scheduled task now",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown task",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task is already running",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/api/v1": {
+            "get": {
+                "description": "Links to every resource the caller can reach, and the caller's rate limit quota when an API key is sent. Hrefs in braces are URI templates.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discovery"
+                ],
+                "summary": "API root",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIRoot"
+                        }
+                    },
+                    "403": {
+                        "description": "Invalid API key",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/capabilities": {
+            "get": {
+                "description": "Every path of the API, as a URI template, with the methods it allows. The methods do not depend on the caller; authentication and scopes still apply.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "discovery"
+                ],
+                "summary": "API capabilities",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.RouteCapability"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/carts": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Start a cart, optionally with items and a promo code. The response prices it at the current prices and previews the promo code's discount. Keep the returned ID to change the cart and check it out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Create a shopping cart",
+                "parameters": [
+                    {
+                        "description": "Items and promo code",
+                        "name": "cart",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CartReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Cart"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input, unknown product or invalid promo code",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Too many products",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many invalid promo codes",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/carts/{cartId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the cart priced at the current prices with its subtotal, the discount its promo code would give and the total. A promo code that would not apply says why in coupon.message.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Get a shopping cart",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cart ID",
+                        "name": "cartId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Cart"
+                        }
+                    },
+                    "404": {
+                        "description": "Cart not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/carts/{cartId}/checkout": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Place an order with the items and promo code of the cart, at the current prices. A cart becomes one order at most; it cannot change afterwards. Send an Idempotency-Key header to retry safely.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Check out a cart",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cart ID",
+                        "name": "cartId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reservation, customer, delivery and payment method",
+                        "name": "checkout",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CheckoutReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input, unknown product or invalid promo code",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "The payment method was declined; the cart stays open",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PaymentDeclinedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Cart not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cart already checked out or changed, reservation expired, or promo code used up",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Empty cart, insufficient stock, or a product needs options chosen, which carts cannot hold",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ValidationErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many orders are being placed",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/carts/{cartId}/coupon": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Set the promo code to preview on the cart and use at checkout, replacing any other",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Apply a promo code to a cart",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cart ID",
+                        "name": "cartId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Promo code",
+                        "name": "coupon",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CartCouponReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Cart"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid promo code",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Cart not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cart already checked out",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many invalid promo codes",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Remove the promo code from a cart",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cart ID",
+                        "name": "cartId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Cart"
+                        }
+                    },
+                    "404": {
+                        "description": "Cart not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cart already checked out",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/carts/{cartId}/items": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Put a product in the cart; a product already in it gets the quantity added",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Add a product to a cart",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cart ID",
+                        "name": "cartId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Product and quantity",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CartItemReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Cart"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or unknown product",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Cart not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cart already checked out",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Too many products",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/carts/{cartId}/items/{productId}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Change the quantity of a product in a cart",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cart ID",
+                        "name": "cartId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New quantity",
+                        "name": "quantity",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CartQuantityReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Cart"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Cart not found or product not in it",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cart already checked out",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "cart"
+                ],
+                "summary": "Remove a product from a cart",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cart ID",
+                        "name": "cartId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Cart"
+                        }
+                    },
+                    "404": {
+                        "description": "Cart not found or product not in it",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cart already checked out",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/categories": {
+            "get": {
+                "description": "Every category of the menu, ordered by name, with the number of products in it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "category"
+                ],
+                "summary": "List categories",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Category"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Add a category to the menu. Its ID is derived from its name when none is given.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "category"
+                ],
+                "summary": "Add a category",
+                "parameters": [
+                    {
+                        "description": "Category",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CategoryReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Category ID or name already in use",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid category",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/categories/{categoryId}": {
+            "get": {
+                "description": "Returns a single category with the number of products in it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "category"
+                ],
+                "summary": "Find category by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "categoryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Category"
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Replace the name and description of a category. A new name is carried over to the products of the category; price rules and promo code discounts naming the old name are not changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "category"
+                ],
+                "summary": "Replace a category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "categoryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Category",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CategoryReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Category"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Category name already in use",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid category",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Remove a category from the menu. Categories with products, including deleted products kept for past orders, cannot be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "category"
+                ],
+                "summary": "Delete a category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "categoryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Products are in the category",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/categories/{categoryId}/products": {
+            "get": {
+                "description": "Products of the category, paginated like the product listing",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "category"
+                ],
+                "summary": "List the products of a category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "categoryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort fields, prefix with - for descending (id, name, price, category)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to return, from the previous page's nextCursor",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Products per page when paginating by cursor",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Preferred languages of product names and descriptions",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid sort expression or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Category not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/customers": {
+            "post": {
+                "description": "Create a customer account. Log in to get an access token for placing orders and listing them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Register a customer account",
+                "parameters": [
+                    {
+                        "description": "Customer registration",
+                        "name": "customer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CustomerReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already registered",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/customers/login": {
+            "post": {
+                "description": "Exchange an email and password for an access token, sent as \"Authorization: Bearer \u003ctoken\u003e\" until it expires",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Log in as a customer",
+                "parameters": [
+                    {
+                        "description": "Customer credentials",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.LoginReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.AccessToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid email or password",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/customers/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Get own customer account",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Customer"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not a customer",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/customers/{customerId}/orders": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Order history of a customer account, newest first unless sorted. Customers may use \"me\" as their ID and only see their own orders.",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "List the orders of a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of customer, or me",
+                        "name": "customerId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orders placed at or after, RFC 3339 or YYYY-MM-DD (UTC)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Orders placed before, RFC 3339 or YYYY-MM-DD (UTC)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by the promo code applied",
+                        "name": "couponCode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by a product ordered",
+                        "name": "productId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort fields, such as -total",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Orders per page",
+                        "name": "perPage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Orders per page after a cursor",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PaginatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Orders of another customer",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/order": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Place a new order in the store",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "summary": "Place an order",
+                "parameters": [
+                    {
+                        "description": "Order request",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderReq"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Retries with the same key return the original order instead of placing another",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "The payment method was declined; the order is not placed",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PaymentDeclinedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "An expectedUnitPrice is out of date, or the promo code reached its usage limit",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PriceMismatchResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation exception, the promo code does not apply to any item, or the modifiers of an item do not fit its product",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "The promo code cannot be checked while the database is degraded, the payment provider is unavailable, or too many orders are being placed",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/orders/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Translate a legacy POS ticket (XML or JSON) into an order. Imports are idempotent on the POS ticket number.",
+                "consumes": [
+                    "application/json",
+                    "text/xml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "summary": "Import a legacy POS order",
+                "responses": {
+                    "200": {
+                        "description": "Ticket already imported",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Order"
+                        }
+                    },
+                    "201": {
+                        "description": "Order imported",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Promo code reached its usage limit",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported payload format",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Validation exception, or the modifiers of an item do not fit its product",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ValidationErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Too many orders are being placed",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/orders/{orderId}/items": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns a page of the items of an order, each with the product as it was priced. Orders with more than 50 items only link here instead of listing their items inline.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "summary": "List the items of an order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of order",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "perPage",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderLine"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/orders/{orderId}/status": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Move an order through its lifecycle: pending, confirmed, preparing, completed. Orders can be cancelled until they are completed. Setting the current status again is a no-op.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "summary": "Update order status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of order",
+                        "name": "orderId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderStatusReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Order not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transition not allowed from the current status",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown status",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ValidationErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/partners": {
+            "post": {
+                "description": "Create a partner pending admin approval and issue its API key. The key is only returned in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "partner"
+                ],
+                "summary": "Register as a partner",
+                "parameters": [
+                    {
+                        "description": "Partner registration",
+                        "name": "partner",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PartnerReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PartnerRegistration"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/partners/{partnerId}/keys/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Issue a new API key. Previous keys keep working for a grace period.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "partner"
+                ],
+                "summary": "Rotate own partner API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Partner ID",
+                        "name": "partnerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.IssuedAPIKey"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Partner not approved",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products": {
+            "get": {
+                "description": "Get all products available for order. With \"Accept: application/x-ndjson\" every matching product is streamed, one per line, without pagination.",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "List products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated sort fields, prefix with - for descending (id, name, price, category)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the page to return, from the previous page's nextCursor",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Products per page when paginating by cursor",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only products of this category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Only products costing at least this many dollars",
+                        "name": "minPrice",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Only products costing at most this many dollars",
+                        "name": "maxPrice",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Preferred languages of product names and descriptions",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid sort expression or filter",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Add a product to the catalogue. A deleted product can be added again under its old ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Add a product",
+                "parameters": [
+                    {
+                        "description": "Product",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ProductReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Product ID, SKU or barcode already in use",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid product",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/availability": {
+            "post": {
+                "description": "Reports for each item whether an order for it would be accepted now, reading the stock of every product in one query, so a cart can grey out items before checkout. A product listed twice must fit its combined quantity. Nothing is held; reserve stock to be sure of it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Check the availability of cart items",
+                "parameters": [
+                    {
+                        "description": "Products and quantities",
+                        "name": "items",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.AvailabilityReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.HATEOASResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ItemAvailability"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/by-barcode/{code}": {
+            "get": {
+                "description": "Resolves a scanned EAN/UPC barcode to a single product",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Find product by barcode",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Barcode of product to return",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Preferred languages of product names and descriptions",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid barcode supplied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/search": {
+            "get": {
+                "description": "Full-text search over product names, categories and descriptions, best match first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Search products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text; supports quoted phrases, or, and -word to exclude a word",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Preferred languages of product names and descriptions",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Product"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or too long search text",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/{productId}": {
+            "get": {
+                "description": "Returns a single product",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Find product by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of product to return",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Preferred languages of product names and descriptions",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID supplied",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Replace the name, price, category, SKU, barcode and description of a product. Stock, currency prices, translations and option groups are kept.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Replace a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of product to replace",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Product",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ProductReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "SKU or barcode already in use",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid product",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Remove a product from the catalogue. It can no longer be ordered; orders placed for it are not changed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Delete a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID of product to delete",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/products/{productId}/option-groups": {
+            "put": {
+                "security": [
+                    {
+                        "AdminKeyAuth": []
+                    }
+                ],
+                "description": "Replace the choices offered with a product, such as its size or toppings, in menu order. Order items select between minSelect and maxSelect options of each group, and the prices of the options they select are added to the unit price. Option IDs must be unique across the groups of the product. An empty list removes the option groups; orders already placed keep the modifiers they were priced with.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Replace the option groups of a product",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Product ID",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Option groups",
+                        "name": "optionGroups",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OptionGroupsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Product not found",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid option groups",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reservations": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Hold the items for a limited time. Pass the returned ID as reservationId when placing the order; unused reservations lapse at expiresAt.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "summary": "Reserve stock for a checkout",
+                "parameters": [
+                    {
+                        "description": "Items to reserve",
+                        "name": "reservation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ReservationReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Reservation"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Insufficient stock",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ValidationErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/reservations/{reservationId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the items a reservation holds and when it expires. Reservations that expired or were used by an order are not found.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "order"
+                ],
+                "summary": "Get a stock reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "reservationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Reservation"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found or expired",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Give the held stock back before the reservation expires, e.g. when the cart is emptied or checkout is abandoned",
+                "tags": [
+                    "order"
+                ],
+                "summary": "Release a stock reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "reservationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Reservation released"
+                    },
+                    "404": {
+                        "description": "Reservation not found or expired",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Without parameters the check is cheap and does not touch dependencies. With verbose=true every dependency is probed and reported with its latency; the response is 503 when a critical component fails.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Probe dependencies and report component statuses",
+                        "name": "verbose",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "A critical component is failing",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Version, git SHA and build time injected at build time, and the Go version",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Build information",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/buildinfo.Info"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "buildinfo.Info": {
+            "type": "object",
+            "properties": {
+                "buildTime": {
+                    "type": "string"
+                },
+                "gitSha": {
+                    "type": "string"
+                },
+                "goVersion": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_couponguard.Block": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "type": "string"
+                },
+                "until": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_couponguard.Stats": {
+            "type": "object",
+            "properties": {
+                "blocks": {
+                    "type": "integer"
+                },
+                "currentlyBlocked": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "integer"
+                },
+                "failures": {
+                    "type": "integer"
+                },
+                "rejected": {
+                    "type": "integer"
+                },
+                "unblocks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_health.ComponentStatus": {
+            "type": "object",
+            "properties": {
+                "critical": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latencyMs": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_health.Report": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_health.ComponentStatus"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.APIRoot": {
+            "type": "object",
+            "properties": {
+                "principal": {
+                    "description": "Principal is the authenticated caller; empty for anonymous callers",
+                    "type": "string"
+                },
+                "quota": {
+                    "description": "Quota is the caller's rate limit state; nil for anonymous callers or\nwhen rate limiting is disabled",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Quota"
+                        }
+                    ]
+                },
+                "version": {
+                    "type": "string",
+                    "example": "v1"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.AccessToken": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "customer": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Customer"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "tokenType": {
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.AvailabilityItem": {
+            "type": "object",
+            "required": [
+                "productId",
+                "quantity"
+            ],
+            "properties": {
+                "productId": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1,
+                    "example": 2
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.AvailabilityReq": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.AvailabilityItem"
+                    }
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.BulkPriceReq": {
+            "type": "object",
+            "required": [
+                "rules"
+            ],
+            "properties": {
+                "apply": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Supplier price increase"
+                },
+                "rules": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PriceRule"
+                    }
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.BulkPriceResult": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean"
+                },
+                "auditId": {
+                    "description": "AuditID identifies the audit log entry of an applied update",
+                    "type": "integer"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PriceChange"
+                    }
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Campaign": {
+            "type": "object",
+            "properties": {
+                "codeCount": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "codeLength": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "string"
+                },
+                "fileNames": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Summer 2025"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "SUM"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CampaignReq": {
+            "type": "object",
+            "required": [
+                "count",
+                "name"
+            ],
+            "properties": {
+                "charset": {
+                    "description": "Charset holds the upper-case letters and digits codes are drawn\nfrom; a set without easily confused characters when empty",
+                    "type": "string",
+                    "maxLength": 36
+                },
+                "count": {
+                    "type": "integer",
+                    "maximum": 100000,
+                    "minimum": 1,
+                    "example": 1000
+                },
+                "files": {
+                    "description": "Files is how many coupons.file_name values every code is written\nunder; promo codes are only valid in 2 or more",
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 2,
+                    "example": 2
+                },
+                "length": {
+                    "description": "Length of each code including the prefix; 8 when zero",
+                    "type": "integer",
+                    "example": 10
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "Summer 2025"
+                },
+                "prefix": {
+                    "description": "Prefix starts every code and counts towards Length",
+                    "type": "string",
+                    "maxLength": 6,
+                    "example": "SUM"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Cart": {
+            "type": "object",
+            "properties": {
+                "coupon": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CartCoupon"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "discount": {
+                    "type": "number",
+                    "example": 1.3
+                },
+                "id": {
+                    "type": "string",
+                    "example": "7f9c2ba4-e88f-4e0b-9a73-0c6d6a3b2f11"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CartLine"
+                    }
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Link"
+                    }
+                },
+                "orderId": {
+                    "description": "OrderID is the order the cart was checked out as; checked out carts\ncannot change",
+                    "type": "string"
+                },
+                "subtotal": {
+                    "type": "number",
+                    "example": 13
+                },
+                "total": {
+                    "type": "number",
+                    "example": 11.7
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CartCoupon": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "code": {
+                    "type": "string",
+                    "example": "HAPPYHRS"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Promo code does not apply to any item in the cart"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CartCouponReq": {
+            "type": "object",
+            "required": [
+                "couponCode"
+            ],
+            "properties": {
+                "couponCode": {
+                    "type": "string",
+                    "example": "HAPPYHRS"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CartItemReq": {
+            "type": "object",
+            "required": [
+                "productId",
+                "quantity"
+            ],
+            "properties": {
+                "productId": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "1"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1,
+                    "example": 2
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CartLine": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "example": "Waffle"
+                },
+                "discount": {
+                    "description": "Discount is the part of the cart discount taken off this line",
+                    "type": "number",
+                    "example": 1.3
+                },
+                "lineTotal": {
+                    "type": "number",
+                    "example": 13
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Waffle with Berries"
+                },
+                "productId": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "unitPrice": {
+                    "type": "number",
+                    "example": 6.5
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CartQuantityReq": {
+            "type": "object",
+            "required": [
+                "quantity"
+            ],
+            "properties": {
+                "quantity": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1,
+                    "example": 3
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CartReq": {
+            "type": "object",
+            "properties": {
+                "couponCode": {
+                    "type": "string",
+                    "example": "HAPPYHRS"
+                },
+                "items": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CartItemReq"
+                    }
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Category": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Belgian waffles, sweet and savoury"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "waffle"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Waffle"
+                },
+                "productCount": {
+                    "description": "ProductCount is the number of products in the category that are not\ndeleted",
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CategoryReq": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 2000,
+                    "example": "Belgian waffles, sweet and savoury"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "waffle"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Waffle"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CheckoutReq": {
+            "type": "object",
+            "properties": {
+                "customerId": {
+                    "description": "CustomerID identifies the customer in the calling system; promo\ncodes limited to one use per customer require it",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "delivery": {
+                    "description": "Delivery is where and to whom the order is delivered; orders\ncollected in store have none",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Delivery"
+                        }
+                    ]
+                },
+                "paymentMethod": {
+                    "description": "PaymentMethod is the customer's card or wallet at the payment provider",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "pm_card_visa"
+                },
+                "reservationId": {
+                    "description": "ReservationID converts a stock reservation made for this checkout",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CouponAnalytics": {
+            "type": "object",
+            "properties": {
+                "byCode": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CouponCodeStats"
+                    }
+                },
+                "byDay": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CouponDayStats"
+                    }
+                },
+                "byFile": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CouponFileStats"
+                    }
+                },
+                "discountTotal": {
+                    "description": "DiscountTotal is the discount promo codes took off those orders",
+                    "type": "number"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "orders": {
+                    "description": "Orders is the number of orders placed in the range",
+                    "type": "integer"
+                },
+                "redemptionRate": {
+                    "description": "RedemptionRate is Redemptions divided by Orders",
+                    "type": "number"
+                },
+                "redemptions": {
+                    "description": "Redemptions is the number of orders in the range that used a promo\ncode, including those archived since",
+                    "type": "integer"
+                },
+                "timeZone": {
+                    "description": "TimeZone is the zone ByDay is bucketed in",
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CouponCodeStats": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "conversionRate": {
+                    "description": "ConversionRate is the share of all orders in the range that used the code",
+                    "type": "number"
+                },
+                "discountTotal": {
+                    "type": "number"
+                },
+                "redemptions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CouponDayStats": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "Date is the day in YYYY-MM-DD form",
+                    "type": "string"
+                },
+                "orders": {
+                    "type": "integer"
+                },
+                "redemptions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CouponFileStats": {
+            "type": "object",
+            "properties": {
+                "conversionRate": {
+                    "description": "ConversionRate is the share of all orders in the range that used a code from the file",
+                    "type": "number"
+                },
+                "discountTotal": {
+                    "type": "number"
+                },
+                "fileName": {
+                    "type": "string"
+                },
+                "redeemedCodes": {
+                    "type": "integer"
+                },
+                "redemptions": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CouponFileUpload": {
+            "type": "object",
+            "properties": {
+                "couponsLoaded": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fileName": {
+                    "type": "string",
+                    "example": "couponbase4.txt"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c"
+                },
+                "operationId": {
+                    "description": "OperationID follows the upload through the loader, see /admin/operations",
+                    "type": "string",
+                    "example": "4bf92f3577b34da6a3ce929d0e0e4736"
+                },
+                "sizeBytes": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "uploadedBy": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CursorMeta": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "nextCursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Customer": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CustomerReq": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 254
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 8
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.DataQualityFinding": {
+            "type": "object",
+            "properties": {
+                "check": {
+                    "type": "string",
+                    "example": "zeroPriceProducts"
+                },
+                "checkedAt": {
+                    "description": "CheckedAt is when the check last ran; it is left out until it has",
+                    "type": "string"
+                },
+                "count": {
+                    "description": "Count is how many rows the check found",
+                    "type": "integer",
+                    "example": 2
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Products on the menu priced at zero"
+                },
+                "durationMs": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "error": {
+                    "description": "Error is why the latest run failed; Count and Samples are then those\nof the run before",
+                    "type": "string"
+                },
+                "samples": {
+                    "description": "Samples identifies up to 10 of the rows found",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "11",
+                        "12"
+                    ]
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.DataQualityReport": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.DataQualityFinding"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "clean",
+                        "issues",
+                        "unchecked"
+                    ]
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Delivery": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.DeliveryAddress"
+                },
+                "contact": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.DeliveryContact"
+                },
+                "instructions": {
+                    "description": "Instructions for the courier, such as a door code",
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.DeliveryAddress": {
+            "type": "object",
+            "required": [
+                "country"
+            ],
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Sydney"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "AU"
+                },
+                "line1": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "1 Market Street"
+                },
+                "line2": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "postalCode": {
+                    "type": "string",
+                    "example": "2000"
+                },
+                "region": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "NSW"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.DeliveryContact": {
+            "type": "object",
+            "required": [
+                "phone"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 254
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Jane Citizen"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+61 2 9999 0000"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.DeploymentConfig": {
+            "type": "object",
+            "properties": {
+                "environment": {
+                    "description": "Environment is the ENVIRONMENT the replica runs in, such as production",
+                    "type": "string",
+                    "example": "production"
+                },
+                "promoCodePolicy": {
+                    "description": "PromoCodePolicy is the rules promo codes are validated against",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PromoCodePolicy"
+                        }
+                    ]
+                },
+                "region": {
+                    "description": "Region is the REGION the replica serves",
+                    "type": "string",
+                    "example": "eu"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Discount": {
+            "type": "object",
+            "required": [
+                "type",
+                "value"
+            ],
+            "properties": {
+                "categories": {
+                    "description": "Categories restricts the discount to items of these product\ncategories; with ProductIDs, an item matching either is discounted",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Waffle"
+                    ]
+                },
+                "productIds": {
+                    "description": "ProductIDs restricts the discount to these products. A discount with\nneither Categories nor ProductIDs applies to the whole order.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "1"
+                    ]
+                },
+                "type": {
+                    "enum": [
+                        "percentage",
+                        "fixed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.DiscountType"
+                        }
+                    ],
+                    "example": "percentage"
+                },
+                "value": {
+                    "type": "number",
+                    "example": 10
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.DiscountType": {
+            "type": "string",
+            "enum": [
+                "percentage",
+                "fixed"
+            ],
+            "x-enum-varnames": [
+                "DiscountTypePercentage",
+                "DiscountTypeFixed"
+            ]
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.HATEOASResponse": {
+            "type": "object",
+            "properties": {
+                "_links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Link"
+                    }
+                },
+                "data": {}
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.IssuedAPIKey": {
+            "type": "object",
+            "properties": {
+                "apiKey": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "partnerId": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "previousKeysExpireAt": {
+                    "description": "PreviousKeysExpireAt is set on rotation to when the replaced keys stop working",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ItemAvailability": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "description": "Available is what orders can still take; null when the product does\nnot track stock or does not exist",
+                    "type": "integer",
+                    "example": 37
+                },
+                "orderable": {
+                    "description": "Orderable is whether an order for the item would be accepted",
+                    "type": "boolean",
+                    "example": true
+                },
+                "productId": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "reason": {
+                    "description": "Reason is why the item cannot be ordered, not_found or\ninsufficient_stock",
+                    "type": "string",
+                    "example": "insufficient_stock"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ItemModifier": {
+            "type": "object",
+            "required": [
+                "optionId"
+            ],
+            "properties": {
+                "group": {
+                    "description": "Group, Name and Price describe the option as it was priced; its price\nis included in the unit price of the item. Only set on responses.",
+                    "type": "string",
+                    "example": "Size"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Large"
+                },
+                "optionId": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "large"
+                },
+                "price": {
+                    "type": "number",
+                    "example": 2.5
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Link": {
+            "type": "object",
+            "properties": {
+                "href": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "rel": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.LoginReq": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.MaintenanceJob": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is why the job failed",
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c"
+                },
+                "instanceId": {
+                    "description": "InstanceID is the replica running the job",
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "reindex"
+                },
+                "operationId": {
+                    "description": "OperationID follows the job in logs and traces, see /admin/operations",
+                    "type": "string",
+                    "example": "4bf92f3577b34da6a3ce929d0e0e4736"
+                },
+                "progress": {
+                    "description": "Progress is the percentage of steps finished",
+                    "type": "integer",
+                    "example": 33
+                },
+                "requestedBy": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "running"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.MaintenanceStep"
+                    }
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.MaintenanceStep": {
+            "type": "object",
+            "properties": {
+                "durationMs": {
+                    "type": "number",
+                    "example": 1520.5
+                },
+                "error": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "searchIndex"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "done"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.MatviewStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error is why the latest refresh failed",
+                    "type": "string"
+                },
+                "instanceId": {
+                    "description": "InstanceID is the replica running, or that ran, the latest refresh",
+                    "type": "string"
+                },
+                "lastDuration": {
+                    "description": "LastDuration is how long the latest successful refresh took, as a Go\nduration",
+                    "type": "string",
+                    "example": "42s"
+                },
+                "progress": {
+                    "description": "Progress estimates how far a refresh under way is in percent, from\nhow long the previous one took; left out when there is none",
+                    "type": "integer",
+                    "example": 40
+                },
+                "refreshedAt": {
+                    "type": "string"
+                },
+                "refreshing": {
+                    "type": "boolean"
+                },
+                "stalenessSeconds": {
+                    "description": "StalenessSeconds is how old the view's data is; left out before the\nfirst refresh",
+                    "type": "number",
+                    "example": 120.5
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "task": {
+                    "description": "Task is the scheduled task refreshing the view",
+                    "type": "string",
+                    "example": "valid-coupons-refresher"
+                },
+                "view": {
+                    "type": "string",
+                    "example": "valid_coupons"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Operation": {
+            "type": "object",
+            "properties": {
+                "couponFiles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CouponFileUpload"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "4bf92f3577b34da6a3ce929d0e0e4736"
+                },
+                "runs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PipelineRun"
+                    }
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OptionGroup": {
+            "type": "object",
+            "required": [
+                "id",
+                "name",
+                "options"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "size"
+                },
+                "maxSelect": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "minSelect": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Size"
+                },
+                "options": {
+                    "type": "array",
+                    "maxItems": 50,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ProductOption"
+                    }
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OptionGroupsReq": {
+            "type": "object",
+            "properties": {
+                "optionGroups": {
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OptionGroup"
+                    }
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Order": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "description": "Archived is set when the order was served from cold storage",
+                    "type": "boolean"
+                },
+                "couponCode": {
+                    "type": "string"
+                },
+                "customerId": {
+                    "description": "CustomerID is the customer account that placed the order; orders\nplaced with an API key have none",
+                    "type": "string"
+                },
+                "delivery": {
+                    "description": "Delivery is only returned for a single order",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Delivery"
+                        }
+                    ]
+                },
+                "discount": {
+                    "description": "Discount is the amount the promo code took off the subtotal; the\nitems it was taken off carry their share",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "itemCount": {
+                    "type": "integer"
+                },
+                "items": {
+                    "description": "Items and Products are left out of responses for orders with more\nitems than are returned inline; ItemCount and an items link are\ngiven instead",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderItem"
+                    }
+                },
+                "payment": {
+                    "description": "Payment is only returned for a single order, and only for orders\ncharged through a payment provider",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Payment"
+                        }
+                    ]
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Product"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderStatus"
+                },
+                "subtotal": {
+                    "description": "Subtotal is the sum of the items before the promo code discount",
+                    "type": "number"
+                },
+                "total": {
+                    "description": "Total is the amount payable, the subtotal minus the discount",
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderItem": {
+            "type": "object",
+            "required": [
+                "productId",
+                "quantity"
+            ],
+            "properties": {
+                "discount": {
+                    "description": "Discount is the part of the order discount taken off this item.\nOnly set on responses.",
+                    "type": "number"
+                },
+                "expectedUnitPrice": {
+                    "description": "ExpectedUnitPrice is the price the customer saw, including the\nmodifiers; the order is refused when the current price differs. Only\nread on requests.",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "modifiers": {
+                    "description": "Modifiers are the options selected from the product's option groups.\nThey apply to every unit of the item.",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ItemModifier"
+                    }
+                },
+                "productId": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderLine": {
+            "type": "object",
+            "required": [
+                "productId",
+                "quantity"
+            ],
+            "properties": {
+                "discount": {
+                    "description": "Discount is the part of the order discount taken off this item.\nOnly set on responses.",
+                    "type": "number"
+                },
+                "expectedUnitPrice": {
+                    "description": "ExpectedUnitPrice is the price the customer saw, including the\nmodifiers; the order is refused when the current price differs. Only\nread on requests.",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "modifiers": {
+                    "description": "Modifiers are the options selected from the product's option groups.\nThey apply to every unit of the item.",
+                    "type": "array",
+                    "maxItems": 20,
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ItemModifier"
+                    }
+                },
+                "product": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Product"
+                },
+                "productId": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderReq": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "couponCode": {
+                    "type": "string"
+                },
+                "customerId": {
+                    "description": "CustomerID identifies the customer in the calling system; promo\ncodes limited to one use per customer require it. Orders placed by a\nsigned-in customer count against their account instead.",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "delivery": {
+                    "description": "Delivery is where and to whom the order is delivered; orders\ncollected in store have none",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Delivery"
+                        }
+                    ]
+                },
+                "items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderItem"
+                    }
+                },
+                "paymentMethod": {
+                    "description": "PaymentMethod is the customer's card or wallet at the payment\nprovider, such as pm_card_visa with the mock provider; orders are\nonly charged when a provider is configured",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "pm_card_visa"
+                },
+                "reservationId": {
+                    "description": "ReservationID converts a stock reservation made for this checkout",
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "confirmed",
+                "preparing",
+                "completed",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "OrderStatusPending",
+                "OrderStatusConfirmed",
+                "OrderStatusPreparing",
+                "OrderStatusCompleted",
+                "OrderStatusCancelled"
+            ]
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderStatusReq": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "enum": [
+                        "pending",
+                        "confirmed",
+                        "preparing",
+                        "completed",
+                        "cancelled"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderVolume": {
+            "type": "object",
+            "properties": {
+                "baselineDays": {
+                    "description": "BaselineDays is how many previous days the expectation is taken from",
+                    "type": "integer",
+                    "example": 7
+                },
+                "checkedAt": {
+                    "type": "string"
+                },
+                "currentOrders": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "currentPerMinute": {
+                    "type": "number",
+                    "example": 0.8
+                },
+                "dropSince": {
+                    "description": "DropSince is when the current drop was first detected",
+                    "type": "string"
+                },
+                "expectedOrders": {
+                    "type": "number",
+                    "example": 48
+                },
+                "expectedPerMinute": {
+                    "type": "number",
+                    "example": 3.2
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "normal",
+                        "drop",
+                        "quiet"
+                    ]
+                },
+                "window": {
+                    "description": "Window is the length of the compared window, as a Go duration",
+                    "type": "string",
+                    "example": "15m0s"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PaginatedResponse": {
+            "type": "object",
+            "properties": {
+                "_links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Link"
+                    }
+                },
+                "cursor": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.CursorMeta"
+                },
+                "data": {},
+                "pagination": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PaginationMeta"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PaginationMeta": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "type": "integer"
+                },
+                "perPage": {
+                    "type": "integer"
+                },
+                "totalItems": {
+                    "type": "integer"
+                },
+                "totalPages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Partner": {
+            "type": "object",
+            "properties": {
+                "contactEmail": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PartnerRegistration": {
+            "type": "object",
+            "properties": {
+                "credentials": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.IssuedAPIKey"
+                },
+                "partner": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Partner"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PartnerReq": {
+            "type": "object",
+            "required": [
+                "contactEmail",
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "contactEmail": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Payment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount is in the smallest unit of Currency, e.g. cents",
+                    "type": "integer",
+                    "example": 1170
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "usd"
+                },
+                "intentId": {
+                    "description": "IntentID is the payment intent at the provider",
+                    "type": "string",
+                    "example": "pi_3b5f0c2a9d7e4e1f8a6b2c4d6e8f0a1b"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "mock"
+                },
+                "status": {
+                    "description": "Status is the status of the intent when the order was stored",
+                    "type": "string",
+                    "example": "succeeded"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PaymentDeclinedResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "declineCode": {
+                    "description": "DeclineCode is the provider's reason, such as insufficient_funds",
+                    "type": "string",
+                    "example": "card_declined"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PipelineRun": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "description": "FinishedAt is nil while the run is going, or when it was killed",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "instanceId": {
+                    "description": "InstanceID names the process that ran, as in its log prefix",
+                    "type": "string"
+                },
+                "job": {
+                    "type": "string",
+                    "example": "database-load"
+                },
+                "operationId": {
+                    "description": "OperationID is shared by every job of the pipeline the run belonged to",
+                    "type": "string",
+                    "example": "4bf92f3577b34da6a3ce929d0e0e4736"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "succeeded"
+                },
+                "summary": {
+                    "type": "string",
+                    "example": "120 products, 3000000 coupons"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PriceChange": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "newPrice": {
+                    "type": "number"
+                },
+                "oldPrice": {
+                    "type": "number"
+                },
+                "productId": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PriceMismatch": {
+            "type": "object",
+            "properties": {
+                "expectedUnitPrice": {
+                    "type": "number"
+                },
+                "productId": {
+                    "type": "string"
+                },
+                "unitPrice": {
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PriceMismatchResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PriceMismatch"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PriceRule": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount changes the price by a fixed amount in dollars",
+                    "type": "number"
+                },
+                "category": {
+                    "description": "Category selects every product in the category",
+                    "type": "string",
+                    "example": "Waffle"
+                },
+                "percent": {
+                    "description": "Percent changes the price by a percentage, e.g. 5 for +5% or -10 for -10%",
+                    "type": "number",
+                    "example": 5
+                },
+                "productIds": {
+                    "description": "ProductIDs selects individual products",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Product": {
+            "type": "object",
+            "required": [
+                "category",
+                "id",
+                "name",
+                "price"
+            ],
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Description is in Language, like Name",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "language": {
+                    "description": "Language is the language tag of Name and Description. It is only set\non product responses, which are localized from Translations.",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "optionGroups": {
+                    "description": "OptionGroups are the choices offered with the product, such as its\nsize or toppings. They are not set on the products of an order,\nwhose Price includes the modifiers selected for its item instead.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OptionGroup"
+                    }
+                },
+                "price": {
+                    "type": "number"
+                },
+                "prices": {
+                    "description": "Prices holds the price in other currencies keyed by ISO 4217 code",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                },
+                "sku": {
+                    "type": "string"
+                },
+                "taxRate": {
+                    "description": "TaxRate is the tax rate applied to an ordered product, as a fraction.\nIt is only set on the products of an order.",
+                    "type": "number"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ProductOption": {
+            "type": "object",
+            "required": [
+                "id",
+                "name"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "maxLength": 50,
+                    "example": "large"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Large"
+                },
+                "price": {
+                    "description": "Price is added to the unit price of the product when the option is\nselected",
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 2.5
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ProductReq": {
+            "type": "object",
+            "required": [
+                "category",
+                "name",
+                "price"
+            ],
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "4006381333931"
+                },
+                "category": {
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Waffle"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Crispy fried chicken on a Belgian waffle"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "11"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Chicken Waffle"
+                },
+                "price": {
+                    "type": "number",
+                    "minimum": 0,
+                    "example": 12.99
+                },
+                "sku": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "WAF-CHK-01"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ProductStock": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "description": "Available is what orders can still take, Stock minus Reserved and\nnever below zero; null when the product does not track stock",
+                    "type": "integer",
+                    "example": 37
+                },
+                "productId": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "reserved": {
+                    "description": "Reserved is the units held by unexpired reservations",
+                    "type": "integer",
+                    "example": 3
+                },
+                "stock": {
+                    "description": "Stock is the units on hand, including reserved units; null when the\nproduct does not track stock",
+                    "type": "integer",
+                    "example": 40
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PromoCode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "HAPPYHRS"
+                },
+                "discount": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Discount"
+                },
+                "limits": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PromoCodeLimits"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PromoCodeLimits": {
+            "type": "object",
+            "properties": {
+                "maxRedemptions": {
+                    "description": "MaxRedemptions is how many orders can use the code in total; 0 for\nno limit",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 100
+                },
+                "oncePerCustomer": {
+                    "description": "OncePerCustomer lets each customerId use the code only once; orders\nwithout a customerId cannot use it",
+                    "type": "boolean",
+                    "example": false
+                },
+                "redemptions": {
+                    "description": "Redemptions is how many orders have used the code. Only set on\nresponses.",
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.PromoCodePolicy": {
+            "type": "object",
+            "properties": {
+                "maxLength": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "minFiles": {
+                    "description": "MinFiles is how many coupon files a code must appear in",
+                    "type": "integer",
+                    "example": 2
+                },
+                "minLength": {
+                    "description": "MinLength and MaxLength bound the length of a code in bytes",
+                    "type": "integer",
+                    "example": 8
+                },
+                "name": {
+                    "description": "Name is the region or environment the policy was configured for, or\n\"default\"",
+                    "type": "string",
+                    "example": "default"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Quota": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "description": "Limit is the number of requests allowed per window",
+                    "type": "integer",
+                    "example": 600
+                },
+                "remaining": {
+                    "description": "Remaining is the number of requests left in the current window",
+                    "type": "integer",
+                    "example": 599
+                },
+                "resetAt": {
+                    "description": "ResetAt is when the current window ends",
+                    "type": "string"
+                },
+                "window": {
+                    "description": "Window is the length of a window in seconds",
+                    "type": "integer",
+                    "example": 60
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.Reservation": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderItem"
+                    }
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ReservationReq": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.OrderItem"
+                    }
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.RouteCapability": {
+            "type": "object",
+            "properties": {
+                "methods": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "GET",
+                        "OPTIONS"
+                    ]
+                },
+                "path": {
+                    "description": "Path is a URI template, such as /api/v1/orders/{orderId}",
+                    "type": "string",
+                    "example": "/api/v1/orders/{orderId}"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ScheduledTask": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "type": "integer"
+                },
+                "interval": {
+                    "description": "Interval between runs, as a Go duration",
+                    "type": "string",
+                    "example": "1m0s"
+                },
+                "lastRun": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.TaskRun"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "stock-reservation-reaper"
+                },
+                "nextRunAt": {
+                    "type": "string"
+                },
+                "running": {
+                    "type": "boolean"
+                },
+                "runs": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.StockAdjustmentReq": {
+            "type": "object",
+            "required": [
+                "delta"
+            ],
+            "properties": {
+                "delta": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Delivery"
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.StockReq": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Weekly stock count"
+                },
+                "stock": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 40
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.TaskRun": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "instanceId": {
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "succeeded",
+                        "failed"
+                    ]
+                }
+            }
+        },
+        "github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.ValidationErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_models.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.CouponGuardStatus": {
+            "type": "object",
+            "properties": {
+                "blocked": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_couponguard.Block"
+                    }
+                },
+                "stats": {
+                    "$ref": "#/definitions/github_com_shyampundkar_kart-challenge-workspace_order-food_internal_couponguard.Stats"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminKeyAuth": {
+            "type": "apiKey",
+            "name": "admin_key",
+            "in": "header"
+        },
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "api_key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Customer access token, as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
+    }
+}`
+
+// SwaggerInfo holds exported Swagger Info so clients can modify it
+var SwaggerInfo = &swag.Spec{
+	Version:          "1.0",
+	Host:             "",
+	BasePath:         "/",
+	Schemes:          []string{},
+	Title:            "Order Food API",
+	Description:      "Products, orders and promo codes of the Order Food service, with the admin API used to operate it.",
+	InfoInstanceName: "swagger",
+	SwaggerTemplate:  docTemplate,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
+}
+
+func init() {
+	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
+}
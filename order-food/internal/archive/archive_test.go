@@ -0,0 +1,128 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	// Setup
+	created := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	orders := []models.ArchivedOrder{
+		{
+			Order: models.Order{
+				ID:       "order-1",
+				Items:    []models.OrderItem{{ProductID: "1", Quantity: 2}},
+				Products: []models.Product{{ID: "1", Name: "Waffle", Price: 6.5, Category: "Waffle"}},
+				Total:    13,
+			},
+			CreatedAt: created,
+		},
+		{Order: models.Order{ID: "order-2", CouponCode: "HAPPYHRS"}, CreatedAt: created},
+	}
+
+	// Execute
+	data, err := Encode(orders)
+	assert.NoError(t, err)
+	decoded, err := Decode(data)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, orders, decoded)
+}
+
+func TestDecode_RejectsUncompressedData(t *testing.T) {
+	_, err := Decode([]byte(`{"id":"order-1"}`))
+	assert.Error(t, err)
+}
+
+func TestFileStore_PutGet(t *testing.T) {
+	// Setup
+	store, err := NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	// Execute
+	err = store.Put(ctx, "orders/2024/01/02/batch.ndjson.gz", []byte("payload"))
+	assert.NoError(t, err)
+	data, err := store.Get(ctx, "orders/2024/01/02/batch.ndjson.gz")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("payload"), data)
+
+	_, err = store.Get(ctx, "orders/missing.ndjson.gz")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestFileStore_RejectsKeysOutsideDirectory(t *testing.T) {
+	// Setup
+	store, err := NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	// Execute & Assert
+	for _, key := range []string{"", "../escape", "/etc/passwd", "orders/../../escape"} {
+		assert.Error(t, store.Put(context.Background(), key, []byte("x")), key)
+	}
+}
+
+// fakeS3 is an in-memory S3Client for tests, keyed by bucket/key
+type fakeS3 map[string][]byte
+
+func (f fakeS3) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	f[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f fakeS3) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	data, ok := f[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func TestS3Store_PutGet(t *testing.T) {
+	// Setup
+	client := fakeS3{}
+	store, err := NewS3Store(client, "archive", "/orders-food/")
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	// Execute
+	err = store.Put(ctx, "orders/2024/01/02/batch.ndjson.gz", []byte("payload"))
+	assert.NoError(t, err)
+	data, err := store.Get(ctx, "orders/2024/01/02/batch.ndjson.gz")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("payload"), data)
+	assert.Contains(t, client, "archive/orders-food/orders/2024/01/02/batch.ndjson.gz", "objects are kept below the prefix")
+
+	_, err = store.Get(ctx, "orders/missing.ndjson.gz")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+}
+
+func TestS3Store_RejectsKeysOutsidePrefix(t *testing.T) {
+	// Setup
+	store, err := NewS3Store(fakeS3{}, "archive", "orders-food")
+	assert.NoError(t, err)
+
+	// Execute & Assert
+	for _, key := range []string{"", "../escape", "/etc/passwd", "orders/../../escape"} {
+		assert.Error(t, store.Put(context.Background(), key, []byte("x")), key)
+	}
+}
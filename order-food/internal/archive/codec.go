@@ -0,0 +1,61 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// maxRecordBytes bounds a single NDJSON line when decoding
+const maxRecordBytes = 4 << 20
+
+// Encode serialises orders as gzip-compressed NDJSON, one order per line
+func Encode(orders []models.ArchivedOrder) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(zw)
+
+	for _, order := range orders {
+		if err := enc.Encode(order); err != nil {
+			return nil, fmt.Errorf("failed to encode archived order %s: %w", order.ID, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decode parses an object written by Encode
+func Decode(data []byte) ([]models.ArchivedOrder, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archive: %w", err)
+	}
+	defer zr.Close()
+
+	scanner := bufio.NewScanner(zr)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordBytes)
+
+	orders := make([]models.ArchivedOrder, 0)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var order models.ArchivedOrder
+		if err := json.Unmarshal(scanner.Bytes(), &order); err != nil {
+			return nil, fmt.Errorf("failed to decode archived order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	return orders, nil
+}
@@ -0,0 +1,127 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3Client is the part of the S3 API an S3Store uses. *s3.Client
+// implements it.
+type S3Client interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// NewS3Client creates an S3 client for region. An empty endpoint means AWS;
+// otherwise it is the URL of an S3 compatible store, such as MinIO.
+func NewS3Client(region, endpoint string, creds aws.CredentialsProvider) (*s3.Client, error) {
+	if region == "" {
+		return nil, fmt.Errorf("S3 region is required")
+	}
+	if endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+		}
+	}
+
+	return s3.New(s3.Options{
+		Region:      region,
+		Credentials: creds,
+		HTTPClient:  awshttp.NewBuildableClient().WithTimeout(5 * time.Minute),
+	}, func(o *s3.Options) {
+		if endpoint == "" {
+			return
+		}
+		// Compatible stores address the bucket in the path instead of the
+		// host name, and not all of them accept the checksums AWS asks for
+		o.BaseEndpoint = aws.String(endpoint)
+		o.UsePathStyle = true
+		o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		o.ResponseChecksumValidation = aws.ResponseChecksumValidationWhenRequired
+	}), nil
+}
+
+// S3Store is a Store backed by an S3 bucket, or a bucket of an S3
+// compatible store, below a prefix
+type S3Store struct {
+	client         S3Client
+	bucket, prefix string
+}
+
+// NewS3Store creates a store keeping its objects in bucket below prefix
+func NewS3Store(client S3Client, bucket, prefix string) (*S3Store, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	return &S3Store{client: client, bucket: bucket, prefix: strings.Trim(prefix, "/")}, nil
+}
+
+// Put uploads an object. S3 writes objects whole, so a failed upload never
+// leaves a partial object.
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(objectKey),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store archive object: %w", err)
+	}
+	return nil
+}
+
+// Get downloads an object
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectKey),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive object: %w", err)
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive object: %w", err)
+	}
+	return data, nil
+}
+
+// objectKey maps a key to the object below the store prefix, rejecting
+// keys a file store would refuse, so both stores accept the same keys
+func (s *S3Store) objectKey(key string) (string, error) {
+	clean := path.Clean(key)
+	if key == "" || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid archive key %q", key)
+	}
+	return path.Join(s.prefix, clean), nil
+}
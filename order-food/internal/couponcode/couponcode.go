@@ -0,0 +1,154 @@
+// Package couponcode generates random promo codes that pass the promo code
+// rules: 8 to 10 characters, made of a fixed prefix and random characters
+// drawn from a charset.
+package couponcode
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+const (
+	// MinLength and MaxLength bound the length of a valid promo code
+	MinLength = 8
+	MaxLength = 10
+	// DefaultCharset leaves out characters that are easily confused when
+	// read out or typed: 0/O, 1/I/L
+	DefaultCharset = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+	// minRandomChars keeps codes from being guessable from the prefix
+	minRandomChars = 4
+	// sparsity is how much larger than the batch the code space must be, so
+	// that guessing a code of the batch is unlikely
+	sparsity = 1000
+)
+
+// Spec describes the codes to generate
+type Spec struct {
+	// Prefix starts every code and counts towards Length
+	Prefix string
+	// Charset holds the characters the random part is drawn from;
+	// DefaultCharset when empty
+	Charset string
+	// Length of each code including the prefix
+	Length int
+}
+
+// charset returns the characters to draw from
+func (s Spec) charset() string {
+	if s.Charset == "" {
+		return DefaultCharset
+	}
+	return s.Charset
+}
+
+// Validate checks that count codes can be generated to the spec and
+// returns a diagnostic for every problem found
+func (s Spec) Validate(count int) []models.FieldError {
+	var diagnostics []models.FieldError
+	if s.Length < MinLength || s.Length > MaxLength {
+		diagnostics = append(diagnostics, models.FieldError{
+			Field:   "length",
+			Message: fmt.Sprintf("length must be between %d and %d", MinLength, MaxLength),
+		})
+	}
+	if !isCodeCharset(s.Prefix) {
+		diagnostics = append(diagnostics, models.FieldError{Field: "prefix", Message: "prefix must only contain upper-case letters and digits"})
+	} else if s.Length-len(s.Prefix) < minRandomChars {
+		diagnostics = append(diagnostics, models.FieldError{
+			Field:   "prefix",
+			Message: fmt.Sprintf("prefix must leave at least %d random characters", minRandomChars),
+		})
+	}
+
+	charset := s.charset()
+	switch {
+	case !isCodeCharset(charset):
+		diagnostics = append(diagnostics, models.FieldError{Field: "charset", Message: "charset must only contain upper-case letters and digits"})
+	case len(charset) < 2:
+		diagnostics = append(diagnostics, models.FieldError{Field: "charset", Message: "charset must have at least 2 characters"})
+	case hasDuplicates(charset):
+		diagnostics = append(diagnostics, models.FieldError{Field: "charset", Message: "charset must not repeat characters"})
+	}
+	if len(diagnostics) > 0 {
+		return diagnostics
+	}
+
+	space := math.Pow(float64(len(charset)), float64(s.Length-len(s.Prefix)))
+	if space < float64(count)*sparsity {
+		diagnostics = append(diagnostics, models.FieldError{
+			Field:   "count",
+			Message: fmt.Sprintf("%d codes would be too easy to guess; use longer codes, a shorter prefix or a larger charset", count),
+		})
+	}
+	return diagnostics
+}
+
+// Generate returns count distinct random codes. The spec must be valid for
+// count.
+func Generate(spec Spec, count int) ([]string, error) {
+	seen := make(map[string]bool, count)
+	codes := make([]string, 0, count)
+	for len(codes) < count {
+		code, err := New(spec)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	return codes, nil
+}
+
+// New returns one random code
+func New(spec Spec) (string, error) {
+	charset := spec.charset()
+	n := spec.Length - len(spec.Prefix)
+
+	// Bytes at or above limit are rejected so every character is equally
+	// likely
+	limit := 256 - 256%len(charset)
+	var b strings.Builder
+	b.Grow(spec.Length)
+	b.WriteString(spec.Prefix)
+	buf := make([]byte, n*2)
+	for b.Len() < spec.Length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("error reading random bytes: %w", err)
+		}
+		for _, r := range buf {
+			if int(r) < limit && b.Len() < spec.Length {
+				b.WriteByte(charset[int(r)%len(charset)])
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// isCodeCharset reports whether s only has upper-case letters and digits.
+// Promo codes are case-insensitive, so lower-case letters would only make
+// codes that collide with upper-case ones.
+func isCodeCharset(s string) bool {
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func hasDuplicates(s string) bool {
+	seen := make(map[rune]bool, len(s))
+	for _, r := range s {
+		if seen[r] {
+			return true
+		}
+		seen[r] = true
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package couponcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	// Setup
+	spec := Spec{Prefix: "SUM", Length: 9}
+
+	// Execute
+	codes, err := Generate(spec, 500)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, codes, 500)
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		assert.Len(t, code, 9)
+		assert.True(t, strings.HasPrefix(code, "SUM"), code)
+		for _, r := range code[3:] {
+			assert.Contains(t, DefaultCharset, string(r))
+		}
+		assert.False(t, seen[code], "duplicate code %s", code)
+		seen[code] = true
+	}
+}
+
+func TestNew_CustomCharset(t *testing.T) {
+	// Execute
+	code, err := New(Spec{Charset: "AB", Length: 10})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, code, 10)
+	assert.Empty(t, strings.Trim(code, "AB"))
+}
+
+func TestSpec_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      Spec
+		count     int
+		wantField string
+	}{
+		{name: "valid", spec: Spec{Prefix: "SUM", Length: 10}, count: 10000},
+		{name: "too short", spec: Spec{Length: 7}, count: 1, wantField: "length"},
+		{name: "too long", spec: Spec{Length: 11}, count: 1, wantField: "length"},
+		{name: "prefix too long", spec: Spec{Prefix: "SUMMER", Length: 9}, count: 1, wantField: "prefix"},
+		{name: "prefix not alphanumeric", spec: Spec{Prefix: "SU-", Length: 10}, count: 1, wantField: "prefix"},
+		{name: "charset not alphanumeric", spec: Spec{Charset: "AB$", Length: 8}, count: 1, wantField: "charset"},
+		{name: "charset lower case", spec: Spec{Charset: "ABCDEFabcdef", Length: 8}, count: 1, wantField: "charset"},
+		{name: "prefix lower case", spec: Spec{Prefix: "summer", Length: 10}, count: 1, wantField: "prefix"},
+		{name: "charset repeats", spec: Spec{Charset: "ABA", Length: 8}, count: 1, wantField: "charset"},
+		{name: "code space too small", spec: Spec{Charset: "AB", Length: 8}, count: 10, wantField: "count"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Execute
+			diagnostics := tt.spec.Validate(tt.count)
+
+			// Assert
+			if tt.wantField == "" {
+				assert.Empty(t, diagnostics)
+				return
+			}
+			assert.Len(t, diagnostics, 1)
+			assert.Equal(t, tt.wantField, diagnostics[0].Field)
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// Package couponfilter keeps a Bloom filter of every valid promo code in
+// memory, so codes that cannot be valid are turned away without a database
+// round trip. The filter only ever answers "definitely not valid" or "ask
+// the database": a code it lets through is still validated as before.
+package couponfilter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/bloom"
+)
+
+// ReloadAll is the invalidation key that rebuilds the whole filter
+const ReloadAll = "*"
+
+// Descriptors of the filter's metrics
+var (
+	loadedDesc       = prometheus.NewDesc("coupon_filter_loaded", "1 while the coupon filter is in service.", nil, nil)
+	codesDesc        = prometheus.NewDesc("coupon_filter_codes", "Promo codes added to the coupon filter.", nil, nil)
+	bytesDesc        = prometheus.NewDesc("coupon_filter_bytes", "Memory taken by the coupon filter.", nil, nil)
+	checksDesc       = prometheus.NewDesc("coupon_filter_checks_total", "Promo codes checked against the coupon filter.", nil, nil)
+	rejectionsDesc   = prometheus.NewDesc("coupon_filter_rejections_total", "Promo codes the coupon filter turned away without a database query.", nil, nil)
+	loadFailuresDesc = prometheus.NewDesc("coupon_filter_load_failures_total", "Coupon filter loads and campaign updates that failed.", nil, nil)
+)
+
+// DefaultBitsPerCode gives a false positive rate of about 1%, at 1.25
+// bytes per code
+const DefaultBitsPerCode = 10
+
+// snapshotMinFiles is how many coupon files the codes in a snapshot written
+// by database-load appear in
+const snapshotMinFiles = 2
+
+// minCapacity is the fewest codes a filter built from the database is sized
+// for, so codes added by campaigns soon after do not saturate a small one
+const minCapacity = 100_000
+
+// Source reads the valid promo codes
+type Source interface {
+	// EstimateValidCodes returns roughly how many codes appear in at least
+	// minFiles coupon files
+	EstimateValidCodes(ctx context.Context, minFiles int) (int, error)
+	// EachValidCode calls fn with every code that appears in at least
+	// minFiles coupon files
+	EachValidCode(ctx context.Context, minFiles int, fn func(code string)) error
+	// EachCampaignCode calls fn with every code of the campaign, or of every
+	// campaign when campaignID is empty
+	EachCampaignCode(ctx context.Context, campaignID string, fn func(code string)) error
+}
+
+// Config tunes the filter
+type Config struct {
+	// Snapshot is the path of a filter written by database-load; the filter
+	// is built from the database when empty or when the file is missing
+	Snapshot string
+	// BitsPerCode sizes a filter built from the database
+	BitsPerCode int
+	// LoadTimeout bounds a reload triggered by an invalidation event
+	LoadTimeout time.Duration
+	// MinFiles is how many coupon files a valid code appears in, from the
+	// promo code policy; 2 when zero. The snapshot only holds codes in at
+	// least 2 files, so it is ignored when fewer are enough.
+	MinFiles int
+}
+
+// Filter answers whether a promo code may be valid. Until the first load
+// has finished, and after an update could not be applied, every code may
+// be valid. It is safe for concurrent use.
+type Filter struct {
+	source Source
+	cfg    Config
+
+	// mu serialises installing a filter and adding campaign codes to it,
+	// so no campaign is lost to a reload that read the codes before it
+	mu       sync.Mutex
+	current  atomic.Pointer[bloom.Filter]
+	loaded   chan struct{}
+	loadOnce sync.Once
+
+	loading atomic.Bool
+	pending atomic.Bool
+
+	checks     atomic.Int64
+	rejections atomic.Int64
+	failures   atomic.Int64
+}
+
+// New creates an empty filter reading codes from source
+func New(source Source, cfg Config) *Filter {
+	if cfg.BitsPerCode <= 0 {
+		cfg.BitsPerCode = DefaultBitsPerCode
+	}
+	if cfg.MinFiles <= 0 {
+		cfg.MinFiles = snapshotMinFiles
+	}
+	return &Filter{source: source, cfg: cfg, loaded: make(chan struct{})}
+}
+
+// Load reads the snapshot, or builds a filter from the database when there
+// is none, adds the codes of every campaign and puts the result in service
+func (f *Filter) Load(ctx context.Context) error {
+	start := time.Now()
+	next, from, err := f.read(ctx)
+	if err != nil {
+		f.failures.Add(1)
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.source.EachCampaignCode(ctx, "", next.Add); err != nil {
+		f.failures.Add(1)
+		return fmt.Errorf("error adding campaign codes to the coupon filter: %w", err)
+	}
+	f.current.Store(next)
+	f.loadOnce.Do(func() { close(f.loaded) })
+
+	slog.Info("Coupon filter loaded", "from", from, "duration", time.Since(start).Round(time.Millisecond),
+		"codes", next.Count(), "megabytes", next.SizeBytes()>>20)
+	return nil
+}
+
+// read returns the snapshot's filter, or one built from the database, and
+// where it came from
+func (f *Filter) read(ctx context.Context) (*bloom.Filter, string, error) {
+	if f.cfg.Snapshot != "" && f.cfg.MinFiles < snapshotMinFiles {
+		slog.Warn("Ignoring coupon filter snapshot, which leaves out codes in too few files; building the filter from the database",
+			"snapshot", f.cfg.Snapshot, "snapshot_min_files", snapshotMinFiles)
+	} else if f.cfg.Snapshot != "" {
+		filter, err := bloom.ReadFile(f.cfg.Snapshot)
+		if err == nil {
+			return filter, f.cfg.Snapshot, nil
+		}
+		if errors.Is(err, fs.ErrNotExist) {
+			slog.Info("Coupon filter snapshot not found; building the filter from the database", "snapshot", f.cfg.Snapshot)
+		} else {
+			slog.Warn("Failed to read coupon filter snapshot, building the filter from the database", "snapshot", f.cfg.Snapshot, "error", err)
+		}
+	}
+
+	estimate, err := f.source.EstimateValidCodes(ctx, f.cfg.MinFiles)
+	if err != nil {
+		return nil, "", fmt.Errorf("error estimating valid promo codes: %w", err)
+	}
+	// Leave room for codes loaded after the filter was built
+	capacity := max(estimate+estimate/4, minCapacity)
+	filter := bloom.New(capacity, f.cfg.BitsPerCode)
+	if err := f.source.EachValidCode(ctx, f.cfg.MinFiles, filter.Add); err != nil {
+		return nil, "", fmt.Errorf("error reading valid promo codes: %w", err)
+	}
+	return filter, "the database", nil
+}
+
+// WaitLoaded blocks until the first load has finished or ctx is done
+func (f *Filter) WaitLoaded(ctx context.Context) error {
+	select {
+	case <-f.loaded:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reload loads the filter again in the background. A reload asked for
+// while one runs is made once it has finished, as the running one may
+// have read the codes before the change that asked for it.
+func (f *Filter) Reload() {
+	f.pending.Store(true)
+	if !f.loading.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		for {
+			for f.pending.Swap(false) {
+				ctx, cancel := context.WithTimeout(context.Background(), f.cfg.LoadTimeout)
+				if err := f.Load(ctx); err != nil {
+					slog.Error("Failed to load coupon filter", "error", err)
+				}
+				cancel()
+			}
+			f.loading.Store(false)
+			// A request that came in after the last check but before the
+			// flag was cleared found the flag set and left it to us
+			if !f.pending.Load() || !f.loading.CompareAndSwap(false, true) {
+				return
+			}
+		}
+	}()
+}
+
+// HandleInvalidation applies an invalidation event of the coupons topic:
+// ReloadAll rebuilds the filter and a campaign key adds that campaign's
+// codes. It is meant to be subscribed to the invalidation service.
+func (f *Filter) HandleInvalidation(key string) {
+	switch {
+	case key == ReloadAll:
+		f.Reload()
+	case strings.HasPrefix(key, models.InvalidationKeyCampaignPrefix):
+		f.addCampaign(strings.TrimPrefix(key, models.InvalidationKeyCampaignPrefix))
+	}
+}
+
+// addCampaign adds the codes of a new campaign. The filter is taken out of
+// service and rebuilt when they cannot be read, since rejecting the
+// campaign's codes would turn away valid ones.
+func (f *Filter) addCampaign(campaignID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	current := f.current.Load()
+	if current == nil {
+		// The first load reads every campaign
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), f.cfg.LoadTimeout)
+	defer cancel()
+	if err := f.source.EachCampaignCode(ctx, campaignID, current.Add); err != nil {
+		f.failures.Add(1)
+		slog.Error("Failed to add campaign to the coupon filter, reloading it", "campaign_id", campaignID, "error", err)
+		f.current.Store(nil)
+		f.Reload()
+	}
+}
+
+// Loaded reports whether the filter is in service
+func (f *Filter) Loaded() bool {
+	return f.current.Load() != nil
+}
+
+// MayContain reports whether code may be a valid promo code. False means
+// it definitely is not.
+func (f *Filter) MayContain(code string) bool {
+	current := f.current.Load()
+	if current == nil {
+		return true
+	}
+	f.checks.Add(1)
+	if current.MayContain(code) {
+		return true
+	}
+	f.rejections.Add(1)
+	return false
+}
+
+// Describe sends the descriptors of the filter's metrics
+func (f *Filter) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{loadedDesc, codesDesc, bytesDesc, checksDesc, rejectionsDesc, loadFailuresDesc} {
+		ch <- desc
+	}
+}
+
+// Collect sends the filter's size and counters
+func (f *Filter) Collect(ch chan<- prometheus.Metric) {
+	var loaded, codes, size uint64
+	if current := f.current.Load(); current != nil {
+		loaded, codes, size = 1, current.Count(), current.SizeBytes()
+	}
+
+	ch <- prometheus.MustNewConstMetric(loadedDesc, prometheus.GaugeValue, float64(loaded))
+	ch <- prometheus.MustNewConstMetric(codesDesc, prometheus.GaugeValue, float64(codes))
+	ch <- prometheus.MustNewConstMetric(bytesDesc, prometheus.GaugeValue, float64(size))
+	ch <- prometheus.MustNewConstMetric(checksDesc, prometheus.CounterValue, float64(f.checks.Load()))
+	ch <- prometheus.MustNewConstMetric(rejectionsDesc, prometheus.CounterValue, float64(f.rejections.Load()))
+	ch <- prometheus.MustNewConstMetric(loadFailuresDesc, prometheus.CounterValue, float64(f.failures.Load()))
+}
@@ -0,0 +1,201 @@
+package couponfilter
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/bloom"
+	"github.com/stretchr/testify/assert"
+)
+
+// memorySource is an in-memory Source
+type memorySource struct {
+	mu          sync.Mutex
+	valid       []string
+	campaigns   map[string][]string
+	campaignErr error
+	// minFiles is the file count valid codes were last read with
+	minFiles int
+}
+
+func (s *memorySource) EstimateValidCodes(ctx context.Context, minFiles int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.valid), nil
+}
+
+func (s *memorySource) EachValidCode(ctx context.Context, minFiles int, fn func(code string)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.minFiles = minFiles
+	for _, code := range s.valid {
+		fn(code)
+	}
+	return nil
+}
+
+func (s *memorySource) EachCampaignCode(ctx context.Context, campaignID string, fn func(code string)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.campaignErr != nil {
+		return s.campaignErr
+	}
+	for id, codes := range s.campaigns {
+		if campaignID != "" && id != campaignID {
+			continue
+		}
+		for _, code := range codes {
+			fn(code)
+		}
+	}
+	return nil
+}
+
+func TestFilter_Load(t *testing.T) {
+	// Setup
+	source := &memorySource{valid: []string{"HAPPYHRS", "FIFTYOFF"}, campaigns: map[string][]string{"c1": {"SUMABCDEFG"}}}
+	filter := New(source, Config{LoadTimeout: time.Minute})
+	assert.True(t, filter.MayContain("NOTACODE"), "every code may be valid before the first load")
+
+	// Execute
+	err := filter.Load(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, filter.WaitLoaded(context.Background()))
+	assert.True(t, filter.MayContain("HAPPYHRS"))
+	assert.True(t, filter.MayContain("FIFTYOFF"))
+	assert.True(t, filter.MayContain("SUMABCDEFG"))
+	assert.False(t, filter.MayContain("NOTACODE"))
+}
+
+func TestFilter_Load_Snapshot(t *testing.T) {
+	// Setup
+	path := filepath.Join(t.TempDir(), "coupons.bloom")
+	snapshot := bloom.New(100, DefaultBitsPerCode)
+	snapshot.Add("SNAPSHOT1")
+	assert.NoError(t, snapshot.WriteFile(path))
+	source := &memorySource{valid: []string{"DATABASE1"}}
+	filter := New(source, Config{Snapshot: path, LoadTimeout: time.Minute})
+
+	// Execute
+	err := filter.Load(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, filter.MayContain("SNAPSHOT1"))
+	assert.False(t, filter.MayContain("DATABASE1"), "the database is not read when there is a snapshot")
+}
+
+func TestFilter_Load_SnapshotOfStricterPolicy(t *testing.T) {
+	// Setup - codes in a single file are valid, but the snapshot leaves them out
+	path := filepath.Join(t.TempDir(), "coupons.bloom")
+	snapshot := bloom.New(100, DefaultBitsPerCode)
+	snapshot.Add("SNAPSHOT1")
+	assert.NoError(t, snapshot.WriteFile(path))
+	source := &memorySource{valid: []string{"ONEFILE1"}}
+	filter := New(source, Config{Snapshot: path, LoadTimeout: time.Minute, MinFiles: 1})
+
+	// Execute
+	err := filter.Load(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, filter.MayContain("ONEFILE1"))
+	assert.Equal(t, 1, source.minFiles)
+}
+
+func TestFilter_Load_MissingSnapshot(t *testing.T) {
+	// Setup
+	source := &memorySource{valid: []string{"DATABASE1"}}
+	filter := New(source, Config{Snapshot: filepath.Join(t.TempDir(), "missing.bloom"), LoadTimeout: time.Minute})
+
+	// Execute
+	err := filter.Load(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, filter.MayContain("DATABASE1"))
+}
+
+func TestFilter_HandleInvalidation_Campaign(t *testing.T) {
+	// Setup
+	source := &memorySource{valid: []string{"HAPPYHRS"}, campaigns: map[string][]string{}}
+	filter := New(source, Config{LoadTimeout: time.Minute})
+	assert.NoError(t, filter.Load(context.Background()))
+	source.mu.Lock()
+	source.campaigns["c2"] = []string{"NEWCODE123"}
+	source.mu.Unlock()
+
+	// Execute
+	filter.HandleInvalidation(models.InvalidationKeyCampaignPrefix + "c2")
+
+	// Assert
+	assert.True(t, filter.MayContain("NEWCODE123"))
+}
+
+func TestFilter_HandleInvalidation_CampaignFailure(t *testing.T) {
+	// Setup
+	source := &memorySource{valid: []string{"HAPPYHRS"}}
+	filter := New(source, Config{LoadTimeout: time.Minute})
+	assert.NoError(t, filter.Load(context.Background()))
+	source.mu.Lock()
+	source.campaignErr = errors.New("connection refused")
+	source.mu.Unlock()
+
+	// Execute
+	filter.HandleInvalidation(models.InvalidationKeyCampaignPrefix + "c2")
+
+	// Assert
+	assert.True(t, filter.MayContain("NOTACODE"), "codes of the lost campaign must not be turned away")
+	assert.GreaterOrEqual(t, filter.failures.Load(), int64(1))
+}
+
+func TestFilter_HandleInvalidation_ReloadAll(t *testing.T) {
+	// Setup
+	source := &memorySource{valid: []string{"HAPPYHRS"}}
+	filter := New(source, Config{LoadTimeout: time.Minute})
+	assert.NoError(t, filter.Load(context.Background()))
+	source.mu.Lock()
+	source.valid = append(source.valid, "UPLOADED1")
+	source.mu.Unlock()
+
+	// Execute
+	filter.HandleInvalidation(ReloadAll)
+
+	// Assert
+	assert.Eventually(t, func() bool { return filter.MayContain("UPLOADED1") }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestFilter_Collect(t *testing.T) {
+	// Setup
+	source := &memorySource{valid: []string{"HAPPYHRS"}}
+	filter := New(source, Config{LoadTimeout: time.Minute})
+	assert.NoError(t, filter.Load(context.Background()))
+	filter.MayContain("HAPPYHRS")
+	filter.MayContain("NOTACODE")
+
+	// Execute & Assert
+	err := testutil.CollectAndCompare(filter, strings.NewReader(`
+# HELP coupon_filter_checks_total Promo codes checked against the coupon filter.
+# TYPE coupon_filter_checks_total counter
+coupon_filter_checks_total 2
+# HELP coupon_filter_codes Promo codes added to the coupon filter.
+# TYPE coupon_filter_codes gauge
+coupon_filter_codes 1
+# HELP coupon_filter_loaded 1 while the coupon filter is in service.
+# TYPE coupon_filter_loaded gauge
+coupon_filter_loaded 1
+# HELP coupon_filter_rejections_total Promo codes the coupon filter turned away without a database query.
+# TYPE coupon_filter_rejections_total counter
+coupon_filter_rejections_total 1
+`), "coupon_filter_checks_total", "coupon_filter_codes", "coupon_filter_loaded", "coupon_filter_rejections_total")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,233 @@
+// Package couponguard throttles clients that repeatedly submit invalid
+// coupon codes. Failures and blocks are kept in a Store; with the Postgres
+// store every replica sees the same clients, so limits apply across the
+// service rather than per instance.
+package couponguard
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// sweepInterval is how often stale client entries are dropped
+const sweepInterval = time.Minute
+
+// Config controls when a client is blocked and for how long
+type Config struct {
+	// MaxFailures is the number of invalid attempts allowed within Window
+	MaxFailures int
+	// Window is the period over which failures are counted
+	Window time.Duration
+	// Cooldown is how long a client stays blocked once it exceeds MaxFailures
+	Cooldown time.Duration
+}
+
+// DefaultConfig is used when no configuration has been supplied
+var DefaultConfig = Config{
+	MaxFailures: 10,
+	Window:      10 * time.Minute,
+	Cooldown:    15 * time.Minute,
+}
+
+// Stats are counters describing guard activity since start-up. The
+// counters are this instance's; CurrentlyBlocked counts the clients blocked
+// in the store.
+type Stats struct {
+	Failures         uint64 `json:"failures"`
+	Blocks           uint64 `json:"blocks"`
+	Rejected         uint64 `json:"rejected"`
+	Unblocks         uint64 `json:"unblocks"`
+	Errors           uint64 `json:"errors"`
+	CurrentlyBlocked int    `json:"currentlyBlocked"`
+}
+
+// Block describes a client that is currently blocked
+type Block struct {
+	Client string    `json:"client"`
+	Until  time.Time `json:"until"`
+}
+
+// Store keeps the failures and blocks of clients. Times are passed in, so
+// every store follows the guard's clock.
+type Store interface {
+	// BlockedUntil returns when the block on client ends, or the zero time
+	// when it is not blocked at now
+	BlockedUntil(ctx context.Context, client string, now time.Time) (time.Time, error)
+	// RecordFailure counts an invalid attempt by client at now. Once
+	// cfg.MaxFailures attempts fall within cfg.Window the client is blocked
+	// for cfg.Cooldown and its failures start over; it reports whether this
+	// attempt blocked the client.
+	RecordFailure(ctx context.Context, client string, now time.Time, cfg Config) (bool, error)
+	// Unblock forgets client and reports whether it was blocked at now
+	Unblock(ctx context.Context, client string, now time.Time) (bool, error)
+	// Blocked returns the clients blocked at now, soonest expiry first
+	Blocked(ctx context.Context, now time.Time) ([]Block, error)
+	// Sweep drops clients whose window started before windowStart and that
+	// are not blocked at now
+	Sweep(ctx context.Context, windowStart, now time.Time) error
+}
+
+// Descriptors of the guard's metrics
+var (
+	failuresDesc       = prometheus.NewDesc("coupon_guard_failures_total", "Invalid promo codes counted against clients.", nil, nil)
+	blocksDesc         = prometheus.NewDesc("coupon_guard_blocks_total", "Clients blocked after too many invalid promo codes.", nil, nil)
+	rejectedDesc       = prometheus.NewDesc("coupon_guard_rejected_total", "Promo code attempts refused because the client was blocked.", nil, nil)
+	unblocksDesc       = prometheus.NewDesc("coupon_guard_unblocks_total", "Blocks lifted by an admin.", nil, nil)
+	errorsDesc         = prometheus.NewDesc("coupon_guard_errors_total", "Coupon guard store operations that failed, letting the client through.", nil, nil)
+	blockedClientsDesc = prometheus.NewDesc("coupon_guard_blocked_clients", "Clients currently blocked from validating promo codes.", nil, nil)
+)
+
+// Guard counts invalid coupon attempts per client and blocks clients that
+// exceed the configured limit. It is safe for concurrent use.
+//
+// The guard fails open: when the store cannot be reached clients are let
+// through and the error is counted, since coupon validation itself is
+// still protected by the coupon service.
+type Guard struct {
+	cfg   Config
+	store Store
+	now   func() time.Time
+
+	sweepMu   sync.Mutex
+	lastSweep time.Time
+
+	failures atomic.Uint64
+	blocks   atomic.Uint64
+	rejected atomic.Uint64
+	unblocks atomic.Uint64
+	errors   atomic.Uint64
+}
+
+// New creates a guard with the given limits keeping its clients in store
+func New(cfg Config, store Store) *Guard {
+	return &Guard{
+		cfg:   cfg,
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Check reports whether client is blocked and, if so, how long remains
+func (g *Guard) Check(ctx context.Context, client string) (time.Duration, bool) {
+	now := g.now()
+	g.sweep(ctx, now)
+
+	until, err := g.store.BlockedUntil(ctx, client, now)
+	if err != nil {
+		g.storeFailed(ctx, "check", err)
+		return 0, false
+	}
+	if !now.Before(until) {
+		return 0, false
+	}
+
+	g.rejected.Add(1)
+	return until.Sub(now), true
+}
+
+// RecordFailure counts an invalid attempt by client. When the attempt
+// exceeds the limit the client is blocked and the cool-down is returned.
+func (g *Guard) RecordFailure(ctx context.Context, client string) (time.Duration, bool) {
+	g.failures.Add(1)
+
+	blocked, err := g.store.RecordFailure(ctx, client, g.now(), g.cfg)
+	if err != nil {
+		g.storeFailed(ctx, "record failure", err)
+		return 0, false
+	}
+	if !blocked {
+		return 0, false
+	}
+
+	g.blocks.Add(1)
+	return g.cfg.Cooldown, true
+}
+
+// Unblock lifts a block on client and clears its failures. It reports
+// whether the client was blocked.
+func (g *Guard) Unblock(ctx context.Context, client string) (bool, error) {
+	unblocked, err := g.store.Unblock(ctx, client, g.now())
+	if err != nil {
+		return false, fmt.Errorf("failed to unblock client: %w", err)
+	}
+	if unblocked {
+		g.unblocks.Add(1)
+	}
+	return unblocked, nil
+}
+
+// Blocked returns the clients that are currently blocked, soonest expiry first
+func (g *Guard) Blocked(ctx context.Context) ([]Block, error) {
+	blocks, err := g.store.Blocked(ctx, g.now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocked clients: %w", err)
+	}
+	return blocks, nil
+}
+
+// Stats returns the guard counters
+func (g *Guard) Stats(ctx context.Context) (Stats, error) {
+	blocks, err := g.Blocked(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{
+		Failures:         g.failures.Load(),
+		Blocks:           g.blocks.Load(),
+		Rejected:         g.rejected.Load(),
+		Unblocks:         g.unblocks.Load(),
+		Errors:           g.errors.Load(),
+		CurrentlyBlocked: len(blocks),
+	}, nil
+}
+
+// Describe sends the descriptors of the guard's metrics
+func (g *Guard) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{failuresDesc, blocksDesc, rejectedDesc, unblocksDesc, errorsDesc, blockedClientsDesc} {
+		ch <- desc
+	}
+}
+
+// Collect sends the guard's counters and the number of blocked clients.
+// The gauge is left out when the store cannot be read.
+func (g *Guard) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(failuresDesc, prometheus.CounterValue, float64(g.failures.Load()))
+	ch <- prometheus.MustNewConstMetric(blocksDesc, prometheus.CounterValue, float64(g.blocks.Load()))
+	ch <- prometheus.MustNewConstMetric(rejectedDesc, prometheus.CounterValue, float64(g.rejected.Load()))
+	ch <- prometheus.MustNewConstMetric(unblocksDesc, prometheus.CounterValue, float64(g.unblocks.Load()))
+
+	blocks, err := g.store.Blocked(context.Background(), g.now())
+	if err != nil {
+		g.errors.Add(1)
+	} else {
+		ch <- prometheus.MustNewConstMetric(blockedClientsDesc, prometheus.GaugeValue, float64(len(blocks)))
+	}
+	ch <- prometheus.MustNewConstMetric(errorsDesc, prometheus.CounterValue, float64(g.errors.Load()))
+}
+
+// sweep drops stale clients from the store at most once per sweepInterval
+func (g *Guard) sweep(ctx context.Context, now time.Time) {
+	g.sweepMu.Lock()
+	if now.Sub(g.lastSweep) < sweepInterval {
+		g.sweepMu.Unlock()
+		return
+	}
+	g.lastSweep = now
+	g.sweepMu.Unlock()
+
+	if err := g.store.Sweep(ctx, now.Add(-g.cfg.Window), now); err != nil {
+		g.storeFailed(ctx, "sweep", err)
+	}
+}
+
+// storeFailed counts and logs a failed store operation
+func (g *Guard) storeFailed(ctx context.Context, op string, err error) {
+	g.errors.Add(1)
+	slog.WarnContext(ctx, "Coupon guard store failed", "op", op, "error", err)
+}
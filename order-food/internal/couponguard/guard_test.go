@@ -0,0 +1,198 @@
+package couponguard
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestGuard returns a guard driven by a controllable clock
+func newTestGuard(cfg Config) (*Guard, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	g := New(cfg, NewMemoryStore())
+	g.now = func() time.Time { return now }
+	return g, &now
+}
+
+func TestGuard_BlocksAfterMaxFailures(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	g, now := newTestGuard(Config{MaxFailures: 3, Window: time.Minute, Cooldown: 5 * time.Minute})
+
+	// Execute
+	_, blocked1 := g.RecordFailure(ctx, "10.0.0.1")
+	_, blocked2 := g.RecordFailure(ctx, "10.0.0.1")
+	cooldown, blocked3 := g.RecordFailure(ctx, "10.0.0.1")
+
+	// Assert
+	assert.False(t, blocked1)
+	assert.False(t, blocked2)
+	assert.True(t, blocked3)
+	assert.Equal(t, 5*time.Minute, cooldown)
+
+	remaining, blocked := g.Check(ctx, "10.0.0.1")
+	assert.True(t, blocked)
+	assert.Equal(t, 5*time.Minute, remaining)
+
+	_, otherBlocked := g.Check(ctx, "10.0.0.2")
+	assert.False(t, otherBlocked)
+
+	// Cool-down expires
+	*now = now.Add(5 * time.Minute)
+	_, blocked = g.Check(ctx, "10.0.0.1")
+	assert.False(t, blocked)
+}
+
+func TestGuard_FailuresOutsideWindowReset(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	g, now := newTestGuard(Config{MaxFailures: 2, Window: time.Minute, Cooldown: time.Minute})
+
+	// Execute
+	g.RecordFailure(ctx, "client")
+	*now = now.Add(2 * time.Minute)
+	_, blocked := g.RecordFailure(ctx, "client")
+
+	// Assert
+	assert.False(t, blocked)
+}
+
+func TestGuard_UnblockAndStats(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	g, _ := newTestGuard(Config{MaxFailures: 1, Window: time.Minute, Cooldown: time.Hour})
+	g.RecordFailure(ctx, "client")
+	g.Check(ctx, "client")
+
+	// Assert blocked
+	assert.Equal(t, []string{"client"}, blockedClients(t, g))
+	assert.Equal(t, Stats{Failures: 1, Blocks: 1, Rejected: 1, CurrentlyBlocked: 1}, stats(t, g))
+
+	// Execute
+	unblocked, err := g.Unblock(ctx, "client")
+	assert.NoError(t, err)
+	assert.True(t, unblocked)
+	unblocked, err = g.Unblock(ctx, "client")
+	assert.NoError(t, err)
+	assert.False(t, unblocked)
+
+	// Assert unblocked
+	_, blocked := g.Check(ctx, "client")
+	assert.False(t, blocked)
+	assert.Equal(t, Stats{Failures: 1, Blocks: 1, Rejected: 1, Unblocks: 1}, stats(t, g))
+}
+
+func TestGuard_SharesBlocksThroughStore(t *testing.T) {
+	// Setup: two replicas on one store
+	ctx := context.Background()
+	cfg := Config{MaxFailures: 2, Window: time.Minute, Cooldown: time.Minute}
+	store := NewMemoryStore()
+	first, second := New(cfg, store), New(cfg, store)
+
+	// Execute: the client spreads its attempts over both
+	_, blocked1 := first.RecordFailure(ctx, "client")
+	_, blocked2 := second.RecordFailure(ctx, "client")
+
+	// Assert
+	assert.False(t, blocked1)
+	assert.True(t, blocked2)
+	_, blocked := first.Check(ctx, "client")
+	assert.True(t, blocked)
+}
+
+func TestGuard_FailsOpenWhenStoreFails(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	g := New(Config{MaxFailures: 1, Window: time.Minute, Cooldown: time.Minute}, failingStore{})
+
+	// Execute
+	_, blockedByFailure := g.RecordFailure(ctx, "client")
+	_, blocked := g.Check(ctx, "client")
+	_, err := g.Stats(ctx)
+
+	// Assert
+	assert.False(t, blockedByFailure)
+	assert.False(t, blocked)
+	assert.Error(t, err)
+	assert.Equal(t, uint64(3), g.errors.Load(), "record, sweep and check")
+}
+
+func TestGuard_Collect(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	g, _ := newTestGuard(Config{MaxFailures: 1, Window: time.Minute, Cooldown: time.Minute})
+	g.RecordFailure(ctx, "client")
+	g.Check(ctx, "client")
+
+	// Execute & Assert
+	err := testutil.CollectAndCompare(g, strings.NewReader(`
+# HELP coupon_guard_blocked_clients Clients currently blocked from validating promo codes.
+# TYPE coupon_guard_blocked_clients gauge
+coupon_guard_blocked_clients 1
+# HELP coupon_guard_blocks_total Clients blocked after too many invalid promo codes.
+# TYPE coupon_guard_blocks_total counter
+coupon_guard_blocks_total 1
+# HELP coupon_guard_failures_total Invalid promo codes counted against clients.
+# TYPE coupon_guard_failures_total counter
+coupon_guard_failures_total 1
+# HELP coupon_guard_rejected_total Promo code attempts refused because the client was blocked.
+# TYPE coupon_guard_rejected_total counter
+coupon_guard_rejected_total 1
+# HELP coupon_guard_unblocks_total Blocks lifted by an admin.
+# TYPE coupon_guard_unblocks_total counter
+coupon_guard_unblocks_total 0
+`), "coupon_guard_blocked_clients", "coupon_guard_blocks_total", "coupon_guard_failures_total", "coupon_guard_rejected_total", "coupon_guard_unblocks_total")
+	assert.NoError(t, err)
+}
+
+// failingStore is a store that cannot be reached
+type failingStore struct{}
+
+var errStoreDown = errors.New("store down")
+
+func (failingStore) BlockedUntil(context.Context, string, time.Time) (time.Time, error) {
+	return time.Time{}, errStoreDown
+}
+
+func (failingStore) RecordFailure(context.Context, string, time.Time, Config) (bool, error) {
+	return false, errStoreDown
+}
+
+func (failingStore) Unblock(context.Context, string, time.Time) (bool, error) {
+	return false, errStoreDown
+}
+
+func (failingStore) Blocked(context.Context, time.Time) ([]Block, error) {
+	return nil, errStoreDown
+}
+
+func (failingStore) Sweep(context.Context, time.Time, time.Time) error {
+	return errStoreDown
+}
+
+// blockedClients returns the clients g reports blocked
+func blockedClients(t *testing.T, g *Guard) []string {
+	blocks, err := g.Blocked(context.Background())
+	assert.NoError(t, err)
+	return clients(blocks)
+}
+
+// stats returns the stats of g
+func stats(t *testing.T, g *Guard) Stats {
+	s, err := g.Stats(context.Background())
+	assert.NoError(t, err)
+	return s
+}
+
+func clients(blocks []Block) []string {
+	out := make([]string, len(blocks))
+	for i, b := range blocks {
+		out[i] = b.Client
+	}
+	return out
+}
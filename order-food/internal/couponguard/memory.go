@@ -0,0 +1,103 @@
+package couponguard
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type clientState struct {
+	windowStart  time.Time
+	failures     int
+	blockedUntil time.Time
+}
+
+// MemoryStore keeps clients in memory, so limits apply per instance. It is
+// meant for tests and single instance deployments.
+type MemoryStore struct {
+	mu      sync.Mutex
+	clients map[string]*clientState
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{clients: make(map[string]*clientState)}
+}
+
+// BlockedUntil returns when the block on client ends
+func (m *MemoryStore) BlockedUntil(ctx context.Context, client string, now time.Time) (time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.clients[client]
+	if !ok || !now.Before(state.blockedUntil) {
+		return time.Time{}, nil
+	}
+	return state.blockedUntil, nil
+}
+
+// RecordFailure counts an invalid attempt by client
+func (m *MemoryStore) RecordFailure(ctx context.Context, client string, now time.Time, cfg Config) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.clients[client]
+	if !ok || now.Sub(state.windowStart) >= cfg.Window {
+		state = &clientState{windowStart: now}
+		m.clients[client] = state
+	}
+
+	state.failures++
+	if state.failures < cfg.MaxFailures {
+		return false, nil
+	}
+
+	state.blockedUntil = now.Add(cfg.Cooldown)
+	state.failures = 0
+	state.windowStart = now
+	return true, nil
+}
+
+// Unblock forgets client
+func (m *MemoryStore) Unblock(ctx context.Context, client string, now time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.clients[client]
+	if !ok {
+		return false, nil
+	}
+	delete(m.clients, client)
+
+	return now.Before(state.blockedUntil), nil
+}
+
+// Blocked returns the clients blocked at now, soonest expiry first
+func (m *MemoryStore) Blocked(ctx context.Context, now time.Time) ([]Block, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	blocks := make([]Block, 0)
+	for client, state := range m.clients {
+		if now.Before(state.blockedUntil) {
+			blocks = append(blocks, Block{Client: client, Until: state.blockedUntil})
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].Until.Before(blocks[j].Until) })
+
+	return blocks, nil
+}
+
+// Sweep drops clients whose window and block have both lapsed
+func (m *MemoryStore) Sweep(ctx context.Context, windowStart, now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for client, state := range m.clients {
+		if !state.windowStart.After(windowStart) && !now.Before(state.blockedUntil) {
+			delete(m.clients, client)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package couponguard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PostgresStore keeps clients in the coupon_guard_clients table, shared by
+// every replica
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a store on db
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+// BlockedUntil returns when the block on client ends
+func (p *PostgresStore) BlockedUntil(ctx context.Context, client string, now time.Time) (time.Time, error) {
+	var until time.Time
+	err := p.db.QueryRowContext(ctx,
+		"SELECT blocked_until FROM coupon_guard_clients WHERE client = $1 AND blocked_until > $2",
+		client, now,
+	).Scan(&until)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return until, err
+}
+
+// recordFailureQuery counts a failure of $1 at $2, starting a new window
+// when the current one started at or before $3, and returns the failures
+// in the window
+const recordFailureQuery = `
+	INSERT INTO coupon_guard_clients AS g (client, window_start, failures)
+	VALUES ($1, $2, 1)
+	ON CONFLICT (client) DO UPDATE SET
+		failures = CASE WHEN g.window_start <= $3 THEN 1 ELSE g.failures + 1 END,
+		window_start = CASE WHEN g.window_start <= $3 THEN EXCLUDED.window_start ELSE g.window_start END
+	RETURNING failures`
+
+// RecordFailure counts an invalid attempt by client. Replicas counting the
+// same client at once all add to its failures, and only the one whose
+// update finds the limit reached blocks it.
+func (p *PostgresStore) RecordFailure(ctx context.Context, client string, now time.Time, cfg Config) (bool, error) {
+	var failures int
+	if err := p.db.QueryRowContext(ctx, recordFailureQuery, client, now, now.Add(-cfg.Window)).Scan(&failures); err != nil {
+		return false, err
+	}
+	if failures < cfg.MaxFailures {
+		return false, nil
+	}
+
+	result, err := p.db.ExecContext(ctx,
+		`UPDATE coupon_guard_clients SET blocked_until = $2, failures = 0, window_start = $3
+		WHERE client = $1 AND failures >= $4`,
+		client, now.Add(cfg.Cooldown), now, cfg.MaxFailures,
+	)
+	if err != nil {
+		return false, err
+	}
+	blocked, err := result.RowsAffected()
+	return blocked > 0, err
+}
+
+// Unblock forgets client
+func (p *PostgresStore) Unblock(ctx context.Context, client string, now time.Time) (bool, error) {
+	var until sql.NullTime
+	err := p.db.QueryRowContext(ctx,
+		"DELETE FROM coupon_guard_clients WHERE client = $1 RETURNING blocked_until",
+		client,
+	).Scan(&until)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return until.Valid && now.Before(until.Time), nil
+}
+
+// Blocked returns the clients blocked at now, soonest expiry first
+func (p *PostgresStore) Blocked(ctx context.Context, now time.Time) ([]Block, error) {
+	rows, err := p.db.QueryContext(ctx,
+		"SELECT client, blocked_until FROM coupon_guard_clients WHERE blocked_until > $1 ORDER BY blocked_until",
+		now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocks := make([]Block, 0)
+	for rows.Next() {
+		var block Block
+		if err := rows.Scan(&block.Client, &block.Until); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, rows.Err()
+}
+
+// Sweep drops clients whose window and block have both lapsed
+func (p *PostgresStore) Sweep(ctx context.Context, windowStart, now time.Time) error {
+	_, err := p.db.ExecContext(ctx,
+		"DELETE FROM coupon_guard_clients WHERE window_start <= $1 AND (blocked_until IS NULL OR blocked_until <= $2)",
+		windowStart, now,
+	)
+	return err
+}
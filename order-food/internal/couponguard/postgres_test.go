@@ -0,0 +1,112 @@
+package couponguard
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresStore_RecordFailureBlocksAtLimit(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	store := NewPostgresStore(db)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := Config{MaxFailures: 2, Window: time.Minute, Cooldown: 5 * time.Minute}
+
+	mock.ExpectQuery("INSERT INTO coupon_guard_clients").
+		WithArgs("client", now, now.Add(-time.Minute)).
+		WillReturnRows(sqlmock.NewRows([]string{"failures"}).AddRow(2))
+	mock.ExpectExec("UPDATE coupon_guard_clients SET blocked_until").
+		WithArgs("client", now.Add(5*time.Minute), now, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute
+	blocked, err := store.RecordFailure(context.Background(), "client", now, cfg)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStore_RecordFailureBlockedByOtherReplica(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	store := NewPostgresStore(db)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("INSERT INTO coupon_guard_clients").
+		WillReturnRows(sqlmock.NewRows([]string{"failures"}).AddRow(3))
+	// Another replica blocked the client and reset its failures first
+	mock.ExpectExec("UPDATE coupon_guard_clients SET blocked_until").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute
+	blocked, err := store.RecordFailure(context.Background(), "client", now, Config{MaxFailures: 2, Window: time.Minute, Cooldown: time.Minute})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStore_BlockedUntil(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	store := NewPostgresStore(db)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT blocked_until FROM coupon_guard_clients").
+		WithArgs("blocked", now).
+		WillReturnRows(sqlmock.NewRows([]string{"blocked_until"}).AddRow(now.Add(time.Minute)))
+	mock.ExpectQuery("SELECT blocked_until FROM coupon_guard_clients").
+		WithArgs("free", now).
+		WillReturnRows(sqlmock.NewRows([]string{"blocked_until"}))
+
+	// Execute
+	blockedUntil, blockedErr := store.BlockedUntil(context.Background(), "blocked", now)
+	freeUntil, freeErr := store.BlockedUntil(context.Background(), "free", now)
+
+	// Assert
+	assert.NoError(t, blockedErr)
+	assert.Equal(t, now.Add(time.Minute), blockedUntil)
+	assert.NoError(t, freeErr)
+	assert.True(t, freeUntil.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStore_Unblock(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	store := NewPostgresStore(db)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("DELETE FROM coupon_guard_clients WHERE client = \\$1 RETURNING blocked_until").
+		WithArgs("blocked").
+		WillReturnRows(sqlmock.NewRows([]string{"blocked_until"}).AddRow(now.Add(time.Minute)))
+	mock.ExpectQuery("DELETE FROM coupon_guard_clients WHERE client = \\$1 RETURNING blocked_until").
+		WithArgs("failing").
+		WillReturnRows(sqlmock.NewRows([]string{"blocked_until"}).AddRow(nil))
+
+	// Execute
+	unblocked, unblockedErr := store.Unblock(context.Background(), "blocked", now)
+	failing, failingErr := store.Unblock(context.Background(), "failing", now)
+
+	// Assert
+	assert.NoError(t, unblockedErr)
+	assert.True(t, unblocked)
+	assert.NoError(t, failingErr)
+	assert.False(t, failing, "a client that only failed was not blocked")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// WithAdvisoryLock runs fn while holding the PostgreSQL advisory lock named
+// key and reports whether the lock was acquired. It does not wait: when
+// another session holds the lock, fn is not called and false is returned.
+//
+// The lock is transaction-scoped and held by a transaction that stays open
+// until fn returns, so it works through transaction poolers, where session
+// locks would be released as soon as the pooler reassigned the connection.
+// fn runs its own queries on other connections; one pool connection stays
+// busy for as long as fn runs. The lock is released when fn returns or
+// when the holder's connection is lost.
+func WithAdvisoryLock(ctx context.Context, db *sql.DB, key string, fn func(ctx context.Context) error) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin advisory lock transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var acquired bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock(hashtextextended($1, 0))`, key).Scan(&acquired); err != nil {
+		return false, fmt.Errorf("error acquiring advisory lock %q: %w", key, err)
+	}
+	if !acquired {
+		return false, nil
+	}
+
+	if err := fn(ctx); err != nil {
+		return true, err
+	}
+	if err := tx.Commit(); err != nil {
+		return true, fmt.Errorf("failed to release advisory lock %q: %w", key, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,123 @@
+// Package database opens the PostgreSQL connection pool. Credentials are
+// resolved through an app.CredentialProvider each time a new connection is
+// dialled, so rotated passwords are picked up without a restart.
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
+)
+
+// Config holds the non-secret connection settings
+type Config struct {
+	// Host is a comma-separated list of the hosts of the cluster, each
+	// optionally with its own port, tried in order until one is the primary
+	Host    string
+	Port    string
+	DBName  string
+	SSLMode string
+	// ConnMaxLifetime bounds how long a pooled connection lives, and so how
+	// long connections opened with old credentials survive a rotation
+	ConnMaxLifetime time.Duration
+	// PoolMode selects protocol settings that work through a pooler
+	PoolMode PoolMode
+	// Queries, when set, counts the statements run on every connection and
+	// logs slow ones
+	Queries *QueryLog
+}
+
+// connector dials PostgreSQL with freshly resolved credentials
+type connector struct {
+	cfg      Config
+	creds    app.CredentialProvider
+	failover *Failover
+}
+
+// Connect opens a new connection to the primary
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.failover.Connect(ctx)
+	if err != nil || c.cfg.Queries == nil {
+		return conn, err
+	}
+	return c.cfg.Queries.wrap(conn), nil
+}
+
+// dial resolves the current credentials and opens a new connection to
+// endpoint
+func (c *connector) dial(ctx context.Context, endpoint Endpoint) (driver.Conn, error) {
+	name, err := c.dsn(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	pqConnector, err := pq.NewConnector(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection settings: %w", err)
+	}
+	return pqConnector.Connect(ctx)
+}
+
+// dsn resolves the current credentials and returns the connection URL of
+// endpoint
+func (c *connector) dsn(ctx context.Context, endpoint Endpoint) (string, error) {
+	creds, err := c.creds.Credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	cfg := c.cfg
+	cfg.Host, cfg.Port = endpoint.Host, endpoint.Port
+	return dsn(cfg, creds), nil
+}
+
+// Driver returns the underlying PostgreSQL driver
+func (c *connector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// Open returns a connection pool whose new connections use the credentials
+// returned by creds at dial time, and the Failover choosing the host each
+// of them goes to
+func Open(cfg Config, creds app.CredentialProvider) (*sql.DB, *Failover, error) {
+	endpoints, err := ParseEndpoints(cfg.Host, cfg.Port)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := &connector{cfg: cfg, creds: creds}
+	c.failover = newFailover(endpoints, c.dial)
+	c.failover.dsn = c.dsn
+	db := sql.OpenDB(c)
+	if cfg.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	return db, c.failover, nil
+}
+
+// dsn builds a lib/pq connection URL, escaping the credentials
+func dsn(cfg Config, creds app.Credentials) string {
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(creds.User, creds.Password),
+		Host:   cfg.Host + ":" + cfg.Port,
+		Path:   "/" + cfg.DBName,
+	}
+	q := url.Values{}
+	q.Set("sslmode", cfg.SSLMode)
+	// Pin the session to UTC so timestamps do not depend on the server's
+	// configured zone; business zones are applied explicitly in queries
+	q.Set("timezone", "UTC")
+	if cfg.PoolMode == PoolModeTransaction {
+		// Send parameters with each query instead of describing a statement
+		// first, so every query is a single round trip on one backend
+		q.Set("binary_parameters", "yes")
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
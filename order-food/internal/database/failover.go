@@ -0,0 +1,311 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrNoPrimary is returned when no endpoint accepts connections as a
+// primary, such as while a standby is being promoted
+var ErrNoPrimary = errors.New("no database endpoint is a reachable primary")
+
+// Descriptors of the failover metrics
+var (
+	failoversDesc       = prometheus.NewDesc("db_failovers_total", "Times new database connections moved to another endpoint.", nil, nil)
+	connectFailuresDesc = prometheus.NewDesc("db_connect_failures_total", "Database endpoints that could not be connected to or were not the primary.", nil, nil)
+	endpointUpDesc      = prometheus.NewDesc("db_endpoint_up", "1 when the latest probe reached the database endpoint.", []string{"endpoint"}, nil)
+	endpointPrimaryDesc = prometheus.NewDesc("db_endpoint_primary", "1 when the latest probe found the database endpoint to be the primary.", []string{"endpoint"}, nil)
+	endpointCurrentDesc = prometheus.NewDesc("db_endpoint_current", "1 for the database endpoint new connections go to.", []string{"endpoint"}, nil)
+)
+
+// Endpoint is a host and port PostgreSQL is reached at
+type Endpoint struct {
+	Host string
+	Port string
+}
+
+// String returns the endpoint as host:port
+func (e Endpoint) String() string {
+	return net.JoinHostPort(e.Host, e.Port)
+}
+
+// ParseEndpoints parses a comma-separated list of hosts, each optionally
+// followed by :port, in the order they should be tried
+func ParseEndpoints(hosts, defaultPort string) ([]Endpoint, error) {
+	var endpoints []Endpoint
+	for _, host := range strings.Split(hosts, ",") {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+		endpoint := Endpoint{Host: host, Port: defaultPort}
+		if h, p, err := net.SplitHostPort(host); err == nil {
+			endpoint = Endpoint{Host: h, Port: p}
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if len(endpoints) == 0 {
+		return nil, errors.New("no database host configured")
+	}
+	return endpoints, nil
+}
+
+// EndpointHealth is the result of the latest probe of an endpoint
+type EndpointHealth struct {
+	Endpoint Endpoint
+	Up       bool
+	Primary  bool
+	Latency  time.Duration
+	Error    string
+}
+
+// dialFunc opens a connection to an endpoint
+type dialFunc func(ctx context.Context, endpoint Endpoint) (driver.Conn, error)
+
+// Failover routes new connections to the primary of a replicated cluster,
+// such as one managed by Patroni. Endpoints are tried in order starting
+// with the last one found to be the primary; standbys are skipped, so
+// connections move to a promoted standby once the old primary is gone.
+// With a single endpoint connections are not checked, so a pooler or a
+// load balancer that routes to the primary keeps working as before. It is
+// safe for concurrent use.
+type Failover struct {
+	endpoints []Endpoint
+	dial      dialFunc
+	// dsn returns the connection URL of an endpoint; nil when the failover
+	// was not created by Open
+	dsn func(ctx context.Context, endpoint Endpoint) (string, error)
+
+	current         atomic.Int32
+	failovers       atomic.Int64
+	connectFailures atomic.Int64
+
+	mu     sync.Mutex
+	health []EndpointHealth
+}
+
+// newFailover creates a failover over endpoints, starting with the first
+func newFailover(endpoints []Endpoint, dial dialFunc) *Failover {
+	return &Failover{endpoints: endpoints, dial: dial}
+}
+
+// Endpoints returns the endpoints in the order they are tried when the
+// current one fails
+func (f *Failover) Endpoints() []Endpoint {
+	return f.endpoints
+}
+
+// Current returns the endpoint new connections go to first
+func (f *Failover) Current() Endpoint {
+	return f.endpoints[f.current.Load()]
+}
+
+// DSN returns the connection URL of the current endpoint with freshly
+// resolved credentials, for a connection kept outside the pool, such as
+// one listening for notifications. The endpoint is the last one found to
+// be the primary, but is not checked again.
+func (f *Failover) DSN(ctx context.Context) (string, error) {
+	if f.dsn == nil {
+		return "", fmt.Errorf("no connection settings")
+	}
+	return f.dsn(ctx, f.Current())
+}
+
+// Connect opens a connection to the first endpoint, starting with the
+// current one, that is reachable and not in recovery
+func (f *Failover) Connect(ctx context.Context) (driver.Conn, error) {
+	if len(f.endpoints) == 1 {
+		return f.dial(ctx, f.endpoints[0])
+	}
+
+	start := int(f.current.Load())
+	var errs []error
+	for i := range f.endpoints {
+		index := (start + i) % len(f.endpoints)
+		endpoint := f.endpoints[index]
+		conn, err := f.dialPrimary(ctx, endpoint)
+		if err != nil {
+			f.connectFailures.Add(1)
+			errs = append(errs, fmt.Errorf("%s: %w", endpoint, err))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		f.use(index)
+		return conn, nil
+	}
+	return nil, fmt.Errorf("%w: %w", ErrNoPrimary, errors.Join(errs...))
+}
+
+// dialPrimary opens a connection to endpoint and closes it again when the
+// server is a standby
+func (f *Failover) dialPrimary(ctx context.Context, endpoint Endpoint) (driver.Conn, error) {
+	conn, err := f.dial(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	standby, err := inRecovery(ctx, conn)
+	if err == nil && standby {
+		err = errors.New("server is a standby")
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// use makes the endpoint at index the current one, logging a failover when
+// it changes
+func (f *Failover) use(index int) {
+	previous := int(f.current.Swap(int32(index)))
+	if previous != index {
+		f.failovers.Add(1)
+		slog.Warn("Database failover", "endpoint", f.endpoints[index], "previous", f.endpoints[previous])
+	}
+}
+
+// Probe connects to every endpoint, records whether it is up and whether it
+// is the primary, and moves new connections to the primary when the
+// current endpoint is no longer one
+func (f *Failover) Probe(ctx context.Context) []EndpointHealth {
+	health := make([]EndpointHealth, len(f.endpoints))
+	for i, endpoint := range f.endpoints {
+		health[i] = f.probe(ctx, endpoint)
+	}
+
+	f.mu.Lock()
+	f.health = health
+	f.mu.Unlock()
+
+	if current := f.current.Load(); !health[current].Primary {
+		for i, h := range health {
+			if h.Primary {
+				f.use(i)
+				break
+			}
+		}
+	}
+	return health
+}
+
+// probe checks a single endpoint
+func (f *Failover) probe(ctx context.Context, endpoint Endpoint) EndpointHealth {
+	health := EndpointHealth{Endpoint: endpoint}
+	start := time.Now()
+	conn, err := f.dial(ctx, endpoint)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	defer conn.Close()
+
+	standby, err := inRecovery(ctx, conn)
+	health.Latency = time.Since(start)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	health.Up = true
+	health.Primary = !standby
+	return health
+}
+
+// Health returns the result of the latest probe, or nil before the first
+func (f *Failover) Health() []EndpointHealth {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.health
+}
+
+// Run probes the endpoints every interval until ctx is cancelled. Probing
+// finds a promoted standby before a request has to fail over to it.
+func (f *Failover) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		probeCtx, cancel := context.WithTimeout(ctx, interval)
+		f.Probe(probeCtx)
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Describe sends the descriptors of the failover metrics
+func (f *Failover) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{failoversDesc, connectFailuresDesc, endpointUpDesc, endpointPrimaryDesc, endpointCurrentDesc} {
+		ch <- desc
+	}
+}
+
+// Collect sends the failover counters and the latest probe of each
+// endpoint
+func (f *Failover) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(failoversDesc, prometheus.CounterValue, float64(f.failovers.Load()))
+	ch <- prometheus.MustNewConstMetric(connectFailuresDesc, prometheus.CounterValue, float64(f.connectFailures.Load()))
+
+	current := f.Current()
+	gauges := []struct {
+		desc  *prometheus.Desc
+		value func(h EndpointHealth) bool
+	}{
+		{endpointUpDesc, func(h EndpointHealth) bool { return h.Up }},
+		{endpointPrimaryDesc, func(h EndpointHealth) bool { return h.Primary }},
+		{endpointCurrentDesc, func(h EndpointHealth) bool { return h.Endpoint == current }},
+	}
+	health := f.Health()
+	if health == nil {
+		// Before the first probe only the current endpoint is known
+		health = make([]EndpointHealth, len(f.endpoints))
+		for i, endpoint := range f.endpoints {
+			health[i] = EndpointHealth{Endpoint: endpoint}
+		}
+		gauges = gauges[2:]
+	}
+	for _, g := range gauges {
+		for _, h := range health {
+			value := 0.0
+			if g.value(h) {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(g.desc, prometheus.GaugeValue, value, h.Endpoint.String())
+		}
+	}
+}
+
+// inRecovery reports whether the server of conn is a standby
+func inRecovery(ctx context.Context, conn driver.Conn) (bool, error) {
+	queryer, ok := conn.(driver.QueryerContext)
+	if !ok {
+		return false, errors.New("driver cannot run queries without preparing them")
+	}
+	rows, err := queryer.QueryContext(ctx, `SELECT pg_is_in_recovery()`, nil)
+	if err != nil {
+		return false, fmt.Errorf("error checking recovery status: %w", err)
+	}
+	defer rows.Close()
+
+	values := make([]driver.Value, 1)
+	if err := rows.Next(values); err != nil {
+		return false, fmt.Errorf("error checking recovery status: %w", err)
+	}
+	standby, _ := values[0].(bool)
+	return standby, nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// PoolMode describes how connections reach PostgreSQL
+type PoolMode string
+
+const (
+	// PoolModeSession is a direct connection, or a pooler in session mode,
+	// where a client keeps one server connection for its whole session
+	PoolModeSession PoolMode = "session"
+	// PoolModeTransaction is a pooler such as pgbouncer in transaction mode,
+	// where consecutive transactions may run on different server
+	// connections, so no session state (prepared statements, SET, advisory
+	// locks) can be relied on
+	PoolModeTransaction PoolMode = "transaction"
+)
+
+// ParsePoolMode validates a DB_POOL_MODE value; empty means session
+func ParsePoolMode(value string) (PoolMode, error) {
+	switch PoolMode(value) {
+	case "", PoolModeSession:
+		return PoolModeSession, nil
+	case PoolModeTransaction:
+		return PoolModeTransaction, nil
+	}
+	return "", fmt.Errorf("unknown pool mode %q, expected %q or %q", value, PoolModeSession, PoolModeTransaction)
+}
+
+// poolerProbes is the number of statements compared by DetectTransactionPooler
+const poolerProbes = 3
+
+// DetectTransactionPooler reports whether statements sent over a single
+// client connection are served by different backends, which only happens
+// behind a transaction-pooling proxy. An idle pooler may hand back the same
+// backend every time, so false means "not detected" rather than "absent".
+func DetectTransactionPooler(ctx context.Context, db *sql.DB) (bool, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	var first int
+	for i := 0; i < poolerProbes; i++ {
+		var pid int
+		if err := conn.QueryRowContext(ctx, `SELECT pg_backend_pid()`).Scan(&pid); err != nil {
+			return false, fmt.Errorf("error querying backend PID: %w", err)
+		}
+		if i == 0 {
+			first = pid
+		} else if pid != first {
+			return true, nil
+		}
+	}
+	return false, nil
+}
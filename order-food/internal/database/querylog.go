@@ -0,0 +1,266 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultSlowQueryThreshold is how long a query may take before it is
+// logged as slow
+const DefaultSlowQueryThreshold = 500 * time.Millisecond
+
+// Descriptors of the query metrics
+var (
+	queriesDesc      = prometheus.NewDesc("db_queries_total", "Database statements run, by logical query.", []string{"query"}, nil)
+	slowQueriesDesc  = prometheus.NewDesc("db_slow_queries_total", "Database statements that took longer than the slow query threshold, by logical query.", []string{"query"}, nil)
+	queryErrorsDesc  = prometheus.NewDesc("db_query_errors_total", "Database statements that failed, by logical query.", []string{"query"}, nil)
+	querySecondsDesc = prometheus.NewDesc("db_query_seconds_total", "Time spent running database statements and reading their rows, by logical query.", []string{"query"}, nil)
+)
+
+// maxLoggedQueries bounds the logical queries counted separately; later
+// ones are counted as "other", so dynamic SQL cannot grow /metrics without
+// bound
+const maxLoggedQueries = 500
+
+// maxStatementLength bounds the statement text in a slow query record
+const maxStatementLength = 1000
+
+var (
+	// whitespace collapses the layout of a statement
+	whitespace = regexp.MustCompile(`\s+`)
+	// stringLiteral matches SQL string literals, which could hold values
+	stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	// firstTable finds the table a statement reads or writes first
+	firstTable = regexp.MustCompile(`(?i)\b(?:FROM|INTO|UPDATE|TABLE)\s+([a-z_][a-z0-9_.]*)`)
+)
+
+// QueryLog counts the statements run on connections it wraps, per logical
+// query, and logs those taking longer than its threshold with their name,
+// duration and rows. Parameters are never logged, and string literals in
+// the statement text are redacted. A query lasts until its rows have been
+// read and closed. It is safe for concurrent use.
+type QueryLog struct {
+	threshold time.Duration
+
+	mu      sync.Mutex
+	queries map[string]*queryStats
+}
+
+// queryStats are the counters of one logical query
+type queryStats struct {
+	calls   int64
+	slow    int64
+	errors  int64
+	seconds float64
+}
+
+// NewQueryLog creates a query log logging queries slower than threshold;
+// a threshold of zero or less logs none, while still counting them
+func NewQueryLog(threshold time.Duration) *QueryLog {
+	return &QueryLog{threshold: threshold, queries: make(map[string]*queryStats)}
+}
+
+// QueryName names the logical query of statement after its command, the
+// first table it touches and a hash of its text, such as
+// select_orders_1a2b3c4d. Statements differing only in layout share a name.
+func QueryName(statement string) string {
+	normalized := normalizeStatement(statement)
+	command, _, _ := strings.Cut(normalized, " ")
+	name := strings.ToLower(command)
+	if match := firstTable.FindStringSubmatch(normalized); match != nil {
+		name += "_" + strings.ToLower(match[1])
+	}
+	h := fnv.New32a()
+	h.Write([]byte(normalized))
+	return fmt.Sprintf("%s_%08x", name, h.Sum32())
+}
+
+// normalizeStatement collapses the whitespace of statement
+func normalizeStatement(statement string) string {
+	return strings.TrimSpace(whitespace.ReplaceAllString(statement, " "))
+}
+
+// observe records a run of statement that took duration and returned or
+// changed rows, with rows -1 when unknown
+func (l *QueryLog) observe(ctx context.Context, statement string, duration time.Duration, rows int64, err error) {
+	name := QueryName(statement)
+	slow := l.threshold > 0 && duration >= l.threshold
+
+	l.mu.Lock()
+	stats, ok := l.queries[name]
+	if !ok {
+		if len(l.queries) >= maxLoggedQueries {
+			name = "other"
+			stats = l.queries[name]
+		}
+		if stats == nil {
+			stats = &queryStats{}
+			l.queries[name] = stats
+		}
+	}
+	stats.calls++
+	stats.seconds += duration.Seconds()
+	if slow {
+		stats.slow++
+	}
+	if err != nil {
+		stats.errors++
+	}
+	l.mu.Unlock()
+
+	if slow {
+		text := stringLiteral.ReplaceAllString(normalizeStatement(statement), "'?'")
+		if len(text) > maxStatementLength {
+			text = text[:maxStatementLength] + "…"
+		}
+		attrs := []any{"query", name, "duration", duration, "statement", text}
+		if rows >= 0 {
+			attrs = append(attrs, "rows", rows)
+		}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+		}
+		slog.WarnContext(ctx, "Slow query", attrs...)
+	}
+}
+
+// Describe sends the descriptors of the query metrics
+func (l *QueryLog) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{queriesDesc, slowQueriesDesc, queryErrorsDesc, querySecondsDesc} {
+		ch <- desc
+	}
+}
+
+// Collect sends the counters of every logical query
+func (l *QueryLog) Collect(ch chan<- prometheus.Metric) {
+	l.mu.Lock()
+	stats := make(map[string]queryStats, len(l.queries))
+	for name, s := range l.queries {
+		stats[name] = *s
+	}
+	l.mu.Unlock()
+
+	for query, s := range stats {
+		ch <- prometheus.MustNewConstMetric(queriesDesc, prometheus.CounterValue, float64(s.calls), query)
+		ch <- prometheus.MustNewConstMetric(slowQueriesDesc, prometheus.CounterValue, float64(s.slow), query)
+		ch <- prometheus.MustNewConstMetric(queryErrorsDesc, prometheus.CounterValue, float64(s.errors), query)
+		ch <- prometheus.MustNewConstMetric(querySecondsDesc, prometheus.CounterValue, s.seconds, query)
+	}
+}
+
+// wrap returns conn recording the statements run on it. Prepared
+// statements are passed through unrecorded.
+func (l *QueryLog) wrap(conn driver.Conn) driver.Conn {
+	return &loggedConn{Conn: conn, log: l}
+}
+
+// loggedConn records the statements run through the context interfaces,
+// which database/sql prefers when a connection implements them
+type loggedConn struct {
+	driver.Conn
+	log *QueryLog
+}
+
+func (c *loggedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		c.log.observe(ctx, query, time.Since(start), -1, err)
+		return nil, err
+	}
+	return &loggedRows{Rows: rows, ctx: ctx, log: c.log, query: query, start: start}, nil
+}
+
+func (c *loggedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	rows := int64(-1)
+	if err == nil {
+		if affected, affectedErr := result.RowsAffected(); affectedErr == nil {
+			rows = affected
+		}
+	}
+	c.log.observe(ctx, query, time.Since(start), rows, err)
+	return result, err
+}
+
+func (c *loggedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *loggedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return nil, fmt.Errorf("database driver does not support transaction options")
+}
+
+func (c *loggedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *loggedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *loggedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// loggedRows records its query once its rows have been read and closed
+type loggedRows struct {
+	driver.Rows
+	ctx   context.Context
+	log   *QueryLog
+	query string
+	start time.Time
+	rows  int64
+	err   error
+}
+
+func (r *loggedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	switch {
+	case err == nil:
+		r.rows++
+	case err != io.EOF:
+		r.err = err
+	}
+	return err
+}
+
+func (r *loggedRows) Close() error {
+	err := r.Rows.Close()
+	r.log.observe(r.ctx, r.query, time.Since(r.start), r.rows, r.err)
+	return err
+}
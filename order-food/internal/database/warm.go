@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// WarmPool opens n connections and runs each of queries once on every one
+// of them before returning them to the pool. A new backend loads its
+// catalog caches and plans its first statements slowly; doing it here keeps
+// that latency off the first requests. The pool only keeps as many
+// connections as its idle limit, so n should not exceed it.
+func WarmPool(ctx context.Context, db *sql.DB, n int, queries ...string) error {
+	conns := make([]*sql.Conn, 0, n)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	// Holding every connection until the end makes each db.Conn open a new
+	// one instead of reusing the last
+	for range n {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to open connection: %w", err)
+		}
+		conns = append(conns, conn)
+
+		for _, query := range queries {
+			rows, err := conn.QueryContext(ctx, query)
+			if err != nil {
+				return fmt.Errorf("error running warm-up query: %w", err)
+			}
+			rows.Close()
+		}
+	}
+	return nil
+}
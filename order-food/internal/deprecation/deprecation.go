@@ -0,0 +1,236 @@
+// Package deprecation keeps the registry of API routes and request fields
+// flagged for removal. Callers of a deprecated element are told with the
+// Deprecation (RFC 9745), Sunset (RFC 8594) and Link successor-version
+// headers, and each use is counted so the old version can be retired once
+// nobody relies on it any more.
+package deprecation
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Response headers announcing a deprecation
+const (
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+	LinkHeader        = "Link"
+)
+
+// dateLayout is the layout of dates in a deprecation list
+const dateLayout = "2006-01-02"
+
+// Deprecation flags a route, or one request field of it, for removal
+type Deprecation struct {
+	// Method and Route name the route as registered, e.g. GET
+	// /api/v1/orders/:orderId
+	Method string
+	Route  string
+	// Field is a query parameter or top-level JSON body field; the whole
+	// route is deprecated when it is empty
+	Field string
+	// Since is when the element was deprecated
+	Since time.Time
+	// Sunset is when the element is expected to be removed; zero when not
+	// yet planned
+	Sunset time.Time
+	// Successor is the URL of the replacement, if there is one
+	Successor string
+}
+
+// Name identifies the element, e.g. "POST /api/v1/orders#couponCode"
+func (d Deprecation) Name() string {
+	name := d.Method + " " + d.Route
+	if d.Field != "" {
+		name += "#" + d.Field
+	}
+	return name
+}
+
+// SetHeaders announces the deprecation on a response. Headers of
+// several deprecated elements used by one request are combined.
+func (d Deprecation) SetHeaders(h http.Header) {
+	if h.Get(DeprecationHeader) == "" {
+		h.Set(DeprecationHeader, fmt.Sprintf("@%d", d.Since.Unix()))
+	}
+	if !d.Sunset.IsZero() {
+		sunset := d.Sunset.UTC().Format(http.TimeFormat)
+		if current := h.Get(SunsetHeader); current == "" || d.Sunset.Before(parseHTTPDate(current)) {
+			// The earliest removal is the one callers must plan for
+			h.Set(SunsetHeader, sunset)
+		}
+	}
+	if d.Successor != "" {
+		link := fmt.Sprintf("<%s>; rel=\"successor-version\"", d.Successor)
+		for _, existing := range h.Values(LinkHeader) {
+			if existing == link {
+				return
+			}
+		}
+		h.Add(LinkHeader, link)
+	}
+}
+
+func parseHTTPDate(value string) time.Time {
+	t, _ := http.ParseTime(value)
+	return t
+}
+
+// Parse parses a semicolon-separated list of deprecations, each a route
+// with an optional #field followed by key=value settings, e.g.
+// "GET /api/v1/orders since=2026-10-01 sunset=2027-04-01
+// successor=/api/v2/orders; POST /api/v1/orders#couponCode since=2026-10-01".
+// since is required; sunset and successor are optional.
+func Parse(spec string) ([]Deprecation, error) {
+	var deprecations []Deprecation
+	for _, entry := range strings.Split(spec, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid deprecation %q, want METHOD /route[#field] since=YYYY-MM-DD", strings.TrimSpace(entry))
+		}
+
+		d := Deprecation{Method: strings.ToUpper(fields[0])}
+		d.Route, d.Field, _ = strings.Cut(fields[1], "#")
+		if !strings.HasPrefix(d.Route, "/") {
+			return nil, fmt.Errorf("invalid deprecation %q: route must start with /", strings.TrimSpace(entry))
+		}
+		for _, setting := range fields[2:] {
+			if err := d.set(setting); err != nil {
+				return nil, fmt.Errorf("invalid deprecation of %s: %w", d.Name(), err)
+			}
+		}
+		if d.Since.IsZero() {
+			return nil, fmt.Errorf("invalid deprecation of %s: since is required", d.Name())
+		}
+		if !d.Sunset.IsZero() && d.Sunset.Before(d.Since) {
+			return nil, fmt.Errorf("invalid deprecation of %s: sunset is before since", d.Name())
+		}
+		deprecations = append(deprecations, d)
+	}
+	return deprecations, nil
+}
+
+// set applies one key=value setting
+func (d *Deprecation) set(setting string) error {
+	key, value, ok := strings.Cut(setting, "=")
+	if !ok || value == "" {
+		return fmt.Errorf("%q is not key=value", setting)
+	}
+	switch key {
+	case "since", "sunset":
+		date, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return fmt.Errorf("invalid %s date %q, want YYYY-MM-DD", key, value)
+		}
+		if key == "since" {
+			d.Since = date
+		} else {
+			d.Sunset = date
+		}
+	case "successor":
+		d.Successor = value
+	default:
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	return nil
+}
+
+// Route holds the deprecations of one route
+type Route struct {
+	// Deprecation is set when the whole route is deprecated
+	Deprecation *Deprecation
+	// Fields are the deprecated fields of the route, by name
+	Fields map[string]Deprecation
+}
+
+// deprecatedRequestsDesc describes the use counts of deprecated elements
+var deprecatedRequestsDesc = prometheus.NewDesc("deprecated_requests_total", "Requests using a deprecated route or field.", []string{"method", "route", "field"}, nil)
+
+// Registry looks up the deprecations of routes and counts their use
+type Registry struct {
+	routes map[string]*Route
+
+	mu   sync.Mutex
+	uses map[string]int64
+}
+
+// NewRegistry creates a registry of deprecations, rejecting an element
+// listed twice
+func NewRegistry(deprecations []Deprecation) (*Registry, error) {
+	r := &Registry{routes: make(map[string]*Route), uses: make(map[string]int64)}
+	for _, d := range deprecations {
+		if _, dup := r.uses[d.Name()]; dup {
+			return nil, fmt.Errorf("deprecation of %s is given twice", d.Name())
+		}
+		r.uses[d.Name()] = 0
+
+		key := d.Method + " " + d.Route
+		route := r.routes[key]
+		if route == nil {
+			route = &Route{Fields: make(map[string]Deprecation)}
+			r.routes[key] = route
+		}
+		if d.Field == "" {
+			route.Deprecation = &d
+		} else {
+			route.Fields[d.Field] = d
+		}
+	}
+	return r, nil
+}
+
+// Lookup returns the deprecations of the route registered as method and
+// route, or nil when nothing about it is deprecated
+func (r *Registry) Lookup(method, route string) *Route {
+	return r.routes[method+" "+route]
+}
+
+// Deprecations returns every registered deprecation ordered by name
+func (r *Registry) Deprecations() []Deprecation {
+	var deprecations []Deprecation
+	for _, route := range r.routes {
+		if route.Deprecation != nil {
+			deprecations = append(deprecations, *route.Deprecation)
+		}
+		for _, d := range route.Fields {
+			deprecations = append(deprecations, d)
+		}
+	}
+	sort.Slice(deprecations, func(i, j int) bool { return deprecations[i].Name() < deprecations[j].Name() })
+	return deprecations
+}
+
+// Record counts a use of d
+func (r *Registry) Record(d Deprecation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.uses[d.Name()]++
+}
+
+// Uses returns how often d has been used
+func (r *Registry) Uses(d Deprecation) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.uses[d.Name()]
+}
+
+// Describe sends the descriptor of the use counts
+func (r *Registry) Describe(ch chan<- *prometheus.Desc) {
+	ch <- deprecatedRequestsDesc
+}
+
+// Collect sends the use count of every deprecated element
+func (r *Registry) Collect(ch chan<- prometheus.Metric) {
+	for _, d := range r.Deprecations() {
+		ch <- prometheus.MustNewConstMetric(deprecatedRequestsDesc, prometheus.CounterValue, float64(r.Uses(d)), d.Method, d.Route, d.Field)
+	}
+}
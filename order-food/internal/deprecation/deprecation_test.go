@@ -0,0 +1,97 @@
+package deprecation
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	deprecations, err := Parse(" get /api/v1/orders since=2026-10-01 sunset=2027-04-01 successor=/api/v2/orders; POST /api/v1/orders#couponCode since=2026-10-01;")
+	assert.NoError(t, err)
+	assert.Equal(t, []Deprecation{
+		{
+			Method:    "GET",
+			Route:     "/api/v1/orders",
+			Since:     time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			Sunset:    time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC),
+			Successor: "/api/v2/orders",
+		},
+		{Method: "POST", Route: "/api/v1/orders", Field: "couponCode", Since: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+	}, deprecations)
+	assert.Equal(t, "POST /api/v1/orders#couponCode", deprecations[1].Name())
+
+	deprecations, err = Parse("")
+	assert.NoError(t, err)
+	assert.Empty(t, deprecations)
+
+	for _, spec := range []string{
+		"GET",
+		"GET api/v1/orders since=2026-10-01",
+		"GET /api/v1/orders",
+		"GET /api/v1/orders since=01.10.2026",
+		"GET /api/v1/orders since=2026-10-01 sunset=2026-09-01",
+		"GET /api/v1/orders since=2026-10-01 until=2027-01-01",
+		"GET /api/v1/orders since=2026-10-01 successor",
+	} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestNewRegistry(t *testing.T) {
+	deprecations, err := Parse("GET /api/v1/orders since=2026-10-01; GET /api/v1/orders#status since=2026-10-01")
+	assert.NoError(t, err)
+
+	// Execute
+	registry, err := NewRegistry(deprecations)
+
+	// Assert
+	assert.NoError(t, err)
+	route := registry.Lookup("GET", "/api/v1/orders")
+	assert.NotNil(t, route.Deprecation)
+	assert.Contains(t, route.Fields, "status")
+	assert.Nil(t, registry.Lookup("POST", "/api/v1/orders"))
+
+	_, err = NewRegistry(append(deprecations, deprecations[1]))
+	assert.ErrorContains(t, err, "GET /api/v1/orders#status is given twice")
+}
+
+func TestDeprecation_SetHeaders(t *testing.T) {
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	route := Deprecation{Method: "GET", Route: "/api/v1/orders", Since: since, Sunset: time.Date(2027, 4, 1, 0, 0, 0, 0, time.UTC), Successor: "/api/v2/orders"}
+	field := Deprecation{Method: "GET", Route: "/api/v1/orders", Field: "status", Since: since.AddDate(0, 1, 0), Sunset: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), Successor: "/api/v2/orders"}
+
+	// Execute
+	header := http.Header{}
+	route.SetHeaders(header)
+	field.SetHeaders(header)
+
+	// Assert: the earliest sunset wins and the successor is linked once
+	assert.Equal(t, "@1790812800", header.Get(DeprecationHeader))
+	assert.Equal(t, "Fri, 01 Jan 2027 00:00:00 GMT", header.Get(SunsetHeader))
+	assert.Equal(t, []string{`</api/v2/orders>; rel="successor-version"`}, header.Values(LinkHeader))
+}
+
+func TestRegistry_Collect(t *testing.T) {
+	// Setup
+	deprecations, err := Parse("GET /api/v1/orders since=2026-10-01; POST /api/v1/orders#couponCode since=2026-10-01")
+	assert.NoError(t, err)
+	registry, err := NewRegistry(deprecations)
+	assert.NoError(t, err)
+	registry.Record(deprecations[1])
+	registry.Record(deprecations[1])
+
+	// Execute & Assert
+	err = testutil.CollectAndCompare(registry, strings.NewReader(`
+# HELP deprecated_requests_total Requests using a deprecated route or field.
+# TYPE deprecated_requests_total counter
+deprecated_requests_total{field="",method="GET",route="/api/v1/orders"} 0
+deprecated_requests_total{field="couponCode",method="POST",route="/api/v1/orders"} 2
+`))
+	assert.NoError(t, err)
+}
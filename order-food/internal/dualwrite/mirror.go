@@ -0,0 +1,303 @@
+// Package dualwrite mirrors the writes of the repositories to a second
+// datastore, such as a CockroachDB cluster under evaluation, and verifies
+// that its copy matches PostgreSQL. Mirroring is asynchronous and best
+// effort: PostgreSQL stays the source of truth, a write that cannot be
+// mirrored is only counted, and Verify finds and repairs what was missed.
+package dualwrite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+// DefaultQueueSize is how many changes wait to be mirrored before new ones
+// are dropped
+const DefaultQueueSize = 10_000
+
+// copyTimeout bounds mirroring a single row
+const copyTimeout = 10 * time.Second
+
+// table describes how the rows of a mirrored table are copied
+type table struct {
+	name    string
+	key     string
+	columns []string
+	// child holds rows keyed by this table's key, such as the items of an
+	// order, which are replaced together with their parent
+	child *table
+}
+
+// tables are the tables that can be mirrored, by the name repositories
+// report their changes under
+var tables = map[string]*table{
+	repository.ChangedTableProducts: {
+		name: "products",
+		key:  "id",
+		columns: []string{"id", "name", "price", "category_id", "sku", "barcode", "description", "stock",
+			"created_at", "updated_at", "deleted_at"},
+	},
+	repository.ChangedTableOrders: {
+		name:    "orders",
+		key:     "id",
+		columns: []string{"id", "coupon_code", "status", "total", "discount", "created_at", "updated_at"},
+		child: &table{
+			name: "order_items",
+			key:  "order_id",
+			columns: []string{"order_id", "product_id", "quantity", "product_name", "product_category",
+				"unit_price", "tax_rate", "discount", "created_at"},
+		},
+	},
+}
+
+// ParseTables validates a comma-separated list of tables to mirror
+func ParseTables(list string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := tables[name]; !ok {
+			return nil, fmt.Errorf("table %q cannot be mirrored, expected %s or %s",
+				name, repository.ChangedTableProducts, repository.ChangedTableOrders)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// change is a row to copy
+type change struct {
+	table string
+	key   string
+}
+
+// Descriptors of the mirror's metrics
+var (
+	mirroredDesc      = prometheus.NewDesc("dual_write_mirrored_total", "Rows copied to the secondary datastore.", nil, nil)
+	failuresDesc      = prometheus.NewDesc("dual_write_failures_total", "Rows that could not be copied to the secondary datastore.", nil, nil)
+	droppedDesc       = prometheus.NewDesc("dual_write_dropped_total", "Changes dropped because the mirror queue was full.", nil, nil)
+	queueDepthDesc    = prometheus.NewDesc("dual_write_queue_depth", "Changes waiting to be copied to the secondary datastore.", nil, nil)
+	divergentRowsDesc = prometheus.NewDesc("dual_write_divergent_rows", "Rows that differed between the datastores at the latest verification.", []string{"table"}, nil)
+)
+
+// Mirror copies the rows repositories report as written from the primary
+// database to the secondary. It is a repository.ChangeRecorder and is safe
+// for concurrent use.
+type Mirror struct {
+	primary   *sql.DB
+	secondary *sql.DB
+	enabled   map[string]bool
+	queue     chan change
+
+	mirrored atomic.Int64
+	failures atomic.Int64
+	dropped  atomic.Int64
+
+	mu        sync.Mutex
+	divergent map[string]int
+}
+
+// New creates a mirror of the named tables with room for queueSize
+// changes
+func New(primary, secondary *sql.DB, names []string, queueSize int) *Mirror {
+	enabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		enabled[name] = true
+	}
+	return &Mirror{
+		primary:   primary,
+		secondary: secondary,
+		enabled:   enabled,
+		queue:     make(chan change, queueSize),
+		divergent: make(map[string]int),
+	}
+}
+
+// Record queues the rows for copying when their table is mirrored. Changes
+// that do not fit in the queue are dropped and counted.
+func (m *Mirror) Record(name string, keys ...string) {
+	if !m.enabled[name] {
+		return
+	}
+	for _, key := range keys {
+		select {
+		case m.queue <- change{table: name, key: key}:
+		default:
+			m.dropped.Add(1)
+		}
+	}
+}
+
+// Run copies queued changes until ctx is cancelled. Changes still queued
+// then are lost; Verify finds them.
+func (m *Mirror) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case c := <-m.queue:
+			copyCtx, cancel := context.WithTimeout(ctx, copyTimeout)
+			err := m.copy(copyCtx, tables[c.table], c.key)
+			cancel()
+			if err != nil {
+				m.failures.Add(1)
+				if ctx.Err() == nil {
+					slog.Error("Failed to mirror change", "table", c.table, "key", c.key, "error", err)
+				}
+				continue
+			}
+			m.mirrored.Add(1)
+		}
+	}
+}
+
+// copy makes the secondary's row with key, and its child rows, match the
+// primary's, deleting them when the primary has none
+func (m *Mirror) copy(ctx context.Context, t *table, key string) error {
+	row, found, err := readRow(ctx, m.primary, t, key)
+	if err != nil {
+		return err
+	}
+	var children [][]any
+	if t.child != nil && found {
+		if children, err = readRows(ctx, m.primary, t.child, key); err != nil {
+			return err
+		}
+	}
+
+	tx, err := m.secondary.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if t.child != nil {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s = $1`, t.child.name, t.child.key), key); err != nil {
+			return fmt.Errorf("error deleting %s: %w", t.child.name, err)
+		}
+	}
+	if !found {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s = $1`, t.name, t.key), key); err != nil {
+			return fmt.Errorf("error deleting %s: %w", t.name, err)
+		}
+	} else {
+		if _, err := tx.ExecContext(ctx, upsertQuery(t), row...); err != nil {
+			return fmt.Errorf("error writing %s: %w", t.name, err)
+		}
+		for _, child := range children {
+			if _, err := tx.ExecContext(ctx, insertQuery(t.child), child...); err != nil {
+				return fmt.Errorf("error writing %s: %w", t.child.name, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// readRow reads the columns of the row with key
+func readRow(ctx context.Context, db *sql.DB, t *table, key string) ([]any, bool, error) {
+	rows, err := readRows(ctx, db, t, key)
+	if err != nil || len(rows) == 0 {
+		return nil, false, err
+	}
+	return rows[0], true, nil
+}
+
+// readRows reads the columns of every row with key
+func readRows(ctx context.Context, db *sql.DB, t *table, key string) ([][]any, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s = $1`, strings.Join(t.columns, ", "), t.name, t.key)
+	rows, err := db.QueryContext(ctx, query, key)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", t.name, err)
+	}
+	defer rows.Close()
+
+	var result [][]any
+	for rows.Next() {
+		values, err := scanValues(rows, len(t.columns))
+		if err != nil {
+			return nil, fmt.Errorf("error scanning %s: %w", t.name, err)
+		}
+		result = append(result, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", t.name, err)
+	}
+	return result, nil
+}
+
+// scanValues scans n columns into values that can be written back as
+// query arguments; lib/pq returns numerics as bytes, which it would send
+// as bytea
+func scanValues(rows *sql.Rows, n int) ([]any, error) {
+	values := make([]any, n)
+	dest := make([]any, n)
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		if b, ok := value.([]byte); ok {
+			values[i] = string(b)
+		}
+	}
+	return values, nil
+}
+
+// insertQuery builds an INSERT of every column of t
+func insertQuery(t *table) string {
+	placeholders := make([]string, len(t.columns))
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	return fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s)`, t.name, strings.Join(t.columns, ", "), strings.Join(placeholders, ", "))
+}
+
+// upsertQuery builds an INSERT of every column of t that overwrites the
+// row with the same key
+func upsertQuery(t *table) string {
+	var set []string
+	for _, column := range t.columns {
+		if column != t.key {
+			set = append(set, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+		}
+	}
+	return fmt.Sprintf(`%s ON CONFLICT (%s) DO UPDATE SET %s`, insertQuery(t), t.key, strings.Join(set, ", "))
+}
+
+// Describe sends the descriptors of the mirror's metrics
+func (m *Mirror) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{mirroredDesc, failuresDesc, droppedDesc, queueDepthDesc, divergentRowsDesc} {
+		ch <- desc
+	}
+}
+
+// Collect sends the mirror's counters and the divergent rows found by the
+// latest verification of each table
+func (m *Mirror) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(mirroredDesc, prometheus.CounterValue, float64(m.mirrored.Load()))
+	ch <- prometheus.MustNewConstMetric(failuresDesc, prometheus.CounterValue, float64(m.failures.Load()))
+	ch <- prometheus.MustNewConstMetric(droppedDesc, prometheus.CounterValue, float64(m.dropped.Load()))
+	ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(len(m.queue)))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for table, count := range m.divergent {
+		ch <- prometheus.MustNewConstMetric(divergentRowsDesc, prometheus.GaugeValue, float64(count), table)
+	}
+}
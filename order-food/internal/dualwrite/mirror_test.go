@@ -0,0 +1,142 @@
+package dualwrite
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var productColumns = []string{"id", "name", "price", "category_id", "sku", "barcode", "description", "stock", "created_at", "updated_at", "deleted_at"}
+
+func TestParseTables(t *testing.T) {
+	names, err := ParseTables(" products, orders ,")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"products", "orders"}, names)
+
+	names, err = ParseTables("")
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+
+	_, err = ParseTables("products,coupons")
+	assert.Error(t, err)
+}
+
+func TestMirror_Record(t *testing.T) {
+	// Setup
+	mirror := New(nil, nil, []string{"products"}, 1)
+
+	// Execute
+	mirror.Record("orders", "order-1")
+	mirror.Record("products", "1", "2")
+
+	// Assert
+	assert.Len(t, mirror.queue, 1, "changes to tables that are not mirrored are ignored")
+	assert.Equal(t, change{table: "products", key: "1"}, <-mirror.queue)
+	assert.Equal(t, int64(1), mirror.dropped.Load())
+}
+
+func TestMirror_Copy_Product(t *testing.T) {
+	// Setup mock databases
+	primary, primaryMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer primary.Close()
+	secondary, secondaryMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer secondary.Close()
+
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	primaryMock.ExpectQuery("SELECT id, name, price, .* FROM products WHERE id = \\$1").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(productColumns).
+			AddRow("1", "Waffle with Berries", []byte("6.50"), "Waffle", nil, nil, nil, int64(12), created, created, nil))
+	secondaryMock.ExpectBegin()
+	secondaryMock.ExpectExec("INSERT INTO products \\(id, name, .*\\) VALUES \\(\\$1, .*\\$11\\) ON CONFLICT \\(id\\) DO UPDATE SET name = EXCLUDED.name").
+		WithArgs("1", "Waffle with Berries", "6.50", "Waffle", nil, nil, nil, int64(12), created, created, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	secondaryMock.ExpectCommit()
+
+	mirror := New(primary, secondary, []string{"products"}, 10)
+
+	// Test
+	err = mirror.copy(context.Background(), tables["products"], "1")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, primaryMock.ExpectationsWereMet())
+	assert.NoError(t, secondaryMock.ExpectationsWereMet())
+}
+
+func TestMirror_Copy_DeletedOrder(t *testing.T) {
+	// Setup mock databases
+	primary, primaryMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer primary.Close()
+	secondary, secondaryMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer secondary.Close()
+
+	// Archived orders are gone from the primary
+	primaryMock.ExpectQuery("FROM orders WHERE id = \\$1").
+		WithArgs("order-1").
+		WillReturnRows(sqlmock.NewRows(tables["orders"].columns))
+	secondaryMock.ExpectBegin()
+	secondaryMock.ExpectExec("DELETE FROM order_items WHERE order_id = \\$1").WithArgs("order-1").WillReturnResult(sqlmock.NewResult(0, 2))
+	secondaryMock.ExpectExec("DELETE FROM orders WHERE id = \\$1").WithArgs("order-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	secondaryMock.ExpectCommit()
+
+	mirror := New(primary, secondary, []string{"orders"}, 10)
+
+	// Test
+	err = mirror.copy(context.Background(), tables["orders"], "order-1")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, primaryMock.ExpectationsWereMet())
+	assert.NoError(t, secondaryMock.ExpectationsWereMet())
+}
+
+func TestMirror_Verify(t *testing.T) {
+	// Setup mock databases
+	primary, primaryMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer primary.Close()
+	secondary, secondaryMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer secondary.Close()
+
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	columns := append([]string{"key"}, productColumns...)
+	primaryMock.ExpectQuery("SELECT id, id, name, .* FROM products").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("1", "1", "Waffle", []byte("6.50"), "Waffle", nil, nil, nil, nil, created, created, nil).
+			AddRow("2", "2", "Crepe", []byte("4.00"), "Crepe", nil, nil, nil, nil, created, created, nil).
+			AddRow("3", "3", "Brownie", []byte("3.00"), "Cake", nil, nil, nil, nil, created, created, nil))
+	// The same instant in another zone matches; a stale price does not
+	secondaryMock.ExpectQuery("SELECT id, id, name, .* FROM products").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("1", "1", "Waffle", []byte("6.50"), "Waffle", nil, nil, nil, nil, created.In(time.FixedZone("CET", 3600)), created, nil).
+			AddRow("2", "2", "Crepe", []byte("3.50"), "Crepe", nil, nil, nil, nil, created, created, nil).
+			AddRow("4", "4", "Cookie", []byte("1.00"), "Cake", nil, nil, nil, nil, created, created, nil))
+
+	mirror := New(primary, secondary, []string{"products"}, 10)
+
+	// Test
+	report, err := mirror.Verify(context.Background(), "products", false)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, Report{Table: "products", Rows: 3, Missing: []string{"3"}, Extra: []string{"4"}, Different: []string{"2"}}, report)
+	assert.NoError(t, primaryMock.ExpectationsWereMet())
+	assert.NoError(t, secondaryMock.ExpectationsWereMet())
+
+	assert.NoError(t, testutil.CollectAndCompare(mirror, strings.NewReader(`
+# HELP dual_write_divergent_rows Rows that differed between the datastores at the latest verification.
+# TYPE dual_write_divergent_rows gauge
+dual_write_divergent_rows{table="products"} 3
+`), "dual_write_divergent_rows"))
+}
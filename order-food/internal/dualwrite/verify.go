@@ -0,0 +1,171 @@
+package dualwrite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report is the result of verifying a mirrored table
+type Report struct {
+	Table string
+	// Rows is the number of rows on the primary
+	Rows int
+	// Missing, Extra and Different list the keys of rows only on the
+	// primary, only on the secondary and on both with other values
+	Missing   []string
+	Extra     []string
+	Different []string
+	// Repaired is the number of divergent rows copied again
+	Repaired int
+}
+
+// Divergent returns the number of rows that differ between the datastores
+func (r Report) Divergent() int {
+	return len(r.Missing) + len(r.Extra) + len(r.Different)
+}
+
+// Verify compares every row of the mirrored table name, with its child
+// rows, on both datastores. With repair, divergent rows are copied from the
+// primary again, which also fills in rows written before mirroring began.
+// Rows written while Verify runs may be reported as divergent.
+func (m *Mirror) Verify(ctx context.Context, name string, repair bool) (Report, error) {
+	t, ok := tables[name]
+	if !ok {
+		return Report{}, fmt.Errorf("table %q cannot be mirrored", name)
+	}
+
+	primary, err := tableHashes(ctx, m.primary, t)
+	if err != nil {
+		return Report{}, fmt.Errorf("error reading primary: %w", err)
+	}
+	secondary, err := tableHashes(ctx, m.secondary, t)
+	if err != nil {
+		return Report{}, fmt.Errorf("error reading secondary: %w", err)
+	}
+
+	report := Report{Table: name, Rows: len(primary)}
+	for key, hash := range primary {
+		other, ok := secondary[key]
+		switch {
+		case !ok:
+			report.Missing = append(report.Missing, key)
+		case other != hash:
+			report.Different = append(report.Different, key)
+		}
+	}
+	for key := range secondary {
+		if _, ok := primary[key]; !ok {
+			report.Extra = append(report.Extra, key)
+		}
+	}
+	sort.Strings(report.Missing)
+	sort.Strings(report.Extra)
+	sort.Strings(report.Different)
+
+	m.mu.Lock()
+	m.divergent[name] = report.Divergent()
+	m.mu.Unlock()
+
+	if !repair {
+		return report, nil
+	}
+	var errs []error
+	for _, keys := range [][]string{report.Missing, report.Extra, report.Different} {
+		for _, key := range keys {
+			copyCtx, cancel := context.WithTimeout(ctx, copyTimeout)
+			err := m.copy(copyCtx, t, key)
+			cancel()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				continue
+			}
+			report.Repaired++
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// VerifyFunc returns a scheduler task verifying the named tables without
+// repairing them, so the divergence metrics stay current
+func (m *Mirror) VerifyFunc(names []string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		for _, name := range names {
+			report, err := m.Verify(ctx, name, false)
+			if err != nil {
+				return err
+			}
+			if report.Divergent() > 0 {
+				slog.Warn("Dual-write verification found divergent rows", "table", name, "divergent", report.Divergent(), "rows", report.Rows)
+			}
+		}
+		return nil
+	}
+}
+
+// tableHashes returns a hash of every row of t, with its child rows mixed
+// in, by key. Child rows are summed so their order does not matter.
+func tableHashes(ctx context.Context, db *sql.DB, t *table) (map[string]uint64, error) {
+	hashes := make(map[string]uint64)
+	for _, part := range []*table{t, t.child} {
+		if part == nil {
+			continue
+		}
+		query := fmt.Sprintf(`SELECT %s, %s FROM %s`, part.key, strings.Join(part.columns, ", "), part.name)
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", part.name, err)
+		}
+		for rows.Next() {
+			values, err := scanValues(rows, len(part.columns)+1)
+			if err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("error scanning %s: %w", part.name, err)
+			}
+			// Orphaned child rows show up under their parent's key
+			hashes[normalize(values[0])] += rowHash(values[1:])
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", part.name, err)
+		}
+	}
+	return hashes, nil
+}
+
+// rowHash hashes the normalized values of a row
+func rowHash(values []any) uint64 {
+	h := fnv.New64a()
+	for _, value := range values {
+		h.Write([]byte(normalize(value)))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// normalize renders a scanned value the same way whichever datastore it
+// came from
+func normalize(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "\x00null"
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
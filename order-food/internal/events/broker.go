@@ -0,0 +1,43 @@
+// Package events sends the domain events order-food publishes, such as
+// placed orders and changed products, to a Kafka cluster or a NATS server
+// for downstream systems like analytics and the kitchen displays.
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Message is an encoded event addressed to a topic. Kafka partitions by
+// Key; NATS ignores it.
+type Message struct {
+	Topic   string
+	Key     string
+	Value   []byte
+	Headers map[string]string
+}
+
+// Broker delivers messages to an event broker
+type Broker interface {
+	// Send returns once the broker has accepted every message
+	Send(ctx context.Context, messages ...Message) error
+	Close() error
+}
+
+// NewBroker connects to the broker at url, either
+// kafka://host:port[,host:port...] or nats://[user:password@]host:port
+func NewBroker(url, clientID string) (Broker, error) {
+	scheme, address, ok := strings.Cut(url, "://")
+	if !ok || address == "" {
+		return nil, fmt.Errorf("invalid event broker URL %q, want kafka://host:port or nats://host:port", url)
+	}
+	switch scheme {
+	case "kafka":
+		return NewKafkaBroker(strings.Split(address, ","), clientID), nil
+	case "nats":
+		return NewNATSBroker(url, clientID)
+	default:
+		return nil, fmt.Errorf("unsupported event broker %q, want kafka or nats", scheme)
+	}
+}
@@ -0,0 +1,19 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBroker(t *testing.T) {
+	broker, err := NewBroker("kafka://kafka-1:9092,kafka-2:9092", "order-food")
+	assert.NoError(t, err)
+	assert.IsType(t, &KafkaBroker{}, broker)
+	assert.NoError(t, broker.Close())
+
+	for _, url := range []string{"", "kafka://", "kafka-1:9092", "amqp://rabbitmq:5672"} {
+		_, err := NewBroker(url, "order-food")
+		assert.Error(t, err, url)
+	}
+}
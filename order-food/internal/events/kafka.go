@@ -0,0 +1,45 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaBroker writes messages to Kafka topics, hashing keys to pick the
+// partition
+type KafkaBroker struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaBroker creates a broker writing to the cluster of the given
+// bootstrap brokers. Writes wait for every in-sync replica.
+func NewKafkaBroker(brokers []string, clientID string) *KafkaBroker {
+	return &KafkaBroker{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		Transport:    &kafka.Transport{ClientID: clientID},
+	}}
+}
+
+// Send writes messages in one batch
+func (b *KafkaBroker) Send(ctx context.Context, messages ...Message) error {
+	records := make([]kafka.Message, len(messages))
+	for i, m := range messages {
+		records[i] = kafka.Message{Topic: m.Topic, Key: []byte(m.Key), Value: m.Value}
+		for name, value := range m.Headers {
+			records[i].Headers = append(records[i].Headers, kafka.Header{Key: name, Value: []byte(value)})
+		}
+	}
+	if err := b.writer.WriteMessages(ctx, records...); err != nil {
+		return fmt.Errorf("error writing to Kafka: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the writer
+func (b *KafkaBroker) Close() error {
+	return b.writer.Close()
+}
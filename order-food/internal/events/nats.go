@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSBroker publishes messages on NATS subjects named after their topic
+type NATSBroker struct {
+	conn *nats.Conn
+}
+
+// NewNATSBroker connects to the NATS server at url, reconnecting for as
+// long as the broker is open
+func NewNATSBroker(url, clientID string) (*NATSBroker, error) {
+	conn, err := nats.Connect(url, nats.Name(clientID), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to NATS: %w", err)
+	}
+	return &NATSBroker{conn: conn}, nil
+}
+
+// Send publishes messages and waits for the server to have received them
+func (b *NATSBroker) Send(ctx context.Context, messages ...Message) error {
+	for _, m := range messages {
+		msg := nats.NewMsg(m.Topic)
+		msg.Data = m.Value
+		for name, value := range m.Headers {
+			msg.Header.Set(name, value)
+		}
+		if err := b.conn.PublishMsg(msg); err != nil {
+			return fmt.Errorf("error publishing to NATS: %w", err)
+		}
+	}
+	if err := b.conn.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("error publishing to NATS: %w", err)
+	}
+	return nil
+}
+
+// Close drains pending messages and closes the connection
+func (b *NATSBroker) Close() error {
+	return b.conn.Drain()
+}
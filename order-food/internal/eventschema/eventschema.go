@@ -0,0 +1,232 @@
+// Package eventschema validates the event payloads order-food publishes
+// against the JSON Schemas registered for them in a schema registry. At
+// startup the schema each event is written with must be compatible with
+// the registered one, so a change to a payload that would break consumers
+// stops the deployment instead of reaching them; afterwards every payload
+// is checked before it is published.
+package eventschema
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// Registry subjects of the events order-food publishes
+const (
+	// SubjectCacheInvalidation is the subject of CacheInvalidation
+	// payloads written to the cache_invalidations outbox
+	SubjectCacheInvalidation = "order-food.cache-invalidation"
+	// SubjectOrderChange is the subject of models.OrderChange payloads
+	// sent on the order_changes notification channel
+	SubjectOrderChange = "order-food.order-change"
+	// SubjectOrderCreated is the subject of the models.OrderCreated data
+	// of OrderCreated events sent to the event broker
+	SubjectOrderCreated = "order-food.order-created"
+	// SubjectOrderStatusChanged is the subject of the
+	// models.OrderStatusChanged data of OrderStatusChanged events
+	SubjectOrderStatusChanged = "order-food.order-status-changed"
+	// SubjectProductUpdated is the subject of the models.ProductUpdated
+	// data of ProductUpdated events
+	SubjectProductUpdated = "order-food.product-updated"
+)
+
+// EventSubjects maps the types of the events sent to the event broker to
+// the subject of their data
+var EventSubjects = map[string]string{
+	models.EventTypeOrderCreated:       SubjectOrderCreated,
+	models.EventTypeOrderStatusChanged: SubjectOrderStatusChanged,
+	models.EventTypeProductUpdated:     SubjectProductUpdated,
+}
+
+// ErrInvalidPayload is returned for a payload its registered schema rejects
+var ErrInvalidPayload = errors.New("event payload does not match its schema")
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// CacheInvalidation is the payload of an outbox event
+type CacheInvalidation struct {
+	Topic string `json:"topic"`
+	Key   string `json:"key"`
+}
+
+// Event is a kind of event order-food publishes
+type Event struct {
+	Subject string
+	// File is the JSON Schema the payload is written with, in schemas/
+	File string
+	// Examples are payloads of every shape the event is published in; they
+	// must satisfy the registered schema
+	Examples []any
+}
+
+// Events lists every kind of event order-food publishes
+var Events = []Event{
+	{
+		Subject: SubjectCacheInvalidation,
+		File:    "cache-invalidation.json",
+		Examples: []any{
+			CacheInvalidation{Topic: models.InvalidationTopicProducts, Key: "*"},
+			CacheInvalidation{Topic: models.InvalidationTopicProducts, Key: "1"},
+			CacheInvalidation{Topic: models.InvalidationTopicCoupons, Key: "*"},
+			CacheInvalidation{Topic: models.InvalidationTopicCoupons, Key: models.InvalidationKeyCampaignPrefix + "0b6f4e2a-1c3d-4e5f-8a9b-0c1d2e3f4a5b"},
+		},
+	},
+	{
+		Subject: SubjectOrderChange,
+		File:    "order-change.json",
+		Examples: []any{
+			models.OrderChange{ID: "0b6f4e2a-1c3d-4e5f-8a9b-0c1d2e3f4a5b", Status: models.OrderStatusPending},
+			models.OrderChange{ID: "0b6f4e2a-1c3d-4e5f-8a9b-0c1d2e3f4a5b", Status: models.OrderStatusCancelled},
+		},
+	},
+	{
+		Subject: SubjectOrderCreated,
+		File:    "order-created.json",
+		Examples: []any{
+			models.OrderCreated{
+				ID:     "0b6f4e2a-1c3d-4e5f-8a9b-0c1d2e3f4a5b",
+				Status: models.OrderStatusPending,
+				Items: []models.OrderCreatedItem{
+					{ProductID: "1", Name: "Waffle with Berries", Category: "Waffle", Quantity: 2, UnitPrice: 6.5},
+				},
+				Subtotal: 13,
+				Total:    13,
+			},
+			models.OrderCreated{
+				ID:         "0b6f4e2a-1c3d-4e5f-8a9b-0c1d2e3f4a5b",
+				Status:     models.OrderStatusPending,
+				CustomerID: "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+				CouponCode: "HAPPYHRS",
+				Items: []models.OrderCreatedItem{
+					{ProductID: "1", Name: "Waffle with Berries", Category: "Waffle", Quantity: 1, UnitPrice: 6.5, Discount: 1.17},
+				},
+				Subtotal: 6.5,
+				Discount: 1.17,
+				Total:    5.33,
+			},
+		},
+	},
+	{
+		Subject: SubjectOrderStatusChanged,
+		File:    "order-status-changed.json",
+		Examples: []any{
+			models.OrderStatusChanged{ID: "0b6f4e2a-1c3d-4e5f-8a9b-0c1d2e3f4a5b", From: models.OrderStatusPending, To: models.OrderStatusConfirmed},
+			models.OrderStatusChanged{ID: "0b6f4e2a-1c3d-4e5f-8a9b-0c1d2e3f4a5b", From: models.OrderStatusPreparing, To: models.OrderStatusCancelled},
+		},
+	},
+	{
+		Subject: SubjectProductUpdated,
+		File:    "product-updated.json",
+		Examples: []any{
+			models.ProductUpdated{ID: "1", Change: models.ProductChangeCreated, Name: "Waffle with Berries", Price: 6.5, Category: "Waffle"},
+			models.ProductUpdated{ID: "1", Change: models.ProductChangeUpdated, Name: "Waffle with Berries", Price: 7, Category: "Waffle", SKU: "WAF-001", Barcode: "4006381333931"},
+			models.ProductUpdated{ID: "1", Change: models.ProductChangeDeleted},
+		},
+	},
+}
+
+// Schema returns the JSON Schema event is written with
+func (e Event) Schema() (string, error) {
+	data, err := schemaFiles.ReadFile("schemas/" + e.File)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Validator checks payloads against the registered schemas of their
+// subjects
+type Validator struct {
+	schemas map[string]*jsonschema.Schema
+}
+
+// Load checks the schema of every event against the registry and
+// returns a validator for the registered ones. It fails when a subject is
+// not registered, is registered with a schema other than a JSON Schema,
+// would not accept the local schema as its next version, or rejects one
+// of the examples.
+func Load(ctx context.Context, client *Client, events []Event) (*Validator, error) {
+	v := &Validator{schemas: make(map[string]*jsonschema.Schema, len(events))}
+	for _, event := range events {
+		local, err := event.Schema()
+		if err != nil {
+			return nil, err
+		}
+
+		registered, err := client.Latest(ctx, event.Subject)
+		if errors.Is(err, ErrSubjectNotFound) {
+			return nil, fmt.Errorf("%s is not registered; register schemas/%s first", event.Subject, event.File)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if registered.Type != SchemaTypeJSON {
+			return nil, fmt.Errorf("%s is registered as %s; only JSON Schema is supported", event.Subject, registered.Type)
+		}
+
+		compatible, reasons, err := client.Compatible(ctx, event.Subject, local)
+		if err != nil {
+			return nil, err
+		}
+		if !compatible {
+			return nil, fmt.Errorf("schemas/%s is not compatible with version %d of %s: %s", event.File, registered.Version, event.Subject, strings.Join(reasons, "; "))
+		}
+
+		schema, err := compile(event.Subject, registered.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema registered for %s: %w", event.Subject, err)
+		}
+		v.schemas[event.Subject] = schema
+		for _, example := range event.Examples {
+			if err := v.Validate(event.Subject, example); err != nil {
+				return nil, fmt.Errorf("version %d of %s: %w", registered.Version, event.Subject, err)
+			}
+		}
+	}
+	return v, nil
+}
+
+// compile compiles a JSON Schema document
+func compile(subject, document string) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(document))
+	if err != nil {
+		return nil, err
+	}
+	location := subject + ".json"
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(location, doc); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(location)
+}
+
+// Validate checks payload, as it is encoded to JSON, against the schema
+// registered for subject. Subjects the validator was not loaded with are
+// not checked.
+func (v *Validator) Validate(subject string, payload any) error {
+	schema, ok := v.schemas[subject]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if err := schema.Validate(instance); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidPayload, subject, err)
+	}
+	return nil
+}
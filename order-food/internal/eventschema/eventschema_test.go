@@ -0,0 +1,147 @@
+package eventschema
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRegistry serves the registry API for subjects registered with
+// their local schemas unless overridden
+type fakeRegistry struct {
+	schemas      map[string]RegisteredSchema
+	incompatible map[string]bool
+	auth         string
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	r := &fakeRegistry{schemas: make(map[string]RegisteredSchema), incompatible: make(map[string]bool)}
+	for _, event := range Events {
+		schema, err := event.Schema()
+		assert.NoError(t, err)
+		r.schemas[event.Subject] = RegisteredSchema{Subject: event.Subject, Version: 3, ID: 7, Type: SchemaTypeJSON, Schema: schema}
+	}
+	return r
+}
+
+func (r *fakeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.auth = req.Header.Get("Authorization")
+	w.Header().Set("Content-Type", registryContentType)
+	switch {
+	case req.Method == http.MethodGet && strings.HasPrefix(req.URL.Path, "/subjects/"):
+		subject := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/subjects/"), "/versions/latest")
+		schema, ok := r.schemas[subject]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40401,"message":"Subject not found."}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(schema)
+	case req.Method == http.MethodPost && strings.HasPrefix(req.URL.Path, "/compatibility/subjects/"):
+		subject := strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/compatibility/subjects/"), "/versions/latest")
+		if r.incompatible[subject] {
+			_, _ = w.Write([]byte(`{"is_compatible":false,"messages":["property 'status' was removed"]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"is_compatible":true}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(r *fakeRegistry)
+		wantErr string
+	}{
+		{name: "registered and compatible"},
+		{
+			name:    "not registered",
+			modify:  func(r *fakeRegistry) { delete(r.schemas, SubjectOrderChange) },
+			wantErr: "order-food.order-change is not registered; register schemas/order-change.json first",
+		},
+		{
+			name:    "incompatible change",
+			modify:  func(r *fakeRegistry) { r.incompatible[SubjectOrderChange] = true },
+			wantErr: "schemas/order-change.json is not compatible with version 3 of order-food.order-change: property 'status' was removed",
+		},
+		{
+			name: "registered as Avro",
+			modify: func(r *fakeRegistry) {
+				schema := r.schemas[SubjectCacheInvalidation]
+				schema.Type = SchemaTypeAvro
+				r.schemas[SubjectCacheInvalidation] = schema
+			},
+			wantErr: "registered as AVRO",
+		},
+		{
+			name: "registered schema rejects a payload",
+			modify: func(r *fakeRegistry) {
+				schema := r.schemas[SubjectCacheInvalidation]
+				schema.Schema = `{"type":"object","properties":{"topic":{"enum":["products"]}}}`
+				r.schemas[SubjectCacheInvalidation] = schema
+			},
+			wantErr: "event payload does not match its schema",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			registry := newFakeRegistry(t)
+			if tt.modify != nil {
+				tt.modify(registry)
+			}
+			server := httptest.NewServer(registry)
+			defer server.Close()
+			client, err := NewClient(server.URL+"/", "key", "secret")
+			assert.NoError(t, err)
+
+			// Execute
+			validator, err := Load(context.Background(), client, Events)
+
+			// Assert
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, validator)
+			assert.True(t, strings.HasPrefix(registry.auth, "Basic "))
+		})
+	}
+}
+
+func TestValidator_Validate(t *testing.T) {
+	// Setup
+	server := httptest.NewServer(newFakeRegistry(t))
+	defer server.Close()
+	client, err := NewClient(server.URL, "", "")
+	assert.NoError(t, err)
+	validator, err := Load(context.Background(), client, Events)
+	assert.NoError(t, err)
+
+	// Assert
+	assert.NoError(t, validator.Validate(SubjectCacheInvalidation, CacheInvalidation{Topic: models.InvalidationTopicProducts, Key: "12"}))
+	assert.ErrorIs(t, validator.Validate(SubjectCacheInvalidation, CacheInvalidation{Topic: "orders", Key: "12"}), ErrInvalidPayload)
+	assert.ErrorIs(t, validator.Validate(SubjectCacheInvalidation, CacheInvalidation{Topic: models.InvalidationTopicProducts}), ErrInvalidPayload)
+	assert.ErrorIs(t, validator.Validate(SubjectOrderChange, models.OrderChange{ID: "1", Status: "lost"}), ErrInvalidPayload)
+	assert.ErrorIs(t, validator.Validate(SubjectOrderCreated, models.OrderCreated{ID: "1", Status: models.OrderStatusPending}), ErrInvalidPayload)
+	assert.ErrorIs(t, validator.Validate(SubjectProductUpdated, models.ProductUpdated{ID: "1", Change: models.ProductChangeUpdated}), ErrInvalidPayload)
+	assert.NoError(t, validator.Validate(SubjectProductUpdated, models.ProductUpdated{ID: "1", Change: models.ProductChangeDeleted}))
+	assert.NoError(t, validator.Validate("unknown", map[string]int{"any": 1}))
+}
+
+func TestNewClient(t *testing.T) {
+	for _, url := range []string{"", "registry:8081", "ftp://registry", "http://"} {
+		_, err := NewClient(url, "", "")
+		assert.Error(t, err, url)
+	}
+}
@@ -0,0 +1,141 @@
+package eventschema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// registryContentType is the media type of schema registry requests
+const registryContentType = "application/vnd.schemaregistry.v1+json"
+
+// ErrSubjectNotFound is returned when a subject has no schema registered
+var ErrSubjectNotFound = errors.New("subject is not registered")
+
+// Schema types of registered schemas
+const (
+	SchemaTypeJSON = "JSON"
+	// SchemaTypeAvro is the type of schemas registered without one
+	SchemaTypeAvro = "AVRO"
+)
+
+// RegisteredSchema is a version of a subject's schema
+type RegisteredSchema struct {
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+	ID      int    `json:"id"`
+	Type    string `json:"schemaType"`
+	Schema  string `json:"schema"`
+}
+
+// Client talks to a Confluent compatible schema registry
+type Client struct {
+	baseURL            *url.URL
+	username, password string
+	client             *http.Client
+}
+
+// NewClient creates a client for the registry at baseURL. Requests are
+// sent with basic authentication when username is not empty.
+func NewClient(baseURL, username, password string) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid schema registry URL %q", baseURL)
+	}
+	return &Client{
+		baseURL:  u,
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Latest returns the newest schema registered for subject
+func (c *Client) Latest(ctx context.Context, subject string) (RegisteredSchema, error) {
+	var schema RegisteredSchema
+	if err := c.do(ctx, http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions/latest", nil, &schema); err != nil {
+		return RegisteredSchema{}, fmt.Errorf("error fetching schema of %s: %w", subject, err)
+	}
+	if schema.Type == "" {
+		schema.Type = SchemaTypeAvro
+	}
+	return schema, nil
+}
+
+// Compatible reports whether a JSON Schema may be registered as the next
+// version of subject under the subject's compatibility rules, with the
+// reasons when it may not
+func (c *Client) Compatible(ctx context.Context, subject, schema string) (bool, []string, error) {
+	body := map[string]string{"schemaType": SchemaTypeJSON, "schema": schema}
+	var result struct {
+		Compatible bool     `json:"is_compatible"`
+		Messages   []string `json:"messages"`
+	}
+	path := "/compatibility/subjects/" + url.PathEscape(subject) + "/versions/latest?verbose=true"
+	if err := c.do(ctx, http.MethodPost, path, body, &result); err != nil {
+		return false, nil, fmt.Errorf("error checking compatibility with %s: %w", subject, err)
+	}
+	return result.Compatible, result.Messages, nil
+}
+
+// do sends a request with body encoded as JSON, if not nil, and decodes
+// the response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	target, err := c.baseURL.Parse(c.baseURL.Path + path)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", registryContentType)
+	if body != nil {
+		req.Header.Set("Content-Type", registryContentType)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var registryErr struct {
+			Code    int    `json:"error_code"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &registryErr)
+		// 40401 and 40402 are the registry's codes for an unknown subject
+		// or version
+		if resp.StatusCode == http.StatusNotFound && (registryErr.Code == 40401 || registryErr.Code == 40402) {
+			return ErrSubjectNotFound
+		}
+		if registryErr.Message != "" {
+			return fmt.Errorf("schema registry answered %s: %s", resp.Status, registryErr.Message)
+		}
+		return fmt.Errorf("schema registry answered %s", resp.Status)
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,102 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// OrdersHeader is the header row of an orders file
+var OrdersHeader = []string{"order_id", "placed_at", "status", "customer_id", "coupon_code", "units", "subtotal", "discount", "total"}
+
+// RefundsHeader is the header row of a refunds file
+var RefundsHeader = []string{"order_id", "placed_at", "refunded_at", "customer_id", "coupon_code", "amount"}
+
+// EncodeOrders writes orders as CSV with a header row. Times are RFC 3339
+// in UTC and amounts have two decimals.
+func EncodeOrders(orders []models.ExportedOrder) ([]byte, error) {
+	return encodeCSV(OrdersHeader, len(orders), func(i int) []string {
+		order := orders[i]
+		return []string{
+			order.ID,
+			formatTime(order.PlacedAt),
+			string(order.Status),
+			order.CustomerID,
+			order.CouponCode,
+			strconv.Itoa(order.Units),
+			formatAmount(order.Subtotal),
+			formatAmount(order.Discount),
+			formatAmount(order.Total),
+		}
+	})
+}
+
+// EncodeRefunds writes cancelled orders as CSV with a header row, one
+// refund of the order total each
+func EncodeRefunds(orders []models.ExportedOrder) ([]byte, error) {
+	for _, order := range orders {
+		if order.CancelledAt == nil {
+			return nil, fmt.Errorf("order %s was not refunded", order.ID)
+		}
+	}
+	return encodeCSV(RefundsHeader, len(orders), func(i int) []string {
+		order := orders[i]
+		return []string{
+			order.ID,
+			formatTime(order.PlacedAt),
+			formatTime(*order.CancelledAt),
+			order.CustomerID,
+			order.CouponCode,
+			formatAmount(order.Total),
+		}
+	})
+}
+
+// encodeCSV writes header and n records returned by record
+func encodeCSV(header []string, n int, record func(i int) []string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for i := 0; i < n; i++ {
+		fields := record(i)
+		for j, field := range fields {
+			fields[j] = neutralizeFormula(field)
+		}
+		if err := w.Write(fields); err != nil {
+			return nil, fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// neutralizeFormula prefixes text a spreadsheet would evaluate as a
+// formula with a quote. Customer IDs and coupon codes come from callers,
+// and accounting files are routinely opened in spreadsheets.
+func neutralizeFormula(field string) string {
+	if field != "" && strings.ContainsRune("=+-@\t\r", rune(field[0])) {
+		if _, err := strconv.ParseFloat(field, 64); err == nil {
+			return field
+		}
+		return "'" + field
+	}
+	return field
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
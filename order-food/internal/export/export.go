@@ -0,0 +1,103 @@
+// Package export delivers the daily order and refund files for accounting
+// systems: CSV files, optionally PGP encrypted, followed by a manifest
+// describing them, pushed to an SFTP server, an S3 bucket or a directory.
+package export
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/archive"
+)
+
+// Destination receives export files. Put replaces a file of the same name,
+// so a day exported again overwrites its earlier files.
+type Destination interface {
+	Put(ctx context.Context, name string, data []byte) error
+}
+
+// DestinationOptions are the settings of a destination not given in its
+// URL
+type DestinationOptions struct {
+	SFTPKeyFile        string
+	SFTPKnownHostsFile string
+	S3Region           string
+	// S3Endpoint is the URL of an S3 compatible store; empty for AWS
+	S3Endpoint    string
+	S3Credentials S3Credentials
+}
+
+// NewDestination creates the destination target names:
+// sftp://user@host[:port]/dir, s3://bucket/prefix or a local directory,
+// such as a mounted share
+func NewDestination(target string, opts DestinationOptions) (Destination, error) {
+	switch {
+	case strings.HasPrefix(target, "sftp://"):
+		u, err := url.Parse(target)
+		if err != nil || u.Host == "" || u.User == nil {
+			return nil, fmt.Errorf("invalid SFTP destination %q, want sftp://user@host[:port]/dir", target)
+		}
+		if _, hasPassword := u.User.Password(); hasPassword {
+			return nil, fmt.Errorf("SFTP destinations log in with a key, not a password")
+		}
+		return NewSFTPDestination(SFTPConfig{
+			Addr:           u.Host,
+			User:           u.User.Username(),
+			Dir:            u.Path,
+			KeyFile:        opts.SFTPKeyFile,
+			KnownHostsFile: opts.SFTPKnownHostsFile,
+		})
+	case strings.HasPrefix(target, "s3://"):
+		u, err := url.Parse(target)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid S3 destination %q, want s3://bucket/prefix", target)
+		}
+		return NewS3Destination(u.Host, u.Path, opts.S3Region, opts.S3Endpoint, opts.S3Credentials)
+	case strings.Contains(target, "://"):
+		return nil, fmt.Errorf("unsupported destination %q", target)
+	default:
+		return archive.NewFileStore(target)
+	}
+}
+
+// Manifest describes the files of one day. It is delivered after them, so
+// its presence tells the accounting system that the day is complete.
+type Manifest struct {
+	// Day is the business day, formatted 2006-01-02
+	Day         string    `json:"day"`
+	TimeZone    string    `json:"timeZone"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	// Encryption is "pgp" when the files are encrypted, empty otherwise
+	Encryption string         `json:"encryption,omitempty"`
+	Files      []ManifestFile `json:"files"`
+}
+
+// ManifestFile describes a delivered file. Size and SHA256 are those of
+// the file as delivered, encrypted or not.
+type ManifestFile struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+	Size    int    `json:"size"`
+	SHA256  string `json:"sha256"`
+}
+
+// Add appends the file name holding data with records records
+func (m *Manifest) Add(name string, data []byte, records int) {
+	sum := sha256.Sum256(data)
+	m.Files = append(m.Files, ManifestFile{Name: name, Records: records, Size: len(data), SHA256: hex.EncodeToString(sum[:])})
+}
+
+// Encode returns the manifest as indented JSON
+func (m *Manifest) Encode() ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,57 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// Encrypter encrypts export files to the PGP public keys of their
+// recipients, such as the accounting system
+type Encrypter struct {
+	recipients openpgp.EntityList
+}
+
+// NewEncrypter creates an encrypter for the ASCII armored public keys read
+// from r; files can be decrypted with the private key of any of them
+func NewEncrypter(r io.Reader) (*Encrypter, error) {
+	recipients, err := openpgp.ReadArmoredKeyRing(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PGP public key: %w", err)
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no PGP public key given")
+	}
+	return &Encrypter{recipients: recipients}, nil
+}
+
+// NewEncrypterFromFile creates an encrypter for the public keys in the
+// ASCII armored file at path
+func NewEncrypterFromFile(path string) (*Encrypter, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PGP public key: %w", err)
+	}
+	defer f.Close()
+	return NewEncrypter(f)
+}
+
+// Encrypt returns data encrypted as a binary OpenPGP message for the
+// file name
+func (e *Encrypter) Encrypt(name string, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := openpgp.Encrypt(&buf, e.recipients, nil, &openpgp.FileHints{IsBinary: true, FileName: name}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt %s: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to encrypt %s: %w", name, err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/archive"
+)
+
+// S3Credentials sign requests to S3
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials
+	SessionToken string
+}
+
+// S3Destination puts files into an S3 bucket, or a bucket of an S3
+// compatible store, below a prefix
+type S3Destination struct {
+	bucket, prefix string
+	client         *s3.Client
+}
+
+// NewS3Destination creates a destination for bucket in region. An empty
+// endpoint means AWS; otherwise it is the URL of an S3 compatible store,
+// such as MinIO.
+func NewS3Destination(bucket, prefix, region, endpoint string, creds S3Credentials) (*S3Destination, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 credentials are required")
+	}
+
+	client, err := archive.NewS3Client(region, endpoint, credentials.NewStaticCredentialsProvider(creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken))
+	if err != nil {
+		return nil, err
+	}
+	return &S3Destination{bucket: bucket, prefix: strings.Trim(prefix, "/"), client: client}, nil
+}
+
+// Put uploads data as the object prefix/name
+func (d *S3Destination) Put(ctx context.Context, name string, data []byte) error {
+	_, err := d.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(d.bucket),
+		Key:           aws.String(path.Join(d.prefix, name)),
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SFTPConfig holds the settings of an SFTP destination
+type SFTPConfig struct {
+	// Addr is the host:port of the server
+	Addr string
+	User string
+	// Dir is the directory files are put in, relative to the login
+	// directory unless absolute
+	Dir string
+	// KeyFile is the private key to log in with
+	KeyFile string
+	// KnownHostsFile holds the server's host key in OpenSSH known_hosts
+	// format; servers whose key is not listed are refused
+	KnownHostsFile string
+	Timeout        time.Duration
+}
+
+// SFTPDestination puts files into a directory on an SFTP server. Each file
+// is written under a temporary name and renamed into place, so the
+// accounting system never reads a partial file.
+type SFTPDestination struct {
+	cfg    SFTPConfig
+	config *ssh.ClientConfig
+}
+
+// NewSFTPDestination creates a destination from cfg, reading its key and
+// known hosts files
+func NewSFTPDestination(cfg SFTPConfig) (*SFTPDestination, error) {
+	if cfg.Addr == "" || cfg.User == "" {
+		return nil, fmt.Errorf("SFTP host and user are required")
+	}
+	if cfg.KeyFile == "" || cfg.KnownHostsFile == "" {
+		return nil, fmt.Errorf("SFTP key and known hosts files are required")
+	}
+	if _, _, err := net.SplitHostPort(cfg.Addr); err != nil {
+		cfg.Addr = net.JoinHostPort(cfg.Addr, "22")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	key, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SFTP key: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SFTP key: %w", err)
+	}
+	hostKeys, err := knownhosts.New(cfg.KnownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SFTP known hosts file: %w", err)
+	}
+
+	return &SFTPDestination{
+		cfg: cfg,
+		config: &ssh.ClientConfig{
+			User:            cfg.User,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeys,
+			Timeout:         cfg.Timeout,
+		},
+	}, nil
+}
+
+// Put connects to the server and writes data as dir/name
+func (d *SFTPDestination) Put(ctx context.Context, name string, data []byte) error {
+	dialer := net.Dialer{Timeout: d.cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", d.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SFTP server: %w", err)
+	}
+	// Abandon the transfer when ctx ends
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, d.cfg.Addr, d.config)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to connect to SFTP server: %w", err)
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+
+	sftpClient, err := sftp.NewClient(client)
+	if err != nil {
+		return fmt.Errorf("failed to start SFTP: %w", err)
+	}
+	defer sftpClient.Close()
+
+	err = putSFTP(sftpClient, path.Join(d.cfg.Dir, name), data)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// putSFTP writes data to target atomically: it is written to a temporary
+// file in the same directory first, which is then renamed over target
+func putSFTP(client *sftp.Client, target string, data []byte) error {
+	tmp := path.Join(path.Dir(target), "."+path.Base(target)+".part")
+	f, err := client.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", tmp, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = client.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	if err := f.Close(); err != nil {
+		_ = client.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", target, err)
+	}
+
+	// Plain SFTP version 3 renames refuse to replace a file; OpenSSH's
+	// extension replaces it atomically. Without it, a file from an earlier
+	// export of the day is removed first.
+	if _, ok := client.HasExtension("posix-rename@openssh.com"); ok {
+		if err := client.PosixRename(tmp, target); err != nil {
+			return fmt.Errorf("failed to rename %s into place: %w", target, err)
+		}
+		return nil
+	}
+	if err := client.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to replace %s: %w", target, err)
+	}
+	if err := client.Rename(tmp, target); err != nil {
+		return fmt.Errorf("failed to rename %s into place: %w", target, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+)
+
+var (
+	// phonePattern matches phone numbers as people write them: an optional
+	// leading +, then digits with spaces, dots, dashes or parentheses
+	phonePattern = regexp.MustCompile(`^\+?[0-9 ().-]+$`)
+	// postalCodePattern matches the postal codes of any country: letters
+	// and digits, separated by single spaces or dashes
+	postalCodePattern = regexp.MustCompile(`^[A-Za-z0-9]+([ -][A-Za-z0-9]+)*$`)
+
+	registerBindingRulesOnce sync.Once
+)
+
+// registerBindingRules adds the binding rules request models use beyond
+// the validator's own to the validator of httpx. It is safe to call
+// repeatedly.
+func registerBindingRules() {
+	registerBindingRulesOnce.Do(func() {
+		// The names and functions are fixed, so registering cannot fail
+		_ = httpx.RegisterValidation("notblank", isNotBlank)
+		_ = httpx.RegisterValidation("phone", isPhone)
+		_ = httpx.RegisterValidation("postal_code", isPostalCode)
+	})
+}
+
+// isNotBlank requires a string with more than whitespace
+func isNotBlank(fl validator.FieldLevel) bool {
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+// isPhone accepts phone numbers with 7 to 15 digits, the range E.164
+// allows for complete numbers
+func isPhone(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if !phonePattern.MatchString(value) {
+		return false
+	}
+	digits := 0
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits >= 7 && digits <= 15
+}
+
+// isPostalCode accepts postal codes of 2 to 10 characters
+func isPostalCode(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	return len(value) >= 2 && len(value) <= 10 && postalCodePattern.MatchString(value)
+}
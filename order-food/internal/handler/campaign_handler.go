@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// CampaignHandler handles promo code campaign HTTP requests
+type CampaignHandler struct {
+	service service.CampaignServiceInterface
+}
+
+// NewCampaignHandler creates a new campaign handler
+func NewCampaignHandler(service service.CampaignServiceInterface) *CampaignHandler {
+	return &CampaignHandler{service: service}
+}
+
+// CreateCampaign handles POST /admin/campaigns
+// @Summary Generate a promo code campaign
+// @Description Generate count unique codes with an optional prefix and charset and make them valid promo codes by writing each under two or more coupon file names. Download the codes from the export link.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param campaign body models.CampaignReq true "Campaign"
+// @Success 201 {object} models.Campaign
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 409 {object} models.APIResponse "A campaign with this name exists"
+// @Failure 422 {object} models.ValidationErrorResponse "Invalid code settings"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/campaigns [post]
+func (h *CampaignHandler) CreateCampaign(c httpx.Context) {
+	var req models.CampaignReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	campaign, err := h.service.CreateCampaign(req, utils.PrincipalFromContext(c))
+	var campaignErr *service.CampaignError
+	switch {
+	case errors.As(err, &campaignErr):
+		c.JSON(http.StatusUnprocessableEntity, models.ValidationError(http.StatusUnprocessableEntity, "Campaign failed validation", campaignErr.Diagnostics))
+		return
+	case errors.Is(err, service.ErrCampaignExists):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "A campaign with this name exists"))
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to create campaign"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.HATEOASResponse{
+		Data:  campaign,
+		Links: campaignLinks(campaign.ID),
+	})
+}
+
+// GetCampaign handles GET /admin/campaigns/:campaignId
+// @Summary Get a promo code campaign
+// @Tags admin
+// @Produce json
+// @Param campaignId path string true "Campaign ID"
+// @Success 200 {object} models.Campaign
+// @Failure 404 {object} models.APIResponse "Campaign not found"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/campaigns/{campaignId} [get]
+func (h *CampaignHandler) GetCampaign(c httpx.Context) {
+	campaign, err := h.service.GetCampaign(c.Param("campaignId"))
+	if errors.Is(err, service.ErrCampaignNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Campaign not found"))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch campaign"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data:  campaign,
+		Links: campaignLinks(campaign.ID),
+	})
+}
+
+// ExportCampaign handles GET /admin/campaigns/:campaignId/export
+// @Summary Download the codes of a campaign
+// @Description The codes of the campaign, one per line, in the format of a coupon file
+// @Tags admin
+// @Produce plain
+// @Param campaignId path string true "Campaign ID"
+// @Success 200 {string} string "Codes, one per line"
+// @Failure 404 {object} models.APIResponse "Campaign not found"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/campaigns/{campaignId}/export [get]
+func (h *CampaignHandler) ExportCampaign(c httpx.Context) {
+	campaign, codes, err := h.service.ExportCodes(c.Param("campaignId"))
+	if errors.Is(err, service.ErrCampaignNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Campaign not found"))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to export campaign"))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"campaign-%s.txt\"", campaign.ID))
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(strings.Join(codes, "\n")+"\n"))
+}
+
+func campaignLinks(id string) []models.Link {
+	return []models.Link{
+		{Href: "/api/v1/admin/campaigns/" + id, Rel: "self", Method: "GET"},
+		{Href: "/api/v1/admin/campaigns/" + id + "/export", Rel: "export", Method: "GET"},
+	}
+}
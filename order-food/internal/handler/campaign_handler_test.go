@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCampaignService is a mock implementation of CampaignServiceInterface
+type MockCampaignService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.CampaignServiceInterface = (*MockCampaignService)(nil)
+
+func (m *MockCampaignService) CreateCampaign(req models.CampaignReq, actor string) (models.Campaign, error) {
+	args := m.Called(req, actor)
+	return args.Get(0).(models.Campaign), args.Error(1)
+}
+
+func (m *MockCampaignService) GetCampaign(id string) (models.Campaign, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.Campaign), args.Error(1)
+}
+
+func (m *MockCampaignService) ExportCodes(id string) (models.Campaign, []string, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.Campaign), args.Get(1).([]string), args.Error(2)
+}
+
+const testCampaignID = "5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c"
+
+func TestCampaignHandler_CreateCampaign(t *testing.T) {
+	req := models.CampaignReq{Name: "Summer", Count: 100, Prefix: "SUM", Length: 10}
+
+	tests := []struct {
+		name       string
+		campaign   models.Campaign
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "created",
+			campaign:   models.Campaign{ID: testCampaignID, Name: "Summer", CodeCount: 100},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"href":"/api/v1/admin/campaigns/` + testCampaignID + `/export"`,
+		},
+		{
+			name:       "invalid settings",
+			err:        &service.CampaignError{Diagnostics: []models.FieldError{{Field: "prefix", Message: "prefix must leave at least 4 random characters"}}},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `"field":"prefix"`,
+		},
+		{
+			name:       "name taken",
+			err:        service.ErrCampaignExists,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "database error",
+			err:        errors.New("database error"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockCampaignService)
+			handler := NewCampaignHandler(mockService)
+			mockService.On("CreateCampaign", req, "admin").Return(tt.campaign, tt.err)
+
+			// Create request
+			body, _ := json.Marshal(req)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/admin/campaigns", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			utils.SetPrincipal(c, "admin", []string{utils.ScopeAll})
+
+			// Execute
+			handler.CreateCampaign(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCampaignHandler_CreateCampaign_InvalidBody(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	handler := NewCampaignHandler(new(MockCampaignService))
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/admin/campaigns", bytes.NewBufferString(`{"name":"Summer","count":0}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.CreateCampaign(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCampaignHandler_ExportCampaign(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCampaignService)
+	handler := NewCampaignHandler(mockService)
+	mockService.On("ExportCodes", testCampaignID).
+		Return(models.Campaign{ID: testCampaignID}, []string{"SUMABCDEFG", "SUMHJKMNPQ"}, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/campaigns/"+testCampaignID+"/export", nil)
+	c.Params = gin.Params{{Key: "campaignId", Value: testCampaignID}}
+
+	// Execute
+	handler.ExportCampaign(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "SUMABCDEFG\nSUMHJKMNPQ\n", w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+	assert.Equal(t, `attachment; filename="campaign-`+testCampaignID+`.txt"`, w.Header().Get("Content-Disposition"))
+}
+
+func TestCampaignHandler_GetCampaign_NotFound(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCampaignService)
+	handler := NewCampaignHandler(mockService)
+	mockService.On("GetCampaign", "missing").Return(models.Campaign{}, service.ErrCampaignNotFound)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/campaigns/missing", nil)
+	c.Params = gin.Params{{Key: "campaignId", Value: "missing"}}
+
+	// Execute
+	handler.GetCampaign(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
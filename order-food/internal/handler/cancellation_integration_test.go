@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/middleware"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// These tests run the real service and repository over a slow mock
+// database, so they prove the query itself is cancelled rather than only the
+// handler giving up on it.
+
+// slowQuery is far longer than any test waits
+const slowQuery = 10 * time.Second
+
+func TestCancellation_ClientDisconnectCancelsOrderQuery(t *testing.T) {
+	// Setup mock database whose first query would take slowQuery
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM orders").
+		WillDelayFor(slowQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	h := NewOrderHandler(service.NewOrderService(nil, repository.NewOrderRepository(db), nil, nil, nil, nil), nil, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(middleware.CancellationMiddleware(0)))
+	handled := make(chan time.Duration, 1)
+	router.GET("/orders", func(c *gin.Context) {
+		start := time.Now()
+		h.ListOrders(ginx.New(c))
+		handled <- time.Since(start)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// Create request from a client that gives up quickly
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/orders", nil)
+	assert.NoError(t, err)
+
+	// Execute
+	_, err = server.Client().Do(req)
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	select {
+	case elapsed := <-handled:
+		assert.Less(t, elapsed, slowQuery/2, "handler kept waiting for the query")
+	case <-time.After(slowQuery / 2):
+		t.Fatal("handler did not return after the client disconnected")
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCancellation_RequestTimeoutCancelsAnalyticsQuery(t *testing.T) {
+	// Setup mock database whose first query would take slowQuery
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	mock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM orders").
+		WillDelayFor(slowQuery).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "redemptions", "sum"}).AddRow(0, 0, 0))
+
+	h := NewCouponAnalyticsHandler(service.NewCouponAnalyticsService(repository.NewCouponRepository(db)), testZones(t))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(middleware.CancellationMiddleware(100 * time.Millisecond)))
+	router.GET("/admin/coupons/analytics", ginx.Wrap(h.GetAnalytics))
+
+	// Create request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/admin/coupons/analytics?from=2024-01-01&to=2024-02-01", nil)
+
+	// Execute
+	start := time.Now()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Less(t, time.Since(start), slowQuery/2, "handler kept waiting for the query")
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// methodOrder is the order methods are listed in
+var methodOrder = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// CapabilityHandler answers OPTIONS requests and describes which methods
+// every path of the API allows
+type CapabilityHandler struct {
+	routes  []models.RouteCapability
+	methods map[string]string
+}
+
+// NewCapabilityHandler creates a capability handler; SetRoutes tells it the
+// routes once they are all registered
+func NewCapabilityHandler() *CapabilityHandler {
+	return &CapabilityHandler{methods: make(map[string]string)}
+}
+
+// SetRoutes records the methods of every path of routes, adding OPTIONS,
+// and returns the paths for registering Options
+func (h *CapabilityHandler) SetRoutes(routes []httpx.Route) []string {
+	methods := make(map[string][]string)
+	var paths []string
+	for _, route := range routes {
+		if _, ok := methods[route.Path]; !ok {
+			paths = append(paths, route.Path)
+		}
+		methods[route.Path] = append(methods[route.Path], route.Method)
+	}
+	slices.Sort(paths)
+
+	h.routes = make([]models.RouteCapability, 0, len(paths))
+	for _, path := range paths {
+		allowed := append(methods[path], http.MethodOptions)
+		slices.SortFunc(allowed, func(a, b string) int {
+			return slices.Index(methodOrder, a) - slices.Index(methodOrder, b)
+		})
+		allowed = slices.Compact(allowed)
+		h.methods[path] = strings.Join(allowed, ", ")
+		h.routes = append(h.routes, models.RouteCapability{Path: uriTemplate(path), Methods: allowed})
+	}
+	return paths
+}
+
+// uriTemplate writes the parameters of a route path, :orderId or *any, as
+// {orderId} or {any}
+func uriTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Options handles OPTIONS on every route with the methods its path allows,
+// in Allow and, for CORS preflight requests, Access-Control-Allow-Methods
+func (h *CapabilityHandler) Options(c httpx.Context) {
+	allowed := h.methods[c.Route()]
+	c.Header("Allow", allowed)
+	c.Header("Access-Control-Allow-Methods", allowed)
+	c.Status(http.StatusNoContent)
+}
+
+// GetCapabilities handles GET /capabilities
+// @Summary API capabilities
+// @Description Every path of the API, as a URI template, with the methods it allows. The methods do not depend on the caller; authentication and scopes still apply.
+// @Tags discovery
+// @Produce json
+// @Success 200 {array} models.RouteCapability
+// @Router /api/v1/capabilities [get]
+func (h *CapabilityHandler) GetCapabilities(c httpx.Context) {
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data: h.routes,
+		Links: []models.Link{
+			{Href: "/api/v1/capabilities", Rel: "self", Method: "GET"},
+			{Href: "/api/v1", Rel: "root", Method: "GET"},
+		},
+	})
+}
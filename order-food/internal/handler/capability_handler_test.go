@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// newCapabilityRouter returns a router with a few routes answering OPTIONS
+// like SetupRouter
+func newCapabilityRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+	capabilities := NewCapabilityHandler()
+	noop := func(c *gin.Context) {}
+	router.GET("/api/v1/capabilities", ginx.Wrap(capabilities.GetCapabilities))
+	router.GET("/api/v1/orders", noop)
+	router.POST("/api/v1/orders", noop)
+	router.PATCH("/api/v1/orders/:orderId/status", noop)
+	for _, path := range capabilities.SetRoutes(ginx.Routes(router.Routes())) {
+		router.OPTIONS(path, ginx.Wrap(capabilities.Options))
+	}
+	return router
+}
+
+func TestCapabilityHandler_Options(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		wantAllow string
+	}{
+		{name: "collection", path: "/api/v1/orders", wantAllow: "GET, POST, OPTIONS"},
+		{name: "path parameter", path: "/api/v1/orders/order-1/status", wantAllow: "PATCH, OPTIONS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			router := newCapabilityRouter()
+
+			// Create CORS preflight request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("OPTIONS", tt.path, nil)
+			req.Header.Set("Origin", "http://example.com")
+			req.Header.Set("Access-Control-Request-Method", "POST")
+
+			// Execute
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, tt.wantAllow, w.Header().Get("Allow"))
+			assert.Equal(t, tt.wantAllow, w.Header().Get("Access-Control-Allow-Methods"))
+		})
+	}
+}
+
+func TestCapabilityHandler_MethodNotAllowed(t *testing.T) {
+	// Setup
+	router := newCapabilityRouter()
+
+	// Create request with a method the path does not allow
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/v1/orders", nil)
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Contains(t, w.Header().Get("Allow"), "POST")
+}
+
+func TestCapabilityHandler_GetCapabilities(t *testing.T) {
+	// Setup
+	router := newCapabilityRouter()
+
+	// Create request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/capabilities", nil)
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []models.RouteCapability `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []models.RouteCapability{
+		{Path: "/api/v1/capabilities", Methods: []string{"GET", "OPTIONS"}},
+		{Path: "/api/v1/orders", Methods: []string{"GET", "POST", "OPTIONS"}},
+		{Path: "/api/v1/orders/{orderId}/status", Methods: []string{"PATCH", "OPTIONS"}},
+	}, response.Data)
+}
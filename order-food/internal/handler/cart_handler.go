@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/couponguard"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/tracing"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// CartHandler handles shopping cart HTTP requests. Carts created with a
+// customer access token are only found with a token of the same customer.
+type CartHandler struct {
+	service          service.CartServiceInterface
+	promoCodeService service.PromoCodeServiceInterface
+	couponGuard      *couponguard.Guard
+}
+
+// NewCartHandler creates a new cart handler. Promo codes set on carts are
+// validated with promoCodeService; a nil couponGuard disables brute-force
+// protection on them.
+func NewCartHandler(service service.CartServiceInterface, promoCodeService service.PromoCodeServiceInterface, couponGuard *couponguard.Guard) *CartHandler {
+	registerBindingRules()
+	return &CartHandler{
+		service:          service,
+		promoCodeService: promoCodeService,
+		couponGuard:      couponGuard,
+	}
+}
+
+// CreateCart handles POST /carts
+// @Summary Create a shopping cart
+// @Description Start a cart, optionally with items and a promo code. The response prices it at the current prices and previews the promo code's discount. Keep the returned ID to change the cart and check it out.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param cart body models.CartReq true "Items and promo code"
+// @Success 201 {object} models.Cart
+// @Failure 400 {object} models.APIResponse "Invalid input, unknown product or invalid promo code"
+// @Failure 422 {object} models.APIResponse "Too many products"
+// @Failure 429 {object} models.APIResponse "Too many invalid promo codes"
+// @Security ApiKeyAuth
+// @Router /api/v1/carts [post]
+func (h *CartHandler) CreateCart(c httpx.Context) {
+	var req models.CartReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+	if !checkPromoCode(c, h.promoCodeService, h.couponGuard, req.CouponCode) {
+		return
+	}
+
+	cart, err := h.service.CreateCart(c.Context(), req, utils.CustomerFromContext(c))
+	if err != nil {
+		writeCartError(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/carts/"+cart.ID)
+	c.JSON(http.StatusCreated, cartResponse(cart))
+}
+
+// GetCart handles GET /carts/:cartId
+// @Summary Get a shopping cart
+// @Description Returns the cart priced at the current prices with its subtotal, the discount its promo code would give and the total. A promo code that would not apply says why in coupon.message.
+// @Tags cart
+// @Produce json
+// @Param cartId path string true "Cart ID"
+// @Success 200 {object} models.Cart
+// @Failure 404 {object} models.APIResponse "Cart not found"
+// @Security ApiKeyAuth
+// @Router /api/v1/carts/{cartId} [get]
+func (h *CartHandler) GetCart(c httpx.Context) {
+	cart, err := h.service.GetCart(c.Context(), c.Param("cartId"), utils.CustomerFromContext(c))
+	if err != nil {
+		writeCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cartResponse(cart))
+}
+
+// AddCartItem handles POST /carts/:cartId/items
+// @Summary Add a product to a cart
+// @Description Put a product in the cart; a product already in it gets the quantity added
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param cartId path string true "Cart ID"
+// @Param item body models.CartItemReq true "Product and quantity"
+// @Success 200 {object} models.Cart
+// @Failure 400 {object} models.APIResponse "Invalid input or unknown product"
+// @Failure 404 {object} models.APIResponse "Cart not found"
+// @Failure 409 {object} models.APIResponse "Cart already checked out"
+// @Failure 422 {object} models.APIResponse "Too many products"
+// @Security ApiKeyAuth
+// @Router /api/v1/carts/{cartId}/items [post]
+func (h *CartHandler) AddCartItem(c httpx.Context) {
+	var req models.CartItemReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	cart, err := h.service.AddItem(c.Context(), c.Param("cartId"), utils.CustomerFromContext(c), req)
+	if err != nil {
+		writeCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cartResponse(cart))
+}
+
+// UpdateCartItem handles PUT /carts/:cartId/items/:productId
+// @Summary Change the quantity of a product in a cart
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param cartId path string true "Cart ID"
+// @Param productId path string true "Product ID"
+// @Param quantity body models.CartQuantityReq true "New quantity"
+// @Success 200 {object} models.Cart
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 404 {object} models.APIResponse "Cart not found or product not in it"
+// @Failure 409 {object} models.APIResponse "Cart already checked out"
+// @Security ApiKeyAuth
+// @Router /api/v1/carts/{cartId}/items/{productId} [put]
+func (h *CartHandler) UpdateCartItem(c httpx.Context) {
+	var req models.CartQuantityReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	cart, err := h.service.UpdateItem(c.Context(), c.Param("cartId"), utils.CustomerFromContext(c), c.Param("productId"), req.Quantity)
+	if err != nil {
+		writeCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cartResponse(cart))
+}
+
+// RemoveCartItem handles DELETE /carts/:cartId/items/:productId
+// @Summary Remove a product from a cart
+// @Tags cart
+// @Produce json
+// @Param cartId path string true "Cart ID"
+// @Param productId path string true "Product ID"
+// @Success 200 {object} models.Cart
+// @Failure 404 {object} models.APIResponse "Cart not found or product not in it"
+// @Failure 409 {object} models.APIResponse "Cart already checked out"
+// @Security ApiKeyAuth
+// @Router /api/v1/carts/{cartId}/items/{productId} [delete]
+func (h *CartHandler) RemoveCartItem(c httpx.Context) {
+	cart, err := h.service.RemoveItem(c.Context(), c.Param("cartId"), utils.CustomerFromContext(c), c.Param("productId"))
+	if err != nil {
+		writeCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cartResponse(cart))
+}
+
+// SetCartCoupon handles PUT /carts/:cartId/coupon
+// @Summary Apply a promo code to a cart
+// @Description Set the promo code to preview on the cart and use at checkout, replacing any other
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param cartId path string true "Cart ID"
+// @Param coupon body models.CartCouponReq true "Promo code"
+// @Success 200 {object} models.Cart
+// @Failure 400 {object} models.APIResponse "Invalid promo code"
+// @Failure 404 {object} models.APIResponse "Cart not found"
+// @Failure 409 {object} models.APIResponse "Cart already checked out"
+// @Failure 429 {object} models.APIResponse "Too many invalid promo codes"
+// @Security ApiKeyAuth
+// @Router /api/v1/carts/{cartId}/coupon [put]
+func (h *CartHandler) SetCartCoupon(c httpx.Context) {
+	var req models.CartCouponReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+	if !checkPromoCode(c, h.promoCodeService, h.couponGuard, req.CouponCode) {
+		return
+	}
+
+	cart, err := h.service.SetCouponCode(c.Context(), c.Param("cartId"), utils.CustomerFromContext(c), req.CouponCode)
+	if err != nil {
+		writeCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cartResponse(cart))
+}
+
+// RemoveCartCoupon handles DELETE /carts/:cartId/coupon
+// @Summary Remove the promo code from a cart
+// @Tags cart
+// @Produce json
+// @Param cartId path string true "Cart ID"
+// @Success 200 {object} models.Cart
+// @Failure 404 {object} models.APIResponse "Cart not found"
+// @Failure 409 {object} models.APIResponse "Cart already checked out"
+// @Security ApiKeyAuth
+// @Router /api/v1/carts/{cartId}/coupon [delete]
+func (h *CartHandler) RemoveCartCoupon(c httpx.Context) {
+	cart, err := h.service.SetCouponCode(c.Context(), c.Param("cartId"), utils.CustomerFromContext(c), "")
+	if err != nil {
+		writeCartError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cartResponse(cart))
+}
+
+// Checkout handles POST /carts/:cartId/checkout
+// @Summary Check out a cart
+// @Description Place an order with the items and promo code of the cart, at the current prices. A cart becomes one order at most; it cannot change afterwards. Send an Idempotency-Key header to retry safely.
+// @Tags cart
+// @Accept json
+// @Produce json
+// @Param cartId path string true "Cart ID"
+// @Param checkout body models.CheckoutReq false "Reservation, customer, delivery and payment method"
+// @Success 201 {object} models.Order
+// @Failure 400 {object} models.APIResponse "Invalid input, unknown product or invalid promo code"
+// @Failure 402 {object} models.PaymentDeclinedResponse "The payment method was declined; the cart stays open"
+// @Failure 404 {object} models.APIResponse "Cart not found"
+// @Failure 409 {object} models.APIResponse "Cart already checked out or changed, reservation expired, or promo code used up"
+// @Failure 422 {object} models.ValidationErrorResponse "Empty cart, insufficient stock, or a product needs options chosen, which carts cannot hold"
+// @Failure 503 {object} models.APIResponse "Too many orders are being placed"
+// @Security ApiKeyAuth
+// @Router /api/v1/carts/{cartId}/checkout [post]
+func (h *CartHandler) Checkout(c httpx.Context) {
+	// The body is optional
+	var req models.CheckoutReq
+	if err := c.Bind(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	order, err := h.service.Checkout(c.Context(), c.Param("cartId"), utils.CustomerFromContext(c), req)
+	if writeStockError(c, err) || writePromoCodeError(c, err, h.promoCodeService.Policy()) {
+		return
+	}
+	if errors.Is(err, service.ErrCartEmpty) {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse(http.StatusUnprocessableEntity, "Cart is empty"))
+		return
+	}
+	if errors.Is(err, repository.ErrCartNotFound) || errors.Is(err, repository.ErrCartCheckedOut) || errors.Is(err, repository.ErrCartChanged) {
+		writeCartError(c, err)
+		return
+	}
+	if err != nil {
+		writePlaceOrderError(c, err)
+		return
+	}
+	tracing.OrderCommitted(c.Context(), tracing.OrderSourceCart, order.ID, len(order.Items), order.Total)
+
+	c.JSON(http.StatusCreated, orderResponse(order))
+}
+
+// writeCartError writes the response for an error of a cart operation
+func writeCartError(c httpx.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrCartNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Cart not found"))
+	case errors.Is(err, repository.ErrCartItemNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Product is not in the cart"))
+	case errors.Is(err, repository.ErrCartCheckedOut):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Cart has already been checked out"))
+	case errors.Is(err, repository.ErrCartChanged):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Cart changed during checkout; review it and try again"))
+	case errors.Is(err, repository.ErrCartFull):
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse(http.StatusUnprocessableEntity,
+			fmt.Sprintf("A cart can hold at most %d products", repository.MaxCartItems)))
+	case errors.Is(err, service.ErrProductNotFound):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+	case errors.Is(err, service.ErrPromoCodeUnavailable):
+		promoCodeUnavailable(c)
+	default:
+		slog.ErrorContext(c.Context(), "Cart request failed", "error", err)
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to process cart"))
+	}
+}
+
+// cartResponse adds the links of a cart; checked out carts link to their
+// order instead of the operations changing them
+func cartResponse(cart models.Cart) models.Cart {
+	self := "/api/v1/carts/" + cart.ID
+	cart.Links = []models.Link{{Href: self, Rel: "self", Method: "GET"}}
+	if cart.OrderID != "" {
+		cart.Links = append(cart.Links, models.Link{Href: "/api/v1/orders/" + cart.OrderID, Rel: "order", Method: "GET"})
+		return cart
+	}
+	cart.Links = append(cart.Links,
+		models.Link{Href: self + "/items", Rel: "add-item", Method: "POST"},
+		models.Link{Href: self + "/coupon", Rel: "coupon", Method: "PUT"},
+		models.Link{Href: self + "/checkout", Rel: "checkout", Method: "POST"},
+	)
+	return cart
+}
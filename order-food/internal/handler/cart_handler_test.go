@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCartService is a mock implementation of CartServiceInterface
+type MockCartService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.CartServiceInterface = (*MockCartService)(nil)
+
+func (m *MockCartService) CreateCart(ctx context.Context, req models.CartReq, accountID string) (models.Cart, error) {
+	args := m.Called(req, accountID)
+	return args.Get(0).(models.Cart), args.Error(1)
+}
+
+func (m *MockCartService) GetCart(ctx context.Context, id, accountID string) (models.Cart, error) {
+	args := m.Called(id, accountID)
+	return args.Get(0).(models.Cart), args.Error(1)
+}
+
+func (m *MockCartService) AddItem(ctx context.Context, id, accountID string, req models.CartItemReq) (models.Cart, error) {
+	args := m.Called(id, accountID, req)
+	return args.Get(0).(models.Cart), args.Error(1)
+}
+
+func (m *MockCartService) UpdateItem(ctx context.Context, id, accountID, productID string, quantity int) (models.Cart, error) {
+	args := m.Called(id, accountID, productID, quantity)
+	return args.Get(0).(models.Cart), args.Error(1)
+}
+
+func (m *MockCartService) RemoveItem(ctx context.Context, id, accountID, productID string) (models.Cart, error) {
+	args := m.Called(id, accountID, productID)
+	return args.Get(0).(models.Cart), args.Error(1)
+}
+
+func (m *MockCartService) SetCouponCode(ctx context.Context, id, accountID, code string) (models.Cart, error) {
+	args := m.Called(id, accountID, code)
+	return args.Get(0).(models.Cart), args.Error(1)
+}
+
+func (m *MockCartService) Checkout(ctx context.Context, id, accountID string, req models.CheckoutReq) (models.Order, error) {
+	args := m.Called(id, accountID, req)
+	return args.Get(0).(models.Order), args.Error(1)
+}
+
+func TestCartHandler_CreateCart_Success(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockCartService := new(MockCartService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewCartHandler(mockCartService, mockPromoService, nil)
+
+	req := models.CartReq{Items: []models.CartItemReq{{ProductID: "1", Quantity: 2}}, CouponCode: "HAPPYHRS"}
+	mockPromoService.On("ValidatePromoCode", "HAPPYHRS").Return(models.PromoCode{Code: "HAPPYHRS"}, true, nil)
+	mockCartService.On("CreateCart", req, "").Return(models.Cart{
+		ID:       "cart-1",
+		Items:    []models.CartLine{{ProductID: "1", Quantity: 2, UnitPrice: 6.5, LineTotal: 13}},
+		Coupon:   &models.CartCoupon{Code: "HAPPYHRS", Applied: true},
+		Subtotal: 13,
+		Total:    13,
+	}, nil)
+
+	// Create request
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/carts", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.CreateCart(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/api/v1/carts/cart-1", w.Header().Get("Location"))
+	assert.Contains(t, w.Body.String(), `"rel":"checkout"`)
+	mockCartService.AssertExpectations(t)
+	mockPromoService.AssertExpectations(t)
+}
+
+func TestCartHandler_CreateCart_InvalidPromoCode(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockCartService := new(MockCartService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewCartHandler(mockCartService, mockPromoService, nil)
+
+	mockPromoService.On("ValidatePromoCode", "NOPE1234").Return(models.PromoCode{}, false, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/carts", bytes.NewBufferString(`{"couponCode":"NOPE1234"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.CreateCart(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockCartService.AssertNotCalled(t, "CreateCart", mock.Anything, mock.Anything)
+}
+
+func TestCartHandler_GetCart_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{name: "not found", err: repository.ErrCartNotFound, wantCode: http.StatusNotFound},
+		{name: "unknown product", err: service.ErrProductNotFound, wantCode: http.StatusBadRequest},
+		{name: "promo codes unavailable", err: service.ErrPromoCodeUnavailable, wantCode: http.StatusServiceUnavailable},
+		{name: "database down", err: assert.AnError, wantCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockCartService := new(MockCartService)
+			handler := NewCartHandler(mockCartService, new(MockPromoCodeService), nil)
+
+			mockCartService.On("GetCart", "cart-1", "").Return(models.Cart{}, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/carts/cart-1", nil)
+			c.Params = gin.Params{{Key: "cartId", Value: "cart-1"}}
+
+			// Execute
+			handler.GetCart(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.NotContains(t, w.Body.String(), assert.AnError.Error())
+			mockCartService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCartHandler_AddCartItem_CheckedOut(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockCartService := new(MockCartService)
+	handler := NewCartHandler(mockCartService, new(MockPromoCodeService), nil)
+
+	item := models.CartItemReq{ProductID: "1", Quantity: 1}
+	mockCartService.On("AddItem", "cart-1", "", item).Return(models.Cart{}, repository.ErrCartCheckedOut)
+
+	// Create request
+	body, _ := json.Marshal(item)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/carts/cart-1/items", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "cartId", Value: "cart-1"}}
+
+	// Execute
+	handler.AddCartItem(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockCartService.AssertExpectations(t)
+}
+
+func TestCartHandler_Checkout_Success(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockCartService := new(MockCartService)
+	handler := NewCartHandler(mockCartService, new(MockPromoCodeService), nil)
+
+	mockCartService.On("Checkout", "cart-1", "", models.CheckoutReq{}).Return(models.Order{
+		ID:    "order-1",
+		Items: []models.OrderItem{{ProductID: "1", Quantity: 2}},
+		Total: 13,
+	}, nil)
+
+	// Create request without a body
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/carts/cart-1/checkout", nil)
+	c.Params = gin.Params{{Key: "cartId", Value: "cart-1"}}
+
+	// Execute
+	handler.Checkout(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"order-1"`)
+	mockCartService.AssertExpectations(t)
+}
+
+func TestCartHandler_Checkout_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+	}{
+		{name: "empty", err: service.ErrCartEmpty, wantCode: http.StatusUnprocessableEntity},
+		{name: "not found", err: repository.ErrCartNotFound, wantCode: http.StatusNotFound},
+		{name: "checked out", err: repository.ErrCartCheckedOut, wantCode: http.StatusConflict},
+		{name: "changed", err: repository.ErrCartChanged, wantCode: http.StatusConflict},
+		{name: "promo code used up", err: service.ErrPromoCodeExhausted, wantCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockCartService := new(MockCartService)
+			handler := NewCartHandler(mockCartService, new(MockPromoCodeService), nil)
+
+			mockCartService.On("Checkout", "cart-1", "", models.CheckoutReq{CustomerID: "customer-1"}).Return(models.Order{}, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/carts/cart-1/checkout", bytes.NewBufferString(`{"customerId":"customer-1"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "cartId", Value: "cart-1"}}
+
+			// Execute
+			handler.Checkout(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantCode, w.Code)
+			mockCartService.AssertExpectations(t)
+		})
+	}
+}
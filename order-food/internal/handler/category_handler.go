@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// CategoryHandler handles category HTTP requests
+type CategoryHandler struct {
+	service  service.CategoryServiceInterface
+	products service.ProductServiceInterface
+}
+
+// NewCategoryHandler creates a new category handler; products lists the
+// products of a category
+func NewCategoryHandler(service service.CategoryServiceInterface, products service.ProductServiceInterface) *CategoryHandler {
+	return &CategoryHandler{service: service, products: products}
+}
+
+// ListCategories handles GET /categories
+// @Summary List categories
+// @Description Every category of the menu, ordered by name, with the number of products in it
+// @Tags category
+// @Produce json
+// @Success 200 {array} models.Category
+// @Router /api/v1/categories [get]
+func (h *CategoryHandler) ListCategories(c httpx.Context) {
+	categories, err := h.service.ListCategories(c.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch categories"))
+		return
+	}
+
+	items := make([]models.HATEOASResponse, len(categories))
+	for i, category := range categories {
+		items[i] = models.HATEOASResponse{Data: category, Links: categoryLinks(category.ID)}
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data:  items,
+		Links: []models.Link{{Href: "/api/v1/categories", Rel: "self", Method: "GET"}},
+	})
+}
+
+// GetCategory handles GET /categories/:categoryId
+// @Summary Find category by ID
+// @Description Returns a single category with the number of products in it
+// @Tags category
+// @Produce json
+// @Param categoryId path string true "Category ID"
+// @Success 200 {object} models.Category
+// @Failure 404 {object} models.APIResponse "Category not found"
+// @Router /api/v1/categories/{categoryId} [get]
+func (h *CategoryHandler) GetCategory(c httpx.Context) {
+	category, err := h.service.GetCategory(c.Context(), c.Param("categoryId"))
+	if writeCategoryError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch category"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{Data: category, Links: categoryLinks(category.ID)})
+}
+
+// ListCategoryProducts handles GET /categories/:categoryId/products with
+// pagination and HATEOAS
+// @Summary List the products of a category
+// @Description Products of the category, paginated like the product listing
+// @Tags category
+// @Produce json
+// @Param categoryId path string true "Category ID"
+// @Param sort query string false "Comma-separated sort fields, prefix with - for descending (id, name, price, category)"
+// @Param after query string false "Cursor of the page to return, from the previous page's nextCursor"
+// @Param limit query int false "Products per page when paginating by cursor"
+// @Param Accept-Language header string false "Preferred languages of product names and descriptions"
+// @Success 200 {array} models.Product
+// @Failure 400 {object} models.APIResponse "Invalid sort expression or cursor"
+// @Failure 404 {object} models.APIResponse "Category not found"
+// @Router /api/v1/categories/{categoryId}/products [get]
+func (h *CategoryHandler) ListCategoryProducts(c httpx.Context) {
+	p := utils.PaginationFromContext(c)
+
+	sort, err := utils.ParseSort(c.Query("sort"), productSortFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	category, err := h.service.GetCategory(c.Context(), c.Param("categoryId"))
+	if writeCategoryError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch category"))
+		return
+	}
+
+	filter := models.ProductFilter{CategoryID: category.ID}
+	path := "/api/v1/categories/" + url.PathEscape(category.ID) + "/products"
+	query := utils.SortQuery(sort)
+	var response models.PaginatedResponse
+	if p.Cursor {
+		products, next, err := h.products.ListProductsAfter(p.After, p.PerPage, sort, filter)
+		if errors.Is(err, service.ErrInvalidCursor) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid cursor; cursors are only valid with the sort they were issued for"))
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch products"))
+			return
+		}
+		response = models.PaginatedResponse{
+			Data:   productsWithLinks(c, products),
+			Cursor: models.CursorMeta{Limit: p.PerPage, NextCursor: next},
+			Links:  utils.BuildCursorLinks(p, next, path, query),
+		}
+	} else {
+		products, total, err := h.products.ListProductsPaginated(p.PerPage, p.Offset, sort, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch products"))
+			return
+		}
+		totalPages := utils.TotalPages(total, p.PerPage)
+		response = models.PaginatedResponse{
+			Data: productsWithLinks(c, products),
+			Pagination: models.PaginationMeta{
+				Page:       p.Page,
+				PerPage:    p.PerPage,
+				TotalPages: totalPages,
+				TotalItems: total,
+			},
+			Links: utils.BuildPaginationLinksWithQuery(p.Page, totalPages, path, p.PerPage, query),
+		}
+	}
+
+	utils.SetLinkHeader(c, response.Links)
+	c.Header("Vary", "Accept-Language")
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateCategory handles POST /categories
+// @Summary Add a category
+// @Description Add a category to the menu. Its ID is derived from its name when none is given.
+// @Tags category
+// @Accept json
+// @Produce json
+// @Param category body models.CategoryReq true "Category"
+// @Success 201 {object} models.Category
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 409 {object} models.APIResponse "Category ID or name already in use"
+// @Failure 422 {object} models.APIResponse "Invalid category"
+// @Security AdminKeyAuth
+// @Router /api/v1/categories [post]
+func (h *CategoryHandler) CreateCategory(c httpx.Context) {
+	var req models.CategoryReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	category, err := h.service.CreateCategory(c.Context(), req, utils.PrincipalFromContext(c))
+	if writeCategoryError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to create category"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.HATEOASResponse{Data: category, Links: categoryLinks(category.ID)})
+}
+
+// UpdateCategory handles PUT /categories/:categoryId
+// @Summary Replace a category
+// @Description Replace the name and description of a category. A new name is carried over to the products of the category; price rules and promo code discounts naming the old name are not changed.
+// @Tags category
+// @Accept json
+// @Produce json
+// @Param categoryId path string true "Category ID"
+// @Param category body models.CategoryReq true "Category"
+// @Success 200 {object} models.Category
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 404 {object} models.APIResponse "Category not found"
+// @Failure 409 {object} models.APIResponse "Category name already in use"
+// @Failure 422 {object} models.APIResponse "Invalid category"
+// @Security AdminKeyAuth
+// @Router /api/v1/categories/{categoryId} [put]
+func (h *CategoryHandler) UpdateCategory(c httpx.Context) {
+	var req models.CategoryReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	category, err := h.service.UpdateCategory(c.Context(), c.Param("categoryId"), req, utils.PrincipalFromContext(c))
+	if writeCategoryError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to update category"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{Data: category, Links: categoryLinks(category.ID)})
+}
+
+// DeleteCategory handles DELETE /categories/:categoryId
+// @Summary Delete a category
+// @Description Remove a category from the menu. Categories with products, including deleted products kept for past orders, cannot be deleted.
+// @Tags category
+// @Produce json
+// @Param categoryId path string true "Category ID"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "Category not found"
+// @Failure 409 {object} models.APIResponse "Products are in the category"
+// @Security AdminKeyAuth
+// @Router /api/v1/categories/{categoryId} [delete]
+func (h *CategoryHandler) DeleteCategory(c httpx.Context) {
+	err := h.service.DeleteCategory(c.Context(), c.Param("categoryId"), utils.PrincipalFromContext(c))
+	if writeCategoryError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to delete category"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Category deleted"))
+}
+
+// writeCategoryError writes the response for a category request refused
+// by the service and reports whether err was such a refusal
+func writeCategoryError(c httpx.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrInvalidCategory):
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse(http.StatusUnprocessableEntity, err.Error()))
+	case errors.Is(err, service.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Category not found"))
+	case errors.Is(err, service.ErrCategoryExists):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "A category with this ID or name already exists"))
+	case errors.Is(err, service.ErrCategoryInUse):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Products are in this category; move them to another category first"))
+	default:
+		return false
+	}
+	return true
+}
+
+func categoryLinks(id string) []models.Link {
+	self := "/api/v1/categories/" + url.PathEscape(id)
+	return []models.Link{
+		{Href: self, Rel: "self", Method: "GET"},
+		{Href: self + "/products", Rel: "products", Method: "GET"},
+		{Href: self, Rel: "update", Method: "PUT"},
+		{Href: self, Rel: "delete", Method: "DELETE"},
+		{Href: "/api/v1/categories", Rel: "collection", Method: "GET"},
+	}
+}
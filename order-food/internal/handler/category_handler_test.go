@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCategoryService is a mock implementation of CategoryServiceInterface
+type MockCategoryService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.CategoryServiceInterface = (*MockCategoryService)(nil)
+
+func (m *MockCategoryService) ListCategories(ctx context.Context) ([]models.Category, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Category), args.Error(1)
+}
+
+func (m *MockCategoryService) GetCategory(ctx context.Context, id string) (models.Category, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.Category), args.Error(1)
+}
+
+func (m *MockCategoryService) CreateCategory(ctx context.Context, req models.CategoryReq, actor string) (models.Category, error) {
+	args := m.Called(req, actor)
+	return args.Get(0).(models.Category), args.Error(1)
+}
+
+func (m *MockCategoryService) UpdateCategory(ctx context.Context, id string, req models.CategoryReq, actor string) (models.Category, error) {
+	args := m.Called(id, req, actor)
+	return args.Get(0).(models.Category), args.Error(1)
+}
+
+func (m *MockCategoryService) DeleteCategory(ctx context.Context, id, actor string) error {
+	args := m.Called(id, actor)
+	return args.Error(0)
+}
+
+func TestCategoryHandler_ListCategories(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCategoryService)
+	handler := NewCategoryHandler(mockService, nil)
+	mockService.On("ListCategories").Return([]models.Category{
+		{ID: "burger", Name: "Burger", ProductCount: 2},
+		{ID: "waffle", Name: "Waffle", ProductCount: 4},
+	}, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/categories", nil)
+
+	// Execute
+	handler.ListCategories(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"waffle","name":"Waffle","productCount":4`)
+	assert.Contains(t, w.Body.String(), `"/api/v1/categories/waffle/products"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestCategoryHandler_GetCategory(t *testing.T) {
+	tests := []struct {
+		name       string
+		category   models.Category
+		err        error
+		wantStatus int
+	}{
+		{name: "found", category: models.Category{ID: "waffle", Name: "Waffle"}, wantStatus: http.StatusOK},
+		{name: "not found", err: service.ErrCategoryNotFound, wantStatus: http.StatusNotFound},
+		{name: "database error", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockCategoryService)
+			handler := NewCategoryHandler(mockService, nil)
+			mockService.On("GetCategory", "waffle").Return(tt.category, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/categories/waffle", nil)
+			c.Params = gin.Params{{Key: "categoryId", Value: "waffle"}}
+
+			// Execute
+			handler.GetCategory(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCategoryHandler_ListCategoryProducts(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCategoryService)
+	mockProducts := new(MockProductService)
+	handler := NewCategoryHandler(mockService, mockProducts)
+	mockService.On("GetCategory", "ice-cream").Return(models.Category{ID: "ice-cream", Name: "Ice Cream"}, nil)
+	mockProducts.On("ListProductsPaginated", 10, 0, noSort, models.ProductFilter{CategoryID: "ice-cream"}).
+		Return([]models.Product{{ID: "7", Name: "Vanilla", Price: 4.5, Category: "Ice Cream"}}, 1, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/categories/ice-cream/products", nil)
+	c.Params = gin.Params{{Key: "categoryId", Value: "ice-cream"}}
+
+	// Execute
+	handler.ListCategoryProducts(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.PaginatedResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Pagination.TotalItems)
+	assert.Contains(t, w.Body.String(), `"/api/v1/categories/ice-cream/products?page=1`)
+	mockService.AssertExpectations(t)
+	mockProducts.AssertExpectations(t)
+}
+
+func TestCategoryHandler_ListCategoryProducts_NotFound(t *testing.T) {
+	// Setup: the products of an unknown category are not listed
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCategoryService)
+	mockProducts := new(MockProductService)
+	handler := NewCategoryHandler(mockService, mockProducts)
+	mockService.On("GetCategory", "pizza").Return(models.Category{}, service.ErrCategoryNotFound)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/categories/pizza/products", nil)
+	c.Params = gin.Params{{Key: "categoryId", Value: "pizza"}}
+
+	// Execute
+	handler.ListCategoryProducts(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockProducts.AssertNotCalled(t, "ListProductsPaginated", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCategoryHandler_CreateCategory(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "created", body: `{"name":"Waffle"}`, wantStatus: http.StatusCreated},
+		{name: "exists", body: `{"name":"Waffle"}`, err: service.ErrCategoryExists, wantStatus: http.StatusConflict},
+		{name: "invalid", body: `{"name":"Waffle"}`, err: service.ErrInvalidCategory, wantStatus: http.StatusUnprocessableEntity},
+		{name: "missing name", body: `{"id":"waffle"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockCategoryService)
+			handler := NewCategoryHandler(mockService, nil)
+			mockService.On("CreateCategory", models.CategoryReq{Name: "Waffle"}, "admin").
+				Return(models.Category{ID: "waffle", Name: "Waffle"}, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/categories", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			utils.SetPrincipal(c, "admin", []string{utils.ScopeAll})
+
+			// Execute
+			handler.CreateCategory(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestCategoryHandler_UpdateCategory(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCategoryService)
+	handler := NewCategoryHandler(mockService, nil)
+	mockService.On("UpdateCategory", "waffle", models.CategoryReq{Name: "Waffles"}, "admin").
+		Return(models.Category{ID: "waffle", Name: "Waffles", ProductCount: 4}, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("PUT", "/api/v1/categories/waffle", bytes.NewBufferString(`{"name":"Waffles"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "categoryId", Value: "waffle"}}
+	utils.SetPrincipal(c, "admin", []string{utils.ScopeAll})
+
+	// Execute
+	handler.UpdateCategory(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Waffles"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestCategoryHandler_DeleteCategory(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "deleted", wantStatus: http.StatusOK},
+		{name: "not found", err: service.ErrCategoryNotFound, wantStatus: http.StatusNotFound},
+		{name: "in use", err: service.ErrCategoryInUse, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockCategoryService)
+			handler := NewCategoryHandler(mockService, nil)
+			mockService.On("DeleteCategory", "waffle", "admin").Return(tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("DELETE", "/api/v1/categories/waffle", nil)
+			c.Params = gin.Params{{Key: "categoryId", Value: "waffle"}}
+			utils.SetPrincipal(c, "admin", []string{utils.ScopeAll})
+
+			// Execute
+			handler.DeleteCategory(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCategoryHandler_ServedWithNetHTTP(t *testing.T) {
+	// Setup: handlers run on net/http as they do on Gin
+	mockService := new(MockCategoryService)
+	handler := NewCategoryHandler(mockService, nil)
+	mockService.On("GetCategory", "waffle").Return(models.Category{ID: "waffle", Name: "Waffle", ProductCount: 4}, nil)
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/v1/categories/{categoryId}", httpx.Std(handler.GetCategory))
+
+	// Execute
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/categories/waffle", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"waffle","name":"Waffle","productCount":4`)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+)
+
+// ConfigHandler reports the configuration of the replica
+type ConfigHandler struct {
+	environment string
+	region      string
+	promoCodes  service.PromoCodeServiceInterface
+}
+
+// NewConfigHandler creates a new config handler for a replica deployed to
+// environment and region
+func NewConfigHandler(environment, region string, promoCodes service.PromoCodeServiceInterface) *ConfigHandler {
+	return &ConfigHandler{environment: environment, region: region, promoCodes: promoCodes}
+}
+
+// GetConfig handles GET /admin/config
+// @Summary Active deployment configuration
+// @Description The environment and region this replica is deployed to and the promo code policy it validates codes against
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.DeploymentConfig
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/config [get]
+func (h *ConfigHandler) GetConfig(c httpx.Context) {
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data: models.DeploymentConfig{
+			Environment:     h.environment,
+			Region:          h.region,
+			PromoCodePolicy: h.promoCodes.Policy(),
+		},
+		Links: []models.Link{
+			{Href: "/api/v1/admin/config", Rel: "self", Method: "GET"},
+		},
+	})
+}
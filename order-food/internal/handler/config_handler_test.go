@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigHandler_GetConfig(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	handler := NewConfigHandler("production", "eu", new(MockPromoCodeService))
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/config", nil)
+
+	// Execute
+	handler.GetConfig(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data models.DeploymentConfig `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "eu", response.Data.Region)
+	assert.Equal(t, models.PromoCodePolicy{Name: "default", MinLength: 8, MaxLength: 10, MinFiles: 2}, response.Data.PromoCodePolicy)
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/timezone"
+)
+
+const (
+	// defaultCouponAnalyticsRange is reported when no from date is given
+	defaultCouponAnalyticsRange = 30 * 24 * time.Hour
+	// defaultCouponAnalyticsTop and maxCouponAnalyticsTop bound the codes and files listed
+	defaultCouponAnalyticsTop = 20
+	maxCouponAnalyticsTop     = 100
+)
+
+// CouponAnalyticsHandler reports promo code usage to admins
+type CouponAnalyticsHandler struct {
+	service service.CouponAnalyticsServiceInterface
+	zones   *timezone.Zones
+	now     func() time.Time
+}
+
+// NewCouponAnalyticsHandler creates a new coupon analytics handler. Dates
+// are read, and daily figures bucketed, in the business time zone of the
+// requested location.
+func NewCouponAnalyticsHandler(service service.CouponAnalyticsServiceInterface, zones *timezone.Zones) *CouponAnalyticsHandler {
+	return &CouponAnalyticsHandler{service: service, zones: zones, now: time.Now}
+}
+
+// GetAnalytics handles GET /admin/coupons/analytics
+// @Summary Promo code analytics
+// @Description Redemption counts, discount given and conversion by code and by coupon file for orders placed in [from, to). Redemptions of archived orders are included; the orders themselves are not.
+// @Tags admin
+// @Produce json
+// @Param from query string false "Start of the range, RFC 3339 or YYYY-MM-DD (default: 30 days before to)"
+// @Param to query string false "End of the range, exclusive, RFC 3339 or YYYY-MM-DD (default: now)"
+// @Param location query string false "Location code whose time zone dates and days are in (default: BUSINESS_TIMEZONE)"
+// @Param top query int false "Number of codes and files to list (default 20, max 100)"
+// @Success 200 {object} models.CouponAnalytics
+// @Failure 400 {object} models.APIResponse "Invalid range"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/coupons/analytics [get]
+func (h *CouponAnalyticsHandler) GetAnalytics(c httpx.Context) {
+	zone, ok := h.zones.Lookup(c.Query("location"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, fmt.Sprintf("Unknown location %q", c.Query("location"))))
+		return
+	}
+
+	to := h.now().In(zone)
+	if value := c.Query("to"); value != "" {
+		parsed, err := timezone.ParseDate(value, zone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, fmt.Sprintf("Invalid to %q", value)))
+			return
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultCouponAnalyticsRange)
+	if value := c.Query("from"); value != "" {
+		parsed, err := timezone.ParseDate(value, zone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, fmt.Sprintf("Invalid from %q", value)))
+			return
+		}
+		from = parsed
+	}
+
+	top := defaultCouponAnalyticsTop
+	if value := c.Query("top"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxCouponAnalyticsTop {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, fmt.Sprintf("top must be between 1 and %d", maxCouponAnalyticsTop)))
+			return
+		}
+		top = parsed
+	}
+
+	analytics, err := h.service.Analytics(c.Context(), from, to, top)
+	if errors.Is(err, service.ErrInvalidDateRange) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+	if err != nil {
+		// The cancellation middleware answers requests that timed out or
+		// whose client went away
+		if c.Context().Err() != nil {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to compute coupon analytics"))
+		return
+	}
+
+	c.JSON(http.StatusOK, analytics)
+}
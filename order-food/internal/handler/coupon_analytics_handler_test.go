@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/timezone"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCouponAnalyticsService is a mock implementation of CouponAnalyticsServiceInterface
+type MockCouponAnalyticsService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.CouponAnalyticsServiceInterface = (*MockCouponAnalyticsService)(nil)
+
+func (m *MockCouponAnalyticsService) Analytics(_ context.Context, from, to time.Time, limit int) (models.CouponAnalytics, error) {
+	args := m.Called(from, to, limit)
+	return args.Get(0).(models.CouponAnalytics), args.Error(1)
+}
+
+func testZones(t *testing.T) *timezone.Zones {
+	zones, err := timezone.Parse("UTC", "store-berlin=Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zones
+}
+
+func TestCouponAnalyticsHandler_GetAnalytics(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	zones := testZones(t)
+	berlin, _ := zones.Lookup("store-berlin")
+
+	tests := []struct {
+		name     string
+		query    string
+		wantFrom time.Time
+		wantTo   time.Time
+		wantTop  int
+	}{
+		{name: "defaults to the last 30 days", wantFrom: now.Add(-30 * 24 * time.Hour), wantTo: now, wantTop: 20},
+		{
+			name:     "dates and top",
+			query:    "?from=2024-01-01&to=2024-02-01T00:00:00Z&top=5",
+			wantFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantTo:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			wantTop:  5,
+		},
+		{
+			name:     "dates in the location's time zone",
+			query:    "?location=store-berlin&from=2024-01-01&to=2024-02-01",
+			wantFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, berlin),
+			wantTo:   time.Date(2024, 2, 1, 0, 0, 0, 0, berlin),
+			wantTop:  20,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockCouponAnalyticsService)
+			handler := NewCouponAnalyticsHandler(mockService, zones)
+			handler.now = func() time.Time { return now }
+			mockService.On("Analytics", tt.wantFrom, tt.wantTo, tt.wantTop).
+				Return(models.CouponAnalytics{Orders: 10, Redemptions: 4, RedemptionRate: 0.4}, nil)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/admin/coupons/analytics"+tt.query, nil)
+
+			// Execute
+			handler.GetAnalytics(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `"redemptionRate":0.4`)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCouponAnalyticsHandler_GetAnalytics_BadRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "invalid from", query: "?from=yesterday"},
+		{name: "invalid to", query: "?to=2024-13-01"},
+		{name: "top out of range", query: "?top=1000"},
+		{name: "reversed range", query: "?from=2024-02-01&to=2024-01-01"},
+		{name: "unknown location", query: "?location=store-unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockCouponAnalyticsService)
+			mockService.On("Analytics", mock.Anything, mock.Anything, mock.Anything).
+				Return(models.CouponAnalytics{}, fmt.Errorf("%w: to must be after from", service.ErrInvalidDateRange))
+			handler := NewCouponAnalyticsHandler(mockService, testZones(t))
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/admin/coupons/analytics"+tt.query, nil)
+
+			// Execute
+			handler.GetAnalytics(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// couponFileFormField is the multipart field holding an uploaded coupon file
+const couponFileFormField = "file"
+
+// CouponFileHandler handles coupon file upload HTTP requests
+type CouponFileHandler struct {
+	service  service.CouponFileServiceInterface
+	maxBytes int64
+}
+
+// NewCouponFileHandler creates a new coupon file handler accepting files of
+// up to maxBytes
+func NewCouponFileHandler(service service.CouponFileServiceInterface, maxBytes int64) *CouponFileHandler {
+	return &CouponFileHandler{service: service, maxBytes: maxBytes}
+}
+
+// UploadCouponFile handles POST /admin/coupon-files
+// @Summary Upload a coupon file
+// @Description Store a coupon file (one code per line) and queue it for loading. Loading happens asynchronously; poll the returned upload for its status.
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Coupon file named <name>.txt"
+// @Success 202 {object} models.CouponFileUpload
+// @Failure 400 {object} models.APIResponse "Invalid upload"
+// @Failure 409 {object} models.APIResponse "A coupon file with this name exists"
+// @Failure 404 {object} models.APIResponse "Uploads are not enabled"
+// @Failure 413 {object} models.APIResponse "File too large"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/coupon-files [post]
+func (h *CouponFileHandler) UploadCouponFile(c httpx.Context) {
+	c.Request().Body = http.MaxBytesReader(c.Writer(), c.Request().Body, h.maxBytes)
+
+	// The file is streamed to disk part by part instead of being buffered,
+	// since coupon files run to hundreds of megabytes
+	reader, err := c.Request().MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Expected a multipart/form-data upload"))
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Missing form field "+couponFileFormField))
+			return
+		}
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeCouponFileError(c, err)
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Malformed multipart upload"))
+			return
+		}
+		if part.FormName() != couponFileFormField {
+			part.Close()
+			continue
+		}
+
+		upload, err := h.service.Upload(part.FileName(), part, utils.PrincipalFromContext(c), operationID(c.Context()))
+		if err != nil {
+			writeCouponFileError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusAccepted, models.HATEOASResponse{
+			Data:  upload,
+			Links: couponFileLinks(upload),
+		})
+		return
+	}
+}
+
+// GetCouponFile handles GET /admin/coupon-files/:uploadId
+// @Summary Get a coupon file upload
+// @Description Loading status of an uploaded coupon file: pending, processing, loaded or failed
+// @Tags admin
+// @Produce json
+// @Param uploadId path string true "Upload ID"
+// @Success 200 {object} models.CouponFileUpload
+// @Failure 404 {object} models.APIResponse "Upload not found"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/coupon-files/{uploadId} [get]
+func (h *CouponFileHandler) GetCouponFile(c httpx.Context) {
+	upload, err := h.service.GetUpload(c.Param("uploadId"))
+	if err != nil {
+		writeCouponFileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data:  upload,
+		Links: couponFileLinks(upload),
+	})
+}
+
+// ListCouponFiles handles GET /admin/coupon-files
+// @Summary List coupon file uploads
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number"
+// @Param perPage query int false "Items per page"
+// @Success 200 {object} models.PaginatedResponse
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/coupon-files [get]
+func (h *CouponFileHandler) ListCouponFiles(c httpx.Context) {
+	p := utils.PaginationFromContext(c)
+
+	uploads, total, err := h.service.ListUploads(p.PerPage, p.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch coupon files"))
+		return
+	}
+
+	totalPages := utils.TotalPages(total, p.PerPage)
+	response := models.PaginatedResponse{
+		Data: uploads,
+		Pagination: models.PaginationMeta{
+			Page:       p.Page,
+			PerPage:    p.PerPage,
+			TotalPages: totalPages,
+			TotalItems: total,
+		},
+		Links: utils.BuildPaginationLinks(p.Page, totalPages, "/api/v1/admin/coupon-files", p.PerPage),
+	}
+
+	utils.SetLinkHeader(c, response.Links)
+	c.JSON(http.StatusOK, response)
+}
+
+func couponFileLinks(upload models.CouponFileUpload) []models.Link {
+	links := []models.Link{
+		{Href: "/api/v1/admin/coupon-files/" + upload.ID, Rel: "self", Method: "GET"},
+		{Href: "/api/v1/admin/coupon-files", Rel: "collection", Method: "GET"},
+	}
+	if upload.OperationID != "" {
+		links = append(links, operationLink(upload.OperationID))
+	}
+	return links
+}
+
+// writeCouponFileError maps coupon file errors to HTTP responses
+func writeCouponFileError(c httpx.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, models.ErrorResponse(http.StatusRequestEntityTooLarge, "Coupon file is too large"))
+	case errors.Is(err, service.ErrInvalidCouponFileName), errors.Is(err, service.ErrEmptyCouponFile):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+	case errors.Is(err, service.ErrCouponFileExists):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "A coupon file with this name exists"))
+	case errors.Is(err, service.ErrCouponFileNotFound), errors.Is(err, service.ErrCouponUploadsDisabled):
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to process coupon file"))
+	}
+}
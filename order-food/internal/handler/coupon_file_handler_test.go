@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCouponFileService is a mock implementation of CouponFileServiceInterface
+type MockCouponFileService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.CouponFileServiceInterface = (*MockCouponFileService)(nil)
+
+func (m *MockCouponFileService) Upload(name string, content io.Reader, actor, operationID string) (models.CouponFileUpload, error) {
+	body, _ := io.ReadAll(content)
+	args := m.Called(name, string(body), actor, operationID)
+	return args.Get(0).(models.CouponFileUpload), args.Error(1)
+}
+
+func (m *MockCouponFileService) GetUpload(id string) (models.CouponFileUpload, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.CouponFileUpload), args.Error(1)
+}
+
+func (m *MockCouponFileService) ListUploads(limit, offset int) ([]models.CouponFileUpload, int, error) {
+	args := m.Called(limit, offset)
+	return args.Get(0).([]models.CouponFileUpload), args.Int(1), args.Error(2)
+}
+
+// multipartBody builds a multipart form with a single file field
+func multipartBody(field, fileName, content string) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile(field, fileName)
+	part.Write([]byte(content))
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
+
+func TestCouponFileHandler_UploadCouponFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		field      string
+		maxBytes   int64
+		upload     models.CouponFileUpload
+		err        error
+		wantCall   bool
+		wantStatus int
+	}{
+		{
+			name:       "accepted",
+			field:      "file",
+			maxBytes:   1 << 20,
+			upload:     models.CouponFileUpload{ID: "u-1", FileName: "couponbase4.txt", Status: models.CouponFileStatusPending},
+			wantCall:   true,
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "name taken",
+			field:      "file",
+			maxBytes:   1 << 20,
+			err:        service.ErrCouponFileExists,
+			wantCall:   true,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "missing file field",
+			field:      "document",
+			maxBytes:   1 << 20,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "too large",
+			field:      "file",
+			maxBytes:   16,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockCouponFileService)
+			handler := NewCouponFileHandler(mockService, tt.maxBytes)
+			if tt.wantCall {
+				mockService.On("Upload", "couponbase4.txt", "HAPPYHRS\nFIFTYOFF\n", "admin", mock.Anything).Return(tt.upload, tt.err)
+			}
+
+			// Create request
+			body, contentType := multipartBody(tt.field, "couponbase4.txt", "HAPPYHRS\nFIFTYOFF\n")
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/admin/coupon-files", body)
+			c.Request.Header.Set("Content-Type", contentType)
+			utils.SetPrincipal(c, "admin", []string{utils.ScopeAll})
+
+			// Execute
+			handler.UploadCouponFile(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCouponFileHandler_GetCouponFile_NotFound(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCouponFileService)
+	handler := NewCouponFileHandler(mockService, 1<<20)
+	mockService.On("GetUpload", "missing").Return(models.CouponFileUpload{}, service.ErrCouponFileNotFound)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/coupon-files/missing", nil)
+	c.Params = gin.Params{{Key: "uploadId", Value: "missing"}}
+
+	// Execute
+	handler.GetCouponFile(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/couponguard"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// CouponGuardStatus is the admin view of coupon brute-force protection
+type CouponGuardStatus struct {
+	Stats   couponguard.Stats   `json:"stats"`
+	Blocked []couponguard.Block `json:"blocked"`
+}
+
+// CouponGuardHandler exposes coupon brute-force protection to admins
+type CouponGuardHandler struct {
+	guard *couponguard.Guard
+}
+
+// NewCouponGuardHandler creates a new coupon guard handler. A nil guard
+// means protection is disabled.
+func NewCouponGuardHandler(guard *couponguard.Guard) *CouponGuardHandler {
+	return &CouponGuardHandler{guard: guard}
+}
+
+// GetStatus handles GET /admin/coupon-guard
+// @Summary Coupon brute-force protection status
+// @Description Counters of this instance since start-up and the clients currently blocked on any instance
+// @Tags admin
+// @Produce json
+// @Success 200 {object} CouponGuardStatus
+// @Failure 404 {object} models.APIResponse "Protection disabled"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/coupon-guard [get]
+func (h *CouponGuardHandler) GetStatus(c httpx.Context) {
+	if h.guard == nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Coupon brute-force protection is disabled"))
+		return
+	}
+
+	stats, err := h.guard.Stats(c.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to read coupon guard status"))
+		return
+	}
+	blocked, err := h.guard.Blocked(c.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to read coupon guard status"))
+		return
+	}
+
+	c.JSON(http.StatusOK, CouponGuardStatus{Stats: stats, Blocked: blocked})
+}
+
+// Unblock handles DELETE /admin/coupon-guard/blocks/:client
+// @Summary Lift a coupon validation block
+// @Tags admin
+// @Produce json
+// @Param client path string true "Client IP or partner principal"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "Client not blocked"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/coupon-guard/blocks/{client} [delete]
+func (h *CouponGuardHandler) Unblock(c httpx.Context) {
+	if h.guard == nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Client is not blocked"))
+		return
+	}
+	unblocked, err := h.guard.Unblock(c.Context(), c.Param("client"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to unblock client"))
+		return
+	}
+	if !unblocked {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Client is not blocked"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Client unblocked"))
+}
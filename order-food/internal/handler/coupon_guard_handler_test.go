@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/couponguard"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCouponGuardHandler_StatusAndUnblock(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	guard := couponguard.New(couponguard.Config{MaxFailures: 1, Window: time.Minute, Cooldown: time.Minute}, couponguard.NewMemoryStore())
+	guard.RecordFailure(context.Background(), "192.0.2.1")
+	handler := NewCouponGuardHandler(guard)
+
+	// Execute - status
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/coupon-guard", nil)
+	handler.GetStatus(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"client":"192.0.2.1"`)
+	assert.Contains(t, w.Body.String(), `"blocks":1`)
+
+	// Execute - unblock
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/api/v1/admin/coupon-guard/blocks/192.0.2.1", nil)
+	c.Params = gin.Params{{Key: "client", Value: "192.0.2.1"}}
+	handler.Unblock(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	_, blocked := guard.Check(context.Background(), "192.0.2.1")
+	assert.False(t, blocked)
+}
+
+func TestCouponGuardHandler_Disabled(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	handler := NewCouponGuardHandler(nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/coupon-guard", nil)
+
+	// Execute
+	handler.GetStatus(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// CustomerHandler handles customer account HTTP requests
+type CustomerHandler struct {
+	service service.CustomerServiceInterface
+}
+
+// NewCustomerHandler creates a new customer handler
+func NewCustomerHandler(service service.CustomerServiceInterface) *CustomerHandler {
+	return &CustomerHandler{service: service}
+}
+
+// customerLinks are the links returned with a customer account
+var customerLinks = []models.Link{
+	{Href: "/api/v1/customers/me", Rel: "self", Method: "GET"},
+	{Href: "/api/v1/customers/me/orders", Rel: "orders", Method: "GET"},
+}
+
+// Register handles POST /customers
+// @Summary Register a customer account
+// @Description Create a customer account. Log in to get an access token for placing orders and listing them.
+// @Tags customer
+// @Accept json
+// @Produce json
+// @Param customer body models.CustomerReq true "Customer registration"
+// @Success 201 {object} models.Customer
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 409 {object} models.APIResponse "Email already registered"
+// @Router /api/v1/customers [post]
+func (h *CustomerHandler) Register(c httpx.Context) {
+	var req models.CustomerReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	customer, err := h.service.Register(req)
+	if errors.Is(err, service.ErrEmailTaken) {
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to register customer"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.HATEOASResponse{
+		Data: customer,
+		Links: []models.Link{
+			{Href: "/api/v1/customers/login", Rel: "login", Method: "POST"},
+		},
+	})
+}
+
+// Login handles POST /customers/login
+// @Summary Log in as a customer
+// @Description Exchange an email and password for an access token, sent as "Authorization: Bearer <token>" until it expires
+// @Tags customer
+// @Accept json
+// @Produce json
+// @Param credentials body models.LoginReq true "Customer credentials"
+// @Success 200 {object} models.AccessToken
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 401 {object} models.APIResponse "Invalid email or password"
+// @Router /api/v1/customers/login [post]
+func (h *CustomerHandler) Login(c httpx.Context) {
+	var req models.LoginReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	token, err := h.service.Login(req)
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		c.JSON(http.StatusUnauthorized, models.ErrorResponse(http.StatusUnauthorized, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to log in"))
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, models.HATEOASResponse{Data: token, Links: customerLinks})
+}
+
+// GetMe handles GET /customers/me for the customer of the access token
+// @Summary Get own customer account
+// @Tags customer
+// @Produce json
+// @Success 200 {object} models.Customer
+// @Failure 401 {object} models.APIResponse "Unauthorized"
+// @Failure 403 {object} models.APIResponse "Caller is not a customer"
+// @Security BearerAuth
+// @Router /api/v1/customers/me [get]
+func (h *CustomerHandler) GetMe(c httpx.Context) {
+	customerID := utils.CustomerFromContext(c)
+	if customerID == "" {
+		c.JSON(http.StatusForbidden, models.ErrorResponse(http.StatusForbidden, "Forbidden: only customers have an account"))
+		return
+	}
+
+	customer, err := h.service.GetCustomer(customerID)
+	if errors.Is(err, service.ErrCustomerNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Customer not found"))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch customer"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{Data: customer, Links: customerLinks})
+}
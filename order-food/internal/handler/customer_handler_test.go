@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCustomerService is a mock implementation of CustomerServiceInterface
+type MockCustomerService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.CustomerServiceInterface = (*MockCustomerService)(nil)
+
+func (m *MockCustomerService) Register(req models.CustomerReq) (models.Customer, error) {
+	args := m.Called(req)
+	return args.Get(0).(models.Customer), args.Error(1)
+}
+
+func (m *MockCustomerService) Login(req models.LoginReq) (models.AccessToken, error) {
+	args := m.Called(req)
+	return args.Get(0).(models.AccessToken), args.Error(1)
+}
+
+func (m *MockCustomerService) GetCustomer(id string) (models.Customer, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.Customer), args.Error(1)
+}
+
+func TestCustomerHandler_Register(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        models.CustomerReq
+		err        error
+		wantStatus int
+	}{
+		{name: "registered", req: models.CustomerReq{Email: "ada@example.com", Password: "correct horse"}, wantStatus: http.StatusCreated},
+		{name: "email taken", req: models.CustomerReq{Email: "ada@example.com", Password: "correct horse"}, err: service.ErrEmailTaken, wantStatus: http.StatusConflict},
+		{name: "invalid email", req: models.CustomerReq{Email: "ada", Password: "correct horse"}, wantStatus: http.StatusBadRequest},
+		{name: "short password", req: models.CustomerReq{Email: "ada@example.com", Password: "short"}, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockCustomerService)
+			handler := NewCustomerHandler(mockService)
+			mockService.On("Register", tt.req).Return(models.Customer{ID: "c1", Email: tt.req.Email}, tt.err).Maybe()
+
+			// Create request
+			body, _ := json.Marshal(tt.req)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/customers", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			// Execute
+			handler.Register(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.NotContains(t, w.Body.String(), tt.req.Password)
+		})
+	}
+}
+
+func TestCustomerHandler_Login(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "valid credentials", wantStatus: http.StatusOK},
+		{name: "invalid credentials", err: service.ErrInvalidCredentials, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockCustomerService)
+			handler := NewCustomerHandler(mockService)
+			req := models.LoginReq{Email: "ada@example.com", Password: "correct horse"}
+			token := models.AccessToken{Token: "signed.jwt.token", TokenType: "Bearer", ExpiresAt: time.Now().Add(time.Hour)}
+			mockService.On("Login", req).Return(token, tt.err)
+
+			// Create request
+			body, _ := json.Marshal(req)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/customers/login", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			// Execute
+			handler.Login(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.err == nil {
+				assert.Contains(t, w.Body.String(), `"accessToken":"signed.jwt.token"`)
+				assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCustomerHandler_GetMe(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCustomerService)
+	handler := NewCustomerHandler(mockService)
+	mockService.On("GetCustomer", "c1").Return(models.Customer{ID: "c1", Email: "ada@example.com"}, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/customers/me", nil)
+	utils.SetPrincipal(c, utils.CustomerPrincipal("c1"), service.CustomerScopes)
+
+	// Execute
+	handler.GetMe(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "ada@example.com")
+	mockService.AssertExpectations(t)
+}
+
+func TestCustomerHandler_GetMe_APIKey(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockCustomerService)
+	handler := NewCustomerHandler(mockService)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/customers/me", nil)
+	utils.SetPrincipal(c, "apikey", []string{utils.ScopeAll})
+
+	// Execute
+	handler.GetMe(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertNotCalled(t, "GetCustomer", mock.Anything)
+}
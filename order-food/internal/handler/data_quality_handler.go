@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+)
+
+// DataQualityHandler handles data quality HTTP requests
+type DataQualityHandler struct {
+	service service.DataQualityServiceInterface
+}
+
+// NewDataQualityHandler creates a new data quality handler
+func NewDataQualityHandler(service service.DataQualityServiceInterface) *DataQualityHandler {
+	return &DataQualityHandler{service: service}
+}
+
+// GetDataQuality handles GET /admin/data-quality
+// @Summary Data quality findings
+// @Description The latest result of each data quality check: orphaned order items, products priced at zero, coupon codes in only one file and coupon files whose coupons are missing. Checks run as the data-quality-checker scheduled task; follow the run link to run them now. Status is issues when a check found rows or failed, and unchecked while a check has not run yet.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.DataQualityReport
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/data-quality [get]
+func (h *DataQualityHandler) GetDataQuality(c httpx.Context) {
+	report, err := h.service.Report(c.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch data quality findings"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data: report,
+		Links: []models.Link{
+			{Href: "/api/v1/admin/data-quality", Rel: "self", Method: "GET"},
+			{Href: "/api/v1/admin/tasks/" + service.DataQualityTask + "/run", Rel: "run", Method: "POST"},
+		},
+	})
+}
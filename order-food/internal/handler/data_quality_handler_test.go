@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockDataQualityService is a mock implementation of DataQualityServiceInterface
+type MockDataQualityService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.DataQualityServiceInterface = (*MockDataQualityService)(nil)
+
+func (m *MockDataQualityService) Report(ctx context.Context) (models.DataQualityReport, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(models.DataQualityReport), args.Error(1)
+}
+
+func TestDataQualityHandler_GetDataQuality(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "report", wantStatus: http.StatusOK},
+		{name: "database error", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockDataQualityService)
+			handler := NewDataQualityHandler(mockService)
+			mockService.On("Report", mock.Anything).Return(models.DataQualityReport{
+				Status: models.DataQualityIssues,
+				Checks: []models.DataQualityFinding{{Check: models.DataQualityZeroPriceProducts, Count: 2, Samples: []string{"11", "12"}}},
+			}, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/admin/data-quality", nil)
+
+			// Execute
+			handler.GetDataQuality(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"status":"issues"`)
+				assert.Contains(t, w.Body.String(), `"href":"/api/v1/admin/tasks/data-quality-checker/run"`)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -3,27 +3,74 @@ package handler
 import (
 	"net/http"
 
-	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/health"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/warmup"
 )
 
 // HealthHandler handles health check endpoints
-type HealthHandler struct{}
+type HealthHandler struct {
+	checker *health.Checker
+	warmer  *warmup.Warmer
+}
 
-// NewHealthHandler creates a new health handler
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+// NewHealthHandler creates a new health handler. checker supplies the
+// dependency checks of the verbose health report and may be nil. The
+// replica reports ready once warmer has finished; a nil warmer makes it
+// ready straight away.
+func NewHealthHandler(checker *health.Checker, warmer *warmup.Warmer) *HealthHandler {
+	if checker == nil {
+		checker = health.NewChecker(health.DefaultTimeout)
+	}
+	return &HealthHandler{checker: checker, warmer: warmer}
 }
 
 // Health handles GET /health
-func (h *HealthHandler) Health(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status": "healthy",
-	})
+// @Summary Health check
+// @Description Without parameters the check is cheap and does not touch dependencies. With verbose=true every dependency is probed and reported with its latency; the response is 503 when a critical component fails.
+// @Tags health
+// @Produce json
+// @Param verbose query bool false "Probe dependencies and report component statuses"
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report "A critical component is failing"
+// @Router /health [get]
+func (h *HealthHandler) Health(c httpx.Context) {
+	if c.Query("verbose") != "true" {
+		c.JSON(http.StatusOK, map[string]any{
+			"status": "healthy",
+		})
+		return
+	}
+
+	report := h.checker.Run(c.Context())
+	code := http.StatusOK
+	if report.Status == health.StatusFail {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, report)
 }
 
-// Ready handles GET /ready
-func (h *HealthHandler) Ready(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
+// Ready handles GET /ready. It answers 503 with the progress of each step
+// until the warm-up has finished, so load balancers hold traffic back from
+// a replica whose caches are still cold.
+func (h *HealthHandler) Ready(c httpx.Context) {
+	if h.warmer != nil && !h.warmer.Ready() {
+		c.JSON(http.StatusServiceUnavailable, map[string]any{
+			"status": "warming up",
+			"warmup": h.warmer.Status(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, map[string]any{
 		"status": "ready",
 	})
 }
+
+// Live handles GET /livez. It only shows that the process is serving
+// requests and never touches dependencies, so a database outage does not
+// get the pod restarted.
+func (h *HealthHandler) Live(c httpx.Context) {
+	c.JSON(http.StatusOK, map[string]any{
+		"status": "alive",
+	})
+}
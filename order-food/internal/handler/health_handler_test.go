@@ -1,19 +1,25 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/health"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/warmup"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestHealthHandler_Health(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
-	handler := NewHealthHandler()
+	handler := NewHealthHandler(nil, nil)
 
 	// Create request
 	w := httptest.NewRecorder()
@@ -21,7 +27,7 @@ func TestHealthHandler_Health(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/health", nil)
 
 	// Execute
-	handler.Health(c)
+	handler.Health(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -35,7 +41,7 @@ func TestHealthHandler_Health(t *testing.T) {
 func TestHealthHandler_Ready(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
-	handler := NewHealthHandler()
+	handler := NewHealthHandler(nil, nil)
 
 	// Create request
 	w := httptest.NewRecorder()
@@ -43,7 +49,7 @@ func TestHealthHandler_Ready(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/ready", nil)
 
 	// Execute
-	handler.Ready(c)
+	handler.Ready(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -57,7 +63,7 @@ func TestHealthHandler_Ready(t *testing.T) {
 func TestHealthHandler_Health_ResponseFormat(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
-	handler := NewHealthHandler()
+	handler := NewHealthHandler(nil, nil)
 
 	// Create request
 	w := httptest.NewRecorder()
@@ -65,7 +71,7 @@ func TestHealthHandler_Health_ResponseFormat(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/health", nil)
 
 	// Execute
-	handler.Health(c)
+	handler.Health(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
@@ -76,7 +82,7 @@ func TestHealthHandler_Health_ResponseFormat(t *testing.T) {
 func TestHealthHandler_Ready_ResponseFormat(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
-	handler := NewHealthHandler()
+	handler := NewHealthHandler(nil, nil)
 
 	// Create request
 	w := httptest.NewRecorder()
@@ -84,10 +90,107 @@ func TestHealthHandler_Ready_ResponseFormat(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/ready", nil)
 
 	// Execute
-	handler.Ready(c)
+	handler.Ready(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
 	assert.Contains(t, w.Body.String(), "status")
 	assert.Contains(t, w.Body.String(), "ready")
 }
+
+func TestHealthHandler_Ready_WarmingUp(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	release := make(chan struct{})
+	warmer := warmup.New(time.Minute, warmup.Step{Name: "productCache", Run: func(ctx context.Context) error {
+		<-release
+		return nil
+	}})
+	done := make(chan struct{})
+	go func() {
+		warmer.Run(context.Background())
+		close(done)
+	}()
+	handler := NewHealthHandler(nil, warmer)
+
+	// Execute
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/ready", nil)
+	handler.Ready(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"warming up"`)
+	assert.Contains(t, w.Body.String(), `"name":"productCache","status":"pending"`)
+
+	close(release)
+	<-done
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/ready", nil)
+	handler.Ready(ginx.New(c))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHealthHandler_Health_Verbose(t *testing.T) {
+	tests := []struct {
+		name       string
+		dbErr      error
+		wantCode   int
+		wantStatus string
+	}{
+		{name: "healthy", wantCode: http.StatusOK, wantStatus: health.StatusPass},
+		{name: "database down", dbErr: errors.New("connection refused"), wantCode: http.StatusServiceUnavailable, wantStatus: health.StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			checker := health.NewChecker(time.Second)
+			checker.Register("database", true, func(ctx context.Context) error { return tt.dbErr })
+			handler := NewHealthHandler(checker, nil)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/health?verbose=true", nil)
+
+			// Execute
+			handler.Health(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantCode, w.Code)
+
+			var report health.Report
+			err := json.Unmarshal(w.Body.Bytes(), &report)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, report.Status)
+			assert.Equal(t, tt.wantStatus, report.Components["database"].Status)
+		})
+	}
+}
+
+func TestHealthHandler_Live(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	checker := health.NewChecker(time.Second)
+	checker.Register("database", true, func(ctx context.Context) error {
+		t.Error("liveness probe must not check dependencies")
+		return nil
+	})
+	handler := NewHealthHandler(checker, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/livez", nil)
+
+	// Execute
+	handler.Live(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "alive")
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// MaintenanceHandler handles HTTP requests starting and following
+// maintenance jobs
+type MaintenanceHandler struct {
+	service service.MaintenanceServiceInterface
+}
+
+// NewMaintenanceHandler creates a new maintenance handler
+func NewMaintenanceHandler(service service.MaintenanceServiceInterface) *MaintenanceHandler {
+	return &MaintenanceHandler{service: service}
+}
+
+// Reindex handles POST /admin/maintenance/reindex
+// @Summary Rebuild the product search index and caches
+// @Description Rebuild the product search index, refresh the materialized views and re-warm the caches, as after a large database-load run. The job runs in the background on the replica serving the request; follow the self link to watch its progress.
+// @Tags admin
+// @Produce json
+// @Success 202 {object} models.MaintenanceJob
+// @Failure 409 {object} models.APIResponse "A reindex is already running"
+// @Failure 503 {object} models.APIResponse "The replica is shutting down"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/maintenance/reindex [post]
+func (h *MaintenanceHandler) Reindex(c httpx.Context) {
+	job, err := h.service.StartReindex(utils.PrincipalFromContext(c), operationID(c.Context()))
+	if err != nil {
+		writeMaintenanceError(c, err)
+		return
+	}
+
+	links := maintenanceJobLinks(job)
+	c.Header("Location", links[0].Href)
+	c.JSON(http.StatusAccepted, models.HATEOASResponse{Data: job, Links: links})
+}
+
+// GetJob handles GET /admin/maintenance/jobs/:jobId
+// @Summary Follow a maintenance job
+// @Description Status of a maintenance job and of each of its steps: pending, running, done or failed. Any replica can answer.
+// @Tags admin
+// @Produce json
+// @Param jobId path string true "Job ID"
+// @Success 200 {object} models.MaintenanceJob
+// @Failure 404 {object} models.APIResponse "Job not found"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/maintenance/jobs/{jobId} [get]
+func (h *MaintenanceHandler) GetJob(c httpx.Context) {
+	job, err := h.service.GetJob(c.Param("jobId"))
+	if err != nil {
+		writeMaintenanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{Data: job, Links: maintenanceJobLinks(job)})
+}
+
+// maintenanceJobLinks generates HATEOAS links for a maintenance job; the
+// first is its self link
+func maintenanceJobLinks(job models.MaintenanceJob) []models.Link {
+	links := []models.Link{{Href: "/api/v1/admin/maintenance/jobs/" + job.ID, Rel: "self", Method: "GET"}}
+	if job.OperationID != "" {
+		links = append(links, operationLink(job.OperationID))
+	}
+	return links
+}
+
+// writeMaintenanceError maps maintenance errors to HTTP responses
+func writeMaintenanceError(c httpx.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrMaintenanceJobNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Maintenance job not found"))
+	case errors.Is(err, service.ErrMaintenanceJobRunning):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "A maintenance job of this kind is already running"))
+	case errors.Is(err, service.ErrMaintenanceUnavailable):
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse(http.StatusServiceUnavailable, "Maintenance jobs are not being run on this replica"))
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to process maintenance job"))
+	}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMaintenanceService is a mock implementation of MaintenanceServiceInterface
+type MockMaintenanceService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.MaintenanceServiceInterface = (*MockMaintenanceService)(nil)
+
+func (m *MockMaintenanceService) StartReindex(actor, operationID string) (models.MaintenanceJob, error) {
+	args := m.Called(actor, operationID)
+	return args.Get(0).(models.MaintenanceJob), args.Error(1)
+}
+
+func (m *MockMaintenanceService) GetJob(id string) (models.MaintenanceJob, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.MaintenanceJob), args.Error(1)
+}
+
+func TestMaintenanceHandler_Reindex(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "started", wantStatus: http.StatusAccepted},
+		{name: "already running", err: service.ErrMaintenanceJobRunning, wantStatus: http.StatusConflict},
+		{name: "shutting down", err: service.ErrMaintenanceUnavailable, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockMaintenanceService)
+			handler := NewMaintenanceHandler(mockService)
+			job := models.MaintenanceJob{ID: "5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c", Kind: models.MaintenanceJobReindex, Status: models.MaintenanceJobRunning}
+			mockService.On("StartReindex", "", mock.AnythingOfType("string")).Return(job, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/admin/maintenance/reindex", nil)
+
+			// Execute
+			handler.Reindex(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.err == nil {
+				assert.Equal(t, "/api/v1/admin/maintenance/jobs/"+job.ID, w.Header().Get("Location"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMaintenanceHandler_GetJob(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockMaintenanceService)
+	handler := NewMaintenanceHandler(mockService)
+	job := models.MaintenanceJob{
+		ID:          "5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c",
+		Kind:        models.MaintenanceJobReindex,
+		Status:      models.MaintenanceJobSucceeded,
+		Progress:    100,
+		OperationID: testOperationID,
+	}
+	mockService.On("GetJob", job.ID).Return(job, nil)
+	mockService.On("GetJob", "missing").Return(models.MaintenanceJob{}, service.ErrMaintenanceJobNotFound)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/maintenance/jobs/"+job.ID, nil)
+	c.Params = gin.Params{{Key: "jobId", Value: job.ID}}
+
+	// Execute
+	handler.GetJob(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data  models.MaintenanceJob `json:"data"`
+		Links []models.Link         `json:"_links"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 100, response.Data.Progress)
+	assert.Contains(t, response.Links, operationLink(testOperationID))
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/maintenance/jobs/missing", nil)
+	c.Params = gin.Params{{Key: "jobId", Value: "missing"}}
+	handler.GetJob(ginx.New(c))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
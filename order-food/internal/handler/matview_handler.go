@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/scheduler"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+)
+
+// MatviewHandler handles materialized view HTTP requests
+type MatviewHandler struct {
+	views map[string]service.MatviewServiceInterface
+	tasks service.ScheduledTaskServiceInterface
+}
+
+// NewMatviewHandler creates a new materialized view handler. Views are
+// refreshed by running their scheduled task on tasks.
+func NewMatviewHandler(views []service.MatviewServiceInterface, tasks service.ScheduledTaskServiceInterface) *MatviewHandler {
+	byName := make(map[string]service.MatviewServiceInterface, len(views))
+	for _, view := range views {
+		byName[view.View()] = view
+	}
+	return &MatviewHandler{views: byName, tasks: tasks}
+}
+
+// GetMatview handles GET /admin/matviews/:view
+// @Summary Get materialized view freshness
+// @Description How stale the view is and how far a refresh under way anywhere in the cluster has got. Progress is estimated from how long the previous refresh took.
+// @Tags admin
+// @Produce json
+// @Param view path string true "View name" example(valid_coupons)
+// @Success 200 {object} models.MatviewStatus
+// @Failure 404 {object} models.APIResponse "Unknown view"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/matviews/{view} [get]
+func (h *MatviewHandler) GetMatview(c httpx.Context) {
+	status, ok := h.status(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, models.HATEOASResponse{Data: status, Links: matviewLinks(status.View)})
+}
+
+// RefreshMatview handles POST /admin/matviews/:view/refresh
+// @Summary Refresh a materialized view now
+// @Description Queue a refresh of the view on the replica serving the request. Follow the self link to watch its progress.
+// @Tags admin
+// @Produce json
+// @Param view path string true "View name" example(valid_coupons)
+// @Success 202 {object} models.MatviewStatus
+// @Failure 404 {object} models.APIResponse "Unknown view"
+// @Failure 409 {object} models.APIResponse "View is already being refreshed"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/matviews/{view}/refresh [post]
+func (h *MatviewHandler) RefreshMatview(c httpx.Context) {
+	status, ok := h.status(c)
+	if !ok {
+		return
+	}
+	if status.Refreshing {
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "View is already being refreshed"))
+		return
+	}
+
+	err := h.tasks.Trigger(status.Task)
+	switch {
+	case errors.Is(err, scheduler.ErrTaskRunning):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "View is already being refreshed"))
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to refresh view"))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.HATEOASResponse{Data: status, Links: matviewLinks(status.View)})
+}
+
+// status writes an error response and returns false when the view in the
+// path is unknown or its status cannot be read
+func (h *MatviewHandler) status(c httpx.Context) (models.MatviewStatus, bool) {
+	view, ok := h.views[c.Param("view")]
+	if !ok {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Unknown materialized view"))
+		return models.MatviewStatus{}, false
+	}
+	status, err := view.Status(c.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch view status"))
+		return models.MatviewStatus{}, false
+	}
+	return status, true
+}
+
+// matviewLinks generates HATEOAS links for a materialized view
+func matviewLinks(view string) []models.Link {
+	return []models.Link{
+		{Href: "/api/v1/admin/matviews/" + view, Rel: "self", Method: "GET"},
+		{Href: "/api/v1/admin/matviews/" + view + "/refresh", Rel: "refresh", Method: "POST"},
+	}
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/scheduler"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMatviewService is a mock implementation of MatviewServiceInterface
+type MockMatviewService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.MatviewServiceInterface = (*MockMatviewService)(nil)
+
+func (m *MockMatviewService) View() string {
+	return "valid_coupons"
+}
+
+func (m *MockMatviewService) Status(ctx context.Context) (models.MatviewStatus, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(models.MatviewStatus), args.Error(1)
+}
+
+func TestMatviewHandler_GetMatview(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockView := new(MockMatviewService)
+	handler := NewMatviewHandler([]service.MatviewServiceInterface{mockView}, new(MockScheduledTaskService))
+	progress := 40
+	mockView.On("Status", mock.Anything).Return(models.MatviewStatus{
+		View: "valid_coupons", Task: "valid-coupons-refresher", Refreshing: true, Progress: &progress,
+	}, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/matviews/valid_coupons", nil)
+	c.Params = gin.Params{{Key: "view", Value: "valid_coupons"}}
+
+	// Execute
+	handler.GetMatview(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"progress":40`)
+	assert.Contains(t, w.Body.String(), `"href":"/api/v1/admin/matviews/valid_coupons/refresh"`)
+	mockView.AssertExpectations(t)
+}
+
+func TestMatviewHandler_RefreshMatview(t *testing.T) {
+	tests := []struct {
+		name        string
+		view        string
+		refreshing  bool
+		triggerErr  error
+		wantTrigger bool
+		wantStatus  int
+	}{
+		{name: "queued", view: "valid_coupons", wantTrigger: true, wantStatus: http.StatusAccepted},
+		{name: "unknown view", view: "coupons", wantStatus: http.StatusNotFound},
+		{name: "refreshing elsewhere", view: "valid_coupons", refreshing: true, wantStatus: http.StatusConflict},
+		{name: "refreshing here", view: "valid_coupons", triggerErr: scheduler.ErrTaskRunning, wantTrigger: true, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockView := new(MockMatviewService)
+			mockTasks := new(MockScheduledTaskService)
+			handler := NewMatviewHandler([]service.MatviewServiceInterface{mockView}, mockTasks)
+			mockView.On("Status", mock.Anything).
+				Return(models.MatviewStatus{View: "valid_coupons", Task: "valid-coupons-refresher", Refreshing: tt.refreshing}, nil).Maybe()
+			if tt.wantTrigger {
+				mockTasks.On("Trigger", "valid-coupons-refresher").Return(tt.triggerErr)
+			}
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/admin/matviews/"+tt.view+"/refresh", nil)
+			c.Params = gin.Params{{Key: "view", Value: tt.view}}
+
+			// Execute
+			handler.RefreshMatview(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockTasks.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// ndjsonContentType is the media type of listings streamed one JSON record
+// per line
+const ndjsonContentType = "application/x-ndjson"
+
+// wantsNDJSON reports whether the Accept header prefers a streamed listing
+// to a page
+func wantsNDJSON(c httpx.Context) bool {
+	return httpx.NegotiateFormat(c.GetHeader("Accept"), "application/json", ndjsonContentType) == ndjsonContentType
+}
+
+// streamNDJSON answers with every record stream passes to emit, one JSON
+// document per line. The status is sent with the first record, so a
+// listing failing before it is still answered with a 500 and message.
+// Later failures can only be reported in the stream, which then ends with
+// an error object instead of a record.
+func streamNDJSON(c httpx.Context, message string, stream func(emit func(record any) error) error) {
+	encoder := json.NewEncoder(c.Writer())
+	started := false
+	start := func() {
+		c.Header("Content-Type", ndjsonContentType)
+		c.Writer().WriteHeader(http.StatusOK)
+		started = true
+	}
+
+	err := stream(func(record any) error {
+		if !started {
+			start()
+		}
+		return encoder.Encode(record)
+	})
+	switch {
+	case err == nil && !started:
+		start()
+	case err == nil:
+	case c.Context().Err() != nil:
+		// The cancellation middleware answers requests that timed out or
+		// whose client went away
+	case !started:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, message))
+	default:
+		slog.ErrorContext(c.Context(), "Error streaming listing", "path", c.Request().URL.Path, "error", err)
+		_ = encoder.Encode(models.ErrorResponse(http.StatusInternalServerError, message))
+	}
+}
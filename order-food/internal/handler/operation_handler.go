@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/operation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// pipelineRunStatuses lists the values accepted by the status filter on pipeline run listings
+var pipelineRunStatuses = []string{
+	models.PipelineRunStatusRunning,
+	models.PipelineRunStatusSucceeded,
+	models.PipelineRunStatusFailed,
+}
+
+// OperationHandler handles HTTP requests following pipeline operations
+type OperationHandler struct {
+	service service.OperationServiceInterface
+}
+
+// NewOperationHandler creates a new operation handler
+func NewOperationHandler(service service.OperationServiceInterface) *OperationHandler {
+	return &OperationHandler{service: service}
+}
+
+// ListPipelineRuns handles GET /admin/pipeline-runs with an optional status filter
+// @Summary List pipeline runs
+// @Description Runs of the database-migration and database-load jobs, newest first. Follow the operation link of a failed run to see the rest of its pipeline.
+// @Tags admin
+// @Produce json
+// @Param status query string false "Filter by status: running, succeeded or failed"
+// @Param page query int false "Page number"
+// @Param perPage query int false "Items per page"
+// @Success 200 {object} models.PaginatedResponse
+// @Failure 400 {object} models.APIResponse "Invalid status"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/pipeline-runs [get]
+func (h *OperationHandler) ListPipelineRuns(c httpx.Context) {
+	p := utils.PaginationFromContext(c)
+
+	status := c.Query("status")
+	if status != "" && !containsString(pipelineRunStatuses, status) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, fmt.Sprintf("Invalid status %q", status)))
+		return
+	}
+
+	runs, total, err := h.service.ListRuns(status, p.PerPage, p.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch pipeline runs"))
+		return
+	}
+
+	items := make([]models.HATEOASResponse, len(runs))
+	for i, run := range runs {
+		items[i] = models.HATEOASResponse{
+			Data:  run,
+			Links: []models.Link{operationLink(run.OperationID)},
+		}
+	}
+
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	totalPages := utils.TotalPages(total, p.PerPage)
+
+	response := models.PaginatedResponse{
+		Data: items,
+		Pagination: models.PaginationMeta{
+			Page:       p.Page,
+			PerPage:    p.PerPage,
+			TotalPages: totalPages,
+			TotalItems: total,
+		},
+		Links: utils.BuildPaginationLinksWithQuery(p.Page, totalPages, "/api/v1/admin/pipeline-runs", p.PerPage, query),
+	}
+
+	utils.SetLinkHeader(c, response.Links)
+	c.JSON(http.StatusOK, response)
+}
+
+// GetOperation handles GET /admin/operations/:operationId
+// @Summary Follow an operation
+// @Description Every pipeline run and coupon file upload recorded under an operation ID. The ID is also the trace ID of the operation.
+// @Tags admin
+// @Produce json
+// @Param operationId path string true "Operation ID (32 hex characters)"
+// @Success 200 {object} models.Operation
+// @Failure 404 {object} models.APIResponse "Operation not found"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/operations/{operationId} [get]
+func (h *OperationHandler) GetOperation(c httpx.Context) {
+	op, err := h.service.GetOperation(c.Param("operationId"))
+	if errors.Is(err, service.ErrOperationNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Operation not found"))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch operation"))
+		return
+	}
+
+	links := []models.Link{
+		operationLink(op.ID),
+		{Href: "/api/v1/admin/pipeline-runs", Rel: "collection", Method: "GET"},
+	}
+	for _, upload := range op.CouponFiles {
+		links = append(links, models.Link{Href: "/api/v1/admin/coupon-files/" + upload.ID, Rel: "coupon-file", Method: "GET"})
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{Data: op, Links: links})
+}
+
+func operationLink(id string) models.Link {
+	return models.Link{Href: "/api/v1/admin/operations/" + id, Rel: "operation", Method: "GET"}
+}
+
+// operationID returns the operation a request belongs to: its trace ID
+// when it is traced, otherwise a new operation ID
+func operationID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return operation.New().ID
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOperationService is a mock implementation of OperationServiceInterface
+type MockOperationService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.OperationServiceInterface = (*MockOperationService)(nil)
+
+func (m *MockOperationService) ListRuns(status string, limit, offset int) ([]models.PipelineRun, int, error) {
+	args := m.Called(status, limit, offset)
+	return args.Get(0).([]models.PipelineRun), args.Int(1), args.Error(2)
+}
+
+func (m *MockOperationService) GetOperation(id string) (models.Operation, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.Operation), args.Error(1)
+}
+
+const testOperationID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+func TestOperationHandler_ListPipelineRuns(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockOperationService)
+	handler := NewOperationHandler(mockService)
+
+	runs := []models.PipelineRun{{
+		ID:          7,
+		OperationID: testOperationID,
+		Job:         "database-load",
+		Status:      models.PipelineRunStatusFailed,
+		Error:       "failed to load coupons",
+		StartedAt:   time.Now(),
+	}}
+	mockService.On("ListRuns", models.PipelineRunStatusFailed, 10, 0).Return(runs, 1, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/pipeline-runs?status=failed", nil)
+
+	// Execute
+	handler.ListPipelineRuns(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"href":"/api/v1/admin/operations/`+testOperationID+`"`)
+	assert.Contains(t, w.Body.String(), "status=failed")
+	mockService.AssertExpectations(t)
+}
+
+func TestOperationHandler_ListPipelineRuns_InvalidStatus(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	handler := NewOperationHandler(new(MockOperationService))
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/pipeline-runs?status=stuck", nil)
+
+	// Execute
+	handler.ListPipelineRuns(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestOperationHandler_GetOperation(t *testing.T) {
+	tests := []struct {
+		name       string
+		op         models.Operation
+		err        error
+		wantStatus int
+	}{
+		{
+			name: "found",
+			op: models.Operation{
+				ID:          testOperationID,
+				Runs:        []models.PipelineRun{{ID: 7, OperationID: testOperationID, Job: "database-load process-uploads"}},
+				CouponFiles: []models.CouponFileUpload{{ID: "u-1", OperationID: testOperationID}},
+			},
+			wantStatus: http.StatusOK,
+		},
+		{name: "not found", err: service.ErrOperationNotFound, wantStatus: http.StatusNotFound},
+		{name: "database error", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockOperationService)
+			handler := NewOperationHandler(mockService)
+			mockService.On("GetOperation", testOperationID).Return(tt.op, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/admin/operations/"+testOperationID, nil)
+			c.Params = gin.Params{{Key: "operationId", Value: testOperationID}}
+
+			// Execute
+			handler.GetOperation(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var resp struct {
+					Data  models.Operation `json:"data"`
+					Links []models.Link    `json:"_links"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Len(t, resp.Data.Runs, 1)
+				assert.Contains(t, resp.Links, models.Link{Href: "/api/v1/admin/coupon-files/u-1", Rel: "coupon-file", Method: "GET"})
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -1,26 +1,70 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/couponguard"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/payment"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/pos"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/timezone"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/tracing"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/promocode"
 )
 
+// maxPOSPayloadBytes caps the size of a legacy POS ticket upload
+const maxPOSPayloadBytes = 1 << 20
+
+// maxInlineOrderItems is the most items an order response lists inline;
+// larger orders, such as catering orders, link to their paginated items
+const maxInlineOrderItems = 50
+
+// orderSortFields lists the fields accepted by the sort query parameter on order listings
+var orderSortFields = []string{"id", "createdAt", "couponCode", "total", "status"}
+
+// orderStatuses lists the values accepted by the status filter on order listings
+var orderStatuses = []string{
+	string(models.OrderStatusPending),
+	string(models.OrderStatusConfirmed),
+	string(models.OrderStatusPreparing),
+	string(models.OrderStatusCompleted),
+	string(models.OrderStatusCancelled),
+}
+
+// maxCouponCodeFilterLength is the width of orders.coupon_code
+const maxCouponCodeFilterLength = 50
+
+// productIDFilterPattern matches the product IDs the productId filter
+// accepts, the IDs products can have
+var productIDFilterPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,50}$`)
+
 // OrderHandler handles order-related HTTP requests
 type OrderHandler struct {
 	service          service.OrderServiceInterface
 	promoCodeService service.PromoCodeServiceInterface
+	couponGuard      *couponguard.Guard
 }
 
-// NewOrderHandler creates a new order handler
-func NewOrderHandler(service service.OrderServiceInterface, promoCodeService service.PromoCodeServiceInterface) *OrderHandler {
+// NewOrderHandler creates a new order handler. A nil couponGuard disables
+// brute-force protection on promo codes.
+func NewOrderHandler(service service.OrderServiceInterface, promoCodeService service.PromoCodeServiceInterface, couponGuard *couponguard.Guard) *OrderHandler {
+	registerBindingRules()
 	return &OrderHandler{
 		service:          service,
 		promoCodeService: promoCodeService,
+		couponGuard:      couponGuard,
 	}
 }
 
@@ -31,40 +75,228 @@ func NewOrderHandler(service service.OrderServiceInterface, promoCodeService ser
 // @Accept json
 // @Produce json
 // @Param order body models.OrderReq true "Order request"
+// @Param Idempotency-Key header string false "Retries with the same key return the original order instead of placing another"
 // @Success 200 {object} models.Order
 // @Failure 400 {object} models.APIResponse "Invalid input"
 // @Failure 401 {object} models.APIResponse "Unauthorized"
+// @Failure 402 {object} models.PaymentDeclinedResponse "The payment method was declined; the order is not placed"
 // @Failure 403 {object} models.APIResponse "Forbidden"
-// @Failure 422 {object} models.APIResponse "Validation exception"
+// @Failure 409 {object} models.PriceMismatchResponse "An expectedUnitPrice is out of date, or the promo code reached its usage limit"
+// @Failure 422 {object} models.APIResponse "Validation exception, the promo code does not apply to any item, or the modifiers of an item do not fit its product"
+// @Failure 503 {object} models.APIResponse "The promo code cannot be checked while the database is degraded, the payment provider is unavailable, or too many orders are being placed"
 // @Security ApiKeyAuth
-// @Router /order [post]
-func (h *OrderHandler) CreateOrder(c *gin.Context) {
+// @Router /api/v1/order [post]
+func (h *OrderHandler) CreateOrder(c httpx.Context) {
 	var req models.OrderReq
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.Bind(&req); err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
 		return
 	}
 
+	req.AccountID = utils.CustomerFromContext(c)
+
 	// Validate promo code if provided
-	if req.CouponCode != "" {
-		valid, err := h.promoCodeService.ValidatePromoCode(req.CouponCode)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to validate promo code"))
-			return
-		}
-		if !valid {
-			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid promo code. Code must be 8-10 characters and exist in at least 2 files."))
-			return
+	if !h.checkPromoCode(c, req.CouponCode) {
+		return
+	}
+
+	order, err := h.service.CreateOrder(c.Context(), req)
+	if writeStockError(c, err) || writePromoCodeError(c, err, h.promoCodeService.Policy()) {
+		return
+	}
+	var priceErr *service.PriceMismatchError
+	if errors.As(err, &priceErr) {
+		c.JSON(http.StatusConflict, models.PriceMismatchResponse{
+			APIResponse: models.ErrorResponse(http.StatusConflict, "Prices changed since they were shown"),
+			Prices:      priceErr.Mismatches,
+		})
+		return
+	}
+	if err != nil {
+		writePlaceOrderError(c, err)
+		return
+	}
+	tracing.OrderCommitted(c.Context(), tracing.OrderSourceAPI, order.ID, len(order.Items), order.Total)
+
+	c.JSON(http.StatusCreated, orderResponse(order))
+}
+
+// ImportOrder handles POST /orders/import for tickets from the legacy POS
+// @Summary Import a legacy POS order
+// @Description Translate a legacy POS ticket (XML or JSON) into an order. Imports are idempotent on the POS ticket number.
+// @Tags order
+// @Accept json,xml
+// @Produce json
+// @Success 201 {object} models.Order "Order imported"
+// @Success 200 {object} models.Order "Ticket already imported"
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 409 {object} models.APIResponse "Promo code reached its usage limit"
+// @Failure 415 {object} models.APIResponse "Unsupported payload format"
+// @Failure 422 {object} models.ValidationErrorResponse "Validation exception, or the modifiers of an item do not fit its product"
+// @Failure 503 {object} models.APIResponse "Too many orders are being placed"
+// @Security ApiKeyAuth
+// @Router /api/v1/orders/import [post]
+func (h *OrderHandler) ImportOrder(c httpx.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxPOSPayloadBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Failed to read request body"))
+		return
+	}
+
+	ticket, err := pos.Parse(httpx.MediaType(c.GetHeader("Content-Type")), body)
+	if errors.Is(err, pos.ErrUnsupportedFormat) {
+		c.JSON(http.StatusUnsupportedMediaType, models.ErrorResponse(http.StatusUnsupportedMediaType, "POS payload must be XML or JSON"))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	req, diagnostics := pos.Translate(ticket)
+	if len(diagnostics) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, models.ValidationError(http.StatusUnprocessableEntity, "POS ticket failed validation", diagnostics))
+		return
+	}
+
+	if !h.checkPromoCode(c, req.CouponCode) {
+		return
+	}
+
+	order, created, err := h.service.ImportPOSOrder(c.Context(), strings.TrimSpace(ticket.TicketNumber), req)
+	if writeStockError(c, err) || writePromoCodeError(c, err, h.promoCodeService.Policy()) {
+		return
+	}
+	if err != nil {
+		writePlaceOrderError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+		tracing.OrderCommitted(c.Context(), tracing.OrderSourcePOS, order.ID, len(order.Items), order.Total)
+	}
+	c.JSON(status, orderResponse(order))
+}
+
+// checkPromoCode validates an optional promo code with the handler's
+// service and guard
+func (h *OrderHandler) checkPromoCode(c httpx.Context, code string) bool {
+	return checkPromoCode(c, h.promoCodeService, h.couponGuard, code)
+}
+
+// checkPromoCode validates an optional promo code and writes the error
+// response when it is rejected. It reports whether the request may proceed.
+// Clients that submit too many invalid codes are refused with 429 until
+// their cool-down ends; a nil couponGuard disables this.
+func checkPromoCode(c httpx.Context, promoCodeService service.PromoCodeServiceInterface, couponGuard *couponguard.Guard, code string) bool {
+	if code == "" {
+		return true
+	}
+
+	client := couponClientKey(c)
+	if couponGuard != nil {
+		if remaining, blocked := couponGuard.Check(c.Context(), client); blocked {
+			tooManyCouponAttempts(c, remaining)
+			return false
 		}
 	}
 
-	order, err := h.service.CreateOrder(req)
+	_, valid, err := promoCodeService.ValidatePromoCode(code)
+	if errors.Is(err, service.ErrPromoCodeUnavailable) {
+		// The database is degraded, which is no reason to count the code
+		// against the client
+		promoCodeUnavailable(c)
+		return false
+	}
 	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to validate promo code"))
+		return false
+	}
+	tracing.CouponValidated(c.Context(), valid)
+	if !valid {
+		if couponGuard != nil {
+			if cooldown, blocked := couponGuard.RecordFailure(c.Context(), client); blocked {
+				slog.WarnContext(c.Context(), "Blocking coupon validation after repeated invalid codes", "client", client, "cooldown", cooldown)
+				tooManyCouponAttempts(c, cooldown)
+				return false
+			}
+		}
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid promo code. "+promoCodeService.Policy().Rules()))
+		return false
+	}
+
+	return true
+}
+
+// writePlaceOrderError answers a request whose order could not be placed
+// for a reason other than stock, the promo code or changed prices. Unknown
+// products and modifiers that do not fit a product are the caller's
+// mistake; anything else is a server error whose details, such as database
+// errors, are only logged.
+func writePlaceOrderError(c httpx.Context, err error) {
+	if errors.Is(err, service.ErrProductNotFound) {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
 		return
 	}
+	if errors.Is(err, service.ErrInvalidModifiers) {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+	if errors.Is(err, service.ErrPromoCodeUnavailable) {
+		promoCodeUnavailable(c)
+		return
+	}
+	var declined *payment.DeclinedError
+	if errors.As(err, &declined) {
+		c.JSON(http.StatusPaymentRequired, models.PaymentDeclinedResponse{
+			APIResponse: models.ErrorResponse(http.StatusPaymentRequired, declined.Message),
+			DeclineCode: declined.Code,
+		})
+		return
+	}
+	if errors.Is(err, payment.ErrUnavailable) {
+		slog.WarnContext(c.Context(), "Payment provider unavailable", "error", err)
+		c.Header("Retry-After", "30")
+		c.JSON(http.StatusServiceUnavailable, models.ErrorResponse(http.StatusServiceUnavailable, "Payments cannot be taken right now. Try again later."))
+		return
+	}
+	slog.ErrorContext(c.Context(), "Failed to place order", "error", err)
+	c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to place order"))
+}
 
-	response := models.HATEOASResponse{
+// couponClientKey identifies the caller for coupon brute-force tracking.
+// Partners are tracked by identity; everyone else, including callers sharing
+// the built-in API key, by client IP.
+func couponClientKey(c httpx.Context) string {
+	if principal := utils.PrincipalFromContext(c); strings.HasPrefix(principal, utils.PartnerPrincipal("")) {
+		return principal
+	}
+	return c.ClientIP()
+}
+
+// tooManyCouponAttempts writes the 429 response for a blocked client
+func tooManyCouponAttempts(c httpx.Context, retryAfter time.Duration) {
+	seconds := int(retryAfter.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, models.ErrorResponse(http.StatusTooManyRequests, "Too many invalid promo codes. Try again later."))
+}
+
+// promoCodeUnavailable writes the 503 response for a promo code that could
+// not be checked; the order can be placed without it
+func promoCodeUnavailable(c httpx.Context) {
+	c.Header("Retry-After", "30")
+	c.JSON(http.StatusServiceUnavailable, models.ErrorResponse(http.StatusServiceUnavailable,
+		"Promo codes cannot be checked right now. Try again later or place the order without one."))
+}
+
+// orderResponse wraps a newly placed order with its HATEOAS links
+func orderResponse(order models.Order) models.HATEOASResponse {
+	return models.HATEOASResponse{
 		Data: order,
 		Links: []models.Link{
 			{Href: fmt.Sprintf("/api/v1/orders/%s", order.ID), Rel: "self", Method: "GET"},
@@ -72,12 +304,10 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 			{Href: "/api/v1/products", Rel: "products", Method: "GET"},
 		},
 	}
-
-	c.JSON(http.StatusCreated, response)
 }
 
 // GetOrder handles GET /order/:orderId with HATEOAS
-func (h *OrderHandler) GetOrder(c *gin.Context) {
+func (h *OrderHandler) GetOrder(c httpx.Context) {
 	orderID := c.Param("orderId")
 
 	if orderID == "" {
@@ -86,67 +316,347 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 	}
 
 	order, err := h.service.GetOrder(orderID)
-	if err != nil {
+	if err != nil || !canSeeOrder(c, order) {
 		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Order not found"))
 		return
 	}
 
+	order, itemLinks := inlineItems(order)
 	response := models.HATEOASResponse{
 		Data: order,
-		Links: []models.Link{
+		Links: append([]models.Link{
 			{Href: fmt.Sprintf("/api/v1/orders/%s", orderID), Rel: "self", Method: "GET"},
 			{Href: "/api/v1/orders", Rel: "collection", Method: "GET"},
 			{Href: "/api/v1/products", Rel: "products", Method: "GET"},
+		}, itemLinks...),
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListOrderItems handles GET /orders/:orderId/items with pagination and HATEOAS
+// @Summary List the items of an order
+// @Description Returns a page of the items of an order, each with the product as it was priced. Orders with more than 50 items only link here instead of listing their items inline.
+// @Tags order
+// @Produce json
+// @Param orderId path string true "ID of order"
+// @Param page query int false "Page number"
+// @Param perPage query int false "Items per page"
+// @Success 200 {array} models.OrderLine
+// @Failure 404 {object} models.APIResponse "Order not found"
+// @Security ApiKeyAuth
+// @Router /api/v1/orders/{orderId}/items [get]
+func (h *OrderHandler) ListOrderItems(c httpx.Context) {
+	// Pagination parameters are parsed and capped by the pagination middleware
+	p := utils.PaginationFromContext(c)
+	orderID := c.Param("orderId")
+
+	// Customers may only page through the items of their own orders
+	if utils.CustomerFromContext(c) != "" {
+		order, err := h.service.GetOrder(orderID)
+		if err != nil || !canSeeOrder(c, order) {
+			c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Order not found"))
+			return
+		}
+	}
+
+	lines, total, err := h.service.ListOrderItems(orderID, p.PerPage, p.Offset)
+	if errors.Is(err, service.ErrOrderNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Order not found"))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch order items"))
+		return
+	}
+
+	totalPages := utils.TotalPages(total, p.PerPage)
+	basePath := fmt.Sprintf("/api/v1/orders/%s/items", orderID)
+	response := models.PaginatedResponse{
+		Data: lines,
+		Pagination: models.PaginationMeta{
+			Page:       p.Page,
+			PerPage:    p.PerPage,
+			TotalPages: totalPages,
+			TotalItems: total,
 		},
+		Links: append(utils.BuildPaginationLinks(p.Page, totalPages, basePath, p.PerPage),
+			models.Link{Href: fmt.Sprintf("/api/v1/orders/%s", orderID), Rel: "order", Method: "GET"}),
 	}
 
+	utils.SetLinkHeader(c, response.Links)
 	c.JSON(http.StatusOK, response)
 }
 
-// ListOrders handles GET /order with pagination and HATEOAS
-func (h *OrderHandler) ListOrders(c *gin.Context) {
-	// Parse pagination parameters
-	page := utils.ParseInt(c.Query("page"), 1)
-	perPage := utils.ParseInt(c.Query("perPage"), 10)
+// canSeeOrder reports whether the caller may see order. Customers only see
+// the orders they placed; API key callers see every order.
+func canSeeOrder(c httpx.Context, order models.Order) bool {
+	customerID := utils.CustomerFromContext(c)
+	return customerID == "" || order.CustomerID == customerID
+}
+
+// inlineItems leaves the items and products out of an order with more than
+// maxInlineOrderItems items, setting its item count instead, and returns
+// the link to its paginated items in that case
+func inlineItems(order models.Order) (models.Order, []models.Link) {
+	if len(order.Items) <= maxInlineOrderItems {
+		return order, nil
+	}
+	order.ItemCount = len(order.Items)
+	order.Items = nil
+	order.Products = nil
+	return order, []models.Link{
+		{Href: fmt.Sprintf("/api/v1/orders/%s/items", order.ID), Rel: "items", Method: "GET"},
+	}
+}
 
-	// Calculate offset
-	offset := (page - 1) * perPage
+// UpdateOrderStatus handles PATCH /orders/:orderId/status
+// @Summary Update order status
+// @Description Move an order through its lifecycle: pending, confirmed, preparing, completed. Orders can be cancelled until they are completed. Setting the current status again is a no-op.
+// @Tags order
+// @Accept json
+// @Produce json
+// @Param orderId path string true "ID of order"
+// @Param status body models.OrderStatusReq true "New status"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 404 {object} models.APIResponse "Order not found"
+// @Failure 409 {object} models.APIResponse "Transition not allowed from the current status"
+// @Failure 422 {object} models.ValidationErrorResponse "Unknown status"
+// @Security ApiKeyAuth
+// @Router /api/v1/orders/{orderId}/status [patch]
+func (h *OrderHandler) UpdateOrderStatus(c httpx.Context) {
+	var req models.OrderStatusReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
 
-	// Get paginated orders
-	orders, total, err := h.service.ListOrdersPaginated(perPage, offset)
+	order, err := h.service.UpdateOrderStatus(c.Context(), c.Param("orderId"), req.Status)
+	switch {
+	case errors.Is(err, service.ErrInvalidOrderStatus):
+		c.JSON(http.StatusUnprocessableEntity, models.ValidationError(http.StatusUnprocessableEntity, "Validation failed", []models.FieldError{
+			{Field: "status", Message: "status must be one of pending, confirmed, preparing, completed, cancelled"},
+		}))
+		return
+	case errors.Is(err, service.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Order not found"))
+		return
+	case errors.Is(err, service.ErrInvalidStatusTransition), errors.Is(err, service.ErrOrderStatusConflict):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, err.Error()))
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to update order status"))
+		return
+	}
+
+	c.JSON(http.StatusOK, orderResponse(order))
+}
+
+// ListOrders handles GET /order with pagination and HATEOAS. Orders are
+// paginated by page number, or after a cursor when after or limit is given,
+// or all streamed as NDJSON when the Accept header asks for it, and can be
+// filtered by status, date, promo code and product. Customers are only shown the orders they
+// placed.
+func (h *OrderHandler) ListOrders(c httpx.Context) {
+	filter, query, ok := parseOrderFilter(c)
+	if !ok {
+		return
+	}
+	filter.CustomerID = utils.CustomerFromContext(c)
+	h.listOrders(c, "/api/v1/orders", filter, query)
+}
+
+// ListCustomerOrders handles GET /customers/:customerId/orders
+// @Summary List the orders of a customer
+// @Description Order history of a customer account, newest first unless sorted. Customers may use "me" as their ID and only see their own orders.
+// @Tags customer
+// @Produce json,application/x-ndjson
+// @Param customerId path string true "ID of customer, or me"
+// @Param status query string false "Filter by status"
+// @Param from query string false "Orders placed at or after, RFC 3339 or YYYY-MM-DD (UTC)"
+// @Param to query string false "Orders placed before, RFC 3339 or YYYY-MM-DD (UTC)"
+// @Param couponCode query string false "Filter by the promo code applied"
+// @Param productId query string false "Filter by a product ordered"
+// @Param sort query string false "Sort fields, such as -total"
+// @Param page query int false "Page number"
+// @Param perPage query int false "Orders per page"
+// @Param after query string false "Cursor of the next page"
+// @Param limit query int false "Orders per page after a cursor"
+// @Success 200 {object} models.PaginatedResponse
+// @Failure 400 {object} models.APIResponse "Invalid filter, sort or cursor"
+// @Failure 401 {object} models.APIResponse "Unauthorized"
+// @Failure 403 {object} models.APIResponse "Orders of another customer"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /api/v1/customers/{customerId}/orders [get]
+func (h *OrderHandler) ListCustomerOrders(c httpx.Context) {
+	customerID := c.Param("customerId")
+	if caller := utils.CustomerFromContext(c); caller != "" {
+		if customerID == "me" {
+			customerID = caller
+		}
+		if customerID != caller {
+			c.JSON(http.StatusForbidden, models.ErrorResponse(http.StatusForbidden, "Forbidden: customers only see their own orders"))
+			return
+		}
+	} else if customerID == "me" {
+		c.JSON(http.StatusForbidden, models.ErrorResponse(http.StatusForbidden, "Forbidden: only customers have an account"))
+		return
+	}
+
+	filter, query, ok := parseOrderFilter(c)
+	if !ok {
+		return
+	}
+	filter.CustomerID = customerID
+	h.listOrders(c, fmt.Sprintf("/api/v1/customers/%s/orders", url.PathEscape(c.Param("customerId"))), filter, query)
+}
+
+// parseOrderFilter reads the status, from, to, couponCode and productId
+// filters of an order listing, and returns them with the query parameters repeating them in
+// pagination links. It answers invalid filters with 400 and returns false.
+func parseOrderFilter(c httpx.Context) (models.OrderFilter, url.Values, bool) {
+	var filter models.OrderFilter
+	query := url.Values{}
+	if status := c.Query("status"); status != "" {
+		if !containsString(orderStatuses, status) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, fmt.Sprintf("Invalid status %q", status)))
+			return models.OrderFilter{}, nil, false
+		}
+		filter.Status = models.OrderStatus(status)
+		query.Set("status", status)
+	}
+	for _, bound := range []struct {
+		name string
+		dest *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := c.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := timezone.ParseDate(value, time.UTC)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, fmt.Sprintf("Invalid %s %q", bound.name, value)))
+			return models.OrderFilter{}, nil, false
+		}
+		*bound.dest = parsed
+		query.Set(bound.name, value)
+	}
+	if code := c.Query("couponCode"); code != "" {
+		if len(code) > maxCouponCodeFilterLength {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid couponCode: too long"))
+			return models.OrderFilter{}, nil, false
+		}
+		filter.CouponCode = promocode.Normalize(code)
+		query.Set("couponCode", code)
+	}
+	if productID := c.Query("productId"); productID != "" {
+		if !productIDFilterPattern.MatchString(productID) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, fmt.Sprintf("Invalid productId %q", productID)))
+			return models.OrderFilter{}, nil, false
+		}
+		filter.ProductID = productID
+		query.Set("productId", productID)
+	}
+	return filter, query, true
+}
+
+// listOrders answers an order listing of the orders filter matches, linking
+// pages under basePath with query, or streams them all as NDJSON
+func (h *OrderHandler) listOrders(c httpx.Context, basePath string, filter models.OrderFilter, query url.Values) {
+	// Pagination parameters are parsed and capped by the pagination middleware
+	p := utils.PaginationFromContext(c)
+
+	sort, err := utils.ParseSort(c.Query("sort"), orderSortFields)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch orders"))
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+	for key, values := range utils.SortQuery(sort) {
+		query[key] = values
+	}
+
+	if wantsNDJSON(c) {
+		streamNDJSON(c, "Failed to fetch orders", func(emit func(any) error) error {
+			return h.service.StreamOrders(c.Context(), sort, filter, func(order models.Order) error {
+				return emit(order)
+			})
+		})
+		return
+	}
+	if p.Cursor {
+		h.listOrdersAfter(c, p, sort, filter, basePath, query)
 		return
 	}
 
-	// Add HATEOAS links to each order
-	ordersWithLinks := make([]models.OrderWithLinks, len(orders))
-	for i, order := range orders {
-		ordersWithLinks[i] = models.OrderWithLinks{
-			Order: order,
-			Links: []models.Link{
-				{Href: fmt.Sprintf("/api/v1/orders/%s", order.ID), Rel: "self", Method: "GET"},
-				{Href: "/api/v1/orders", Rel: "collection", Method: "GET"},
-			},
+	// Get paginated orders
+	orders, total, err := h.service.ListOrdersPaginated(c.Context(), p.PerPage, p.Offset, sort, filter)
+	if err != nil {
+		// The cancellation middleware answers requests that timed out or
+		// whose client went away
+		if c.Context().Err() != nil {
+			return
 		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch orders"))
+		return
 	}
 
 	// Build pagination response
-	totalPages := (total + perPage - 1) / perPage
-	if totalPages == 0 {
-		totalPages = 1
-	}
+	totalPages := utils.TotalPages(total, p.PerPage)
 
 	response := models.PaginatedResponse{
-		Data: ordersWithLinks,
+		Data: ordersWithLinks(orders),
 		Pagination: models.PaginationMeta{
-			Page:       page,
-			PerPage:    perPage,
+			Page:       p.Page,
+			PerPage:    p.PerPage,
 			TotalPages: totalPages,
 			TotalItems: total,
 		},
-		Links: utils.BuildPaginationLinks(page, totalPages, "/api/v1/orders", perPage),
+		Links: utils.BuildPaginationLinksWithQuery(p.Page, totalPages, basePath, p.PerPage, query),
 	}
 
+	utils.SetLinkHeader(c, response.Links)
 	c.JSON(http.StatusOK, response)
 }
+
+// listOrdersAfter answers an order listing for the page after a cursor
+func (h *OrderHandler) listOrdersAfter(c httpx.Context, p utils.Pagination, sort []models.SortField, filter models.OrderFilter, basePath string, query url.Values) {
+	orders, next, err := h.service.ListOrdersAfter(c.Context(), p.After, p.PerPage, sort, filter)
+	if errors.Is(err, service.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid cursor; cursors are only valid with the sort they were issued for"))
+		return
+	}
+	if err != nil {
+		if c.Context().Err() != nil {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch orders"))
+		return
+	}
+
+	response := models.PaginatedResponse{
+		Data:   ordersWithLinks(orders),
+		Cursor: models.CursorMeta{Limit: p.PerPage, NextCursor: next},
+		Links:  utils.BuildCursorLinks(p, next, basePath, query),
+	}
+
+	utils.SetLinkHeader(c, response.Links)
+	c.JSON(http.StatusOK, response)
+}
+
+// ordersWithLinks adds HATEOAS links to each order of a listing
+func ordersWithLinks(orders []models.Order) []models.OrderWithLinks {
+	result := make([]models.OrderWithLinks, len(orders))
+	for i, order := range orders {
+		order, itemLinks := inlineItems(order)
+		result[i] = models.OrderWithLinks{
+			Order: order,
+			Links: append([]models.Link{
+				{Href: fmt.Sprintf("/api/v1/orders/%s", order.ID), Rel: "self", Method: "GET"},
+				{Href: "/api/v1/orders", Rel: "collection", Method: "GET"},
+			}, itemLinks...),
+		}
+	}
+	return result
+}
@@ -2,15 +2,23 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/couponguard"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/payment"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,21 +31,53 @@ type MockOrderService struct {
 // Verify interface compliance
 var _ service.OrderServiceInterface = (*MockOrderService)(nil)
 
-func (m *MockOrderService) CreateOrder(req models.OrderReq) (models.Order, error) {
+func (m *MockOrderService) CreateOrder(ctx context.Context, req models.OrderReq) (models.Order, error) {
 	args := m.Called(req)
 	return args.Get(0).(models.Order), args.Error(1)
 }
 
+func (m *MockOrderService) ImportPOSOrder(ctx context.Context, ticketNumber string, req models.OrderReq) (models.Order, bool, error) {
+	args := m.Called(ticketNumber, req)
+	return args.Get(0).(models.Order), args.Bool(1), args.Error(2)
+}
+
 func (m *MockOrderService) GetOrder(id string) (models.Order, error) {
 	args := m.Called(id)
 	return args.Get(0).(models.Order), args.Error(1)
 }
 
-func (m *MockOrderService) ListOrdersPaginated(limit, offset int) ([]models.Order, int, error) {
-	args := m.Called(limit, offset)
+func (m *MockOrderService) ListOrderItems(id string, limit, offset int) ([]models.OrderLine, int, error) {
+	args := m.Called(id, limit, offset)
+	return args.Get(0).([]models.OrderLine), args.Int(1), args.Error(2)
+}
+
+func (m *MockOrderService) UpdateOrderStatus(ctx context.Context, id string, status models.OrderStatus) (models.Order, error) {
+	args := m.Called(id, status)
+	return args.Get(0).(models.Order), args.Error(1)
+}
+
+func (m *MockOrderService) ListOrdersPaginated(_ context.Context, limit, offset int, sort []models.SortField, filter models.OrderFilter) ([]models.Order, int, error) {
+	args := m.Called(limit, offset, sort, filter)
 	return args.Get(0).([]models.Order), args.Int(1), args.Error(2)
 }
 
+func (m *MockOrderService) ListOrdersAfter(_ context.Context, after string, limit int, sort []models.SortField, filter models.OrderFilter) ([]models.Order, string, error) {
+	args := m.Called(after, limit, sort, filter)
+	return args.Get(0).([]models.Order), args.String(1), args.Error(2)
+}
+
+// StreamOrders passes the orders the mock returns to fn, then returns its
+// error
+func (m *MockOrderService) StreamOrders(_ context.Context, sort []models.SortField, filter models.OrderFilter, fn func(models.Order) error) error {
+	args := m.Called(sort, filter)
+	for _, order := range args.Get(0).([]models.Order) {
+		if err := fn(order); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
 // MockPromoCodeService is a mock implementation of PromoCodeServiceInterface
 type MockPromoCodeService struct {
 	mock.Mock
@@ -46,9 +86,13 @@ type MockPromoCodeService struct {
 // Verify interface compliance
 var _ service.PromoCodeServiceInterface = (*MockPromoCodeService)(nil)
 
-func (m *MockPromoCodeService) ValidatePromoCode(code string) (bool, error) {
+func (m *MockPromoCodeService) Policy() models.PromoCodePolicy {
+	return service.DefaultPromoCodePolicy
+}
+
+func (m *MockPromoCodeService) ValidatePromoCode(code string) (models.PromoCode, bool, error) {
 	args := m.Called(code)
-	return args.Bool(0), args.Error(1)
+	return args.Get(0).(models.PromoCode), args.Bool(1), args.Error(2)
 }
 
 func TestOrderHandler_CreateOrder_Success_WithValidPromoCode(t *testing.T) {
@@ -56,7 +100,7 @@ func TestOrderHandler_CreateOrder_Success_WithValidPromoCode(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockOrderService := new(MockOrderService)
 	mockPromoService := new(MockPromoCodeService)
-	handler := NewOrderHandler(mockOrderService, mockPromoService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
 
 	// Mock data
 	orderReq := models.OrderReq{
@@ -74,7 +118,7 @@ func TestOrderHandler_CreateOrder_Success_WithValidPromoCode(t *testing.T) {
 		},
 	}
 
-	mockPromoService.On("ValidatePromoCode", "HAPPYHRS").Return(true, nil)
+	mockPromoService.On("ValidatePromoCode", "HAPPYHRS").Return(models.PromoCode{Code: "HAPPYHRS"}, true, nil)
 	mockOrderService.On("CreateOrder", orderReq).Return(order, nil)
 
 	// Create request
@@ -85,7 +129,7 @@ func TestOrderHandler_CreateOrder_Success_WithValidPromoCode(t *testing.T) {
 	c.Request.Header.Set("Content-Type", "application/json")
 
 	// Execute
-	handler.CreateOrder(c)
+	handler.CreateOrder(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusCreated, w.Code)
@@ -107,7 +151,7 @@ func TestOrderHandler_CreateOrder_Success_WithoutPromoCode(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockOrderService := new(MockOrderService)
 	mockPromoService := new(MockPromoCodeService)
-	handler := NewOrderHandler(mockOrderService, mockPromoService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
 
 	// Mock data
 	orderReq := models.OrderReq{
@@ -131,7 +175,7 @@ func TestOrderHandler_CreateOrder_Success_WithoutPromoCode(t *testing.T) {
 	c.Request.Header.Set("Content-Type", "application/json")
 
 	// Execute
-	handler.CreateOrder(c)
+	handler.CreateOrder(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusCreated, w.Code)
@@ -140,12 +184,41 @@ func TestOrderHandler_CreateOrder_Success_WithoutPromoCode(t *testing.T) {
 	mockPromoService.AssertNotCalled(t, "ValidatePromoCode")
 }
 
+func TestOrderHandler_CreateOrder_PriceChanged(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	mismatch := models.PriceMismatch{ProductID: "1", ExpectedUnitPrice: 6.0, UnitPrice: 6.5}
+	mockOrderService.On("CreateOrder", mock.Anything).
+		Return(models.Order{}, &service.PriceMismatchError{Mismatches: []models.PriceMismatch{mismatch}})
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/orders",
+		bytes.NewBufferString(`{"items":[{"productId":"1","quantity":2,"expectedUnitPrice":6.0}]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.CreateOrder(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var resp models.PriceMismatchResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []models.PriceMismatch{mismatch}, resp.Prices)
+	mockOrderService.AssertExpectations(t)
+}
+
 func TestOrderHandler_CreateOrder_InvalidPromoCode(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
 	mockOrderService := new(MockOrderService)
 	mockPromoService := new(MockPromoCodeService)
-	handler := NewOrderHandler(mockOrderService, mockPromoService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
 
 	// Mock data
 	orderReq := models.OrderReq{
@@ -155,7 +228,7 @@ func TestOrderHandler_CreateOrder_InvalidPromoCode(t *testing.T) {
 		},
 	}
 
-	mockPromoService.On("ValidatePromoCode", "INVALID").Return(false, nil)
+	mockPromoService.On("ValidatePromoCode", "INVALID").Return(models.PromoCode{}, false, nil)
 
 	// Create request
 	body, _ := json.Marshal(orderReq)
@@ -165,7 +238,7 @@ func TestOrderHandler_CreateOrder_InvalidPromoCode(t *testing.T) {
 	c.Request.Header.Set("Content-Type", "application/json")
 
 	// Execute
-	handler.CreateOrder(c)
+	handler.CreateOrder(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusBadRequest, w.Code)
@@ -180,12 +253,134 @@ func TestOrderHandler_CreateOrder_InvalidPromoCode(t *testing.T) {
 	mockOrderService.AssertNotCalled(t, "CreateOrder")
 }
 
+func TestOrderHandler_CreateOrder_PromoCodeLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "exhausted", err: service.ErrPromoCodeExhausted, wantStatus: http.StatusConflict},
+		{name: "already redeemed", err: service.ErrPromoCodeRedeemed, wantStatus: http.StatusConflict},
+		{name: "customer required", err: service.ErrCustomerRequired, wantStatus: http.StatusBadRequest},
+		{name: "not applicable", err: service.ErrPromoCodeNotApplicable, wantStatus: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockOrderService := new(MockOrderService)
+			mockPromoService := new(MockPromoCodeService)
+			handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+			orderReq := models.OrderReq{
+				CouponCode: "HAPPYHRS",
+				CustomerID: "customer-1",
+				Items:      []models.OrderItem{{ProductID: "1", Quantity: 1}},
+			}
+			mockPromoService.On("ValidatePromoCode", "HAPPYHRS").Return(models.PromoCode{Code: "HAPPYHRS"}, true, nil)
+			mockOrderService.On("CreateOrder", orderReq).Return(models.Order{}, tt.err)
+
+			// Create request
+			body, _ := json.Marshal(orderReq)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/orders", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			// Execute
+			handler.CreateOrder(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockOrderService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrderHandler_CreateOrder_Failed(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantMessage string
+	}{
+		{name: "unknown product", err: fmt.Errorf("%w: 42", service.ErrProductNotFound), wantStatus: http.StatusBadRequest, wantMessage: "product not found: 42"},
+		{name: "modifiers do not fit", err: fmt.Errorf("%w: product 42 needs at least 1 of its Size options", service.ErrInvalidModifiers), wantStatus: http.StatusUnprocessableEntity, wantMessage: "invalid modifiers: product 42 needs at least 1 of its Size options"},
+		{name: "database error", err: errors.New(`error querying products: pq: relation "products" does not exist`), wantStatus: http.StatusInternalServerError, wantMessage: "Failed to place order"},
+		{name: "payment declined", err: fmt.Errorf("failed to confirm payment: %w", &payment.DeclinedError{Code: "card_declined", Message: "Your card was declined."}), wantStatus: http.StatusPaymentRequired, wantMessage: "Your card was declined."},
+		{name: "payment provider down", err: fmt.Errorf("failed to create payment intent: %w", payment.ErrUnavailable), wantStatus: http.StatusServiceUnavailable, wantMessage: "Payments cannot be taken right now. Try again later."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockOrderService := new(MockOrderService)
+			mockPromoService := new(MockPromoCodeService)
+			handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+			orderReq := models.OrderReq{Items: []models.OrderItem{{ProductID: "42", Quantity: 1}}}
+			mockOrderService.On("CreateOrder", orderReq).Return(models.Order{}, tt.err)
+
+			// Create request
+			body, _ := json.Marshal(orderReq)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/orders", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			// Execute
+			handler.CreateOrder(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response models.APIResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantMessage, response.Message)
+		})
+	}
+}
+
+func TestOrderHandler_CreateOrder_PaymentDeclined(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	orderReq := models.OrderReq{
+		Items:         []models.OrderItem{{ProductID: "1", Quantity: 1}},
+		PaymentMethod: payment.MethodCardInsufficientFunds,
+	}
+	mockOrderService.On("CreateOrder", orderReq).
+		Return(models.Order{}, &payment.DeclinedError{Code: "insufficient_funds", Message: "Your card has insufficient funds."})
+
+	// Create request
+	body, _ := json.Marshal(orderReq)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/orders", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.CreateOrder(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusPaymentRequired, w.Code)
+	var resp models.PaymentDeclinedResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "insufficient_funds", resp.DeclineCode)
+	assert.Equal(t, "Your card has insufficient funds.", resp.Message)
+	mockOrderService.AssertExpectations(t)
+}
+
 func TestOrderHandler_CreateOrder_PromoCodeValidationError(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
 	mockOrderService := new(MockOrderService)
 	mockPromoService := new(MockPromoCodeService)
-	handler := NewOrderHandler(mockOrderService, mockPromoService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
 
 	// Mock data
 	orderReq := models.OrderReq{
@@ -195,7 +390,7 @@ func TestOrderHandler_CreateOrder_PromoCodeValidationError(t *testing.T) {
 		},
 	}
 
-	mockPromoService.On("ValidatePromoCode", "TESTCODE").Return(false, errors.New("database error"))
+	mockPromoService.On("ValidatePromoCode", "TESTCODE").Return(models.PromoCode{}, false, errors.New("database error"))
 
 	// Create request
 	body, _ := json.Marshal(orderReq)
@@ -205,7 +400,7 @@ func TestOrderHandler_CreateOrder_PromoCodeValidationError(t *testing.T) {
 	c.Request.Header.Set("Content-Type", "application/json")
 
 	// Execute
-	handler.CreateOrder(c)
+	handler.CreateOrder(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
@@ -224,7 +419,7 @@ func TestOrderHandler_CreateOrder_InvalidJSON(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockOrderService := new(MockOrderService)
 	mockPromoService := new(MockPromoCodeService)
-	handler := NewOrderHandler(mockOrderService, mockPromoService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
 
 	// Create request with invalid JSON
 	w := httptest.NewRecorder()
@@ -233,18 +428,77 @@ func TestOrderHandler_CreateOrder_InvalidJSON(t *testing.T) {
 	c.Request.Header.Set("Content-Type", "application/json")
 
 	// Execute
-	handler.CreateOrder(c)
+	handler.CreateOrder(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+func TestOrderHandler_CreateOrder_Delivery(t *testing.T) {
+	valid := func() *models.Delivery {
+		return &models.Delivery{
+			Address: models.DeliveryAddress{Line1: "1 Market Street", City: "Sydney", Region: "NSW", PostalCode: "2000", Country: "AU"},
+			Contact: models.DeliveryContact{Name: "Jane Citizen", Phone: "+61 2 9999 0000", Email: "jane@example.com"},
+		}
+	}
+	tests := []struct {
+		name     string
+		modify   func(d *models.Delivery)
+		wantCode int
+	}{
+		{name: "valid", modify: func(d *models.Delivery) {}, wantCode: http.StatusCreated},
+		{name: "no postal code", modify: func(d *models.Delivery) { d.Address.PostalCode = "" }, wantCode: http.StatusCreated},
+		{name: "blank street", modify: func(d *models.Delivery) { d.Address.Line1 = "  " }, wantCode: http.StatusBadRequest},
+		{name: "unknown country", modify: func(d *models.Delivery) { d.Address.Country = "XX" }, wantCode: http.StatusBadRequest},
+		{name: "invalid postal code", modify: func(d *models.Delivery) { d.Address.PostalCode = "20#0" }, wantCode: http.StatusBadRequest},
+		{name: "phone with letters", modify: func(d *models.Delivery) { d.Contact.Phone = "call me" }, wantCode: http.StatusBadRequest},
+		{name: "phone too short", modify: func(d *models.Delivery) { d.Contact.Phone = "12 34" }, wantCode: http.StatusBadRequest},
+		{name: "missing phone", modify: func(d *models.Delivery) { d.Contact.Phone = "" }, wantCode: http.StatusBadRequest},
+		{name: "invalid email", modify: func(d *models.Delivery) { d.Contact.Email = "jane" }, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockOrderService := new(MockOrderService)
+			mockPromoService := new(MockPromoCodeService)
+			handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+			orderReq := models.OrderReq{
+				Items:    []models.OrderItem{{ProductID: "1", Quantity: 1}},
+				Delivery: valid(),
+			}
+			tt.modify(orderReq.Delivery)
+			mockOrderService.On("CreateOrder", orderReq).Return(models.Order{ID: "order-456", Items: orderReq.Items, Delivery: orderReq.Delivery}, nil).Maybe()
+
+			body, _ := json.Marshal(orderReq)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/orders", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			// Execute
+			handler.CreateOrder(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantCode == http.StatusCreated {
+				mockOrderService.AssertExpectations(t)
+				assert.Contains(t, w.Body.String(), `"delivery"`)
+			} else {
+				mockOrderService.AssertNotCalled(t, "CreateOrder", mock.Anything)
+			}
+		})
+	}
+}
+
 func TestOrderHandler_GetOrder_Success(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
 	mockOrderService := new(MockOrderService)
 	mockPromoService := new(MockPromoCodeService)
-	handler := NewOrderHandler(mockOrderService, mockPromoService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
 
 	// Mock data
 	order := models.Order{
@@ -263,7 +517,7 @@ func TestOrderHandler_GetOrder_Success(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/api/v1/orders/order-123", nil)
 
 	// Execute
-	handler.GetOrder(c)
+	handler.GetOrder(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -279,12 +533,108 @@ func TestOrderHandler_GetOrder_Success(t *testing.T) {
 	mockOrderService.AssertExpectations(t)
 }
 
+func TestOrderHandler_GetOrder_LargeOrder(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	// Mock data: a catering order with more items than are listed inline
+	order := models.Order{ID: "order-123"}
+	for i := 0; i <= maxInlineOrderItems; i++ {
+		order.Items = append(order.Items, models.OrderItem{ProductID: fmt.Sprint(i), Quantity: 1})
+		order.Products = append(order.Products, models.Product{ID: fmt.Sprint(i)})
+	}
+
+	mockOrderService.On("GetOrder", "order-123").Return(order, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "orderId", Value: "order-123"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/orders/order-123", nil)
+
+	// Execute
+	handler.GetOrder(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"items":`)
+	assert.Contains(t, w.Body.String(), fmt.Sprintf(`"itemCount":%d`, maxInlineOrderItems+1))
+
+	var response models.HATEOASResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Contains(t, response.Links, models.Link{Href: "/api/v1/orders/order-123/items", Rel: "items", Method: "GET"})
+
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestOrderHandler_ListOrderItems(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	lines := []models.OrderLine{
+		{OrderItem: models.OrderItem{ProductID: "1", Quantity: 40}, Product: models.Product{ID: "1", Name: "Waffle", Price: 6.5}},
+	}
+	mockOrderService.On("ListOrderItems", "order-123", 1, 1).Return(lines, 3, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "orderId", Value: "order-123"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/orders/order-123/items?page=2&perPage=1", nil)
+
+	// Execute
+	handler.ListOrderItems(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.PaginatedResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, response.Pagination.TotalItems)
+	assert.Equal(t, 3, response.Pagination.TotalPages)
+	assert.Equal(t, "/api/v1/orders/order-123/items?page=2&perPage=1", response.Links[0].Href)
+	assert.Contains(t, response.Links, models.Link{Href: "/api/v1/orders/order-123", Rel: "order", Method: "GET"})
+
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestOrderHandler_ListOrderItems_NotFound(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	mockOrderService.On("ListOrderItems", "nonexistent", 10, 0).Return([]models.OrderLine(nil), 0, service.ErrOrderNotFound)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "orderId", Value: "nonexistent"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/orders/nonexistent/items", nil)
+
+	// Execute
+	handler.ListOrderItems(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockOrderService.AssertExpectations(t)
+}
+
 func TestOrderHandler_GetOrder_NotFound(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
 	mockOrderService := new(MockOrderService)
 	mockPromoService := new(MockPromoCodeService)
-	handler := NewOrderHandler(mockOrderService, mockPromoService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
 
 	mockOrderService.On("GetOrder", "nonexistent").Return(models.Order{}, errors.New("not found"))
 
@@ -295,7 +645,7 @@ func TestOrderHandler_GetOrder_NotFound(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/api/v1/orders/nonexistent", nil)
 
 	// Execute
-	handler.GetOrder(c)
+	handler.GetOrder(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusNotFound, w.Code)
@@ -313,7 +663,7 @@ func TestOrderHandler_ListOrders_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockOrderService := new(MockOrderService)
 	mockPromoService := new(MockPromoCodeService)
-	handler := NewOrderHandler(mockOrderService, mockPromoService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
 
 	// Mock data
 	orders := []models.Order{
@@ -321,7 +671,7 @@ func TestOrderHandler_ListOrders_Success(t *testing.T) {
 		{ID: "order-2", Items: []models.OrderItem{{ProductID: "2", Quantity: 2}}},
 	}
 
-	mockOrderService.On("ListOrdersPaginated", 10, 0).Return(orders, 2, nil)
+	mockOrderService.On("ListOrdersPaginated", 10, 0, noSort, models.OrderFilter{}).Return(orders, 2, nil)
 
 	// Create request
 	w := httptest.NewRecorder()
@@ -329,7 +679,7 @@ func TestOrderHandler_ListOrders_Success(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/api/v1/orders?page=1&perPage=10", nil)
 
 	// Execute
-	handler.ListOrders(c)
+	handler.ListOrders(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -346,14 +696,207 @@ func TestOrderHandler_ListOrders_Success(t *testing.T) {
 	mockOrderService.AssertExpectations(t)
 }
 
+func TestOrderHandler_CreateOrder_AttachesCustomer(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	orderReq := models.OrderReq{Items: []models.OrderItem{{ProductID: "1", Quantity: 1}}}
+	mockOrderService.On("CreateOrder", mock.MatchedBy(func(req models.OrderReq) bool {
+		return req.AccountID == "c1"
+	})).Return(models.Order{ID: "order-1", CustomerID: "c1"}, nil)
+
+	// Create request
+	body, _ := json.Marshal(orderReq)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/orders", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	utils.SetPrincipal(c, utils.CustomerPrincipal("c1"), service.CustomerScopes)
+
+	// Execute
+	handler.CreateOrder(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"customerId":"c1"`)
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestOrderHandler_ListOrders_CustomerSeesOwnOrders(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	mockOrderService.On("ListOrdersPaginated", 10, 0, noSort, models.OrderFilter{CustomerID: "c1"}).Return([]models.Order{{ID: "order-1", CustomerID: "c1"}}, 1, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/orders", nil)
+	utils.SetPrincipal(c, utils.CustomerPrincipal("c1"), service.CustomerScopes)
+
+	// Execute
+	handler.ListOrders(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestOrderHandler_ListCustomerOrders(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		customerID string
+		query      string
+		principal  string
+		scopes     []string
+		filter     *models.OrderFilter
+		wantStatus int
+	}{
+		{
+			name:       "own orders as me",
+			customerID: "me",
+			query:      "?from=2024-03-01&status=completed",
+			principal:  utils.CustomerPrincipal("c1"),
+			scopes:     service.CustomerScopes,
+			filter:     &models.OrderFilter{CustomerID: "c1", Status: models.OrderStatusCompleted, From: from},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "own orders by ID",
+			customerID: "c1",
+			principal:  utils.CustomerPrincipal("c1"),
+			scopes:     service.CustomerScopes,
+			filter:     &models.OrderFilter{CustomerID: "c1"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "API key lists any customer",
+			customerID: "c2",
+			principal:  "apikey",
+			scopes:     []string{utils.ScopeAll},
+			filter:     &models.OrderFilter{CustomerID: "c2"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "another customer",
+			customerID: "c2",
+			principal:  utils.CustomerPrincipal("c1"),
+			scopes:     service.CustomerScopes,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "me without an account",
+			customerID: "me",
+			principal:  "apikey",
+			scopes:     []string{utils.ScopeAll},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "invalid status",
+			customerID: "me",
+			query:      "?status=shipped",
+			principal:  utils.CustomerPrincipal("c1"),
+			scopes:     service.CustomerScopes,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "orders with a promo code and product",
+			customerID: "me",
+			query:      "?couponCode=HAPPYHRS&productId=waffle-1",
+			principal:  utils.CustomerPrincipal("c1"),
+			scopes:     service.CustomerScopes,
+			filter:     &models.OrderFilter{CustomerID: "c1", CouponCode: "HAPPYHRS", ProductID: "waffle-1"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid product ID",
+			customerID: "me",
+			query:      "?productId=a%20b",
+			principal:  utils.CustomerPrincipal("c1"),
+			scopes:     service.CustomerScopes,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid date",
+			customerID: "me",
+			query:      "?to=yesterday",
+			principal:  utils.CustomerPrincipal("c1"),
+			scopes:     service.CustomerScopes,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockOrderService := new(MockOrderService)
+			mockPromoService := new(MockPromoCodeService)
+			handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+			if tt.filter != nil {
+				mockOrderService.On("ListOrdersPaginated", 10, 0, noSort, *tt.filter).Return([]models.Order{{ID: "order-1", CustomerID: tt.filter.CustomerID}}, 11, nil)
+			}
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/customers/"+tt.customerID+"/orders"+tt.query, nil)
+			c.Params = gin.Params{{Key: "customerId", Value: tt.customerID}}
+			utils.SetPrincipal(c, tt.principal, tt.scopes)
+
+			// Execute
+			handler.ListCustomerOrders(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.filter != nil {
+				assert.Contains(t, w.Header().Get("Link"), "/api/v1/customers/"+tt.customerID+"/orders?page=2&perPage=10"+strings.ReplaceAll(tt.query, "?", "&"))
+			}
+			mockOrderService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrderHandler_GetOrder_OtherCustomersOrder(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	mockOrderService.On("GetOrder", "order-1").Return(models.Order{ID: "order-1", CustomerID: "c2"}, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/orders/order-1", nil)
+	c.Params = gin.Params{{Key: "orderId", Value: "order-1"}}
+	utils.SetPrincipal(c, utils.CustomerPrincipal("c1"), service.CustomerScopes)
+
+	// Execute
+	handler.GetOrder(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code, "another customer's order is reported as missing")
+	mockOrderService.AssertExpectations(t)
+}
+
 func TestOrderHandler_ListOrders_DatabaseError(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
 	mockOrderService := new(MockOrderService)
 	mockPromoService := new(MockPromoCodeService)
-	handler := NewOrderHandler(mockOrderService, mockPromoService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
 
-	mockOrderService.On("ListOrdersPaginated", 10, 0).Return([]models.Order{}, 0, errors.New("database error"))
+	mockOrderService.On("ListOrdersPaginated", 10, 0, noSort, models.OrderFilter{}).Return([]models.Order{}, 0, errors.New("database error"))
 
 	// Create request
 	w := httptest.NewRecorder()
@@ -361,7 +904,7 @@ func TestOrderHandler_ListOrders_DatabaseError(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/api/v1/orders", nil)
 
 	// Execute
-	handler.ListOrders(c)
+	handler.ListOrders(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
@@ -373,3 +916,409 @@ func TestOrderHandler_ListOrders_DatabaseError(t *testing.T) {
 
 	mockOrderService.AssertExpectations(t)
 }
+
+func TestOrderHandler_ImportOrder_XMLCreated(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	expectedReq := models.OrderReq{
+		Items: []models.OrderItem{{ProductID: "1", Quantity: 2}},
+	}
+	order := models.Order{ID: "order-789", Items: expectedReq.Items}
+	mockOrderService.On("ImportPOSOrder", "T-1001", expectedReq).Return(order, true, nil)
+
+	body := `<Ticket><TicketNo>T-1001</TicketNo><Lines><Line><PLU>1</PLU><Qty>2</Qty></Line></Lines></Ticket>`
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/orders/import", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/xml")
+
+	// Execute
+	handler.ImportOrder(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockOrderService.AssertExpectations(t)
+	mockPromoService.AssertNotCalled(t, "ValidatePromoCode")
+}
+
+func TestOrderHandler_ImportOrder_ReplayReturnsExisting(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	expectedReq := models.OrderReq{
+		CouponCode: "HAPPYHRS",
+		Items:      []models.OrderItem{{ProductID: "3", Quantity: 1}},
+	}
+	order := models.Order{ID: "order-existing", Items: expectedReq.Items}
+	mockPromoService.On("ValidatePromoCode", "HAPPYHRS").Return(models.PromoCode{Code: "HAPPYHRS"}, true, nil)
+	mockOrderService.On("ImportPOSOrder", "T-1002", expectedReq).Return(order, false, nil)
+
+	body := `{"ticketNo":"T-1002","promoCd":"HAPPYHRS","lines":[{"plu":"3","qty":"1"}]}`
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/orders/import", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.ImportOrder(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "order-existing")
+	mockOrderService.AssertExpectations(t)
+	mockPromoService.AssertExpectations(t)
+}
+
+func TestOrderHandler_ImportOrder_ValidationDiagnostics(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	body := `{"lines":[{"plu":"1","qty":"zero"}]}`
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/orders/import", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.ImportOrder(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	var response models.ValidationErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Errors, 2)
+	assert.Equal(t, "TicketNo", response.Errors[0].Field)
+	assert.Equal(t, "Lines[0].Qty", response.Errors[1].Field)
+
+	mockOrderService.AssertNotCalled(t, "ImportPOSOrder")
+}
+
+func TestOrderHandler_ImportOrder_UnsupportedFormat(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/orders/import", bytes.NewBufferString("TICKET|1|2"))
+	c.Request.Header.Set("Content-Type", "text/plain")
+
+	// Execute
+	handler.ImportOrder(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	mockOrderService.AssertNotCalled(t, "ImportPOSOrder")
+}
+
+func TestOrderHandler_ListOrders_WithSort(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	sort := []models.SortField{{Field: "total", Desc: true}, {Field: "createdAt"}}
+	mockOrderService.On("ListOrdersPaginated", 10, 0, sort, models.OrderFilter{}).Return([]models.Order{}, 0, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/orders?sort=-total,createdAt", nil)
+
+	// Execute
+	handler.ListOrders(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestOrderHandler_ListOrders_Cursor(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	sort := []models.SortField{{Field: "total", Desc: true}}
+	orders := []models.Order{{ID: "order-2", Total: 12.5}, {ID: "order-1", Total: 9}}
+	mockOrderService.On("ListOrdersAfter", "page-1", 2, sort, models.OrderFilter{}).Return(orders, "page-2", nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/orders?after=page-1&limit=2&sort=-total", nil)
+
+	// Execute
+	handler.ListOrders(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.NotContains(t, response, "pagination", "cursor pages are not counted")
+	assert.Equal(t, map[string]interface{}{"limit": float64(2), "nextCursor": "page-2"}, response["cursor"])
+	assert.Contains(t, w.Header().Get("Link"), `</api/v1/orders?after=page-2&limit=2&sort=-total>; rel="next"`)
+	mockOrderService.AssertNotCalled(t, "ListOrdersPaginated")
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestOrderHandler_ListOrders_InvalidCursor(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	mockOrderService.On("ListOrdersAfter", "garbage", 10, []models.SortField(nil), models.OrderFilter{}).Return([]models.Order(nil), "", service.ErrInvalidCursor)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/orders?after=garbage", nil)
+
+	// Execute
+	handler.ListOrders(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestOrderHandler_ListOrders_InvalidSort(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/orders?sort=price", nil)
+
+	// Execute
+	handler.ListOrders(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockOrderService.AssertNotCalled(t, "ListOrdersPaginated")
+}
+
+func TestOrderHandler_CreateOrder_BlocksRepeatedInvalidPromoCodes(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	guard := couponguard.New(couponguard.Config{MaxFailures: 2, Window: time.Minute, Cooldown: time.Minute}, couponguard.NewMemoryStore())
+	handler := NewOrderHandler(mockOrderService, mockPromoService, guard)
+
+	orderReq := models.OrderReq{
+		CouponCode: "GUESS123",
+		Items:      []models.OrderItem{{ProductID: "1", Quantity: 1}},
+	}
+	mockPromoService.On("ValidatePromoCode", "GUESS123").Return(models.PromoCode{}, false, nil)
+
+	post := func() *httptest.ResponseRecorder {
+		body, _ := json.Marshal(orderReq)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/orders", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.RemoteAddr = "192.0.2.1:1234"
+		handler.CreateOrder(ginx.New(c))
+		return w
+	}
+
+	// Execute
+	first := post()
+	second := post()
+	third := post()
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, first.Code)
+	assert.Equal(t, http.StatusTooManyRequests, second.Code)
+	assert.Equal(t, "60", second.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusTooManyRequests, third.Code)
+	mockPromoService.AssertNumberOfCalls(t, "ValidatePromoCode", 2)
+	mockOrderService.AssertNotCalled(t, "CreateOrder", mock.Anything)
+}
+
+func TestOrderHandler_CreateOrder_PromoCodeUnavailable(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	mockPromoService := new(MockPromoCodeService)
+	guard := couponguard.New(couponguard.Config{MaxFailures: 1, Window: time.Minute, Cooldown: time.Minute}, couponguard.NewMemoryStore())
+	handler := NewOrderHandler(mockOrderService, mockPromoService, guard)
+
+	orderReq := models.OrderReq{
+		CouponCode: "HAPPYHRS",
+		Items:      []models.OrderItem{{ProductID: "1", Quantity: 1}},
+	}
+	mockPromoService.On("ValidatePromoCode", "HAPPYHRS").Return(models.PromoCode{}, false, service.ErrPromoCodeUnavailable)
+
+	post := func() *httptest.ResponseRecorder {
+		body, _ := json.Marshal(orderReq)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/v1/orders", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.RemoteAddr = "192.0.2.1:1234"
+		handler.CreateOrder(ginx.New(c))
+		return w
+	}
+
+	// Execute
+	first := post()
+	second := post()
+
+	// Assert: a degraded database does not count against the client
+	assert.Equal(t, http.StatusServiceUnavailable, first.Code)
+	assert.Equal(t, "30", first.Header().Get("Retry-After"))
+	assert.Contains(t, first.Body.String(), "without one")
+	assert.Equal(t, http.StatusServiceUnavailable, second.Code)
+	mockOrderService.AssertNotCalled(t, "CreateOrder", mock.Anything)
+}
+
+func TestOrderHandler_UpdateOrderStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		serviceErr error
+		wantStatus int
+	}{
+		{name: "success", body: `{"status":"confirmed"}`, wantStatus: http.StatusOK},
+		{name: "missing status", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown status", body: `{"status":"shipped"}`, serviceErr: service.ErrInvalidOrderStatus, wantStatus: http.StatusUnprocessableEntity},
+		{name: "not found", body: `{"status":"confirmed"}`, serviceErr: service.ErrOrderNotFound, wantStatus: http.StatusNotFound},
+		{name: "invalid transition", body: `{"status":"confirmed"}`, serviceErr: service.ErrInvalidStatusTransition, wantStatus: http.StatusConflict},
+		{name: "concurrent change", body: `{"status":"confirmed"}`, serviceErr: service.ErrOrderStatusConflict, wantStatus: http.StatusConflict},
+		{name: "database error", body: `{"status":"confirmed"}`, serviceErr: errors.New("database error"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockOrderService := new(MockOrderService)
+			handler := NewOrderHandler(mockOrderService, new(MockPromoCodeService), nil)
+
+			var req models.OrderStatusReq
+			_ = json.Unmarshal([]byte(tt.body), &req)
+			mockOrderService.On("UpdateOrderStatus", "order-1", req.Status).
+				Return(models.Order{ID: "order-1", Status: req.Status}, tt.serviceErr)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("PATCH", "/api/v1/orders/order-1/status", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "orderId", Value: "order-1"}}
+
+			// Execute
+			handler.UpdateOrderStatus(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"status":"confirmed"`)
+			}
+		})
+	}
+}
+
+func TestOrderHandler_ListOrders_NDJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		orders     []models.Order
+		err        error
+		wantStatus int
+		wantLines  []string
+	}{
+		{
+			name:       "every order",
+			orders:     []models.Order{{ID: "order-1", Status: models.OrderStatusPending}, {ID: "order-2", Status: models.OrderStatusCompleted}},
+			wantStatus: http.StatusOK,
+			wantLines:  []string{`"id":"order-1"`, `"id":"order-2"`},
+		},
+		{
+			name:       "no orders",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "failure before the first order",
+			err:        errors.New("database down"),
+			wantStatus: http.StatusInternalServerError,
+			wantLines:  []string{`"message":"Failed to fetch orders"`},
+		},
+		{
+			name:       "failure midway",
+			orders:     []models.Order{{ID: "order-1"}},
+			err:        errors.New("database down"),
+			wantStatus: http.StatusOK,
+			wantLines:  []string{`"id":"order-1"`, `"message":"Failed to fetch orders"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockOrderService := new(MockOrderService)
+			mockPromoService := new(MockPromoCodeService)
+			handler := NewOrderHandler(mockOrderService, mockPromoService, nil)
+			mockOrderService.On("StreamOrders", noSort, models.OrderFilter{Status: models.OrderStatusPending}).Return(tt.orders, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/orders?status=pending", nil)
+			c.Request.Header.Set("Accept", "application/x-ndjson")
+
+			// Execute
+			handler.ListOrders(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+			if len(tt.wantLines) == 0 {
+				assert.Empty(t, w.Body.String())
+			} else {
+				assert.Len(t, lines, len(tt.wantLines))
+				for i, want := range tt.wantLines {
+					assert.Contains(t, lines[i], want)
+				}
+			}
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+			}
+			mockOrderService.AssertNotCalled(t, "ListOrdersPaginated")
+			mockOrderService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+)
+
+// OrderVolumeHandler handles order volume HTTP requests
+type OrderVolumeHandler struct {
+	service service.OrderVolumeServiceInterface
+}
+
+// NewOrderVolumeHandler creates a new order volume handler
+func NewOrderVolumeHandler(service service.OrderVolumeServiceInterface) *OrderVolumeHandler {
+	return &OrderVolumeHandler{service: service}
+}
+
+// GetOrderVolume handles GET /admin/order-volume
+// @Summary Current vs expected order volume
+// @Description Orders placed in the latest window against the median of the same window on previous days. Status is drop when far fewer orders arrive than expected, and quiet when too few are expected to judge. Served from the latest check of this replica unless refresh is set.
+// @Tags admin
+// @Produce json
+// @Param refresh query bool false "Count the orders now instead of returning the latest check"
+// @Success 200 {object} models.OrderVolume
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/order-volume [get]
+func (h *OrderVolumeHandler) GetOrderVolume(c httpx.Context) {
+	check := h.service.Current
+	if c.Query("refresh") == "true" {
+		check = h.service.Check
+	}
+
+	volume, err := check(c.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to check order volume"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data: volume,
+		Links: []models.Link{
+			{Href: "/api/v1/admin/order-volume", Rel: "self", Method: "GET"},
+			{Href: "/api/v1/admin/order-volume?refresh=true", Rel: "refresh", Method: "GET"},
+		},
+	})
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOrderVolumeService is a mock implementation of OrderVolumeServiceInterface
+type MockOrderVolumeService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.OrderVolumeServiceInterface = (*MockOrderVolumeService)(nil)
+
+func (m *MockOrderVolumeService) Current(ctx context.Context) (models.OrderVolume, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(models.OrderVolume), args.Error(1)
+}
+
+func (m *MockOrderVolumeService) Check(ctx context.Context) (models.OrderVolume, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(models.OrderVolume), args.Error(1)
+}
+
+func TestOrderVolumeHandler_GetOrderVolume(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		method     string
+		err        error
+		wantStatus int
+	}{
+		{name: "latest check", method: "Current", wantStatus: http.StatusOK},
+		{name: "refresh", query: "?refresh=true", method: "Check", wantStatus: http.StatusOK},
+		{name: "database error", method: "Current", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockOrderVolumeService)
+			handler := NewOrderVolumeHandler(mockService)
+			mockService.On(tt.method, mock.Anything).
+				Return(models.OrderVolume{Status: models.OrderVolumeDrop, CurrentOrders: 5, ExpectedOrders: 49}, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/admin/order-volume"+tt.query, nil)
+
+			// Execute
+			handler.GetOrderVolume(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"status":"drop"`)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
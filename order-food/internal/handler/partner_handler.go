@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// partnerStatuses lists the values accepted by the status filter on partner listings
+var partnerStatuses = []string{
+	models.PartnerStatusPending,
+	models.PartnerStatusApproved,
+	models.PartnerStatusRejected,
+	models.PartnerStatusSuspended,
+}
+
+// PartnerHandler handles partner onboarding HTTP requests
+type PartnerHandler struct {
+	service service.PartnerServiceInterface
+}
+
+// NewPartnerHandler creates a new partner handler
+func NewPartnerHandler(service service.PartnerServiceInterface) *PartnerHandler {
+	return &PartnerHandler{service: service}
+}
+
+// RegisterPartner handles POST /partners
+// @Summary Register as a partner
+// @Description Create a partner pending admin approval and issue its API key. The key is only returned in this response.
+// @Tags partner
+// @Accept json
+// @Produce json
+// @Param partner body models.PartnerReq true "Partner registration"
+// @Success 201 {object} models.PartnerRegistration
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Router /api/v1/partners [post]
+func (h *PartnerHandler) RegisterPartner(c httpx.Context) {
+	var req models.PartnerReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	registration, err := h.service.RegisterPartner(req)
+	if errors.Is(err, service.ErrInvalidScope) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to register partner"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.HATEOASResponse{
+		Data: registration,
+		Links: []models.Link{
+			{Href: fmt.Sprintf("/api/v1/partners/%s/keys/rotate", registration.Partner.ID), Rel: "rotate-key", Method: "POST"},
+		},
+	})
+}
+
+// RotateOwnKey handles POST /partners/:partnerId/keys/rotate for a partner
+// rotating its own key
+// @Summary Rotate own partner API key
+// @Description Issue a new API key. Previous keys keep working for a grace period.
+// @Tags partner
+// @Produce json
+// @Param partnerId path string true "Partner ID"
+// @Success 201 {object} models.IssuedAPIKey
+// @Failure 403 {object} models.APIResponse "Forbidden"
+// @Failure 409 {object} models.APIResponse "Partner not approved"
+// @Security ApiKeyAuth
+// @Router /api/v1/partners/{partnerId}/keys/rotate [post]
+func (h *PartnerHandler) RotateOwnKey(c httpx.Context) {
+	partnerID := c.Param("partnerId")
+	if utils.PrincipalFromContext(c) != utils.PartnerPrincipal(partnerID) {
+		c.JSON(http.StatusForbidden, models.ErrorResponse(http.StatusForbidden, "Forbidden: partners may only rotate their own keys"))
+		return
+	}
+
+	h.rotateKey(c, partnerID)
+}
+
+// RotatePartnerKey handles POST /admin/partners/:partnerId/keys/rotate
+// @Summary Rotate a partner API key
+// @Tags admin
+// @Produce json
+// @Param partnerId path string true "Partner ID"
+// @Success 201 {object} models.IssuedAPIKey
+// @Failure 404 {object} models.APIResponse "Partner not found"
+// @Failure 409 {object} models.APIResponse "Partner not approved"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/partners/{partnerId}/keys/rotate [post]
+func (h *PartnerHandler) RotatePartnerKey(c httpx.Context) {
+	h.rotateKey(c, c.Param("partnerId"))
+}
+
+func (h *PartnerHandler) rotateKey(c httpx.Context, partnerID string) {
+	key, err := h.service.RotatePartnerKey(partnerID)
+	if err != nil {
+		writePartnerError(c, err, "Failed to rotate partner API key")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.HATEOASResponse{
+		Data: key,
+		Links: []models.Link{
+			{Href: fmt.Sprintf("/api/v1/partners/%s/keys/rotate", partnerID), Rel: "rotate-key", Method: "POST"},
+		},
+	})
+}
+
+// ListPartners handles GET /admin/partners with an optional status filter
+// @Summary List partners
+// @Tags admin
+// @Produce json
+// @Param status query string false "Filter by approval status"
+// @Param page query int false "Page number"
+// @Param perPage query int false "Items per page"
+// @Success 200 {object} models.PaginatedResponse
+// @Failure 400 {object} models.APIResponse "Invalid status"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/partners [get]
+func (h *PartnerHandler) ListPartners(c httpx.Context) {
+	p := utils.PaginationFromContext(c)
+
+	status := c.Query("status")
+	if status != "" && !containsString(partnerStatuses, status) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, fmt.Sprintf("Invalid status %q", status)))
+		return
+	}
+
+	partners, total, err := h.service.ListPartners(status, p.PerPage, p.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch partners"))
+		return
+	}
+
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	totalPages := utils.TotalPages(total, p.PerPage)
+
+	response := models.PaginatedResponse{
+		Data: partners,
+		Pagination: models.PaginationMeta{
+			Page:       p.Page,
+			PerPage:    p.PerPage,
+			TotalPages: totalPages,
+			TotalItems: total,
+		},
+		Links: utils.BuildPaginationLinksWithQuery(p.Page, totalPages, "/api/v1/admin/partners", p.PerPage, query),
+	}
+
+	utils.SetLinkHeader(c, response.Links)
+	c.JSON(http.StatusOK, response)
+}
+
+// ApprovePartner handles POST /admin/partners/:partnerId/approve
+// @Summary Approve a partner
+// @Tags admin
+// @Produce json
+// @Param partnerId path string true "Partner ID"
+// @Success 200 {object} models.Partner
+// @Failure 404 {object} models.APIResponse "Partner not found"
+// @Failure 409 {object} models.APIResponse "Invalid status transition"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/partners/{partnerId}/approve [post]
+func (h *PartnerHandler) ApprovePartner(c httpx.Context) {
+	h.setStatus(c, models.PartnerStatusApproved)
+}
+
+// RejectPartner handles POST /admin/partners/:partnerId/reject
+// @Summary Reject a pending partner
+// @Tags admin
+// @Produce json
+// @Param partnerId path string true "Partner ID"
+// @Success 200 {object} models.Partner
+// @Failure 404 {object} models.APIResponse "Partner not found"
+// @Failure 409 {object} models.APIResponse "Invalid status transition"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/partners/{partnerId}/reject [post]
+func (h *PartnerHandler) RejectPartner(c httpx.Context) {
+	h.setStatus(c, models.PartnerStatusRejected)
+}
+
+// SuspendPartner handles POST /admin/partners/:partnerId/suspend
+// @Summary Suspend an approved partner
+// @Tags admin
+// @Produce json
+// @Param partnerId path string true "Partner ID"
+// @Success 200 {object} models.Partner
+// @Failure 404 {object} models.APIResponse "Partner not found"
+// @Failure 409 {object} models.APIResponse "Invalid status transition"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/partners/{partnerId}/suspend [post]
+func (h *PartnerHandler) SuspendPartner(c httpx.Context) {
+	h.setStatus(c, models.PartnerStatusSuspended)
+}
+
+func (h *PartnerHandler) setStatus(c httpx.Context, status string) {
+	partner, err := h.service.SetPartnerStatus(c.Param("partnerId"), status)
+	if err != nil {
+		writePartnerError(c, err, "Failed to update partner")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data: partner,
+		Links: []models.Link{
+			{Href: "/api/v1/admin/partners", Rel: "collection", Method: "GET"},
+		},
+	})
+}
+
+// writePartnerError maps partner service errors to HTTP responses
+func writePartnerError(c httpx.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrPartnerNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Partner not found"))
+	case errors.Is(err, service.ErrInvalidPartnerTransition), errors.Is(err, service.ErrPartnerNotApproved):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, fallback))
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPartnerService is a mock implementation of PartnerServiceInterface
+type MockPartnerService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.PartnerServiceInterface = (*MockPartnerService)(nil)
+
+func (m *MockPartnerService) RegisterPartner(req models.PartnerReq) (models.PartnerRegistration, error) {
+	args := m.Called(req)
+	return args.Get(0).(models.PartnerRegistration), args.Error(1)
+}
+
+func (m *MockPartnerService) ListPartners(status string, limit, offset int) ([]models.Partner, int, error) {
+	args := m.Called(status, limit, offset)
+	return args.Get(0).([]models.Partner), args.Int(1), args.Error(2)
+}
+
+func (m *MockPartnerService) SetPartnerStatus(id, status string) (models.Partner, error) {
+	args := m.Called(id, status)
+	return args.Get(0).(models.Partner), args.Error(1)
+}
+
+func (m *MockPartnerService) RotatePartnerKey(id string) (models.IssuedAPIKey, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.IssuedAPIKey), args.Error(1)
+}
+
+func TestPartnerHandler_RegisterPartner_Success(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPartnerService)
+	handler := NewPartnerHandler(mockService)
+
+	req := models.PartnerReq{Name: "Acme", ContactEmail: "ops@acme.test", Scopes: []string{"orders:read"}}
+	registration := models.PartnerRegistration{
+		Partner: models.Partner{ID: "p1", Name: "Acme", Status: models.PartnerStatusPending, Scopes: req.Scopes},
+		APIKey:  models.IssuedAPIKey{Key: "pk_secret", Prefix: "pk_secret", PartnerID: "p1"},
+	}
+	mockService.On("RegisterPartner", req).Return(registration, nil)
+
+	// Create request
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/partners", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.RegisterPartner(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "pk_secret")
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestPartnerHandler_RegisterPartner_InvalidScope(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPartnerService)
+	handler := NewPartnerHandler(mockService)
+
+	req := models.PartnerReq{Name: "Acme", ContactEmail: "ops@acme.test", Scopes: []string{"admin"}}
+	mockService.On("RegisterPartner", req).
+		Return(models.PartnerRegistration{}, fmt.Errorf("%w: %q", service.ErrInvalidScope, "admin"))
+
+	// Create request
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/partners", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.RegisterPartner(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid scope")
+}
+
+func TestPartnerHandler_RegisterPartner_MissingFields(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPartnerService)
+	handler := NewPartnerHandler(mockService)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/partners", bytes.NewBufferString(`{"name":"Acme"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.RegisterPartner(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "RegisterPartner", mock.Anything)
+}
+
+func TestPartnerHandler_ApprovePartner(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "approved", err: nil, want: http.StatusOK},
+		{name: "not found", err: service.ErrPartnerNotFound, want: http.StatusNotFound},
+		{name: "invalid transition", err: service.ErrInvalidPartnerTransition, want: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockPartnerService)
+			handler := NewPartnerHandler(mockService)
+			mockService.On("SetPartnerStatus", "p1", models.PartnerStatusApproved).
+				Return(models.Partner{ID: "p1", Status: models.PartnerStatusApproved}, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/admin/partners/p1/approve", nil)
+			c.Params = gin.Params{{Key: "partnerId", Value: "p1"}}
+
+			// Execute
+			handler.ApprovePartner(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.want, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPartnerHandler_RotateOwnKey(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPartnerService)
+	handler := NewPartnerHandler(mockService)
+	mockService.On("RotatePartnerKey", "p1").Return(models.IssuedAPIKey{Key: "pk_new", PartnerID: "p1"}, nil)
+
+	// Create request authenticated as partner p1
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/partners/p1/keys/rotate", nil)
+	c.Params = gin.Params{{Key: "partnerId", Value: "p1"}}
+	utils.SetPrincipal(c, utils.PartnerPrincipal("p1"), []string{"orders:read"})
+
+	// Execute
+	handler.RotateOwnKey(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), "pk_new")
+	mockService.AssertExpectations(t)
+}
+
+func TestPartnerHandler_RotateOwnKey_OtherPartner(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPartnerService)
+	handler := NewPartnerHandler(mockService)
+
+	// Create request authenticated as partner p2
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/partners/p1/keys/rotate", nil)
+	c.Params = gin.Params{{Key: "partnerId", Value: "p1"}}
+	utils.SetPrincipal(c, utils.PartnerPrincipal("p2"), []string{"orders:read"})
+
+	// Execute
+	handler.RotateOwnKey(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertNotCalled(t, "RotatePartnerKey", mock.Anything)
+}
+
+func TestPartnerHandler_ListPartners_InvalidStatus(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPartnerService)
+	handler := NewPartnerHandler(mockService)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/partners?status=bogus", nil)
+
+	// Execute
+	handler.ListPartners(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "ListPartners", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPartnerHandler_ListPartners_FilteredByStatus(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPartnerService)
+	handler := NewPartnerHandler(mockService)
+	partners := []models.Partner{{ID: "p1", Status: models.PartnerStatusPending}}
+	mockService.On("ListPartners", models.PartnerStatusPending, 10, 0).Return(partners, 1, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/partners?status=pending", nil)
+
+	// Execute
+	handler.ListPartners(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "status=pending")
+	mockService.AssertExpectations(t)
+}
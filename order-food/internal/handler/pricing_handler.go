@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// PricingHandler handles admin price management HTTP requests
+type PricingHandler struct {
+	service service.PricingServiceInterface
+}
+
+// NewPricingHandler creates a new pricing handler
+func NewPricingHandler(service service.PricingServiceInterface) *PricingHandler {
+	return &PricingHandler{service: service}
+}
+
+// BulkUpdatePrices handles POST /admin/products/bulk-price
+// @Summary Bulk update product prices
+// @Description Preview the price changes of a set of rules, e.g. +5% for category Waffle. With apply set the changes are committed in one transaction with an audit log entry, and every replica drops the affected products from its cache.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param rules body models.BulkPriceReq true "Price rules"
+// @Success 200 {object} models.BulkPriceResult
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 409 {object} models.APIResponse "Prices changed concurrently"
+// @Failure 422 {object} models.ValidationErrorResponse "Invalid rules"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/products/bulk-price [post]
+func (h *PricingHandler) BulkUpdatePrices(c httpx.Context) {
+	var req models.BulkPriceReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	result, err := h.service.BulkUpdatePrices(c.Context(), req, utils.PrincipalFromContext(c))
+	var ruleErr *service.PriceRuleError
+	switch {
+	case errors.As(err, &ruleErr):
+		c.JSON(http.StatusUnprocessableEntity, models.ValidationError(http.StatusUnprocessableEntity, "Price rules failed validation", ruleErr.Diagnostics))
+		return
+	case errors.Is(err, repository.ErrPriceConflict):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Prices changed while applying; preview again and retry"))
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to update prices"))
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
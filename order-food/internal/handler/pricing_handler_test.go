@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPricingService is a mock implementation of PricingServiceInterface
+type MockPricingService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.PricingServiceInterface = (*MockPricingService)(nil)
+
+func (m *MockPricingService) BulkUpdatePrices(ctx context.Context, req models.BulkPriceReq, actor string) (models.BulkPriceResult, error) {
+	args := m.Called(req, actor)
+	return args.Get(0).(models.BulkPriceResult), args.Error(1)
+}
+
+func TestPricingHandler_BulkUpdatePrices(t *testing.T) {
+	req := models.BulkPriceReq{Rules: []models.PriceRule{{Category: "Waffle", Percent: 5}}, Apply: true}
+
+	tests := []struct {
+		name       string
+		result     models.BulkPriceResult
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "applied",
+			result:     models.BulkPriceResult{Applied: true, AuditID: 7, Changes: []models.PriceChange{{ProductID: "1", OldPrice: 6.5, NewPrice: 6.83}}},
+			wantStatus: http.StatusOK,
+			wantBody:   `"auditId":7`,
+		},
+		{
+			name:       "invalid rules",
+			err:        &service.PriceRuleError{Diagnostics: []models.FieldError{{Field: "rules[0]", Message: "category or productIds is required"}}},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `"field":"rules[0]"`,
+		},
+		{
+			name:       "conflict",
+			err:        fmt.Errorf("%w: 1", repository.ErrPriceConflict),
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockPricingService)
+			handler := NewPricingHandler(mockService)
+			mockService.On("BulkUpdatePrices", req, "admin").Return(tt.result, tt.err)
+
+			// Create request
+			body, _ := json.Marshal(req)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/admin/products/bulk-price", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			utils.SetPrincipal(c, "admin", []string{utils.ScopeAll})
+
+			// Execute
+			handler.BulkUpdatePrices(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPricingHandler_BulkUpdatePrices_InvalidBody(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	handler := NewPricingHandler(new(MockPricingService))
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/admin/products/bulk-price", bytes.NewBufferString(`{"rules":[]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.BulkUpdatePrices(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -1,15 +1,29 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
-	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
 )
 
+// maxBarcodeLength matches the width of the products.barcode column
+const maxBarcodeLength = 32
+
+// maxSearchQueryLength bounds the text of a product search
+const maxSearchQueryLength = 200
+
+// productSortFields lists the fields accepted by the sort query parameter on product listings
+var productSortFields = []string{"id", "name", "price", "category"}
+
 // ProductHandler handles product-related HTTP requests
 type ProductHandler struct {
 	service service.ProductServiceInterface
@@ -20,71 +34,159 @@ func NewProductHandler(service service.ProductServiceInterface) *ProductHandler
 	return &ProductHandler{service: service}
 }
 
-// ListProducts handles GET /product with pagination and HATEOAS
+// ListProducts handles GET /products with pagination and HATEOAS, or
+// streams every product as NDJSON when the Accept header asks for it
 // @Summary List products
-// @Description Get all products available for order
+// @Description Get all products available for order. With "Accept: application/x-ndjson" every matching product is streamed, one per line, without pagination.
 // @Tags product
-// @Produce json
+// @Produce json,application/x-ndjson
+// @Param sort query string false "Comma-separated sort fields, prefix with - for descending (id, name, price, category)"
+// @Param after query string false "Cursor of the page to return, from the previous page's nextCursor"
+// @Param limit query int false "Products per page when paginating by cursor"
+// @Param category query string false "Only products of this category"
+// @Param minPrice query number false "Only products costing at least this many dollars"
+// @Param maxPrice query number false "Only products costing at most this many dollars"
+// @Param Accept-Language header string false "Preferred languages of product names and descriptions"
 // @Success 200 {array} models.Product
-// @Router /product [get]
-func (h *ProductHandler) ListProducts(c *gin.Context) {
-	// Parse pagination parameters
-	page := utils.ParseInt(c.Query("page"), 1)
-	perPage := utils.ParseInt(c.Query("perPage"), 10)
+// @Failure 400 {object} models.APIResponse "Invalid sort expression or filter"
+// @Router /api/v1/products [get]
+func (h *ProductHandler) ListProducts(c httpx.Context) {
+	// Pagination parameters are parsed and capped by the pagination middleware
+	p := utils.PaginationFromContext(c)
 
-	// Calculate offset
-	offset := (page - 1) * perPage
+	sort, err := utils.ParseSort(c.Query("sort"), productSortFields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	if wantsNDJSON(c) {
+		languages := utils.LanguagesFromContext(c)
+		c.Header("Vary", "Accept, Accept-Language")
+		streamNDJSON(c, "Failed to fetch products", func(emit func(any) error) error {
+			return h.service.StreamProducts(c.Context(), sort, filter, func(product models.Product) error {
+				return emit(utils.LocalizeProduct(product, languages))
+			})
+		})
+		return
+	}
+	if p.Cursor {
+		h.listProductsAfter(c, p, sort, filter)
+		return
+	}
 
 	// Get paginated products
-	products, total, err := h.service.ListProductsPaginated(perPage, offset)
+	products, total, err := h.service.ListProductsPaginated(p.PerPage, p.Offset, sort, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch products"))
 		return
 	}
 
-	// Add HATEOAS links to each product
-	productsWithLinks := make([]models.ProductWithLinks, len(products))
-	for i, product := range products {
-		productsWithLinks[i] = models.ProductWithLinks{
-			Product: product,
-			Links: []models.Link{
-				{Href: fmt.Sprintf("/api/v1/products/%s", product.ID), Rel: "self", Method: "GET"},
-				{Href: "/api/v1/products", Rel: "collection", Method: "GET"},
-			},
-		}
+	// Build pagination response
+	totalPages := utils.TotalPages(total, p.PerPage)
+
+	response := models.PaginatedResponse{
+		Data: productsWithLinks(c, products),
+		Pagination: models.PaginationMeta{
+			Page:       p.Page,
+			PerPage:    p.PerPage,
+			TotalPages: totalPages,
+			TotalItems: total,
+		},
+		Links: utils.BuildPaginationLinksWithQuery(p.Page, totalPages, "/api/v1/products", p.PerPage, productListQuery(sort, filter)),
 	}
 
-	// Build pagination response
-	totalPages := (total + perPage - 1) / perPage
-	if totalPages == 0 {
-		totalPages = 1
+	utils.SetLinkHeader(c, response.Links)
+	c.Header("Vary", "Accept, Accept-Language")
+	c.JSON(http.StatusOK, response)
+}
+
+// listProductsAfter answers a ListProducts request for the page after a
+// cursor
+func (h *ProductHandler) listProductsAfter(c httpx.Context, p utils.Pagination, sort []models.SortField, filter models.ProductFilter) {
+	products, next, err := h.service.ListProductsAfter(p.After, p.PerPage, sort, filter)
+	if errors.Is(err, service.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid cursor; cursors are only valid with the sort they were issued for"))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch products"))
+		return
+	}
+
+	response := models.PaginatedResponse{
+		Data:   productsWithLinks(c, products),
+		Cursor: models.CursorMeta{Limit: p.PerPage, NextCursor: next},
+		Links:  utils.BuildCursorLinks(p, next, "/api/v1/products", productListQuery(sort, filter)),
 	}
 
+	utils.SetLinkHeader(c, response.Links)
+	c.Header("Vary", "Accept, Accept-Language")
+	c.JSON(http.StatusOK, response)
+}
+
+// SearchProducts handles GET /products/search with pagination and HATEOAS
+// @Summary Search products
+// @Description Full-text search over product names, categories and descriptions, best match first
+// @Tags product
+// @Produce json
+// @Param q query string true "Search text; supports quoted phrases, or, and -word to exclude a word"
+// @Param Accept-Language header string false "Preferred languages of product names and descriptions"
+// @Success 200 {array} models.Product
+// @Failure 400 {object} models.APIResponse "Missing or too long search text"
+// @Router /api/v1/products/search [get]
+func (h *ProductHandler) SearchProducts(c httpx.Context) {
+	p := utils.PaginationFromContext(c)
+
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" || len(text) > maxSearchQueryLength {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest,
+			fmt.Sprintf("q must be between 1 and %d characters", maxSearchQueryLength)))
+		return
+	}
+
+	products, total, err := h.service.SearchProducts(text, p.PerPage, p.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to search products"))
+		return
+	}
+
+	totalPages := utils.TotalPages(total, p.PerPage)
+
 	response := models.PaginatedResponse{
-		Data: productsWithLinks,
+		Data: productsWithLinks(c, products),
 		Pagination: models.PaginationMeta{
-			Page:       page,
-			PerPage:    perPage,
+			Page:       p.Page,
+			PerPage:    p.PerPage,
 			TotalPages: totalPages,
 			TotalItems: total,
 		},
-		Links: utils.BuildPaginationLinks(page, totalPages, "/api/v1/products", perPage),
+		Links: utils.BuildPaginationLinksWithQuery(p.Page, totalPages, "/api/v1/products/search", p.PerPage, url.Values{"q": {text}}),
 	}
 
+	utils.SetLinkHeader(c, response.Links)
+	c.Header("Vary", "Accept-Language")
 	c.JSON(http.StatusOK, response)
 }
 
-// GetProduct handles GET /product/:productId with HATEOAS
+// GetProduct handles GET /products/:productId with HATEOAS
 // @Summary Find product by ID
 // @Description Returns a single product
 // @Tags product
 // @Produce json
 // @Param productId path int true "ID of product to return"
+// @Param Accept-Language header string false "Preferred languages of product names and descriptions"
 // @Success 200 {object} models.Product
 // @Failure 400 {object} models.APIResponse "Invalid ID supplied"
 // @Failure 404 {object} models.APIResponse "Product not found"
-// @Router /product/{productId} [get]
-func (h *ProductHandler) GetProduct(c *gin.Context) {
+// @Router /api/v1/products/{productId} [get]
+func (h *ProductHandler) GetProduct(c httpx.Context) {
 	productID := c.Param("productId")
 
 	if productID == "" {
@@ -97,6 +199,7 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Product not found"))
 		return
 	}
+	product = localizeProduct(c, product)
 
 	response := models.HATEOASResponse{
 		Data: product,
@@ -108,3 +211,269 @@ func (h *ProductHandler) GetProduct(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// GetProductByBarcode handles GET /products/by-barcode/:code with HATEOAS
+// @Summary Find product by barcode
+// @Description Resolves a scanned EAN/UPC barcode to a single product
+// @Tags product
+// @Produce json
+// @Param code path string true "Barcode of product to return"
+// @Param Accept-Language header string false "Preferred languages of product names and descriptions"
+// @Success 200 {object} models.Product
+// @Failure 400 {object} models.APIResponse "Invalid barcode supplied"
+// @Failure 404 {object} models.APIResponse "Product not found"
+// @Router /api/v1/products/by-barcode/{code} [get]
+func (h *ProductHandler) GetProductByBarcode(c httpx.Context) {
+	code := strings.TrimSpace(c.Param("code"))
+
+	if code == "" || len(code) > maxBarcodeLength {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid barcode supplied"))
+		return
+	}
+
+	product, err := h.service.GetProductByBarcode(code)
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Product not found"))
+		return
+	}
+	product = localizeProduct(c, product)
+
+	response := models.HATEOASResponse{
+		Data: product,
+		Links: []models.Link{
+			{Href: fmt.Sprintf("/api/v1/products/by-barcode/%s", code), Rel: "self", Method: "GET"},
+			{Href: fmt.Sprintf("/api/v1/products/%s", product.ID), Rel: "product", Method: "GET"},
+			{Href: "/api/v1/products", Rel: "collection", Method: "GET"},
+		},
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// parseProductFilter reads the category, minPrice and maxPrice query
+// parameters of a product listing
+func parseProductFilter(c httpx.Context) (models.ProductFilter, error) {
+	filter := models.ProductFilter{Category: strings.TrimSpace(c.Query("category"))}
+	for _, bound := range []struct {
+		name  string
+		price **float64
+	}{{"minPrice", &filter.MinPrice}, {"maxPrice", &filter.MaxPrice}} {
+		value := c.Query(bound.name)
+		if value == "" {
+			continue
+		}
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 || math.IsInf(price, 0) || math.IsNaN(price) {
+			return models.ProductFilter{}, fmt.Errorf("%s must be a non-negative number", bound.name)
+		}
+		*bound.price = &price
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return models.ProductFilter{}, errors.New("minPrice must not be above maxPrice")
+	}
+	return filter, nil
+}
+
+// productListQuery returns the sort and filter parameters to carry over
+// into the pagination links of a product listing
+func productListQuery(sort []models.SortField, filter models.ProductFilter) url.Values {
+	query := utils.SortQuery(sort)
+	if query == nil {
+		query = url.Values{}
+	}
+	if filter.Category != "" {
+		query.Set("category", filter.Category)
+	}
+	if filter.MinPrice != nil {
+		query.Set("minPrice", strconv.FormatFloat(*filter.MinPrice, 'f', -1, 64))
+	}
+	if filter.MaxPrice != nil {
+		query.Set("maxPrice", strconv.FormatFloat(*filter.MaxPrice, 'f', -1, 64))
+	}
+	return query
+}
+
+// CreateProduct handles POST /products
+// @Summary Add a product
+// @Description Add a product to the catalogue. A deleted product can be added again under its old ID.
+// @Tags product
+// @Accept json
+// @Produce json
+// @Param product body models.ProductReq true "Product"
+// @Success 201 {object} models.Product
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 409 {object} models.APIResponse "Product ID, SKU or barcode already in use"
+// @Failure 422 {object} models.APIResponse "Invalid product"
+// @Security AdminKeyAuth
+// @Router /api/v1/products [post]
+func (h *ProductHandler) CreateProduct(c httpx.Context) {
+	var req models.ProductReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	product, err := h.service.CreateProduct(c.Context(), req, utils.PrincipalFromContext(c))
+	if writeProductError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to create product"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.HATEOASResponse{
+		Data:  localizeProduct(c, product),
+		Links: productLinks(product.ID),
+	})
+}
+
+// UpdateProduct handles PUT /products/:productId
+// @Summary Replace a product
+// @Description Replace the name, price, category, SKU, barcode and description of a product. Stock, currency prices, translations and option groups are kept.
+// @Tags product
+// @Accept json
+// @Produce json
+// @Param productId path string true "ID of product to replace"
+// @Param product body models.ProductReq true "Product"
+// @Success 200 {object} models.Product
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 404 {object} models.APIResponse "Product not found"
+// @Failure 409 {object} models.APIResponse "SKU or barcode already in use"
+// @Failure 422 {object} models.APIResponse "Invalid product"
+// @Security AdminKeyAuth
+// @Router /api/v1/products/{productId} [put]
+func (h *ProductHandler) UpdateProduct(c httpx.Context) {
+	var req models.ProductReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	product, err := h.service.UpdateProduct(c.Context(), c.Param("productId"), req, utils.PrincipalFromContext(c))
+	if writeProductError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to update product"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data:  localizeProduct(c, product),
+		Links: productLinks(product.ID),
+	})
+}
+
+// SetOptionGroups handles PUT /products/:productId/option-groups
+// @Summary Replace the option groups of a product
+// @Description Replace the choices offered with a product, such as its size or toppings, in menu order. Order items select between minSelect and maxSelect options of each group, and the prices of the options they select are added to the unit price. Option IDs must be unique across the groups of the product. An empty list removes the option groups; orders already placed keep the modifiers they were priced with.
+// @Tags product
+// @Accept json
+// @Produce json
+// @Param productId path string true "Product ID"
+// @Param optionGroups body models.OptionGroupsReq true "Option groups"
+// @Success 200 {object} models.Product
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 404 {object} models.APIResponse "Product not found"
+// @Failure 422 {object} models.APIResponse "Invalid option groups"
+// @Security AdminKeyAuth
+// @Router /api/v1/products/{productId}/option-groups [put]
+func (h *ProductHandler) SetOptionGroups(c httpx.Context) {
+	var req models.OptionGroupsReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	product, err := h.service.SetOptionGroups(c.Context(), c.Param("productId"), req, utils.PrincipalFromContext(c))
+	if writeProductError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to set option groups"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data:  localizeProduct(c, product),
+		Links: productLinks(product.ID),
+	})
+}
+
+// DeleteProduct handles DELETE /products/:productId
+// @Summary Delete a product
+// @Description Remove a product from the catalogue. It can no longer be ordered; orders placed for it are not changed.
+// @Tags product
+// @Produce json
+// @Param productId path string true "ID of product to delete"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "Product not found"
+// @Security AdminKeyAuth
+// @Router /api/v1/products/{productId} [delete]
+func (h *ProductHandler) DeleteProduct(c httpx.Context) {
+	err := h.service.DeleteProduct(c.Context(), c.Param("productId"), utils.PrincipalFromContext(c))
+	if writeProductError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to delete product"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Product deleted"))
+}
+
+// writeProductError writes the response for a product change refused by
+// the service and reports whether err was such a refusal
+func writeProductError(c httpx.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrInvalidProduct):
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse(http.StatusUnprocessableEntity, err.Error()))
+	case errors.Is(err, service.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Product not found"))
+	case errors.Is(err, service.ErrProductExists):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "A product with this ID already exists"))
+	case errors.Is(err, service.ErrProductIdentifierTaken):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "SKU or barcode belongs to another product"))
+	default:
+		return false
+	}
+	return true
+}
+
+func productLinks(id string) []models.Link {
+	self := fmt.Sprintf("/api/v1/products/%s", id)
+	return []models.Link{
+		{Href: self, Rel: "self", Method: "GET"},
+		{Href: self, Rel: "update", Method: "PUT"},
+		{Href: self, Rel: "delete", Method: "DELETE"},
+		{Href: self + "/option-groups", Rel: "option-groups", Method: "PUT"},
+		{Href: "/api/v1/products", Rel: "collection", Method: "GET"},
+	}
+}
+
+// localizeProduct translates product into the request's preferred language
+// and names the language in the Content-Language header
+func localizeProduct(c httpx.Context, product models.Product) models.Product {
+	product = utils.LocalizeProduct(product, utils.LanguagesFromContext(c))
+	c.Header("Content-Language", product.Language)
+	c.Header("Vary", "Accept-Language")
+	return product
+}
+
+// productsWithLinks localizes products and adds HATEOAS links to each
+func productsWithLinks(c httpx.Context, products []models.Product) []models.ProductWithLinks {
+	languages := utils.LanguagesFromContext(c)
+	withLinks := make([]models.ProductWithLinks, len(products))
+	for i, product := range products {
+		withLinks[i] = models.ProductWithLinks{
+			Product: utils.LocalizeProduct(product, languages),
+			Links: []models.Link{
+				{Href: fmt.Sprintf("/api/v1/products/%s", product.ID), Rel: "self", Method: "GET"},
+				{Href: "/api/v1/products", Rel: "collection", Method: "GET"},
+			},
+		}
+	}
+	return withLinks
+}
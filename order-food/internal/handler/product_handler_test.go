@@ -1,19 +1,29 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+// noSort is the sort argument handlers pass when no sort parameter is given
+var noSort []models.SortField
+
+// noFilter is the filter argument handlers pass when no filter parameter is given
+var noFilter models.ProductFilter
+
 // MockProductService is a mock implementation of ProductServiceInterface
 type MockProductService struct {
 	mock.Mock
@@ -27,8 +37,30 @@ func (m *MockProductService) ListProducts() []models.Product {
 	return args.Get(0).([]models.Product)
 }
 
-func (m *MockProductService) ListProductsPaginated(limit, offset int) ([]models.Product, int, error) {
-	args := m.Called(limit, offset)
+func (m *MockProductService) ListProductsPaginated(limit, offset int, sort []models.SortField, filter models.ProductFilter) ([]models.Product, int, error) {
+	args := m.Called(limit, offset, sort, filter)
+	return args.Get(0).([]models.Product), args.Int(1), args.Error(2)
+}
+
+func (m *MockProductService) ListProductsAfter(after string, limit int, sort []models.SortField, filter models.ProductFilter) ([]models.Product, string, error) {
+	args := m.Called(after, limit, sort, filter)
+	return args.Get(0).([]models.Product), args.String(1), args.Error(2)
+}
+
+// StreamProducts passes the products the mock returns to fn, then returns
+// its error
+func (m *MockProductService) StreamProducts(_ context.Context, sort []models.SortField, filter models.ProductFilter, fn func(models.Product) error) error {
+	args := m.Called(sort, filter)
+	for _, product := range args.Get(0).([]models.Product) {
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockProductService) SearchProducts(query string, limit, offset int) ([]models.Product, int, error) {
+	args := m.Called(query, limit, offset)
 	return args.Get(0).([]models.Product), args.Int(1), args.Error(2)
 }
 
@@ -37,6 +69,31 @@ func (m *MockProductService) GetProduct(id string) (models.Product, error) {
 	return args.Get(0).(models.Product), args.Error(1)
 }
 
+func (m *MockProductService) GetProductByBarcode(barcode string) (models.Product, error) {
+	args := m.Called(barcode)
+	return args.Get(0).(models.Product), args.Error(1)
+}
+
+func (m *MockProductService) CreateProduct(ctx context.Context, req models.ProductReq, actor string) (models.Product, error) {
+	args := m.Called(req, actor)
+	return args.Get(0).(models.Product), args.Error(1)
+}
+
+func (m *MockProductService) UpdateProduct(ctx context.Context, id string, req models.ProductReq, actor string) (models.Product, error) {
+	args := m.Called(id, req, actor)
+	return args.Get(0).(models.Product), args.Error(1)
+}
+
+func (m *MockProductService) SetOptionGroups(ctx context.Context, id string, req models.OptionGroupsReq, actor string) (models.Product, error) {
+	args := m.Called(id, req, actor)
+	return args.Get(0).(models.Product), args.Error(1)
+}
+
+func (m *MockProductService) DeleteProduct(ctx context.Context, id, actor string) error {
+	args := m.Called(id, actor)
+	return args.Error(0)
+}
+
 func TestProductHandler_ListProducts_Success(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -49,7 +106,7 @@ func TestProductHandler_ListProducts_Success(t *testing.T) {
 		{ID: "2", Name: "Beef Waffle", Price: 14.99, Category: "Waffle"},
 	}
 
-	mockService.On("ListProductsPaginated", 10, 0).Return(products, 2, nil)
+	mockService.On("ListProductsPaginated", 10, 0, noSort, noFilter).Return(products, 2, nil)
 
 	// Create request
 	w := httptest.NewRecorder()
@@ -57,7 +114,7 @@ func TestProductHandler_ListProducts_Success(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/api/v1/products?page=1&perPage=10", nil)
 
 	// Execute
-	handler.ListProducts(c)
+	handler.ListProducts(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -74,6 +131,40 @@ func TestProductHandler_ListProducts_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestProductHandler_ListProducts_NDJSON(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+
+	products := []models.Product{
+		{ID: "1", Name: "Chicken Waffle", Translations: map[string]models.ProductTranslation{"fr": {Name: "Gaufre au poulet"}}},
+		{ID: "2", Name: "Beef Waffle"},
+	}
+	mockService.On("StreamProducts", noSort, models.ProductFilter{Category: "Waffle"}).Return(products, nil)
+
+	// Create request for every waffle in French
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/products?category=Waffle&perPage=1", nil)
+	c.Request.Header.Set("Accept", "application/x-ndjson")
+	c.Request.Header.Set("Accept-Language", "fr")
+
+	// Execute
+	handler.ListProducts(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept, Accept-Language", w.Header().Get("Vary"))
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	assert.Len(t, lines, 2, "streams ignore pagination")
+	assert.Contains(t, lines[0], `"name":"Gaufre au poulet"`)
+	assert.Contains(t, lines[1], `"id":"2"`)
+	mockService.AssertNotCalled(t, "ListProductsPaginated")
+	mockService.AssertExpectations(t)
+}
+
 func TestProductHandler_ListProducts_WithCustomPagination(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -85,7 +176,7 @@ func TestProductHandler_ListProducts_WithCustomPagination(t *testing.T) {
 		{ID: "6", Name: "Product 6", Price: 10.99, Category: "Category"},
 	}
 
-	mockService.On("ListProductsPaginated", 5, 5).Return(products, 11, nil)
+	mockService.On("ListProductsPaginated", 5, 5, noSort, noFilter).Return(products, 11, nil)
 
 	// Create request
 	w := httptest.NewRecorder()
@@ -93,7 +184,7 @@ func TestProductHandler_ListProducts_WithCustomPagination(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/api/v1/products?page=2&perPage=5", nil)
 
 	// Execute
-	handler.ListProducts(c)
+	handler.ListProducts(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -106,6 +197,11 @@ func TestProductHandler_ListProducts_WithCustomPagination(t *testing.T) {
 	assert.Equal(t, 5, response.Pagination.PerPage)
 	assert.Equal(t, 3, response.Pagination.TotalPages) // 11 items / 5 per page = 3 pages
 
+	// Pagination links are mirrored into the RFC 8288 Link header
+	linkHeader := w.Header().Get("Link")
+	assert.Contains(t, linkHeader, `</api/v1/products?page=1&perPage=5>; rel="first"`)
+	assert.Contains(t, linkHeader, `</api/v1/products?page=3&perPage=5>; rel="next"`)
+
 	mockService.AssertExpectations(t)
 }
 
@@ -115,7 +211,7 @@ func TestProductHandler_ListProducts_DatabaseError(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := NewProductHandler(mockService)
 
-	mockService.On("ListProductsPaginated", 10, 0).Return([]models.Product{}, 0, errors.New("database error"))
+	mockService.On("ListProductsPaginated", 10, 0, noSort, noFilter).Return([]models.Product{}, 0, errors.New("database error"))
 
 	// Create request
 	w := httptest.NewRecorder()
@@ -123,7 +219,7 @@ func TestProductHandler_ListProducts_DatabaseError(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/api/v1/products", nil)
 
 	// Execute
-	handler.ListProducts(c)
+	handler.ListProducts(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
@@ -160,7 +256,7 @@ func TestProductHandler_GetProduct_Success(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/api/v1/products/1", nil)
 
 	// Execute
-	handler.GetProduct(c)
+	handler.GetProduct(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -178,6 +274,52 @@ func TestProductHandler_GetProduct_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestProductHandler_GetProduct_Localized(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+
+	// Mock data
+	product := models.Product{
+		ID:       "1",
+		Name:     "Chicken Waffle",
+		Price:    12.99,
+		Category: "Waffle",
+		Translations: map[string]models.ProductTranslation{
+			"fr": {Name: "Gaufre au poulet"},
+		},
+	}
+
+	mockService.On("GetProduct", "1").Return(product, nil)
+
+	// Create request preferring Canadian French
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "productId", Value: "1"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/products/1", nil)
+	c.Request.Header.Set("Accept-Language", "fr-CA, en;q=0.5")
+
+	// Execute
+	handler.GetProduct(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "fr", w.Header().Get("Content-Language"))
+	assert.Equal(t, "Accept-Language", w.Header().Get("Vary"))
+
+	var response struct {
+		Data map[string]any `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Gaufre au poulet", response.Data["name"])
+	assert.Equal(t, "fr", response.Data["language"])
+	assert.NotContains(t, response.Data, "Translations")
+
+	mockService.AssertExpectations(t)
+}
+
 func TestProductHandler_GetProduct_NotFound(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
@@ -193,7 +335,7 @@ func TestProductHandler_GetProduct_NotFound(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/api/v1/products/999", nil)
 
 	// Execute
-	handler.GetProduct(c)
+	handler.GetProduct(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusNotFound, w.Code)
@@ -220,7 +362,7 @@ func TestProductHandler_GetProduct_EmptyID(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/api/v1/products/", nil)
 
 	// Execute
-	handler.GetProduct(c)
+	handler.GetProduct(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusBadRequest, w.Code)
@@ -242,7 +384,7 @@ func TestProductHandler_ListProducts_HATEOASLinksPresent(t *testing.T) {
 		{ID: "1", Name: "Product 1", Price: 10.99, Category: "Category"},
 	}
 
-	mockService.On("ListProductsPaginated", 10, 0).Return(products, 1, nil)
+	mockService.On("ListProductsPaginated", 10, 0, noSort, noFilter).Return(products, 1, nil)
 
 	// Create request
 	w := httptest.NewRecorder()
@@ -250,7 +392,7 @@ func TestProductHandler_ListProducts_HATEOASLinksPresent(t *testing.T) {
 	c.Request = httptest.NewRequest("GET", "/api/v1/products", nil)
 
 	// Execute
-	handler.ListProducts(c)
+	handler.ListProducts(ginx.New(c))
 
 	// Assert
 	assert.Equal(t, http.StatusOK, w.Code)
@@ -269,3 +411,468 @@ func TestProductHandler_ListProducts_HATEOASLinksPresent(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestProductHandler_GetProductByBarcode_Success(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+
+	product := models.Product{
+		ID:       "1",
+		Name:     "Chicken Waffle",
+		Price:    12.99,
+		Category: "Waffle",
+		SKU:      "WAF-CHK-001",
+		Barcode:  "9300000000011",
+	}
+
+	mockService.On("GetProductByBarcode", "9300000000011").Return(product, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "code", Value: "9300000000011"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/products/by-barcode/9300000000011", nil)
+
+	// Execute
+	handler.GetProductByBarcode(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.HATEOASResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Len(t, response.Links, 3)
+	assert.Equal(t, "/api/v1/products/1", response.Links[1].Href)
+	assert.Contains(t, w.Body.String(), "WAF-CHK-001")
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetProductByBarcode_NotFound(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+
+	mockService.On("GetProductByBarcode", "0000000000000").Return(models.Product{}, errors.New("not found"))
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "code", Value: "0000000000000"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/products/by-barcode/0000000000000", nil)
+
+	// Execute
+	handler.GetProductByBarcode(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetProductByBarcode_TooLong(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+
+	code := "123456789012345678901234567890123"
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "code", Value: code}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/products/by-barcode/"+code, nil)
+
+	// Execute
+	handler.GetProductByBarcode(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetProductByBarcode")
+}
+
+func TestProductHandler_ListProducts_WithSort(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+
+	sort := []models.SortField{{Field: "price", Desc: true}, {Field: "name"}}
+	products := []models.Product{
+		{ID: "8", Name: "Pepperoni Pizza", Price: 15.99, Category: "Pizza"},
+	}
+
+	mockService.On("ListProductsPaginated", 1, 0, sort, noFilter).Return(products, 2, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/products?perPage=1&sort=-price,name", nil)
+
+	// Execute
+	handler.ListProducts(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.PaginatedResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	// Sort is carried through the pagination links
+	assert.Equal(t, "/api/v1/products?page=1&perPage=1&sort=-price%2Cname", response.Links[0].Href)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ListProducts_Cursor(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+
+	filter := models.ProductFilter{Category: "Waffle"}
+	products := []models.Product{
+		{ID: "1", Name: "Waffle with Berries", Price: 6.5, Category: "Waffle"},
+	}
+	mockService.On("ListProductsAfter", "", 1, noSort, filter).Return(products, "next-page", nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/products?limit=1&category=Waffle", nil)
+
+	// Execute
+	handler.ListProducts(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.PaginatedResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.CursorMeta{Limit: 1, NextCursor: "next-page"}, response.Cursor)
+
+	// The filter is carried through the cursor links
+	assert.Equal(t, []models.Link{
+		{Href: "/api/v1/products?category=Waffle&limit=1", Rel: "self", Method: "GET"},
+		{Href: "/api/v1/products?after=next-page&category=Waffle&limit=1", Rel: "next", Method: "GET"},
+	}, response.Links)
+
+	mockService.AssertNotCalled(t, "ListProductsPaginated")
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ListProducts_InvalidSort(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/products?sort=price%3Bdrop", nil)
+
+	// Execute
+	handler.ListProducts(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "cannot sort by")
+	mockService.AssertNotCalled(t, "ListProductsPaginated")
+}
+
+func TestProductHandler_ListProducts_WithFilter(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+
+	minPrice, maxPrice := 5.0, 12.5
+	filter := models.ProductFilter{Category: "Waffle", MinPrice: &minPrice, MaxPrice: &maxPrice}
+	products := []models.Product{
+		{ID: "1", Name: "Waffle with Berries", Price: 6.5, Category: "Waffle"},
+	}
+
+	mockService.On("ListProductsPaginated", 10, 0, noSort, filter).Return(products, 1, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/products?category=Waffle&minPrice=5&maxPrice=12.50", nil)
+
+	// Execute
+	handler.ListProducts(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.PaginatedResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	// The filter is carried through the pagination links
+	assert.Equal(t, "/api/v1/products?page=1&perPage=10&category=Waffle&maxPrice=12.5&minPrice=5", response.Links[0].Href)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ListProducts_InvalidFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "not a number", query: "minPrice=cheap"},
+		{name: "negative", query: "maxPrice=-1"},
+		{name: "infinite", query: "maxPrice=Inf"},
+		{name: "min above max", query: "minPrice=10&maxPrice=5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockProductService)
+			handler := NewProductHandler(mockService)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/products?"+tt.query, nil)
+
+			// Execute
+			handler.ListProducts(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockService.AssertNotCalled(t, "ListProductsPaginated")
+		})
+	}
+}
+
+func TestProductHandler_SearchProducts(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+
+	products := []models.Product{
+		{ID: "1", Name: "Waffle with Berries", Price: 6.5, Category: "Waffle"},
+	}
+
+	mockService.On("SearchProducts", "berry waffle", 10, 0).Return(products, 1, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/products/search?q=berry+waffle+", nil)
+
+	// Execute
+	handler.SearchProducts(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.PaginatedResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, response.Pagination.TotalItems)
+	assert.Len(t, response.Data, 1)
+
+	// The search text is carried through the pagination links
+	assert.Equal(t, "/api/v1/products/search?page=1&perPage=10&q=berry+waffle", response.Links[0].Href)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_SearchProducts_InvalidQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "missing", query: ""},
+		{name: "blank", query: "q=+++"},
+		{name: "too long", query: "q=" + strings.Repeat("a", maxSearchQueryLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockProductService)
+			handler := NewProductHandler(mockService)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/products/search?"+tt.query, nil)
+
+			// Execute
+			handler.SearchProducts(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockService.AssertNotCalled(t, "SearchProducts")
+		})
+	}
+}
+
+func TestProductHandler_CreateProduct(t *testing.T) {
+	price := 12.99
+	req := models.ProductReq{ID: "11", Name: "Chicken Waffle", Price: &price, Category: "Waffle"}
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "created", body: `{"id":"11","name":"Chicken Waffle","price":12.99,"category":"Waffle"}`, wantStatus: http.StatusCreated},
+		{name: "id taken", body: `{"id":"11","name":"Chicken Waffle","price":12.99,"category":"Waffle"}`, err: service.ErrProductExists, wantStatus: http.StatusConflict},
+		{name: "barcode taken", body: `{"id":"11","name":"Chicken Waffle","price":12.99,"category":"Waffle"}`, err: service.ErrProductIdentifierTaken, wantStatus: http.StatusConflict},
+		{name: "invalid product", body: `{"id":"11","name":"Chicken Waffle","price":12.99,"category":"Waffle"}`, err: service.ErrInvalidProduct, wantStatus: http.StatusUnprocessableEntity},
+		{name: "missing price", body: `{"id":"11","name":"Chicken Waffle","category":"Waffle"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockProductService)
+			handler := NewProductHandler(mockService)
+			mockService.On("CreateProduct", req, mock.Anything).
+				Return(models.Product{ID: "11", Name: "Chicken Waffle", Price: price, Category: "Waffle"}, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/products", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			// Execute
+			handler.CreateProduct(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusCreated {
+				var response models.HATEOASResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Contains(t, response.Links, models.Link{Href: "/api/v1/products/11", Rel: "delete", Method: "DELETE"})
+			}
+		})
+	}
+}
+
+func TestProductHandler_UpdateProduct_NotFound(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	mockService.On("UpdateProduct", "999", mock.Anything, mock.Anything).Return(models.Product{}, service.ErrProductNotFound)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("PUT", "/api/v1/products/999",
+		bytes.NewBufferString(`{"name":"Chicken Waffle","price":12.99,"category":"Waffle"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "productId", Value: "999"}}
+
+	// Execute
+	handler.UpdateProduct(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_SetOptionGroups(t *testing.T) {
+	req := models.OptionGroupsReq{OptionGroups: []models.OptionGroup{{
+		ID: "size", Name: "Size", MinSelect: 1, MaxSelect: 1,
+		Options: []models.ProductOption{{ID: "regular", Name: "Regular"}, {ID: "large", Name: "Large", Price: 2}},
+	}}}
+	body := `{"optionGroups":[{"id":"size","name":"Size","minSelect":1,"maxSelect":1,"options":[{"id":"regular","name":"Regular","price":0},{"id":"large","name":"Large","price":2}]}]}`
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "replaced", body: body, wantStatus: http.StatusOK},
+		{name: "not found", body: body, err: service.ErrProductNotFound, wantStatus: http.StatusNotFound},
+		{name: "invalid groups", body: body, err: service.ErrInvalidProduct, wantStatus: http.StatusUnprocessableEntity},
+		{name: "group without options", body: `{"optionGroups":[{"id":"size","name":"Size","maxSelect":1,"options":[]}]}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockProductService)
+			handler := NewProductHandler(mockService)
+			mockService.On("SetOptionGroups", "11", req, mock.Anything).
+				Return(models.Product{ID: "11", Name: "Chicken Waffle", Price: 12.99, Category: "Waffle", OptionGroups: req.OptionGroups}, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("PUT", "/api/v1/products/11/option-groups", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "productId", Value: "11"}}
+
+			// Execute
+			handler.SetOptionGroups(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var response struct {
+					Data models.Product `json:"data"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, req.OptionGroups, response.Data.OptionGroups)
+			}
+		})
+	}
+}
+
+func TestProductHandler_DeleteProduct(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "deleted", wantStatus: http.StatusOK},
+		{name: "not found", err: service.ErrProductNotFound, wantStatus: http.StatusNotFound},
+		{name: "database error", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockProductService)
+			handler := NewProductHandler(mockService)
+			mockService.On("DeleteProduct", "1", mock.Anything).Return(tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("DELETE", "/api/v1/products/1", nil)
+			c.Params = gin.Params{{Key: "productId", Value: "1"}}
+
+			// Execute
+			handler.DeleteProduct(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// PromoCodeHandler handles promo code discount and limit HTTP requests
+type PromoCodeHandler struct {
+	service service.PromoCodeAdminServiceInterface
+}
+
+// NewPromoCodeHandler creates a new promo code handler
+func NewPromoCodeHandler(service service.PromoCodeAdminServiceInterface) *PromoCodeHandler {
+	return &PromoCodeHandler{service: service}
+}
+
+// SetDiscount handles PUT /admin/promo-codes/:code/discount
+// @Summary Set the discount of a promo code
+// @Description Set the percentage or fixed amount a promo code takes off orders, optionally restricted to some categories or products. The code does not have to be loaded yet. Orders already placed keep their discount.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param code path string true "Promo code"
+// @Param discount body models.Discount true "Discount"
+// @Success 200 {object} models.PromoCode
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 422 {object} models.APIResponse "Invalid promo code or discount"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/promo-codes/{code}/discount [put]
+func (h *PromoCodeHandler) SetDiscount(c httpx.Context) {
+	var discount models.Discount
+	if err := c.Bind(&discount); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	promo, err := h.service.SetDiscount(c.Param("code"), discount, utils.PrincipalFromContext(c))
+	if errors.Is(err, service.ErrInvalidPromoCode) || errors.Is(err, service.ErrInvalidDiscount) {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to set promo code discount"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data:  promo,
+		Links: promoCodeLinks(promo.Code),
+	})
+}
+
+// RemoveDiscount handles DELETE /admin/promo-codes/:code/discount
+// @Summary Remove the discount of a promo code
+// @Description The promo code stays valid but no longer takes anything off orders.
+// @Tags admin
+// @Produce json
+// @Param code path string true "Promo code"
+// @Success 200 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "Promo code has no discount"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/promo-codes/{code}/discount [delete]
+func (h *PromoCodeHandler) RemoveDiscount(c httpx.Context) {
+	err := h.service.RemoveDiscount(c.Param("code"), utils.PrincipalFromContext(c))
+	if errors.Is(err, service.ErrDiscountNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Promo code has no discount"))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to remove promo code discount"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.SuccessResponse(http.StatusOK, "Promo code discount removed"))
+}
+
+// GetLimits handles GET /admin/promo-codes/:code/limits
+// @Summary Get the usage limits of a promo code
+// @Description Returns the usage limits of a promo code and how many orders have used it. Codes without limits have zero values.
+// @Tags admin
+// @Produce json
+// @Param code path string true "Promo code"
+// @Success 200 {object} models.PromoCode
+// @Failure 422 {object} models.APIResponse "Invalid promo code"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/promo-codes/{code}/limits [get]
+func (h *PromoCodeHandler) GetLimits(c httpx.Context) {
+	promo, err := h.service.GetLimits(c.Param("code"))
+	if errors.Is(err, service.ErrInvalidPromoCode) {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to get promo code limits"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data:  promo,
+		Links: promoCodeLimitLinks(promo.Code),
+	})
+}
+
+// SetLimits handles PUT /admin/promo-codes/:code/limits
+// @Summary Set the usage limits of a promo code
+// @Description Limit how many orders can use a promo code in total, or let each customerId use it once. Zero limits make the code unlimited. Uses made before the limits were set count towards them.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param code path string true "Promo code"
+// @Param limits body models.PromoCodeLimits true "Usage limits; redemptions is ignored"
+// @Success 200 {object} models.PromoCode
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 422 {object} models.APIResponse "Invalid promo code or limits"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/promo-codes/{code}/limits [put]
+func (h *PromoCodeHandler) SetLimits(c httpx.Context) {
+	var limits models.PromoCodeLimits
+	if err := c.Bind(&limits); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	promo, err := h.service.SetLimits(c.Param("code"), limits, utils.PrincipalFromContext(c))
+	if errors.Is(err, service.ErrInvalidPromoCode) || errors.Is(err, service.ErrInvalidLimits) {
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to set promo code limits"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data:  promo,
+		Links: promoCodeLimitLinks(promo.Code),
+	})
+}
+
+// writePromoCodeError writes the response for an order refused by policy
+// or the limits of its promo code and reports whether err was such a
+// refusal
+func writePromoCodeError(c httpx.Context, err error, policy models.PromoCodePolicy) bool {
+	switch {
+	case errors.Is(err, service.ErrInvalidPromoCode):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Invalid promo code. "+policy.Rules()))
+	case errors.Is(err, service.ErrCustomerRequired):
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, "Promo code can be used once per customer; customerId is required"))
+	case errors.Is(err, service.ErrPromoCodeExhausted):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Promo code has reached its usage limit"))
+	case errors.Is(err, service.ErrPromoCodeRedeemed):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Promo code has already been used by this customer"))
+	case errors.Is(err, service.ErrPromoCodeNotApplicable):
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse(http.StatusUnprocessableEntity, "Promo code does not apply to any item in the order"))
+	default:
+		return false
+	}
+	return true
+}
+
+func promoCodeLinks(code string) []models.Link {
+	discount := "/api/v1/admin/promo-codes/" + code + "/discount"
+	return []models.Link{
+		{Href: discount, Rel: "self", Method: "PUT"},
+		{Href: discount, Rel: "remove", Method: "DELETE"},
+	}
+}
+
+func promoCodeLimitLinks(code string) []models.Link {
+	limits := "/api/v1/admin/promo-codes/" + code + "/limits"
+	return []models.Link{
+		{Href: limits, Rel: "self", Method: "GET"},
+		{Href: limits, Rel: "update", Method: "PUT"},
+		{Href: "/api/v1/admin/promo-codes/" + code + "/discount", Rel: "discount", Method: "PUT"},
+	}
+}
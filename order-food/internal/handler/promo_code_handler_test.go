@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPromoCodeAdminService is a mock implementation of PromoCodeAdminServiceInterface
+type MockPromoCodeAdminService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.PromoCodeAdminServiceInterface = (*MockPromoCodeAdminService)(nil)
+
+func (m *MockPromoCodeAdminService) SetDiscount(code string, discount models.Discount, actor string) (models.PromoCode, error) {
+	args := m.Called(code, discount, actor)
+	return args.Get(0).(models.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodeAdminService) RemoveDiscount(code, actor string) error {
+	args := m.Called(code, actor)
+	return args.Error(0)
+}
+
+func (m *MockPromoCodeAdminService) GetLimits(code string) (models.PromoCode, error) {
+	args := m.Called(code)
+	return args.Get(0).(models.PromoCode), args.Error(1)
+}
+
+func (m *MockPromoCodeAdminService) SetLimits(code string, limits models.PromoCodeLimits, actor string) (models.PromoCode, error) {
+	args := m.Called(code, limits, actor)
+	return args.Get(0).(models.PromoCode), args.Error(1)
+}
+
+func TestPromoCodeHandler_SetDiscount(t *testing.T) {
+	discount := models.Discount{Type: models.DiscountTypePercentage, Value: 10}
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "set", body: `{"type":"percentage","value":10}`, wantStatus: http.StatusOK},
+		{name: "invalid discount", body: `{"type":"percentage","value":10}`, err: service.ErrInvalidDiscount, wantStatus: http.StatusUnprocessableEntity},
+		{name: "unknown type", body: `{"type":"bogo","value":1}`, wantStatus: http.StatusBadRequest},
+		{name: "missing value", body: `{"type":"fixed"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockPromoCodeAdminService)
+			handler := NewPromoCodeHandler(mockService)
+			mockService.On("SetDiscount", "HAPPYHRS", discount, mock.Anything).
+				Return(models.PromoCode{Code: "HAPPYHRS", Discount: &discount}, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("PUT", "/api/v1/admin/promo-codes/HAPPYHRS/discount", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "code", Value: "HAPPYHRS"}}
+
+			// Execute
+			handler.SetDiscount(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"discount":{"type":"percentage","value":10}`)
+			}
+		})
+	}
+}
+
+func TestPromoCodeHandler_RemoveDiscount_NotFound(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPromoCodeAdminService)
+	handler := NewPromoCodeHandler(mockService)
+	mockService.On("RemoveDiscount", "HAPPYHRS", mock.Anything).Return(service.ErrDiscountNotFound)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/api/v1/admin/promo-codes/HAPPYHRS/discount", nil)
+	c.Params = gin.Params{{Key: "code", Value: "HAPPYHRS"}}
+
+	// Execute
+	handler.RemoveDiscount(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestPromoCodeHandler_SetLimits(t *testing.T) {
+	limits := models.PromoCodeLimits{MaxRedemptions: 100}
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{name: "set", body: `{"maxRedemptions":100}`, wantStatus: http.StatusOK},
+		{name: "invalid code", body: `{"maxRedemptions":100}`, err: service.ErrInvalidPromoCode, wantStatus: http.StatusUnprocessableEntity},
+		{name: "negative limit", body: `{"maxRedemptions":-1}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockPromoCodeAdminService)
+			handler := NewPromoCodeHandler(mockService)
+			used := limits
+			used.Redemptions = 42
+			mockService.On("SetLimits", "HAPPYHRS", limits, mock.Anything).
+				Return(models.PromoCode{Code: "HAPPYHRS", Limits: &used}, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("PUT", "/api/v1/admin/promo-codes/HAPPYHRS/limits", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "code", Value: "HAPPYHRS"}}
+
+			// Execute
+			handler.SetLimits(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"limits":{"maxRedemptions":100,"oncePerCustomer":false,"redemptions":42}`)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/tracing"
+)
+
+// ReservationHandler handles stock reservation HTTP requests
+type ReservationHandler struct {
+	service service.ReservationServiceInterface
+}
+
+// NewReservationHandler creates a new reservation handler
+func NewReservationHandler(service service.ReservationServiceInterface) *ReservationHandler {
+	return &ReservationHandler{service: service}
+}
+
+// CreateReservation handles POST /reservations
+// @Summary Reserve stock for a checkout
+// @Description Hold the items for a limited time. Pass the returned ID as reservationId when placing the order; unused reservations lapse at expiresAt.
+// @Tags order
+// @Accept json
+// @Produce json
+// @Param reservation body models.ReservationReq true "Items to reserve"
+// @Success 201 {object} models.Reservation
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 422 {object} models.ValidationErrorResponse "Insufficient stock"
+// @Security ApiKeyAuth
+// @Router /api/v1/reservations [post]
+func (h *ReservationHandler) CreateReservation(c httpx.Context) {
+	var req models.ReservationReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	reservation, err := h.service.Reserve(req)
+	if writeStockError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to reserve stock"))
+		return
+	}
+	tracing.StockReserved(c.Context(), reservation.ID, len(reservation.Items))
+
+	c.JSON(http.StatusCreated, reservation)
+}
+
+// GetReservation handles GET /reservations/:reservationId
+// @Summary Get a stock reservation
+// @Description Returns the items a reservation holds and when it expires. Reservations that expired or were used by an order are not found.
+// @Tags order
+// @Produce json
+// @Param reservationId path string true "Reservation ID"
+// @Success 200 {object} models.Reservation
+// @Failure 404 {object} models.APIResponse "Reservation not found or expired"
+// @Security ApiKeyAuth
+// @Router /api/v1/reservations/{reservationId} [get]
+func (h *ReservationHandler) GetReservation(c httpx.Context) {
+	reservation, err := h.service.GetReservation(c.Context(), c.Param("reservationId"))
+	if errors.Is(err, repository.ErrReservationNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Reservation not found or expired"))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to get reservation"))
+		return
+	}
+
+	c.JSON(http.StatusOK, reservation)
+}
+
+// ReleaseReservation handles DELETE /reservations/:reservationId
+// @Summary Release a stock reservation
+// @Description Give the held stock back before the reservation expires, e.g. when the cart is emptied or checkout is abandoned
+// @Tags order
+// @Param reservationId path string true "Reservation ID"
+// @Success 204 "Reservation released"
+// @Failure 404 {object} models.APIResponse "Reservation not found or expired"
+// @Security ApiKeyAuth
+// @Router /api/v1/reservations/{reservationId} [delete]
+func (h *ReservationHandler) ReleaseReservation(c httpx.Context) {
+	err := h.service.Release(c.Context(), c.Param("reservationId"))
+	if errors.Is(err, repository.ErrReservationNotFound) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Reservation not found or expired"))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to release reservation"))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// writeStockError writes the response for stock and reservation errors and
+// reports whether err was one of them
+func writeStockError(c httpx.Context, err error) bool {
+	var stockErr *repository.StockError
+	switch {
+	case errors.As(err, &stockErr):
+		diagnostics := make([]models.FieldError, len(stockErr.Shortages))
+		for i, s := range stockErr.Shortages {
+			diagnostics[i] = models.FieldError{
+				Field:   "items[" + s.ProductID + "].quantity",
+				Message: fmt.Sprintf("requested %d but only %d available", s.Requested, s.Available),
+			}
+		}
+		c.JSON(http.StatusUnprocessableEntity, models.ValidationError(http.StatusUnprocessableEntity, "Insufficient stock", diagnostics))
+		return true
+	case errors.Is(err, repository.ErrReservationNotFound):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Reservation not found or expired"))
+		return true
+	}
+	return false
+}
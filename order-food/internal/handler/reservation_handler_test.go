@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockReservationService is a mock implementation of ReservationServiceInterface
+type MockReservationService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.ReservationServiceInterface = (*MockReservationService)(nil)
+
+func (m *MockReservationService) Reserve(req models.ReservationReq) (models.Reservation, error) {
+	args := m.Called(req)
+	return args.Get(0).(models.Reservation), args.Error(1)
+}
+
+func (m *MockReservationService) GetReservation(ctx context.Context, id string) (models.Reservation, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.Reservation), args.Error(1)
+}
+
+func (m *MockReservationService) Release(ctx context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestReservationHandler_CreateReservation_Success(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockReservationService)
+	handler := NewReservationHandler(mockService)
+
+	req := models.ReservationReq{Items: []models.OrderItem{{ProductID: "1", Quantity: 2}}}
+	reservation := models.Reservation{ID: "res-1", Items: req.Items, ExpiresAt: time.Now().Add(15 * time.Minute)}
+	mockService.On("Reserve", req).Return(reservation, nil)
+
+	// Create request
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/reservations", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.CreateReservation(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":"res-1"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestReservationHandler_CreateReservation_InsufficientStock(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockReservationService)
+	handler := NewReservationHandler(mockService)
+
+	req := models.ReservationReq{Items: []models.OrderItem{{ProductID: "1", Quantity: 3}}}
+	mockService.On("Reserve", req).Return(models.Reservation{}, &repository.StockError{
+		Shortages: []models.StockShortage{{ProductID: "1", Requested: 3, Available: 1}},
+	})
+
+	// Create request
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/reservations", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.CreateReservation(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var response models.ValidationErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []models.FieldError{{Field: "items[1].quantity", Message: "requested 3 but only 1 available"}}, response.Errors)
+}
+
+func TestOrderHandler_CreateOrder_ExpiredReservation(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockOrderService := new(MockOrderService)
+	handler := NewOrderHandler(mockOrderService, new(MockPromoCodeService), nil)
+
+	orderReq := models.OrderReq{Items: []models.OrderItem{{ProductID: "1", Quantity: 1}}, ReservationID: "res-1"}
+	mockOrderService.On("CreateOrder", orderReq).Return(models.Order{}, repository.ErrReservationNotFound)
+
+	// Create request
+	body, _ := json.Marshal(orderReq)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/orders", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.CreateOrder(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockOrderService.AssertExpectations(t)
+}
+
+func TestReservationHandler_GetReservation(t *testing.T) {
+	reservation := models.Reservation{ID: "res-1", Items: []models.OrderItem{{ProductID: "1", Quantity: 2}}, ExpiresAt: time.Now().Add(15 * time.Minute)}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "held", wantStatus: http.StatusOK, wantBody: `"productId":"1","quantity":2`},
+		{name: "expired", err: repository.ErrReservationNotFound, wantStatus: http.StatusNotFound},
+		{name: "database error", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockReservationService)
+			handler := NewReservationHandler(mockService)
+			if tt.err != nil {
+				mockService.On("GetReservation", "res-1").Return(models.Reservation{}, tt.err)
+			} else {
+				mockService.On("GetReservation", "res-1").Return(reservation, nil)
+			}
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/reservations/res-1", nil)
+			c.Params = gin.Params{{Key: "reservationId", Value: "res-1"}}
+
+			// Execute
+			handler.GetReservation(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReservationHandler_ReleaseReservation(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "released", wantStatus: http.StatusNoContent},
+		{name: "expired", err: repository.ErrReservationNotFound, wantStatus: http.StatusNotFound},
+		{name: "database error", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockReservationService)
+			handler := NewReservationHandler(mockService)
+			mockService.On("Release", "res-1").Return(tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("DELETE", "/api/v1/reservations/res-1", nil)
+			c.Params = gin.Params{{Key: "reservationId", Value: "res-1"}}
+
+			// Execute
+			handler.ReleaseReservation(ginx.New(c))
+			c.Writer.WriteHeaderNow()
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
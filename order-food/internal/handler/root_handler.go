@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// RootHandler serves the API discovery document
+type RootHandler struct{}
+
+// NewRootHandler creates a new root handler
+func NewRootHandler() *RootHandler {
+	return &RootHandler{}
+}
+
+// Root handles GET /
+// @Summary API root
+// @Description Links to every resource the caller can reach, and the caller's rate limit quota when an API key is sent. Hrefs in braces are URI templates.
+// @Tags discovery
+// @Produce json
+// @Success 200 {object} models.APIRoot
+// @Failure 403 {object} models.APIResponse "Invalid API key"
+// @Failure 429 {object} models.APIResponse "Rate limit exceeded"
+// @Router /api/v1/api/v1 [get]
+func (h *RootHandler) Root(c httpx.Context) {
+	principal := utils.PrincipalFromContext(c)
+	root := models.APIRoot{Version: "v1", Principal: principal}
+	if quota, ok := utils.QuotaFromContext(c); ok {
+		root.Quota = &quota
+	}
+
+	links := []models.Link{
+		{Href: "/api/v1", Rel: "self", Method: "GET"},
+		{Href: "/api/v1/capabilities", Rel: "capabilities", Method: "GET"},
+		{Href: "/api/v1/products", Rel: "products", Method: "GET"},
+		{Href: "/api/v1/products/{productId}", Rel: "product", Method: "GET"},
+		{Href: "/api/v1/products/by-barcode/{code}", Rel: "product-by-barcode", Method: "GET"},
+		{Href: "/api/v1/products/availability", Rel: "product-availability", Method: "POST"},
+		{Href: "/api/v1/categories", Rel: "categories", Method: "GET"},
+	}
+	if principal == "" {
+		links = append(links, models.Link{Href: "/api/v1/partners", Rel: "register-partner", Method: "POST"})
+	}
+	if utils.HasScope(c, service.ScopeOrdersRead) {
+		links = append(links,
+			models.Link{Href: "/api/v1/orders", Rel: "orders", Method: "GET"},
+			models.Link{Href: "/api/v1/orders/{orderId}", Rel: "order", Method: "GET"},
+		)
+	}
+	if utils.HasScope(c, service.ScopeOrdersWrite) {
+		links = append(links,
+			models.Link{Href: "/api/v1/orders", Rel: "create-order", Method: "POST"},
+			models.Link{Href: "/api/v1/reservations", Rel: "create-reservation", Method: "POST"},
+		)
+	}
+	if utils.HasScope(c, service.ScopeOrdersImport) {
+		links = append(links, models.Link{Href: "/api/v1/orders/import", Rel: "import-order", Method: "POST"})
+	}
+	if partnerID, ok := strings.CutPrefix(principal, utils.PartnerPrincipal("")); ok {
+		links = append(links, models.Link{Href: "/api/v1/partners/" + partnerID + "/keys/rotate", Rel: "rotate-key", Method: "POST"})
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{Data: root, Links: links})
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRootHandler_Root(t *testing.T) {
+	quota := models.Quota{Limit: 600, Remaining: 599, Window: 60, ResetAt: time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name      string
+		principal string
+		scopes    []string
+		wantRels  []string
+		wantQuota bool
+	}{
+		{
+			name:     "anonymous",
+			wantRels: []string{"self", "capabilities", "products", "product", "product-by-barcode", "product-availability", "categories", "register-partner"},
+		},
+		{
+			name:      "partner with read scope",
+			principal: utils.PartnerPrincipal("p-1"),
+			scopes:    []string{service.ScopeOrdersRead},
+			wantRels:  []string{"self", "capabilities", "products", "product", "product-by-barcode", "product-availability", "categories", "orders", "order", "rotate-key"},
+			wantQuota: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			handler := NewRootHandler()
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1", nil)
+			if tt.principal != "" {
+				utils.SetPrincipal(c, tt.principal, tt.scopes)
+				utils.SetQuota(c, quota)
+			}
+
+			// Execute
+			handler.Root(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response struct {
+				Data  models.APIRoot `json:"data"`
+				Links []models.Link  `json:"_links"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			rels := make([]string, len(response.Links))
+			for i, link := range response.Links {
+				rels[i] = link.Rel
+			}
+			assert.Equal(t, tt.wantRels, rels)
+			if tt.wantQuota {
+				assert.Equal(t, &quota, response.Data.Quota)
+			} else {
+				assert.Nil(t, response.Data.Quota)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// StockHandler handles admin stock management HTTP requests and the public
+// availability check of carts
+type StockHandler struct {
+	service service.StockServiceInterface
+}
+
+// NewStockHandler creates a new stock handler
+func NewStockHandler(service service.StockServiceInterface) *StockHandler {
+	return &StockHandler{service: service}
+}
+
+// GetStock handles GET /admin/products/:productId/stock
+// @Summary Get the stock of a product
+// @Description Returns the units on hand, how many are held by reservations and how many orders can still take. Stock is null for products that do not track it.
+// @Tags admin
+// @Produce json
+// @Param productId path string true "Product ID"
+// @Success 200 {object} models.ProductStock
+// @Failure 404 {object} models.APIResponse "Product not found"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/products/{productId}/stock [get]
+func (h *StockHandler) GetStock(c httpx.Context) {
+	stock, err := h.service.GetStock(c.Context(), c.Param("productId"))
+	if writeStockUpdateError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to get product stock"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data:  stock,
+		Links: stockLinks(stock.ProductID),
+	})
+}
+
+// SetStock handles PUT /admin/products/:productId/stock
+// @Summary Set the stock of a product
+// @Description Replace the units on hand, e.g. after a stock count, including units held by reservations. A null stock stops tracking the product, so it never runs out. The change is recorded in the audit log.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param productId path string true "Product ID"
+// @Param stock body models.StockReq true "New stock"
+// @Success 200 {object} models.ProductStock
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 404 {object} models.APIResponse "Product not found"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/products/{productId}/stock [put]
+func (h *StockHandler) SetStock(c httpx.Context) {
+	var req models.StockReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	stock, err := h.service.SetStock(c.Context(), c.Param("productId"), req, utils.PrincipalFromContext(c))
+	if writeStockUpdateError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to set product stock"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data:  stock,
+		Links: stockLinks(stock.ProductID),
+	})
+}
+
+// AdjustStock handles POST /admin/products/:productId/stock/adjustments
+// @Summary Adjust the stock of a product
+// @Description Add units to the stock of a product, e.g. for a delivery, or take them away with a negative delta, e.g. for breakage. The adjustment is applied to the stock at that moment, so concurrent orders are not overwritten, and is recorded in the audit log.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param productId path string true "Product ID"
+// @Param adjustment body models.StockAdjustmentReq true "Units to add or, when negative, take away"
+// @Success 200 {object} models.ProductStock
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Failure 404 {object} models.APIResponse "Product not found"
+// @Failure 409 {object} models.APIResponse "Product does not track stock"
+// @Failure 422 {object} models.APIResponse "Stock would go below zero"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/products/{productId}/stock/adjustments [post]
+func (h *StockHandler) AdjustStock(c httpx.Context) {
+	var req models.StockAdjustmentReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	stock, err := h.service.AdjustStock(c.Context(), c.Param("productId"), req, utils.PrincipalFromContext(c))
+	if writeStockUpdateError(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to adjust product stock"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data:  stock,
+		Links: stockLinks(stock.ProductID),
+	})
+}
+
+// CheckAvailability handles POST /products/availability
+// @Summary Check the availability of cart items
+// @Description Reports for each item whether an order for it would be accepted now, reading the stock of every product in one query, so a cart can grey out items before checkout. A product listed twice must fit its combined quantity. Nothing is held; reserve stock to be sure of it.
+// @Tags product
+// @Accept json
+// @Produce json
+// @Param items body models.AvailabilityReq true "Products and quantities"
+// @Success 200 {object} models.HATEOASResponse{data=[]models.ItemAvailability}
+// @Failure 400 {object} models.APIResponse "Invalid input"
+// @Router /api/v1/products/availability [post]
+func (h *StockHandler) CheckAvailability(c httpx.Context) {
+	var req models.AvailabilityReq
+	if err := c.Bind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse(http.StatusBadRequest, err.Error()))
+		return
+	}
+
+	items, err := h.service.CheckAvailability(c.Context(), req.Items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to check availability"))
+		return
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data: items,
+		Links: []models.Link{
+			{Href: "/api/v1/reservations", Rel: "reserve", Method: "POST"},
+			{Href: "/api/v1/orders", Rel: "order", Method: "POST"},
+		},
+	})
+}
+
+// writeStockUpdateError writes the response for errors of the stock admin
+// API and reports whether err was one of them
+func writeStockUpdateError(c httpx.Context, err error) bool {
+	switch {
+	case errors.Is(err, repository.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Product not found"))
+	case errors.Is(err, repository.ErrStockNotTracked):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Product does not track stock; set its stock first"))
+	case errors.Is(err, repository.ErrNegativeStock):
+		c.JSON(http.StatusUnprocessableEntity, models.ErrorResponse(http.StatusUnprocessableEntity, err.Error()))
+	default:
+		return false
+	}
+	return true
+}
+
+func stockLinks(productID string) []models.Link {
+	stock := "/api/v1/admin/products/" + productID + "/stock"
+	return []models.Link{
+		{Href: stock, Rel: "self", Method: "GET"},
+		{Href: stock, Rel: "update", Method: "PUT"},
+		{Href: stock + "/adjustments", Rel: "adjust", Method: "POST"},
+		{Href: "/api/v1/products/" + productID, Rel: "product", Method: "GET"},
+	}
+}
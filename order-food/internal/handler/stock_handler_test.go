@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockStockService is a mock implementation of StockServiceInterface
+type MockStockService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.StockServiceInterface = (*MockStockService)(nil)
+
+func (m *MockStockService) GetStock(ctx context.Context, productID string) (models.ProductStock, error) {
+	args := m.Called(productID)
+	return args.Get(0).(models.ProductStock), args.Error(1)
+}
+
+func (m *MockStockService) SetStock(ctx context.Context, productID string, req models.StockReq, actor string) (models.ProductStock, error) {
+	args := m.Called(productID, req, actor)
+	return args.Get(0).(models.ProductStock), args.Error(1)
+}
+
+func (m *MockStockService) AdjustStock(ctx context.Context, productID string, req models.StockAdjustmentReq, actor string) (models.ProductStock, error) {
+	args := m.Called(productID, req, actor)
+	return args.Get(0).(models.ProductStock), args.Error(1)
+}
+
+func (m *MockStockService) CheckAvailability(ctx context.Context, items []models.AvailabilityItem) ([]models.ItemAvailability, error) {
+	args := m.Called(items)
+	return args.Get(0).([]models.ItemAvailability), args.Error(1)
+}
+
+func TestStockHandler_GetStock(t *testing.T) {
+	stock, available := 5, 3
+
+	tests := []struct {
+		name       string
+		stock      models.ProductStock
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "tracked",
+			stock:      models.ProductStock{ProductID: "1", Stock: &stock, Reserved: 2, Available: &available},
+			wantStatus: http.StatusOK,
+			wantBody:   `"stock":5,"reserved":2,"available":3`,
+		},
+		{
+			name:       "not tracked",
+			stock:      models.ProductStock{ProductID: "1"},
+			wantStatus: http.StatusOK,
+			wantBody:   `"stock":null,"reserved":0,"available":null`,
+		},
+		{
+			name:       "not found",
+			err:        repository.ErrProductNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "database error",
+			err:        errors.New("connection refused"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockStockService)
+			handler := NewStockHandler(mockService)
+			mockService.On("GetStock", "1").Return(tt.stock, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/admin/products/1/stock", nil)
+			c.Params = gin.Params{{Key: "productId", Value: "1"}}
+
+			// Execute
+			handler.GetStock(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStockHandler_SetStock(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockStockService)
+	handler := NewStockHandler(mockService)
+	mockService.On("SetStock", "1", models.StockReq{Reason: "Sold loose"}, "admin").
+		Return(models.ProductStock{ProductID: "1"}, nil)
+
+	// Create request: a null stock stops tracking
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("PUT", "/api/v1/admin/products/1/stock", bytes.NewBufferString(`{"stock":null,"reason":"Sold loose"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "productId", Value: "1"}}
+	utils.SetPrincipal(c, "admin", []string{utils.ScopeAll})
+
+	// Execute
+	handler.SetStock(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"href":"/api/v1/admin/products/1/stock/adjustments","rel":"adjust"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestStockHandler_AdjustStock(t *testing.T) {
+	stock := 3
+
+	tests := []struct {
+		name       string
+		stock      models.ProductStock
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "adjusted",
+			stock:      models.ProductStock{ProductID: "1", Stock: &stock, Available: &stock},
+			wantStatus: http.StatusOK,
+			wantBody:   `"stock":3`,
+		},
+		{
+			name:       "not found",
+			err:        repository.ErrProductNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "not tracked",
+			err:        repository.ErrStockNotTracked,
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "below zero",
+			err:        fmt.Errorf("%w: 1 on hand", repository.ErrNegativeStock),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   "1 on hand",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockStockService)
+			handler := NewStockHandler(mockService)
+			mockService.On("AdjustStock", "1", models.StockAdjustmentReq{Delta: -2, Reason: "Breakage"}, "admin").Return(tt.stock, tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/admin/products/1/stock/adjustments", bytes.NewBufferString(`{"delta":-2,"reason":"Breakage"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "productId", Value: "1"}}
+			utils.SetPrincipal(c, "admin", []string{utils.ScopeAll})
+
+			// Execute
+			handler.AdjustStock(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStockHandler_InvalidBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		body   string
+	}{
+		{name: "negative stock", method: "PUT", body: `{"stock":-1}`},
+		{name: "zero delta", method: "POST", body: `{"delta":0}`},
+		{name: "missing delta", method: "POST", body: `{"reason":"Delivery"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			handler := NewStockHandler(new(MockStockService))
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(tt.method, "/api/v1/admin/products/1/stock", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "productId", Value: "1"}}
+
+			// Execute
+			if tt.method == "PUT" {
+				handler.SetStock(ginx.New(c))
+			} else {
+				handler.AdjustStock(ginx.New(c))
+			}
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestStockHandler_CheckAvailability(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockStockService)
+	handler := NewStockHandler(mockService)
+	available := 1
+	mockService.On("CheckAvailability", []models.AvailabilityItem{{ProductID: "1", Quantity: 2}, {ProductID: "2", Quantity: 1}}).
+		Return([]models.ItemAvailability{
+			{ProductID: "1", Quantity: 2, Available: &available, Reason: models.AvailabilityInsufficientStock},
+			{ProductID: "2", Quantity: 1, Orderable: true},
+		}, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/api/v1/products/availability",
+		bytes.NewBufferString(`{"items":[{"productId":"1","quantity":2},{"productId":"2","quantity":1}]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	handler.CheckAvailability(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"productId":"1","quantity":2,"orderable":false,"available":1,"reason":"insufficient_stock"`)
+	assert.Contains(t, w.Body.String(), `"productId":"2","quantity":1,"orderable":true,"available":null`)
+	mockService.AssertExpectations(t)
+}
+
+func TestStockHandler_CheckAvailability_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "no items", body: `{"items":[]}`},
+		{name: "zero quantity", body: `{"items":[{"productId":"1","quantity":0}]}`},
+		{name: "missing product", body: `{"items":[{"quantity":1}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockStockService)
+			handler := NewStockHandler(mockService)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/products/availability", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			// Execute
+			handler.CheckAvailability(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			mockService.AssertNotCalled(t, "CheckAvailability", mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/scheduler"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+)
+
+// TaskHandler handles scheduled task HTTP requests
+type TaskHandler struct {
+	scheduler service.ScheduledTaskServiceInterface
+}
+
+// NewTaskHandler creates a new scheduled task handler
+func NewTaskHandler(scheduler service.ScheduledTaskServiceInterface) *TaskHandler {
+	return &TaskHandler{scheduler: scheduler}
+}
+
+// ListTasks handles GET /admin/tasks
+// @Summary List scheduled tasks
+// @Description Recurring maintenance tasks with their latest run anywhere in the cluster. Run counts and the next run time are those of the replica serving the request.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} models.ScheduledTask
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/tasks [get]
+func (h *TaskHandler) ListTasks(c httpx.Context) {
+	tasks, err := h.scheduler.Tasks(c.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to fetch scheduled tasks"))
+		return
+	}
+
+	items := make([]models.HATEOASResponse, len(tasks))
+	for i, task := range tasks {
+		items[i] = models.HATEOASResponse{
+			Data:  task,
+			Links: []models.Link{{Href: "/api/v1/admin/tasks/" + task.Name + "/run", Rel: "run", Method: "POST"}},
+		}
+	}
+
+	c.JSON(http.StatusOK, models.HATEOASResponse{
+		Data:  items,
+		Links: []models.Link{{Href: "/api/v1/admin/tasks", Rel: "self", Method: "GET"}},
+	})
+}
+
+// RunTask handles POST /admin/tasks/:name/run
+// @Summary Run a scheduled task now
+// @Description Queue a run of the task on the replica serving the request. The run goes ahead even if the task ran recently, unless another replica is running it.
+// @Tags admin
+// @Produce json
+// @Param name path string true "Task name"
+// @Success 202 {object} models.APIResponse
+// @Failure 404 {object} models.APIResponse "Unknown task"
+// @Failure 409 {object} models.APIResponse "Task is already running"
+// @Security AdminKeyAuth
+// @Router /api/v1/admin/tasks/{name}/run [post]
+func (h *TaskHandler) RunTask(c httpx.Context) {
+	err := h.scheduler.Trigger(c.Param("name"))
+	switch {
+	case errors.Is(err, scheduler.ErrUnknownTask):
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Unknown scheduled task"))
+		return
+	case errors.Is(err, scheduler.ErrTaskRunning):
+		c.JSON(http.StatusConflict, models.ErrorResponse(http.StatusConflict, "Task is already running"))
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse(http.StatusInternalServerError, "Failed to run task"))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, models.SuccessResponse(http.StatusAccepted, "Task run queued"))
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/scheduler"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockScheduledTaskService is a mock implementation of ScheduledTaskServiceInterface
+type MockScheduledTaskService struct {
+	mock.Mock
+}
+
+// Verify interface compliance
+var _ service.ScheduledTaskServiceInterface = (*MockScheduledTaskService)(nil)
+
+func (m *MockScheduledTaskService) Tasks(ctx context.Context) ([]models.ScheduledTask, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.ScheduledTask), args.Error(1)
+}
+
+func (m *MockScheduledTaskService) Trigger(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+func TestTaskHandler_ListTasks(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockScheduledTaskService)
+	handler := NewTaskHandler(mockService)
+	mockService.On("Tasks", mock.Anything).Return([]models.ScheduledTask{{Name: "order-archiver", Interval: "1h0m0s"}}, nil)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/admin/tasks", nil)
+
+	// Execute
+	handler.ListTasks(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"href":"/api/v1/admin/tasks/order-archiver/run"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestTaskHandler_RunTask(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "queued", wantStatus: http.StatusAccepted},
+		{name: "unknown", err: fmt.Errorf("%w: %q", scheduler.ErrUnknownTask, "order-archiver"), wantStatus: http.StatusNotFound},
+		{name: "running", err: scheduler.ErrTaskRunning, wantStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			mockService := new(MockScheduledTaskService)
+			handler := NewTaskHandler(mockService)
+			mockService.On("Trigger", "order-archiver").Return(tt.err)
+
+			// Create request
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/api/v1/admin/tasks/order-archiver/run", nil)
+			c.Params = gin.Params{{Key: "name", Value: "order-archiver"}}
+
+			// Execute
+			handler.RunTask(ginx.New(c))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
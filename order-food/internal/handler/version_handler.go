@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/buildinfo"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/instance"
+)
+
+// metricsNamespace prefixes the metrics exported by the service
+const metricsNamespace = "order_food"
+
+// VersionHandler serves the build description of the running binary
+type VersionHandler struct {
+	info    buildinfo.Info
+	metrics http.Handler
+}
+
+// NewVersionHandler creates a new version handler. The build_info and
+// instance_info gauges and the collectors in metrics are registered on one
+// registry, prefixed by the service's namespace, next to the Go runtime and
+// process metrics. It panics when two collectors export the same metric.
+func NewVersionHandler(info buildinfo.Info, id instance.Identity, metrics ...prometheus.Collector) *VersionHandler {
+	registry := newRegistry()
+	service := prometheus.WrapRegistererWithPrefix(metricsNamespace+"_", registry)
+	service.MustRegister(
+		infoGauge("build_info", "Build information of the running binary.", info.Labels()),
+		infoGauge("instance_info", "Identity of the running instance.", id.Labels()),
+	)
+	service.MustRegister(metrics...)
+
+	return &VersionHandler{
+		info:    info,
+		metrics: promhttp.HandlerFor(registry, promhttp.HandlerOpts{}),
+	}
+}
+
+// newRegistry creates a registry with the Go runtime and process metrics
+func newRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return registry
+}
+
+// infoGauge returns a gauge of 1 carrying labels, in the style of
+// build_info
+func infoGauge(name, help string, labels prometheus.Labels) prometheus.Gauge {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: name, Help: help, ConstLabels: labels})
+	gauge.Set(1)
+	return gauge
+}
+
+// Version handles GET /version
+// @Summary Build information
+// @Description Version, git SHA and build time injected at build time, and the Go version
+// @Tags health
+// @Produce json
+// @Success 200 {object} buildinfo.Info
+// @Router /version [get]
+func (h *VersionHandler) Version(c httpx.Context) {
+	c.JSON(http.StatusOK, h.info)
+}
+
+// Metrics handles GET /metrics with the registered metrics in the
+// Prometheus exposition format
+func (h *VersionHandler) Metrics(c httpx.Context) {
+	h.metrics.ServeHTTP(c.Writer(), c.Request())
+}
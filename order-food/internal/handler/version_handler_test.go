@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/buildinfo"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/instance"
+	"github.com/stretchr/testify/assert"
+)
+
+var testBuildInfo = buildinfo.Info{
+	Version:   "1.2.0",
+	GitSHA:    "abc1234",
+	BuildTime: "2024-01-01T00:00:00Z",
+	GoVersion: "go1.25.4",
+}
+
+var testInstance = instance.Identity{
+	ID:       "0b6f4e2a-1c3d-4e5f-8a9b-0c1d2e3f4a5b",
+	Hostname: "host-1",
+	Pod:      "order-food-7d9f-abcde",
+}
+
+func TestVersionHandler_Version(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	handler := NewVersionHandler(testBuildInfo, testInstance)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/version", nil)
+
+	// Execute
+	handler.Version(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response buildinfo.Info
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, testBuildInfo, response)
+}
+
+func TestVersionHandler_Metrics(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	handler := NewVersionHandler(testBuildInfo, testInstance)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/metrics", nil)
+
+	// Execute
+	handler.Metrics(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, w.Body.String(), `order_food_build_info{buildtime="2024-01-01T00:00:00Z",goversion="go1.25.4",revision="abc1234",version="1.2.0"} 1`)
+	assert.Contains(t, w.Body.String(), `order_food_instance_info{hostname="host-1",instance_id="0b6f4e2a-1c3d-4e5f-8a9b-0c1d2e3f4a5b",pod="order-food-7d9f-abcde"} 1`)
+	assert.Contains(t, w.Body.String(), "# TYPE go_goroutines gauge\n")
+}
+
+func TestVersionHandler_Metrics_Collectors(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	requests := prometheus.NewCounter(prometheus.CounterOpts{Name: "widget_requests_total", Help: "Widget requests."})
+	requests.Add(3)
+	handler := NewVersionHandler(testBuildInfo, testInstance, requests)
+
+	// Create request
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/metrics", nil)
+
+	// Execute
+	handler.Metrics(ginx.New(c))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "# TYPE order_food_widget_requests_total counter\norder_food_widget_requests_total 3\n")
+}
@@ -0,0 +1,107 @@
+// Package health runs dependency checks for the verbose health endpoint.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Component and overall states, ordered from best to worst
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// DefaultTimeout bounds each check
+const DefaultTimeout = 2 * time.Second
+
+// CheckFunc probes one dependency; a nil error means it is healthy
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name     string
+	critical bool
+	fn       CheckFunc
+}
+
+// ComponentStatus is the result of one check
+type ComponentStatus struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the result of running every check. Status is fail when a
+// critical component fails, warn when only non-critical components fail.
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// Checker holds the registered checks. It is safe for concurrent use once
+// all checks are registered.
+type Checker struct {
+	timeout time.Duration
+	checks  []check
+}
+
+// NewChecker creates a checker whose checks time out after timeout
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Register adds a check. A failing critical check fails the whole report.
+func (c *Checker) Register(name string, critical bool, fn CheckFunc) {
+	c.checks = append(c.checks, check{name: name, critical: critical, fn: fn})
+}
+
+// Run executes every check concurrently and builds the report
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{Status: StatusPass, Components: make(map[string]ComponentStatus, len(c.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, chk := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status := c.runCheck(ctx, chk)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[chk.name] = status
+			switch {
+			case status.Status == StatusPass:
+			case chk.critical:
+				report.Status = StatusFail
+			case report.Status == StatusPass:
+				report.Status = StatusWarn
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+// runCheck runs one check under the checker timeout
+func (c *Checker) runCheck(ctx context.Context, chk check) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := chk.fn(ctx)
+	status := ComponentStatus{
+		Status:    StatusPass,
+		Critical:  chk.critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = StatusFail
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,68 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_Run(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	broken := func(ctx context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name   string
+		setup  func(c *Checker)
+		status string
+	}{
+		{name: "no checks", setup: func(c *Checker) {}, status: StatusPass},
+		{name: "all pass", setup: func(c *Checker) {
+			c.Register("database", true, ok)
+			c.Register("cache", false, ok)
+		}, status: StatusPass},
+		{name: "non-critical failure", setup: func(c *Checker) {
+			c.Register("database", true, ok)
+			c.Register("cache", false, broken)
+		}, status: StatusWarn},
+		{name: "critical failure", setup: func(c *Checker) {
+			c.Register("database", true, broken)
+			c.Register("cache", false, broken)
+		}, status: StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			c := NewChecker(time.Second)
+			tt.setup(c)
+
+			// Execute
+			report := c.Run(context.Background())
+
+			// Assert
+			assert.Equal(t, tt.status, report.Status)
+		})
+	}
+}
+
+func TestChecker_ReportsComponentErrorAndTimeout(t *testing.T) {
+	// Setup
+	c := NewChecker(10 * time.Millisecond)
+	c.Register("database", true, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	// Execute
+	report := c.Run(context.Background())
+
+	// Assert
+	component := report.Components["database"]
+	assert.Equal(t, StatusFail, component.Status)
+	assert.True(t, component.Critical)
+	assert.Equal(t, context.DeadlineExceeded.Error(), component.Error)
+	assert.GreaterOrEqual(t, component.LatencyMs, 10.0)
+}
@@ -0,0 +1,54 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+)
+
+// Validator returns the validator of request models. It reads the binding
+// tags of their fields, the rules Gin's binding reads, so models validate
+// the same whichever adapter serves them.
+func Validator() *validator.Validate {
+	validateOnce.Do(func() {
+		validate = validator.New()
+		validate.SetTagName("binding")
+	})
+	return validate
+}
+
+// RegisterValidation adds a rule request models can name in their binding
+// tags
+func RegisterValidation(tag string, fn validator.Func) error {
+	return Validator().RegisterValidation(tag, fn)
+}
+
+// BindJSON decodes the JSON body of r into obj and, when obj is a struct
+// or a pointer to one, validates it against its binding tags. The errors
+// are those of encoding/json and the validator.
+func BindJSON(r *http.Request, obj any) error {
+	if r == nil || r.Body == nil {
+		return errors.New("invalid request")
+	}
+	if err := json.NewDecoder(r.Body).Decode(obj); err != nil {
+		return err
+	}
+
+	value := reflect.ValueOf(obj)
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	return Validator().Struct(value.Interface())
+}
@@ -0,0 +1,169 @@
+// Package ginx serves httpx handlers with Gin
+package ginx
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+)
+
+// Wrap serves h with Gin
+func Wrap(h httpx.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h(New(c))
+	}
+}
+
+// New returns the httpx.Context of a Gin request. Values set on c by Gin
+// middleware are the Values of the context.
+func New(c *gin.Context) httpx.Context {
+	return ginContext{c: c}
+}
+
+// Routes describes the routes registered with a Gin engine
+func Routes(routes gin.RoutesInfo) []httpx.Route {
+	described := make([]httpx.Route, len(routes))
+	for i, route := range routes {
+		described[i] = httpx.Route{Method: route.Method, Path: route.Path}
+	}
+	return described
+}
+
+// ginContext is the httpx.Context of a request served with Gin
+type ginContext struct {
+	c *gin.Context
+}
+
+func (g ginContext) Get(key any) (any, bool)      { return g.c.Get(key) }
+func (g ginContext) Set(key any, value any)       { g.c.Set(key, value) }
+func (g ginContext) Context() context.Context     { return g.c.Request.Context() }
+func (g ginContext) Request() *http.Request       { return g.c.Request }
+func (g ginContext) Writer() http.ResponseWriter  { return g.c.Writer }
+func (g ginContext) Route() string                { return g.c.FullPath() }
+func (g ginContext) Param(name string) string     { return g.c.Param(name) }
+func (g ginContext) Query(name string) string     { return g.c.Query(name) }
+func (g ginContext) GetHeader(name string) string { return g.c.GetHeader(name) }
+func (g ginContext) ClientIP() string             { return g.c.ClientIP() }
+func (g ginContext) Header(name, value string)    { g.c.Header(name, value) }
+func (g ginContext) Status(status int)            { g.c.Status(status) }
+func (g ginContext) JSON(status int, obj any)     { g.c.JSON(status, obj) }
+
+// Bind binds with httpx.BindJSON rather than Gin's binding, so the rules
+// registered with httpx.RegisterValidation apply
+func (g ginContext) Bind(obj any) error { return httpx.BindJSON(g.c.Request, obj) }
+
+func (g ginContext) Data(status int, contentType string, data []byte) {
+	g.c.Data(status, contentType, data)
+}
+
+// Middleware runs m with Gin. The Values m sets are those of the Gin
+// context, the route and client IP it sees are Gin's, and the request and
+// writer it passes on are the ones the later handlers get. A request m
+// answers without calling next is aborted.
+func Middleware(m httpx.Middleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		request, writer := c.Request, c.Writer
+		called := false
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			c.Request = r
+			var wrapped *responseWriter
+			if w != http.ResponseWriter(writer) {
+				wrapped = &responseWriter{ResponseWriter: w, status: http.StatusOK, size: notWritten}
+				c.Writer = wrapped
+			}
+			c.Next()
+			if wrapped != nil && wrapped.statusSet {
+				wrapped.WriteHeaderNow()
+			}
+			c.Writer = writer
+		})
+
+		r := httpx.UseValues(httpx.WithClientIP(httpx.WithRoute(c.Request, c.FullPath()), c.ClientIP()), c)
+		m(next).ServeHTTP(writer, r)
+		c.Request, c.Writer = request, writer
+		if !called {
+			c.Abort()
+		}
+	}
+}
+
+// notWritten is the size of a response whose header is not written yet
+const notWritten = -1
+
+// responseWriter is the gin.ResponseWriter of the handlers after a
+// middleware that passed on a writer of its own. Like Gin's writer, it
+// holds back the status until the body is written or the handlers are
+// done with a status set.
+type responseWriter struct {
+	http.ResponseWriter
+	status    int
+	statusSet bool
+	size      int
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if status > 0 && !w.Written() {
+		w.status = status
+		w.statusSet = true
+	}
+}
+
+func (w *responseWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.size = 0
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *responseWriter) Status() int   { return w.status }
+func (w *responseWriter) Size() int     { return w.size }
+func (w *responseWriter) Written() bool { return w.size != notWritten }
+
+// Flush sends what was written so far, for streamed responses
+func (w *responseWriter) Flush() {
+	w.WriteHeaderNow()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.Written() {
+		return nil, nil, errors.New("response already written")
+	}
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// CloseNotify is required by gin.ResponseWriter; handlers watch the request
+// context instead
+func (w *responseWriter) CloseNotify() <-chan bool {
+	if notifier, ok := w.ResponseWriter.(http.CloseNotifier); ok {
+		return notifier.CloseNotify()
+	}
+	return make(chan bool)
+}
+
+func (w *responseWriter) Pusher() http.Pusher {
+	pusher, _ := w.ResponseWriter.(http.Pusher)
+	return pusher
+}
+
+// Unwrap lets http.ResponseController reach the middleware's writer
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
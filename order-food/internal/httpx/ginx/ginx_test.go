@@ -0,0 +1,114 @@
+package ginx
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrap(t *testing.T) {
+	// Setup: a value set by Gin middleware reaches the handler
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("principal", "apikey")
+		c.Next()
+	})
+	router.POST("/orders/:orderId/notes", Wrap(func(c httpx.Context) {
+		var req struct {
+			Note string `json:"note" binding:"required"`
+		}
+		if err := c.Bind(&req); err != nil {
+			c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		principal, _ := c.Get("principal")
+		c.JSON(http.StatusCreated, map[string]any{
+			"order":     c.Param("orderId"),
+			"route":     c.Route(),
+			"principal": principal,
+			"note":      req.Note,
+		})
+	}))
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/orders/order-1/notes", bytes.NewBufferString(`{"note":"No onions"}`)))
+	invalid := httptest.NewRecorder()
+	router.ServeHTTP(invalid, httptest.NewRequest("POST", "/orders/order-1/notes", bytes.NewBufferString(`{}`)))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"order":"order-1","route":"/orders/:orderId/notes","principal":"apikey","note":"No onions"}`, w.Body.String())
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+}
+
+func TestMiddleware(t *testing.T) {
+	// Setup: net/http middleware sees Gin's route and shares its values,
+	// and the writer it passes on reaches the handler
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	var seenRoute string
+	router.Use(Middleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seenRoute = httpx.RouteOf(r)
+			if r.Header.Get("X-Refuse") != "" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			r, values := httpx.WithValues(r)
+			values.Set("principal", "apikey")
+			next.ServeHTTP(&taggingWriter{ResponseWriter: w}, r)
+		})
+	}))
+	handled := 0
+	router.GET("/orders/:orderId", func(c *gin.Context) {
+		handled++
+		principal, _ := c.Get("principal")
+		c.JSON(http.StatusAccepted, gin.H{"principal": principal})
+	})
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/orders/order-1", nil))
+	refused := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/orders/order-1", nil)
+	req.Header.Set("X-Refuse", "1")
+	router.ServeHTTP(refused, req)
+
+	// Assert
+	assert.Equal(t, "/orders/:orderId", seenRoute)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.JSONEq(t, `{"principal":"apikey"}`, w.Body.String())
+	assert.Equal(t, "yes", w.Header().Get("X-Tagged"))
+	assert.Equal(t, http.StatusForbidden, refused.Code)
+	assert.Equal(t, 1, handled)
+}
+
+// taggingWriter marks the responses written through it
+type taggingWriter struct {
+	http.ResponseWriter
+}
+
+func (w *taggingWriter) WriteHeader(status int) {
+	w.Header().Set("X-Tagged", "yes")
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func TestRoutes(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/orders/:orderId", func(c *gin.Context) {})
+
+	// Execute
+	routes := Routes(router.Routes())
+
+	// Assert
+	assert.Equal(t, []httpx.Route{{Method: "GET", Path: "/orders/:orderId"}}, routes)
+}
@@ -0,0 +1,73 @@
+// Package httpx is the thin layer between the API handlers and the HTTP
+// framework serving them. Handlers are written against Context, which
+// covers what they need of a request: its context and route parameters,
+// binding its body and rendering the response. Adapters run them on a
+// framework: Std serves them with net/http and package ginx with Gin, so
+// the handlers depend on neither. Middleware is written against net/http
+// and runs on both the same way. This package only imports the standard
+// library and the validator of request models.
+package httpx
+
+import (
+	"context"
+	"net/http"
+)
+
+// Values holds what middleware learned about a request for the handlers,
+// such as the authenticated caller. *gin.Context implements it too, so
+// helpers storing or reading these values serve both sides.
+type Values interface {
+	Get(key any) (value any, exists bool)
+	Set(key any, value any)
+}
+
+// Context is a request as handlers see it
+type Context interface {
+	Values
+
+	// Context returns the context of the request, done when it times out
+	// or the client goes away
+	Context() context.Context
+	// Request returns the request; handlers should prefer the other
+	// methods and use it for what they do not cover, such as streaming
+	// the body
+	Request() *http.Request
+	// Writer returns the response writer, for responses written in parts.
+	// The status set with Status is sent with the first write.
+	Writer() http.ResponseWriter
+
+	// Route returns the path pattern the request was routed by
+	Route() string
+	// Param returns the value of a path parameter of the route
+	Param(name string) string
+	// Query returns the first value of a query parameter, or ""
+	Query(name string) string
+	// GetHeader returns the first value of a request header, or ""
+	GetHeader(name string) string
+	// ClientIP returns the IP address of the client
+	ClientIP() string
+
+	// Bind decodes the JSON body into obj and validates it against the
+	// binding tags of its fields, see BindJSON
+	Bind(obj any) error
+
+	// Header sets a response header; it must be called before the body is
+	// written
+	Header(name, value string)
+	// Status sets the status of the response
+	Status(status int)
+	// JSON writes obj as the JSON body of the response
+	JSON(status int, obj any)
+	// Data writes data as the body of the response
+	Data(status int, contentType string, data []byte)
+}
+
+// HandlerFunc handles a request
+type HandlerFunc func(Context)
+
+// Route is a route registered with a router, for describing the API
+type Route struct {
+	Method string
+	// Path is the path pattern, with parameters written :name or *name
+	Path string
+}
@@ -0,0 +1,63 @@
+package httpx
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// Middleware runs around the handler of a route, such as authentication or
+// access logging. It is written against net/http so it serves every
+// framework: routers on net/http chain it with Chain, and ginx.Middleware
+// runs it on Gin. Middleware answering a request itself does not call
+// next.
+type Middleware func(next http.Handler) http.Handler
+
+// Chain returns h wrapped in middleware, the first running first
+func Chain(h http.Handler, middleware ...Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h
+}
+
+// routeKey is the request context key holding the route of a request
+type routeKey struct{}
+
+// WithRoute returns r routed by route, a path pattern written like
+// Route.Path. Routers set it before running middleware, so middleware
+// configured by route, such as the deprecation notices, sees the same
+// pattern on every framework.
+func WithRoute(r *http.Request, route string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, route))
+}
+
+// RouteOf returns the route set with WithRoute, or "" when the request
+// matched no route
+func RouteOf(r *http.Request) string {
+	route, _ := r.Context().Value(routeKey{}).(string)
+	return route
+}
+
+// clientIPKey is the request context key holding the address of the client
+type clientIPKey struct{}
+
+// WithClientIP returns r from the client at ip, for adapters of frameworks
+// that find the client behind proxies
+func WithClientIP(r *http.Request, ip string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip))
+}
+
+// ClientIP returns the address set with WithClientIP, or else the address
+// the request came from. Behind a proxy that is the proxy's, unless
+// middleware sets RemoteAddr from its headers.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
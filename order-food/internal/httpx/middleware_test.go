@@ -0,0 +1,37 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChain(t *testing.T) {
+	// Setup: the first middleware runs first, and the route and client IP
+	// set for the request reach the handler
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	var route, clientIP string
+	h := Chain(Std(func(c Context) {
+		route, clientIP = c.Route(), c.ClientIP()
+	}), trace("outer"), trace("inner"))
+	r := httptest.NewRequest("GET", "/orders/order-1", nil)
+	r = WithClientIP(WithRoute(r, "/orders/:orderId"), "203.0.113.9")
+
+	// Execute
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	// Assert
+	assert.Equal(t, []string{"outer", "inner"}, order)
+	assert.Equal(t, "/orders/:orderId", route)
+	assert.Equal(t, "203.0.113.9", clientIP)
+}
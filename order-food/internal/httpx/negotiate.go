@@ -0,0 +1,48 @@
+package httpx
+
+import "strings"
+
+// NegotiateFormat returns the first of offered media types the Accept
+// header accept allows, taking its media ranges in the order listed.
+// Quality values are ignored, as Gin does. The first offered type is
+// returned when accept is empty and "" when none is acceptable.
+func NegotiateFormat(accept string, offered ...string) string {
+	var accepted []string
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, _, _ := strings.Cut(part, ";")
+		if mediaRange = strings.TrimSpace(mediaRange); mediaRange != "" {
+			accepted = append(accepted, mediaRange)
+		}
+	}
+	if len(accepted) == 0 && len(offered) > 0 {
+		return offered[0]
+	}
+
+	for _, mediaRange := range accepted {
+		for _, offer := range offered {
+			if mediaTypeMatches(mediaRange, offer) {
+				return offer
+			}
+		}
+	}
+	return ""
+}
+
+// mediaTypeMatches reports whether the media range of an Accept header,
+// such as text/*, includes mediaType
+func mediaTypeMatches(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == "*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(mediaRange, "*"); ok {
+		return strings.HasPrefix(mediaType, prefix)
+	}
+	return strings.EqualFold(mediaRange, mediaType)
+}
+
+// MediaType returns the media type of a Content-Type header without its
+// parameters, e.g. application/json for application/json; charset=utf-8
+func MediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.TrimSpace(mediaType)
+}
@@ -0,0 +1,39 @@
+package httpx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{name: "no accept header", accept: "", want: "application/json"},
+		{name: "exact match", accept: "application/x-ndjson", want: "application/x-ndjson"},
+		{name: "listed first wins", accept: "application/x-ndjson, application/json", want: "application/x-ndjson"},
+		{name: "wildcard first", accept: "*/*, application/x-ndjson", want: "application/json"},
+		{name: "type wildcard", accept: "application/*", want: "application/json"},
+		{name: "parameters ignored", accept: "application/x-ndjson;q=0.9", want: "application/x-ndjson"},
+		{name: "nothing acceptable", accept: "text/html", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Execute
+			got := NegotiateFormat(tt.accept, "application/json", "application/x-ndjson")
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMediaType(t *testing.T) {
+	assert.Equal(t, "application/json", MediaType("application/json; charset=utf-8"))
+	assert.Equal(t, "text/xml", MediaType(" text/xml "))
+	assert.Equal(t, "", MediaType(""))
+}
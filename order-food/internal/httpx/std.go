@@ -0,0 +1,170 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// valuesKey is the request context key holding the Values of a request
+// served with net/http
+type valuesKey struct{}
+
+// valueMap is the Values of a request served with net/http
+type valueMap map[any]any
+
+func (m valueMap) Get(key any) (any, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+func (m valueMap) Set(key any, value any) {
+	m[key] = value
+}
+
+// WithValues returns r with a Values store in its context, creating one
+// unless it has one, and the store. net/http middleware sets values for
+// the handlers with it, e.g. utils.SetPrincipal(values, ...), and passes
+// the returned request on.
+func WithValues(r *http.Request) (*http.Request, Values) {
+	if values, ok := r.Context().Value(valuesKey{}).(Values); ok {
+		return r, values
+	}
+	values := make(valueMap)
+	return UseValues(r, values), values
+}
+
+// UseValues returns r with values as its Values store. Adapters use it to
+// share the store of their framework with net/http middleware.
+func UseValues(r *http.Request, values Values) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), valuesKey{}, values))
+}
+
+// Std serves h with net/http. Path parameters are read with
+// Request.PathValue, so h can be registered on an http.ServeMux, or on any
+// router that sets path values.
+func Std(h HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, values := WithValues(r)
+		c := &stdContext{request: r, writer: &stdWriter{ResponseWriter: w, status: http.StatusOK}, values: values}
+		h(c)
+		c.writer.writeHeader()
+	})
+}
+
+// stdContext is the Context of a request served with net/http
+type stdContext struct {
+	request *http.Request
+	writer  *stdWriter
+	values  Values
+}
+
+func (c *stdContext) Get(key any) (any, bool)      { return c.values.Get(key) }
+func (c *stdContext) Set(key any, value any)       { c.values.Set(key, value) }
+func (c *stdContext) Context() context.Context     { return c.request.Context() }
+func (c *stdContext) Request() *http.Request       { return c.request }
+func (c *stdContext) Writer() http.ResponseWriter  { return c.writer }
+func (c *stdContext) Param(name string) string     { return c.request.PathValue(name) }
+func (c *stdContext) Query(name string) string     { return c.request.URL.Query().Get(name) }
+func (c *stdContext) GetHeader(name string) string { return c.request.Header.Get(name) }
+func (c *stdContext) Header(name, value string)    { c.writer.Header().Set(name, value) }
+func (c *stdContext) Status(status int)            { c.writer.setStatus(status) }
+func (c *stdContext) Bind(obj any) error           { return BindJSON(c.request, obj) }
+func (c *stdContext) Data(status int, contentType string, data []byte) {
+	c.Header("Content-Type", contentType)
+	c.Status(status)
+	_, _ = c.writer.Write(data)
+}
+
+// Route returns the route set with WithRoute, or else the pattern of the
+// http.ServeMux route without its method and host, e.g.
+// /api/v1/orders/{orderId}
+func (c *stdContext) Route() string {
+	if route := RouteOf(c.request); route != "" {
+		return route
+	}
+	pattern := c.request.Pattern
+	if i := strings.Index(pattern, "/"); i >= 0 {
+		return pattern[i:]
+	}
+	return pattern
+}
+
+// ClientIP returns the address the request came from, see ClientIP
+func (c *stdContext) ClientIP() string { return ClientIP(c.request) }
+
+// JSON writes obj like Gin does: marshalled without indentation, and not
+// at all for statuses without a body
+func (c *stdContext) JSON(status int, obj any) {
+	c.Header("Content-Type", "application/json; charset=utf-8")
+	c.Status(status)
+	if !bodyAllowed(status) {
+		c.writer.writeHeader()
+		return
+	}
+	body, err := json.Marshal(obj)
+	if err != nil {
+		slog.ErrorContext(c.Context(), "Failed to encode response", "error", err)
+		c.writer.setStatus(http.StatusInternalServerError)
+		c.writer.writeHeader()
+		return
+	}
+	_, _ = c.writer.Write(body)
+}
+
+// bodyAllowed reports whether a response with status may have a body
+func bodyAllowed(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent, status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// stdWriter holds back the status until the body is written, so headers
+// and status can be set in any order before it, as with Gin
+type stdWriter struct {
+	http.ResponseWriter
+	status  int
+	written bool
+}
+
+func (w *stdWriter) setStatus(status int) {
+	if !w.written {
+		w.status = status
+	}
+}
+
+func (w *stdWriter) writeHeader() {
+	if !w.written {
+		w.written = true
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+func (w *stdWriter) WriteHeader(status int) {
+	w.setStatus(status)
+	w.writeHeader()
+}
+
+func (w *stdWriter) Write(data []byte) (int, error) {
+	w.writeHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush sends what was written so far, for streamed responses
+func (w *stdWriter) Flush() {
+	w.writeHeader()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *stdWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,132 @@
+package httpx
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bindReq struct {
+	Name     string `json:"name" binding:"required,max=5"`
+	Quantity int    `json:"quantity" binding:"min=1"`
+}
+
+func TestStd_RoutesParamsAndJSON(t *testing.T) {
+	// Setup
+	mux := http.NewServeMux()
+	mux.Handle("GET /orders/{orderId}", Std(func(c Context) {
+		c.Header("X-Route", c.Route())
+		c.JSON(http.StatusOK, map[string]string{"id": c.Param("orderId"), "sort": c.Query("sort")})
+	}))
+
+	// Execute
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/orders/order-1?sort=-total", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "/orders/{orderId}", w.Header().Get("X-Route"))
+	assert.Equal(t, `{"id":"order-1","sort":"-total"}`, w.Body.String())
+}
+
+func TestStd_Bind(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{name: "valid", body: `{"name":"Tea","quantity":2}`},
+		{name: "missing required field", body: `{"quantity":2}`, wantErr: true},
+		{name: "rule broken", body: `{"name":"Tea","quantity":0}`, wantErr: true},
+		{name: "malformed", body: `{"name":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			var err error
+			h := Std(func(c Context) {
+				var req bindReq
+				err = c.Bind(&req)
+			})
+
+			// Execute
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", bytes.NewBufferString(tt.body)))
+
+			// Assert
+			assert.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+		})
+	}
+}
+
+func TestStd_StatusIsSentWithTheBody(t *testing.T) {
+	// Setup: headers set after the status still reach the client
+	h := Std(func(c Context) {
+		c.Status(http.StatusAccepted)
+		c.Header("Content-Type", "text/plain")
+		_, _ = c.Writer().Write([]byte("queued"))
+	})
+
+	// Execute
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "queued", w.Body.String())
+}
+
+func TestStd_StatusWithoutBody(t *testing.T) {
+	// Setup
+	h := Std(func(c Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	// Execute
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("DELETE", "/", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, w.Body.String())
+}
+
+func TestStd_ValuesSetByMiddleware(t *testing.T) {
+	// Setup: net/http middleware passes values to the handler
+	var got any
+	h := Std(func(c Context) {
+		got, _ = c.Get("principal")
+	})
+	middleware := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r, values := WithValues(r)
+		values.Set("principal", "partner:acme")
+		h.ServeHTTP(w, r)
+	})
+
+	// Execute
+	middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	// Assert
+	assert.Equal(t, "partner:acme", got)
+}
+
+func TestStd_ClientIP(t *testing.T) {
+	// Setup
+	var got string
+	h := Std(func(c Context) {
+		got = c.ClientIP()
+	})
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "198.51.100.7:51234"
+
+	// Execute
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	// Assert
+	assert.Equal(t, "198.51.100.7", got)
+}
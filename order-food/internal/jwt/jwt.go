@@ -0,0 +1,144 @@
+// Package jwt issues and verifies the JSON Web Tokens customers
+// authenticate with, using github.com/golang-jwt/jwt. Tokens are signed with
+// HMAC-SHA256 (HS256) and carry only the claims this service sets: the
+// customer as subject, the issuer, and the issue, not-before and expiry
+// times. Tokens with another algorithm, including "none", are rejected.
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// Issuer is the iss claim of the tokens this service issues
+	Issuer = "order-food"
+	// DefaultTTL is how long a token is valid after it is issued
+	DefaultTTL = 24 * time.Hour
+	// MinSecretLength is the shortest secret accepted, the size of the
+	// HMAC-SHA256 output
+	MinSecretLength = 32
+)
+
+var (
+	// ErrInvalidToken is returned for a token that is malformed, signed
+	// with an unknown secret or algorithm, issued by someone else or not
+	// valid yet
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrExpiredToken is returned for a token past its expiry time
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+// Claims are the claims of a verified token
+type Claims struct {
+	Subject   string `json:"sub"`
+	Issuer    string `json:"iss"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Signer issues and verifies tokens with one or more shared secrets
+type Signer struct {
+	secrets [][]byte
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// ParseSecrets splits a comma-separated list of secrets, newest first, and
+// checks that each is at least MinSecretLength bytes
+func ParseSecrets(spec string) ([]string, error) {
+	var secrets []string
+	for _, secret := range strings.Split(spec, ",") {
+		secret = strings.TrimSpace(secret)
+		if secret == "" {
+			continue
+		}
+		if len(secret) < MinSecretLength {
+			return nil, fmt.Errorf("secrets must be at least %d bytes", MinSecretLength)
+		}
+		secrets = append(secrets, secret)
+	}
+	if len(secrets) == 0 {
+		return nil, errors.New("no secret given")
+	}
+	return secrets, nil
+}
+
+// NewSigner creates a signer issuing tokens valid for ttl with the first of
+// secrets and accepting tokens signed with any of them. Listing the new
+// secret before the old one rotates secrets without logging customers out.
+func NewSigner(secrets []string, ttl time.Duration) *Signer {
+	s := &Signer{ttl: ttl, now: time.Now}
+	for _, secret := range secrets {
+		s.secrets = append(s.secrets, []byte(secret))
+	}
+	return s
+}
+
+// Issue returns a token for subject with its expiry time
+func (s *Signer) Issue(subject string) (string, time.Time, error) {
+	if len(s.secrets) == 0 {
+		return "", time.Time{}, errors.New("no secret to sign tokens with")
+	}
+	now := s.now()
+	expiresAt := now.Add(s.ttl).Truncate(time.Second)
+	token := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.RegisteredClaims{
+		Subject:   subject,
+		Issuer:    Issuer,
+		IssuedAt:  gojwt.NewNumericDate(now),
+		NotBefore: gojwt.NewNumericDate(now),
+		ExpiresAt: gojwt.NewNumericDate(expiresAt),
+	})
+
+	signed, err := token.SignedString(s.secrets[0])
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// Verify checks the algorithm, signature, issuer, expiry and not-before
+// time of token and returns its claims
+func (s *Signer) Verify(token string) (Claims, error) {
+	parser := gojwt.NewParser(
+		gojwt.WithValidMethods([]string{gojwt.SigningMethodHS256.Alg()}),
+		gojwt.WithIssuer(Issuer),
+		gojwt.WithExpirationRequired(),
+		gojwt.WithIssuedAt(),
+		gojwt.WithTimeFunc(s.now),
+	)
+
+	var registered gojwt.RegisteredClaims
+	_, err := parser.ParseWithClaims(token, &registered, func(*gojwt.Token) (any, error) {
+		keys := gojwt.VerificationKeySet{}
+		for _, secret := range s.secrets {
+			keys.Keys = append(keys.Keys, secret)
+		}
+		return keys, nil
+	})
+	if errors.Is(err, gojwt.ErrTokenExpired) {
+		return Claims{}, ErrExpiredToken
+	}
+	if err != nil || registered.Subject == "" {
+		return Claims{}, ErrInvalidToken
+	}
+
+	return Claims{
+		Subject:   registered.Subject,
+		Issuer:    registered.Issuer,
+		IssuedAt:  unix(registered.IssuedAt),
+		ExpiresAt: unix(registered.ExpiresAt),
+	}, nil
+}
+
+// unix returns a time claim in seconds since the epoch, 0 when it is unset
+func unix(date *gojwt.NumericDate) int64 {
+	if date == nil {
+		return 0
+	}
+	return date.Unix()
+}
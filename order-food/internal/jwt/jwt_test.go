@@ -0,0 +1,105 @@
+package jwt
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	newSecret = "new-secret-of-at-least-32-bytes!!"
+	oldSecret = "old-secret-of-at-least-32-bytes!!"
+)
+
+func TestSigner_Verify(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	issue := func(secret string, at time.Time) string {
+		s := NewSigner([]string{secret}, time.Hour)
+		s.now = func() time.Time { return at }
+		token, _, err := s.Issue("customer-1")
+		assert.NoError(t, err)
+		return token
+	}
+	valid := issue(newSecret, now)
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "valid", token: valid},
+		{name: "signed with previous secret", token: issue(oldSecret, now)},
+		{name: "unknown secret", token: issue("attacker-secret-of-at-least-32-bytes", now), wantErr: ErrInvalidToken},
+		{name: "expired", token: issue(newSecret, now.Add(-time.Hour)), wantErr: ErrExpiredToken},
+		{
+			name:    "unsigned",
+			token:   base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + parts[1] + ".",
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "tampered payload",
+			token:   parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"customer-2","iss":"order-food","exp":9999999999}`)) + "." + parts[2],
+			wantErr: ErrInvalidToken,
+		},
+		{name: "malformed", token: "not-a-token", wantErr: ErrInvalidToken},
+		{name: "not valid yet", token: issue(newSecret, now.Add(time.Hour)), wantErr: ErrInvalidToken},
+		{
+			name:    "other algorithm",
+			token:   sign(t, gojwt.SigningMethodHS512, gojwt.MapClaims{"sub": "customer-1", "iss": Issuer, "exp": now.Add(time.Hour).Unix()}),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "no expiry",
+			token:   sign(t, gojwt.SigningMethodHS256, gojwt.MapClaims{"sub": "customer-1", "iss": Issuer}),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "other issuer",
+			token:   sign(t, gojwt.SigningMethodHS256, gojwt.MapClaims{"sub": "customer-1", "iss": "someone-else", "exp": now.Add(time.Hour).Unix()}),
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			s := NewSigner([]string{newSecret, oldSecret}, time.Hour)
+			s.now = func() time.Time { return now.Add(time.Minute) }
+
+			// Execute
+			claims, err := s.Verify(tt.token)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.Equal(t, "customer-1", claims.Subject)
+				assert.Equal(t, now.Add(time.Hour).Unix(), claims.ExpiresAt)
+			}
+		})
+	}
+}
+
+// sign signs claims with newSecret and method, for tokens Issue does not
+// produce
+func sign(t *testing.T, method gojwt.SigningMethod, claims gojwt.MapClaims) string {
+	token, err := gojwt.NewWithClaims(method, claims).SignedString([]byte(newSecret))
+	assert.NoError(t, err)
+	return token
+}
+
+func TestParseSecrets(t *testing.T) {
+	secrets, err := ParseSecrets(newSecret + ", " + oldSecret)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{newSecret, oldSecret}, secrets)
+
+	_, err = ParseSecrets("short")
+	assert.Error(t, err)
+
+	_, err = ParseSecrets("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+// Package matview keeps materialized views fresh. A view is refreshed by a
+// scheduled task, so one replica at a time does the work, and the state of
+// its refreshes is kept in the database, so every replica can tell how
+// stale the view is and how far a refresh under way has got.
+package matview
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/scheduler"
+)
+
+// Store refreshes materialized views and keeps the state of their
+// refreshes
+type Store interface {
+	// Populated reports whether view holds data; a view created WITH NO
+	// DATA holds none until its first refresh
+	Populated(ctx context.Context, view string) (bool, error)
+	// Refresh refreshes view, concurrently with its readers if asked
+	Refresh(ctx context.Context, view string, concurrently bool) error
+	// StartRefresh records a refresh of view starting at startedAt
+	StartRefresh(ctx context.Context, view, instanceID string, startedAt time.Time) error
+	// FinishRefresh records the end of the refresh started at startedAt,
+	// which failed with refreshErr unless it is nil
+	FinishRefresh(ctx context.Context, view string, startedAt, finishedAt time.Time, refreshErr error) error
+	// LastRefresh returns the latest refresh of view, if any
+	LastRefresh(ctx context.Context, view string) (models.MatviewRefresh, bool, error)
+}
+
+// statusTimeout bounds reading the state of the view for metrics
+const statusTimeout = 2 * time.Second
+
+// Descriptors of the view's metrics
+var (
+	stalenessDesc  = prometheus.NewDesc("matview_staleness_seconds", "Age of the data of each materialized view, since its latest successful refresh started.", []string{"view"}, nil)
+	refreshingDesc = prometheus.NewDesc("matview_refreshing", "Whether each materialized view is being refreshed.", []string{"view"}, nil)
+)
+
+// Refresher refreshes one materialized view. It is safe for concurrent
+// use; refreshes are serialised by the scheduled task running them.
+type Refresher struct {
+	store      Store
+	view       string
+	task       string
+	instanceID string
+	now        func() time.Time
+}
+
+// New creates a refresher for view, refreshed by the scheduled task named
+// task. instanceID is recorded on every refresh so operators can tell which
+// replica did the work.
+func New(store Store, view, task, instanceID string) *Refresher {
+	return &Refresher{store: store, view: view, task: task, instanceID: instanceID, now: time.Now}
+}
+
+// View returns the name of the refreshed view
+func (r *Refresher) View() string {
+	return r.view
+}
+
+// Refresh refreshes the view and records the refresh. Once the view holds
+// data it is refreshed concurrently, so promo code lookups reading it are
+// not blocked; the first refresh of an empty view cannot be.
+func (r *Refresher) Refresh(ctx context.Context) error {
+	startedAt := r.now()
+	if err := r.store.StartRefresh(ctx, r.view, r.instanceID, startedAt); err != nil {
+		return err
+	}
+
+	err := r.refresh(ctx)
+	// Record the end even when ctx was cancelled, or the refresh would be
+	// reported as running until the next one
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusTimeout)
+	defer cancel()
+	if recordErr := r.store.FinishRefresh(recordCtx, r.view, startedAt, r.now(), err); recordErr != nil {
+		slog.Error("Failed to record materialized view refresh", "view", r.view, "error", recordErr)
+	}
+	if err != nil {
+		return err
+	}
+	slog.Info("Refreshed materialized view", "view", r.view, "duration", r.now().Sub(startedAt).Round(time.Millisecond))
+	return nil
+}
+
+func (r *Refresher) refresh(ctx context.Context) error {
+	populated, err := r.store.Populated(ctx, r.view)
+	if err != nil {
+		return err
+	}
+	return r.store.Refresh(ctx, r.view, populated)
+}
+
+// Status describes how current the view is and how far a refresh under way
+// has got, wherever in the cluster it runs
+func (r *Refresher) Status(ctx context.Context) (models.MatviewStatus, error) {
+	status := models.MatviewStatus{View: r.view, Task: r.task}
+	last, found, err := r.store.LastRefresh(ctx, r.view)
+	if err != nil || !found {
+		return status, err
+	}
+
+	now := r.now()
+	status.InstanceID = last.InstanceID
+	status.StartedAt = &last.StartedAt
+	status.Refreshing = last.FinishedAt == nil
+	status.Error = last.Error
+	if last.RefreshedAt != nil {
+		status.RefreshedAt = last.RefreshedAt
+		staleness := now.Sub(*last.RefreshedAt).Seconds()
+		status.StalenessSeconds = &staleness
+	}
+	if last.LastDuration > 0 {
+		status.LastDuration = last.LastDuration.Round(time.Millisecond).String()
+		if status.Refreshing {
+			// Refreshes take about as long as the previous one; one that
+			// takes longer stays at 99% until it finishes
+			progress := min(int(100*now.Sub(last.StartedAt)/last.LastDuration), 99)
+			status.Progress = &progress
+		}
+	}
+	return status, nil
+}
+
+// RefreshOnLoad returns an invalidation handler for the coupons topic that
+// runs the refresh task through trigger when a load announces every code
+// may have changed. Every replica receives the event, so the refresh is
+// left out where one is already under way anywhere in the cluster.
+func (r *Refresher) RefreshOnLoad(trigger func(task string) error) func(key string) {
+	return func(key string) {
+		if key != "*" {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+		defer cancel()
+		if status, err := r.Status(ctx); err == nil && status.Refreshing {
+			return
+		}
+		if err := trigger(r.task); err != nil && !errors.Is(err, scheduler.ErrTaskRunning) {
+			slog.Error("Failed to refresh materialized view after coupon load", "view", r.view, "error", err)
+		}
+	}
+}
+
+// Describe sends the descriptors of the view's metrics
+func (r *Refresher) Describe(ch chan<- *prometheus.Desc) {
+	ch <- stalenessDesc
+	ch <- refreshingDesc
+}
+
+// Collect sends how stale the view is and whether it is being refreshed.
+// Nothing is sent when the state cannot be read.
+func (r *Refresher) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+	defer cancel()
+	status, err := r.Status(ctx)
+	if err != nil {
+		slog.Error("Failed to read materialized view refresh state", "view", r.view, "error", err)
+		return
+	}
+
+	if status.StalenessSeconds != nil {
+		ch <- prometheus.MustNewConstMetric(stalenessDesc, prometheus.GaugeValue, *status.StalenessSeconds, r.view)
+	}
+	refreshing := 0.0
+	if status.Refreshing {
+		refreshing = 1
+	}
+	ch <- prometheus.MustNewConstMetric(refreshingDesc, prometheus.GaugeValue, refreshing, r.view)
+}
@@ -0,0 +1,215 @@
+package matview
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryStore is an in-memory Store recording how views were refreshed
+type memoryStore struct {
+	mu         sync.Mutex
+	populated  bool
+	refreshErr error
+	refreshes  []bool
+	last       map[string]models.MatviewRefresh
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{last: make(map[string]models.MatviewRefresh)}
+}
+
+func (s *memoryStore) Populated(ctx context.Context, view string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.populated, nil
+}
+
+func (s *memoryStore) Refresh(ctx context.Context, view string, concurrently bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshes = append(s.refreshes, concurrently)
+	if s.refreshErr != nil {
+		return s.refreshErr
+	}
+	s.populated = true
+	return nil
+}
+
+func (s *memoryStore) StartRefresh(ctx context.Context, view, instanceID string, startedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refresh := s.last[view]
+	refresh.View, refresh.InstanceID, refresh.StartedAt, refresh.FinishedAt = view, instanceID, startedAt, nil
+	s.last[view] = refresh
+	return nil
+}
+
+func (s *memoryStore) FinishRefresh(ctx context.Context, view string, startedAt, finishedAt time.Time, refreshErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refresh := s.last[view]
+	refresh.FinishedAt = &finishedAt
+	refresh.Error = ""
+	if refreshErr != nil {
+		refresh.Error = refreshErr.Error()
+	} else {
+		refresh.RefreshedAt = &startedAt
+		refresh.LastDuration = finishedAt.Sub(startedAt)
+	}
+	s.last[view] = refresh
+	return nil
+}
+
+func (s *memoryStore) LastRefresh(ctx context.Context, view string) (models.MatviewRefresh, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refresh, found := s.last[view]
+	return refresh, found, nil
+}
+
+// newTestRefresher returns a refresher whose clock advances by step on
+// every reading
+func newTestRefresher(store Store, step time.Duration) (*Refresher, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := New(store, "valid_coupons", "valid-coupons-refresher", "instance-1")
+	r.now = func() time.Time {
+		current := now
+		now = now.Add(step)
+		return current
+	}
+	return r, &now
+}
+
+func TestRefresher_Refresh_ConcurrentOncePopulated(t *testing.T) {
+	// Setup
+	store := newMemoryStore()
+	r, _ := newTestRefresher(store, 10*time.Second)
+
+	// Execute
+	assert.NoError(t, r.Refresh(context.Background()))
+	assert.NoError(t, r.Refresh(context.Background()))
+
+	// Assert: the first refresh fills the empty view, later ones run
+	// alongside its readers
+	assert.Equal(t, []bool{false, true}, store.refreshes)
+	refresh := store.last["valid_coupons"]
+	assert.Equal(t, "instance-1", refresh.InstanceID)
+	assert.NotNil(t, refresh.FinishedAt)
+	assert.Equal(t, refresh.StartedAt, *refresh.RefreshedAt)
+}
+
+func TestRefresher_Refresh_FailureKeepsLastSuccess(t *testing.T) {
+	// Setup
+	store := newMemoryStore()
+	r, _ := newTestRefresher(store, 10*time.Second)
+	assert.NoError(t, r.Refresh(context.Background()))
+	refreshedAt := *store.last["valid_coupons"].RefreshedAt
+	store.refreshErr = errors.New("canceling statement due to statement timeout")
+
+	// Execute
+	err := r.Refresh(context.Background())
+	status, statusErr := r.Status(context.Background())
+
+	// Assert
+	assert.ErrorIs(t, err, store.refreshErr)
+	assert.NoError(t, statusErr)
+	assert.False(t, status.Refreshing)
+	assert.Equal(t, refreshedAt, *status.RefreshedAt)
+	assert.Equal(t, "canceling statement due to statement timeout", status.Error)
+}
+
+func TestRefresher_Status(t *testing.T) {
+	// Setup
+	store := newMemoryStore()
+	r, now := newTestRefresher(store, 0)
+
+	// Before the first refresh only the view and task are known
+	status, err := r.Status(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, models.MatviewStatus{View: "valid_coupons", Task: "valid-coupons-refresher"}, status)
+
+	// A refresh that took 50s finished a minute ago
+	started := now.Add(-2 * time.Minute)
+	finished := started.Add(50 * time.Second)
+	store.last["valid_coupons"] = models.MatviewRefresh{
+		View: "valid_coupons", InstanceID: "instance-2", StartedAt: started,
+		FinishedAt: &finished, RefreshedAt: &started, LastDuration: 50 * time.Second,
+	}
+	status, err = r.Status(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, status.Refreshing)
+	assert.Nil(t, status.Progress)
+	assert.Equal(t, 120.0, *status.StalenessSeconds)
+	assert.Equal(t, "50s", status.LastDuration)
+
+	// Another replica started refreshing 20s ago
+	assert.NoError(t, store.StartRefresh(context.Background(), "valid_coupons", "instance-3", now.Add(-20*time.Second)))
+	status, err = r.Status(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, status.Refreshing)
+	assert.Equal(t, "instance-3", status.InstanceID)
+	assert.Equal(t, 40, *status.Progress)
+	assert.Equal(t, 120.0, *status.StalenessSeconds)
+
+	// A refresh outlasting the previous one stays just short of done
+	assert.NoError(t, store.StartRefresh(context.Background(), "valid_coupons", "instance-3", now.Add(-90*time.Second)))
+	status, err = r.Status(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 99, *status.Progress)
+}
+
+func TestRefresher_Collect(t *testing.T) {
+	// Setup
+	store := newMemoryStore()
+	r, _ := newTestRefresher(store, 0)
+
+	// Execute: before the first refresh there is no staleness to report
+	err := testutil.CollectAndCompare(r, strings.NewReader(`
+# HELP matview_refreshing Whether each materialized view is being refreshed.
+# TYPE matview_refreshing gauge
+matview_refreshing{view="valid_coupons"} 0
+`))
+	assert.NoError(t, err)
+
+	// Execute
+	assert.NoError(t, r.Refresh(context.Background()))
+
+	// Assert
+	err = testutil.CollectAndCompare(r, strings.NewReader(`
+# HELP matview_refreshing Whether each materialized view is being refreshed.
+# TYPE matview_refreshing gauge
+matview_refreshing{view="valid_coupons"} 0
+# HELP matview_staleness_seconds Age of the data of each materialized view, since its latest successful refresh started.
+# TYPE matview_staleness_seconds gauge
+matview_staleness_seconds{view="valid_coupons"} 0
+`))
+	assert.NoError(t, err)
+}
+
+func TestRefresher_RefreshOnLoad(t *testing.T) {
+	// Setup
+	store := newMemoryStore()
+	r, now := newTestRefresher(store, 0)
+	var triggered []string
+	handle := r.RefreshOnLoad(func(task string) error {
+		triggered = append(triggered, task)
+		return nil
+	})
+
+	// Execute: campaigns do not change the coupons table
+	handle("campaign/42")
+	handle("*")
+	assert.NoError(t, store.StartRefresh(context.Background(), "valid_coupons", "instance-2", *now))
+	handle("*")
+
+	// Assert: one refresh, not repeated while another replica runs it
+	assert.Equal(t, []string{"valid-coupons-refresher"}, triggered)
+}
@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"strings"
 
-	"github.com/gin-gonic/gin"
-	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
 )
 
 const (
@@ -12,25 +14,160 @@ const (
 	ValidAPIKey = "apitest"
 	// APIKeyHeader is the header name for the API key
 	APIKeyHeader = "api_key"
+	// AdminKeyHeader is the header name for the admin API key
+	AdminKeyHeader = "admin_key"
+	// AuthorizationHeader carries customer access tokens as "Bearer <token>"
+	AuthorizationHeader = "Authorization"
 )
 
-// AuthMiddleware validates the API key from the request header
-func AuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		apiKey := c.GetHeader(APIKeyHeader)
+// APIKeyVerifier resolves an API key that is not the built-in key to a
+// caller ID and its scopes. It reports false for unknown keys.
+type APIKeyVerifier func(key string) (id string, scopes []string, ok bool)
 
-		if apiKey == "" {
-			c.JSON(http.StatusUnauthorized, models.ErrorResponse(http.StatusUnauthorized, "Unauthorized: API key is required"))
-			c.Abort()
-			return
-		}
+// TokenVerifier resolves a customer access token to the customer's ID and
+// scopes. It returns an error for tokens that are invalid or expired.
+type TokenVerifier func(token string) (customerID string, scopes []string, err error)
+
+// CustomerAuthMiddleware authenticates customers that send an access token
+// in the Authorization header. Requests without one are passed on to the
+// API key check; requests with an invalid or expired one are rejected.
+func CustomerAuthMiddleware(verify TokenVerifier) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get(AuthorizationHeader)
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scheme, token, _ := strings.Cut(header, " ")
+			if !strings.EqualFold(scheme, "Bearer") || token == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				writeError(w, http.StatusUnauthorized, "Unauthorized: Authorization header must be a Bearer token")
+				return
+			}
+
+			customerID, scopes, err := verify(strings.TrimSpace(token))
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				writeError(w, http.StatusUnauthorized, "Unauthorized: access token is invalid or expired")
+				return
+			}
+
+			r, values := httpx.WithValues(r)
+			utils.SetPrincipal(values, utils.CustomerPrincipal(customerID), scopes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AuthMiddleware validates the API key from the request header. The built-in
+// key is granted every scope; other keys are checked against the verifiers.
+// Callers already authenticated by CustomerAuthMiddleware need no key.
+func AuthMiddleware(verifiers ...APIKeyVerifier) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, values := httpx.WithValues(r)
+			if utils.PrincipalFromContext(values) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			apiKey := r.Header.Get(APIKeyHeader)
+
+			if apiKey == "" {
+				writeError(w, http.StatusUnauthorized, "Unauthorized: API key is required")
+				return
+			}
+
+			if !authenticate(values, apiKey, verifiers) {
+				writeError(w, http.StatusForbidden, "Forbidden: Invalid API key")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// OptionalAuthMiddleware authenticates callers that send an API key, like
+// AuthMiddleware, and lets callers without one through anonymously
+func OptionalAuthMiddleware(verifiers ...APIKeyVerifier) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, values := httpx.WithValues(r)
+			apiKey := r.Header.Get(APIKeyHeader)
+
+			if apiKey != "" && !authenticate(values, apiKey, verifiers) {
+				writeError(w, http.StatusForbidden, "Forbidden: Invalid API key")
+				return
+			}
 
-		if apiKey != ValidAPIKey {
-			c.JSON(http.StatusForbidden, models.ErrorResponse(http.StatusForbidden, "Forbidden: Invalid API key"))
-			c.Abort()
-			return
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authenticate stores the caller identified by apiKey in values and
+// reports whether the key is valid
+func authenticate(values httpx.Values, apiKey string, verifiers []APIKeyVerifier) bool {
+	if apiKey == ValidAPIKey {
+		utils.SetPrincipal(values, "apikey", []string{utils.ScopeAll})
+		return true
+	}
+
+	for _, verify := range verifiers {
+		if id, scopes, ok := verify(apiKey); ok {
+			utils.SetPrincipal(values, utils.PartnerPrincipal(id), scopes)
+			return true
 		}
+	}
+
+	return false
+}
+
+// RequireScope rejects callers authenticated by AuthMiddleware that were
+// granted none of scopes
+func RequireScope(scopes ...string) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, values := httpx.WithValues(r)
+			for _, scope := range scopes {
+				if utils.HasScope(values, scope) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			writeError(w, http.StatusForbidden, "Forbidden: API key lacks scope "+strings.Join(scopes, " or "))
+		})
+	}
+}
+
+// AdminAuthMiddleware validates the admin API key. When no admin key is
+// configured every request is rejected so admin routes stay closed.
+func AdminAuthMiddleware(adminKey string) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if adminKey == "" {
+				writeError(w, http.StatusNotFound, "Admin API is not enabled")
+				return
+			}
+
+			key := r.Header.Get(AdminKeyHeader)
+			if key == "" {
+				writeError(w, http.StatusUnauthorized, "Unauthorized: admin key is required")
+				return
+			}
+
+			if subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) != 1 {
+				writeError(w, http.StatusForbidden, "Forbidden: Invalid admin key")
+				return
+			}
 
-		c.Next()
+			r, values := httpx.WithValues(r)
+			utils.SetPrincipal(values, "admin", []string{utils.ScopeAll})
+			next.ServeHTTP(w, r)
+		})
 	}
 }
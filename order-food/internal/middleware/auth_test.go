@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -13,7 +16,7 @@ func TestAuthMiddleware_ValidAPIKey(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(AuthMiddleware())
+	router.Use(ginx.Middleware(AuthMiddleware()))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
@@ -35,7 +38,7 @@ func TestAuthMiddleware_MissingAPIKey(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(AuthMiddleware())
+	router.Use(ginx.Middleware(AuthMiddleware()))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
@@ -56,7 +59,7 @@ func TestAuthMiddleware_InvalidAPIKey(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(AuthMiddleware())
+	router.Use(ginx.Middleware(AuthMiddleware()))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
@@ -78,7 +81,7 @@ func TestAuthMiddleware_EmptyAPIKey(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(AuthMiddleware())
+	router.Use(ginx.Middleware(AuthMiddleware()))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
@@ -100,7 +103,7 @@ func TestAuthMiddleware_CaseSensitive(t *testing.T) {
 	// Setup
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(AuthMiddleware())
+	router.Use(ginx.Middleware(AuthMiddleware()))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
@@ -122,7 +125,7 @@ func TestAuthMiddleware_NextCalledOnSuccess(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	handlerCalled := false
 	router := gin.New()
-	router.Use(AuthMiddleware())
+	router.Use(ginx.Middleware(AuthMiddleware()))
 	router.GET("/test", func(c *gin.Context) {
 		handlerCalled = true
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
@@ -146,7 +149,7 @@ func TestAuthMiddleware_AbortOnFailure(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	handlerCalled := false
 	router := gin.New()
-	router.Use(AuthMiddleware())
+	router.Use(ginx.Middleware(AuthMiddleware()))
 	router.GET("/test", func(c *gin.Context) {
 		handlerCalled = true
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
@@ -163,3 +166,207 @@ func TestAuthMiddleware_AbortOnFailure(t *testing.T) {
 	assert.False(t, handlerCalled)
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+func TestAuthMiddleware_VerifiedPartnerKey(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	verifier := func(key string) (string, []string, bool) {
+		return "p1", []string{"orders:read"}, key == "pk_valid"
+	}
+	router := gin.New()
+	router.Use(ginx.Middleware(AuthMiddleware(verifier)))
+	router.GET("/test", ginx.Middleware(RequireScope("orders:read")), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"principal": utils.PrincipalFromContext(c)})
+	})
+	router.POST("/test", ginx.Middleware(RequireScope("orders:write")), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	// Execute - granted scope
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(APIKeyHeader, "pk_valid")
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "partner:p1")
+
+	// Execute - missing scope
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/test", nil)
+	req.Header.Set(APIKeyHeader, "pk_valid")
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "orders:write")
+
+	// Execute - unknown key
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(APIKeyHeader, "pk_unknown")
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRequireScope_BuiltInKeyHasAllScopes(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(AuthMiddleware()))
+	router.POST("/test", ginx.Middleware(RequireScope("orders:import")), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	// Create request with the built-in API key
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/test", nil)
+	req.Header.Set(APIKeyHeader, ValidAPIKey)
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCustomerAuthMiddleware(t *testing.T) {
+	verifier := func(token string) (string, []string, error) {
+		if token != "valid-token" {
+			return "", nil, errors.New("invalid token")
+		}
+		return "c1", []string{"orders:read", "orders:place"}, nil
+	}
+	tests := []struct {
+		name          string
+		authorization string
+		apiKey        string
+		wantStatus    int
+		wantPrincipal string
+	}{
+		{name: "customer token", authorization: "Bearer valid-token", wantStatus: http.StatusOK, wantPrincipal: "customer:c1"},
+		{name: "scheme is case-insensitive", authorization: "bearer valid-token", wantStatus: http.StatusOK, wantPrincipal: "customer:c1"},
+		{name: "token takes precedence over API key", authorization: "Bearer valid-token", apiKey: ValidAPIKey, wantStatus: http.StatusOK, wantPrincipal: "customer:c1"},
+		{name: "API key without token", apiKey: ValidAPIKey, wantStatus: http.StatusOK, wantPrincipal: "apikey"},
+		{name: "invalid token", authorization: "Bearer forged", apiKey: ValidAPIKey, wantStatus: http.StatusUnauthorized},
+		{name: "other scheme", authorization: "Basic dXNlcjpwYXNz", wantStatus: http.StatusUnauthorized},
+		{name: "neither", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(ginx.Middleware(CustomerAuthMiddleware(verifier)), ginx.Middleware(AuthMiddleware()))
+			var principal string
+			router.GET("/test", ginx.Middleware(RequireScope("orders:write", "orders:place")), func(c *gin.Context) {
+				principal = utils.PrincipalFromContext(c)
+				c.Status(http.StatusOK)
+			})
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.authorization != "" {
+				req.Header.Set(AuthorizationHeader, tt.authorization)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+
+			// Execute
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantPrincipal, principal)
+			if tt.authorization != "" && tt.wantStatus == http.StatusUnauthorized {
+				assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		adminKey string
+		header   string
+		want     int
+	}{
+		{name: "disabled when unset", adminKey: "", header: "anything", want: http.StatusNotFound},
+		{name: "missing key", adminKey: "secret", header: "", want: http.StatusUnauthorized},
+		{name: "wrong key", adminKey: "secret", header: "nope", want: http.StatusForbidden},
+		{name: "valid key", adminKey: "secret", header: "secret", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(ginx.Middleware(AdminAuthMiddleware(tt.adminKey)))
+			router.GET("/test", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.header != "" {
+				req.Header.Set(AdminKeyHeader, tt.header)
+			}
+
+			// Execute
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestOptionalAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		apiKey        string
+		wantStatus    int
+		wantPrincipal string
+	}{
+		{name: "anonymous", wantStatus: http.StatusOK},
+		{name: "valid key", apiKey: ValidAPIKey, wantStatus: http.StatusOK, wantPrincipal: "apikey"},
+		{name: "invalid key", apiKey: "wrong", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(ginx.Middleware(OptionalAuthMiddleware()))
+			var principal string
+			router.GET("/test", func(c *gin.Context) {
+				principal = utils.PrincipalFromContext(c)
+				c.Status(http.StatusOK)
+			})
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+
+			// Execute
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantPrincipal, principal)
+		})
+	}
+}
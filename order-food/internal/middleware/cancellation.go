@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+)
+
+// StatusClientClosedRequest is logged for requests whose client went away
+// before a response was written. It is never sent, as nobody is listening.
+const StatusClientClosedRequest = 499
+
+// CancellationMiddleware bounds every request by timeout (no bound when it
+// is zero) and makes sure handlers see the client going away. The request
+// context is what handlers pass down to the repositories, so either event
+// cancels the queries still running for the request.
+func CancellationMiddleware(timeout time.Duration) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithCancel(r.Context())
+			if timeout > 0 {
+				ctx, cancel = context.WithTimeout(r.Context(), timeout)
+			}
+			defer cancel()
+
+			writer := newResponseWriter(w)
+			next.ServeHTTP(writer, r.WithContext(ctx))
+
+			switch {
+			case errors.Is(r.Context().Err(), context.Canceled):
+				slog.InfoContext(r.Context(), "Client closed the request before the response was sent", "method", r.Method, "path", r.URL.Path)
+			case errors.Is(ctx.Err(), context.DeadlineExceeded) && !writer.Written():
+				writeError(w, http.StatusGatewayTimeout, "Request timed out")
+			}
+		})
+	}
+}
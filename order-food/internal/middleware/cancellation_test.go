@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancellationMiddleware_Timeout(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(CancellationMiddleware(20 * time.Millisecond)))
+	router.GET("/slow", func(c *gin.Context) {
+		// A handler that gives up when its context does, without answering
+		<-c.Request.Context().Done()
+	})
+
+	// Create request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/slow", nil)
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Contains(t, w.Body.String(), "Request timed out")
+}
+
+func TestCancellationMiddleware_NoTimeout(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(CancellationMiddleware(0)))
+	router.GET("/test", func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		assert.False(t, hasDeadline)
+		c.Status(http.StatusNoContent)
+	})
+
+	// Create request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test", nil)
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestCancellationMiddleware_AnsweredBeforeTimeout(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(CancellationMiddleware(time.Minute)))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	// Create request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test", nil)
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCancellationMiddleware_ClientGone(t *testing.T) {
+	// Setup - capture log output
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(LoggerMiddleware()))
+	router.Use(ginx.Middleware(CancellationMiddleware(time.Minute)))
+	handlerErr := make(chan error, 1)
+	router.GET("/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		handlerErr <- c.Request.Context().Err()
+	})
+
+	// Create request whose client disconnects straight away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/slow", nil).WithContext(ctx)
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.ErrorIs(t, <-handlerErr, context.Canceled)
+	assert.Contains(t, buf.String(), "Client closed the request before the response was sent method=GET path=/slow")
+	assert.Contains(t, buf.String(), "status=499")
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+)
+
+const (
+	// ChaosLatencyHeader requests added latency, as a Go duration
+	ChaosLatencyHeader = "X-Chaos-Latency"
+	// ChaosErrorHeader requests an injected error response with the given status
+	ChaosErrorHeader = "X-Chaos-Error"
+	// ChaosDropHeader requests that the connection is dropped without a response
+	ChaosDropHeader = "X-Chaos-Drop"
+	// ChaosInjectedHeader names the faults injected into a response
+	ChaosInjectedHeader = "X-Chaos-Injected"
+)
+
+// ChaosRule injects faults into requests for one route
+type ChaosRule struct {
+	// Route is "METHOD /path" using the router's path pattern, for example
+	// "GET /api/v1/products/:productId", or "*" for every route
+	Route string
+	// Latency is added before the request is handled
+	Latency time.Duration
+	// ErrorRate is the fraction of requests answered with ErrorStatus
+	ErrorRate float64
+	// ErrorStatus is the status of injected errors; 503 when zero
+	ErrorStatus int
+	// DropRate is the fraction of requests whose connection is closed
+	// without a response
+	DropRate float64
+}
+
+// ChaosConfig controls fault injection for resilience testing
+type ChaosConfig struct {
+	Rules []ChaosRule
+	// AllowHeaders lets callers request faults with the X-Chaos-* headers
+	AllowHeaders bool
+}
+
+// ChaosMiddleware injects latency, errors and dropped connections according
+// to the configured rules and, when allowed, the X-Chaos-* request headers.
+// It must only be enabled outside production.
+func ChaosMiddleware(cfg ChaosConfig) httpx.Middleware {
+	return chaosMiddleware(cfg, rand.Float64)
+}
+
+// chaosMiddleware is ChaosMiddleware with an injectable random source
+func chaosMiddleware(cfg ChaosConfig, random func() float64) httpx.Middleware {
+	rules := make(map[string]ChaosRule, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		rules[rule.Route] = rule
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rule, ok := rules[r.Method+" "+httpx.RouteOf(r)]
+			if !ok {
+				rule = rules["*"]
+			}
+			if cfg.AllowHeaders {
+				applyChaosHeaders(r, &rule)
+			}
+
+			var injected []string
+			if rule.Latency > 0 {
+				injected = append(injected, "latency")
+				select {
+				case <-time.After(rule.Latency):
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			if rule.DropRate > 0 && random() < rule.DropRate {
+				dropConnection(w)
+				return
+			}
+
+			if rule.ErrorRate > 0 && random() < rule.ErrorRate {
+				status := rule.ErrorStatus
+				if status == 0 {
+					status = http.StatusServiceUnavailable
+				}
+				w.Header().Set(ChaosInjectedHeader, strings.Join(append(injected, "error"), ","))
+				writeError(w, status, "Injected fault")
+				return
+			}
+
+			if len(injected) > 0 {
+				w.Header().Set(ChaosInjectedHeader, strings.Join(injected, ","))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// applyChaosHeaders overrides rule with the faults requested by the caller
+func applyChaosHeaders(r *http.Request, rule *ChaosRule) {
+	if value := r.Header.Get(ChaosLatencyHeader); value != "" {
+		if latency, err := time.ParseDuration(value); err == nil {
+			rule.Latency = latency
+		}
+	}
+	if value := r.Header.Get(ChaosErrorHeader); value != "" {
+		if status, err := strconv.Atoi(value); err == nil && status >= 400 && status <= 599 {
+			rule.ErrorRate = 1
+			rule.ErrorStatus = status
+		}
+	}
+	if r.Header.Get(ChaosDropHeader) == "true" {
+		rule.DropRate = 1
+	}
+}
+
+// dropConnection closes the client connection without writing a response.
+// Writers that cannot be hijacked (HTTP/2) get an empty 502 instead.
+func dropConnection(w http.ResponseWriter) {
+	if conn, _, err := http.NewResponseController(w).Hijack(); err == nil {
+		conn.Close()
+		return
+	}
+	w.Header().Set(ChaosInjectedHeader, "drop")
+	w.WriteHeader(http.StatusBadGateway)
+}
+
+// ParseChaosRules parses a CHAOS_RULES specification: rules separated by
+// ";", each a route followed by "=" and comma-separated settings, e.g.
+//
+//	GET /api/v1/products=latency:200ms,error:0.1;*=drop:0.01,error:0.05,status:500
+func ParseChaosRules(spec string) ([]ChaosRule, error) {
+	var rules []ChaosRule
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, settings, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("chaos rule %q: expected route=settings", entry)
+		}
+		rule := ChaosRule{Route: strings.Join(strings.Fields(route), " ")}
+
+		for _, setting := range strings.Split(settings, ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(setting), ":")
+			var err error
+			switch key {
+			case "latency":
+				rule.Latency, err = time.ParseDuration(value)
+			case "error":
+				rule.ErrorRate, err = parseRate(value)
+			case "drop":
+				rule.DropRate, err = parseRate(value)
+			case "status":
+				rule.ErrorStatus, err = strconv.Atoi(value)
+				if err == nil && (rule.ErrorStatus < 400 || rule.ErrorStatus > 599) {
+					err = fmt.Errorf("status %d is not an error status", rule.ErrorStatus)
+				}
+			default:
+				err = fmt.Errorf("unknown setting %q", key)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("chaos rule %q: %w", rule.Route, err)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseRate parses a fraction between 0 and 1
+func parseRate(value string) (float64, error) {
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("rate %q must be between 0 and 1", value)
+	}
+	return rate, nil
+}
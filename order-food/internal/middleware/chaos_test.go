@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/stretchr/testify/assert"
+)
+
+// newChaosRouter returns a router with one products route behind the chaos
+// middleware, using a fixed random value
+func newChaosRouter(cfg ChaosConfig, random float64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(chaosMiddleware(cfg, func() float64 { return random })))
+	router.GET("/api/v1/products/:productId", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestChaosMiddleware_InjectsErrorsPerRoute(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []ChaosRule
+		random   float64
+		wantCode int
+	}{
+		{name: "no rules", wantCode: http.StatusOK},
+		{
+			name:     "route rule hit",
+			rules:    []ChaosRule{{Route: "GET /api/v1/products/:productId", ErrorRate: 0.5, ErrorStatus: 500}},
+			random:   0.2,
+			wantCode: http.StatusInternalServerError,
+		},
+		{
+			name:     "route rule miss",
+			rules:    []ChaosRule{{Route: "GET /api/v1/products/:productId", ErrorRate: 0.5}},
+			random:   0.7,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "other route only",
+			rules:    []ChaosRule{{Route: "POST /api/v1/orders", ErrorRate: 1}},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "wildcard rule with default status",
+			rules:    []ChaosRule{{Route: "*", ErrorRate: 1}},
+			wantCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			router := newChaosRouter(ChaosConfig{Rules: tt.rules}, tt.random)
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/api/v1/products/1", nil)
+
+			// Execute
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func TestChaosMiddleware_Headers(t *testing.T) {
+	// Setup
+	router := newChaosRouter(ChaosConfig{AllowHeaders: true}, 0)
+
+	// Create request asking for latency and an error
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/products/1", nil)
+	req.Header.Set(ChaosLatencyHeader, "20ms")
+	req.Header.Set(ChaosErrorHeader, "429")
+
+	// Execute
+	start := time.Now()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "latency,error", w.Header().Get(ChaosInjectedHeader))
+}
+
+func TestChaosMiddleware_IgnoresHeadersUnlessAllowed(t *testing.T) {
+	// Setup
+	router := newChaosRouter(ChaosConfig{}, 0)
+
+	// Create request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/products/1", nil)
+	req.Header.Set(ChaosErrorHeader, "500")
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(ChaosInjectedHeader))
+}
+
+func TestChaosMiddleware_DropsConnection(t *testing.T) {
+	// Setup
+	router := newChaosRouter(ChaosConfig{Rules: []ChaosRule{{Route: "*", DropRate: 1}}}, 0)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	// Execute
+	resp, err := http.Get(server.URL + "/api/v1/products/1")
+
+	// Assert
+	if err == nil {
+		resp.Body.Close()
+	}
+	assert.Error(t, err)
+}
+
+func TestParseChaosRules(t *testing.T) {
+	rules, err := ParseChaosRules("GET  /api/v1/products=latency:200ms,error:0.1; *=drop:0.01,error:0.05,status:500")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []ChaosRule{
+		{Route: "GET /api/v1/products", Latency: 200 * time.Millisecond, ErrorRate: 0.1},
+		{Route: "*", DropRate: 0.01, ErrorRate: 0.05, ErrorStatus: 500},
+	}, rules)
+
+	for _, spec := range []string{"GET /x", "*=error:2", "*=status:200", "*=jitter:1s"} {
+		_, err := ParseChaosRules(spec)
+		assert.Error(t, err, spec)
+	}
+}
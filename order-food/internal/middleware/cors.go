@@ -1,22 +1,26 @@
 package middleware
 
 import (
-	"github.com/gin-gonic/gin"
-)
+	"net/http"
 
-// CORSMiddleware handles Cross-Origin Resource Sharing
-func CORSMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, api_key")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+)
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+// CORSMiddleware sets the Cross-Origin Resource Sharing headers of every
+// response
+func CORSMiddleware() httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			header.Set("Access-Control-Allow-Origin", "*")
+			header.Set("Access-Control-Allow-Credentials", "true")
+			header.Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID, api_key, Idempotency-Key, If-None-Match")
+			header.Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+			header.Set("Access-Control-Expose-Headers", "Link, X-Instance-ID, X-Request-ID, Idempotent-Replayed, ETag")
 
-		c.Next()
+			// OPTIONS requests, CORS preflight included, are answered by the
+			// OPTIONS route of their path with the methods it allows
+			next.ServeHTTP(w, r)
+		})
 	}
 }
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/deprecation"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+)
+
+// maxDeprecatedFieldScanBytes caps how much of a request body is read to
+// look for deprecated fields; larger bodies are passed on unchecked
+const maxDeprecatedFieldScanBytes = 1 << 20
+
+// DeprecationMiddleware announces deprecated routes, and deprecated fields
+// a request sets, with the Deprecation, Sunset and Link headers and counts
+// each use in registry. Fields are found among the query parameters and
+// the top-level fields of a JSON body. Deprecated elements keep working
+// until they are removed from the router.
+func DeprecationMiddleware(registry *deprecation.Registry) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := registry.Lookup(r.Method, httpx.RouteOf(r))
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			if route.Deprecation != nil {
+				route.Deprecation.SetHeaders(header)
+				registry.Record(*route.Deprecation)
+			}
+			if len(route.Fields) > 0 {
+				query := r.URL.Query()
+				body := jsonBodyFields(r)
+				for name, field := range route.Fields {
+					if query.Has(name) || body[name] {
+						field.SetHeaders(header)
+						registry.Record(field)
+					}
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// jsonBodyFields returns the names of the top-level fields of a JSON
+// object body and leaves the body to be read again by the handler
+func jsonBodyFields(r *http.Request) map[string]bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if r.Body == nil || mediaType != "application/json" {
+		return nil
+	}
+
+	prefix, err := io.ReadAll(io.LimitReader(r.Body, maxDeprecatedFieldScanBytes+1))
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(prefix), r.Body), r.Body}
+	if err != nil || len(prefix) > maxDeprecatedFieldScanBytes {
+		return nil
+	}
+
+	var object map[string]json.RawMessage
+	if json.Unmarshal(prefix, &object) != nil {
+		return nil
+	}
+	fields := make(map[string]bool, len(object))
+	for name := range object {
+		fields[name] = true
+	}
+	return fields
+}
+
+// readCloser reads the buffered and the remaining body and closes the
+// original
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/deprecation"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecationMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	deprecations, err := deprecation.Parse("GET /orders/:orderId since=2026-10-01 sunset=2027-04-01 successor=/api/v2/orders; POST /orders#couponCode since=2026-10-01; POST /orders#legacy since=2026-10-01")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		body            string
+		wantDeprecation bool
+		wantSunset      string
+		wantLink        string
+	}{
+		{name: "deprecated route", method: http.MethodGet, path: "/orders/1", wantDeprecation: true, wantSunset: "Thu, 01 Apr 2027 00:00:00 GMT", wantLink: `</api/v2/orders>; rel="successor-version"`},
+		{name: "deprecated body field", method: http.MethodPost, path: "/orders", body: `{"couponCode":"HAPPYHRS","items":[]}`, wantDeprecation: true},
+		{name: "deprecated query parameter", method: http.MethodPost, path: "/orders?legacy=1", body: `{"items":[]}`, wantDeprecation: true},
+		{name: "current fields only", method: http.MethodPost, path: "/orders", body: `{"items":[]}`},
+		{name: "other route", method: http.MethodGet, path: "/products"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			registry, err := deprecation.NewRegistry(deprecations)
+			assert.NoError(t, err)
+			var received string
+			router := gin.New()
+			router.Use(ginx.Middleware(DeprecationMiddleware(registry)))
+			handle := func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				received = string(body)
+				c.Status(http.StatusNoContent)
+			}
+			router.GET("/orders/:orderId", handle)
+			router.POST("/orders", handle)
+			router.GET("/products", handle)
+
+			// Execute
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json; charset=utf-8")
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			assert.Equal(t, tt.body, received, "the handler reads the whole body")
+			assert.Equal(t, tt.wantDeprecation, w.Header().Get(deprecation.DeprecationHeader) == "@1790812800")
+			assert.Equal(t, tt.wantSunset, w.Header().Get(deprecation.SunsetHeader))
+			assert.Equal(t, tt.wantLink, w.Header().Get(deprecation.LinkHeader))
+			var uses int64
+			for _, d := range registry.Deprecations() {
+				uses += registry.Uses(d)
+			}
+			assert.Equal(t, map[bool]int64{true: 1, false: 0}[tt.wantDeprecation], uses)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETagMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(ETagMiddleware()))
+	body := gin.H{"id": "1", "name": "Waffle"}
+	router.GET("/products/:productId", func(c *gin.Context) {
+		if c.Param("productId") == "missing" {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Product not found"})
+			return
+		}
+		c.JSON(http.StatusOK, body)
+	})
+	router.GET("/stream", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		c.Writer.Flush()
+		c.Writer.WriteString("{}\n")
+	})
+
+	get := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// A response gets an ETag
+	w := get("/products/1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"1","name":"Waffle"}`, w.Body.String())
+	etag := w.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{32}"$`, etag)
+
+	// The same response again is not sent
+	w = get("/products/1", `"other", W/`+etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+
+	// A changed response is sent with a new ETag
+	body = gin.H{"id": "1", "name": "Pancake"}
+	w = get("/products/1", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"1","name":"Pancake"}`, w.Body.String())
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	// Errors get no ETag
+	w = get("/products/missing", "*")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), "Product not found")
+
+	// Streamed responses are passed through
+	w = get("/stream", "*")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "{}\n", w.Body.String())
+	assert.Empty(t, w.Header().Get("ETag"))
+}
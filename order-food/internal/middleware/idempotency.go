@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// Idempotency headers
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// maxIdempotencyKeyLength matches the width of idempotency_keys.idempotency_key
+const maxIdempotencyKeyLength = 255
+
+// maxIdempotencyBodyBytes bounds how much of a request is read to fingerprint it
+const maxIdempotencyBodyBytes = 1 << 20
+
+// IdempotencyStore remembers the responses sent for Idempotency-Key headers
+type IdempotencyStore interface {
+	// Claim records the key and reports whether the request should run;
+	// otherwise it returns the record of the first request with the key
+	Claim(principal, key, requestHash string) (models.IdempotencyRecord, bool, error)
+	// Complete stores the response to replay for the key
+	Complete(principal, key string, statusCode int, body []byte) error
+	// Release forgets the key so a retry runs the request again
+	Release(principal, key string) error
+}
+
+// IdempotencyMiddleware lets clients retry a request safely by sending an
+// Idempotency-Key header. The first request with a key runs as usual and a
+// successful response is stored; a retry with the same key and body gets
+// that response again with Idempotent-Replayed set, without reaching the
+// handler. Failed requests release the key so the retry runs again. It
+// must run after the auth middleware, as keys are scoped to the caller.
+// Requests without the header are not affected.
+func IdempotencyMiddleware(store IdempotencyStore) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := strings.TrimSpace(r.Header.Get(IdempotencyKeyHeader))
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				writeError(w, http.StatusBadRequest, IdempotencyKeyHeader+" header is too long")
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotencyBodyBytes+1))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Failed to read request body")
+				return
+			}
+			if len(body) > maxIdempotencyBodyBytes {
+				writeError(w, http.StatusRequestEntityTooLarge, "Request body is too large")
+				return
+			}
+
+			r, values := httpx.WithValues(r)
+			principal := utils.PrincipalFromContext(values)
+			requestHash := hashRequest(r.Method, r.URL.Path, body)
+			record, claimed, err := store.Claim(principal, key, requestHash)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Error claiming idempotency key", "principal", principal, "error", err)
+				writeError(w, http.StatusServiceUnavailable, "Idempotency key could not be checked")
+				return
+			}
+			if !claimed {
+				replayIdempotent(w, record, requestHash)
+				return
+			}
+
+			recorder := newBodyRecorder(w)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			next.ServeHTTP(recorder, r)
+
+			status := recorder.Status()
+			if recorder.Written() && status >= http.StatusOK && status < http.StatusMultipleChoices {
+				if err := store.Complete(principal, key, status, recorder.body.Bytes()); err != nil {
+					// The key stays claimed, so a retry waits for it to be
+					// abandoned rather than placing a second order right away
+					slog.ErrorContext(r.Context(), "Error storing idempotent response", "principal", principal, "error", err)
+				}
+				return
+			}
+			if err := store.Release(principal, key); err != nil {
+				slog.ErrorContext(r.Context(), "Error releasing idempotency key", "principal", principal, "error", err)
+			}
+		})
+	}
+}
+
+// replayIdempotent answers a request whose key was already claimed
+func replayIdempotent(w http.ResponseWriter, record models.IdempotencyRecord, requestHash string) {
+	switch {
+	case record.RequestHash != requestHash:
+		writeError(w, http.StatusUnprocessableEntity, IdempotencyKeyHeader+" was already used for a different request")
+	case record.StatusCode == 0:
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusConflict, "A request with this "+IdempotencyKeyHeader+" is still in progress")
+	default:
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(record.StatusCode)
+		_, _ = w.Write(record.Body)
+	}
+}
+
+// hashRequest fingerprints a request so a key reused for another one is caught
+func hashRequest(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
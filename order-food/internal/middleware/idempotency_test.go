@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryIdempotencyStore is an in-memory IdempotencyStore
+type memoryIdempotencyStore map[string]*models.IdempotencyRecord
+
+func (s memoryIdempotencyStore) Claim(principal, key, requestHash string) (models.IdempotencyRecord, bool, error) {
+	if record, ok := s[principal+"/"+key]; ok {
+		return *record, false, nil
+	}
+	s[principal+"/"+key] = &models.IdempotencyRecord{RequestHash: requestHash}
+	return models.IdempotencyRecord{RequestHash: requestHash}, true, nil
+}
+
+func (s memoryIdempotencyStore) Complete(principal, key string, statusCode int, body []byte) error {
+	s[principal+"/"+key].StatusCode = statusCode
+	s[principal+"/"+key].Body = body
+	return nil
+}
+
+func (s memoryIdempotencyStore) Release(principal, key string) error {
+	delete(s, principal+"/"+key)
+	return nil
+}
+
+func newIdempotencyRouter(store memoryIdempotencyStore, status *int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/orders", func(c *gin.Context) {
+		utils.SetPrincipal(c, c.GetHeader("api_key"), nil)
+	}, ginx.Middleware(IdempotencyMiddleware(store)), func(c *gin.Context) {
+		*calls++
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(*status, gin.H{"id": fmt.Sprintf("order-%d", *calls), "request": string(body)})
+	})
+	return router
+}
+
+func idempotentRequest(principal, key, body string) *http.Request {
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(body))
+	req.Header.Set("api_key", principal)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return req
+}
+
+func TestIdempotencyMiddleware_ReplayReturnsOriginalResponse(t *testing.T) {
+	// Setup
+	status, calls := http.StatusCreated, 0
+	router := newIdempotencyRouter(memoryIdempotencyStore{}, &status, &calls)
+
+	// Execute
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, idempotentRequest("apikey", "key-1", `{"items":[]}`))
+	replay := httptest.NewRecorder()
+	router.ServeHTTP(replay, idempotentRequest("apikey", "key-1", `{"items":[]}`))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, http.StatusCreated, replay.Code)
+	assert.Equal(t, "true", replay.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, first.Body.String(), replay.Body.String())
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotencyMiddleware_KeysAreScopedToCaller(t *testing.T) {
+	// Setup
+	status, calls := http.StatusCreated, 0
+	router := newIdempotencyRouter(memoryIdempotencyStore{}, &status, &calls)
+
+	// Execute
+	router.ServeHTTP(httptest.NewRecorder(), idempotentRequest("apikey", "key-1", "{}"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, idempotentRequest("partner:1", "key-1", "{}"))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyMiddleware_FailedRequestIsRetried(t *testing.T) {
+	// Setup
+	status, calls := http.StatusConflict, 0
+	router := newIdempotencyRouter(memoryIdempotencyStore{}, &status, &calls)
+
+	// Execute
+	router.ServeHTTP(httptest.NewRecorder(), idempotentRequest("apikey", "key-1", "{}"))
+	status = http.StatusCreated
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, idempotentRequest("apikey", "key-1", "{}"))
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyMiddleware_Rejects(t *testing.T) {
+	tests := []struct {
+		name       string
+		store      memoryIdempotencyStore
+		req        *http.Request
+		wantStatus int
+	}{
+		{
+			name:       "key reused for another body",
+			store:      memoryIdempotencyStore{"apikey/key-1": {RequestHash: hashRequest("POST", "/orders", []byte("{}")), StatusCode: http.StatusCreated}},
+			req:        idempotentRequest("apikey", "key-1", `{"couponCode":"HAPPYHRS"}`),
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "first request in flight",
+			store:      memoryIdempotencyStore{"apikey/key-1": {RequestHash: hashRequest("POST", "/orders", []byte("{}"))}},
+			req:        idempotentRequest("apikey", "key-1", "{}"),
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "key too long",
+			store:      memoryIdempotencyStore{},
+			req:        idempotentRequest("apikey", strings.Repeat("k", maxIdempotencyKeyLength+1), "{}"),
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			status, calls := http.StatusCreated, 0
+			router := newIdempotencyRouter(tt.store, &status, &calls)
+
+			// Execute
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, 0, calls)
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_WithoutKey(t *testing.T) {
+	// Setup
+	status, calls := http.StatusCreated, 0
+	store := memoryIdempotencyStore{}
+	router := newIdempotencyRouter(store, &status, &calls)
+
+	// Execute
+	router.ServeHTTP(httptest.NewRecorder(), idempotentRequest("apikey", "", "{}"))
+	router.ServeHTTP(httptest.NewRecorder(), idempotentRequest("apikey", "", "{}"))
+
+	// Assert
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, store)
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+)
+
+// InputEncodingMiddleware rejects requests whose path or query parameters
+// are not valid UTF-8 or contain NUL characters. PostgreSQL refuses such
+// text, so passing it on would turn a bad request into a server error.
+func InputEncodingMiddleware() httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			valid := validText(r.URL.Path)
+			for key, values := range r.URL.Query() {
+				valid = valid && validText(key)
+				for _, value := range values {
+					valid = valid && validText(value)
+				}
+			}
+			if !valid {
+				writeError(w, http.StatusBadRequest, "Request path and query must be valid UTF-8 without NUL characters")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// validText reports whether s can be stored as PostgreSQL text
+func validText(s string) bool {
+	return utf8.ValidString(s) && !strings.ContainsRune(s, 0)
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInputEncodingMiddleware(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(InputEncodingMiddleware()))
+	router.GET("/products/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for target, want := range map[string]int{
+		"/products/waffle?category=Gaufre%20%C3%A0%20la%20cr%C3%A8me": http.StatusOK,
+		"/products/waffle%00":                  http.StatusBadRequest,
+		"/products/%FF%FE":                     http.StatusBadRequest,
+		"/products/waffle?category=Waffle%00":  http.StatusBadRequest,
+		"/products/waffle?cat%C3%28egory=Wafe": http.StatusBadRequest,
+	} {
+		// Create request
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", target, nil)
+
+		// Execute
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, want, w.Code, target)
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/instance"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstanceMiddleware names the replica that served the request in the
+// X-Instance-ID response header and on the request's server span, so a
+// misbehaving replica can be found from a single bad response or trace. It
+// must run after TracingMiddleware.
+func InstanceMiddleware(id instance.Identity) httpx.Middleware {
+	attributes := []attribute.KeyValue{
+		attribute.String("service.instance.id", id.ID),
+		attribute.String("host.name", id.Hostname),
+	}
+	if id.Pod != "" {
+		attributes = append(attributes, attribute.String("k8s.pod.name", id.Pod))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(instance.Header, id.ID)
+			trace.SpanFromContext(r.Context()).SetAttributes(attributes...)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
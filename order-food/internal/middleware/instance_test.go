@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/instance"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInstanceMiddleware(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	id := instance.Identity{ID: "0b6f4e2a-1c3d-4e5f-8a9b-0c1d2e3f4a5b", Hostname: "host-1", Pod: "order-food-7d9f-abcde"}
+	router := gin.New()
+	router.Use(ginx.Middleware(TracingMiddleware(provider)))
+	router.Use(ginx.Middleware(InstanceMiddleware(id)))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	// Create request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test", nil)
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, id.ID, w.Header().Get(instance.Header))
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("service.instance.id", id.ID))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("k8s.pod.name", "order-food-7d9f-abcde"))
+}
@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// Order lanes
+const (
+	// LanePartner carries the orders of partners, such as their kiosks
+	LanePartner = "partner"
+	// LanePublic carries every other order, such as those of the public app
+	LanePublic = "public"
+)
+
+// laneQueueBuckets are the upper bounds in seconds of the queue time
+// histogram
+var laneQueueBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// LaneConfig is the concurrency budget of a lane
+type LaneConfig struct {
+	// MaxInFlight bounds the requests of the lane running at once; the lane
+	// is not limited when it is zero
+	MaxInFlight int
+	// MaxQueued bounds the requests waiting for a slot; further requests
+	// are refused right away
+	MaxQueued int
+	// MaxWait is how long a request waits for a slot before it is refused
+	MaxWait time.Duration
+}
+
+// OrderLanesConfig configures the lanes in front of order creation
+type OrderLanesConfig struct {
+	Partner LaneConfig
+	Public  LaneConfig
+}
+
+// Descriptors of the lanes' metrics
+var (
+	laneAdmittedDesc     = prometheus.NewDesc("order_lane_admitted_total", "Order requests admitted by lane.", []string{"lane"}, nil)
+	laneRejectedDesc     = prometheus.NewDesc("order_lane_rejected_total", "Order requests refused because their lane was saturated.", []string{"lane"}, nil)
+	laneInFlightDesc     = prometheus.NewDesc("order_lane_in_flight", "Order requests running by lane.", []string{"lane"}, nil)
+	laneQueuedDesc       = prometheus.NewDesc("order_lane_queued", "Order requests waiting for a slot by lane.", []string{"lane"}, nil)
+	laneQueueSecondsDesc = prometheus.NewDesc("order_lane_queue_seconds", "Time admitted order requests waited for a slot by lane.", []string{"lane"}, nil)
+)
+
+// OrderLanes admits requests through separate lanes for partners and for
+// everyone else, each with its own concurrency budget, so a burst of public
+// orders cannot starve partner kiosks. Requests wait in their lane's queue
+// for a slot and are refused with 503 when the queue is full or the wait
+// runs out. It is safe for concurrent use.
+type OrderLanes struct {
+	lanes map[string]*lane
+}
+
+// lane is the concurrency budget of one lane with its metrics
+type lane struct {
+	name    string
+	cfg     LaneConfig
+	slots   chan struct{}
+	waiting atomic.Int64
+
+	admitted atomic.Int64
+	rejected atomic.Int64
+	// queueBuckets counts admitted requests by queue time, one counter per
+	// bound of laneQueueBuckets and one for longer waits
+	queueBuckets []atomic.Int64
+	// queueMicros sums the queue time of admitted requests
+	queueMicros atomic.Int64
+}
+
+// NewOrderLanes creates the lanes configured by cfg
+func NewOrderLanes(cfg OrderLanesConfig) *OrderLanes {
+	return &OrderLanes{lanes: map[string]*lane{
+		LanePartner: newLane(LanePartner, cfg.Partner),
+		LanePublic:  newLane(LanePublic, cfg.Public),
+	}}
+}
+
+func newLane(name string, cfg LaneConfig) *lane {
+	l := &lane{name: name, cfg: cfg, queueBuckets: make([]atomic.Int64, len(laneQueueBuckets)+1)}
+	if cfg.MaxInFlight > 0 {
+		l.slots = make(chan struct{}, cfg.MaxInFlight)
+	}
+	return l
+}
+
+// LaneOf returns the lane of a caller by principal
+func LaneOf(principal string) string {
+	if strings.HasPrefix(principal, utils.PartnerPrincipal("")) {
+		return LanePartner
+	}
+	return LanePublic
+}
+
+// Middleware returns the handler admitting requests through their caller's
+// lane. It must run after authentication, and before idempotency so a
+// refused request does not hold its key.
+func (o *OrderLanes) Middleware() httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, values := httpx.WithValues(r)
+			l := o.lanes[LaneOf(utils.PrincipalFromContext(values))]
+			if l.slots == nil {
+				l.admitted.Add(1)
+				l.observe(0)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			if !l.acquire(w, r) {
+				return
+			}
+			defer func() { <-l.slots }()
+			l.observe(time.Since(start))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// acquire waits for a slot of the lane and reports whether one was taken.
+// Requests that are not admitted are answered, unless the client went
+// away.
+func (l *lane) acquire(w http.ResponseWriter, r *http.Request) bool {
+	select {
+	case l.slots <- struct{}{}:
+		l.admitted.Add(1)
+		return true
+	default:
+	}
+
+	if l.waiting.Add(1) > int64(l.cfg.MaxQueued) {
+		l.waiting.Add(-1)
+		l.reject(w, r, "queue full")
+		return false
+	}
+	defer l.waiting.Add(-1)
+
+	timer := time.NewTimer(l.cfg.MaxWait)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		l.admitted.Add(1)
+		return true
+	case <-timer.C:
+		l.reject(w, r, "wait timed out")
+		return false
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// reject refuses a request with 503 and a hint to retry shortly
+func (l *lane) reject(w http.ResponseWriter, r *http.Request, reason string) {
+	l.rejected.Add(1)
+	slog.WarnContext(r.Context(), "Order lane is saturated", "lane", l.name, "reason", reason)
+	retryAfter := max(int(l.cfg.MaxWait.Round(time.Second)/time.Second), 1)
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	writeError(w, http.StatusServiceUnavailable, "Too many orders are being placed, try again shortly")
+}
+
+// observe records the queue time of an admitted request
+func (l *lane) observe(wait time.Duration) {
+	l.queueMicros.Add(wait.Microseconds())
+	seconds := wait.Seconds()
+	for i, bound := range laneQueueBuckets {
+		if seconds <= bound {
+			l.queueBuckets[i].Add(1)
+			return
+		}
+	}
+	l.queueBuckets[len(laneQueueBuckets)].Add(1)
+}
+
+// Describe sends the descriptors of the lanes' metrics
+func (o *OrderLanes) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{laneAdmittedDesc, laneRejectedDesc, laneInFlightDesc, laneQueuedDesc, laneQueueSecondsDesc} {
+		ch <- desc
+	}
+}
+
+// Collect sends the admissions, refusals, occupancy and queue times of
+// the lanes
+func (o *OrderLanes) Collect(ch chan<- prometheus.Metric) {
+	for _, name := range []string{LanePartner, LanePublic} {
+		l := o.lanes[name]
+		ch <- prometheus.MustNewConstMetric(laneAdmittedDesc, prometheus.CounterValue, float64(l.admitted.Load()), name)
+		ch <- prometheus.MustNewConstMetric(laneRejectedDesc, prometheus.CounterValue, float64(l.rejected.Load()), name)
+		ch <- prometheus.MustNewConstMetric(laneInFlightDesc, prometheus.GaugeValue, float64(len(l.slots)), name)
+		ch <- prometheus.MustNewConstMetric(laneQueuedDesc, prometheus.GaugeValue, float64(l.waiting.Load()), name)
+
+		var count uint64
+		buckets := make(map[float64]uint64, len(laneQueueBuckets))
+		for i, bound := range laneQueueBuckets {
+			count += uint64(l.queueBuckets[i].Load())
+			buckets[bound] = count
+		}
+		count += uint64(l.queueBuckets[len(laneQueueBuckets)].Load())
+		ch <- prometheus.MustNewConstHistogram(laneQueueSecondsDesc, count, float64(l.queueMicros.Load())/1e6, buckets, name)
+	}
+}
@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/expfmt"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// lanesRouter serves POST /orders through lanes with the caller taken from
+// the X-Caller header. Orders block until release is closed once they have
+// signalled started.
+func lanesRouter(lanes *OrderLanes, started chan<- struct{}, release <-chan struct{}) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	authenticate := func(c *gin.Context) {
+		utils.SetPrincipal(c, c.GetHeader("X-Caller"), nil)
+	}
+	router.POST("/orders", authenticate, ginx.Middleware(lanes.Middleware()), func(c *gin.Context) {
+		started <- struct{}{}
+		<-release
+		c.Status(http.StatusCreated)
+	})
+	return router
+}
+
+// placeOrder sends an order by caller in the background and returns the
+// channel its response is delivered on
+func placeOrder(router *gin.Engine, caller string) <-chan *httptest.ResponseRecorder {
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set("X-Caller", caller)
+		router.ServeHTTP(w, req)
+		done <- w
+	}()
+	return done
+}
+
+func TestLaneOf(t *testing.T) {
+	assert.Equal(t, LanePartner, LaneOf(utils.PartnerPrincipal("acme")))
+	assert.Equal(t, LanePublic, LaneOf(utils.CustomerPrincipal("c1")))
+	assert.Equal(t, LanePublic, LaneOf("apikey"))
+	assert.Equal(t, LanePublic, LaneOf(""))
+}
+
+func TestOrderLanes_PartnersKeepTheirBudget(t *testing.T) {
+	// Setup
+	lanes := NewOrderLanes(OrderLanesConfig{
+		Partner: LaneConfig{MaxInFlight: 1, MaxQueued: 1, MaxWait: time.Second},
+		Public:  LaneConfig{MaxInFlight: 1, MaxQueued: 0, MaxWait: time.Second},
+	})
+	started := make(chan struct{}, 4)
+	release := make(chan struct{})
+	router := lanesRouter(lanes, started, release)
+
+	// Test: a public order takes the public lane's only slot
+	public := placeOrder(router, utils.CustomerPrincipal("c1"))
+	<-started
+	rejected := placeOrder(router, utils.CustomerPrincipal("c2"))
+	partner := placeOrder(router, utils.PartnerPrincipal("acme"))
+
+	// Assert: the second public order is refused while the partner's runs
+	w := <-rejected
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	<-started
+	close(release)
+	assert.Equal(t, http.StatusCreated, (<-public).Code)
+	assert.Equal(t, http.StatusCreated, (<-partner).Code)
+}
+
+func TestOrderLanes_QueuesUntilASlotIsFree(t *testing.T) {
+	// Setup
+	lanes := NewOrderLanes(OrderLanesConfig{
+		Public: LaneConfig{MaxInFlight: 1, MaxQueued: 1, MaxWait: 5 * time.Second},
+	})
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	router := lanesRouter(lanes, started, release)
+
+	// Test
+	first := placeOrder(router, "apikey")
+	<-started
+	second := placeOrder(router, "apikey")
+	assert.Eventually(t, func() bool { return lanes.lanes[LanePublic].waiting.Load() == 1 }, time.Second, time.Millisecond)
+	close(release)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, (<-first).Code)
+	assert.Equal(t, http.StatusCreated, (<-second).Code)
+	assert.Equal(t, int64(2), lanes.lanes[LanePublic].admitted.Load())
+	assert.Equal(t, int64(0), lanes.lanes[LanePublic].rejected.Load())
+}
+
+func TestOrderLanes_RefusesWhenTheWaitRunsOut(t *testing.T) {
+	// Setup
+	lanes := NewOrderLanes(OrderLanesConfig{
+		Public: LaneConfig{MaxInFlight: 1, MaxQueued: 5, MaxWait: 10 * time.Millisecond},
+	})
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	router := lanesRouter(lanes, started, release)
+
+	// Test
+	first := placeOrder(router, "apikey")
+	<-started
+	w := <-placeOrder(router, "apikey")
+	close(release)
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, http.StatusCreated, (<-first).Code)
+	assert.Equal(t, int64(0), lanes.lanes[LanePublic].waiting.Load())
+}
+
+func TestOrderLanes_UnlimitedLane(t *testing.T) {
+	// Setup
+	lanes := NewOrderLanes(OrderLanesConfig{})
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	router := lanesRouter(lanes, started, release)
+
+	// Test
+	var responses []<-chan *httptest.ResponseRecorder
+	for range 3 {
+		responses = append(responses, placeOrder(router, utils.PartnerPrincipal("acme")))
+	}
+	for range 3 {
+		<-started
+	}
+	close(release)
+
+	// Assert
+	for _, response := range responses {
+		assert.Equal(t, http.StatusCreated, (<-response).Code)
+	}
+}
+
+func TestOrderLanes_Collect(t *testing.T) {
+	// Setup
+	lanes := NewOrderLanes(OrderLanesConfig{Partner: LaneConfig{MaxInFlight: 2}})
+	lanes.lanes[LanePartner].admitted.Add(2)
+	lanes.lanes[LanePartner].observe(3 * time.Millisecond)
+	lanes.lanes[LanePartner].observe(time.Minute)
+	lanes.lanes[LanePublic].rejected.Add(1)
+
+	// Test
+	buf, err := testutil.CollectAndFormat(lanes, expfmt.TypeTextPlain, "order_lane_admitted_total", "order_lane_rejected_total", "order_lane_queue_seconds")
+
+	// Assert
+	assert.NoError(t, err)
+	out := string(buf)
+	assert.Contains(t, out, "# TYPE order_lane_admitted_total counter\n")
+	assert.Contains(t, out, `order_lane_admitted_total{lane="partner"} 2`)
+	assert.Contains(t, out, `order_lane_rejected_total{lane="public"} 1`)
+	assert.Contains(t, out, "# TYPE order_lane_queue_seconds histogram\n")
+	assert.Contains(t, out, `order_lane_queue_seconds_bucket{lane="partner",le="0.005"} 1`)
+	assert.Contains(t, out, `order_lane_queue_seconds_bucket{lane="partner",le="5"} 1`)
+	assert.Contains(t, out, `order_lane_queue_seconds_bucket{lane="partner",le="+Inf"} 2`)
+	assert.Contains(t, out, `order_lane_queue_seconds_sum{lane="partner"} 60.003`)
+	assert.Contains(t, out, `order_lane_queue_seconds_count{lane="public"} 0`)
+}
+
+// TestOrderLanes_ClientGoneWhileQueued checks a request whose client went
+// away leaves the queue without taking a slot
+func TestOrderLanes_ClientGoneWhileQueued(t *testing.T) {
+	// Setup
+	lanes := NewOrderLanes(OrderLanesConfig{
+		Public: LaneConfig{MaxInFlight: 1, MaxQueued: 1, MaxWait: time.Minute},
+	})
+	l := lanes.lanes[LanePublic]
+	l.slots <- struct{}{}
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	ctx, cancel := context.WithCancel(req.Context())
+	req = req.WithContext(ctx)
+
+	// Test
+	var wg sync.WaitGroup
+	var acquired bool
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		acquired = l.acquire(w, req)
+	}()
+	assert.Eventually(t, func() bool { return l.waiting.Load() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	// Assert
+	assert.False(t, acquired)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+	assert.Empty(t, w.Body.String())
+	assert.Equal(t, int64(0), l.waiting.Load())
+	assert.Equal(t, int64(0), l.rejected.Load())
+}
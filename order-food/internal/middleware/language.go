@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// LanguageMiddleware parses the Accept-Language header once and stores the
+// fallback chain in the context for handlers to read with
+// utils.LanguagesFromContext. defaultLanguage is the language of the
+// untranslated product names.
+func LanguageMiddleware(defaultLanguage string) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, values := httpx.WithValues(r)
+			utils.SetLanguages(values, utils.ParseAcceptLanguage(r.Header.Get("Accept-Language"), defaultLanguage))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLanguageMiddleware_ParsesAcceptLanguage(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	var got utils.Languages
+	router := gin.New()
+	router.Use(ginx.Middleware(LanguageMiddleware("de")))
+	router.GET("/test", func(c *gin.Context) {
+		got = utils.LanguagesFromContext(ginx.New(c))
+		c.Status(http.StatusOK)
+	})
+
+	// Create request preferring Canadian French
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Accept-Language", "fr-CA, en;q=0.5")
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, utils.Languages{Fallbacks: []string{"fr-ca", "fr", "en"}, Default: "de"}, got)
+}
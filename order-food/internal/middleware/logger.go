@@ -1,27 +1,68 @@
 package middleware
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
 	"time"
 
-	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
 )
 
-// LoggerMiddleware logs HTTP requests
-func LoggerMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		startTime := time.Now()
-
-		c.Next()
-
-		duration := time.Since(startTime)
-		log.Printf(
-			"[%s] %s %s - Status: %d - Duration: %v",
-			c.Request.Method,
-			c.Request.RequestURI,
-			c.ClientIP(),
-			c.Writer.Status(),
-			duration,
-		)
+// logAttrsKey is the key of the request-scoped log attributes in the
+// request's values
+const logAttrsKey = "logAttrs"
+
+// LoggerMiddleware logs every HTTP request with its method, path, status
+// and latency and the request-scoped attributes, such as the request and
+// trace IDs. Server errors are logged at error level.
+func LoggerMiddleware() httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			startTime := time.Now()
+			r, values := httpx.WithValues(r)
+			writer := newResponseWriter(w)
+
+			next.ServeHTTP(writer, r)
+
+			duration := time.Since(startTime)
+			status := writer.Status()
+			if !writer.Written() && errors.Is(r.Context().Err(), context.Canceled) {
+				status = StatusClientClosedRequest
+			}
+			level := slog.LevelInfo
+			if status >= 500 {
+				level = slog.LevelError
+			}
+			attrs := append([]slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.RequestURI),
+				slog.String("client_ip", httpx.ClientIP(r)),
+				slog.Int("status", status),
+				slog.Duration("latency", duration),
+			}, logAttrs(values)...)
+			// The attributes of the request context are already in attrs
+			slog.LogAttrs(context.Background(), level, "Request served", attrs...)
+		})
 	}
 }
+
+// addLogAttrs adds attrs to every record logged for the request: those
+// logged with the context of the returned request further down, and its
+// access log line
+func addLogAttrs(r *http.Request, attrs ...slog.Attr) *http.Request {
+	r, values := httpx.WithValues(r)
+	existing := logAttrs(values)
+	values.Set(logAttrsKey, append(existing[:len(existing):len(existing)], attrs...))
+	return r.WithContext(app.WithLogAttrs(r.Context(), attrs...))
+}
+
+// logAttrs returns the request-scoped log attributes added with
+// addLogAttrs
+func logAttrs(values httpx.Values) []slog.Attr {
+	attrs, _ := values.Get(logAttrsKey)
+	list, _ := attrs.([]slog.Attr)
+	return list
+}
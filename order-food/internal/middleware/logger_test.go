@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,7 +22,7 @@ func TestLoggerMiddleware_LogsRequest(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(LoggerMiddleware())
+	router.Use(ginx.Middleware(LoggerMiddleware()))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
@@ -52,7 +53,7 @@ func TestLoggerMiddleware_LogsDifferentMethods(t *testing.T) {
 
 		gin.SetMode(gin.TestMode)
 		router := gin.New()
-		router.Use(LoggerMiddleware())
+		router.Use(ginx.Middleware(LoggerMiddleware()))
 		router.Handle(method, "/test", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"message": "success"})
 		})
@@ -87,7 +88,7 @@ func TestLoggerMiddleware_LogsStatusCodes(t *testing.T) {
 
 		gin.SetMode(gin.TestMode)
 		router := gin.New()
-		router.Use(LoggerMiddleware())
+		router.Use(ginx.Middleware(LoggerMiddleware()))
 		router.GET("/test", func(c *gin.Context) {
 			c.JSON(statusCode, gin.H{"message": "test"})
 		})
@@ -114,7 +115,7 @@ func TestLoggerMiddleware_LogsLatency(t *testing.T) {
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(LoggerMiddleware())
+	router.Use(ginx.Middleware(LoggerMiddleware()))
 	router.GET("/test", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
@@ -136,7 +137,7 @@ func TestLoggerMiddleware_NextCalled(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	handlerCalled := false
 	router := gin.New()
-	router.Use(LoggerMiddleware())
+	router.Use(ginx.Middleware(LoggerMiddleware()))
 	router.GET("/test", func(c *gin.Context) {
 		handlerCalled = true
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
@@ -153,3 +154,33 @@ func TestLoggerMiddleware_NextCalled(t *testing.T) {
 	assert.True(t, handlerCalled)
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+func TestLoggerMiddleware_ServerErrorsWithRequestFields(t *testing.T) {
+	// Setup - capture log output
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(RequestIDMiddleware()), ginx.Middleware(LoggerMiddleware()))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	// Create request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test?page=2", nil)
+	req.Header.Set("X-Request-ID", "checkout-7f3a")
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	logOutput := buf.String()
+	assert.Contains(t, logOutput, "ERROR Request served")
+	assert.Contains(t, logOutput, "method=GET path=\"/test?page=2\"")
+	assert.Contains(t, logOutput, "status=500")
+	assert.Contains(t, logOutput, "latency=")
+	assert.Contains(t, logOutput, "request_id=checkout-7f3a")
+}
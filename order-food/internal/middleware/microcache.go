@@ -0,0 +1,302 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+)
+
+const (
+	// maxMicroCacheEntries bounds the number of cached responses; the cache
+	// is flushed when it fills up
+	maxMicroCacheEntries = 1000
+	// microCacheRefreshTimeout bounds a refresh, which outlives the response
+	// of the request running it
+	microCacheRefreshTimeout = 10 * time.Second
+)
+
+// Descriptors of the micro-cache counters
+var (
+	microCacheHitsDesc      = prometheus.NewDesc("product_list_cache_hits_total", "Product listings served from the micro-cache while fresh.", nil, nil)
+	microCacheStaleHitsDesc = prometheus.NewDesc("product_list_cache_stale_hits_total", "Product listings served from the micro-cache while being refreshed.", nil, nil)
+	microCacheMissesDesc    = prometheus.NewDesc("product_list_cache_misses_total", "Product listings the micro-cache had no response for.", nil, nil)
+	microCacheWaitsDesc     = prometheus.NewDesc("product_list_cache_waits_total", "Product listings that waited for another request to build the response.", nil, nil)
+)
+
+// MicroCache holds the successful responses of a GET route for a few
+// seconds, so a burst of identical requests, such as every app opening its
+// home screen at once, runs the handler once. Responses are kept per API
+// key, path, normalized query string, Accept and Accept-Language. Requests
+// arriving while the first response for a key is being built wait for it
+// instead of running the handler too. For stale seconds after the TTL a
+// response is still served, while the first request to see it stale runs
+// the handler once more to refresh it. Streamed responses are not cached.
+// It is safe for concurrent use.
+type MicroCache struct {
+	ttl   time.Duration
+	stale time.Duration
+	now   func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*microEntry
+
+	hits      atomic.Int64
+	staleHits atomic.Int64
+	misses    atomic.Int64
+	waits     atomic.Int64
+}
+
+// microEntry is the cached response for one key. response is nil while
+// the first request for the key is running the handler; ready is closed
+// when it is done.
+type microEntry struct {
+	ready      chan struct{}
+	response   *cachedResponse
+	storedAt   time.Time
+	refreshing bool
+}
+
+// cachedResponse is a response as written by the handler. header only
+// holds the headers the handler set.
+type cachedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// NewMicroCache creates a cache serving responses for ttl and, while they
+// are refreshed, for stale more
+func NewMicroCache(ttl, stale time.Duration) *MicroCache {
+	return &MicroCache{
+		ttl:     ttl,
+		stale:   stale,
+		now:     time.Now,
+		entries: make(map[string]*microEntry),
+	}
+}
+
+// Invalidate drops every cached response. It takes the key of an
+// invalidation event, but any product may appear on any listing, so the
+// key is not used.
+func (m *MicroCache) Invalidate(string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = make(map[string]*microEntry)
+}
+
+// Middleware returns the handler caching the responses of the route it is
+// added to
+func (m *MicroCache) Middleware() httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || strings.Contains(r.Header.Get("Accept"), "application/x-ndjson") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := microCacheKey(r)
+
+			m.mu.Lock()
+			entry := m.entries[key]
+			now := m.now()
+			if entry != nil && entry.response != nil && now.Sub(entry.storedAt) >= m.ttl+m.stale {
+				entry = nil
+			}
+			switch {
+			case entry == nil:
+				if len(m.entries) >= maxMicroCacheEntries {
+					m.entries = make(map[string]*microEntry)
+				}
+				entry = &microEntry{ready: make(chan struct{})}
+				m.entries[key] = entry
+				m.mu.Unlock()
+				m.misses.Add(1)
+				m.fill(next, w, r, key, entry)
+
+			case entry.response == nil:
+				m.mu.Unlock()
+				m.waits.Add(1)
+				m.wait(next, w, r, entry)
+
+			case now.Sub(entry.storedAt) < m.ttl:
+				response, storedAt := entry.response, entry.storedAt
+				m.mu.Unlock()
+				m.hits.Add(1)
+				serveCached(w, response, now.Sub(storedAt))
+
+			default:
+				response, storedAt := entry.response, entry.storedAt
+				refresh := !entry.refreshing
+				entry.refreshing = true
+				m.mu.Unlock()
+				m.staleHits.Add(1)
+				serveCached(w, response, now.Sub(storedAt))
+				if !refresh {
+					return
+				}
+				_ = http.NewResponseController(w).Flush()
+				m.refresh(next, r, entry)
+			}
+		})
+	}
+}
+
+// fill runs the handler for the first request of key and stores a
+// successful response. Otherwise the entry is dropped, so the requests
+// waiting for it run the handler themselves.
+func (m *MicroCache) fill(next http.Handler, w http.ResponseWriter, r *http.Request, key string, entry *microEntry) {
+	var response *cachedResponse
+	defer func() {
+		m.mu.Lock()
+		if response != nil {
+			entry.response = response
+			entry.storedAt = m.now()
+		} else if m.entries[key] == entry {
+			delete(m.entries, key)
+		}
+		m.mu.Unlock()
+		close(entry.ready)
+	}()
+
+	before := w.Header().Clone()
+	recorder := newBodyRecorder(w)
+	next.ServeHTTP(recorder, r)
+	response = newCachedResponse(recorder.Status(), before, w.Header(), recorder.body.Bytes())
+}
+
+// wait answers a request with the response another request is building,
+// or runs the handler when that one fails or the request is cancelled
+func (m *MicroCache) wait(next http.Handler, w http.ResponseWriter, r *http.Request, entry *microEntry) {
+	select {
+	case <-entry.ready:
+	case <-r.Context().Done():
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	m.mu.Lock()
+	response, storedAt := entry.response, entry.storedAt
+	m.mu.Unlock()
+	if response == nil {
+		next.ServeHTTP(w, r)
+		return
+	}
+	serveCached(w, response, m.now().Sub(storedAt))
+}
+
+// refresh runs the handler again for a request whose client has already
+// been served the stale response, and stores what it writes. A failed
+// refresh keeps the stale response until the next request tries again.
+func (m *MicroCache) refresh(next http.Handler, r *http.Request, entry *microEntry) {
+	var response *cachedResponse
+	defer func() {
+		m.mu.Lock()
+		if response != nil {
+			entry.response = response
+			entry.storedAt = m.now()
+		}
+		entry.refreshing = false
+		m.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), microCacheRefreshTimeout)
+	defer cancel()
+	discard := &discardWriter{header: make(http.Header)}
+	next.ServeHTTP(discard, r.WithContext(ctx))
+	response = newCachedResponse(discard.Status(), http.Header{}, discard.header, discard.body.Bytes())
+}
+
+// newCachedResponse returns the response to store for a handler that
+// answered status with body, setting the headers that differ from before,
+// or nil when it is not worth caching
+func newCachedResponse(status int, before, after http.Header, body []byte) *cachedResponse {
+	if status != http.StatusOK || len(body) == 0 {
+		return nil
+	}
+	header := make(http.Header)
+	for name, values := range after {
+		if !slices.Equal(before[name], values) {
+			header[name] = slices.Clone(values)
+		}
+	}
+	return &cachedResponse{status: status, header: header, body: slices.Clone(body)}
+}
+
+// serveCached writes response with an Age header of age
+func serveCached(w http.ResponseWriter, response *cachedResponse, age time.Duration) {
+	header := w.Header()
+	for name, values := range response.header {
+		header[name] = slices.Clone(values)
+	}
+	header.Set("Age", strconv.Itoa(int(age.Seconds())))
+	header.Set("Content-Length", strconv.Itoa(len(response.body)))
+	w.WriteHeader(response.status)
+	_, _ = w.Write(response.body)
+}
+
+// microCacheKey identifies the response to a request: the caller's API
+// key, hashed so it is not kept in memory, the path, the query with its
+// parameters sorted and the headers responses vary by
+func microCacheKey(r *http.Request) string {
+	caller := sha256.Sum256([]byte(r.Header.Get(APIKeyHeader)))
+	return strings.Join([]string{
+		hex.EncodeToString(caller[:8]),
+		r.URL.Path + "?" + r.URL.Query().Encode(),
+		r.Header.Get("Accept"),
+		r.Header.Get("Accept-Language"),
+	}, "\n")
+}
+
+// Describe sends the descriptors of the cache counters
+func (m *MicroCache) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{microCacheHitsDesc, microCacheStaleHitsDesc, microCacheMissesDesc, microCacheWaitsDesc} {
+		ch <- desc
+	}
+}
+
+// Collect sends the cache counters
+func (m *MicroCache) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(microCacheHitsDesc, prometheus.CounterValue, float64(m.hits.Load()))
+	ch <- prometheus.MustNewConstMetric(microCacheStaleHitsDesc, prometheus.CounterValue, float64(m.staleHits.Load()))
+	ch <- prometheus.MustNewConstMetric(microCacheMissesDesc, prometheus.CounterValue, float64(m.misses.Load()))
+	ch <- prometheus.MustNewConstMetric(microCacheWaitsDesc, prometheus.CounterValue, float64(m.waits.Load()))
+}
+
+// discardWriter records a response without sending it
+type discardWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *discardWriter) Header() http.Header { return w.header }
+
+func (w *discardWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *discardWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+func (w *discardWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *discardWriter) Flush() {}
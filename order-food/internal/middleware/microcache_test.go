@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/stretchr/testify/assert"
+)
+
+// microCacheRouter serves /products through cache, etag and a handler
+// answering name and counting its calls
+func microCacheRouter(cache *MicroCache, name *atomic.Value, calls *atomic.Int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/products", ginx.Middleware(ETagMiddleware()), ginx.Middleware(cache.Middleware()), func(c *gin.Context) {
+		calls.Add(1)
+		if name.Load() == "" {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Failed to fetch products"})
+			return
+		}
+		c.Header("Link", `</api/v1/products?page=2>; rel="next"`)
+		c.JSON(http.StatusOK, gin.H{"name": name.Load(), "page": c.Query("page")})
+	})
+	return router
+}
+
+func getProducts(router *gin.Engine, target string, header map[string]string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for name, value := range header {
+		req.Header.Set(name, value)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMicroCache(t *testing.T) {
+	// Setup
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewMicroCache(2*time.Second, 10*time.Second)
+	cache.now = func() time.Time { return now }
+	var name atomic.Value
+	name.Store("Waffle")
+	var calls atomic.Int64
+	router := microCacheRouter(cache, &name, &calls)
+
+	// A repeated listing is answered from the cache, with the handler's headers
+	w := getProducts(router, "/products?page=1&perPage=10", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	name.Store("Pancake")
+	now = now.Add(time.Second)
+	w = getProducts(router, "/products?perPage=10&page=1", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name":"Waffle","page":"1"}`, w.Body.String())
+	assert.Equal(t, `</api/v1/products?page=2>; rel="next"`, w.Header().Get("Link"))
+	assert.Equal(t, "1", w.Header().Get("Age"))
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Equal(t, int64(1), calls.Load())
+
+	// Other callers, queries and languages get responses of their own
+	getProducts(router, "/products?page=1&perPage=10", map[string]string{APIKeyHeader: "partner-key"})
+	getProducts(router, "/products?page=2&perPage=10", nil)
+	getProducts(router, "/products?page=1&perPage=10", map[string]string{"Accept-Language": "de"})
+	assert.Equal(t, int64(4), calls.Load())
+
+	// Once stale, the response is still served while the request refreshes it
+	now = now.Add(2 * time.Second)
+	w = getProducts(router, "/products?page=1&perPage=10", nil)
+	assert.JSONEq(t, `{"name":"Waffle","page":"1"}`, w.Body.String())
+	assert.Equal(t, int64(5), calls.Load())
+	w = getProducts(router, "/products?page=1&perPage=10", nil)
+	assert.JSONEq(t, `{"name":"Pancake","page":"1"}`, w.Body.String())
+	assert.Equal(t, "0", w.Header().Get("Age"))
+	assert.Equal(t, int64(5), calls.Load())
+
+	// Beyond the stale window the handler answers
+	name.Store("Crepe")
+	now = now.Add(12 * time.Second)
+	w = getProducts(router, "/products?page=1&perPage=10", nil)
+	assert.JSONEq(t, `{"name":"Crepe","page":"1"}`, w.Body.String())
+	assert.Empty(t, w.Header().Get("Age"))
+	assert.Equal(t, int64(6), calls.Load())
+
+	// Invalidation drops every response
+	name.Store("Toast")
+	cache.Invalidate("*")
+	w = getProducts(router, "/products?page=1&perPage=10", nil)
+	assert.JSONEq(t, `{"name":"Toast","page":"1"}`, w.Body.String())
+	assert.Equal(t, int64(7), calls.Load())
+
+	// Streams are not cached
+	getProducts(router, "/products?page=1&perPage=10", map[string]string{"Accept": "application/x-ndjson"})
+	getProducts(router, "/products?page=1&perPage=10", map[string]string{"Accept": "application/x-ndjson"})
+	assert.Equal(t, int64(9), calls.Load())
+}
+
+func TestMicroCache_ErrorsAreNotCached(t *testing.T) {
+	// Setup
+	cache := NewMicroCache(time.Minute, time.Minute)
+	var name atomic.Value
+	name.Store("")
+	var calls atomic.Int64
+	router := microCacheRouter(cache, &name, &calls)
+
+	// Execute
+	first := getProducts(router, "/products", nil)
+	name.Store("Waffle")
+	second := getProducts(router, "/products", nil)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, first.Code)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, int64(2), calls.Load())
+}
+
+func TestMicroCache_FailedRefreshKeepsStaleResponse(t *testing.T) {
+	// Setup
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewMicroCache(2*time.Second, 10*time.Second)
+	cache.now = func() time.Time { return now }
+	var name atomic.Value
+	name.Store("Waffle")
+	var calls atomic.Int64
+	router := microCacheRouter(cache, &name, &calls)
+	getProducts(router, "/products", nil)
+
+	// Execute: the refresh fails
+	name.Store("")
+	now = now.Add(3 * time.Second)
+	getProducts(router, "/products", nil)
+	w := getProducts(router, "/products", nil)
+
+	// Assert: the stale response is served and refreshed again
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"name":"Waffle","page":""}`, w.Body.String())
+	assert.Equal(t, "3", w.Header().Get("Age"))
+	assert.Equal(t, int64(3), calls.Load())
+}
+
+func TestMicroCache_CoalescesConcurrentMisses(t *testing.T) {
+	// Setup: the handler blocks until released
+	gin.SetMode(gin.TestMode)
+	cache := NewMicroCache(time.Minute, time.Minute)
+	var calls atomic.Int64
+	started := make(chan struct{})
+	release := make(chan struct{})
+	router := gin.New()
+	router.GET("/products", ginx.Middleware(cache.Middleware()), func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		c.JSON(http.StatusOK, gin.H{"name": "Waffle"})
+	})
+
+	// Execute
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 10)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		responses[0] = getProducts(router, "/products", nil)
+	}()
+	<-started
+	for i := 1; i < len(responses); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = getProducts(router, "/products", nil)
+		}()
+	}
+	assert.Eventually(t, func() bool { return cache.waits.Load() == int64(len(responses)-1) }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// Assert
+	assert.Equal(t, int64(1), calls.Load())
+	for _, w := range responses {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"name":"Waffle"}`, w.Body.String())
+	}
+}
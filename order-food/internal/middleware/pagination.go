@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// PaginationMiddleware parses the page and perPage query parameters, or the
+// after and limit parameters of cursor pagination, once, applying the
+// configured defaults and hard cap, and stores the result in the context
+// for list handlers to read with utils.PaginationFromContext
+func PaginationMiddleware(cfg utils.PaginationConfig) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, values := httpx.WithValues(r)
+			utils.SetPagination(values, utils.ParseRequestPagination(r.URL.Query(), cfg))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginationMiddleware_AppliesConfiguredDefaults(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	var got utils.Pagination
+	router := gin.New()
+	router.Use(ginx.Middleware(PaginationMiddleware(utils.PaginationConfig{DefaultPerPage: 25, MaxPerPage: 50})))
+	router.GET("/test", func(c *gin.Context) {
+		got = utils.PaginationFromContext(ginx.New(c))
+		c.Status(http.StatusOK)
+	})
+
+	// Create request without pagination parameters
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test", nil)
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, utils.Pagination{Page: 1, PerPage: 25, Offset: 0}, got)
+}
+
+func TestPaginationMiddleware_CapsPerPage(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	var got utils.Pagination
+	router := gin.New()
+	router.Use(ginx.Middleware(PaginationMiddleware(utils.PaginationConfig{DefaultPerPage: 25, MaxPerPage: 50})))
+	router.GET("/test", func(c *gin.Context) {
+		got = utils.PaginationFromContext(ginx.New(c))
+		c.Status(http.StatusOK)
+	})
+
+	// Create request asking for more than the cap
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test?page=3&perPage=1000", nil)
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, utils.Pagination{Page: 3, PerPage: 50, Offset: 100}, got)
+}
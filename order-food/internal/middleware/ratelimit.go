@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// Rate limit response headers
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// QuotaChecker counts requests against callers' quotas
+type QuotaChecker interface {
+	// Consume counts a request by principal and returns its quota afterwards
+	Consume(principal string) (models.Quota, error)
+}
+
+// RateLimitMiddleware counts each request by an authenticated caller against
+// its quota and rejects it with 429 once the quota is used up. It must run
+// after the auth middleware; anonymous requests are not limited. When the
+// counter is unavailable requests are let through rather than failing the
+// API.
+func RateLimitMiddleware(quotas QuotaChecker) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, values := httpx.WithValues(r)
+			principal := utils.PrincipalFromContext(values)
+			if principal == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			quota, err := quotas.Consume(principal)
+			if err != nil {
+				slog.WarnContext(r.Context(), "Rate limit check failed", "principal", principal, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			utils.SetQuota(values, quota)
+
+			header := w.Header()
+			header.Set(RateLimitLimitHeader, strconv.Itoa(quota.Limit))
+			header.Set(RateLimitRemainingHeader, strconv.Itoa(max(quota.Remaining, 0)))
+			header.Set(RateLimitResetHeader, strconv.FormatInt(quota.ResetAt.Unix(), 10))
+
+			if quota.Remaining < 0 {
+				retryAfter := max(int(math.Ceil(time.Until(quota.ResetAt).Seconds())), 1)
+				header.Set("Retry-After", strconv.Itoa(retryAfter))
+				writeError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryQuotas is an in-memory QuotaChecker allowing limit requests per principal
+type memoryQuotas struct {
+	limit int
+	used  map[string]int
+	err   error
+}
+
+func (q *memoryQuotas) Consume(principal string) (models.Quota, error) {
+	if q.err != nil {
+		return models.Quota{}, q.err
+	}
+	q.used[principal]++
+	return models.Quota{
+		Limit:     q.limit,
+		Remaining: q.limit - q.used[principal],
+		Window:    60,
+		ResetAt:   time.Now().Add(30 * time.Second),
+	}, nil
+}
+
+func newRateLimitRouter(quotas QuotaChecker) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(OptionalAuthMiddleware()), ginx.Middleware(RateLimitMiddleware(quotas)))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestRateLimitMiddleware_RejectsOnceQuotaIsUsed(t *testing.T) {
+	// Setup
+	router := newRateLimitRouter(&memoryQuotas{limit: 2, used: map[string]int{}})
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		// Create request
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set(APIKeyHeader, ValidAPIKey)
+
+		// Execute
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, want, w.Code, "request %d", i+1)
+		assert.Equal(t, "2", w.Header().Get(RateLimitLimitHeader))
+		assert.Equal(t, strconv.Itoa(max(1-i, 0)), w.Header().Get(RateLimitRemainingHeader))
+	}
+}
+
+func TestRateLimitMiddleware_RetryAfter(t *testing.T) {
+	// Setup
+	router := newRateLimitRouter(&memoryQuotas{limit: 0, used: map[string]int{}})
+
+	// Create request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(APIKeyHeader, ValidAPIKey)
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	assert.NoError(t, err)
+	assert.InDelta(t, 30, retryAfter, 1)
+}
+
+func TestRateLimitMiddleware_PassesThrough(t *testing.T) {
+	tests := []struct {
+		name   string
+		quotas *memoryQuotas
+		apiKey string
+	}{
+		{name: "anonymous", quotas: &memoryQuotas{limit: 0, used: map[string]int{}}},
+		{name: "counter unavailable", quotas: &memoryQuotas{err: errors.New("db down")}, apiKey: ValidAPIKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			router := newRateLimitRouter(tt.quotas)
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.apiKey != "" {
+				req.Header.Set(APIKeyHeader, tt.apiKey)
+			}
+
+			// Execute
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Empty(t, w.Header().Get(RateLimitLimitHeader))
+		})
+	}
+}
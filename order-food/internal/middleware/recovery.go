@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+)
+
+// RecoveryMiddleware answers requests whose handler panicked with 500 and
+// logs the panic, instead of dropping the connection. Gin's engine recovers
+// on its own; routers on net/http run this first. A panic with
+// http.ErrAbortHandler still aborts the response, as it is meant to.
+func RecoveryMiddleware() httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writer := newResponseWriter(w)
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(recovered)
+				}
+				slog.ErrorContext(r.Context(), "Handler panicked", "method", r.Method, "path", r.URL.Path,
+					"panic", recovered, "stack", string(debug.Stack()))
+				if !writer.Written() {
+					writeError(w, http.StatusInternalServerError, "Internal server error")
+				}
+			}()
+			next.ServeHTTP(writer, r)
+		})
+	}
+}
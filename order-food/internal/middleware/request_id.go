@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+// maxRequestIDLength bounds the request IDs taken from callers, which end
+// up in every log line of the request
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware gives every request an ID: the caller's X-Request-ID
+// when it is a sensible one, so a request can be followed from the caller's
+// logs, or a new UUID. The ID is stored in the request's values, echoed in
+// the X-Request-ID response header and added to JSON error responses as
+// requestId, and every record logged for the request carries it as
+// request_id. It must run before LoggerMiddleware and TracingMiddleware.
+func RequestIDMiddleware() httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(utils.RequestIDHeader)
+			if !validRequestID(id) {
+				id = uuid.NewString()
+			}
+			r, values := httpx.WithValues(r)
+			utils.SetRequestID(values, id)
+			r = addLogAttrs(r, slog.String("request_id", id))
+			w.Header().Set(utils.RequestIDHeader, id)
+			next.ServeHTTP(&requestIDWriter{responseWriter: newResponseWriter(w), field: []byte(`{"requestId":` + strconv.Quote(id) + `,`)}, r)
+		})
+	}
+}
+
+// validRequestID reports whether id is short printable ASCII without
+// spaces, so it cannot forge log lines or headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	return !strings.ContainsFunc(id, func(r rune) bool { return r <= ' ' || r > '~' })
+}
+
+// errorPrefix starts every models.ErrorResponse document
+var errorPrefix = []byte(`{"code":`)
+
+// requestIDWriter adds the request ID to the JSON error document of an
+// error response as it is written
+type requestIDWriter struct {
+	*responseWriter
+	field   []byte
+	checked bool
+}
+
+func (w *requestIDWriter) Write(data []byte) (int, error) {
+	if w.checked {
+		return w.responseWriter.Write(data)
+	}
+	w.checked = true
+	if w.Status() < 400 || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") || !bytes.HasPrefix(data, errorPrefix) {
+		return w.responseWriter.Write(data)
+	}
+	if _, err := w.responseWriter.Write(w.field); err != nil {
+		return 0, err
+	}
+	n, err := w.responseWriter.Write(data[1:])
+	return n + 1, err
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantKept bool
+	}{
+		{name: "honors caller ID", header: "checkout-7f3a", wantKept: true},
+		{name: "generates when missing", header: ""},
+		{name: "replaces ID with spaces", header: "forged\nline"},
+		{name: "replaces overlong ID", header: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(ginx.Middleware(RequestIDMiddleware()))
+			var seen string
+			router.GET("/test", func(c *gin.Context) {
+				seen = utils.RequestIDFromContext(c)
+				c.JSON(http.StatusOK, gin.H{"message": "success"})
+			})
+
+			// Create request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.header != "" {
+				req.Header.Set(utils.RequestIDHeader, tt.header)
+			}
+
+			// Execute
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, seen, w.Header().Get(utils.RequestIDHeader))
+			if tt.wantKept {
+				assert.Equal(t, tt.header, seen)
+			} else {
+				_, err := uuid.Parse(seen)
+				assert.NoError(t, err)
+			}
+			assert.JSONEq(t, `{"message":"success"}`, w.Body.String(), "successful responses are left alone")
+		})
+	}
+}
+
+func TestRequestIDMiddleware_ErrorResponses(t *testing.T) {
+	// Setup
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(RequestIDMiddleware()), ginx.Middleware(LoggerMiddleware()))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, models.ErrorResponse(http.StatusNotFound, "Product not found"))
+	})
+
+	// Create request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(utils.RequestIDHeader, "checkout-7f3a")
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, map[string]any{"requestId": "checkout-7f3a", "code": 404.0, "type": "error", "message": "Product not found"}, body)
+	assert.Contains(t, buf.String(), "request_id=checkout-7f3a")
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// writeJSON answers with obj as the JSON body, like the handlers do
+func writeJSON(w http.ResponseWriter, status int, obj any) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		slog.Error("Failed to encode response", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// writeError answers with the error document of status and message
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, models.ErrorResponse(status, message))
+}
+
+// responseWriter records the status of a response as it passes, for
+// middleware acting on how the request was answered
+type responseWriter struct {
+	http.ResponseWriter
+	status  int
+	written bool
+}
+
+func newResponseWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.written {
+		w.status = status
+		w.written = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Status returns the status of the response, 200 until one is written
+func (w *responseWriter) Status() int { return w.status }
+
+// Written reports whether the response was started
+func (w *responseWriter) Written() bool { return w.written }
+
+// Flush sends what was written so far, for streamed responses
+func (w *responseWriter) Flush() {
+	w.written = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// bodyRecorder keeps a copy of the response body as it is written
+type bodyRecorder struct {
+	*responseWriter
+	body bytes.Buffer
+}
+
+func newBodyRecorder(w http.ResponseWriter) *bodyRecorder {
+	return &bodyRecorder{responseWriter: newResponseWriter(w)}
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.responseWriter.Write(b)
+}
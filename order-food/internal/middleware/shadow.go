@@ -0,0 +1,355 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+)
+
+const (
+	// ShadowHeader marks requests replayed to the shadow target, so it can
+	// tell mirrored traffic from real traffic
+	ShadowHeader = "X-Shadow-Request"
+	// defaultShadowInFlight bounds the shadow requests running at once when
+	// ShadowConfig.MaxInFlight is zero
+	defaultShadowInFlight = 16
+	// shadowTimeout bounds a shadow request, which outlives the request it
+	// mirrors
+	shadowTimeout = 10 * time.Second
+	// maxShadowDiffs caps the differences logged for one response
+	maxShadowDiffs = 10
+)
+
+// ShadowConfig selects the reads mirrored to a shadow target
+type ShadowConfig struct {
+	// Target serves the new implementations of the mirrored routes, such as
+	// a proxy to a deployment running the v2 handlers
+	Target http.Handler
+	// Routes are the "METHOD /path" router patterns mirrored, for example
+	// "GET /api/v1/products/:productId"; every read is mirrored when empty
+	Routes []string
+	// Rate is the fraction of reads mirrored
+	Rate float64
+	// KeyRates overrides Rate for callers by principal, such as
+	// "partner:acme", so a rollout can start with a few API keys
+	KeyRates map[string]float64
+	// MaxInFlight bounds the shadow requests running at once; reads
+	// arriving while it is reached are not mirrored
+	MaxInFlight int
+}
+
+// Descriptors of the shadow traffic counters
+var (
+	shadowRequestsDesc = prometheus.NewDesc("shadow_requests_total", "Reads replayed to the shadow target.", nil, nil)
+	shadowMatchesDesc  = prometheus.NewDesc("shadow_matches_total", "Shadow responses matching the response the client got.", nil, nil)
+	shadowDiffsDesc    = prometheus.NewDesc("shadow_diffs_total", "Shadow responses differing from the response the client got.", nil, nil)
+	shadowFailuresDesc = prometheus.NewDesc("shadow_failures_total", "Shadow requests that timed out or panicked.", nil, nil)
+	shadowSkippedDesc  = prometheus.NewDesc("shadow_skipped_total", "Sampled reads not mirrored because too many shadow requests were running.", nil, nil)
+)
+
+// ShadowTraffic replays a sample of read requests to a shadow target after
+// they have been answered, and logs where the shadow's response differs
+// from the one the client got. Clients never wait for or see the shadow's
+// response. Only GET and HEAD requests are mirrored, so the shadow cannot
+// change data. It is safe for concurrent use.
+type ShadowTraffic struct {
+	target   http.Handler
+	routes   map[string]bool
+	rate     float64
+	keyRates map[string]float64
+	random   func() float64
+	slots    chan struct{}
+
+	mirrored atomic.Int64
+	matched  atomic.Int64
+	differed atomic.Int64
+	failed   atomic.Int64
+	skipped  atomic.Int64
+}
+
+// NewShadowTraffic creates a mirror of reads to cfg.Target
+func NewShadowTraffic(cfg ShadowConfig) *ShadowTraffic {
+	return newShadowTraffic(cfg, rand.Float64)
+}
+
+// newShadowTraffic is NewShadowTraffic with an injectable random source
+func newShadowTraffic(cfg ShadowConfig, random func() float64) *ShadowTraffic {
+	inFlight := cfg.MaxInFlight
+	if inFlight <= 0 {
+		inFlight = defaultShadowInFlight
+	}
+	s := &ShadowTraffic{
+		target:   cfg.Target,
+		rate:     cfg.Rate,
+		keyRates: cfg.KeyRates,
+		random:   random,
+		slots:    make(chan struct{}, inFlight),
+	}
+	if len(cfg.Routes) > 0 {
+		s.routes = make(map[string]bool, len(cfg.Routes))
+		for _, route := range cfg.Routes {
+			s.routes[strings.Join(strings.Fields(route), " ")] = true
+		}
+	}
+	return s
+}
+
+// Middleware returns the handler mirroring the reads of the routes it is
+// added to. It must run before authentication, so the caller is known
+// once the request has been answered.
+func (s *ShadowTraffic) Middleware() httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.Method + " " + httpx.RouteOf(r)
+			if (r.Method != http.MethodGet && r.Method != http.MethodHead) ||
+				httpx.RouteOf(r) == "" || (s.routes != nil && !s.routes[route]) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			r, values := httpx.WithValues(r)
+			recorder := newBodyRecorder(w)
+			next.ServeHTTP(recorder, r)
+
+			// Refused, failed and streamed responses say nothing about the
+			// new handlers
+			status := recorder.Status()
+			if refused(status) || status >= http.StatusInternalServerError ||
+				strings.Contains(w.Header().Get("Content-Type"), "application/x-ndjson") {
+				return
+			}
+			caller := utils.PrincipalFromContext(values)
+			rate, ok := s.keyRates[caller]
+			if !ok {
+				rate = s.rate
+			}
+			if s.random() >= rate {
+				return
+			}
+			select {
+			case s.slots <- struct{}{}:
+			default:
+				s.skipped.Add(1)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), shadowTimeout)
+			request := r.Clone(ctx)
+			request.Body = http.NoBody
+			request.Header.Set(ShadowHeader, "true")
+			primary := shadowResponse{status: status, body: slices.Clone(recorder.body.Bytes())}
+			requestID := utils.RequestIDFromContext(values)
+			go func() {
+				defer func() { <-s.slots }()
+				defer cancel()
+				s.compare(ctx, route, caller, requestID, request, primary)
+			}()
+		})
+	}
+}
+
+// refused reports whether status is how the auth and rate limit
+// middleware turn a request away
+func refused(status int) bool {
+	return status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusTooManyRequests
+}
+
+// shadowResponse is a response captured for comparison
+type shadowResponse struct {
+	status int
+	body   []byte
+}
+
+// compare replays request to the target and logs how its response differs
+// from primary
+func (s *ShadowTraffic) compare(ctx context.Context, route, caller, requestID string, request *http.Request, primary shadowResponse) {
+	s.mirrored.Add(1)
+	defer func() {
+		if r := recover(); r != nil {
+			s.failed.Add(1)
+			slog.ErrorContext(ctx, "Shadow request panicked", "route", route, "request_id", requestID, "panic", r)
+		}
+	}()
+
+	writer := &shadowWriter{header: make(http.Header)}
+	s.target.ServeHTTP(writer, request)
+	if ctx.Err() != nil {
+		s.failed.Add(1)
+		slog.WarnContext(ctx, "Shadow request timed out", "route", route, "request_id", requestID)
+		return
+	}
+
+	shadow := shadowResponse{status: writer.Status(), body: writer.body.Bytes()}
+	diffs := diffResponses(primary, shadow)
+	if len(diffs) == 0 {
+		s.matched.Add(1)
+		return
+	}
+	s.differed.Add(1)
+	slog.WarnContext(ctx, "Shadow response differs",
+		"route", route,
+		"path", request.URL.RequestURI(),
+		"caller", caller,
+		"request_id", requestID,
+		"status", primary.status,
+		"shadow_status", shadow.status,
+		"diffs", diffs,
+	)
+}
+
+// diffResponses lists where shadow differs from primary: the status, then
+// the JSON paths whose values differ, or the body as a whole when either
+// is not JSON
+func diffResponses(primary, shadow shadowResponse) []string {
+	var diffs []string
+	if primary.status != shadow.status {
+		diffs = append(diffs, fmt.Sprintf("status: %d != %d", primary.status, shadow.status))
+	}
+
+	var want, got any
+	if json.Unmarshal(primary.body, &want) != nil || json.Unmarshal(shadow.body, &got) != nil {
+		if !bytes.Equal(primary.body, shadow.body) {
+			diffs = append(diffs, fmt.Sprintf("body: %d bytes != %d bytes", len(primary.body), len(shadow.body)))
+		}
+		return diffs
+	}
+	return diffJSON("$", want, got, diffs)
+}
+
+// diffJSON appends the paths under path where the decoded JSON values
+// want and got differ, up to maxShadowDiffs
+func diffJSON(path string, want, got any, diffs []string) []string {
+	if len(diffs) >= maxShadowDiffs {
+		return diffs
+	}
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(w)+len(g))
+		for key := range w {
+			keys = append(keys, key)
+		}
+		for key := range g {
+			if _, ok := w[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			diffs = diffJSON(path+"."+key, w[key], g[key], diffs)
+		}
+		return diffs
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			break
+		}
+		for i := range w {
+			diffs = diffJSON(fmt.Sprintf("%s[%d]", path, i), w[i], g[i], diffs)
+		}
+		return diffs
+	}
+	if reflect.DeepEqual(want, got) {
+		return diffs
+	}
+	return append(diffs, fmt.Sprintf("%s: %s != %s", path, shadowValue(want), shadowValue(got)))
+}
+
+// shadowValue describes a differing JSON value briefly; objects and
+// arrays, which differ in shape, are only named
+func shadowValue(v any) string {
+	switch v := v.(type) {
+	case map[string]any:
+		return fmt.Sprintf("object(%d keys)", len(v))
+	case []any:
+		return fmt.Sprintf("array(%d)", len(v))
+	case nil:
+		return "missing"
+	}
+	b, _ := json.Marshal(v)
+	if len(b) > 64 {
+		return string(b[:64]) + "..."
+	}
+	return string(b)
+}
+
+// ParseShadowRates parses comma-separated principal=rate pairs, such as
+// "*=0.05,partner:acme=1", where * stands for every other caller
+func ParseShadowRates(spec string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		principal, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(principal) == "" {
+			return nil, fmt.Errorf("shadow rate %q: expected principal=rate", entry)
+		}
+		rate, err := parseRate(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("shadow rate %q: %w", entry, err)
+		}
+		rates[strings.TrimSpace(principal)] = rate
+	}
+	return rates, nil
+}
+
+// Describe sends the descriptors of the shadow traffic counters
+func (s *ShadowTraffic) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{shadowRequestsDesc, shadowMatchesDesc, shadowDiffsDesc, shadowFailuresDesc, shadowSkippedDesc} {
+		ch <- desc
+	}
+}
+
+// Collect sends the shadow traffic counters
+func (s *ShadowTraffic) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(shadowRequestsDesc, prometheus.CounterValue, float64(s.mirrored.Load()))
+	ch <- prometheus.MustNewConstMetric(shadowMatchesDesc, prometheus.CounterValue, float64(s.matched.Load()))
+	ch <- prometheus.MustNewConstMetric(shadowDiffsDesc, prometheus.CounterValue, float64(s.differed.Load()))
+	ch <- prometheus.MustNewConstMetric(shadowFailuresDesc, prometheus.CounterValue, float64(s.failed.Load()))
+	ch <- prometheus.MustNewConstMetric(shadowSkippedDesc, prometheus.CounterValue, float64(s.skipped.Load()))
+}
+
+// shadowWriter records the response of the shadow target
+type shadowWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *shadowWriter) Header() http.Header { return w.header }
+
+func (w *shadowWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *shadowWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+func (w *shadowWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
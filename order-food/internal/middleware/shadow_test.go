@@ -0,0 +1,300 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// shadowTarget answers the mirrored requests it receives with body and
+// hands each request to requests
+type shadowTarget struct {
+	body     string
+	requests chan *http.Request
+}
+
+func (t *shadowTarget) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(t.body))
+	t.requests <- r
+}
+
+// shadowRouter serves GET and POST /products/:productId with the caller
+// taken from the X-Caller header, mirroring reads through shadow
+func shadowRouter(shadow *ShadowTraffic) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginx.Middleware(shadow.Middleware()))
+	authenticate := func(c *gin.Context) {
+		utils.SetPrincipal(c, c.GetHeader("X-Caller"), nil)
+	}
+	answer := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("productId"), "price": 6.5, "tags": []string{"sweet"}})
+	}
+	router.GET("/products/:productId", authenticate, answer)
+	router.POST("/products/:productId", authenticate, answer)
+	router.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.String(http.StatusOK, "{}\n")
+	})
+	return router
+}
+
+// captureLogs sends the default logger's output to the returned buffer
+// until the test ends
+func captureLogs(t *testing.T) *syncBuffer {
+	logs := &syncBuffer{}
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return logs
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// nextShadowRequest waits for the target to receive a mirrored request
+func nextShadowRequest(t *testing.T, target *shadowTarget) *http.Request {
+	t.Helper()
+	select {
+	case r := <-target.requests:
+		return r
+	case <-time.After(time.Second):
+		t.Fatal("request was not mirrored")
+		return nil
+	}
+}
+
+// waitForCount waits until read returns want
+func waitForCount(t *testing.T, read func() int64, want int64) {
+	t.Helper()
+	assert.Eventually(t, func() bool { return read() == want }, time.Second, time.Millisecond)
+}
+
+func TestShadowTraffic_MatchingResponse(t *testing.T) {
+	// Setup
+	target := &shadowTarget{body: `{"tags":["sweet"],"price":6.5,"id":"1"}`, requests: make(chan *http.Request, 1)}
+	shadow := newShadowTraffic(ShadowConfig{Target: target, Rate: 1}, func() float64 { return 0.5 })
+	router := shadowRouter(shadow)
+	logs := captureLogs(t)
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products/1?lang=de", nil))
+
+	// Assert: the client is answered by v1 and the same read is replayed
+	assert.Equal(t, http.StatusOK, w.Code)
+	mirrored := nextShadowRequest(t, target)
+	assert.Equal(t, "/products/1?lang=de", mirrored.URL.RequestURI())
+	assert.Equal(t, "true", mirrored.Header.Get(ShadowHeader))
+	waitForCount(t, shadow.matched.Load, 1)
+	assert.Zero(t, shadow.differed.Load())
+	assert.NotContains(t, logs.String(), "Shadow response differs")
+}
+
+func TestShadowTraffic_LogsDiffs(t *testing.T) {
+	// Setup
+	target := &shadowTarget{body: `{"id":"1","price":7,"tags":["sweet"],"currency":"EUR"}`, requests: make(chan *http.Request, 1)}
+	shadow := newShadowTraffic(ShadowConfig{Target: target, Rate: 1}, func() float64 { return 0 })
+	router := shadowRouter(shadow)
+	logs := captureLogs(t)
+
+	// Execute
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/products/1", nil))
+
+	// Assert
+	nextShadowRequest(t, target)
+	waitForCount(t, shadow.differed.Load, 1)
+	assert.Contains(t, logs.String(), "Shadow response differs")
+	assert.Contains(t, logs.String(), "$.currency: missing != \\\"EUR\\\"")
+	assert.Contains(t, logs.String(), "$.price: 6.5 != 7")
+	assert.Contains(t, logs.String(), "route=\"GET /products/:productId\"")
+}
+
+func TestShadowTraffic_SamplesPerKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller string
+		want   bool
+	}{
+		{name: "key rolled out", caller: "partner:acme", want: true},
+		{name: "key held back", caller: "partner:globex", want: false},
+		{name: "other keys use the default rate", caller: "partner:initech", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			target := &shadowTarget{body: `{}`, requests: make(chan *http.Request, 1)}
+			shadow := newShadowTraffic(ShadowConfig{
+				Target:   target,
+				Rate:     0.1,
+				KeyRates: map[string]float64{"partner:acme": 1, "partner:globex": 0},
+			}, func() float64 { return 0.5 })
+			router := shadowRouter(shadow)
+
+			// Execute
+			req := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+			req.Header.Set("X-Caller", tt.caller)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Assert
+			if tt.want {
+				mirrored := nextShadowRequest(t, target)
+				assert.Equal(t, tt.caller, mirrored.Header.Get("X-Caller"))
+				return
+			}
+			assert.Never(t, func() bool { return len(target.requests) > 0 }, 50*time.Millisecond, 5*time.Millisecond)
+		})
+	}
+}
+
+func TestShadowTraffic_OnlyMirrorsReads(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		routes []string
+	}{
+		{name: "writes", method: http.MethodPost, target: "/products/1"},
+		{name: "streams", method: http.MethodGet, target: "/stream"},
+		{name: "unmatched routes", method: http.MethodGet, target: "/missing"},
+		{name: "routes not selected", method: http.MethodGet, target: "/products/1", routes: []string{"GET /stream"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			target := &shadowTarget{body: `{}`, requests: make(chan *http.Request, 1)}
+			shadow := newShadowTraffic(ShadowConfig{Target: target, Routes: tt.routes, Rate: 1}, func() float64 { return 0 })
+			router := shadowRouter(shadow)
+
+			// Execute
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, strings.NewReader(`{}`)))
+
+			// Assert
+			assert.Never(t, func() bool { return len(target.requests) > 0 }, 50*time.Millisecond, 5*time.Millisecond)
+			assert.Zero(t, shadow.mirrored.Load())
+		})
+	}
+}
+
+func TestShadowTraffic_SkipsWhenBusy(t *testing.T) {
+	// Setup: the target holds the only slot until released
+	release := make(chan struct{})
+	var served sync.WaitGroup
+	served.Add(1)
+	target := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer served.Done()
+		<-release
+	})
+	shadow := newShadowTraffic(ShadowConfig{Target: target, Rate: 1, MaxInFlight: 1}, func() float64 { return 0 })
+	router := shadowRouter(shadow)
+
+	// Execute
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/products/1", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/products/2", nil))
+	close(release)
+	served.Wait()
+
+	// Assert
+	assert.Equal(t, int64(1), shadow.skipped.Load())
+	waitForCount(t, shadow.mirrored.Load, 1)
+}
+
+func TestShadowTraffic_RecoversPanics(t *testing.T) {
+	// Setup
+	target := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { panic("v2 is broken") })
+	shadow := newShadowTraffic(ShadowConfig{Target: target, Rate: 1}, func() float64 { return 0 })
+	router := shadowRouter(shadow)
+	captureLogs(t)
+
+	// Execute
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products/1", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	waitForCount(t, shadow.failed.Load, 1)
+}
+
+func TestDiffResponses(t *testing.T) {
+	tests := []struct {
+		name            string
+		primary, shadow shadowResponse
+		want            []string
+	}{
+		{
+			name:    "equal JSON in another order",
+			primary: shadowResponse{status: 200, body: []byte(`{"a":1,"b":[1,2]}`)},
+			shadow:  shadowResponse{status: 200, body: []byte(`{"b":[1,2],"a":1}`)},
+		},
+		{
+			name:    "status",
+			primary: shadowResponse{status: 200, body: []byte(`{}`)},
+			shadow:  shadowResponse{status: 404, body: []byte(`{}`)},
+			want:    []string{"status: 200 != 404"},
+		},
+		{
+			name:    "nested values",
+			primary: shadowResponse{status: 200, body: []byte(`{"data":[{"id":"1","price":6.5}]}`)},
+			shadow:  shadowResponse{status: 200, body: []byte(`{"data":[{"id":"1","price":"6.50"}]}`)},
+			want:    []string{`$.data[0].price: 6.5 != "6.50"`},
+		},
+		{
+			name:    "array length",
+			primary: shadowResponse{status: 200, body: []byte(`{"data":[1,2]}`)},
+			shadow:  shadowResponse{status: 200, body: []byte(`{"data":[1]}`)},
+			want:    []string{"$.data: array(2) != array(1)"},
+		},
+		{
+			name:    "not JSON",
+			primary: shadowResponse{status: 200, body: []byte("id,name\n")},
+			shadow:  shadowResponse{status: 200, body: []byte("id;name\n")},
+			want:    []string{"body: 8 bytes != 8 bytes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, diffResponses(tt.primary, tt.shadow))
+		})
+	}
+}
+
+func TestParseShadowRates(t *testing.T) {
+	rates, err := ParseShadowRates(" partner:acme=1, partner:globex = 0.25 ,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"partner:acme": 1, "partner:globex": 0.25}, rates)
+
+	for _, spec := range []string{"partner:acme", "=0.5", "partner:acme=2", "partner:acme=half"} {
+		_, err := ParseShadowRates(spec)
+		assert.Error(t, err, spec)
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/tracing"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware starts a server span for every request, continuing the
+// caller's trace when it sends trace context headers. The span carries the
+// request ID set by RequestIDMiddleware, and records logged for the request
+// carry the trace ID as trace_id. Handlers reach the span through the
+// request context. Spans are dropped unless provider is backed by an SDK.
+func TracingMiddleware(provider trace.TracerProvider) httpx.Middleware {
+	tracer := provider.Tracer(tracing.TracerName)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, values := httpx.WithValues(r)
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			route := httpx.RouteOf(r)
+			if route == "" {
+				route = "unmatched"
+			}
+			ctx, span := tracer.Start(ctx, r.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("http.route", route),
+				),
+			)
+			defer span.End()
+			if id := utils.RequestIDFromContext(values); id != "" {
+				span.SetAttributes(attribute.String("http.request.id", id))
+			}
+
+			r = r.WithContext(ctx)
+			if sc := span.SpanContext(); sc.HasTraceID() {
+				r = addLogAttrs(r, slog.String("trace_id", sc.TraceID().String()))
+			}
+			writer := newResponseWriter(w)
+			next.ServeHTTP(writer, r)
+
+			status := writer.Status()
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= 500 {
+				span.SetStatus(codes.Error, "")
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware_StartsServerSpan(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	router := gin.New()
+	router.Use(ginx.Middleware(TracingMiddleware(provider)))
+	var handlerSpan trace.SpanContext
+	router.GET("/orders/:orderId", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusInternalServerError)
+	})
+
+	// Create request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/orders/42", nil)
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "GET /orders/:orderId", spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), handlerSpan.SpanID())
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("http.response.status_code", http.StatusInternalServerError))
+	assert.Equal(t, "Error", spans[0].Status().Code.String())
+}
+
+func TestTracingMiddleware_RecordsRequestID(t *testing.T) {
+	// Setup
+	gin.SetMode(gin.TestMode)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	router := gin.New()
+	router.Use(ginx.Middleware(RequestIDMiddleware()), ginx.Middleware(TracingMiddleware(provider)))
+	router.GET("/products", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	// Create request
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/products", nil)
+	req.Header.Set("X-Request-ID", "checkout-7f3a")
+
+	// Execute
+	router.ServeHTTP(w, req)
+
+	// Assert
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Contains(t, spans[0].Attributes(), attribute.String("http.request.id", "checkout-7f3a"))
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/webhook"
+)
+
+// maxWebhookBodyBytes bounds how much of a callback is read for verification
+const maxWebhookBodyBytes = 1 << 20
+
+// WebhookEventStore remembers which webhook events have been accepted
+type WebhookEventStore interface {
+	// Claim reports whether the event is new and records it
+	Claim(source, eventID string) (bool, error)
+	// Release forgets an event whose processing failed
+	Release(source, eventID string) error
+}
+
+// WebhookMiddleware authenticates callbacks from source. The body must be
+// signed by verifier and carry an event ID that store has not seen; a
+// replayed event is acknowledged with 200 and never reaches the handler, so
+// a sender retrying a delivered callback stops without it being applied
+// twice. When the handler fails with a 5xx the claim is released so the
+// sender's retry is processed.
+func WebhookMiddleware(source string, verifier *webhook.Verifier, store WebhookEventStore) httpx.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodyBytes+1))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "Failed to read webhook body")
+				return
+			}
+			if len(body) > maxWebhookBodyBytes {
+				writeError(w, http.StatusRequestEntityTooLarge, "Webhook body is too large")
+				return
+			}
+
+			if err := verifier.Verify(r.Header.Get(webhook.SignatureHeader), body); err != nil {
+				if errors.Is(err, webhook.ErrStaleTimestamp) {
+					slog.WarnContext(r.Context(), "Rejected webhook outside the timestamp window", "source", source)
+				}
+				writeError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+				return
+			}
+
+			eventID := strings.TrimSpace(r.Header.Get(webhook.EventIDHeader))
+			if eventID == "" {
+				writeError(w, http.StatusBadRequest, webhook.EventIDHeader+" header is required")
+				return
+			}
+
+			first, err := store.Claim(source, eventID)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "Error recording webhook", "source", source, "event_id", eventID, "error", err)
+				writeError(w, http.StatusServiceUnavailable, "Webhook could not be recorded")
+				return
+			}
+			if !first {
+				slog.InfoContext(r.Context(), "Ignored replayed webhook", "source", source, "event_id", eventID)
+				writeJSON(w, http.StatusOK, map[string]any{"eventId": eventID, "duplicate": true})
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			writer := newResponseWriter(w)
+			next.ServeHTTP(writer, r)
+
+			if writer.Status() >= http.StatusInternalServerError {
+				if err := store.Release(source, eventID); err != nil {
+					slog.ErrorContext(r.Context(), "Error releasing webhook", "source", source, "event_id", eventID, "error", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryEventStore is an in-memory WebhookEventStore
+type memoryEventStore map[string]bool
+
+func (s memoryEventStore) Claim(source, eventID string) (bool, error) {
+	if s[source+"/"+eventID] {
+		return false, nil
+	}
+	s[source+"/"+eventID] = true
+	return true, nil
+}
+
+func (s memoryEventStore) Release(source, eventID string) error {
+	delete(s, source+"/"+eventID)
+	return nil
+}
+
+func newWebhookRouter(store memoryEventStore, status *int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	verifier := webhook.NewVerifier([]string{"secret"}, webhook.DefaultTolerance)
+	router.POST("/webhooks/payments", ginx.Middleware(WebhookMiddleware("payments", verifier, store)), func(c *gin.Context) {
+		*calls++
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(*status, string(body))
+	})
+	return router
+}
+
+func signedWebhook(eventID, body, secret string, at time.Time) *http.Request {
+	req := httptest.NewRequest("POST", "/webhooks/payments", strings.NewReader(body))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(secret, at, []byte(body)))
+	req.Header.Set(webhook.EventIDHeader, eventID)
+	return req
+}
+
+func TestWebhookMiddleware_ReplayIsNotProcessedTwice(t *testing.T) {
+	// Setup
+	status, calls := http.StatusOK, 0
+	router := newWebhookRouter(memoryEventStore{}, &status, &calls)
+	body := `{"type":"payment.succeeded"}`
+
+	// Execute
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, signedWebhook("evt_1", body, "secret", time.Now()))
+	replay := httptest.NewRecorder()
+	router.ServeHTTP(replay, signedWebhook("evt_1", body, "secret", time.Now()))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, body, first.Body.String())
+	assert.Equal(t, http.StatusOK, replay.Code)
+	assert.Contains(t, replay.Body.String(), `"duplicate":true`)
+	assert.Equal(t, 1, calls)
+}
+
+func TestWebhookMiddleware_FailedEventIsRetried(t *testing.T) {
+	// Setup
+	status, calls := http.StatusInternalServerError, 0
+	router := newWebhookRouter(memoryEventStore{}, &status, &calls)
+
+	// Execute
+	router.ServeHTTP(httptest.NewRecorder(), signedWebhook("evt_1", "{}", "secret", time.Now()))
+	status = http.StatusOK
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, signedWebhook("evt_1", "{}", "secret", time.Now()))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, calls)
+}
+
+func TestWebhookMiddleware_Rejects(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        *http.Request
+		wantStatus int
+	}{
+		{name: "wrong secret", req: signedWebhook("evt_1", "{}", "other", time.Now()), wantStatus: http.StatusUnauthorized},
+		{name: "stale timestamp", req: signedWebhook("evt_1", "{}", "secret", time.Now().Add(-time.Hour)), wantStatus: http.StatusUnauthorized},
+		{name: "missing event ID", req: signedWebhook("", "{}", "secret", time.Now()), wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			status, calls := http.StatusOK, 0
+			router := newWebhookRouter(memoryEventStore{}, &status, &calls)
+
+			// Execute
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, 0, calls)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package models
+
+// Audit log actions
+const (
+	AuditActionBulkPrice         = "products.bulk_price"
+	AuditActionCampaignCreate    = "campaigns.create"
+	AuditActionCategoryCreate    = "categories.create"
+	AuditActionCategoryUpdate    = "categories.update"
+	AuditActionCategoryDelete    = "categories.delete"
+	AuditActionProductCreate     = "products.create"
+	AuditActionProductUpdate     = "products.update"
+	AuditActionProductDelete     = "products.delete"
+	AuditActionProductOptions    = "products.option_groups"
+	AuditActionPromoCodeDiscount = "promo_codes.discount"
+	AuditActionPromoCodeLimits   = "promo_codes.limits"
+	AuditActionStockSet          = "products.stock_set"
+	AuditActionStockAdjust       = "products.stock_adjust"
+)
+
+// AuditEntry records a change made through the admin API. Details is
+// stored as JSON.
+type AuditEntry struct {
+	Action  string
+	Actor   string
+	Details any
+}
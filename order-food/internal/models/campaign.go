@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// CampaignReq represents a request to generate the promo codes of a campaign
+type CampaignReq struct {
+	Name  string `json:"name" binding:"required,max=64" example:"Summer 2025"`
+	Count int    `json:"count" binding:"required,min=1,max=100000" example:"1000"`
+	// Prefix starts every code and counts towards Length
+	Prefix string `json:"prefix,omitempty" binding:"max=6" example:"SUM"`
+	// Charset holds the upper-case letters and digits codes are drawn
+	// from; a set without easily confused characters when empty
+	Charset string `json:"charset,omitempty" binding:"max=36"`
+	// Length of each code including the prefix; 8 when zero
+	Length int `json:"length,omitempty" example:"10"`
+	// Files is how many coupons.file_name values every code is written
+	// under; promo codes are only valid in 2 or more
+	Files int `json:"files,omitempty" binding:"omitempty,min=2,max=10" example:"2"`
+}
+
+// Campaign is a set of promo codes generated through the admin API
+type Campaign struct {
+	ID         string    `json:"id" example:"5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c"`
+	Name       string    `json:"name" example:"Summer 2025"`
+	Prefix     string    `json:"prefix,omitempty" example:"SUM"`
+	CodeLength int       `json:"codeLength" example:"10"`
+	CodeCount  int       `json:"codeCount" example:"1000"`
+	FileNames  []string  `json:"fileNames"`
+	CreatedBy  string    `json:"createdBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// CartItemReq adds a product to a cart, or more of it when it is already
+// in the cart
+type CartItemReq struct {
+	ProductID string `json:"productId" binding:"required,max=50" example:"1"`
+	Quantity  int    `json:"quantity" binding:"required,min=1,max=1000" example:"2"`
+}
+
+// CartReq creates a cart, optionally with items and a promo code
+type CartReq struct {
+	Items      []CartItemReq `json:"items" binding:"max=100,dive"`
+	CouponCode string        `json:"couponCode,omitempty" example:"HAPPYHRS"`
+}
+
+// CartQuantityReq sets the quantity of a product in a cart
+type CartQuantityReq struct {
+	Quantity int `json:"quantity" binding:"required,min=1,max=1000" example:"3"`
+}
+
+// CartCouponReq sets the promo code of a cart
+type CartCouponReq struct {
+	CouponCode string `json:"couponCode" binding:"required" example:"HAPPYHRS"`
+}
+
+// CheckoutReq converts a cart into an order. The items and promo code are
+// taken from the cart.
+type CheckoutReq struct {
+	// ReservationID converts a stock reservation made for this checkout
+	ReservationID string `json:"reservationId,omitempty"`
+	// CustomerID identifies the customer in the calling system; promo
+	// codes limited to one use per customer require it
+	CustomerID string `json:"customerId,omitempty" binding:"max=64"`
+	// Delivery is where and to whom the order is delivered; orders
+	// collected in store have none
+	Delivery *Delivery `json:"delivery,omitempty"`
+	// PaymentMethod is the customer's card or wallet at the payment provider
+	PaymentMethod string `json:"paymentMethod,omitempty" binding:"max=255" example:"pm_card_visa"`
+}
+
+// CartLine is a product in a cart with its current price
+type CartLine struct {
+	ProductID string  `json:"productId" example:"1"`
+	Name      string  `json:"name" example:"Waffle with Berries"`
+	Category  string  `json:"category" example:"Waffle"`
+	UnitPrice float64 `json:"unitPrice" example:"6.5"`
+	Quantity  int     `json:"quantity" example:"2"`
+	LineTotal float64 `json:"lineTotal" example:"13"`
+	// Discount is the part of the cart discount taken off this line
+	Discount float64 `json:"discount,omitempty" example:"1.3"`
+}
+
+// CartCoupon previews the promo code of a cart. A code that is not applied
+// says why; checking the cart out with it fails for the same reason.
+type CartCoupon struct {
+	Code    string `json:"code" example:"HAPPYHRS"`
+	Applied bool   `json:"applied" example:"true"`
+	Message string `json:"message,omitempty" example:"Promo code does not apply to any item in the cart"`
+}
+
+// Cart is a shopping cart priced at the current product prices, with the
+// discount its promo code would give at checkout
+type Cart struct {
+	ID       string      `json:"id" example:"7f9c2ba4-e88f-4e0b-9a73-0c6d6a3b2f11"`
+	Items    []CartLine  `json:"items"`
+	Coupon   *CartCoupon `json:"coupon,omitempty"`
+	Subtotal float64     `json:"subtotal" example:"13"`
+	Discount float64     `json:"discount" example:"1.3"`
+	Total    float64     `json:"total" example:"11.7"`
+	// OrderID is the order the cart was checked out as; checked out carts
+	// cannot change
+	OrderID   string    `json:"orderId,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Links     []Link    `json:"links,omitempty"`
+}
+
+// StoredCart is a cart as stored, before it is priced
+type StoredCart struct {
+	ID         string
+	AccountID  string
+	CouponCode string
+	OrderID    string
+	Items      []OrderItem
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,20 @@
+package models
+
+// Category is a category of the menu. Products refer to it by ID and show
+// its name in their category field.
+type Category struct {
+	ID          string `json:"id" example:"waffle"`
+	Name        string `json:"name" example:"Waffle"`
+	Description string `json:"description,omitempty" example:"Belgian waffles, sweet and savoury"`
+	// ProductCount is the number of products in the category that are not
+	// deleted
+	ProductCount int `json:"productCount" example:"4"`
+}
+
+// CategoryReq adds or replaces a category. The ID is taken from the path
+// when replacing.
+type CategoryReq struct {
+	ID          string `json:"id,omitempty" example:"waffle"`
+	Name        string `json:"name" binding:"required,max=100" example:"Waffle"`
+	Description string `json:"description,omitempty" binding:"max=2000" example:"Belgian waffles, sweet and savoury"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// DeploymentConfig is the configuration a replica is running with, as far
+// as it varies between environments and regions
+type DeploymentConfig struct {
+	// Environment is the ENVIRONMENT the replica runs in, such as production
+	Environment string `json:"environment,omitempty" example:"production"`
+	// Region is the REGION the replica serves
+	Region string `json:"region,omitempty" example:"eu"`
+	// PromoCodePolicy is the rules promo codes are validated against
+	PromoCodePolicy PromoCodePolicy `json:"promoCodePolicy"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// CouponAnalytics reports promo code usage for orders placed in [From, To)
+type CouponAnalytics struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// TimeZone is the zone ByDay is bucketed in
+	TimeZone string `json:"timeZone"`
+	// Orders is the number of orders placed in the range
+	Orders int `json:"orders"`
+	// Redemptions is the number of orders in the range that used a promo
+	// code, including those archived since
+	Redemptions int `json:"redemptions"`
+	// RedemptionRate is Redemptions divided by Orders
+	RedemptionRate float64 `json:"redemptionRate"`
+	// DiscountTotal is the discount promo codes took off those orders
+	DiscountTotal float64           `json:"discountTotal"`
+	ByCode        []CouponCodeStats `json:"byCode"`
+	ByFile        []CouponFileStats `json:"byFile"`
+	ByDay         []CouponDayStats  `json:"byDay"`
+}
+
+// CouponDayStats is promo code usage on one calendar day
+type CouponDayStats struct {
+	// Date is the day in YYYY-MM-DD form
+	Date        string `json:"date"`
+	Orders      int    `json:"orders"`
+	Redemptions int    `json:"redemptions"`
+}
+
+// CouponCodeStats is the usage of a single promo code
+type CouponCodeStats struct {
+	Code          string  `json:"code"`
+	Redemptions   int     `json:"redemptions"`
+	DiscountTotal float64 `json:"discountTotal"`
+	// ConversionRate is the share of all orders in the range that used the code
+	ConversionRate float64 `json:"conversionRate"`
+}
+
+// CouponFileStats is the usage of the promo codes listed in a coupon file.
+// A code listed in several files counts towards each of them.
+type CouponFileStats struct {
+	FileName      string  `json:"fileName"`
+	RedeemedCodes int     `json:"redeemedCodes"`
+	Redemptions   int     `json:"redemptions"`
+	DiscountTotal float64 `json:"discountTotal"`
+	// ConversionRate is the share of all orders in the range that used a code from the file
+	ConversionRate float64 `json:"conversionRate"`
+}
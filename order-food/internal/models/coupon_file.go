@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Coupon file upload statuses
+const (
+	CouponFileStatusReceiving  = "receiving"
+	CouponFileStatusPending    = "pending"
+	CouponFileStatusProcessing = "processing"
+	CouponFileStatusLoaded     = "loaded"
+	CouponFileStatusFailed     = "failed"
+)
+
+// CouponFileUpload is a coupon file uploaded through the admin API and the
+// progress of loading it
+type CouponFileUpload struct {
+	ID            string    `json:"id" example:"5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c"`
+	FileName      string    `json:"fileName" example:"couponbase4.txt"`
+	SizeBytes     int64     `json:"sizeBytes"`
+	Status        string    `json:"status" example:"pending"`
+	CouponsLoaded int64     `json:"couponsLoaded"`
+	Error         string    `json:"error,omitempty"`
+	UploadedBy    string    `json:"uploadedBy"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	// OperationID follows the upload through the loader, see /admin/operations
+	OperationID string `json:"operationId,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+}
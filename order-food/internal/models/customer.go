@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Customer is a customer account
+type Customer struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CustomerReq represents a customer registration request
+type CustomerReq struct {
+	Email    string `json:"email" binding:"required,email,max=254"`
+	Name     string `json:"name,omitempty" binding:"max=100"`
+	Password string `json:"password" binding:"required,min=8,max=128"`
+}
+
+// LoginReq represents a customer login request
+type LoginReq struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// AccessToken is a token a customer authenticates with, sent as
+// "Authorization: Bearer <token>"
+type AccessToken struct {
+	Token     string    `json:"accessToken"`
+	TokenType string    `json:"tokenType" example:"Bearer"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Customer  Customer  `json:"customer"`
+}
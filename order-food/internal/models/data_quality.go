@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Data quality checks, named as in reports
+const (
+	// DataQualityOrphanedOrderItems finds order items whose order or product
+	// row is missing
+	DataQualityOrphanedOrderItems = "orphanedOrderItems"
+	// DataQualityZeroPriceProducts finds products on the menu priced at zero
+	DataQualityZeroPriceProducts = "zeroPriceProducts"
+	// DataQualitySingleFileCoupons finds coupon codes found in only one
+	// coupon file, which are never valid
+	DataQualitySingleFileCoupons = "singleFileCoupons"
+	// DataQualityLoadManifestGaps finds uploaded coupon files recorded as
+	// loaded whose coupons are missing from the coupons table
+	DataQualityLoadManifestGaps = "loadManifestGaps"
+)
+
+// Data quality report statuses
+const (
+	// DataQualityClean is reported when every check ran and found nothing
+	DataQualityClean = "clean"
+	// DataQualityIssues is reported when a check found rows or failed
+	DataQualityIssues = "issues"
+	// DataQualityUnchecked is reported when a check has not run yet and
+	// none found anything
+	DataQualityUnchecked = "unchecked"
+)
+
+// DataQualityFinding is the latest result of a data quality check
+type DataQualityFinding struct {
+	Check       string `json:"check" example:"zeroPriceProducts"`
+	Description string `json:"description" example:"Products on the menu priced at zero"`
+	// Count is how many rows the check found
+	Count int64 `json:"count" example:"2"`
+	// Samples identifies up to 10 of the rows found
+	Samples []string `json:"samples" example:"11,12"`
+	// Error is why the latest run failed; Count and Samples are then those
+	// of the run before
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs" example:"42"`
+	// CheckedAt is when the check last ran; it is left out until it has
+	CheckedAt *time.Time `json:"checkedAt,omitempty"`
+}
+
+// DataQualityReport is the latest result of every data quality check
+type DataQualityReport struct {
+	Status string               `json:"status" enums:"clean,issues,unchecked"`
+	Checks []DataQualityFinding `json:"checks"`
+}
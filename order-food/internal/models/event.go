@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Types of the domain events published to the event broker
+const (
+	// EventTypeOrderCreated is published with an OrderCreated when an order
+	// is placed or imported from the POS
+	EventTypeOrderCreated = "OrderCreated"
+	// EventTypeOrderStatusChanged is published with an OrderStatusChanged
+	// when an order moves to another status
+	EventTypeOrderStatusChanged = "OrderStatusChanged"
+	// EventTypeProductUpdated is published with a ProductUpdated when a
+	// product is created, changed, repriced or deleted
+	EventTypeProductUpdated = "ProductUpdated"
+)
+
+// Changes a ProductUpdated describes
+const (
+	ProductChangeCreated = "created"
+	ProductChangeUpdated = "updated"
+	ProductChangeDeleted = "deleted"
+)
+
+// Event is a domain event as it is sent to the broker. Key identifies the
+// order or product it is about, so events about one of them keep their
+// order on a partitioned topic.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	Key        string    `json:"key"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Data       any       `json:"data"`
+}
+
+// OrderCreated is the data of an OrderCreated event
+type OrderCreated struct {
+	ID         string             `json:"id"`
+	Status     OrderStatus        `json:"status"`
+	CustomerID string             `json:"customerId,omitempty"`
+	CouponCode string             `json:"couponCode,omitempty"`
+	Items      []OrderCreatedItem `json:"items"`
+	Subtotal   float64            `json:"subtotal"`
+	Discount   float64            `json:"discount"`
+	Total      float64            `json:"total"`
+}
+
+// OrderCreatedItem is an item of an OrderCreated, with the product as it
+// was priced
+type OrderCreatedItem struct {
+	ProductID string  `json:"productId"`
+	Name      string  `json:"name"`
+	Category  string  `json:"category"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unitPrice"`
+	Discount  float64 `json:"discount"`
+}
+
+// OrderStatusChanged is the data of an OrderStatusChanged event
+type OrderStatusChanged struct {
+	ID   string      `json:"id"`
+	From OrderStatus `json:"from"`
+	To   OrderStatus `json:"to"`
+}
+
+// ProductUpdated is the data of a ProductUpdated event. Deleted products
+// only carry their ID.
+type ProductUpdated struct {
+	ID       string  `json:"id"`
+	Change   string  `json:"change"`
+	Name     string  `json:"name,omitempty"`
+	Price    float64 `json:"price,omitempty"`
+	Category string  `json:"category,omitempty"`
+	SKU      string  `json:"sku,omitempty"`
+	Barcode  string  `json:"barcode,omitempty"`
+}
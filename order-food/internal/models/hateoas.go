@@ -21,10 +21,20 @@ type PaginationMeta struct {
 	TotalItems int `json:"totalItems"`
 }
 
-// PaginatedResponse wraps paginated data with HATEOAS links
+// CursorMeta contains cursor pagination metadata. NextCursor is the after
+// value of the next page and is left out on the last page.
+type CursorMeta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// PaginatedResponse wraps paginated data with HATEOAS links. Pages
+// requested by number carry Pagination; pages requested with a cursor carry
+// Cursor instead, as their total is not counted.
 type PaginatedResponse struct {
 	Data       interface{}    `json:"data"`
-	Pagination PaginationMeta `json:"pagination"`
+	Pagination PaginationMeta `json:"pagination,omitzero"`
+	Cursor     CursorMeta     `json:"cursor,omitzero"`
 	Links      []Link         `json:"_links"`
 }
 
@@ -39,3 +49,10 @@ type OrderWithLinks struct {
 	Order
 	Links []Link `json:"_links"`
 }
+
+// RouteCapability is a path of the API with the methods it allows
+type RouteCapability struct {
+	// Path is a URI template, such as /api/v1/orders/{orderId}
+	Path    string   `json:"path" example:"/api/v1/orders/{orderId}"`
+	Methods []string `json:"methods" example:"GET,OPTIONS"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// IdempotencyRecord is what is stored for a used Idempotency-Key
+type IdempotencyRecord struct {
+	// RequestHash fingerprints the request the key was first used for
+	RequestHash string
+	// StatusCode is zero while the first request with the key is in flight
+	StatusCode int
+	// Body is the response sent to the first request
+	Body []byte
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// InvalidationTopicProducts is the outbox topic for product catalogue changes
+const InvalidationTopicProducts = "products"
+
+// InvalidationTopicCoupons is the outbox topic for changes to the set of
+// valid promo codes
+const InvalidationTopicCoupons = "coupons"
+
+// InvalidationKeyCampaignPrefix starts the coupons topic key announcing a
+// new campaign; the campaign ID follows it
+const InvalidationKeyCampaignPrefix = "campaign/"
+
+// CacheInvalidation is an outbox event telling every replica to drop cached
+// entries for Key on Topic; a Key of "*" drops the whole topic
+type CacheInvalidation struct {
+	ID        int64
+	Topic     string
+	Key       string
+	CreatedAt time.Time
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Maintenance job kinds
+const (
+	// MaintenanceJobReindex rebuilds the product search index, refreshes
+	// the materialized views and re-warms the caches
+	MaintenanceJobReindex = "reindex"
+)
+
+// Maintenance job statuses
+const (
+	MaintenanceJobRunning   = "running"
+	MaintenanceJobSucceeded = "succeeded"
+	MaintenanceJobFailed    = "failed"
+)
+
+// Maintenance step statuses
+const (
+	MaintenanceStepPending = "pending"
+	MaintenanceStepRunning = "running"
+	MaintenanceStepDone    = "done"
+	MaintenanceStepFailed  = "failed"
+)
+
+// MaintenanceStep is the progress of one step of a maintenance job
+type MaintenanceStep struct {
+	Name       string  `json:"name" example:"searchIndex"`
+	Status     string  `json:"status" example:"done"`
+	DurationMs float64 `json:"durationMs,omitempty" example:"1520.5"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// MaintenanceJob is a maintenance job started through the admin API and
+// how far it has got
+type MaintenanceJob struct {
+	ID     string `json:"id" example:"5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c"`
+	Kind   string `json:"kind" example:"reindex"`
+	Status string `json:"status" example:"running"`
+	// Progress is the percentage of steps finished
+	Progress int               `json:"progress" example:"33"`
+	Steps    []MaintenanceStep `json:"steps"`
+	// Error is why the job failed
+	Error       string `json:"error,omitempty"`
+	RequestedBy string `json:"requestedBy"`
+	// InstanceID is the replica running the job
+	InstanceID string     `json:"instanceId"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	// OperationID follows the job in logs and traces, see /admin/operations
+	OperationID string `json:"operationId,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+}
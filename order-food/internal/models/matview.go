@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// MatviewRefresh is the latest refresh of a materialized view anywhere in
+// the cluster
+type MatviewRefresh struct {
+	View       string
+	InstanceID string
+	StartedAt  time.Time
+	// FinishedAt is nil while the refresh runs
+	FinishedAt *time.Time
+	// RefreshedAt is when the latest successful refresh started, which is
+	// how current the view's data is; nil before the first one
+	RefreshedAt *time.Time
+	// LastDuration is how long the latest successful refresh took
+	LastDuration time.Duration
+	// Error is why the latest refresh failed; empty when it succeeded
+	Error string
+}
+
+// MatviewStatus describes how current a materialized view is and how far a
+// refresh under way has got
+type MatviewStatus struct {
+	View string `json:"view" example:"valid_coupons"`
+	// Task is the scheduled task refreshing the view
+	Task       string `json:"task" example:"valid-coupons-refresher"`
+	Refreshing bool   `json:"refreshing"`
+	// InstanceID is the replica running, or that ran, the latest refresh
+	InstanceID string     `json:"instanceId,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	// Progress estimates how far a refresh under way is in percent, from
+	// how long the previous one took; left out when there is none
+	Progress    *int       `json:"progress,omitempty" example:"40"`
+	RefreshedAt *time.Time `json:"refreshedAt,omitempty"`
+	// StalenessSeconds is how old the view's data is; left out before the
+	// first refresh
+	StalenessSeconds *float64 `json:"stalenessSeconds,omitempty" example:"120.5"`
+	// LastDuration is how long the latest successful refresh took, as a Go
+	// duration
+	LastDuration string `json:"lastDuration,omitempty" example:"42s"`
+	// Error is why the latest refresh failed
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// Channels the database sends notifications on when changes commit
+const (
+	// NotifyChannelCacheInvalidations is notified when cache invalidation
+	// events are published; the payload is empty
+	NotifyChannelCacheInvalidations = "cache_invalidations"
+	// NotifyChannelOrderChanges is notified when an order is placed or its
+	// status changes, with an OrderChange as payload
+	NotifyChannelOrderChanges = "order_changes"
+)
+
+// OrderChange is an order placed or moved to another status
+type OrderChange struct {
+	ID     string      `json:"id"`
+	Status OrderStatus `json:"status"`
+}
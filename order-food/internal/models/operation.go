@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Pipeline run statuses, as recorded by the migration and load jobs
+const (
+	PipelineRunStatusRunning   = "running"
+	PipelineRunStatusSucceeded = "succeeded"
+	PipelineRunStatusFailed    = "failed"
+)
+
+// PipelineRun is one run of the database-migration or database-load job
+type PipelineRun struct {
+	ID int64 `json:"id"`
+	// OperationID is shared by every job of the pipeline the run belonged to
+	OperationID string `json:"operationId" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+	Job         string `json:"job" example:"database-load"`
+	// InstanceID names the process that ran, as in its log prefix
+	InstanceID string    `json:"instanceId"`
+	Status     string    `json:"status" example:"succeeded"`
+	Summary    string    `json:"summary,omitempty" example:"120 products, 3000000 coupons"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	// FinishedAt is nil while the run is going, or when it was killed
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Operation gathers everything recorded under one operation ID. The ID is
+// also the trace ID, so it finds the operation in the tracing backend.
+type Operation struct {
+	ID          string             `json:"id" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+	Runs        []PipelineRun      `json:"runs"`
+	CouponFiles []CouponFileUpload `json:"couponFiles"`
+}
@@ -1,21 +1,187 @@
 package models
 
+import "time"
+
 // OrderItem represents an item in an order
 type OrderItem struct {
 	ProductID string `json:"productId" binding:"required"`
 	Quantity  int    `json:"quantity" binding:"required,min=1"`
+	// ExpectedUnitPrice is the price the customer saw, including the
+	// modifiers; the order is refused when the current price differs. Only
+	// read on requests.
+	ExpectedUnitPrice *float64 `json:"expectedUnitPrice,omitempty" binding:"omitempty,gte=0"`
+	// Modifiers are the options selected from the product's option groups.
+	// They apply to every unit of the item.
+	Modifiers []ItemModifier `json:"modifiers,omitempty" binding:"omitempty,max=20,dive"`
+	// Discount is the part of the order discount taken off this item.
+	// Only set on responses.
+	Discount float64 `json:"discount,omitempty"`
+}
+
+// ItemModifier is an option selected for an order item, such as its size
+// or a topping
+type ItemModifier struct {
+	OptionID string `json:"optionId" binding:"required,max=50" example:"large"`
+	// Group, Name and Price describe the option as it was priced; its price
+	// is included in the unit price of the item. Only set on responses.
+	Group string  `json:"group,omitempty" example:"Size"`
+	Name  string  `json:"name,omitempty" example:"Large"`
+	Price float64 `json:"price,omitempty" example:"2.5"`
+}
+
+// PriceMismatch is an item whose expected unit price is no longer current
+type PriceMismatch struct {
+	ProductID         string  `json:"productId"`
+	ExpectedUnitPrice float64 `json:"expectedUnitPrice"`
+	UnitPrice         float64 `json:"unitPrice"`
+}
+
+// PriceMismatchResponse is the error API response for an order whose
+// expected prices are out of date; it carries the current prices
+type PriceMismatchResponse struct {
+	APIResponse
+	Prices []PriceMismatch `json:"prices"`
+}
+
+// PaymentDeclinedResponse is the error API response for an order whose
+// payment method was declined; the order is not placed
+type PaymentDeclinedResponse struct {
+	APIResponse
+	// DeclineCode is the provider's reason, such as insufficient_funds
+	DeclineCode string `json:"declineCode" example:"card_declined"`
 }
 
 // OrderReq represents a request to create a new order
 type OrderReq struct {
 	CouponCode string      `json:"couponCode,omitempty"`
 	Items      []OrderItem `json:"items" binding:"required,min=1,dive"`
+	// ReservationID converts a stock reservation made for this checkout
+	ReservationID string `json:"reservationId,omitempty"`
+	// CustomerID identifies the customer in the calling system; promo
+	// codes limited to one use per customer require it. Orders placed by a
+	// signed-in customer count against their account instead.
+	CustomerID string `json:"customerId,omitempty" binding:"max=64"`
+	// Delivery is where and to whom the order is delivered; orders
+	// collected in store have none
+	Delivery *Delivery `json:"delivery,omitempty"`
+	// PaymentMethod is the customer's card or wallet at the payment
+	// provider, such as pm_card_visa with the mock provider; orders are
+	// only charged when a provider is configured
+	PaymentMethod string `json:"paymentMethod,omitempty" binding:"max=255" example:"pm_card_visa"`
+	// AccountID is the customer account placing the order, taken from its
+	// access token; it is never read from requests
+	AccountID string `json:"-"`
+}
+
+// OrderFilter narrows an order listing; zero fields do not filter
+type OrderFilter struct {
+	// CustomerID matches the customer account that placed the order
+	CustomerID string
+	// Status matches the current status of the order
+	Status OrderStatus
+	// From and To match orders placed in [From, To)
+	From, To time.Time
+	// CouponCode matches the promo code applied to the order
+	CouponCode string
+	// ProductID matches orders with an item of the product
+	ProductID string
+}
+
+// OrderStatus is the stage of an order in the kitchen lifecycle
+type OrderStatus string
+
+// Order statuses. Orders start pending and move forward one stage at a
+// time until completed; they can be cancelled until they are completed.
+const (
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusConfirmed OrderStatus = "confirmed"
+	OrderStatusPreparing OrderStatus = "preparing"
+	OrderStatusCompleted OrderStatus = "completed"
+	OrderStatusCancelled OrderStatus = "cancelled"
+)
+
+// OrderStatusReq represents a request to move an order to another status
+type OrderStatusReq struct {
+	Status OrderStatus `json:"status" binding:"required" enums:"pending,confirmed,preparing,completed,cancelled"`
 }
 
 // Order represents a completed order
 type Order struct {
 	ID         string      `json:"id"`
 	CouponCode string      `json:"couponCode,omitempty"`
-	Items      []OrderItem `json:"items"`
-	Products   []Product   `json:"products"`
+	Status     OrderStatus `json:"status"`
+	// CustomerID is the customer account that placed the order; orders
+	// placed with an API key have none
+	CustomerID string `json:"customerId,omitempty"`
+	// Items and Products are left out of responses for orders with more
+	// items than are returned inline; ItemCount and an items link are
+	// given instead
+	Items     []OrderItem `json:"items,omitempty"`
+	Products  []Product   `json:"products,omitempty"`
+	ItemCount int         `json:"itemCount,omitempty"`
+	// Subtotal is the sum of the items before the promo code discount
+	Subtotal float64 `json:"subtotal"`
+	// Discount is the amount the promo code took off the subtotal; the
+	// items it was taken off carry their share
+	Discount float64 `json:"discount"`
+	// Total is the amount payable, the subtotal minus the discount
+	Total float64 `json:"total"`
+	// Archived is set when the order was served from cold storage
+	Archived bool `json:"archived,omitempty"`
+	// Delivery is only returned for a single order
+	Delivery *Delivery `json:"delivery,omitempty"`
+	// Payment is only returned for a single order, and only for orders
+	// charged through a payment provider
+	Payment *Payment `json:"payment,omitempty"`
+}
+
+// Payment is the charge of an order at a payment provider
+type Payment struct {
+	Provider string `json:"provider" example:"mock"`
+	// IntentID is the payment intent at the provider
+	IntentID string `json:"intentId" example:"pi_3b5f0c2a9d7e4e1f8a6b2c4d6e8f0a1b"`
+	// Status is the status of the intent when the order was stored
+	Status string `json:"status" example:"succeeded"`
+	// Amount is in the smallest unit of Currency, e.g. cents
+	Amount   int64  `json:"amount" example:"1170"`
+	Currency string `json:"currency" example:"usd"`
+}
+
+// Delivery is the delivery address and contact of an order
+type Delivery struct {
+	Address DeliveryAddress `json:"address"`
+	Contact DeliveryContact `json:"contact"`
+	// Instructions for the courier, such as a door code
+	Instructions string `json:"instructions,omitempty" binding:"max=500"`
+}
+
+// DeliveryAddress is a postal address. Country is an upper-case ISO 3166-1
+// alpha-2 code.
+type DeliveryAddress struct {
+	Line1      string `json:"line1" binding:"notblank,max=200" example:"1 Market Street"`
+	Line2      string `json:"line2,omitempty" binding:"max=200"`
+	City       string `json:"city" binding:"notblank,max=100" example:"Sydney"`
+	Region     string `json:"region,omitempty" binding:"max=100" example:"NSW"`
+	PostalCode string `json:"postalCode,omitempty" binding:"omitempty,postal_code" example:"2000"`
+	Country    string `json:"country" binding:"required,iso3166_1_alpha2" example:"AU"`
+}
+
+// DeliveryContact is who receives a delivery
+type DeliveryContact struct {
+	Name  string `json:"name" binding:"notblank,max=100" example:"Jane Citizen"`
+	Phone string `json:"phone" binding:"required,phone" example:"+61 2 9999 0000"`
+	Email string `json:"email,omitempty" binding:"omitempty,email,max=254"`
+}
+
+// OrderLine is an item of an order with the product as it was priced
+type OrderLine struct {
+	OrderItem
+	Product Product `json:"product"`
+}
+
+// ArchivedOrder is an order as written to cold storage by the archiver.
+// The personal data of its Delivery is sealed as in the database.
+type ArchivedOrder struct {
+	Order
+	CreatedAt time.Time `json:"createdAt"`
 }
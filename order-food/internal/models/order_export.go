@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Order export statuses
+const (
+	OrderExportStatusExported = "exported"
+	OrderExportStatusFailed   = "failed"
+)
+
+// ExportedOrder is an order as written to the accounting export
+type ExportedOrder struct {
+	Order
+	PlacedAt time.Time
+	// Units is the number of items ordered, summed over every line
+	Units int
+	// CancelledAt is when the order was cancelled and refunded; nil for
+	// orders that were not
+	CancelledAt *time.Time
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Order volume statuses
+const (
+	OrderVolumeNormal = "normal"
+	// OrderVolumeDrop is reported when far fewer orders arrive than usual
+	OrderVolumeDrop = "drop"
+	// OrderVolumeQuiet is reported when too few orders are expected at this
+	// time of day to tell a drop from chance
+	OrderVolumeQuiet = "quiet"
+)
+
+// OrderVolume compares the orders placed in the latest window with the
+// same window on previous days
+type OrderVolume struct {
+	Status string `json:"status" enums:"normal,drop,quiet"`
+	// Window is the length of the compared window, as a Go duration
+	Window            string  `json:"window" example:"15m0s"`
+	CurrentOrders     int     `json:"currentOrders" example:"12"`
+	ExpectedOrders    float64 `json:"expectedOrders" example:"48"`
+	CurrentPerMinute  float64 `json:"currentPerMinute" example:"0.8"`
+	ExpectedPerMinute float64 `json:"expectedPerMinute" example:"3.2"`
+	// BaselineDays is how many previous days the expectation is taken from
+	BaselineDays int       `json:"baselineDays" example:"7"`
+	CheckedAt    time.Time `json:"checkedAt"`
+	// DropSince is when the current drop was first detected
+	DropSince *time.Time `json:"dropSince,omitempty"`
+}
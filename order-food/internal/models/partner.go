@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Partner approval states
+const (
+	PartnerStatusPending   = "pending"
+	PartnerStatusApproved  = "approved"
+	PartnerStatusRejected  = "rejected"
+	PartnerStatusSuspended = "suspended"
+)
+
+// Partner represents an API partner onboarded through self-registration
+type Partner struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	ContactEmail string    `json:"contactEmail"`
+	Status       string    `json:"status"`
+	Scopes       []string  `json:"scopes"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// PartnerReq represents a partner self-registration request
+type PartnerReq struct {
+	Name         string   `json:"name" binding:"required,max=255"`
+	ContactEmail string   `json:"contactEmail" binding:"required,email,max=255"`
+	Scopes       []string `json:"scopes" binding:"required,min=1,dive,required"`
+}
+
+// IssuedAPIKey is a newly issued partner API key. The key itself is only
+// ever returned in the response that issues it.
+type IssuedAPIKey struct {
+	Key       string    `json:"apiKey"`
+	Prefix    string    `json:"prefix"`
+	PartnerID string    `json:"partnerId"`
+	CreatedAt time.Time `json:"createdAt"`
+	// PreviousKeysExpireAt is set on rotation to when the replaced keys stop working
+	PreviousKeysExpireAt *time.Time `json:"previousKeysExpireAt,omitempty"`
+}
+
+// PartnerRegistration is the response to a successful self-registration
+type PartnerRegistration struct {
+	Partner Partner      `json:"partner"`
+	APIKey  IssuedAPIKey `json:"credentials"`
+}
@@ -0,0 +1,42 @@
+package models
+
+// PriceRule changes the price of the products it selects, either by a
+// percentage or by a fixed amount. Products are selected by category, by
+// ID, or both.
+type PriceRule struct {
+	// Category selects every product in the category
+	Category string `json:"category,omitempty" example:"Waffle"`
+	// ProductIDs selects individual products
+	ProductIDs []string `json:"productIds,omitempty"`
+	// Percent changes the price by a percentage, e.g. 5 for +5% or -10 for -10%
+	Percent float64 `json:"percent,omitempty" example:"5"`
+	// Amount changes the price by a fixed amount in dollars
+	Amount float64 `json:"amount,omitempty"`
+}
+
+// BulkPriceReq is a bulk price update. Rules are applied in order, so a
+// product selected by two rules gets both changes. Without Apply the
+// changes are only previewed.
+type BulkPriceReq struct {
+	Rules  []PriceRule `json:"rules" binding:"required,min=1,max=50"`
+	Reason string      `json:"reason,omitempty" binding:"max=500" example:"Supplier price increase"`
+	Apply  bool        `json:"apply"`
+}
+
+// PriceChange is the change of one product's price in a bulk update
+type PriceChange struct {
+	ProductID string  `json:"productId"`
+	Name      string  `json:"name"`
+	Category  string  `json:"category"`
+	OldPrice  float64 `json:"oldPrice"`
+	NewPrice  float64 `json:"newPrice"`
+}
+
+// BulkPriceResult lists the changes of a bulk price update and whether
+// they were applied
+type BulkPriceResult struct {
+	Applied bool          `json:"applied"`
+	Changes []PriceChange `json:"changes"`
+	// AuditID identifies the audit log entry of an applied update
+	AuditID int64 `json:"auditId,omitempty"`
+}
@@ -6,4 +6,80 @@ type Product struct {
 	Name     string  `json:"name" binding:"required"`
 	Price    float64 `json:"price" binding:"required"`
 	Category string  `json:"category" binding:"required"`
+	SKU      string  `json:"sku,omitempty"`
+	Barcode  string  `json:"barcode,omitempty"`
+	// Description is in Language, like Name
+	Description string `json:"description,omitempty"`
+	// Language is the language tag of Name and Description. It is only set
+	// on product responses, which are localized from Translations.
+	Language string `json:"language,omitempty"`
+	// Translations holds the localized names and descriptions keyed by
+	// lower-case language tag; it is not part of responses
+	Translations map[string]ProductTranslation `json:"-"`
+	// Prices holds the price in other currencies keyed by ISO 4217 code
+	Prices map[string]float64 `json:"prices,omitempty"`
+	// TaxRate is the tax rate applied to an ordered product, as a fraction.
+	// It is only set on the products of an order.
+	TaxRate float64 `json:"taxRate,omitempty"`
+	// OptionGroups are the choices offered with the product, such as its
+	// size or toppings. They are not set on the products of an order,
+	// whose Price includes the modifiers selected for its item instead.
+	OptionGroups []OptionGroup `json:"optionGroups,omitempty"`
+}
+
+// OptionGroup is a choice offered with a product, such as its size or
+// toppings. Order items select between MinSelect and MaxSelect of its
+// options; a group with a MinSelect of 0 is optional.
+type OptionGroup struct {
+	ID        string          `json:"id" binding:"required,max=50" example:"size"`
+	Name      string          `json:"name" binding:"required,max=100" example:"Size"`
+	MinSelect int             `json:"minSelect" binding:"gte=0" example:"1"`
+	MaxSelect int             `json:"maxSelect" binding:"gte=1" example:"1"`
+	Options   []ProductOption `json:"options" binding:"required,min=1,max=50,dive"`
+}
+
+// ProductOption is an option of an option group. Option IDs are unique per
+// product.
+type ProductOption struct {
+	ID   string `json:"id" binding:"required,max=50" example:"large"`
+	Name string `json:"name" binding:"required,max=100" example:"Large"`
+	// Price is added to the unit price of the product when the option is
+	// selected
+	Price float64 `json:"price" binding:"gte=0" example:"2.5"`
+}
+
+// OptionGroupsReq replaces the option groups of a product; an empty list
+// removes them
+type OptionGroupsReq struct {
+	OptionGroups []OptionGroup `json:"optionGroups" binding:"max=20,dive"`
+}
+
+// ProductFilter narrows a product listing; zero fields do not filter
+type ProductFilter struct {
+	// Category matches the name of the product category exactly
+	Category string
+	// CategoryID matches the ID of the product category
+	CategoryID string
+	// MinPrice and MaxPrice bound the price in dollars, inclusive
+	MinPrice *float64
+	MaxPrice *float64
+}
+
+// ProductTranslation is the name and description of a product in one
+// language. An empty Description falls back to the default one.
+type ProductTranslation struct {
+	Name        string
+	Description string
+}
+
+// ProductReq is a request to create or replace a product. The ID is taken
+// from the path when a product is replaced.
+type ProductReq struct {
+	ID          string   `json:"id,omitempty" example:"11"`
+	Name        string   `json:"name" binding:"required,max=255" example:"Chicken Waffle"`
+	Price       *float64 `json:"price" binding:"required,gte=0" example:"12.99"`
+	Category    string   `json:"category" binding:"required,max=100" example:"Waffle"`
+	SKU         string   `json:"sku,omitempty" binding:"max=64" example:"WAF-CHK-01"`
+	Barcode     string   `json:"barcode,omitempty" binding:"max=32" example:"4006381333931"`
+	Description string   `json:"description,omitempty" example:"Crispy fried chicken on a Belgian waffle"`
 }
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// DiscountType is how a promo code discount is worked out
+type DiscountType string
+
+// Discount types. A percentage discount takes Value percent off the order
+// subtotal; a fixed discount takes Value dollars off, never more than the
+// subtotal.
+const (
+	DiscountTypePercentage DiscountType = "percentage"
+	DiscountTypeFixed      DiscountType = "fixed"
+)
+
+// Discount is what a promo code takes off an order
+type Discount struct {
+	Type  DiscountType `json:"type" binding:"required,oneof=percentage fixed" enums:"percentage,fixed" example:"percentage"`
+	Value float64      `json:"value" binding:"required,gt=0" example:"10"`
+	// Categories restricts the discount to items of these product
+	// categories; with ProductIDs, an item matching either is discounted
+	Categories []string `json:"categories,omitempty" example:"Waffle"`
+	// ProductIDs restricts the discount to these products. A discount with
+	// neither Categories nor ProductIDs applies to the whole order.
+	ProductIDs []string `json:"productIds,omitempty" example:"1"`
+}
+
+// Scoped reports whether the discount is restricted to some categories or
+// products
+func (d *Discount) Scoped() bool {
+	return d != nil && (len(d.Categories) > 0 || len(d.ProductIDs) > 0)
+}
+
+// AppliesTo reports whether the discount applies to product. An unscoped
+// discount applies to every product; a nil discount to none.
+func (d *Discount) AppliesTo(product Product) bool {
+	if d == nil {
+		return false
+	}
+	if !d.Scoped() {
+		return true
+	}
+	for _, category := range d.Categories {
+		if category == product.Category {
+			return true
+		}
+	}
+	for _, id := range d.ProductIDs {
+		if id == product.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// Amount returns the discount on subtotal in dollars, rounded to cents.
+// For a scoped discount, subtotal is that of the items it applies to.
+// A nil discount is worth nothing.
+func (d *Discount) Amount(subtotal float64) float64 {
+	if d == nil {
+		return 0
+	}
+	var amount float64
+	switch d.Type {
+	case DiscountTypePercentage:
+		amount = subtotal * min(d.Value, 100) / 100
+	case DiscountTypeFixed:
+		amount = min(d.Value, subtotal)
+	}
+	return math.Round(amount*100) / 100
+}
+
+// PromoCodeLimits restricts how often a promo code can be used
+type PromoCodeLimits struct {
+	// MaxRedemptions is how many orders can use the code in total; 0 for
+	// no limit
+	MaxRedemptions int `json:"maxRedemptions" binding:"gte=0" example:"100"`
+	// OncePerCustomer lets each customerId use the code only once; orders
+	// without a customerId cannot use it
+	OncePerCustomer bool `json:"oncePerCustomer" example:"false"`
+	// Redemptions is how many orders have used the code. Only set on
+	// responses.
+	Redemptions int `json:"redemptions" example:"42"`
+}
+
+// PromoCode is a valid promo code and the discount it gives, if any
+type PromoCode struct {
+	Code     string           `json:"code" example:"HAPPYHRS"`
+	Discount *Discount        `json:"discount,omitempty"`
+	Limits   *PromoCodeLimits `json:"limits,omitempty"`
+}
+
+// PromoCodePolicy is the rules a promo code must pass to be valid, which
+// vary between the regions the service is deployed in
+type PromoCodePolicy struct {
+	// Name is the region or environment the policy was configured for, or
+	// "default"
+	Name string `json:"name" example:"default"`
+	// MinLength and MaxLength bound the length of a code in bytes
+	MinLength int `json:"minLength" example:"8"`
+	MaxLength int `json:"maxLength" example:"10"`
+	// MinFiles is how many coupon files a code must appear in
+	MinFiles int `json:"minFiles" example:"2"`
+}
+
+// Rules describes the policy to callers whose code was rejected
+func (p PromoCodePolicy) Rules() string {
+	files := "file"
+	if p.MinFiles != 1 {
+		files = "files"
+	}
+	return fmt.Sprintf("Code must be %d-%d characters and exist in at least %d %s.", p.MinLength, p.MaxLength, p.MinFiles, files)
+}
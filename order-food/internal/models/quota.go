@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Quota is a caller's rate limit state in the current window
+type Quota struct {
+	// Limit is the number of requests allowed per window
+	Limit int `json:"limit" example:"600"`
+	// Remaining is the number of requests left in the current window
+	Remaining int `json:"remaining" example:"599"`
+	// Window is the length of a window in seconds
+	Window int `json:"window" example:"60"`
+	// ResetAt is when the current window ends
+	ResetAt time.Time `json:"resetAt"`
+}
+
+// APIRoot is the discovery document served at the API root. Its links
+// name every resource the caller can reach.
+type APIRoot struct {
+	Version string `json:"version" example:"v1"`
+	// Principal is the authenticated caller; empty for anonymous callers
+	Principal string `json:"principal,omitempty"`
+	// Quota is the caller's rate limit state; nil for anonymous callers or
+	// when rate limiting is disabled
+	Quota *Quota `json:"quota,omitempty"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ReservationReq represents a request to hold stock for a checkout
+type ReservationReq struct {
+	Items []OrderItem `json:"items" binding:"required,min=1,dive"`
+}
+
+// Reservation is stock held for a checkout. Placing an order with its ID
+// turns the hold into a stock decrement; otherwise it lapses at ExpiresAt.
+type Reservation struct {
+	ID        string      `json:"id"`
+	Items     []OrderItem `json:"items"`
+	ExpiresAt time.Time   `json:"expiresAt"`
+}
+
+// StockShortage describes an item that asks for more than is available
+type StockShortage struct {
+	ProductID string `json:"productId"`
+	Requested int    `json:"requested"`
+	Available int    `json:"available"`
+}
@@ -1,6 +1,7 @@
 package models
 
-// APIResponse represents a standard API response
+// APIResponse represents a standard API response. Error responses served
+// by the router also carry the requestId of the request.
 type APIResponse struct {
 	Code    int    `json:"code"`
 	Type    string `json:"type"`
@@ -24,3 +25,23 @@ func SuccessResponse(code int, message string) APIResponse {
 		Message: message,
 	}
 }
+
+// FieldError describes a validation problem with a single request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is an error API response carrying per-field diagnostics
+type ValidationErrorResponse struct {
+	APIResponse
+	Errors []FieldError `json:"errors"`
+}
+
+// ValidationError creates an error API response with per-field diagnostics
+func ValidationError(code int, message string, errs []FieldError) ValidationErrorResponse {
+	return ValidationErrorResponse{
+		APIResponse: ErrorResponse(code, message),
+		Errors:      errs,
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Scheduled task run statuses
+const (
+	TaskRunSucceeded = "succeeded"
+	TaskRunFailed    = "failed"
+)
+
+// TaskRun is a run of a scheduled task on one of the replicas
+type TaskRun struct {
+	Task       string    `json:"-"`
+	InstanceID string    `json:"instanceId"`
+	Status     string    `json:"status" enums:"succeeded,failed"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// ScheduledTask is a recurring task run by the elected replica. LastRun is
+// the latest run anywhere in the cluster; the other fields describe the
+// replica serving the request.
+type ScheduledTask struct {
+	Name string `json:"name" example:"stock-reservation-reaper"`
+	// Interval between runs, as a Go duration
+	Interval  string    `json:"interval" example:"1m0s"`
+	Running   bool      `json:"running"`
+	NextRunAt time.Time `json:"nextRunAt"`
+	LastRun   *TaskRun  `json:"lastRun,omitempty"`
+	Runs      int64     `json:"runs"`
+	Failures  int64     `json:"failures"`
+	Skipped   int64     `json:"skipped"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// SortField is a single term of a sort expression such as "-createdAt"
+type SortField struct {
+	Field string
+	Desc  bool
+}
@@ -0,0 +1,75 @@
+package models
+
+// ProductStock is the stock of a product as managed through the admin API
+type ProductStock struct {
+	ProductID string `json:"productId" example:"1"`
+	// Stock is the units on hand, including reserved units; null when the
+	// product does not track stock
+	Stock *int `json:"stock" example:"40"`
+	// Reserved is the units held by unexpired reservations
+	Reserved int `json:"reserved" example:"3"`
+	// Available is what orders can still take, Stock minus Reserved and
+	// never below zero; null when the product does not track stock
+	Available *int `json:"available" example:"37"`
+}
+
+// StockReq replaces the stock of a product, e.g. after a stock count. A
+// null stock stops tracking it, so it never runs out.
+type StockReq struct {
+	Stock  *int   `json:"stock" binding:"omitempty,gte=0" example:"40"`
+	Reason string `json:"reason,omitempty" binding:"max=500" example:"Weekly stock count"`
+}
+
+// StockAdjustmentReq changes the stock of a product by Delta units, e.g. 24
+// for a delivery or -2 for breakage
+type StockAdjustmentReq struct {
+	Delta  int    `json:"delta" binding:"required" example:"24"`
+	Reason string `json:"reason,omitempty" binding:"max=500" example:"Delivery"`
+}
+
+// StockChange describes a change to the stock of a product in the audit log
+type StockChange struct {
+	ProductID string `json:"productId"`
+	// Delta is the requested adjustment; zero when the stock was replaced
+	Delta    int    `json:"delta,omitempty"`
+	OldStock *int   `json:"oldStock"`
+	NewStock *int   `json:"newStock"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// AvailabilityReq asks whether the items of a cart can be ordered. The
+// items are checked, nothing is held.
+type AvailabilityReq struct {
+	Items []AvailabilityItem `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// AvailabilityItem is a product and the quantity a cart holds of it
+type AvailabilityItem struct {
+	ProductID string `json:"productId" binding:"required" example:"1"`
+	Quantity  int    `json:"quantity" binding:"required,min=1,max=1000" example:"2"`
+}
+
+// Item availability reasons
+const (
+	// AvailabilityNotFound is the reason for a product that does not exist
+	// or has been deleted
+	AvailabilityNotFound = "not_found"
+	// AvailabilityInsufficientStock is the reason for a quantity larger
+	// than the units orders can still take
+	AvailabilityInsufficientStock = "insufficient_stock"
+)
+
+// ItemAvailability reports whether an item can be ordered right now. It is
+// advisory: stock may be taken by other orders before checkout.
+type ItemAvailability struct {
+	ProductID string `json:"productId" example:"1"`
+	Quantity  int    `json:"quantity" example:"2"`
+	// Orderable is whether an order for the item would be accepted
+	Orderable bool `json:"orderable" example:"true"`
+	// Available is what orders can still take; null when the product does
+	// not track stock or does not exist
+	Available *int `json:"available" example:"37"`
+	// Reason is why the item cannot be ordered, not_found or
+	// insufficient_stock
+	Reason string `json:"reason,omitempty" example:"insufficient_stock"`
+}
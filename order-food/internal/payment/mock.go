@@ -0,0 +1,127 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Test payment methods of the mock provider, named like the test cards of
+// Stripe. Every other payment method starting with pm_ succeeds.
+const (
+	// MethodCardVisa is charged successfully
+	MethodCardVisa = "pm_card_visa"
+	// MethodCardDeclined is declined with card_declined
+	MethodCardDeclined = "pm_card_chargeDeclined"
+	// MethodCardInsufficientFunds is declined with insufficient_funds
+	MethodCardInsufficientFunds = "pm_card_chargeDeclinedInsufficientFunds"
+	// MethodCardExpired is declined with expired_card
+	MethodCardExpired = "pm_card_chargeDeclinedExpiredCard"
+)
+
+// mockDeclines are the declines of the mock provider's test payment methods
+var mockDeclines = map[string]DeclinedError{
+	MethodCardDeclined:          {Code: "card_declined", Message: "Your card was declined."},
+	MethodCardInsufficientFunds: {Code: "insufficient_funds", Message: "Your card has insufficient funds."},
+	MethodCardExpired:           {Code: "expired_card", Message: "Your card has expired."},
+}
+
+// Mock is an in-memory provider behaving like Stripe's payment intents in
+// test mode, for development and tests. Intents are kept in memory only,
+// so they are lost when the process restarts.
+type Mock struct {
+	mu      sync.Mutex
+	intents map[string]*Intent
+}
+
+// NewMock creates a mock provider
+func NewMock() *Mock {
+	return &Mock{intents: make(map[string]*Intent)}
+}
+
+// Name implements Provider
+func (m *Mock) Name() string {
+	return "mock"
+}
+
+// CreateIntent implements Provider. Intents without a payment method are
+// paid with MethodCardVisa.
+func (m *Mock) CreateIntent(ctx context.Context, req IntentReq) (Intent, error) {
+	if req.Amount <= 0 {
+		return Intent{}, fmt.Errorf("amount must be positive, got %d", req.Amount)
+	}
+	method := req.PaymentMethod
+	if method == "" {
+		method = MethodCardVisa
+	}
+	if !strings.HasPrefix(method, "pm_") {
+		return Intent{}, &DeclinedError{Code: "payment_method_invalid", Message: "The payment method is not valid."}
+	}
+
+	intent := &Intent{
+		ID:            "pi_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		OrderID:       req.OrderID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		PaymentMethod: method,
+		Status:        StatusRequiresConfirmation,
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.intents[intent.ID] = intent
+	return *intent, nil
+}
+
+// Confirm implements Provider
+func (m *Mock) Confirm(ctx context.Context, id string) (Intent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	intent, ok := m.intents[id]
+	if !ok {
+		return Intent{}, ErrIntentNotFound
+	}
+	if intent.Status != StatusRequiresConfirmation {
+		return *intent, fmt.Errorf("%w: intent is %s", ErrInvalidIntent, intent.Status)
+	}
+
+	if decline, ok := mockDeclines[intent.PaymentMethod]; ok {
+		intent.Status = StatusFailed
+		intent.FailureCode = decline.Code
+		return *intent, &decline
+	}
+	intent.Status = StatusSucceeded
+	return *intent, nil
+}
+
+// Cancel implements Provider
+func (m *Mock) Cancel(ctx context.Context, id string) (Intent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	intent, ok := m.intents[id]
+	if !ok {
+		return Intent{}, ErrIntentNotFound
+	}
+	switch intent.Status {
+	case StatusRequiresConfirmation, StatusFailed:
+		intent.Status = StatusCanceled
+	case StatusSucceeded:
+		intent.Status = StatusRefunded
+	default:
+		return *intent, fmt.Errorf("%w: intent is %s", ErrInvalidIntent, intent.Status)
+	}
+	return *intent, nil
+}
+
+// Intent returns an intent by ID, to check what happened to a payment
+func (m *Mock) Intent(id string) (Intent, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	intent, ok := m.intents[id]
+	if !ok {
+		return Intent{}, false
+	}
+	return *intent, true
+}
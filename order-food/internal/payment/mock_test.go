@@ -0,0 +1,88 @@
+package payment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMock_ConfirmAndRefund(t *testing.T) {
+	// Setup
+	mock := NewMock()
+	ctx := context.Background()
+	intent, err := mock.CreateIntent(ctx, IntentReq{OrderID: "order-1", Amount: 1300, Currency: "usd"})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRequiresConfirmation, intent.Status)
+	assert.Equal(t, MethodCardVisa, intent.PaymentMethod)
+
+	// Execute
+	confirmed, err := mock.Confirm(ctx, intent.ID)
+	assert.NoError(t, err)
+	_, err = mock.Confirm(ctx, intent.ID)
+	assert.ErrorIs(t, err, ErrInvalidIntent)
+	refunded, err := mock.Cancel(ctx, intent.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, confirmed.Status)
+	assert.Equal(t, StatusRefunded, refunded.Status)
+	_, err = mock.Cancel(ctx, intent.ID)
+	assert.ErrorIs(t, err, ErrInvalidIntent)
+}
+
+func TestMock_Declines(t *testing.T) {
+	tests := []struct {
+		method   string
+		wantCode string
+	}{
+		{method: MethodCardDeclined, wantCode: "card_declined"},
+		{method: MethodCardInsufficientFunds, wantCode: "insufficient_funds"},
+		{method: MethodCardExpired, wantCode: "expired_card"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			// Setup
+			mock := NewMock()
+			ctx := context.Background()
+			intent, err := mock.CreateIntent(ctx, IntentReq{OrderID: "order-1", Amount: 650, Currency: "usd", PaymentMethod: tt.method})
+			assert.NoError(t, err)
+
+			// Execute
+			failed, err := mock.Confirm(ctx, intent.ID)
+
+			// Assert
+			var declined *DeclinedError
+			if assert.ErrorAs(t, err, &declined) {
+				assert.Equal(t, tt.wantCode, declined.Code)
+			}
+			assert.ErrorIs(t, err, ErrDeclined)
+			assert.Equal(t, StatusFailed, failed.Status)
+			assert.Equal(t, tt.wantCode, failed.FailureCode)
+			canceled, err := mock.Cancel(ctx, intent.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, StatusCanceled, canceled.Status)
+		})
+	}
+}
+
+func TestMock_RejectsInvalidIntents(t *testing.T) {
+	mock := NewMock()
+	ctx := context.Background()
+
+	_, err := mock.CreateIntent(ctx, IntentReq{OrderID: "order-1", Amount: 650, Currency: "usd", PaymentMethod: "4242424242424242"})
+	assert.ErrorIs(t, err, ErrDeclined)
+	_, err = mock.CreateIntent(ctx, IntentReq{OrderID: "order-1", Amount: 0, Currency: "usd"})
+	assert.Error(t, err)
+	_, err = mock.Confirm(ctx, "pi_missing")
+	assert.ErrorIs(t, err, ErrIntentNotFound)
+	_, err = mock.Cancel(ctx, "pi_missing")
+	assert.ErrorIs(t, err, ErrIntentNotFound)
+}
+
+func TestMinorUnits(t *testing.T) {
+	assert.Equal(t, int64(1300), MinorUnits(13))
+	assert.Equal(t, int64(1170), MinorUnits(11.7))
+	assert.Equal(t, int64(1), MinorUnits(0.005))
+}
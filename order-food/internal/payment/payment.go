@@ -0,0 +1,101 @@
+// Package payment charges orders through a payment provider. Providers
+// follow the payment intent model: an intent for the amount is created
+// before the order is stored, confirmed once everything else about the
+// order succeeded, and cancelled when the order is rolled back instead. A
+// confirmed intent whose order cannot be stored is refunded by cancelling
+// it too.
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Status is the state of a payment intent
+type Status string
+
+// Payment intent statuses. Intents are created waiting for confirmation
+// and end succeeded, failed or canceled; a succeeded intent that is
+// canceled is refunded.
+const (
+	StatusRequiresConfirmation Status = "requires_confirmation"
+	StatusSucceeded            Status = "succeeded"
+	StatusFailed               Status = "failed"
+	StatusCanceled             Status = "canceled"
+	StatusRefunded             Status = "refunded"
+)
+
+var (
+	// ErrDeclined is wrapped by DeclinedError
+	ErrDeclined = errors.New("payment declined")
+	// ErrUnavailable is returned when the provider cannot be reached or
+	// fails; the order can be retried later
+	ErrUnavailable = errors.New("payment provider unavailable")
+	// ErrIntentNotFound is returned for an intent the provider does not know
+	ErrIntentNotFound = errors.New("payment intent not found")
+	// ErrInvalidIntent is returned when an intent is not in a status
+	// allowing the operation, such as confirming a canceled intent
+	ErrInvalidIntent = errors.New("payment intent cannot be changed")
+)
+
+// DeclinedError is returned when confirming an intent fails because the
+// payment method was declined
+type DeclinedError struct {
+	// Code is the provider's reason, such as card_declined
+	Code string
+	// Message explains the decline to the customer
+	Message string
+}
+
+func (e *DeclinedError) Error() string {
+	return fmt.Sprintf("payment declined: %s", e.Code)
+}
+
+func (e *DeclinedError) Unwrap() error { return ErrDeclined }
+
+// IntentReq requests a payment intent for an order
+type IntentReq struct {
+	OrderID string
+	// Amount is in the smallest unit of Currency, e.g. cents
+	Amount int64
+	// Currency is a lower-case ISO 4217 code, such as usd
+	Currency string
+	// PaymentMethod identifies the customer's card or wallet at the
+	// provider, such as a token collected by its checkout form
+	PaymentMethod string
+}
+
+// Intent is a payment intent at the provider
+type Intent struct {
+	ID            string
+	OrderID       string
+	Amount        int64
+	Currency      string
+	PaymentMethod string
+	Status        Status
+	// FailureCode is the reason of a failed intent
+	FailureCode string
+}
+
+// Provider charges payment methods. Implementations must be safe for
+// concurrent use.
+type Provider interface {
+	// Name identifies the provider in stored payments
+	Name() string
+	// CreateIntent creates an intent waiting for confirmation
+	CreateIntent(ctx context.Context, req IntentReq) (Intent, error)
+	// Confirm charges the payment method of an intent. A declined
+	// payment method returns a *DeclinedError.
+	Confirm(ctx context.Context, id string) (Intent, error)
+	// Cancel cancels an intent that has not been confirmed, or refunds
+	// one that succeeded
+	Cancel(ctx context.Context, id string) (Intent, error)
+}
+
+// MinorUnits converts an amount in dollars, or another currency with two
+// decimals, to cents
+func MinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
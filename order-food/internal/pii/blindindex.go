@@ -0,0 +1,45 @@
+package pii
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// BlindIndex hashes values with a secret key, so rows can be looked up by
+// a sealed column without decrypting it: sealing the same value twice gives
+// different results, hashing it always gives the same one. The key is kept
+// apart from the encryption keys, so rotating those does not change the
+// hashes.
+type BlindIndex struct {
+	key []byte
+}
+
+// NewBlindIndex creates a blind index hashing with key. An empty key gives
+// an unkeyed hash, which is only good enough while the values are stored
+// in plaintext anyway.
+func NewBlindIndex(key []byte) *BlindIndex {
+	return &BlindIndex{key: key}
+}
+
+// ParseBlindIndexKey builds a BlindIndex from a base64 encoded 32 byte
+// key, as read from PII_BLIND_INDEX_KEY
+func ParseBlindIndexKey(encoded string) (*BlindIndex, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blind index key: %w", err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("invalid blind index key: must be %d bytes, got %d", keySize, len(key))
+	}
+	return NewBlindIndex(key), nil
+}
+
+// Hash returns the hex encoded HMAC-SHA256 of value
+func (b *BlindIndex) Hash(value string) string {
+	mac := hmac.New(sha256.New, b.key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,72 @@
+package pii
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// keySize is the AES-256 key length in bytes
+const keySize = 32
+
+// StaticKeyProvider serves a fixed, ordered set of keys. The first key is
+// the current one.
+type StaticKeyProvider struct {
+	currentID string
+	keys      map[string][]byte
+}
+
+// ParseKeys builds a StaticKeyProvider from a comma-separated list of
+// id:base64key pairs, newest first, as read from PII_ENCRYPTION_KEYS.
+// Rotating means prepending a new key and keeping the old ones listed
+// until every row has been rewritten.
+func ParseKeys(spec string) (*StaticKeyProvider, error) {
+	p := &StaticKeyProvider{keys: make(map[string][]byte)}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid key entry %q: expected id:base64key", entry)
+		}
+		if _, dup := p.keys[id]; dup {
+			return nil, fmt.Errorf("duplicate key id %q", id)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("invalid key %q: must be %d bytes, got %d", id, keySize, len(key))
+		}
+
+		if p.currentID == "" {
+			p.currentID = id
+		}
+		p.keys[id] = key
+	}
+
+	if p.currentID == "" {
+		return nil, fmt.Errorf("no encryption keys configured")
+	}
+	return p, nil
+}
+
+// CurrentKey returns the key used for new values
+func (p *StaticKeyProvider) CurrentKey() (string, []byte, error) {
+	return p.currentID, p.keys[p.currentID], nil
+}
+
+// Key returns the key with the given id
+func (p *StaticKeyProvider) Key(id string) ([]byte, error) {
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, id)
+	}
+	return key, nil
+}
@@ -0,0 +1,131 @@
+// Package pii encrypts personal data before it is written to the database.
+// Values are sealed with AES-256-GCM under a named key; the key name is
+// stored with each value so keys can be rotated without rewriting old rows.
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix marks values written by Cipher.Encode
+const encryptedPrefix = "enc:v1:"
+
+// ErrUnknownKey is returned when a value was sealed with a key the provider does not have
+var ErrUnknownKey = errors.New("unknown encryption key")
+
+// Codec converts a field value to and from its stored form
+type Codec interface {
+	Encode(value string) (string, error)
+	Decode(stored string) (string, error)
+}
+
+// KeyProvider supplies data encryption keys. New values are sealed with
+// the current key; older keys stay available for decryption.
+type KeyProvider interface {
+	CurrentKey() (id string, key []byte, err error)
+	Key(id string) ([]byte, error)
+}
+
+// Plaintext is a Codec that stores values unchanged
+type Plaintext struct{}
+
+// Encode returns value unchanged
+func (Plaintext) Encode(value string) (string, error) { return value, nil }
+
+// Decode returns stored unchanged
+func (Plaintext) Decode(stored string) (string, error) { return stored, nil }
+
+// Cipher is a Codec sealing values with AES-256-GCM
+type Cipher struct {
+	keys KeyProvider
+}
+
+// NewCipher creates a cipher using keys from provider
+func NewCipher(provider KeyProvider) *Cipher {
+	return &Cipher{keys: provider}
+}
+
+// Encode seals value with the current key. Empty values are stored as is.
+func (c *Cipher) Encode(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	id, key, err := c.keys.CurrentKey()
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(id))
+
+	return encryptedPrefix + id + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decode opens a value sealed by Encode. Values without the encryption
+// prefix are returned unchanged, so rows written before encryption was
+// enabled stay readable.
+func (c *Cipher) Decode(stored string) (string, error) {
+	if !strings.HasPrefix(stored, encryptedPrefix) {
+		return stored, nil
+	}
+
+	id, payload, ok := strings.Cut(strings.TrimPrefix(stored, encryptedPrefix), ":")
+	if !ok {
+		return "", errors.New("malformed encrypted value")
+	}
+	key, err := c.keys.Key(id)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+
+	return string(plain), nil
+}
+
+// NeedsRotation reports whether a stored value is plaintext or sealed with
+// a key other than the current one
+func (c *Cipher) NeedsRotation(stored string) bool {
+	if stored == "" {
+		return false
+	}
+	if !strings.HasPrefix(stored, encryptedPrefix) {
+		return true
+	}
+	id, _, err := c.keys.CurrentKey()
+	return err != nil || !strings.HasPrefix(stored, encryptedPrefix+id+":")
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,134 @@
+package pii
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune(b)), keySize)))
+}
+
+func TestCipher_RoundTrip(t *testing.T) {
+	// Setup
+	keys, err := ParseKeys("k1:" + testKey('a'))
+	assert.NoError(t, err)
+	c := NewCipher(keys)
+
+	// Execute
+	stored, err := c.Encode("ops@acme.test")
+	assert.NoError(t, err)
+	plain, err := c.Decode(stored)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "ops@acme.test", plain)
+	assert.True(t, strings.HasPrefix(stored, "enc:v1:k1:"))
+	assert.NotContains(t, stored, "acme")
+}
+
+func TestCipher_DecodesPlaintextAndEmpty(t *testing.T) {
+	// Setup
+	keys, err := ParseKeys("k1:" + testKey('a'))
+	assert.NoError(t, err)
+	c := NewCipher(keys)
+
+	// Execute & Assert
+	plain, err := c.Decode("legacy@acme.test")
+	assert.NoError(t, err)
+	assert.Equal(t, "legacy@acme.test", plain)
+
+	stored, err := c.Encode("")
+	assert.NoError(t, err)
+	assert.Equal(t, "", stored)
+}
+
+func TestCipher_KeyRotation(t *testing.T) {
+	// Setup - value written under k1
+	oldKeys, err := ParseKeys("k1:" + testKey('a'))
+	assert.NoError(t, err)
+	stored, err := NewCipher(oldKeys).Encode("ops@acme.test")
+	assert.NoError(t, err)
+
+	// Execute - k2 becomes current, k1 kept for decryption
+	rotated, err := ParseKeys("k2:" + testKey('b') + ",k1:" + testKey('a'))
+	assert.NoError(t, err)
+	c := NewCipher(rotated)
+	plain, err := c.Decode(stored)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "ops@acme.test", plain)
+	assert.True(t, c.NeedsRotation(stored))
+
+	fresh, err := c.Encode(plain)
+	assert.NoError(t, err)
+	assert.False(t, c.NeedsRotation(fresh))
+
+	// Once k1 is dropped, old values can no longer be read
+	onlyNew, err := ParseKeys("k2:" + testKey('b'))
+	assert.NoError(t, err)
+	_, err = NewCipher(onlyNew).Decode(stored)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestCipher_RejectsTamperedValue(t *testing.T) {
+	// Setup
+	keys, err := ParseKeys("k1:" + testKey('a'))
+	assert.NoError(t, err)
+	c := NewCipher(keys)
+	stored, err := c.Encode("ops@acme.test")
+	assert.NoError(t, err)
+
+	// Execute - change one character in the middle of the payload
+	i := len(stored) - 10
+	replacement := byte('A')
+	if stored[i] == 'A' {
+		replacement = 'B'
+	}
+	_, err = c.Decode(stored[:i] + string(replacement) + stored[i+1:])
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestParseKeys_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"nokey",
+		"k1:not-base64!",
+		"k1:" + base64.StdEncoding.EncodeToString([]byte("short")),
+		"k1:" + testKey('a') + ",k1:" + testKey('b'),
+	} {
+		_, err := ParseKeys(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestBlindIndex_Hash(t *testing.T) {
+	// Setup
+	index, err := ParseBlindIndexKey(testKey('a'))
+	assert.NoError(t, err)
+	other, err := ParseBlindIndexKey(testKey('b'))
+	assert.NoError(t, err)
+
+	// Execute
+	hash := index.Hash("ada@example.com")
+
+	// Assert
+	assert.Equal(t, hash, index.Hash("ada@example.com"), "the same value always has the same hash")
+	assert.NotEqual(t, hash, index.Hash("bob@example.com"))
+	assert.NotEqual(t, hash, other.Hash("ada@example.com"), "the hash depends on the key")
+	assert.NotContains(t, hash, "example")
+	assert.Len(t, hash, 64)
+}
+
+func TestParseBlindIndexKey_Invalid(t *testing.T) {
+	for _, encoded := range []string{"", "not-base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		_, err := ParseBlindIndexKey(encoded)
+		assert.Error(t, err, encoded)
+	}
+}
@@ -0,0 +1,145 @@
+// Package pos is the anti-corruption layer between the legacy point-of-sale
+// ticket format and the order-food domain model. Nothing outside this package
+// should need to know how the POS names or encodes its fields.
+package pos
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// maxTicketNumberLength matches the width of pos_order_imports.ticket_number
+const maxTicketNumberLength = 64
+
+// ErrUnsupportedFormat is returned when the payload is neither XML nor JSON
+var ErrUnsupportedFormat = errors.New("unsupported POS payload format")
+
+// Ticket is the legacy POS order ticket as exported by the in-store tills.
+// All values arrive as strings because the POS does not type its output.
+type Ticket struct {
+	XMLName      xml.Name     `xml:"Ticket" json:"-"`
+	TicketNumber string       `xml:"TicketNo" json:"ticketNo"`
+	StoreCode    string       `xml:"StoreCode" json:"storeCode"`
+	PromoCode    string       `xml:"PromoCd" json:"promoCd"`
+	Lines        []TicketLine `xml:"Lines>Line" json:"lines"`
+}
+
+// TicketLine is a single line item on a legacy POS ticket
+type TicketLine struct {
+	PLU      string `xml:"PLU" json:"plu"`
+	Quantity string `xml:"Qty" json:"qty"`
+}
+
+// Parse decodes a legacy POS payload. The content type decides the decoder;
+// when it is missing the first non-space byte is used to sniff the format.
+func Parse(contentType string, body []byte) (Ticket, error) {
+	var ticket Ticket
+
+	switch detectFormat(contentType, body) {
+	case "xml":
+		if err := xml.Unmarshal(body, &ticket); err != nil {
+			return Ticket{}, fmt.Errorf("invalid POS XML payload: %w", err)
+		}
+	case "json":
+		if err := json.Unmarshal(body, &ticket); err != nil {
+			return Ticket{}, fmt.Errorf("invalid POS JSON payload: %w", err)
+		}
+	default:
+		return Ticket{}, ErrUnsupportedFormat
+	}
+
+	return ticket, nil
+}
+
+// detectFormat resolves the payload format from the content type or body
+func detectFormat(contentType string, body []byte) string {
+	ct := strings.ToLower(contentType)
+	switch {
+	case strings.Contains(ct, "xml"):
+		return "xml"
+	case strings.Contains(ct, "json"):
+		return "json"
+	}
+
+	trimmed := strings.TrimSpace(string(body))
+	switch {
+	case strings.HasPrefix(trimmed, "<"):
+		return "xml"
+	case strings.HasPrefix(trimmed, "{"):
+		return "json"
+	}
+	return ""
+}
+
+// Translate maps a legacy ticket into an OrderReq. Every problem found is
+// reported as a field diagnostic so the POS operator can fix the ticket in
+// one go; the returned OrderReq must not be used when diagnostics is non-empty.
+func Translate(ticket Ticket) (models.OrderReq, []models.FieldError) {
+	var diagnostics []models.FieldError
+
+	ticketNumber := strings.TrimSpace(ticket.TicketNumber)
+	if ticketNumber == "" {
+		diagnostics = append(diagnostics, models.FieldError{Field: "TicketNo", Message: "ticket number is required"})
+	} else if len(ticketNumber) > maxTicketNumberLength {
+		diagnostics = append(diagnostics, models.FieldError{
+			Field:   "TicketNo",
+			Message: fmt.Sprintf("ticket number must be at most %d characters", maxTicketNumberLength),
+		})
+	}
+
+	if len(ticket.Lines) == 0 {
+		diagnostics = append(diagnostics, models.FieldError{Field: "Lines", Message: "ticket must contain at least one line"})
+	}
+
+	// The orders schema allows each product only once per order, so repeated
+	// PLUs on a ticket are merged into a single item
+	quantities := make(map[string]int)
+	order := make([]string, 0, len(ticket.Lines))
+
+	for i, line := range ticket.Lines {
+		plu := strings.TrimSpace(line.PLU)
+		if plu == "" {
+			diagnostics = append(diagnostics, models.FieldError{
+				Field:   fmt.Sprintf("Lines[%d].PLU", i),
+				Message: "PLU is required",
+			})
+		}
+
+		qty, err := strconv.Atoi(strings.TrimSpace(line.Quantity))
+		if err != nil || qty < 1 {
+			diagnostics = append(diagnostics, models.FieldError{
+				Field:   fmt.Sprintf("Lines[%d].Qty", i),
+				Message: fmt.Sprintf("quantity %q must be a positive whole number", line.Quantity),
+			})
+			continue
+		}
+
+		if plu == "" {
+			continue
+		}
+		if _, seen := quantities[plu]; !seen {
+			order = append(order, plu)
+		}
+		quantities[plu] += qty
+	}
+
+	if len(diagnostics) > 0 {
+		return models.OrderReq{}, diagnostics
+	}
+
+	req := models.OrderReq{
+		CouponCode: strings.TrimSpace(ticket.PromoCode),
+		Items:      make([]models.OrderItem, 0, len(order)),
+	}
+	for _, plu := range order {
+		req.Items = append(req.Items, models.OrderItem{ProductID: plu, Quantity: quantities[plu]})
+	}
+
+	return req, nil
+}
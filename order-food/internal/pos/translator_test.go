@@ -0,0 +1,123 @@
+package pos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_XML(t *testing.T) {
+	body := []byte(`<Ticket>
+		<TicketNo>T-42</TicketNo>
+		<StoreCode>SYD01</StoreCode>
+		<PromoCd>HAPPYHRS</PromoCd>
+		<Lines>
+			<Line><PLU>1</PLU><Qty>2</Qty></Line>
+			<Line><PLU>3</PLU><Qty>1</Qty></Line>
+		</Lines>
+	</Ticket>`)
+
+	ticket, err := Parse("application/xml", body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "T-42", ticket.TicketNumber)
+	assert.Equal(t, "SYD01", ticket.StoreCode)
+	assert.Equal(t, "HAPPYHRS", ticket.PromoCode)
+	assert.Len(t, ticket.Lines, 2)
+	assert.Equal(t, "3", ticket.Lines[1].PLU)
+}
+
+func TestParse_JSON(t *testing.T) {
+	body := []byte(`{"ticketNo":"T-43","lines":[{"plu":"2","qty":"4"}]}`)
+
+	ticket, err := Parse("application/json", body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "T-43", ticket.TicketNumber)
+	assert.Equal(t, "4", ticket.Lines[0].Quantity)
+}
+
+func TestParse_SniffsFormatWithoutContentType(t *testing.T) {
+	ticket, err := Parse("", []byte(`  <Ticket><TicketNo>T-44</TicketNo></Ticket>`))
+	assert.NoError(t, err)
+	assert.Equal(t, "T-44", ticket.TicketNumber)
+
+	ticket, err = Parse("", []byte(`{"ticketNo":"T-45"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "T-45", ticket.TicketNumber)
+}
+
+func TestParse_UnsupportedFormat(t *testing.T) {
+	_, err := Parse("text/plain", []byte("T-46|1|2"))
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestParse_MalformedPayload(t *testing.T) {
+	_, err := Parse("application/json", []byte(`{"ticketNo":`))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestTranslate_Valid(t *testing.T) {
+	ticket := Ticket{
+		TicketNumber: " T-50 ",
+		PromoCode:    " HAPPYHRS ",
+		Lines: []TicketLine{
+			{PLU: "1", Quantity: "2"},
+			{PLU: "3", Quantity: " 1 "},
+		},
+	}
+
+	req, diagnostics := Translate(ticket)
+
+	assert.Empty(t, diagnostics)
+	assert.Equal(t, "HAPPYHRS", req.CouponCode)
+	assert.Len(t, req.Items, 2)
+	assert.Equal(t, "1", req.Items[0].ProductID)
+	assert.Equal(t, 2, req.Items[0].Quantity)
+	assert.Equal(t, 1, req.Items[1].Quantity)
+}
+
+func TestTranslate_MergesRepeatedPLUs(t *testing.T) {
+	ticket := Ticket{
+		TicketNumber: "T-51",
+		Lines: []TicketLine{
+			{PLU: "1", Quantity: "2"},
+			{PLU: "2", Quantity: "1"},
+			{PLU: "1", Quantity: "3"},
+		},
+	}
+
+	req, diagnostics := Translate(ticket)
+
+	assert.Empty(t, diagnostics)
+	assert.Len(t, req.Items, 2)
+	assert.Equal(t, "1", req.Items[0].ProductID)
+	assert.Equal(t, 5, req.Items[0].Quantity)
+	assert.Equal(t, "2", req.Items[1].ProductID)
+}
+
+func TestTranslate_ReportsEveryFieldProblem(t *testing.T) {
+	ticket := Ticket{
+		Lines: []TicketLine{
+			{PLU: "", Quantity: "1"},
+			{PLU: "2", Quantity: "-1"},
+			{PLU: "3", Quantity: "abc"},
+		},
+	}
+
+	_, diagnostics := Translate(ticket)
+
+	fields := make([]string, len(diagnostics))
+	for i, d := range diagnostics {
+		fields[i] = d.Field
+	}
+	assert.Equal(t, []string{"TicketNo", "Lines[0].PLU", "Lines[1].Qty", "Lines[2].Qty"}, fields)
+}
+
+func TestTranslate_NoLines(t *testing.T) {
+	_, diagnostics := Translate(Ticket{TicketNumber: "T-52"})
+
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, "Lines", diagnostics[0].Field)
+}
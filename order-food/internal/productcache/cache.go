@@ -0,0 +1,180 @@
+// Package productcache keeps recently read products in memory. Entries
+// expire after a TTL and are dropped early when an invalidation event for
+// the product arrives, so replicas do not serve stale catalogue data.
+package productcache
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// InvalidateAll is the key that flushes every cached product
+const InvalidateAll = "*"
+
+// maxPages bounds the number of cached listing pages; the page cache is
+// flushed when it fills up
+const maxPages = 1000
+
+// Page is one cached page of the product listing
+type Page struct {
+	Products []models.Product
+	Total    int
+}
+
+type productEntry struct {
+	product   models.Product
+	expiresAt time.Time
+}
+
+type pageEntry struct {
+	page      Page
+	expiresAt time.Time
+}
+
+// Cache holds products by ID and barcode and listing pages by query. It is
+// safe for concurrent use.
+type Cache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.Mutex
+	products map[string]productEntry
+	barcodes map[string]string
+	pages    map[string]pageEntry
+}
+
+// New creates a cache whose entries expire after ttl
+func New(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:      ttl,
+		now:      time.Now,
+		products: make(map[string]productEntry),
+		barcodes: make(map[string]string),
+		pages:    make(map[string]pageEntry),
+	}
+}
+
+// Product returns the cached product with the given ID
+func (c *Cache) Product(id string) (models.Product, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.productLocked(id)
+}
+
+// ProductByBarcode returns the cached product with the given barcode
+func (c *Cache) ProductByBarcode(barcode string) (models.Product, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id, ok := c.barcodes[barcode]
+	if !ok {
+		return models.Product{}, false
+	}
+	product, ok := c.productLocked(id)
+	if !ok || product.Barcode != barcode {
+		delete(c.barcodes, barcode)
+		return models.Product{}, false
+	}
+	return product, true
+}
+
+func (c *Cache) productLocked(id string) (models.Product, bool) {
+	entry, ok := c.products[id]
+	if !ok {
+		return models.Product{}, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.products, id)
+		return models.Product{}, false
+	}
+	return entry.product, true
+}
+
+// StoreProduct caches a product
+func (c *Cache) StoreProduct(product models.Product) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.products[product.ID] = productEntry{product: product, expiresAt: c.now().Add(c.ttl)}
+	if product.Barcode != "" {
+		c.barcodes[product.Barcode] = product.ID
+	}
+}
+
+// Page returns the cached listing page for key
+func (c *Cache) Page(key string) (Page, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.pages[key]
+	if !ok {
+		return Page{}, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.pages, key)
+		return Page{}, false
+	}
+	return entry.page, true
+}
+
+// StorePage caches a listing page under key
+func (c *Cache) StorePage(key string, page Page) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.pages) >= maxPages {
+		c.pages = make(map[string]pageEntry)
+	}
+	c.pages[key] = pageEntry{page: page, expiresAt: c.now().Add(c.ttl)}
+}
+
+// Invalidate drops the product with the given ID, or everything when key
+// is InvalidateAll. Listing pages are always dropped since any of them may
+// contain the product.
+func (c *Cache) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key == InvalidateAll {
+		c.products = make(map[string]productEntry)
+		c.barcodes = make(map[string]string)
+	} else {
+		delete(c.products, key)
+	}
+	c.pages = make(map[string]pageEntry)
+}
+
+// PageKey builds the cache key of a listing page
+func PageKey(limit, offset int, sort []models.SortField, filter models.ProductFilter) string {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(limit))
+	b.WriteByte(':')
+	b.WriteString(strconv.Itoa(offset))
+	for _, field := range sort {
+		b.WriteByte(':')
+		if field.Desc {
+			b.WriteByte('-')
+		}
+		b.WriteString(field.Field)
+	}
+	// Categories may contain any character, so the filter is quoted
+	if filter != (models.ProductFilter{}) {
+		b.WriteString("|")
+		b.WriteString(strconv.Quote(filter.Category))
+		for _, price := range []*float64{filter.MinPrice, filter.MaxPrice} {
+			b.WriteByte(':')
+			if price != nil {
+				b.WriteString(strconv.FormatFloat(*price, 'f', -1, 64))
+			}
+		}
+		if filter.CategoryID != "" {
+			b.WriteString("|")
+			b.WriteString(strconv.Quote(filter.CategoryID))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,91 @@
+package productcache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCache returns a cache driven by a controllable clock
+func newTestCache(ttl time.Duration) (*Cache, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := New(ttl)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestCache_ProductExpiresAfterTTL(t *testing.T) {
+	// Setup
+	c, now := newTestCache(time.Minute)
+	c.StoreProduct(models.Product{ID: "1", Name: "Waffle", Barcode: "4006381333931"})
+
+	// Execute
+	product, ok := c.Product("1")
+	byBarcode, barcodeOK := c.ProductByBarcode("4006381333931")
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, "Waffle", product.Name)
+	assert.True(t, barcodeOK)
+	assert.Equal(t, "1", byBarcode.ID)
+
+	*now = now.Add(time.Minute)
+	_, ok = c.Product("1")
+	assert.False(t, ok)
+	_, ok = c.ProductByBarcode("4006381333931")
+	assert.False(t, ok)
+}
+
+func TestCache_InvalidateProductDropsPages(t *testing.T) {
+	// Setup
+	c, _ := newTestCache(time.Minute)
+	c.StoreProduct(models.Product{ID: "1"})
+	c.StoreProduct(models.Product{ID: "2"})
+	key := PageKey(10, 0, nil, models.ProductFilter{})
+	c.StorePage(key, Page{Products: []models.Product{{ID: "1"}, {ID: "2"}}, Total: 2})
+
+	// Execute
+	c.Invalidate("1")
+
+	// Assert
+	_, ok := c.Product("1")
+	assert.False(t, ok)
+	_, ok = c.Product("2")
+	assert.True(t, ok)
+	_, ok = c.Page(key)
+	assert.False(t, ok)
+}
+
+func TestCache_InvalidateAll(t *testing.T) {
+	// Setup
+	c, _ := newTestCache(time.Minute)
+	c.StoreProduct(models.Product{ID: "1", Barcode: "123"})
+	c.StorePage(PageKey(10, 0, nil, models.ProductFilter{}), Page{Total: 1})
+
+	// Execute
+	c.Invalidate(InvalidateAll)
+
+	// Assert
+	_, ok := c.Product("1")
+	assert.False(t, ok)
+	_, ok = c.ProductByBarcode("123")
+	assert.False(t, ok)
+	_, ok = c.Page(PageKey(10, 0, nil, models.ProductFilter{}))
+	assert.False(t, ok)
+}
+
+func TestPageKey(t *testing.T) {
+	sort := []models.SortField{{Field: "price", Desc: true}, {Field: "name"}}
+
+	assert.Equal(t, "20:40:-price:name", PageKey(20, 40, sort, models.ProductFilter{}))
+	assert.NotEqual(t, PageKey(20, 40, sort, models.ProductFilter{}), PageKey(20, 40, nil, models.ProductFilter{}))
+
+	minPrice := 5.5
+	assert.Equal(t, `20:40|"Waffle":5.5:`, PageKey(20, 40, nil, models.ProductFilter{Category: "Waffle", MinPrice: &minPrice}))
+	assert.NotEqual(t,
+		PageKey(20, 40, nil, models.ProductFilter{MinPrice: &minPrice}),
+		PageKey(20, 40, nil, models.ProductFilter{MaxPrice: &minPrice}))
+	assert.Equal(t, `20:40|""::|"waffle"`, PageKey(20, 40, nil, models.ProductFilter{CategoryID: "waffle"}))
+}
@@ -0,0 +1,243 @@
+package productcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// DefaultRedisPages is the number of leading listing pages cached in Redis
+const DefaultRedisPages = 3
+
+// invalidateAllBatch is the number of keys scanned per round when every
+// product is invalidated
+const invalidateAllBatch = 500
+
+// Descriptors of the Redis cache counters
+var (
+	redisHitsDesc   = prometheus.NewDesc("redis_product_cache_hits_total", "Product reads served from Redis.", nil, nil)
+	redisMissesDesc = prometheus.NewDesc("redis_product_cache_misses_total", "Product reads Redis had no entry for.", nil, nil)
+	redisErrorsDesc = prometheus.NewDesc("redis_product_cache_errors_total", "Failed Redis product cache operations.", nil, nil)
+)
+
+// redisProduct is a product as stored in Redis. Translations are not part
+// of the product's JSON, but responses are localized from them.
+type redisProduct struct {
+	models.Product
+	Translations map[string]models.ProductTranslation `json:"translations,omitempty"`
+}
+
+// redisPage is a listing page as stored in Redis
+type redisPage struct {
+	Products []redisProduct `json:"products"`
+	Total    int            `json:"total"`
+}
+
+// Redis caches products by ID and the first pages of the listing in Redis,
+// shared by every replica, so a product read through one replica spares
+// the database the reads of the others. Entries expire after a TTL and
+// are deleted when an invalidation event for the product arrives. Pages
+// are kept in one hash, which any product change deletes whole.
+//
+// Redis is an optimisation only: a failing or slow Redis is counted and
+// logged, and reads fall through to the database.
+type Redis struct {
+	client  redis.UniversalClient
+	prefix  string
+	ttl     time.Duration
+	pages   int
+	timeout time.Duration
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// NewRedis creates a cache storing entries in client under keys starting
+// with prefix for ttl. Listing pages are cached while they are among the
+// first pages; every command gives up after timeout.
+func NewRedis(client redis.UniversalClient, prefix string, ttl time.Duration, pages int, timeout time.Duration) *Redis {
+	return &Redis{client: client, prefix: prefix, ttl: ttl, pages: pages, timeout: timeout}
+}
+
+func (r *Redis) productKey(id string) string {
+	return r.prefix + "product:" + id
+}
+
+func (r *Redis) pagesKey() string {
+	return r.prefix + "product-pages"
+}
+
+// Product returns the cached product with the given ID
+func (r *Redis) Product(id string) (models.Product, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	data, err := r.client.Get(ctx, r.productKey(id)).Bytes()
+	if !r.found(err) {
+		return models.Product{}, false
+	}
+	var product redisProduct
+	if err := json.Unmarshal(data, &product); err != nil {
+		r.failed("decode product", err)
+		return models.Product{}, false
+	}
+	return product.toModel(), true
+}
+
+// StoreProduct caches a product
+func (r *Redis) StoreProduct(product models.Product) {
+	data, err := json.Marshal(fromModel(product))
+	if err != nil {
+		r.failed("encode product", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	if err := r.client.Set(ctx, r.productKey(product.ID), data, r.ttl).Err(); err != nil {
+		r.failed("store product", err)
+	}
+}
+
+// CachesPage reports whether the page at offset is among the pages cached
+func (r *Redis) CachesPage(limit, offset int) bool {
+	return offset < r.pages*limit
+}
+
+// Page returns the cached listing page for key
+func (r *Redis) Page(key string) (Page, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	data, err := r.client.HGet(ctx, r.pagesKey(), key).Bytes()
+	if !r.found(err) {
+		return Page{}, false
+	}
+	var page redisPage
+	if err := json.Unmarshal(data, &page); err != nil {
+		r.failed("decode page", err)
+		return Page{}, false
+	}
+	products := make([]models.Product, len(page.Products))
+	for i, p := range page.Products {
+		products[i] = p.toModel()
+	}
+	return Page{Products: products, Total: page.Total}, true
+}
+
+// StorePage caches a listing page under key. The hash of pages expires a
+// TTL after its first page was stored.
+func (r *Redis) StorePage(key string, page Page) {
+	stored := redisPage{Products: make([]redisProduct, len(page.Products)), Total: page.Total}
+	for i, p := range page.Products {
+		stored.Products[i] = fromModel(p)
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		r.failed("encode page", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	pipe := r.client.TxPipeline()
+	pipe.HSet(ctx, r.pagesKey(), key, data)
+	pipe.ExpireNX(ctx, r.pagesKey(), r.ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		r.failed("store page", err)
+	}
+}
+
+// Invalidate deletes the product with the given ID, or every product when
+// key is InvalidateAll, and every listing page
+func (r *Redis) Invalidate(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if key != InvalidateAll {
+		if err := r.client.Del(ctx, r.productKey(key), r.pagesKey()).Err(); err != nil {
+			r.failed("invalidate product", err)
+		}
+		return
+	}
+
+	if err := r.client.Del(ctx, r.pagesKey()).Err(); err != nil {
+		r.failed("invalidate pages", err)
+	}
+	// Scanning may take many rounds, each with a timeout of its own
+	cancel()
+	var cursor uint64
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		keys, next, err := r.client.Scan(ctx, cursor, r.productKey("*"), invalidateAllBatch).Result()
+		if err == nil && len(keys) > 0 {
+			err = r.client.Unlink(ctx, keys...).Err()
+		}
+		cancel()
+		if err != nil {
+			r.failed("invalidate products", err)
+			return
+		}
+		if cursor = next; cursor == 0 {
+			return
+		}
+	}
+}
+
+// Ping checks that Redis answers
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+// found counts the outcome of a lookup and reports whether it was a hit
+func (r *Redis) found(err error) bool {
+	switch {
+	case err == nil:
+		r.hits.Add(1)
+		return true
+	case errors.Is(err, redis.Nil):
+		r.misses.Add(1)
+	default:
+		r.misses.Add(1)
+		r.failed("read", err)
+	}
+	return false
+}
+
+// failed counts and logs a failed Redis operation
+func (r *Redis) failed(op string, err error) {
+	r.errors.Add(1)
+	slog.Warn("Redis product cache "+op+" failed", "error", err)
+}
+
+func (p redisProduct) toModel() models.Product {
+	product := p.Product
+	product.Translations = p.Translations
+	return product
+}
+
+func fromModel(product models.Product) redisProduct {
+	return redisProduct{Product: product, Translations: product.Translations}
+}
+
+// Describe sends the descriptors of the cache's counters
+func (r *Redis) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{redisHitsDesc, redisMissesDesc, redisErrorsDesc} {
+		ch <- desc
+	}
+}
+
+// Collect sends the cache's counters
+func (r *Redis) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(redisHitsDesc, prometheus.CounterValue, float64(r.hits.Load()))
+	ch <- prometheus.MustNewConstMetric(redisMissesDesc, prometheus.CounterValue, float64(r.misses.Load()))
+	ch <- prometheus.MustNewConstMetric(redisErrorsDesc, prometheus.CounterValue, float64(r.errors.Load()))
+}
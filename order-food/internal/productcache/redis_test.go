@@ -0,0 +1,111 @@
+package productcache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRedis returns a Redis cache backed by an in-process server
+func newTestRedis(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedis(client, "test:", time.Minute, 2, time.Second), server
+}
+
+func TestRedis_Product(t *testing.T) {
+	// Setup
+	r, server := newTestRedis(t)
+	r.StoreProduct(models.Product{
+		ID:           "1",
+		Name:         "Waffle",
+		Price:        6.5,
+		Translations: map[string]models.ProductTranslation{"de": {Name: "Waffel"}},
+	})
+
+	// Execute
+	product, ok := r.Product("1")
+	_, missing := r.Product("2")
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, "Waffle", product.Name)
+	assert.Equal(t, "Waffel", product.Translations["de"].Name)
+	assert.False(t, missing)
+	assert.Equal(t, time.Minute, server.TTL("test:product:1"))
+
+	server.FastForward(time.Minute)
+	_, ok = r.Product("1")
+	assert.False(t, ok)
+}
+
+func TestRedis_Page(t *testing.T) {
+	// Setup
+	r, server := newTestRedis(t)
+	page := Page{Products: []models.Product{{ID: "1", Name: "Waffle"}}, Total: 9}
+	r.StorePage("10:0", page)
+	r.StorePage("10:10", page)
+
+	// Execute
+	cached, ok := r.Page("10:0")
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, page, cached)
+	assert.Equal(t, time.Minute, server.TTL("test:product-pages"))
+	assert.True(t, r.CachesPage(10, 10))
+	assert.False(t, r.CachesPage(10, 20))
+}
+
+func TestRedis_Invalidate(t *testing.T) {
+	// Setup
+	r, server := newTestRedis(t)
+	r.StoreProduct(models.Product{ID: "1"})
+	r.StoreProduct(models.Product{ID: "2"})
+	r.StorePage("10:0", Page{Products: []models.Product{{ID: "1"}}, Total: 2})
+
+	// Execute: a single product drops it and every page
+	r.Invalidate("1")
+
+	// Assert
+	_, ok := r.Product("1")
+	assert.False(t, ok)
+	_, ok = r.Product("2")
+	assert.True(t, ok)
+	_, ok = r.Page("10:0")
+	assert.False(t, ok)
+
+	// Execute: everything
+	server.Set("other:product:2", "kept")
+	r.Invalidate(InvalidateAll)
+
+	// Assert
+	_, ok = r.Product("2")
+	assert.False(t, ok)
+	assert.True(t, server.Exists("other:product:2"))
+}
+
+func TestRedis_Unavailable(t *testing.T) {
+	// Setup
+	r, server := newTestRedis(t)
+	server.Close()
+
+	// Execute
+	r.StoreProduct(models.Product{ID: "1"})
+	_, ok := r.Product("1")
+
+	// Assert
+	assert.False(t, ok)
+	assert.NoError(t, testutil.CollectAndCompare(r, strings.NewReader(`
+# HELP redis_product_cache_errors_total Failed Redis product cache operations.
+# TYPE redis_product_cache_errors_total counter
+redis_product_cache_errors_total 2
+`), "redis_product_cache_errors_total"))
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// insertAuditEntry records entry as part of tx, so the audit log only ever
+// holds changes that were committed, and returns the entry's ID
+func insertAuditEntry(ctx context.Context, tx *sql.Tx, entry models.AuditEntry) (int64, error) {
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return 0, fmt.Errorf("error encoding audit details: %w", err)
+	}
+
+	var id int64
+	query := `INSERT INTO audit_log (action, actor, details) VALUES ($1, $2, $3) RETURNING id`
+	if err := tx.QueryRowContext(ctx, query, entry.Action, entry.Actor, details).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error inserting audit entry: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+var (
+	// ErrCampaignExists is returned when a campaign with the same name exists
+	ErrCampaignExists = errors.New("campaign already exists")
+	// ErrCampaignNotFound is returned when a campaign does not exist
+	ErrCampaignNotFound = errors.New("campaign not found")
+)
+
+// CampaignRepository stores generated promo code campaigns and their codes
+type CampaignRepository struct {
+	db *sql.DB
+}
+
+// NewCampaignRepository creates a new campaign repository
+func NewCampaignRepository(db *sql.DB) *CampaignRepository {
+	return &CampaignRepository{db: db}
+}
+
+// ExistingCodes returns the codes that are already in the coupons table,
+// in any case. codes must be in upper case.
+func (r *CampaignRepository) ExistingCodes(codes []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT upper(coupon) FROM coupons WHERE upper(coupon) = ANY($1)`, pq.Array(codes))
+	if err != nil {
+		return nil, fmt.Errorf("error querying existing coupons: %w", err)
+	}
+	defer rows.Close()
+
+	var existing []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("error scanning coupon: %w", err)
+		}
+		existing = append(existing, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying existing coupons: %w", err)
+	}
+	return existing, nil
+}
+
+// Create stores campaign and writes every code to the coupons table under
+// each of the campaign's file names, with an audit entry and an
+// invalidation event for the coupon filters, in one transaction. campaign.CreatedAt is set from the database.
+// ErrCampaignExists is returned when the name is taken.
+func (r *CampaignRepository) Create(campaign *models.Campaign, codes []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	campaignQuery := `INSERT INTO campaigns (id, name, prefix, code_length, code_count, file_names, created_by)
+	                  VALUES ($1, $2, $3, $4, $5, $6, $7)
+	                  ON CONFLICT (name) DO NOTHING
+	                  RETURNING created_at`
+	err = tx.QueryRowContext(ctx, campaignQuery, campaign.ID, campaign.Name, campaign.Prefix, campaign.CodeLength,
+		campaign.CodeCount, pq.Array(campaign.FileNames), campaign.CreatedBy).Scan(&campaign.CreatedAt)
+	if err == sql.ErrNoRows {
+		return ErrCampaignExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert campaign: %w", err)
+	}
+
+	codesQuery := `INSERT INTO campaign_codes (campaign_id, code) SELECT $1, unnest($2::text[])`
+	if _, err := tx.ExecContext(ctx, codesQuery, campaign.ID, pq.Array(codes)); err != nil {
+		return fmt.Errorf("failed to insert campaign codes: %w", err)
+	}
+
+	couponsQuery := `INSERT INTO coupons (coupon, file_name)
+	                 SELECT code, file_name FROM unnest($1::text[]) code CROSS JOIN unnest($2::text[]) file_name
+	                 ON CONFLICT DO NOTHING`
+	if _, err := tx.ExecContext(ctx, couponsQuery, pq.Array(codes), pq.Array(campaign.FileNames)); err != nil {
+		return fmt.Errorf("failed to insert coupons: %w", err)
+	}
+
+	// Replicas add the new codes to their coupon filters
+	if err := publishInTx(ctx, tx, models.InvalidationTopicCoupons, []string{models.InvalidationKeyCampaignPrefix + campaign.ID}); err != nil {
+		return err
+	}
+
+	_, err = insertAuditEntry(ctx, tx, models.AuditEntry{
+		Action: models.AuditActionCampaignCreate,
+		Actor:  campaign.CreatedBy,
+		Details: map[string]any{
+			"campaignId": campaign.ID,
+			"name":       campaign.Name,
+			"codeCount":  campaign.CodeCount,
+			"fileNames":  campaign.FileNames,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a campaign by ID
+func (r *CampaignRepository) GetByID(id string) (models.Campaign, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT id, name, prefix, code_length, code_count, file_names, created_by, created_at
+	          FROM campaigns WHERE id = $1`
+	var campaign models.Campaign
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&campaign.ID, &campaign.Name, &campaign.Prefix,
+		&campaign.CodeLength, &campaign.CodeCount, pq.Array(&campaign.FileNames), &campaign.CreatedBy, &campaign.CreatedAt)
+	if err == sql.ErrNoRows {
+		return models.Campaign{}, ErrCampaignNotFound
+	}
+	if err != nil {
+		return models.Campaign{}, fmt.Errorf("error querying campaign: %w", err)
+	}
+	return campaign, nil
+}
+
+// Codes returns the codes generated for a campaign in alphabetical order
+func (r *CampaignRepository) Codes(id string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT code FROM campaign_codes WHERE campaign_id = $1 ORDER BY code`, id)
+	if err != nil {
+		return nil, fmt.Errorf("error querying campaign codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := make([]string, 0)
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("error scanning campaign code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying campaign codes: %w", err)
+	}
+	return codes, nil
+}
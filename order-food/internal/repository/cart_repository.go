@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// MaxCartItems is how many different products a cart can hold
+const MaxCartItems = 100
+
+var (
+	// ErrCartNotFound is returned when a cart does not exist or belongs to
+	// another customer account
+	ErrCartNotFound = errors.New("cart not found")
+	// ErrCartCheckedOut is returned when changing or checking out a cart
+	// that has already been checked out
+	ErrCartCheckedOut = errors.New("cart has already been checked out")
+	// ErrCartChanged is returned when a cart changed while it was being
+	// checked out
+	ErrCartChanged = errors.New("cart changed during checkout")
+	// ErrCartItemNotFound is returned when a product is not in a cart
+	ErrCartItemNotFound = errors.New("product is not in the cart")
+	// ErrCartFull is returned when adding a product to a cart holding
+	// MaxCartItems products
+	ErrCartFull = errors.New("cart is full")
+)
+
+// CartRepository stores shopping carts. Every method taking an accountID
+// only finds carts created by that customer account or without one.
+type CartRepository struct {
+	db *sql.DB
+}
+
+// NewCartRepository creates a new cart repository
+func NewCartRepository(db *sql.DB) *CartRepository {
+	return &CartRepository{db: db}
+}
+
+// Create stores a new cart with its items. ErrProductNotFound is returned
+// when an item's product does not exist.
+func (r *CartRepository) Create(ctx context.Context, cart models.StoredCart) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO carts (id, account_id, coupon_code) VALUES ($1, NULLIF($2, ''), NULLIF($3, ''))`,
+		cart.ID, cart.AccountID, cart.CouponCode)
+	if err != nil {
+		return fmt.Errorf("error inserting cart: %w", err)
+	}
+	for _, item := range cart.Items {
+		if err := addCartItem(ctx, tx, cart.ID, item); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Get returns a cart with the items of products that are not deleted, in
+// the order they were added
+func (r *CartRepository) Get(ctx context.Context, id, accountID string) (models.StoredCart, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	cart := models.StoredCart{ID: id}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COALESCE(account_id, ''), COALESCE(coupon_code, ''), COALESCE(order_id, ''), created_at, updated_at
+		 FROM carts WHERE id = $1 AND (account_id IS NULL OR account_id = $2)`, id, accountID).
+		Scan(&cart.AccountID, &cart.CouponCode, &cart.OrderID, &cart.CreatedAt, &cart.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.StoredCart{}, ErrCartNotFound
+	}
+	if err != nil {
+		return models.StoredCart{}, fmt.Errorf("error querying cart: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT i.product_id, i.quantity FROM cart_items i
+		 JOIN products p ON p.id = i.product_id AND p.deleted_at IS NULL
+		 WHERE i.cart_id = $1
+		 ORDER BY i.added_at, i.product_id`, id)
+	if err != nil {
+		return models.StoredCart{}, fmt.Errorf("error querying cart items: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item models.OrderItem
+		if err := rows.Scan(&item.ProductID, &item.Quantity); err != nil {
+			return models.StoredCart{}, fmt.Errorf("error scanning cart item: %w", err)
+		}
+		cart.Items = append(cart.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return models.StoredCart{}, fmt.Errorf("error querying cart items: %w", err)
+	}
+	return cart, nil
+}
+
+// AddItem puts a product in a cart, adding to its quantity when it is
+// already there. ErrProductNotFound is returned for a product that does
+// not exist and ErrCartFull for a new product in a full cart.
+func (r *CartRepository) AddItem(ctx context.Context, id, accountID string, item models.OrderItem) error {
+	return r.update(ctx, id, accountID, func(ctx context.Context, tx *sql.Tx) error {
+		var count int
+		var present bool
+		err := tx.QueryRowContext(ctx,
+			`SELECT COUNT(*), COALESCE(BOOL_OR(product_id = $2), FALSE) FROM cart_items WHERE cart_id = $1`,
+			id, item.ProductID).Scan(&count, &present)
+		if err != nil {
+			return fmt.Errorf("error counting cart items: %w", err)
+		}
+		if !present && count >= MaxCartItems {
+			return ErrCartFull
+		}
+		return addCartItem(ctx, tx, id, item)
+	})
+}
+
+// SetItemQuantity changes the quantity of a product in a cart, or returns
+// ErrCartItemNotFound
+func (r *CartRepository) SetItemQuantity(ctx context.Context, id, accountID, productID string, quantity int) error {
+	return r.update(ctx, id, accountID, func(ctx context.Context, tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			`UPDATE cart_items SET quantity = $3 WHERE cart_id = $1 AND product_id = $2`,
+			id, productID, quantity)
+		return expectCartItem(result, err)
+	})
+}
+
+// RemoveItem takes a product out of a cart, or returns ErrCartItemNotFound
+func (r *CartRepository) RemoveItem(ctx context.Context, id, accountID, productID string) error {
+	return r.update(ctx, id, accountID, func(ctx context.Context, tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			`DELETE FROM cart_items WHERE cart_id = $1 AND product_id = $2`, id, productID)
+		return expectCartItem(result, err)
+	})
+}
+
+// SetCouponCode sets the promo code of a cart; an empty code removes it
+func (r *CartRepository) SetCouponCode(ctx context.Context, id, accountID, code string) error {
+	return r.update(ctx, id, accountID, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `UPDATE carts SET coupon_code = NULLIF($2, '') WHERE id = $1`, id, code); err != nil {
+			return fmt.Errorf("error updating cart promo code: %w", err)
+		}
+		return nil
+	})
+}
+
+// CheckOut marks a cart as checked out as the order with orderID, if it
+// has not changed since updatedAt. It must be called within the TxManager
+// transaction storing the order. The cart stays locked until the
+// transaction ends, so a concurrent checkout of the same cart waits and
+// then gets ErrCartCheckedOut; ErrCartChanged is returned when the cart
+// was changed after its items were read for the order.
+func (r *CartRepository) CheckOut(ctx context.Context, id, accountID, orderID string, updatedAt time.Time) error {
+	tx, err := txFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	result, err := tx.ExecContext(ctx,
+		`UPDATE carts SET order_id = $3, updated_at = NOW()
+		 WHERE id = $1 AND (account_id IS NULL OR account_id = $2) AND order_id IS NULL AND updated_at = $4`,
+		id, accountID, orderID, updatedAt)
+	if err != nil {
+		return fmt.Errorf("error checking out cart: %w", err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking out cart: %w", err)
+	}
+	if updated > 0 {
+		return nil
+	}
+
+	var checkedOut bool
+	err = tx.QueryRowContext(ctx, `SELECT order_id IS NOT NULL FROM carts WHERE id = $1`, id).Scan(&checkedOut)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrCartNotFound
+	case err != nil:
+		return fmt.Errorf("error checking out cart: %w", err)
+	case checkedOut:
+		return ErrCartCheckedOut
+	}
+	return ErrCartChanged
+}
+
+// DeleteAbandoned deletes carts not changed since before, with their
+// items, and returns how many were deleted
+func (r *CartRepository) DeleteAbandoned(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM carts WHERE updated_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting abandoned carts: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error deleting abandoned carts: %w", err)
+	}
+	return deleted, nil
+}
+
+// update locks an open cart, runs fn in the same transaction and marks
+// the cart as changed. ErrCartNotFound is returned for a cart that does
+// not exist and ErrCartCheckedOut for one that was checked out.
+func (r *CartRepository) update(ctx context.Context, id, accountID string, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var checkedOut bool
+	err = tx.QueryRowContext(ctx,
+		`SELECT order_id IS NOT NULL FROM carts
+		 WHERE id = $1 AND (account_id IS NULL OR account_id = $2) FOR UPDATE`, id, accountID).
+		Scan(&checkedOut)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCartNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error locking cart: %w", err)
+	}
+	if checkedOut {
+		return ErrCartCheckedOut
+	}
+
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE carts SET updated_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("error updating cart: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// addCartItem adds item to the cart with cartID, or returns
+// ErrProductNotFound when its product does not exist or was deleted
+func addCartItem(ctx context.Context, tx *sql.Tx, cartID string, item models.OrderItem) error {
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO cart_items (cart_id, product_id, quantity)
+		 SELECT $1, id, $3 FROM products WHERE id = $2 AND deleted_at IS NULL
+		 ON CONFLICT (cart_id, product_id) DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity`,
+		cartID, item.ProductID, item.Quantity)
+	if err != nil {
+		return fmt.Errorf("error adding cart item: %w", err)
+	}
+	added, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error adding cart item: %w", err)
+	}
+	if added == 0 {
+		return fmt.Errorf("%w: %s", ErrProductNotFound, item.ProductID)
+	}
+	return nil
+}
+
+// expectCartItem returns ErrCartItemNotFound when a statement on one cart
+// item affected none
+func expectCartItem(result sql.Result, err error) error {
+	if err != nil {
+		return fmt.Errorf("error updating cart item: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error updating cart item: %w", err)
+	}
+	if affected == 0 {
+		return ErrCartItemNotFound
+	}
+	return nil
+}
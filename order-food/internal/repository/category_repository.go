@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+var (
+	// ErrCategoryNotFound is returned when a category does not exist, or a
+	// product names a category that does not
+	ErrCategoryNotFound = errors.New("category not found")
+	// ErrCategoryExists is returned when another category has the same ID
+	// or name
+	ErrCategoryExists = errors.New("category already exists")
+	// ErrCategoryInUse is returned when deleting a category products are
+	// still in
+	ErrCategoryInUse = errors.New("category has products")
+)
+
+// categoryColumns is the select list of category queries, counting the
+// products of each category that are not deleted
+const categoryColumns = `c.id, c.name, COALESCE(c.description, ''),
+	(SELECT COUNT(*) FROM products p WHERE p.category_id = c.id AND p.deleted_at IS NULL)`
+
+// CategoryRepository handles category data operations
+type CategoryRepository struct {
+	db      *sql.DB
+	changes ChangeRecorder
+}
+
+// NewCategoryRepository creates a new category repository with an existing
+// database connection
+func NewCategoryRepository(db *sql.DB) *CategoryRepository {
+	return &CategoryRepository{db: db, changes: noChanges{}}
+}
+
+// SetChangeRecorder makes the repository report the products a category
+// rename writes to changes
+func (r *CategoryRepository) SetChangeRecorder(changes ChangeRecorder) {
+	r.changes = changes
+}
+
+// GetAll returns every category ordered by name
+func (r *CategoryRepository) GetAll(ctx context.Context) ([]models.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+categoryColumns+` FROM categories c ORDER BY c.name`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make([]models.Category, 0)
+	for rows.Next() {
+		var category models.Category
+		if err := rows.Scan(&category.ID, &category.Name, &category.Description, &category.ProductCount); err != nil {
+			return nil, fmt.Errorf("error scanning category: %w", err)
+		}
+		categories = append(categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying categories: %w", err)
+	}
+	return categories, nil
+}
+
+// GetByID returns the category with the given ID
+func (r *CategoryRepository) GetByID(ctx context.Context, id string) (models.Category, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var category models.Category
+	err := r.db.QueryRowContext(ctx, `SELECT `+categoryColumns+` FROM categories c WHERE c.id = $1`, id).
+		Scan(&category.ID, &category.Name, &category.Description, &category.ProductCount)
+	if err == sql.ErrNoRows {
+		return models.Category{}, ErrCategoryNotFound
+	}
+	if err != nil {
+		return models.Category{}, fmt.Errorf("error querying category: %w", err)
+	}
+	return category, nil
+}
+
+// Create stores a new category together with the audit entry.
+// ErrCategoryExists is returned when its ID or name is taken.
+func (r *CategoryRepository) Create(ctx context.Context, category models.Category, audit models.AuditEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO categories (id, name, description, created_at, updated_at)
+	                              VALUES ($1, $2, NULLIF($3, ''), NOW(), NOW())`,
+		category.ID, category.Name, category.Description)
+	if isUniqueViolation(err) {
+		return ErrCategoryExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert category: %w", err)
+	}
+	if _, err := insertAuditEntry(ctx, tx, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Update replaces the name and description of a category together with
+// the audit entry, and returns the IDs of the products of a renamed
+// category, whose cached copies are invalidated in the same transaction.
+// Products refer to the category by ID, so they are not written. Promo code
+// discounts scoped to the category are renamed with it, so they keep
+// applying. ErrCategoryNotFound is returned when it does not exist and
+// ErrCategoryExists when another category has the name.
+func (r *CategoryRepository) Update(ctx context.Context, category models.Category, audit models.AuditEntry) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldName string
+	err = tx.QueryRowContext(ctx, `SELECT name FROM categories WHERE id = $1 FOR UPDATE`, category.ID).Scan(&oldName)
+	if err == sql.ErrNoRows {
+		return nil, ErrCategoryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying category: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE categories SET name = $2, description = NULLIF($3, ''), updated_at = NOW() WHERE id = $1`,
+		category.ID, category.Name, category.Description)
+	if isUniqueViolation(err) {
+		return nil, ErrCategoryExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+
+	var renamed []string
+	if category.Name != oldName {
+		rows, err := tx.QueryContext(ctx, `SELECT id FROM products WHERE category_id = $1 ORDER BY id`, category.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error querying products: %w", err)
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("error scanning product: %w", err)
+			}
+			renamed = append(renamed, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error querying products: %w", err)
+		}
+
+		// Discount scopes name categories without a foreign key
+		_, err = tx.ExecContext(ctx, `UPDATE coupon_discounts SET categories = array_replace(categories, $1, $2)
+		                              WHERE $1 = ANY(categories)`, oldName, category.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rename category in promo code discounts: %w", err)
+		}
+	}
+
+	if _, err := insertAuditEntry(ctx, tx, audit); err != nil {
+		return nil, err
+	}
+	if len(renamed) > 0 {
+		if err := publishInTx(ctx, tx, models.InvalidationTopicProducts, renamed); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	if len(renamed) > 0 {
+		r.changes.Record(ChangedTableProducts, renamed...)
+	}
+	return renamed, nil
+}
+
+// Delete removes a category together with the audit entry.
+// ErrCategoryNotFound is returned when it does not exist and
+// ErrCategoryInUse while products, including deleted ones kept for past
+// orders, are in it.
+func (r *CategoryRepository) Delete(ctx context.Context, id string, audit models.AuditEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id)
+	if isForeignKeyViolation(err) {
+		return ErrCategoryInUse
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	if deleted == 0 {
+		return ErrCategoryNotFound
+	}
+	if _, err := insertAuditEntry(ctx, tx, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// isForeignKeyViolation reports whether err is a foreign key violation
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
@@ -0,0 +1,21 @@
+package repository
+
+// Tables whose writes are reported to a ChangeRecorder. A change to an
+// order covers its items.
+const (
+	ChangedTableProducts = "products"
+	ChangedTableOrders   = "orders"
+)
+
+// ChangeRecorder is told the keys of the rows a repository wrote once the
+// write has committed, for example to mirror them to another datastore.
+// Record must not block.
+type ChangeRecorder interface {
+	Record(table string, keys ...string)
+}
+
+// noChanges is the ChangeRecorder of repositories nobody listens to
+type noChanges struct{}
+
+// Record discards the change
+func (noChanges) Record(table string, keys ...string) {}
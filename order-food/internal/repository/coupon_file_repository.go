@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+var (
+	// ErrCouponFileExists is returned when a coupon file with the same name was already uploaded
+	ErrCouponFileExists = errors.New("coupon file already exists")
+	// ErrCouponFileNotFound is returned when a coupon file upload does not exist
+	ErrCouponFileNotFound = errors.New("coupon file upload not found")
+)
+
+// couponFileColumns is the select list shared by coupon file upload queries
+const couponFileColumns = `id, file_name, size_bytes, status, coupons_loaded, COALESCE(error, ''),
+	uploaded_by, COALESCE(operation_id, ''), created_at, updated_at`
+
+// CouponFileRepository tracks uploaded coupon files for the loader
+type CouponFileRepository struct {
+	db *sql.DB
+}
+
+// NewCouponFileRepository creates a new coupon file repository
+func NewCouponFileRepository(db *sql.DB) *CouponFileRepository {
+	return &CouponFileRepository{db: db}
+}
+
+func scanCouponFile(row rowScanner, upload *models.CouponFileUpload) error {
+	return row.Scan(&upload.ID, &upload.FileName, &upload.SizeBytes, &upload.Status, &upload.CouponsLoaded,
+		&upload.Error, &upload.UploadedBy, &upload.OperationID, &upload.CreatedAt, &upload.UpdatedAt)
+}
+
+// Create records an upload in the receiving state, which claims its file
+// name. ErrCouponFileExists is returned when the name is taken.
+func (r *CouponFileRepository) Create(upload *models.CouponFileUpload) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO coupon_file_uploads (id, file_name, status, uploaded_by, operation_id)
+	          VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+	          ON CONFLICT (file_name) DO NOTHING
+	          RETURNING ` + couponFileColumns
+	err := scanCouponFile(r.db.QueryRowContext(ctx, query,
+		upload.ID, upload.FileName, models.CouponFileStatusReceiving, upload.UploadedBy, upload.OperationID), upload)
+	if err == sql.ErrNoRows {
+		return ErrCouponFileExists
+	}
+	if err != nil {
+		return fmt.Errorf("error inserting coupon file upload: %w", err)
+	}
+	return nil
+}
+
+// MarkPending records that the file of an upload is complete, which hands
+// it to the loader
+func (r *CouponFileRepository) MarkPending(id string, sizeBytes int64) (models.CouponFileUpload, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE coupon_file_uploads
+	          SET status = $2, size_bytes = $3, updated_at = NOW()
+	          WHERE id = $1
+	          RETURNING ` + couponFileColumns
+	var upload models.CouponFileUpload
+	err := scanCouponFile(r.db.QueryRowContext(ctx, query, id, models.CouponFileStatusPending, sizeBytes), &upload)
+	if err == sql.ErrNoRows {
+		return models.CouponFileUpload{}, ErrCouponFileNotFound
+	}
+	if err != nil {
+		return models.CouponFileUpload{}, fmt.Errorf("error updating coupon file upload: %w", err)
+	}
+	return upload, nil
+}
+
+// Delete forgets an upload whose file could not be received, releasing its
+// file name
+func (r *CouponFileRepository) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM coupon_file_uploads WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("error deleting coupon file upload: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a coupon file upload by ID
+func (r *CouponFileRepository) GetByID(id string) (models.CouponFileUpload, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + couponFileColumns + ` FROM coupon_file_uploads WHERE id = $1`
+	var upload models.CouponFileUpload
+	err := scanCouponFile(r.db.QueryRowContext(ctx, query, id), &upload)
+	if err == sql.ErrNoRows {
+		return models.CouponFileUpload{}, ErrCouponFileNotFound
+	}
+	if err != nil {
+		return models.CouponFileUpload{}, fmt.Errorf("error querying coupon file upload: %w", err)
+	}
+	return upload, nil
+}
+
+// GetAll returns coupon file uploads with pagination, newest first
+func (r *CouponFileRepository) GetAll(limit, offset int) ([]models.CouponFileUpload, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM coupon_file_uploads`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error counting coupon file uploads: %w", err)
+	}
+
+	query := `SELECT ` + couponFileColumns + ` FROM coupon_file_uploads
+	          ORDER BY created_at DESC, id
+	          LIMIT $1 OFFSET $2`
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying coupon file uploads: %w", err)
+	}
+	defer rows.Close()
+
+	uploads := make([]models.CouponFileUpload, 0)
+	for rows.Next() {
+		var upload models.CouponFileUpload
+		if err := scanCouponFile(rows, &upload); err != nil {
+			return nil, 0, fmt.Errorf("error scanning coupon file upload: %w", err)
+		}
+		uploads = append(uploads, upload)
+	}
+
+	return uploads, total, rows.Err()
+}
+
+// GetByOperation returns the coupon file uploads of an operation, oldest first
+func (r *CouponFileRepository) GetByOperation(operationID string) ([]models.CouponFileUpload, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + couponFileColumns + ` FROM coupon_file_uploads
+	          WHERE operation_id = $1
+	          ORDER BY created_at, id`
+	rows, err := r.db.QueryContext(ctx, query, operationID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying coupon file uploads: %w", err)
+	}
+	defer rows.Close()
+
+	uploads := make([]models.CouponFileUpload, 0)
+	for rows.Next() {
+		var upload models.CouponFileUpload
+		if err := scanCouponFile(rows, &upload); err != nil {
+			return nil, fmt.Errorf("error scanning coupon file upload: %w", err)
+		}
+		uploads = append(uploads, upload)
+	}
+
+	return uploads, rows.Err()
+}
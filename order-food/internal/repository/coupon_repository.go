@@ -0,0 +1,412 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+var (
+	// ErrDiscountNotFound is returned when a promo code has no discount
+	ErrDiscountNotFound = errors.New("promo code discount not found")
+	// ErrPromoCodeExhausted is returned when a promo code has been used as
+	// many times as its limit allows
+	ErrPromoCodeExhausted = errors.New("promo code usage limit reached")
+	// ErrPromoCodeRedeemed is returned when a customer has already used a
+	// promo code limited to one use per customer
+	ErrPromoCodeRedeemed = errors.New("promo code already used by this customer")
+	// ErrCustomerRequired is returned when an order without a customer ID
+	// uses a promo code limited to one use per customer
+	ErrCustomerRequired = errors.New("promo code requires a customer ID")
+)
+
+// CouponRepository aggregates the recorded redemptions of promo codes and
+// keeps the discounts and usage limits of promo codes
+type CouponRepository struct {
+	db *sql.DB
+}
+
+// NewCouponRepository creates a new coupon repository
+func NewCouponRepository(db *sql.DB) *CouponRepository {
+	return &CouponRepository{db: db}
+}
+
+// RedemptionSummary returns how many orders were placed in [from, to), how
+// many promo codes were redeemed and the discount they gave. Redemptions
+// are counted from their records, which outlive archived orders.
+func (r *CouponRepository) RedemptionSummary(ctx context.Context, from, to time.Time) (orders, redemptions int, discountTotal float64, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `SELECT (SELECT COUNT(*) FROM orders WHERE created_at >= $1 AND created_at < $2),
+	                 COUNT(*),
+	                 COALESCE(SUM(discount), 0)
+	          FROM coupon_redemptions
+	          WHERE redeemed_at >= $1 AND redeemed_at < $2`
+	if err := r.db.QueryRowContext(ctx, query, from, to).Scan(&orders, &redemptions, &discountTotal); err != nil {
+		return 0, 0, 0, fmt.Errorf("error querying coupon redemption summary: %w", err)
+	}
+	return orders, redemptions, discountTotal, nil
+}
+
+// RedemptionsByCode returns the limit most redeemed codes in [from, to)
+func (r *CouponRepository) RedemptionsByCode(ctx context.Context, from, to time.Time, limit int) ([]models.CouponCodeStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `SELECT coupon, COUNT(*), COALESCE(SUM(discount), 0)
+	          FROM coupon_redemptions
+	          WHERE redeemed_at >= $1 AND redeemed_at < $2
+	          GROUP BY coupon
+	          ORDER BY COUNT(*) DESC, coupon
+	          LIMIT $3`
+	rows, err := r.db.QueryContext(ctx, query, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying coupon redemptions by code: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]models.CouponCodeStats, 0)
+	for rows.Next() {
+		var s models.CouponCodeStats
+		if err := rows.Scan(&s.Code, &s.Redemptions, &s.DiscountTotal); err != nil {
+			return nil, fmt.Errorf("error scanning coupon redemptions by code: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying coupon redemptions by code: %w", err)
+	}
+	return stats, nil
+}
+
+// RedemptionsByFile returns the limit coupon files whose codes were redeemed
+// most in [from, to). A file may list a code more than once, in different
+// cases from before codes were stored in upper case, so each redemption is
+// paired with each file listing its code only once.
+func (r *CouponRepository) RedemptionsByFile(ctx context.Context, from, to time.Time, limit int) ([]models.CouponFileStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `SELECT file_name, COUNT(DISTINCT coupon), COUNT(*), COALESCE(SUM(discount), 0)
+	          FROM (SELECT DISTINCT c.file_name, r.id, r.coupon, r.discount
+	                FROM coupon_redemptions r
+	                JOIN coupons c ON upper(c.coupon) = r.coupon
+	                WHERE r.redeemed_at >= $1 AND r.redeemed_at < $2) redeemed
+	          GROUP BY file_name
+	          ORDER BY COUNT(*) DESC, file_name
+	          LIMIT $3`
+	rows, err := r.db.QueryContext(ctx, query, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying coupon redemptions by file: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]models.CouponFileStats, 0)
+	for rows.Next() {
+		var s models.CouponFileStats
+		if err := rows.Scan(&s.FileName, &s.RedeemedCodes, &s.Redemptions, &s.DiscountTotal); err != nil {
+			return nil, fmt.Errorf("error scanning coupon redemptions by file: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying coupon redemptions by file: %w", err)
+	}
+	return stats, nil
+}
+
+// RedemptionsByDay returns order and redemption counts for each day in
+// [from, to) that had orders or redemptions, with days starting at midnight
+// in zone
+func (r *CouponRepository) RedemptionsByDay(ctx context.Context, from, to time.Time, zone string) ([]models.CouponDayStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	query := `SELECT day, COALESCE(o.orders, 0), COALESCE(r.redemptions, 0)
+	          FROM (SELECT to_char(created_at AT TIME ZONE $3, 'YYYY-MM-DD') AS day, COUNT(*) AS orders
+	                FROM orders
+	                WHERE created_at >= $1 AND created_at < $2
+	                GROUP BY day) o
+	          FULL JOIN (SELECT to_char(redeemed_at AT TIME ZONE $3, 'YYYY-MM-DD') AS day, COUNT(*) AS redemptions
+	                     FROM coupon_redemptions
+	                     WHERE redeemed_at >= $1 AND redeemed_at < $2
+	                     GROUP BY day) r USING (day)
+	          ORDER BY day`
+	rows, err := r.db.QueryContext(ctx, query, from, to, zone)
+	if err != nil {
+		return nil, fmt.Errorf("error querying coupon redemptions by day: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make([]models.CouponDayStats, 0)
+	for rows.Next() {
+		var s models.CouponDayStats
+		if err := rows.Scan(&s.Date, &s.Orders, &s.Redemptions); err != nil {
+			return nil, fmt.Errorf("error scanning coupon redemptions by day: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying coupon redemptions by day: %w", err)
+	}
+	return stats, nil
+}
+
+// SetDiscount creates or replaces the discount of a promo code, recording
+// audit in the same transaction
+func (r *CouponRepository) SetDiscount(code string, discount models.Discount, audit models.AuditEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO coupon_discounts (coupon, discount_type, discount_value, categories, product_ids, updated_by, updated_at)
+	          VALUES ($1, $2, $3, COALESCE($4, '{}'::text[]), COALESCE($5, '{}'::text[]), $6, NOW())
+	          ON CONFLICT (coupon) DO UPDATE
+	          SET discount_type = EXCLUDED.discount_type,
+	              discount_value = EXCLUDED.discount_value,
+	              categories = EXCLUDED.categories,
+	              product_ids = EXCLUDED.product_ids,
+	              updated_by = EXCLUDED.updated_by,
+	              updated_at = EXCLUDED.updated_at`
+	if _, err := tx.ExecContext(ctx, query, code, discount.Type, discount.Value,
+		pq.Array(discount.Categories), pq.Array(discount.ProductIDs), audit.Actor); err != nil {
+		return fmt.Errorf("error setting promo code discount: %w", err)
+	}
+	if _, err := insertAuditEntry(ctx, tx, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DeleteDiscount removes the discount of a promo code, recording audit in
+// the same transaction. ErrDiscountNotFound is returned when it had none.
+func (r *CouponRepository) DeleteDiscount(code string, audit models.AuditEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM coupon_discounts WHERE coupon = $1`, code)
+	if err != nil {
+		return fmt.Errorf("error deleting promo code discount: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting promo code discount: %w", err)
+	}
+	if deleted == 0 {
+		return ErrDiscountNotFound
+	}
+	if _, err := insertAuditEntry(ctx, tx, audit); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Redeem records that the order with orderID used code, taking discount
+// off it, and enforces the code's limits. It must be called within a TxManager transaction, so the
+// use only counts if the order is stored. The use is counted by a single
+// UPDATE that only matches while the code has uses left, so the database
+// itself refuses a use beyond the limit, whatever else the transaction
+// does. The code's limits row then stays locked until the transaction
+// ends, which serialises the orders that use a limited code: an order
+// waiting for the lock counts against the total the previous one
+// committed, and sees its redemption when checking the customer.
+func (r *CouponRepository) Redeem(ctx context.Context, code, orderID, customerID string, discount float64) error {
+	tx, err := txFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var counted, oncePerCustomer bool
+	err = tx.QueryRowContext(ctx,
+		`WITH counted AS (
+		     UPDATE coupon_limits SET redemptions = redemptions + 1
+		     WHERE coupon = $1 AND (max_redemptions IS NULL OR redemptions < max_redemptions)
+		     RETURNING coupon)
+		 SELECT EXISTS (SELECT 1 FROM counted), once_per_customer FROM coupon_limits WHERE coupon = $1`, code).
+		Scan(&counted, &oncePerCustomer)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		// The code has no limits
+	case err != nil:
+		return fmt.Errorf("error counting promo code redemption: %w", err)
+	case !counted:
+		return ErrPromoCodeExhausted
+	case oncePerCustomer:
+		if customerID == "" {
+			return ErrCustomerRequired
+		}
+		var used bool
+		err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM coupon_redemptions WHERE coupon = $1 AND customer_id = $2)`,
+			code, customerID).Scan(&used)
+		if err != nil {
+			return fmt.Errorf("error checking promo code redemptions: %w", err)
+		}
+		if used {
+			return ErrPromoCodeRedeemed
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO coupon_redemptions (coupon, order_id, customer_id, discount) VALUES ($1, $2, NULLIF($3, ''), $4)`,
+		code, orderID, customerID, discount)
+	if err != nil {
+		return fmt.Errorf("error recording promo code redemption: %w", err)
+	}
+	return nil
+}
+
+// GetLimits returns the usage limits of a promo code and how many orders
+// have used it. A code without limits has zero values.
+func (r *CouponRepository) GetLimits(code string) (models.PromoCodeLimits, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT COALESCE((SELECT max_redemptions FROM coupon_limits WHERE coupon = $1), 0),
+	                 COALESCE((SELECT once_per_customer FROM coupon_limits WHERE coupon = $1), FALSE),
+	                 (SELECT COUNT(*) FROM coupon_redemptions WHERE coupon = $1)`
+	var limits models.PromoCodeLimits
+	err := r.db.QueryRowContext(ctx, query, code).Scan(&limits.MaxRedemptions, &limits.OncePerCustomer, &limits.Redemptions)
+	if err != nil {
+		return models.PromoCodeLimits{}, fmt.Errorf("error querying promo code limits: %w", err)
+	}
+	return limits, nil
+}
+
+// SetLimits creates or replaces the usage limits of a promo code, recording
+// audit in the same transaction, and returns how many orders have used it.
+// Uses made before the code had limits count towards them.
+func (r *CouponRepository) SetLimits(code string, limits models.PromoCodeLimits, audit models.AuditEntry) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO coupon_limits (coupon, max_redemptions, once_per_customer, redemptions, updated_by, updated_at)
+	          VALUES ($1, NULLIF($2, 0), $3, (SELECT COUNT(*) FROM coupon_redemptions WHERE coupon = $1), $4, NOW())
+	          ON CONFLICT (coupon) DO UPDATE
+	          SET max_redemptions = EXCLUDED.max_redemptions,
+	              once_per_customer = EXCLUDED.once_per_customer,
+	              updated_by = EXCLUDED.updated_by,
+	              updated_at = EXCLUDED.updated_at
+	          RETURNING redemptions`
+	var redemptions int
+	err = tx.QueryRowContext(ctx, query, code, limits.MaxRedemptions, limits.OncePerCustomer, audit.Actor).Scan(&redemptions)
+	if err != nil {
+		return 0, fmt.Errorf("error setting promo code limits: %w", err)
+	}
+	if _, err := insertAuditEntry(ctx, tx, audit); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return redemptions, nil
+}
+
+// EstimateValidCodes returns roughly how many promo codes appear in at
+// least minFiles files. Once the valid_coupons view has been refreshed it
+// is counted, which is exact as of the refresh for minFiles of 2 or more.
+// Otherwise the estimate comes from the planner's row estimate of the
+// coupons table: at most the rows divided by minFiles. The rows are
+// counted when the table has never been analysed.
+func (r *CouponRepository) EstimateValidCodes(ctx context.Context, minFiles int) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if minFiles >= 2 {
+		populated, err := matviewPopulated(ctx, r.db, ValidCouponsView)
+		if err != nil {
+			return 0, err
+		}
+		if populated {
+			var codes int
+			err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM valid_coupons WHERE files >= $1`, minFiles).Scan(&codes)
+			if err != nil {
+				return 0, fmt.Errorf("error counting valid promo codes: %w", err)
+			}
+			return codes, nil
+		}
+	}
+
+	var rows int64
+	err := r.db.QueryRowContext(ctx, `SELECT reltuples::bigint FROM pg_class WHERE oid = 'coupons'::regclass`).Scan(&rows)
+	if err != nil {
+		return 0, fmt.Errorf("error estimating coupon rows: %w", err)
+	}
+	if rows < 0 {
+		if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM coupons`).Scan(&rows); err != nil {
+			return 0, fmt.Errorf("error counting coupon rows: %w", err)
+		}
+	}
+	return int(rows / int64(max(minFiles, 1))), nil
+}
+
+// EachValidCode calls fn with every code that appears in at least minFiles
+// coupon files, in upper case. The codes are streamed, so the whole set is
+// never held in memory.
+func (r *CouponRepository) EachValidCode(ctx context.Context, minFiles int, fn func(code string)) error {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT upper(coupon) FROM coupons GROUP BY upper(coupon) HAVING COUNT(DISTINCT file_name) >= $1`, minFiles)
+	if err != nil {
+		return fmt.Errorf("error querying valid promo codes: %w", err)
+	}
+	return eachCode(rows, fn)
+}
+
+// EachCampaignCode calls fn with every code generated for the campaign, or
+// for every campaign when campaignID is empty
+func (r *CouponRepository) EachCampaignCode(ctx context.Context, campaignID string, fn func(code string)) error {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT code FROM campaign_codes WHERE $1 = '' OR campaign_id::text = $1`, campaignID)
+	if err != nil {
+		return fmt.Errorf("error querying campaign codes: %w", err)
+	}
+	return eachCode(rows, fn)
+}
+
+// eachCode calls fn with the code in every row and closes rows
+func eachCode(rows *sql.Rows, fn func(code string)) error {
+	defer rows.Close()
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return fmt.Errorf("error scanning promo code: %w", err)
+		}
+		fn(code)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating promo codes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// ErrInvalidCursor is returned for a cursor that is malformed or was issued
+// for another sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// streamBatchSize is how many rows a streamed listing reads per keyset page
+const streamBatchSize = 500
+
+// keysetTerm is a column rows are ordered by in keyset pagination
+type keysetTerm struct {
+	column string
+	desc   bool
+}
+
+// cursor is the content of an opaque cursor token: the ordering it was
+// issued for and the key of the last row of the page it ends
+type cursor struct {
+	Order  string          `json:"o"`
+	Values json.RawMessage `json:"v"`
+}
+
+// keysetTerms returns the columns rows are ordered by for sort fields, like
+// orderByClause, ending with the primary key so every row has a distinct
+// position. The primary key follows the direction of the last field, so a
+// sort in a single direction can be resumed with a row comparison.
+func keysetTerms(sort []models.SortField, columns map[string]string, fallback []keysetTerm) []keysetTerm {
+	terms := make([]keysetTerm, 0, len(sort)+1)
+	for _, f := range sort {
+		column, ok := columns[f.Field]
+		if !ok {
+			continue
+		}
+		terms = append(terms, keysetTerm{column: column, desc: f.Desc})
+		if column == "id" {
+			return terms
+		}
+	}
+	if len(terms) == 0 {
+		return fallback
+	}
+	return append(terms, keysetTerm{column: "id", desc: terms[len(terms)-1].desc})
+}
+
+// keysetOrderBy renders terms as an ORDER BY expression
+func keysetOrderBy(terms []keysetTerm) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		parts[i] = t.column + " ASC"
+		if t.desc {
+			parts[i] = t.column + " DESC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// keysetKey selects the values of terms of a row as a JSON array, which
+// becomes the cursor of a page ending with that row
+func keysetKey(terms []keysetTerm) string {
+	columns := make([]string, len(terms))
+	for i, t := range terms {
+		columns[i] = t.column
+	}
+	return "json_build_array(" + strings.Join(columns, ", ") + ")::text"
+}
+
+// encodeCursor returns the opaque token of the position after the row whose
+// keysetKey is key
+func encodeCursor(terms []keysetTerm, key string) string {
+	data, _ := json.Marshal(cursor{Order: keysetOrderBy(terms), Values: json.RawMessage(key)})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns the key values of an opaque token, which must have
+// been issued for terms
+func decodeCursor(token string, terms []keysetTerm) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Order != keysetOrderBy(terms) {
+		return nil, ErrInvalidCursor
+	}
+	var values []any
+	if err := json.Unmarshal(c.Values, &values); err != nil || len(values) != len(terms) {
+		return nil, ErrInvalidCursor
+	}
+	for _, value := range values {
+		switch value.(type) {
+		case string, float64:
+		default:
+			return nil, ErrInvalidCursor
+		}
+	}
+	return values, nil
+}
+
+// keysetCondition builds a condition selecting the rows after the position
+// with values, numbering arguments from start. A single direction is
+// compared as a row; mixed directions expand to one alternative per term.
+func keysetCondition(terms []keysetTerm, values []any, start int) (string, []any) {
+	placeholders := make([]string, len(terms))
+	for i := range terms {
+		placeholders[i] = fmt.Sprintf("$%d", start+i)
+	}
+	comparison := func(t keysetTerm) string {
+		if t.desc {
+			return "<"
+		}
+		return ">"
+	}
+
+	mixed := false
+	for _, t := range terms[1:] {
+		mixed = mixed || t.desc != terms[0].desc
+	}
+	if !mixed {
+		columns := make([]string, len(terms))
+		for i, t := range terms {
+			columns[i] = t.column
+		}
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), comparison(terms[0]), strings.Join(placeholders, ", ")), values
+	}
+
+	alternatives := make([]string, len(terms))
+	for i, t := range terms {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s = %s", terms[j].column, placeholders[j]))
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", t.column, comparison(t), placeholders[i]))
+		alternatives[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")", values
+}
+
+// keyScanner scans a row selected with its keysetKey as the last column
+type keyScanner struct {
+	row rowScanner
+	key *string
+}
+
+// Scan scans the row's columns into dest and its key into the scanner's key
+func (s keyScanner) Scan(dest ...any) error {
+	return s.row.Scan(append(dest, s.key)...)
+}
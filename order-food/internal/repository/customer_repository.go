@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/pii"
+)
+
+var (
+	// ErrCustomerNotFound is returned when a customer does not exist
+	ErrCustomerNotFound = errors.New("customer not found")
+	// ErrDuplicateEmail is returned when a customer already has the email
+	ErrDuplicateEmail = errors.New("a customer with this email already exists")
+)
+
+// customerBackfillBatchSize is how many customers are read per query while
+// sealing or re-encrypting customer rows
+const customerBackfillBatchSize = 500
+
+// CustomerRepository handles customer account data operations. Emails and
+// names are stored sealed with codec; customers are found by email through
+// email_hash, the blind index of the lowercased email.
+type CustomerRepository struct {
+	db    *sql.DB
+	codec pii.Codec
+	index *pii.BlindIndex
+}
+
+// NewCustomerRepository creates a new customer repository. A nil codec
+// stores emails and names in plaintext, and a nil index hashes emails
+// without a key.
+func NewCustomerRepository(db *sql.DB, codec pii.Codec, index *pii.BlindIndex) *CustomerRepository {
+	if codec == nil {
+		codec = pii.Plaintext{}
+	}
+	if index == nil {
+		index = pii.NewBlindIndex(nil)
+	}
+	return &CustomerRepository{db: db, codec: codec, index: index}
+}
+
+// customerColumns is the select list shared by customer queries
+const customerColumns = `id, email, name, created_at`
+
+// scanCustomer scans customerColumns, followed by extra, and opens the
+// sealed email and name
+func (r *CustomerRepository) scanCustomer(row rowScanner, customer *models.Customer, extra ...any) error {
+	var email, name string
+	if err := row.Scan(append([]any{&customer.ID, &email, &name, &customer.CreatedAt}, extra...)...); err != nil {
+		return err
+	}
+
+	var err error
+	if customer.Email, err = r.codec.Decode(email); err != nil {
+		return fmt.Errorf("failed to decrypt email of customer %s: %w", customer.ID, err)
+	}
+	if customer.Name, err = r.codec.Decode(name); err != nil {
+		return fmt.Errorf("failed to decrypt name of customer %s: %w", customer.ID, err)
+	}
+	return nil
+}
+
+// seal returns the stored form of a customer's email and name
+func (r *CustomerRepository) seal(email, name string) (string, string, error) {
+	sealedEmail, err := r.codec.Encode(email)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt email: %w", err)
+	}
+	sealedName, err := r.codec.Encode(name)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt name: %w", err)
+	}
+	return sealedEmail, sealedName, nil
+}
+
+// Create stores a new customer with the hash of its password. The email is
+// also refused while a customer with it has not been given an email_hash
+// yet, see BackfillEmailHashes.
+func (r *CustomerRepository) Create(customer *models.Customer, passwordHash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	email, name, err := r.seal(customer.Email, customer.Name)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO customers (id, email, name, email_hash, password_hash, created_at, updated_at)
+	          SELECT $1, $2, $3, $4, $5, NOW(), NOW()
+	          WHERE NOT EXISTS (SELECT 1 FROM customers WHERE email_hash IS NULL AND email = $6)
+	          RETURNING created_at`
+	err = r.db.QueryRowContext(ctx, query, customer.ID, email, name, r.index.Hash(customer.Email), passwordHash, customer.Email).
+		Scan(&customer.CreatedAt)
+	if err == sql.ErrNoRows || isUniqueViolation(err) {
+		return ErrDuplicateEmail
+	}
+	if err != nil {
+		return fmt.Errorf("failed to insert customer: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID returns a customer by ID
+func (r *CustomerRepository) GetByID(id string) (models.Customer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + customerColumns + ` FROM customers WHERE id = $1`
+	var customer models.Customer
+	err := r.scanCustomer(r.db.QueryRowContext(ctx, query, id), &customer)
+	if err == sql.ErrNoRows {
+		return models.Customer{}, ErrCustomerNotFound
+	}
+	if err != nil {
+		return models.Customer{}, fmt.Errorf("error querying customer: %w", err)
+	}
+
+	return customer, nil
+}
+
+// FindByEmail returns the customer with a lowercased email and the hash of
+// its password. Customers are found by the blind index of the email, or by
+// the plaintext email while they have not been given one yet.
+func (r *CustomerRepository) FindByEmail(email string) (models.Customer, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + customerColumns + `, password_hash FROM customers
+	          WHERE email_hash = $1 OR (email_hash IS NULL AND email = $2)`
+	var customer models.Customer
+	var passwordHash string
+	err := r.scanCustomer(r.db.QueryRowContext(ctx, query, r.index.Hash(email), email), &customer, &passwordHash)
+	if err == sql.ErrNoRows {
+		return models.Customer{}, "", ErrCustomerNotFound
+	}
+	if err != nil {
+		return models.Customer{}, "", fmt.Errorf("error querying customer: %w", err)
+	}
+
+	return customer, passwordHash, nil
+}
+
+// storedCustomer is the stored form of a customer's personal data
+type storedCustomer struct {
+	id, email, name string
+	emailHash       sql.NullString
+}
+
+// BackfillEmailHashes seals the email and name of every customer stored
+// before they were encrypted and gives it an email_hash. Customers are
+// read in batches, and replicas running it at once skip each other's rows.
+// It returns the number of customers rewritten.
+func (r *CustomerRepository) BackfillEmailHashes() (int, error) {
+	rewritten := 0
+	after := ""
+	for {
+		pending, err := r.storedCustomers(after, true)
+		if err != nil {
+			return rewritten, err
+		}
+		for _, stored := range pending {
+			after = stored.id
+			ok, err := r.rewriteCustomer(stored)
+			if err != nil {
+				return rewritten, err
+			}
+			if ok {
+				rewritten++
+			}
+		}
+		if len(pending) < customerBackfillBatchSize {
+			return rewritten, nil
+		}
+	}
+}
+
+// ReencryptCustomers rewrites every customer whose email or name is
+// plaintext or sealed with an old key of cipher, or whose email_hash was
+// computed with another blind index key. It returns the number of
+// customers rewritten.
+func (r *CustomerRepository) ReencryptCustomers(cipher *pii.Cipher) (int, error) {
+	rewritten := 0
+	after := ""
+	for {
+		batch, err := r.storedCustomers(after, false)
+		if err != nil {
+			return rewritten, err
+		}
+		for _, stored := range batch {
+			after = stored.id
+			email, err := cipher.Decode(stored.email)
+			if err != nil {
+				return rewritten, fmt.Errorf("failed to decrypt email of customer %s: %w", stored.id, err)
+			}
+			if !cipher.NeedsRotation(stored.email) && !cipher.NeedsRotation(stored.name) &&
+				stored.emailHash.String == r.index.Hash(email) {
+				continue
+			}
+			ok, err := r.rewriteCustomer(stored)
+			if err != nil {
+				return rewritten, err
+			}
+			if ok {
+				rewritten++
+			}
+		}
+		if len(batch) < customerBackfillBatchSize {
+			return rewritten, nil
+		}
+	}
+}
+
+// storedCustomers returns a batch of the stored customers following the ID
+// after, in ID order. pendingOnly limits them to customers without an
+// email_hash.
+func (r *CustomerRepository) storedCustomers(after string, pendingOnly bool) ([]storedCustomer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	query := `SELECT id, email, name, email_hash FROM customers WHERE id > $1`
+	if pendingOnly {
+		query += ` AND email_hash IS NULL`
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY id LIMIT $2`, after, customerBackfillBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("error querying customers: %w", err)
+	}
+	defer rows.Close()
+
+	var customers []storedCustomer
+	for rows.Next() {
+		var stored storedCustomer
+		if err := rows.Scan(&stored.id, &stored.email, &stored.name, &stored.emailHash); err != nil {
+			return nil, fmt.Errorf("error scanning customer: %w", err)
+		}
+		customers = append(customers, stored)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying customers: %w", err)
+	}
+	return customers, nil
+}
+
+// rewriteCustomer seals the email and name of a stored customer with the
+// current key, sets its email_hash and reports whether the row was
+// rewritten
+func (r *CustomerRepository) rewriteCustomer(stored storedCustomer) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	email, err := r.codec.Decode(stored.email)
+	if err != nil {
+		return false, fmt.Errorf("failed to decrypt email of customer %s: %w", stored.id, err)
+	}
+	name, err := r.codec.Decode(stored.name)
+	if err != nil {
+		return false, fmt.Errorf("failed to decrypt name of customer %s: %w", stored.id, err)
+	}
+	sealedEmail, sealedName, err := r.seal(email, name)
+	if err != nil {
+		return false, err
+	}
+
+	// Only overwrite the values that were read, in case they changed meanwhile
+	result, err := r.db.ExecContext(ctx, `UPDATE customers
+	                                      SET email = $2, name = $3, email_hash = $4, updated_at = NOW()
+	                                      WHERE id = $1 AND email = $5 AND name = $6 AND email_hash IS NOT DISTINCT FROM $7`,
+		stored.id, sealedEmail, sealedName, r.index.Hash(email), stored.email, stored.name, stored.emailHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to update customer %s: %w", stored.id, err)
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
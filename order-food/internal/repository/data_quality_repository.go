@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// dataQualityQueries find the rows of each data quality check. Each returns
+// an identifier of up to $1 rows with the number of rows found.
+var dataQualityQueries = map[string]string{
+	// The foreign keys of order_items prevent these, but restores and
+	// loads run with session_replication_role = replica skip them
+	models.DataQualityOrphanedOrderItems: `SELECT oi.order_id || '/' || oi.product_id, COUNT(*) OVER ()
+		FROM order_items oi
+		LEFT JOIN orders o ON o.id = oi.order_id
+		LEFT JOIN products p ON p.id = oi.product_id
+		WHERE o.id IS NULL OR p.id IS NULL
+		ORDER BY oi.id
+		LIMIT $1`,
+	models.DataQualityZeroPriceProducts: `SELECT id, COUNT(*) OVER ()
+		FROM products
+		WHERE price = 0 AND deleted_at IS NULL
+		ORDER BY id
+		LIMIT $1`,
+	// Codes match case-insensitively, like promo code lookups, which
+	// idx_coupons_upper_coupon serves
+	models.DataQualitySingleFileCoupons: `SELECT upper(coupon), COUNT(*) OVER ()
+		FROM coupons
+		GROUP BY upper(coupon)
+		HAVING COUNT(DISTINCT file_name) = 1
+		ORDER BY upper(coupon)
+		LIMIT $1`,
+	models.DataQualityLoadManifestGaps: `SELECT u.file_name, COUNT(*) OVER ()
+		FROM coupon_file_uploads u
+		WHERE u.status = 'loaded'
+		  AND u.coupons_loaded > (SELECT COUNT(*) FROM coupons c WHERE c.file_name = u.file_name)
+		ORDER BY u.file_name
+		LIMIT $1`,
+}
+
+// DataQualityRepository runs the data quality checks and stores their
+// findings
+type DataQualityRepository struct {
+	db *sql.DB
+}
+
+// NewDataQualityRepository creates a new data quality repository
+func NewDataQualityRepository(db *sql.DB) *DataQualityRepository {
+	return &DataQualityRepository{db: db}
+}
+
+// RunCheck runs a data quality check, returning how many rows it found and
+// identifiers of up to limit of them
+func (r *DataQualityRepository) RunCheck(ctx context.Context, check string, limit int) (int64, []string, error) {
+	query, ok := dataQualityQueries[check]
+	if !ok {
+		return 0, nil, fmt.Errorf("unknown data quality check %q", check)
+	}
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error running data quality check %s: %w", check, err)
+	}
+	defer rows.Close()
+
+	var count int64
+	samples := []string{}
+	for rows.Next() {
+		var sample string
+		if err := rows.Scan(&sample, &count); err != nil {
+			return 0, nil, fmt.Errorf("error scanning data quality check %s: %w", check, err)
+		}
+		samples = append(samples, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("error running data quality check %s: %w", check, err)
+	}
+	return count, samples, nil
+}
+
+// SaveFinding stores the result of a successful check run
+func (r *DataQualityRepository) SaveFinding(ctx context.Context, finding models.DataQualityFinding, checkedAt time.Time) error {
+	samples, err := json.Marshal(finding.Samples)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO data_quality_findings (check_name, count, samples, error, duration_ms, checked_at)
+	          VALUES ($1, $2, $3, '', $4, $5)
+	          ON CONFLICT (check_name) DO UPDATE
+	          SET count = EXCLUDED.count, samples = EXCLUDED.samples, error = '',
+	              duration_ms = EXCLUDED.duration_ms, checked_at = EXCLUDED.checked_at`
+	if _, err := r.db.ExecContext(ctx, query, finding.Check, finding.Count, samples, finding.DurationMs, checkedAt); err != nil {
+		return fmt.Errorf("error saving data quality finding: %w", err)
+	}
+	return nil
+}
+
+// SaveFailure records a failed check run, keeping the findings of the last
+// successful one
+func (r *DataQualityRepository) SaveFailure(ctx context.Context, check, checkErr string, durationMs int64, checkedAt time.Time) error {
+	query := `INSERT INTO data_quality_findings (check_name, error, duration_ms, checked_at)
+	          VALUES ($1, $2, $3, $4)
+	          ON CONFLICT (check_name) DO UPDATE
+	          SET error = EXCLUDED.error, duration_ms = EXCLUDED.duration_ms, checked_at = EXCLUDED.checked_at`
+	if _, err := r.db.ExecContext(ctx, query, check, checkErr, durationMs, checkedAt); err != nil {
+		return fmt.Errorf("error saving data quality failure: %w", err)
+	}
+	return nil
+}
+
+// ListFindings returns the stored findings keyed by check
+func (r *DataQualityRepository) ListFindings(ctx context.Context) (map[string]models.DataQualityFinding, error) {
+	query := `SELECT check_name, count, samples, error, duration_ms, checked_at FROM data_quality_findings`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying data quality findings: %w", err)
+	}
+	defer rows.Close()
+
+	findings := make(map[string]models.DataQualityFinding)
+	for rows.Next() {
+		var finding models.DataQualityFinding
+		var samples []byte
+		var checkedAt time.Time
+		if err := rows.Scan(&finding.Check, &finding.Count, &samples, &finding.Error, &finding.DurationMs, &checkedAt); err != nil {
+			return nil, fmt.Errorf("error scanning data quality finding: %w", err)
+		}
+		if err := json.Unmarshal(samples, &finding.Samples); err != nil {
+			return nil, fmt.Errorf("error decoding samples of data quality check %s: %w", finding.Check, err)
+		}
+		finding.CheckedAt = &checkedAt
+		findings[finding.Check] = finding
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying data quality findings: %w", err)
+	}
+	return findings, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// IdempotencyRepository stores the responses sent for Idempotency-Key
+// headers so retried requests can be answered without running again
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewIdempotencyRepository creates a new idempotency repository
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Claim records key for principal and reports whether the caller now owns
+// it. Concurrent requests with the same key are serialised by the primary
+// key, so exactly one of them claims it. A claim that has been in flight
+// for longer than abandonAfter is taken over, so a replica dying mid-request
+// does not block the key until it is pruned. When the key is not claimed
+// the stored record is returned instead.
+func (r *IdempotencyRepository) Claim(principal, key, requestHash string, abandonAfter time.Duration) (models.IdempotencyRecord, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO idempotency_keys (principal, idempotency_key, request_hash)
+	          VALUES ($1, $2, $3)
+	          ON CONFLICT (principal, idempotency_key) DO UPDATE
+	          SET request_hash = EXCLUDED.request_hash, created_at = NOW()
+	          WHERE idempotency_keys.status_code IS NULL
+	            AND idempotency_keys.created_at < NOW() - make_interval(secs => $4)
+	          RETURNING request_hash`
+	var claimed string
+	err := r.db.QueryRowContext(ctx, query, principal, key, requestHash, abandonAfter.Seconds()).Scan(&claimed)
+	if err == nil {
+		return models.IdempotencyRecord{RequestHash: claimed}, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return models.IdempotencyRecord{}, false, fmt.Errorf("error claiming idempotency key: %w", err)
+	}
+
+	var record models.IdempotencyRecord
+	var status sql.NullInt64
+	query = `SELECT request_hash, status_code, response_body FROM idempotency_keys
+	         WHERE principal = $1 AND idempotency_key = $2`
+	if err := r.db.QueryRowContext(ctx, query, principal, key).Scan(&record.RequestHash, &status, &record.Body); err != nil {
+		return models.IdempotencyRecord{}, false, fmt.Errorf("error reading idempotency key: %w", err)
+	}
+	record.StatusCode = int(status.Int64)
+
+	return record, false, nil
+}
+
+// Complete stores the response sent for a claimed key
+func (r *IdempotencyRepository) Complete(principal, key string, statusCode int, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE idempotency_keys
+	          SET status_code = $3, response_body = $4, completed_at = NOW()
+	          WHERE principal = $1 AND idempotency_key = $2`
+	if _, err := r.db.ExecContext(ctx, query, principal, key, statusCode, body); err != nil {
+		return fmt.Errorf("error storing idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Release forgets a claimed key whose request failed so a retry runs again.
+// Keys that already have a stored response are kept.
+func (r *IdempotencyRepository) Release(principal, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM idempotency_keys
+	          WHERE principal = $1 AND idempotency_key = $2 AND status_code IS NULL`
+	if _, err := r.db.ExecContext(ctx, query, principal, key); err != nil {
+		return fmt.Errorf("error releasing idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteBefore removes keys first used before cutoff and returns how many
+// were removed
+func (r *IdempotencyRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("error pruning idempotency keys: %w", err)
+	}
+	return result.RowsAffected()
+}
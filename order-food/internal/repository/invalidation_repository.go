@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// InvalidationRepository reads and writes the cache invalidation outbox
+type InvalidationRepository struct {
+	db *sql.DB
+}
+
+// NewInvalidationRepository creates a new invalidation repository
+func NewInvalidationRepository(db *sql.DB) *InvalidationRepository {
+	return &InvalidationRepository{db: db}
+}
+
+// Publish appends an invalidation event to the outbox
+func (r *InvalidationRepository) Publish(topic, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO cache_invalidations (topic, cache_key) VALUES ($1, $2)`
+	if _, err := r.db.ExecContext(ctx, query, topic, key); err != nil {
+		return fmt.Errorf("error publishing cache invalidation: %w", err)
+	}
+	return nil
+}
+
+// publishInTx appends an invalidation event per key as part of tx, so
+// replicas only drop their caches once the change is committed
+func publishInTx(ctx context.Context, tx *sql.Tx, topic string, keys []string) error {
+	query := `INSERT INTO cache_invalidations (topic, cache_key) SELECT $1, unnest($2::text[])`
+	if _, err := tx.ExecContext(ctx, query, topic, pq.Array(keys)); err != nil {
+		return fmt.Errorf("error publishing cache invalidation: %w", err)
+	}
+	return nil
+}
+
+// LatestID returns the ID of the newest event, or 0 when the outbox is empty
+func (r *InvalidationRepository) LatestID() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var id int64
+	query := `SELECT COALESCE(MAX(id), 0) FROM cache_invalidations`
+	if err := r.db.QueryRowContext(ctx, query).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error querying latest cache invalidation: %w", err)
+	}
+	return id, nil
+}
+
+// ListAfter returns up to limit events with an ID greater than afterID in
+// publication order
+func (r *InvalidationRepository) ListAfter(afterID int64, limit int) ([]models.CacheInvalidation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT id, topic, cache_key, created_at
+	          FROM cache_invalidations
+	          WHERE id > $1
+	          ORDER BY id
+	          LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying cache invalidations: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]models.CacheInvalidation, 0)
+	for rows.Next() {
+		var event models.CacheInvalidation
+		if err := rows.Scan(&event.ID, &event.Topic, &event.Key, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning cache invalidation: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying cache invalidations: %w", err)
+	}
+	return events, nil
+}
+
+// DeleteBefore removes events published before cutoff and returns how many
+// were removed
+func (r *InvalidationRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM cache_invalidations WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("error pruning cache invalidations: %w", err)
+	}
+	return result.RowsAffected()
+}
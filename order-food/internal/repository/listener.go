@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+const (
+	// listenerPingInterval is how often an idle listening connection is
+	// checked, so a connection that died silently is replaced
+	listenerPingInterval = 30 * time.Second
+	// listenerMaxBackoff bounds the wait between attempts to listen again
+	listenerMaxBackoff = 30 * time.Second
+)
+
+// NotificationHandler receives the payload of a notification
+type NotificationHandler func(payload string)
+
+// Listener receives PostgreSQL notifications sent with NOTIFY on a
+// connection of its own, outside the pool, and hands them to the handlers
+// subscribed to their channel. Notifications are only delivered while the
+// connection is up, so after every (re)connect the resync handlers run to
+// let subscribers catch up from the tables.
+type Listener struct {
+	// dsn returns the connection URL to listen on, resolved anew for every
+	// connection so rotated credentials and a new primary are picked up
+	dsn func(ctx context.Context) (string, error)
+
+	mu       sync.Mutex
+	handlers map[string][]NotificationHandler
+	resync   []func()
+}
+
+// NewListener creates a listener connecting to the URL dsn returns
+func NewListener(dsn func(ctx context.Context) (string, error)) *Listener {
+	return &Listener{dsn: dsn, handlers: make(map[string][]NotificationHandler)}
+}
+
+// Subscribe registers handler for notifications on channel. Handlers run
+// one at a time on the listening goroutine, so they must not block.
+// Subscribe must be called before Run.
+func (l *Listener) Subscribe(channel string, handler NotificationHandler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handlers[channel] = append(l.handlers[channel], handler)
+}
+
+// OnResync registers fn to run whenever notifications may have been
+// missed: once listening starts and after every reconnect
+func (l *Listener) OnResync(fn func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.resync = append(l.resync, fn)
+}
+
+// SubscribeOrderChanges registers handler for orders placed or moved to
+// another status
+func (l *Listener) SubscribeOrderChanges(handler func(models.OrderChange)) {
+	l.Subscribe(models.NotifyChannelOrderChanges, func(payload string) {
+		var change models.OrderChange
+		if err := json.Unmarshal([]byte(payload), &change); err != nil || change.ID == "" {
+			slog.Warn("Ignoring malformed order change notification", "payload", payload)
+			return
+		}
+		handler(change)
+	})
+}
+
+// Run listens on every subscribed channel until ctx is cancelled,
+// connecting again with a growing delay whenever the connection is lost
+func (l *Listener) Run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > listenerMaxBackoff {
+			backoff = time.Second
+		}
+		slog.Warn("Listening for database notifications stopped; retrying", "error", err, "retry_in", backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, listenerMaxBackoff)
+	}
+}
+
+// listen opens a listening connection and delivers its notifications
+// until ctx is cancelled or the connection is lost. The driver's own
+// reconnects would keep the URL of the first connection, so the
+// connection is replaced instead.
+func (l *Listener) listen(ctx context.Context) error {
+	dsn, err := l.dsn(ctx)
+	if err != nil {
+		return err
+	}
+
+	lost := make(chan error, 1)
+	listener := pq.NewListener(dsn, time.Second, listenerMaxBackoff, func(event pq.ListenerEventType, err error) {
+		if event == pq.ListenerEventDisconnected || event == pq.ListenerEventConnectionAttemptFailed {
+			select {
+			case lost <- fmt.Errorf("listening connection lost: %w", err):
+			default:
+			}
+		}
+	})
+	defer listener.Close()
+
+	l.mu.Lock()
+	channels := make([]string, 0, len(l.handlers))
+	for channel := range l.handlers {
+		channels = append(channels, channel)
+	}
+	l.mu.Unlock()
+	for _, channel := range channels {
+		if err := listener.Listen(channel); err != nil {
+			return fmt.Errorf("error listening on %s: %w", channel, err)
+		}
+	}
+	slog.Info("Listening for database notifications", "channels", channels)
+	l.resyncAll()
+
+	ping := time.NewTicker(listenerPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-lost:
+			return err
+		case n := <-listener.Notify:
+			if n == nil {
+				// The driver reconnected, so notifications may have been
+				// missed in between
+				l.resyncAll()
+				continue
+			}
+			l.deliver(n.Channel, n.Extra)
+		case <-ping.C:
+			if err := listener.Ping(); err != nil {
+				return fmt.Errorf("listening connection lost: %w", err)
+			}
+		}
+	}
+}
+
+// deliver hands a notification to the handlers of its channel
+func (l *Listener) deliver(channel, payload string) {
+	l.mu.Lock()
+	handlers := l.handlers[channel]
+	l.mu.Unlock()
+	for _, handler := range handlers {
+		handler(payload)
+	}
+}
+
+func (l *Listener) resyncAll() {
+	l.mu.Lock()
+	resync := l.resync
+	l.mu.Unlock()
+	for _, fn := range resync {
+		fn()
+	}
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+var (
+	// ErrMaintenanceJobRunning is returned when a job of the same kind is
+	// already running
+	ErrMaintenanceJobRunning = errors.New("maintenance job already running")
+	// ErrMaintenanceJobNotFound is returned when a maintenance job does not exist
+	ErrMaintenanceJobNotFound = errors.New("maintenance job not found")
+)
+
+// maintenanceJobAbandonedAfter is how long a running job may go without
+// starting or finishing a step before it is taken as abandoned, as when
+// its replica stopped, so a new one can start
+const maintenanceJobAbandonedAfter = time.Hour
+
+// maintenanceJobColumns is the select list shared by maintenance job queries
+const maintenanceJobColumns = `id, kind, status, steps, error, requested_by, instance_id,
+	COALESCE(operation_id, ''), created_at, updated_at, finished_at`
+
+// MaintenanceRepository tracks maintenance jobs and runs the database side
+// of them
+type MaintenanceRepository struct {
+	db *sql.DB
+}
+
+// NewMaintenanceRepository creates a new maintenance repository
+func NewMaintenanceRepository(db *sql.DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+func scanMaintenanceJob(row rowScanner, job *models.MaintenanceJob) error {
+	var steps []byte
+	if err := row.Scan(&job.ID, &job.Kind, &job.Status, &steps, &job.Error, &job.RequestedBy, &job.InstanceID,
+		&job.OperationID, &job.CreatedAt, &job.UpdatedAt, &job.FinishedAt); err != nil {
+		return err
+	}
+	return json.Unmarshal(steps, &job.Steps)
+}
+
+// Create records job as running. ErrMaintenanceJobRunning is returned when
+// a job of its kind is running; one abandoned by its replica is marked
+// failed first.
+func (r *MaintenanceRepository) Create(job *models.MaintenanceJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	abandon := `UPDATE maintenance_jobs
+	            SET status = $2, error = 'abandoned by its replica', updated_at = NOW(), finished_at = NOW()
+	            WHERE kind = $1 AND status = $3 AND updated_at < NOW() - $4 * INTERVAL '1 second'`
+	if _, err := r.db.ExecContext(ctx, abandon, job.Kind, models.MaintenanceJobFailed, models.MaintenanceJobRunning,
+		maintenanceJobAbandonedAfter.Seconds()); err != nil {
+		return fmt.Errorf("error expiring maintenance jobs: %w", err)
+	}
+
+	steps, err := json.Marshal(job.Steps)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO maintenance_jobs (id, kind, status, steps, requested_by, instance_id, operation_id)
+	          VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+	          ON CONFLICT (kind) WHERE status = 'running' DO NOTHING
+	          RETURNING ` + maintenanceJobColumns
+	err = scanMaintenanceJob(r.db.QueryRowContext(ctx, query, job.ID, job.Kind, models.MaintenanceJobRunning, steps,
+		job.RequestedBy, job.InstanceID, job.OperationID), job)
+	if err == sql.ErrNoRows {
+		return ErrMaintenanceJobRunning
+	}
+	if err != nil {
+		return fmt.Errorf("error inserting maintenance job: %w", err)
+	}
+	return nil
+}
+
+// Update records the progress of a running job, and its outcome once
+// status is no longer running
+func (r *MaintenanceRepository) Update(ctx context.Context, id, status string, steps []models.MaintenanceStep, jobErr string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	data, err := json.Marshal(steps)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE maintenance_jobs
+	          SET status = $2, steps = $3, error = $4, updated_at = NOW(),
+	              finished_at = CASE WHEN $2 = 'running' THEN NULL ELSE NOW() END
+	          WHERE id = $1`
+	if _, err := r.db.ExecContext(ctx, query, id, status, data, jobErr); err != nil {
+		return fmt.Errorf("error updating maintenance job: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a maintenance job by ID
+func (r *MaintenanceRepository) GetByID(id string) (models.MaintenanceJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + maintenanceJobColumns + ` FROM maintenance_jobs WHERE id = $1`
+	var job models.MaintenanceJob
+	err := scanMaintenanceJob(r.db.QueryRowContext(ctx, query, id), &job)
+	if err == sql.ErrNoRows {
+		return models.MaintenanceJob{}, ErrMaintenanceJobNotFound
+	}
+	if err != nil {
+		return models.MaintenanceJob{}, fmt.Errorf("error querying maintenance job: %w", err)
+	}
+	return job, nil
+}
+
+// RebuildProductSearch rebuilds the product search index and refreshes the
+// planner statistics of products. The search documents themselves are
+// generated columns and always current, but a large load leaves the GIN
+// index bloated and the statistics stale. The index is rebuilt
+// concurrently, so searches keep working meanwhile.
+func (r *MaintenanceRepository) RebuildProductSearch(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `REINDEX INDEX CONCURRENTLY idx_products_search_vector`); err != nil {
+		return fmt.Errorf("error rebuilding product search index: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, `ANALYZE products`); err != nil {
+		return fmt.Errorf("error analyzing products: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// ValidCouponsView holds the promo codes in at least two coupon files
+const ValidCouponsView = "valid_coupons"
+
+// MatviewRepository refreshes materialized views and keeps the state of
+// their refreshes
+type MatviewRepository struct {
+	db *sql.DB
+}
+
+// NewMatviewRepository creates a new materialized view repository
+func NewMatviewRepository(db *sql.DB) *MatviewRepository {
+	return &MatviewRepository{db: db}
+}
+
+// Populated reports whether view holds data
+func (r *MatviewRepository) Populated(ctx context.Context, view string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return matviewPopulated(ctx, r.db, view)
+}
+
+// matviewPopulated reports whether view holds data; a missing view holds
+// none
+func matviewPopulated(ctx context.Context, db *sql.DB, view string) (bool, error) {
+	var populated bool
+	err := db.QueryRowContext(ctx,
+		`SELECT ispopulated FROM pg_matviews WHERE schemaname = current_schema() AND matviewname = $1`, view).
+		Scan(&populated)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error checking materialized view %s: %w", view, err)
+	}
+	return populated, nil
+}
+
+// Refresh refreshes view. A concurrent refresh lets readers keep using the
+// view while it runs, but needs the view to be populated and to have a
+// unique index.
+func (r *MatviewRepository) Refresh(ctx context.Context, view string, concurrently bool) error {
+	query := "REFRESH MATERIALIZED VIEW " + pq.QuoteIdentifier(view)
+	if concurrently {
+		query = "REFRESH MATERIALIZED VIEW CONCURRENTLY " + pq.QuoteIdentifier(view)
+	}
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("error refreshing materialized view %s: %w", view, err)
+	}
+	return nil
+}
+
+// StartRefresh records a refresh of view starting at startedAt, keeping
+// when the view was last refreshed successfully
+func (r *MatviewRepository) StartRefresh(ctx context.Context, view, instanceID string, startedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO matview_refreshes (view_name, instance_id, started_at)
+	          VALUES ($1, $2, $3)
+	          ON CONFLICT (view_name) DO UPDATE
+	          SET instance_id = EXCLUDED.instance_id,
+	              started_at = EXCLUDED.started_at,
+	              finished_at = NULL`
+	if _, err := r.db.ExecContext(ctx, query, view, instanceID, startedAt); err != nil {
+		return fmt.Errorf("error recording materialized view refresh: %w", err)
+	}
+	return nil
+}
+
+// FinishRefresh records the end of the refresh of view started at
+// startedAt. A successful refresh makes the view current as of startedAt.
+func (r *MatviewRepository) FinishRefresh(ctx context.Context, view string, startedAt, finishedAt time.Time, refreshErr error) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `UPDATE matview_refreshes
+	          SET finished_at = $3, error = '',
+	              refreshed_at = $2, last_duration_ms = $4
+	          WHERE view_name = $1 AND started_at = $2`
+	args := []any{view, startedAt, finishedAt, finishedAt.Sub(startedAt).Milliseconds()}
+	if refreshErr != nil {
+		query = `UPDATE matview_refreshes SET finished_at = $3, error = $4
+		         WHERE view_name = $1 AND started_at = $2`
+		args = []any{view, startedAt, finishedAt, refreshErr.Error()}
+	}
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("error recording materialized view refresh: %w", err)
+	}
+	return nil
+}
+
+// LastRefresh returns the latest refresh of view
+func (r *MatviewRepository) LastRefresh(ctx context.Context, view string) (models.MatviewRefresh, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT view_name, instance_id, started_at, finished_at, refreshed_at, last_duration_ms, error
+	          FROM matview_refreshes WHERE view_name = $1`
+	var refresh models.MatviewRefresh
+	var durationMS int64
+	err := r.db.QueryRowContext(ctx, query, view).Scan(&refresh.View, &refresh.InstanceID, &refresh.StartedAt,
+		&refresh.FinishedAt, &refresh.RefreshedAt, &durationMS, &refresh.Error)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.MatviewRefresh{}, false, nil
+	}
+	if err != nil {
+		return models.MatviewRefresh{}, false, fmt.Errorf("error querying materialized view refresh: %w", err)
+	}
+	refresh.LastDuration = time.Duration(durationMS) * time.Millisecond
+	return refresh, true, nil
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/pii"
+)
+
+// deliveryReencryptBatchSize is how many delivery addresses are read per
+// query while re-encrypting
+const deliveryReencryptBatchSize = 500
+
+// sealedDeliveryColumns are the order_addresses columns stored through the
+// PII codec, in the order of sealedDeliveryFields. City, region, postal
+// code and country stay plaintext so deliveries can be grouped by area.
+const sealedDeliveryColumns = `line1, line2, contact_name, contact_phone, contact_email, instructions`
+
+// sealedDeliveryFields returns the fields of delivery stored in
+// sealedDeliveryColumns
+func sealedDeliveryFields(delivery *models.Delivery) []*string {
+	return []*string{
+		&delivery.Address.Line1, &delivery.Address.Line2,
+		&delivery.Contact.Name, &delivery.Contact.Phone, &delivery.Contact.Email,
+		&delivery.Instructions,
+	}
+}
+
+// insertDelivery stores the delivery address and contact of an order
+// using the given transaction, sealing its personal data
+func (r *OrderRepository) insertDelivery(ctx context.Context, tx *sql.Tx, orderID string, delivery models.Delivery) error {
+	for _, field := range sealedDeliveryFields(&delivery) {
+		sealed, err := r.codec.Encode(*field)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt delivery address: %w", err)
+		}
+		*field = sealed
+	}
+
+	query := `INSERT INTO order_addresses (order_id, city, region, postal_code, country, ` + sealedDeliveryColumns + `, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NOW())`
+	address, contact := delivery.Address, delivery.Contact
+	_, err := tx.ExecContext(ctx, query, orderID, address.City, address.Region, address.PostalCode, address.Country,
+		address.Line1, address.Line2, contact.Name, contact.Phone, contact.Email, delivery.Instructions)
+	if err != nil {
+		return fmt.Errorf("failed to insert delivery address: %w", err)
+	}
+	return nil
+}
+
+// getDelivery returns the delivery address and contact of an order, or nil
+// when it has none
+func (r *OrderRepository) getDelivery(ctx context.Context, orderID string) (*models.Delivery, error) {
+	query := `SELECT city, region, postal_code, country, ` + sealedDeliveryColumns + `
+	          FROM order_addresses WHERE order_id = $1`
+	var delivery models.Delivery
+	dest := append([]any{&delivery.Address.City, &delivery.Address.Region, &delivery.Address.PostalCode, &delivery.Address.Country},
+		anyPointers(sealedDeliveryFields(&delivery))...)
+	err := r.db.QueryRowContext(ctx, query, orderID).Scan(dest...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying delivery address: %w", err)
+	}
+
+	if err := r.OpenDelivery(&delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// getSealedDeliveries returns the delivery addresses and contacts of the
+// given orders keyed by order ID, with their personal data left sealed as
+// stored, for archiving
+func (r *OrderRepository) getSealedDeliveries(ctx context.Context, orderIDs []string) (map[string]*models.Delivery, error) {
+	query := `SELECT order_id, city, region, postal_code, country, ` + sealedDeliveryColumns + `
+	          FROM order_addresses WHERE order_id = ANY($1)`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(orderIDs))
+	if err != nil {
+		return nil, fmt.Errorf("error querying delivery addresses: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make(map[string]*models.Delivery)
+	for rows.Next() {
+		var orderID string
+		var delivery models.Delivery
+		dest := append([]any{&orderID, &delivery.Address.City, &delivery.Address.Region, &delivery.Address.PostalCode, &delivery.Address.Country},
+			anyPointers(sealedDeliveryFields(&delivery))...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("error scanning delivery address: %w", err)
+		}
+		deliveries[orderID] = &delivery
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying delivery addresses: %w", err)
+	}
+	return deliveries, nil
+}
+
+// OpenDelivery decrypts the personal data of a delivery read sealed, such
+// as from the archive
+func (r *OrderRepository) OpenDelivery(delivery *models.Delivery) error {
+	for _, field := range sealedDeliveryFields(delivery) {
+		plain, err := r.codec.Decode(*field)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt delivery address: %w", err)
+		}
+		*field = plain
+	}
+	return nil
+}
+
+// ReencryptDeliveries rewrites every delivery address with personal data
+// that is plaintext or sealed with an old key using the current key of
+// cipher. Addresses are read in batches, so orders can keep being placed
+// meanwhile. It returns the number of addresses rewritten.
+func (r *OrderRepository) ReencryptDeliveries(cipher *pii.Cipher) (int, error) {
+	rewritten := 0
+	after := ""
+	for {
+		stale, last, err := r.staleDeliveries(cipher, after)
+		if err != nil {
+			return rewritten, err
+		}
+		for orderID, stored := range stale {
+			ok, err := r.reencryptDelivery(cipher, orderID, stored)
+			if err != nil {
+				return rewritten, err
+			}
+			if ok {
+				rewritten++
+			}
+		}
+		if last == "" {
+			return rewritten, nil
+		}
+		after = last
+	}
+}
+
+// staleDeliveries returns the sealed columns of the addresses following
+// the order ID after that need re-encrypting, keyed by order ID, and the
+// last order ID read, which is empty once there are no more
+func (r *OrderRepository) staleDeliveries(cipher *pii.Cipher, after string) (map[string][]string, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT order_id, `+sealedDeliveryColumns+`
+	                                     FROM order_addresses WHERE order_id > $1 ORDER BY order_id LIMIT $2`,
+		after, deliveryReencryptBatchSize)
+	if err != nil {
+		return nil, "", fmt.Errorf("error querying delivery addresses: %w", err)
+	}
+	defer rows.Close()
+
+	stale := make(map[string][]string)
+	last, read := "", 0
+	for rows.Next() {
+		var delivery models.Delivery
+		if err := rows.Scan(append([]any{&last}, anyPointers(sealedDeliveryFields(&delivery))...)...); err != nil {
+			return nil, "", fmt.Errorf("error scanning delivery address: %w", err)
+		}
+		read++
+		var stored []string
+		needsRotation := false
+		for _, field := range sealedDeliveryFields(&delivery) {
+			stored = append(stored, *field)
+			needsRotation = needsRotation || cipher.NeedsRotation(*field)
+		}
+		if needsRotation {
+			stale[last] = stored
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("error querying delivery addresses: %w", err)
+	}
+	if read < deliveryReencryptBatchSize {
+		last = ""
+	}
+	return stale, last, nil
+}
+
+// reencryptDelivery seals the stored columns of an address with the
+// current key and reports whether the row was rewritten
+func (r *OrderRepository) reencryptDelivery(cipher *pii.Cipher, orderID string, stored []string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	args := []any{orderID}
+	for _, value := range stored {
+		plain, err := cipher.Decode(value)
+		if err != nil {
+			return false, fmt.Errorf("failed to decrypt delivery address of order %s: %w", orderID, err)
+		}
+		sealed, err := cipher.Encode(plain)
+		if err != nil {
+			return false, err
+		}
+		args = append(args, sealed)
+	}
+	for _, value := range stored {
+		args = append(args, value)
+	}
+
+	// Only overwrite the values that were read, in case they changed meanwhile
+	result, err := r.db.ExecContext(ctx, `UPDATE order_addresses
+	                                      SET (`+sealedDeliveryColumns+`) = ($2, $3, $4, $5, $6, $7)
+	                                      WHERE order_id = $1 AND (`+sealedDeliveryColumns+`) = ($8, $9, $10, $11, $12, $13)`, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update delivery address of order %s: %w", orderID, err)
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// anyPointers converts string pointers to scan destinations
+func anyPointers(fields []*string) []any {
+	dest := make([]any, len(fields))
+	for i, field := range fields {
+		dest[i] = field
+	}
+	return dest
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// exportedOrderColumns are the columns scanned by scanExportedOrder, from
+// orders aliased o
+const exportedOrderColumns = `o.id, o.coupon_code, o.status, COALESCE(o.total, 0), o.discount, COALESCE(o.customer_id, ''),
+	          o.created_at, o.cancelled_at,
+	          COALESCE((SELECT SUM(i.quantity) FROM order_items i WHERE i.order_id = o.id), 0)`
+
+// OrderExportRepository reads the orders and refunds of a day for the
+// accounting export and records which days were exported
+type OrderExportRepository struct {
+	db *sql.DB
+}
+
+// NewOrderExportRepository creates a new order export repository
+func NewOrderExportRepository(db *sql.DB) *OrderExportRepository {
+	return &OrderExportRepository{db: db}
+}
+
+// ListPlaced returns the orders placed in [from, to), oldest first
+func (r *OrderExportRepository) ListPlaced(ctx context.Context, from, to time.Time) ([]models.ExportedOrder, error) {
+	query := `SELECT ` + exportedOrderColumns + `
+	          FROM orders o
+	          WHERE o.created_at >= $1 AND o.created_at < $2
+	          ORDER BY o.created_at, o.id`
+	return r.list(ctx, query, from, to)
+}
+
+// ListRefunded returns the orders cancelled, and so refunded, in
+// [from, to), oldest cancellation first
+func (r *OrderExportRepository) ListRefunded(ctx context.Context, from, to time.Time) ([]models.ExportedOrder, error) {
+	query := `SELECT ` + exportedOrderColumns + `
+	          FROM orders o
+	          WHERE o.cancelled_at >= $1 AND o.cancelled_at < $2
+	          ORDER BY o.cancelled_at, o.id`
+	return r.list(ctx, query, from, to)
+}
+
+func (r *OrderExportRepository) list(ctx context.Context, query string, from, to time.Time) ([]models.ExportedOrder, error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error querying orders to export: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]models.ExportedOrder, 0)
+	for rows.Next() {
+		var order models.ExportedOrder
+		var cancelledAt sql.NullTime
+		if err := scanOrder(rows, &order.Order, &order.PlacedAt, &cancelledAt, &order.Units); err != nil {
+			return nil, fmt.Errorf("error scanning order: %w", err)
+		}
+		if cancelledAt.Valid {
+			order.CancelledAt = &cancelledAt.Time
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying orders to export: %w", err)
+	}
+	return orders, nil
+}
+
+// ExportedDays returns the days in [from, to] that were exported, as
+// dates formatted 2006-01-02
+func (r *OrderExportRepository) ExportedDays(ctx context.Context, from, to time.Time) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT to_char(day, 'YYYY-MM-DD') FROM order_exports WHERE day BETWEEN $1::date AND $2::date AND status = $3`,
+		from.Format(time.DateOnly), to.Format(time.DateOnly), models.OrderExportStatusExported)
+	if err != nil {
+		return nil, fmt.Errorf("error querying order exports: %w", err)
+	}
+	defer rows.Close()
+
+	days := make(map[string]bool)
+	for rows.Next() {
+		var day string
+		if err := rows.Scan(&day); err != nil {
+			return nil, fmt.Errorf("error scanning order export: %w", err)
+		}
+		days[day] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying order exports: %w", err)
+	}
+	return days, nil
+}
+
+// RecordExported marks day exported with the number of orders and refunds
+// delivered
+func (r *OrderExportRepository) RecordExported(ctx context.Context, day time.Time, orders, refunds int) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO order_exports (day, status, attempts, orders, refunds, exported_at, updated_at)
+	          VALUES ($1::date, $2, 1, $3, $4, NOW(), NOW())
+	          ON CONFLICT (day) DO UPDATE
+	          SET status = EXCLUDED.status,
+	              attempts = order_exports.attempts + 1,
+	              orders = EXCLUDED.orders,
+	              refunds = EXCLUDED.refunds,
+	              error = '',
+	              exported_at = EXCLUDED.exported_at,
+	              updated_at = EXCLUDED.updated_at`
+	if _, err := r.db.ExecContext(ctx, query, day.Format(time.DateOnly), models.OrderExportStatusExported, orders, refunds); err != nil {
+		return fmt.Errorf("error recording order export: %w", err)
+	}
+	return nil
+}
+
+// RecordFailed records a failed attempt to export day and why it failed
+func (r *OrderExportRepository) RecordFailed(ctx context.Context, day time.Time, cause error) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO order_exports (day, status, attempts, error, updated_at)
+	          VALUES ($1::date, $2, 1, $3, NOW())
+	          ON CONFLICT (day) DO UPDATE
+	          SET status = EXCLUDED.status,
+	              attempts = order_exports.attempts + 1,
+	              error = EXCLUDED.error,
+	              updated_at = EXCLUDED.updated_at`
+	if _, err := r.db.ExecContext(ctx, query, day.Format(time.DateOnly), models.OrderExportStatusFailed, cause.Error()); err != nil {
+		return fmt.Errorf("error recording order export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// insertItemModifiers stores the modifiers of the order item with the
+// given ID using the given transaction, after the item itself
+func insertItemModifiers(ctx context.Context, tx *sql.Tx, itemID int64, item models.OrderItem) error {
+	query := `INSERT INTO order_item_modifiers (order_item_id, option_id, group_name, option_name, price)
+	          VALUES ($1, $2, $3, $4, $5)`
+	for _, modifier := range item.Modifiers {
+		_, err := tx.ExecContext(ctx, query, itemID, modifier.OptionID, modifier.Group, modifier.Name, modifier.Price)
+		if err != nil {
+			return fmt.Errorf("failed to insert order item modifier: %w", err)
+		}
+	}
+	return nil
+}
+
+// itemModifiers are the modifiers of order items keyed by order ID, then
+// the position of the item in its order
+type itemModifiers map[string]map[int][]models.ItemModifier
+
+// getItemModifiers returns the modifiers of the items of the given orders
+// in the order they were selected. Items are positioned in the order they
+// were inserted, like the item queries list them.
+func getItemModifiers(ctx context.Context, db *sql.DB, orderIDs []string) (itemModifiers, error) {
+	query := `SELECT oi.order_id, oi.position, m.option_id, m.group_name, m.option_name, m.price
+	          FROM order_item_modifiers m
+	          JOIN (
+	              SELECT id, order_id, ROW_NUMBER() OVER (PARTITION BY order_id ORDER BY id) - 1 AS position
+	              FROM order_items
+	              WHERE order_id = ANY($1)
+	          ) oi ON oi.id = m.order_item_id
+	          ORDER BY m.id`
+	rows, err := db.QueryContext(ctx, query, pq.Array(orderIDs))
+	if err != nil {
+		return nil, fmt.Errorf("error querying order item modifiers: %w", err)
+	}
+	defer rows.Close()
+
+	modifiers := make(itemModifiers)
+	for rows.Next() {
+		var orderID string
+		var position int
+		var modifier models.ItemModifier
+		if err := rows.Scan(&orderID, &position, &modifier.OptionID, &modifier.Group, &modifier.Name, &modifier.Price); err != nil {
+			return nil, fmt.Errorf("error scanning order item modifier: %w", err)
+		}
+		if modifiers[orderID] == nil {
+			modifiers[orderID] = make(map[int][]models.ItemModifier)
+		}
+		modifiers[orderID][position] = append(modifiers[orderID][position], modifier)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying order item modifiers: %w", err)
+	}
+	return modifiers, nil
+}
+
+// attach sets the modifiers of the items of the order with the given ID,
+// which are all its items in order
+func (m itemModifiers) attach(orderID string, items []models.OrderItem) {
+	for i := range items {
+		items[i].Modifiers = m[orderID][i]
+	}
+}
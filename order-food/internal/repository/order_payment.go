@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// InsertPayment stores the payment of an order. It must be called within
+// the TxManager transaction storing the order.
+func (r *OrderRepository) InsertPayment(ctx context.Context, orderID string, payment models.Payment) error {
+	tx, err := txFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	query := `INSERT INTO order_payments (order_id, provider, intent_id, status, amount, currency, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())`
+	_, err = tx.ExecContext(ctx, query, orderID, payment.Provider, payment.IntentID, payment.Status, payment.Amount, payment.Currency)
+	if err != nil {
+		return fmt.Errorf("failed to insert payment: %w", err)
+	}
+	return nil
+}
+
+// getPayment returns the payment of an order, or nil when it was not
+// charged through a payment provider
+func (r *OrderRepository) getPayment(ctx context.Context, orderID string) (*models.Payment, error) {
+	query := `SELECT provider, intent_id, status, amount, currency FROM order_payments WHERE order_id = $1`
+	var payment models.Payment
+	err := r.db.QueryRowContext(ctx, query, orderID).
+		Scan(&payment.Provider, &payment.IntentID, &payment.Status, &payment.Amount, &payment.Currency)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying payment: %w", err)
+	}
+	return &payment, nil
+}
+
+// getPayments returns the payments of the given orders keyed by order ID
+func (r *OrderRepository) getPayments(ctx context.Context, orderIDs []string) (map[string]*models.Payment, error) {
+	query := `SELECT order_id, provider, intent_id, status, amount, currency FROM order_payments WHERE order_id = ANY($1)`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(orderIDs))
+	if err != nil {
+		return nil, fmt.Errorf("error querying payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := make(map[string]*models.Payment)
+	for rows.Next() {
+		var orderID string
+		var payment models.Payment
+		if err := rows.Scan(&orderID, &payment.Provider, &payment.IntentID, &payment.Status, &payment.Amount, &payment.Currency); err != nil {
+			return nil, fmt.Errorf("error scanning payment: %w", err)
+		}
+		payments[orderID] = &payment
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying payments: %w", err)
+	}
+	return payments, nil
+}
@@ -5,84 +5,238 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/pii"
 )
 
 // OrderRepository handles order data operations
 type OrderRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	changes ChangeRecorder
+	// codec seals the personal data of delivery addresses
+	codec pii.Codec
 }
 
 // NewOrderRepository creates a new order repository connected to PostgreSQL
 func NewOrderRepository(db *sql.DB) *OrderRepository {
 	return &OrderRepository{
-		db: db,
+		db:      db,
+		changes: noChanges{},
+		codec:   pii.Plaintext{},
 	}
 }
 
-// Create stores a new order
-func (r *OrderRepository) Create(order models.Order) error {
+// SetCodec makes the repository encrypt the personal data of delivery
+// addresses with codec
+func (r *OrderRepository) SetCodec(codec pii.Codec) {
+	r.codec = codec
+}
+
+// SetChangeRecorder makes the repository report the orders it writes to
+// changes
+func (r *OrderRepository) SetChangeRecorder(changes ChangeRecorder) {
+	r.changes = changes
+}
+
+var (
+	// ErrDuplicatePOSTicket is returned when a POS ticket has already been imported
+	ErrDuplicatePOSTicket = errors.New("POS ticket already imported")
+	// ErrOrderNotFound is returned when an order does not exist
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrOrderStatusConflict is returned when an order's status changed
+	// between reading and updating it
+	ErrOrderStatusConflict = errors.New("order status changed concurrently")
+)
+
+// Insert stores a new order with its items. It must be called within a
+// TxManager transaction, which the caller uses to take the items out of
+// stock in the same unit of work.
+func (r *OrderRepository) Insert(ctx context.Context, order models.Order) error {
+	tx, err := txFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := insertOrder(ctx, tx, order); err != nil {
+		return err
+	}
+	if order.Delivery != nil {
+		if err := r.insertDelivery(ctx, tx, order.ID, *order.Delivery); err != nil {
+			return err
+		}
+	}
+	afterCommit(ctx, func() { r.changes.Record(ChangedTableOrders, order.ID) })
+	return nil
+}
+
+// ClaimPOSTicket records that the POS ticket was imported as orderID. It
+// must be called within a TxManager transaction together with the order's
+// Insert. If the ticket has already been imported ErrDuplicatePOSTicket is
+// returned; a concurrent import of the same ticket blocks here until the
+// other transaction finishes, so callers claim the ticket last.
+func (r *OrderRepository) ClaimPOSTicket(ctx context.Context, ticketNumber, orderID string) error {
+	tx, err := txFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	importQuery := `INSERT INTO pos_order_imports (ticket_number, order_id, imported_at)
+	                VALUES ($1, $2, NOW())
+	                ON CONFLICT (ticket_number) DO NOTHING`
+	result, err := tx.ExecContext(ctx, importQuery, ticketNumber, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to record POS import: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to record POS import: %w", err)
+	}
+	if affected == 0 {
+		return ErrDuplicatePOSTicket
+	}
+
+	return nil
+}
+
+// GetOrderIDByPOSTicket returns the ID of the order a POS ticket was imported as
+func (r *OrderRepository) GetOrderIDByPOSTicket(ticketNumber string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// Start a transaction
-	tx, err := r.db.BeginTx(ctx, nil)
+	query := `SELECT order_id FROM pos_order_imports WHERE ticket_number = $1`
+	var orderID string
+	err := r.db.QueryRowContext(ctx, query, ticketNumber).Scan(&orderID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return "", fmt.Errorf("error querying POS import: %w", err)
 	}
-	defer tx.Rollback()
 
+	return orderID, nil
+}
+
+// orderColumns is the select list shared by order queries, scanned by
+// scanOrder
+const orderColumns = `id, coupon_code, status, COALESCE(total, 0), discount, COALESCE(customer_id, '')`
+
+// scanOrder scans a row selected with orderColumns, followed by extra
+// columns, into order
+func scanOrder(row rowScanner, order *models.Order, extra ...any) error {
+	dest := []any{&order.ID, &order.CouponCode, &order.Status, &order.Total, &order.Discount, &order.CustomerID}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	order.Subtotal = orderSubtotal(*order)
+	return nil
+}
+
+// orderFilterClause returns the conditions selecting the orders filter
+// matches, numbering arguments from 1, and their arguments
+func orderFilterClause(filter models.OrderFilter) ([]string, []any) {
+	var conditions []string
+	var args []any
+	if filter.CustomerID != "" {
+		args = append(args, filter.CustomerID)
+		conditions = append(conditions, fmt.Sprintf("customer_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	if filter.CouponCode != "" {
+		args = append(args, filter.CouponCode)
+		conditions = append(conditions, fmt.Sprintf("coupon_code = $%d", len(args)))
+	}
+	if filter.ProductID != "" {
+		args = append(args, filter.ProductID)
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM order_items oi WHERE oi.order_id = orders.id AND oi.product_id = $%d)", len(args)))
+	}
+	return conditions, args
+}
+
+// whereClause joins conditions into a WHERE clause with a leading space,
+// or returns "" for none
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// orderItemProductColumns selects the product snapshot of an order item in
+// the order scanned into models.Product: id, name, category, price, tax rate
+const orderItemProductColumns = `oi.product_id, oi.product_name, COALESCE(oi.product_category, ''), oi.unit_price, oi.tax_rate`
+
+// insertOrder writes an order and its items using the given transaction
+func insertOrder(ctx context.Context, tx *sql.Tx, order models.Order) error {
 	// Insert order
-	orderQuery := `INSERT INTO orders (id, coupon_code, created_at, updated_at)
-	               VALUES ($1, $2, NOW(), NOW())`
-	_, err = tx.ExecContext(ctx, orderQuery, order.ID, order.CouponCode)
+	orderQuery := `INSERT INTO orders (id, coupon_code, status, total, discount, customer_id, created_at, updated_at)
+	               VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NOW(), NOW())`
+	_, err := tx.ExecContext(ctx, orderQuery, order.ID, order.CouponCode, order.Status, order.Total, order.Discount, order.CustomerID)
 	if err != nil {
 		return fmt.Errorf("failed to insert order: %w", err)
 	}
 
-	// Insert order items
-	itemQuery := `INSERT INTO order_items (order_id, product_id, quantity, created_at)
-	              VALUES ($1, $2, $3, NOW())`
-	for _, item := range order.Items {
-		_, err = tx.ExecContext(ctx, itemQuery, order.ID, item.ProductID, item.Quantity)
+	// Insert order items with a snapshot of the product as it was priced
+	// for the item; a product may be listed on several items
+	itemQuery := `INSERT INTO order_items (order_id, product_id, quantity, product_name, product_category, unit_price, tax_rate, discount, created_at)
+	              VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, NOW())
+	              RETURNING id`
+	for i, item := range order.Items {
+		p := order.Products[i]
+		var itemID int64
+		err = tx.QueryRowContext(ctx, itemQuery, order.ID, item.ProductID, item.Quantity, p.Name, p.Category, p.Price, p.TaxRate, item.Discount).Scan(&itemID)
 		if err != nil {
 			return fmt.Errorf("failed to insert order item: %w", err)
 		}
-	}
-
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		if err := insertItemModifiers(ctx, tx, itemID, item); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// orderSubtotal returns the subtotal of a stored order, which keeps its
+// total and discount
+func orderSubtotal(order models.Order) float64 {
+	return math.Round((order.Total+order.Discount)*100) / 100
+}
+
 // GetByID returns an order by ID
 func (r *OrderRepository) GetByID(id string) (models.Order, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	// Get order details
-	orderQuery := `SELECT id, coupon_code FROM orders WHERE id = $1`
+	orderQuery := `SELECT ` + orderColumns + ` FROM orders WHERE id = $1`
 	var order models.Order
-	err := r.db.QueryRowContext(ctx, orderQuery, id).Scan(&order.ID, &order.CouponCode)
+	err := scanOrder(r.db.QueryRowContext(ctx, orderQuery, id), &order)
 	if err == sql.ErrNoRows {
-		return models.Order{}, errors.New("order not found")
+		return models.Order{}, ErrOrderNotFound
 	}
 	if err != nil {
 		return models.Order{}, fmt.Errorf("error querying order: %w", err)
 	}
 
-	// Get order items with product details
+	// Get order items with the product details snapshotted when it was placed
 	itemsQuery := `
-		SELECT oi.product_id, oi.quantity, p.id, p.name, p.price, p.category
+		SELECT oi.product_id, oi.quantity, oi.discount, ` + orderItemProductColumns + `
 		FROM order_items oi
-		JOIN products p ON oi.product_id = p.id
 		WHERE oi.order_id = $1
 		ORDER BY oi.id`
 
@@ -100,8 +254,8 @@ func (r *OrderRepository) GetByID(id string) (models.Order, error) {
 		var product models.Product
 
 		err := rows.Scan(
-			&item.ProductID, &item.Quantity,
-			&product.ID, &product.Name, &product.Price, &product.Category,
+			&item.ProductID, &item.Quantity, &item.Discount,
+			&product.ID, &product.Name, &product.Category, &product.Price, &product.TaxRate,
 		)
 		if err != nil {
 			return models.Order{}, fmt.Errorf("error scanning order item: %w", err)
@@ -110,26 +264,140 @@ func (r *OrderRepository) GetByID(id string) (models.Order, error) {
 		order.Items = append(order.Items, item)
 		order.Products = append(order.Products, product)
 	}
+	if err := rows.Err(); err != nil {
+		return models.Order{}, fmt.Errorf("error querying order items: %w", err)
+	}
+
+	modifiers, err := getItemModifiers(ctx, r.db, []string{id})
+	if err != nil {
+		return models.Order{}, err
+	}
+	modifiers.attach(id, order.Items)
+
+	if order.Delivery, err = r.getDelivery(ctx, id); err != nil {
+		return models.Order{}, err
+	}
+	if order.Payment, err = r.getPayment(ctx, id); err != nil {
+		return models.Order{}, err
+	}
 
 	return order, nil
 }
 
-// GetAll returns all orders with pagination
-func (r *OrderRepository) GetAll(limit, offset int) ([]models.Order, int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// GetItemsPaginated returns a page of the items of an order, in the order
+// they were placed, with the number of items it has. ErrOrderNotFound is
+// returned when the order does not exist.
+func (r *OrderRepository) GetItemsPaginated(id string, limit, offset int) ([]models.OrderLine, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var exists bool
+	var total int
+	countQuery := `SELECT EXISTS(SELECT 1 FROM orders WHERE id = $1), (SELECT COUNT(*) FROM order_items WHERE order_id = $1)`
+	if err := r.db.QueryRowContext(ctx, countQuery, id).Scan(&exists, &total); err != nil {
+		return nil, 0, fmt.Errorf("error counting order items: %w", err)
+	}
+	if !exists {
+		return nil, 0, ErrOrderNotFound
+	}
+
+	itemsQuery := `
+		SELECT oi.product_id, oi.quantity, oi.discount, ` + orderItemProductColumns + `
+		FROM order_items oi
+		WHERE oi.order_id = $1
+		ORDER BY oi.id
+		LIMIT $2 OFFSET $3`
+	rows, err := r.db.QueryContext(ctx, itemsQuery, id, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying order items: %w", err)
+	}
+	defer rows.Close()
+
+	lines := make([]models.OrderLine, 0)
+	for rows.Next() {
+		var line models.OrderLine
+		err := rows.Scan(
+			&line.ProductID, &line.Quantity, &line.Discount,
+			&line.Product.ID, &line.Product.Name, &line.Product.Category, &line.Product.Price, &line.Product.TaxRate,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error scanning order item: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error querying order items: %w", err)
+	}
+
+	if len(lines) > 0 {
+		modifiers, err := getItemModifiers(ctx, r.db, []string{id})
+		if err != nil {
+			return nil, 0, err
+		}
+		for i := range lines {
+			lines[i].Modifiers = modifiers[id][offset+i]
+		}
+	}
+
+	return lines, total, nil
+}
+
+// UpdateStatus moves an order from status from to status to. The update
+// only applies while the order is still in from, so a concurrent change
+// returns ErrOrderStatusConflict instead of being overwritten.
+func (r *OrderRepository) UpdateStatus(id string, from, to models.OrderStatus) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Cancelling refunds the order, which the accounting export picks up by
+	// when it happened
+	query := `UPDATE orders
+	          SET status = $3, updated_at = NOW(),
+	              cancelled_at = CASE WHEN $3 = 'cancelled' THEN NOW() ELSE cancelled_at END
+	          WHERE id = $1 AND status = $2`
+	result, err := r.db.ExecContext(ctx, query, id, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update order status: %w", err)
+	}
+	if affected == 0 {
+		var exists bool
+		if err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM orders WHERE id = $1)`, id).Scan(&exists); err != nil {
+			return fmt.Errorf("error querying order: %w", err)
+		}
+		if !exists {
+			return ErrOrderNotFound
+		}
+		return ErrOrderStatusConflict
+	}
+
+	r.changes.Record(ChangedTableOrders, id)
+	return nil
+}
+
+// GetAll returns the orders filter matches with pagination, ordered by the
+// given sort fields (newest first when none are given)
+func (r *OrderRepository) GetAll(ctx context.Context, limit, offset int, sort []models.SortField, filter models.OrderFilter) ([]models.Order, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	// Get total count
+	conditions, args := orderFilterClause(filter)
+	where := whereClause(conditions)
 	var total int
-	countQuery := `SELECT COUNT(*) FROM orders`
-	if err := r.db.QueryRowContext(ctx, countQuery).Scan(&total); err != nil {
-		log.Printf("Error counting orders: %v", err)
+	countQuery := `SELECT COUNT(*) FROM orders` + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		slog.ErrorContext(ctx, "Error counting orders", "error", err)
 		return nil, 0, fmt.Errorf("error counting orders: %w", err)
 	}
 
 	// Get paginated orders
-	ordersQuery := `SELECT id, coupon_code FROM orders ORDER BY created_at DESC LIMIT $1 OFFSET $2`
-	rows, err := r.db.QueryContext(ctx, ordersQuery, limit, offset)
+	ordersQuery := fmt.Sprintf(`SELECT `+orderColumns+` FROM orders%s ORDER BY %s LIMIT $%d OFFSET $%d`,
+		where, orderByClause(sort, orderSortColumns, "created_at DESC"), len(args)+1, len(args)+2)
+	rows, err := r.db.QueryContext(ctx, ordersQuery, append(args, limit, offset)...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("error querying orders: %w", err)
 	}
@@ -140,8 +408,8 @@ func (r *OrderRepository) GetAll(limit, offset int) ([]models.Order, int, error)
 
 	for rows.Next() {
 		var order models.Order
-		if err := rows.Scan(&order.ID, &order.CouponCode); err != nil {
-			log.Printf("Error scanning order: %v", err)
+		if err := scanOrder(rows, &order); err != nil {
+			slog.ErrorContext(ctx, "Error scanning order", "error", err)
 			continue
 		}
 		orders = append(orders, order)
@@ -154,17 +422,122 @@ func (r *OrderRepository) GetAll(limit, offset int) ([]models.Order, int, error)
 	}
 
 	// Get all order items and products for these orders with a single query
+	if err := loadOrderItems(ctx, r.db, orders, orderIDs); err != nil {
+		// Orders without their items are only better than nothing while
+		// someone is still waiting for them
+		if ctx.Err() != nil {
+			return nil, 0, fmt.Errorf("error querying order items: %w", err)
+		}
+		slog.ErrorContext(ctx, "Error querying order items", "error", err)
+	}
+
+	return orders, total, nil
+}
+
+// EachOrder calls fn with every order filter matches, with its items, in
+// the order of GetAllAfter. Orders are read a keyset page at a time, so
+// memory use and the cost of each query stay flat however many orders
+// there are. It stops at the first error from fn or the queries.
+func (r *OrderRepository) EachOrder(ctx context.Context, sort []models.SortField, filter models.OrderFilter, fn func(models.Order) error) error {
+	after := ""
+	for {
+		orders, next, err := r.GetAllAfter(ctx, after, streamBatchSize, sort, filter)
+		if err != nil {
+			return err
+		}
+		for _, order := range orders {
+			if err := fn(order); err != nil {
+				return err
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		after = next
+	}
+}
+
+// GetAllAfter returns up to limit of the orders filter matches that follow
+// the position of the after cursor, or the first orders when after is
+// empty, with their items, newest first unless sort fields are given. The
+// returned cursor continues after the last order and is empty once there
+// are no more. Unlike GetAll, the orders are not counted and no rows are
+// skipped, so later pages cost no more than the first.
+func (r *OrderRepository) GetAllAfter(ctx context.Context, after string, limit int, sort []models.SortField, filter models.OrderFilter) ([]models.Order, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	terms := keysetTerms(sort, orderKeysetColumns, []keysetTerm{{column: "created_at", desc: true}, {column: "id", desc: true}})
+	conditions, args := orderFilterClause(filter)
+	if after != "" {
+		values, err := decodeCursor(after, terms)
+		if err != nil {
+			return nil, "", err
+		}
+		condition, keyArgs := keysetCondition(terms, values, len(args)+1)
+		conditions = append(conditions, condition)
+		args = append(args, keyArgs...)
+	}
+
+	// One order more than asked for tells whether another page follows
+	ordersQuery := fmt.Sprintf(`SELECT `+orderColumns+`, %s FROM orders%s ORDER BY %s LIMIT $%d`,
+		keysetKey(terms), whereClause(conditions), keysetOrderBy(terms), len(args)+1)
+	rows, err := r.db.QueryContext(ctx, ordersQuery, append(args, limit+1)...)
+	if err != nil {
+		return nil, "", fmt.Errorf("error querying orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]models.Order, 0, limit)
+	var keys []string
+	for rows.Next() {
+		var order models.Order
+		var key string
+		if err := scanOrder(rows, &order, &key); err != nil {
+			return nil, "", fmt.Errorf("error scanning order: %w", err)
+		}
+		orders = append(orders, order)
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("error querying orders: %w", err)
+	}
+
+	next := ""
+	if len(orders) > limit {
+		orders = orders[:limit]
+		next = encodeCursor(terms, keys[limit-1])
+	}
+	if len(orders) == 0 {
+		return orders, next, nil
+	}
+
+	orderIDs := make([]string, len(orders))
+	for i, order := range orders {
+		orderIDs[i] = order.ID
+	}
+	if err := loadOrderItems(ctx, r.db, orders, orderIDs); err != nil {
+		if ctx.Err() != nil {
+			return nil, "", fmt.Errorf("error querying order items: %w", err)
+		}
+		slog.ErrorContext(ctx, "Error querying order items", "error", err)
+	}
+
+	return orders, next, nil
+}
+
+// loadOrderItems fills in the items and products of orders, whose IDs are
+// given in orderIDs, with a single query
+func loadOrderItems(ctx context.Context, db *sql.DB, orders []models.Order, orderIDs []string) error {
 	itemsQuery := `
-		SELECT oi.order_id, oi.product_id, oi.quantity, p.id, p.name, p.price, p.category
+		SELECT oi.order_id, oi.product_id, oi.quantity, oi.discount, ` + orderItemProductColumns + `
 		FROM order_items oi
-		JOIN products p ON oi.product_id = p.id
 		WHERE oi.order_id = ANY($1)
 		ORDER BY oi.order_id, oi.id`
 
-	itemRows, err := r.db.QueryContext(ctx, itemsQuery, pq.Array(orderIDs))
+	itemRows, err := db.QueryContext(ctx, itemsQuery, pq.Array(orderIDs))
 	if err != nil {
-		log.Printf("Error querying order items: %v", err)
-		return orders, total, nil
+		return err
 	}
 	defer itemRows.Close()
 
@@ -178,23 +551,317 @@ func (r *OrderRepository) GetAll(limit, offset int) ([]models.Order, int, error)
 		var product models.Product
 
 		err := itemRows.Scan(
-			&orderID, &item.ProductID, &item.Quantity,
-			&product.ID, &product.Name, &product.Price, &product.Category,
+			&orderID, &item.ProductID, &item.Quantity, &item.Discount,
+			&product.ID, &product.Name, &product.Category, &product.Price, &product.TaxRate,
 		)
 		if err != nil {
-			log.Printf("Error scanning order item: %v", err)
-			continue
+			return fmt.Errorf("error scanning order item: %w", err)
 		}
 
 		orderItemsMap[orderID] = append(orderItemsMap[orderID], item)
 		orderProductsMap[orderID] = append(orderProductsMap[orderID], product)
 	}
 
+	if err := itemRows.Err(); err != nil {
+		return err
+	}
+
 	// Populate items and products for each order
 	for i := range orders {
 		orders[i].Items = orderItemsMap[orders[i].ID]
 		orders[i].Products = orderProductsMap[orders[i].ID]
 	}
 
-	return orders, total, nil
+	return nil
+}
+
+// Count returns the number of stored orders
+func (r *OrderRepository) Count() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM orders`).Scan(&total); err != nil {
+		return 0, fmt.Errorf("error counting orders: %w", err)
+	}
+	return total, nil
+}
+
+// BackfillCheckpoint records how far a resumable backfill has progressed
+type BackfillCheckpoint struct {
+	LastID    string
+	Processed int
+	Completed bool
+}
+
+// GetBackfillCheckpoint returns the saved checkpoint for a backfill, or the
+// zero checkpoint if it has never run
+func (r *OrderRepository) GetBackfillCheckpoint(name string) (BackfillCheckpoint, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT last_id, processed, completed_at IS NOT NULL FROM data_backfills WHERE name = $1`
+	var cp BackfillCheckpoint
+	err := r.db.QueryRowContext(ctx, query, name).Scan(&cp.LastID, &cp.Processed, &cp.Completed)
+	if err == sql.ErrNoRows {
+		return BackfillCheckpoint{}, nil
+	}
+	if err != nil {
+		return BackfillCheckpoint{}, fmt.Errorf("error querying backfill checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// ResetBackfillCheckpoint discards saved progress so a backfill starts over
+func (r *OrderRepository) ResetBackfillCheckpoint(name string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM data_backfills WHERE name = $1`, name); err != nil {
+		return fmt.Errorf("failed to reset backfill checkpoint: %w", err)
+	}
+	return nil
+}
+
+// BackfillTotalsBatch recomputes the totals of up to batchSize orders with an
+// ID greater than afterID from the unit prices recorded on their items, less
+// the order discount.
+// The checkpoint is advanced in the same transaction, so an interrupted run
+// resumes exactly after the last committed chunk. It returns the last order
+// ID processed and the number of orders updated; zero means nothing was left.
+func (r *OrderRepository) BackfillTotalsBatch(checkpoint, afterID string, batchSize int) (string, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	updateQuery := `
+		WITH batch AS (
+			SELECT id FROM orders WHERE id > $1 ORDER BY id LIMIT $2
+		), totals AS (
+			SELECT b.id, COALESCE(SUM(oi.quantity * oi.unit_price), 0) AS total
+			FROM batch b
+			LEFT JOIN order_items oi ON oi.order_id = b.id
+			GROUP BY b.id
+		)
+		UPDATE orders o SET total = GREATEST(t.total - o.discount, 0)
+		FROM totals t
+		WHERE o.id = t.id
+		RETURNING o.id`
+
+	rows, err := tx.QueryContext(ctx, updateQuery, afterID, batchSize)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to recompute order totals: %w", err)
+	}
+
+	lastID := afterID
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return "", 0, fmt.Errorf("error scanning order id: %w", err)
+		}
+		if id > lastID {
+			lastID = id
+		}
+		ids = append(ids, id)
+	}
+	count := len(ids)
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", 0, fmt.Errorf("failed to recompute order totals: %w", err)
+	}
+
+	checkpointQuery := `
+		INSERT INTO data_backfills (name, last_id, processed, completed_at, updated_at)
+		VALUES ($1, $2, $3, CASE WHEN $4 THEN NOW() END, NOW())
+		ON CONFLICT (name) DO UPDATE
+		SET last_id = EXCLUDED.last_id,
+		    processed = data_backfills.processed + EXCLUDED.processed,
+		    completed_at = EXCLUDED.completed_at,
+		    updated_at = NOW()`
+	if _, err := tx.ExecContext(ctx, checkpointQuery, checkpoint, lastID, count, count < batchSize); err != nil {
+		return "", 0, fmt.Errorf("failed to save backfill checkpoint: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.changes.Record(ChangedTableOrders, ids...)
+	return lastID, count, nil
+}
+
+// CountInWindows returns how many orders were created in [from, to) and in
+// the same window on each of the days previous days, starting with the
+// window itself
+func (r *OrderRepository) CountInWindows(ctx context.Context, from, to time.Time, days int) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	query := `SELECT d, COUNT(o.id)
+	          FROM generate_series(0, $3::int) AS d
+	          LEFT JOIN orders o
+	            ON o.created_at >= $1::timestamptz - make_interval(days => d)
+	           AND o.created_at < $2::timestamptz - make_interval(days => d)
+	          GROUP BY d
+	          ORDER BY d`
+	rows, err := r.db.QueryContext(ctx, query, from, to, days)
+	if err != nil {
+		return nil, fmt.Errorf("error counting orders: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]int, days+1)
+	for rows.Next() {
+		var day, count int
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, fmt.Errorf("error scanning order count: %w", err)
+		}
+		if day >= 0 && day <= days {
+			counts[day] = count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error counting orders: %w", err)
+	}
+	return counts, nil
+}
+
+// ListCreatedBefore returns up to limit of the oldest orders created before
+// cutoff, with their items, item modifiers, products, delivery and payment,
+// for archiving. Delivery personal data stays sealed as stored, so the
+// archive holds it no less protected than the database; OpenDelivery
+// decrypts it on the way back.
+func (r *OrderRepository) ListCreatedBefore(cutoff time.Time, limit int) ([]models.ArchivedOrder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + orderColumns + `, created_at
+	          FROM orders
+	          WHERE created_at < $1
+	          ORDER BY created_at, id
+	          LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, query, cutoff, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying orders to archive: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]models.Order, 0)
+	createdAt := make([]time.Time, 0)
+	orderIDs := make([]string, 0)
+
+	for rows.Next() {
+		var order models.Order
+		var created time.Time
+		if err := scanOrder(rows, &order, &created); err != nil {
+			return nil, fmt.Errorf("error scanning order: %w", err)
+		}
+		orders = append(orders, order)
+		createdAt = append(createdAt, created)
+		orderIDs = append(orderIDs, order.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying orders to archive: %w", err)
+	}
+	if len(orders) == 0 {
+		return nil, nil
+	}
+
+	// Archiving an order without its items would lose them, so unlike
+	// GetAll an item query failure is an error here
+	if err := loadOrderItems(ctx, r.db, orders, orderIDs); err != nil {
+		return nil, fmt.Errorf("error querying order items: %w", err)
+	}
+	modifiers, err := getItemModifiers(ctx, r.db, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	// Deleting the orders cascades to their addresses and payments, so they
+	// go into the archive with them
+	deliveries, err := r.getSealedDeliveries(ctx, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := r.getPayments(ctx, orderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	archived := make([]models.ArchivedOrder, len(orders))
+	for i, order := range orders {
+		modifiers.attach(order.ID, order.Items)
+		order.Delivery, order.Payment = deliveries[order.ID], payments[order.ID]
+		archived[i] = models.ArchivedOrder{Order: order, CreatedAt: createdAt[i]}
+	}
+	return archived, nil
+}
+
+// MarkArchived records that orders were written to the archive object with
+// the given key and deletes them, with their items, addresses and payments,
+// in one transaction.
+// It returns the number of orders deleted.
+func (r *OrderRepository) MarkArchived(orders []models.ArchivedOrder, objectKey string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// A rerun after a crash between writing the object and this commit
+	// archives the same orders again; the newest object wins
+	indexQuery := `INSERT INTO archived_orders (order_id, object_key, order_created_at, archived_at)
+	               VALUES ($1, $2, $3, NOW())
+	               ON CONFLICT (order_id) DO UPDATE
+	               SET object_key = EXCLUDED.object_key, archived_at = NOW()`
+	ids := make([]string, len(orders))
+	for i, order := range orders {
+		if _, err := tx.ExecContext(ctx, indexQuery, order.ID, objectKey, order.CreatedAt); err != nil {
+			return 0, fmt.Errorf("failed to index archived order: %w", err)
+		}
+		ids[i] = order.ID
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM orders WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete archived orders: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete archived orders: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.changes.Record(ChangedTableOrders, ids...)
+	return int(deleted), nil
+}
+
+// GetArchiveObjectKey returns the key of the archive object holding an
+// archived order, or "" if the order was never archived
+func (r *OrderRepository) GetArchiveObjectKey(id string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var objectKey string
+	err := r.db.QueryRowContext(ctx, `SELECT object_key FROM archived_orders WHERE order_id = $1`, id).Scan(&objectKey)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error querying archived order: %w", err)
+	}
+
+	return objectKey, nil
 }
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/pii"
+)
+
+// ErrPartnerNotFound is returned when a partner does not exist
+var ErrPartnerNotFound = errors.New("partner not found")
+
+// PartnerRepository handles partner and partner API key data operations.
+// Contact details pass through codec on their way to and from the database.
+type PartnerRepository struct {
+	db    *sql.DB
+	codec pii.Codec
+}
+
+// NewPartnerRepository creates a new partner repository. A nil codec stores
+// contact details in plaintext.
+func NewPartnerRepository(db *sql.DB, codec pii.Codec) *PartnerRepository {
+	if codec == nil {
+		codec = pii.Plaintext{}
+	}
+	return &PartnerRepository{db: db, codec: codec}
+}
+
+// partnerColumns is the select list shared by partner queries
+const partnerColumns = `id, name, contact_email, status, scopes, created_at`
+
+func (r *PartnerRepository) scanPartner(row rowScanner, partner *models.Partner) error {
+	var contactEmail string
+	err := row.Scan(&partner.ID, &partner.Name, &contactEmail, &partner.Status,
+		pq.Array(&partner.Scopes), &partner.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	partner.ContactEmail, err = r.codec.Decode(contactEmail)
+	if err != nil {
+		return fmt.Errorf("failed to decode partner contact email: %w", err)
+	}
+	return nil
+}
+
+// CreateWithKey stores a new partner together with its first API key
+func (r *PartnerRepository) CreateWithKey(partner *models.Partner, keyPrefix, keyHash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	contactEmail, err := r.codec.Encode(partner.ContactEmail)
+	if err != nil {
+		return fmt.Errorf("failed to encode partner contact email: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	partnerQuery := `INSERT INTO partners (id, name, contact_email, status, scopes, created_at, updated_at)
+	                 VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+	                 RETURNING created_at`
+	err = tx.QueryRowContext(ctx, partnerQuery, partner.ID, partner.Name, contactEmail,
+		partner.Status, pq.Array(partner.Scopes)).Scan(&partner.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert partner: %w", err)
+	}
+
+	keyQuery := `INSERT INTO partner_api_keys (partner_id, key_prefix, key_hash, created_at)
+	             VALUES ($1, $2, $3, NOW())`
+	if _, err := tx.ExecContext(ctx, keyQuery, partner.ID, keyPrefix, keyHash); err != nil {
+		return fmt.Errorf("failed to insert partner API key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID returns a partner by ID
+func (r *PartnerRepository) GetByID(id string) (models.Partner, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + partnerColumns + ` FROM partners WHERE id = $1`
+	var partner models.Partner
+	err := r.scanPartner(r.db.QueryRowContext(ctx, query, id), &partner)
+	if err == sql.ErrNoRows {
+		return models.Partner{}, ErrPartnerNotFound
+	}
+	if err != nil {
+		return models.Partner{}, fmt.Errorf("error querying partner: %w", err)
+	}
+
+	return partner, nil
+}
+
+// GetAll returns partners with pagination, optionally filtered by status
+func (r *PartnerRepository) GetAll(status string, limit, offset int) ([]models.Partner, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM partners WHERE ($1 = '' OR status = $1)`
+	if err := r.db.QueryRowContext(ctx, countQuery, status).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error counting partners: %w", err)
+	}
+
+	query := `SELECT ` + partnerColumns + ` FROM partners
+	          WHERE ($1 = '' OR status = $1)
+	          ORDER BY created_at, id
+	          LIMIT $2 OFFSET $3`
+	rows, err := r.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying partners: %w", err)
+	}
+	defer rows.Close()
+
+	partners := make([]models.Partner, 0)
+	for rows.Next() {
+		var partner models.Partner
+		if err := r.scanPartner(rows, &partner); err != nil {
+			return nil, 0, fmt.Errorf("error scanning partner: %w", err)
+		}
+		partners = append(partners, partner)
+	}
+
+	return partners, total, rows.Err()
+}
+
+// UpdateStatus changes the approval state of a partner
+func (r *PartnerRepository) UpdateStatus(id, status string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE partners SET status = $2, updated_at = NOW() WHERE id = $1`, id, status)
+	if err != nil {
+		return fmt.Errorf("failed to update partner status: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update partner status: %w", err)
+	}
+	if affected == 0 {
+		return ErrPartnerNotFound
+	}
+
+	return nil
+}
+
+// RotateKey issues a new key for a partner and schedules every key that is
+// still active to expire at retireAt, all in one transaction
+func (r *PartnerRepository) RotateKey(partnerID, keyPrefix, keyHash string, retireAt time.Time) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	retireQuery := `UPDATE partner_api_keys SET expires_at = $2
+	                WHERE partner_id = $1 AND (expires_at IS NULL OR expires_at > $2)`
+	if _, err := tx.ExecContext(ctx, retireQuery, partnerID, retireAt); err != nil {
+		return time.Time{}, fmt.Errorf("failed to retire partner API keys: %w", err)
+	}
+
+	var createdAt time.Time
+	keyQuery := `INSERT INTO partner_api_keys (partner_id, key_prefix, key_hash, created_at)
+	             VALUES ($1, $2, $3, NOW())
+	             RETURNING created_at`
+	if err := tx.QueryRowContext(ctx, keyQuery, partnerID, keyPrefix, keyHash).Scan(&createdAt); err != nil {
+		return time.Time{}, fmt.Errorf("failed to insert partner API key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return createdAt, nil
+}
+
+// FindByKeyHash returns the partner owning an unexpired key with the given hash
+func (r *PartnerRepository) FindByKeyHash(keyHash string) (models.Partner, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT p.id, p.name, p.contact_email, p.status, p.scopes, p.created_at
+	          FROM partner_api_keys k
+	          JOIN partners p ON p.id = k.partner_id
+	          WHERE k.key_hash = $1 AND (k.expires_at IS NULL OR k.expires_at > NOW())`
+	var partner models.Partner
+	err := r.scanPartner(r.db.QueryRowContext(ctx, query, keyHash), &partner)
+	if err == sql.ErrNoRows {
+		return models.Partner{}, ErrPartnerNotFound
+	}
+	if err != nil {
+		return models.Partner{}, fmt.Errorf("error querying partner API key: %w", err)
+	}
+
+	return partner, nil
+}
+
+// ReencryptContactDetails rewrites every partner contact email that is
+// plaintext or sealed with an old key using the current key of cipher.
+// It returns the number of partners rewritten.
+func (r *PartnerRepository) ReencryptContactDetails(cipher *pii.Cipher) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, contact_email FROM partners ORDER BY id`)
+	if err != nil {
+		return 0, fmt.Errorf("error querying partners: %w", err)
+	}
+
+	stale := make(map[string]string)
+	for rows.Next() {
+		var id, stored string
+		if err := rows.Scan(&id, &stored); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error scanning partner: %w", err)
+		}
+		if cipher.NeedsRotation(stored) {
+			stale[id] = stored
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error querying partners: %w", err)
+	}
+
+	rewritten := 0
+	for id, stored := range stale {
+		plain, err := cipher.Decode(stored)
+		if err != nil {
+			return rewritten, fmt.Errorf("failed to decrypt contact email of partner %s: %w", id, err)
+		}
+		sealed, err := cipher.Encode(plain)
+		if err != nil {
+			return rewritten, err
+		}
+
+		// Only overwrite the value that was read, in case it changed meanwhile
+		result, err := r.db.ExecContext(ctx,
+			`UPDATE partners SET contact_email = $2 WHERE id = $1 AND contact_email = $3`, id, sealed, stored)
+		if err != nil {
+			return rewritten, fmt.Errorf("failed to update partner %s: %w", id, err)
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			rewritten++
+		}
+	}
+
+	return rewritten, nil
+}
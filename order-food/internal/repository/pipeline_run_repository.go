@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// pipelineRunColumns is the select list shared by pipeline run queries
+const pipelineRunColumns = `id, operation_id, job, instance_id, status, COALESCE(summary, ''), COALESCE(error, ''),
+	started_at, finished_at`
+
+// PipelineRunRepository reads the runs recorded by the migration and load
+// jobs; order-food never writes them
+type PipelineRunRepository struct {
+	db *sql.DB
+}
+
+// NewPipelineRunRepository creates a new pipeline run repository
+func NewPipelineRunRepository(db *sql.DB) *PipelineRunRepository {
+	return &PipelineRunRepository{db: db}
+}
+
+func scanPipelineRun(row rowScanner, run *models.PipelineRun) error {
+	var finishedAt sql.NullTime
+	if err := row.Scan(&run.ID, &run.OperationID, &run.Job, &run.InstanceID, &run.Status, &run.Summary,
+		&run.Error, &run.StartedAt, &finishedAt); err != nil {
+		return err
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	return nil
+}
+
+// GetAll returns pipeline runs with pagination, newest first. An empty
+// status returns runs of every status.
+func (r *PipelineRunRepository) GetAll(status string, limit, offset int) ([]models.PipelineRun, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var total int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM pipeline_runs WHERE $1 = '' OR status = $1`, status).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting pipeline runs: %w", err)
+	}
+
+	query := `SELECT ` + pipelineRunColumns + ` FROM pipeline_runs
+	          WHERE $1 = '' OR status = $1
+	          ORDER BY started_at DESC, id DESC
+	          LIMIT $2 OFFSET $3`
+	rows, err := r.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying pipeline runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]models.PipelineRun, 0)
+	for rows.Next() {
+		var run models.PipelineRun
+		if err := scanPipelineRun(rows, &run); err != nil {
+			return nil, 0, fmt.Errorf("error scanning pipeline run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, total, rows.Err()
+}
+
+// GetByOperation returns the runs of an operation in the order they started
+func (r *PipelineRunRepository) GetByOperation(operationID string) ([]models.PipelineRun, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + pipelineRunColumns + ` FROM pipeline_runs
+	          WHERE operation_id = $1
+	          ORDER BY started_at, id`
+	rows, err := r.db.QueryContext(ctx, query, operationID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying pipeline runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make([]models.PipelineRun, 0)
+	for rows.Next() {
+		var run models.PipelineRun
+		if err := scanPipelineRun(rows, &run); err != nil {
+			return nil, fmt.Errorf("error scanning pipeline run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
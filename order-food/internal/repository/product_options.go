@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// GetOptionGroups returns the option groups of the given products with
+// their options, in menu order, keyed by product ID. Products without
+// option groups have no entry.
+func (r *ProductRepository) GetOptionGroups(ctx context.Context, ids []string) (map[string][]models.OptionGroup, error) {
+	groups := make(map[string][]models.OptionGroup)
+	if len(ids) == 0 {
+		return groups, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT g.product_id, g.id, g.name, g.min_select, g.max_select, o.id, o.name, o.price
+	          FROM product_option_groups g
+	          JOIN product_options o ON o.product_id = g.product_id AND o.group_id = g.id
+	          WHERE g.product_id = ANY($1)
+	          ORDER BY g.product_id, g.position, g.id, o.position, o.id`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("error querying option groups: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID string
+		var group models.OptionGroup
+		var option models.ProductOption
+		if err := rows.Scan(&productID, &group.ID, &group.Name, &group.MinSelect, &group.MaxSelect,
+			&option.ID, &option.Name, &option.Price); err != nil {
+			return nil, fmt.Errorf("error scanning option group: %w", err)
+		}
+		productGroups := groups[productID]
+		if n := len(productGroups); n == 0 || productGroups[n-1].ID != group.ID {
+			productGroups = append(productGroups, group)
+		}
+		last := &productGroups[len(productGroups)-1]
+		last.Options = append(last.Options, option)
+		groups[productID] = productGroups
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying option groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// attachOptionGroups loads the option groups of the given products with a
+// single query and stores them on each product
+func (r *ProductRepository) attachOptionGroups(ctx context.Context, products []models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	groups, err := r.GetOptionGroups(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		products[i].OptionGroups = groups[products[i].ID]
+	}
+	return nil
+}
+
+// SetOptionGroups replaces the option groups of a product together with
+// the audit entry and a cache invalidation. ErrProductNotFound is returned
+// when the product does not exist.
+func (r *ProductRepository) SetOptionGroups(id string, groups []models.OptionGroup, audit models.AuditEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Touching the product locks it, so concurrent replacements of its
+	// option groups apply one after the other
+	result, err := tx.ExecContext(ctx, `UPDATE products SET updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("error writing product: %w", err)
+	}
+	changed, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error writing product: %w", err)
+	}
+	if changed == 0 {
+		return ErrProductNotFound
+	}
+
+	// Deleting the groups deletes their options too
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_option_groups WHERE product_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete option groups: %w", err)
+	}
+	groupQuery := `INSERT INTO product_option_groups (product_id, id, name, min_select, max_select, position)
+	               VALUES ($1, $2, $3, $4, $5, $6)`
+	optionQuery := `INSERT INTO product_options (product_id, group_id, id, name, price, position)
+	                VALUES ($1, $2, $3, $4, $5, $6)`
+	for i, group := range groups {
+		if _, err := tx.ExecContext(ctx, groupQuery, id, group.ID, group.Name, group.MinSelect, group.MaxSelect, i); err != nil {
+			return fmt.Errorf("failed to insert option group: %w", err)
+		}
+		for j, option := range group.Options {
+			if _, err := tx.ExecContext(ctx, optionQuery, id, group.ID, option.ID, option.Name, option.Price, j); err != nil {
+				return fmt.Errorf("failed to insert option: %w", err)
+			}
+		}
+	}
+
+	if _, err := insertAuditEntry(ctx, tx, audit); err != nil {
+		return err
+	}
+	if err := publishInTx(ctx, tx, models.InvalidationTopicProducts, []string{id}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.changes.Record(ChangedTableProducts, id)
+	return nil
+}
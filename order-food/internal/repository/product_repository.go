@@ -6,25 +6,70 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/app"
 )
 
+// productCategoryName selects the name of the category of a product, which
+// products refer to by ID
+const productCategoryName = `(SELECT c.name FROM categories c WHERE c.id = products.category_id)`
+
+// productColumns is the select list shared by all product queries; optional
+// identifiers are coalesced so they scan into plain strings
+const productColumns = `id, name, price, ` + productCategoryName + `, COALESCE(sku, ''), COALESCE(barcode, ''), COALESCE(description, '')`
+
+// productCategoryID selects the ID of the category named by a parameter
+const productCategoryID = `(SELECT id FROM categories WHERE name = $%d)`
+
+var (
+	// ErrPriceConflict is returned when a price changed between computing a
+	// bulk update and applying it
+	ErrPriceConflict = errors.New("product price changed concurrently")
+	// ErrProductNotFound is returned when a product does not exist or has
+	// been deleted
+	ErrProductNotFound = errors.New("product not found")
+	// ErrProductExists is returned when creating a product whose ID is taken
+	ErrProductExists = errors.New("product already exists")
+	// ErrProductIdentifierTaken is returned when another product has the
+	// same SKU or barcode
+	ErrProductIdentifierTaken = errors.New("sku or barcode belongs to another product")
+)
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanProduct reads a row selected with productColumns
+func scanProduct(row rowScanner, product *models.Product) error {
+	return row.Scan(&product.ID, &product.Name, &product.Price, &product.Category, &product.SKU, &product.Barcode, &product.Description)
+}
+
 // ProductRepository handles product data operations
 type ProductRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	changes ChangeRecorder
 }
 
 // NewProductRepository creates a new product repository with an existing database connection
 func NewProductRepository(db *sql.DB) *ProductRepository {
 	return &ProductRepository{
-		db: db,
+		db:      db,
+		changes: noChanges{},
 	}
 }
 
+// SetChangeRecorder makes the repository report the products it writes to
+// changes
+func (r *ProductRepository) SetChangeRecorder(changes ChangeRecorder) {
+	r.changes = changes
+}
+
 // NewProductRepositoryWithConnection creates a new product repository and establishes a connection
 func NewProductRepositoryWithConnection() *ProductRepository {
 	db, err := connectDB()
@@ -33,18 +78,19 @@ func NewProductRepositoryWithConnection() *ProductRepository {
 	}
 
 	return &ProductRepository{
-		db: db,
+		db:      db,
+		changes: noChanges{},
 	}
 }
 
 // connectDB establishes a connection to PostgreSQL
 func connectDB() (*sql.DB, error) {
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbUser := getEnv("DB_USER", "postgres")
-	dbPassword := getEnv("DB_PASSWORD", "postgres")
-	dbName := getEnv("DB_NAME", "orderfood")
-	dbSSLMode := getEnv("DB_SSLMODE", "disable")
+	dbHost := app.Getenv("DB_HOST", "localhost")
+	dbPort := app.Getenv("DB_PORT", "5432")
+	dbUser := app.Getenv("DB_USER", "postgres")
+	dbPassword := app.Getenv("DB_PASSWORD", "postgres")
+	dbName := app.Getenv("DB_NAME", "orderfood")
+	dbSSLMode := app.Getenv("DB_SSLMODE", "disable")
 
 	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		dbHost, dbPort, dbUser, dbPassword, dbName, dbSSLMode)
@@ -60,32 +106,25 @@ func connectDB() (*sql.DB, error) {
 
 	for i := 0; i < 10; i++ {
 		if err := db.PingContext(ctx); err == nil {
-			log.Println("Successfully connected to products database")
+			slog.Info("Successfully connected to products database")
 			return db, nil
 		}
-		log.Printf("Waiting for database connection... (attempt %d/10)", i+1)
+		slog.Info("Waiting for database connection", "attempt", i+1, "attempts", 10)
 		time.Sleep(2 * time.Second)
 	}
 
 	return nil, fmt.Errorf("failed to connect to database after retries")
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
 // GetAll returns all products
 func (r *ProductRepository) GetAll() []models.Product {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, name, price, category FROM products ORDER BY id`
+	query := `SELECT ` + productColumns + ` FROM products WHERE deleted_at IS NULL ORDER BY id`
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
-		log.Printf("Error querying products: %v", err)
+		slog.ErrorContext(ctx, "Error querying products", "error", err)
 		return []models.Product{}
 	}
 	defer rows.Close()
@@ -93,31 +132,44 @@ func (r *ProductRepository) GetAll() []models.Product {
 	products := make([]models.Product, 0)
 	for rows.Next() {
 		var product models.Product
-		if err := rows.Scan(&product.ID, &product.Name, &product.Price, &product.Category); err != nil {
-			log.Printf("Error scanning product: %v", err)
+		if err := scanProduct(rows, &product); err != nil {
+			slog.ErrorContext(ctx, "Error scanning product", "error", err)
 			continue
 		}
 		products = append(products, product)
 	}
 
+	if err := r.attachCurrencyPrices(ctx, products); err != nil {
+		slog.ErrorContext(ctx, "Error loading currency prices", "error", err)
+	}
+	if err := r.attachTranslations(ctx, products); err != nil {
+		slog.ErrorContext(ctx, "Error loading product translations", "error", err)
+	}
+	if err := r.attachOptionGroups(ctx, products); err != nil {
+		slog.ErrorContext(ctx, "Error loading option groups", "error", err)
+	}
+
 	return products
 }
 
-// GetAllPaginated returns paginated products with total count
-func (r *ProductRepository) GetAllPaginated(limit, offset int) ([]models.Product, int, error) {
+// GetAllPaginated returns paginated products matching filter with their
+// total count, ordered by the given sort fields (by id when none are given)
+func (r *ProductRepository) GetAllPaginated(limit, offset int, sort []models.SortField, filter models.ProductFilter) ([]models.Product, int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Get total count
+	where, args := productFilterClause(filter)
 	var total int
-	countQuery := `SELECT COUNT(*) FROM products`
-	if err := r.db.QueryRowContext(ctx, countQuery).Scan(&total); err != nil {
+	countQuery := `SELECT COUNT(*) FROM products ` + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, 0, fmt.Errorf("error counting products: %w", err)
 	}
 
 	// Get paginated results
-	query := `SELECT id, name, price, category FROM products ORDER BY id LIMIT $1 OFFSET $2`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	query := fmt.Sprintf(`SELECT `+productColumns+` FROM products %s ORDER BY %s LIMIT $%d OFFSET $%d`,
+		where, orderByClause(sort, productSortColumns, "id"), len(args)+1, len(args)+2)
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("error querying products: %w", err)
 	}
@@ -126,38 +178,224 @@ func (r *ProductRepository) GetAllPaginated(limit, offset int) ([]models.Product
 	products := make([]models.Product, 0)
 	for rows.Next() {
 		var product models.Product
-		if err := rows.Scan(&product.ID, &product.Name, &product.Price, &product.Category); err != nil {
-			log.Printf("Error scanning product: %v", err)
+		if err := scanProduct(rows, &product); err != nil {
+			slog.ErrorContext(ctx, "Error scanning product", "error", err)
 			continue
 		}
 		products = append(products, product)
 	}
 
+	if err := r.attachCurrencyPrices(ctx, products); err != nil {
+		return nil, 0, err
+	}
+	if err := r.attachTranslations(ctx, products); err != nil {
+		return nil, 0, err
+	}
+	if err := r.attachOptionGroups(ctx, products); err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+// GetPageAfter returns up to limit products matching filter that follow
+// the position of the after cursor, or the first products when after is
+// empty, ordered by the given sort fields (by id when none are given). The
+// returned cursor continues after the last product and is empty once there
+// are no more. Unlike GetAllPaginated, the products are not counted and no
+// rows are skipped, so later pages cost no more than the first.
+func (r *ProductRepository) GetPageAfter(after string, limit int, sort []models.SortField, filter models.ProductFilter) ([]models.Product, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	terms := keysetTerms(sort, productSortColumns, []keysetTerm{{column: "id"}})
+	where, args := productFilterClause(filter)
+	if after != "" {
+		values, err := decodeCursor(after, terms)
+		if err != nil {
+			return nil, "", err
+		}
+		condition, keyArgs := keysetCondition(terms, values, len(args)+1)
+		where += " AND " + condition
+		args = append(args, keyArgs...)
+	}
+
+	// One product more than asked for tells whether another page follows
+	query := fmt.Sprintf(`SELECT `+productColumns+`, %s FROM products %s ORDER BY %s LIMIT $%d`,
+		keysetKey(terms), where, keysetOrderBy(terms), len(args)+1)
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit+1)...)
+	if err != nil {
+		return nil, "", fmt.Errorf("error querying products: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]models.Product, 0, limit)
+	var keys []string
+	for rows.Next() {
+		var product models.Product
+		var key string
+		if err := scanProduct(keyScanner{row: rows, key: &key}, &product); err != nil {
+			return nil, "", fmt.Errorf("error scanning product: %w", err)
+		}
+		products = append(products, product)
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("error querying products: %w", err)
+	}
+
+	next := ""
+	if len(products) > limit {
+		products = products[:limit]
+		next = encodeCursor(terms, keys[limit-1])
+	}
+
+	if err := r.attachCurrencyPrices(ctx, products); err != nil {
+		return nil, "", err
+	}
+	if err := r.attachTranslations(ctx, products); err != nil {
+		return nil, "", err
+	}
+	if err := r.attachOptionGroups(ctx, products); err != nil {
+		return nil, "", err
+	}
+
+	return products, next, nil
+}
+
+// EachProduct calls fn with every product matching filter in the order of
+// GetPageAfter, reading a keyset page at a time. It stops at the first
+// error from fn or the queries, or once ctx is done.
+func (r *ProductRepository) EachProduct(ctx context.Context, sort []models.SortField, filter models.ProductFilter, fn func(models.Product) error) error {
+	after := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		products, next, err := r.GetPageAfter(after, streamBatchSize, sort, filter)
+		if err != nil {
+			return err
+		}
+		for _, product := range products {
+			if err := fn(product); err != nil {
+				return err
+			}
+		}
+		if next == "" {
+			return nil
+		}
+		after = next
+	}
+}
+
+// productSearchMatch matches the products whose search document or
+// category name matches the search query q
+const productSearchMatch = `(search_vector @@ q OR category_id IN (SELECT id FROM categories WHERE to_tsvector('english', name) @@ q))`
+
+// Search returns the live products matching a web-style search query,
+// best match first, with the total number of matches. The query accepts
+// quoted phrases, "or" and a leading - to exclude a word. Products only
+// matched by the name of their category come last.
+func (r *ProductRepository) Search(text string, limit, offset int) ([]models.Product, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Get total count
+	var total int
+	countQuery := `SELECT COUNT(*) FROM products, websearch_to_tsquery('english', $1) AS q
+		WHERE deleted_at IS NULL AND ` + productSearchMatch
+	if err := r.db.QueryRowContext(ctx, countQuery, text).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error counting matching products: %w", err)
+	}
+
+	// Get ranked results
+	query := `SELECT ` + productColumns + ` FROM products, websearch_to_tsquery('english', $1) AS q
+		WHERE deleted_at IS NULL AND ` + productSearchMatch + `
+		ORDER BY ts_rank(search_vector, q) DESC, id
+		LIMIT $2 OFFSET $3`
+	rows, err := r.db.QueryContext(ctx, query, text, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error searching products: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	for rows.Next() {
+		var product models.Product
+		if err := scanProduct(rows, &product); err != nil {
+			return nil, 0, fmt.Errorf("error scanning product: %w", err)
+		}
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error searching products: %w", err)
+	}
+
+	if err := r.attachCurrencyPrices(ctx, products); err != nil {
+		return nil, 0, err
+	}
+	if err := r.attachTranslations(ctx, products); err != nil {
+		return nil, 0, err
+	}
+	if err := r.attachOptionGroups(ctx, products); err != nil {
+		return nil, 0, err
+	}
+
 	return products, total, nil
 }
 
+// productFilterClause builds the WHERE clause selecting the live products
+// matching filter, with its arguments numbered from $1
+func productFilterClause(filter models.ProductFilter) (string, []any) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []any
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.CategoryID != "" {
+		add("category_id = $%d", filter.CategoryID)
+	}
+	if filter.Category != "" {
+		add("category_id = "+productCategoryID, filter.Category)
+	}
+	if filter.MinPrice != nil {
+		add("price >= $%d", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		add("price <= $%d", *filter.MaxPrice)
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
 // GetByID returns a product by ID
 func (r *ProductRepository) GetByID(id string) (models.Product, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	query := `SELECT id, name, price, category FROM products WHERE id = $1`
+	query := `SELECT ` + productColumns + ` FROM products WHERE id = $1 AND deleted_at IS NULL`
 	var product models.Product
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&product.ID,
-		&product.Name,
-		&product.Price,
-		&product.Category,
-	)
+	err := scanProduct(r.db.QueryRowContext(ctx, query, id), &product)
 
 	if err == sql.ErrNoRows {
-		return models.Product{}, errors.New("product not found")
+		return models.Product{}, ErrProductNotFound
 	}
 	if err != nil {
 		return models.Product{}, fmt.Errorf("error querying product: %w", err)
 	}
 
-	return product, nil
+	products := []models.Product{product}
+	if err := r.attachCurrencyPrices(ctx, products); err != nil {
+		return models.Product{}, err
+	}
+	if err := r.attachTranslations(ctx, products); err != nil {
+		return models.Product{}, err
+	}
+	if err := r.attachOptionGroups(ctx, products); err != nil {
+		return models.Product{}, err
+	}
+
+	return products[0], nil
 }
 
 // GetByIDs returns multiple products by their IDs
@@ -170,7 +408,7 @@ func (r *ProductRepository) GetByIDs(ids []string) ([]models.Product, error) {
 	defer cancel()
 
 	// Build query with placeholders
-	query := `SELECT id, name, price, category FROM products WHERE id = ANY($1)`
+	query := `SELECT ` + productColumns + ` FROM products WHERE id = ANY($1) AND deleted_at IS NULL`
 
 	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
 	if err != nil {
@@ -183,7 +421,7 @@ func (r *ProductRepository) GetByIDs(ids []string) ([]models.Product, error) {
 
 	for rows.Next() {
 		var product models.Product
-		if err := rows.Scan(&product.ID, &product.Name, &product.Price, &product.Category); err != nil {
+		if err := scanProduct(rows, &product); err != nil {
 			return nil, fmt.Errorf("error scanning product: %w", err)
 		}
 		products = append(products, product)
@@ -193,9 +431,296 @@ func (r *ProductRepository) GetByIDs(ids []string) ([]models.Product, error) {
 	// Check if all requested IDs were found
 	for _, id := range ids {
 		if !foundIDs[id] {
-			return nil, errors.New("product not found: " + id)
+			return nil, fmt.Errorf("%w: %s", ErrProductNotFound, id)
 		}
 	}
 
 	return products, nil
 }
+
+// GetByCategoriesOrIDs returns the products in any of categories or with
+// any of ids, ordered by id
+func (r *ProductRepository) GetByCategoriesOrIDs(categories, ids []string) ([]models.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + productColumns + ` FROM products
+	          WHERE (category_id IN (SELECT id FROM categories WHERE name = ANY($1)) OR id = ANY($2)) AND deleted_at IS NULL
+	          ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(categories), pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("error querying products: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]models.Product, 0)
+	for rows.Next() {
+		var product models.Product
+		if err := scanProduct(rows, &product); err != nil {
+			return nil, fmt.Errorf("error scanning product: %w", err)
+		}
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying products: %w", err)
+	}
+
+	return products, nil
+}
+
+// UpdatePrices applies changes in a single transaction together with the
+// audit entry and a cache invalidation per product, and returns the audit
+// entry's ID. Each update only succeeds while the product still has its
+// old price; otherwise nothing is changed and ErrPriceConflict is returned.
+func (r *ProductRepository) UpdatePrices(changes []models.PriceChange, audit models.AuditEntry) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE products SET price = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND price = $3`
+	ids := make([]string, len(changes))
+	for i, change := range changes {
+		result, err := tx.ExecContext(ctx, query, change.ProductID, change.NewPrice, change.OldPrice)
+		if err != nil {
+			return 0, fmt.Errorf("error updating product price: %w", err)
+		}
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("error updating product price: %w", err)
+		}
+		if updated == 0 {
+			return 0, fmt.Errorf("%w: %s", ErrPriceConflict, change.ProductID)
+		}
+		ids[i] = change.ProductID
+	}
+
+	auditID, err := insertAuditEntry(ctx, tx, audit)
+	if err != nil {
+		return 0, err
+	}
+	if err := publishInTx(ctx, tx, models.InvalidationTopicProducts, ids); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.changes.Record(ChangedTableProducts, ids...)
+	return auditID, nil
+}
+
+// Create stores a new product together with the audit entry and a cache
+// invalidation. A deleted product with the same ID is brought back with the
+// new details and untracked stock; its currency prices, translations and
+// option groups are kept. ErrProductExists is returned when the ID is taken,
+// ErrProductIdentifierTaken when the SKU or barcode is and
+// ErrCategoryNotFound when the category does not exist.
+func (r *ProductRepository) Create(product models.Product, audit models.AuditEntry) error {
+	query := `INSERT INTO products (id, name, price, category_id, sku, barcode, description, created_at, updated_at)
+	          VALUES ($1, $2, $3, ` + fmt.Sprintf(productCategoryID, 4) + `, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NOW(), NOW())
+	          ON CONFLICT (id) DO UPDATE
+	          SET name = EXCLUDED.name,
+	              price = EXCLUDED.price,
+	              category_id = EXCLUDED.category_id,
+	              sku = EXCLUDED.sku,
+	              barcode = EXCLUDED.barcode,
+	              description = EXCLUDED.description,
+	              stock = NULL,
+	              created_at = NOW(),
+	              updated_at = NOW(),
+	              deleted_at = NULL
+	          WHERE products.deleted_at IS NOT NULL`
+	return r.write(product.ID, ErrProductExists, audit, query,
+		product.ID, product.Name, product.Price, product.Category, product.SKU, product.Barcode, product.Description)
+}
+
+// Update replaces the details of a product together with the audit entry
+// and a cache invalidation. Stock, currency prices, translations and
+// option groups are kept. ErrProductNotFound is returned when it does not
+// exist, ErrProductIdentifierTaken when the SKU or barcode belongs to
+// another product and ErrCategoryNotFound when the category does not
+// exist.
+func (r *ProductRepository) Update(product models.Product, audit models.AuditEntry) error {
+	query := `UPDATE products
+	          SET name = $2, price = $3, category_id = ` + fmt.Sprintf(productCategoryID, 4) + `, sku = NULLIF($5, ''), barcode = NULLIF($6, ''),
+	              description = NULLIF($7, ''), updated_at = NOW()
+	          WHERE id = $1 AND deleted_at IS NULL`
+	return r.write(product.ID, ErrProductNotFound, audit, query,
+		product.ID, product.Name, product.Price, product.Category, product.SKU, product.Barcode, product.Description)
+}
+
+// Delete marks a product deleted together with the audit entry and a cache
+// invalidation. The row is kept for the orders that refer to it.
+// ErrProductNotFound is returned when it does not exist.
+func (r *ProductRepository) Delete(id string, audit models.AuditEntry) error {
+	query := `UPDATE products SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	return r.write(id, ErrProductNotFound, audit, query, id)
+}
+
+// write runs a statement changing the product with the given ID in a
+// transaction with its audit entry and cache invalidation. errNoRows is
+// returned when the statement changed nothing.
+func (r *ProductRepository) write(id string, errNoRows error, audit models.AuditEntry, query string, args ...any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if isUniqueViolation(err) {
+		return ErrProductIdentifierTaken
+	}
+	// The category is looked up by name; no category gives no ID
+	if isForeignKeyViolation(err) || isNotNullViolation(err) {
+		return ErrCategoryNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error writing product: %w", err)
+	}
+	changed, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error writing product: %w", err)
+	}
+	if changed == 0 {
+		return errNoRows
+	}
+
+	if _, err := insertAuditEntry(ctx, tx, audit); err != nil {
+		return err
+	}
+	if err := publishInTx(ctx, tx, models.InvalidationTopicProducts, []string{id}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.changes.Record(ChangedTableProducts, id)
+	return nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// isNotNullViolation reports whether err is a not-null constraint violation
+func isNotNullViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23502"
+}
+
+// GetByBarcode returns the product with the given barcode
+func (r *ProductRepository) GetByBarcode(barcode string) (models.Product, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + productColumns + ` FROM products WHERE barcode = $1 AND deleted_at IS NULL`
+	var product models.Product
+	err := scanProduct(r.db.QueryRowContext(ctx, query, barcode), &product)
+
+	if err == sql.ErrNoRows {
+		return models.Product{}, ErrProductNotFound
+	}
+	if err != nil {
+		return models.Product{}, fmt.Errorf("error querying product: %w", err)
+	}
+
+	products := []models.Product{product}
+	if err := r.attachCurrencyPrices(ctx, products); err != nil {
+		return models.Product{}, err
+	}
+	if err := r.attachTranslations(ctx, products); err != nil {
+		return models.Product{}, err
+	}
+	if err := r.attachOptionGroups(ctx, products); err != nil {
+		return models.Product{}, err
+	}
+
+	return products[0], nil
+}
+
+// attachCurrencyPrices loads the per-currency prices of the given products
+// with a single query and stores them on each product
+func (r *ProductRepository) attachCurrencyPrices(ctx context.Context, products []models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(products))
+	index := make(map[string]int, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+		index[p.ID] = i
+	}
+
+	query := `SELECT product_id, currency, price FROM product_prices_currency WHERE product_id = ANY($1)`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error querying currency prices: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID, currency string
+		var price float64
+		if err := rows.Scan(&productID, &currency, &price); err != nil {
+			return fmt.Errorf("error scanning currency price: %w", err)
+		}
+		i := index[productID]
+		if products[i].Prices == nil {
+			products[i].Prices = make(map[string]float64)
+		}
+		products[i].Prices[currency] = price
+	}
+
+	return rows.Err()
+}
+
+// attachTranslations loads the localized names and descriptions of the
+// given products with a single query and stores them on each product
+func (r *ProductRepository) attachTranslations(ctx context.Context, products []models.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(products))
+	index := make(map[string]int, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+		index[p.ID] = i
+	}
+
+	query := `SELECT product_id, language, name, COALESCE(description, '') FROM product_translations WHERE product_id = ANY($1)`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error querying product translations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID, language string
+		var translation models.ProductTranslation
+		if err := rows.Scan(&productID, &language, &translation.Name, &translation.Description); err != nil {
+			return fmt.Errorf("error scanning product translation: %w", err)
+		}
+		i := index[productID]
+		if products[i].Translations == nil {
+			products[i].Translations = make(map[string]models.ProductTranslation)
+		}
+		products[i].Translations[language] = translation
+	}
+
+	return rows.Err()
+}
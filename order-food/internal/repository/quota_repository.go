@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// QuotaRepository counts API requests per caller in fixed windows. Counts
+// live in the database so every replica enforces the same quota.
+type QuotaRepository struct {
+	db *sql.DB
+}
+
+// NewQuotaRepository creates a new quota repository
+func NewQuotaRepository(db *sql.DB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+// Consume counts one request by principal in the window starting at
+// windowStart and returns the requests counted so far together with the
+// principal's limit, which is defaultLimit unless overridden in api_quotas
+func (r *QuotaRepository) Consume(principal string, windowStart time.Time, defaultLimit int) (int, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO api_usage (principal, window_start, requests)
+	          VALUES ($1, $2, 1)
+	          ON CONFLICT (principal, window_start) DO UPDATE SET requests = api_usage.requests + 1
+	          RETURNING requests,
+	                    COALESCE((SELECT request_limit FROM api_quotas WHERE principal = $1), $3)`
+	var used, limit int
+	if err := r.db.QueryRowContext(ctx, query, principal, windowStart, defaultLimit).Scan(&used, &limit); err != nil {
+		return 0, 0, fmt.Errorf("error counting api usage: %w", err)
+	}
+	return used, limit, nil
+}
+
+// DeleteBefore removes windows that started before cutoff and returns how
+// many were removed
+func (r *QuotaRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM api_usage WHERE window_start < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("error pruning api usage: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,463 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+var (
+	// ErrInsufficientStock is wrapped by StockError
+	ErrInsufficientStock = errors.New("insufficient stock")
+	// ErrReservationNotFound is returned when a reservation does not exist or has expired
+	ErrReservationNotFound = errors.New("reservation not found or expired")
+	// ErrStockNotTracked is returned when adjusting the stock of a product
+	// that does not track stock
+	ErrStockNotTracked = errors.New("product does not track stock")
+	// ErrNegativeStock is returned when an adjustment would take stock
+	// below zero
+	ErrNegativeStock = errors.New("stock cannot go below zero")
+)
+
+// stockQuery selects the stock of a product that is not deleted and the
+// units held by its unexpired reservations
+const stockQuery = `SELECT p.stock,
+                           COALESCE((SELECT SUM(r.quantity) FROM stock_reservations r
+                                     WHERE r.product_id = p.id AND r.expires_at > NOW()), 0)
+                    FROM products p
+                    WHERE p.id = $1 AND p.deleted_at IS NULL`
+
+// StockError lists the items that ask for more stock than is available
+type StockError struct {
+	Shortages []models.StockShortage
+}
+
+func (e *StockError) Error() string {
+	parts := make([]string, len(e.Shortages))
+	for i, s := range e.Shortages {
+		parts[i] = fmt.Sprintf("%s: requested %d, available %d", s.ProductID, s.Requested, s.Available)
+	}
+	return fmt.Sprintf("%v: %s", ErrInsufficientStock, strings.Join(parts, "; "))
+}
+
+// Unwrap makes errors.Is(err, ErrInsufficientStock) match
+func (e *StockError) Unwrap() error {
+	return ErrInsufficientStock
+}
+
+// ReservationRepository holds and releases stock for checkouts
+type ReservationRepository struct {
+	db      *sql.DB
+	changes ChangeRecorder
+}
+
+// NewReservationRepository creates a new reservation repository
+func NewReservationRepository(db *sql.DB) *ReservationRepository {
+	return &ReservationRepository{db: db, changes: noChanges{}}
+}
+
+// SetChangeRecorder makes the repository report the products whose stock it writes to
+// changes
+func (r *ReservationRepository) SetChangeRecorder(changes ChangeRecorder) {
+	r.changes = changes
+}
+
+// Reserve holds the items for ttl under reservation.ID and returns when the
+// hold expires. Items of products that do not track stock are recorded but
+// never refused.
+func (r *ReservationRepository) Reserve(reservation models.Reservation, ttl time.Duration) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkStock(ctx, tx, reservation.Items); err != nil {
+		return time.Time{}, err
+	}
+
+	query := `INSERT INTO stock_reservations (id, product_id, quantity, expires_at)
+	          VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+	          RETURNING expires_at`
+	var expiresAt time.Time
+	for _, item := range reservation.Items {
+		err := tx.QueryRowContext(ctx, query, reservation.ID, item.ProductID, item.Quantity, ttl.Seconds()).Scan(&expiresAt)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to insert stock reservation: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return expiresAt, nil
+}
+
+// Get returns the items held by an unexpired reservation, or
+// ErrReservationNotFound
+func (r *ReservationRepository) Get(ctx context.Context, id string) (models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT product_id, quantity, expires_at FROM stock_reservations
+		 WHERE id = $1 AND expires_at > NOW()
+		 ORDER BY created_at, product_id`, id)
+	if err != nil {
+		return models.Reservation{}, fmt.Errorf("error querying stock reservation: %w", err)
+	}
+	defer rows.Close()
+
+	reservation := models.Reservation{ID: id}
+	for rows.Next() {
+		var item models.OrderItem
+		if err := rows.Scan(&item.ProductID, &item.Quantity, &reservation.ExpiresAt); err != nil {
+			return models.Reservation{}, fmt.Errorf("error scanning stock reservation: %w", err)
+		}
+		reservation.Items = append(reservation.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Reservation{}, fmt.Errorf("error querying stock reservation: %w", err)
+	}
+	if len(reservation.Items) == 0 {
+		return models.Reservation{}, ErrReservationNotFound
+	}
+	return reservation, nil
+}
+
+// Release gives the stock held by an unexpired reservation back before it
+// lapses, or returns ErrReservationNotFound
+func (r *ReservationRepository) Release(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM stock_reservations WHERE id = $1 AND expires_at > NOW()`, id)
+	if err != nil {
+		return fmt.Errorf("error releasing stock reservation: %w", err)
+	}
+	released, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error releasing stock reservation: %w", err)
+	}
+	if released == 0 {
+		return ErrReservationNotFound
+	}
+	return nil
+}
+
+// DeleteExpired removes lapsed reservations and returns how many item rows
+// were removed. Expired rows already no longer count against stock; this
+// only keeps the table small.
+func (r *ReservationRepository) DeleteExpired() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM stock_reservations WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired stock reservations: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// TakeStock takes the items of an order out of stock, consuming the
+// reservation with reservationID first when it is not empty. It must be
+// called within a TxManager transaction, so the stock only goes if the
+// order is stored. A *StockError is returned when an item exceeds the
+// available stock.
+func (r *ReservationRepository) TakeStock(ctx context.Context, items []models.OrderItem, reservationID string) error {
+	tx, err := txFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := commitStock(ctx, tx, items, reservationID); err != nil {
+		return err
+	}
+
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductID
+	}
+	afterCommit(ctx, func() { r.changes.Record(ChangedTableProducts, ids...) })
+	return nil
+}
+
+// commitStock takes the items out of stock as part of placing an order.
+// When reservationID is set, the reservation is consumed first so its units
+// count as available to this order; items beyond it are checked against the
+// unreserved stock like any other order.
+func commitStock(ctx context.Context, tx *sql.Tx, items []models.OrderItem, reservationID string) error {
+	if reservationID != "" {
+		result, err := tx.ExecContext(ctx,
+			`DELETE FROM stock_reservations WHERE id = $1 AND expires_at > NOW()`, reservationID)
+		if err != nil {
+			return fmt.Errorf("failed to consume stock reservation: %w", err)
+		}
+		consumed, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to consume stock reservation: %w", err)
+		}
+		if consumed == 0 {
+			return ErrReservationNotFound
+		}
+	}
+
+	if err := checkStock(ctx, tx, items); err != nil {
+		return err
+	}
+
+	query := `UPDATE products SET stock = stock - $2 WHERE id = $1 AND stock IS NOT NULL`
+	for _, item := range items {
+		if _, err := tx.ExecContext(ctx, query, item.ProductID, item.Quantity); err != nil {
+			return fmt.Errorf("failed to decrement stock: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkStock locks the products of items and returns a *StockError when
+// any item asks for more than its stock minus the units held by active
+// reservations. The row locks serialise every reservation and order that
+// touches the same product, so two checkouts cannot both take the last unit.
+func checkStock(ctx context.Context, tx *sql.Tx, items []models.OrderItem) error {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ProductID
+	}
+	// Locking in a fixed order keeps concurrent checkouts from deadlocking
+	sort.Strings(ids)
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, stock FROM products WHERE id = ANY($1) ORDER BY id FOR UPDATE`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error locking product stock: %w", err)
+	}
+	stock := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var onHand sql.NullInt64
+		if err := rows.Scan(&id, &onHand); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning product stock: %w", err)
+		}
+		if onHand.Valid {
+			stock[id] = int(onHand.Int64)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error locking product stock: %w", err)
+	}
+	if len(stock) == 0 {
+		return nil
+	}
+
+	rows, err = tx.QueryContext(ctx,
+		`SELECT product_id, SUM(quantity) FROM stock_reservations
+		 WHERE product_id = ANY($1) AND expires_at > NOW()
+		 GROUP BY product_id`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error querying reserved stock: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var reserved int
+		if err := rows.Scan(&id, &reserved); err != nil {
+			return fmt.Errorf("error scanning reserved stock: %w", err)
+		}
+		if onHand, tracked := stock[id]; tracked {
+			stock[id] = onHand - reserved
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error querying reserved stock: %w", err)
+	}
+
+	// A product listed twice must fit its combined quantity
+	requested := make(map[string]int)
+	order := make([]string, 0, len(items))
+	for _, item := range items {
+		if _, seen := requested[item.ProductID]; !seen {
+			order = append(order, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+
+	var shortages []models.StockShortage
+	for _, id := range order {
+		available, tracked := stock[id]
+		if tracked && requested[id] > available {
+			shortages = append(shortages, models.StockShortage{
+				ProductID: id,
+				Requested: requested[id],
+				Available: max(available, 0),
+			})
+		}
+	}
+	if len(shortages) > 0 {
+		return &StockError{Shortages: shortages}
+	}
+
+	return nil
+}
+
+// GetStock returns the stock of a product, or ErrProductNotFound when it
+// does not exist or has been deleted
+func (r *ReservationRepository) GetStock(ctx context.Context, productID string) (models.ProductStock, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return scanStock(r.db.QueryRowContext(ctx, stockQuery, productID), productID)
+}
+
+// GetStocks returns the stock of the products with the given IDs that
+// exist and are not deleted, keyed by ID, in a single query. Unlike
+// checkStock it locks nothing, so the stock may change right after.
+func (r *ReservationRepository) GetStocks(ctx context.Context, productIDs []string) (map[string]models.ProductStock, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT p.id, p.stock, COALESCE(r.reserved, 0)
+	          FROM products p
+	          LEFT JOIN (SELECT product_id, SUM(quantity) AS reserved FROM stock_reservations
+	                     WHERE product_id = ANY($1) AND expires_at > NOW()
+	                     GROUP BY product_id) r ON r.product_id = p.id
+	          WHERE p.id = ANY($1) AND p.deleted_at IS NULL`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(productIDs))
+	if err != nil {
+		return nil, fmt.Errorf("error querying product stock: %w", err)
+	}
+	defer rows.Close()
+
+	stocks := make(map[string]models.ProductStock, len(productIDs))
+	for rows.Next() {
+		var id string
+		var stock sql.NullInt64
+		var reserved int
+		if err := rows.Scan(&id, &stock, &reserved); err != nil {
+			return nil, fmt.Errorf("error scanning product stock: %w", err)
+		}
+		if stock.Valid {
+			onHand := int(stock.Int64)
+			stocks[id] = newProductStock(id, &onHand, reserved)
+		} else {
+			stocks[id] = newProductStock(id, nil, reserved)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying product stock: %w", err)
+	}
+	return stocks, nil
+}
+
+// SetStock replaces the stock of a product with change.NewStock, which
+// stops tracking it when nil, and records the change in the audit log on
+// behalf of actor. ErrProductNotFound is returned when the product does not
+// exist or has been deleted.
+func (r *ReservationRepository) SetStock(ctx context.Context, change models.StockChange, actor string) (models.ProductStock, error) {
+	stock := change.NewStock
+	return r.updateStock(ctx, change, models.AuditActionStockSet, actor, func(*int) (*int, error) {
+		return stock, nil
+	})
+}
+
+// AdjustStock adds change.Delta to the stock of a product and records the
+// change in the audit log on behalf of actor. Besides ErrProductNotFound,
+// ErrStockNotTracked is returned for products without stock and
+// ErrNegativeStock when the delta takes away more than is on hand. Units
+// held by reservations may be taken away, since stock lost in the shop is
+// gone either way; orders for those reservations are then refused.
+func (r *ReservationRepository) AdjustStock(ctx context.Context, change models.StockChange, actor string) (models.ProductStock, error) {
+	delta := change.Delta
+	return r.updateStock(ctx, change, models.AuditActionStockAdjust, actor, func(old *int) (*int, error) {
+		if old == nil {
+			return nil, ErrStockNotTracked
+		}
+		stock := *old + delta
+		if stock < 0 {
+			return nil, fmt.Errorf("%w: %d on hand", ErrNegativeStock, *old)
+		}
+		return &stock, nil
+	})
+}
+
+// updateStock locks the product's row, replaces its stock with next of the
+// current stock and records change with both values under action. The lock
+// makes orders and reservations of the product wait for the change, as in
+// checkStock.
+func (r *ReservationRepository) updateStock(ctx context.Context, change models.StockChange, action, actor string, next func(old *int) (*int, error)) (models.ProductStock, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.ProductStock{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := scanStock(tx.QueryRowContext(ctx, stockQuery+` FOR UPDATE OF p`, change.ProductID), change.ProductID)
+	if err != nil {
+		return models.ProductStock{}, err
+	}
+	stock, err := next(current.Stock)
+	if err != nil {
+		return models.ProductStock{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE products SET stock = $2 WHERE id = $1`, change.ProductID, stock); err != nil {
+		return models.ProductStock{}, fmt.Errorf("error updating product stock: %w", err)
+	}
+	change.OldStock = current.Stock
+	change.NewStock = stock
+	if _, err := insertAuditEntry(ctx, tx, models.AuditEntry{Action: action, Actor: actor, Details: change}); err != nil {
+		return models.ProductStock{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.ProductStock{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.changes.Record(ChangedTableProducts, change.ProductID)
+	return newProductStock(change.ProductID, stock, current.Reserved), nil
+}
+
+// scanStock reads a row selected with stockQuery
+func scanStock(row rowScanner, productID string) (models.ProductStock, error) {
+	var stock sql.NullInt64
+	var reserved int
+	err := row.Scan(&stock, &reserved)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ProductStock{}, ErrProductNotFound
+	}
+	if err != nil {
+		return models.ProductStock{}, fmt.Errorf("error querying product stock: %w", err)
+	}
+
+	if !stock.Valid {
+		return newProductStock(productID, nil, reserved), nil
+	}
+	onHand := int(stock.Int64)
+	return newProductStock(productID, &onHand, reserved), nil
+}
+
+// newProductStock describes stock units on hand of which reserved are held
+func newProductStock(productID string, stock *int, reserved int) models.ProductStock {
+	result := models.ProductStock{ProductID: productID, Stock: stock, Reserved: reserved}
+	if stock != nil {
+		available := max(*stock-reserved, 0)
+		result.Available = &available
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/database"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// scheduledTaskLockPrefix namespaces the advisory locks of scheduled tasks
+const scheduledTaskLockPrefix = "order-food/scheduled-task/"
+
+// ScheduledTaskRepository keeps the locks and latest runs of scheduled tasks
+type ScheduledTaskRepository struct {
+	db *sql.DB
+}
+
+// NewScheduledTaskRepository creates a new scheduled task repository
+func NewScheduledTaskRepository(db *sql.DB) *ScheduledTaskRepository {
+	return &ScheduledTaskRepository{db: db}
+}
+
+// WithLock runs fn while holding the advisory lock of task and reports
+// whether the lock was acquired
+func (r *ScheduledTaskRepository) WithLock(ctx context.Context, task string, fn func(ctx context.Context) error) (bool, error) {
+	return database.WithAdvisoryLock(ctx, r.db, scheduledTaskLockPrefix+task, fn)
+}
+
+// LastRun returns the latest recorded run of task
+func (r *ScheduledTaskRepository) LastRun(ctx context.Context, task string) (models.TaskRun, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT name, instance_id, status, COALESCE(error, ''), started_at, finished_at
+	          FROM scheduled_tasks WHERE name = $1`
+	var run models.TaskRun
+	err := r.db.QueryRowContext(ctx, query, task).
+		Scan(&run.Task, &run.InstanceID, &run.Status, &run.Error, &run.StartedAt, &run.FinishedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TaskRun{}, false, nil
+	}
+	if err != nil {
+		return models.TaskRun{}, false, fmt.Errorf("error querying scheduled task run: %w", err)
+	}
+	return run, true, nil
+}
+
+// LastRuns returns the latest recorded run of every task, by task name
+func (r *ScheduledTaskRepository) LastRuns(ctx context.Context) (map[string]models.TaskRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `SELECT name, instance_id, status, COALESCE(error, ''), started_at, finished_at FROM scheduled_tasks`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error querying scheduled task runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := make(map[string]models.TaskRun)
+	for rows.Next() {
+		var run models.TaskRun
+		if err := rows.Scan(&run.Task, &run.InstanceID, &run.Status, &run.Error, &run.StartedAt, &run.FinishedAt); err != nil {
+			return nil, fmt.Errorf("error scanning scheduled task run: %w", err)
+		}
+		runs[run.Task] = run
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error querying scheduled task runs: %w", err)
+	}
+	return runs, nil
+}
+
+// RecordRun stores run as the latest run of its task
+func (r *ScheduledTaskRepository) RecordRun(ctx context.Context, run models.TaskRun) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO scheduled_tasks (name, instance_id, status, error, started_at, finished_at)
+	          VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+	          ON CONFLICT (name) DO UPDATE
+	          SET instance_id = EXCLUDED.instance_id,
+	              status = EXCLUDED.status,
+	              error = EXCLUDED.error,
+	              started_at = EXCLUDED.started_at,
+	              finished_at = EXCLUDED.finished_at`
+	_, err := r.db.ExecContext(ctx, query, run.Task, run.InstanceID, run.Status, run.Error, run.StartedAt, run.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("error recording scheduled task run: %w", err)
+	}
+	return nil
+}
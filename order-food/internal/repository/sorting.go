@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// productSortColumns maps sortable product API fields to their columns
+var productSortColumns = map[string]string{
+	"id":       "id",
+	"name":     "name",
+	"price":    "price",
+	"category": productCategoryName,
+}
+
+// orderSortColumns maps sortable order API fields to their columns
+var orderSortColumns = map[string]string{
+	"id":         "id",
+	"createdAt":  "created_at",
+	"couponCode": "coupon_code",
+	"total":      "total",
+	"status":     "status",
+}
+
+// orderKeysetColumns maps sortable order API fields to the expressions
+// cursor pagination orders by, which must not be NULL to be compared
+var orderKeysetColumns = map[string]string{
+	"id":         "id",
+	"createdAt":  "created_at",
+	"couponCode": "COALESCE(coupon_code, '')",
+	"total":      "COALESCE(total, 0)",
+	"status":     "status",
+}
+
+// orderByClause builds an ORDER BY expression from sort fields. Only columns
+// found in the whitelist are ever written into the SQL; unknown fields are
+// skipped. The primary key is appended as a tie-breaker so pages are stable.
+func orderByClause(sort []models.SortField, columns map[string]string, fallback string) string {
+	terms := make([]string, 0, len(sort)+1)
+	hasID := false
+
+	for _, f := range sort {
+		column, ok := columns[f.Field]
+		if !ok {
+			continue
+		}
+		if column == "id" {
+			hasID = true
+		}
+		if f.Desc {
+			terms = append(terms, column+" DESC")
+		} else {
+			terms = append(terms, column+" ASC")
+		}
+	}
+
+	if len(terms) == 0 {
+		return fallback
+	}
+	if !hasID {
+		terms = append(terms, "id ASC")
+	}
+	return strings.Join(terms, ", ")
+}
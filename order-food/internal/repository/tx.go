@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// defaultTxTimeout bounds transactions started without a deadline, like
+// the single statement writes of the repositories
+const defaultTxTimeout = 5 * time.Second
+
+// ErrNoTransaction is returned by repository methods that only make sense
+// as part of a larger transaction when they are called outside WithinTx
+var ErrNoTransaction = errors.New("repository operation requires a transaction")
+
+// txKey carries the current transaction in a context
+type txKey struct{}
+
+// afterCommitKey carries the functions to run once the current transaction
+// has committed
+type afterCommitKey struct{}
+
+// TxManager lets the service layer compose operations of several
+// repositories into one unit of work. Repositories never begin these
+// transactions themselves; they join the one carried by the context they
+// are called with.
+type TxManager struct {
+	db *sql.DB
+}
+
+// NewTxManager creates a transaction manager for db
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx runs fn in a transaction that is committed when fn returns nil
+// and rolled back otherwise. Repository methods called with the context
+// passed to fn take part in the transaction. A WithinTx nested in another
+// joins the outer transaction, which decides whether it commits.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultTxTimeout)
+		defer cancel()
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var hooks []func()
+	txCtx := context.WithValue(context.WithValue(ctx, txKey{}, tx), afterCommitKey{}, &hooks)
+	if err := fn(txCtx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	for _, hook := range hooks {
+		hook()
+	}
+	return nil
+}
+
+// afterCommit runs fn once the transaction carried by ctx has committed,
+// or at once when ctx carries none
+func afterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(afterCommitKey{}).(*[]func()); ok {
+		*hooks = append(*hooks, fn)
+		return
+	}
+	fn()
+}
+
+// txFromContext returns the transaction started by WithinTx, or
+// ErrNoTransaction when ctx does not carry one
+func txFromContext(ctx context.Context) (*sql.Tx, error) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	if !ok {
+		return nil, ErrNoTransaction
+	}
+	return tx, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// WebhookEventRepository records webhook events that have been accepted so
+// replays of the same event can be detected
+type WebhookEventRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookEventRepository creates a new webhook event repository
+func NewWebhookEventRepository(db *sql.DB) *WebhookEventRepository {
+	return &WebhookEventRepository{db: db}
+}
+
+// Claim records the event and reports whether this is the first time it
+// was seen. Concurrent deliveries of the same event are serialised by the
+// primary key, so exactly one of them claims it.
+func (r *WebhookEventRepository) Claim(source, eventID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO webhook_events (source, event_id) VALUES ($1, $2)
+	          ON CONFLICT (source, event_id) DO NOTHING`
+	result, err := r.db.ExecContext(ctx, query, source, eventID)
+	if err != nil {
+		return false, fmt.Errorf("error recording webhook event: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error recording webhook event: %w", err)
+	}
+	return rows == 1, nil
+}
+
+// Release forgets a claimed event so the sender's retry is processed
+func (r *WebhookEventRepository) Release(source, eventID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `DELETE FROM webhook_events WHERE source = $1 AND event_id = $2`
+	if _, err := r.db.ExecContext(ctx, query, source, eventID); err != nil {
+		return fmt.Errorf("error releasing webhook event: %w", err)
+	}
+	return nil
+}
+
+// DeleteBefore removes events received before cutoff and returns how many
+// were removed. Cutoff must be well past the tolerance of every verifier,
+// otherwise a pruned event could be replayed while its signature is valid.
+func (r *WebhookEventRepository) DeleteBefore(cutoff time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_events WHERE received_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("error pruning webhook events: %w", err)
+	}
+	return result.RowsAffected()
+}
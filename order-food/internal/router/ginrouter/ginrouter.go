@@ -0,0 +1,38 @@
+// Package ginrouter serves the API described by router.NewAPI on Gin
+package ginrouter
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx/ginx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/router"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// New returns api served by a Gin engine
+func New(api *router.API) *gin.Engine {
+	engine := gin.Default()
+	// Wrong methods get 405 with the Allow header rather than 404
+	engine.HandleMethodNotAllowed = true
+
+	// Apply global middleware
+	for _, m := range api.Middleware {
+		engine.Use(ginx.Middleware(m))
+	}
+
+	// Interactive API documentation generated from the handler annotations
+	// (no auth required; try-it-out requests carry the keys entered there)
+	if api.Swagger {
+		engine.GET(router.SwaggerPath, ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
+
+	for _, route := range api.Routes {
+		handlers := make([]gin.HandlerFunc, 0, len(route.Middleware)+1)
+		for _, m := range route.Middleware {
+			handlers = append(handlers, ginx.Middleware(m))
+		}
+		engine.Handle(route.Method, route.Path, append(handlers, ginx.Wrap(route.Handler))...)
+	}
+
+	return engine
+}
@@ -0,0 +1,92 @@
+package ginrouter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/deprecation"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/handler"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/router"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	// Setup: the routes answer like they do on net/http
+	gin.SetMode(gin.TestMode)
+	deprecations, err := deprecation.Parse("GET /api/v1/orders/:orderId since=2026-10-01")
+	assert.NoError(t, err)
+	registry, err := deprecation.NewRegistry(deprecations)
+	assert.NoError(t, err)
+	engine := New(router.NewAPI(router.Handlers{
+		Health:   handler.NewHealthHandler(nil, nil),
+		Customer: &handler.CustomerHandler{},
+	}, router.Config{Pagination: utils.DefaultPaginationConfig, Deprecations: registry}))
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		wantStatus      int
+		wantBody        string
+		wantAllow       string
+		wantDeprecation bool
+	}{
+		{name: "route", method: http.MethodGet, path: "/health", wantStatus: http.StatusOK, wantBody: `"healthy"`},
+		{name: "capabilities", method: http.MethodGet, path: "/api/v1/capabilities", wantStatus: http.StatusOK, wantBody: `"/api/v1/orders/{orderId}"`},
+		{name: "options", method: http.MethodOptions, path: "/api/v1/orders/order-1", wantStatus: http.StatusNoContent, wantAllow: "GET, OPTIONS"},
+		{name: "options of overlapping paths", method: http.MethodOptions, path: "/api/v1/products/by-barcode/4006381333931", wantStatus: http.StatusNoContent, wantAllow: "GET, OPTIONS"},
+		{name: "route middleware", method: http.MethodGet, path: "/api/v1/orders/order-1", wantStatus: http.StatusUnauthorized, wantDeprecation: true},
+		{name: "wrong method", method: http.MethodDelete, path: "/health", wantStatus: http.StatusMethodNotAllowed, wantAllow: "GET, OPTIONS"},
+		{name: "no route", method: http.MethodGet, path: "/api/v1/nothing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Execute
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			assert.NotEmpty(t, w.Header().Get(utils.RequestIDHeader))
+			if tt.wantAllow != "" {
+				assert.Equal(t, tt.wantAllow, w.Header().Get("Allow"))
+			}
+			assert.Equal(t, tt.wantDeprecation, w.Header().Get("Deprecation") != "")
+		})
+	}
+}
+
+func TestNew_Swagger(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name       string
+		swagger    bool
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "off by default", path: "/swagger/index.html", wantStatus: http.StatusNotFound},
+		{name: "UI", swagger: true, path: "/swagger/index.html", wantStatus: http.StatusOK, wantBody: "swagger-ui"},
+		{name: "document", swagger: true, path: "/swagger/doc.json", wantStatus: http.StatusOK, wantBody: `"/api/v1/order"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			engine := New(router.NewAPI(router.Handlers{}, router.Config{Pagination: utils.DefaultPaginationConfig, Swagger: tt.swagger}))
+
+			// Execute
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -1,41 +1,364 @@
 package router
 
 import (
-	"github.com/gin-gonic/gin"
+	"net/http"
+	"slices"
+	"time"
+
+	// Registers the OpenAPI document generated by swag
+	_ "github.com/shyampundkar/kart-challenge-workspace/order-food/docs"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/deprecation"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/handler"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/middleware"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/instance"
+	"go.opentelemetry.io/otel"
 )
 
-// SetupRouter configures and returns the Gin router
-func SetupRouter(
-	productHandler *handler.ProductHandler,
-	orderHandler *handler.OrderHandler,
-	healthHandler *handler.HealthHandler,
-) *gin.Engine {
-	router := gin.Default()
+// Handlers groups the HTTP handlers served by the router
+type Handlers struct {
+	Product         *handler.ProductHandler
+	Category        *handler.CategoryHandler
+	Order           *handler.OrderHandler
+	Health          *handler.HealthHandler
+	Partner         *handler.PartnerHandler
+	CouponGuard     *handler.CouponGuardHandler
+	Version         *handler.VersionHandler
+	CouponAnalytics *handler.CouponAnalyticsHandler
+	Reservation     *handler.ReservationHandler
+	Cart            *handler.CartHandler
+	Pricing         *handler.PricingHandler
+	Stock           *handler.StockHandler
+	Root            *handler.RootHandler
+	CouponFile      *handler.CouponFileHandler
+	Campaign        *handler.CampaignHandler
+	Operation       *handler.OperationHandler
+	PromoCode       *handler.PromoCodeHandler
+	Task            *handler.TaskHandler
+	Matview         *handler.MatviewHandler
+	OrderVolume     *handler.OrderVolumeHandler
+	DataQuality     *handler.DataQualityHandler
+	Config          *handler.ConfigHandler
+	Maintenance     *handler.MaintenanceHandler
+	// Customer serves customer accounts; the routes are left out when nil
+	Customer *handler.CustomerHandler
+}
+
+// Config holds router level settings
+type Config struct {
+	// Pagination holds the defaults and hard cap shared by all list endpoints
+	Pagination utils.PaginationConfig
+	// DefaultLanguage is the language of untranslated product names;
+	// utils.DefaultLanguage when empty
+	DefaultLanguage string
+	// AdminAPIKey enables the admin routes; they are closed when it is empty
+	AdminAPIKey string
+	// APIKeyVerifiers resolve API keys other than the built-in one
+	APIKeyVerifiers []middleware.APIKeyVerifier
+	// TokenVerifier resolves customer access tokens; customers cannot
+	// authenticate when it is nil
+	TokenVerifier middleware.TokenVerifier
+	// Chaos enables fault injection for resilience testing when non-nil
+	Chaos *middleware.ChaosConfig
+	// Quotas rate limits authenticated callers when non-nil
+	Quotas middleware.QuotaChecker
+	// RequestTimeout cancels requests running longer than this; zero disables it
+	RequestTimeout time.Duration
+	// Instance names this replica in response headers and spans
+	Instance instance.Identity
+	// ProductListCache answers repeated product listings from memory for a
+	// few seconds when non-nil
+	ProductListCache *middleware.MicroCache
+	// OrderLanes admits order creation through separate lanes for
+	// partners and public traffic when non-nil
+	OrderLanes *middleware.OrderLanes
+	// Idempotency stores responses for the Idempotency-Key header on order creation
+	Idempotency middleware.IdempotencyStore
+	// Swagger serves the generated OpenAPI document and Swagger UI under
+	// /swagger/
+	Swagger bool
+	// Deprecations announces and counts the use of deprecated routes and
+	// fields when non-nil
+	Deprecations *deprecation.Registry
+	// ShadowTraffic mirrors a sample of API reads to the v2 handlers under
+	// evaluation when non-nil
+	ShadowTraffic *middleware.ShadowTraffic
+}
+
+// SwaggerPath is the route of Swagger UI and the OpenAPI document, served
+// when Config.Swagger is set
+const SwaggerPath = "/swagger/*any"
+
+// Route is an endpoint of the API: the method and path it answers, with
+// parameters written like Gin's (:orderId, *any), and the middleware
+// running before its handler
+type Route struct {
+	Method     string
+	Path       string
+	Middleware []httpx.Middleware
+	Handler    httpx.HandlerFunc
+}
+
+// API describes what the router serves independently of the HTTP
+// framework; NewHandler serves it on net/http and ginrouter.New on Gin
+type API struct {
+	// Middleware runs on every request in order, whether it matches a
+	// route or not
+	Middleware []httpx.Middleware
+	// Routes are the endpoints, OPTIONS on every path included
+	Routes []Route
+	// Swagger serves Swagger UI and the OpenAPI document under SwaggerPath
+	Swagger bool
+}
 
-	// Apply global middleware
-	router.Use(middleware.CORSMiddleware())
-	router.Use(middleware.LoggerMiddleware())
+// NewAPI describes the API served with h and cfg
+func NewAPI(h Handlers, cfg Config) *API {
+	api := &API{Swagger: cfg.Swagger}
+	root := &group{api: api}
+	capabilities := handler.NewCapabilityHandler()
+
+	// Global middleware
+	api.Middleware = []httpx.Middleware{
+		middleware.RequestIDMiddleware(),
+		middleware.CORSMiddleware(),
+		middleware.LoggerMiddleware(),
+		middleware.CancellationMiddleware(cfg.RequestTimeout),
+		middleware.TracingMiddleware(otel.GetTracerProvider()),
+		middleware.InstanceMiddleware(cfg.Instance),
+		middleware.InputEncodingMiddleware(),
+	}
+	if cfg.Deprecations != nil {
+		api.Middleware = append(api.Middleware, middleware.DeprecationMiddleware(cfg.Deprecations))
+	}
+	if cfg.Chaos != nil {
+		api.Middleware = append(api.Middleware, middleware.ChaosMiddleware(*cfg.Chaos))
+	}
 
 	// Health check endpoints (no auth required)
-	router.GET("/health", healthHandler.Health)
-	router.GET("/ready", healthHandler.Ready)
+	root.GET("/health", h.Health.Health)
+	root.GET("/ready", h.Health.Ready)
+	root.GET("/livez", h.Health.Live)
+
+	// Build information (no auth required)
+	root.GET("/version", h.Version.Version)
+	root.GET("/metrics", h.Version.Metrics)
+
+	auth := middleware.AuthMiddleware(cfg.APIKeyVerifiers...)
+	customerAuth := httpx.Middleware(pass)
+	if cfg.TokenVerifier != nil {
+		customerAuth = middleware.CustomerAuthMiddleware(cfg.TokenVerifier)
+	}
+	optionalAuth := middleware.OptionalAuthMiddleware(cfg.APIKeyVerifiers...)
+	rateLimit := httpx.Middleware(pass)
+	if cfg.Quotas != nil {
+		rateLimit = middleware.RateLimitMiddleware(cfg.Quotas)
+	}
+	productListCache := httpx.Middleware(pass)
+	if cfg.ProductListCache != nil {
+		productListCache = cfg.ProductListCache.Middleware()
+	}
+	orderLanes := httpx.Middleware(pass)
+	if cfg.OrderLanes != nil {
+		orderLanes = cfg.OrderLanes.Middleware()
+	}
+	idempotent := httpx.Middleware(pass)
+	if cfg.Idempotency != nil {
+		idempotent = middleware.IdempotencyMiddleware(cfg.Idempotency)
+	}
 
 	// API v1 routes
-	v1 := router.Group("/api/v1")
+	v1Middleware := []httpx.Middleware{middleware.PaginationMiddleware(cfg.Pagination)}
+	if cfg.ShadowTraffic != nil {
+		v1Middleware = append(v1Middleware, cfg.ShadowTraffic.Middleware())
+	}
+	defaultLanguage := cfg.DefaultLanguage
+	if defaultLanguage == "" {
+		defaultLanguage = utils.DefaultLanguage
+	}
+	v1Middleware = append(v1Middleware, middleware.LanguageMiddleware(defaultLanguage))
+	v1 := root.Group("/api/v1", v1Middleware...)
 	{
-		// Product routes (no auth required)
-		v1.GET("/products", productHandler.ListProducts)
-		v1.GET("/products/:productId", productHandler.GetProduct)
+		// API root for discovery (auth optional, to report the caller's quota)
+		v1.With(optionalAuth, rateLimit).GET("", h.Root.Root)
+		v1.GET("/capabilities", capabilities.GetCapabilities)
+
+		// Product routes (no auth required); ETags let pollers skip
+		// unchanged responses, and bursts of listings are answered from the
+		// micro-cache
+		etag := middleware.ETagMiddleware()
+		v1.With(etag, productListCache).GET("/products", h.Product.ListProducts)
+		v1.With(etag).GET("/products/search", h.Product.SearchProducts)
+		v1.With(etag).GET("/products/:productId", h.Product.GetProduct)
+		v1.With(etag).GET("/products/by-barcode/:code", h.Product.GetProductByBarcode)
+		v1.With(optionalAuth, rateLimit).POST("/products/availability", h.Stock.CheckAvailability)
+
+		// Product management (admin key required)
+		adminAuth := middleware.AdminAuthMiddleware(cfg.AdminAPIKey)
+		v1.With(adminAuth).POST("/products", h.Product.CreateProduct)
+		v1.With(adminAuth).PUT("/products/:productId", h.Product.UpdateProduct)
+		v1.With(adminAuth).PUT("/products/:productId/option-groups", h.Product.SetOptionGroups)
+		v1.With(adminAuth).DELETE("/products/:productId", h.Product.DeleteProduct)
+
+		// Categories (reads are public, changes need the admin key)
+		v1.With(etag).GET("/categories", h.Category.ListCategories)
+		v1.With(etag).GET("/categories/:categoryId", h.Category.GetCategory)
+		v1.With(etag).GET("/categories/:categoryId/products", h.Category.ListCategoryProducts)
+		v1.With(adminAuth).POST("/categories", h.Category.CreateCategory)
+		v1.With(adminAuth).PUT("/categories/:categoryId", h.Category.UpdateCategory)
+		v1.With(adminAuth).DELETE("/categories/:categoryId", h.Category.DeleteCategory)
+
+		// Order routes (API key or customer access token required)
+		orderRoutes := v1.Group("", customerAuth, auth, rateLimit)
+		ordersRead := middleware.RequireScope(service.ScopeOrdersRead)
+		ordersWrite := middleware.RequireScope(service.ScopeOrdersWrite)
+		ordersPlace := middleware.RequireScope(service.ScopeOrdersWrite, service.ScopeOrdersPlace)
+		orderRoutes.With(ordersRead, etag).GET("/orders", h.Order.ListOrders)
+		orderRoutes.With(ordersRead, etag).GET("/orders/:orderId", h.Order.GetOrder)
+		orderRoutes.With(ordersRead, etag).GET("/orders/:orderId/items", h.Order.ListOrderItems)
+		orderRoutes.With(ordersRead, etag).GET("/customers/:customerId/orders", h.Order.ListCustomerOrders)
+		orderRoutes.With(ordersPlace, orderLanes, idempotent).POST("/orders", h.Order.CreateOrder)
+		orderRoutes.With(ordersWrite).PATCH("/orders/:orderId/status", h.Order.UpdateOrderStatus)
+		orderRoutes.With(middleware.RequireScope(service.ScopeOrdersImport), orderLanes).POST("/orders/import", h.Order.ImportOrder)
+		orderRoutes.With(ordersWrite).POST("/reservations", h.Reservation.CreateReservation)
+		orderRoutes.With(ordersWrite).GET("/reservations/:reservationId", h.Reservation.GetReservation)
+		orderRoutes.With(ordersWrite).DELETE("/reservations/:reservationId", h.Reservation.ReleaseReservation)
+		orderRoutes.With(ordersWrite).POST("/carts", h.Cart.CreateCart)
+		orderRoutes.With(ordersWrite).GET("/carts/:cartId", h.Cart.GetCart)
+		orderRoutes.With(ordersWrite).POST("/carts/:cartId/items", h.Cart.AddCartItem)
+		orderRoutes.With(ordersWrite).PUT("/carts/:cartId/items/:productId", h.Cart.UpdateCartItem)
+		orderRoutes.With(ordersWrite).DELETE("/carts/:cartId/items/:productId", h.Cart.RemoveCartItem)
+		orderRoutes.With(ordersWrite).PUT("/carts/:cartId/coupon", h.Cart.SetCartCoupon)
+		orderRoutes.With(ordersWrite).DELETE("/carts/:cartId/coupon", h.Cart.RemoveCartCoupon)
+		orderRoutes.With(ordersPlace, orderLanes, idempotent).POST("/carts/:cartId/checkout", h.Cart.Checkout)
+
+		// Partner onboarding (registration is public, rotation needs the partner's own key)
+		v1.POST("/partners", h.Partner.RegisterPartner)
+		v1.With(auth, rateLimit).POST("/partners/:partnerId/keys/rotate", h.Partner.RotateOwnKey)
 
-		// Order routes (auth required)
-		orderRoutes := v1.Group("")
-		orderRoutes.Use(middleware.AuthMiddleware())
-		orderRoutes.GET("/orders", orderHandler.ListOrders)
-		orderRoutes.GET("/orders/:orderId", orderHandler.GetOrder)
-		orderRoutes.POST("/orders", orderHandler.CreateOrder)
+		// Customer accounts (registration and login are public)
+		if h.Customer != nil {
+			v1.POST("/customers", h.Customer.Register)
+			v1.POST("/customers/login", h.Customer.Login)
+			v1.With(customerAuth, auth, rateLimit).GET("/customers/me", h.Customer.GetMe)
+		}
+
+		// Admin routes (admin key required)
+		adminRoutes := v1.Group("/admin", adminAuth)
+		adminRoutes.GET("/partners", h.Partner.ListPartners)
+		adminRoutes.POST("/partners/:partnerId/approve", h.Partner.ApprovePartner)
+		adminRoutes.POST("/partners/:partnerId/reject", h.Partner.RejectPartner)
+		adminRoutes.POST("/partners/:partnerId/suspend", h.Partner.SuspendPartner)
+		adminRoutes.POST("/partners/:partnerId/keys/rotate", h.Partner.RotatePartnerKey)
+		adminRoutes.GET("/coupon-guard", h.CouponGuard.GetStatus)
+		adminRoutes.DELETE("/coupon-guard/blocks/:client", h.CouponGuard.Unblock)
+		adminRoutes.GET("/coupons/analytics", h.CouponAnalytics.GetAnalytics)
+		adminRoutes.POST("/products/bulk-price", h.Pricing.BulkUpdatePrices)
+		adminRoutes.GET("/products/:productId/stock", h.Stock.GetStock)
+		adminRoutes.PUT("/products/:productId/stock", h.Stock.SetStock)
+		adminRoutes.POST("/products/:productId/stock/adjustments", h.Stock.AdjustStock)
+		adminRoutes.GET("/coupon-files", h.CouponFile.ListCouponFiles)
+		adminRoutes.GET("/coupon-files/:uploadId", h.CouponFile.GetCouponFile)
+		adminRoutes.POST("/coupon-files", h.CouponFile.UploadCouponFile)
+		adminRoutes.POST("/campaigns", h.Campaign.CreateCampaign)
+		adminRoutes.GET("/campaigns/:campaignId", h.Campaign.GetCampaign)
+		adminRoutes.GET("/campaigns/:campaignId/export", h.Campaign.ExportCampaign)
+		adminRoutes.GET("/pipeline-runs", h.Operation.ListPipelineRuns)
+		adminRoutes.GET("/operations/:operationId", h.Operation.GetOperation)
+		adminRoutes.PUT("/promo-codes/:code/discount", h.PromoCode.SetDiscount)
+		adminRoutes.DELETE("/promo-codes/:code/discount", h.PromoCode.RemoveDiscount)
+		adminRoutes.GET("/promo-codes/:code/limits", h.PromoCode.GetLimits)
+		adminRoutes.PUT("/promo-codes/:code/limits", h.PromoCode.SetLimits)
+		adminRoutes.GET("/tasks", h.Task.ListTasks)
+		adminRoutes.POST("/tasks/:name/run", h.Task.RunTask)
+		adminRoutes.GET("/matviews/:view", h.Matview.GetMatview)
+		adminRoutes.POST("/matviews/:view/refresh", h.Matview.RefreshMatview)
+		adminRoutes.GET("/order-volume", h.OrderVolume.GetOrderVolume)
+		adminRoutes.GET("/data-quality", h.DataQuality.GetDataQuality)
+		adminRoutes.GET("/config", h.Config.GetConfig)
+		adminRoutes.POST("/maintenance/reindex", h.Maintenance.Reindex)
+		adminRoutes.GET("/maintenance/jobs/:jobId", h.Maintenance.GetJob)
+	}
+
+	// OPTIONS on every path answers with the methods it allows. Swagger is
+	// served by the router itself, outside the route table.
+	routes := api.Endpoints()
+	if cfg.Swagger {
+		routes = append(routes, httpx.Route{Method: http.MethodGet, Path: SwaggerPath})
+	}
+	for _, path := range capabilities.SetRoutes(routes) {
+		root.Handle(http.MethodOptions, path, capabilities.Options)
+	}
+
+	return api
+}
+
+// Endpoints returns the method and path of every route
+func (a *API) Endpoints() []httpx.Route {
+	routes := make([]httpx.Route, len(a.Routes))
+	for i, route := range a.Routes {
+		routes[i] = httpx.Route{Method: route.Method, Path: route.Path}
 	}
+	return routes
+}
+
+// pass is middleware that only calls the next handler, standing in for
+// middleware that is switched off
+func pass(next http.Handler) http.Handler {
+	return next
+}
+
+// group adds routes to an API below a path prefix, behind the middleware
+// of the group, like Gin's router groups
+type group struct {
+	api        *API
+	prefix     string
+	middleware []httpx.Middleware
+}
+
+// Group returns a group below prefix whose routes also run middleware,
+// after the middleware of g
+func (g *group) Group(prefix string, middleware ...httpx.Middleware) *group {
+	return &group{api: g.api, prefix: g.prefix + prefix, middleware: slices.Concat(g.middleware, middleware)}
+}
+
+// With returns g with middleware added for the routes added through it
+func (g *group) With(middleware ...httpx.Middleware) *group {
+	return g.Group("", middleware...)
+}
+
+// Handle adds the route of method and path, relative to the prefix of g
+func (g *group) Handle(method, path string, handler httpx.HandlerFunc) {
+	g.api.Routes = append(g.api.Routes, Route{
+		Method:     method,
+		Path:       g.prefix + path,
+		Middleware: g.middleware,
+		Handler:    handler,
+	})
+}
+
+// GET adds the GET route of path
+func (g *group) GET(path string, handler httpx.HandlerFunc) {
+	g.Handle(http.MethodGet, path, handler)
+}
+
+// POST adds the POST route of path
+func (g *group) POST(path string, handler httpx.HandlerFunc) {
+	g.Handle(http.MethodPost, path, handler)
+}
+
+// PUT adds the PUT route of path
+func (g *group) PUT(path string, handler httpx.HandlerFunc) {
+	g.Handle(http.MethodPut, path, handler)
+}
+
+// PATCH adds the PATCH route of path
+func (g *group) PATCH(path string, handler httpx.HandlerFunc) {
+	g.Handle(http.MethodPatch, path, handler)
+}
 
-	return router
+// DELETE adds the DELETE route of path
+func (g *group) DELETE(path string, handler httpx.HandlerFunc) {
+	g.Handle(http.MethodDelete, path, handler)
 }
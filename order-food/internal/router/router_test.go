@@ -0,0 +1,90 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/deprecation"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/handler"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHandler(t *testing.T) {
+	// Setup: every route is registered, and served without Gin
+	deprecations, err := deprecation.Parse("GET /api/v1/orders/:orderId since=2026-10-01")
+	assert.NoError(t, err)
+	registry, err := deprecation.NewRegistry(deprecations)
+	assert.NoError(t, err)
+	h := NewHandler(NewAPI(Handlers{
+		Health:   handler.NewHealthHandler(nil, nil),
+		Root:     handler.NewRootHandler(),
+		Customer: &handler.CustomerHandler{},
+	}, Config{Pagination: utils.DefaultPaginationConfig, Deprecations: registry}))
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		wantStatus      int
+		wantBody        string
+		wantAllow       string
+		wantDeprecation bool
+	}{
+		{name: "route", method: http.MethodGet, path: "/health", wantStatus: http.StatusOK, wantBody: `"healthy"`},
+		{name: "v1 root", method: http.MethodGet, path: "/api/v1", wantStatus: http.StatusOK, wantBody: `"/api/v1/capabilities"`},
+		{name: "capabilities", method: http.MethodGet, path: "/api/v1/capabilities", wantStatus: http.StatusOK, wantBody: `"/api/v1/orders/{orderId}"`},
+		{name: "options", method: http.MethodOptions, path: "/api/v1/orders/order-1", wantStatus: http.StatusNoContent, wantAllow: "GET, OPTIONS"},
+		{name: "options of overlapping paths", method: http.MethodOptions, path: "/api/v1/products/by-barcode/4006381333931", wantStatus: http.StatusNoContent, wantAllow: "GET, OPTIONS"},
+		{name: "route middleware", method: http.MethodGet, path: "/api/v1/orders/order-1", wantStatus: http.StatusUnauthorized, wantDeprecation: true},
+		{name: "wrong method", method: http.MethodDelete, path: "/health", wantStatus: http.StatusMethodNotAllowed, wantAllow: "GET, HEAD"},
+		{name: "no route", method: http.MethodGet, path: "/api/v1/nothing", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Execute
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			assert.NotEmpty(t, w.Header().Get(utils.RequestIDHeader))
+			if tt.wantAllow != "" {
+				assert.Equal(t, tt.wantAllow, w.Header().Get("Allow"))
+			}
+			assert.Equal(t, tt.wantDeprecation, w.Header().Get("Deprecation") != "")
+		})
+	}
+}
+
+func TestNewHandler_Swagger(t *testing.T) {
+	tests := []struct {
+		name       string
+		swagger    bool
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "off by default", path: "/swagger/index.html", wantStatus: http.StatusNotFound},
+		{name: "UI", swagger: true, path: "/swagger/index.html", wantStatus: http.StatusOK, wantBody: "swagger-ui"},
+		{name: "document", swagger: true, path: "/swagger/doc.json", wantStatus: http.StatusOK, wantBody: `"/api/v1/order"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			h := NewHandler(NewAPI(Handlers{}, Config{Pagination: utils.DefaultPaginationConfig, Swagger: tt.swagger}))
+
+			// Execute
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/middleware"
+	httpSwagger "github.com/swaggo/http-swagger/v2"
+)
+
+// probeMethods are the methods tried to find the route of an OPTIONS
+// request, which answers for every method of its path
+var probeMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// NewHandler returns api served by an http.ServeMux, without Gin. It
+// answers like the Gin router: wrong methods get 405 with the Allow header,
+// and middleware sees the route in Gin's syntax through httpx.RouteOf.
+func NewHandler(api *API) http.Handler {
+	mux := http.NewServeMux()
+	// paths maps ServeMux patterns to the routes registered with them
+	paths := make(map[string]string)
+	options := make(map[string]http.Handler)
+	for _, route := range api.Routes {
+		handler := httpx.Chain(httpx.Std(route.Handler), route.Middleware...)
+		if route.Method == http.MethodOptions {
+			// Paths such as /products/by-barcode/:code and
+			// /products/:productId/option-groups overlap, which ServeMux
+			// refuses within one method, so OPTIONS is routed by the
+			// route its path has for other methods
+			options[route.Path] = handler
+			continue
+		}
+		pattern := route.Method + " " + muxPath(route.Path)
+		paths[pattern] = route.Path
+		mux.Handle(pattern, handler)
+	}
+	// Interactive API documentation, as on Gin
+	if api.Swagger {
+		pattern := http.MethodGet + " " + muxPath(SwaggerPath)
+		paths[pattern] = SwaggerPath
+		mux.Handle(pattern, httpSwagger.Handler(httpSwagger.URL("/swagger/doc.json")))
+	}
+
+	routed := httpx.Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handler, ok := options[httpx.RouteOf(r)]; ok && r.Method == http.MethodOptions {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}), append([]httpx.Middleware{middleware.RecoveryMiddleware()}, api.Middleware...)...)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods := []string{r.Method}
+		if r.Method == http.MethodOptions {
+			methods = probeMethods
+		}
+		for _, method := range methods {
+			probe := *r
+			probe.Method = method
+			if _, pattern := mux.Handler(&probe); paths[pattern] != "" {
+				r = httpx.WithRoute(r, paths[pattern])
+				break
+			}
+		}
+		routed.ServeHTTP(w, r)
+	})
+}
+
+// muxPath writes the parameters of a route path, :orderId or *any, the way
+// ServeMux patterns do, as {orderId} or {any...}
+func muxPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			segments[i] = "{" + segment[1:] + "}"
+		case strings.HasPrefix(segment, "*"):
+			segments[i] = "{" + segment[1:] + "...}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,340 @@
+// Package scheduler runs recurring maintenance tasks on one replica at a
+// time. Every replica runs the same schedule, but a run only goes ahead on
+// the replica that wins the task's lock and finds that no other replica ran
+// the task within the last interval, so adding replicas does not multiply
+// the work.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+var (
+	// ErrUnknownTask is returned when no task has the requested name
+	ErrUnknownTask = errors.New("unknown scheduled task")
+	// ErrTaskRunning is returned when a task is triggered while it runs
+	ErrTaskRunning = errors.New("scheduled task is already running")
+)
+
+// Store keeps the cluster-wide state of scheduled tasks
+type Store interface {
+	// WithLock runs fn while holding the lock of task and reports whether
+	// the lock was acquired; fn is not called when another replica holds it
+	WithLock(ctx context.Context, task string, fn func(ctx context.Context) error) (bool, error)
+	// LastRun returns the latest recorded run of task, if any
+	LastRun(ctx context.Context, task string) (models.TaskRun, bool, error)
+	// LastRuns returns the latest recorded run of every task that has one
+	LastRuns(ctx context.Context) (map[string]models.TaskRun, error)
+	// RecordRun stores run as the latest run of its task
+	RecordRun(ctx context.Context, run models.TaskRun) error
+}
+
+// Task is a recurring job
+type Task struct {
+	Name     string
+	Interval time.Duration
+	// Jitter is the most each run is delayed by at random, so replicas
+	// started together do not all contend for the lock at the same moment.
+	// A tenth of Interval when zero.
+	Jitter time.Duration
+	Run    func(ctx context.Context) error
+}
+
+// jitter returns a random delay up to the task's jitter
+func (t Task) jitter() time.Duration {
+	limit := t.Jitter
+	if limit <= 0 {
+		limit = t.Interval / 10
+	}
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit)
+}
+
+// minSpacing is the shortest time between two runs of a task in the
+// cluster; a replica whose turn comes sooner after another replica's run
+// skips it
+func (t Task) minSpacing() time.Duration {
+	jitter := t.Jitter
+	if jitter <= 0 {
+		jitter = t.Interval / 10
+	}
+	return t.Interval - jitter
+}
+
+// taskState is a task and what this replica knows about its runs
+type taskState struct {
+	Task
+	trigger chan struct{}
+
+	mu           sync.Mutex
+	running      bool
+	nextRun      time.Time
+	runs         int64
+	failures     int64
+	skipped      int64
+	lastDuration time.Duration
+	lastSuccess  time.Time
+}
+
+// Descriptors of the task metrics
+var (
+	runsDesc         = prometheus.NewDesc("scheduled_task_runs_total", "Runs of each scheduled task on this instance.", []string{"task", "result"}, nil)
+	skippedDesc      = prometheus.NewDesc("scheduled_task_skipped_total", "Scheduled runs skipped because another instance held the task or ran it recently.", []string{"task"}, nil)
+	lastDurationDesc = prometheus.NewDesc("scheduled_task_last_duration_seconds", "Duration of the latest run of each scheduled task on this instance.", []string{"task"}, nil)
+	lastSuccessDesc  = prometheus.NewDesc("scheduled_task_last_success_timestamp_seconds", "Unix time of the latest successful run of each scheduled task on this instance.", []string{"task"}, nil)
+)
+
+// Scheduler runs tasks on their intervals. It is safe for concurrent use.
+type Scheduler struct {
+	store      Store
+	instanceID string
+	tasks      []*taskState
+	byName     map[string]*taskState
+	now        func() time.Time
+}
+
+// New creates a scheduler for tasks. instanceID is recorded on every run
+// so operators can tell which replica did the work.
+func New(store Store, instanceID string, tasks ...Task) *Scheduler {
+	s := &Scheduler{
+		store:      store,
+		instanceID: instanceID,
+		byName:     make(map[string]*taskState, len(tasks)),
+		now:        time.Now,
+	}
+	for _, task := range tasks {
+		state := &taskState{Task: task, trigger: make(chan struct{}, 1)}
+		s.tasks = append(s.tasks, state)
+		s.byName[task.Name] = state
+	}
+	return s
+}
+
+// Run runs every task on its interval until ctx is cancelled and returns
+// once the tasks running at that point have finished
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, t := range s.tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, t)
+		}()
+	}
+	wg.Wait()
+}
+
+// loop runs one task until ctx is cancelled. The first run comes after
+// the jitter rather than a full interval, so a fresh deployment catches up
+// with work that piled up while it was down.
+func (s *Scheduler) loop(ctx context.Context, t *taskState) {
+	delay := t.jitter()
+	t.setNextRun(s.now().Add(delay))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for {
+		forced := false
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-t.trigger:
+			forced = true
+			timer.Stop()
+		}
+
+		s.runOnce(ctx, t, forced)
+
+		delay = t.Interval + t.jitter()
+		t.setNextRun(s.now().Add(delay))
+		timer.Reset(delay)
+	}
+}
+
+// runOnce runs t if this replica wins its lock and, unless forced, no
+// other replica ran it within the last interval. It reports whether t ran
+// and returns the error of the run, or why it could not be recorded.
+func (s *Scheduler) runOnce(ctx context.Context, t *taskState, forced bool) (bool, error) {
+	t.mu.Lock()
+	t.running = true
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		t.running = false
+		t.mu.Unlock()
+	}()
+
+	ran := false
+	var runErr error
+	acquired, err := s.store.WithLock(ctx, t.Name, func(ctx context.Context) error {
+		if !forced {
+			last, found, err := s.store.LastRun(ctx, t.Name)
+			if err != nil {
+				return err
+			}
+			if found && s.now().Sub(last.StartedAt) < t.minSpacing() {
+				return nil
+			}
+		}
+
+		ran = true
+		run := models.TaskRun{Task: t.Name, InstanceID: s.instanceID, Status: models.TaskRunSucceeded, StartedAt: s.now()}
+		if runErr = t.Run(ctx); runErr != nil {
+			run.Status = models.TaskRunFailed
+			run.Error = runErr.Error()
+			slog.Error("Scheduled task failed", "task", t.Name, "error", runErr)
+		}
+		run.FinishedAt = s.now()
+		t.record(run)
+		return s.store.RecordRun(ctx, run)
+	})
+	if err != nil && ctx.Err() == nil {
+		slog.Error("Scheduled task could not run", "task", t.Name, "error", err)
+	}
+	if !acquired || !ran {
+		t.mu.Lock()
+		t.skipped++
+		t.mu.Unlock()
+	}
+	if runErr != nil {
+		return ran, runErr
+	}
+	return ran, err
+}
+
+func (t *taskState) setNextRun(at time.Time) {
+	t.mu.Lock()
+	t.nextRun = at
+	t.mu.Unlock()
+}
+
+// record counts a run made by this replica
+func (t *taskState) record(run models.TaskRun) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.runs++
+	t.lastDuration = run.FinishedAt.Sub(run.StartedAt)
+	if run.Status == models.TaskRunFailed {
+		t.failures++
+	} else {
+		t.lastSuccess = run.FinishedAt
+	}
+}
+
+// Trigger asks for a run of the named task as soon as possible. The run
+// skips the check for a recent run elsewhere, but still only goes ahead if
+// no other replica is running the task.
+func (s *Scheduler) Trigger(name string) error {
+	t, found := s.byName[name]
+	if !found {
+		return fmt.Errorf("%w: %q", ErrUnknownTask, name)
+	}
+	t.mu.Lock()
+	running := t.running
+	t.mu.Unlock()
+	if running {
+		return ErrTaskRunning
+	}
+
+	select {
+	case t.trigger <- struct{}{}:
+	default:
+		// A trigger is already pending
+	}
+	return nil
+}
+
+// RunNow runs the named task straight away and waits for it, returning
+// the error of the run. Like a triggered run it skips the check for a
+// recent run elsewhere; ErrTaskRunning is returned when the task is
+// running on this or another replica.
+func (s *Scheduler) RunNow(ctx context.Context, name string) error {
+	t, found := s.byName[name]
+	if !found {
+		return fmt.Errorf("%w: %q", ErrUnknownTask, name)
+	}
+	t.mu.Lock()
+	running := t.running
+	t.mu.Unlock()
+	if running {
+		return ErrTaskRunning
+	}
+
+	ran, err := s.runOnce(ctx, t, true)
+	if err != nil {
+		return err
+	}
+	if !ran {
+		return ErrTaskRunning
+	}
+	return nil
+}
+
+// Tasks describes every task with its latest run in the cluster
+func (s *Scheduler) Tasks(ctx context.Context) ([]models.ScheduledTask, error) {
+	lastRuns, err := s.store.LastRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]models.ScheduledTask, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		t.mu.Lock()
+		task := models.ScheduledTask{
+			Name:      t.Name,
+			Interval:  t.Interval.String(),
+			Running:   t.running,
+			NextRunAt: t.nextRun,
+			Runs:      t.runs,
+			Failures:  t.failures,
+			Skipped:   t.skipped,
+		}
+		t.mu.Unlock()
+		if run, found := lastRuns[t.Name]; found {
+			task.LastRun = &run
+		}
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Name < tasks[j].Name })
+	return tasks, nil
+}
+
+// Describe sends the descriptors of the task metrics
+func (s *Scheduler) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{runsDesc, skippedDesc, lastDurationDesc, lastSuccessDesc} {
+		ch <- desc
+	}
+}
+
+// Collect sends the run counters and timings of this replica
+func (s *Scheduler) Collect(ch chan<- prometheus.Metric) {
+	for _, t := range s.tasks {
+		t.mu.Lock()
+		succeeded, failed, skipped := t.runs-t.failures, t.failures, t.skipped
+		seconds := t.lastDuration.Seconds()
+		var lastSuccess float64
+		if !t.lastSuccess.IsZero() {
+			lastSuccess = float64(t.lastSuccess.Unix())
+		}
+		t.mu.Unlock()
+
+		ch <- prometheus.MustNewConstMetric(runsDesc, prometheus.CounterValue, float64(succeeded), t.Name, "succeeded")
+		ch <- prometheus.MustNewConstMetric(runsDesc, prometheus.CounterValue, float64(failed), t.Name, "failed")
+		ch <- prometheus.MustNewConstMetric(skippedDesc, prometheus.CounterValue, float64(skipped), t.Name)
+		ch <- prometheus.MustNewConstMetric(lastDurationDesc, prometheus.GaugeValue, seconds, t.Name)
+		ch <- prometheus.MustNewConstMetric(lastSuccessDesc, prometheus.GaugeValue, lastSuccess, t.Name)
+	}
+}
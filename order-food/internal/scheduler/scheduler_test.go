@@ -0,0 +1,247 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryStore is an in-memory Store whose lock can be held by "another
+// replica"
+type memoryStore struct {
+	mu         sync.Mutex
+	lockedHere bool
+	heldByPeer bool
+	runs       map[string]models.TaskRun
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{runs: make(map[string]models.TaskRun)}
+}
+
+func (s *memoryStore) WithLock(ctx context.Context, task string, fn func(ctx context.Context) error) (bool, error) {
+	s.mu.Lock()
+	if s.heldByPeer || s.lockedHere {
+		s.mu.Unlock()
+		return false, nil
+	}
+	s.lockedHere = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.lockedHere = false
+		s.mu.Unlock()
+	}()
+	return true, fn(ctx)
+}
+
+func (s *memoryStore) LastRun(ctx context.Context, task string) (models.TaskRun, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run, found := s.runs[task]
+	return run, found, nil
+}
+
+func (s *memoryStore) LastRuns(ctx context.Context) (map[string]models.TaskRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := make(map[string]models.TaskRun, len(s.runs))
+	for name, run := range s.runs {
+		runs[name] = run
+	}
+	return runs, nil
+}
+
+func (s *memoryStore) RecordRun(ctx context.Context, run models.TaskRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs[run.Task] = run
+	return nil
+}
+
+// newTestScheduler returns a scheduler for task driven by a fixed clock
+func newTestScheduler(store Store, task Task) (*Scheduler, time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := New(store, "instance-1", task)
+	s.now = func() time.Time { return now }
+	return s, now
+}
+
+func TestScheduler_RunOnce_RecordsRun(t *testing.T) {
+	// Setup
+	store := newMemoryStore()
+	calls := 0
+	s, now := newTestScheduler(store, Task{Name: "reaper", Interval: time.Minute, Run: func(ctx context.Context) error {
+		calls++
+		return nil
+	}})
+
+	// Execute
+	s.runOnce(context.Background(), s.tasks[0], false)
+
+	// Assert
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, models.TaskRun{
+		Task:       "reaper",
+		InstanceID: "instance-1",
+		Status:     models.TaskRunSucceeded,
+		StartedAt:  now,
+		FinishedAt: now,
+	}, store.runs["reaper"])
+}
+
+func TestScheduler_RunOnce_RecordsFailure(t *testing.T) {
+	// Setup
+	store := newMemoryStore()
+	s, _ := newTestScheduler(store, Task{Name: "reaper", Interval: time.Minute, Run: func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}})
+
+	// Execute
+	s.runOnce(context.Background(), s.tasks[0], false)
+
+	// Assert
+	assert.Equal(t, models.TaskRunFailed, store.runs["reaper"].Status)
+	assert.Equal(t, "connection refused", store.runs["reaper"].Error)
+	assert.Equal(t, int64(1), s.tasks[0].failures)
+}
+
+func TestScheduler_RunOnce_Skips(t *testing.T) {
+	tests := []struct {
+		name       string
+		heldByPeer bool
+		lastRunAgo time.Duration
+		forced     bool
+		wantRun    bool
+	}{
+		{name: "held by another replica", heldByPeer: true},
+		{name: "ran recently elsewhere", lastRunAgo: 10 * time.Second},
+		{name: "ran an interval ago", lastRunAgo: time.Minute, wantRun: true},
+		{name: "forced after a recent run", lastRunAgo: 10 * time.Second, forced: true, wantRun: true},
+		{name: "forced while held by another replica", heldByPeer: true, forced: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			store := newMemoryStore()
+			store.heldByPeer = tt.heldByPeer
+			ran := false
+			s, now := newTestScheduler(store, Task{Name: "reaper", Interval: time.Minute, Run: func(ctx context.Context) error {
+				ran = true
+				return nil
+			}})
+			if tt.lastRunAgo > 0 {
+				store.runs["reaper"] = models.TaskRun{Task: "reaper", InstanceID: "instance-2", StartedAt: now.Add(-tt.lastRunAgo)}
+			}
+
+			// Execute
+			s.runOnce(context.Background(), s.tasks[0], tt.forced)
+
+			// Assert
+			assert.Equal(t, tt.wantRun, ran)
+			if !tt.wantRun {
+				assert.Equal(t, int64(1), s.tasks[0].skipped)
+			}
+		})
+	}
+}
+
+func TestScheduler_Trigger(t *testing.T) {
+	// Setup
+	store := newMemoryStore()
+	ran := make(chan struct{}, 1)
+	s := New(store, "instance-1", Task{Name: "reaper", Interval: time.Hour, Jitter: time.Hour, Run: func(ctx context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	// Execute
+	err := s.Trigger("reaper")
+
+	// Assert
+	assert.NoError(t, err)
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("triggered task did not run")
+	}
+	assert.True(t, errors.Is(s.Trigger("unknown"), ErrUnknownTask))
+
+	cancel()
+	<-done
+}
+
+func TestScheduler_RunNow(t *testing.T) {
+	// Setup
+	store := newMemoryStore()
+	fail := errors.New("refresh failed")
+	var result error
+	s, _ := newTestScheduler(store, Task{Name: "refresher", Interval: time.Hour, Run: func(ctx context.Context) error { return result }})
+
+	// Execute: a recent run elsewhere does not hold the run back
+	err := s.RunNow(context.Background(), "refresher")
+	assert.NoError(t, err)
+	result = fail
+	err = s.RunNow(context.Background(), "refresher")
+
+	// Assert
+	assert.ErrorIs(t, err, fail)
+	assert.Equal(t, models.TaskRunFailed, store.runs["refresher"].Status)
+	assert.ErrorIs(t, s.RunNow(context.Background(), "unknown"), ErrUnknownTask)
+
+	store.heldByPeer = true
+	assert.ErrorIs(t, s.RunNow(context.Background(), "refresher"), ErrTaskRunning)
+}
+
+func TestScheduler_Tasks(t *testing.T) {
+	// Setup
+	store := newMemoryStore()
+	s, now := newTestScheduler(store, Task{Name: "reaper", Interval: time.Minute, Run: func(ctx context.Context) error { return nil }})
+	store.runs["reaper"] = models.TaskRun{Task: "reaper", InstanceID: "instance-2", Status: models.TaskRunSucceeded, StartedAt: now}
+
+	// Execute
+	tasks, err := s.Tasks(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, "1m0s", tasks[0].Interval)
+	assert.Equal(t, "instance-2", tasks[0].LastRun.InstanceID)
+}
+
+func TestScheduler_Collect(t *testing.T) {
+	// Setup
+	store := newMemoryStore()
+	s, _ := newTestScheduler(store, Task{Name: "reaper", Interval: time.Minute, Run: func(ctx context.Context) error { return nil }})
+	s.runOnce(context.Background(), s.tasks[0], false)
+	s.runOnce(context.Background(), s.tasks[0], false)
+
+	// Execute & Assert
+	err := testutil.CollectAndCompare(s, strings.NewReader(`
+# HELP scheduled_task_last_success_timestamp_seconds Unix time of the latest successful run of each scheduled task on this instance.
+# TYPE scheduled_task_last_success_timestamp_seconds gauge
+scheduled_task_last_success_timestamp_seconds{task="reaper"} 1704110400
+# HELP scheduled_task_runs_total Runs of each scheduled task on this instance.
+# TYPE scheduled_task_runs_total counter
+scheduled_task_runs_total{result="failed",task="reaper"} 0
+scheduled_task_runs_total{result="succeeded",task="reaper"} 1
+# HELP scheduled_task_skipped_total Scheduled runs skipped because another instance held the task or ran it recently.
+# TYPE scheduled_task_skipped_total counter
+scheduled_task_skipped_total{task="reaper"} 1
+`), "scheduled_task_last_success_timestamp_seconds", "scheduled_task_runs_total", "scheduled_task_skipped_total")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,199 @@
+package securitytest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// statement is a query or command the application sent to the database
+type statement struct {
+	query string
+	args  []driver.NamedValue
+}
+
+// recorder is a database/sql driver that records every statement it is
+// given and answers as an empty PostgreSQL database would. Like
+// PostgreSQL, it rejects text arguments PostgreSQL cannot store and
+// negative LIMIT or OFFSET values, so inputs that would fail in production
+// fail here too. When fail is set every statement fails with it.
+type recorder struct {
+	fail error
+
+	mu         sync.Mutex
+	statements []statement
+}
+
+// open returns a pool over the recorder
+func (r *recorder) open() *sql.DB {
+	return sql.OpenDB(r)
+}
+
+// Connect implements driver.Connector
+func (r *recorder) Connect(context.Context) (driver.Conn, error) {
+	return &recorderConn{r: r}, nil
+}
+
+// Driver implements driver.Connector
+func (r *recorder) Driver() driver.Driver {
+	return recorderDriver{r: r}
+}
+
+// recorded returns the statements sent so far
+func (r *recorder) recorded() []statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]statement(nil), r.statements...)
+}
+
+// pagingArg matches the placeholders bound to LIMIT and OFFSET
+var pagingArg = regexp.MustCompile(`(?i)\b(?:LIMIT|OFFSET)\s+\$(\d+)`)
+
+// record stores a statement and returns the error PostgreSQL would give it
+func (r *recorder) record(query string, args []driver.NamedValue) error {
+	r.mu.Lock()
+	r.statements = append(r.statements, statement{query: query, args: args})
+	r.mu.Unlock()
+
+	if r.fail != nil {
+		return r.fail
+	}
+	for _, arg := range args {
+		if s, ok := arg.Value.(string); ok && (strings.ContainsRune(s, 0) || !utf8.ValidString(s)) {
+			return errors.New(`pq: invalid byte sequence for encoding "UTF8"`)
+		}
+	}
+	for _, match := range pagingArg.FindAllStringSubmatch(query, -1) {
+		n, _ := strconv.Atoi(match[1])
+		if n < 1 || n > len(args) {
+			continue
+		}
+		if value, ok := args[n-1].Value.(int64); ok && value < 0 {
+			return errors.New("pq: LIMIT and OFFSET must not be negative")
+		}
+	}
+	return nil
+}
+
+type recorderDriver struct {
+	r *recorder
+}
+
+func (d recorderDriver) Open(string) (driver.Conn, error) {
+	return &recorderConn{r: d.r}, nil
+}
+
+type recorderConn struct {
+	r *recorder
+}
+
+func (c *recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("statements are not prepared")
+}
+
+func (c *recorderConn) Close() error {
+	return nil
+}
+
+func (c *recorderConn) Begin() (driver.Tx, error) {
+	return recorderTx{}, nil
+}
+
+func (c *recorderConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	return recorderTx{}, nil
+}
+
+func (c *recorderConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.r.record(query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(0), nil
+}
+
+// QueryContext answers queries that always return a row with one of zeros
+// and NULLs and everything else with no rows
+func (c *recorderConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.r.record(query, args); err != nil {
+		return nil, err
+	}
+	if row := emptyRow(query); row != nil {
+		return &recorderRows{columns: len(row), rows: [][]driver.Value{row}}, nil
+	}
+	return &recorderRows{}, nil
+}
+
+// fromKeyword matches the FROM ending the select list
+var fromKeyword = regexp.MustCompile(`^\sFROM\s`)
+
+// emptyRow returns the row an empty database answers query with, or nil
+// when it answers with no rows. A SELECT without a FROM returns zeros, such
+// as false from EXISTS; a COUNT over a table, capped with LEAST or not,
+// returns 0 followed by NULLs for its subqueries.
+func emptyRow(query string) []driver.Value {
+	query = strings.ToUpper(strings.TrimSpace(query))
+	if !strings.HasPrefix(query, "SELECT ") {
+		return nil
+	}
+	columns, depth, from := 1, 0, false
+	for i := 0; i < len(query) && !from; i++ {
+		switch query[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				columns++
+			}
+		default:
+			from = depth == 0 && fromKeyword.MatchString(query[i:])
+		}
+	}
+
+	row := make([]driver.Value, columns)
+	switch {
+	case !from:
+		for i := range row {
+			row[i] = int64(0)
+		}
+	case strings.HasPrefix(query, "SELECT COUNT("), strings.HasPrefix(query, "SELECT LEAST(COUNT("):
+		row[0] = int64(0)
+	default:
+		return nil
+	}
+	return row
+}
+
+type recorderTx struct{}
+
+func (recorderTx) Commit() error   { return nil }
+func (recorderTx) Rollback() error { return nil }
+
+type recorderRows struct {
+	columns int
+	rows    [][]driver.Value
+}
+
+func (r *recorderRows) Columns() []string {
+	return make([]string, r.columns)
+}
+
+func (r *recorderRows) Close() error {
+	return nil
+}
+
+func (r *recorderRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
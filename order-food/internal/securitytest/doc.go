@@ -0,0 +1,7 @@
+// Package securitytest holds the security regression tests of the API.
+// They send hostile query parameters, path parameters, sort fields and
+// promo codes through the real router, services and repositories over a
+// driver that records every statement, and check that none causes a server
+// error, leaks database details or is written into the text of a query
+// rather than bound as an argument.
+package securitytest
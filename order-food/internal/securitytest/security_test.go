@@ -0,0 +1,337 @@
+package securitytest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/handler"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/middleware"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/router"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/router/ginrouter"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/service"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/utils"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/promocode"
+	"github.com/stretchr/testify/assert"
+)
+
+// adminKey opens the admin routes of the test router
+const adminKey = "security-test-admin"
+
+// payloads are hostile inputs sent wherever the API takes text. Each is
+// distinctive enough that finding it in the text of a statement means it
+// was spliced into the SQL rather than bound as an argument.
+var payloads = []string{
+	`' OR '1'='1`,
+	`'; DROP TABLE orders; --`,
+	`1 UNION SELECT api_key FROM partners--`,
+	`" OR ""="`,
+	`1); SELECT pg_sleep(10); --`,
+	`price; DELETE FROM products`,
+	`$1 OR 1=1`,
+	`\' OR 1=1 --`,
+	`' || (SELECT version()) || '`,
+	`%' OR 'a' LIKE '%`,
+	"name\x00; DROP TABLE coupons",
+	"\xff\xfe' OR 1=1",
+	`9223372036854775807`,
+	`-9223372036854775808`,
+	`1e400`,
+	`NaN`,
+	strings.Repeat(`'`, 4096),
+}
+
+// couponPayloads are hostile promo codes of the 8-10 characters a code
+// must have to be looked up at all
+var couponPayloads = []string{
+	`' OR 1=1--`,
+	`';DROP--x`,
+	`'||'a'||'`,
+	`x' OR 'x'`,
+	"ab\x00cdefgh",
+	`$1 OR 1=1`,
+}
+
+// leakMarkers are fragments of database errors and SQL that must never
+// reach a client
+var leakMarkers = []string{"pq:", "sql:", "SQLSTATE", "syntax error", "SELECT ", "INSERT ", "UPDATE ", "DELETE FROM", "invalid byte sequence", "must not be negative"}
+
+// newRouter returns the API router over real services and repositories
+// backed by db
+func newRouter(db *recorder) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	pool := db.open()
+	products := repository.NewProductRepository(pool)
+	promoCodes := service.NewPromoCodeService(pool, nil)
+	stock := repository.NewReservationRepository(pool)
+	orders := service.NewOrderService(repository.NewTxManager(pool), repository.NewOrderRepository(pool), products,
+		stock, nil, nil)
+
+	return ginrouter.New(router.NewAPI(router.Handlers{
+		Product: handler.NewProductHandler(service.NewProductService(products, nil)),
+		Category: handler.NewCategoryHandler(service.NewCategoryService(repository.NewCategoryRepository(pool), products),
+			service.NewProductService(products, nil)),
+		Order:     handler.NewOrderHandler(orders, promoCodes, nil),
+		PromoCode: handler.NewPromoCodeHandler(promoCodes),
+		Stock:     handler.NewStockHandler(service.NewStockService(stock)),
+		Cart: handler.NewCartHandler(service.NewCartService(repository.NewCartRepository(pool), products, promoCodes, orders,
+			service.DefaultCartTTL), promoCodes, nil),
+	}, router.Config{
+		Pagination:  utils.DefaultPaginationConfig,
+		AdminAPIKey: adminKey,
+	}))
+}
+
+// send serves a request through r with the API and admin keys
+func send(r *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(middleware.APIKeyHeader, middleware.ValidAPIKey)
+	req.Header.Set(middleware.AdminKeyHeader, adminKey)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// assertNoLeak checks that a response carries no database details. Markers
+// found in payload may be the input echoed back in a validation error.
+func assertNoLeak(t *testing.T, w *httptest.ResponseRecorder, payload string) {
+	t.Helper()
+	for _, marker := range leakMarkers {
+		if !strings.Contains(payload, marker) {
+			assert.NotContains(t, w.Body.String(), marker, "database details leaked")
+		}
+	}
+}
+
+// assertSafe checks that a response to a hostile request is neither a
+// server error nor a leak of database details, and that payload reached
+// the database, if at all, only as a bound argument
+func assertSafe(t *testing.T, db *recorder, w *httptest.ResponseRecorder, payload string) {
+	t.Helper()
+	assert.Less(t, w.Code, http.StatusInternalServerError, "server error: %s", w.Body.String())
+	assertNoLeak(t, w, payload)
+	for _, s := range db.recorded() {
+		assert.NotContains(t, s.query, payload, "input was written into the SQL of %q", s.query)
+	}
+}
+
+// boundAsArgument reports whether payload was sent to the database as the
+// value of a placeholder
+func boundAsArgument(db *recorder, payload string) bool {
+	for _, s := range db.recorded() {
+		for _, arg := range s.args {
+			if arg.Value == payload {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestQueryParametersAreNotInjectable(t *testing.T) {
+	targets := []string{
+		"/api/v1/products?category=%s",
+		"/api/v1/products?sort=%s",
+		"/api/v1/products?minPrice=%s&maxPrice=%s",
+		"/api/v1/products?page=%s&perPage=%s",
+		"/api/v1/products?after=%s",
+		"/api/v1/products?limit=%s",
+		"/api/v1/products/search?q=%s",
+		"/api/v1/products/search?q=waffle&page=%s",
+		"/api/v1/categories/waffle/products?sort=%s",
+		"/api/v1/categories/waffle/products?page=%s&perPage=%s",
+		"/api/v1/orders?sort=%s",
+		"/api/v1/orders?page=%s&perPage=%s",
+		"/api/v1/orders?after=%s&sort=-total",
+		"/api/v1/orders/order-1/items?page=%s",
+	}
+	for _, target := range targets {
+		for _, payload := range payloads {
+			t.Run(target+"/"+payload[:min(len(payload), 20)], func(t *testing.T) {
+				// Setup
+				db := &recorder{}
+				r := newRouter(db)
+				escaped := url.QueryEscape(payload)
+				path := fmt.Sprintf(target, escaped, escaped)
+				path = strings.ReplaceAll(path, "%!(EXTRA string="+escaped+")", "")
+
+				// Execute
+				w := send(r, "GET", path, "")
+
+				// Assert
+				assertSafe(t, db, w, payload)
+			})
+		}
+	}
+}
+
+func TestPathParametersAreNotInjectable(t *testing.T) {
+	requests := []struct {
+		method, target, body string
+	}{
+		{"GET", "/api/v1/products/%s", ""},
+		{"GET", "/api/v1/products/by-barcode/%s", ""},
+		{"GET", "/api/v1/orders/%s", ""},
+		{"GET", "/api/v1/orders/%s/items", ""},
+		{"PATCH", "/api/v1/orders/%s/status", `{"status":"confirmed"}`},
+		{"PUT", "/api/v1/products/%s", `{"name":"Waffle","price":6.5,"category":"Waffle"}`},
+		{"DELETE", "/api/v1/products/%s", ""},
+		{"GET", "/api/v1/categories/%s", ""},
+		{"GET", "/api/v1/categories/%s/products", ""},
+		{"PUT", "/api/v1/categories/%s", `{"name":"Waffle"}`},
+		{"DELETE", "/api/v1/categories/%s", ""},
+		{"GET", "/api/v1/admin/products/%s/stock", ""},
+		{"PUT", "/api/v1/admin/products/%s/stock", `{"stock":40}`},
+		{"POST", "/api/v1/admin/products/%s/stock/adjustments", `{"delta":-2}`},
+		{"GET", "/api/v1/carts/%s", ""},
+		{"POST", "/api/v1/carts/%s/items", `{"productId":"1","quantity":1}`},
+		{"DELETE", "/api/v1/carts/cart-1/items/%s", ""},
+		{"POST", "/api/v1/carts/%s/checkout", ""},
+	}
+	for _, req := range requests {
+		for _, payload := range payloads {
+			t.Run(req.method+" "+req.target+"/"+payload[:min(len(payload), 20)], func(t *testing.T) {
+				// Setup
+				db := &recorder{}
+				r := newRouter(db)
+
+				// Execute
+				w := send(r, req.method, fmt.Sprintf(req.target, url.PathEscape(payload)), req.body)
+
+				// Assert
+				assertSafe(t, db, w, payload)
+			})
+		}
+	}
+}
+
+func TestCouponCodesAreNotInjectable(t *testing.T) {
+	for _, code := range couponPayloads {
+		t.Run(code, func(t *testing.T) {
+			// Setup
+			db := &recorder{}
+			r := newRouter(db)
+			body, err := json.Marshal(map[string]any{
+				"couponCode": code,
+				"items":      []map[string]any{{"productId": "1", "quantity": 1}},
+			})
+			assert.NoError(t, err)
+
+			// Execute
+			placed := send(r, "POST", "/api/v1/orders", string(body))
+			limits := send(r, "GET", "/api/v1/admin/promo-codes/"+url.PathEscape(code)+"/limits", "")
+
+			// Assert
+			assertSafe(t, db, placed, code)
+			assertSafe(t, db, limits, code)
+			if !strings.ContainsRune(code, 0) {
+				// Codes are looked up in upper case
+				assert.True(t, boundAsArgument(db, promocode.Normalize(code)), "the code was never looked up")
+			}
+		})
+	}
+}
+
+func TestAvailabilityProductIDsAreNotInjectable(t *testing.T) {
+	for _, payload := range payloads {
+		t.Run(payload[:min(len(payload), 20)], func(t *testing.T) {
+			// Setup
+			db := &recorder{}
+			r := newRouter(db)
+			body, err := json.Marshal(map[string]any{
+				"items": []map[string]any{{"productId": payload, "quantity": 1}, {"productId": "1", "quantity": 2}},
+			})
+			assert.NoError(t, err)
+
+			// Execute
+			w := send(r, "POST", "/api/v1/products/availability", string(body))
+
+			// Assert
+			assertSafe(t, db, w, payload)
+		})
+	}
+}
+
+func TestSortFieldsAreWhitelisted(t *testing.T) {
+	for _, target := range []string{"/api/v1/products", "/api/v1/orders"} {
+		for _, sort := range []string{"id;DROP TABLE orders", "(SELECT 1)", "created_at", "-id,password", "1"} {
+			t.Run(target+"?sort="+sort, func(t *testing.T) {
+				// Setup
+				db := &recorder{}
+				r := newRouter(db)
+
+				// Execute
+				w := send(r, "GET", target+"?sort="+url.QueryEscape(sort), "")
+
+				// Assert
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				assert.Empty(t, db.recorded(), "a rejected sort reached the database")
+			})
+		}
+	}
+}
+
+func TestFiltersAreBoundAsArguments(t *testing.T) {
+	// Setup
+	db := &recorder{}
+	r := newRouter(db)
+	category := `Waffle' OR '1'='1`
+
+	// Execute
+	w := send(r, "GET", "/api/v1/products?category="+url.QueryEscape(category), "")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assertSafe(t, db, w, category)
+	assert.True(t, boundAsArgument(db, category))
+}
+
+func TestDatabaseErrorsAreNotLeaked(t *testing.T) {
+	requests := []struct {
+		method, target, body string
+	}{
+		{"GET", "/api/v1/products", ""},
+		{"GET", "/api/v1/products?limit=5", ""},
+		{"GET", "/api/v1/products/search?q=waffle", ""},
+		{"GET", "/api/v1/products/1", ""},
+		{"GET", "/api/v1/products/by-barcode/4006381333931", ""},
+		{"GET", "/api/v1/orders", ""},
+		{"GET", "/api/v1/orders?limit=5", ""},
+		{"GET", "/api/v1/orders/order-1", ""},
+		{"GET", "/api/v1/orders/order-1/items", ""},
+		{"PATCH", "/api/v1/orders/order-1/status", `{"status":"confirmed"}`},
+		{"POST", "/api/v1/orders", `{"items":[{"productId":"1","quantity":1}]}`},
+		{"POST", "/api/v1/orders", `{"couponCode":"HAPPYHRS","items":[{"productId":"1","quantity":1}]}`},
+		{"POST", "/api/v1/products", `{"id":"waffle-1","name":"Waffle","price":6.5,"category":"Waffle"}`},
+		{"POST", "/api/v1/products/availability", `{"items":[{"productId":"1","quantity":1}]}`},
+		{"GET", "/api/v1/categories", ""},
+		{"GET", "/api/v1/categories/waffle/products", ""},
+		{"POST", "/api/v1/categories", `{"name":"Waffle"}`},
+		{"GET", "/api/v1/admin/promo-codes/HAPPYHRS/limits", ""},
+		{"GET", "/api/v1/admin/products/1/stock", ""},
+		{"POST", "/api/v1/admin/products/1/stock/adjustments", `{"delta":-2}`},
+	}
+	for _, req := range requests {
+		t.Run(req.method+" "+req.target, func(t *testing.T) {
+			// Setup
+			db := &recorder{fail: errors.New(`pq: syntax error at or near "FROM" (SQLSTATE 42601)`)}
+			r := newRouter(db)
+
+			// Execute
+			w := send(r, req.method, req.target, req.body)
+
+			// Assert
+			assertNoLeak(t, w, "")
+		})
+	}
+}
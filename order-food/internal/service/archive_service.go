@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/archive"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+// ArchiveService moves orders past their retention period to cold storage
+// and reads them back on demand
+type ArchiveService struct {
+	orderRepo *repository.OrderRepository
+	store     archive.Store
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewArchiveService creates a new archive service. Orders older than
+// retention are moved to store.
+func NewArchiveService(orderRepo *repository.OrderRepository, store archive.Store, retention time.Duration) *ArchiveService {
+	return &ArchiveService{
+		orderRepo: orderRepo,
+		store:     store,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// ArchiveOldOrders archives every order past the retention period in
+// batches of batchSize. Each batch is written as one compressed NDJSON
+// object before its orders are deleted, so a failure never loses orders.
+// It returns the number of orders archived.
+func (s *ArchiveService) ArchiveOldOrders(batchSize int) (int, error) {
+	if batchSize < 1 {
+		return 0, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	cutoff := s.now().Add(-s.retention)
+	archived := 0
+
+	for {
+		count, err := s.archiveBatch(cutoff, batchSize)
+		archived += count
+		if err != nil {
+			return archived, err
+		}
+		if count < batchSize {
+			return archived, nil
+		}
+	}
+}
+
+// archiveBatch archives up to batchSize orders created before cutoff
+func (s *ArchiveService) archiveBatch(cutoff time.Time, batchSize int) (int, error) {
+	orders, err := s.orderRepo.ListCreatedBefore(cutoff, batchSize)
+	if err != nil || len(orders) == 0 {
+		return 0, err
+	}
+
+	data, err := archive.Encode(orders)
+	if err != nil {
+		return 0, err
+	}
+
+	key := fmt.Sprintf("orders/%s/%s.ndjson.gz", s.now().UTC().Format("2006/01/02"), uuid.New().String())
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	if err := s.store.Put(ctx, key, data); err != nil {
+		return 0, err
+	}
+
+	if _, err := s.orderRepo.MarkArchived(orders, key); err != nil {
+		return 0, err
+	}
+
+	return len(orders), nil
+}
+
+// ArchiveBatch archives up to batchSize old orders. It is run by the
+// scheduler.
+func (s *ArchiveService) ArchiveBatch(ctx context.Context, batchSize int) error {
+	count, err := s.ArchiveOldOrders(batchSize)
+	if err != nil {
+		return fmt.Errorf("order archiver failed after %d orders: %w", count, err)
+	}
+	if count > 0 {
+		slog.Info("Order archiver moved orders to cold storage", "orders", count)
+	}
+	return nil
+}
+
+// GetArchivedOrder rehydrates an archived order. The boolean is false when
+// the order was never archived.
+func (s *ArchiveService) GetArchivedOrder(id string) (models.Order, bool, error) {
+	key, err := s.orderRepo.GetArchiveObjectKey(id)
+	if err != nil || key == "" {
+		return models.Order{}, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	data, err := s.store.Get(ctx, key)
+	if err != nil {
+		return models.Order{}, false, fmt.Errorf("failed to load archive %s: %w", key, err)
+	}
+
+	orders, err := archive.Decode(data)
+	if err != nil {
+		return models.Order{}, false, err
+	}
+	for _, order := range orders {
+		if order.ID == id {
+			if order.Delivery != nil {
+				if err := s.orderRepo.OpenDelivery(order.Delivery); err != nil {
+					return models.Order{}, false, err
+				}
+			}
+			order.Order.Archived = true
+			return order.Order, true, nil
+		}
+	}
+
+	return models.Order{}, false, fmt.Errorf("order %s missing from archive %s", id, key)
+}
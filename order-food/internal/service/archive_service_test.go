@@ -0,0 +1,262 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/archive"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/pii"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryStore is an in-memory archive.Store for tests
+type memoryStore map[string][]byte
+
+func (m memoryStore) Put(ctx context.Context, key string, data []byte) error {
+	m[key] = data
+	return nil
+}
+
+func (m memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, ok := m[key]
+	if !ok {
+		return nil, archive.ErrObjectNotFound
+	}
+	return data, nil
+}
+
+func TestArchiveService_ArchiveOldOrders(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := memoryStore{}
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	service := NewArchiveService(repository.NewOrderRepository(db), store, 30*24*time.Hour)
+	service.now = func() time.Time { return now }
+
+	created := now.Add(-60 * 24 * time.Hour)
+	mock.ExpectQuery("SELECT id, coupon_code, status, COALESCE\\(total, 0\\), discount, COALESCE\\(customer_id, ''\\), created_at").
+		WithArgs(now.Add(-30*24*time.Hour), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coupon_code", "status", "total", "discount", "customer_id", "created_at"}).
+			AddRow("order-1", "", "completed", 13.0, 0.0, "", created))
+	mock.ExpectQuery("SELECT oi.order_id").
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "product_id", "quantity", "discount", "id", "name", "category", "price", "tax_rate"}).
+			AddRow("order-1", "1", 2, 0.0, "1", "Waffle", "Waffle", 6.5, 0.0))
+	mock.ExpectQuery("FROM order_item_modifiers m").
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "position", "option_id", "group_name", "option_name", "price"}).
+			AddRow("order-1", 0, "syrup", "Toppings", "Maple syrup", 0.5))
+	mock.ExpectQuery("FROM order_addresses").
+		WillReturnRows(sqlmock.NewRows(deliveryColumns))
+	mock.ExpectQuery("FROM order_payments").
+		WillReturnRows(sqlmock.NewRows(paymentColumns))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO archived_orders").
+		WithArgs("order-1", sqlmock.AnyArg(), created).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM orders").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Test
+	archived, err := service.ArchiveOldOrders(10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, archived)
+	assert.Len(t, store, 1)
+	for key, data := range store {
+		assert.Contains(t, key, "orders/2024/06/01/")
+		orders, err := archive.Decode(data)
+		assert.NoError(t, err)
+		assert.Equal(t, "order-1", orders[0].ID)
+		assert.Equal(t, 2, orders[0].Items[0].Quantity)
+		assert.Equal(t, []models.ItemModifier{{OptionID: "syrup", Group: "Toppings", Name: "Maple syrup", Price: 0.5}}, orders[0].Items[0].Modifiers)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// deliveryColumns and paymentColumns are the columns the archiver reads the
+// addresses and payments of orders with
+var (
+	deliveryColumns = []string{"order_id", "city", "region", "postal_code", "country",
+		"line1", "line2", "contact_name", "contact_phone", "contact_email", "instructions"}
+	paymentColumns = []string{"order_id", "provider", "intent_id", "status", "amount", "currency"}
+)
+
+func TestArchiveService_ArchivesDeliveryAndPayment(t *testing.T) {
+	// Setup: the address is sealed in the database, and stays sealed in
+	// the archive
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	keys, err := pii.ParseKeys("k1:" + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32)))
+	assert.NoError(t, err)
+	codec := pii.NewCipher(keys)
+	seal := func(value string) string {
+		sealed, err := codec.Encode(value)
+		assert.NoError(t, err)
+		return sealed
+	}
+
+	orderRepo := repository.NewOrderRepository(db)
+	orderRepo.SetCodec(codec)
+	store := memoryStore{}
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	service := NewArchiveService(orderRepo, store, 30*24*time.Hour)
+	service.now = func() time.Time { return now }
+
+	created := now.Add(-60 * 24 * time.Hour)
+	mock.ExpectQuery("SELECT id, coupon_code, status").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coupon_code", "status", "total", "discount", "customer_id", "created_at"}).
+			AddRow("order-1", "", "completed", 11.7, 0.0, "", created))
+	mock.ExpectQuery("SELECT oi.order_id").
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "product_id", "quantity", "discount", "id", "name", "category", "price", "tax_rate"}).
+			AddRow("order-1", "1", 2, 0.0, "1", "Waffle", "Waffle", 5.85, 0.0))
+	mock.ExpectQuery("FROM order_item_modifiers m").
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "position", "option_id", "group_name", "option_name", "price"}))
+	mock.ExpectQuery("FROM order_addresses WHERE order_id = ANY").
+		WillReturnRows(sqlmock.NewRows(deliveryColumns).
+			AddRow("order-1", "Sydney", "NSW", "2000", "AU", seal("1 Market Street"), seal(""),
+				seal("Jane Citizen"), seal("+61 2 9999 0000"), seal(""), seal("Ring twice")))
+	mock.ExpectQuery("FROM order_payments WHERE order_id = ANY").
+		WillReturnRows(sqlmock.NewRows(paymentColumns).
+			AddRow("order-1", "mock", "pi_1", "succeeded", 1170, "usd"))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO archived_orders").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM orders").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Test
+	archived, err := service.ArchiveOldOrders(10)
+	assert.NoError(t, err)
+	var key string
+	for stored := range store {
+		key = stored
+	}
+	mock.ExpectQuery("SELECT object_key FROM archived_orders").
+		WithArgs("order-1").
+		WillReturnRows(sqlmock.NewRows([]string{"object_key"}).AddRow(key))
+	order, found, err := service.GetArchivedOrder("order-1")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, archived)
+	assert.True(t, found)
+	assert.NotContains(t, string(mustDecompress(t, store[key])), "Market Street")
+	assert.Equal(t, &models.Delivery{
+		Address:      models.DeliveryAddress{Line1: "1 Market Street", City: "Sydney", Region: "NSW", PostalCode: "2000", Country: "AU"},
+		Contact:      models.DeliveryContact{Name: "Jane Citizen", Phone: "+61 2 9999 0000"},
+		Instructions: "Ring twice",
+	}, order.Delivery)
+	assert.Equal(t, &models.Payment{Provider: "mock", IntentID: "pi_1", Status: "succeeded", Amount: 1170, Currency: "usd"}, order.Payment)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// mustDecompress returns the NDJSON of an archive object
+func mustDecompress(t *testing.T, data []byte) []byte {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+	plain, err := io.ReadAll(zr)
+	assert.NoError(t, err)
+	return plain
+}
+
+func TestOrderService_GetOrder_RehydratesArchivedOrder(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	orderRepo := repository.NewOrderRepository(db)
+	data, err := archive.Encode([]models.ArchivedOrder{{Order: models.Order{ID: "order-1", Total: 13}}})
+	assert.NoError(t, err)
+	store := memoryStore{"orders/2024/06/01/batch.ndjson.gz": data}
+	service := NewOrderService(nil, orderRepo, nil, nil, nil, NewArchiveService(orderRepo, store, time.Hour))
+
+	mock.ExpectQuery("SELECT id, coupon_code, status, COALESCE\\(total, 0\\), discount, COALESCE\\(customer_id, ''\\) FROM orders WHERE id").
+		WithArgs("order-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coupon_code", "status", "total", "discount", "customer_id"}))
+	mock.ExpectQuery("SELECT object_key FROM archived_orders").
+		WithArgs("order-1").
+		WillReturnRows(sqlmock.NewRows([]string{"object_key"}).AddRow("orders/2024/06/01/batch.ndjson.gz"))
+
+	// Test
+	order, err := service.GetOrder("order-1")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "order-1", order.ID)
+	assert.True(t, order.Archived)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_GetOrder_NotArchived(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	orderRepo := repository.NewOrderRepository(db)
+	service := NewOrderService(nil, orderRepo, nil, nil, nil, NewArchiveService(orderRepo, memoryStore{}, time.Hour))
+
+	mock.ExpectQuery("SELECT id, coupon_code, status, COALESCE\\(total, 0\\), discount, COALESCE\\(customer_id, ''\\) FROM orders WHERE id").
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coupon_code", "status", "total", "discount", "customer_id"}))
+	mock.ExpectQuery("SELECT object_key FROM archived_orders").
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"object_key"}))
+
+	// Test
+	_, err = service.GetOrder("missing")
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrOrderNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_ImportPOSOrder_ReplaysArchivedOrder(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	orderRepo := repository.NewOrderRepository(db)
+	data, err := archive.Encode([]models.ArchivedOrder{{Order: models.Order{ID: "order-1", Total: 13}}})
+	assert.NoError(t, err)
+	store := memoryStore{"orders/2024/06/01/batch.ndjson.gz": data}
+	service := NewOrderService(nil, orderRepo, nil, nil, nil, NewArchiveService(orderRepo, store, time.Hour))
+
+	// The import record outlives the order it points to
+	mock.ExpectQuery("SELECT order_id FROM pos_order_imports").
+		WithArgs("T-1").
+		WillReturnRows(sqlmock.NewRows([]string{"order_id"}).AddRow("order-1"))
+	mock.ExpectQuery("SELECT id, coupon_code, status, COALESCE\\(total, 0\\), discount, COALESCE\\(customer_id, ''\\) FROM orders WHERE id").
+		WithArgs("order-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coupon_code", "status", "total", "discount", "customer_id"}))
+	mock.ExpectQuery("SELECT object_key FROM archived_orders").
+		WithArgs("order-1").
+		WillReturnRows(sqlmock.NewRows([]string{"object_key"}).AddRow("orders/2024/06/01/batch.ndjson.gz"))
+
+	// Test
+	order, created, err := service.ImportPOSOrder(context.Background(), "T-1", models.OrderReq{Items: []models.OrderItem{{ProductID: "1", Quantity: 2}}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "order-1", order.ID)
+	assert.True(t, order.Archived)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
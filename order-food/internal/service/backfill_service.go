@@ -0,0 +1,90 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+// orderTotalsBackfill is the checkpoint name used by the order totals backfill
+const orderTotalsBackfill = "order_totals"
+
+// BackfillService runs chunked, resumable data backfills
+type BackfillService struct {
+	orderRepo *repository.OrderRepository
+}
+
+// NewBackfillService creates a new backfill service
+func NewBackfillService(orderRepo *repository.OrderRepository) *BackfillService {
+	return &BackfillService{orderRepo: orderRepo}
+}
+
+// BackfillOrderTotals recomputes the total of every historical order in
+// chunks of batchSize, logging progress after each chunk. Progress is
+// checkpointed, so a rerun resumes where the last one stopped unless
+// restart is set. It returns the number of orders processed by this run.
+//
+// Totals are recomputed from the current product prices; there is no price
+// history table to reconstruct the price in effect when each order was placed.
+func (s *BackfillService) BackfillOrderTotals(batchSize int, restart bool) (int, error) {
+	if batchSize < 1 {
+		return 0, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+
+	if restart {
+		if err := s.orderRepo.ResetBackfillCheckpoint(orderTotalsBackfill); err != nil {
+			return 0, err
+		}
+	}
+
+	checkpoint, err := s.orderRepo.GetBackfillCheckpoint(orderTotalsBackfill)
+	if err != nil {
+		return 0, err
+	}
+	if checkpoint.Completed {
+		slog.Info("Order totals backfill already completed; use --restart to run it again", "orders", checkpoint.Processed)
+		return 0, nil
+	}
+
+	totalOrders, err := s.orderRepo.Count()
+	if err != nil {
+		return 0, err
+	}
+
+	if checkpoint.LastID != "" {
+		slog.Info("Resuming order totals backfill", "after_order", checkpoint.LastID, "processed", checkpoint.Processed)
+	}
+
+	lastID := checkpoint.LastID
+	done := checkpoint.Processed
+	processed := 0
+
+	for {
+		nextID, count, err := s.orderRepo.BackfillTotalsBatch(orderTotalsBackfill, lastID, batchSize)
+		if err != nil {
+			return processed, fmt.Errorf("backfill stopped after order %q: %w", lastID, err)
+		}
+
+		lastID = nextID
+		processed += count
+		done += count
+
+		if count > 0 {
+			slog.Info("Order totals backfill progress", "done", done, "total", totalOrders, "percent", percent(done, totalOrders))
+		}
+		if count < batchSize {
+			break
+		}
+	}
+
+	slog.Info("Order totals backfill completed", "processed", processed)
+	return processed, nil
+}
+
+func percent(done, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(done) * 100 / float64(total)
+}
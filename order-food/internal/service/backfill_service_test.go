@@ -0,0 +1,113 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackfillService_BackfillOrderTotals_ChunksUntilDone(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewBackfillService(repository.NewOrderRepository(db))
+
+	mock.ExpectQuery("SELECT last_id, processed").
+		WithArgs("order_totals").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM orders").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	// First chunk is full, so another chunk is requested
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE orders o SET total").
+		WithArgs("", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("a").AddRow("b"))
+	mock.ExpectExec("INSERT INTO data_backfills").
+		WithArgs("order_totals", "b", 2, false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Second chunk is short, which ends the backfill
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE orders o SET total").
+		WithArgs("b", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("c"))
+	mock.ExpectExec("INSERT INTO data_backfills").
+		WithArgs("order_totals", "c", 1, true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Test
+	processed, err := service.BackfillOrderTotals(2, false)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, processed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBackfillService_BackfillOrderTotals_ResumesFromCheckpoint(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewBackfillService(repository.NewOrderRepository(db))
+
+	mock.ExpectQuery("SELECT last_id, processed").
+		WithArgs("order_totals").
+		WillReturnRows(sqlmock.NewRows([]string{"last_id", "processed", "completed"}).AddRow("b", 2, false))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM orders").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE orders o SET total").
+		WithArgs("b", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("c"))
+	mock.ExpectExec("INSERT INTO data_backfills").
+		WithArgs("order_totals", "c", 1, true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Test
+	processed, err := service.BackfillOrderTotals(10, false)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBackfillService_BackfillOrderTotals_AlreadyCompleted(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewBackfillService(repository.NewOrderRepository(db))
+
+	mock.ExpectQuery("SELECT last_id, processed").
+		WithArgs("order_totals").
+		WillReturnRows(sqlmock.NewRows([]string{"last_id", "processed", "completed"}).AddRow("c", 3, true))
+
+	// Test
+	processed, err := service.BackfillOrderTotals(10, false)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, processed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBackfillService_BackfillOrderTotals_InvalidBatchSize(t *testing.T) {
+	service := NewBackfillService(nil)
+
+	_, err := service.BackfillOrderTotals(0, false)
+
+	assert.Error(t, err)
+}
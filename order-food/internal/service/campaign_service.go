@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/couponcode"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+const (
+	// defaultCampaignFiles is the fewest file names that make a code valid
+	defaultCampaignFiles = 2
+	// maxCodeReplacements bounds the rounds of replacing generated codes
+	// that collide with existing promo codes
+	maxCodeReplacements = 5
+	// maxCampaignSlugLength keeps generated file names readable
+	maxCampaignSlugLength = 32
+)
+
+var (
+	// ErrCampaignExists is returned when a campaign with the same name exists
+	ErrCampaignExists = repository.ErrCampaignExists
+	// ErrCampaignNotFound is returned when a campaign does not exist
+	ErrCampaignNotFound = repository.ErrCampaignNotFound
+)
+
+// CampaignError lists the problems found in a campaign request; no codes
+// are generated when it is returned
+type CampaignError struct {
+	Diagnostics []models.FieldError
+}
+
+func (e *CampaignError) Error() string {
+	return fmt.Sprintf("invalid campaign: %d problems", len(e.Diagnostics))
+}
+
+// CampaignService generates promo code campaigns
+type CampaignService struct {
+	repo *repository.CampaignRepository
+}
+
+// NewCampaignService creates a new campaign service
+func NewCampaignService(repo *repository.CampaignRepository) *CampaignService {
+	return &CampaignService{repo: repo}
+}
+
+// CreateCampaign generates req.Count unique codes on behalf of actor and
+// makes them valid promo codes by writing each under req.Files file names.
+// Generated codes that are already promo codes are replaced, so a campaign
+// never takes over codes handed out before. A *CampaignError is returned
+// for invalid requests.
+func (s *CampaignService) CreateCampaign(req models.CampaignReq, actor string) (models.Campaign, error) {
+	name := strings.TrimSpace(req.Name)
+	length := req.Length
+	if length == 0 {
+		length = couponcode.MinLength
+	}
+	files := req.Files
+	if files == 0 {
+		files = defaultCampaignFiles
+	}
+
+	spec := couponcode.Spec{Prefix: req.Prefix, Charset: req.Charset, Length: length}
+	diagnostics := spec.Validate(req.Count)
+	if name == "" {
+		diagnostics = append(diagnostics, models.FieldError{Field: "name", Message: "name must not be blank"})
+	}
+	if len(diagnostics) > 0 {
+		return models.Campaign{}, &CampaignError{Diagnostics: diagnostics}
+	}
+
+	codes, err := s.uniqueCodes(spec, req.Count)
+	if err != nil {
+		return models.Campaign{}, err
+	}
+
+	id := uuid.New().String()
+	campaign := models.Campaign{
+		ID:         id,
+		Name:       name,
+		Prefix:     req.Prefix,
+		CodeLength: length,
+		CodeCount:  len(codes),
+		FileNames:  campaignFileNames(name, id, files),
+		CreatedBy:  actor,
+	}
+	if err := s.repo.Create(&campaign, codes); err != nil {
+		return models.Campaign{}, err
+	}
+	return campaign, nil
+}
+
+// uniqueCodes generates count codes that are not yet promo codes
+func (s *CampaignService) uniqueCodes(spec couponcode.Spec, count int) ([]string, error) {
+	codes, err := couponcode.Generate(spec, count)
+	if err != nil {
+		return nil, err
+	}
+
+	for range maxCodeReplacements {
+		existing, err := s.repo.ExistingCodes(codes)
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) == 0 {
+			return codes, nil
+		}
+
+		taken := make(map[string]bool, len(codes))
+		for _, code := range codes {
+			taken[code] = true
+		}
+		replace := make(map[string]bool, len(existing))
+		for _, code := range existing {
+			replace[code] = true
+		}
+		for i, code := range codes {
+			if !replace[code] {
+				continue
+			}
+			for taken[codes[i]] {
+				if codes[i], err = couponcode.New(spec); err != nil {
+					return nil, err
+				}
+			}
+			taken[codes[i]] = true
+		}
+	}
+	return nil, errors.New("could not generate codes that are not already in use")
+}
+
+// campaignFileNames returns the coupons.file_name values of a campaign.
+// They name the campaign for redemption reports and include its ID, since
+// names that differ only in punctuation share a slug.
+func campaignFileNames(name, id string, count int) []string {
+	var slug strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if slug.Len() >= maxCampaignSlugLength {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			slug.WriteRune(r)
+		case slug.Len() > 0 && !strings.HasSuffix(slug.String(), "-"):
+			slug.WriteByte('-')
+		}
+	}
+	base := "campaign"
+	if trimmed := strings.TrimSuffix(slug.String(), "-"); trimmed != "" {
+		base += "-" + trimmed
+	}
+
+	names := make([]string, count)
+	for i := range names {
+		names[i] = fmt.Sprintf("%s-%s-%d", base, id[:8], i+1)
+	}
+	return names
+}
+
+// GetCampaign returns a campaign by ID
+func (s *CampaignService) GetCampaign(id string) (models.Campaign, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return models.Campaign{}, ErrCampaignNotFound
+	}
+	return s.repo.GetByID(id)
+}
+
+// ExportCodes returns a campaign with its codes for download
+func (s *CampaignService) ExportCodes(id string) (models.Campaign, []string, error) {
+	campaign, err := s.GetCampaign(id)
+	if err != nil {
+		return models.Campaign{}, nil, err
+	}
+	codes, err := s.repo.Codes(id)
+	if err != nil {
+		return models.Campaign{}, nil, err
+	}
+	return campaign, codes, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCampaignService_CreateCampaign(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewCampaignService(repository.NewCampaignRepository(db))
+	createdAt := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Codes are checked again after a collision is reported
+	mock.ExpectQuery("SELECT DISTINCT upper\\(coupon\\) FROM coupons").
+		WillReturnRows(sqlmock.NewRows([]string{"coupon"}).AddRow("placeholder"))
+	mock.ExpectQuery("SELECT DISTINCT upper\\(coupon\\) FROM coupons").
+		WillReturnRows(sqlmock.NewRows([]string{"coupon"}))
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO campaigns").
+		WithArgs(sqlmock.AnyArg(), "Summer Sale!", "SUM", 10, 50, sqlmock.AnyArg(), "admin").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+	mock.ExpectExec("INSERT INTO campaign_codes").WillReturnResult(sqlmock.NewResult(0, 50))
+	mock.ExpectExec("INSERT INTO coupons").WillReturnResult(sqlmock.NewResult(0, 100))
+	mock.ExpectExec("INSERT INTO cache_invalidations").
+		WithArgs(models.InvalidationTopicCoupons, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(models.AuditActionCampaignCreate, "admin", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	// Test
+	campaign, err := service.CreateCampaign(models.CampaignReq{Name: " Summer Sale! ", Count: 50, Prefix: "SUM", Length: 10}, "admin")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Summer Sale!", campaign.Name)
+	assert.Equal(t, 50, campaign.CodeCount)
+	assert.Len(t, campaign.FileNames, 2)
+	fileName := regexp.MustCompile(`^campaign-summer-sale-[0-9a-f]{8}-[12]$`)
+	for _, name := range campaign.FileNames {
+		assert.Regexp(t, fileName, name)
+	}
+	assert.Equal(t, createdAt, campaign.CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCampaignService_CreateCampaign_Invalid(t *testing.T) {
+	// Setup
+	service := NewCampaignService(nil)
+
+	// Test
+	_, err := service.CreateCampaign(models.CampaignReq{Name: "Summer", Count: 10, Prefix: "SUMMER", Length: 8}, "admin")
+
+	// Assert
+	var campaignErr *CampaignError
+	assert.True(t, errors.As(err, &campaignErr))
+	assert.Equal(t, "prefix", campaignErr.Diagnostics[0].Field)
+}
+
+func TestCampaignService_GetCampaign_InvalidID(t *testing.T) {
+	// Setup
+	service := NewCampaignService(nil)
+
+	// Test
+	_, err := service.GetCampaign("not-a-uuid")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrCampaignNotFound))
+}
+
+func TestCampaignFileNames(t *testing.T) {
+	id := "5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c"
+
+	assert.Equal(t, []string{"campaign-black-friday-5f0c6a4e-1", "campaign-black-friday-5f0c6a4e-2"}, campaignFileNames("Black Friday!!", id, 2))
+	assert.Equal(t, []string{"campaign-5f0c6a4e-1"}, campaignFileNames("¡¡!!", id, 1))
+}
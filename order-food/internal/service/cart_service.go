@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/promocode"
+)
+
+// DefaultCartTTL is how long a cart is kept after it last changed
+const DefaultCartTTL = 7 * 24 * time.Hour
+
+// ErrCartEmpty is returned when checking out a cart without items
+var ErrCartEmpty = errors.New("cart is empty")
+
+// CartService keeps shopping carts and prices them, previewing the
+// discount of their promo code, until they are checked out as orders.
+// Carts store products and quantities only; prices and the discount are
+// worked out on every read, the same way PlaceOrder works them out, so a
+// cart always shows what checking it out would cost.
+type CartService struct {
+	repo       *repository.CartRepository
+	products   *repository.ProductRepository
+	promoCodes *PromoCodeService
+	orders     *OrderService
+	ttl        time.Duration
+}
+
+// NewCartService creates a new cart service. Carts are checked out through
+// orders and deleted once they have not changed for ttl. Without
+// promoCodes, promo codes on carts are not previewed.
+func NewCartService(repo *repository.CartRepository, products *repository.ProductRepository, promoCodes *PromoCodeService, orders *OrderService, ttl time.Duration) *CartService {
+	return &CartService{repo: repo, products: products, promoCodes: promoCodes, orders: orders, ttl: ttl}
+}
+
+// CreateCart stores a new cart for the customer account accountID, or for
+// anyone holding its ID when accountID is empty. The promo code should
+// have been validated by the caller.
+func (s *CartService) CreateCart(ctx context.Context, req models.CartReq, accountID string) (models.Cart, error) {
+	items := make([]models.OrderItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = models.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	items = mergeItems(items)
+	if len(items) > repository.MaxCartItems {
+		return models.Cart{}, repository.ErrCartFull
+	}
+
+	cart := models.StoredCart{
+		ID:         uuid.New().String(),
+		AccountID:  accountID,
+		CouponCode: promocode.Normalize(req.CouponCode),
+		Items:      items,
+	}
+	if err := s.repo.Create(ctx, cart); err != nil {
+		return models.Cart{}, err
+	}
+	return s.GetCart(ctx, cart.ID, accountID)
+}
+
+// GetCart returns a cart priced at the current product prices.
+// repository.ErrCartNotFound is returned for a cart that does not exist
+// or belongs to another account.
+func (s *CartService) GetCart(ctx context.Context, id, accountID string) (models.Cart, error) {
+	stored, err := s.repo.Get(ctx, id, accountID)
+	if err != nil {
+		return models.Cart{}, err
+	}
+	return s.price(stored)
+}
+
+// AddItem puts a product in a cart, or more of it when it is already there
+func (s *CartService) AddItem(ctx context.Context, id, accountID string, req models.CartItemReq) (models.Cart, error) {
+	item := models.OrderItem{ProductID: req.ProductID, Quantity: req.Quantity}
+	if err := s.repo.AddItem(ctx, id, accountID, item); err != nil {
+		return models.Cart{}, err
+	}
+	return s.GetCart(ctx, id, accountID)
+}
+
+// UpdateItem sets the quantity of a product in a cart
+func (s *CartService) UpdateItem(ctx context.Context, id, accountID, productID string, quantity int) (models.Cart, error) {
+	if err := s.repo.SetItemQuantity(ctx, id, accountID, productID, quantity); err != nil {
+		return models.Cart{}, err
+	}
+	return s.GetCart(ctx, id, accountID)
+}
+
+// RemoveItem takes a product out of a cart
+func (s *CartService) RemoveItem(ctx context.Context, id, accountID, productID string) (models.Cart, error) {
+	if err := s.repo.RemoveItem(ctx, id, accountID, productID); err != nil {
+		return models.Cart{}, err
+	}
+	return s.GetCart(ctx, id, accountID)
+}
+
+// SetCouponCode sets the promo code of a cart; an empty code removes it.
+// The code should have been validated by the caller.
+func (s *CartService) SetCouponCode(ctx context.Context, id, accountID, code string) (models.Cart, error) {
+	if err := s.repo.SetCouponCode(ctx, id, accountID, promocode.Normalize(code)); err != nil {
+		return models.Cart{}, err
+	}
+	return s.GetCart(ctx, id, accountID)
+}
+
+// Checkout places an order with the items and promo code of a cart and
+// marks the cart as checked out in the same transaction, so a cart
+// becomes one order at most. repository.ErrCartCheckedOut is returned
+// when it already has, repository.ErrCartChanged when it changed while
+// being checked out and ErrCartEmpty when it has no items; otherwise the
+// errors are those of PlaceOrder.
+func (s *CartService) Checkout(ctx context.Context, id, accountID string, req models.CheckoutReq) (models.Order, error) {
+	cart, err := s.repo.Get(ctx, id, accountID)
+	if err != nil {
+		return models.Order{}, err
+	}
+	if cart.OrderID != "" {
+		return models.Order{}, repository.ErrCartCheckedOut
+	}
+	if len(cart.Items) == 0 {
+		return models.Order{}, ErrCartEmpty
+	}
+
+	orderReq := models.OrderReq{
+		CouponCode:    cart.CouponCode,
+		Items:         cart.Items,
+		ReservationID: req.ReservationID,
+		CustomerID:    req.CustomerID,
+		Delivery:      req.Delivery,
+		PaymentMethod: req.PaymentMethod,
+		AccountID:     accountID,
+	}
+	return s.orders.placeOrder(ctx, orderReq, func(ctx context.Context, order models.Order) error {
+		return s.repo.CheckOut(ctx, id, accountID, order.ID, cart.UpdatedAt)
+	})
+}
+
+// DeleteAbandoned deletes carts that have not changed for the TTL. It is
+// run by the scheduler.
+func (s *CartService) DeleteAbandoned(ctx context.Context) error {
+	count, err := s.repo.DeleteAbandoned(ctx, time.Now().Add(-s.ttl))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		slog.InfoContext(ctx, "Deleted abandoned carts", "count", count)
+	}
+	return nil
+}
+
+// price works out the lines and totals of a stored cart
+func (s *CartService) price(stored models.StoredCart) (models.Cart, error) {
+	ids := make([]string, len(stored.Items))
+	for i, item := range stored.Items {
+		ids[i] = item.ProductID
+	}
+	products, err := s.products.GetByIDs(ids)
+	if err != nil {
+		return models.Cart{}, err
+	}
+
+	items := slices.Clone(stored.Items)
+	lines := lineProducts(items, products)
+	cart := models.Cart{
+		ID:        stored.ID,
+		Subtotal:  calculateTotal(items, lines),
+		OrderID:   stored.OrderID,
+		CreatedAt: stored.CreatedAt,
+		UpdatedAt: stored.UpdatedAt,
+	}
+	if stored.CouponCode != "" && s.promoCodes != nil {
+		cart.Coupon, cart.Discount, err = s.previewCoupon(stored.CouponCode, items, lines)
+		if err != nil {
+			return models.Cart{}, err
+		}
+	}
+	cart.Total = math.Round((cart.Subtotal-cart.Discount)*100) / 100
+
+	cart.Items = make([]models.CartLine, len(items))
+	for i, item := range items {
+		product := lines[i]
+		cart.Items[i] = models.CartLine{
+			ProductID: item.ProductID,
+			Name:      product.Name,
+			Category:  product.Category,
+			UnitPrice: product.Price,
+			Quantity:  item.Quantity,
+			LineTotal: math.Round(product.Price*float64(item.Quantity)*100) / 100,
+			Discount:  item.Discount,
+		}
+	}
+	return cart, nil
+}
+
+// previewCoupon works out the discount code would give on items, whose
+// products are in lines, at checkout, setting the Discount of each, or
+// says why it would not apply
+func (s *CartService) previewCoupon(code string, items []models.OrderItem, lines []models.Product) (*models.CartCoupon, float64, error) {
+	promo, valid, err := s.promoCodes.ValidatePromoCode(code)
+	if errors.Is(err, ErrPromoCodeUnavailable) {
+		return &models.CartCoupon{Code: code, Message: "Promo codes cannot be checked right now"}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	if !valid {
+		return &models.CartCoupon{Code: code, Message: "Promo code is not valid"}, 0, nil
+	}
+
+	limits, err := s.promoCodes.GetLimits(code)
+	if err != nil {
+		return nil, 0, err
+	}
+	if l := limits.Limits; l != nil && l.MaxRedemptions > 0 && l.Redemptions >= l.MaxRedemptions {
+		return &models.CartCoupon{Code: code, Message: "Promo code has reached its usage limit"}, 0, nil
+	}
+
+	amount, err := applyDiscount(promo.Discount, items, lines)
+	if errors.Is(err, ErrPromoCodeNotApplicable) {
+		return &models.CartCoupon{Code: code, Message: "Promo code does not apply to any item in the cart"}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return &models.CartCoupon{Code: code, Applied: true}, amount, nil
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCartService(db *sql.DB) *CartService {
+	promoCodes := NewPromoCodeService(db, nil)
+	orders := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), promoCodes, nil)
+	return NewCartService(repository.NewCartRepository(db), repository.NewProductRepository(db), promoCodes, orders, DefaultCartTTL)
+}
+
+// expectCart expects a cart holding two waffles to be read
+func expectCart(mock sqlmock.Sqlmock, id, couponCode, orderID string, updatedAt time.Time) {
+	mock.ExpectQuery("SELECT COALESCE\\(account_id, ''\\), COALESCE\\(coupon_code, ''\\), COALESCE\\(order_id, ''\\), created_at, updated_at\\s+FROM carts WHERE id = \\$1").
+		WithArgs(id, "").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "coupon_code", "order_id", "created_at", "updated_at"}).
+			AddRow("", couponCode, orderID, updatedAt, updatedAt))
+	mock.ExpectQuery("SELECT i.product_id, i.quantity FROM cart_items i").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "quantity"}).AddRow("1", 2))
+}
+
+// expectWaffle expects the waffle in carts to be looked up
+func expectWaffle(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 6.5, "Waffle", "", "", ""))
+}
+
+func TestCartService_CreateCart_MergesItems(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := newTestCartService(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO carts").
+		WithArgs(sqlmock.AnyArg(), "account-1", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO cart_items").
+		WithArgs(sqlmock.AnyArg(), "1", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("FROM carts WHERE id = \\$1").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "coupon_code", "order_id", "created_at", "updated_at"}).
+			AddRow("account-1", "", "", time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT i.product_id, i.quantity FROM cart_items i").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "quantity"}).AddRow("1", 3))
+	expectWaffle(mock)
+
+	// Test
+	cart, err := service.CreateCart(context.Background(), models.CartReq{
+		Items: []models.CartItemReq{{ProductID: "1", Quantity: 1}, {ProductID: "1", Quantity: 2}},
+	}, "account-1")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, cart.Items, 1)
+	assert.Equal(t, 19.5, cart.Items[0].LineTotal)
+	assert.Equal(t, 19.5, cart.Total)
+	assert.Nil(t, cart.Coupon)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartService_CreateCart_UnknownProduct(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := newTestCartService(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO carts").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO cart_items").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	// Test
+	_, err = service.CreateCart(context.Background(), models.CartReq{
+		Items: []models.CartItemReq{{ProductID: "99", Quantity: 1}},
+	}, "")
+
+	// Assert
+	assert.True(t, errors.Is(err, repository.ErrProductNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartService_GetCart_PreviewsPromoCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		count        int
+		maxUses      int
+		uses         int
+		categories   any
+		wantApplied  bool
+		wantMessage  string
+		wantDiscount float64
+	}{
+		{name: "applied", count: 2, wantApplied: true, wantDiscount: 1.3},
+		{name: "not valid", count: 1, wantMessage: "Promo code is not valid"},
+		{name: "used up", count: 2, maxUses: 5, uses: 5, wantMessage: "Promo code has reached its usage limit"},
+		{name: "not applicable", count: 2, categories: "{Cake}", wantMessage: "Promo code does not apply to any item in the cart"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			service := newTestCartService(db)
+
+			expectCart(mock, "cart-1", "HAPPYHRS", "", time.Now())
+			expectWaffle(mock)
+			mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+				WithArgs("HAPPYHRS", 2).
+				WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(tt.count, "percentage", 10.0, tt.categories, nil))
+			if tt.count == 2 {
+				mock.ExpectQuery("SELECT COALESCE\\(\\(SELECT max_redemptions FROM coupon_limits").
+					WithArgs("HAPPYHRS").
+					WillReturnRows(sqlmock.NewRows([]string{"max_redemptions", "once_per_customer", "count"}).
+						AddRow(tt.maxUses, false, tt.uses))
+			}
+
+			// Test
+			cart, err := service.GetCart(context.Background(), "cart-1", "")
+
+			// Assert
+			assert.NoError(t, err)
+			if assert.NotNil(t, cart.Coupon) {
+				assert.Equal(t, "HAPPYHRS", cart.Coupon.Code)
+				assert.Equal(t, tt.wantApplied, cart.Coupon.Applied)
+				assert.Equal(t, tt.wantMessage, cart.Coupon.Message)
+			}
+			assert.Equal(t, 13.0, cart.Subtotal)
+			assert.Equal(t, tt.wantDiscount, cart.Discount)
+			assert.Equal(t, 13.0-tt.wantDiscount, cart.Total)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCartService_GetCart_NotFound(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := newTestCartService(db)
+
+	mock.ExpectQuery("FROM carts WHERE id = \\$1").
+		WithArgs("cart-1", "account-2").
+		WillReturnError(sql.ErrNoRows)
+
+	// Test
+	_, err = service.GetCart(context.Background(), "cart-1", "account-2")
+
+	// Assert
+	assert.True(t, errors.Is(err, repository.ErrCartNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartService_AddItem_CheckedOut(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := newTestCartService(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT order_id IS NOT NULL FROM carts\\s+WHERE id = \\$1 .* FOR UPDATE").
+		WithArgs("cart-1", "").
+		WillReturnRows(sqlmock.NewRows([]string{"checked_out"}).AddRow(true))
+	mock.ExpectRollback()
+
+	// Test
+	_, err = service.AddItem(context.Background(), "cart-1", "", models.CartItemReq{ProductID: "1", Quantity: 1})
+
+	// Assert
+	assert.True(t, errors.Is(err, repository.ErrCartCheckedOut))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartService_AddItem_Full(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := newTestCartService(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"checked_out"}).AddRow(false))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\), COALESCE\\(BOOL_OR\\(product_id = \\$2\\), FALSE\\) FROM cart_items").
+		WithArgs("cart-1", "101").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "present"}).AddRow(repository.MaxCartItems, false))
+	mock.ExpectRollback()
+
+	// Test
+	_, err = service.AddItem(context.Background(), "cart-1", "", models.CartItemReq{ProductID: "101", Quantity: 1})
+
+	// Assert
+	assert.True(t, errors.Is(err, repository.ErrCartFull))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartService_Checkout_PlacesOrder(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := newTestCartService(db)
+	updatedAt := time.Now()
+
+	expectCart(mock, "cart-1", "HAPPYHRS", "", updatedAt)
+	expectWaffle(mock)
+	expectNoOptionGroups(mock)
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("HAPPYHRS", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, "percentage", 10.0, nil, nil))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE carts SET order_id = \\$3").
+		WithArgs("cart-1", "", sqlmock.AnyArg(), updatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id, stock FROM products").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock"}).AddRow("1", nil))
+	mock.ExpectExec("UPDATE products SET stock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO orders").
+		WithArgs(sqlmock.AnyArg(), "HAPPYHRS", models.OrderStatusPending, 11.7, 1.3, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO order_items").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("UPDATE coupon_limits SET redemptions").
+		WithArgs("HAPPYHRS").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("INSERT INTO coupon_redemptions").
+		WithArgs("HAPPYHRS", sqlmock.AnyArg(), "customer-1", 1.3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Test
+	order, err := service.Checkout(context.Background(), "cart-1", "", models.CheckoutReq{CustomerID: "customer-1"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "HAPPYHRS", order.CouponCode)
+	assert.Equal(t, 11.7, order.Total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartService_Checkout_ChangedMeanwhile(t *testing.T) {
+	tests := []struct {
+		name       string
+		checkedOut bool
+		wantErr    error
+	}{
+		{name: "checked out concurrently", checkedOut: true, wantErr: repository.ErrCartCheckedOut},
+		{name: "items changed", checkedOut: false, wantErr: repository.ErrCartChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			service := newTestCartService(db)
+
+			expectCart(mock, "cart-1", "", "", time.Now())
+			expectWaffle(mock)
+			expectNoOptionGroups(mock)
+			mock.ExpectBegin()
+			mock.ExpectExec("UPDATE carts SET order_id = \\$3").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT order_id IS NOT NULL FROM carts WHERE id = \\$1").
+				WithArgs("cart-1").
+				WillReturnRows(sqlmock.NewRows([]string{"checked_out"}).AddRow(tt.checkedOut))
+			mock.ExpectRollback()
+
+			// Test
+			_, err = service.Checkout(context.Background(), "cart-1", "", models.CheckoutReq{})
+
+			// Assert
+			assert.True(t, errors.Is(err, tt.wantErr))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCartService_Checkout_AlreadyCheckedOut(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := newTestCartService(db)
+
+	expectCart(mock, "cart-1", "", "order-1", time.Now())
+
+	// Test
+	_, err = service.Checkout(context.Background(), "cart-1", "", models.CheckoutReq{})
+
+	// Assert
+	assert.True(t, errors.Is(err, repository.ErrCartCheckedOut))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartService_Checkout_Empty(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := newTestCartService(db)
+
+	mock.ExpectQuery("FROM carts WHERE id = \\$1").
+		WillReturnRows(sqlmock.NewRows([]string{"account_id", "coupon_code", "order_id", "created_at", "updated_at"}).
+			AddRow("", "", "", time.Now(), time.Now()))
+	mock.ExpectQuery("SELECT i.product_id, i.quantity FROM cart_items i").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "quantity"}))
+
+	// Test
+	_, err = service.Checkout(context.Background(), "cart-1", "", models.CheckoutReq{})
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrCartEmpty))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCartService_DeleteAbandoned(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := newTestCartService(db)
+
+	mock.ExpectExec("DELETE FROM carts WHERE updated_at < \\$1").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	// Test
+	err = service.DeleteAbandoned(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+var (
+	// ErrInvalidCategory is returned for category details that cannot be
+	// stored
+	ErrInvalidCategory = errors.New("invalid category")
+	// ErrCategoryNotFound is returned when a category does not exist
+	ErrCategoryNotFound = repository.ErrCategoryNotFound
+	// ErrCategoryExists is returned when another category has the same ID
+	// or name
+	ErrCategoryExists = repository.ErrCategoryExists
+	// ErrCategoryInUse is returned when deleting a category products still
+	// name
+	ErrCategoryInUse = repository.ErrCategoryInUse
+)
+
+// categorySlugSeparators are the runs of characters replaced by a dash
+// when a category ID is derived from its name
+var categorySlugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// CategoryService handles category business logic
+type CategoryService struct {
+	repo     *repository.CategoryRepository
+	products *repository.ProductRepository
+	// events publishes the products a rename moves when non-nil
+	events EventPublisher
+}
+
+// NewCategoryService creates a new category service
+func NewCategoryService(repo *repository.CategoryRepository, products *repository.ProductRepository) *CategoryService {
+	return &CategoryService{repo: repo, products: products}
+}
+
+// SetEventPublisher makes the service publish a ProductUpdated event for
+// every product a category rename carries the new name over to
+func (s *CategoryService) SetEventPublisher(events EventPublisher) {
+	s.events = events
+}
+
+// ListCategories returns every category ordered by name
+func (s *CategoryService) ListCategories(ctx context.Context) ([]models.Category, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// GetCategory returns the category with the given ID
+func (s *CategoryService) GetCategory(ctx context.Context, id string) (models.Category, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// CreateCategory adds a category on behalf of actor. Its ID is derived
+// from its name when req has none.
+func (s *CategoryService) CreateCategory(ctx context.Context, req models.CategoryReq, actor string) (models.Category, error) {
+	if strings.TrimSpace(req.ID) == "" {
+		req.ID = categorySlug(req.Name)
+	}
+	category, err := categoryFromRequest(req)
+	if err != nil {
+		return models.Category{}, err
+	}
+	err = s.repo.Create(ctx, category, models.AuditEntry{
+		Action:  models.AuditActionCategoryCreate,
+		Actor:   actor,
+		Details: category,
+	})
+	if err != nil {
+		return models.Category{}, err
+	}
+	return category, nil
+}
+
+// UpdateCategory replaces the name and description of the category with
+// the given ID on behalf of actor. Its products show a new name at once.
+func (s *CategoryService) UpdateCategory(ctx context.Context, id string, req models.CategoryReq, actor string) (models.Category, error) {
+	req.ID = id
+	category, err := categoryFromRequest(req)
+	if err != nil {
+		return models.Category{}, err
+	}
+	renamed, err := s.repo.Update(ctx, category, models.AuditEntry{
+		Action:  models.AuditActionCategoryUpdate,
+		Actor:   actor,
+		Details: category,
+	})
+	if err != nil {
+		return models.Category{}, err
+	}
+	if len(renamed) > 0 && s.events != nil {
+		s.publishRenamed(ctx, category.Name)
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// publishRenamed publishes a ProductUpdated event for every product of the
+// category with the given name. The rename has committed by then, so a
+// failure to read the products is only logged.
+func (s *CategoryService) publishRenamed(ctx context.Context, name string) {
+	products, err := s.products.GetByCategoriesOrIDs([]string{name}, nil)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to read renamed products", "category", name, "error", err)
+		return
+	}
+	for _, product := range products {
+		publishEvent(ctx, s.events, models.EventTypeProductUpdated, product.ID, productUpdated(models.ProductChangeUpdated, product))
+	}
+}
+
+// DeleteCategory removes the category with the given ID on behalf of
+// actor. Categories with products cannot be deleted.
+func (s *CategoryService) DeleteCategory(ctx context.Context, id, actor string) error {
+	return s.repo.Delete(ctx, id, models.AuditEntry{
+		Action:  models.AuditActionCategoryDelete,
+		Actor:   actor,
+		Details: map[string]string{"id": id},
+	})
+}
+
+// categorySlug derives a category ID from a name: lower case, with runs
+// of other characters turned into dashes
+func categorySlug(name string) string {
+	slug := strings.Trim(categorySlugSeparators.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(slug) > 50 {
+		slug = strings.TrimRight(slug[:50], "-")
+	}
+	return slug
+}
+
+// categoryFromRequest validates req and returns the category it
+// describes, with surrounding whitespace trimmed
+func categoryFromRequest(req models.CategoryReq) (models.Category, error) {
+	category := models.Category{
+		ID:          strings.TrimSpace(req.ID),
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+	}
+	switch {
+	case !productIDPattern.MatchString(category.ID):
+		return models.Category{}, fmt.Errorf("%w: id must be 1-50 letters, digits, dashes or underscores", ErrInvalidCategory)
+	case category.Name == "":
+		return models.Category{}, fmt.Errorf("%w: name is required", ErrInvalidCategory)
+	}
+	return category, nil
+}
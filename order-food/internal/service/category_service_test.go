@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryService_CreateCategory(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewCategoryService(repository.NewCategoryRepository(db), repository.NewProductRepository(db))
+	// The ID is derived from the name when none is given
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO categories").
+		WithArgs("ice-cream-sundaes", "Ice Cream & Sundaes", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(models.AuditActionCategoryCreate, "admin", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	// Test
+	category, err := service.CreateCategory(context.Background(), models.CategoryReq{Name: " Ice Cream & Sundaes "}, "admin")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.Category{ID: "ice-cream-sundaes", Name: "Ice Cream & Sundaes"}, category)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryService_CreateCategory_Exists(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewCategoryService(repository.NewCategoryRepository(db), repository.NewProductRepository(db))
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO categories").
+		WillReturnError(&pq.Error{Code: "23505", Message: `duplicate key value violates unique constraint "uq_categories_name"`})
+	mock.ExpectRollback()
+
+	// Test
+	_, err = service.CreateCategory(context.Background(), models.CategoryReq{ID: "waffles", Name: "Waffle"}, "admin")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrCategoryExists))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryService_CreateCategory_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		req  models.CategoryReq
+	}{
+		{name: "id with slash", req: models.CategoryReq{ID: "a/b", Name: "Waffle"}},
+		{name: "blank name", req: models.CategoryReq{ID: "waffle", Name: "  "}},
+		{name: "name without letters or digits", req: models.CategoryReq{Name: "&&"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			service := NewCategoryService(repository.NewCategoryRepository(nil), nil)
+
+			// Test
+			_, err := service.CreateCategory(context.Background(), tt.req, "admin")
+
+			// Assert
+			assert.True(t, errors.Is(err, ErrInvalidCategory))
+		})
+	}
+}
+
+func TestCategoryService_UpdateCategory_Rename(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewCategoryService(repository.NewCategoryRepository(db), repository.NewProductRepository(db))
+	// The foreign key carries the new name over, discounts scoped to the
+	// category are renamed, and the cached copies of the products are
+	// invalidated
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT name FROM categories").
+		WithArgs("waffle").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Waffle"))
+	mock.ExpectExec("UPDATE categories").
+		WithArgs("waffle", "Waffles", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id FROM products WHERE category_id = \\$1").
+		WithArgs("waffle").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1").AddRow("2"))
+	mock.ExpectExec("UPDATE coupon_discounts SET categories = array_replace").
+		WithArgs("Waffle", "Waffles").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(models.AuditActionCategoryUpdate, "admin", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO cache_invalidations").
+		WithArgs(models.InvalidationTopicProducts, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT (.+) FROM categories c WHERE c.id").
+		WithArgs("waffle").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "count"}).AddRow("waffle", "Waffles", "", 2))
+
+	// Test
+	category, err := service.UpdateCategory(context.Background(), "waffle", models.CategoryReq{Name: "Waffles"}, "admin")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.Category{ID: "waffle", Name: "Waffles", ProductCount: 2}, category)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryService_UpdateCategory_SameName(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewCategoryService(repository.NewCategoryRepository(db), repository.NewProductRepository(db))
+	// Products and discounts are left alone when the name does not change
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT name FROM categories").
+		WithArgs("waffle").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Waffle"))
+	mock.ExpectExec("UPDATE categories").
+		WithArgs("waffle", "Waffle", "Crispy").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(models.AuditActionCategoryUpdate, "admin", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT (.+) FROM categories c WHERE c.id").
+		WithArgs("waffle").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "count"}).AddRow("waffle", "Waffle", "Crispy", 2))
+
+	// Test
+	_, err = service.UpdateCategory(context.Background(), "waffle", models.CategoryReq{Name: "Waffle", Description: "Crispy"}, "admin")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryService_UpdateCategory_NotFound(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewCategoryService(repository.NewCategoryRepository(db), repository.NewProductRepository(db))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT name FROM categories").
+		WithArgs("pizza").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	mock.ExpectRollback()
+
+	// Test
+	_, err = service.UpdateCategory(context.Background(), "pizza", models.CategoryReq{Name: "Pizza"}, "admin")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrCategoryNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryService_DeleteCategory_InUse(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewCategoryService(repository.NewCategoryRepository(db), repository.NewProductRepository(db))
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM categories").
+		WithArgs("waffle").
+		WillReturnError(&pq.Error{Code: "23503", Message: `update or delete on table "categories" violates foreign key constraint "fk_products_category"`})
+	mock.ExpectRollback()
+
+	// Test
+	err = service.DeleteCategory(context.Background(), "waffle", "admin")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrCategoryInUse))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
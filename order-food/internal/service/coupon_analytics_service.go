@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+// MaxCouponAnalyticsRange bounds the date range of a single analytics request
+// so the aggregate queries stay cheap enough to run against the primary
+const MaxCouponAnalyticsRange = 366 * 24 * time.Hour
+
+// ErrInvalidDateRange is returned when a date range is empty, reversed or too long
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// CouponAnalyticsService reports promo code usage
+type CouponAnalyticsService struct {
+	repo *repository.CouponRepository
+}
+
+// NewCouponAnalyticsService creates a new coupon analytics service
+func NewCouponAnalyticsService(repo *repository.CouponRepository) *CouponAnalyticsService {
+	return &CouponAnalyticsService{repo: repo}
+}
+
+// Analytics reports promo code usage for orders placed in [from, to),
+// listing at most limit codes and files. Daily figures are bucketed in the
+// time zone of from. Redemptions of archived orders are included; the
+// orders themselves are not.
+func (s *CouponAnalyticsService) Analytics(ctx context.Context, from, to time.Time, limit int) (models.CouponAnalytics, error) {
+	if !to.After(from) {
+		return models.CouponAnalytics{}, fmt.Errorf("%w: to must be after from", ErrInvalidDateRange)
+	}
+	if to.Sub(from) > MaxCouponAnalyticsRange {
+		return models.CouponAnalytics{}, fmt.Errorf("%w: range must not exceed 366 days", ErrInvalidDateRange)
+	}
+
+	orders, redemptions, discountTotal, err := s.repo.RedemptionSummary(ctx, from, to)
+	if err != nil {
+		return models.CouponAnalytics{}, err
+	}
+	byCode, err := s.repo.RedemptionsByCode(ctx, from, to, limit)
+	if err != nil {
+		return models.CouponAnalytics{}, err
+	}
+	byFile, err := s.repo.RedemptionsByFile(ctx, from, to, limit)
+	if err != nil {
+		return models.CouponAnalytics{}, err
+	}
+	// Server-local time has no portable zone name; report it in UTC
+	if from.Location() == time.Local {
+		from, to = from.UTC(), to.UTC()
+	}
+	zone := from.Location().String()
+	byDay, err := s.repo.RedemptionsByDay(ctx, from, to, zone)
+	if err != nil {
+		return models.CouponAnalytics{}, err
+	}
+
+	for i := range byCode {
+		byCode[i].ConversionRate = rate(byCode[i].Redemptions, orders)
+	}
+	for i := range byFile {
+		byFile[i].ConversionRate = rate(byFile[i].Redemptions, orders)
+	}
+
+	return models.CouponAnalytics{
+		From:           from,
+		To:             to,
+		TimeZone:       zone,
+		Orders:         orders,
+		Redemptions:    redemptions,
+		RedemptionRate: rate(redemptions, orders),
+		DiscountTotal:  discountTotal,
+		ByCode:         byCode,
+		ByFile:         byFile,
+		ByDay:          byDay,
+	}, nil
+}
+
+// rate returns part/whole rounded to four decimal places, or 0 when whole is 0
+func rate(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 10000
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCouponAnalyticsService_Analytics(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewCouponAnalyticsService(repository.NewCouponRepository(db))
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	mock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM orders").
+		WithArgs(from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "redemptions", "sum"}).AddRow(200, 50, 96.5))
+	mock.ExpectQuery("SELECT coupon, COUNT\\(\\*\\), COALESCE\\(SUM\\(discount\\), 0\\)\\s+FROM coupon_redemptions").
+		WithArgs(from, to, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"coupon", "count", "sum"}).
+			AddRow("HAPPYHRS", 30, 60.0).
+			AddRow("FIFTYOFF", 20, 36.5))
+	mock.ExpectQuery("SELECT file_name, COUNT\\(DISTINCT coupon\\), COUNT\\(\\*\\), COALESCE\\(SUM\\(discount\\), 0\\)\\s+FROM \\(SELECT DISTINCT c.file_name, r.id").
+		WithArgs(from, to, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"file_name", "codes", "count", "sum"}).
+			AddRow("couponbase1.gz", 2, 50, 96.5).
+			AddRow("couponbase2.gz", 1, 30, 60.0))
+	mock.ExpectQuery("SELECT day, COALESCE\\(o.orders, 0\\), COALESCE\\(r.redemptions, 0\\)").
+		WithArgs(from, to, "UTC").
+		WillReturnRows(sqlmock.NewRows([]string{"day", "count", "count"}).
+			AddRow("2024-01-01", 120, 30).
+			AddRow("2024-01-02", 80, 20))
+
+	// Test
+	analytics, err := service.Analytics(context.Background(), from, to, 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 200, analytics.Orders)
+	assert.Equal(t, 50, analytics.Redemptions)
+	assert.Equal(t, 0.25, analytics.RedemptionRate)
+	assert.Equal(t, 96.5, analytics.DiscountTotal)
+	assert.Len(t, analytics.ByCode, 2)
+	assert.Equal(t, "HAPPYHRS", analytics.ByCode[0].Code)
+	assert.Equal(t, 0.15, analytics.ByCode[0].ConversionRate)
+	assert.Equal(t, 60.0, analytics.ByCode[0].DiscountTotal)
+	assert.Len(t, analytics.ByFile, 2)
+	assert.Equal(t, 2, analytics.ByFile[0].RedeemedCodes)
+	assert.Equal(t, 0.25, analytics.ByFile[0].ConversionRate)
+	assert.Equal(t, "UTC", analytics.TimeZone)
+	assert.Len(t, analytics.ByDay, 2)
+	assert.Equal(t, "2024-01-01", analytics.ByDay[0].Date)
+	assert.Equal(t, 30, analytics.ByDay[0].Redemptions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCouponAnalyticsService_Analytics_InvalidRange(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		to   time.Time
+	}{
+		{name: "empty", to: from},
+		{name: "reversed", to: from.Add(-time.Hour)},
+		{name: "too long", to: from.AddDate(2, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			service := NewCouponAnalyticsService(nil)
+
+			// Test
+			_, err := service.Analytics(context.Background(), from, tt.to, 10)
+
+			// Assert
+			assert.True(t, errors.Is(err, ErrInvalidDateRange))
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+// couponFileNamePattern accepts the plain .txt names the loader picks up
+var couponFileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,250}\.txt$`)
+
+var (
+	// ErrInvalidCouponFileName is returned for names the loader would not pick up
+	ErrInvalidCouponFileName = errors.New("coupon file name must be a plain file name ending in .txt")
+	// ErrCouponUploadsDisabled is returned when no coupon data directory is configured
+	ErrCouponUploadsDisabled = errors.New("coupon file uploads are not enabled")
+	// ErrEmptyCouponFile is returned when an uploaded coupon file has no content
+	ErrEmptyCouponFile = errors.New("coupon file is empty")
+	// ErrCouponFileExists is returned when a coupon file with the same name exists
+	ErrCouponFileExists = repository.ErrCouponFileExists
+	// ErrCouponFileNotFound is returned when a coupon file upload does not exist
+	ErrCouponFileNotFound = repository.ErrCouponFileNotFound
+)
+
+// CouponFileService accepts coupon files uploaded by marketing and hands
+// them to the loader. Files are written to the coupon data directory shared
+// with the loader, which loads pending uploads asynchronously and records
+// the outcome on the upload.
+type CouponFileService struct {
+	repo *repository.CouponFileRepository
+	dir  string
+}
+
+// NewCouponFileService creates a new coupon file service writing to dir.
+// Uploads are refused when dir is empty.
+func NewCouponFileService(repo *repository.CouponFileRepository, dir string) *CouponFileService {
+	return &CouponFileService{repo: repo, dir: dir}
+}
+
+// Upload stores content as the coupon file name on behalf of actor and
+// queues it for loading in operationID, which the loader continues. The
+// file only appears under its name once it is complete, so a loader
+// scanning the directory never reads a partial file.
+func (s *CouponFileService) Upload(name string, content io.Reader, actor, operationID string) (models.CouponFileUpload, error) {
+	if s.dir == "" {
+		return models.CouponFileUpload{}, ErrCouponUploadsDisabled
+	}
+	if !couponFileNamePattern.MatchString(name) {
+		return models.CouponFileUpload{}, ErrInvalidCouponFileName
+	}
+
+	// Files copied to the volume by hand are not in the table
+	path := filepath.Join(s.dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return models.CouponFileUpload{}, ErrCouponFileExists
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return models.CouponFileUpload{}, fmt.Errorf("error checking coupon file: %w", err)
+	}
+
+	upload := models.CouponFileUpload{ID: uuid.New().String(), FileName: name, UploadedBy: actor, OperationID: operationID}
+	if err := s.repo.Create(&upload); err != nil {
+		return models.CouponFileUpload{}, err
+	}
+
+	size, err := s.store(path, content)
+	if err != nil {
+		if deleteErr := s.repo.Delete(upload.ID); deleteErr != nil {
+			slog.Error("Failed to release coupon file name", "file", name, "error", deleteErr)
+		}
+		return models.CouponFileUpload{}, err
+	}
+
+	return s.repo.MarkPending(upload.ID, size)
+}
+
+// store writes content to a hidden partial file next to path and renames
+// it into place once complete, returning its size
+func (s *CouponFileService) store(path string, content io.Reader) (int64, error) {
+	part, err := os.CreateTemp(s.dir, "."+filepath.Base(path)+".*.part")
+	if err != nil {
+		return 0, fmt.Errorf("error creating coupon file: %w", err)
+	}
+
+	size, err := io.Copy(part, content)
+	if err == nil {
+		err = part.Sync()
+	}
+	if closeErr := part.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && size == 0 {
+		err = ErrEmptyCouponFile
+	}
+	if err == nil {
+		err = os.Rename(part.Name(), path)
+	}
+	if err != nil {
+		os.Remove(part.Name())
+		return 0, err
+	}
+
+	return size, nil
+}
+
+// GetUpload returns a coupon file upload and its loading status
+func (s *CouponFileService) GetUpload(id string) (models.CouponFileUpload, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return models.CouponFileUpload{}, ErrCouponFileNotFound
+	}
+	return s.repo.GetByID(id)
+}
+
+// ListUploads returns coupon file uploads with pagination, newest first
+func (s *CouponFileService) ListUploads(limit, offset int) ([]models.CouponFileUpload, int, error) {
+	return s.repo.GetAll(limit, offset)
+}
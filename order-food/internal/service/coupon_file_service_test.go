@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+var couponFileRowColumns = []string{
+	"id", "file_name", "size_bytes", "status", "coupons_loaded", "error", "uploaded_by", "operation_id", "created_at", "updated_at",
+}
+
+func TestCouponFileService_Upload(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dir := t.TempDir()
+	service := NewCouponFileService(repository.NewCouponFileRepository(db), dir)
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO coupon_file_uploads").
+		WithArgs(sqlmock.AnyArg(), "couponbase4.txt", models.CouponFileStatusReceiving, "admin", "").
+		WillReturnRows(sqlmock.NewRows(couponFileRowColumns).
+			AddRow("u-1", "couponbase4.txt", 0, models.CouponFileStatusReceiving, 0, "", "admin", "", now, now))
+	mock.ExpectQuery("UPDATE coupon_file_uploads").
+		WithArgs("u-1", models.CouponFileStatusPending, int64(18)).
+		WillReturnRows(sqlmock.NewRows(couponFileRowColumns).
+			AddRow("u-1", "couponbase4.txt", 18, models.CouponFileStatusPending, 0, "", "admin", "", now, now))
+
+	// Test
+	upload, err := service.Upload("couponbase4.txt", strings.NewReader("HAPPYHRS\nFIFTYOFF\n"), "admin", "")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.CouponFileStatusPending, upload.Status)
+	content, err := os.ReadFile(filepath.Join(dir, "couponbase4.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "HAPPYHRS\nFIFTYOFF\n", string(content))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCouponFileService_Upload_FailedWriteReleasesName(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	dir := t.TempDir()
+	service := NewCouponFileService(repository.NewCouponFileRepository(db), dir)
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO coupon_file_uploads").
+		WillReturnRows(sqlmock.NewRows(couponFileRowColumns).
+			AddRow("u-1", "couponbase4.txt", 0, models.CouponFileStatusReceiving, 0, "", "admin", "", now, now))
+	mock.ExpectExec("DELETE FROM coupon_file_uploads").
+		WithArgs("u-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Test
+	_, err = service.Upload("couponbase4.txt", strings.NewReader(""), "admin", "")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrEmptyCouponFile))
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries, "partial file must be removed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCouponFileService_Upload_Rejects(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "couponbase1.txt"), []byte("CODE\n"), 0o644))
+
+	tests := []struct {
+		name     string
+		dir      string
+		fileName string
+		wantErr  error
+	}{
+		{name: "disabled", fileName: "couponbase4.txt", wantErr: ErrCouponUploadsDisabled},
+		{name: "path traversal", dir: dir, fileName: "../couponbase4.txt", wantErr: ErrInvalidCouponFileName},
+		{name: "hidden file", dir: dir, fileName: ".couponbase4.txt", wantErr: ErrInvalidCouponFileName},
+		{name: "wrong extension", dir: dir, fileName: "couponbase4.gz", wantErr: ErrInvalidCouponFileName},
+		{name: "copied by hand", dir: dir, fileName: "couponbase1.txt", wantErr: ErrCouponFileExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			service := NewCouponFileService(nil, tt.dir)
+
+			// Test
+			_, err := service.Upload(tt.fileName, strings.NewReader("CODE\n"), "admin", "")
+
+			// Assert
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/jwt"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+const (
+	// passwordHashScheme names the password hash format
+	passwordHashScheme = "pbkdf2-sha256"
+	// passwordIterations is the PBKDF2 work factor recommended by OWASP
+	// for HMAC-SHA256; stored hashes keep their own count, so it can be
+	// raised without invalidating them
+	passwordIterations = 600000
+	passwordSaltBytes  = 16
+	passwordKeyBytes   = 32
+)
+
+// ScopeOrdersPlace lets a caller place orders without managing them; it is
+// granted to customers
+const ScopeOrdersPlace = "orders:place"
+
+// CustomerScopes are the scopes of a customer access token. Customers only
+// ever see their own orders.
+var CustomerScopes = []string{ScopeOrdersRead, ScopeOrdersPlace}
+
+var (
+	// ErrCustomerNotFound is returned when a customer does not exist
+	ErrCustomerNotFound = repository.ErrCustomerNotFound
+	// ErrEmailTaken is returned when registering an email that already has an account
+	ErrEmailTaken = repository.ErrDuplicateEmail
+	// ErrInvalidCredentials is returned when an email and password do not
+	// match an account; which of the two is wrong is not revealed
+	ErrInvalidCredentials = errors.New("invalid email or password")
+)
+
+// dummyPasswordHash is checked against when logging in with an unknown
+// email, so the response takes as long as for a wrong password
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := hashPassword(uuid.NewString())
+	return hash
+})
+
+// CustomerService handles customer accounts and their access tokens
+type CustomerService struct {
+	repo   *repository.CustomerRepository
+	tokens *jwt.Signer
+}
+
+// NewCustomerService creates a new customer service issuing access tokens
+// with tokens
+func NewCustomerService(repo *repository.CustomerRepository, tokens *jwt.Signer) *CustomerService {
+	return &CustomerService{repo: repo, tokens: tokens}
+}
+
+// Register creates a customer account
+func (s *CustomerService) Register(req models.CustomerReq) (models.Customer, error) {
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return models.Customer{}, err
+	}
+
+	customer := models.Customer{
+		ID:    uuid.New().String(),
+		Email: normalizeEmail(req.Email),
+		Name:  strings.TrimSpace(req.Name),
+	}
+	if err := s.repo.Create(&customer, hash); err != nil {
+		return models.Customer{}, err
+	}
+	return customer, nil
+}
+
+// Login checks a customer's email and password and issues an access token
+func (s *CustomerService) Login(req models.LoginReq) (models.AccessToken, error) {
+	customer, hash, err := s.repo.FindByEmail(normalizeEmail(req.Email))
+	if errors.Is(err, repository.ErrCustomerNotFound) {
+		checkPassword(req.Password, dummyPasswordHash())
+		return models.AccessToken{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return models.AccessToken{}, err
+	}
+	if !checkPassword(req.Password, hash) {
+		return models.AccessToken{}, ErrInvalidCredentials
+	}
+
+	token, expiresAt, err := s.tokens.Issue(customer.ID)
+	if err != nil {
+		return models.AccessToken{}, fmt.Errorf("failed to issue access token: %w", err)
+	}
+	return models.AccessToken{Token: token, TokenType: "Bearer", ExpiresAt: expiresAt, Customer: customer}, nil
+}
+
+// GetCustomer returns a customer by ID
+func (s *CustomerService) GetCustomer(id string) (models.Customer, error) {
+	return s.repo.GetByID(id)
+}
+
+// VerifyToken resolves an access token to the customer it was issued to
+// and the customer scopes
+func (s *CustomerService) VerifyToken(token string) (string, []string, error) {
+	claims, err := s.tokens.Verify(token)
+	if err != nil {
+		return "", nil, err
+	}
+	return claims.Subject, CustomerScopes, nil
+}
+
+// normalizeEmail returns the form emails are stored and looked up in
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// hashPassword returns a salted PBKDF2 hash of password as
+// "pbkdf2-sha256$<iterations>$<salt>$<key>"
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, passwordKeyBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return strings.Join([]string{
+		passwordHashScheme,
+		strconv.Itoa(passwordIterations),
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	}, "$"), nil
+}
+
+// checkPassword reports whether password matches a hash from hashPassword
+func checkPassword(password, hash string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != passwordHashScheme {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations < 1 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	return err == nil && subtle.ConstantTimeCompare(key, want) == 1
+}
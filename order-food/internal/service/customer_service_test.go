@@ -0,0 +1,170 @@
+package service
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/jwt"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/pii"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+const testJWTSecret = "test-secret-of-at-least-32-bytes!"
+
+// capturedArg is a sqlmock argument matcher that accepts any string and
+// keeps it, for asserting on values the code under test generates
+type capturedArg struct {
+	value *string
+}
+
+func (c capturedArg) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	*c.value = s
+	return ok
+}
+
+// capture returns a matcher storing the argument in value
+func capture(value *string) sqlmock.Argument {
+	return capturedArg{value: value}
+}
+
+func TestHashPassword(t *testing.T) {
+	// Execute
+	hash, err := hashPassword("correct horse")
+	other, _ := hashPassword("correct horse")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotContains(t, hash, "correct horse")
+	assert.NotEqual(t, hash, other, "each hash has its own salt")
+	assert.True(t, checkPassword("correct horse", hash))
+	assert.False(t, checkPassword("wrong horse", hash))
+	assert.False(t, checkPassword("correct horse", "not-a-hash"))
+}
+
+func TestCustomerService_Login(t *testing.T) {
+	hash, err := hashPassword("correct horse")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		rows     *sqlmock.Rows
+		wantErr  error
+	}{
+		{
+			name:     "valid credentials",
+			email:    " Ada@Example.com",
+			password: "correct horse",
+			rows:     sqlmock.NewRows([]string{"id", "email", "name", "created_at", "password_hash"}).AddRow("c1", "ada@example.com", "Ada", time.Now(), hash),
+		},
+		{
+			name:     "wrong password",
+			email:    "ada@example.com",
+			password: "wrong horse",
+			rows:     sqlmock.NewRows([]string{"id", "email", "name", "created_at", "password_hash"}).AddRow("c1", "ada@example.com", "Ada", time.Now(), hash),
+			wantErr:  ErrInvalidCredentials,
+		},
+		{
+			name:     "unknown email",
+			email:    "bob@example.com",
+			password: "correct horse",
+			rows:     sqlmock.NewRows([]string{"id", "email", "name", "created_at", "password_hash"}),
+			wantErr:  ErrInvalidCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			index := pii.NewBlindIndex([]byte("blind-index-key-of-32-bytes-long"))
+			mock.ExpectQuery("FROM customers\\s+WHERE email_hash = \\$1 OR \\(email_hash IS NULL AND email = \\$2\\)").
+				WithArgs(index.Hash(normalizeEmail(tt.email)), normalizeEmail(tt.email)).
+				WillReturnRows(tt.rows)
+			svc := NewCustomerService(repository.NewCustomerRepository(db, nil, index), jwt.NewSigner([]string{testJWTSecret}, time.Hour))
+
+			// Execute
+			token, err := svc.Login(models.LoginReq{Email: tt.email, Password: tt.password})
+
+			// Assert
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				customerID, scopes, err := svc.VerifyToken(token.Token)
+				assert.NoError(t, err)
+				assert.Equal(t, "c1", customerID)
+				assert.Equal(t, CustomerScopes, scopes)
+				assert.Equal(t, "Bearer", token.TokenType)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestCustomerService_Register_SealsContactDetails(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	keys, err := pii.ParseKeys("k1:" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 32))))
+	assert.NoError(t, err)
+	cipher := pii.NewCipher(keys)
+	index := pii.NewBlindIndex([]byte("blind-index-key-of-32-bytes-long"))
+	svc := NewCustomerService(repository.NewCustomerRepository(db, cipher, index), jwt.NewSigner([]string{testJWTSecret}, time.Hour))
+
+	var sealedEmail, sealedName string
+	mock.ExpectQuery("INSERT INTO customers").
+		WithArgs(sqlmock.AnyArg(), capture(&sealedEmail), capture(&sealedName), index.Hash("ada@example.com"), sqlmock.AnyArg(), "ada@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+
+	// Execute
+	customer, err := svc.Register(models.CustomerReq{Email: "Ada@Example.com", Name: "Ada", Password: "correct horse"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "ada@example.com", customer.Email)
+	assert.NotContains(t, sealedEmail, "example")
+	assert.NotContains(t, sealedName, "Ada")
+	email, err := cipher.Decode(sealedEmail)
+	assert.NoError(t, err)
+	assert.Equal(t, "ada@example.com", email)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCustomerRepository_BackfillEmailHashes(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	keys, err := pii.ParseKeys("k1:" + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", 32))))
+	assert.NoError(t, err)
+	index := pii.NewBlindIndex([]byte("blind-index-key-of-32-bytes-long"))
+	repo := repository.NewCustomerRepository(db, pii.NewCipher(keys), index)
+
+	// A customer stored in plaintext before emails were sealed
+	mock.ExpectQuery("SELECT id, email, name, email_hash FROM customers WHERE id > \\$1 AND email_hash IS NULL").
+		WithArgs("", 500).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name", "email_hash"}).AddRow("c1", "ada@example.com", "Ada", nil))
+	var sealedEmail string
+	mock.ExpectExec("UPDATE customers").
+		WithArgs("c1", capture(&sealedEmail), sqlmock.AnyArg(), index.Hash("ada@example.com"), "ada@example.com", "Ada", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute
+	count, err := repo.BackfillEmailHashes()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.True(t, strings.HasPrefix(sealedEmail, "enc:v1:k1:"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+const (
+	// DataQualityTask is the scheduled task running the data quality checks
+	DataQualityTask = "data-quality-checker"
+	// dataQualitySamples is how many rows of each finding are identified
+	dataQualitySamples = 10
+	// dataQualityCheckTimeout bounds one check; the coupon checks scan the
+	// whole coupons table
+	dataQualityCheckTimeout = 10 * time.Minute
+)
+
+// dataQualityChecks are the checks run, in report order, with what they
+// find
+var dataQualityChecks = []struct {
+	name, description string
+}{
+	{models.DataQualityOrphanedOrderItems, "Order items whose order or product row is missing"},
+	{models.DataQualityZeroPriceProducts, "Products on the menu priced at zero"},
+	{models.DataQualitySingleFileCoupons, "Coupon codes found in only one coupon file, which are never valid"},
+	{models.DataQualityLoadManifestGaps, "Coupon files recorded as loaded whose coupons are missing from the coupons table"},
+}
+
+// DataQualityService checks the orders, products and coupons for rows that
+// should not exist, and reports what the latest checks found. The checks
+// run as a scheduled task, since the coupon checks are too slow for a
+// request.
+type DataQualityService struct {
+	repo *repository.DataQualityRepository
+	now  func() time.Time
+}
+
+// NewDataQualityService creates a new data quality service
+func NewDataQualityService(repo *repository.DataQualityRepository) *DataQualityService {
+	return &DataQualityService{repo: repo, now: time.Now}
+}
+
+// Run runs every check and stores its finding. A failed check is stored
+// and logged, and does not stop the others.
+func (s *DataQualityService) Run(ctx context.Context) error {
+	var errs []error
+	for _, check := range dataQualityChecks {
+		if err := s.runCheck(ctx, check.name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runCheck runs one check and stores its finding or failure
+func (s *DataQualityService) runCheck(ctx context.Context, check string) error {
+	started := s.now()
+	checkCtx, cancel := context.WithTimeout(ctx, dataQualityCheckTimeout)
+	count, samples, err := s.repo.RunCheck(checkCtx, check, dataQualitySamples)
+	cancel()
+	duration := s.now().Sub(started).Milliseconds()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Data quality check failed", "check", check, "error", err)
+		if saveErr := s.repo.SaveFailure(ctx, check, err.Error(), duration, started); saveErr != nil {
+			return errors.Join(err, saveErr)
+		}
+		return err
+	}
+	if count > 0 {
+		slog.WarnContext(ctx, "Data quality check found rows", "check", check, "count", count, "samples", samples)
+	}
+	finding := models.DataQualityFinding{Check: check, Count: count, Samples: samples, DurationMs: duration}
+	if err := s.repo.SaveFinding(ctx, finding, started); err != nil {
+		return fmt.Errorf("data quality check %s: %w", check, err)
+	}
+	return nil
+}
+
+// Report returns the latest finding of every check
+func (s *DataQualityService) Report(ctx context.Context) (models.DataQualityReport, error) {
+	stored, err := s.repo.ListFindings(ctx)
+	if err != nil {
+		return models.DataQualityReport{}, err
+	}
+
+	report := models.DataQualityReport{Status: models.DataQualityClean}
+	unchecked := false
+	for _, check := range dataQualityChecks {
+		finding, ok := stored[check.name]
+		if !ok {
+			finding = models.DataQualityFinding{Check: check.name, Samples: []string{}}
+			unchecked = true
+		}
+		finding.Description = check.description
+		if finding.Count > 0 || finding.Error != "" {
+			report.Status = models.DataQualityIssues
+		}
+		report.Checks = append(report.Checks, finding)
+	}
+	if unchecked && report.Status == models.DataQualityClean {
+		report.Status = models.DataQualityUnchecked
+	}
+	return report, nil
+}
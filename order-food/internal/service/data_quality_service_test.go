@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataQualityService_Run(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewDataQualityService(repository.NewDataQualityRepository(db))
+	now := time.Date(2025, 6, 2, 3, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	sampleColumns := []string{"sample", "count"}
+	mock.ExpectQuery("FROM order_items oi").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows(sampleColumns))
+	mock.ExpectExec("INSERT INTO data_quality_findings").
+		WithArgs(models.DataQualityOrphanedOrderItems, int64(0), []byte("[]"), int64(0), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("FROM products\\s+WHERE price = 0").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows(sampleColumns).AddRow("11", 2).AddRow("12", 2))
+	mock.ExpectExec("INSERT INTO data_quality_findings").
+		WithArgs(models.DataQualityZeroPriceProducts, int64(2), []byte(`["11","12"]`), int64(0), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("FROM coupons\\s+GROUP BY upper\\(coupon\\)").WithArgs(10).
+		WillReturnError(errors.New("canceling statement due to statement timeout"))
+	mock.ExpectExec("INSERT INTO data_quality_findings \\(check_name, error, duration_ms, checked_at\\)").
+		WithArgs(models.DataQualitySingleFileCoupons, sqlmock.AnyArg(), int64(0), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("FROM coupon_file_uploads u").WithArgs(10).
+		WillReturnRows(sqlmock.NewRows(sampleColumns).AddRow("couponbase2.gz", 1))
+	mock.ExpectExec("INSERT INTO data_quality_findings").
+		WithArgs(models.DataQualityLoadManifestGaps, int64(1), []byte(`["couponbase2.gz"]`), int64(0), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Test
+	err = service.Run(context.Background())
+
+	// Assert: the failed check is reported and the others still ran
+	assert.ErrorContains(t, err, "statement timeout")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDataQualityService_Report(t *testing.T) {
+	checkedAt := time.Date(2025, 6, 2, 3, 0, 0, 0, time.UTC)
+	findingColumns := []string{"check_name", "count", "samples", "error", "duration_ms", "checked_at"}
+	allClean := func() *sqlmock.Rows {
+		rows := sqlmock.NewRows(findingColumns)
+		for _, check := range dataQualityChecks {
+			rows.AddRow(check.name, 0, []byte("[]"), "", 5, checkedAt)
+		}
+		return rows
+	}
+
+	tests := []struct {
+		name       string
+		rows       *sqlmock.Rows
+		wantStatus string
+	}{
+		{name: "clean", rows: allClean(), wantStatus: models.DataQualityClean},
+		{
+			name:       "never run",
+			rows:       sqlmock.NewRows(findingColumns),
+			wantStatus: models.DataQualityUnchecked,
+		},
+		{
+			name: "rows found",
+			rows: sqlmock.NewRows(findingColumns).
+				AddRow(models.DataQualityZeroPriceProducts, 2, []byte(`["11","12"]`), "", 5, checkedAt),
+			wantStatus: models.DataQualityIssues,
+		},
+		{
+			name: "check failed",
+			rows: sqlmock.NewRows(findingColumns).
+				AddRow(models.DataQualitySingleFileCoupons, 0, []byte("[]"), "statement timeout", 5, checkedAt),
+			wantStatus: models.DataQualityIssues,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			service := NewDataQualityService(repository.NewDataQualityRepository(db))
+			mock.ExpectQuery("FROM data_quality_findings").WillReturnRows(tt.rows)
+
+			// Test
+			report, err := service.Report(context.Background())
+
+			// Assert: every check is listed in order, run or not
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, report.Status)
+			if assert.Len(t, report.Checks, len(dataQualityChecks)) {
+				for i, check := range dataQualityChecks {
+					assert.Equal(t, check.name, report.Checks[i].Check)
+					assert.Equal(t, check.description, report.Checks[i].Description)
+					assert.NotNil(t, report.Checks[i].Samples)
+				}
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
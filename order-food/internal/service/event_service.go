@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/events"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/eventschema"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultEventQueueSize is how many events wait to be sent before new
+	// ones are dropped
+	DefaultEventQueueSize = 10_000
+	// eventBatchSize is the number of queued events sent together
+	eventBatchSize = 100
+	// eventSendTimeout bounds sending one batch
+	eventSendTimeout = 10 * time.Second
+	// eventRetryMax is the longest wait between attempts to send a batch
+	// the broker refused
+	eventRetryMax = 30 * time.Second
+	// eventFlushTimeout bounds sending the events still queued at shutdown
+	eventFlushTimeout = 5 * time.Second
+)
+
+// ErrEventQueueFull is returned when an event is published while the
+// broker is too far behind to queue it
+var ErrEventQueueFull = errors.New("event queue is full")
+
+// EventPublisher publishes domain events for downstream systems
+type EventPublisher interface {
+	Publish(ctx context.Context, event models.Event) error
+}
+
+// NewEvent returns an event of the given type about key, occurring now
+func NewEvent(eventType, key string, data any) models.Event {
+	return models.Event{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		Key:        key,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// publishEvent hands an event to publisher when there is one, on behalf
+// of the request in ctx. The change the event describes is already
+// committed, so failing to publish it is only logged.
+func publishEvent(ctx context.Context, publisher EventPublisher, eventType, key string, data any) {
+	if publisher == nil {
+		return
+	}
+	if err := publisher.Publish(ctx, NewEvent(eventType, key, data)); err != nil {
+		slog.Error("Failed to publish event", "type", eventType, "key", key, "error", err)
+	}
+}
+
+// EventService sends published events to an event broker in the
+// background, so requests never wait for the broker. Events about orders
+// go to the <prefix>.orders topic and events about products to
+// <prefix>.products, in the order they were published. Batches the broker
+// refuses are retried until it accepts them; meanwhile new events queue up
+// to the queue size and are dropped beyond it. Queued events are lost
+// when the process stops without reaching the broker.
+//
+// Publishing an event records a create span under the request's span, and
+// every batch a send span linked to the create spans of its events. Each
+// message carries the trace context of its create span in its headers, so
+// consumers can continue the trace of the request that caused it.
+type EventService struct {
+	broker events.Broker
+	prefix string
+	queue  chan queuedEvent
+	tracer trace.Tracer
+	// validator checks events before they are queued when non-nil
+	validator EventValidator
+
+	published atomic.Int64
+	failures  atomic.Int64
+	dropped   atomic.Int64
+}
+
+// queuedEvent is an event waiting to be sent with the context of its
+// create span
+type queuedEvent struct {
+	models.Event
+	span trace.SpanContext
+}
+
+// NewEventService creates an event service sending to broker on topics
+// named after prefix, with room for queueSize events
+func NewEventService(broker events.Broker, prefix string, queueSize int) *EventService {
+	return &EventService{
+		broker: broker,
+		prefix: prefix,
+		queue:  make(chan queuedEvent, queueSize),
+		tracer: otel.Tracer(tracing.TracerName),
+	}
+}
+
+// SetTracerProvider records the spans of published events with provider
+// instead of the global one
+func (s *EventService) SetTracerProvider(provider trace.TracerProvider) {
+	s.tracer = provider.Tracer(tracing.TracerName)
+}
+
+// SetValidator makes Publish reject events whose data the registered
+// schema does not accept
+func (s *EventService) SetValidator(validator EventValidator) {
+	s.validator = validator
+}
+
+// Publish queues event for sending. ErrEventQueueFull is returned when
+// there is no room for it.
+func (s *EventService) Publish(ctx context.Context, event models.Event) error {
+	_, span := tracing.StartCreate(ctx, s.tracer, s.topic(event.Type), event.ID, event.Type)
+	defer span.End()
+
+	if s.validator != nil {
+		if err := s.validator.Validate(eventschema.EventSubjects[event.Type], event.Data); err != nil {
+			tracing.Fail(span, err)
+			return err
+		}
+	}
+	select {
+	case s.queue <- queuedEvent{Event: event, span: span.SpanContext()}:
+		return nil
+	default:
+		s.dropped.Add(1)
+		tracing.Fail(span, ErrEventQueueFull)
+		return ErrEventQueueFull
+	}
+}
+
+// Run sends queued events until ctx is cancelled, then makes a last
+// attempt to send the events still queued
+func (s *EventService) Run(ctx context.Context) {
+	defer s.flush()
+
+	backoff := time.Second
+	var batch []queuedEvent
+	for {
+		if len(batch) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-s.queue:
+				batch = append(batch, event)
+			}
+		}
+		batch = s.fill(batch)
+
+		if err := s.send(ctx, batch); err != nil {
+			if ctx.Err() != nil {
+				// Leave the batch for flush
+				s.requeue(batch)
+				return
+			}
+			s.failures.Add(1)
+			slog.Error("Failed to send events, retrying", "events", len(batch), "retryIn", backoff, "error", err)
+			select {
+			case <-ctx.Done():
+				s.requeue(batch)
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, eventRetryMax)
+			continue
+		}
+		backoff = time.Second
+		batch = batch[:0]
+	}
+}
+
+// fill adds queued events to batch until it holds eventBatchSize
+func (s *EventService) fill(batch []queuedEvent) []queuedEvent {
+	for len(batch) < eventBatchSize {
+		select {
+		case event := <-s.queue:
+			batch = append(batch, event)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// requeue puts the events of an unsent batch back in the queue for flush,
+// dropping those that no longer fit
+func (s *EventService) requeue(batch []queuedEvent) {
+	for _, event := range batch {
+		select {
+		case s.queue <- event:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// flush sends the events still queued, dropping them when the broker does
+// not accept them in time
+func (s *EventService) flush() {
+	ctx, cancel := context.WithTimeout(context.Background(), eventFlushTimeout)
+	defer cancel()
+	for {
+		batch := s.fill(nil)
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.send(ctx, batch); err != nil {
+			lost := int64(len(batch) + len(s.queue))
+			s.dropped.Add(lost)
+			slog.Error("Dropped events at shutdown", "events", lost, "error", err)
+			return
+		}
+	}
+}
+
+// send encodes batch and sends it to the broker
+func (s *EventService) send(ctx context.Context, batch []queuedEvent) error {
+	creates := make([]trace.SpanContext, len(batch))
+	oldest := time.Now()
+	for i, event := range batch {
+		creates[i] = event.span
+		if event.OccurredAt.Before(oldest) {
+			oldest = event.OccurredAt
+		}
+	}
+	ctx, span := tracing.StartSend(ctx, s.tracer, creates)
+	defer span.End()
+	span.SetAttributes(tracing.AttrEventQueueSeconds.Float64(time.Since(oldest).Seconds()))
+
+	messages := make([]events.Message, len(batch))
+	for i, event := range batch {
+		value, err := json.Marshal(event.Event)
+		if err != nil {
+			err = fmt.Errorf("error encoding %s event: %w", event.Type, err)
+			tracing.Fail(span, err)
+			return err
+		}
+		messages[i] = events.Message{
+			Topic: s.topic(event.Type),
+			Key:   event.Key,
+			Value: value,
+			Headers: map[string]string{
+				"event-id":   event.ID,
+				"event-type": event.Type,
+			},
+		}
+		tracing.InjectMessage(event.span, messages[i].Headers)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, eventSendTimeout)
+	defer cancel()
+	if err := s.broker.Send(ctx, messages...); err != nil {
+		tracing.Fail(span, err)
+		return err
+	}
+	s.published.Add(int64(len(batch)))
+	return nil
+}
+
+// topic returns the topic events of eventType are sent to
+func (s *EventService) topic(eventType string) string {
+	if eventType == models.EventTypeProductUpdated {
+		return s.prefix + ".products"
+	}
+	return s.prefix + ".orders"
+}
+
+// Descriptors of the event service's metrics
+var (
+	eventsPublishedDesc    = prometheus.NewDesc("events_published_total", "Domain events the event broker accepted.", nil, nil)
+	eventsSendFailuresDesc = prometheus.NewDesc("events_send_failures_total", "Batches of domain events the event broker refused.", nil, nil)
+	eventsDroppedDesc      = prometheus.NewDesc("events_dropped_total", "Domain events dropped without reaching the event broker.", nil, nil)
+	eventsQueueDepthDesc   = prometheus.NewDesc("events_queue_depth", "Domain events waiting to be sent to the event broker.", nil, nil)
+)
+
+// Describe sends the descriptors of the service's metrics
+func (s *EventService) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{eventsPublishedDesc, eventsSendFailuresDesc, eventsDroppedDesc, eventsQueueDepthDesc} {
+		ch <- desc
+	}
+}
+
+// Collect sends the service's counters and queue depth
+func (s *EventService) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(eventsPublishedDesc, prometheus.CounterValue, float64(s.published.Load()))
+	ch <- prometheus.MustNewConstMetric(eventsSendFailuresDesc, prometheus.CounterValue, float64(s.failures.Load()))
+	ch <- prometheus.MustNewConstMetric(eventsDroppedDesc, prometheus.CounterValue, float64(s.dropped.Load()))
+	ch <- prometheus.MustNewConstMetric(eventsQueueDepthDesc, prometheus.GaugeValue, float64(len(s.queue)))
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/events"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/tracing"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// fakeBroker records the messages it is sent, refusing them while err is
+// set
+type fakeBroker struct {
+	mu       sync.Mutex
+	err      error
+	messages []events.Message
+}
+
+func (b *fakeBroker) Send(ctx context.Context, messages ...events.Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return b.err
+	}
+	b.messages = append(b.messages, messages...)
+	return nil
+}
+
+func (b *fakeBroker) Close() error { return nil }
+
+func (b *fakeBroker) sent() []events.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]events.Message(nil), b.messages...)
+}
+
+// recordingPublisher records the events it is given
+type recordingPublisher struct {
+	events []models.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event models.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestEventService_Run(t *testing.T) {
+	// Setup
+	broker := &fakeBroker{}
+	service := NewEventService(broker, "order-food", 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Run(ctx)
+	}()
+
+	// Execute
+	assert.NoError(t, service.Publish(ctx, NewEvent(models.EventTypeOrderCreated, "order-1", models.OrderCreated{ID: "order-1"})))
+	assert.NoError(t, service.Publish(ctx, NewEvent(models.EventTypeProductUpdated, "1", models.ProductUpdated{ID: "1", Change: models.ProductChangeDeleted})))
+
+	// Assert
+	assert.Eventually(t, func() bool { return len(broker.sent()) == 2 }, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	sent := broker.sent()
+	assert.Equal(t, "order-food.orders", sent[0].Topic)
+	assert.Equal(t, "order-1", sent[0].Key)
+	assert.Equal(t, models.EventTypeOrderCreated, sent[0].Headers["event-type"])
+	assert.Equal(t, "order-food.products", sent[1].Topic)
+
+	var event struct {
+		ID   string                `json:"id"`
+		Type string                `json:"type"`
+		Data models.ProductUpdated `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(sent[1].Value, &event))
+	assert.Equal(t, sent[1].Headers["event-id"], event.ID)
+	assert.Equal(t, models.EventTypeProductUpdated, event.Type)
+	assert.Equal(t, models.ProductChangeDeleted, event.Data.Change)
+
+	assert.NoError(t, testutil.CollectAndCompare(service, strings.NewReader(`
+# HELP events_published_total Domain events the event broker accepted.
+# TYPE events_published_total counter
+events_published_total 2
+`), "events_published_total"))
+}
+
+func TestEventService_Run_TracesEvents(t *testing.T) {
+	// Setup
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previous)
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	broker := &fakeBroker{}
+	service := NewEventService(broker, "order-food", 10)
+	service.SetTracerProvider(provider)
+	requestCtx, request := provider.Tracer("test").Start(context.Background(), "POST /api/v1/orders")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Run(ctx)
+	}()
+
+	// Execute
+	assert.NoError(t, service.Publish(requestCtx, NewEvent(models.EventTypeOrderCreated, "order-1", models.OrderCreated{ID: "order-1"})))
+	request.End()
+
+	// Assert
+	assert.Eventually(t, func() bool { return len(broker.sent()) == 1 }, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	var create, send sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "create order-food.orders":
+			create = span
+		case tracing.MessagingSend:
+			send = span
+		}
+	}
+	if !assert.NotNil(t, create) || !assert.NotNil(t, send) {
+		return
+	}
+	assert.Equal(t, request.SpanContext().SpanID(), create.Parent().SpanID())
+	if assert.Len(t, send.Links(), 1) {
+		assert.Equal(t, create.SpanContext().SpanID(), send.Links()[0].SpanContext.SpanID())
+	}
+	assert.Contains(t, broker.sent()[0].Headers["traceparent"], create.SpanContext().SpanID().String())
+	assert.Contains(t, broker.sent()[0].Headers["traceparent"], request.SpanContext().TraceID().String())
+}
+
+func TestEventService_Run_FlushesAtShutdown(t *testing.T) {
+	// Setup: the broker refuses events until shutdown
+	broker := &fakeBroker{err: errors.New("broker unavailable")}
+	service := NewEventService(broker, "order-food", 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Run(ctx)
+	}()
+	assert.NoError(t, service.Publish(ctx, NewEvent(models.EventTypeOrderStatusChanged, "order-1", models.OrderStatusChanged{ID: "order-1"})))
+	assert.Eventually(t, func() bool { return service.failures.Load() > 0 }, time.Second, 10*time.Millisecond)
+
+	// Execute
+	broker.mu.Lock()
+	broker.err = nil
+	broker.mu.Unlock()
+	cancel()
+	<-done
+
+	// Assert
+	assert.Len(t, broker.sent(), 1)
+}
+
+func TestEventService_Publish_QueueFull(t *testing.T) {
+	service := NewEventService(&fakeBroker{}, "order-food", 1)
+
+	assert.NoError(t, service.Publish(context.Background(), NewEvent(models.EventTypeOrderCreated, "order-1", nil)))
+	assert.ErrorIs(t, service.Publish(context.Background(), NewEvent(models.EventTypeOrderCreated, "order-2", nil)), ErrEventQueueFull)
+	assert.Equal(t, int64(1), service.dropped.Load())
+}
+
+func TestEventService_Publish_RejectedBySchema(t *testing.T) {
+	service := NewEventService(&fakeBroker{}, "order-food", 10)
+	service.SetValidator(rejectingValidator{})
+
+	err := service.Publish(context.Background(), NewEvent(models.EventTypeOrderCreated, "order-1", models.OrderCreated{ID: "order-1"}))
+
+	assert.Error(t, err)
+	assert.Empty(t, service.queue)
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+// DefaultIdempotencyKeyTTL is how long a used Idempotency-Key is remembered
+const DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+// idempotencyAbandonAfter is how long a request may hold its key before a
+// retry assumes the replica serving it died. It must exceed the longest
+// request, which REQUEST_TIMEOUT bounds in production.
+const idempotencyAbandonAfter = 5 * time.Minute
+
+// IdempotencyService remembers the responses sent for Idempotency-Key
+// headers so clients retrying over flaky networks do not repeat a request
+type IdempotencyService struct {
+	repo *repository.IdempotencyRepository
+	ttl  time.Duration
+	now  func() time.Time
+}
+
+// NewIdempotencyService creates a new idempotency service remembering keys for ttl
+func NewIdempotencyService(repo *repository.IdempotencyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{repo: repo, ttl: ttl, now: time.Now}
+}
+
+// Claim records key for principal and reports whether the request should
+// run. When it should not, the record of the first request is returned.
+func (s *IdempotencyService) Claim(principal, key, requestHash string) (models.IdempotencyRecord, bool, error) {
+	return s.repo.Claim(principal, key, requestHash, idempotencyAbandonAfter)
+}
+
+// Complete stores the response to replay for key
+func (s *IdempotencyService) Complete(principal, key string, statusCode int, body []byte) error {
+	return s.repo.Complete(principal, key, statusCode, body)
+}
+
+// Release forgets key so a retry runs the request again
+func (s *IdempotencyService) Release(principal, key string) error {
+	return s.repo.Release(principal, key)
+}
+
+// PruneExpired deletes keys older than the TTL. It is run by the scheduler.
+func (s *IdempotencyService) PruneExpired(ctx context.Context) error {
+	count, err := s.repo.DeleteBefore(s.now().Add(-s.ttl))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		slog.Info("Pruned expired idempotency keys", "keys", count)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyService_Claim(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewIdempotencyService(repository.NewIdempotencyRepository(db), DefaultIdempotencyKeyTTL)
+
+	mock.ExpectQuery("INSERT INTO idempotency_keys").
+		WithArgs("apikey", "key-1", "hash", idempotencyAbandonAfter.Seconds()).
+		WillReturnRows(sqlmock.NewRows([]string{"request_hash"}).AddRow("hash"))
+
+	// Test
+	record, claimed, err := service.Claim("apikey", "key-1", "hash")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, "hash", record.RequestHash)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIdempotencyService_Claim_ReturnsStoredResponse(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewIdempotencyService(repository.NewIdempotencyRepository(db), DefaultIdempotencyKeyTTL)
+
+	mock.ExpectQuery("INSERT INTO idempotency_keys").
+		WithArgs("apikey", "key-1", "hash", idempotencyAbandonAfter.Seconds()).
+		WillReturnRows(sqlmock.NewRows([]string{"request_hash"}))
+	mock.ExpectQuery("SELECT request_hash, status_code, response_body FROM idempotency_keys").
+		WithArgs("apikey", "key-1").
+		WillReturnRows(sqlmock.NewRows([]string{"request_hash", "status_code", "response_body"}).
+			AddRow("hash", http.StatusCreated, []byte(`{"data":{"id":"1"}}`)))
+
+	// Test
+	record, claimed, err := service.Claim("apikey", "key-1", "hash")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Equal(t, http.StatusCreated, record.StatusCode)
+	assert.JSONEq(t, `{"data":{"id":"1"}}`, string(record.Body))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
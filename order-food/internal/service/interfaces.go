@@ -1,22 +1,160 @@
 package service
 
-import "github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// CategoryServiceInterface defines the interface for category operations
+type CategoryServiceInterface interface {
+	ListCategories(ctx context.Context) ([]models.Category, error)
+	GetCategory(ctx context.Context, id string) (models.Category, error)
+	CreateCategory(ctx context.Context, req models.CategoryReq, actor string) (models.Category, error)
+	UpdateCategory(ctx context.Context, id string, req models.CategoryReq, actor string) (models.Category, error)
+	DeleteCategory(ctx context.Context, id, actor string) error
+}
 
 // ProductServiceInterface defines the interface for product operations
 type ProductServiceInterface interface {
 	ListProducts() []models.Product
-	ListProductsPaginated(limit, offset int) ([]models.Product, int, error)
+	ListProductsPaginated(limit, offset int, sort []models.SortField, filter models.ProductFilter) ([]models.Product, int, error)
+	ListProductsAfter(after string, limit int, sort []models.SortField, filter models.ProductFilter) ([]models.Product, string, error)
+	StreamProducts(ctx context.Context, sort []models.SortField, filter models.ProductFilter, fn func(models.Product) error) error
+	SearchProducts(query string, limit, offset int) ([]models.Product, int, error)
 	GetProduct(id string) (models.Product, error)
+	GetProductByBarcode(barcode string) (models.Product, error)
+	CreateProduct(ctx context.Context, req models.ProductReq, actor string) (models.Product, error)
+	UpdateProduct(ctx context.Context, id string, req models.ProductReq, actor string) (models.Product, error)
+	DeleteProduct(ctx context.Context, id, actor string) error
+	SetOptionGroups(ctx context.Context, id string, req models.OptionGroupsReq, actor string) (models.Product, error)
 }
 
 // OrderServiceInterface defines the interface for order operations
 type OrderServiceInterface interface {
-	CreateOrder(req models.OrderReq) (models.Order, error)
+	CreateOrder(ctx context.Context, req models.OrderReq) (models.Order, error)
+	ImportPOSOrder(ctx context.Context, ticketNumber string, req models.OrderReq) (models.Order, bool, error)
 	GetOrder(id string) (models.Order, error)
-	ListOrdersPaginated(limit, offset int) ([]models.Order, int, error)
+	ListOrderItems(id string, limit, offset int) ([]models.OrderLine, int, error)
+	UpdateOrderStatus(ctx context.Context, id string, status models.OrderStatus) (models.Order, error)
+	ListOrdersPaginated(ctx context.Context, limit, offset int, sort []models.SortField, filter models.OrderFilter) ([]models.Order, int, error)
+	ListOrdersAfter(ctx context.Context, after string, limit int, sort []models.SortField, filter models.OrderFilter) ([]models.Order, string, error)
+	StreamOrders(ctx context.Context, sort []models.SortField, filter models.OrderFilter, fn func(models.Order) error) error
 }
 
 // PromoCodeServiceInterface defines the interface for promo code operations
 type PromoCodeServiceInterface interface {
-	ValidatePromoCode(code string) (bool, error)
+	ValidatePromoCode(code string) (models.PromoCode, bool, error)
+	Policy() models.PromoCodePolicy
+}
+
+// PromoCodeAdminServiceInterface defines the interface for managing promo code discounts and limits
+type PromoCodeAdminServiceInterface interface {
+	SetDiscount(code string, discount models.Discount, actor string) (models.PromoCode, error)
+	RemoveDiscount(code, actor string) error
+	GetLimits(code string) (models.PromoCode, error)
+	SetLimits(code string, limits models.PromoCodeLimits, actor string) (models.PromoCode, error)
+}
+
+// PartnerServiceInterface defines the interface for partner onboarding operations
+type PartnerServiceInterface interface {
+	RegisterPartner(req models.PartnerReq) (models.PartnerRegistration, error)
+	ListPartners(status string, limit, offset int) ([]models.Partner, int, error)
+	SetPartnerStatus(id, status string) (models.Partner, error)
+	RotatePartnerKey(id string) (models.IssuedAPIKey, error)
+}
+
+// CustomerServiceInterface defines the interface for customer account operations
+type CustomerServiceInterface interface {
+	Register(req models.CustomerReq) (models.Customer, error)
+	Login(req models.LoginReq) (models.AccessToken, error)
+	GetCustomer(id string) (models.Customer, error)
+}
+
+// CouponAnalyticsServiceInterface defines the interface for promo code usage reporting
+type CouponAnalyticsServiceInterface interface {
+	Analytics(ctx context.Context, from, to time.Time, limit int) (models.CouponAnalytics, error)
+}
+
+// ReservationServiceInterface defines the interface for stock reservation operations
+type ReservationServiceInterface interface {
+	Reserve(req models.ReservationReq) (models.Reservation, error)
+	GetReservation(ctx context.Context, id string) (models.Reservation, error)
+	Release(ctx context.Context, id string) error
+}
+
+// CartServiceInterface defines the interface for shopping cart operations
+type CartServiceInterface interface {
+	CreateCart(ctx context.Context, req models.CartReq, accountID string) (models.Cart, error)
+	GetCart(ctx context.Context, id, accountID string) (models.Cart, error)
+	AddItem(ctx context.Context, id, accountID string, req models.CartItemReq) (models.Cart, error)
+	UpdateItem(ctx context.Context, id, accountID, productID string, quantity int) (models.Cart, error)
+	RemoveItem(ctx context.Context, id, accountID, productID string) (models.Cart, error)
+	SetCouponCode(ctx context.Context, id, accountID, code string) (models.Cart, error)
+	Checkout(ctx context.Context, id, accountID string, req models.CheckoutReq) (models.Order, error)
+}
+
+// StockServiceInterface defines the interface for stock management operations
+type StockServiceInterface interface {
+	GetStock(ctx context.Context, productID string) (models.ProductStock, error)
+	SetStock(ctx context.Context, productID string, req models.StockReq, actor string) (models.ProductStock, error)
+	AdjustStock(ctx context.Context, productID string, req models.StockAdjustmentReq, actor string) (models.ProductStock, error)
+	CheckAvailability(ctx context.Context, items []models.AvailabilityItem) ([]models.ItemAvailability, error)
+}
+
+// PricingServiceInterface defines the interface for bulk price operations
+type PricingServiceInterface interface {
+	BulkUpdatePrices(ctx context.Context, req models.BulkPriceReq, actor string) (models.BulkPriceResult, error)
+}
+
+// CouponFileServiceInterface defines the interface for coupon file upload operations
+type CouponFileServiceInterface interface {
+	Upload(name string, content io.Reader, actor, operationID string) (models.CouponFileUpload, error)
+	GetUpload(id string) (models.CouponFileUpload, error)
+	ListUploads(limit, offset int) ([]models.CouponFileUpload, int, error)
+}
+
+// CampaignServiceInterface defines the interface for promo code campaign operations
+type CampaignServiceInterface interface {
+	CreateCampaign(req models.CampaignReq, actor string) (models.Campaign, error)
+	GetCampaign(id string) (models.Campaign, error)
+	ExportCodes(id string) (models.Campaign, []string, error)
+}
+
+// OperationServiceInterface defines the interface for following pipeline operations
+type OperationServiceInterface interface {
+	ListRuns(status string, limit, offset int) ([]models.PipelineRun, int, error)
+	GetOperation(id string) (models.Operation, error)
+}
+
+// OrderVolumeServiceInterface defines the interface for order volume anomaly checks
+type OrderVolumeServiceInterface interface {
+	Current(ctx context.Context) (models.OrderVolume, error)
+	Check(ctx context.Context) (models.OrderVolume, error)
+}
+
+// DataQualityServiceInterface defines the interface for data quality reports
+type DataQualityServiceInterface interface {
+	Report(ctx context.Context) (models.DataQualityReport, error)
+}
+
+// MatviewServiceInterface defines the interface for reporting on a
+// materialized view kept fresh in the background
+type MatviewServiceInterface interface {
+	View() string
+	Status(ctx context.Context) (models.MatviewStatus, error)
+}
+
+// ScheduledTaskServiceInterface defines the interface for listing and triggering scheduled tasks
+type ScheduledTaskServiceInterface interface {
+	Tasks(ctx context.Context) ([]models.ScheduledTask, error)
+	Trigger(name string) error
+}
+
+// MaintenanceServiceInterface defines the interface for maintenance jobs
+type MaintenanceServiceInterface interface {
+	StartReindex(actor, operationID string) (models.MaintenanceJob, error)
+	GetJob(id string) (models.MaintenanceJob, error)
 }
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/eventschema"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+const (
+	// invalidationBatchSize is the number of outbox events read per query
+	invalidationBatchSize = 500
+	// invalidationRetention is how long events stay in the outbox; it must
+	// comfortably exceed the poll interval of every replica
+	invalidationRetention = 24 * time.Hour
+	// invalidationPruneInterval is how often expired events are deleted
+	invalidationPruneInterval = time.Hour
+)
+
+// InvalidationHandler drops cached entries for key
+type InvalidationHandler func(key string)
+
+// EventValidator checks an event payload against the schema of its subject
+// before it is published
+type EventValidator interface {
+	Validate(subject string, payload any) error
+}
+
+// InvalidationService relays cache invalidation events from the database
+// outbox to local caches, so a change made through any replica or by the
+// load job reaches every replica without waiting for TTL expiry
+type InvalidationService struct {
+	repo *repository.InvalidationRepository
+	now  func() time.Time
+	// wake asks Run to poll before the next tick
+	wake chan struct{}
+	// events checks published events when non-nil
+	events EventValidator
+
+	mu        sync.Mutex
+	handlers  map[string][]InvalidationHandler
+	lastID    int64
+	started   bool
+	lastPoll  time.Time
+	lastPrune time.Time
+}
+
+// NewInvalidationService creates a new invalidation service
+func NewInvalidationService(repo *repository.InvalidationRepository) *InvalidationService {
+	return &InvalidationService{
+		repo:     repo,
+		now:      time.Now,
+		wake:     make(chan struct{}, 1),
+		handlers: make(map[string][]InvalidationHandler),
+	}
+}
+
+// Subscribe registers handler for events on topic
+func (s *InvalidationService) Subscribe(topic string, handler InvalidationHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[topic] = append(s.handlers[topic], handler)
+}
+
+// SetValidator makes Publish reject events the registered schema does not
+// accept
+func (s *InvalidationService) SetValidator(events EventValidator) {
+	s.events = events
+}
+
+// Publish records an invalidation event for every replica, including this one
+func (s *InvalidationService) Publish(topic, key string) error {
+	if s.events != nil {
+		if err := s.events.Validate(eventschema.SubjectCacheInvalidation, eventschema.CacheInvalidation{Topic: topic, Key: key}); err != nil {
+			return err
+		}
+	}
+	return s.repo.Publish(topic, key)
+}
+
+// Poll delivers events published since the previous poll to the subscribed
+// handlers and returns how many were delivered. The first poll only records
+// the current position, since caches start out empty.
+func (s *InvalidationService) Poll() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.started {
+		id, err := s.repo.LatestID()
+		if err != nil {
+			return 0, err
+		}
+		s.lastID = id
+		s.started = true
+		s.lastPoll = s.now()
+		return 0, nil
+	}
+
+	delivered := 0
+	for {
+		events, err := s.repo.ListAfter(s.lastID, invalidationBatchSize)
+		if err != nil {
+			return delivered, err
+		}
+		for _, event := range events {
+			for _, handler := range s.handlers[event.Topic] {
+				handler(event.Key)
+			}
+			s.lastID = event.ID
+			delivered++
+		}
+		if len(events) < invalidationBatchSize {
+			s.lastPoll = s.now()
+			return delivered, nil
+		}
+	}
+}
+
+// LastPoll returns when the outbox was last read successfully, or the zero
+// time before the first successful poll
+func (s *InvalidationService) LastPoll() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastPoll
+}
+
+// prune deletes expired events at most once per invalidationPruneInterval
+func (s *InvalidationService) prune() {
+	now := s.now()
+	if now.Sub(s.lastPrune) < invalidationPruneInterval {
+		return
+	}
+	s.lastPrune = now
+
+	if _, err := s.repo.DeleteBefore(now.Add(-invalidationRetention)); err != nil {
+		slog.Error("Failed to prune cache invalidations", "error", err)
+	}
+}
+
+// Notify makes Run poll the outbox straight away, as when the database
+// reports new events. Calls made while a poll is pending are merged.
+func (s *InvalidationService) Notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run polls the outbox every interval, and whenever Notify is called,
+// until ctx is cancelled
+func (s *InvalidationService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Poll(); err != nil {
+			slog.Error("Failed to poll cache invalidations", "error", err)
+		}
+		s.prune()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/eventschema"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidationService_Poll(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewInvalidationService(repository.NewInvalidationRepository(db))
+	var products, other []string
+	service.Subscribe(models.InvalidationTopicProducts, func(key string) { products = append(products, key) })
+	service.Subscribe("coupons", func(key string) { other = append(other, key) })
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(id\\), 0\\) FROM cache_invalidations").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(41))
+	mock.ExpectQuery("SELECT id, topic, cache_key, created_at").
+		WithArgs(int64(41), invalidationBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "cache_key", "created_at"}).
+			AddRow(42, "products", "1", now).
+			AddRow(43, "products", "*", now))
+	mock.ExpectQuery("SELECT id, topic, cache_key, created_at").
+		WithArgs(int64(43), invalidationBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "cache_key", "created_at"}))
+
+	// Test: the first poll only records the current position
+	delivered, err := service.Poll()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+
+	delivered, err = service.Poll()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+
+	delivered, err = service.Poll()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Equal(t, []string{"1", "*"}, products)
+	assert.Empty(t, other)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvalidationService_Publish(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewInvalidationService(repository.NewInvalidationRepository(db))
+	mock.ExpectExec("INSERT INTO cache_invalidations").
+		WithArgs("products", "*").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// Test
+	err = service.Publish(models.InvalidationTopicProducts, "*")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvalidationService_Notify(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewInvalidationService(repository.NewInvalidationRepository(db))
+	delivered := make(chan string, 1)
+	service.Subscribe(models.InvalidationTopicProducts, func(key string) { delivered <- key })
+
+	mock.ExpectQuery("SELECT COALESCE\\(MAX\\(id\\), 0\\) FROM cache_invalidations").
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(41))
+	mock.ExpectExec("DELETE FROM cache_invalidations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT id, topic, cache_key, created_at").
+		WithArgs(int64(41), invalidationBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "cache_key", "created_at"}).
+			AddRow(42, "products", "1", time.Now()))
+
+	// Test: with an hour between ticks only a notification triggers the
+	// second poll
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.Run(ctx, time.Hour)
+	}()
+	service.Notify()
+
+	// Assert
+	select {
+	case key := <-delivered:
+		assert.Equal(t, "1", key)
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification did not trigger a poll")
+	}
+	cancel()
+	<-done
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvalidationService_Publish_RejectedBySchema(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewInvalidationService(repository.NewInvalidationRepository(db))
+	service.SetValidator(rejectingValidator{})
+
+	// Test
+	err = service.Publish(models.InvalidationTopicProducts, "*")
+
+	// Assert: nothing is written to the outbox
+	assert.ErrorIs(t, err, eventschema.ErrInvalidPayload)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// rejectingValidator rejects every payload
+type rejectingValidator struct{}
+
+func (rejectingValidator) Validate(subject string, payload any) error {
+	return eventschema.ErrInvalidPayload
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+var (
+	// ErrMaintenanceJobRunning is returned when a job of the same kind is
+	// already running somewhere in the cluster
+	ErrMaintenanceJobRunning = repository.ErrMaintenanceJobRunning
+	// ErrMaintenanceJobNotFound is returned when a maintenance job does not exist
+	ErrMaintenanceJobNotFound = repository.ErrMaintenanceJobNotFound
+	// ErrMaintenanceUnavailable is returned when this replica is not
+	// running maintenance jobs, as while it shuts down
+	ErrMaintenanceUnavailable = errors.New("maintenance jobs are not being run")
+)
+
+// MaintenanceStep is one step of a maintenance job
+type MaintenanceStep struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// MaintenanceService runs maintenance jobs, such as rebuilding the product
+// search index and caches after a large load, in the background on the
+// replica that accepted them. Progress is recorded after every step so
+// any replica can report it.
+type MaintenanceService struct {
+	repo       *repository.MaintenanceRepository
+	instanceID string
+	reindex    []MaintenanceStep
+
+	mu      sync.Mutex
+	jobs    chan models.MaintenanceJob
+	running bool
+}
+
+// NewMaintenanceService creates a maintenance service recording jobs as
+// run by instanceID. A reindex job runs the reindex steps in order.
+func NewMaintenanceService(repo *repository.MaintenanceRepository, instanceID string, reindex ...MaintenanceStep) *MaintenanceService {
+	return &MaintenanceService{
+		repo:       repo,
+		instanceID: instanceID,
+		reindex:    reindex,
+		jobs:       make(chan models.MaintenanceJob, 1),
+	}
+}
+
+// StartReindex starts a reindex job on behalf of actor in operationID and
+// returns it while it runs
+func (s *MaintenanceService) StartReindex(actor, operationID string) (models.MaintenanceJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		return models.MaintenanceJob{}, ErrMaintenanceUnavailable
+	}
+
+	job := models.MaintenanceJob{
+		ID:          uuid.New().String(),
+		Kind:        models.MaintenanceJobReindex,
+		RequestedBy: actor,
+		InstanceID:  s.instanceID,
+		OperationID: operationID,
+		Steps:       make([]models.MaintenanceStep, len(s.reindex)),
+	}
+	for i, step := range s.reindex {
+		job.Steps[i] = models.MaintenanceStep{Name: step.Name, Status: models.MaintenanceStepPending}
+	}
+	if err := s.repo.Create(&job); err != nil {
+		return models.MaintenanceJob{}, err
+	}
+
+	// Only one job runs at a time, so the queue is empty unless the
+	// previous job is still being picked up
+	select {
+	case s.jobs <- job:
+	default:
+		s.finish(context.Background(), &job, "this replica is busy with another job")
+		return models.MaintenanceJob{}, ErrMaintenanceJobRunning
+	}
+	job.Progress = maintenanceProgress(job.Steps)
+	return job, nil
+}
+
+// GetJob returns a maintenance job by ID
+func (s *MaintenanceService) GetJob(id string) (models.MaintenanceJob, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return models.MaintenanceJob{}, ErrMaintenanceJobNotFound
+	}
+	job, err := s.repo.GetByID(id)
+	if err != nil {
+		return models.MaintenanceJob{}, err
+	}
+	job.Progress = maintenanceProgress(job.Steps)
+	return job, nil
+}
+
+// Run runs the jobs started on this replica until ctx is cancelled. A job
+// interrupted by the cancellation is recorded as failed.
+func (s *MaintenanceService) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.running = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-s.jobs:
+			s.run(ctx, job)
+		}
+	}
+}
+
+// run runs the steps of job in order, stopping at the first that fails
+func (s *MaintenanceService) run(ctx context.Context, job models.MaintenanceJob) {
+	slog.InfoContext(ctx, "Maintenance job started", "job_id", job.ID, "kind", job.Kind, "operation_id", job.OperationID)
+	start := time.Now()
+	for i, step := range s.reindex {
+		job.Steps[i].Status = models.MaintenanceStepRunning
+		s.record(ctx, &job, "")
+
+		stepStart := time.Now()
+		err := step.Run(ctx)
+		job.Steps[i].DurationMs = float64(time.Since(stepStart).Microseconds()) / 1000
+		if err != nil {
+			job.Steps[i].Status = models.MaintenanceStepFailed
+			job.Steps[i].Error = err.Error()
+			slog.ErrorContext(ctx, "Maintenance job failed", "job_id", job.ID, "step", step.Name, "error", err)
+			s.finish(ctx, &job, step.Name+" failed: "+err.Error())
+			return
+		}
+		job.Steps[i].Status = models.MaintenanceStepDone
+	}
+
+	job.Status = models.MaintenanceJobSucceeded
+	s.record(ctx, &job, "")
+	slog.InfoContext(ctx, "Maintenance job finished", "job_id", job.ID, "duration", time.Since(start).Round(time.Millisecond))
+}
+
+// finish records job as failed with reason
+func (s *MaintenanceService) finish(ctx context.Context, job *models.MaintenanceJob, reason string) {
+	job.Status = models.MaintenanceJobFailed
+	s.record(ctx, job, reason)
+}
+
+// record stores the progress of job. The outcome is recorded even when ctx
+// was cancelled, or the job would be reported as running until it is
+// taken as abandoned.
+func (s *MaintenanceService) record(ctx context.Context, job *models.MaintenanceJob, jobErr string) {
+	if err := s.repo.Update(context.WithoutCancel(ctx), job.ID, job.Status, job.Steps, jobErr); err != nil {
+		slog.ErrorContext(ctx, "Failed to record maintenance job progress", "job_id", job.ID, "error", err)
+	}
+}
+
+// maintenanceProgress returns the percentage of steps finished
+func maintenanceProgress(steps []models.MaintenanceStep) int {
+	if len(steps) == 0 {
+		return 100
+	}
+	done := 0
+	for _, step := range steps {
+		if step.Status == models.MaintenanceStepDone {
+			done++
+		}
+	}
+	return done * 100 / len(steps)
+}
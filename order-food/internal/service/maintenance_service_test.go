@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+var maintenanceJobRowColumns = []string{"id", "kind", "status", "steps", "error", "requested_by", "instance_id", "operation_id", "created_at", "updated_at", "finished_at"}
+
+func TestMaintenanceService_Reindex(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	var ran []string
+	service := NewMaintenanceService(repository.NewMaintenanceRepository(db), "instance-1",
+		MaintenanceStep{Name: "searchIndex", Run: func(ctx context.Context) error {
+			ran = append(ran, "searchIndex")
+			return nil
+		}},
+		MaintenanceStep{Name: "caches", Run: func(ctx context.Context) error {
+			ran = append(ran, "caches")
+			return errors.New("warm-up timed out")
+		}},
+	)
+	service.running = true
+
+	// Mock expectations: abandoned jobs are expired before the new one is recorded
+	now := time.Now()
+	mock.ExpectExec("UPDATE maintenance_jobs").
+		WithArgs(models.MaintenanceJobReindex, models.MaintenanceJobFailed, models.MaintenanceJobRunning, float64(3600)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("INSERT INTO maintenance_jobs").
+		WithArgs(sqlmock.AnyArg(), models.MaintenanceJobReindex, models.MaintenanceJobRunning, sqlmock.AnyArg(), "admin", "instance-1", "op-1").
+		WillReturnRows(sqlmock.NewRows(maintenanceJobRowColumns).AddRow(
+			"5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c", "reindex", "running",
+			`[{"name":"searchIndex","status":"pending"},{"name":"caches","status":"pending"}]`,
+			"", "admin", "instance-1", "op-1", now, now, nil))
+	for range 2 {
+		mock.ExpectExec("UPDATE maintenance_jobs SET status").
+			WithArgs("5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c", models.MaintenanceJobRunning, sqlmock.AnyArg(), "").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec("UPDATE maintenance_jobs SET status").
+		WithArgs("5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c", models.MaintenanceJobFailed, sqlmock.AnyArg(), "caches failed: warm-up timed out").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Test
+	job, err := service.StartReindex("admin", "op-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, job.Progress)
+	service.run(context.Background(), <-service.jobs)
+
+	// Assert
+	assert.Equal(t, []string{"searchIndex", "caches"}, ran)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMaintenanceService_StartReindex_Conflicts(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewMaintenanceService(repository.NewMaintenanceRepository(db), "instance-1")
+
+	// Test: nothing is recorded while jobs are not being run
+	_, err = service.StartReindex("admin", "")
+	assert.ErrorIs(t, err, ErrMaintenanceUnavailable)
+
+	// Mock expectations: a reindex is running elsewhere
+	service.running = true
+	mock.ExpectExec("UPDATE maintenance_jobs").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("INSERT INTO maintenance_jobs").WillReturnRows(sqlmock.NewRows(maintenanceJobRowColumns))
+	_, err = service.StartReindex("admin", "")
+
+	// Assert
+	assert.ErrorIs(t, err, ErrMaintenanceJobRunning)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMaintenanceService_GetJob(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewMaintenanceService(repository.NewMaintenanceRepository(db), "instance-1")
+	now := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM maintenance_jobs WHERE id").
+		WithArgs("5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c").
+		WillReturnRows(sqlmock.NewRows(maintenanceJobRowColumns).AddRow(
+			"5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c", "reindex", "running",
+			`[{"name":"searchIndex","status":"done"},{"name":"materializedViews","status":"running"},{"name":"caches","status":"pending"}]`,
+			"", "admin", "instance-1", "", now, now, nil))
+
+	// Test
+	job, err := service.GetJob("5f0c6a4e-8d1b-4c1e-9a2f-3b7d9e1f2a4c")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 33, job.Progress)
+	assert.Len(t, job.Steps, 3)
+	_, err = service.GetJob("not-a-uuid")
+	assert.ErrorIs(t, err, ErrMaintenanceJobNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
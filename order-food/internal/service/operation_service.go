@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/operation"
+)
+
+// ErrOperationNotFound is returned when nothing was recorded under an operation ID
+var ErrOperationNotFound = errors.New("operation not found")
+
+// OperationService follows operations across the migration and load jobs
+// and the coupon file uploads that start them
+type OperationService struct {
+	runs        *repository.PipelineRunRepository
+	couponFiles *repository.CouponFileRepository
+}
+
+// NewOperationService creates a new operation service
+func NewOperationService(runs *repository.PipelineRunRepository, couponFiles *repository.CouponFileRepository) *OperationService {
+	return &OperationService{runs: runs, couponFiles: couponFiles}
+}
+
+// ListRuns returns pipeline runs, newest first, optionally filtered by status
+func (s *OperationService) ListRuns(status string, limit, offset int) ([]models.PipelineRun, int, error) {
+	return s.runs.GetAll(status, limit, offset)
+}
+
+// GetOperation returns everything recorded under id. ErrOperationNotFound
+// is returned for a malformed ID or one nothing was recorded under.
+func (s *OperationService) GetOperation(id string) (models.Operation, error) {
+	if !operation.Valid(id) {
+		return models.Operation{}, ErrOperationNotFound
+	}
+
+	runs, err := s.runs.GetByOperation(id)
+	if err != nil {
+		return models.Operation{}, err
+	}
+	couponFiles, err := s.couponFiles.GetByOperation(id)
+	if err != nil {
+		return models.Operation{}, err
+	}
+	if len(runs) == 0 && len(couponFiles) == 0 {
+		return models.Operation{}, ErrOperationNotFound
+	}
+
+	return models.Operation{ID: id, Runs: runs, CouponFiles: couponFiles}, nil
+}
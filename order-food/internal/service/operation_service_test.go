@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+const testOperationID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+var pipelineRunRowColumns = []string{
+	"id", "operation_id", "job", "instance_id", "status", "summary", "error", "started_at", "finished_at",
+}
+
+func TestOperationService_GetOperation(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOperationService(repository.NewPipelineRunRepository(db), repository.NewCouponFileRepository(db))
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT .* FROM pipeline_runs").
+		WithArgs(testOperationID).
+		WillReturnRows(sqlmock.NewRows(pipelineRunRowColumns).
+			AddRow(7, testOperationID, "database-load process-uploads", "i-1", "failed", "0 coupons from couponbase4.txt", "disk full", now, now))
+	mock.ExpectQuery("SELECT .* FROM coupon_file_uploads").
+		WithArgs(testOperationID).
+		WillReturnRows(sqlmock.NewRows(couponFileRowColumns).
+			AddRow("u-1", "couponbase4.txt", 18, models.CouponFileStatusFailed, 0, "disk full", "admin", testOperationID, now, now))
+
+	// Test
+	op, err := service.GetOperation(testOperationID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, testOperationID, op.ID)
+	assert.Len(t, op.Runs, 1)
+	assert.Equal(t, "disk full", op.Runs[0].Error)
+	assert.NotNil(t, op.Runs[0].FinishedAt)
+	assert.Len(t, op.CouponFiles, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOperationService_GetOperation_NotFound(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOperationService(repository.NewPipelineRunRepository(db), repository.NewCouponFileRepository(db))
+
+	mock.ExpectQuery("SELECT .* FROM pipeline_runs").
+		WithArgs(testOperationID).
+		WillReturnRows(sqlmock.NewRows(pipelineRunRowColumns))
+	mock.ExpectQuery("SELECT .* FROM coupon_file_uploads").
+		WithArgs(testOperationID).
+		WillReturnRows(sqlmock.NewRows(couponFileRowColumns))
+
+	// Test
+	_, err = service.GetOperation(testOperationID)
+	_, malformedErr := service.GetOperation("not-an-operation")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrOperationNotFound))
+	assert.True(t, errors.Is(malformedErr, ErrOperationNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/export"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+// Order export defaults
+const (
+	DefaultOrderExportLookbackDays = 7
+	DefaultOrderExportRetries      = 3
+	DefaultOrderExportRetryDelay   = 10 * time.Second
+)
+
+// OrderExportConfig configures the daily accounting export
+type OrderExportConfig struct {
+	Destination export.Destination
+	// Encrypter encrypts the files; nil delivers them as plain CSV
+	Encrypter *export.Encrypter
+	// Zone is the business time zone whose calendar days are exported
+	Zone *time.Location
+	// LookbackDays is how many days before today are exported when they
+	// have not been yet, so days missed while the export failed catch up
+	LookbackDays int
+	// Retries is how often a failed upload is retried, with RetryDelay
+	// doubling after each attempt, before the day's export fails
+	Retries    int
+	RetryDelay time.Duration
+}
+
+// OrderExportService pushes the orders placed and refunded on each
+// business day to the accounting system: an orders file, a refunds file
+// and then a manifest of both
+type OrderExportService struct {
+	repo *repository.OrderExportRepository
+	cfg  OrderExportConfig
+	now  func() time.Time
+
+	mu           sync.Mutex
+	exported     int64
+	failed       int64
+	lastExported time.Time
+}
+
+// NewOrderExportService creates a new order export service
+func NewOrderExportService(repo *repository.OrderExportRepository, cfg OrderExportConfig) *OrderExportService {
+	if cfg.Zone == nil {
+		cfg.Zone = time.UTC
+	}
+	if cfg.LookbackDays < 1 {
+		cfg.LookbackDays = DefaultOrderExportLookbackDays
+	}
+	if cfg.Retries < 0 {
+		cfg.Retries = 0
+	}
+	return &OrderExportService{repo: repo, cfg: cfg, now: time.Now}
+}
+
+// Run exports the days of the lookback period that were not exported yet,
+// oldest first, up to yesterday. It stops at the first day that fails, so
+// days are delivered in order; that day is retried on the next run. It is
+// run by the scheduler.
+func (s *OrderExportService) Run(ctx context.Context) error {
+	today := s.now().In(s.cfg.Zone)
+	first := time.Date(today.Year(), today.Month(), today.Day()-s.cfg.LookbackDays, 0, 0, 0, 0, s.cfg.Zone)
+	last := time.Date(today.Year(), today.Month(), today.Day()-1, 0, 0, 0, 0, s.cfg.Zone)
+
+	exported, err := s.repo.ExportedDays(ctx, first, last)
+	if err != nil {
+		return err
+	}
+	for day := first; !day.After(last); day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, s.cfg.Zone) {
+		if exported[day.Format(time.DateOnly)] {
+			continue
+		}
+		if err := s.ExportDay(ctx, day); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportDay exports the business day starting at day, replacing any
+// earlier export of it, and records the outcome
+func (s *OrderExportService) ExportDay(ctx context.Context, day time.Time) error {
+	orders, refunds, err := s.exportDay(ctx, day)
+	if err != nil {
+		err = fmt.Errorf("order export of %s failed: %w", day.Format(time.DateOnly), err)
+		s.record(false)
+		if recordErr := s.repo.RecordFailed(ctx, day, err); recordErr != nil {
+			slog.ErrorContext(ctx, "Error recording failed order export", "day", day.Format(time.DateOnly), "error", recordErr)
+		}
+		return err
+	}
+
+	s.record(true)
+	slog.InfoContext(ctx, "Exported orders to accounting", "day", day.Format(time.DateOnly), "orders", orders, "refunds", refunds)
+	return s.repo.RecordExported(ctx, day, orders, refunds)
+}
+
+// exportDay delivers the files of day and returns the number of orders
+// and refunds in them
+func (s *OrderExportService) exportDay(ctx context.Context, day time.Time) (int, int, error) {
+	from := day
+	to := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, s.cfg.Zone)
+
+	placed, err := s.repo.ListPlaced(ctx, from, to)
+	if err != nil {
+		return 0, 0, err
+	}
+	refunded, err := s.repo.ListRefunded(ctx, from, to)
+	if err != nil {
+		return 0, 0, err
+	}
+	ordersCSV, err := export.EncodeOrders(placed)
+	if err != nil {
+		return 0, 0, err
+	}
+	refundsCSV, err := export.EncodeRefunds(refunded)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	date := day.Format(time.DateOnly)
+	manifest := export.Manifest{Day: date, TimeZone: s.cfg.Zone.String(), GeneratedAt: s.now().UTC()}
+	if s.cfg.Encrypter != nil {
+		manifest.Encryption = "pgp"
+	}
+	files := []struct {
+		name    string
+		data    []byte
+		records int
+	}{
+		{"orders-" + date + ".csv", ordersCSV, len(placed)},
+		{"refunds-" + date + ".csv", refundsCSV, len(refunded)},
+	}
+	for _, file := range files {
+		name, data := file.name, file.data
+		if s.cfg.Encrypter != nil {
+			name += ".pgp"
+			if data, err = s.cfg.Encrypter.Encrypt(file.name, data); err != nil {
+				return 0, 0, err
+			}
+		}
+		if err := s.put(ctx, name, data); err != nil {
+			return 0, 0, err
+		}
+		manifest.Add(name, data, file.records)
+	}
+
+	// The manifest goes last, telling the accounting system the day is
+	// complete
+	data, err := manifest.Encode()
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := s.put(ctx, "manifest-"+date+".json", data); err != nil {
+		return 0, 0, err
+	}
+	return len(placed), len(refunded), nil
+}
+
+// put uploads a file, retrying failed uploads with a growing delay
+func (s *OrderExportService) put(ctx context.Context, name string, data []byte) error {
+	delay := s.cfg.RetryDelay
+	for attempt := 0; ; attempt++ {
+		err := s.cfg.Destination.Put(ctx, name, data)
+		if err == nil || attempt >= s.cfg.Retries || ctx.Err() != nil {
+			return err
+		}
+		slog.WarnContext(ctx, "Retrying order export upload", "file", name, "attempt", attempt+1, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+}
+
+// record counts an export that succeeded or failed
+func (s *OrderExportService) record(ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ok {
+		s.exported++
+		s.lastExported = s.now()
+	} else {
+		s.failed++
+	}
+}
+
+// Descriptors of the export metrics
+var (
+	orderExportsDesc           = prometheus.NewDesc("order_exports_total", "Days exported to the accounting system by this instance, by result.", []string{"result"}, nil)
+	orderExportLastSuccessDesc = prometheus.NewDesc("order_export_last_success_timestamp_seconds", "When this instance last exported a day to the accounting system.", nil, nil)
+)
+
+// Describe sends the descriptors of the export metrics
+func (s *OrderExportService) Describe(ch chan<- *prometheus.Desc) {
+	ch <- orderExportsDesc
+	ch <- orderExportLastSuccessDesc
+}
+
+// Collect sends the export counters and, once a day was exported, when
+// that last happened
+func (s *OrderExportService) Collect(ch chan<- prometheus.Metric) {
+	s.mu.Lock()
+	exported, failed, lastExported := s.exported, s.failed, s.lastExported
+	s.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(orderExportsDesc, prometheus.CounterValue, float64(exported), models.OrderExportStatusExported)
+	ch <- prometheus.MustNewConstMetric(orderExportsDesc, prometheus.CounterValue, float64(failed), models.OrderExportStatusFailed)
+	if !lastExported.IsZero() {
+		ch <- prometheus.MustNewConstMetric(orderExportLastSuccessDesc, prometheus.GaugeValue, float64(lastExported.Unix()))
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/expfmt"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyDestination fails the first failures uploads before storing files
+type flakyDestination struct {
+	memoryStore
+	failures int
+	attempts int
+}
+
+func (d *flakyDestination) Put(ctx context.Context, name string, data []byte) error {
+	d.attempts++
+	if d.failures > 0 {
+		d.failures--
+		return errors.New("connection reset by peer")
+	}
+	return d.memoryStore.Put(ctx, name, data)
+}
+
+var exportedOrderRowColumns = []string{"id", "coupon_code", "status", "total", "discount", "customer_id", "created_at", "cancelled_at", "units"}
+
+func TestOrderExportService_Run(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	zone := time.FixedZone("CEST", 2*3600)
+	destination := &flakyDestination{memoryStore: memoryStore{}, failures: 1}
+	service := NewOrderExportService(repository.NewOrderExportRepository(db), OrderExportConfig{
+		Destination:  destination,
+		Zone:         zone,
+		LookbackDays: 2,
+		Retries:      2,
+		RetryDelay:   time.Millisecond,
+	})
+	service.now = func() time.Time { return time.Date(2024, 5, 3, 1, 0, 0, 0, time.UTC) }
+
+	// The first day was exported already; only the second is due
+	dayStart := time.Date(2024, 5, 2, 0, 0, 0, 0, zone)
+	mock.ExpectQuery("SELECT to_char\\(day, 'YYYY-MM-DD'\\) FROM order_exports").
+		WithArgs("2024-05-01", "2024-05-02", "exported").
+		WillReturnRows(sqlmock.NewRows([]string{"day"}).AddRow("2024-05-01"))
+	placed := dayStart.Add(time.Hour)
+	cancelled := dayStart.Add(2 * time.Hour)
+	mock.ExpectQuery("WHERE o.created_at >= \\$1 AND o.created_at < \\$2").
+		WithArgs(dayStart, dayStart.AddDate(0, 0, 1)).
+		WillReturnRows(sqlmock.NewRows(exportedOrderRowColumns).
+			AddRow("o1", "", "completed", 13.0, 0.0, "c1", placed, nil, 2).
+			AddRow("o2", "HAPPYHRS", "cancelled", 9.0, 1.0, "", placed, cancelled, 1))
+	mock.ExpectQuery("WHERE o.cancelled_at >= \\$1 AND o.cancelled_at < \\$2").
+		WithArgs(dayStart, dayStart.AddDate(0, 0, 1)).
+		WillReturnRows(sqlmock.NewRows(exportedOrderRowColumns).
+			AddRow("o2", "HAPPYHRS", "cancelled", 9.0, 1.0, "", placed, cancelled, 1))
+	mock.ExpectExec("INSERT INTO order_exports").
+		WithArgs("2024-05-02", "exported", 2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute
+	err = service.Run(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, 4, destination.attempts, "the failed upload is retried")
+	assert.Equal(t, "order_id,placed_at,status,customer_id,coupon_code,units,subtotal,discount,total\n"+
+		"o1,2024-05-01T23:00:00Z,completed,c1,,2,13.00,0.00,13.00\n"+
+		"o2,2024-05-01T23:00:00Z,cancelled,,HAPPYHRS,1,10.00,1.00,9.00\n", string(destination.memoryStore["orders-2024-05-02.csv"]))
+	assert.Contains(t, string(destination.memoryStore["refunds-2024-05-02.csv"]), "o2,2024-05-01T23:00:00Z,2024-05-02T00:00:00Z,,HAPPYHRS,9.00\n")
+	assert.Contains(t, string(destination.memoryStore["manifest-2024-05-02.json"]), `"name": "refunds-2024-05-02.csv"`)
+
+	metrics, err := testutil.CollectAndFormat(service, expfmt.TypeTextPlain, "order_exports_total", "order_export_last_success_timestamp_seconds")
+	assert.NoError(t, err)
+	assert.Contains(t, string(metrics), `order_exports_total{result="exported"} 1`)
+	assert.Contains(t, string(metrics), "order_export_last_success_timestamp_seconds ")
+}
+
+func TestOrderExportService_Run_RecordsFailure(t *testing.T) {
+	// Setup
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	destination := &flakyDestination{memoryStore: memoryStore{}, failures: 10}
+	service := NewOrderExportService(repository.NewOrderExportRepository(db), OrderExportConfig{
+		Destination:  destination,
+		LookbackDays: 2,
+		Retries:      1,
+		RetryDelay:   time.Millisecond,
+	})
+	service.now = func() time.Time { return time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC) }
+
+	mock.ExpectQuery("FROM order_exports").
+		WillReturnRows(sqlmock.NewRows([]string{"day"}))
+	mock.ExpectQuery("WHERE o.created_at").
+		WillReturnRows(sqlmock.NewRows(exportedOrderRowColumns))
+	mock.ExpectQuery("WHERE o.cancelled_at").
+		WillReturnRows(sqlmock.NewRows(exportedOrderRowColumns))
+	mock.ExpectExec("INSERT INTO order_exports").
+		WithArgs("2024-05-01", "failed", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute
+	err = service.Run(context.Background())
+
+	// Assert - the next day waits until the failed one is delivered
+	assert.ErrorContains(t, err, "order export of 2024-05-01 failed")
+	assert.ErrorContains(t, err, "connection reset by peer")
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, 2, destination.attempts)
+	assert.Empty(t, destination.memoryStore, "no manifest marks the day complete")
+}
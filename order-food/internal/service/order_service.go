@@ -1,27 +1,242 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+
 	"github.com/google/uuid"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/payment"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/tracing"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/promocode"
+)
+
+var (
+	// ErrInvalidOrderStatus is returned for a status that does not exist
+	ErrInvalidOrderStatus = errors.New("invalid order status")
+	// ErrInvalidStatusTransition is returned when an order cannot move from
+	// its current status to the requested one
+	ErrInvalidStatusTransition = errors.New("invalid order status transition")
+	// ErrOrderNotFound is returned when an order does not exist
+	ErrOrderNotFound = repository.ErrOrderNotFound
+	// ErrOrderStatusConflict is returned when an order's status changed
+	// while it was being updated
+	ErrOrderStatusConflict = repository.ErrOrderStatusConflict
+	// ErrInvalidCursor is returned for a pagination cursor that is
+	// malformed or was issued for another sort order
+	ErrInvalidCursor = repository.ErrInvalidCursor
+	// ErrInvalidModifiers is returned for an order item whose modifiers are
+	// not options of its product or do not fit its option groups
+	ErrInvalidModifiers = errors.New("invalid modifiers")
 )
 
+// PriceMismatchError lists the items of an order whose expected unit price
+// differs from the current one; the order is not placed when it is returned
+type PriceMismatchError struct {
+	Mismatches []models.PriceMismatch
+}
+
+func (e *PriceMismatchError) Error() string {
+	return fmt.Sprintf("prices changed for %d items", len(e.Mismatches))
+}
+
+// orderStatusTransitions lists the statuses each status can move to.
+// Completed and cancelled orders are final.
+var orderStatusTransitions = map[models.OrderStatus][]models.OrderStatus{
+	models.OrderStatusPending:   {models.OrderStatusConfirmed, models.OrderStatusCancelled},
+	models.OrderStatusConfirmed: {models.OrderStatusPreparing, models.OrderStatusCancelled},
+	models.OrderStatusPreparing: {models.OrderStatusCompleted, models.OrderStatusCancelled},
+	models.OrderStatusCompleted: {},
+	models.OrderStatusCancelled: {},
+}
+
 // OrderService handles order business logic
 type OrderService struct {
+	tx          *repository.TxManager
 	orderRepo   *repository.OrderRepository
 	productRepo *repository.ProductRepository
+	stockRepo   *repository.ReservationRepository
+	promoCodes  *PromoCodeService
+	archive     *ArchiveService
+	// events publishes placed orders and status changes when non-nil
+	events EventPublisher
+	// payments charges new orders in currency when non-nil
+	payments payment.Provider
+	currency string
 }
 
-// NewOrderService creates a new order service
-func NewOrderService(orderRepo *repository.OrderRepository, productRepo *repository.ProductRepository) *OrderService {
+// NewOrderService creates a new order service. Placing an order takes its
+// stock and stores it in one transaction of tx. Promo codes on new orders
+// are checked with promoCodes, their discount taken off the total and
+// their use counted against their limits in the same transaction. When
+// archive is not nil, orders that have been moved to cold storage are still
+// found by GetOrder.
+func NewOrderService(tx *repository.TxManager, orderRepo *repository.OrderRepository, productRepo *repository.ProductRepository, stockRepo *repository.ReservationRepository, promoCodes *PromoCodeService, archive *ArchiveService) *OrderService {
 	return &OrderService{
+		tx:          tx,
 		orderRepo:   orderRepo,
 		productRepo: productRepo,
+		stockRepo:   stockRepo,
+		promoCodes:  promoCodes,
+		archive:     archive,
 	}
 }
 
-// PlaceOrder creates a new order
-func (s *OrderService) PlaceOrder(req models.OrderReq) (models.Order, error) {
+// SetEventPublisher makes the service publish an OrderCreated event for
+// every order placed or imported and an OrderStatusChanged event for every
+// status change
+func (s *OrderService) SetEventPublisher(events EventPublisher) {
+	s.events = events
+}
+
+// SetPaymentProvider makes the service charge new orders with something
+// to pay through payments, in currency. POS imports were paid at the till
+// and are not charged.
+func (s *OrderService) SetPaymentProvider(payments payment.Provider, currency string) {
+	s.payments = payments
+	s.currency = currency
+}
+
+// PlaceOrder creates a new order for the request in ctx. The order is
+// stored even when ctx is cancelled meanwhile, so a client that goes away
+// mid-checkout does not leave it half placed.
+func (s *OrderService) PlaceOrder(ctx context.Context, req models.OrderReq) (models.Order, error) {
+	return s.placeOrder(ctx, req, nil)
+}
+
+// placeOrder places an order for req. When claim is not nil it runs first
+// in the transaction storing the order, which is rolled back when it
+// fails, e.g. to mark the cart the order was placed from as checked out.
+func (s *OrderService) placeOrder(ctx context.Context, req models.OrderReq, claim func(ctx context.Context, order models.Order) error) (models.Order, error) {
+	order, err := s.buildOrder(ctx, req, false)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	intent, err := s.createPaymentIntent(ctx, order, req.PaymentMethod)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	// Take the stock and store the order together, consuming the
+	// reservation if the checkout made one. The payment is confirmed last,
+	// so an order that cannot be placed is never charged.
+	err = s.tx.WithinTx(context.WithoutCancel(ctx), func(ctx context.Context) error {
+		if claim != nil {
+			if err := claim(ctx, order); err != nil {
+				return err
+			}
+		}
+		if err := s.stockRepo.TakeStock(ctx, order.Items, req.ReservationID); err != nil {
+			return err
+		}
+		if err := s.orderRepo.Insert(ctx, order); err != nil {
+			return err
+		}
+		if err := s.redeemPromoCode(ctx, order, redeemingCustomer(req)); err != nil {
+			return err
+		}
+		return s.confirmPayment(ctx, &order, intent)
+	})
+	if err != nil {
+		s.cancelPayment(ctx, intent)
+		return models.Order{}, err
+	}
+
+	s.publishOrderCreated(ctx, order)
+	return order, nil
+}
+
+// ImportPOSOrder creates an order translated from a legacy POS ticket.
+// Imports are idempotent on the ticket number: replaying a ticket returns the
+// order created by the first import and created is false, also once that
+// order has been archived.
+func (s *OrderService) ImportPOSOrder(ctx context.Context, ticketNumber string, req models.OrderReq) (order models.Order, created bool, err error) {
+	existingID, err := s.orderRepo.GetOrderIDByPOSTicket(ticketNumber)
+	if err != nil {
+		return models.Order{}, false, err
+	}
+	if existingID != "" {
+		order, err := s.GetOrder(existingID)
+		return order, false, err
+	}
+
+	order, err = s.buildOrder(ctx, req, true)
+	if err != nil {
+		return models.Order{}, false, err
+	}
+
+	// POS tickets never carry a reservation
+	err = s.tx.WithinTx(context.WithoutCancel(ctx), func(ctx context.Context) error {
+		if err := s.stockRepo.TakeStock(ctx, order.Items, ""); err != nil {
+			return err
+		}
+		if err := s.orderRepo.Insert(ctx, order); err != nil {
+			return err
+		}
+		if err := s.redeemPromoCode(ctx, order, ""); err != nil {
+			return err
+		}
+		return s.orderRepo.ClaimPOSTicket(ctx, ticketNumber, order.ID)
+	})
+	if errors.Is(err, repository.ErrDuplicatePOSTicket) {
+		// Lost a race with a concurrent import of the same ticket
+		existingID, err := s.orderRepo.GetOrderIDByPOSTicket(ticketNumber)
+		if err != nil {
+			return models.Order{}, false, err
+		}
+		order, err := s.GetOrder(existingID)
+		return order, false, err
+	}
+	if err != nil {
+		return models.Order{}, false, err
+	}
+
+	s.publishOrderCreated(ctx, order)
+	return order, true, nil
+}
+
+// publishOrderCreated publishes an OrderCreated event for a new order
+func (s *OrderService) publishOrderCreated(ctx context.Context, order models.Order) {
+	if s.events == nil {
+		return
+	}
+	items := make([]models.OrderCreatedItem, len(order.Items))
+	for i, item := range order.Items {
+		product := order.Products[i]
+		items[i] = models.OrderCreatedItem{
+			ProductID: item.ProductID,
+			Name:      product.Name,
+			Category:  product.Category,
+			Quantity:  item.Quantity,
+			UnitPrice: product.Price,
+			Discount:  item.Discount,
+		}
+	}
+	publishEvent(ctx, s.events, models.EventTypeOrderCreated, order.ID, models.OrderCreated{
+		ID:         order.ID,
+		Status:     order.Status,
+		CustomerID: order.CustomerID,
+		CouponCode: order.CouponCode,
+		Items:      items,
+		Subtotal:   order.Subtotal,
+		Discount:   order.Discount,
+		Total:      order.Total,
+	})
+}
+
+// buildOrder resolves the requested products, modifiers and promo code and
+// assembles a new order. ErrInvalidPromoCode is returned for a code that is
+// not valid and ErrPromoCodeNotApplicable for one restricted to none of
+// the items. Imported orders were served at the till already, so option
+// groups they select too few options of are accepted.
+func (s *OrderService) buildOrder(ctx context.Context, req models.OrderReq, imported bool) (models.Order, error) {
 	// Extract product IDs from order items
 	productIDs := make([]string, len(req.Items))
 	for i, item := range req.Items {
@@ -33,34 +248,415 @@ func (s *OrderService) PlaceOrder(req models.OrderReq) (models.Order, error) {
 	if err != nil {
 		return models.Order{}, err
 	}
+	groups, err := s.productRepo.GetOptionGroups(ctx, productIDs)
+	if err != nil {
+		return models.Order{}, err
+	}
+	items, lines, err := priceModifiers(req.Items, products, groups, imported)
+	if err != nil {
+		return models.Order{}, err
+	}
+	if mismatches := checkExpectedPrices(req.Items, lines); len(mismatches) > 0 {
+		return models.Order{}, &PriceMismatchError{Mismatches: mismatches}
+	}
+
+	var discount *models.Discount
+	couponCode := promocode.Normalize(req.CouponCode)
+	if couponCode != "" && s.promoCodes != nil {
+		promo, valid, err := s.promoCodes.ValidatePromoCode(couponCode)
+		if err != nil {
+			return models.Order{}, err
+		}
+		if !valid {
+			return models.Order{}, ErrInvalidPromoCode
+		}
+		discount = promo.Discount
+	}
 
 	// Create order
-	order := models.Order{
+	subtotal := calculateTotal(items, lines)
+	discountAmount, err := applyDiscount(discount, items, lines)
+	if err != nil {
+		return models.Order{}, err
+	}
+	return models.Order{
 		ID:         uuid.New().String(),
-		CouponCode: req.CouponCode,
-		Items:      req.Items,
-		Products:   products,
+		CouponCode: couponCode,
+		Status:     models.OrderStatusPending,
+		CustomerID: req.AccountID,
+		Items:      items,
+		Products:   lines,
+		Subtotal:   subtotal,
+		Discount:   discountAmount,
+		Total:      math.Round((subtotal-discountAmount)*100) / 100,
+		Delivery:   normalizeDelivery(req.Delivery),
+	}, nil
+}
+
+// normalizeDelivery returns a copy of delivery with surrounding whitespace
+// trimmed and the postal code upper-cased, or nil for no delivery
+func normalizeDelivery(delivery *models.Delivery) *models.Delivery {
+	if delivery == nil {
+		return nil
+	}
+	address, contact := delivery.Address, delivery.Contact
+	return &models.Delivery{
+		Address: models.DeliveryAddress{
+			Line1:      strings.TrimSpace(address.Line1),
+			Line2:      strings.TrimSpace(address.Line2),
+			City:       strings.TrimSpace(address.City),
+			Region:     strings.TrimSpace(address.Region),
+			PostalCode: strings.ToUpper(strings.TrimSpace(address.PostalCode)),
+			Country:    address.Country,
+		},
+		Contact: models.DeliveryContact{
+			Name:  strings.TrimSpace(contact.Name),
+			Phone: strings.TrimSpace(contact.Phone),
+			Email: strings.TrimSpace(contact.Email),
+		},
+		Instructions: strings.TrimSpace(delivery.Instructions),
 	}
+}
 
-	// Store order
-	if err := s.orderRepo.Create(order); err != nil {
-		return models.Order{}, err
+// createPaymentIntent creates the payment intent charging order with
+// paymentMethod, or returns nil when there is no provider or nothing to pay
+func (s *OrderService) createPaymentIntent(ctx context.Context, order models.Order, paymentMethod string) (*payment.Intent, error) {
+	amount := payment.MinorUnits(order.Total)
+	if s.payments == nil || amount <= 0 {
+		return nil, nil
 	}
+	intent, err := s.payments.CreateIntent(ctx, payment.IntentReq{
+		OrderID:       order.ID,
+		Amount:        amount,
+		Currency:      s.currency,
+		PaymentMethod: paymentMethod,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment intent: %w", err)
+	}
+	return &intent, nil
+}
 
-	return order, nil
+// confirmPayment charges intent, if any, within the transaction of ctx and
+// stores the payment on order. The transaction holds the order's stock
+// while the provider is called.
+func (s *OrderService) confirmPayment(ctx context.Context, order *models.Order, intent *payment.Intent) error {
+	if intent == nil {
+		return nil
+	}
+	confirmed, err := s.payments.Confirm(ctx, intent.ID)
+	if err != nil {
+		return fmt.Errorf("failed to confirm payment: %w", err)
+	}
+	tracing.PaymentAuthorized(ctx, s.payments.Name(), confirmed.ID, confirmed.Amount)
+	order.Payment = &models.Payment{
+		Provider: s.payments.Name(),
+		IntentID: confirmed.ID,
+		Status:   string(confirmed.Status),
+		Amount:   confirmed.Amount,
+		Currency: confirmed.Currency,
+	}
+	return s.orderRepo.InsertPayment(ctx, order.ID, *order.Payment)
 }
 
-// GetOrder returns an order by ID
+// cancelPayment cancels intent, if any, after its order was rolled back.
+// An intent that was confirmed before the rollback is refunded. A failure
+// is only logged, since the order has failed already.
+func (s *OrderService) cancelPayment(ctx context.Context, intent *payment.Intent) {
+	if intent == nil {
+		return
+	}
+	if _, err := s.payments.Cancel(context.WithoutCancel(ctx), intent.ID); err != nil {
+		slog.ErrorContext(ctx, "Failed to cancel the payment of an order that was not placed; cancel or refund it at the provider",
+			"provider", s.payments.Name(), "intent_id", intent.ID, "order_id", intent.OrderID, "error", err)
+	}
+}
+
+// redeemPromoCode counts the use of the order's promo code, if any, within
+// the transaction of ctx
+func (s *OrderService) redeemPromoCode(ctx context.Context, order models.Order, customerID string) error {
+	if order.CouponCode == "" || s.promoCodes == nil {
+		return nil
+	}
+	return s.promoCodes.RedeemPromoCode(ctx, order.CouponCode, order.ID, customerID, order.Discount)
+}
+
+// redeemingCustomer returns who a promo code on req is redeemed for: the
+// signed-in customer account placing the order, or else the customerId the
+// caller sent. A signed-in customer cannot get around a once-per-customer
+// limit by sending another customerId.
+func redeemingCustomer(req models.OrderReq) string {
+	if req.AccountID != "" {
+		return req.AccountID
+	}
+	return req.CustomerID
+}
+
+// priceModifiers resolves the modifiers selected for each item against the
+// option groups of its product. It returns the items to store, which leave
+// out the expected prices, and the product of each item with the prices of
+// its modifiers added to its unit price, so totals, discounts and the
+// stored order include them. A product listed on several items, such as a
+// small and a large pizza, is priced for each item on its own.
+func priceModifiers(reqItems []models.OrderItem, products []models.Product, groups map[string][]models.OptionGroup, imported bool) ([]models.OrderItem, []models.Product, error) {
+	lines := lineProducts(reqItems, products)
+	items := make([]models.OrderItem, len(reqItems))
+	for i, item := range reqItems {
+		modifiers, err := selectModifiers(item, groups[item.ProductID], imported)
+		if err != nil {
+			return nil, nil, err
+		}
+		items[i] = models.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity, Modifiers: modifiers}
+
+		if len(modifiers) == 0 {
+			continue
+		}
+		price := lines[i].Price
+		for _, modifier := range modifiers {
+			price += modifier.Price
+		}
+		lines[i].Price = math.Round(price*100) / 100
+	}
+	return items, lines, nil
+}
+
+// lineProducts returns the product of each item, in the order of items
+func lineProducts(items []models.OrderItem, products []models.Product) []models.Product {
+	byID := make(map[string]models.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+	lines := make([]models.Product, len(items))
+	for i, item := range items {
+		lines[i] = byID[item.ProductID]
+	}
+	return lines
+}
+
+// selectModifiers checks the modifiers of item against the option groups
+// of its product and returns them with the group, name and price of each
+// option, or nil when it has none. Unless imported is set, every group
+// must have at least its minimum of options selected.
+func selectModifiers(item models.OrderItem, groups []models.OptionGroup, imported bool) ([]models.ItemModifier, error) {
+	if len(item.Modifiers) == 0 && len(groups) == 0 {
+		return nil, nil
+	}
+
+	type choice struct {
+		group  *models.OptionGroup
+		option models.ProductOption
+	}
+	choices := make(map[string]choice)
+	for g := range groups {
+		for _, option := range groups[g].Options {
+			choices[option.ID] = choice{group: &groups[g], option: option}
+		}
+	}
+
+	var modifiers []models.ItemModifier
+	selected := make(map[string]int, len(groups))
+	for _, modifier := range item.Modifiers {
+		optionID := strings.TrimSpace(modifier.OptionID)
+		c, found := choices[optionID]
+		if !found {
+			return nil, fmt.Errorf("%w: product %s has no option %q", ErrInvalidModifiers, item.ProductID, optionID)
+		}
+		if slices.ContainsFunc(modifiers, func(m models.ItemModifier) bool { return m.OptionID == optionID }) {
+			return nil, fmt.Errorf("%w: option %s of product %s is selected twice", ErrInvalidModifiers, optionID, item.ProductID)
+		}
+		selected[c.group.ID]++
+		modifiers = append(modifiers, models.ItemModifier{
+			OptionID: optionID,
+			Group:    c.group.Name,
+			Name:     c.option.Name,
+			Price:    c.option.Price,
+		})
+	}
+
+	for _, group := range groups {
+		switch n := selected[group.ID]; {
+		case n > group.MaxSelect:
+			return nil, fmt.Errorf("%w: product %s takes at most %d of its %s options", ErrInvalidModifiers, item.ProductID, group.MaxSelect, group.Name)
+		case n < group.MinSelect && !imported:
+			return nil, fmt.Errorf("%w: product %s needs at least %d of its %s options", ErrInvalidModifiers, item.ProductID, group.MinSelect, group.Name)
+		}
+	}
+	return modifiers, nil
+}
+
+// checkExpectedPrices returns the items whose expected unit price differs
+// from the current price of their line in lines, which includes the item's
+// modifiers, comparing whole cents
+func checkExpectedPrices(items []models.OrderItem, lines []models.Product) []models.PriceMismatch {
+	var mismatches []models.PriceMismatch
+	for i, item := range items {
+		if item.ExpectedUnitPrice == nil {
+			continue
+		}
+		price := lines[i].Price
+		if math.Round(*item.ExpectedUnitPrice*100) == math.Round(price*100) {
+			continue
+		}
+		mismatches = append(mismatches, models.PriceMismatch{
+			ProductID:         item.ProductID,
+			ExpectedUnitPrice: *item.ExpectedUnitPrice,
+			UnitPrice:         price,
+		})
+	}
+	return mismatches
+}
+
+// applyDiscount works out the discount on the items it applies to and
+// shares it among them in proportion to their line totals, setting the
+// Discount of each. lines holds the product of each item. The shares are
+// rounded to cents, with the rounding left on the last discounted item so
+// they add up to the returned amount.
+func applyDiscount(discount *models.Discount, items []models.OrderItem, lines []models.Product) (float64, error) {
+	if discount == nil {
+		return 0, nil
+	}
+
+	var eligible []int
+	lineTotals := make([]float64, len(items))
+	eligibleSubtotal := 0.0
+	for i, item := range items {
+		product := lines[i]
+		if product.ID == "" || !discount.AppliesTo(product) {
+			continue
+		}
+		lineTotals[i] = product.Price * float64(item.Quantity)
+		eligibleSubtotal += lineTotals[i]
+		eligible = append(eligible, i)
+	}
+	if len(eligible) == 0 {
+		if discount.Scoped() {
+			return 0, ErrPromoCodeNotApplicable
+		}
+		return 0, nil
+	}
+
+	amount := discount.Amount(math.Round(eligibleSubtotal*100) / 100)
+	remaining := amount
+	for n, i := range eligible {
+		share := remaining
+		if n < len(eligible)-1 && eligibleSubtotal > 0 {
+			share = math.Round(amount*lineTotals[i]/eligibleSubtotal*100) / 100
+		}
+		share = min(share, remaining)
+		items[i].Discount = share
+		remaining = math.Round((remaining-share)*100) / 100
+	}
+	return amount, nil
+}
+
+// calculateTotal sums quantity times unit price for each item, taking the
+// unit price from the item's product in lines, rounded to cents
+func calculateTotal(items []models.OrderItem, lines []models.Product) float64 {
+	total := 0.0
+	for i, item := range items {
+		total += lines[i].Price * float64(item.Quantity)
+	}
+	return math.Round(total*100) / 100
+}
+
+// GetOrder returns an order by ID, rehydrating it from the archive if it
+// has been moved to cold storage
 func (s *OrderService) GetOrder(id string) (models.Order, error) {
-	return s.orderRepo.GetByID(id)
+	order, err := s.orderRepo.GetByID(id)
+	if !errors.Is(err, repository.ErrOrderNotFound) || s.archive == nil {
+		return order, err
+	}
+
+	archived, found, archiveErr := s.archive.GetArchivedOrder(id)
+	if archiveErr != nil {
+		return models.Order{}, archiveErr
+	}
+	if !found {
+		return models.Order{}, err
+	}
+	return archived, nil
+}
+
+// ListOrderItems returns a page of the items of an order with the number
+// of items it has, reading them from the archive if the order has been
+// moved to cold storage
+func (s *OrderService) ListOrderItems(id string, limit, offset int) ([]models.OrderLine, int, error) {
+	lines, total, err := s.orderRepo.GetItemsPaginated(id, limit, offset)
+	if !errors.Is(err, repository.ErrOrderNotFound) || s.archive == nil {
+		return lines, total, err
+	}
+
+	archived, found, archiveErr := s.archive.GetArchivedOrder(id)
+	if archiveErr != nil {
+		return nil, 0, archiveErr
+	}
+	if !found {
+		return nil, 0, err
+	}
+	return pageOrderLines(archived, limit, offset), len(archived.Items), nil
+}
+
+// pageOrderLines pairs the items of order with their products and returns
+// the requested page of them
+func pageOrderLines(order models.Order, limit, offset int) []models.OrderLine {
+	lines := make([]models.OrderLine, 0, limit)
+	for i := offset; i < len(order.Items) && len(lines) < limit; i++ {
+		line := models.OrderLine{OrderItem: order.Items[i]}
+		if i < len(order.Products) {
+			line.Product = order.Products[i]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// UpdateOrderStatus moves an order to status and returns the updated order.
+// Setting the status an order already has is a no-op, so kitchen systems
+// can safely retry.
+func (s *OrderService) UpdateOrderStatus(ctx context.Context, id string, status models.OrderStatus) (models.Order, error) {
+	if _, known := orderStatusTransitions[status]; !known {
+		return models.Order{}, fmt.Errorf("%w: %q", ErrInvalidOrderStatus, status)
+	}
+
+	order, err := s.orderRepo.GetByID(id)
+	if err != nil {
+		return models.Order{}, err
+	}
+	if order.Status == status {
+		return order, nil
+	}
+	if !slices.Contains(orderStatusTransitions[order.Status], status) {
+		return models.Order{}, fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, order.Status, status)
+	}
+
+	if err := s.orderRepo.UpdateStatus(id, order.Status, status); err != nil {
+		return models.Order{}, err
+	}
+	publishEvent(ctx, s.events, models.EventTypeOrderStatusChanged, id, models.OrderStatusChanged{ID: id, From: order.Status, To: status})
+	order.Status = status
+	return order, nil
 }
 
 // CreateOrder creates a new order (alias for PlaceOrder)
-func (s *OrderService) CreateOrder(req models.OrderReq) (models.Order, error) {
-	return s.PlaceOrder(req)
+func (s *OrderService) CreateOrder(ctx context.Context, req models.OrderReq) (models.Order, error) {
+	return s.PlaceOrder(ctx, req)
+}
+
+// ListOrdersPaginated returns paginated orders matching filter with total
+// count. The queries are cancelled when ctx is.
+func (s *OrderService) ListOrdersPaginated(ctx context.Context, limit, offset int, sort []models.SortField, filter models.OrderFilter) ([]models.Order, int, error) {
+	return s.orderRepo.GetAll(ctx, limit, offset, sort, filter)
+}
+
+// ListOrdersAfter returns up to limit orders matching filter following the
+// after cursor with the cursor of the next page, which is empty on the
+// last. The queries are cancelled when ctx is.
+func (s *OrderService) ListOrdersAfter(ctx context.Context, after string, limit int, sort []models.SortField, filter models.OrderFilter) ([]models.Order, string, error) {
+	return s.orderRepo.GetAllAfter(ctx, after, limit, sort, filter)
 }
 
-// ListOrdersPaginated returns paginated orders with total count
-func (s *OrderService) ListOrdersPaginated(limit, offset int) ([]models.Order, int, error) {
-	return s.orderRepo.GetAll(limit, offset)
+// StreamOrders calls fn with every order matching filter, for listings
+// streamed in one response. The queries are cancelled when ctx is.
+func (s *OrderService) StreamOrders(ctx context.Context, sort []models.SortField, filter models.OrderFilter, fn func(models.Order) error) error {
+	return s.orderRepo.EachOrder(ctx, sort, filter, fn)
 }
@@ -0,0 +1,1175 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/payment"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderService_PlaceOrder_SnapshotsProducts(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 6.5, "Waffle", "", "", ""))
+	expectNoOptionGroups(mock)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, stock FROM products").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock"}).AddRow("1", nil))
+	mock.ExpectExec("UPDATE products SET stock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO orders").
+		WithArgs(sqlmock.AnyArg(), "", models.OrderStatusPending, 13.0, 0.0, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO order_items").
+		WithArgs(sqlmock.AnyArg(), "1", 2, "Waffle", "Waffle", 6.5, 0.0, 0.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	// Test
+	_, err = service.PlaceOrder(context.Background(), models.OrderReq{Items: []models.OrderItem{{ProductID: "1", Quantity: 2}}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_PlaceOrder_StoresDelivery(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 6.5, "Waffle", "", "", ""))
+	expectNoOptionGroups(mock)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, stock FROM products").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock"}).AddRow("1", nil))
+	mock.ExpectExec("UPDATE products SET stock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO order_items").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO order_addresses").
+		WithArgs(sqlmock.AnyArg(), "Berlin", "", "10115", "DE",
+			"Invalidenstr. 1", "", "Ada Lovelace", "+49 30 1234567", "", "Ring twice").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Test
+	order, err := service.PlaceOrder(context.Background(), models.OrderReq{
+		Items: []models.OrderItem{{ProductID: "1", Quantity: 2}},
+		Delivery: &models.Delivery{
+			Address:      models.DeliveryAddress{Line1: " Invalidenstr. 1 ", City: "Berlin", PostalCode: "10115", Country: "DE"},
+			Contact:      models.DeliveryContact{Name: "Ada Lovelace", Phone: "+49 30 1234567"},
+			Instructions: "Ring twice ",
+		},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.NotNil(t, order.Delivery) {
+		assert.Equal(t, "Invalidenstr. 1", order.Delivery.Address.Line1)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_PlaceOrder_AppliesPromoCodeDiscount(t *testing.T) {
+	tests := []struct {
+		name         string
+		discountType any
+		value        any
+		wantDiscount float64
+		wantTotal    float64
+	}{
+		{name: "percentage", discountType: "percentage", value: 10.0, wantDiscount: 1.3, wantTotal: 11.7},
+		{name: "fixed", discountType: "fixed", value: 5.0, wantDiscount: 5, wantTotal: 8},
+		{name: "fixed above subtotal", discountType: "fixed", value: 20.0, wantDiscount: 13, wantTotal: 0},
+		{name: "no discount", discountType: nil, value: nil, wantDiscount: 0, wantTotal: 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), NewPromoCodeService(db, nil), nil)
+
+			mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+					AddRow("1", "Waffle", 6.5, "Waffle", "", "", ""))
+			expectNoOptionGroups(mock)
+			mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+				WithArgs("HAPPYHRS", 2).
+				WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, tt.discountType, tt.value, nil, nil))
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT id, stock FROM products").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "stock"}).AddRow("1", nil))
+			mock.ExpectExec("UPDATE products SET stock").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("INSERT INTO orders").
+				WithArgs(sqlmock.AnyArg(), "HAPPYHRS", models.OrderStatusPending, tt.wantTotal, tt.wantDiscount, "").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery("INSERT INTO order_items").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("UPDATE coupon_limits SET redemptions").
+				WithArgs("HAPPYHRS").
+				WillReturnError(sql.ErrNoRows)
+			mock.ExpectExec("INSERT INTO coupon_redemptions").
+				WithArgs("HAPPYHRS", sqlmock.AnyArg(), "", tt.wantDiscount).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			// Test
+			order, err := service.PlaceOrder(context.Background(), models.OrderReq{
+				CouponCode: "HAPPYHRS",
+				Items:      []models.OrderItem{{ProductID: "1", Quantity: 2}},
+			})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, 13.0, order.Subtotal)
+			assert.Equal(t, tt.wantDiscount, order.Discount)
+			assert.Equal(t, tt.wantTotal, order.Total)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOrderService_PlaceOrder_InvalidPromoCode(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), NewPromoCodeService(db, nil), nil)
+
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 6.5, "Waffle", "", "", ""))
+	expectNoOptionGroups(mock)
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("ONLYONCE", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(1, nil, nil, nil, nil))
+
+	// Test
+	_, err = service.PlaceOrder(context.Background(), models.OrderReq{
+		CouponCode: "ONLYONCE",
+		Items:      []models.OrderItem{{ProductID: "1", Quantity: 2}},
+	})
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrInvalidPromoCode))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_PlaceOrder_ScopedPromoCode(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), NewPromoCodeService(db, nil), nil)
+
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 6.5, "Waffle", "", "", "").
+			AddRow("2", "Latte", 4.0, "Coffee", "", "", "").
+			AddRow("3", "Brownie", 5.0, "Brownie", "", "", ""))
+	expectNoOptionGroups(mock)
+	// 10% off waffles and product 3
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("HAPPYHRS", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, "percentage", 10.0, "{Waffle}", "{3}"))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, stock FROM products").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock"}).AddRow("1", nil).AddRow("2", nil).AddRow("3", nil))
+	for range 3 {
+		mock.ExpectExec("UPDATE products SET stock").WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	mock.ExpectExec("INSERT INTO orders").
+		WithArgs(sqlmock.AnyArg(), "HAPPYHRS", models.OrderStatusPending, 20.2, 1.8, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO order_items").
+		WithArgs(sqlmock.AnyArg(), "1", 2, "Waffle", "Waffle", 6.5, 0.0, 1.3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("INSERT INTO order_items").
+		WithArgs(sqlmock.AnyArg(), "2", 1, "Latte", "Coffee", 4.0, 0.0, 0.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("INSERT INTO order_items").
+		WithArgs(sqlmock.AnyArg(), "3", 1, "Brownie", "Brownie", 5.0, 0.0, 0.5).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("UPDATE coupon_limits SET redemptions").
+		WithArgs("HAPPYHRS").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("INSERT INTO coupon_redemptions").
+		WithArgs("HAPPYHRS", sqlmock.AnyArg(), "", 1.8).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Test
+	order, err := service.PlaceOrder(context.Background(), models.OrderReq{
+		CouponCode: "HAPPYHRS",
+		Items: []models.OrderItem{
+			{ProductID: "1", Quantity: 2},
+			{ProductID: "2", Quantity: 1},
+			{ProductID: "3", Quantity: 1},
+		},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 22.0, order.Subtotal)
+	assert.Equal(t, 1.8, order.Discount)
+	assert.Equal(t, 20.2, order.Total)
+	assert.Equal(t, []float64{1.3, 0, 0.5}, []float64{order.Items[0].Discount, order.Items[1].Discount, order.Items[2].Discount})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_PlaceOrder_PromoCodeNotApplicable(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), NewPromoCodeService(db, nil), nil)
+
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("2", "Latte", 4.0, "Coffee", "", "", ""))
+	expectNoOptionGroups(mock)
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("WAFFLES22", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, "fixed", 2.0, "{Waffle}", "{}"))
+
+	// Test
+	_, err = service.PlaceOrder(context.Background(), models.OrderReq{
+		CouponCode: "WAFFLES22",
+		Items:      []models.OrderItem{{ProductID: "2", Quantity: 1}},
+	})
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrPromoCodeNotApplicable))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApplyDiscount(t *testing.T) {
+	products := []models.Product{
+		{ID: "1", Category: "Waffle", Price: 1},
+		{ID: "2", Category: "Waffle", Price: 1},
+		{ID: "3", Category: "Waffle", Price: 1},
+	}
+	tests := []struct {
+		name       string
+		discount   *models.Discount
+		wantAmount float64
+		wantShares []float64
+	}{
+		{name: "no discount", discount: nil, wantAmount: 0, wantShares: []float64{0, 0, 0}},
+		{name: "whole order", discount: &models.Discount{Type: models.DiscountTypeFixed, Value: 1}, wantAmount: 1, wantShares: []float64{0.33, 0.33, 0.34}},
+		{name: "products", discount: &models.Discount{Type: models.DiscountTypeFixed, Value: 5, ProductIDs: []string{"1", "3"}}, wantAmount: 2, wantShares: []float64{1, 0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			items := []models.OrderItem{{ProductID: "1", Quantity: 1}, {ProductID: "2", Quantity: 1}, {ProductID: "3", Quantity: 1}}
+
+			// Test
+			amount, err := applyDiscount(tt.discount, items, products)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAmount, amount)
+			assert.Equal(t, tt.wantShares, []float64{items[0].Discount, items[1].Discount, items[2].Discount})
+		})
+	}
+}
+
+func TestOrderService_PlaceOrder_PromoCodeLimits(t *testing.T) {
+	tests := []struct {
+		name            string
+		customerID      string
+		accountID       string
+		counted         bool
+		oncePerCustomer bool
+		usedByCustomer  bool
+		wantErr         error
+	}{
+		{name: "within limit", counted: true},
+		{name: "limit reached", counted: false, wantErr: ErrPromoCodeExhausted},
+		{name: "first use by customer", customerID: "customer-1", counted: true, oncePerCustomer: true},
+		{name: "second use by customer", customerID: "customer-1", counted: true, oncePerCustomer: true, usedByCustomer: true, wantErr: ErrPromoCodeRedeemed},
+		{name: "no customer", counted: true, oncePerCustomer: true, wantErr: ErrCustomerRequired},
+		{name: "first use by account", accountID: "account-1", counted: true, oncePerCustomer: true},
+		// A signed-in customer cannot reuse the code by sending another customerId
+		{name: "second use by account", customerID: "customer-2", accountID: "account-1", counted: true, oncePerCustomer: true, usedByCustomer: true, wantErr: ErrPromoCodeRedeemed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), NewPromoCodeService(db, nil), nil)
+
+			mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+					AddRow("1", "Waffle", 6.5, "Waffle", "", "", ""))
+			expectNoOptionGroups(mock)
+			mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+				WithArgs("HAPPYHRS", 2).
+				WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, nil, nil, nil, nil))
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT id, stock FROM products").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "stock"}).AddRow("1", nil))
+			mock.ExpectExec("UPDATE products SET stock").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery("INSERT INTO order_items").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			mock.ExpectQuery("UPDATE coupon_limits SET redemptions = redemptions \\+ 1\\s+WHERE coupon = \\$1 AND \\(max_redemptions IS NULL OR redemptions < max_redemptions\\)").
+				WithArgs("HAPPYHRS").
+				WillReturnRows(sqlmock.NewRows([]string{"exists", "once_per_customer"}).
+					AddRow(tt.counted, tt.oncePerCustomer))
+			// The code is redeemed for the account when there is one
+			redeemer := tt.customerID
+			if tt.accountID != "" {
+				redeemer = tt.accountID
+			}
+			if tt.counted && tt.oncePerCustomer && redeemer != "" {
+				mock.ExpectQuery("SELECT EXISTS").
+					WithArgs("HAPPYHRS", redeemer).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(tt.usedByCustomer))
+			}
+			if tt.wantErr == nil {
+				mock.ExpectExec("INSERT INTO coupon_redemptions").
+					WithArgs("HAPPYHRS", sqlmock.AnyArg(), redeemer, 0.0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			// Test
+			_, err = service.PlaceOrder(context.Background(), models.OrderReq{
+				CouponCode: "HAPPYHRS",
+				CustomerID: tt.customerID,
+				AccountID:  tt.accountID,
+				Items:      []models.OrderItem{{ProductID: "1", Quantity: 2}},
+			})
+
+			// Assert
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.Is(err, tt.wantErr))
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOrderService_PlaceOrder_ExpectedPriceChanged(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 6.5, "Waffle", "", "", "").
+			AddRow("2", "Latte", 4.25, "Drinks", "", "", ""))
+	expectNoOptionGroups(mock)
+	seen, current := 6.0, 4.25
+
+	// Test
+	_, err = service.PlaceOrder(context.Background(), models.OrderReq{Items: []models.OrderItem{
+		{ProductID: "1", Quantity: 2, ExpectedUnitPrice: &seen},
+		{ProductID: "2", Quantity: 1, ExpectedUnitPrice: &current},
+	}})
+
+	// Assert
+	var priceErr *PriceMismatchError
+	assert.True(t, errors.As(err, &priceErr))
+	assert.Equal(t, []models.PriceMismatch{{ProductID: "1", ExpectedUnitPrice: 6.0, UnitPrice: 6.5}}, priceErr.Mismatches)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// recordingPayments is the mock payment provider remembering the intents
+// it created
+type recordingPayments struct {
+	*payment.Mock
+	created []string
+}
+
+func (p *recordingPayments) CreateIntent(ctx context.Context, req payment.IntentReq) (payment.Intent, error) {
+	intent, err := p.Mock.CreateIntent(ctx, req)
+	if err == nil {
+		p.created = append(p.created, intent.ID)
+	}
+	return intent, err
+}
+
+// lastIntent returns the last intent created
+func (p *recordingPayments) lastIntent(t *testing.T) payment.Intent {
+	t.Helper()
+	if !assert.NotEmpty(t, p.created, "no payment intent was created") {
+		return payment.Intent{}
+	}
+	intent, _ := p.Intent(p.created[len(p.created)-1])
+	return intent
+}
+
+func TestOrderService_PlaceOrder_PricesModifiers(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 6.5, "Waffle", "", "", ""))
+	mock.ExpectQuery("FROM product_option_groups g").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "id", "name", "min_select", "max_select", "id", "name", "price"}).
+			AddRow("1", "size", "Size", 1, 1, "regular", "Regular", 0.0).
+			AddRow("1", "size", "Size", 1, 1, "large", "Large", 2.0).
+			AddRow("1", "toppings", "Toppings", 0, 2, "syrup", "Maple syrup", 0.5).
+			AddRow("1", "toppings", "Toppings", 0, 2, "cream", "Whipped cream", 0.75))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, stock FROM products").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock"}).AddRow("1", nil))
+	mock.ExpectExec("UPDATE products SET stock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO orders").
+		WithArgs(sqlmock.AnyArg(), "", models.OrderStatusPending, 18.0, 0.0, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// The unit price includes the modifiers
+	mock.ExpectQuery("INSERT INTO order_items").
+		WithArgs(sqlmock.AnyArg(), "1", 2, "Waffle", "Waffle", 9.0, 0.0, 0.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO order_item_modifiers").
+		WithArgs(int64(1), "large", "Size", "Large", 2.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO order_item_modifiers").
+		WithArgs(int64(1), "syrup", "Toppings", "Maple syrup", 0.5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Test
+	expected := 9.0
+	order, err := service.PlaceOrder(context.Background(), models.OrderReq{Items: []models.OrderItem{{
+		ProductID:         "1",
+		Quantity:          2,
+		ExpectedUnitPrice: &expected,
+		Modifiers:         []models.ItemModifier{{OptionID: "large"}, {OptionID: " syrup"}},
+	}}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 18.0, order.Total)
+	assert.Equal(t, 9.0, order.Products[0].Price)
+	assert.Equal(t, []models.ItemModifier{
+		{OptionID: "large", Group: "Size", Name: "Large", Price: 2.0},
+		{OptionID: "syrup", Group: "Toppings", Name: "Maple syrup", Price: 0.5},
+	}, order.Items[0].Modifiers)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_PlaceOrder_PricesEachItemOfAProduct(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 6.5, "Waffle", "", "", ""))
+	mock.ExpectQuery("FROM product_option_groups g").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "id", "name", "min_select", "max_select", "id", "name", "price"}).
+			AddRow("1", "size", "Size", 1, 1, "regular", "Regular", 0.0).
+			AddRow("1", "size", "Size", 1, 1, "large", "Large", 2.0))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, stock FROM products").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock"}).AddRow("1", nil))
+	mock.ExpectExec("UPDATE products SET stock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE products SET stock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO orders").
+		WithArgs(sqlmock.AnyArg(), "", models.OrderStatusPending, 23.5, 0.0, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Each item is stored with its own unit price and modifiers
+	mock.ExpectQuery("INSERT INTO order_items").
+		WithArgs(sqlmock.AnyArg(), "1", 1, "Waffle", "Waffle", 6.5, 0.0, 0.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO order_item_modifiers").
+		WithArgs(int64(1), "regular", "Size", "Regular", 0.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO order_items").
+		WithArgs(sqlmock.AnyArg(), "1", 2, "Waffle", "Waffle", 8.5, 0.0, 0.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectExec("INSERT INTO order_item_modifiers").
+		WithArgs(int64(2), "large", "Size", "Large", 2.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Test
+	regular, large := 6.5, 8.5
+	order, err := service.PlaceOrder(context.Background(), models.OrderReq{Items: []models.OrderItem{
+		{ProductID: "1", Quantity: 1, ExpectedUnitPrice: &regular, Modifiers: []models.ItemModifier{{OptionID: "regular"}}},
+		{ProductID: "1", Quantity: 2, ExpectedUnitPrice: &large, Modifiers: []models.ItemModifier{{OptionID: "large"}}},
+	}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 23.5, order.Total)
+	assert.Equal(t, []float64{6.5, 8.5}, []float64{order.Products[0].Price, order.Products[1].Price})
+	assert.Equal(t, "regular", order.Items[0].Modifiers[0].OptionID)
+	assert.Equal(t, "large", order.Items[1].Modifiers[0].OptionID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSelectModifiers(t *testing.T) {
+	groups := []models.OptionGroup{
+		{ID: "size", Name: "Size", MinSelect: 1, MaxSelect: 1, Options: []models.ProductOption{
+			{ID: "regular", Name: "Regular"}, {ID: "large", Name: "Large", Price: 2},
+		}},
+		{ID: "toppings", Name: "Toppings", MaxSelect: 1, Options: []models.ProductOption{
+			{ID: "syrup", Name: "Maple syrup", Price: 0.5}, {ID: "cream", Name: "Whipped cream", Price: 0.75},
+		}},
+	}
+	selecting := func(ids ...string) models.OrderItem {
+		item := models.OrderItem{ProductID: "1", Quantity: 1}
+		for _, id := range ids {
+			item.Modifiers = append(item.Modifiers, models.ItemModifier{OptionID: id})
+		}
+		return item
+	}
+
+	tests := []struct {
+		name     string
+		item     models.OrderItem
+		groups   []models.OptionGroup
+		imported bool
+		want     []string
+		wantErr  bool
+	}{
+		{name: "required and optional", item: selecting("regular", "cream"), groups: groups, want: []string{"regular", "cream"}},
+		{name: "required only", item: selecting("large"), groups: groups, want: []string{"large"}},
+		{name: "product without groups", item: selecting(), want: nil},
+		{name: "required group missing", item: selecting("syrup"), groups: groups, wantErr: true},
+		{name: "required group missing on import", item: selecting(), groups: groups, imported: true, want: nil},
+		{name: "too many of a group", item: selecting("regular", "syrup", "cream"), groups: groups, wantErr: true},
+		{name: "too many of a group on import", item: selecting("regular", "large"), groups: groups, imported: true, wantErr: true},
+		{name: "unknown option", item: selecting("regular", "sprinkles"), groups: groups, wantErr: true},
+		{name: "option of a product without groups", item: selecting("large"), wantErr: true},
+		{name: "option selected twice", item: selecting("large", "syrup", "syrup"), groups: groups, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Test
+			modifiers, err := selectModifiers(tt.item, tt.groups, tt.imported)
+
+			// Assert
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrInvalidModifiers), "got %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			var got []string
+			for _, m := range modifiers {
+				got = append(got, m.OptionID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// expectNoOptionGroups expects the option groups of the ordered products
+// to be looked up, finding none
+func expectNoOptionGroups(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("FROM product_option_groups g").WillReturnRows(sqlmock.NewRows(nil))
+}
+
+// expectPaidOrderInsert expects the statements storing an order of two
+// waffles up to its payment
+func expectPaidOrderInsert(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 6.5, "Waffle", "", "", ""))
+	expectNoOptionGroups(mock)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, stock FROM products").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock"}).AddRow("1", nil))
+	mock.ExpectExec("UPDATE products SET stock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO order_items").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+}
+
+func TestOrderService_PlaceOrder_ChargesPayment(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+	payments := &recordingPayments{Mock: payment.NewMock()}
+	service.SetPaymentProvider(payments, "usd")
+
+	expectPaidOrderInsert(mock)
+	mock.ExpectExec("INSERT INTO order_payments").
+		WithArgs(sqlmock.AnyArg(), "mock", sqlmock.AnyArg(), "succeeded", int64(1300), "usd").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Test
+	order, err := service.PlaceOrder(context.Background(), models.OrderReq{
+		Items:         []models.OrderItem{{ProductID: "1", Quantity: 2}},
+		PaymentMethod: payment.MethodCardVisa,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	intent := payments.lastIntent(t)
+	assert.Equal(t, payment.StatusSucceeded, intent.Status)
+	assert.Equal(t, order.ID, intent.OrderID)
+	assert.Equal(t, &models.Payment{Provider: "mock", IntentID: intent.ID, Status: "succeeded", Amount: 1300, Currency: "usd"}, order.Payment)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_PlaceOrder_PaymentDeclined(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+	payments := &recordingPayments{Mock: payment.NewMock()}
+	service.SetPaymentProvider(payments, "usd")
+
+	expectPaidOrderInsert(mock)
+	mock.ExpectRollback()
+
+	// Test
+	_, err = service.PlaceOrder(context.Background(), models.OrderReq{
+		Items:         []models.OrderItem{{ProductID: "1", Quantity: 2}},
+		PaymentMethod: payment.MethodCardInsufficientFunds,
+	})
+
+	// Assert: the order is rolled back and the failed intent canceled
+	var declined *payment.DeclinedError
+	if assert.ErrorAs(t, err, &declined) {
+		assert.Equal(t, "insufficient_funds", declined.Code)
+	}
+	assert.Equal(t, payment.StatusCanceled, payments.lastIntent(t).Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_PlaceOrder_RefundsWhenCommitFails(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+	payments := &recordingPayments{Mock: payment.NewMock()}
+	service.SetPaymentProvider(payments, "usd")
+
+	expectPaidOrderInsert(mock)
+	mock.ExpectExec("INSERT INTO order_payments").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit().WillReturnError(errors.New("connection reset"))
+
+	// Test
+	_, err = service.PlaceOrder(context.Background(), models.OrderReq{Items: []models.OrderItem{{ProductID: "1", Quantity: 2}}})
+
+	// Assert: the charge is refunded since the order was not stored
+	assert.Error(t, err)
+	assert.Equal(t, payment.StatusRefunded, payments.lastIntent(t).Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_PlaceOrder_InvalidPaymentMethod(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+	payments := &recordingPayments{Mock: payment.NewMock()}
+	service.SetPaymentProvider(payments, "usd")
+
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 6.5, "Waffle", "", "", ""))
+	expectNoOptionGroups(mock)
+
+	// Test
+	_, err = service.PlaceOrder(context.Background(), models.OrderReq{
+		Items:         []models.OrderItem{{ProductID: "1", Quantity: 2}},
+		PaymentMethod: "4242424242424242",
+	})
+
+	// Assert: nothing is stored or charged
+	assert.ErrorIs(t, err, payment.ErrDeclined)
+	assert.Empty(t, payments.created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_GetOrder_ReturnsSnapshot(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(func(expected, actual string) error {
+		assert.NotContains(t, actual, "JOIN products", "order history must not be read from the live catalog")
+		return sqlmock.QueryMatcherRegexp.Match(expected, actual)
+	})))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	mock.ExpectQuery("SELECT id, coupon_code, status, COALESCE\\(total, 0\\), discount, COALESCE\\(customer_id, ''\\) FROM orders WHERE id = \\$1").
+		WithArgs("order-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coupon_code", "status", "total", "discount", "customer_id"}).AddRow("order-1", "", "pending", 13.0, 0.0, ""))
+	mock.ExpectQuery("SELECT oi.product_id, oi.quantity, oi.discount, oi.product_id, oi.product_name").
+		WithArgs("order-1").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "quantity", "discount", "id", "name", "category", "price", "tax_rate"}).
+			AddRow("1", 2, 0.0, "1", "Belgian Waffle", "Waffle", 6.5, 0.0825))
+	mock.ExpectQuery("FROM order_item_modifiers m").WillReturnRows(sqlmock.NewRows(nil))
+	mock.ExpectQuery("FROM order_addresses WHERE order_id = \\$1").
+		WithArgs("order-1").
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("FROM order_payments WHERE order_id = \\$1").
+		WithArgs("order-1").
+		WillReturnError(sql.ErrNoRows)
+
+	// Test
+	order, err := service.GetOrder("order-1")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Product{{ID: "1", Name: "Belgian Waffle", Category: "Waffle", Price: 6.5, TaxRate: 0.0825}}, order.Products)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_GetOrder_ReturnsDelivery(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	mock.ExpectQuery("FROM orders WHERE id = \\$1").
+		WithArgs("order-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coupon_code", "status", "total", "discount", "customer_id"}).AddRow("order-1", "", "pending", 13.0, 0.0, ""))
+	mock.ExpectQuery("SELECT oi.product_id").
+		WithArgs("order-1").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "quantity", "discount", "id", "name", "category", "price", "tax_rate"}))
+	mock.ExpectQuery("FROM order_item_modifiers m").WillReturnRows(sqlmock.NewRows(nil))
+	mock.ExpectQuery("FROM order_addresses WHERE order_id = \\$1").
+		WithArgs("order-1").
+		WillReturnRows(sqlmock.NewRows([]string{"city", "region", "postal_code", "country", "line1", "line2", "contact_name", "contact_phone", "contact_email", "instructions"}).
+			AddRow("Berlin", "", "10115", "DE", "Invalidenstr. 1", "", "Ada Lovelace", "+49 30 1234567", "ada@example.com", ""))
+	mock.ExpectQuery("FROM order_payments WHERE order_id = \\$1").
+		WithArgs("order-1").
+		WillReturnError(sql.ErrNoRows)
+
+	// Test
+	order, err := service.GetOrder("order-1")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Delivery{
+		Address: models.DeliveryAddress{Line1: "Invalidenstr. 1", City: "Berlin", PostalCode: "10115", Country: "DE"},
+		Contact: models.DeliveryContact{Name: "Ada Lovelace", Phone: "+49 30 1234567", Email: "ada@example.com"},
+	}, order.Delivery)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_ListOrdersPaginated_Sorted(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM orders").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("FROM orders ORDER BY total DESC, created_at ASC, id ASC LIMIT \\$1 OFFSET \\$2").
+		WithArgs(10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coupon_code", "status", "total", "discount", "customer_id"}))
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	// Test
+	orders, total, err := service.ListOrdersPaginated(context.Background(), 10, 0, []models.SortField{{Field: "total", Desc: true}, {Field: "createdAt"}}, models.OrderFilter{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+	assert.Equal(t, 0, total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_ListOrdersPaginated_CustomerHistory(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM orders WHERE customer_id = \\$1 AND status = \\$2 AND created_at >= \\$3 AND created_at < \\$4").
+		WithArgs("c1", models.OrderStatusCompleted, from, to).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("FROM orders WHERE customer_id = \\$1 AND status = \\$2 AND created_at >= \\$3 AND created_at < \\$4 ORDER BY created_at DESC LIMIT \\$5 OFFSET \\$6").
+		WithArgs("c1", models.OrderStatusCompleted, from, to, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coupon_code", "status", "total", "discount", "customer_id"}).
+			AddRow("order-1", "", "completed", 12.5, 0.0, "c1"))
+	mock.ExpectQuery("FROM order_items").WillReturnRows(sqlmock.NewRows(nil))
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	// Test
+	orders, total, err := service.ListOrdersPaginated(context.Background(), 10, 0, nil,
+		models.OrderFilter{CustomerID: "c1", Status: models.OrderStatusCompleted, From: from, To: to})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, "c1", orders[0].CustomerID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_ListOrdersAfter_CouponAndProduct(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	columns := []string{"id", "coupon_code", "status", "total", "discount", "customer_id", "key"}
+	mock.ExpectQuery("FROM orders WHERE coupon_code = \\$1 AND EXISTS \\(SELECT 1 FROM order_items oi WHERE oi.order_id = orders.id AND oi.product_id = \\$2\\) ORDER BY created_at DESC, id DESC LIMIT \\$3").
+		WithArgs("HAPPYHRS", "1", 11).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("order-1", "HAPPYHRS", "completed", 12.5, 1.25, "", `["2024-03-01T12:00:01+00:00", "order-1"]`))
+	mock.ExpectQuery("FROM order_items").WillReturnRows(sqlmock.NewRows(nil))
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	// Test
+	orders, next, err := service.ListOrdersAfter(context.Background(), "", 10, nil, models.OrderFilter{CouponCode: "HAPPYHRS", ProductID: "1"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, next)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, "HAPPYHRS", orders[0].CouponCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_StreamOrders(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// Orders are read in keyset pages of 500, one more telling whether
+	// another page follows
+	columns := []string{"id", "coupon_code", "status", "total", "discount", "customer_id", "key"}
+	mock.ExpectQuery("FROM orders WHERE status = \\$1 ORDER BY created_at DESC, id DESC LIMIT \\$2").
+		WithArgs(models.OrderStatusPending, 501).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("order-2", "", "pending", 20.0, 0.0, "", `["2024-03-01T12:00:02+00:00", "order-2"]`).
+			AddRow("order-1", "", "pending", 30.0, 0.0, "", `["2024-03-01T12:00:01+00:00", "order-1"]`))
+	mock.ExpectQuery("FROM order_items").WillReturnRows(sqlmock.NewRows(nil))
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	// Test
+	var ids []string
+	err = service.StreamOrders(context.Background(), nil, models.OrderFilter{Status: models.OrderStatusPending}, func(order models.Order) error {
+		ids = append(ids, order.ID)
+		return nil
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"order-2", "order-1"}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_ListOrdersAfter(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	columns := []string{"id", "coupon_code", "status", "total", "discount", "customer_id", "key"}
+	mock.ExpectQuery("FROM orders ORDER BY created_at DESC, id DESC LIMIT \\$1").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("order-3", "", "pending", 10.0, 0.0, "", `["2024-03-01T12:00:03+00:00", "order-3"]`).
+			AddRow("order-2", "", "pending", 20.0, 0.0, "", `["2024-03-01T12:00:02+00:00", "order-2"]`).
+			AddRow("order-1", "", "pending", 30.0, 0.0, "", `["2024-03-01T12:00:01+00:00", "order-1"]`))
+	mock.ExpectQuery("FROM order_items").WillReturnRows(sqlmock.NewRows(nil))
+	// The next page continues after the last order of the first
+	mock.ExpectQuery("FROM orders WHERE \\(created_at, id\\) < \\(\\$1, \\$2\\) ORDER BY created_at DESC, id DESC LIMIT \\$3").
+		WithArgs("2024-03-01T12:00:02+00:00", "order-2", 3).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("order-1", "", "pending", 30.0, 0.0, "", `["2024-03-01T12:00:01+00:00", "order-1"]`))
+	mock.ExpectQuery("FROM order_items").WillReturnRows(sqlmock.NewRows(nil))
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	// Test
+	first, next, err := service.ListOrdersAfter(context.Background(), "", 2, nil, models.OrderFilter{})
+	assert.NoError(t, err)
+	second, last, err := service.ListOrdersAfter(context.Background(), next, 2, nil, models.OrderFilter{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, first, 2)
+	assert.NotEmpty(t, next)
+	assert.Equal(t, "order-1", second[0].ID)
+	assert.Empty(t, last, "the last page has no next cursor")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_ListOrdersAfter_CursorOfOtherSort(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	columns := []string{"id", "coupon_code", "status", "total", "discount", "customer_id", "key"}
+	mock.ExpectQuery("FROM orders ORDER BY COALESCE\\(total, 0\\) DESC, id DESC LIMIT \\$1").
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("order-3", "", "pending", 30.0, 0.0, "", `[30.00, "order-3"]`).
+			AddRow("order-2", "", "pending", 20.0, 0.0, "", `[20.00, "order-2"]`).
+			AddRow("order-1", "", "pending", 10.0, 0.0, "", `[10.00, "order-1"]`))
+	mock.ExpectQuery("FROM order_items").WillReturnRows(sqlmock.NewRows(nil))
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+	_, next, err := service.ListOrdersAfter(context.Background(), "", 2, []models.SortField{{Field: "total", Desc: true}}, models.OrderFilter{})
+	assert.NoError(t, err)
+
+	// Test
+	_, _, err = service.ListOrdersAfter(context.Background(), next, 2, []models.SortField{{Field: "status"}}, models.OrderFilter{})
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_UpdateOrderStatus(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	expectOrder(mock, "order-1", models.OrderStatusConfirmed)
+	mock.ExpectExec("UPDATE orders SET status").
+		WithArgs("order-1", models.OrderStatusConfirmed, models.OrderStatusPreparing).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Test
+	order, err := service.UpdateOrderStatus(context.Background(), "order-1", models.OrderStatusPreparing)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusPreparing, order.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_UpdateOrderStatus_PublishesEvent(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+	publisher := &recordingPublisher{}
+	service.SetEventPublisher(publisher)
+
+	expectOrder(mock, "order-1", models.OrderStatusConfirmed)
+	mock.ExpectExec("UPDATE orders SET status").
+		WithArgs("order-1", models.OrderStatusConfirmed, models.OrderStatusPreparing).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Test
+	_, err = service.UpdateOrderStatus(context.Background(), "order-1", models.OrderStatusPreparing)
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, publisher.events, 1) {
+		assert.Equal(t, models.EventTypeOrderStatusChanged, publisher.events[0].Type)
+		assert.Equal(t, "order-1", publisher.events[0].Key)
+		assert.Equal(t, models.OrderStatusChanged{ID: "order-1", From: models.OrderStatusConfirmed, To: models.OrderStatusPreparing}, publisher.events[0].Data)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_UpdateOrderStatus_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		current models.OrderStatus
+		status  models.OrderStatus
+		wantErr error
+	}{
+		{name: "skips a stage", current: models.OrderStatusPending, status: models.OrderStatusPreparing, wantErr: ErrInvalidStatusTransition},
+		{name: "moves backwards", current: models.OrderStatusPreparing, status: models.OrderStatusConfirmed, wantErr: ErrInvalidStatusTransition},
+		{name: "cancels completed order", current: models.OrderStatusCompleted, status: models.OrderStatusCancelled, wantErr: ErrInvalidStatusTransition},
+		{name: "reopens cancelled order", current: models.OrderStatusCancelled, status: models.OrderStatusPending, wantErr: ErrInvalidStatusTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+			expectOrder(mock, "order-1", tt.current)
+
+			// Test
+			_, err = service.UpdateOrderStatus(context.Background(), "order-1", tt.status)
+
+			// Assert
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOrderService_UpdateOrderStatus_SameStatus(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+	expectOrder(mock, "order-1", models.OrderStatusCompleted)
+
+	// Test
+	order, err := service.UpdateOrderStatus(context.Background(), "order-1", models.OrderStatusCompleted)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusCompleted, order.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_UpdateOrderStatus_UnknownStatus(t *testing.T) {
+	// Setup
+	service := NewOrderService(nil, nil, nil, nil, nil, nil)
+
+	// Test
+	_, err := service.UpdateOrderStatus(context.Background(), "order-1", "shipped")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrInvalidOrderStatus))
+}
+
+func TestOrderService_UpdateOrderStatus_ConcurrentChange(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+	expectOrder(mock, "order-1", models.OrderStatusPending)
+	mock.ExpectExec("UPDATE orders SET status").
+		WithArgs("order-1", models.OrderStatusPending, models.OrderStatusConfirmed).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("order-1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	// Test
+	_, err = service.UpdateOrderStatus(context.Background(), "order-1", models.OrderStatusConfirmed)
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrOrderStatusConflict))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// expectOrder expects GetByID to read an order with one item in status
+func expectOrder(mock sqlmock.Sqlmock, id string, status models.OrderStatus) {
+	mock.ExpectQuery("SELECT id, coupon_code, status, COALESCE\\(total, 0\\), discount, COALESCE\\(customer_id, ''\\) FROM orders WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "coupon_code", "status", "total", "discount", "customer_id"}).AddRow(id, "", status, 13.0, 0.0, ""))
+	mock.ExpectQuery("SELECT oi.product_id").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "quantity", "discount", "id", "name", "category", "price", "tax_rate"}).
+			AddRow("1", 2, 0.0, "1", "Waffle", "Waffle", 6.5, 0.0))
+	mock.ExpectQuery("FROM order_item_modifiers m").WillReturnRows(sqlmock.NewRows(nil))
+	mock.ExpectQuery("FROM order_addresses WHERE order_id = \\$1").
+		WithArgs(id).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("FROM order_payments WHERE order_id = \\$1").
+		WithArgs(id).
+		WillReturnError(sql.ErrNoRows)
+}
+
+func TestOrderService_ImportPOSOrder_LostRaceRollsBack(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	mock.ExpectQuery("SELECT order_id FROM pos_order_imports").
+		WithArgs("T-1").
+		WillReturnRows(sqlmock.NewRows([]string{"order_id"}))
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 6.5, "Waffle", "", "", ""))
+	expectNoOptionGroups(mock)
+	// The stock taken and the order stored are undone with the ticket claim
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, stock FROM products").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock"}).AddRow("1", 5))
+	mock.ExpectQuery("SELECT product_id, SUM\\(quantity\\) FROM stock_reservations").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "sum"}))
+	mock.ExpectExec("UPDATE products SET stock").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO order_items").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO pos_order_imports").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	// The order of the import that won is returned instead
+	mock.ExpectQuery("SELECT order_id FROM pos_order_imports").
+		WithArgs("T-1").
+		WillReturnRows(sqlmock.NewRows([]string{"order_id"}).AddRow("order-0"))
+	expectOrder(mock, "order-0", models.OrderStatusPending)
+
+	// Test
+	order, created, err := service.ImportPOSOrder(context.Background(), "T-1", models.OrderReq{Items: []models.OrderItem{{ProductID: "1", Quantity: 2}}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "order-0", order.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+// OrderVolumeConfig tunes the order volume anomaly detector
+type OrderVolumeConfig struct {
+	// Window is how far back the current order count reaches
+	Window time.Duration
+	// BaselineDays is how many previous days the same window is counted on
+	// to work out the expected volume
+	BaselineDays int
+	// DropPercent is how far below the expected volume, in percent, the
+	// current count must fall to be reported as a drop
+	DropPercent int
+	// MinExpected is the fewest orders expected in a window for a drop to
+	// be reported; quieter windows are too noisy to judge
+	MinExpected int
+}
+
+// DefaultOrderVolumeConfig reports a drop when the last 15 minutes brought
+// in under half the orders of the same 15 minutes on a typical day of the
+// past week
+var DefaultOrderVolumeConfig = OrderVolumeConfig{
+	Window:       15 * time.Minute,
+	BaselineDays: 7,
+	DropPercent:  50,
+	MinExpected:  10,
+}
+
+// OrderVolumeService compares recent order volume with the same time on
+// previous days to catch checkouts that silently stopped working. Each
+// replica runs its own checks; they only read from the database.
+type OrderVolumeService struct {
+	repo *repository.OrderRepository
+	cfg  OrderVolumeConfig
+	now  func() time.Time
+
+	mu      sync.Mutex
+	latest  *models.OrderVolume
+	alerts  int64
+	failure bool
+}
+
+// NewOrderVolumeService creates a new order volume service
+func NewOrderVolumeService(repo *repository.OrderRepository, cfg OrderVolumeConfig) *OrderVolumeService {
+	return &OrderVolumeService{repo: repo, cfg: cfg, now: time.Now}
+}
+
+// Check counts the orders of the latest window, compares them with the
+// baseline and returns the result. A drop is logged when it starts and
+// when it ends.
+func (s *OrderVolumeService) Check(ctx context.Context) (models.OrderVolume, error) {
+	now := s.now()
+	counts, err := s.repo.CountInWindows(ctx, now.Add(-s.cfg.Window), now, s.cfg.BaselineDays)
+	if err != nil {
+		s.mu.Lock()
+		s.failure = true
+		s.mu.Unlock()
+		return models.OrderVolume{}, err
+	}
+
+	minutes := s.cfg.Window.Minutes()
+	expected := median(counts[1:])
+	volume := models.OrderVolume{
+		Status:            models.OrderVolumeNormal,
+		Window:            s.cfg.Window.String(),
+		CurrentOrders:     counts[0],
+		ExpectedOrders:    expected,
+		CurrentPerMinute:  math.Round(float64(counts[0])/minutes*100) / 100,
+		ExpectedPerMinute: math.Round(expected/minutes*100) / 100,
+		BaselineDays:      s.cfg.BaselineDays,
+		CheckedAt:         now,
+	}
+	threshold := expected * float64(100-s.cfg.DropPercent) / 100
+	switch {
+	case expected < float64(s.cfg.MinExpected):
+		volume.Status = models.OrderVolumeQuiet
+	case float64(volume.CurrentOrders) < threshold:
+		volume.Status = models.OrderVolumeDrop
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failure = false
+	previous := s.latest
+	if volume.Status == models.OrderVolumeDrop {
+		if previous != nil && previous.DropSince != nil {
+			volume.DropSince = previous.DropSince
+		} else {
+			volume.DropSince = &now
+			s.alerts++
+			slog.Warn("ALERT: order volume drop", "orders", volume.CurrentOrders, "window", volume.Window,
+				"expected", volume.ExpectedOrders)
+		}
+	} else if previous != nil && previous.DropSince != nil {
+		slog.Info("Order volume recovered", "after", now.Sub(*previous.DropSince).Round(time.Second),
+			"orders", volume.CurrentOrders, "window", volume.Window, "expected", volume.ExpectedOrders)
+	}
+	s.latest = &volume
+	return volume, nil
+}
+
+// Current returns the latest check, running one if there has been none
+func (s *OrderVolumeService) Current(ctx context.Context) (models.OrderVolume, error) {
+	s.mu.Lock()
+	latest := s.latest
+	s.mu.Unlock()
+	if latest != nil {
+		return *latest, nil
+	}
+	return s.Check(ctx)
+}
+
+// Run checks the order volume every interval until ctx is cancelled
+func (s *OrderVolumeService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Check(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("Failed to check order volume", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Descriptors of the order volume metrics
+var (
+	orderVolumeCurrentDesc     = prometheus.NewDesc("order_volume_current_orders", "Orders placed in the latest window.", nil, nil)
+	orderVolumeExpectedDesc    = prometheus.NewDesc("order_volume_expected_orders", "Median orders placed in the same window on previous days.", nil, nil)
+	orderVolumeDropDesc        = prometheus.NewDesc("order_volume_drop", "1 while far fewer orders arrive than expected.", nil, nil)
+	orderVolumeCheckFailedDesc = prometheus.NewDesc("order_volume_check_failed", "1 when the latest order volume check could not read the orders.", nil, nil)
+	orderVolumeAlertsDesc      = prometheus.NewDesc("order_volume_alerts_total", "Order volume drops detected by this instance.", nil, nil)
+)
+
+// Describe sends the descriptors of the order volume metrics
+func (s *OrderVolumeService) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{orderVolumeCurrentDesc, orderVolumeExpectedDesc, orderVolumeDropDesc, orderVolumeCheckFailedDesc, orderVolumeAlertsDesc} {
+		ch <- desc
+	}
+}
+
+// Collect sends the latest check. Nothing is sent before the first check.
+func (s *OrderVolumeService) Collect(ch chan<- prometheus.Metric) {
+	s.mu.Lock()
+	latest, alerts, failure := s.latest, s.alerts, s.failure
+	s.mu.Unlock()
+	if latest == nil {
+		return
+	}
+
+	drop, checkFailed := 0.0, 0.0
+	if latest.Status == models.OrderVolumeDrop {
+		drop = 1
+	}
+	if failure {
+		checkFailed = 1
+	}
+
+	ch <- prometheus.MustNewConstMetric(orderVolumeCurrentDesc, prometheus.GaugeValue, float64(latest.CurrentOrders))
+	ch <- prometheus.MustNewConstMetric(orderVolumeExpectedDesc, prometheus.GaugeValue, latest.ExpectedOrders)
+	ch <- prometheus.MustNewConstMetric(orderVolumeDropDesc, prometheus.GaugeValue, drop)
+	ch <- prometheus.MustNewConstMetric(orderVolumeCheckFailedDesc, prometheus.GaugeValue, checkFailed)
+	ch <- prometheus.MustNewConstMetric(orderVolumeAlertsDesc, prometheus.CounterValue, float64(alerts))
+}
+
+// median returns the middle value of counts, or 0 when there are none. A
+// day with an outage or a promotion does not skew it the way it would
+// skew the mean.
+func median(counts []int) float64 {
+	if len(counts) == 0 {
+		return 0
+	}
+	sorted := append([]int(nil), counts...)
+	sort.Ints(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return float64(sorted[mid])
+	}
+	return float64(sorted[mid-1]+sorted[mid]) / 2
+}
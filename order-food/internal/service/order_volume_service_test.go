@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// expectOrderCounts expects one count query returning counts by day offset
+func expectOrderCounts(mock sqlmock.Sqlmock, counts ...int) {
+	rows := sqlmock.NewRows([]string{"d", "count"})
+	for day, count := range counts {
+		rows.AddRow(day, count)
+	}
+	mock.ExpectQuery("SELECT d, COUNT\\(o.id\\)").WillReturnRows(rows)
+}
+
+func TestOrderVolumeService_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		counts     []int
+		wantStatus string
+		wantExpect float64
+	}{
+		{name: "normal", counts: []int{40, 45, 50, 48, 52, 47, 0, 49}, wantStatus: models.OrderVolumeNormal, wantExpect: 48},
+		{name: "drop", counts: []int{5, 45, 50, 48, 52, 47, 51, 49}, wantStatus: models.OrderVolumeDrop, wantExpect: 49},
+		{name: "quiet", counts: []int{0, 2, 1, 3, 0, 2, 1, 4}, wantStatus: models.OrderVolumeQuiet, wantExpect: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			service := NewOrderVolumeService(repository.NewOrderRepository(db), DefaultOrderVolumeConfig)
+			expectOrderCounts(mock, tt.counts...)
+
+			// Test
+			volume, err := service.Check(context.Background())
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, volume.Status)
+			assert.Equal(t, tt.counts[0], volume.CurrentOrders)
+			assert.Equal(t, tt.wantExpect, volume.ExpectedOrders)
+			assert.Equal(t, "15m0s", volume.Window)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestOrderVolumeService_Check_KeepsDropStart(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderVolumeService(repository.NewOrderRepository(db), DefaultOrderVolumeConfig)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	service.now = func() time.Time { return now }
+	expectOrderCounts(mock, 5, 50, 50, 50, 50, 50, 50, 50)
+	expectOrderCounts(mock, 4, 50, 50, 50, 50, 50, 50, 50)
+	expectOrderCounts(mock, 45, 50, 50, 50, 50, 50, 50, 50)
+
+	// Test
+	first, err := service.Check(context.Background())
+	assert.NoError(t, err)
+	now = start.Add(time.Minute)
+	second, err := service.Check(context.Background())
+	assert.NoError(t, err)
+	now = start.Add(2 * time.Minute)
+	recovered, err := service.Check(context.Background())
+	assert.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, &start, first.DropSince)
+	assert.Equal(t, &start, second.DropSince)
+	assert.Nil(t, recovered.DropSince)
+	assert.NoError(t, testutil.CollectAndCompare(service, strings.NewReader(`
+# HELP order_volume_alerts_total Order volume drops detected by this instance.
+# TYPE order_volume_alerts_total counter
+order_volume_alerts_total 1
+# HELP order_volume_drop 1 while far fewer orders arrive than expected.
+# TYPE order_volume_drop gauge
+order_volume_drop 0
+# HELP order_volume_expected_orders Median orders placed in the same window on previous days.
+# TYPE order_volume_expected_orders gauge
+order_volume_expected_orders 50
+`), "order_volume_alerts_total", "order_volume_drop", "order_volume_expected_orders"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
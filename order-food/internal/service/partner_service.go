@@ -0,0 +1,225 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+const (
+	// partnerKeyPrefix marks partner keys so they are recognisable in logs and secret scanners
+	partnerKeyPrefix = "pk_"
+	// partnerKeyBytes is the amount of randomness in a partner key
+	partnerKeyBytes = 32
+	// partnerKeyDisplayLength is how much of a key is stored in clear for identification
+	partnerKeyDisplayLength = 11
+)
+
+// Partner API scopes
+const (
+	ScopeOrdersRead   = "orders:read"
+	ScopeOrdersWrite  = "orders:write"
+	ScopeOrdersImport = "orders:import"
+)
+
+// PartnerScopes lists the scopes a partner may request
+var PartnerScopes = []string{ScopeOrdersRead, ScopeOrdersWrite, ScopeOrdersImport}
+
+// DefaultKeyRotationGrace is how long a replaced key keeps working after rotation
+const DefaultKeyRotationGrace = 24 * time.Hour
+
+var (
+	// ErrPartnerNotFound is returned when a partner does not exist
+	ErrPartnerNotFound = repository.ErrPartnerNotFound
+	// ErrInvalidScope is returned when a partner requests an unknown scope
+	ErrInvalidScope = errors.New("invalid scope")
+	// ErrPartnerNotApproved is returned when an action requires an approved partner
+	ErrPartnerNotApproved = errors.New("partner is not approved")
+	// ErrInvalidPartnerTransition is returned for approval changes that are not allowed
+	ErrInvalidPartnerTransition = errors.New("invalid partner status transition")
+)
+
+// PartnerService handles partner onboarding and partner API keys
+type PartnerService struct {
+	repo          *repository.PartnerRepository
+	rotationGrace time.Duration
+}
+
+// NewPartnerService creates a new partner service. Keys replaced by a rotation
+// keep working for rotationGrace so partners can roll the new key out.
+func NewPartnerService(repo *repository.PartnerRepository, rotationGrace time.Duration) *PartnerService {
+	if rotationGrace < 0 {
+		rotationGrace = 0
+	}
+	return &PartnerService{repo: repo, rotationGrace: rotationGrace}
+}
+
+// RegisterPartner creates a pending partner and issues its first API key.
+// The key only starts working once an admin approves the partner.
+func (s *PartnerService) RegisterPartner(req models.PartnerReq) (models.PartnerRegistration, error) {
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return models.PartnerRegistration{}, err
+	}
+
+	key, prefix, hash, err := generatePartnerKey()
+	if err != nil {
+		return models.PartnerRegistration{}, err
+	}
+
+	partner := models.Partner{
+		ID:           uuid.New().String(),
+		Name:         strings.TrimSpace(req.Name),
+		ContactEmail: strings.TrimSpace(req.ContactEmail),
+		Status:       models.PartnerStatusPending,
+		Scopes:       scopes,
+	}
+	if err := s.repo.CreateWithKey(&partner, prefix, hash); err != nil {
+		return models.PartnerRegistration{}, err
+	}
+
+	return models.PartnerRegistration{
+		Partner: partner,
+		APIKey: models.IssuedAPIKey{
+			Key:       key,
+			Prefix:    prefix,
+			PartnerID: partner.ID,
+			CreatedAt: partner.CreatedAt,
+		},
+	}, nil
+}
+
+// ListPartners returns partners, optionally filtered by status
+func (s *PartnerService) ListPartners(status string, limit, offset int) ([]models.Partner, int, error) {
+	return s.repo.GetAll(status, limit, offset)
+}
+
+// SetPartnerStatus applies an admin approval decision to a partner
+func (s *PartnerService) SetPartnerStatus(id, status string) (models.Partner, error) {
+	partner, err := s.repo.GetByID(id)
+	if err != nil {
+		return models.Partner{}, err
+	}
+
+	if !partnerTransitionAllowed(partner.Status, status) {
+		return models.Partner{}, fmt.Errorf("%w: %s to %s", ErrInvalidPartnerTransition, partner.Status, status)
+	}
+
+	if err := s.repo.UpdateStatus(id, status); err != nil {
+		return models.Partner{}, err
+	}
+	partner.Status = status
+
+	return partner, nil
+}
+
+// RotatePartnerKey issues a new key for an approved partner. Existing keys
+// expire after the configured grace period.
+func (s *PartnerService) RotatePartnerKey(id string) (models.IssuedAPIKey, error) {
+	partner, err := s.repo.GetByID(id)
+	if err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+	if partner.Status != models.PartnerStatusApproved {
+		return models.IssuedAPIKey{}, ErrPartnerNotApproved
+	}
+
+	key, prefix, hash, err := generatePartnerKey()
+	if err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+
+	retireAt := time.Now().Add(s.rotationGrace).UTC()
+	createdAt, err := s.repo.RotateKey(id, prefix, hash, retireAt)
+	if err != nil {
+		return models.IssuedAPIKey{}, err
+	}
+
+	return models.IssuedAPIKey{
+		Key:                  key,
+		Prefix:               prefix,
+		PartnerID:            id,
+		CreatedAt:            createdAt,
+		PreviousKeysExpireAt: &retireAt,
+	}, nil
+}
+
+// VerifyAPIKey resolves a partner API key to the partner ID and its scopes.
+// The boolean is false for unknown, expired or unapproved keys.
+func (s *PartnerService) VerifyAPIKey(key string) (string, []string, bool) {
+	if !strings.HasPrefix(key, partnerKeyPrefix) {
+		return "", nil, false
+	}
+
+	partner, err := s.repo.FindByKeyHash(hashPartnerKey(key))
+	if err != nil || partner.Status != models.PartnerStatusApproved {
+		return "", nil, false
+	}
+
+	return partner.ID, partner.Scopes, true
+}
+
+// partnerTransitionAllowed reports whether an admin may move a partner between states
+func partnerTransitionAllowed(from, to string) bool {
+	switch to {
+	case models.PartnerStatusApproved:
+		return from == models.PartnerStatusPending || from == models.PartnerStatusSuspended
+	case models.PartnerStatusRejected:
+		return from == models.PartnerStatusPending
+	case models.PartnerStatusSuspended:
+		return from == models.PartnerStatusApproved
+	}
+	return false
+}
+
+// normalizeScopes validates requested scopes and removes duplicates
+func normalizeScopes(requested []string) ([]string, error) {
+	seen := make(map[string]bool, len(requested))
+	scopes := make([]string, 0, len(requested))
+
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		valid := false
+		for _, allowed := range PartnerScopes {
+			if scope == allowed {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidScope, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes, nil
+}
+
+// generatePartnerKey returns a new random key with its display prefix and hash
+func generatePartnerKey() (key, prefix, hash string, err error) {
+	buf := make([]byte, partnerKeyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	key = partnerKeyPrefix + hex.EncodeToString(buf)
+	return key, key[:partnerKeyDisplayLength], hashPartnerKey(key), nil
+}
+
+// hashPartnerKey returns the hex SHA-256 of a key. Keys carry 256 bits of
+// randomness, so a fast unsalted hash is sufficient for lookup.
+func hashPartnerKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeScopes(t *testing.T) {
+	// Execute
+	scopes, err := normalizeScopes([]string{" orders:read", "orders:write", "orders:read"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"orders:read", "orders:write"}, scopes)
+
+	// Execute - unknown scope
+	_, err = normalizeScopes([]string{"orders:read", "admin"})
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrInvalidScope))
+}
+
+func TestPartnerTransitionAllowed(t *testing.T) {
+	assert.True(t, partnerTransitionAllowed(models.PartnerStatusPending, models.PartnerStatusApproved))
+	assert.True(t, partnerTransitionAllowed(models.PartnerStatusPending, models.PartnerStatusRejected))
+	assert.True(t, partnerTransitionAllowed(models.PartnerStatusApproved, models.PartnerStatusSuspended))
+	assert.True(t, partnerTransitionAllowed(models.PartnerStatusSuspended, models.PartnerStatusApproved))
+	assert.False(t, partnerTransitionAllowed(models.PartnerStatusRejected, models.PartnerStatusApproved))
+	assert.False(t, partnerTransitionAllowed(models.PartnerStatusApproved, models.PartnerStatusRejected))
+	assert.False(t, partnerTransitionAllowed(models.PartnerStatusApproved, models.PartnerStatusPending))
+}
+
+func TestGeneratePartnerKey(t *testing.T) {
+	// Execute
+	key, prefix, hash, err := generatePartnerKey()
+	other, _, _, _ := generatePartnerKey()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, partnerKeyPrefix))
+	assert.True(t, strings.HasPrefix(key, prefix))
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hashPartnerKey(key), hash)
+	assert.NotEqual(t, key, other)
+}
+
+func TestVerifyAPIKey_RejectsForeignKeysWithoutLookup(t *testing.T) {
+	// Setup - a nil repository would panic if it were queried
+	svc := NewPartnerService(nil, DefaultKeyRotationGrace)
+
+	// Execute
+	_, _, ok := svc.VerifyAPIKey("apitest")
+
+	// Assert
+	assert.False(t, ok)
+}
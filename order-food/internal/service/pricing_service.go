@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+// PriceRuleError lists the problems found in the rules of a bulk price
+// update; nothing is previewed or applied when it is returned
+type PriceRuleError struct {
+	Diagnostics []models.FieldError
+}
+
+func (e *PriceRuleError) Error() string {
+	return fmt.Sprintf("invalid price rules: %d problems", len(e.Diagnostics))
+}
+
+// PricingService changes product prices in bulk
+type PricingService struct {
+	repo *repository.ProductRepository
+	// events publishes repriced products when non-nil
+	events EventPublisher
+}
+
+// NewPricingService creates a new pricing service
+func NewPricingService(repo *repository.ProductRepository) *PricingService {
+	return &PricingService{repo: repo}
+}
+
+// SetEventPublisher makes the service publish a ProductUpdated event for
+// every product an applied update reprices
+func (s *PricingService) SetEventPublisher(events EventPublisher) {
+	s.events = events
+}
+
+// BulkUpdatePrices computes the price changes of req and, when req.Apply is
+// set, applies them on behalf of actor. Products whose price would not
+// change are left out. A *PriceRuleError is returned for invalid rules and
+// repository.ErrPriceConflict when prices changed while applying.
+func (s *PricingService) BulkUpdatePrices(ctx context.Context, req models.BulkPriceReq, actor string) (models.BulkPriceResult, error) {
+	var diagnostics []models.FieldError
+	var categories, ids []string
+	for i, rule := range req.Rules {
+		field := fmt.Sprintf("rules[%d]", i)
+		if rule.Category == "" && len(rule.ProductIDs) == 0 {
+			diagnostics = append(diagnostics, models.FieldError{Field: field, Message: "category or productIds is required"})
+		}
+		switch {
+		case (rule.Percent == 0) == (rule.Amount == 0):
+			diagnostics = append(diagnostics, models.FieldError{Field: field, Message: "exactly one of percent and amount is required"})
+		case rule.Percent <= -100:
+			diagnostics = append(diagnostics, models.FieldError{Field: field + ".percent", Message: "percent must be greater than -100"})
+		}
+		if rule.Category != "" {
+			categories = append(categories, rule.Category)
+		}
+		ids = append(ids, rule.ProductIDs...)
+	}
+	if len(diagnostics) > 0 {
+		return models.BulkPriceResult{}, &PriceRuleError{Diagnostics: diagnostics}
+	}
+
+	products, err := s.repo.GetByCategoriesOrIDs(categories, ids)
+	if err != nil {
+		return models.BulkPriceResult{}, err
+	}
+	found := make(map[string]bool, len(products))
+	for _, p := range products {
+		found[p.ID] = true
+	}
+	for i, rule := range req.Rules {
+		for _, id := range rule.ProductIDs {
+			if !found[id] {
+				diagnostics = append(diagnostics, models.FieldError{
+					Field:   fmt.Sprintf("rules[%d].productIds", i),
+					Message: "product not found: " + id,
+				})
+			}
+		}
+	}
+
+	changes := make([]models.PriceChange, 0, len(products))
+	for _, p := range products {
+		price := p.Price
+		for _, rule := range req.Rules {
+			if ruleSelects(rule, p) {
+				price = price*(1+rule.Percent/100) + rule.Amount
+			}
+		}
+		price = math.Round(price*100) / 100
+		if price < 0 {
+			diagnostics = append(diagnostics, models.FieldError{
+				Field:   "rules",
+				Message: fmt.Sprintf("price of %s would be negative", p.ID),
+			})
+			continue
+		}
+		if price != p.Price {
+			changes = append(changes, models.PriceChange{
+				ProductID: p.ID,
+				Name:      p.Name,
+				Category:  p.Category,
+				OldPrice:  p.Price,
+				NewPrice:  price,
+			})
+		}
+	}
+	if len(diagnostics) > 0 {
+		return models.BulkPriceResult{}, &PriceRuleError{Diagnostics: diagnostics}
+	}
+
+	result := models.BulkPriceResult{Changes: changes}
+	if !req.Apply || len(changes) == 0 {
+		return result, nil
+	}
+
+	auditID, err := s.repo.UpdatePrices(changes, models.AuditEntry{
+		Action: models.AuditActionBulkPrice,
+		Actor:  actor,
+		Details: map[string]any{
+			"rules":   req.Rules,
+			"reason":  req.Reason,
+			"changes": changes,
+		},
+	})
+	if err != nil {
+		return models.BulkPriceResult{}, err
+	}
+	result.Applied = true
+	result.AuditID = auditID
+
+	byID := make(map[string]models.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+	for _, change := range changes {
+		product := byID[change.ProductID]
+		product.Price = change.NewPrice
+		publishEvent(ctx, s.events, models.EventTypeProductUpdated, product.ID, productUpdated(models.ProductChangeUpdated, product))
+	}
+
+	return result, nil
+}
+
+// ruleSelects reports whether rule applies to product
+func ruleSelects(rule models.PriceRule, product models.Product) bool {
+	if rule.Category != "" && rule.Category == product.Category {
+		return true
+	}
+	for _, id := range rule.ProductIDs {
+		if id == product.ID {
+			return true
+		}
+	}
+	return false
+}
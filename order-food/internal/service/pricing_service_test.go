@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+var productRowColumns = []string{"id", "name", "price", "category", "sku", "barcode", "description"}
+
+func TestPricingService_BulkUpdatePrices_Preview(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewPricingService(repository.NewProductRepository(db))
+	mock.ExpectQuery("SELECT (.+) FROM products\\s+WHERE \\(category_id IN \\(SELECT id FROM categories WHERE name = ANY").
+		WillReturnRows(sqlmock.NewRows(productRowColumns).
+			AddRow("1", "Waffle with Berries", 6.5, "Waffle", "", "", "").
+			AddRow("2", "Vanilla Bean Crème Brûlée", 7.0, "Crème Brûlée", "", "", ""))
+
+	// Test
+	result, err := service.BulkUpdatePrices(context.Background(), models.BulkPriceReq{Rules: []models.PriceRule{
+		{Category: "Waffle", Percent: 5},
+		{ProductIDs: []string{"1", "2"}, Amount: 0.5},
+	}}, "admin")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, result.Applied)
+	assert.Equal(t, []models.PriceChange{
+		{ProductID: "1", Name: "Waffle with Berries", Category: "Waffle", OldPrice: 6.5, NewPrice: 7.33},
+		{ProductID: "2", Name: "Vanilla Bean Crème Brûlée", Category: "Crème Brûlée", OldPrice: 7.0, NewPrice: 7.5},
+	}, result.Changes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPricingService_BulkUpdatePrices_Apply(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewPricingService(repository.NewProductRepository(db))
+	mock.ExpectQuery("SELECT (.+) FROM products\\s+WHERE \\(category_id IN \\(SELECT id FROM categories WHERE name = ANY").
+		WillReturnRows(sqlmock.NewRows(productRowColumns).AddRow("1", "Waffle with Berries", 6.5, "Waffle", "", "", ""))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE products SET price").
+		WithArgs("1", 6.83, 6.5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(models.AuditActionBulkPrice, "admin", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(42))
+	mock.ExpectExec("INSERT INTO cache_invalidations").
+		WithArgs(models.InvalidationTopicProducts, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Test
+	result, err := service.BulkUpdatePrices(context.Background(), models.BulkPriceReq{
+		Rules: []models.PriceRule{{Category: "Waffle", Percent: 5}},
+		Apply: true,
+	}, "admin")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, result.Applied)
+	assert.Equal(t, int64(42), result.AuditID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPricingService_BulkUpdatePrices_Conflict(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewPricingService(repository.NewProductRepository(db))
+	mock.ExpectQuery("SELECT (.+) FROM products\\s+WHERE \\(category_id IN \\(SELECT id FROM categories WHERE name = ANY").
+		WillReturnRows(sqlmock.NewRows(productRowColumns).AddRow("1", "Waffle with Berries", 6.5, "Waffle", "", "", ""))
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE products SET price").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	// Test
+	_, err = service.BulkUpdatePrices(context.Background(), models.BulkPriceReq{
+		Rules: []models.PriceRule{{Category: "Waffle", Amount: 1}},
+		Apply: true,
+	}, "admin")
+
+	// Assert
+	assert.True(t, errors.Is(err, repository.ErrPriceConflict))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPricingService_BulkUpdatePrices_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule models.PriceRule
+	}{
+		{name: "no selector", rule: models.PriceRule{Percent: 5}},
+		{name: "no change", rule: models.PriceRule{Category: "Waffle"}},
+		{name: "percent and amount", rule: models.PriceRule{Category: "Waffle", Percent: 5, Amount: 1}},
+		{name: "price wiped out", rule: models.PriceRule{Category: "Waffle", Percent: -100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			service := NewPricingService(nil)
+
+			// Test
+			_, err := service.BulkUpdatePrices(context.Background(), models.BulkPriceReq{Rules: []models.PriceRule{tt.rule}}, "admin")
+
+			// Assert
+			var ruleErr *PriceRuleError
+			assert.True(t, errors.As(err, &ruleErr))
+		})
+	}
+}
+
+func TestPricingService_BulkUpdatePrices_UnknownProduct(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewPricingService(repository.NewProductRepository(db))
+	mock.ExpectQuery("SELECT (.+) FROM products\\s+WHERE \\(category_id IN \\(SELECT id FROM categories WHERE name = ANY").
+		WillReturnRows(sqlmock.NewRows(productRowColumns))
+
+	// Test
+	_, err = service.BulkUpdatePrices(context.Background(), models.BulkPriceReq{
+		Rules: []models.PriceRule{{ProductIDs: []string{"99"}, Amount: 1}},
+	}, "admin")
+
+	// Assert
+	var ruleErr *PriceRuleError
+	assert.True(t, errors.As(err, &ruleErr))
+	assert.Equal(t, "rules[0].productIds", ruleErr.Diagnostics[0].Field)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,18 +1,61 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/productcache"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
 )
 
+var (
+	// ErrInvalidProduct is returned for product details that cannot be stored
+	ErrInvalidProduct = errors.New("invalid product")
+	// ErrProductNotFound is returned when a product does not exist
+	ErrProductNotFound = repository.ErrProductNotFound
+	// ErrProductExists is returned when creating a product whose ID is taken
+	ErrProductExists = repository.ErrProductExists
+	// ErrProductIdentifierTaken is returned when another product has the
+	// same SKU or barcode
+	ErrProductIdentifierTaken = repository.ErrProductIdentifierTaken
+)
+
+// productIDPattern matches the product IDs the API accepts; they appear in
+// URLs, so they are kept to letters, digits, dashes and underscores
+var productIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,50}$`)
+
 // ProductService handles product business logic
 type ProductService struct {
-	repo *repository.ProductRepository
+	repo  *repository.ProductRepository
+	cache *productcache.Cache
+	// shared is the cache shared with the other replicas, consulted after
+	// cache, when non-nil
+	shared *productcache.Redis
+	// events publishes catalogue changes when non-nil
+	events EventPublisher
+}
+
+// NewProductService creates a new product service. Reads are served from
+// cache when it is non-nil.
+func NewProductService(repo *repository.ProductRepository, cache *productcache.Cache) *ProductService {
+	return &ProductService{repo: repo, cache: cache}
 }
 
-// NewProductService creates a new product service
-func NewProductService(repo *repository.ProductRepository) *ProductService {
-	return &ProductService{repo: repo}
+// SetSharedCache makes the service read products and the first listing
+// pages through shared when this replica's cache does not have them
+func (s *ProductService) SetSharedCache(shared *productcache.Redis) {
+	s.shared = shared
+}
+
+// SetEventPublisher makes the service publish a ProductUpdated event for
+// every product created, updated or deleted
+func (s *ProductService) SetEventPublisher(events EventPublisher) {
+	s.events = events
 }
 
 // ListProducts returns all available products
@@ -20,12 +63,323 @@ func (s *ProductService) ListProducts() []models.Product {
 	return s.repo.GetAll()
 }
 
-// ListProductsPaginated returns paginated products with total count
-func (s *ProductService) ListProductsPaginated(limit, offset int) ([]models.Product, int, error) {
-	return s.repo.GetAllPaginated(limit, offset)
+// ListProductsPaginated returns paginated products matching filter with
+// total count
+func (s *ProductService) ListProductsPaginated(limit, offset int, sort []models.SortField, filter models.ProductFilter) ([]models.Product, int, error) {
+	shared := s.shared != nil && s.shared.CachesPage(limit, offset)
+	if s.cache == nil && !shared {
+		return s.repo.GetAllPaginated(limit, offset, sort, filter)
+	}
+
+	key := productcache.PageKey(limit, offset, sort, filter)
+	if s.cache != nil {
+		if page, ok := s.cache.Page(key); ok {
+			return page.Products, page.Total, nil
+		}
+	}
+	if shared {
+		if page, ok := s.shared.Page(key); ok {
+			if s.cache != nil {
+				s.cache.StorePage(key, page)
+			}
+			return page.Products, page.Total, nil
+		}
+	}
+
+	products, total, err := s.repo.GetAllPaginated(limit, offset, sort, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	page := productcache.Page{Products: products, Total: total}
+	if s.cache != nil {
+		s.cache.StorePage(key, page)
+	}
+	if shared {
+		s.shared.StorePage(key, page)
+	}
+	return products, total, nil
+}
+
+// ListProductsAfter returns up to limit products matching filter following
+// the after cursor with the cursor of the next page, which is empty on the
+// last. Cursor pages are not cached; they are meant for walking large
+// listings, which seldom repeat.
+func (s *ProductService) ListProductsAfter(after string, limit int, sort []models.SortField, filter models.ProductFilter) ([]models.Product, string, error) {
+	return s.repo.GetPageAfter(after, limit, sort, filter)
+}
+
+// StreamProducts calls fn with every product matching filter, for listings
+// streamed in one response. Streams bypass the cache like cursor pages.
+func (s *ProductService) StreamProducts(ctx context.Context, sort []models.SortField, filter models.ProductFilter, fn func(models.Product) error) error {
+	return s.repo.EachProduct(ctx, sort, filter, fn)
+}
+
+// SearchProducts returns paginated products matching a full-text search
+// query, best match first, with the total number of matches. Results are
+// not cached, as queries rarely repeat.
+func (s *ProductService) SearchProducts(query string, limit, offset int) ([]models.Product, int, error) {
+	return s.repo.Search(query, limit, offset)
 }
 
 // GetProduct returns a single product by ID
 func (s *ProductService) GetProduct(id string) (models.Product, error) {
-	return s.repo.GetByID(id)
+	if s.cache != nil {
+		if product, ok := s.cache.Product(id); ok {
+			return product, nil
+		}
+	}
+	if s.shared != nil {
+		if product, ok := s.shared.Product(id); ok {
+			if s.cache != nil {
+				s.cache.StoreProduct(product)
+			}
+			return product, nil
+		}
+	}
+
+	product, err := s.repo.GetByID(id)
+	if err != nil {
+		return product, err
+	}
+	if s.cache != nil {
+		s.cache.StoreProduct(product)
+	}
+	if s.shared != nil {
+		s.shared.StoreProduct(product)
+	}
+	return product, nil
+}
+
+// GetProductByBarcode returns a single product by its scanned barcode
+func (s *ProductService) GetProductByBarcode(barcode string) (models.Product, error) {
+	if s.cache != nil {
+		if product, ok := s.cache.ProductByBarcode(barcode); ok {
+			return product, nil
+		}
+	}
+
+	product, err := s.repo.GetByBarcode(barcode)
+	if err == nil && s.cache != nil {
+		s.cache.StoreProduct(product)
+	}
+	return product, err
+}
+
+// Warm fills the cache with the first page of the default product listing
+// and every product by ID, so the first requests after start-up do not all
+// go to the database. It does nothing without a cache of this replica's
+// own; the shared cache is filled by the replicas' reads.
+func (s *ProductService) Warm(perPage int) error {
+	if s.cache == nil {
+		return nil
+	}
+	if _, _, err := s.ListProductsPaginated(perPage, 0, nil, models.ProductFilter{}); err != nil {
+		return err
+	}
+	for _, product := range s.repo.GetAll() {
+		s.cache.StoreProduct(product)
+	}
+	return nil
+}
+
+// CreateProduct adds a product to the catalogue on behalf of actor
+func (s *ProductService) CreateProduct(ctx context.Context, req models.ProductReq, actor string) (models.Product, error) {
+	product, err := productFromRequest(req)
+	if err != nil {
+		return models.Product{}, err
+	}
+	err = s.repo.Create(product, models.AuditEntry{
+		Action:  models.AuditActionProductCreate,
+		Actor:   actor,
+		Details: product,
+	})
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		return models.Product{}, unknownCategory(product.Category)
+	}
+	if err != nil {
+		return models.Product{}, err
+	}
+	s.invalidate(product.ID)
+	publishEvent(ctx, s.events, models.EventTypeProductUpdated, product.ID, productUpdated(models.ProductChangeCreated, product))
+	return product, nil
+}
+
+// UpdateProduct replaces the details of the product with the given ID on
+// behalf of actor. Stock, currency prices, translations and option groups
+// are kept.
+func (s *ProductService) UpdateProduct(ctx context.Context, id string, req models.ProductReq, actor string) (models.Product, error) {
+	req.ID = id
+	product, err := productFromRequest(req)
+	if err != nil {
+		return models.Product{}, err
+	}
+	err = s.repo.Update(product, models.AuditEntry{
+		Action:  models.AuditActionProductUpdate,
+		Actor:   actor,
+		Details: product,
+	})
+	if errors.Is(err, repository.ErrCategoryNotFound) {
+		return models.Product{}, unknownCategory(product.Category)
+	}
+	if err != nil {
+		return models.Product{}, err
+	}
+	s.invalidate(product.ID)
+	publishEvent(ctx, s.events, models.EventTypeProductUpdated, product.ID, productUpdated(models.ProductChangeUpdated, product))
+	return s.repo.GetByID(product.ID)
+}
+
+// DeleteProduct removes the product with the given ID from the catalogue
+// on behalf of actor. Orders placed for it keep their copy of it.
+func (s *ProductService) DeleteProduct(ctx context.Context, id, actor string) error {
+	err := s.repo.Delete(id, models.AuditEntry{
+		Action:  models.AuditActionProductDelete,
+		Actor:   actor,
+		Details: map[string]string{"id": id},
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidate(id)
+	publishEvent(ctx, s.events, models.EventTypeProductUpdated, id, models.ProductUpdated{ID: id, Change: models.ProductChangeDeleted})
+	return nil
+}
+
+// SetOptionGroups replaces the option groups of the product with the given
+// ID on behalf of actor. Orders already placed keep the modifiers they
+// were priced with.
+func (s *ProductService) SetOptionGroups(ctx context.Context, id string, req models.OptionGroupsReq, actor string) (models.Product, error) {
+	groups, err := optionGroupsFromRequest(req)
+	if err != nil {
+		return models.Product{}, err
+	}
+	err = s.repo.SetOptionGroups(id, groups, models.AuditEntry{
+		Action:  models.AuditActionProductOptions,
+		Actor:   actor,
+		Details: map[string]any{"id": id, "optionGroups": groups},
+	})
+	if err != nil {
+		return models.Product{}, err
+	}
+	s.invalidate(id)
+	product, err := s.repo.GetByID(id)
+	if err != nil {
+		return models.Product{}, err
+	}
+	publishEvent(ctx, s.events, models.EventTypeProductUpdated, id, productUpdated(models.ProductChangeUpdated, product))
+	return product, nil
+}
+
+// productUpdated returns the data of a ProductUpdated event for product
+func productUpdated(change string, product models.Product) models.ProductUpdated {
+	return models.ProductUpdated{
+		ID:       product.ID,
+		Change:   change,
+		Name:     product.Name,
+		Price:    product.Price,
+		Category: product.Category,
+		SKU:      product.SKU,
+		Barcode:  product.Barcode,
+	}
+}
+
+// invalidate drops a changed product from this replica's cache and the
+// shared cache straight away; other replicas drop it when the invalidation
+// event reaches them
+func (s *ProductService) invalidate(id string) {
+	if s.cache != nil {
+		s.cache.Invalidate(id)
+	}
+	if s.shared != nil {
+		s.shared.Invalidate(id)
+	}
+}
+
+// unknownCategory is the error for a product naming a category that does
+// not exist
+func unknownCategory(name string) error {
+	return fmt.Errorf("%w: category %q does not exist, add it under /api/v1/categories first", ErrInvalidProduct, name)
+}
+
+// productFromRequest validates req and returns the product it describes,
+// with surrounding whitespace trimmed
+func productFromRequest(req models.ProductReq) (models.Product, error) {
+	product := models.Product{
+		ID:          strings.TrimSpace(req.ID),
+		Name:        strings.TrimSpace(req.Name),
+		Category:    strings.TrimSpace(req.Category),
+		SKU:         strings.TrimSpace(req.SKU),
+		Barcode:     strings.TrimSpace(req.Barcode),
+		Description: strings.TrimSpace(req.Description),
+	}
+	if req.Price != nil {
+		product.Price = *req.Price
+	}
+
+	switch {
+	case !productIDPattern.MatchString(product.ID):
+		return models.Product{}, fmt.Errorf("%w: id must be 1-50 letters, digits, dashes or underscores", ErrInvalidProduct)
+	case product.Name == "":
+		return models.Product{}, fmt.Errorf("%w: name is required", ErrInvalidProduct)
+	case product.Category == "":
+		return models.Product{}, fmt.Errorf("%w: category is required", ErrInvalidProduct)
+	case product.Price < 0 || product.Price != math.Round(product.Price*100)/100:
+		return models.Product{}, fmt.Errorf("%w: price must be a non-negative amount in cents", ErrInvalidProduct)
+	}
+	return product, nil
+}
+
+// optionGroupsFromRequest validates req and returns the option groups it
+// describes, with surrounding whitespace trimmed. Group and option IDs
+// follow the rules of product IDs, and option IDs must be unique across
+// the groups so order items can name options on their own.
+func optionGroupsFromRequest(req models.OptionGroupsReq) ([]models.OptionGroup, error) {
+	groups := make([]models.OptionGroup, len(req.OptionGroups))
+	groupIDs := make(map[string]bool, len(req.OptionGroups))
+	optionIDs := make(map[string]bool)
+	for i, g := range req.OptionGroups {
+		group := models.OptionGroup{
+			ID:        strings.TrimSpace(g.ID),
+			Name:      strings.TrimSpace(g.Name),
+			MinSelect: g.MinSelect,
+			MaxSelect: g.MaxSelect,
+			Options:   make([]models.ProductOption, len(g.Options)),
+		}
+		switch {
+		case !productIDPattern.MatchString(group.ID):
+			return nil, fmt.Errorf("%w: option group id must be 1-50 letters, digits, dashes or underscores", ErrInvalidProduct)
+		case groupIDs[group.ID]:
+			return nil, fmt.Errorf("%w: option group %s is given twice", ErrInvalidProduct, group.ID)
+		case group.Name == "":
+			return nil, fmt.Errorf("%w: option group %s needs a name", ErrInvalidProduct, group.ID)
+		case len(g.Options) == 0:
+			return nil, fmt.Errorf("%w: option group %s has no options", ErrInvalidProduct, group.ID)
+		case group.MinSelect < 0 || group.MaxSelect < 1 || group.MinSelect > group.MaxSelect || group.MaxSelect > len(g.Options):
+			return nil, fmt.Errorf("%w: option group %s needs maxSelect between 1 and its %d options, and minSelect between 0 and maxSelect",
+				ErrInvalidProduct, group.ID, len(g.Options))
+		}
+		groupIDs[group.ID] = true
+
+		for j, o := range g.Options {
+			option := models.ProductOption{
+				ID:    strings.TrimSpace(o.ID),
+				Name:  strings.TrimSpace(o.Name),
+				Price: o.Price,
+			}
+			switch {
+			case !productIDPattern.MatchString(option.ID):
+				return nil, fmt.Errorf("%w: option id must be 1-50 letters, digits, dashes or underscores", ErrInvalidProduct)
+			case optionIDs[option.ID]:
+				return nil, fmt.Errorf("%w: option %s is given twice", ErrInvalidProduct, option.ID)
+			case option.Name == "":
+				return nil, fmt.Errorf("%w: option %s needs a name", ErrInvalidProduct, option.ID)
+			case option.Price < 0 || option.Price != math.Round(option.Price*100)/100:
+				return nil, fmt.Errorf("%w: price of option %s must be a non-negative amount in cents", ErrInvalidProduct, option.ID)
+			}
+			optionIDs[option.ID] = true
+			group.Options[j] = option
+		}
+		groups[i] = group
+	}
+	return groups, nil
 }
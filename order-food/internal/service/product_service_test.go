@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/productcache"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProductService_CreateProduct(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewProductService(repository.NewProductRepository(db), nil)
+	price := 12.99
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO products").
+		WithArgs("11", "Chicken Waffle", 12.99, "Waffle", "", "", "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(models.AuditActionProductCreate, "admin", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO cache_invalidations").
+		WithArgs(models.InvalidationTopicProducts, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Test
+	product, err := service.CreateProduct(context.Background(), models.ProductReq{ID: " 11 ", Name: "Chicken Waffle ", Price: &price, Category: "Waffle"}, "admin")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.Product{ID: "11", Name: "Chicken Waffle", Price: 12.99, Category: "Waffle"}, product)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductService_CreateProduct_Exists(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewProductService(repository.NewProductRepository(db), nil)
+	price := 12.99
+	// The upsert only revives deleted products, so a live one changes nothing
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO products").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	// Test
+	_, err = service.CreateProduct(context.Background(), models.ProductReq{ID: "1", Name: "Chicken Waffle", Price: &price, Category: "Waffle"}, "admin")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrProductExists))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductService_CreateProduct_UnknownCategory(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewProductService(repository.NewProductRepository(db), nil)
+	price := 3.5
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO products").
+		WillReturnError(&pq.Error{Code: "23503", Message: `insert or update on table "products" violates foreign key constraint "fk_products_category"`})
+	mock.ExpectRollback()
+
+	// Test
+	_, err = service.CreateProduct(context.Background(), models.ProductReq{ID: "11", Name: "Margherita", Price: &price, Category: "Pizza"}, "admin")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrInvalidProduct))
+	assert.Contains(t, err.Error(), `category "Pizza" does not exist`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductService_UpdateProduct_IdentifierTaken(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewProductService(repository.NewProductRepository(db), nil)
+	price := 6.5
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE products").
+		WillReturnError(&pq.Error{Code: "23505", Message: `duplicate key value violates unique constraint "idx_products_barcode"`})
+	mock.ExpectRollback()
+
+	// Test
+	_, err = service.UpdateProduct(context.Background(), "1", models.ProductReq{Name: "Waffle", Price: &price, Category: "Waffle", Barcode: "4006381333931"}, "admin")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrProductIdentifierTaken))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductService_CreateProduct_Invalid(t *testing.T) {
+	price, negative, fraction := 1.0, -1.0, 1.999
+
+	tests := []struct {
+		name string
+		req  models.ProductReq
+	}{
+		{name: "missing id", req: models.ProductReq{Name: "Waffle", Price: &price, Category: "Waffle"}},
+		{name: "id with slash", req: models.ProductReq{ID: "a/b", Name: "Waffle", Price: &price, Category: "Waffle"}},
+		{name: "blank name", req: models.ProductReq{ID: "1", Name: "  ", Price: &price, Category: "Waffle"}},
+		{name: "negative price", req: models.ProductReq{ID: "1", Name: "Waffle", Price: &negative, Category: "Waffle"}},
+		{name: "fraction of a cent", req: models.ProductReq{ID: "1", Name: "Waffle", Price: &fraction, Category: "Waffle"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			service := NewProductService(repository.NewProductRepository(nil), nil)
+
+			// Test
+			_, err := service.CreateProduct(context.Background(), tt.req, "admin")
+
+			// Assert
+			assert.True(t, errors.Is(err, ErrInvalidProduct))
+		})
+	}
+}
+
+func TestProductService_DeleteProduct(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	cache := productcache.New(time.Minute)
+	cache.StoreProduct(models.Product{ID: "1", Name: "Waffle"})
+	service := NewProductService(repository.NewProductRepository(db), cache)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE products SET deleted_at").
+		WithArgs("1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(models.AuditActionProductDelete, "admin", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO cache_invalidations").
+		WithArgs(models.InvalidationTopicProducts, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Test
+	err = service.DeleteProduct(context.Background(), "1", "admin")
+
+	// Assert
+	assert.NoError(t, err)
+	_, cached := cache.Product("1")
+	assert.False(t, cached, "the deleted product must not be served from cache")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductService_SetOptionGroups(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewProductService(repository.NewProductRepository(db), nil)
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE products SET updated_at = NOW\\(\\) WHERE id = \\$1 AND deleted_at IS NULL").
+		WithArgs("1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM product_option_groups WHERE product_id = \\$1").
+		WithArgs("1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO product_option_groups").
+		WithArgs("1", "size", "Size", 1, 1, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO product_options").
+		WithArgs("1", "size", "regular", "Regular", 0.0, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO product_options").
+		WithArgs("1", "size", "large", "Large", 2.0, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(models.AuditActionProductOptions, "admin", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO cache_invalidations").
+		WithArgs(models.InvalidationTopicProducts, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("FROM products WHERE id = \\$1").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 6.5, "Waffle", "", "", ""))
+	mock.ExpectQuery("FROM product_prices_currency").WillReturnRows(sqlmock.NewRows([]string{"product_id", "currency", "price"}))
+	mock.ExpectQuery("FROM product_translations").WillReturnRows(sqlmock.NewRows([]string{"product_id", "language", "name", "description"}))
+	mock.ExpectQuery("FROM product_option_groups").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "id", "name", "min_select", "max_select", "id", "name", "price"}).
+			AddRow("1", "size", "Size", 1, 1, "regular", "Regular", 0.0).
+			AddRow("1", "size", "Size", 1, 1, "large", "Large", 2.0))
+
+	// Test
+	product, err := service.SetOptionGroups(context.Background(), "1", models.OptionGroupsReq{OptionGroups: []models.OptionGroup{{
+		ID: "size", Name: " Size ", MinSelect: 1, MaxSelect: 1,
+		Options: []models.ProductOption{{ID: "regular", Name: "Regular"}, {ID: "large", Name: "Large", Price: 2}},
+	}}}, "admin")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []models.OptionGroup{{
+		ID: "size", Name: "Size", MinSelect: 1, MaxSelect: 1,
+		Options: []models.ProductOption{{ID: "regular", Name: "Regular"}, {ID: "large", Name: "Large", Price: 2}},
+	}}, product.OptionGroups)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductService_SetOptionGroups_Invalid(t *testing.T) {
+	regular := models.ProductOption{ID: "regular", Name: "Regular"}
+	large := models.ProductOption{ID: "large", Name: "Large", Price: 2}
+
+	tests := []struct {
+		name   string
+		groups []models.OptionGroup
+	}{
+		{name: "id with slash", groups: []models.OptionGroup{{ID: "a/b", Name: "Size", MaxSelect: 1, Options: []models.ProductOption{regular}}}},
+		{name: "group given twice", groups: []models.OptionGroup{
+			{ID: "size", Name: "Size", MaxSelect: 1, Options: []models.ProductOption{regular}},
+			{ID: "size", Name: "Size", MaxSelect: 1, Options: []models.ProductOption{large}},
+		}},
+		{name: "option in two groups", groups: []models.OptionGroup{
+			{ID: "size", Name: "Size", MaxSelect: 1, Options: []models.ProductOption{regular}},
+			{ID: "crust", Name: "Crust", MaxSelect: 1, Options: []models.ProductOption{regular}},
+		}},
+		{name: "no options", groups: []models.OptionGroup{{ID: "size", Name: "Size", MaxSelect: 1}}},
+		{name: "min above max", groups: []models.OptionGroup{{ID: "size", Name: "Size", MinSelect: 2, MaxSelect: 1, Options: []models.ProductOption{regular, large}}}},
+		{name: "max above options", groups: []models.OptionGroup{{ID: "size", Name: "Size", MaxSelect: 3, Options: []models.ProductOption{regular, large}}}},
+		{name: "fraction of a cent", groups: []models.OptionGroup{{ID: "size", Name: "Size", MaxSelect: 1, Options: []models.ProductOption{{ID: "large", Name: "Large", Price: 0.999}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			service := NewProductService(repository.NewProductRepository(nil), nil)
+
+			// Test
+			_, err := service.SetOptionGroups(context.Background(), "1", models.OptionGroupsReq{OptionGroups: tt.groups}, "admin")
+
+			// Assert
+			assert.True(t, errors.Is(err, ErrInvalidProduct))
+		})
+	}
+}
+
+func TestProductService_GetProduct_SharedCache(t *testing.T) {
+	// Setup mock database; no query is expected
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	shared := productcache.NewRedis(client, "test:", time.Minute, productcache.DefaultRedisPages, time.Second)
+	shared.StoreProduct(models.Product{ID: "1", Name: "Waffle"})
+
+	cache := productcache.New(time.Minute)
+	service := NewProductService(repository.NewProductRepository(db), cache)
+	service.SetSharedCache(shared)
+
+	// Test
+	product, err := service.GetProduct("1")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Waffle", product.Name)
+	_, cached := cache.Product("1")
+	assert.True(t, cached, "a product read from the shared cache is kept locally")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductService_ListProductsPaginated_Filter(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewProductService(repository.NewProductRepository(db), nil)
+	minPrice := 5.0
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products WHERE deleted_at IS NULL AND category_id = \\(SELECT id FROM categories WHERE name = \\$1\\) AND price >= \\$2").
+		WithArgs("Waffle", 5.0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("FROM products WHERE deleted_at IS NULL AND category_id = \\(SELECT id FROM categories WHERE name = \\$1\\) AND price >= \\$2 ORDER BY id LIMIT \\$3 OFFSET \\$4").
+		WithArgs("Waffle", 5.0, 10, 0).
+		WillReturnRows(sqlmock.NewRows(productRowColumns).AddRow("1", "Waffle with Berries", 6.5, "Waffle", "", "", ""))
+	mock.ExpectQuery("FROM product_prices_currency").WillReturnRows(sqlmock.NewRows([]string{"product_id", "currency", "price"}))
+	mock.ExpectQuery("FROM product_translations").WillReturnRows(sqlmock.NewRows([]string{"product_id", "language", "name", "description"}))
+	mock.ExpectQuery("FROM product_option_groups").WillReturnRows(sqlmock.NewRows(nil))
+
+	// Test
+	products, total, err := service.ListProductsPaginated(10, 0, nil, models.ProductFilter{Category: "Waffle", MinPrice: &minPrice})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Len(t, products, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProductService_SearchProducts(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewProductService(repository.NewProductRepository(db), nil)
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM products, websearch_to_tsquery\\('english', \\$1\\) AS q\\s+WHERE deleted_at IS NULL AND \\(search_vector @@ q OR category_id IN").
+		WithArgs("berry waffle").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("ORDER BY ts_rank\\(search_vector, q\\) DESC, id\\s+LIMIT \\$2 OFFSET \\$3").
+		WithArgs("berry waffle", 10, 0).
+		WillReturnRows(sqlmock.NewRows(productRowColumns).AddRow("1", "Waffle with Berries", 6.5, "Waffle", "", "", ""))
+	mock.ExpectQuery("FROM product_prices_currency").WillReturnRows(sqlmock.NewRows([]string{"product_id", "currency", "price"}))
+	mock.ExpectQuery("FROM product_translations").WillReturnRows(sqlmock.NewRows([]string{"product_id", "language", "name", "description"}))
+	mock.ExpectQuery("FROM product_option_groups").WillReturnRows(sqlmock.NewRows(nil))
+
+	// Test
+	products, total, err := service.SearchProducts("berry waffle", 10, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "Waffle with Berries", products[0].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrPromoCodeUnavailable is returned when a promo code cannot be checked
+// because the database did not answer and the fallback rejects codes
+var ErrPromoCodeUnavailable = errors.New("promo codes cannot be checked right now")
+
+// PromoCodeFallback selects how promo codes are validated when the
+// database does not answer the lookup in time
+type PromoCodeFallback string
+
+const (
+	// PromoCodeFallbackOff fails the lookup, and with it the request
+	PromoCodeFallbackOff PromoCodeFallback = "off"
+	// PromoCodeFallbackOpen accepts codes the coupon filter may contain,
+	// without their discount, and rejects the rest. Codes are rejected
+	// when the filter is not loaded.
+	PromoCodeFallbackOpen PromoCodeFallback = "open"
+	// PromoCodeFallbackClosed rejects every code with
+	// ErrPromoCodeUnavailable, so orders without one still go through
+	PromoCodeFallbackClosed PromoCodeFallback = "closed"
+)
+
+// ParsePromoCodeFallback validates a PROMO_CODE_FALLBACK value; empty means
+// off
+func ParsePromoCodeFallback(value string) (PromoCodeFallback, error) {
+	switch PromoCodeFallback(value) {
+	case "", PromoCodeFallbackOff:
+		return PromoCodeFallbackOff, nil
+	case PromoCodeFallbackOpen, PromoCodeFallbackClosed:
+		return PromoCodeFallback(value), nil
+	}
+	return "", fmt.Errorf("unknown promo code fallback %q, expected %q, %q or %q",
+		value, PromoCodeFallbackOff, PromoCodeFallbackOpen, PromoCodeFallbackClosed)
+}
+
+// fallback decides on a code after the lookup failed with err, counting the
+// decision
+func (s *PromoCodeService) fallback(err error) (bool, error) {
+	switch s.fallbackMode {
+	case PromoCodeFallbackOpen:
+		// The filter already let the code through; without a loaded
+		// filter nothing vouches for it
+		if s.filter != nil && s.filter.Loaded() {
+			s.fallbackAccepted.Add(1)
+			slog.Warn("Accepting promo code without a database check", "error", err)
+			return true, nil
+		}
+		s.fallbackRejected.Add(1)
+		slog.Warn("Rejecting promo code, the coupon filter is not loaded", "error", err)
+		return false, ErrPromoCodeUnavailable
+	case PromoCodeFallbackClosed:
+		s.fallbackRejected.Add(1)
+		slog.Warn("Rejecting promo code without a database check", "error", err)
+		return false, ErrPromoCodeUnavailable
+	}
+	return false, fmt.Errorf("failed to validate promo code: %w", err)
+}
+
+// promoCodeFallbackDesc describes the fallback decisions taken on promo
+// codes the database did not check
+var promoCodeFallbackDesc = prometheus.NewDesc("promo_code_fallback_total", "Promo codes decided on without a database check, by decision.", []string{"mode", "decision"}, nil)
+
+// Describe sends the descriptor of the fallback decisions
+func (s *PromoCodeService) Describe(ch chan<- *prometheus.Desc) {
+	ch <- promoCodeFallbackDesc
+}
+
+// Collect sends the fallback decisions taken on promo codes the database
+// did not check
+func (s *PromoCodeService) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(promoCodeFallbackDesc, prometheus.CounterValue, float64(s.fallbackAccepted.Load()), string(s.fallbackMode), "accepted")
+	ch <- prometheus.MustNewConstMetric(promoCodeFallbackDesc, prometheus.CounterValue, float64(s.fallbackRejected.Load()), string(s.fallbackMode), "rejected")
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/expfmt"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/couponfilter"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePromoCodeFallback(t *testing.T) {
+	for value, want := range map[string]PromoCodeFallback{
+		"":       PromoCodeFallbackOff,
+		"off":    PromoCodeFallbackOff,
+		"open":   PromoCodeFallbackOpen,
+		"closed": PromoCodeFallbackClosed,
+	} {
+		got, err := ParsePromoCodeFallback(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+
+	_, err := ParsePromoCodeFallback("fail-open")
+	assert.Error(t, err)
+}
+
+func TestPromoCodeService_ValidatePromoCode_Fallback(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       PromoCodeFallback
+		withFilter bool
+		wantValid  bool
+		wantErr    error
+		wantMetric string
+	}{
+		{name: "off", mode: PromoCodeFallbackOff},
+		{name: "closed", mode: PromoCodeFallbackClosed, wantErr: ErrPromoCodeUnavailable, wantMetric: `decision="rejected",mode="closed"} 1`},
+		{name: "open with filter", mode: PromoCodeFallbackOpen, withFilter: true, wantValid: true, wantMetric: `decision="accepted",mode="open"} 1`},
+		{name: "open without filter", mode: PromoCodeFallbackOpen, wantErr: ErrPromoCodeUnavailable, wantMetric: `decision="rejected",mode="open"} 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			var filter *couponfilter.Filter
+			if tt.withFilter {
+				mock.ExpectQuery("SELECT ispopulated FROM pg_matviews").
+					WillReturnRows(sqlmock.NewRows([]string{"ispopulated"}))
+				mock.ExpectQuery("SELECT reltuples::bigint FROM pg_class").
+					WillReturnRows(sqlmock.NewRows([]string{"reltuples"}).AddRow(4))
+				mock.ExpectQuery("SELECT upper\\(coupon\\) FROM coupons GROUP BY upper\\(coupon\\)").
+					WillReturnRows(sqlmock.NewRows([]string{"coupon"}).AddRow("HAPPYHRS"))
+				mock.ExpectQuery("SELECT code FROM campaign_codes").
+					WillReturnRows(sqlmock.NewRows([]string{"code"}))
+				filter = couponfilter.New(repository.NewCouponRepository(db), couponfilter.Config{})
+				assert.NoError(t, filter.Load(context.Background()))
+			}
+			service := NewPromoCodeService(db, filter)
+			service.SetFallback(tt.mode, 10*time.Millisecond)
+
+			// Mock expectation: the database answers too late
+			mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+				WithArgs("HAPPYHRS", 2).
+				WillDelayFor(time.Second).
+				WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, nil, nil, nil, nil))
+
+			// Execute
+			promo, valid, err := service.ValidatePromoCode("HAPPYHRS")
+
+			// Assert
+			assert.Equal(t, tt.wantValid, valid)
+			switch {
+			case tt.mode == PromoCodeFallbackOff:
+				assert.ErrorContains(t, err, "failed to validate promo code")
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, "HAPPYHRS", promo.Code)
+				assert.Nil(t, promo.Discount, "the discount is unknown without the database")
+			}
+
+			buf, err := testutil.CollectAndFormat(service, expfmt.TypeTextPlain, "promo_code_fallback_total")
+			assert.NoError(t, err)
+			if tt.wantMetric != "" {
+				assert.Contains(t, string(buf), "promo_code_fallback_total{"+tt.wantMetric)
+			} else {
+				assert.NotContains(t, string(buf), "} 1")
+			}
+		})
+	}
+}
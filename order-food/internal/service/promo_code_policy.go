@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// maxPromoCodeLength is the width of coupons.coupon
+const maxPromoCodeLength = 255
+
+// DefaultPromoCodePolicy applies where no policy is configured: codes of
+// 8 to 10 characters found in at least 2 coupon files
+var DefaultPromoCodePolicy = models.PromoCodePolicy{Name: "default", MinLength: 8, MaxLength: 10, MinFiles: 2}
+
+// ParsePromoCodePolicies parses a comma-separated list of
+// name=minLength-maxLength:minFiles policies, keyed by region or
+// environment, e.g. "eu=8-10:2,apac=6-12:1"
+func ParsePromoCodePolicies(spec string) (map[string]models.PromoCodePolicy, error) {
+	policies := make(map[string]models.PromoCodePolicy)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, rules, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid promo code policy %q, want name=minLength-maxLength:minFiles", entry)
+		}
+		policy, err := parsePromoCodeRules(strings.TrimSpace(rules))
+		if err != nil {
+			return nil, fmt.Errorf("invalid promo code policy for %s: %w", name, err)
+		}
+		if _, dup := policies[name]; dup {
+			return nil, fmt.Errorf("promo code policy for %s is given twice", name)
+		}
+		policy.Name = name
+		policies[name] = policy
+	}
+	return policies, nil
+}
+
+// parsePromoCodeRules parses minLength-maxLength:minFiles
+func parsePromoCodeRules(rules string) (models.PromoCodePolicy, error) {
+	lengths, files, ok := strings.Cut(rules, ":")
+	minLength, maxLength, ok2 := strings.Cut(lengths, "-")
+	if !ok || !ok2 {
+		return models.PromoCodePolicy{}, fmt.Errorf("%q is not minLength-maxLength:minFiles", rules)
+	}
+
+	var policy models.PromoCodePolicy
+	var err error
+	if policy.MinLength, err = strconv.Atoi(minLength); err != nil {
+		return models.PromoCodePolicy{}, fmt.Errorf("invalid minimum length %q", minLength)
+	}
+	if policy.MaxLength, err = strconv.Atoi(maxLength); err != nil {
+		return models.PromoCodePolicy{}, fmt.Errorf("invalid maximum length %q", maxLength)
+	}
+	if policy.MinFiles, err = strconv.Atoi(files); err != nil {
+		return models.PromoCodePolicy{}, fmt.Errorf("invalid file count %q", files)
+	}
+	switch {
+	case policy.MinLength < 1 || policy.MaxLength > maxPromoCodeLength || policy.MinLength > policy.MaxLength:
+		return models.PromoCodePolicy{}, fmt.Errorf("lengths must satisfy 1 <= minLength <= maxLength <= %d", maxPromoCodeLength)
+	case policy.MinFiles < 1:
+		return models.PromoCodePolicy{}, fmt.Errorf("codes must appear in at least 1 file")
+	}
+	return policy, nil
+}
+
+// SelectPromoCodePolicy returns the policy of the first of names that has
+// one, such as the region and then the environment, or
+// DefaultPromoCodePolicy when none has
+func SelectPromoCodePolicy(policies map[string]models.PromoCodePolicy, names ...string) models.PromoCodePolicy {
+	for _, name := range names {
+		if policy, ok := policies[name]; ok && name != "" {
+			return policy
+		}
+	}
+	return DefaultPromoCodePolicy
+}
+
+// promoCodeLengthValid reports whether code has a length policy allows and
+// is text PostgreSQL can store, as every loaded code is
+func promoCodeLengthValid(policy models.PromoCodePolicy, code string) bool {
+	return len(code) >= policy.MinLength && len(code) <= policy.MaxLength && utf8.ValidString(code) && !strings.ContainsRune(code, 0)
+}
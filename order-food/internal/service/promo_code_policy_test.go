@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePromoCodePolicies(t *testing.T) {
+	policies, err := ParsePromoCodePolicies(" eu=8-10:2, apac=6-12:1,")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]models.PromoCodePolicy{
+		"eu":   {Name: "eu", MinLength: 8, MaxLength: 10, MinFiles: 2},
+		"apac": {Name: "apac", MinLength: 6, MaxLength: 12, MinFiles: 1},
+	}, policies)
+
+	policies, err = ParsePromoCodePolicies("")
+	assert.NoError(t, err)
+	assert.Empty(t, policies)
+
+	for _, spec := range []string{"eu", "=8-10:2", "eu=8-10", "eu=8:2", "eu=a-10:2", "eu=10-8:2", "eu=0-8:2", "eu=8-300:2", "eu=8-10:0", "eu=8-10:2,eu=6-8:1"} {
+		_, err := ParsePromoCodePolicies(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestSelectPromoCodePolicy(t *testing.T) {
+	policies := map[string]models.PromoCodePolicy{
+		"eu":         {Name: "eu", MinLength: 8, MaxLength: 10, MinFiles: 3},
+		"production": {Name: "production", MinLength: 6, MaxLength: 12, MinFiles: 1},
+	}
+
+	assert.Equal(t, "eu", SelectPromoCodePolicy(policies, "eu", "production").Name)
+	assert.Equal(t, "production", SelectPromoCodePolicy(policies, "us", "production").Name)
+	assert.Equal(t, "production", SelectPromoCodePolicy(policies, "", "production").Name)
+	assert.Equal(t, DefaultPromoCodePolicy, SelectPromoCodePolicy(policies, "us", "staging"))
+	assert.Equal(t, DefaultPromoCodePolicy, SelectPromoCodePolicy(nil))
+}
+
+func TestPromoCodeService_ValidatePromoCode_RegionalPolicy(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewPromoCodeService(db, nil)
+	service.SetPolicy(models.PromoCodePolicy{Name: "apac", MinLength: 6, MaxLength: 12, MinFiles: 1})
+
+	// Mock expectation: a 6 character code in 1 file is enough
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("SAKURA", 1).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(1, nil, nil, nil, nil))
+
+	// Test
+	_, valid, err := service.ValidatePromoCode("SAKURA")
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	// Codes longer than the policy allows are rejected without a query
+	_, valid, err = service.ValidatePromoCode("THIRTEENCHARS")
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	_, err = service.SetDiscount("SHORT", models.Discount{Type: models.DiscountTypePercentage, Value: 10}, "admin")
+	assert.ErrorIs(t, err, ErrInvalidPromoCode)
+	assert.ErrorContains(t, err, "6-12 characters")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
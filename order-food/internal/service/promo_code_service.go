@@ -3,45 +3,345 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/couponfilter"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/promocode"
+)
+
+var (
+	// ErrInvalidPromoCode is returned for a promo code that is not valid
+	ErrInvalidPromoCode = errors.New("invalid promo code")
+	// ErrInvalidDiscount is returned for a discount that cannot be given
+	ErrInvalidDiscount = errors.New("invalid discount")
+	// ErrPromoCodeNotApplicable is returned when a promo code restricted to
+	// some categories or products is used on an order with none of them
+	ErrPromoCodeNotApplicable = errors.New("promo code does not apply to any item")
+	// ErrDiscountNotFound is returned when a promo code has no discount
+	ErrDiscountNotFound = repository.ErrDiscountNotFound
+	// ErrInvalidLimits is returned for usage limits that cannot be set
+	ErrInvalidLimits = errors.New("invalid promo code limits")
+	// ErrPromoCodeExhausted is returned when a promo code has been used as
+	// many times as its limit allows
+	ErrPromoCodeExhausted = repository.ErrPromoCodeExhausted
+	// ErrPromoCodeRedeemed is returned when a customer has already used a
+	// promo code limited to one use per customer
+	ErrPromoCodeRedeemed = repository.ErrPromoCodeRedeemed
+	// ErrCustomerRequired is returned when an order without a customer ID
+	// uses a promo code limited to one use per customer
+	ErrCustomerRequired = repository.ErrCustomerRequired
 )
 
-// PromoCodeService handles promo code validation
+// maxDiscountScope is the most categories, and the most products, a
+// discount can be restricted to
+const maxDiscountScope = 100
+
+// PromoCodeLookup selects the query promo codes are validated with
+type PromoCodeLookup string
+
+const (
+	// PromoCodeLookupCount counts the distinct files of a code over the
+	// btree index on upper(coupon)
+	PromoCodeLookupCount PromoCodeLookup = "count"
+	// PromoCodeLookupHash compares codes by equality only, which the hash
+	// index on upper(coupon) can answer, and stops at the second file. Rows
+	// loaded before codes were stored in upper case can hold a code twice
+	// in one file in different cases, so files are counted distinctly like
+	// the count lookup does.
+	PromoCodeLookupHash PromoCodeLookup = "hash"
+)
+
+// ParsePromoCodeLookup validates a PROMO_CODE_LOOKUP value; empty means
+// count
+func ParsePromoCodeLookup(value string) (PromoCodeLookup, error) {
+	switch PromoCodeLookup(value) {
+	case "", PromoCodeLookupCount:
+		return PromoCodeLookupCount, nil
+	case PromoCodeLookupHash:
+		return PromoCodeLookupHash, nil
+	}
+	return "", fmt.Errorf("unknown promo code lookup %q, expected %q or %q", value, PromoCodeLookupCount, PromoCodeLookupHash)
+}
+
+// promoCodeQueries validate the promo code $1, returning the number of
+// files it appears in, up to at least the $2 files the policy asks for, and
+// its discount, by lookup. $1 is normalized; coupons are matched on
+// upper(coupon), so rows loaded before codes were stored in upper case
+// still match.
+var promoCodeQueries = map[PromoCodeLookup]string{
+	PromoCodeLookupCount: `
+		SELECT LEAST(COUNT(DISTINCT file_name), $2::int),
+		       (SELECT discount_type FROM coupon_discounts WHERE coupon = $1),
+		       (SELECT discount_value FROM coupon_discounts WHERE coupon = $1),
+		       (SELECT categories FROM coupon_discounts WHERE coupon = $1),
+		       (SELECT product_ids FROM coupon_discounts WHERE coupon = $1)
+		FROM coupons
+		WHERE upper(coupon) = $1
+	`,
+	PromoCodeLookupHash: `
+		SELECT (SELECT COUNT(*) FROM (SELECT DISTINCT file_name FROM coupons WHERE upper(coupon) = $1 LIMIT $2) AS files),
+		       (SELECT discount_type FROM coupon_discounts WHERE coupon = $1),
+		       (SELECT discount_value FROM coupon_discounts WHERE coupon = $1),
+		       (SELECT categories FROM coupon_discounts WHERE coupon = $1),
+		       (SELECT product_ids FROM coupon_discounts WHERE coupon = $1)
+	`,
+}
+
+// PromoCodeQuery returns the query lookup validates promo codes with, for
+// tools comparing query plans. It takes the code and the number of files
+// the policy asks for.
+func PromoCodeQuery(lookup PromoCodeLookup) string {
+	return promoCodeQueries[lookup]
+}
+
+// PromoCodeService handles promo code validation, discounts and usage
+// limits
 type PromoCodeService struct {
-	db *sql.DB
+	db      *sql.DB
+	coupons *repository.CouponRepository
+	filter  *couponfilter.Filter
+	lookup  PromoCodeLookup
+	policy  models.PromoCodePolicy
+
+	// fallbackMode decides on codes the database does not check within
+	// lookupTimeout
+	fallbackMode     PromoCodeFallback
+	lookupTimeout    time.Duration
+	fallbackAccepted atomic.Int64
+	fallbackRejected atomic.Int64
 }
 
-// NewPromoCodeService creates a new promo code service
-func NewPromoCodeService(db *sql.DB) *PromoCodeService {
-	return &PromoCodeService{db: db}
+// DefaultPromoCodeLookupTimeout bounds a promo code lookup
+const DefaultPromoCodeLookupTimeout = 5 * time.Second
+
+// NewPromoCodeService creates a new promo code service applying
+// DefaultPromoCodePolicy. Codes filter rules out are rejected without a
+// database query; filter may be nil.
+func NewPromoCodeService(db *sql.DB, filter *couponfilter.Filter) *PromoCodeService {
+	return &PromoCodeService{
+		db:            db,
+		coupons:       repository.NewCouponRepository(db),
+		filter:        filter,
+		lookup:        PromoCodeLookupCount,
+		policy:        DefaultPromoCodePolicy,
+		fallbackMode:  PromoCodeFallbackOff,
+		lookupTimeout: DefaultPromoCodeLookupTimeout,
+	}
+}
+
+// SetFallback selects how codes are validated when the database does not
+// answer a lookup within timeout
+func (s *PromoCodeService) SetFallback(mode PromoCodeFallback, timeout time.Duration) {
+	s.fallbackMode = mode
+	s.lookupTimeout = timeout
+}
+
+// SetLookup selects the query promo codes are validated with
+func (s *PromoCodeService) SetLookup(lookup PromoCodeLookup) {
+	s.lookup = lookup
+}
+
+// SetPolicy replaces the rules promo codes are validated against
+func (s *PromoCodeService) SetPolicy(policy models.PromoCodePolicy) {
+	s.policy = policy
+}
+
+// Policy returns the rules promo codes are validated against
+func (s *PromoCodeService) Policy() models.PromoCodePolicy {
+	return s.policy
+}
+
+// Lookup returns the query promo codes are validated with
+func (s *PromoCodeService) Lookup() PromoCodeLookup {
+	return s.lookup
 }
 
-// ValidatePromoCode checks if a promo code is valid
-// Rules:
-// 1. Must be 8-10 characters long
-// 2. Must appear in at least 2 different files in the coupons table
-func (s *PromoCodeService) ValidatePromoCode(code string) (bool, error) {
+// ValidatePromoCode checks if a promo code is valid and returns it with its
+// discount. Valid codes without a discount have a nil Discount.
+// Codes are case-insensitive and surrounding whitespace is ignored; the
+// returned code is in upper case.
+// Rules, with the bounds of the policy (8-10 characters and 2 files by
+// default):
+// 1. Must be MinLength-MaxLength characters long
+// 2. Must appear in at least MinFiles different files in the coupons table
+//
+// When the database does not answer, the fallback decides: see
+// PromoCodeFallback.
+func (s *PromoCodeService) ValidatePromoCode(code string) (models.PromoCode, bool, error) {
+	code = promocode.Normalize(code)
 	// Rule 1: Check length
-	if len(code) < 8 || len(code) > 10 {
-		return false, nil
+	if !promoCodeLengthValid(s.policy, code) {
+		return models.PromoCode{}, false, nil
+	}
+	// Codes the filter has never seen are in too few files
+	if s.filter != nil && !s.filter.MayContain(code) {
+		return models.PromoCode{}, false, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), s.lookupTimeout)
 	defer cancel()
 
-	// Rule 2: Check if code appears in at least 2 files
-	query := `
-		SELECT COUNT(DISTINCT file_name)
-		FROM coupons
-		WHERE coupon = $1
-	`
-
+	// Rule 2: Check if code appears in enough files
 	var fileCount int
-	err := s.db.QueryRowContext(ctx, query, code).Scan(&fileCount)
+	var discountType sql.NullString
+	var discountValue sql.NullFloat64
+	var categories, productIDs []string
+	err := s.db.QueryRowContext(ctx, promoCodeQueries[s.lookup], code, s.policy.MinFiles).Scan(&fileCount, &discountType, &discountValue,
+		pq.Array(&categories), pq.Array(&productIDs))
 	if err != nil {
-		return false, fmt.Errorf("failed to validate promo code: %w", err)
+		valid, err := s.fallback(err)
+		if !valid {
+			return models.PromoCode{}, false, err
+		}
+		return models.PromoCode{Code: code}, true, nil
+	}
+	if fileCount < s.policy.MinFiles {
+		return models.PromoCode{}, false, nil
 	}
 
-	return fileCount >= 2, nil
+	promo := models.PromoCode{Code: code}
+	if discountType.Valid {
+		promo.Discount = &models.Discount{
+			Type:       models.DiscountType(discountType.String),
+			Value:      discountValue.Float64,
+			Categories: categories,
+			ProductIDs: productIDs,
+		}
+	}
+	return promo, true, nil
+}
+
+// SetDiscount sets the discount a promo code gives and records the change
+// in the audit log. The code does not have to be loaded yet, so discounts
+// can be set up before a coupon file is uploaded.
+func (s *PromoCodeService) SetDiscount(code string, discount models.Discount, actor string) (models.PromoCode, error) {
+	code = promocode.Normalize(code)
+	if err := s.checkLength(code); err != nil {
+		return models.PromoCode{}, err
+	}
+	switch discount.Type {
+	case models.DiscountTypePercentage:
+		if discount.Value <= 0 || discount.Value > 100 {
+			return models.PromoCode{}, fmt.Errorf("%w: percentage must be above 0 and at most 100", ErrInvalidDiscount)
+		}
+	case models.DiscountTypeFixed:
+		if discount.Value <= 0 {
+			return models.PromoCode{}, fmt.Errorf("%w: amount must be above 0", ErrInvalidDiscount)
+		}
+	default:
+		return models.PromoCode{}, fmt.Errorf("%w: unknown type %q", ErrInvalidDiscount, discount.Type)
+	}
+	var err error
+	if discount.Categories, err = normalizeScope(discount.Categories, "categories"); err != nil {
+		return models.PromoCode{}, err
+	}
+	if discount.ProductIDs, err = normalizeScope(discount.ProductIDs, "productIds"); err != nil {
+		return models.PromoCode{}, err
+	}
+
+	err = s.coupons.SetDiscount(code, discount, models.AuditEntry{
+		Action:  models.AuditActionPromoCodeDiscount,
+		Actor:   actor,
+		Details: models.PromoCode{Code: code, Discount: &discount},
+	})
+	if err != nil {
+		return models.PromoCode{}, err
+	}
+	return models.PromoCode{Code: code, Discount: &discount}, nil
+}
+
+// RemoveDiscount removes the discount of a promo code, which stays valid,
+// and records the change in the audit log
+func (s *PromoCodeService) RemoveDiscount(code, actor string) error {
+	code = promocode.Normalize(code)
+	return s.coupons.DeleteDiscount(code, models.AuditEntry{
+		Action:  models.AuditActionPromoCodeDiscount,
+		Actor:   actor,
+		Details: models.PromoCode{Code: code},
+	})
+}
+
+// RedeemPromoCode records that the order with orderID used code, taking
+// discount off it. The code is refused with ErrPromoCodeExhausted once it
+// has reached its usage limit and with ErrPromoCodeRedeemed when customerID
+// already used a code limited to one use per customer. It must be called within a TxManager
+// transaction, so the use only counts if the order is stored.
+func (s *PromoCodeService) RedeemPromoCode(ctx context.Context, code, orderID, customerID string, discount float64) error {
+	return s.coupons.Redeem(ctx, promocode.Normalize(code), orderID, customerID, discount)
+}
+
+// GetLimits returns the usage limits of a promo code and how many orders
+// have used it
+func (s *PromoCodeService) GetLimits(code string) (models.PromoCode, error) {
+	code = promocode.Normalize(code)
+	if err := s.checkLength(code); err != nil {
+		return models.PromoCode{}, err
+	}
+	limits, err := s.coupons.GetLimits(code)
+	if err != nil {
+		return models.PromoCode{}, err
+	}
+	return models.PromoCode{Code: code, Limits: &limits}, nil
+}
+
+// SetLimits sets how often a promo code can be used and records the change
+// in the audit log. Zero limits make the code unlimited again. Lowering the
+// limit below the current redemptions stops further use but does not
+// affect orders already placed.
+func (s *PromoCodeService) SetLimits(code string, limits models.PromoCodeLimits, actor string) (models.PromoCode, error) {
+	code = promocode.Normalize(code)
+	if err := s.checkLength(code); err != nil {
+		return models.PromoCode{}, err
+	}
+	if limits.MaxRedemptions < 0 {
+		return models.PromoCode{}, fmt.Errorf("%w: maxRedemptions must not be negative", ErrInvalidLimits)
+	}
+	limits.Redemptions = 0
+
+	redemptions, err := s.coupons.SetLimits(code, limits, models.AuditEntry{
+		Action:  models.AuditActionPromoCodeLimits,
+		Actor:   actor,
+		Details: models.PromoCode{Code: code, Limits: &limits},
+	})
+	if err != nil {
+		return models.PromoCode{}, err
+	}
+	limits.Redemptions = redemptions
+	return models.PromoCode{Code: code, Limits: &limits}, nil
+}
+
+// normalizeScope trims the categories or product IDs a discount is
+// restricted to and drops empty and repeated ones, refusing more than
+// maxDiscountScope
+func normalizeScope(values []string, field string) ([]string, error) {
+	var scope []string
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		scope = append(scope, value)
+	}
+	if len(scope) > maxDiscountScope {
+		return nil, fmt.Errorf("%w: at most %d %s", ErrInvalidDiscount, maxDiscountScope, field)
+	}
+	return scope, nil
+}
+
+// checkLength returns ErrInvalidPromoCode for a code of a length the
+// policy does not allow
+func (s *PromoCodeService) checkLength(code string) error {
+	if !promoCodeLengthValid(s.policy, code) {
+		return fmt.Errorf("%w: code must be %d-%d characters", ErrInvalidPromoCode, s.policy.MinLength, s.policy.MaxLength)
+	}
+	return nil
 }
@@ -1,32 +1,63 @@
 package service
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/couponfilter"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
 	"github.com/stretchr/testify/assert"
 )
 
+var promoCodeRowColumns = []string{"count", "discount_type", "discount_value", "categories", "product_ids"}
+
 func TestPromoCodeService_ValidatePromoCode_ValidCode(t *testing.T) {
 	// Setup mock database
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
 	defer db.Close()
 
-	service := NewPromoCodeService(db)
+	service := NewPromoCodeService(db, nil)
 
 	// Mock expectation: code exists in 2 files
-	mock.ExpectQuery("SELECT COUNT\\(DISTINCT file_name\\)").
-		WithArgs("HAPPYHRS").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("HAPPYHRS", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, nil, nil, nil, nil))
+
+	// Test
+	_, valid, err := service.ValidatePromoCode("HAPPYHRS")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromoCodeService_ValidatePromoCode_CaseInsensitive(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewPromoCodeService(db, nil)
+
+	// Mock expectation: the code is looked up in upper case
+	mock.ExpectQuery("FROM coupons\\s+WHERE upper\\(coupon\\) = \\$1").
+		WithArgs("HAPPYHRS", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, nil, nil, nil, nil))
 
 	// Test
-	valid, err := service.ValidatePromoCode("HAPPYHRS")
+	promo, valid, err := service.ValidatePromoCode(" HappyHrs ")
 
 	// Assert
 	assert.NoError(t, err)
 	assert.True(t, valid)
+	assert.Equal(t, "HAPPYHRS", promo.Code)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -36,10 +67,10 @@ func TestPromoCodeService_ValidatePromoCode_InvalidCode_TooShort(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	service := NewPromoCodeService(db)
+	service := NewPromoCodeService(db, nil)
 
 	// Test with code that's too short (less than 8 characters)
-	valid, err := service.ValidatePromoCode("SHORT")
+	_, valid, err := service.ValidatePromoCode("SHORT")
 
 	// Assert
 	assert.NoError(t, err)
@@ -52,31 +83,69 @@ func TestPromoCodeService_ValidatePromoCode_InvalidCode_TooLong(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	service := NewPromoCodeService(db)
+	service := NewPromoCodeService(db, nil)
 
 	// Test with code that's too long (more than 10 characters)
-	valid, err := service.ValidatePromoCode("VERYLONGCODE")
+	_, valid, err := service.ValidatePromoCode("VERYLONGCODE")
 
 	// Assert
 	assert.NoError(t, err)
 	assert.False(t, valid)
 }
 
+func TestPromoCodeService_ValidatePromoCode_RejectedByFilter(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT ispopulated FROM pg_matviews").
+		WithArgs("valid_coupons").
+		WillReturnRows(sqlmock.NewRows([]string{"ispopulated"}).AddRow(false))
+	mock.ExpectQuery("SELECT reltuples::bigint FROM pg_class").
+		WillReturnRows(sqlmock.NewRows([]string{"reltuples"}).AddRow(4))
+	mock.ExpectQuery("SELECT upper\\(coupon\\) FROM coupons GROUP BY upper\\(coupon\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"coupon"}).AddRow("HAPPYHRS"))
+	mock.ExpectQuery("SELECT code FROM campaign_codes").
+		WithArgs("").
+		WillReturnRows(sqlmock.NewRows([]string{"code"}).AddRow("SUMABCDEFG"))
+	filter := couponfilter.New(repository.NewCouponRepository(db), couponfilter.Config{})
+	assert.NoError(t, filter.Load(context.Background()))
+
+	service := NewPromoCodeService(db, filter)
+
+	// Mock expectation: only the code the filter knows reaches the database
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("HAPPYHRS", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, nil, nil, nil, nil))
+
+	// Test
+	_, unknownValid, unknownErr := service.ValidatePromoCode("NOTACODE")
+	_, valid, err := service.ValidatePromoCode("HAPPYHRS")
+
+	// Assert
+	assert.NoError(t, unknownErr)
+	assert.False(t, unknownValid)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestPromoCodeService_ValidatePromoCode_InvalidCode_OnlyOneFile(t *testing.T) {
 	// Setup mock database
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
 	defer db.Close()
 
-	service := NewPromoCodeService(db)
+	service := NewPromoCodeService(db, nil)
 
 	// Mock expectation: code exists in only 1 file
-	mock.ExpectQuery("SELECT COUNT\\(DISTINCT file_name\\)").
-		WithArgs("ONLYONCE").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("ONLYONCE", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(1, nil, nil, nil, nil))
 
 	// Test
-	valid, err := service.ValidatePromoCode("ONLYONCE")
+	_, valid, err := service.ValidatePromoCode("ONLYONCE")
 
 	// Assert
 	assert.NoError(t, err)
@@ -84,21 +153,58 @@ func TestPromoCodeService_ValidatePromoCode_InvalidCode_OnlyOneFile(t *testing.T
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestPromoCodeService_ValidatePromoCode_HashLookup(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewPromoCodeService(db, nil)
+	service.SetLookup(PromoCodeLookupHash)
+
+	// Mock expectation: files are counted by equality, up to the second
+	mock.ExpectQuery("SELECT DISTINCT file_name FROM coupons WHERE upper\\(coupon\\) = \\$1 LIMIT \\$2").
+		WithArgs("HAPPYHRS", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, "percentage", 10.0, nil, nil))
+
+	// Test
+	promo, valid, err := service.ValidatePromoCode("HAPPYHRS")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, 10.0, promo.Discount.Value)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestParsePromoCodeLookup(t *testing.T) {
+	lookup, err := ParsePromoCodeLookup("")
+	assert.NoError(t, err)
+	assert.Equal(t, PromoCodeLookupCount, lookup)
+
+	lookup, err = ParsePromoCodeLookup("hash")
+	assert.NoError(t, err)
+	assert.Equal(t, PromoCodeLookupHash, lookup)
+
+	_, err = ParsePromoCodeLookup("btree")
+	assert.Error(t, err)
+}
+
 func TestPromoCodeService_ValidatePromoCode_InvalidCode_NotFound(t *testing.T) {
 	// Setup mock database
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
 	defer db.Close()
 
-	service := NewPromoCodeService(db)
+	service := NewPromoCodeService(db, nil)
 
 	// Mock expectation: code doesn't exist
-	mock.ExpectQuery("SELECT COUNT\\(DISTINCT file_name\\)").
-		WithArgs("NOTFOUND").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("NOTFOUND", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(0, nil, nil, nil, nil))
 
 	// Test
-	valid, err := service.ValidatePromoCode("NOTFOUND")
+	_, valid, err := service.ValidatePromoCode("NOTFOUND")
 
 	// Assert
 	assert.NoError(t, err)
@@ -112,15 +218,15 @@ func TestPromoCodeService_ValidatePromoCode_DatabaseError(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	service := NewPromoCodeService(db)
+	service := NewPromoCodeService(db, nil)
 
 	// Mock expectation: database error
-	mock.ExpectQuery("SELECT COUNT\\(DISTINCT file_name\\)").
-		WithArgs("TESTCODE").
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("TESTCODE", 2).
 		WillReturnError(sql.ErrConnDone)
 
 	// Test
-	valid, err := service.ValidatePromoCode("TESTCODE")
+	_, valid, err := service.ValidatePromoCode("TESTCODE")
 
 	// Assert
 	assert.Error(t, err)
@@ -135,15 +241,15 @@ func TestPromoCodeService_ValidatePromoCode_ExactlyTwoFiles(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	service := NewPromoCodeService(db)
+	service := NewPromoCodeService(db, nil)
 
 	// Mock expectation: code exists in exactly 2 files
-	mock.ExpectQuery("SELECT COUNT\\(DISTINCT file_name\\)").
-		WithArgs("TWOFILES").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("TWOFILES", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, nil, nil, nil, nil))
 
 	// Test
-	valid, err := service.ValidatePromoCode("TWOFILES")
+	_, valid, err := service.ValidatePromoCode("TWOFILES")
 
 	// Assert
 	assert.NoError(t, err)
@@ -157,15 +263,15 @@ func TestPromoCodeService_ValidatePromoCode_MoreThanTwoFiles(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	service := NewPromoCodeService(db)
+	service := NewPromoCodeService(db, nil)
 
 	// Mock expectation: code exists in 3 files (8 characters)
-	mock.ExpectQuery("SELECT COUNT\\(DISTINCT file_name\\)").
-		WithArgs("POPULAR1").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("POPULAR1", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(3, nil, nil, nil, nil))
 
 	// Test
-	valid, err := service.ValidatePromoCode("POPULAR1")
+	_, valid, err := service.ValidatePromoCode("POPULAR1")
 
 	// Assert
 	assert.NoError(t, err)
@@ -179,15 +285,15 @@ func TestPromoCodeService_ValidatePromoCode_MinimumLength(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	service := NewPromoCodeService(db)
+	service := NewPromoCodeService(db, nil)
 
 	// Mock expectation: code with exactly 8 characters exists in 2 files
-	mock.ExpectQuery("SELECT COUNT\\(DISTINCT file_name\\)").
-		WithArgs("EIGHTCHR").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("EIGHTCHR", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, nil, nil, nil, nil))
 
 	// Test
-	valid, err := service.ValidatePromoCode("EIGHTCHR")
+	_, valid, err := service.ValidatePromoCode("EIGHTCHR")
 
 	// Assert
 	assert.NoError(t, err)
@@ -201,18 +307,191 @@ func TestPromoCodeService_ValidatePromoCode_MaximumLength(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	service := NewPromoCodeService(db)
+	service := NewPromoCodeService(db, nil)
 
 	// Mock expectation: code with exactly 10 characters exists in 2 files
-	mock.ExpectQuery("SELECT COUNT\\(DISTINCT file_name\\)").
-		WithArgs("TENCHARS10").
-		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("TENCHARS10", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, nil, nil, nil, nil))
+
+	// Test
+	_, valid, err := service.ValidatePromoCode("TENCHARS10")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromoCodeService_ValidatePromoCode_WithDiscount(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewPromoCodeService(db, nil)
+
+	// Mock expectation: code exists in 2 files and takes 15% off
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("HAPPYHRS", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, "percentage", 15.0, nil, nil))
 
 	// Test
-	valid, err := service.ValidatePromoCode("TENCHARS10")
+	promo, valid, err := service.ValidatePromoCode("HAPPYHRS")
 
 	// Assert
 	assert.NoError(t, err)
 	assert.True(t, valid)
+	assert.Equal(t, "HAPPYHRS", promo.Code)
+	assert.Equal(t, &models.Discount{Type: models.DiscountTypePercentage, Value: 15}, promo.Discount)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestPromoCodeService_ValidatePromoCode_ScopedDiscount(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewPromoCodeService(db, nil)
+
+	// Mock expectation: code takes $2 off waffles only
+	mock.ExpectQuery("SELECT LEAST\\(COUNT\\(DISTINCT file_name\\), \\$2::int\\)").
+		WithArgs("WAFFLES22", 2).
+		WillReturnRows(sqlmock.NewRows(promoCodeRowColumns).AddRow(2, "fixed", 2.0, "{Waffle}", "{}"))
+
+	// Test
+	promo, valid, err := service.ValidatePromoCode("WAFFLES22")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, []string{"Waffle"}, promo.Discount.Categories)
+	assert.Empty(t, promo.Discount.ProductIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromoCodeService_SetDiscount(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewPromoCodeService(db, nil)
+	discount := models.Discount{Type: models.DiscountTypeFixed, Value: 5}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO coupon_discounts").
+		WithArgs("HAPPYHRS", models.DiscountTypeFixed, 5.0, sqlmock.AnyArg(), sqlmock.AnyArg(), "admin").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(models.AuditActionPromoCodeDiscount, "admin", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	// Test
+	promo, err := service.SetDiscount("HAPPYHRS", discount, "admin")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &discount, promo.Discount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromoCodeService_SetDiscount_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		discount models.Discount
+		wantErr  error
+	}{
+		{name: "short code", code: "SHORT", discount: models.Discount{Type: models.DiscountTypeFixed, Value: 5}, wantErr: ErrInvalidPromoCode},
+		{name: "over 100 percent", code: "HAPPYHRS", discount: models.Discount{Type: models.DiscountTypePercentage, Value: 120}, wantErr: ErrInvalidDiscount},
+		{name: "zero amount", code: "HAPPYHRS", discount: models.Discount{Type: models.DiscountTypeFixed}, wantErr: ErrInvalidDiscount},
+		{name: "unknown type", code: "HAPPYHRS", discount: models.Discount{Type: "bogo", Value: 1}, wantErr: ErrInvalidDiscount},
+		{name: "too many categories", code: "HAPPYHRS", discount: models.Discount{Type: models.DiscountTypeFixed, Value: 5, Categories: manyCategories(maxDiscountScope + 1)}, wantErr: ErrInvalidDiscount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			service := NewPromoCodeService(nil, nil)
+
+			// Test
+			_, err := service.SetDiscount(tt.code, tt.discount, "admin")
+
+			// Assert
+			assert.True(t, errors.Is(err, tt.wantErr))
+		})
+	}
+}
+
+// manyCategories returns n distinct category names
+func manyCategories(n int) []string {
+	categories := make([]string, n)
+	for i := range categories {
+		categories[i] = fmt.Sprintf("Category %d", i)
+	}
+	return categories
+}
+
+func TestPromoCodeService_RemoveDiscount_NotFound(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewPromoCodeService(db, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM coupon_discounts").
+		WithArgs("HAPPYHRS").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	// Test
+	err = service.RemoveDiscount("HAPPYHRS", "admin")
+
+	// Assert
+	assert.True(t, errors.Is(err, ErrDiscountNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromoCodeService_SetLimits(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewPromoCodeService(db, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO coupon_limits").
+		WithArgs("HAPPYHRS", 100, true, "admin").
+		WillReturnRows(sqlmock.NewRows([]string{"redemptions"}).AddRow(42))
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(models.AuditActionPromoCodeLimits, "admin", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	// Test
+	promo, err := service.SetLimits("HAPPYHRS", models.PromoCodeLimits{MaxRedemptions: 100, OncePerCustomer: true}, "admin")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &models.PromoCodeLimits{MaxRedemptions: 100, OncePerCustomer: true, Redemptions: 42}, promo.Limits)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPromoCodeService_SetLimits_Invalid(t *testing.T) {
+	// Setup
+	service := NewPromoCodeService(nil, nil)
+
+	// Test
+	_, shortErr := service.SetLimits("SHORT", models.PromoCodeLimits{MaxRedemptions: 1}, "admin")
+	_, negativeErr := service.SetLimits("HAPPYHRS", models.PromoCodeLimits{MaxRedemptions: -1}, "admin")
+
+	// Assert
+	assert.True(t, errors.Is(shortErr, ErrInvalidPromoCode))
+	assert.True(t, errors.Is(negativeErr, ErrInvalidLimits))
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+// Default rate limit applied to callers without an entry in api_quotas
+const (
+	DefaultRateLimitRequests = 600
+	DefaultRateLimitWindow   = time.Minute
+)
+
+// QuotaService enforces per-caller request quotas in fixed windows
+type QuotaService struct {
+	repo   *repository.QuotaRepository
+	limit  int
+	window time.Duration
+	now    func() time.Time
+}
+
+// NewQuotaService creates a new quota service allowing limit requests per
+// window unless a caller has its own limit
+func NewQuotaService(repo *repository.QuotaRepository, limit int, window time.Duration) *QuotaService {
+	return &QuotaService{repo: repo, limit: limit, window: window, now: time.Now}
+}
+
+// Consume counts a request by principal and returns its quota afterwards.
+// Remaining is negative once the quota is exceeded.
+func (s *QuotaService) Consume(principal string) (models.Quota, error) {
+	start := s.now().Truncate(s.window)
+	used, limit, err := s.repo.Consume(principal, start, s.limit)
+	if err != nil {
+		return models.Quota{}, err
+	}
+	return s.quota(start, used, limit), nil
+}
+
+func (s *QuotaService) quota(start time.Time, used, limit int) models.Quota {
+	return models.Quota{
+		Limit:     limit,
+		Remaining: limit - used,
+		Window:    int(s.window.Seconds()),
+		ResetAt:   start.Add(s.window),
+	}
+}
+
+// PruneUsage deletes the counters of finished windows. It is run by the
+// scheduler every window.
+func (s *QuotaService) PruneUsage(ctx context.Context) error {
+	_, err := s.repo.DeleteBefore(s.now().Truncate(s.window))
+	return err
+}
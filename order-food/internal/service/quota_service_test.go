@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaService_Consume(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewQuotaService(repository.NewQuotaRepository(db), 600, time.Minute)
+	now := time.Date(2024, 1, 1, 12, 30, 45, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	windowStart := time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC)
+
+	mock.ExpectQuery("INSERT INTO api_usage").
+		WithArgs("partner:p-1", windowStart, 600).
+		WillReturnRows(sqlmock.NewRows([]string{"requests", "limit"}).AddRow(101, 100))
+
+	// Test
+	quota, err := service.Consume("partner:p-1")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 100, quota.Limit)
+	assert.Equal(t, -1, quota.Remaining)
+	assert.Equal(t, 60, quota.Window)
+	assert.Equal(t, windowStart.Add(time.Minute), quota.ResetAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
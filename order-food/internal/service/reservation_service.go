@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+// DefaultReservationTTL is how long stock is held for a checkout
+const DefaultReservationTTL = 15 * time.Minute
+
+// ReservationService holds stock while a customer checks out, so two
+// customers cannot both buy the last unit during a slow payment
+type ReservationService struct {
+	repo *repository.ReservationRepository
+	ttl  time.Duration
+}
+
+// NewReservationService creates a new reservation service holding stock for ttl
+func NewReservationService(repo *repository.ReservationRepository, ttl time.Duration) *ReservationService {
+	return &ReservationService{repo: repo, ttl: ttl}
+}
+
+// Reserve holds the items until the reservation expires or an order is
+// placed with its ID. A *repository.StockError is returned when any item
+// exceeds the available stock, in which case nothing is held.
+func (s *ReservationService) Reserve(req models.ReservationReq) (models.Reservation, error) {
+	reservation := models.Reservation{
+		ID:    uuid.New().String(),
+		Items: mergeItems(req.Items),
+	}
+
+	expiresAt, err := s.repo.Reserve(reservation, s.ttl)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+	reservation.ExpiresAt = expiresAt
+
+	return reservation, nil
+}
+
+// GetReservation returns an unexpired reservation.
+// repository.ErrReservationNotFound is returned once it has expired or
+// been used by an order.
+func (s *ReservationService) GetReservation(ctx context.Context, id string) (models.Reservation, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// Release gives the stock of a reservation back before it expires, e.g.
+// when the customer empties the cart. repository.ErrReservationNotFound is
+// returned once it has expired or been used by an order.
+func (s *ReservationService) Release(ctx context.Context, id string) error {
+	return s.repo.Release(ctx, id)
+}
+
+// ReleaseExpired deletes expired reservations. It is run by the scheduler.
+func (s *ReservationService) ReleaseExpired(ctx context.Context) error {
+	count, err := s.repo.DeleteExpired()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		slog.Info("Stock reservation reaper released expired items", "items", count)
+	}
+	return nil
+}
+
+// mergeItems combines items for the same product, keeping first-seen order
+func mergeItems(items []models.OrderItem) []models.OrderItem {
+	merged := make([]models.OrderItem, 0, len(items))
+	index := make(map[string]int, len(items))
+	for _, item := range items {
+		if i, seen := index[item.ProductID]; seen {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[item.ProductID] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservationService_Reserve(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewReservationService(repository.NewReservationRepository(db), 10*time.Minute)
+	expiresAt := time.Now().Add(10 * time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, stock FROM products WHERE id = ANY\\(\\$1\\) ORDER BY id FOR UPDATE").
+		WithArgs(pq.Array([]string{"1", "2"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock"}).AddRow("1", 5).AddRow("2", nil))
+	mock.ExpectQuery("SELECT product_id, SUM\\(quantity\\) FROM stock_reservations").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "sum"}).AddRow("1", 2))
+	mock.ExpectQuery("INSERT INTO stock_reservations").
+		WithArgs(sqlmock.AnyArg(), "2", 4, 600.0).
+		WillReturnRows(sqlmock.NewRows([]string{"expires_at"}).AddRow(expiresAt))
+	mock.ExpectQuery("INSERT INTO stock_reservations").
+		WithArgs(sqlmock.AnyArg(), "1", 3, 600.0).
+		WillReturnRows(sqlmock.NewRows([]string{"expires_at"}).AddRow(expiresAt))
+	mock.ExpectCommit()
+
+	// Test: duplicate items are merged, product 2 does not track stock
+	reservation, err := service.Reserve(models.ReservationReq{Items: []models.OrderItem{
+		{ProductID: "2", Quantity: 4},
+		{ProductID: "1", Quantity: 1},
+		{ProductID: "1", Quantity: 2},
+	}})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotEmpty(t, reservation.ID)
+	assert.Equal(t, []models.OrderItem{{ProductID: "2", Quantity: 4}, {ProductID: "1", Quantity: 3}}, reservation.Items)
+	assert.Equal(t, expiresAt, reservation.ExpiresAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReservationService_Reserve_InsufficientStock(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewReservationService(repository.NewReservationRepository(db), 10*time.Minute)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id, stock FROM products").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock"}).AddRow("1", 1))
+	mock.ExpectQuery("SELECT product_id, SUM\\(quantity\\) FROM stock_reservations").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "sum"}).AddRow("1", 1))
+	mock.ExpectRollback()
+
+	// Test: the last unit is already held by another checkout
+	_, err = service.Reserve(models.ReservationReq{Items: []models.OrderItem{{ProductID: "1", Quantity: 1}}})
+
+	// Assert
+	var stockErr *repository.StockError
+	assert.True(t, errors.As(err, &stockErr))
+	assert.True(t, errors.Is(err, repository.ErrInsufficientStock))
+	assert.Equal(t, []models.StockShortage{{ProductID: "1", Requested: 1, Available: 0}}, stockErr.Shortages)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReservationService_GetReservation(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewReservationService(repository.NewReservationRepository(db), 10*time.Minute)
+	expiresAt := time.Now().Add(5 * time.Minute)
+
+	mock.ExpectQuery("SELECT product_id, quantity, expires_at FROM stock_reservations").
+		WithArgs("res-1").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "quantity", "expires_at"}).
+			AddRow("2", 4, expiresAt).
+			AddRow("1", 3, expiresAt))
+	mock.ExpectQuery("SELECT product_id, quantity, expires_at FROM stock_reservations").
+		WithArgs("res-2").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "quantity", "expires_at"}))
+
+	// Test
+	reservation, err := service.GetReservation(context.Background(), "res-1")
+	_, expiredErr := service.GetReservation(context.Background(), "res-2")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.Reservation{
+		ID:        "res-1",
+		Items:     []models.OrderItem{{ProductID: "2", Quantity: 4}, {ProductID: "1", Quantity: 3}},
+		ExpiresAt: expiresAt,
+	}, reservation)
+	assert.ErrorIs(t, expiredErr, repository.ErrReservationNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReservationService_Release(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewReservationService(repository.NewReservationRepository(db), 10*time.Minute)
+
+	mock.ExpectExec("DELETE FROM stock_reservations WHERE id = \\$1 AND expires_at > NOW\\(\\)").
+		WithArgs("res-1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM stock_reservations").
+		WithArgs("res-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Test: the second release finds nothing left to give back
+	err = service.Release(context.Background(), "res-1")
+	againErr := service.Release(context.Background(), "res-1")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.ErrorIs(t, againErr, repository.ErrReservationNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_PlaceOrder_ConsumesReservation(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 5.0, "Waffle", "", "", ""))
+	expectNoOptionGroups(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM stock_reservations WHERE id = \\$1 AND expires_at > NOW\\(\\)").
+		WithArgs("res-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id, stock FROM products").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock"}).AddRow("1", 2))
+	mock.ExpectQuery("SELECT product_id, SUM\\(quantity\\) FROM stock_reservations").
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "sum"}))
+	mock.ExpectExec("UPDATE products SET stock = stock - \\$2").
+		WithArgs("1", 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO order_items").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	// Test
+	order, err := service.PlaceOrder(context.Background(), models.OrderReq{
+		Items:         []models.OrderItem{{ProductID: "1", Quantity: 2}},
+		ReservationID: "res-1",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, order.Total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrderService_PlaceOrder_ExpiredReservation(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewOrderService(repository.NewTxManager(db), repository.NewOrderRepository(db), repository.NewProductRepository(db), repository.NewReservationRepository(db), nil, nil)
+
+	mock.ExpectQuery("SELECT .* FROM products WHERE id = ANY").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "category", "sku", "barcode", "description"}).
+			AddRow("1", "Waffle", 5.0, "Waffle", "", "", ""))
+	expectNoOptionGroups(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM stock_reservations").
+		WithArgs("res-1").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	// Test
+	_, err = service.PlaceOrder(context.Background(), models.OrderReq{
+		Items:         []models.OrderItem{{ProductID: "1", Quantity: 2}},
+		ReservationID: "res-1",
+	})
+
+	// Assert
+	assert.True(t, errors.Is(err, repository.ErrReservationNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
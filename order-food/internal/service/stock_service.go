@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+)
+
+// StockService lets admins count and correct the stock of products. Orders
+// take stock through OrderService, in the transaction that stores them.
+type StockService struct {
+	repo *repository.ReservationRepository
+}
+
+// NewStockService creates a new stock service
+func NewStockService(repo *repository.ReservationRepository) *StockService {
+	return &StockService{repo: repo}
+}
+
+// GetStock returns the stock of a product. repository.ErrProductNotFound is
+// returned when it does not exist.
+func (s *StockService) GetStock(ctx context.Context, productID string) (models.ProductStock, error) {
+	return s.repo.GetStock(ctx, productID)
+}
+
+// SetStock replaces the stock of a product on behalf of actor; a nil
+// req.Stock stops tracking it
+func (s *StockService) SetStock(ctx context.Context, productID string, req models.StockReq, actor string) (models.ProductStock, error) {
+	return s.repo.SetStock(ctx, models.StockChange{
+		ProductID: productID,
+		NewStock:  req.Stock,
+		Reason:    req.Reason,
+	}, actor)
+}
+
+// AdjustStock adds req.Delta to the stock of a product on behalf of actor.
+// repository.ErrStockNotTracked is returned for products that do not track
+// stock and repository.ErrNegativeStock when more is taken away than is on
+// hand.
+func (s *StockService) AdjustStock(ctx context.Context, productID string, req models.StockAdjustmentReq, actor string) (models.ProductStock, error) {
+	return s.repo.AdjustStock(ctx, models.StockChange{
+		ProductID: productID,
+		Delta:     req.Delta,
+		Reason:    req.Reason,
+	}, actor)
+}
+
+// CheckAvailability reports for each item whether an order for it would
+// be accepted now, in the order given, reading the stock of every product
+// in one query. A product listed twice must fit its combined quantity, as
+// in an order. IDs PostgreSQL cannot store as text, which no product can
+// have, are not looked up. Nothing is held, so a later order may still be
+// refused.
+func (s *StockService) CheckAvailability(ctx context.Context, items []models.AvailabilityItem) ([]models.ItemAvailability, error) {
+	requested := make(map[string]int)
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if _, seen := requested[item.ProductID]; !seen && utf8.ValidString(item.ProductID) && !strings.ContainsRune(item.ProductID, 0) {
+			ids = append(ids, item.ProductID)
+		}
+		requested[item.ProductID] += item.Quantity
+	}
+
+	stocks, err := s.repo.GetStocks(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.ItemAvailability, len(items))
+	for i, item := range items {
+		availability := models.ItemAvailability{ProductID: item.ProductID, Quantity: item.Quantity, Orderable: true}
+		stock, found := stocks[item.ProductID]
+		switch {
+		case !found:
+			availability.Orderable = false
+			availability.Reason = models.AvailabilityNotFound
+		case stock.Available != nil:
+			availability.Available = stock.Available
+			if requested[item.ProductID] > *stock.Available {
+				availability.Orderable = false
+				availability.Reason = models.AvailabilityInsufficientStock
+			}
+		}
+		result[i] = availability
+	}
+	return result, nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(v int) *int { return &v }
+
+func TestStockService_GetStock(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		want    models.ProductStock
+		wantErr error
+	}{
+		{
+			name: "tracked",
+			rows: sqlmock.NewRows([]string{"stock", "reserved"}).AddRow(5, 2),
+			want: models.ProductStock{ProductID: "1", Stock: intPtr(5), Reserved: 2, Available: intPtr(3)},
+		},
+		{
+			name: "more reserved than on hand",
+			rows: sqlmock.NewRows([]string{"stock", "reserved"}).AddRow(1, 2),
+			want: models.ProductStock{ProductID: "1", Stock: intPtr(1), Reserved: 2, Available: intPtr(0)},
+		},
+		{
+			name: "not tracked",
+			rows: sqlmock.NewRows([]string{"stock", "reserved"}).AddRow(nil, 0),
+			want: models.ProductStock{ProductID: "1"},
+		},
+		{
+			name:    "not found",
+			rows:    sqlmock.NewRows([]string{"stock", "reserved"}),
+			wantErr: repository.ErrProductNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			service := NewStockService(repository.NewReservationRepository(db))
+			mock.ExpectQuery("SELECT p.stock,.+FROM products p\\s+WHERE p.id = \\$1 AND p.deleted_at IS NULL$").
+				WithArgs("1").
+				WillReturnRows(tt.rows)
+
+			// Test
+			stock, err := service.GetStock(context.Background(), "1")
+
+			// Assert
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, stock)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStockService_SetStock(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewStockService(repository.NewReservationRepository(db))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT p.stock,.+ FOR UPDATE OF p").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"stock", "reserved"}).AddRow(nil, 2))
+	mock.ExpectExec("UPDATE products SET stock = \\$2 WHERE id = \\$1").
+		WithArgs("1", 40).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(models.AuditActionStockSet, "admin", []byte(`{"productId":"1","oldStock":null,"newStock":40,"reason":"Weekly stock count"}`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	// Test: an untracked product starts tracking stock
+	stock, err := service.SetStock(context.Background(), "1", models.StockReq{Stock: intPtr(40), Reason: "Weekly stock count"}, "admin")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.ProductStock{ProductID: "1", Stock: intPtr(40), Reserved: 2, Available: intPtr(38)}, stock)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStockService_AdjustStock(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewStockService(repository.NewReservationRepository(db))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT p.stock,.+ FOR UPDATE OF p").
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"stock", "reserved"}).AddRow(5, 2))
+	mock.ExpectExec("UPDATE products SET stock = \\$2 WHERE id = \\$1").
+		WithArgs("1", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO audit_log").
+		WithArgs(models.AuditActionStockAdjust, "admin", []byte(`{"productId":"1","delta":-2,"oldStock":5,"newStock":3,"reason":"Breakage"}`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	// Test
+	stock, err := service.AdjustStock(context.Background(), "1", models.StockAdjustmentReq{Delta: -2, Reason: "Breakage"}, "admin")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, models.ProductStock{ProductID: "1", Stock: intPtr(3), Reserved: 2, Available: intPtr(1)}, stock)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStockService_AdjustStock_Refused(t *testing.T) {
+	tests := []struct {
+		name    string
+		stock   any
+		wantErr error
+	}{
+		{name: "below zero", stock: 1, wantErr: repository.ErrNegativeStock},
+		{name: "not tracked", stock: nil, wantErr: repository.ErrStockNotTracked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup mock database
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			service := NewStockService(repository.NewReservationRepository(db))
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT p.stock,.+ FOR UPDATE OF p").
+				WillReturnRows(sqlmock.NewRows([]string{"stock", "reserved"}).AddRow(tt.stock, 0))
+			mock.ExpectRollback()
+
+			// Test: nothing is written
+			_, err = service.AdjustStock(context.Background(), "1", models.StockAdjustmentReq{Delta: -2}, "admin")
+
+			// Assert
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStockService_CheckAvailability(t *testing.T) {
+	// Setup mock database
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	service := NewStockService(repository.NewReservationRepository(db))
+	// One query reads every product, each listed once
+	mock.ExpectQuery("SELECT p.id, p.stock").
+		WithArgs(pq.Array([]string{"1", "2", "3", "4"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "stock", "reserved"}).
+			AddRow("1", 5, 2).
+			AddRow("2", nil, 0).
+			AddRow("4", 3, 0))
+
+	// Test: product 1 is listed twice, 3 does not exist
+	items, err := service.CheckAvailability(context.Background(), []models.AvailabilityItem{
+		{ProductID: "1", Quantity: 2},
+		{ProductID: "2", Quantity: 50},
+		{ProductID: "1", Quantity: 2},
+		{ProductID: "3", Quantity: 1},
+		{ProductID: "4", Quantity: 3},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ItemAvailability{
+		{ProductID: "1", Quantity: 2, Available: intPtr(3), Reason: models.AvailabilityInsufficientStock},
+		{ProductID: "2", Quantity: 50, Orderable: true},
+		{ProductID: "1", Quantity: 2, Available: intPtr(3), Reason: models.AvailabilityInsufficientStock},
+		{ProductID: "3", Quantity: 1, Reason: models.AvailabilityNotFound},
+		{ProductID: "4", Quantity: 3, Orderable: true, Available: intPtr(3)},
+	}, items)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
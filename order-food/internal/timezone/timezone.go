@@ -0,0 +1,73 @@
+// Package timezone resolves the time zone of each business location.
+// Timestamps are stored and exchanged in UTC; a zone is only applied where
+// a calendar day matters, such as interpreting a date a user typed or
+// bucketing a report by day, so "1 March" means 1 March where the store is.
+package timezone
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Zones maps location codes, such as POS store codes, to time zones
+type Zones struct {
+	defaultZone *time.Location
+	locations   map[string]*time.Location
+}
+
+// Parse builds Zones from an IANA zone name used for unlisted locations
+// and a comma-separated list of code=zone pairs, e.g.
+// "store-001=America/New_York,store-002=Europe/Berlin"
+func Parse(defaultName, locations string) (*Zones, error) {
+	defaultZone, err := time.LoadLocation(defaultName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", defaultName, err)
+	}
+	if defaultZone == time.Local {
+		return nil, fmt.Errorf("time zone %q depends on the server; name a zone such as UTC", defaultName)
+	}
+
+	z := &Zones{defaultZone: defaultZone, locations: make(map[string]*time.Location)}
+	for _, pair := range strings.Split(locations, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		code, name, ok := strings.Cut(pair, "=")
+		code, name = strings.TrimSpace(code), strings.TrimSpace(name)
+		if !ok || code == "" || name == "" {
+			return nil, fmt.Errorf("invalid location time zone %q, want code=zone", pair)
+		}
+		zone, err := time.LoadLocation(name)
+		if err != nil || zone == time.Local {
+			return nil, fmt.Errorf("invalid time zone %q for location %s", name, code)
+		}
+		z.locations[code] = zone
+	}
+	return z, nil
+}
+
+// Default returns the zone of locations without their own zone
+func (z *Zones) Default() *time.Location {
+	return z.defaultZone
+}
+
+// Lookup returns the zone of location, or the default zone when location
+// is empty. It reports false for locations that are not configured.
+func (z *Zones) Lookup(location string) (*time.Location, bool) {
+	if location == "" {
+		return z.defaultZone, true
+	}
+	zone, ok := z.locations[location]
+	return zone, ok
+}
+
+// ParseDate accepts an RFC 3339 timestamp, or a YYYY-MM-DD date which is
+// taken as the start of that day in zone
+func ParseDate(value string, zone *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(zone), nil
+	}
+	return time.ParseInLocation(time.DateOnly, value, zone)
+}
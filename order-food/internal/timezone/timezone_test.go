@@ -0,0 +1,68 @@
+package timezone
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	// Setup
+	zones, err := Parse("America/New_York", "store-001=Europe/Berlin, store-002 = Asia/Tokyo")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "America/New_York", zones.Default().String())
+
+	zone, ok := zones.Lookup("store-002")
+	assert.True(t, ok)
+	assert.Equal(t, "Asia/Tokyo", zone.String())
+
+	zone, ok = zones.Lookup("")
+	assert.True(t, ok)
+	assert.Equal(t, zones.Default(), zone)
+
+	_, ok = zones.Lookup("store-999")
+	assert.False(t, ok)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		defaultName string
+		locations   string
+	}{
+		{name: "unknown default", defaultName: "Mars/Olympus", locations: ""},
+		{name: "server local", defaultName: "Local", locations: ""},
+		{name: "unknown location zone", defaultName: "UTC", locations: "store-001=Nowhere"},
+		{name: "missing zone", defaultName: "UTC", locations: "store-001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Execute
+			_, err := Parse(tt.defaultName, tt.locations)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	// Setup
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	// Execute
+	day, err := ParseDate("2024-03-10", newYork)
+	assert.NoError(t, err)
+	instant, err := ParseDate("2024-03-10T12:00:00Z", newYork)
+	assert.NoError(t, err)
+
+	// Assert: a date is midnight where the store is, not midnight UTC
+	assert.Equal(t, time.Date(2024, 3, 10, 5, 0, 0, 0, time.UTC), day.UTC())
+	assert.Equal(t, time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC), instant.UTC())
+	assert.Equal(t, newYork, instant.Location())
+}
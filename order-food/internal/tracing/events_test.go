@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEvents_RecordedOnCurrentSpan(t *testing.T) {
+	// Setup
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "POST /api/v1/orders")
+
+	// Execute
+	CouponValidated(ctx, true)
+	PaymentAuthorized(ctx, "mock", "pi_1", 2450)
+	OrderCommitted(ctx, OrderSourceAPI, "order-1", 2, 24.5)
+	span.End()
+
+	// Assert
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	events := spans[0].Events()
+	assert.Len(t, events, 3)
+	assert.Equal(t, EventCouponValidated, events[0].Name)
+	assert.Equal(t, []attribute.KeyValue{AttrCouponValid.Bool(true)}, events[0].Attributes)
+	assert.Equal(t, EventPaymentAuthorized, events[1].Name)
+	assert.Contains(t, events[1].Attributes, AttrPaymentAmount.Int64(2450))
+	assert.Equal(t, EventOrderCommitted, events[2].Name)
+	assert.Contains(t, events[2].Attributes, AttrOrderID.String("order-1"))
+	assert.Contains(t, events[2].Attributes, AttrOrderTotal.Float64(24.5))
+}
+
+func TestEvents_NoopWithoutSpan(t *testing.T) {
+	// Execute and Assert: recording outside a trace must not panic
+	assert.NotPanics(t, func() {
+		CouponValidated(context.Background(), false)
+		OrderCommitted(context.Background(), OrderSourcePOS, "order-1", 1, 1)
+	})
+}
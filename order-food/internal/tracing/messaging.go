@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName identifies spans started by order-food
+const TracerName = "github.com/shyampundkar/kart-challenge-workspace/order-food"
+
+// Messaging operation types, as named by the OpenTelemetry messaging
+// conventions
+const (
+	MessagingCreate = "create"
+	MessagingSend   = "send"
+)
+
+// Attribute keys of messaging spans
+const (
+	AttrMessagingOperation   = attribute.Key("messaging.operation.type")
+	AttrMessagingDestination = attribute.Key("messaging.destination.name")
+	AttrMessagingMessageID   = attribute.Key("messaging.message.id")
+	AttrMessagingBatchCount  = attribute.Key("messaging.batch.message_count")
+	AttrEventType            = attribute.Key("event.type")
+	// AttrEventQueueSeconds is how long the oldest message of a batch
+	// waited to be sent
+	AttrEventQueueSeconds = attribute.Key("event.queue_seconds")
+)
+
+// StartCreate starts the producer span of a message queued for background
+// delivery, as a child of the span in ctx, so the request that caused the
+// message shows when it was queued. The span's context is what consumers
+// of the message continue from; see InjectMessage.
+func StartCreate(ctx context.Context, tracer trace.Tracer, destination, messageID, eventType string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, MessagingCreate+" "+destination,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			AttrMessagingOperation.String(MessagingCreate),
+			AttrMessagingDestination.String(destination),
+			AttrMessagingMessageID.String(messageID),
+			AttrEventType.String(eventType),
+		),
+	)
+}
+
+// StartSend starts the span of a background job delivering a batch of
+// messages. Batches mix messages of many requests, so the span starts a
+// trace of its own and links to the create span of every message: from
+// any request, the links lead to the send that delivered its message, and
+// back. ctx only bounds the job.
+func StartSend(ctx context.Context, tracer trace.Tracer, creates []trace.SpanContext) (context.Context, trace.Span) {
+	links := make([]trace.Link, 0, len(creates))
+	for _, sc := range creates {
+		if sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+	return tracer.Start(ctx, MessagingSend,
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithLinks(links...),
+		trace.WithAttributes(
+			AttrMessagingOperation.String(MessagingSend),
+			AttrMessagingBatchCount.Int(len(creates)),
+		),
+	)
+}
+
+// InjectMessage writes the trace context of a message's create span into
+// its headers, so consumers can start their spans as its children
+func InjectMessage(create trace.SpanContext, headers map[string]string) {
+	if !create.IsValid() {
+		return
+	}
+	ctx := trace.ContextWithSpanContext(context.Background(), create)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+}
+
+// Fail marks span as failed with err
+func Fail(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMessaging_CreateAndSendSpans(t *testing.T) {
+	// Setup
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	ctx, request := tracer.Start(context.Background(), "POST /api/v1/orders")
+
+	// Execute
+	_, create := StartCreate(ctx, tracer, "order-food.orders", "event-1", "OrderCreated")
+	create.End()
+	request.End()
+	_, send := StartSend(context.Background(), tracer, []trace.SpanContext{create.SpanContext(), {}})
+	send.End()
+
+	// Assert
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	assert.Equal(t, "create order-food.orders", spans[0].Name())
+	assert.Equal(t, trace.SpanKindProducer, spans[0].SpanKind())
+	assert.Equal(t, request.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Contains(t, spans[0].Attributes(), AttrMessagingMessageID.String("event-1"))
+
+	assert.Equal(t, MessagingSend, spans[2].Name())
+	assert.False(t, spans[2].Parent().IsValid(), "a send starts a trace of its own")
+	if assert.Len(t, spans[2].Links(), 1, "invalid span contexts are not linked") {
+		assert.Equal(t, create.SpanContext().SpanID(), spans[2].Links()[0].SpanContext.SpanID())
+	}
+	assert.Contains(t, spans[2].Attributes(), AttrMessagingBatchCount.Int(2))
+}
+
+func TestInjectMessage(t *testing.T) {
+	// Setup
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previous)
+	tracer := sdktrace.NewTracerProvider().Tracer("test")
+	_, span := tracer.Start(context.Background(), "create order-food.orders")
+	span.End()
+
+	// Execute
+	headers := map[string]string{"event-id": "event-1"}
+	InjectMessage(span.SpanContext(), headers)
+	untraced := map[string]string{}
+	InjectMessage(trace.SpanContext{}, untraced)
+
+	// Assert
+	assert.Equal(t, "00-"+span.SpanContext().TraceID().String()+"-"+span.SpanContext().SpanID().String()+"-01", headers["traceparent"])
+	assert.Equal(t, "event-1", headers["event-id"])
+	assert.Empty(t, untraced)
+}
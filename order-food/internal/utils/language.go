@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// languagesKey is the context key holding the parsed Languages
+const languagesKey = "languages"
+
+// DefaultLanguage is the language of product names stored on products when
+// none has been configured
+const DefaultLanguage = "en"
+
+// Languages holds the languages a request accepts, most preferred first
+type Languages struct {
+	// Fallbacks lists the accepted language tags in lower case, each
+	// followed by its less specific forms (fr-ca, then fr)
+	Fallbacks []string
+	// Default is the language of the untranslated product names
+	Default string
+}
+
+// ParseAcceptLanguage parses an Accept-Language header into a fallback
+// chain. Tags are ordered by quality, keeping the header order for equal
+// qualities, and each is followed by its less specific forms. Wildcards
+// and tags with a quality of 0 are left out.
+func ParseAcceptLanguage(header, defaultLanguage string) Languages {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = q
+		}
+		if quality <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag, quality})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].quality > tags[j].quality })
+
+	languages := Languages{Default: strings.ToLower(defaultLanguage)}
+	seen := make(map[string]bool)
+	for _, t := range tags {
+		for tag := t.tag; tag != ""; {
+			if !seen[tag] {
+				seen[tag] = true
+				languages.Fallbacks = append(languages.Fallbacks, tag)
+			}
+			i := strings.LastIndex(tag, "-")
+			if i < 0 {
+				break
+			}
+			tag = tag[:i]
+		}
+	}
+	return languages
+}
+
+// SetLanguages stores the parsed languages in the request's values
+func SetLanguages(c httpx.Values, languages Languages) {
+	c.Set(languagesKey, languages)
+}
+
+// LanguagesFromContext returns the languages parsed by the language
+// middleware, parsing the header with DefaultLanguage if it did not run
+func LanguagesFromContext(c httpx.Context) Languages {
+	if value, ok := c.Get(languagesKey); ok {
+		if languages, ok := value.(Languages); ok {
+			return languages
+		}
+	}
+	return ParseAcceptLanguage(c.GetHeader("Accept-Language"), DefaultLanguage)
+}
+
+// LocalizeProduct returns product with the name and description of the
+// first language in the fallback chain it has a translation for. The
+// untranslated name is kept when the chain reaches the default language
+// first or has no match. Language is set to the language used.
+func LocalizeProduct(product models.Product, languages Languages) models.Product {
+	product.Language = languages.Default
+	for _, tag := range languages.Fallbacks {
+		if tag == languages.Default {
+			break
+		}
+		translation, ok := product.Translations[tag]
+		if !ok {
+			continue
+		}
+		product.Name = translation.Name
+		if translation.Description != "" {
+			product.Description = translation.Description
+		}
+		product.Language = tag
+		break
+	}
+	return product
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []string
+	}{
+		{name: "empty", header: "", want: nil},
+		{name: "single tag", header: "fr", want: []string{"fr"}},
+		{name: "region falls back to language", header: "pt-BR", want: []string{"pt-br", "pt"}},
+		{name: "ordered by quality", header: "en;q=0.5, de-AT, fr;q=0.8", want: []string{"de-at", "de", "fr", "en"}},
+		{name: "equal qualities keep header order", header: "es, it", want: []string{"es", "it"}},
+		{name: "duplicates dropped", header: "fr-CA, fr-BE, fr", want: []string{"fr-ca", "fr", "fr-be"}},
+		{name: "wildcard and zero quality dropped", header: "*, de;q=0, nl", want: []string{"nl"}},
+		{name: "invalid quality dropped", header: "de;q=high, nl", want: []string{"nl"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseAcceptLanguage(tt.header, "EN")
+
+			assert.Equal(t, "en", got.Default)
+			assert.Equal(t, tt.want, got.Fallbacks)
+		})
+	}
+}
+
+func TestLocalizeProduct(t *testing.T) {
+	product := models.Product{
+		ID:          "1",
+		Name:        "Chicken Waffle",
+		Description: "Crispy chicken on a waffle",
+		Translations: map[string]models.ProductTranslation{
+			"fr":    {Name: "Gaufre au poulet", Description: "Poulet croustillant sur une gaufre"},
+			"fr-ca": {Name: "Gaufre au poulet frit"},
+			"de":    {Name: "Hähnchen-Waffel"},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		header          string
+		wantName        string
+		wantDescription string
+		wantLanguage    string
+	}{
+		{name: "no preference", header: "", wantName: "Chicken Waffle", wantDescription: "Crispy chicken on a waffle", wantLanguage: "en"},
+		{name: "exact match", header: "fr", wantName: "Gaufre au poulet", wantDescription: "Poulet croustillant sur une gaufre", wantLanguage: "fr"},
+		{name: "description falls back to default", header: "fr-CA", wantName: "Gaufre au poulet frit", wantDescription: "Crispy chicken on a waffle", wantLanguage: "fr-ca"},
+		{name: "region falls back to language", header: "de-CH", wantName: "Hähnchen-Waffel", wantDescription: "Crispy chicken on a waffle", wantLanguage: "de"},
+		{name: "next language tried", header: "es, de;q=0.5", wantName: "Hähnchen-Waffel", wantDescription: "Crispy chicken on a waffle", wantLanguage: "de"},
+		{name: "default language preferred", header: "en-GB, fr;q=0.5", wantName: "Chicken Waffle", wantDescription: "Crispy chicken on a waffle", wantLanguage: "en"},
+		{name: "no translation", header: "ja", wantName: "Chicken Waffle", wantDescription: "Crispy chicken on a waffle", wantLanguage: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LocalizeProduct(product, ParseAcceptLanguage(tt.header, DefaultLanguage))
+
+			assert.Equal(t, tt.wantName, got.Name)
+			assert.Equal(t, tt.wantDescription, got.Description)
+			assert.Equal(t, tt.wantLanguage, got.Language)
+		})
+	}
+}
@@ -2,25 +2,138 @@ package utils
 
 import (
 	"fmt"
+	"math"
+	"net/url"
 	"strconv"
+	"strings"
 
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
 	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
 )
 
+// paginationKey is the context key holding the parsed Pagination
+const paginationKey = "pagination"
+
+// PaginationConfig holds the default page size and the hard cap on perPage
+type PaginationConfig struct {
+	DefaultPerPage int
+	MaxPerPage     int
+}
+
+// DefaultPaginationConfig is used when no configuration has been supplied
+var DefaultPaginationConfig = PaginationConfig{
+	DefaultPerPage: 10,
+	MaxPerPage:     100,
+}
+
+// Pagination holds the parsed pagination parameters of a list request
+type Pagination struct {
+	Page    int
+	PerPage int
+	Offset  int
+	// Cursor is set when the request asked for a page after a cursor
+	// rather than by number; PerPage then holds its limit
+	Cursor bool
+	After  string
+}
+
+// ParsePagination parses page and perPage query values. Missing or invalid
+// values fall back to the defaults and perPage is capped at MaxPerPage.
+// Pages so far out that their offset would overflow are clamped to the
+// last page that can be addressed, which is empty anyway.
+func ParsePagination(pageStr, perPageStr string, cfg PaginationConfig) Pagination {
+	page := ParseInt(pageStr, 1)
+	perPage := ParseInt(perPageStr, cfg.DefaultPerPage)
+	if cfg.MaxPerPage > 0 && perPage > cfg.MaxPerPage {
+		perPage = cfg.MaxPerPage
+	}
+	if perPage > 0 && page > math.MaxInt64/perPage {
+		page = math.MaxInt64 / perPage
+	}
+
+	return Pagination{
+		Page:    page,
+		PerPage: perPage,
+		Offset:  (page - 1) * perPage,
+	}
+}
+
+// ParseCursor parses the after and limit query values of cursor
+// pagination. It reports false when neither is given, so the request is
+// paginated by page number. The limit is defaulted and capped like perPage.
+func ParseCursor(after, limitStr string, cfg PaginationConfig) (Pagination, bool) {
+	if after == "" && limitStr == "" {
+		return Pagination{}, false
+	}
+	p := ParsePagination("", limitStr, cfg)
+	p.Cursor = true
+	p.After = after
+	return p, true
+}
+
+// ParseRequestPagination parses the pagination parameters of a list
+// request, preferring cursor pagination when it is asked for
+func ParseRequestPagination(query url.Values, cfg PaginationConfig) Pagination {
+	if p, ok := ParseCursor(query.Get("after"), query.Get("limit"), cfg); ok {
+		return p
+	}
+	return ParsePagination(query.Get("page"), query.Get("perPage"), cfg)
+}
+
+// SetPagination stores parsed pagination parameters in the request's
+// values
+func SetPagination(c httpx.Values, p Pagination) {
+	c.Set(paginationKey, p)
+}
+
+// PaginationFromContext returns the pagination parameters parsed by the
+// pagination middleware, parsing them with the defaults if it did not run
+func PaginationFromContext(c httpx.Context) Pagination {
+	if value, ok := c.Get(paginationKey); ok {
+		if p, ok := value.(Pagination); ok {
+			return p
+		}
+	}
+	return ParseRequestPagination(c.Request().URL.Query(), DefaultPaginationConfig)
+}
+
+// TotalPages returns the number of pages needed for total items, never less than 1
+func TotalPages(total, perPage int) int {
+	totalPages := (total + perPage - 1) / perPage
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	return totalPages
+}
+
 // BuildPaginationLinks creates HATEOAS links for pagination
 func BuildPaginationLinks(page, totalPages int, basePath string, perPage int) []models.Link {
+	return BuildPaginationLinksWithQuery(page, totalPages, basePath, perPage, nil)
+}
+
+// BuildPaginationLinksWithQuery creates HATEOAS links for pagination that
+// carry extra query parameters (such as sort) through to every page
+func BuildPaginationLinksWithQuery(page, totalPages int, basePath string, perPage int, query url.Values) []models.Link {
+	extra := ""
+	if encoded := query.Encode(); encoded != "" {
+		extra = "&" + encoded
+	}
+	href := func(p int) string {
+		return fmt.Sprintf("%s?page=%d&perPage=%d%s", basePath, p, perPage, extra)
+	}
+
 	links := []models.Link{
-		{Href: fmt.Sprintf("%s?page=%d&perPage=%d", basePath, page, perPage), Rel: "self", Method: "GET"},
+		{Href: href(page), Rel: "self", Method: "GET"},
 	}
 
 	if page > 1 {
 		links = append(links, models.Link{
-			Href:   fmt.Sprintf("%s?page=1&perPage=%d", basePath, perPage),
+			Href:   href(1),
 			Rel:    "first",
 			Method: "GET",
 		})
 		links = append(links, models.Link{
-			Href:   fmt.Sprintf("%s?page=%d&perPage=%d", basePath, page-1, perPage),
+			Href:   href(page - 1),
 			Rel:    "prev",
 			Method: "GET",
 		})
@@ -28,12 +141,12 @@ func BuildPaginationLinks(page, totalPages int, basePath string, perPage int) []
 
 	if page < totalPages {
 		links = append(links, models.Link{
-			Href:   fmt.Sprintf("%s?page=%d&perPage=%d", basePath, page+1, perPage),
+			Href:   href(page + 1),
 			Rel:    "next",
 			Method: "GET",
 		})
 		links = append(links, models.Link{
-			Href:   fmt.Sprintf("%s?page=%d&perPage=%d", basePath, totalPages, perPage),
+			Href:   href(totalPages),
 			Rel:    "last",
 			Method: "GET",
 		})
@@ -42,6 +155,58 @@ func BuildPaginationLinks(page, totalPages int, basePath string, perPage int) []
 	return links
 }
 
+// BuildCursorLinks creates HATEOAS links for a page of cursor pagination
+// that carry extra query parameters through. The next link is left out on
+// the last page, when next is empty.
+func BuildCursorLinks(p Pagination, next, basePath string, query url.Values) []models.Link {
+	href := func(after string) string {
+		params := url.Values{}
+		for key, values := range query {
+			params[key] = values
+		}
+		if after != "" {
+			params.Set("after", after)
+		}
+		params.Set("limit", strconv.Itoa(p.PerPage))
+		return basePath + "?" + params.Encode()
+	}
+
+	links := []models.Link{
+		{Href: href(p.After), Rel: "self", Method: "GET"},
+	}
+	if p.After != "" {
+		links = append(links, models.Link{Href: href(""), Rel: "first", Method: "GET"})
+	}
+	if next != "" {
+		links = append(links, models.Link{Href: href(next), Rel: "next", Method: "GET"})
+	}
+	return links
+}
+
+// linkHeaderRels are the pagination relations mirrored into the Link header
+var linkHeaderRels = map[string]bool{"first": true, "prev": true, "next": true, "last": true}
+
+// FormatLinkHeader renders the first/prev/next/last pagination links as an
+// RFC 8288 Link header value. It returns an empty string when there are none.
+func FormatLinkHeader(links []models.Link) string {
+	parts := make([]string, 0, len(links))
+	for _, link := range links {
+		if !linkHeaderRels[link.Rel] {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("<%s>; rel=%q", link.Href, link.Rel))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SetLinkHeader writes the pagination links to the response Link header so
+// generic HTTP clients can paginate without parsing the response body
+func SetLinkHeader(c httpx.Context, links []models.Link) {
+	if header := FormatLinkHeader(links); header != "" {
+		c.Header("Link", header)
+	}
+}
+
 // ParseInt parses a string to int with a default value
 func ParseInt(s string, defaultValue int) int {
 	if s == "" {
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"net/url"
 	"testing"
 
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -140,3 +142,99 @@ func TestBuildPaginationLinks_SecondPageOfTwo(t *testing.T) {
 	assert.Equal(t, "first", links[1].Rel)
 	assert.Equal(t, "prev", links[2].Rel)
 }
+
+func TestParsePagination_Defaults(t *testing.T) {
+	p := ParsePagination("", "", DefaultPaginationConfig)
+
+	assert.Equal(t, 1, p.Page)
+	assert.Equal(t, 10, p.PerPage)
+	assert.Equal(t, 0, p.Offset)
+}
+
+func TestParsePagination_ComputesOffset(t *testing.T) {
+	p := ParsePagination("3", "20", DefaultPaginationConfig)
+
+	assert.Equal(t, 3, p.Page)
+	assert.Equal(t, 20, p.PerPage)
+	assert.Equal(t, 40, p.Offset)
+}
+
+func TestParsePagination_CapsPerPage(t *testing.T) {
+	p := ParsePagination("1", "500", PaginationConfig{DefaultPerPage: 10, MaxPerPage: 100})
+
+	assert.Equal(t, 100, p.PerPage, "Should cap perPage at MaxPerPage")
+}
+
+func TestParsePagination_NoCapWhenZero(t *testing.T) {
+	p := ParsePagination("1", "500", PaginationConfig{DefaultPerPage: 10})
+
+	assert.Equal(t, 500, p.PerPage)
+}
+
+func TestParsePagination_ClampsOverflowingPage(t *testing.T) {
+	p := ParsePagination("9223372036854775807", "100", DefaultPaginationConfig)
+	assert.GreaterOrEqual(t, p.Offset, 0)
+	assert.Less(t, p.Page, 9223372036854775807)
+}
+
+func TestTotalPages(t *testing.T) {
+	assert.Equal(t, 1, TotalPages(0, 10), "Should never return less than one page")
+	assert.Equal(t, 1, TotalPages(10, 10))
+	assert.Equal(t, 3, TotalPages(11, 5))
+}
+
+func TestFormatLinkHeader_MiddlePage(t *testing.T) {
+	links := BuildPaginationLinks(3, 5, "/api/v1/products", 10)
+
+	header := FormatLinkHeader(links)
+
+	assert.Equal(t,
+		`</api/v1/products?page=1&perPage=10>; rel="first", `+
+			`</api/v1/products?page=2&perPage=10>; rel="prev", `+
+			`</api/v1/products?page=4&perPage=10>; rel="next", `+
+			`</api/v1/products?page=5&perPage=10>; rel="last"`,
+		header)
+}
+
+func TestFormatLinkHeader_SinglePage(t *testing.T) {
+	links := BuildPaginationLinks(1, 1, "/api/v1/products", 10)
+
+	assert.Empty(t, FormatLinkHeader(links), "Self link alone should not produce a Link header")
+}
+
+func TestBuildPaginationLinksWithQuery_CarriesQuery(t *testing.T) {
+	links := BuildPaginationLinksWithQuery(2, 3, "/api/v1/products", 10, url.Values{"sort": {"-price"}})
+
+	for _, link := range links {
+		assert.Contains(t, link.Href, "&sort=-price", "Failed for rel: "+link.Rel)
+	}
+	assert.Equal(t, "/api/v1/products?page=2&perPage=10&sort=-price", links[0].Href)
+}
+
+func TestParseCursor(t *testing.T) {
+	_, ok := ParseCursor("", "", DefaultPaginationConfig)
+	assert.False(t, ok, "without after or limit pages are numbered")
+
+	p, ok := ParseCursor("abc", "", DefaultPaginationConfig)
+	assert.True(t, ok)
+	assert.Equal(t, Pagination{Page: 1, PerPage: 10, Cursor: true, After: "abc"}, p)
+
+	p, ok = ParseCursor("", "500", DefaultPaginationConfig)
+	assert.True(t, ok)
+	assert.Equal(t, 100, p.PerPage)
+}
+
+func TestBuildCursorLinks(t *testing.T) {
+	p := Pagination{PerPage: 20, Cursor: true, After: "abc"}
+
+	links := BuildCursorLinks(p, "def", "/api/v1/orders", url.Values{"sort": {"-total"}})
+	assert.Equal(t, []models.Link{
+		{Href: "/api/v1/orders?after=abc&limit=20&sort=-total", Rel: "self", Method: "GET"},
+		{Href: "/api/v1/orders?limit=20&sort=-total", Rel: "first", Method: "GET"},
+		{Href: "/api/v1/orders?after=def&limit=20&sort=-total", Rel: "next", Method: "GET"},
+	}, links)
+
+	// The last page has no next link
+	links = BuildCursorLinks(p, "", "/api/v1/orders", nil)
+	assert.Len(t, links, 2)
+}
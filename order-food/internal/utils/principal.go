@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+)
+
+const (
+	// principalKey is the context key holding the authenticated caller
+	principalKey = "principal"
+	// scopesKey is the context key holding the caller's granted scopes
+	scopesKey = "scopes"
+	// ScopeAll grants every scope; it is held by the built-in API key
+	ScopeAll = "*"
+)
+
+// SetPrincipal stores the authenticated caller and its scopes in the
+// request's values
+func SetPrincipal(c httpx.Values, principal string, scopes []string) {
+	c.Set(principalKey, principal)
+	c.Set(scopesKey, scopes)
+}
+
+// PrincipalFromContext returns the authenticated caller, or "" when the
+// request was not authenticated
+func PrincipalFromContext(c httpx.Values) string {
+	value, _ := c.Get(principalKey)
+	principal, _ := value.(string)
+	return principal
+}
+
+// HasScope reports whether the authenticated caller was granted scope
+func HasScope(c httpx.Values, scope string) bool {
+	value, _ := c.Get(scopesKey)
+	scopes, _ := value.([]string)
+	for _, granted := range scopes {
+		if granted == scope || granted == ScopeAll {
+			return true
+		}
+	}
+	return false
+}
+
+// PartnerPrincipal returns the principal of a caller using a partner API key
+func PartnerPrincipal(partnerID string) string {
+	return "partner:" + partnerID
+}
+
+// CustomerPrincipal returns the principal of a customer using an access token
+func CustomerPrincipal(customerID string) string {
+	return "customer:" + customerID
+}
+
+// CustomerFromContext returns the ID of the customer the request was
+// authenticated as, or "" when the caller is not a customer
+func CustomerFromContext(c httpx.Values) string {
+	id, ok := strings.CutPrefix(PrincipalFromContext(c), CustomerPrincipal(""))
+	if !ok {
+		return ""
+	}
+	return id
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// quotaKey is the context key holding the caller's rate limit quota
+const quotaKey = "quota"
+
+// SetQuota stores the caller's quota after counting the current request
+func SetQuota(c httpx.Values, quota models.Quota) {
+	c.Set(quotaKey, quota)
+}
+
+// QuotaFromContext returns the caller's quota, or false when the request
+// was not rate limited
+func QuotaFromContext(c httpx.Values) (models.Quota, bool) {
+	value, ok := c.Get(quotaKey)
+	if !ok {
+		return models.Quota{}, false
+	}
+	quota, ok := value.(models.Quota)
+	return quota, ok
+}
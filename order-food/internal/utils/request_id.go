@@ -0,0 +1,23 @@
+package utils
+
+import "github.com/shyampundkar/kart-challenge-workspace/order-food/internal/httpx"
+
+// RequestIDHeader carries the ID correlating a request across logs, traces
+// and the services it passes through
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key holding the request ID
+const requestIDKey = "requestID"
+
+// SetRequestID stores the ID of the request in the request's values
+func SetRequestID(c httpx.Values, id string) {
+	c.Set(requestIDKey, id)
+}
+
+// RequestIDFromContext returns the ID of the request, or "" outside
+// RequestIDMiddleware
+func RequestIDFromContext(c httpx.Values) string {
+	value, _ := c.Get(requestIDKey)
+	id, _ := value.(string)
+	return id
+}
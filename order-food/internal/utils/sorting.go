@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+)
+
+// maxSortFields bounds the number of terms accepted in a sort expression
+const maxSortFields = 5
+
+// ParseSort parses a sort expression like "-createdAt,price" into sort
+// fields. A leading "-" sorts descending and a leading "+" (or none) sorts
+// ascending. Only fields in allowed are accepted; an empty expression
+// returns nil so the caller can apply its default ordering.
+func ParseSort(raw string, allowed []string) ([]models.SortField, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	terms := strings.Split(raw, ",")
+	if len(terms) > maxSortFields {
+		return nil, fmt.Errorf("sort accepts at most %d fields", maxSortFields)
+	}
+
+	fields := make([]models.SortField, 0, len(terms))
+	seen := make(map[string]bool, len(terms))
+
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+
+		var field models.SortField
+		switch {
+		case strings.HasPrefix(term, "-"):
+			field = models.SortField{Field: term[1:], Desc: true}
+		case strings.HasPrefix(term, "+"):
+			field = models.SortField{Field: term[1:]}
+		default:
+			field = models.SortField{Field: term}
+		}
+
+		if field.Field == "" {
+			return nil, fmt.Errorf("sort contains an empty field")
+		}
+		if !contains(allowed, field.Field) {
+			return nil, fmt.Errorf("cannot sort by %q; allowed fields: %s", field.Field, strings.Join(allowed, ", "))
+		}
+		if seen[field.Field] {
+			return nil, fmt.Errorf("sort field %q is repeated", field.Field)
+		}
+		seen[field.Field] = true
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// FormatSort renders sort fields back into the query parameter form
+func FormatSort(fields []models.SortField) string {
+	terms := make([]string, len(fields))
+	for i, f := range fields {
+		if f.Desc {
+			terms[i] = "-" + f.Field
+		} else {
+			terms[i] = f.Field
+		}
+	}
+	return strings.Join(terms, ",")
+}
+
+// SortQuery returns the sort query parameter for pagination links, or nil
+// when the default ordering is in effect
+func SortQuery(fields []models.SortField) url.Values {
+	if len(fields) == 0 {
+		return nil
+	}
+	return url.Values{"sort": {FormatSort(fields)}}
+}
+
+func contains(values []string, v string) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/shyampundkar/kart-challenge-workspace/order-food/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSortFields = []string{"id", "name", "price", "createdAt"}
+
+func TestParseSort_Empty(t *testing.T) {
+	fields, err := ParseSort("  ", testSortFields)
+
+	assert.NoError(t, err)
+	assert.Nil(t, fields, "Empty expression should leave ordering to the caller")
+}
+
+func TestParseSort_MixedDirections(t *testing.T) {
+	fields, err := ParseSort("-createdAt, price,+name", testSortFields)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.SortField{
+		{Field: "createdAt", Desc: true},
+		{Field: "price"},
+		{Field: "name"},
+	}, fields)
+}
+
+func TestParseSort_UnknownField(t *testing.T) {
+	_, err := ParseSort("price;DROP TABLE products", testSortFields)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot sort by")
+}
+
+func TestParseSort_EmptyTerm(t *testing.T) {
+	_, err := ParseSort("price,,name", testSortFields)
+	assert.Error(t, err)
+
+	_, err = ParseSort("-", testSortFields)
+	assert.Error(t, err)
+}
+
+func TestParseSort_RepeatedField(t *testing.T) {
+	_, err := ParseSort("price,-price", testSortFields)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "repeated")
+}
+
+func TestParseSort_TooManyFields(t *testing.T) {
+	_, err := ParseSort("a,b,c,d,e,f", []string{"a", "b", "c", "d", "e", "f"})
+
+	assert.Error(t, err)
+}
+
+func TestFormatSort_RoundTrip(t *testing.T) {
+	fields, err := ParseSort("-createdAt,price", testSortFields)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "-createdAt,price", FormatSort(fields))
+}
@@ -0,0 +1,157 @@
+// Package warmup prepares a fresh replica for traffic before it reports
+// ready. Steps fill caches and open database connections, so the first
+// requests after a deploy do not pay for a cold start.
+package warmup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Step states
+const (
+	StepPending = "pending"
+	StepDone    = "done"
+	StepFailed  = "failed"
+)
+
+// Step is one warm-up action
+type Step struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// StepResult is the outcome of one step
+type StepResult struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	DurationMs float64 `json:"durationMs,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// Status is the progress of the warm-up
+type Status struct {
+	Ready bool         `json:"ready"`
+	Steps []StepResult `json:"steps"`
+}
+
+// Warmer runs the warm-up steps and reports whether they have finished. It
+// is safe for concurrent use.
+type Warmer struct {
+	timeout time.Duration
+	steps   []Step
+
+	mu      sync.Mutex
+	results []StepResult
+	ready   bool
+}
+
+// New creates a warmer for steps. Run gives up on steps still running after
+// timeout.
+func New(timeout time.Duration, steps ...Step) *Warmer {
+	results := make([]StepResult, len(steps))
+	for i, step := range steps {
+		results[i] = StepResult{Name: step.Name, Status: StepPending}
+	}
+	return &Warmer{timeout: timeout, steps: steps, results: results}
+}
+
+// Run runs every step concurrently and marks the warmer ready once they
+// have all finished or timed out. Failed steps are logged but do not keep
+// the replica out of service: warm-up only saves latency, and a replica
+// that cannot warm up serves requests as it would have without it. The
+// warmer stays not ready when ctx is cancelled first.
+func (w *Warmer) Run(ctx context.Context) {
+	stepCtx := ctx
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		stepCtx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i, step := range w.steps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.runStep(stepCtx, i, step)
+		}()
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+	w.mu.Lock()
+	w.ready = true
+	w.mu.Unlock()
+	slog.Info("Warm-up finished", "duration", time.Since(start).Round(time.Millisecond))
+}
+
+// Rewarm runs every step again, as after the caches were dropped, and
+// returns the errors of the steps that failed. Readiness is unaffected:
+// a replica serving traffic keeps serving it while it re-warms.
+func (w *Warmer) Rewarm(ctx context.Context) error {
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+
+	var wg sync.WaitGroup
+	for i, step := range w.steps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.runStep(ctx, i, step)
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, result := range w.Status().Steps {
+		if result.Status == StepFailed {
+			errs = append(errs, fmt.Errorf("%s: %s", result.Name, result.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runStep runs one step and records its result
+func (w *Warmer) runStep(ctx context.Context, i int, step Step) {
+	start := time.Now()
+	err := step.Run(ctx)
+	result := StepResult{
+		Name:       step.Name,
+		Status:     StepDone,
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StepFailed
+		result.Error = err.Error()
+		slog.Error("Warm-up step failed", "step", step.Name, "error", err)
+	}
+
+	w.mu.Lock()
+	w.results[i] = result
+	w.mu.Unlock()
+}
+
+// Ready reports whether the warm-up has finished
+func (w *Warmer) Ready() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ready
+}
+
+// Status returns the progress of every step
+func (w *Warmer) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return Status{Ready: w.ready, Steps: append([]StepResult(nil), w.results...)}
+}
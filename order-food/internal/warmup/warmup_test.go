@@ -0,0 +1,89 @@
+package warmup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarmer_Run(t *testing.T) {
+	// Setup
+	release := make(chan struct{})
+	w := New(time.Minute,
+		Step{Name: "cache", Run: func(ctx context.Context) error {
+			<-release
+			return nil
+		}},
+		Step{Name: "connections", Run: func(ctx context.Context) error { return errors.New("connection refused") }},
+	)
+	done := make(chan struct{})
+
+	// Execute
+	go func() {
+		w.Run(context.Background())
+		close(done)
+	}()
+
+	// Assert
+	assert.False(t, w.Ready())
+	assert.Equal(t, StepPending, w.Status().Steps[0].Status)
+	close(release)
+	<-done
+	status := w.Status()
+	assert.True(t, status.Ready)
+	assert.Equal(t, StepDone, status.Steps[0].Status)
+	assert.Equal(t, StepFailed, status.Steps[1].Status)
+	assert.Equal(t, "connection refused", status.Steps[1].Error)
+}
+
+func TestWarmer_Run_Timeout(t *testing.T) {
+	// Setup
+	w := New(10*time.Millisecond, Step{Name: "slow", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	// Execute
+	w.Run(context.Background())
+
+	// Assert
+	assert.True(t, w.Ready())
+	assert.Equal(t, StepFailed, w.Status().Steps[0].Status)
+}
+
+func TestWarmer_Run_Cancelled(t *testing.T) {
+	// Setup
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := New(time.Minute, Step{Name: "cache", Run: func(ctx context.Context) error { return ctx.Err() }})
+
+	// Execute
+	w.Run(ctx)
+
+	// Assert
+	assert.False(t, w.Ready())
+}
+
+func TestWarmer_Rewarm(t *testing.T) {
+	// Setup
+	runs := 0
+	w := New(time.Minute,
+		Step{Name: "cache", Run: func(ctx context.Context) error {
+			runs++
+			return nil
+		}},
+		Step{Name: "connections", Run: func(ctx context.Context) error { return errors.New("connection refused") }},
+	)
+
+	// Execute
+	err := w.Rewarm(context.Background())
+
+	// Assert: readiness is left to Run
+	assert.EqualError(t, err, "connections: connection refused")
+	assert.Equal(t, 1, runs)
+	assert.False(t, w.Ready())
+	assert.Equal(t, StepDone, w.Status().Steps[0].Status)
+}
@@ -0,0 +1,218 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxRedirects bounds the redirects followed by a delivery; each one is
+// checked against the policy like the URL it came from
+const maxRedirects = 3
+
+// ErrTargetNotAllowed is wrapped by every refusal of the egress policy
+var ErrTargetNotAllowed = errors.New("webhook target is not allowed")
+
+// blockedPrefixes are the networks a target may not be in besides the
+// private, loopback, link-local, multicast and unspecified ones netip
+// recognises: shared and reserved ranges and IPv6 prefixes that embed or
+// translate IPv4 addresses
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2001::/32"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// EgressPolicy decides which URLs webhooks may be delivered to, so a
+// partner cannot register a callback that makes order-food call its own
+// network, such as the database or a cloud metadata endpoint. The zero
+// value allows HTTPS on the default port to any public address.
+type EgressPolicy struct {
+	// AllowedHosts limits targets to these host names when not empty. An
+	// entry starting with "*." matches any subdomain of the rest.
+	AllowedHosts []string
+	// AllowedNetworks are exempt from the block on private and reserved
+	// addresses, for targets inside the operator's own network
+	AllowedNetworks []netip.Prefix
+	// AllowHTTP permits plain http targets, e.g. in development
+	AllowHTTP bool
+	// AllowedPorts are the ports targets may use besides the scheme's
+	// default
+	AllowedPorts []string
+}
+
+// ParseEgressPolicy builds a policy from comma-separated lists of hosts,
+// networks in CIDR notation and port numbers
+func ParseEgressPolicy(hosts, networks, ports string, allowHTTP bool) (EgressPolicy, error) {
+	policy := EgressPolicy{AllowHTTP: allowHTTP}
+	for _, host := range splitList(hosts) {
+		policy.AllowedHosts = append(policy.AllowedHosts, strings.ToLower(strings.TrimSuffix(host, ".")))
+	}
+	for _, network := range splitList(networks) {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return EgressPolicy{}, fmt.Errorf("invalid network %q: %w", network, err)
+		}
+		policy.AllowedNetworks = append(policy.AllowedNetworks, prefix.Masked())
+	}
+	for _, port := range splitList(ports) {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return EgressPolicy{}, fmt.Errorf("invalid port %q", port)
+		}
+		policy.AllowedPorts = append(policy.AllowedPorts, port)
+	}
+	return policy, nil
+}
+
+// ValidateURL checks a callback URL when it is registered: the scheme,
+// port and host must be allowed, it must not carry credentials, and an IP
+// address given as the host must not be blocked. Host names are resolved
+// again and checked on every delivery by the client of NewClient, since
+// what a name points to can change after registration.
+func (p EgressPolicy) ValidateURL(raw string) error {
+	target, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrTargetNotAllowed, err)
+	}
+	switch {
+	case target.Scheme != "https" && !(target.Scheme == "http" && p.AllowHTTP):
+		return fmt.Errorf("%w: scheme %q", ErrTargetNotAllowed, target.Scheme)
+	case target.User != nil:
+		return fmt.Errorf("%w: credentials in URL", ErrTargetNotAllowed)
+	case target.Hostname() == "":
+		return fmt.Errorf("%w: no host", ErrTargetNotAllowed)
+	}
+
+	if port := target.Port(); port != "" && port != defaultPort(target.Scheme) && !slices.Contains(p.AllowedPorts, port) {
+		return fmt.Errorf("%w: port %s", ErrTargetNotAllowed, port)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(target.Hostname(), "."))
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if len(p.AllowedHosts) > 0 && !slices.Contains(p.AllowedHosts, addr.String()) {
+			return fmt.Errorf("%w: host %s is not on the allowlist", ErrTargetNotAllowed, host)
+		}
+		return p.checkAddr(addr)
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: host %s", ErrTargetNotAllowed, host)
+	}
+	if len(p.AllowedHosts) > 0 && !p.hostAllowed(host) {
+		return fmt.Errorf("%w: host %s is not on the allowlist", ErrTargetNotAllowed, host)
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client for delivering webhooks under the
+// policy. Every connection is checked against the address it is actually
+// made to, after DNS resolution, so a host name that resolves to an
+// internal address, at registration or only later, is refused. Proxies
+// from the environment are not used, as they would hide the address, and
+// redirects are validated like registered URLs.
+func (p EgressPolicy) NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrTargetNotAllowed, err)
+			}
+			return p.checkAddr(addrPort.Addr())
+		},
+	}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConnsPerHost: 2,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("%w: more than %d redirects", ErrTargetNotAllowed, maxRedirects)
+			}
+			return p.ValidateURL(req.URL.String())
+		},
+	}
+}
+
+// checkAddr refuses addresses of the local machine and of private,
+// link-local, shared and reserved networks, unless AllowedNetworks lists
+// them
+func (p EgressPolicy) checkAddr(addr netip.Addr) error {
+	addr = addr.Unmap().WithZone("")
+	for _, prefix := range p.AllowedNetworks {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+	if blocked(addr) {
+		return fmt.Errorf("%w: address %s is not public", ErrTargetNotAllowed, addr)
+	}
+	return nil
+}
+
+// blocked reports whether addr is not a public unicast address
+func blocked(addr netip.Addr) bool {
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostAllowed reports whether host is on the allowlist
+func (p EgressPolicy) hostAllowed(host string) bool {
+	for _, allowed := range p.AllowedHosts {
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultPort(scheme string) string {
+	if scheme == "http" {
+		return "80"
+	}
+	return "443"
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
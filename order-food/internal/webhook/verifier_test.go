@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":"evt_1","type":"payment.succeeded"}`)
+
+	tests := []struct {
+		name    string
+		header  string
+		wantErr error
+	}{
+		{name: "valid", header: Sign("new-secret", now, body)},
+		{name: "signed with previous secret", header: Sign("old-secret", now.Add(-time.Minute), body)},
+		{name: "unknown secret", header: Sign("attacker", now, body), wantErr: ErrInvalidSignature},
+		{name: "replayed after window", header: Sign("new-secret", now.Add(-6*time.Minute), body), wantErr: ErrStaleTimestamp},
+		{name: "timestamp in the future", header: Sign("new-secret", now.Add(6*time.Minute), body), wantErr: ErrStaleTimestamp},
+		{name: "missing", header: "", wantErr: ErrMissingSignature},
+		{name: "no signature", header: "t=1704110400", wantErr: ErrMissingSignature},
+		{
+			name:   "one of several signatures matches",
+			header: "t=1704110400,v1=00ff," + Sign("new-secret", now, body)[len("t=1704110400,"):],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			v := NewVerifier([]string{"new-secret", "old-secret"}, DefaultTolerance)
+			v.now = func() time.Time { return now }
+
+			// Execute
+			err := v.Verify(tt.header, body)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestVerifier_RejectsTamperedBody(t *testing.T) {
+	// Setup
+	now := time.Now()
+	v := NewVerifier([]string{"secret"}, DefaultTolerance)
+	header := Sign("secret", now, []byte(`{"amount":100}`))
+
+	// Execute
+	err := v.Verify(header, []byte(`{"amount":1}`))
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
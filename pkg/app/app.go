@@ -0,0 +1,138 @@
+// Package app is the shared process bootstrap for the workspace services.
+// It configures structured logging, cancels a root context on SIGINT/SIGTERM and runs
+// registered shutdown hooks in reverse order once the service returns.
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/buildinfo"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/instance"
+)
+
+// DefaultShutdownTimeout bounds how long shutdown hooks may take in total
+const DefaultShutdownTimeout = 10 * time.Second
+
+// ShutdownFunc releases a resource during shutdown
+type ShutdownFunc func(ctx context.Context) error
+
+type hook struct {
+	name string
+	fn   ShutdownFunc
+}
+
+// App is a running service process
+type App struct {
+	name            string
+	shutdownTimeout time.Duration
+
+	mu    sync.Mutex
+	hooks []hook
+}
+
+// New creates the app for the named service, configures the default slog
+// logger and logs the build and instance. LOG_FORMAT selects text or JSON
+// output and LOG_LEVEL the lowest level logged; the standard logger writes
+// through slog at info level. Every record carries the service name and
+// the short instance ID, so records from different replicas can be told
+// apart once aggregated. It also installs the global OpenTelemetry tracer
+// provider, which exports spans over OTLP when OTEL_EXPORTER_OTLP_ENDPOINT
+// is set and is shut down on exit. SHUTDOWN_TIMEOUT overrides
+// DefaultShutdownTimeout.
+func New(name string) *App {
+	id := instance.Get()
+	level, levelErr := ParseLogLevel(Getenv("LOG_LEVEL", ""))
+	logger, err := NewLogger(os.Stderr, Getenv("LOG_FORMAT", LogFormatText), level)
+	if err != nil {
+		log.Fatalf("Invalid LOG_FORMAT: %v", err)
+	}
+	slog.SetDefault(logger.With("service", name, "instance", id.ShortID()))
+	if levelErr != nil {
+		slog.Warn("Invalid LOG_LEVEL, logging at info level", "error", levelErr)
+	}
+
+	info := buildinfo.Get()
+	slog.Info("Starting "+name, "version", info.Version, "revision", info.GitSHA, "built", info.BuildTime, "go", info.GoVersion)
+	slog.Info("Instance started", "instance_id", id.ID, "host", id.Name())
+
+	a := &App{
+		name:            name,
+		shutdownTimeout: GetenvDuration("SHUTDOWN_TIMEOUT", DefaultShutdownTimeout),
+	}
+
+	// Registered first, so spans recorded while the other resources shut
+	// down are still flushed
+	provider, err := newTracerProvider(context.Background(), name, id)
+	if err != nil {
+		log.Fatalf("Invalid tracing configuration: %v", err)
+	}
+	installTracing(provider)
+	a.OnShutdown("tracer provider", provider.Shutdown)
+
+	return a
+}
+
+// OnShutdown registers fn to run during shutdown. Hooks run in reverse
+// registration order, so a resource registered after its dependencies is
+// released before them.
+func (a *App) OnShutdown(name string, fn ShutdownFunc) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hooks = append(a.hooks, hook{name: name, fn: fn})
+}
+
+// Run calls run with a context that is cancelled on SIGINT or SIGTERM,
+// then runs the shutdown hooks. It exits the process with status 1 if run
+// or any hook fails, and never returns.
+func (a *App) Run(run func(ctx context.Context) error) {
+	os.Exit(a.run(run))
+}
+
+// run is Run without the exit, returning the exit status
+func (a *App) run(run func(ctx context.Context) error) int {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	status := 0
+	if err := run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		slog.Error(a.name+" failed", "error", err)
+		status = 1
+	}
+	if ctx.Err() != nil {
+		slog.Info("Shutdown signal received")
+	}
+
+	if !a.shutdown() {
+		status = 1
+	}
+	return status
+}
+
+// shutdown runs the hooks in reverse order within the shutdown timeout and
+// reports whether they all succeeded
+func (a *App) shutdown() bool {
+	a.mu.Lock()
+	hooks := a.hooks
+	a.hooks = nil
+	a.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	ok := true
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			slog.Error("Shutdown failed", "resource", hooks[i].name, "error", err)
+			ok = false
+		}
+	}
+	return ok
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRun_HooksRunInReverseOrder(t *testing.T) {
+	// Setup
+	a := &App{name: "test", shutdownTimeout: time.Second}
+	var order []string
+	for _, name := range []string{"database", "archiver", "http server"} {
+		a.OnShutdown(name, func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	// Execute
+	status := a.run(func(ctx context.Context) error { return nil })
+
+	// Assert
+	if status != 0 {
+		t.Errorf("status = %d, want 0", status)
+	}
+	want := []string{"http server", "archiver", "database"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("hook order = %v, want %v", order, want)
+	}
+}
+
+func TestRun_FailureStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		runErr  error
+		hookErr error
+		want    int
+	}{
+		{name: "success", want: 0},
+		{name: "run fails", runErr: errors.New("boom"), want: 1},
+		{name: "cancelled run is not a failure", runErr: context.Canceled, want: 0},
+		{name: "hook fails", hookErr: errors.New("close failed"), want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			a := &App{name: "test", shutdownTimeout: time.Second}
+			hookRan := false
+			a.OnShutdown("resource", func(ctx context.Context) error {
+				hookRan = true
+				return tt.hookErr
+			})
+
+			// Execute
+			status := a.run(func(ctx context.Context) error { return tt.runErr })
+
+			// Assert
+			if status != tt.want {
+				t.Errorf("status = %d, want %d", status, tt.want)
+			}
+			if !hookRan {
+				t.Error("shutdown hook did not run")
+			}
+		})
+	}
+}
+
+func TestShutdown_HooksShareTimeout(t *testing.T) {
+	// Setup
+	a := &App{name: "test", shutdownTimeout: 10 * time.Millisecond}
+	a.OnShutdown("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	// Execute
+	ok := a.shutdown()
+
+	// Assert
+	if ok {
+		t.Error("shutdown reported success for a hook that timed out")
+	}
+}
+
+func TestGetenvInt(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{value: "", want: 7},
+		{value: "25", want: 25},
+		{value: "0", want: 7},
+		{value: "-3", want: 7},
+		{value: "abc", want: 7},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("APP_TEST_INT", tt.value)
+		if got := GetenvInt("APP_TEST_INT", 7); got != tt.want {
+			t.Errorf("GetenvInt(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGetenvDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: time.Minute},
+		{value: "90s", want: 90 * time.Second},
+		{value: "soon", want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("APP_TEST_DURATION", tt.value)
+		if got := GetenvDuration("APP_TEST_DURATION", time.Minute); got != tt.want {
+			t.Errorf("GetenvDuration(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGetenv_File(t *testing.T) {
+	// Setup
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_TEST_SECRET", "")
+	t.Setenv("APP_TEST_SECRET_FILE", path)
+
+	// Execute
+	got := Getenv("APP_TEST_SECRET", "default")
+
+	// Assert
+	if got != "s3cret" {
+		t.Errorf("Getenv = %q, want %q", got, "s3cret")
+	}
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Getenv returns the value of an environment variable or a default value.
+// When KEY_FILE is set the value is read from that file instead, so secrets
+// can be mounted as Docker or Kubernetes secret files.
+func Getenv(key, defaultValue string) string {
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		if os.Getenv(key) != "" {
+			log.Fatalf("Both %s and %s_FILE are set; set only one", key, key)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read %s_FILE: %v", key, err)
+		}
+		if value := strings.TrimRight(string(data), "\r\n"); value != "" {
+			return value
+		}
+		return defaultValue
+	}
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// GetenvInt returns a positive integer setting, falling back to
+// defaultValue when it is unset or invalid
+func GetenvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(Getenv(key, ""))
+	if err != nil || value < 1 {
+		return defaultValue
+	}
+	return value
+}
+
+// GetenvDuration returns a Go duration setting, falling back to
+// defaultValue when it is unset or invalid
+func GetenvDuration(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(Getenv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,274 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/rds/auth"
+)
+
+// Credentials are the user name and password for a database login
+type Credentials struct {
+	User     string `json:"username"`
+	Password string `json:"password"`
+}
+
+// CredentialProvider returns the credentials to use for a new database
+// connection. Services ask for them each time they dial, so rotated
+// credentials are picked up without a restart; jobs ask once per run.
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// NewCredentialProvider returns the source of database credentials
+// selected by DB_CREDENTIALS_PROVIDER:
+//
+//   - "env" (default) uses DB_USER and DB_PASSWORD
+//   - "file" re-reads DB_CREDENTIALS_FILE whenever it changes
+//   - "vault" reads DB_VAULT_PATH from Vault at VAULT_ADDR with VAULT_TOKEN
+//   - "rds-iam" signs an IAM authentication token for DB_USER on the RDS
+//     instance at host:port in AWS_REGION
+//
+// DB_USER defaults to postgres.
+func NewCredentialProvider(ctx context.Context, host, port string) (CredentialProvider, error) {
+	user := Getenv("DB_USER", "postgres")
+
+	switch provider := Getenv("DB_CREDENTIALS_PROVIDER", "env"); provider {
+	case "env":
+		return StaticCredentials{User: user, Password: Getenv("DB_PASSWORD", "postgres")}, nil
+	case "file":
+		path := Getenv("DB_CREDENTIALS_FILE", "")
+		if path == "" {
+			return nil, errors.New("DB_CREDENTIALS_FILE must be set when DB_CREDENTIALS_PROVIDER=file")
+		}
+		return NewFileCredentials(path, user), nil
+	case "vault":
+		addr, path := Getenv("VAULT_ADDR", ""), Getenv("DB_VAULT_PATH", "")
+		if addr == "" || path == "" {
+			return nil, errors.New("VAULT_ADDR and DB_VAULT_PATH must be set when DB_CREDENTIALS_PROVIDER=vault")
+		}
+		return NewVaultCredentials(addr, path, func() string { return Getenv("VAULT_TOKEN", "") }), nil
+	case "rds-iam":
+		region := Getenv("AWS_REGION", "")
+		if region == "" {
+			return nil, errors.New("AWS_REGION must be set when DB_CREDENTIALS_PROVIDER=rds-iam")
+		}
+		cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS credentials: %w", err)
+		}
+		return NewRDSIAMCredentials(net.JoinHostPort(host, port), region, user, cfg.Credentials), nil
+	default:
+		return nil, fmt.Errorf("unknown DB_CREDENTIALS_PROVIDER %q", provider)
+	}
+}
+
+// StaticCredentials is a CredentialProvider with fixed credentials
+type StaticCredentials Credentials
+
+// Credentials returns the fixed credentials
+func (s StaticCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	return Credentials(s), nil
+}
+
+// FileCredentials reads credentials from a file that is replaced on
+// rotation, such as a Kubernetes secret volume or a file rendered by the
+// Vault agent. The file holds either the bare password or a JSON object
+// with "username" and "password". The file is re-read whenever its
+// modification time changes.
+type FileCredentials struct {
+	path        string
+	defaultUser string
+
+	mu      sync.Mutex
+	modTime time.Time
+	cached  Credentials
+}
+
+// NewFileCredentials creates a provider reading path. defaultUser is used
+// when the file does not name a user.
+func NewFileCredentials(path, defaultUser string) *FileCredentials {
+	return &FileCredentials{path: path, defaultUser: defaultUser}
+}
+
+// Credentials returns the credentials currently in the file
+func (f *FileCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.modTime.IsZero() && info.ModTime().Equal(f.modTime) {
+		return f.cached, nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	creds, err := parseCredentials(data, f.defaultUser)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	f.cached = creds
+	f.modTime = info.ModTime()
+	return creds, nil
+}
+
+// parseCredentials decodes a credentials file
+func parseCredentials(data []byte, defaultUser string) (Credentials, error) {
+	content := strings.TrimSpace(string(data))
+
+	creds := Credentials{User: defaultUser, Password: content}
+	if strings.HasPrefix(content, "{") {
+		var parsed Credentials
+		if err := json.Unmarshal([]byte(content), &parsed); err != nil {
+			return Credentials{}, fmt.Errorf("invalid credentials file: %w", err)
+		}
+		creds.Password = parsed.Password
+		if parsed.User != "" {
+			creds.User = parsed.User
+		}
+	}
+
+	if creds.Password == "" {
+		return Credentials{}, fmt.Errorf("credentials file contains no password")
+	}
+	return creds, nil
+}
+
+// VaultCredentials reads credentials from a Vault secret: dynamic
+// credentials of the database secrets engine, such as database/creds/app,
+// or a static secret of the KV engine holding "username" and "password".
+// Leased credentials are read again once two thirds of their lease has
+// passed, so new connections never use credentials about to be revoked.
+type VaultCredentials struct {
+	addr, path string
+	token      func() string
+	client     *http.Client
+	now        func() time.Time
+
+	mu        sync.Mutex
+	refreshAt time.Time
+	cached    Credentials
+}
+
+// NewVaultCredentials creates a provider reading path from the Vault
+// server at addr. token is called for each read, so a token file renewed by
+// the Vault agent is picked up.
+func NewVaultCredentials(addr, path string, token func() string) *VaultCredentials {
+	return &VaultCredentials{
+		addr:   strings.TrimRight(addr, "/"),
+		path:   strings.Trim(path, "/"),
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// vaultSecret is the response of a Vault secret read
+type vaultSecret struct {
+	LeaseDuration int `json:"lease_duration"`
+	Data          struct {
+		Credentials
+		// Data holds the secret of a KV version 2 read
+		Data *Credentials `json:"data"`
+	} `json:"data"`
+}
+
+// Credentials returns the credentials of the secret, reading it again when
+// its lease is due for renewal
+func (v *VaultCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.cached.Password != "" && (v.refreshAt.IsZero() || v.now().Before(v.refreshAt)) {
+		return v.cached, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.addr+"/v1/"+v.path, nil)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("invalid Vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.token())
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read credentials from Vault: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("failed to read credentials from Vault: %s", resp.Status)
+	}
+
+	var secret vaultSecret
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return Credentials{}, fmt.Errorf("invalid Vault response: %w", err)
+	}
+	creds := secret.Data.Credentials
+	if secret.Data.Data != nil {
+		creds = *secret.Data.Data
+	}
+	if creds.User == "" || creds.Password == "" {
+		return Credentials{}, fmt.Errorf("Vault secret %s holds no username and password", v.path)
+	}
+
+	v.cached = creds
+	v.refreshAt = time.Time{}
+	if secret.LeaseDuration > 0 {
+		v.refreshAt = v.now().Add(time.Duration(secret.LeaseDuration) * time.Second * 2 / 3)
+	}
+	return creds, nil
+}
+
+// rdsTokenLifetime is how long an RDS IAM authentication token is reused.
+// Tokens open connections for 15 minutes; established connections are not
+// affected when they expire.
+const rdsTokenLifetime = 10 * time.Minute
+
+// RDSIAMCredentials signs IAM authentication tokens for an RDS or Aurora
+// database user with the AWS credentials of the process, such as those of
+// an IAM role for the service account. The token is the password.
+type RDSIAMCredentials struct {
+	endpoint, region, user string
+	creds                  aws.CredentialsProvider
+	now                    func() time.Time
+
+	mu       sync.Mutex
+	signedAt time.Time
+	token    string
+}
+
+// NewRDSIAMCredentials creates a provider for user on the database at
+// endpoint, given as host:port, in region
+func NewRDSIAMCredentials(endpoint, region, user string, creds aws.CredentialsProvider) *RDSIAMCredentials {
+	return &RDSIAMCredentials{endpoint: endpoint, region: region, user: user, creds: creds, now: time.Now}
+}
+
+// Credentials returns the user with a token signed within rdsTokenLifetime
+func (r *RDSIAMCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.token == "" || r.now().Sub(r.signedAt) >= rdsTokenLifetime {
+		token, err := auth.BuildAuthToken(ctx, r.endpoint, r.region, r.user, r.creds)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("failed to sign RDS IAM token: %w", err)
+		}
+		r.token, r.signedAt = token, r.now()
+	}
+	return Credentials{User: r.user, Password: r.token}, nil
+}
@@ -0,0 +1,202 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+func TestFileCredentials_PicksUpRotation(t *testing.T) {
+	// Setup
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	provider := NewFileCredentials(path, "postgres")
+
+	// Execute
+	creds, err := provider.Credentials(context.Background())
+
+	// Assert
+	if err != nil || creds != (Credentials{User: "postgres", Password: "first"}) {
+		t.Fatalf("Credentials() = %+v, %v, want postgres/first", creds, err)
+	}
+
+	// Rotate - Vault style JSON with a new user
+	if err := os.WriteFile(path, []byte(`{"username":"v-app-123","password":"second"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err = provider.Credentials(context.Background())
+	if err != nil || creds != (Credentials{User: "v-app-123", Password: "second"}) {
+		t.Errorf("Credentials() after rotation = %+v, %v, want v-app-123/second", creds, err)
+	}
+}
+
+func TestFileCredentials_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := NewFileCredentials(filepath.Join(dir, "missing"), "postgres").Credentials(context.Background()); err == nil {
+		t.Error("missing file: want an error")
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileCredentials(empty, "postgres").Credentials(context.Background()); err == nil {
+		t.Error("empty file: want an error")
+	}
+}
+
+func TestVaultCredentials_RenewsLease(t *testing.T) {
+	// Setup: a database secrets engine handing out new credentials on every read
+	reads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/database/creds/order-food" || r.Header.Get("X-Vault-Token") != "s.token" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		reads++
+		user := []string{"", "v-app-1", "v-app-2"}[reads]
+		w.Write([]byte(`{"lease_duration":3600,"data":{"username":"` + user + `","password":"secret"}}`))
+	}))
+	defer server.Close()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider := NewVaultCredentials(server.URL+"/", "/database/creds/order-food", func() string { return "s.token" })
+	provider.now = func() time.Time { return now }
+
+	// Execute
+	first, err := provider.Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("Credentials() error = %v", err)
+	}
+	now = now.Add(30 * time.Minute)
+	cached, _ := provider.Credentials(context.Background())
+	now = now.Add(11 * time.Minute)
+	renewed, err := provider.Credentials(context.Background())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Credentials() after two thirds of the lease error = %v", err)
+	}
+	if first.User != "v-app-1" || cached.User != "v-app-1" {
+		t.Errorf("users within the lease = %q, %q, want v-app-1 twice", first.User, cached.User)
+	}
+	if renewed.User != "v-app-2" {
+		t.Errorf("user after two thirds of the lease = %q, want v-app-2", renewed.User)
+	}
+}
+
+func TestVaultCredentials_KVSecret(t *testing.T) {
+	// Setup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"lease_duration":0,"data":{"data":{"username":"app","password":"kv-secret"},"metadata":{"version":3}}}`))
+	}))
+	defer server.Close()
+
+	// Execute
+	creds, err := NewVaultCredentials(server.URL, "secret/data/order-food/db", func() string { return "s.token" }).Credentials(context.Background())
+
+	// Assert
+	if err != nil || creds != (Credentials{User: "app", Password: "kv-secret"}) {
+		t.Errorf("Credentials() = %+v, %v, want app/kv-secret", creds, err)
+	}
+}
+
+func TestVaultCredentials_Denied(t *testing.T) {
+	// Setup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "permission denied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	// Execute
+	_, err := NewVaultCredentials(server.URL, "database/creds/order-food", func() string { return "" }).Credentials(context.Background())
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Credentials() error = %v, want the 403 from Vault", err)
+	}
+}
+
+func TestRDSIAMCredentials_SignsToken(t *testing.T) {
+	// Setup
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider := NewRDSIAMCredentials("db.example.eu-west-1.rds.amazonaws.com:5432", "eu-west-1", "app",
+		credentials.NewStaticCredentialsProvider("AKID", "secret", ""))
+	provider.now = func() time.Time { return now }
+
+	// Execute
+	creds, err := provider.Credentials(context.Background())
+	if err != nil {
+		t.Fatalf("Credentials() error = %v", err)
+	}
+	now = now.Add(5 * time.Minute)
+	reused, _ := provider.Credentials(context.Background())
+	now = now.Add(6 * time.Minute)
+	resigned, err := provider.Credentials(context.Background())
+
+	// Assert
+	if creds.User != "app" {
+		t.Errorf("User = %q, want app", creds.User)
+	}
+	for _, want := range []string{"db.example.eu-west-1.rds.amazonaws.com:5432?Action=connect", "DBUser=app", "X-Amz-Signature="} {
+		if !strings.Contains(creds.Password, want) {
+			t.Errorf("token %q does not contain %q", creds.Password, want)
+		}
+	}
+	if reused.Password != creds.Password {
+		t.Error("a token younger than its lifetime is not reused")
+	}
+	if err != nil || resigned.Password == "" || !provider.signedAt.Equal(now) {
+		t.Errorf("an old token is not signed again: %v", err)
+	}
+}
+
+func TestNewCredentialProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "env by default"},
+		{name: "file", env: map[string]string{"DB_CREDENTIALS_PROVIDER": "file", "DB_CREDENTIALS_FILE": "/run/secrets/db"}},
+		{name: "file without path", env: map[string]string{"DB_CREDENTIALS_PROVIDER": "file"}, wantErr: true},
+		{name: "vault", env: map[string]string{"DB_CREDENTIALS_PROVIDER": "vault", "VAULT_ADDR": "http://vault:8200", "DB_VAULT_PATH": "database/creds/app"}},
+		{name: "vault without path", env: map[string]string{"DB_CREDENTIALS_PROVIDER": "vault", "VAULT_ADDR": "http://vault:8200"}, wantErr: true},
+		{name: "rds-iam without region", env: map[string]string{"DB_CREDENTIALS_PROVIDER": "rds-iam"}, wantErr: true},
+		{name: "unknown", env: map[string]string{"DB_CREDENTIALS_PROVIDER": "ldap"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			for _, key := range []string{"DB_CREDENTIALS_PROVIDER", "DB_CREDENTIALS_FILE", "VAULT_ADDR", "DB_VAULT_PATH", "AWS_REGION"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			// Execute
+			provider, err := NewCredentialProvider(context.Background(), "db", "5432")
+
+			// Assert
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewCredentialProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && provider == nil {
+				t.Error("NewCredentialProvider() returned no provider")
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Log formats accepted by LOG_FORMAT
+const (
+	// LogFormatText writes key=value lines, easy to read in a terminal
+	LogFormatText = "text"
+	// LogFormatJSON writes one JSON object per line for log aggregators
+	LogFormatJSON = "json"
+)
+
+// ParseLogLevel parses a LOG_LEVEL value: debug, info, warn or error. Empty
+// means info.
+func ParseLogLevel(value string) (slog.Level, error) {
+	if value == "" {
+		return slog.LevelInfo, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", value)
+	}
+	return level, nil
+}
+
+// NewLogger returns a logger writing records at level and above to w in
+// format, LogFormatText or LogFormatJSON. Records carry the attributes
+// added to their context with WithLogAttrs.
+func NewLogger(w io.Writer, format string, level slog.Level) (*slog.Logger, error) {
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", LogFormatText:
+		handler = slog.NewTextHandler(w, options)
+	case LogFormatJSON:
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected %q or %q", format, LogFormatText, LogFormatJSON)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+
+// logAttrsKey is the context key holding the attributes of WithLogAttrs
+type logAttrsKey struct{}
+
+// WithLogAttrs returns a copy of ctx whose log records carry attrs, such as
+// the ID of the request being served, besides those already added
+func WithLogAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := LogAttrs(ctx)
+	return context.WithValue(ctx, logAttrsKey{}, append(existing[:len(existing):len(existing)], attrs...))
+}
+
+// LogAttrs returns the attributes added to ctx with WithLogAttrs
+func LogAttrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(logAttrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// contextHandler adds the attributes of the record's context to it
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs := LogAttrs(ctx); len(attrs) > 0 {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	for value, want := range map[string]slog.Level{"": slog.LevelInfo, "debug": slog.LevelDebug, "WARN": slog.LevelWarn, "error": slog.LevelError} {
+		got, err := ParseLogLevel(value)
+		if err != nil || got != want {
+			t.Errorf("ParseLogLevel(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	if _, err := ParseLogLevel("verbose"); err == nil {
+		t.Error("ParseLogLevel(verbose) succeeded, want an error")
+	}
+}
+
+func TestNewLogger_JSONWithContextAttrs(t *testing.T) {
+	// Setup
+	var buf bytes.Buffer
+	logger, err := NewLogger(&buf, LogFormatJSON, slog.LevelInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithLogAttrs(context.Background(), slog.String("request_id", "r1"))
+	ctx = WithLogAttrs(ctx, slog.String("trace_id", "t1"))
+
+	// Execute
+	logger.With("service", "order-food").InfoContext(ctx, "Order placed", "order_id", "o1")
+	logger.DebugContext(ctx, "dropped below the level")
+
+	// Assert
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d records, want 1: %s", len(lines), buf.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"msg": "Order placed", "service": "order-food", "order_id": "o1", "request_id": "r1", "trace_id": "t1"} {
+		if record[key] != want {
+			t.Errorf("%s = %v, want %q", key, record[key], want)
+		}
+	}
+}
+
+func TestNewLogger_UnknownFormat(t *testing.T) {
+	if _, err := NewLogger(&bytes.Buffer{}, "xml", slog.LevelInfo); err == nil {
+		t.Error("NewLogger(xml) succeeded, want an error")
+	}
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/buildinfo"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/instance"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/operation"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName names the tracer of the spans started by this package
+const tracerName = "github.com/shyampundkar/kart-challenge-workspace/pkg/app"
+
+// otlpEndpointVars configure where the OTLP exporter sends spans; the
+// exporter reads them itself, along with the other OTEL_EXPORTER_OTLP_*
+// settings
+var otlpEndpointVars = []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"}
+
+// newTracerProvider creates the tracer provider of the named service. Its
+// resource describes the build and the instance. Spans are exported over
+// OTLP/HTTP when an OTLP endpoint is set; without one they are still
+// recorded, so trace IDs are set, but go nowhere.
+func newTracerProvider(ctx context.Context, name string, id instance.Identity) (*sdktrace.TracerProvider, error) {
+	info := buildinfo.Get()
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			semconv.ServiceName(name),
+			semconv.ServiceVersion(info.Version),
+			semconv.ServiceInstanceID(id.ID),
+			semconv.VCSRefHeadRevision(info.GitSHA),
+		),
+		// OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME take precedence
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe tracing resource: %w", err)
+	}
+
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	if otlpConfigured() {
+		exporter, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	return sdktrace.NewTracerProvider(options...), nil
+}
+
+// otlpConfigured reports whether an OTLP endpoint is set
+func otlpConfigured() bool {
+	for _, key := range otlpEndpointVars {
+		if os.Getenv(key) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// installTracing makes provider the global tracer provider and reads and
+// writes trace context in the W3C trace context and baggage formats
+func installTracing(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// StartOperation starts the span of a job run, continuing the trace in
+// TRACEPARENT when it is set, and returns the operation of the run. The
+// operation ID is the trace ID and its step is the span, so the run is
+// found by the same ID in the tracing backend. End the span when the run
+// is over.
+func StartOperation(ctx context.Context, name string) (context.Context, operation.Operation, trace.Span) {
+	parent, parentErr := operation.Parse(os.Getenv(operation.EnvTraceparent))
+	ctx = propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": os.Getenv(operation.EnvTraceparent)})
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, name)
+	sc := span.SpanContext()
+	if !sc.IsValid() {
+		// Only a no-op tracer provider returns invalid spans
+		return ctx, operation.Start(), span
+	}
+
+	op := operation.Operation{ID: sc.TraceID().String(), SpanID: sc.SpanID().String()}
+	if parentErr == nil {
+		op.ParentSpanID = parent.SpanID
+	}
+	return ctx, op, span
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/instance"
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/operation"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+)
+
+func TestNewTracerProvider_Resource(t *testing.T) {
+	// Setup
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	recorder := tracetest.NewSpanRecorder()
+
+	// Execute
+	provider, err := newTracerProvider(context.Background(), "order-food", instance.Identity{ID: "instance-1"})
+	if err != nil {
+		t.Fatalf("newTracerProvider() error = %v", err)
+	}
+	defer provider.Shutdown(context.Background())
+	provider.RegisterSpanProcessor(recorder)
+	_, span := provider.Tracer("test").Start(context.Background(), "work")
+	span.End()
+
+	// Assert
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	attrs := spans[0].Resource().Set()
+	if got, _ := attrs.Value(semconv.ServiceNameKey); got.AsString() != "order-food" {
+		t.Errorf("service.name = %q, want %q", got.AsString(), "order-food")
+	}
+	if got, _ := attrs.Value(semconv.ServiceInstanceIDKey); got.AsString() != "instance-1" {
+		t.Errorf("service.instance.id = %q, want %q", got.AsString(), "instance-1")
+	}
+}
+
+func TestStartOperation_ContinuesTraceparent(t *testing.T) {
+	// Setup
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+	otel.SetTracerProvider(trace.NewTracerProvider())
+	t.Setenv(operation.EnvTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// Execute
+	_, op, span := StartOperation(context.Background(), "database-load")
+	defer span.End()
+
+	// Assert
+	if op.ID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("ID = %q, want the trace ID of TRACEPARENT", op.ID)
+	}
+	if op.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("ParentSpanID = %q, want the span ID of TRACEPARENT", op.ParentSpanID)
+	}
+	if op.SpanID != span.SpanContext().SpanID().String() {
+		t.Errorf("SpanID = %q, want the span of the run %s", op.SpanID, span.SpanContext().SpanID())
+	}
+}
+
+func TestStartOperation_NewTrace(t *testing.T) {
+	// Setup
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+	otel.SetTracerProvider(trace.NewTracerProvider())
+	t.Setenv(operation.EnvTraceparent, "")
+
+	// Execute
+	_, op, span := StartOperation(context.Background(), "database-migration")
+	defer span.End()
+
+	// Assert
+	if op.ID != span.SpanContext().TraceID().String() {
+		t.Errorf("ID = %q, want the trace ID of the run %s", op.ID, span.SpanContext().TraceID())
+	}
+	if op.ParentSpanID != "" {
+		t.Errorf("ParentSpanID = %q, want none", op.ParentSpanID)
+	}
+}
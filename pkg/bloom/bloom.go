@@ -0,0 +1,179 @@
+// Package bloom is a Bloom filter: a compact set that answers "definitely
+// absent" or "possibly present". It is used to turn away promo codes that
+// cannot be valid without asking the database. Filters can be written to a
+// snapshot file by one process and read by another; the hashing is fixed,
+// so a snapshot means the same thing to every reader.
+package bloom
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// magic starts every snapshot and names its format version
+var magic = [4]byte{'B', 'L', 'M', '1'}
+
+// maxBits bounds the size of a snapshot that is read, so a corrupt header
+// cannot make the reader allocate without limit
+const maxBits = 1 << 38
+
+// ErrInvalidSnapshot is returned when a snapshot is not a filter written
+// by this package
+var ErrInvalidSnapshot = errors.New("invalid bloom filter snapshot")
+
+// Filter is a Bloom filter. Add and MayContain are safe for concurrent use.
+type Filter struct {
+	bits  []uint64
+	m     uint64
+	k     uint32
+	count atomic.Uint64
+}
+
+// New creates a filter sized for n values at bitsPerValue bits each. Ten
+// bits per value give a false positive rate of about 1% once n values have
+// been added; the rate rises as more are added.
+func New(n, bitsPerValue int) *Filter {
+	n = max(n, 1)
+	bitsPerValue = max(bitsPerValue, 1)
+	m := (uint64(n)*uint64(bitsPerValue) + 63) / 64 * 64
+	k := uint32(math.Round(float64(bitsPerValue) * math.Ln2))
+	return &Filter{bits: make([]uint64, m/64), m: m, k: max(k, 1)}
+}
+
+// hashes returns the two base hashes of value; the k probe positions are
+// derived from them by double hashing
+func hashes(value string) (uint64, uint64) {
+	h := fnv.New128a()
+	h.Write([]byte(value))
+	sum := h.Sum(nil)
+	// An odd step visits distinct positions for every probe
+	return binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:]) | 1
+}
+
+// Add adds value to the filter
+func (f *Filter) Add(value string) {
+	h1, h2 := hashes(value)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		atomic.OrUint64(&f.bits[bit/64], 1<<(bit%64))
+	}
+	f.count.Add(1)
+}
+
+// MayContain reports whether value may have been added. False means it
+// definitely was not.
+func (f *Filter) MayContain(value string) bool {
+	h1, h2 := hashes(value)
+	for i := uint64(0); i < uint64(f.k); i++ {
+		bit := (h1 + i*h2) % f.m
+		if atomic.LoadUint64(&f.bits[bit/64])&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns how many values have been added, counting repeats
+func (f *Filter) Count() uint64 {
+	return f.count.Load()
+}
+
+// SizeBytes returns the memory taken by the filter's bits
+func (f *Filter) SizeBytes() uint64 {
+	return f.m / 8
+}
+
+// WriteTo writes the filter as a snapshot. It must not run concurrently
+// with Add.
+func (f *Filter) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	header := make([]byte, 0, 24)
+	header = append(header, magic[:]...)
+	header = binary.LittleEndian.AppendUint64(header, f.m)
+	header = binary.LittleEndian.AppendUint32(header, f.k)
+	header = binary.LittleEndian.AppendUint64(header, f.Count())
+	written, err := bw.Write(header)
+	if err != nil {
+		return int64(written), err
+	}
+
+	word := make([]byte, 8)
+	for _, bits := range f.bits {
+		binary.LittleEndian.PutUint64(word, bits)
+		n, err := bw.Write(word)
+		written += n
+		if err != nil {
+			return int64(written), err
+		}
+	}
+	return int64(written), bw.Flush()
+}
+
+// Read reads a filter from a snapshot written by WriteTo
+func Read(r io.Reader) (*Filter, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if [4]byte(header[:4]) != magic {
+		return nil, fmt.Errorf("%w: unknown format", ErrInvalidSnapshot)
+	}
+	m := binary.LittleEndian.Uint64(header[4:12])
+	k := binary.LittleEndian.Uint32(header[12:16])
+	if m == 0 || m%64 != 0 || m > maxBits || k == 0 || k > 64 {
+		return nil, fmt.Errorf("%w: %d bits, %d hashes", ErrInvalidSnapshot, m, k)
+	}
+
+	f := &Filter{bits: make([]uint64, m/64), m: m, k: k}
+	f.count.Store(binary.LittleEndian.Uint64(header[16:24]))
+	word := make([]byte, 8)
+	for i := range f.bits {
+		if _, err := io.ReadFull(br, word); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+		f.bits[i] = binary.LittleEndian.Uint64(word)
+	}
+	return f, nil
+}
+
+// WriteFile writes the filter as a snapshot at path. The snapshot is
+// written beside it and renamed into place, so readers never see a partial
+// file.
+func (f *Filter) WriteFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create bloom filter snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := f.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write bloom filter snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write bloom filter snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to install bloom filter snapshot: %w", err)
+	}
+	return nil
+}
+
+// ReadFile reads a filter from the snapshot at path
+func ReadFile(path string) (*Filter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
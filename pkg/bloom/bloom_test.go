@@ -0,0 +1,92 @@
+package bloom
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilter_NoFalseNegatives(t *testing.T) {
+	f := New(10000, 10)
+	for i := range 10000 {
+		f.Add(fmt.Sprintf("CODE%06d", i))
+	}
+
+	for i := range 10000 {
+		if code := fmt.Sprintf("CODE%06d", i); !f.MayContain(code) {
+			t.Fatalf("MayContain(%q) = false for an added value", code)
+		}
+	}
+	if f.Count() != 10000 {
+		t.Errorf("Count() = %d, want 10000", f.Count())
+	}
+}
+
+func TestFilter_FalsePositiveRate(t *testing.T) {
+	f := New(10000, 10)
+	for i := range 10000 {
+		f.Add(fmt.Sprintf("CODE%06d", i))
+	}
+
+	positives := 0
+	for i := range 10000 {
+		if f.MayContain(fmt.Sprintf("MISS%06d", i)) {
+			positives++
+		}
+	}
+	// About 1% is expected at ten bits per value
+	if positives > 200 {
+		t.Errorf("%d false positives in 10000 lookups, want about 100", positives)
+	}
+}
+
+func TestFilter_Snapshot(t *testing.T) {
+	f := New(100, 10)
+	f.Add("HAPPYHRS")
+	f.Add("FIFTYOFF")
+	path := filepath.Join(t.TempDir(), "coupons.bloom")
+
+	if err := f.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	read, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	if !read.MayContain("HAPPYHRS") || !read.MayContain("FIFTYOFF") {
+		t.Error("read filter lost an added value")
+	}
+	if read.Count() != 2 {
+		t.Errorf("Count() = %d, want 2", read.Count())
+	}
+	if read.SizeBytes() != f.SizeBytes() {
+		t.Errorf("SizeBytes() = %d, want %d", read.SizeBytes(), f.SizeBytes())
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	var valid bytes.Buffer
+	if _, err := New(100, 10).WriteTo(&valid); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "wrong magic", data: append([]byte("XXXX"), valid.Bytes()[4:]...)},
+		{name: "truncated", data: valid.Bytes()[:valid.Len()-8]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Read(bytes.NewReader(tt.data)); !errors.Is(err, ErrInvalidSnapshot) {
+				t.Errorf("Read() error = %v, want ErrInvalidSnapshot", err)
+			}
+		})
+	}
+}
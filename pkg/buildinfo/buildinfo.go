@@ -0,0 +1,75 @@
+// Package buildinfo describes the running binary. The values are injected
+// at build time with
+//
+//	-ldflags "-X github.com/shyampundkar/kart-challenge-workspace/pkg/buildinfo.Version=1.2.0
+//	          -X github.com/shyampundkar/kart-challenge-workspace/pkg/buildinfo.GitSHA=abc1234
+//	          -X github.com/shyampundkar/kart-challenge-workspace/pkg/buildinfo.BuildTime=2024-01-01T00:00:00Z"
+//
+// and fall back to the VCS stamp Go embeds when building inside a checkout.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Set through -ldflags -X; see the package documentation
+var (
+	Version   = "dev"
+	GitSHA    = ""
+	BuildTime = ""
+)
+
+// Info is the build description served by GET /version
+type Info struct {
+	Version   string `json:"version"`
+	GitSHA    string `json:"gitSha"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get returns the build description of the running binary
+func Get() Info {
+	once.Do(func() {
+		info = Info{
+			Version:   Version,
+			GitSHA:    GitSHA,
+			BuildTime: BuildTime,
+			GoVersion: runtime.Version(),
+		}
+		if info.GitSHA != "" && info.BuildTime != "" {
+			return
+		}
+
+		bi, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.GitSHA == "":
+				info.GitSHA = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = setting.Value
+			}
+		}
+	})
+	return info
+}
+
+// Labels returns the build description as the labels of a build_info
+// metric
+func (i Info) Labels() map[string]string {
+	return map[string]string{
+		"version":   i.Version,
+		"revision":  i.GitSHA,
+		"buildtime": i.BuildTime,
+		"goversion": i.GoVersion,
+	}
+}
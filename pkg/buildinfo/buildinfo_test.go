@@ -0,0 +1,32 @@
+package buildinfo
+
+import (
+	"maps"
+	"runtime"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	info := Get()
+
+	if info.Version != Version {
+		t.Errorf("Version = %q, want %q", info.Version, Version)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+}
+
+func TestInfo_Labels(t *testing.T) {
+	// Setup
+	info := Info{Version: "1.2.0", GitSHA: "abc1234", BuildTime: "2024-01-01T00:00:00Z", GoVersion: "go1.25.4"}
+
+	// Execute
+	labels := info.Labels()
+
+	// Assert
+	want := map[string]string{"version": "1.2.0", "revision": "abc1234", "buildtime": "2024-01-01T00:00:00Z", "goversion": "go1.25.4"}
+	if !maps.Equal(labels, want) {
+		t.Errorf("Labels() = %v, want %v", labels, want)
+	}
+}
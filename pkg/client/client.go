@@ -0,0 +1,225 @@
+// Package client is the Go SDK of the order-food API for services calling
+// it, so they do not hand-roll HTTP calls. It covers products, orders and
+// promo codes with typed methods, and pages through listings with
+// iterators. Requests go through an httpclient client, so reads and orders
+// are retried with backoff and a failing deployment trips its circuit
+// breaker.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/httpclient"
+)
+
+// Authentication headers of the order-food API
+const (
+	apiKeyHeader   = "api_key"
+	adminKeyHeader = "admin_key"
+	// requestIDHeader lets order-food log a call under the caller's request ID
+	requestIDHeader = "X-Request-ID"
+)
+
+// maxErrorBody bounds the error document read from a failed response
+const maxErrorBody = 64 << 10
+
+// Config configures a client. Only BaseURL is required.
+type Config struct {
+	// BaseURL is where order-food is served, such as http://order-food:8080
+	BaseURL string
+	// Name identifies the calling service in the User-Agent header
+	Name string
+	// APIKey authenticates product and order calls
+	APIKey string
+	// AdminKey authenticates promo code calls
+	AdminKey string
+	// Propagate copies trace context from ctx into outgoing headers, for
+	// example with the OpenTelemetry propagator of the calling service
+	Propagate func(ctx context.Context, header http.Header)
+	// HTTPClient sends the requests; it defaults to an httpclient client
+	// with httpclient.DefaultConfig
+	HTTPClient *http.Client
+}
+
+// Client calls the order-food API. It is safe for concurrent use.
+type Client struct {
+	baseURL  *url.URL
+	apiKey   string
+	adminKey string
+	http     *http.Client
+}
+
+// New creates a client from cfg
+func New(cfg Config) (*Client, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(cfg.BaseURL, "/"))
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q", cfg.BaseURL)
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		name := cfg.Name
+		if name == "" {
+			name = "order-food-client"
+		}
+		httpCfg := httpclient.DefaultConfig(name)
+		httpCfg.Propagate = cfg.Propagate
+		httpClient = httpclient.New(httpCfg)
+	}
+	return &Client{baseURL: baseURL, apiKey: cfg.APIKey, adminKey: cfg.AdminKey, http: httpClient}, nil
+}
+
+// Error is a response of order-food with an error status
+type Error struct {
+	StatusCode int
+	// Message is order-food's explanation of the error
+	Message string
+	// RequestID identifies the call in order-food's logs
+	RequestID string
+	// Body is the error document, for errors carrying more than a message
+	// such as the current prices of a 409
+	Body []byte
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("order-food: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("order-food: %d %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from order-food
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is a 409 from order-food, such as a price
+// that changed or a status transition that is not allowed
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose calls carry id as X-Request-ID, so
+// order-food logs them under the request of the calling service
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// envelope is the data wrapper of order-food responses
+type envelope[T any] struct {
+	Data T `json:"data"`
+}
+
+// page is a cursor page of a listing
+type page[T any] struct {
+	Data   []T `json:"data"`
+	Cursor struct {
+		NextCursor string `json:"nextCursor"`
+	} `json:"cursor"`
+}
+
+// request describes a call to order-food
+type request struct {
+	method string
+	// path is escaped, with path parameters escaped by url.PathEscape
+	path   string
+	query  url.Values
+	body   any
+	admin  bool
+	header http.Header
+}
+
+// do sends req and decodes the data of the response into out, if not nil
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	target, err := url.Parse(c.baseURL.String() + req.path)
+	if err != nil {
+		return err
+	}
+	target.RawQuery = req.query.Encode()
+
+	var body io.Reader
+	var payload []byte
+	if req.body != nil {
+		if payload, err = json.Marshal(req.body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target.String(), body)
+	if err != nil {
+		return err
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if payload != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if req.admin {
+		httpReq.Header.Set(adminKeyHeader, c.adminKey)
+	} else if c.apiKey != "" {
+		httpReq.Header.Set(apiKeyHeader, c.apiKey)
+	}
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok && id != "" {
+		httpReq.Header.Set(requestIDHeader, id)
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", req.method, req.path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return responseError(resp)
+	}
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: failed to decode response: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// responseError reads the error document of a failed response
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	apiErr := &Error{StatusCode: resp.StatusCode, RequestID: resp.Header.Get(requestIDHeader), Body: body}
+	var doc struct {
+		Message   string `json:"message"`
+		RequestID string `json:"requestId"`
+	}
+	if json.Unmarshal(body, &doc) == nil {
+		apiErr.Message = doc.Message
+		if doc.RequestID != "" {
+			apiErr.RequestID = doc.RequestID
+		}
+	}
+	return apiErr
+}
+
+// newIdempotencyKey returns a random key for a call that may be retried
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
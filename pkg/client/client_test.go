@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/httpclient"
+)
+
+// newTestClient returns a client of server that retries without waiting
+func newTestClient(t *testing.T, server *httptest.Server) *Client {
+	t.Helper()
+	c, err := New(Config{
+		BaseURL:    server.URL,
+		APIKey:     "apitest",
+		AdminKey:   "admintest",
+		HTTPClient: httpclient.New(httpclient.Config{MaxRetries: 2, RetryBudget: 1}),
+	})
+	if err != nil {
+		t.Fatalf("New returned %v", err)
+	}
+	return c
+}
+
+func TestClient_PlaceOrder_RetriesWithSameKey(t *testing.T) {
+	// Setup: the first attempt fails after order-food saw the key
+	var mu sync.Mutex
+	var keys []string
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		mu.Unlock()
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/orders" || r.Header.Get("api_key") != "apitest" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var req OrderReq
+		json.NewDecoder(r.Body).Decode(&req)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"data":{"id":"order-1","status":"pending","couponCode":%q,"total":13},"_links":[]}`, req.CouponCode)
+	}))
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	// Execute
+	order, err := c.PlaceOrder(context.Background(), OrderReq{
+		CouponCode: "HAPPYHRS",
+		Items:      []OrderItem{{ProductID: "1", Quantity: 2}},
+	})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("PlaceOrder returned %v", err)
+	}
+	if order.ID != "order-1" || order.CouponCode != "HAPPYHRS" || order.Total != 13 {
+		t.Errorf("got %+v, want order-1 with the promo code", order)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("idempotency keys %q, want the same key on both attempts", keys)
+	}
+}
+
+func TestClient_Orders_FollowsCursors(t *testing.T) {
+	// Setup: three orders served two at a time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("status") != "completed" || r.URL.Query().Get("limit") != "2" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		switch r.URL.Query().Get("after") {
+		case "":
+			fmt.Fprint(w, `{"data":[{"id":"a"},{"id":"b"}],"cursor":{"limit":2,"nextCursor":"b"}}`)
+		case "b":
+			fmt.Fprint(w, `{"data":[{"id":"c"}],"cursor":{"limit":2}}`)
+		default:
+			t.Errorf("unexpected cursor %q", r.URL.Query().Get("after"))
+		}
+	}))
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	// Execute
+	var ids []string
+	for order, err := range c.Orders(context.Background(), OrderListOptions{Status: OrderStatusCompleted, PageSize: 2}) {
+		if err != nil {
+			t.Fatalf("Orders yielded %v", err)
+		}
+		ids = append(ids, order.ID)
+	}
+
+	// Assert
+	if fmt.Sprint(ids) != "[a b c]" {
+		t.Errorf("got orders %v, want [a b c]", ids)
+	}
+}
+
+func TestClient_Products_StopsEarly(t *testing.T) {
+	// Setup
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		fmt.Fprint(w, `{"data":[{"id":"1","name":"Waffle","price":6.5},{"id":"2"}],"cursor":{"limit":100,"nextCursor":"2"}}`)
+	}))
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	// Execute
+	var first Product
+	for product, err := range c.Products(context.Background(), ProductListOptions{}) {
+		if err != nil {
+			t.Fatalf("Products yielded %v", err)
+		}
+		first = product
+		break
+	}
+
+	// Assert: the next page is never fetched
+	if first.Name != "Waffle" || first.Price != 6.5 {
+		t.Errorf("got %+v, want the waffle", first)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("server saw %d calls, want 1", calls.Load())
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	// Setup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Request-ID"); got != "req-1" {
+			t.Errorf("X-Request-ID is %q, want req-1", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"requestId":"req-1","code":404,"type":"error","message":"Order not found"}`)
+	}))
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	// Execute
+	_, err := c.GetOrder(WithRequestID(context.Background(), "req-1"), "missing")
+
+	// Assert
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("got %v, want an *Error", err)
+	}
+	if apiErr.Message != "Order not found" || apiErr.RequestID != "req-1" {
+		t.Errorf("got %+v, want the message and request ID of the response", apiErr)
+	}
+	if !IsNotFound(err) || IsConflict(err) {
+		t.Errorf("IsNotFound = %t, IsConflict = %t, want a not found error", IsNotFound(err), IsConflict(err))
+	}
+}
+
+func TestClient_PromoCodesUseAdminKey(t *testing.T) {
+	// Setup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("admin_key") != "admintest" || r.Header.Get("api_key") != "" {
+			t.Errorf("promo code call sent with the wrong key")
+		}
+		if r.URL.EscapedPath() != "/api/v1/admin/promo-codes/HAPPY%2FHRS/limits" {
+			t.Errorf("unexpected path %s", r.URL.EscapedPath())
+		}
+		var limits PromoCodeLimits
+		json.NewDecoder(r.Body).Decode(&limits)
+		fmt.Fprintf(w, `{"data":{"code":"HAPPY/HRS","limits":{"maxRedemptions":%d,"redemptions":3}}}`, limits.MaxRedemptions)
+	}))
+	defer server.Close()
+	c := newTestClient(t, server)
+
+	// Execute
+	promo, err := c.SetPromoCodeLimits(context.Background(), "HAPPY/HRS", PromoCodeLimits{MaxRedemptions: 100})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("SetPromoCodeLimits returned %v", err)
+	}
+	if promo.Limits == nil || promo.Limits.MaxRedemptions != 100 || promo.Limits.Redemptions != 3 {
+		t.Errorf("got %+v, want the stored limits", promo)
+	}
+}
+
+func TestNew_InvalidBaseURL(t *testing.T) {
+	for _, baseURL := range []string{"", "order-food:8080", "://"} {
+		if _, err := New(Config{BaseURL: baseURL}); err == nil {
+			t.Errorf("New(%q) succeeded, want an error", baseURL)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package client
+
+// Product is a product on the menu
+type Product struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Price       float64 `json:"price"`
+	Category    string  `json:"category"`
+	SKU         string  `json:"sku,omitempty"`
+	Barcode     string  `json:"barcode,omitempty"`
+	Description string  `json:"description,omitempty"`
+	// Language is the language tag Name and Description are in
+	Language string `json:"language,omitempty"`
+	// Prices holds the price in other currencies keyed by ISO 4217 code
+	Prices map[string]float64 `json:"prices,omitempty"`
+	// TaxRate is only set on the products of an order
+	TaxRate float64 `json:"taxRate,omitempty"`
+	// OptionGroups are the choices offered with the product, such as its
+	// size; on the products of an order, Price includes the modifiers of
+	// the item instead
+	OptionGroups []OptionGroup `json:"optionGroups,omitempty"`
+}
+
+// OptionGroup is a choice offered with a product. Order items select
+// between MinSelect and MaxSelect of its options.
+type OptionGroup struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	MinSelect int             `json:"minSelect"`
+	MaxSelect int             `json:"maxSelect"`
+	Options   []ProductOption `json:"options"`
+}
+
+// ProductOption is an option of an option group
+type ProductOption struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Price is added to the unit price of the product when selected
+	Price float64 `json:"price"`
+}
+
+// OrderItem is a product and quantity of an order
+type OrderItem struct {
+	ProductID string `json:"productId"`
+	Quantity  int    `json:"quantity"`
+	// ExpectedUnitPrice is the price the customer saw, including the
+	// modifiers; the order is refused with a conflict when the current
+	// price differs
+	ExpectedUnitPrice *float64 `json:"expectedUnitPrice,omitempty"`
+	// Modifiers are the options selected for the item
+	Modifiers []ItemModifier `json:"modifiers,omitempty"`
+	// Discount is the part of the order discount taken off this item
+	Discount float64 `json:"discount,omitempty"`
+}
+
+// ItemModifier is an option selected for an order item. Only OptionID is
+// sent; Group, Name and Price are set on orders.
+type ItemModifier struct {
+	OptionID string  `json:"optionId"`
+	Group    string  `json:"group,omitempty"`
+	Name     string  `json:"name,omitempty"`
+	Price    float64 `json:"price,omitempty"`
+}
+
+// OrderReq is an order to place
+type OrderReq struct {
+	CouponCode    string      `json:"couponCode,omitempty"`
+	Items         []OrderItem `json:"items"`
+	ReservationID string      `json:"reservationId,omitempty"`
+	CustomerID    string      `json:"customerId,omitempty"`
+	Delivery      *Delivery   `json:"delivery,omitempty"`
+	PaymentMethod string      `json:"paymentMethod,omitempty"`
+	// IdempotencyKey makes placing the order safe to retry: order-food
+	// answers a repeated key with the order placed first. A random key is
+	// used for the retries of one call when it is empty.
+	IdempotencyKey string `json:"-"`
+}
+
+// OrderStatus is the stage of an order in the kitchen lifecycle
+type OrderStatus string
+
+// Order statuses
+const (
+	OrderStatusPending   OrderStatus = "pending"
+	OrderStatusConfirmed OrderStatus = "confirmed"
+	OrderStatusPreparing OrderStatus = "preparing"
+	OrderStatusCompleted OrderStatus = "completed"
+	OrderStatusCancelled OrderStatus = "cancelled"
+)
+
+// Order is a placed order
+type Order struct {
+	ID         string      `json:"id"`
+	CouponCode string      `json:"couponCode,omitempty"`
+	Status     OrderStatus `json:"status"`
+	CustomerID string      `json:"customerId,omitempty"`
+	// Items and Products are left out for orders with many items;
+	// ItemCount is given instead
+	Items     []OrderItem `json:"items,omitempty"`
+	Products  []Product   `json:"products,omitempty"`
+	ItemCount int         `json:"itemCount,omitempty"`
+	Subtotal  float64     `json:"subtotal"`
+	Discount  float64     `json:"discount"`
+	Total     float64     `json:"total"`
+	Archived  bool        `json:"archived,omitempty"`
+	// Delivery and Payment are only returned for a single order
+	Delivery *Delivery `json:"delivery,omitempty"`
+	Payment  *Payment  `json:"payment,omitempty"`
+}
+
+// Delivery is the delivery address and contact of an order
+type Delivery struct {
+	Address      DeliveryAddress `json:"address"`
+	Contact      DeliveryContact `json:"contact"`
+	Instructions string          `json:"instructions,omitempty"`
+}
+
+// DeliveryAddress is where an order is delivered
+type DeliveryAddress struct {
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postalCode"`
+	// Country is an ISO 3166-1 alpha-2 code
+	Country string `json:"country"`
+}
+
+// DeliveryContact is who receives an order
+type DeliveryContact struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+	Email string `json:"email,omitempty"`
+}
+
+// Payment is the charge of an order at a payment provider
+type Payment struct {
+	Provider string `json:"provider"`
+	IntentID string `json:"intentId"`
+	Status   string `json:"status"`
+	// Amount is in the smallest unit of Currency, e.g. cents
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// DiscountType is how a promo code discount is worked out
+type DiscountType string
+
+// Discount types
+const (
+	DiscountTypePercentage DiscountType = "percentage"
+	DiscountTypeFixed      DiscountType = "fixed"
+)
+
+// Discount is what a promo code takes off an order
+type Discount struct {
+	Type  DiscountType `json:"type"`
+	Value float64      `json:"value"`
+	// Categories and ProductIDs restrict the discount to some items
+	Categories []string `json:"categories,omitempty"`
+	ProductIDs []string `json:"productIds,omitempty"`
+}
+
+// PromoCodeLimits restricts how often a promo code can be used
+type PromoCodeLimits struct {
+	// MaxRedemptions is 0 for no limit
+	MaxRedemptions  int  `json:"maxRedemptions"`
+	OncePerCustomer bool `json:"oncePerCustomer"`
+	// Redemptions is how many orders have used the code; it is ignored
+	// when setting limits
+	Redemptions int `json:"redemptions"`
+}
+
+// PromoCode is a promo code with its discount and limits
+type PromoCode struct {
+	Code     string           `json:"code"`
+	Discount *Discount        `json:"discount,omitempty"`
+	Limits   *PromoCodeLimits `json:"limits,omitempty"`
+}
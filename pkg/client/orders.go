@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// OrderListOptions narrows and orders an order listing; zero fields do
+// not filter
+type OrderListOptions struct {
+	Status OrderStatus
+	// From and To match orders placed in [From, To)
+	From, To   time.Time
+	CouponCode string
+	ProductID  string
+	// Sort is comma-separated fields, - prefixed for descending
+	Sort string
+	// PageSize is the number of orders fetched per request, up to 100
+	PageSize int
+}
+
+// PlaceOrder places an order. The call is retried on transient failures
+// under the order's idempotency key, so it places the order at most once.
+func (c *Client) PlaceOrder(ctx context.Context, req OrderReq) (Order, error) {
+	key := req.IdempotencyKey
+	if key == "" {
+		key = newIdempotencyKey()
+	}
+	var resp envelope[Order]
+	err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/v1/orders",
+		body:   req,
+		header: http.Header{"Idempotency-Key": {key}},
+	}, &resp)
+	return resp.Data, err
+}
+
+// GetOrder returns an order by ID, with its delivery and payment
+func (c *Client) GetOrder(ctx context.Context, orderID string) (Order, error) {
+	var resp envelope[Order]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/orders/" + url.PathEscape(orderID)}, &resp)
+	return resp.Data, err
+}
+
+// UpdateOrderStatus moves an order to status. A transition the lifecycle
+// does not allow fails with a conflict, see IsConflict.
+func (c *Client) UpdateOrderStatus(ctx context.Context, orderID string, status OrderStatus) (Order, error) {
+	var resp envelope[Order]
+	err := c.do(ctx, request{
+		method: http.MethodPatch,
+		path:   "/api/v1/orders/" + url.PathEscape(orderID) + "/status",
+		body:   map[string]OrderStatus{"status": status},
+		// Setting the status an order already has is a no-op, so the call
+		// can be retried
+		header: http.Header{"Idempotency-Key": {newIdempotencyKey()}},
+	}, &resp)
+	return resp.Data, err
+}
+
+// Orders yields every order matching opts, page by page
+func (c *Client) Orders(ctx context.Context, opts OrderListOptions) iter.Seq2[Order, error] {
+	query := url.Values{}
+	if opts.Status != "" {
+		query.Set("status", string(opts.Status))
+	}
+	if !opts.From.IsZero() {
+		query.Set("from", opts.From.Format(time.RFC3339))
+	}
+	if !opts.To.IsZero() {
+		query.Set("to", opts.To.Format(time.RFC3339))
+	}
+	if opts.CouponCode != "" {
+		query.Set("couponCode", opts.CouponCode)
+	}
+	if opts.ProductID != "" {
+		query.Set("productId", opts.ProductID)
+	}
+	if opts.Sort != "" {
+		query.Set("sort", opts.Sort)
+	}
+	return listAll[Order](ctx, c, "/api/v1/orders", query, opts.PageSize)
+}
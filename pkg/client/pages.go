@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// listAll yields every record of the listing at path, fetching cursor
+// pages of limit records as the loop asks for them. An error ends the
+// listing after it is yielded.
+func listAll[T any](ctx context.Context, c *Client, path string, query url.Values, limit int) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		query := cloneQuery(query)
+		if limit > 0 {
+			query.Set("limit", strconv.Itoa(limit))
+		} else {
+			query.Set("limit", "100")
+		}
+		for {
+			var p page[T]
+			if err := c.do(ctx, request{method: http.MethodGet, path: path, query: query}, &p); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, record := range p.Data {
+				if !yield(record, nil) {
+					return
+				}
+			}
+			if p.Cursor.NextCursor == "" {
+				return
+			}
+			query.Set("after", p.Cursor.NextCursor)
+		}
+	}
+}
+
+// cloneQuery copies query, so a listing can be ranged over more than once
+func cloneQuery(query url.Values) url.Values {
+	clone := make(url.Values, len(query)+2)
+	for key, values := range query {
+		clone[key] = append([]string(nil), values...)
+	}
+	return clone
+}
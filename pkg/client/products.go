@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ProductListOptions narrows and orders a product listing; zero fields
+// do not filter
+type ProductListOptions struct {
+	Category string
+	// MinPrice and MaxPrice bound the price in dollars, inclusive
+	MinPrice *float64
+	MaxPrice *float64
+	// Sort is comma-separated fields, - prefixed for descending
+	Sort string
+	// PageSize is the number of products fetched per request, up to 100
+	PageSize int
+}
+
+// GetProduct returns a product by ID
+func (c *Client) GetProduct(ctx context.Context, productID string) (Product, error) {
+	var resp envelope[Product]
+	err := c.do(ctx, request{method: http.MethodGet, path: "/api/v1/products/" + url.PathEscape(productID)}, &resp)
+	return resp.Data, err
+}
+
+// Products yields every product matching opts, page by page
+func (c *Client) Products(ctx context.Context, opts ProductListOptions) iter.Seq2[Product, error] {
+	query := url.Values{}
+	if opts.Category != "" {
+		query.Set("category", opts.Category)
+	}
+	if opts.MinPrice != nil {
+		query.Set("minPrice", strconv.FormatFloat(*opts.MinPrice, 'f', -1, 64))
+	}
+	if opts.MaxPrice != nil {
+		query.Set("maxPrice", strconv.FormatFloat(*opts.MaxPrice, 'f', -1, 64))
+	}
+	if opts.Sort != "" {
+		query.Set("sort", opts.Sort)
+	}
+	return listAll[Product](ctx, c, "/api/v1/products", query, opts.PageSize)
+}
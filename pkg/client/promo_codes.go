@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// SetPromoCodeDiscount sets the discount a promo code gives
+func (c *Client) SetPromoCodeDiscount(ctx context.Context, code string, discount Discount) (PromoCode, error) {
+	var resp envelope[PromoCode]
+	err := c.do(ctx, request{method: http.MethodPut, path: promoCodePath(code, "discount"), body: discount, admin: true}, &resp)
+	return resp.Data, err
+}
+
+// RemovePromoCodeDiscount removes the discount of a promo code
+func (c *Client) RemovePromoCodeDiscount(ctx context.Context, code string) error {
+	return c.do(ctx, request{method: http.MethodDelete, path: promoCodePath(code, "discount"), admin: true}, nil)
+}
+
+// GetPromoCodeLimits returns a promo code with its usage limits and
+// redemptions
+func (c *Client) GetPromoCodeLimits(ctx context.Context, code string) (PromoCode, error) {
+	var resp envelope[PromoCode]
+	err := c.do(ctx, request{method: http.MethodGet, path: promoCodePath(code, "limits"), admin: true}, &resp)
+	return resp.Data, err
+}
+
+// SetPromoCodeLimits sets the usage limits of a promo code
+func (c *Client) SetPromoCodeLimits(ctx context.Context, code string, limits PromoCodeLimits) (PromoCode, error) {
+	var resp envelope[PromoCode]
+	err := c.do(ctx, request{method: http.MethodPut, path: promoCodePath(code, "limits"), body: limits, admin: true}, &resp)
+	return resp.Data, err
+}
+
+func promoCodePath(code, resource string) string {
+	return "/api/v1/admin/promo-codes/" + url.PathEscape(code) + "/" + resource
+}
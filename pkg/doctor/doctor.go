@@ -0,0 +1,209 @@
+// Package doctor runs start-up self-checks and prints a diagnosis, so a
+// misconfigured deployment fails with an explanation instead of a crash.
+package doctor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrSkipped marks a check that does not apply to this deployment
+var ErrSkipped = errors.New("skipped")
+
+// DefaultTimeout bounds each check
+const DefaultTimeout = 5 * time.Second
+
+// CheckFunc runs one check and returns a short detail for the report
+type CheckFunc func(ctx context.Context) (string, error)
+
+type check struct {
+	name      string
+	dependsOn string
+	fn        CheckFunc
+}
+
+// Doctor is an ordered list of checks
+type Doctor struct {
+	timeout time.Duration
+	checks  []check
+}
+
+// New creates a doctor whose checks time out after timeout
+func New(timeout time.Duration) *Doctor {
+	return &Doctor{timeout: timeout}
+}
+
+// Check adds a check. Checks run in the order they were added.
+func (d *Doctor) Check(name string, fn CheckFunc) {
+	d.checks = append(d.checks, check{name: name, fn: fn})
+}
+
+// CheckAfter adds a check that is skipped when the earlier check named
+// dependsOn failed, so one outage is not reported many times over
+func (d *Doctor) CheckAfter(dependsOn, name string, fn CheckFunc) {
+	d.checks = append(d.checks, check{name: name, dependsOn: dependsOn, fn: fn})
+}
+
+// Run executes every check, writes one line per check to w and returns an
+// error naming the failed checks, if any. Every check runs even after a
+// failure so the whole diagnosis is printed at once.
+func (d *Doctor) Run(ctx context.Context, w io.Writer) error {
+	var failed []string
+	for _, c := range d.checks {
+		if c.dependsOn != "" && slices.Contains(failed, c.dependsOn) {
+			fmt.Fprintf(w, "-  %s: skipped, %s failed\n", c.name, c.dependsOn)
+			continue
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, d.timeout)
+		detail, err := c.fn(checkCtx)
+		cancel()
+
+		switch {
+		case errors.Is(err, ErrSkipped):
+			fmt.Fprintf(w, "-  %s: %s\n", c.name, detailOr(detail, "skipped"))
+		case err != nil:
+			fmt.Fprintf(w, "✗  %s: %v\n", c.name, err)
+			failed = append(failed, c.name)
+		default:
+			fmt.Fprintf(w, "✓  %s: %s\n", c.name, detailOr(detail, "ok"))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d checks failed: %s", len(failed), len(d.checks), strings.Join(failed, ", "))
+	}
+	fmt.Fprintf(w, "All %d checks passed\n", len(d.checks))
+	return nil
+}
+
+func detailOr(detail, fallback string) string {
+	if detail == "" {
+		return fallback
+	}
+	return detail
+}
+
+// Connectivity checks that db answers and reports the server version
+func Connectivity(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		var version string
+		if err := db.QueryRowContext(ctx, `SHOW server_version`).Scan(&version); err != nil {
+			return "", err
+		}
+		return "PostgreSQL " + version, nil
+	}
+}
+
+// Tables checks that every table exists and that the current user holds
+// each of the privileges (for example "SELECT,INSERT") on it
+func Tables(db *sql.DB, privileges string, tables ...string) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		var problems []string
+		for _, table := range tables {
+			var exists bool
+			if err := db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists); err != nil {
+				return "", err
+			}
+			if !exists {
+				problems = append(problems, table+" is missing")
+				continue
+			}
+
+			// has_table_privilege is true when any listed privilege is
+			// held, so each one is checked on its own
+			for _, privilege := range strings.Split(privileges, ",") {
+				var allowed bool
+				if err := db.QueryRowContext(ctx, `SELECT has_table_privilege($1, $2)`, table, privilege).Scan(&allowed); err != nil {
+					return "", err
+				}
+				if !allowed {
+					problems = append(problems, fmt.Sprintf("no %s privilege on %s", privilege, table))
+				}
+			}
+		}
+		if len(problems) > 0 {
+			return "", errors.New(strings.Join(problems, "; "))
+		}
+		return fmt.Sprintf("%d tables present with %s", len(tables), privileges), nil
+	}
+}
+
+// SchemaVersion reads the golang-migrate version of the database. ok is
+// false when no migration has been applied.
+func SchemaVersion(ctx context.Context, db *sql.DB) (version uint, dirty, ok bool, err error) {
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, false, false, err
+	}
+	if !exists {
+		return 0, false, false, nil
+	}
+
+	err = db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, false, nil
+	}
+	if err != nil {
+		return 0, false, false, err
+	}
+	return version, dirty, true, nil
+}
+
+// MinSchemaVersion checks that migrations up to at least minVersion have
+// been applied cleanly
+func MinSchemaVersion(db *sql.DB, minVersion uint) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		version, dirty, ok, err := SchemaVersion(ctx, db)
+		switch {
+		case err != nil:
+			return "", err
+		case !ok:
+			return "", errors.New("no migrations applied; run database-migration")
+		case dirty:
+			return "", fmt.Errorf("version %d is dirty; a migration failed part way and needs fixing", version)
+		case version < minVersion:
+			return "", fmt.Errorf("version %d is older than the required %d; run database-migration", version, minVersion)
+		}
+		return fmt.Sprintf("version %d", version), nil
+	}
+}
+
+// Reachable checks that a TCP connection can be opened to endpoint, given
+// either as a URL or as host:port. An empty endpoint skips the check.
+func Reachable(endpoint string) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		if endpoint == "" {
+			return "not configured", ErrSkipped
+		}
+
+		address := endpoint
+		if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+			address = u.Host
+			if u.Port() == "" {
+				switch u.Scheme {
+				case "https":
+					address += ":443"
+				default:
+					address += ":80"
+				}
+			}
+		}
+
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return "", err
+		}
+		conn.Close()
+		return address + " reachable", nil
+	}
+}
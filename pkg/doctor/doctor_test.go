@@ -0,0 +1,108 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDoctor_RunReportsEveryCheck(t *testing.T) {
+	// Setup
+	d := New(time.Second)
+	d.Check("config", func(ctx context.Context) (string, error) { return "", nil })
+	d.Check("database", func(ctx context.Context) (string, error) { return "", errors.New("connection refused") })
+	d.Check("exporter", func(ctx context.Context) (string, error) { return "not configured", ErrSkipped })
+	d.Check("tables", func(ctx context.Context) (string, error) { return "3 tables present", nil })
+
+	// Execute
+	var out bytes.Buffer
+	err := d.Run(context.Background(), &out)
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "1 of 4 checks failed: database") {
+		t.Errorf("Run error = %v, want database failure", err)
+	}
+	want := "✓  config: ok\n" +
+		"✗  database: connection refused\n" +
+		"-  exporter: not configured\n" +
+		"✓  tables: 3 tables present\n"
+	if out.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestDoctor_CheckAfterSkipsOnFailedDependency(t *testing.T) {
+	// Setup
+	d := New(time.Second)
+	d.Check("database", func(ctx context.Context) (string, error) { return "", errors.New("connection refused") })
+	d.CheckAfter("database", "tables", func(ctx context.Context) (string, error) {
+		t.Error("dependent check ran after its dependency failed")
+		return "", nil
+	})
+
+	// Execute
+	var out bytes.Buffer
+	err := d.Run(context.Background(), &out)
+
+	// Assert
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 checks failed") {
+		t.Errorf("Run error = %v, want one failure", err)
+	}
+	if !strings.Contains(out.String(), "-  tables: skipped, database failed\n") {
+		t.Errorf("output = %q, want skipped tables check", out.String())
+	}
+}
+
+func TestDoctor_RunPasses(t *testing.T) {
+	// Setup
+	d := New(time.Second)
+	d.Check("config", func(ctx context.Context) (string, error) { return "", nil })
+
+	// Execute
+	var out bytes.Buffer
+	err := d.Run(context.Background(), &out)
+
+	// Assert
+	if err != nil {
+		t.Errorf("Run error = %v, want nil", err)
+	}
+	if !strings.HasSuffix(out.String(), "All 1 checks passed\n") {
+		t.Errorf("output = %q, want summary line", out.String())
+	}
+}
+
+func TestReachable(t *testing.T) {
+	// Setup
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	address := listener.Addr().String()
+
+	tests := []struct {
+		name     string
+		endpoint string
+		wantErr  error
+	}{
+		{name: "not configured", endpoint: "", wantErr: ErrSkipped},
+		{name: "host and port", endpoint: address},
+		{name: "URL", endpoint: "http://" + address + "/v1/traces"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Execute
+			_, err := Reachable(tt.endpoint)(context.Background())
+
+			// Assert
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
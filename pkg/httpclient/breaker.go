@@ -0,0 +1,108 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// maxBudgetTokens caps how many retries can be saved up while healthy
+const maxBudgetTokens = 10
+
+// budget is a token bucket that limits retries to a share of requests
+type budget struct {
+	mu     sync.Mutex
+	ratio  float64
+	tokens float64
+}
+
+func newBudget(ratio float64) *budget {
+	return &budget{ratio: ratio, tokens: maxBudgetTokens}
+}
+
+// deposit credits one request
+func (b *budget) deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio, maxBudgetTokens)
+}
+
+// withdraw spends one retry, reporting false when the budget is exhausted
+func (b *budget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.ratio <= 0 || b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// breakers holds one circuit breaker per host
+type breakers struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	hosts     map[string]*breaker
+}
+
+func newBreakers(threshold int, cooldown time.Duration) *breakers {
+	return &breakers{threshold: threshold, cooldown: cooldown, now: time.Now, hosts: make(map[string]*breaker)}
+}
+
+func (b *breakers) forHost(host string) *breaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	br, ok := b.hosts[host]
+	if !ok {
+		br = &breaker{parent: b}
+		b.hosts[host] = br
+	}
+	return br
+}
+
+// breaker opens after parent.threshold consecutive failures. Once the
+// cooldown has passed it lets a single trial through: success closes it,
+// failure opens it for another cooldown.
+type breaker struct {
+	parent   *breakers
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// allow reports whether a request may be sent
+func (b *breaker) allow() bool {
+	if b.parent.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.parent.threshold {
+		return true
+	}
+	if b.trial || b.parent.now().Sub(b.openedAt) < b.parent.cooldown {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// record reports the outcome of a request let through by allow
+func (b *breaker) record(ok bool) {
+	if b.parent.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if ok {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.parent.threshold {
+		b.openedAt = b.parent.now()
+	}
+}
@@ -0,0 +1,214 @@
+// Package httpclient builds the HTTP client services use for outgoing
+// calls. Every attempt gets its own timeout, idempotent requests are retried
+// with jittered backoff within a retry budget, and a per-host circuit
+// breaker stops calls to a dependency that keeps failing. Use it instead of
+// http.DefaultClient, which has no timeout at all.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/buildinfo"
+)
+
+// ErrCircuitOpen is returned without calling the host while its breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// IdempotencyKeyHeader marks a POST or PATCH as safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Config configures a client. Zero values disable the matching feature,
+// except Transport which defaults to http.DefaultTransport.
+type Config struct {
+	// Name identifies the caller in the User-Agent header
+	Name string
+	// Timeout bounds each attempt, including reading the response body
+	Timeout time.Duration
+	// MaxRetries is how many times a failed attempt is repeated
+	MaxRetries int
+	// BaseBackoff and MaxBackoff bound the randomised wait between attempts
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// RetryBudget is the number of retries earned per request, so that a
+	// failing dependency sees at most 1+RetryBudget times its normal load
+	RetryBudget float64
+	// BreakerThreshold consecutive failures open a host's breaker for
+	// BreakerCooldown, after which a single trial request is let through
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// Propagate copies trace context from ctx into outgoing headers
+	Propagate func(ctx context.Context, header http.Header)
+	// Transport performs the requests
+	Transport http.RoundTripper
+}
+
+// DefaultConfig returns the settings used for calls to partner services
+func DefaultConfig(name string) Config {
+	return Config{
+		Name:             name,
+		Timeout:          10 * time.Second,
+		MaxRetries:       2,
+		BaseBackoff:      100 * time.Millisecond,
+		MaxBackoff:       2 * time.Second,
+		RetryBudget:      0.2,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// New creates a client from cfg
+func New(cfg Config) *http.Client {
+	if cfg.Transport == nil {
+		cfg.Transport = http.DefaultTransport
+	}
+	return &http.Client{Transport: &transport{
+		cfg:       cfg,
+		userAgent: cfg.Name + "/" + buildinfo.Get().Version,
+		budget:    newBudget(cfg.RetryBudget),
+		breakers:  newBreakers(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		sleep:     sleep,
+	}}
+}
+
+// transport implements the retry and breaker policy around cfg.Transport
+type transport struct {
+	cfg       Config
+	userAgent string
+	budget    *budget
+	breakers  *breakers
+	sleep     func(ctx context.Context, d time.Duration) error
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.budget.deposit()
+	breaker := t.breakers.forHost(req.URL.Host)
+
+	for attempt := 0; ; attempt++ {
+		if !breaker.allow() {
+			return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Host, ErrCircuitOpen)
+		}
+
+		resp, err := t.attempt(req, attempt)
+		breaker.record(err == nil && resp.StatusCode < http.StatusInternalServerError)
+
+		if !t.shouldRetry(req, resp, err, attempt) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends one copy of req under the per-attempt timeout
+func (t *transport) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.cfg.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.cfg.Timeout)
+	}
+
+	out := req.Clone(ctx)
+	if attempt > 0 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		out.Body = body
+	}
+	if out.Header.Get("User-Agent") == "" {
+		out.Header.Set("User-Agent", t.userAgent)
+	}
+	if t.cfg.Propagate != nil {
+		t.cfg.Propagate(ctx, out.Header)
+	}
+
+	resp, err := t.cfg.Transport.RoundTrip(out)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The timeout must keep running while the caller reads the body
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// shouldRetry reports whether another attempt is allowed and worthwhile
+func (t *transport) shouldRetry(req *http.Request, resp *http.Response, err error, attempt int) bool {
+	if attempt >= t.cfg.MaxRetries || req.Context().Err() != nil || !replayable(req) {
+		return false
+	}
+	if err == nil {
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		default:
+			return false
+		}
+	}
+	return t.budget.withdraw()
+}
+
+// replayable reports whether req can be sent again without side effects
+func replayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// backoff returns the wait before the next attempt. Retry-After is honoured
+// up to MaxBackoff; otherwise the wait is drawn uniformly from zero to the
+// exponential backoff so retrying clients spread out.
+func (t *transport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, t.cfg.MaxBackoff)
+		}
+	}
+	ceiling := min(t.cfg.BaseBackoff<<attempt, t.cfg.MaxBackoff)
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// cancelOnClose releases the attempt's timeout once the body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,226 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client for tests that does not sleep between attempts
+func newTestClient(cfg Config) (*http.Client, *transport) {
+	client := New(cfg)
+	tr := client.Transport.(*transport)
+	tr.sleep = func(ctx context.Context, d time.Duration) error { return ctx.Err() }
+	return client, tr
+}
+
+// flakyServer fails the first failures requests with status
+func flakyServer(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestClient_RetriesTransientFailures(t *testing.T) {
+	// Setup
+	server, calls := flakyServer(t, 2, http.StatusServiceUnavailable)
+	client, _ := newTestClient(DefaultConfig("test"))
+
+	// Execute
+	req, _ := http.NewRequest(http.MethodPut, server.URL, strings.NewReader("payload"))
+	resp, err := client.Do(req)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Do returned %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "payload" {
+		t.Errorf("got %d %q, want 200 with the replayed body", resp.StatusCode, body)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("server saw %d calls, want 3", calls.Load())
+	}
+}
+
+func TestClient_DoesNotRetryUnsafeRequests(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		wantCalls int32
+	}{
+		{name: "POST without idempotency key", wantCalls: 1},
+		{name: "POST with idempotency key", key: "order-1", wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Setup
+			server, calls := flakyServer(t, 5, http.StatusBadGateway)
+			client, _ := newTestClient(DefaultConfig("test"))
+			req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("{}"))
+			if tt.key != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.key)
+			}
+
+			// Execute
+			resp, err := client.Do(req)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Do returned %v", err)
+			}
+			resp.Body.Close()
+			if calls.Load() != tt.wantCalls {
+				t.Errorf("server saw %d calls, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClient_RetryBudgetLimitsRetries(t *testing.T) {
+	// Setup
+	server, calls := flakyServer(t, 1000, http.StatusServiceUnavailable)
+	cfg := DefaultConfig("test")
+	cfg.BreakerThreshold = 0
+	client, tr := newTestClient(cfg)
+	tr.budget.tokens = 1
+
+	// Execute
+	for range 5 {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Get returned %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// Assert: one saved token plus 0.2 per request allows two retries
+	if calls.Load() != 7 {
+		t.Errorf("server saw %d calls, want 7", calls.Load())
+	}
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	// Setup
+	server, calls := flakyServer(t, 3, http.StatusInternalServerError)
+	cfg := DefaultConfig("test")
+	cfg.BreakerThreshold = 3
+	client, tr := newTestClient(cfg)
+	now := time.Now()
+	tr.breakers.now = func() time.Time { return now }
+
+	for range 3 {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Get returned %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// Execute: the breaker is open
+	_, err := client.Get(server.URL)
+
+	// Assert
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Get error = %v, want ErrCircuitOpen", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("server saw %d calls, want 3", calls.Load())
+	}
+
+	// Execute: after the cooldown a trial request closes it again
+	now = now.Add(cfg.BreakerCooldown)
+	resp, err := client.Get(server.URL)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Get after cooldown returned %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status after cooldown = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestClient_AttemptTimeout(t *testing.T) {
+	// Setup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	cfg := DefaultConfig("test")
+	cfg.Timeout = 20 * time.Millisecond
+	cfg.MaxRetries = 0
+	client, _ := newTestClient(cfg)
+
+	// Execute
+	_, err := client.Get(server.URL)
+
+	// Assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestClient_SetsHeaders(t *testing.T) {
+	// Setup
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+	cfg := DefaultConfig("order-food")
+	cfg.Propagate = func(ctx context.Context, header http.Header) {
+		header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	}
+	client, _ := newTestClient(cfg)
+
+	// Execute
+	resp, err := client.Get(server.URL)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Get returned %v", err)
+	}
+	resp.Body.Close()
+	if ua := got.Get("User-Agent"); !strings.HasPrefix(ua, "order-food/") {
+		t.Errorf("User-Agent = %q, want order-food/<version>", ua)
+	}
+	if got.Get("traceparent") == "" {
+		t.Error("trace context was not propagated")
+	}
+}
+
+func TestTransport_BackoffHonoursRetryAfter(t *testing.T) {
+	// Setup
+	tr := &transport{cfg: DefaultConfig("test")}
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"1"}}}
+
+	// Execute
+	wait := tr.backoff(0, resp)
+	jittered := tr.backoff(3, nil)
+
+	// Assert
+	if wait != time.Second {
+		t.Errorf("backoff with Retry-After = %v, want 1s", wait)
+	}
+	if jittered < 0 || jittered >= 800*time.Millisecond {
+		t.Errorf("backoff(3) = %v, want within [0, 800ms)", jittered)
+	}
+}
@@ -0,0 +1,82 @@
+// Package instance identifies the running replica of a service, so a
+// response, log line, metric or span can be traced back to the process that
+// produced it. The pod name comes from POD_NAME, which the Helm charts set
+// through the downward API.
+package instance
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Header carries the instance ID on every response
+const Header = "X-Instance-ID"
+
+// Identity describes one running process
+type Identity struct {
+	// ID is a random UUID chosen at startup; it tells apart restarts of the
+	// same pod
+	ID       string `json:"id"`
+	Hostname string `json:"hostname"`
+	// Pod is empty outside Kubernetes
+	Pod string `json:"pod,omitempty"`
+}
+
+var (
+	once     sync.Once
+	identity Identity
+)
+
+// Get returns the identity of the running process
+func Get() Identity {
+	once.Do(func() {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "unknown"
+		}
+		identity = Identity{
+			ID:       newID(),
+			Hostname: hostname,
+			Pod:      os.Getenv("POD_NAME"),
+		}
+	})
+	return identity
+}
+
+// Name is the most recognisable name of the instance: the pod name when
+// running in Kubernetes, otherwise the hostname
+func (i Identity) Name() string {
+	if i.Pod != "" {
+		return i.Pod
+	}
+	return i.Hostname
+}
+
+// ShortID is the first block of ID, short enough for a log prefix
+func (i Identity) ShortID() string {
+	if len(i.ID) < 8 {
+		return i.ID
+	}
+	return i.ID[:8]
+}
+
+// Labels returns the identity as the labels of an instance_info metric
+func (i Identity) Labels() map[string]string {
+	return map[string]string{
+		"instance_id": i.ID,
+		"hostname":    i.Hostname,
+		"pod":         i.Pod,
+	}
+}
+
+// newID returns a random version 4 UUID
+func newID() string {
+	var b [16]byte
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
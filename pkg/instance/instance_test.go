@@ -0,0 +1,55 @@
+package instance
+
+import (
+	"maps"
+	"regexp"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	id := Get()
+
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !uuidPattern.MatchString(id.ID) {
+		t.Errorf("ID = %q, want a version 4 UUID", id.ID)
+	}
+	if id.Hostname == "" {
+		t.Error("Hostname is empty")
+	}
+	if again := Get(); again != id {
+		t.Errorf("Get() = %+v on second call, want %+v", again, id)
+	}
+}
+
+func TestIdentity_Name(t *testing.T) {
+	tests := []struct {
+		name string
+		id   Identity
+		want string
+	}{
+		{name: "pod", id: Identity{Hostname: "host-1", Pod: "order-food-7d9f-abcde"}, want: "order-food-7d9f-abcde"},
+		{name: "no pod", id: Identity{Hostname: "host-1"}, want: "host-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.id.Name(); got != tt.want {
+				t.Errorf("Name() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentity_Labels(t *testing.T) {
+	// Setup
+	id := Identity{ID: "0b6f4e2a-1c3d-4e5f-8a9b-0c1d2e3f4a5b", Hostname: "host-1", Pod: "order-food-7d9f-abcde"}
+
+	// Execute
+	labels := id.Labels()
+
+	// Assert
+	want := map[string]string{"instance_id": "0b6f4e2a-1c3d-4e5f-8a9b-0c1d2e3f4a5b", "hostname": "host-1", "pod": "order-food-7d9f-abcde"}
+	if !maps.Equal(labels, want) {
+		t.Errorf("Labels() = %v, want %v", labels, want)
+	}
+}
@@ -0,0 +1,115 @@
+// Package operation gives every run of a pipeline job an operation ID that
+// can be followed across services. An operation ID is a W3C trace ID, so
+// the same value finds the run in the tracing backend, the logs and the
+// pipeline_runs table. A job continues the operation of whatever started
+// it through the TRACEPARENT environment variable, the OpenTelemetry
+// convention for passing trace context to a process; without one it starts
+// a new operation.
+package operation
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+)
+
+// EnvTraceparent is the environment variable a job reads its parent from
+const EnvTraceparent = "TRACEPARENT"
+
+// ErrInvalidTraceparent is returned for a malformed traceparent value
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// Operation is one traced run of a job
+type Operation struct {
+	// ID is the 32 hex character trace ID shared by every step of the operation
+	ID string
+	// SpanID identifies this step within the operation
+	SpanID string
+	// ParentSpanID is the step that started this one; empty for a new operation
+	ParentSpanID string
+}
+
+// Start continues the operation named by TRACEPARENT, or starts a new one
+// when it is unset or malformed
+func Start() Operation {
+	if parent, err := Parse(os.Getenv(EnvTraceparent)); err == nil {
+		return parent.Child()
+	}
+	return New()
+}
+
+// New starts a new operation
+func New() Operation {
+	return Operation{ID: randomHex(16), SpanID: randomHex(8)}
+}
+
+// Continue returns a new step of the operation with ID id, for work picked
+// up from a record that only kept the operation ID. An invalid id starts a
+// new operation.
+func Continue(id string) Operation {
+	if !validHex(id, 32) {
+		return New()
+	}
+	return Operation{ID: id, SpanID: randomHex(8)}
+}
+
+// Parse reads a W3C traceparent header value
+func Parse(traceparent string) (Operation, error) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) != 4 || parts[0] != "00" || !validHex(parts[1], 32) || !validHex(parts[2], 16) || !isHex(parts[3], 2) {
+		return Operation{}, ErrInvalidTraceparent
+	}
+	return Operation{ID: parts[1], SpanID: parts[2]}, nil
+}
+
+// Child returns a new step of the same operation started by o
+func (o Operation) Child() Operation {
+	return Operation{ID: o.ID, SpanID: randomHex(8), ParentSpanID: o.SpanID}
+}
+
+// Traceparent formats o as a W3C traceparent value, to hand the operation
+// to another job or service
+func (o Operation) Traceparent() string {
+	return "00-" + o.ID + "-" + o.SpanID + "-01"
+}
+
+// ShortID is the first block of ID, short enough for a log prefix
+func (o Operation) ShortID() string {
+	if len(o.ID) < 8 {
+		return o.ID
+	}
+	return o.ID[:8]
+}
+
+// Valid reports whether id is a well-formed operation ID
+func Valid(id string) bool {
+	return validHex(id, 32)
+}
+
+// validHex reports whether s is n lowercase hex characters and not all
+// zero, which W3C reserves as invalid for trace and span IDs
+func validHex(s string, n int) bool {
+	return isHex(s, n) && strings.Trim(s, "0") != ""
+}
+
+// isHex reports whether s is n lowercase hex characters
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	// crypto/rand.Read never returns an error
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
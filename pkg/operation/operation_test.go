@@ -0,0 +1,72 @@
+package operation
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestStart(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		wantID      string
+		wantParent  string
+	}{
+		{
+			name:        "continues parent",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantParent:  "00f067aa0ba902b7",
+		},
+		{name: "unset", traceparent: ""},
+		{name: "malformed", traceparent: "00-xyz-00f067aa0ba902b7-01"},
+		{name: "zero trace ID", traceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvTraceparent, tt.traceparent)
+
+			op := Start()
+
+			if !Valid(op.ID) {
+				t.Errorf("ID = %q, want a valid operation ID", op.ID)
+			}
+			if tt.wantID != "" && op.ID != tt.wantID {
+				t.Errorf("ID = %q, want %q", op.ID, tt.wantID)
+			}
+			if op.ParentSpanID != tt.wantParent {
+				t.Errorf("ParentSpanID = %q, want %q", op.ParentSpanID, tt.wantParent)
+			}
+			if op.SpanID == tt.wantParent {
+				t.Error("SpanID was not renewed")
+			}
+		})
+	}
+}
+
+func TestOperation_Traceparent(t *testing.T) {
+	op := New()
+
+	pattern := regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`)
+	if !pattern.MatchString(op.Traceparent()) {
+		t.Errorf("Traceparent() = %q, want a W3C traceparent", op.Traceparent())
+	}
+	parsed, err := Parse(op.Traceparent())
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", op.Traceparent(), err)
+	}
+	if parsed != op {
+		t.Errorf("Parse(Traceparent()) = %+v, want %+v", parsed, op)
+	}
+}
+
+func TestContinue(t *testing.T) {
+	id := "4bf92f3577b34da6a3ce929d0e0e4736"
+	if op := Continue(id); op.ID != id {
+		t.Errorf("Continue(%q).ID = %q", id, op.ID)
+	}
+	if op := Continue("not-an-id"); !Valid(op.ID) {
+		t.Errorf("Continue(invalid).ID = %q, want a new valid ID", op.ID)
+	}
+}
@@ -0,0 +1,79 @@
+package operation
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/shyampundkar/kart-challenge-workspace/pkg/instance"
+)
+
+// Run statuses recorded in pipeline_runs
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Run records one run of a job in the pipeline_runs table
+type Run struct {
+	Operation Operation
+	Job       string
+	StartedAt time.Time
+
+	db *sql.DB
+	id int64
+}
+
+// NewRun prepares the record of a run of job in op. Nothing is written
+// until Begin or Finish.
+func NewRun(db *sql.DB, op Operation, job string) *Run {
+	return &Run{Operation: op, Job: job, StartedAt: time.Now(), db: db}
+}
+
+// Begin records the run as running, so a run that is killed stays visible
+func (r *Run) Begin(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	query := `INSERT INTO pipeline_runs (operation_id, job, instance_id, status, started_at)
+	          VALUES ($1, $2, $3, $4, $5)
+	          RETURNING id`
+	err := r.db.QueryRowContext(ctx, query, r.Operation.ID, r.Job, instance.Get().ID, StatusRunning, r.StartedAt).Scan(&r.id)
+	if err != nil {
+		return fmt.Errorf("error recording pipeline run: %w", err)
+	}
+	return nil
+}
+
+// Finish records the outcome of the run: failed with runErr when it is not
+// nil, otherwise succeeded with summary. A run that was not begun, such as
+// a migration that created pipeline_runs, is inserted finished.
+func (r *Run) Finish(ctx context.Context, summary string, runErr error) error {
+	// The job's own context may be cancelled by the time it finishes
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	status, message := StatusSucceeded, sql.NullString{}
+	if runErr != nil {
+		status, message = StatusFailed, sql.NullString{String: runErr.Error(), Valid: true}
+	}
+	details := sql.NullString{String: summary, Valid: summary != ""}
+
+	var err error
+	if r.id == 0 {
+		_, err = r.db.ExecContext(ctx,
+			`INSERT INTO pipeline_runs (operation_id, job, instance_id, status, summary, error, started_at, finished_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())`,
+			r.Operation.ID, r.Job, instance.Get().ID, status, details, message, r.StartedAt)
+	} else {
+		_, err = r.db.ExecContext(ctx,
+			`UPDATE pipeline_runs SET status = $2, summary = $3, error = $4, finished_at = NOW() WHERE id = $1`,
+			r.id, status, details, message)
+	}
+	if err != nil {
+		return fmt.Errorf("error recording pipeline run result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+// Package promocode holds the rules promo codes follow across services.
+// Codes are case-insensitive: database-load stores them in upper case and
+// order-food upper-cases what customers type before looking it up, so
+// "happyhrs" and "HAPPYHRS" are the same code.
+package promocode
+
+import "strings"
+
+// Normalize returns the canonical form of a promo code: without
+// surrounding whitespace and in upper case. It matches the upper(coupon)
+// expression the coupons table is indexed on.
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,21 @@
+package promocode
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "HAPPYHRS", want: "HAPPYHRS"},
+		{code: "happyhrs", want: "HAPPYHRS"},
+		{code: " HappyHrs\t", want: "HAPPYHRS"},
+		{code: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.code); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
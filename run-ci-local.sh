@@ -147,13 +147,13 @@ run_module_checks() {
 
     # Build
     print_info "Building..."
-    go build -v -o bin/$module ./cmd/main.go
+    go build -v -o bin/$module ./cmd
     print_success "Build successful"
 
     # Build with optimization (order-food only)
     if [ "$module" == "order-food" ]; then
         print_info "Building with optimization..."
-        go build -ldflags="-s -w" -o bin/$module-optimized ./cmd/main.go
+        go build -ldflags="-s -w" -o bin/$module-optimized ./cmd
         ls -lh bin/
         print_success "Optimized build successful"
     fi
@@ -208,7 +208,7 @@ main() {
 
     # Determine which modules to check
     if [ "$1" == "all" ] || [ -z "$1" ]; then
-        MODULES=("database-migration" "database-load" "order-food")
+        MODULES=("pkg" "database-migration" "database-load" "order-food")
     elif [ "$1" == "database-migration" ] || [ "$1" == "database-load" ] || [ "$1" == "order-food" ]; then
         MODULES=("$1")
     else